@@ -19,23 +19,39 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	Dex_GetClient_FullMethodName       = "/api.Dex/GetClient"
-	Dex_CreateClient_FullMethodName    = "/api.Dex/CreateClient"
-	Dex_UpdateClient_FullMethodName    = "/api.Dex/UpdateClient"
-	Dex_DeleteClient_FullMethodName    = "/api.Dex/DeleteClient"
-	Dex_CreatePassword_FullMethodName  = "/api.Dex/CreatePassword"
-	Dex_UpdatePassword_FullMethodName  = "/api.Dex/UpdatePassword"
-	Dex_DeletePassword_FullMethodName  = "/api.Dex/DeletePassword"
-	Dex_ListPasswords_FullMethodName   = "/api.Dex/ListPasswords"
-	Dex_CreateConnector_FullMethodName = "/api.Dex/CreateConnector"
-	Dex_UpdateConnector_FullMethodName = "/api.Dex/UpdateConnector"
-	Dex_DeleteConnector_FullMethodName = "/api.Dex/DeleteConnector"
-	Dex_ListConnectors_FullMethodName  = "/api.Dex/ListConnectors"
-	Dex_GetVersion_FullMethodName      = "/api.Dex/GetVersion"
-	Dex_GetDiscovery_FullMethodName    = "/api.Dex/GetDiscovery"
-	Dex_ListRefresh_FullMethodName     = "/api.Dex/ListRefresh"
-	Dex_RevokeRefresh_FullMethodName   = "/api.Dex/RevokeRefresh"
-	Dex_VerifyPassword_FullMethodName  = "/api.Dex/VerifyPassword"
+	Dex_GetClient_FullMethodName            = "/api.Dex/GetClient"
+	Dex_CreateClient_FullMethodName         = "/api.Dex/CreateClient"
+	Dex_UpdateClient_FullMethodName         = "/api.Dex/UpdateClient"
+	Dex_DeleteClient_FullMethodName         = "/api.Dex/DeleteClient"
+	Dex_ListClients_FullMethodName          = "/api.Dex/ListClients"
+	Dex_RotateClientSecret_FullMethodName   = "/api.Dex/RotateClientSecret"
+	Dex_CreatePassword_FullMethodName       = "/api.Dex/CreatePassword"
+	Dex_UpdatePassword_FullMethodName       = "/api.Dex/UpdatePassword"
+	Dex_DeletePassword_FullMethodName       = "/api.Dex/DeletePassword"
+	Dex_ListPasswords_FullMethodName        = "/api.Dex/ListPasswords"
+	Dex_UnlockPassword_FullMethodName       = "/api.Dex/UnlockPassword"
+	Dex_CreateConnector_FullMethodName      = "/api.Dex/CreateConnector"
+	Dex_UpdateConnector_FullMethodName      = "/api.Dex/UpdateConnector"
+	Dex_DeleteConnector_FullMethodName      = "/api.Dex/DeleteConnector"
+	Dex_ListConnectors_FullMethodName       = "/api.Dex/ListConnectors"
+	Dex_GetVersion_FullMethodName           = "/api.Dex/GetVersion"
+	Dex_GetDiscovery_FullMethodName         = "/api.Dex/GetDiscovery"
+	Dex_ListRefresh_FullMethodName          = "/api.Dex/ListRefresh"
+	Dex_RevokeRefresh_FullMethodName        = "/api.Dex/RevokeRefresh"
+	Dex_ListUsers_FullMethodName            = "/api.Dex/ListUsers"
+	Dex_RevokeUserSessions_FullMethodName   = "/api.Dex/RevokeUserSessions"
+	Dex_VerifyPassword_FullMethodName       = "/api.Dex/VerifyPassword"
+	Dex_WatchEvents_FullMethodName          = "/api.Dex/WatchEvents"
+	Dex_RunGarbageCollection_FullMethodName = "/api.Dex/RunGarbageCollection"
+	Dex_RotateSigningKeys_FullMethodName    = "/api.Dex/RotateSigningKeys"
+	Dex_ListDeviceRequests_FullMethodName   = "/api.Dex/ListDeviceRequests"
+	Dex_ApproveDeviceRequest_FullMethodName = "/api.Dex/ApproveDeviceRequest"
+	Dex_DenyDeviceRequest_FullMethodName    = "/api.Dex/DenyDeviceRequest"
+	Dex_Import_FullMethodName               = "/api.Dex/Import"
+	Dex_Export_FullMethodName               = "/api.Dex/Export"
+	Dex_CreateWebhook_FullMethodName        = "/api.Dex/CreateWebhook"
+	Dex_ListWebhooks_FullMethodName         = "/api.Dex/ListWebhooks"
+	Dex_DeleteWebhook_FullMethodName        = "/api.Dex/DeleteWebhook"
 )
 
 // DexClient is the client API for Dex service.
@@ -50,6 +66,11 @@ type DexClient interface {
 	UpdateClient(ctx context.Context, in *UpdateClientReq, opts ...grpc.CallOption) (*UpdateClientResp, error)
 	// DeleteClient deletes the provided client.
 	DeleteClient(ctx context.Context, in *DeleteClientReq, opts ...grpc.CallOption) (*DeleteClientResp, error)
+	// ListClients lists clients page by page.
+	ListClients(ctx context.Context, in *ListClientsReq, opts ...grpc.CallOption) (*ListClientsResp, error)
+	// RotateClientSecret replaces the secret of a client, optionally keeping the
+	// current secret valid for a grace period.
+	RotateClientSecret(ctx context.Context, in *RotateClientSecretReq, opts ...grpc.CallOption) (*RotateClientSecretResp, error)
 	// CreatePassword creates a password.
 	CreatePassword(ctx context.Context, in *CreatePasswordReq, opts ...grpc.CallOption) (*CreatePasswordResp, error)
 	// UpdatePassword modifies existing password.
@@ -58,6 +79,9 @@ type DexClient interface {
 	DeletePassword(ctx context.Context, in *DeletePasswordReq, opts ...grpc.CallOption) (*DeletePasswordResp, error)
 	// ListPassword lists all password entries.
 	ListPasswords(ctx context.Context, in *ListPasswordReq, opts ...grpc.CallOption) (*ListPasswordResp, error)
+	// UnlockPassword ends the lockout of a password after repeated failed
+	// logins and resets its count of failures.
+	UnlockPassword(ctx context.Context, in *UnlockPasswordReq, opts ...grpc.CallOption) (*UnlockPasswordResp, error)
 	// CreateConnector creates a connector.
 	CreateConnector(ctx context.Context, in *CreateConnectorReq, opts ...grpc.CallOption) (*CreateConnectorResp, error)
 	// UpdateConnector modifies existing connector.
@@ -72,12 +96,45 @@ type DexClient interface {
 	GetDiscovery(ctx context.Context, in *DiscoveryReq, opts ...grpc.CallOption) (*DiscoveryResp, error)
 	// ListRefresh lists all the refresh token entries for a particular user.
 	ListRefresh(ctx context.Context, in *ListRefreshReq, opts ...grpc.CallOption) (*ListRefreshResp, error)
-	// RevokeRefresh revokes the refresh token for the provided user-client pair.
+	// RevokeRefresh revokes the refresh tokens for the provided user-client pair.
 	//
 	// Note that each user-client pair can have only one refresh token at a time.
 	RevokeRefresh(ctx context.Context, in *RevokeRefreshReq, opts ...grpc.CallOption) (*RevokeRefreshResp, error)
+	// ListUsers lists the users with a password or offline sessions, along with
+	// their sessions.
+	ListUsers(ctx context.Context, in *ListUsersReq, opts ...grpc.CallOption) (*ListUsersResp, error)
+	// RevokeUserSessions deletes all refresh tokens and the offline sessions of
+	// a user of a connector, for example when deprovisioning an account.
+	RevokeUserSessions(ctx context.Context, in *RevokeUserSessionsReq, opts ...grpc.CallOption) (*RevokeUserSessionsResp, error)
 	// VerifyPassword returns whether a password matches a hash for a specific email or not.
 	VerifyPassword(ctx context.Context, in *VerifyPasswordReq, opts ...grpc.CallOption) (*VerifyPasswordResp, error)
+	// WatchEvents streams audit events as they happen. Events are not stored,
+	// and are dropped for subscribers that can't keep up.
+	WatchEvents(ctx context.Context, in *WatchEventsReq, opts ...grpc.CallOption) (Dex_WatchEventsClient, error)
+	// RunGarbageCollection deletes expired auth requests, auth codes and device
+	// flow objects without waiting for the next scheduled run.
+	RunGarbageCollection(ctx context.Context, in *RunGarbageCollectionReq, opts ...grpc.CallOption) (*RunGarbageCollectionResp, error)
+	// RotateSigningKeys rotates the signing keys without waiting for the next
+	// scheduled rotation, e.g. when a key may have been compromised.
+	RotateSigningKeys(ctx context.Context, in *RotateSigningKeysReq, opts ...grpc.CallOption) (*RotateSigningKeysResp, error)
+	// ListDeviceRequests lists device authorization requests waiting for a user.
+	ListDeviceRequests(ctx context.Context, in *ListDeviceRequestsReq, opts ...grpc.CallOption) (*ListDeviceRequestsResp, error)
+	// ApproveDeviceRequest completes a device request for a user without a
+	// browser login. No refresh token is issued to the device.
+	ApproveDeviceRequest(ctx context.Context, in *ApproveDeviceRequestReq, opts ...grpc.CallOption) (*ApproveDeviceRequestResp, error)
+	// DenyDeviceRequest rejects a device request.
+	DenyDeviceRequest(ctx context.Context, in *DenyDeviceRequestReq, opts ...grpc.CallOption) (*DenyDeviceRequestResp, error)
+	// Import creates or replaces a batch of clients and passwords, e.g. to
+	// bootstrap an environment from files kept in version control.
+	Import(ctx context.Context, in *ImportReq, opts ...grpc.CallOption) (*ImportResp, error)
+	// Export returns all clients and passwords, including secrets and hashes.
+	Export(ctx context.Context, in *ExportReq, opts ...grpc.CallOption) (*ExportResp, error)
+	// CreateWebhook registers an endpoint notified of audit events.
+	CreateWebhook(ctx context.Context, in *CreateWebhookReq, opts ...grpc.CallOption) (*CreateWebhookResp, error)
+	// ListWebhooks lists the registered webhooks.
+	ListWebhooks(ctx context.Context, in *ListWebhooksReq, opts ...grpc.CallOption) (*ListWebhooksResp, error)
+	// DeleteWebhook unregisters a webhook.
+	DeleteWebhook(ctx context.Context, in *DeleteWebhookReq, opts ...grpc.CallOption) (*DeleteWebhookResp, error)
 }
 
 type dexClient struct {
@@ -124,6 +181,24 @@ func (c *dexClient) DeleteClient(ctx context.Context, in *DeleteClientReq, opts
 	return out, nil
 }
 
+func (c *dexClient) ListClients(ctx context.Context, in *ListClientsReq, opts ...grpc.CallOption) (*ListClientsResp, error) {
+	out := new(ListClientsResp)
+	err := c.cc.Invoke(ctx, Dex_ListClients_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dexClient) RotateClientSecret(ctx context.Context, in *RotateClientSecretReq, opts ...grpc.CallOption) (*RotateClientSecretResp, error) {
+	out := new(RotateClientSecretResp)
+	err := c.cc.Invoke(ctx, Dex_RotateClientSecret_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *dexClient) CreatePassword(ctx context.Context, in *CreatePasswordReq, opts ...grpc.CallOption) (*CreatePasswordResp, error) {
 	out := new(CreatePasswordResp)
 	err := c.cc.Invoke(ctx, Dex_CreatePassword_FullMethodName, in, out, opts...)
@@ -160,6 +235,15 @@ func (c *dexClient) ListPasswords(ctx context.Context, in *ListPasswordReq, opts
 	return out, nil
 }
 
+func (c *dexClient) UnlockPassword(ctx context.Context, in *UnlockPasswordReq, opts ...grpc.CallOption) (*UnlockPasswordResp, error) {
+	out := new(UnlockPasswordResp)
+	err := c.cc.Invoke(ctx, Dex_UnlockPassword_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *dexClient) CreateConnector(ctx context.Context, in *CreateConnectorReq, opts ...grpc.CallOption) (*CreateConnectorResp, error) {
 	out := new(CreateConnectorResp)
 	err := c.cc.Invoke(ctx, Dex_CreateConnector_FullMethodName, in, out, opts...)
@@ -232,6 +316,24 @@ func (c *dexClient) RevokeRefresh(ctx context.Context, in *RevokeRefreshReq, opt
 	return out, nil
 }
 
+func (c *dexClient) ListUsers(ctx context.Context, in *ListUsersReq, opts ...grpc.CallOption) (*ListUsersResp, error) {
+	out := new(ListUsersResp)
+	err := c.cc.Invoke(ctx, Dex_ListUsers_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dexClient) RevokeUserSessions(ctx context.Context, in *RevokeUserSessionsReq, opts ...grpc.CallOption) (*RevokeUserSessionsResp, error) {
+	out := new(RevokeUserSessionsResp)
+	err := c.cc.Invoke(ctx, Dex_RevokeUserSessions_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *dexClient) VerifyPassword(ctx context.Context, in *VerifyPasswordReq, opts ...grpc.CallOption) (*VerifyPasswordResp, error) {
 	out := new(VerifyPasswordResp)
 	err := c.cc.Invoke(ctx, Dex_VerifyPassword_FullMethodName, in, out, opts...)
@@ -241,6 +343,128 @@ func (c *dexClient) VerifyPassword(ctx context.Context, in *VerifyPasswordReq, o
 	return out, nil
 }
 
+func (c *dexClient) WatchEvents(ctx context.Context, in *WatchEventsReq, opts ...grpc.CallOption) (Dex_WatchEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Dex_ServiceDesc.Streams[0], Dex_WatchEvents_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dexWatchEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Dex_WatchEventsClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type dexWatchEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *dexWatchEventsClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *dexClient) RunGarbageCollection(ctx context.Context, in *RunGarbageCollectionReq, opts ...grpc.CallOption) (*RunGarbageCollectionResp, error) {
+	out := new(RunGarbageCollectionResp)
+	err := c.cc.Invoke(ctx, Dex_RunGarbageCollection_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dexClient) RotateSigningKeys(ctx context.Context, in *RotateSigningKeysReq, opts ...grpc.CallOption) (*RotateSigningKeysResp, error) {
+	out := new(RotateSigningKeysResp)
+	err := c.cc.Invoke(ctx, Dex_RotateSigningKeys_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dexClient) ListDeviceRequests(ctx context.Context, in *ListDeviceRequestsReq, opts ...grpc.CallOption) (*ListDeviceRequestsResp, error) {
+	out := new(ListDeviceRequestsResp)
+	err := c.cc.Invoke(ctx, Dex_ListDeviceRequests_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dexClient) ApproveDeviceRequest(ctx context.Context, in *ApproveDeviceRequestReq, opts ...grpc.CallOption) (*ApproveDeviceRequestResp, error) {
+	out := new(ApproveDeviceRequestResp)
+	err := c.cc.Invoke(ctx, Dex_ApproveDeviceRequest_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dexClient) DenyDeviceRequest(ctx context.Context, in *DenyDeviceRequestReq, opts ...grpc.CallOption) (*DenyDeviceRequestResp, error) {
+	out := new(DenyDeviceRequestResp)
+	err := c.cc.Invoke(ctx, Dex_DenyDeviceRequest_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dexClient) Import(ctx context.Context, in *ImportReq, opts ...grpc.CallOption) (*ImportResp, error) {
+	out := new(ImportResp)
+	err := c.cc.Invoke(ctx, Dex_Import_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dexClient) Export(ctx context.Context, in *ExportReq, opts ...grpc.CallOption) (*ExportResp, error) {
+	out := new(ExportResp)
+	err := c.cc.Invoke(ctx, Dex_Export_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dexClient) CreateWebhook(ctx context.Context, in *CreateWebhookReq, opts ...grpc.CallOption) (*CreateWebhookResp, error) {
+	out := new(CreateWebhookResp)
+	err := c.cc.Invoke(ctx, Dex_CreateWebhook_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dexClient) ListWebhooks(ctx context.Context, in *ListWebhooksReq, opts ...grpc.CallOption) (*ListWebhooksResp, error) {
+	out := new(ListWebhooksResp)
+	err := c.cc.Invoke(ctx, Dex_ListWebhooks_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dexClient) DeleteWebhook(ctx context.Context, in *DeleteWebhookReq, opts ...grpc.CallOption) (*DeleteWebhookResp, error) {
+	out := new(DeleteWebhookResp)
+	err := c.cc.Invoke(ctx, Dex_DeleteWebhook_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DexServer is the server API for Dex service.
 // All implementations must embed UnimplementedDexServer
 // for forward compatibility
@@ -253,6 +477,11 @@ type DexServer interface {
 	UpdateClient(context.Context, *UpdateClientReq) (*UpdateClientResp, error)
 	// DeleteClient deletes the provided client.
 	DeleteClient(context.Context, *DeleteClientReq) (*DeleteClientResp, error)
+	// ListClients lists clients page by page.
+	ListClients(context.Context, *ListClientsReq) (*ListClientsResp, error)
+	// RotateClientSecret replaces the secret of a client, optionally keeping the
+	// current secret valid for a grace period.
+	RotateClientSecret(context.Context, *RotateClientSecretReq) (*RotateClientSecretResp, error)
 	// CreatePassword creates a password.
 	CreatePassword(context.Context, *CreatePasswordReq) (*CreatePasswordResp, error)
 	// UpdatePassword modifies existing password.
@@ -261,6 +490,9 @@ type DexServer interface {
 	DeletePassword(context.Context, *DeletePasswordReq) (*DeletePasswordResp, error)
 	// ListPassword lists all password entries.
 	ListPasswords(context.Context, *ListPasswordReq) (*ListPasswordResp, error)
+	// UnlockPassword ends the lockout of a password after repeated failed
+	// logins and resets its count of failures.
+	UnlockPassword(context.Context, *UnlockPasswordReq) (*UnlockPasswordResp, error)
 	// CreateConnector creates a connector.
 	CreateConnector(context.Context, *CreateConnectorReq) (*CreateConnectorResp, error)
 	// UpdateConnector modifies existing connector.
@@ -275,12 +507,45 @@ type DexServer interface {
 	GetDiscovery(context.Context, *DiscoveryReq) (*DiscoveryResp, error)
 	// ListRefresh lists all the refresh token entries for a particular user.
 	ListRefresh(context.Context, *ListRefreshReq) (*ListRefreshResp, error)
-	// RevokeRefresh revokes the refresh token for the provided user-client pair.
+	// RevokeRefresh revokes the refresh tokens for the provided user-client pair.
 	//
 	// Note that each user-client pair can have only one refresh token at a time.
 	RevokeRefresh(context.Context, *RevokeRefreshReq) (*RevokeRefreshResp, error)
+	// ListUsers lists the users with a password or offline sessions, along with
+	// their sessions.
+	ListUsers(context.Context, *ListUsersReq) (*ListUsersResp, error)
+	// RevokeUserSessions deletes all refresh tokens and the offline sessions of
+	// a user of a connector, for example when deprovisioning an account.
+	RevokeUserSessions(context.Context, *RevokeUserSessionsReq) (*RevokeUserSessionsResp, error)
 	// VerifyPassword returns whether a password matches a hash for a specific email or not.
 	VerifyPassword(context.Context, *VerifyPasswordReq) (*VerifyPasswordResp, error)
+	// WatchEvents streams audit events as they happen. Events are not stored,
+	// and are dropped for subscribers that can't keep up.
+	WatchEvents(*WatchEventsReq, Dex_WatchEventsServer) error
+	// RunGarbageCollection deletes expired auth requests, auth codes and device
+	// flow objects without waiting for the next scheduled run.
+	RunGarbageCollection(context.Context, *RunGarbageCollectionReq) (*RunGarbageCollectionResp, error)
+	// RotateSigningKeys rotates the signing keys without waiting for the next
+	// scheduled rotation, e.g. when a key may have been compromised.
+	RotateSigningKeys(context.Context, *RotateSigningKeysReq) (*RotateSigningKeysResp, error)
+	// ListDeviceRequests lists device authorization requests waiting for a user.
+	ListDeviceRequests(context.Context, *ListDeviceRequestsReq) (*ListDeviceRequestsResp, error)
+	// ApproveDeviceRequest completes a device request for a user without a
+	// browser login. No refresh token is issued to the device.
+	ApproveDeviceRequest(context.Context, *ApproveDeviceRequestReq) (*ApproveDeviceRequestResp, error)
+	// DenyDeviceRequest rejects a device request.
+	DenyDeviceRequest(context.Context, *DenyDeviceRequestReq) (*DenyDeviceRequestResp, error)
+	// Import creates or replaces a batch of clients and passwords, e.g. to
+	// bootstrap an environment from files kept in version control.
+	Import(context.Context, *ImportReq) (*ImportResp, error)
+	// Export returns all clients and passwords, including secrets and hashes.
+	Export(context.Context, *ExportReq) (*ExportResp, error)
+	// CreateWebhook registers an endpoint notified of audit events.
+	CreateWebhook(context.Context, *CreateWebhookReq) (*CreateWebhookResp, error)
+	// ListWebhooks lists the registered webhooks.
+	ListWebhooks(context.Context, *ListWebhooksReq) (*ListWebhooksResp, error)
+	// DeleteWebhook unregisters a webhook.
+	DeleteWebhook(context.Context, *DeleteWebhookReq) (*DeleteWebhookResp, error)
 	mustEmbedUnimplementedDexServer()
 }
 
@@ -300,6 +565,12 @@ func (UnimplementedDexServer) UpdateClient(context.Context, *UpdateClientReq) (*
 func (UnimplementedDexServer) DeleteClient(context.Context, *DeleteClientReq) (*DeleteClientResp, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteClient not implemented")
 }
+func (UnimplementedDexServer) ListClients(context.Context, *ListClientsReq) (*ListClientsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListClients not implemented")
+}
+func (UnimplementedDexServer) RotateClientSecret(context.Context, *RotateClientSecretReq) (*RotateClientSecretResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RotateClientSecret not implemented")
+}
 func (UnimplementedDexServer) CreatePassword(context.Context, *CreatePasswordReq) (*CreatePasswordResp, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreatePassword not implemented")
 }
@@ -312,6 +583,9 @@ func (UnimplementedDexServer) DeletePassword(context.Context, *DeletePasswordReq
 func (UnimplementedDexServer) ListPasswords(context.Context, *ListPasswordReq) (*ListPasswordResp, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListPasswords not implemented")
 }
+func (UnimplementedDexServer) UnlockPassword(context.Context, *UnlockPasswordReq) (*UnlockPasswordResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnlockPassword not implemented")
+}
 func (UnimplementedDexServer) CreateConnector(context.Context, *CreateConnectorReq) (*CreateConnectorResp, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateConnector not implemented")
 }
@@ -336,9 +610,48 @@ func (UnimplementedDexServer) ListRefresh(context.Context, *ListRefreshReq) (*Li
 func (UnimplementedDexServer) RevokeRefresh(context.Context, *RevokeRefreshReq) (*RevokeRefreshResp, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RevokeRefresh not implemented")
 }
+func (UnimplementedDexServer) ListUsers(context.Context, *ListUsersReq) (*ListUsersResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListUsers not implemented")
+}
+func (UnimplementedDexServer) RevokeUserSessions(context.Context, *RevokeUserSessionsReq) (*RevokeUserSessionsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeUserSessions not implemented")
+}
 func (UnimplementedDexServer) VerifyPassword(context.Context, *VerifyPasswordReq) (*VerifyPasswordResp, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method VerifyPassword not implemented")
 }
+func (UnimplementedDexServer) WatchEvents(*WatchEventsReq, Dex_WatchEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchEvents not implemented")
+}
+func (UnimplementedDexServer) RunGarbageCollection(context.Context, *RunGarbageCollectionReq) (*RunGarbageCollectionResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RunGarbageCollection not implemented")
+}
+func (UnimplementedDexServer) RotateSigningKeys(context.Context, *RotateSigningKeysReq) (*RotateSigningKeysResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RotateSigningKeys not implemented")
+}
+func (UnimplementedDexServer) ListDeviceRequests(context.Context, *ListDeviceRequestsReq) (*ListDeviceRequestsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDeviceRequests not implemented")
+}
+func (UnimplementedDexServer) ApproveDeviceRequest(context.Context, *ApproveDeviceRequestReq) (*ApproveDeviceRequestResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ApproveDeviceRequest not implemented")
+}
+func (UnimplementedDexServer) DenyDeviceRequest(context.Context, *DenyDeviceRequestReq) (*DenyDeviceRequestResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DenyDeviceRequest not implemented")
+}
+func (UnimplementedDexServer) Import(context.Context, *ImportReq) (*ImportResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Import not implemented")
+}
+func (UnimplementedDexServer) Export(context.Context, *ExportReq) (*ExportResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Export not implemented")
+}
+func (UnimplementedDexServer) CreateWebhook(context.Context, *CreateWebhookReq) (*CreateWebhookResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateWebhook not implemented")
+}
+func (UnimplementedDexServer) ListWebhooks(context.Context, *ListWebhooksReq) (*ListWebhooksResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWebhooks not implemented")
+}
+func (UnimplementedDexServer) DeleteWebhook(context.Context, *DeleteWebhookReq) (*DeleteWebhookResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteWebhook not implemented")
+}
 func (UnimplementedDexServer) mustEmbedUnimplementedDexServer() {}
 
 // UnsafeDexServer may be embedded to opt out of forward compatibility for this service.
@@ -424,6 +737,42 @@ func _Dex_DeleteClient_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Dex_ListClients_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListClientsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DexServer).ListClients(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dex_ListClients_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DexServer).ListClients(ctx, req.(*ListClientsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Dex_RotateClientSecret_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RotateClientSecretReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DexServer).RotateClientSecret(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dex_RotateClientSecret_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DexServer).RotateClientSecret(ctx, req.(*RotateClientSecretReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Dex_CreatePassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CreatePasswordReq)
 	if err := dec(in); err != nil {
@@ -496,6 +845,24 @@ func _Dex_ListPasswords_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Dex_UnlockPassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnlockPasswordReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DexServer).UnlockPassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dex_UnlockPassword_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DexServer).UnlockPassword(ctx, req.(*UnlockPasswordReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Dex_CreateConnector_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CreateConnectorReq)
 	if err := dec(in); err != nil {
@@ -640,6 +1007,42 @@ func _Dex_RevokeRefresh_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Dex_ListUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListUsersReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DexServer).ListUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dex_ListUsers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DexServer).ListUsers(ctx, req.(*ListUsersReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Dex_RevokeUserSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeUserSessionsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DexServer).RevokeUserSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dex_RevokeUserSessions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DexServer).RevokeUserSessions(ctx, req.(*RevokeUserSessionsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Dex_VerifyPassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(VerifyPasswordReq)
 	if err := dec(in); err != nil {
@@ -658,6 +1061,207 @@ func _Dex_VerifyPassword_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Dex_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEventsReq)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DexServer).WatchEvents(m, &dexWatchEventsServer{stream})
+}
+
+type Dex_WatchEventsServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type dexWatchEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *dexWatchEventsServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Dex_RunGarbageCollection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunGarbageCollectionReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DexServer).RunGarbageCollection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dex_RunGarbageCollection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DexServer).RunGarbageCollection(ctx, req.(*RunGarbageCollectionReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Dex_RotateSigningKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RotateSigningKeysReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DexServer).RotateSigningKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dex_RotateSigningKeys_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DexServer).RotateSigningKeys(ctx, req.(*RotateSigningKeysReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Dex_ListDeviceRequests_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDeviceRequestsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DexServer).ListDeviceRequests(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dex_ListDeviceRequests_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DexServer).ListDeviceRequests(ctx, req.(*ListDeviceRequestsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Dex_ApproveDeviceRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApproveDeviceRequestReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DexServer).ApproveDeviceRequest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dex_ApproveDeviceRequest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DexServer).ApproveDeviceRequest(ctx, req.(*ApproveDeviceRequestReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Dex_DenyDeviceRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DenyDeviceRequestReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DexServer).DenyDeviceRequest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dex_DenyDeviceRequest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DexServer).DenyDeviceRequest(ctx, req.(*DenyDeviceRequestReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Dex_Import_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DexServer).Import(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dex_Import_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DexServer).Import(ctx, req.(*ImportReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Dex_Export_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DexServer).Export(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dex_Export_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DexServer).Export(ctx, req.(*ExportReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Dex_CreateWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateWebhookReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DexServer).CreateWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dex_CreateWebhook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DexServer).CreateWebhook(ctx, req.(*CreateWebhookReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Dex_ListWebhooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWebhooksReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DexServer).ListWebhooks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dex_ListWebhooks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DexServer).ListWebhooks(ctx, req.(*ListWebhooksReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Dex_DeleteWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteWebhookReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DexServer).DeleteWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dex_DeleteWebhook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DexServer).DeleteWebhook(ctx, req.(*DeleteWebhookReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Dex_ServiceDesc is the grpc.ServiceDesc for Dex service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -681,6 +1285,14 @@ var Dex_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteClient",
 			Handler:    _Dex_DeleteClient_Handler,
 		},
+		{
+			MethodName: "ListClients",
+			Handler:    _Dex_ListClients_Handler,
+		},
+		{
+			MethodName: "RotateClientSecret",
+			Handler:    _Dex_RotateClientSecret_Handler,
+		},
 		{
 			MethodName: "CreatePassword",
 			Handler:    _Dex_CreatePassword_Handler,
@@ -697,6 +1309,10 @@ var Dex_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListPasswords",
 			Handler:    _Dex_ListPasswords_Handler,
 		},
+		{
+			MethodName: "UnlockPassword",
+			Handler:    _Dex_UnlockPassword_Handler,
+		},
 		{
 			MethodName: "CreateConnector",
 			Handler:    _Dex_CreateConnector_Handler,
@@ -729,11 +1345,65 @@ var Dex_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RevokeRefresh",
 			Handler:    _Dex_RevokeRefresh_Handler,
 		},
+		{
+			MethodName: "ListUsers",
+			Handler:    _Dex_ListUsers_Handler,
+		},
+		{
+			MethodName: "RevokeUserSessions",
+			Handler:    _Dex_RevokeUserSessions_Handler,
+		},
 		{
 			MethodName: "VerifyPassword",
 			Handler:    _Dex_VerifyPassword_Handler,
 		},
+		{
+			MethodName: "RunGarbageCollection",
+			Handler:    _Dex_RunGarbageCollection_Handler,
+		},
+		{
+			MethodName: "RotateSigningKeys",
+			Handler:    _Dex_RotateSigningKeys_Handler,
+		},
+		{
+			MethodName: "ListDeviceRequests",
+			Handler:    _Dex_ListDeviceRequests_Handler,
+		},
+		{
+			MethodName: "ApproveDeviceRequest",
+			Handler:    _Dex_ApproveDeviceRequest_Handler,
+		},
+		{
+			MethodName: "DenyDeviceRequest",
+			Handler:    _Dex_DenyDeviceRequest_Handler,
+		},
+		{
+			MethodName: "Import",
+			Handler:    _Dex_Import_Handler,
+		},
+		{
+			MethodName: "Export",
+			Handler:    _Dex_Export_Handler,
+		},
+		{
+			MethodName: "CreateWebhook",
+			Handler:    _Dex_CreateWebhook_Handler,
+		},
+		{
+			MethodName: "ListWebhooks",
+			Handler:    _Dex_ListWebhooks_Handler,
+		},
+		{
+			MethodName: "DeleteWebhook",
+			Handler:    _Dex_DeleteWebhook_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _Dex_WatchEvents_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/v2/api.proto",
 }
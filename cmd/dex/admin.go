@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dexidp/dex/api/v2"
+	"github.com/dexidp/dex/pkg/apiclient"
+)
+
+type adminOptions struct {
+	addr       string
+	caCert     string
+	clientCert string
+	clientKey  string
+	token      string
+	timeout    time.Duration
+}
+
+func commandAdmin() *cobra.Command {
+	options := adminOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "admin",
+		Short: "Run maintenance actions on a running server through the gRPC API",
+		Run: func(cmd *cobra.Command, _ []string) {
+			cmd.Help()
+			os.Exit(2)
+		},
+	}
+
+	options.addFlags(cmd)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:     "gc",
+		Short:   "Delete expired auth requests, auth codes and device flow objects",
+		Example: "dex admin gc --addr 127.0.0.1:5557",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cmd.SilenceUsage = true
+			return runAdmin(options, func(ctx context.Context, client api.DexClient) error {
+				resp, err := client.RunGarbageCollection(ctx, &api.RunGarbageCollectionReq{})
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Deleted %d auth requests, %d auth codes, %d device requests and %d device tokens\n",
+					resp.AuthRequests, resp.AuthCodes, resp.DeviceRequests, resp.DeviceTokens)
+				return nil
+			})
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:     "rotate-keys",
+		Short:   "Rotate the signing keys",
+		Example: "dex admin rotate-keys --addr 127.0.0.1:5557",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cmd.SilenceUsage = true
+			return runAdmin(options, func(ctx context.Context, client api.DexClient) error {
+				if _, err := client.RotateSigningKeys(ctx, &api.RotateSigningKeysReq{}); err != nil {
+					return err
+				}
+				fmt.Println("Rotated signing keys")
+				return nil
+			})
+		},
+	})
+
+	return cmd
+}
+
+// addFlags adds the flags connecting to the gRPC API to a command and its
+// subcommands.
+func (o *adminOptions) addFlags(cmd *cobra.Command) {
+	flags := cmd.PersistentFlags()
+	flags.StringVar(&o.addr, "addr", "127.0.0.1:5557", "Address of the gRPC API")
+	flags.StringVar(&o.caCert, "ca-cert", "", "CA certificate verifying the server, enables TLS")
+	flags.StringVar(&o.clientCert, "client-cert", "", "Client certificate for TLS client authentication")
+	flags.StringVar(&o.clientKey, "client-key", "", "Key of the client certificate")
+	flags.StringVar(&o.token, "token", "", "Bearer token, defaults to $DEX_API_TOKEN")
+	flags.DurationVar(&o.timeout, "timeout", time.Minute, "Timeout of the call")
+}
+
+// runAdmin connects to the gRPC API and runs a call.
+func runAdmin(options adminOptions, call func(ctx context.Context, client api.DexClient) error) error {
+	token := options.token
+	if token == "" {
+		token = os.Getenv("DEX_API_TOKEN")
+	}
+	clientOptions := apiclient.Options{
+		Addr:       options.addr,
+		CACert:     options.caCert,
+		ClientCert: options.clientCert,
+		ClientKey:  options.clientKey,
+		Token:      token,
+	}
+	conn, err := apiclient.Dial(clientOptions)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), options.timeout)
+	defer cancel()
+	return call(clientOptions.Context(ctx), api.NewDexClient(conn))
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -8,15 +9,22 @@ import (
 	"net/http"
 	"net/netip"
 	"os"
+	"reflect"
+	"slices"
 	"strings"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 
+	"github.com/dexidp/dex/connector/middleware"
 	"github.com/dexidp/dex/pkg/featureflags"
 	"github.com/dexidp/dex/server"
+	"github.com/dexidp/dex/server/signer"
 	"github.com/dexidp/dex/storage"
+	"github.com/dexidp/dex/storage/bolt"
 	"github.com/dexidp/dex/storage/ent"
 	"github.com/dexidp/dex/storage/etcd"
+	"github.com/dexidp/dex/storage/external"
 	"github.com/dexidp/dex/storage/kubernetes"
 	"github.com/dexidp/dex/storage/memory"
 	"github.com/dexidp/dex/storage/sql"
@@ -26,12 +34,16 @@ import (
 type Config struct {
 	Issuer    string    `json:"issuer"`
 	Storage   Storage   `json:"storage"`
+	Signer    Signer    `json:"signer"`
 	Web       Web       `json:"web"`
 	Telemetry Telemetry `json:"telemetry"`
 	OAuth2    OAuth2    `json:"oauth2"`
 	GRPC      GRPC      `json:"grpc"`
 	Expiry    Expiry    `json:"expiry"`
+	GC        GC        `json:"gc"`
 	Logger    Logger    `json:"logger"`
+	WebFinger WebFinger `json:"webfinger"`
+	Discovery Discovery `json:"discovery"`
 
 	Frontend server.WebConfig `json:"frontend"`
 
@@ -51,6 +63,99 @@ type Config struct {
 	// querying the storage. Cannot be specified without enabling a passwords
 	// database.
 	StaticPasswords []password `json:"staticPasswords"`
+
+	// PasswordReset lets users of the password database reset their password
+	// with a link sent by e-mail.
+	PasswordReset *PasswordReset `json:"passwordReset"`
+
+	// PasswordLockout locks users of the password database, and IP
+	// addresses, out after repeated failed logins.
+	PasswordLockout *PasswordLockout `json:"passwordLockout"`
+
+	// PasswordPolicy is the policy new passwords of the password database
+	// have to meet.
+	PasswordPolicy *PasswordPolicy `json:"passwordPolicy"`
+
+	// Registration lets users create accounts in the password database after
+	// verifying their e-mail address.
+	Registration *Registration `json:"registration"`
+
+	// EmailVerification sends users of the password database with an
+	// unverified e-mail address a link to verify it when they log in.
+	EmailVerification *EmailVerification `json:"emailVerification"`
+
+	// Captcha challenges users on the password and registration forms after
+	// repeated failed attempts.
+	Captcha *Captcha `json:"captcha"`
+
+	// HomeRealmDiscovery asks users for their e-mail address on the login
+	// page and sends them to the connector of its domain.
+	HomeRealmDiscovery *HomeRealmDiscovery `json:"homeRealmDiscovery"`
+
+	// SMTP server sending the e-mails of password resets, registrations and
+	// e-mail verification.
+	SMTP *SMTP `json:"smtp"`
+
+	// AuditLog writes a record of security relevant events to sinks.
+	AuditLog *AuditLog `json:"auditLog"`
+
+	// SecurityEvents configures the detection of security events, which are
+	// delivered to webhooks and the audit log like other events.
+	SecurityEvents SecurityEvents `json:"securityEvents"`
+
+	// RateLimit limits the rate of requests to the authorization, token and
+	// device endpoints.
+	RateLimit *RateLimit `json:"rateLimit"`
+
+	// Issuers served by the same process and storage in addition to the
+	// default issuer.
+	Issuers []Issuer `json:"issuers"`
+
+	// Clients and connectors of the default issuer when additional issuers
+	// are served.
+	DefaultIssuer DefaultIssuer `json:"defaultIssuer"`
+}
+
+// DefaultIssuer holds the clients and connectors available to the default
+// issuer, the one set by the issuer field, alongside additional issuers.
+type DefaultIssuer struct {
+	// IDs of the clients and connectors available to the default issuer. If
+	// empty, all clients or connectors are available.
+	Clients    []string `json:"clients"`
+	Connectors []string `json:"connectors"`
+}
+
+// Issuer is an additional issuer served alongside the default issuer, for
+// example for another tenant. Requests are routed to it by the host and path
+// of its issuer URL.
+type Issuer struct {
+	Issuer string `json:"issuer"`
+
+	// Frontend of the issuer. Defaults to the frontend of the default issuer.
+	Frontend *server.WebConfig `json:"frontend"`
+
+	// IDs of the clients and connectors available to the issuer. If empty,
+	// all clients or connectors are available.
+	Clients    []string `json:"clients"`
+	Connectors []string `json:"connectors"`
+
+	// WebFinger issuer discovery for the users of the issuer.
+	WebFinger WebFinger `json:"webfinger"`
+}
+
+// WebFinger holds the configuration of WebFinger issuer discovery, which
+// resolves e-mail addresses of users to their issuer.
+type WebFinger struct {
+	// E-mail domains of the users of the issuer. If empty, the issuer is
+	// returned for any e-mail address not claimed by another issuer.
+	Domains []string `json:"domains"`
+}
+
+// Discovery holds the configuration of the OpenID Connect discovery document.
+type Discovery struct {
+	// Fields added to the discovery document, replacing the generated ones.
+	// Fields set to null are removed.
+	ExtraFields map[string]interface{} `json:"extraFields"`
 }
 
 // Validate the configuration
@@ -63,7 +168,11 @@ func (c Config) Validate() error {
 		{c.Issuer == "", "no issuer specified in config file"},
 		{!c.EnablePasswordDB && len(c.StaticPasswords) != 0, "cannot specify static passwords without enabling password db"},
 		{c.Storage.Config == nil, "no storage supplied in config file"},
-		{c.Web.HTTP == "" && c.Web.HTTPS == "", "must supply a HTTP/HTTPS  address to listen on"},
+		{c.Signer.Config != nil && c.Expiry.SigningKeys != "", "cannot specify signing keys expiry with an external signer"},
+		{c.Signer.Config != nil && c.Expiry.VerificationKeys != "", "cannot specify verification keys expiry with an external signer"},
+		{c.Signer.Config != nil && len(c.Issuers) > 0, "cannot serve additional issuers with an external signer"},
+		{len(c.Issuers) == 0 && (len(c.DefaultIssuer.Clients) > 0 || len(c.DefaultIssuer.Connectors) > 0), "defaultIssuer can only be specified with additional issuers"},
+		{c.Web.HTTP == "" && c.Web.HTTPS == "" && len(c.Web.Listeners) == 0, "must supply a HTTP/HTTPS  address to listen on"},
 		{c.Web.HTTPS != "" && c.Web.TLSCert == "", "no cert specified for HTTPS"},
 		{c.Web.HTTPS != "" && c.Web.TLSKey == "", "no private key specified for HTTPS"},
 		{c.Web.TLSMinVersion != "" && c.Web.TLSMinVersion != "1.2" && c.Web.TLSMinVersion != "1.3", "supported TLS versions are: 1.2, 1.3"},
@@ -73,9 +182,27 @@ func (c Config) Validate() error {
 		{c.GRPC.TLSKey != "" && c.GRPC.Addr == "", "no address specified for gRPC"},
 		{(c.GRPC.TLSCert == "") != (c.GRPC.TLSKey == ""), "must specific both a gRPC TLS cert and key"},
 		{c.GRPC.TLSCert == "" && c.GRPC.TLSClientCA != "", "cannot specify gRPC TLS client CA without a gRPC TLS cert"},
+		{c.Web.ClientRemoteIP.ForwardedHeaders && len(c.Web.ClientRemoteIP.TrustedProxies) == 0, "cannot trust forwarded headers without trusted proxies"},
+		{c.Web.HTTPS == "" && len(c.Web.TLSCertificates) != 0, "cannot specify TLS certificates without an HTTPS address"},
+		{c.Web.HTTPS == "" && c.Web.TLSClientCA != "", "cannot specify a TLS client CA without an HTTPS address"},
+		{!validTLSCipherSuites(c.Web.TLSCipherSuites), "unsupported TLS cipher suites, only the secure TLS 1.2 suites of Go are supported"},
+		{!validTLSCurves(c.Web.TLSCurvePreferences), "unsupported TLS curves, supported are: X25519, P256, P384, P521"},
+		{c.Web.Limits.MaxHeaderBytes < 0 || c.Web.Limits.MaxBodyBytes < 0, "web header and body size limits must not be negative"},
+		{c.GRPC.TLSCert == "" && len(c.GRPC.TLSCertificates) != 0, "cannot specify gRPC TLS certificates without a gRPC TLS cert"},
+		{!validTLSCertificates(c.Web.TLSCertificates) || !validTLSCertificates(c.GRPC.TLSCertificates), "TLS certificates must specify both a cert and key"},
 		{c.GRPC.TLSMinVersion != "" && c.GRPC.TLSMinVersion != "1.2" && c.GRPC.TLSMinVersion != "1.3", "supported TLS versions are: 1.2, 1.3"},
 		{c.GRPC.TLSMaxVersion != "" && c.GRPC.TLSMaxVersion != "1.2" && c.GRPC.TLSMaxVersion != "1.3", "supported TLS versions are: 1.2, 1.3"},
 		{c.GRPC.TLSMaxVersion != "" && c.GRPC.TLSMinVersion != "" && c.GRPC.TLSMinVersion > c.GRPC.TLSMaxVersion, "TLSMinVersion greater than TLSMaxVersion"},
+		{c.GRPC.Auth.Enabled() && c.GRPC.Addr == "", "no address specified for gRPC"},
+		{c.OAuth2.StatelessAuthRequests != nil && len(c.OAuth2.StatelessAuthRequests.Keys) == 0, "no keys specified for stateless auth requests"},
+		{c.PasswordReset != nil && !c.EnablePasswordDB, "cannot enable password resets without enabling password db"},
+		{c.PasswordLockout != nil && !c.EnablePasswordDB, "cannot enable password lockout without enabling password db"},
+		{c.PasswordPolicy != nil && !c.EnablePasswordDB, "cannot specify a password policy without enabling password db"},
+		{c.Registration != nil && !c.EnablePasswordDB, "cannot enable registrations without enabling password db"},
+		{c.EmailVerification != nil && !c.EnablePasswordDB, "cannot enable e-mail verification without enabling password db"},
+		{(c.PasswordReset != nil || c.Registration != nil || c.EmailVerification != nil) && c.SMTP == nil, "no SMTP server specified for sending e-mails"},
+		{c.AuditLog != nil && len(c.AuditLog.Sinks) == 0, "no audit log sinks specified"},
+		{c.SecurityEvents.FailedLoginThreshold < 0, "failed login threshold must not be negative"},
 	}
 
 	var checkErrors []string
@@ -85,12 +212,131 @@ func (c Config) Validate() error {
 			checkErrors = append(checkErrors, check.errMsg)
 		}
 	}
+
+	issuers := map[string]bool{c.Issuer: true}
+	for _, issuer := range c.Issuers {
+		switch {
+		case issuer.Issuer == "":
+			checkErrors = append(checkErrors, "no issuer specified for additional issuer")
+		case issuers[issuer.Issuer]:
+			checkErrors = append(checkErrors, fmt.Sprintf("issuer %q specified more than once", issuer.Issuer))
+		}
+		issuers[issuer.Issuer] = true
+	}
+
+	// Names of the http and https listeners, and of the TLS metrics of the web
+	// and gRPC listeners.
+	listenerNames := map[string]bool{"http": true, "https": true, "web": true, "grpc": true}
+	for _, listener := range c.Web.Listeners {
+		switch {
+		case listener.Name == "":
+			checkErrors = append(checkErrors, "no name specified for web listener")
+		case listenerNames[listener.Name]:
+			checkErrors = append(checkErrors, fmt.Sprintf("web listener name %q is reserved or used more than once", listener.Name))
+		case listener.Addr == "":
+			checkErrors = append(checkErrors, fmt.Sprintf("no address specified for web listener %q", listener.Name))
+		case !validTLSCertificates(listener.TLSCertificates):
+			checkErrors = append(checkErrors, fmt.Sprintf("TLS certificates of web listener %q must specify both a cert and key", listener.Name))
+		case !validTLSVersion(listener.TLSMinVersion) || !validTLSVersion(listener.TLSMaxVersion):
+			checkErrors = append(checkErrors, fmt.Sprintf("web listener %q: supported TLS versions are: 1.2, 1.3", listener.Name))
+		case !validTLSCipherSuites(listener.TLSCipherSuites):
+			checkErrors = append(checkErrors, fmt.Sprintf("web listener %q: unsupported TLS cipher suites", listener.Name))
+		case !validTLSCurves(listener.TLSCurvePreferences):
+			checkErrors = append(checkErrors, fmt.Sprintf("web listener %q: unsupported TLS curves", listener.Name))
+		case len(listener.TLSCertificates) == 0 && listener.TLSClientCA != "":
+			checkErrors = append(checkErrors, fmt.Sprintf("web listener %q: cannot specify a TLS client CA without TLS certificates", listener.Name))
+		}
+		listenerNames[listener.Name] = true
+	}
 	if len(checkErrors) != 0 {
 		return fmt.Errorf("invalid Config:\n\t-\t%s", strings.Join(checkErrors, "\n\t-\t"))
 	}
 	return nil
 }
 
+// validTLSVersion reports whether a TLS version is unset or supported.
+func validTLSVersion(version string) bool {
+	return version == "" || version == "1.2" || version == "1.3"
+}
+
+// defaultTLSCipherSuites are the TLS 1.2 cipher suites of listeners not
+// configuring their own. TLS 1.3 suites aren't configurable.
+var defaultTLSCipherSuites = []uint16{
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305,
+	tls.TLS_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_RSA_WITH_AES_256_GCM_SHA384,
+}
+
+// tlsCurves are the curves of the TLS key exchange by their config name.
+var tlsCurves = map[string]tls.CurveID{
+	"X25519": tls.X25519,
+	"P256":   tls.CurveP256,
+	"P384":   tls.CurveP384,
+	"P521":   tls.CurveP521,
+}
+
+// tlsCipherSuites returns the IDs of TLS 1.2 cipher suites given by their
+// names, like "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256", or the default ones
+// if there are none. Only the suites Go considers secure are supported.
+func tlsCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return defaultTLSCipherSuites, nil
+	}
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		i := slices.IndexFunc(tls.CipherSuites(), func(s *tls.CipherSuite) bool {
+			return s.Name == name && slices.Contains(s.SupportedVersions, tls.VersionTLS12)
+		})
+		if i < 0 {
+			return nil, fmt.Errorf("unsupported TLS cipher suite %q", name)
+		}
+		ids = append(ids, tls.CipherSuites()[i].ID)
+	}
+	return ids, nil
+}
+
+// validTLSCipherSuites reports whether all cipher suites are supported.
+func validTLSCipherSuites(names []string) bool {
+	_, err := tlsCipherSuites(names)
+	return err == nil
+}
+
+// tlsCurvePreferences returns the curves given by their names in order of
+// preference, or nil for the defaults of Go if there are none.
+func tlsCurvePreferences(names []string) ([]tls.CurveID, error) {
+	var curves []tls.CurveID
+	for _, name := range names {
+		curve, ok := tlsCurves[name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported TLS curve %q", name)
+		}
+		curves = append(curves, curve)
+	}
+	return curves, nil
+}
+
+// validTLSCurves reports whether all curves are supported.
+func validTLSCurves(names []string) bool {
+	_, err := tlsCurvePreferences(names)
+	return err == nil
+}
+
+// validTLSCertificates reports whether all certificates specify a cert and
+// key.
+func validTLSCertificates(certs []TLSCertificate) bool {
+	for _, cert := range certs {
+		if cert.Cert == "" || cert.Key == "" {
+			return false
+		}
+	}
+	return true
+}
+
 type password storage.Password
 
 func (p *password) UnmarshalJSON(b []byte) error {
@@ -100,14 +346,18 @@ func (p *password) UnmarshalJSON(b []byte) error {
 		UserID      string `json:"userID"`
 		Hash        string `json:"hash"`
 		HashFromEnv string `json:"hashFromEnv"`
+		// Static passwords can't be updated, so addresses can't be verified
+		// by e-mail and are considered verified unless specified otherwise.
+		EmailVerified *bool `json:"emailVerified"`
 	}
 	if err := json.Unmarshal(b, &data); err != nil {
 		return err
 	}
 	*p = password(storage.Password{
-		Email:    data.Email,
-		Username: data.Username,
-		UserID:   data.UserID,
+		Email:         data.Email,
+		Username:      data.Username,
+		UserID:        data.UserID,
+		EmailVerified: data.EmailVerified == nil || *data.EmailVerified,
 	})
 	if len(data.Hash) == 0 && len(data.HashFromEnv) > 0 {
 		data.Hash = os.Getenv(data.HashFromEnv)
@@ -149,25 +399,289 @@ type OAuth2 struct {
 	AlwaysShowLoginScreen bool `json:"alwaysShowLoginScreen"`
 	// This is the connector that can be used for password grant
 	PasswordConnector string `json:"passwordConnector"`
+	// Additional scopes clients can request, each adding a set of claims to
+	// ID tokens.
+	CustomScopes []CustomScope `json:"customScopes"`
+	// CEL expressions setting claims of ID and access tokens right before
+	// they're signed.
+	ClaimsTransforms []storage.ClaimsTransform `json:"claimsTransforms"`
+	// Webhook called before tokens are signed, which can add or override
+	// claims or deny issuing tokens.
+	TokenWebhook *TokenWebhook `json:"tokenWebhook"`
+	// External policy, e.g. an Open Policy Agent, allowing or denying the
+	// authorization of users and the issuance of tokens.
+	AuthorizationPolicy *AuthorizationPolicy `json:"authorizationPolicy"`
+	// Settings of the device flow.
+	DeviceFlow DeviceFlow `json:"deviceFlow"`
+	// Encrypt auth requests into their IDs instead of writing them to the
+	// storage.
+	StatelessAuthRequests *StatelessAuthRequests `json:"statelessAuthRequests"`
+}
+
+// TokenWebhook is the config format of the webhook called before tokens are
+// signed.
+type TokenWebhook struct {
+	URL    string `json:"url"`
+	Secret string `json:"secret"`
+	// How long to wait for a response, e.g. "2s". Defaults to 5 seconds.
+	Timeout string `json:"timeout"`
+	// Issue tokens unchanged if the webhook fails, instead of failing the
+	// token request.
+	FailOpen bool `json:"failOpen"`
+}
+
+// ToServerConfig converts the token webhook settings.
+func (w TokenWebhook) ToServerConfig() (*server.TokenWebhookConfig, error) {
+	c := &server.TokenWebhookConfig{
+		URL:      w.URL,
+		Secret:   w.Secret,
+		FailOpen: w.FailOpen,
+	}
+	if w.Timeout != "" {
+		timeout, err := time.ParseDuration(w.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid config value %q for token webhook timeout: %v", w.Timeout, err)
+		}
+		c.Timeout = timeout
+	}
+	return c, nil
+}
+
+// AuthorizationPolicy is the config format of the external authorization
+// policy.
+type AuthorizationPolicy struct {
+	// URL of the policy decision in the OPA data API.
+	URL   string `json:"url"`
+	Token string `json:"token"`
+	// How long to wait for a decision, e.g. "1s". Defaults to 5 seconds.
+	Timeout string `json:"timeout"`
+	// Allow requests if the policy agent fails, instead of denying them.
+	FailOpen bool `json:"failOpen"`
+}
+
+// ToServerConfig converts the authorization policy settings.
+func (p AuthorizationPolicy) ToServerConfig() (*server.AuthorizationPolicyConfig, error) {
+	c := &server.AuthorizationPolicyConfig{
+		URL:      p.URL,
+		Token:    p.Token,
+		FailOpen: p.FailOpen,
+	}
+	if p.Timeout != "" {
+		timeout, err := time.ParseDuration(p.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid config value %q for authorization policy timeout: %v", p.Timeout, err)
+		}
+		c.Timeout = timeout
+	}
+	return c, nil
+}
+
+// StatelessAuthRequests is the config format of stateless auth requests.
+type StatelessAuthRequests struct {
+	// Base64 encoded AES keys of 16, 24 or 32 bytes. The first encrypts auth
+	// requests, all decrypt them.
+	Keys []string `json:"keys"`
+}
+
+// ToServerConfig decodes the keys of stateless auth requests.
+func (a StatelessAuthRequests) ToServerConfig() (*server.StatelessAuthRequestsConfig, error) {
+	c := &server.StatelessAuthRequestsConfig{}
+	for i, key := range a.Keys {
+		decoded, err := base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid config value for stateless auth requests key %d: %v", i, err)
+		}
+		c.Keys = append(c.Keys, decoded)
+	}
+	return c, nil
+}
+
+// DeviceFlow holds the settings of the device authorization grant. How long
+// device requests are valid is configured by expiry.deviceRequests.
+type DeviceFlow struct {
+	// How long devices have to wait between polling for tokens, e.g. "5s".
+	PollInterval string `json:"pollInterval"`
+	// Number of characters of user codes, defaults to 8.
+	UserCodeLength int `json:"userCodeLength"`
+	// Characters user codes are made of, defaults to upper case consonants.
+	UserCodeCharset string `json:"userCodeCharset"`
+}
+
+// CustomScope is the config format for a scope releasing a set of claims.
+type CustomScope struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Claims      []string `json:"claims"`
 }
 
 // Web is the config format for the HTTP server.
 type Web struct {
-	HTTP           string         `json:"http"`
-	HTTPS          string         `json:"https"`
-	Headers        Headers        `json:"headers"`
-	TLSCert        string         `json:"tlsCert"`
-	TLSKey         string         `json:"tlsKey"`
-	TLSMinVersion  string         `json:"tlsMinVersion"`
-	TLSMaxVersion  string         `json:"tlsMaxVersion"`
+	HTTP          string  `json:"http"`
+	HTTPS         string  `json:"https"`
+	Headers       Headers `json:"headers"`
+	TLSCert       string  `json:"tlsCert"`
+	TLSKey        string  `json:"tlsKey"`
+	TLSMinVersion string  `json:"tlsMinVersion"`
+	TLSMaxVersion string  `json:"tlsMaxVersion"`
+	// TLS 1.2 cipher suites, and curves in order of preference, replacing
+	// the defaults.
+	TLSCipherSuites     []string `json:"tlsCipherSuites"`
+	TLSCurvePreferences []string `json:"tlsCurvePreferences"`
+	// If set, clients must present a certificate signed by this CA.
+	TLSClientCA string `json:"tlsClientCA"`
+	// Certificates served instead of TLSCert to clients asking for their
+	// names (SNI), e.g. when dex is reachable under several hostnames.
+	TLSCertificates []TLSCertificate `json:"tlsCertificates"`
+	// Listeners served in addition to HTTP and HTTPS, e.g. a plain HTTP
+	// listener for a service mesh next to a public HTTPS one.
+	Listeners      []WebListener  `json:"listeners"`
 	AllowedOrigins []string       `json:"allowedOrigins"`
 	AllowedHeaders []string       `json:"allowedHeaders"`
 	ClientRemoteIP ClientRemoteIP `json:"clientRemoteIP"`
+	// Timeouts and size limits of requests to the web listeners.
+	Limits WebLimits `json:"limits"`
+	// Attributes of the cookies set by dex.
+	Cookies *Cookies `json:"cookies"`
+	// If set, the password, approval and device forms are protected against
+	// cross-site request forgery with double-submit tokens.
+	CSRFProtection bool `json:"csrfProtection"`
+}
+
+// Cookies is the config format of the attributes of cookies.
+type Cookies struct {
+	// One of "lax", "strict" or "none". Defaults to "lax".
+	SameSite string `json:"sameSite"`
+	// Defaults to whether the issuer is an https URL.
+	Secure *bool  `json:"secure"`
+	Domain string `json:"domain"`
+	// Lifetime of cookies. Defaults to session cookies.
+	MaxAge string `json:"maxAge"`
+}
+
+var cookieSameSite = map[string]http.SameSite{
+	"":       http.SameSiteLaxMode,
+	"lax":    http.SameSiteLaxMode,
+	"strict": http.SameSiteStrictMode,
+	"none":   http.SameSiteNoneMode,
 }
 
+// ToServerConfig parses the cookie settings.
+func (k Cookies) ToServerConfig() (*server.CookieConfig, error) {
+	sameSite, ok := cookieSameSite[strings.ToLower(k.SameSite)]
+	if !ok {
+		return nil, fmt.Errorf("invalid config value %q for cookies same site, supported are: lax, strict, none", k.SameSite)
+	}
+	c := &server.CookieConfig{
+		SameSite: sameSite,
+		Secure:   k.Secure,
+		Domain:   k.Domain,
+	}
+	if k.MaxAge != "" {
+		maxAge, err := time.ParseDuration(k.MaxAge)
+		if err != nil || maxAge <= 0 {
+			return nil, fmt.Errorf("invalid config value %q for cookies max age", k.MaxAge)
+		}
+		c.MaxAge = maxAge
+	}
+	return c, nil
+}
+
+// WebLimits are the timeouts and size limits of requests to the web
+// listeners, protecting them against slow and oversized requests. Timeouts
+// are durations like "30s".
+type WebLimits struct {
+	// How long reading the request headers may take. Defaults to 10s.
+	ReadHeaderTimeout string `json:"readHeaderTimeout"`
+	// How long reading the whole request may take. Defaults to no limit.
+	ReadTimeout string `json:"readTimeout"`
+	// How long writing the response may take. Defaults to no limit.
+	WriteTimeout string `json:"writeTimeout"`
+	// How long idle keep-alive connections are kept open. Defaults to 2m.
+	IdleTimeout string `json:"idleTimeout"`
+	// Maximum size of the request headers. Defaults to 1 MiB.
+	MaxHeaderBytes int `json:"maxHeaderBytes"`
+	// Maximum size of request bodies. Defaults to 1 MiB.
+	MaxBodyBytes int64 `json:"maxBodyBytes"`
+}
+
+// httpServer returns a server of the web listeners with these limits.
+func (l WebLimits) httpServer(handler http.Handler) (*http.Server, error) {
+	server := &http.Server{
+		ReadHeaderTimeout: 10 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+		MaxHeaderBytes:    l.MaxHeaderBytes,
+	}
+	for _, d := range []struct {
+		name  string
+		value string
+		dst   *time.Duration
+	}{
+		{"read header timeout", l.ReadHeaderTimeout, &server.ReadHeaderTimeout},
+		{"read timeout", l.ReadTimeout, &server.ReadTimeout},
+		{"write timeout", l.WriteTimeout, &server.WriteTimeout},
+		{"idle timeout", l.IdleTimeout, &server.IdleTimeout},
+	} {
+		if d.value == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(d.value)
+		if err != nil || duration <= 0 {
+			return nil, fmt.Errorf("invalid config value %q for web %s", d.value, d.name)
+		}
+		*d.dst = duration
+	}
+	maxBodyBytes := l.MaxBodyBytes
+	if maxBodyBytes == 0 {
+		maxBodyBytes = 1 << 20
+	}
+	server.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > maxBodyBytes {
+			http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+		handler.ServeHTTP(w, r)
+	})
+	return server, nil
+}
+
+// certificates returns the certificates of the HTTPS listener, the default
+// one first.
+func (w Web) certificates() []TLSCertificate {
+	return append([]TLSCertificate{{Cert: w.TLSCert, Key: w.TLSKey}}, w.TLSCertificates...)
+}
+
+// WebListener is an additional listener of the web server. All listeners
+// serve the same issuer.
+type WebListener struct {
+	// Name of the listener in logs and the TLS certificate metric.
+	Name string `json:"name"`
+	// Addr is a TCP address, Unix socket or systemd socket, like HTTP.
+	Addr string `json:"addr"`
+	// Certificates of HTTPS listeners, the first served unless clients ask
+	// for the name of another one. Listeners without certificates serve HTTP.
+	TLSCertificates     []TLSCertificate `json:"tlsCertificates"`
+	TLSMinVersion       string           `json:"tlsMinVersion"`
+	TLSMaxVersion       string           `json:"tlsMaxVersion"`
+	TLSCipherSuites     []string         `json:"tlsCipherSuites"`
+	TLSCurvePreferences []string         `json:"tlsCurvePreferences"`
+	TLSClientCA         string           `json:"tlsClientCA"`
+}
+
+// TLSCertificate is a certificate and key of a listener.
+type TLSCertificate struct {
+	Cert string `json:"cert"`
+	Key  string `json:"key"`
+}
+
+// ClientRemoteIP is the config of the reverse proxies in front of dex.
 type ClientRemoteIP struct {
+	// Header listing the client IP, like X-Forwarded-For or Forwarded.
 	Header         string   `json:"header"`
 	TrustedProxies []string `json:"trustedProxies"`
+	// ForwardedHeaders applies the host, scheme and path prefix forwarded by
+	// the trusted proxies to requests.
+	ForwardedHeaders bool `json:"forwardedHeaders"`
 }
 
 func (cr *ClientRemoteIP) ParseTrustedProxies() ([]netip.Prefix, error) {
@@ -187,49 +701,67 @@ func (cr *ClientRemoteIP) ParseTrustedProxies() ([]netip.Prefix, error) {
 	return trusted, nil
 }
 
+// Headers are the security headers set on the responses of the issuer
+// endpoints. Headers left blank are set to the defaults of defaultHeaders,
+// unless DisableDefaults is set.
 type Headers struct {
 	// Set the Content-Security-Policy header to HTTP responses.
-	// Unset if blank.
 	// https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Content-Security-Policy
 	ContentSecurityPolicy string `json:"Content-Security-Policy"`
 	// Set the X-Frame-Options header to HTTP responses.
-	// Unset if blank. Accepted values are deny and sameorigin.
+	// Accepted values are deny and sameorigin.
 	// https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/X-Frame-Options
 	XFrameOptions string `json:"X-Frame-Options"`
 	// Set the X-Content-Type-Options header to HTTP responses.
-	// Unset if blank. Accepted value is nosniff.
+	// Accepted value is nosniff.
 	// https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/X-Content-Type-Options
 	XContentTypeOptions string `json:"X-Content-Type-Options"`
 	// Set the X-XSS-Protection header to all responses.
 	// Unset if blank.
 	// https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/X-XSS-Protection
 	XXSSProtection string `json:"X-XSS-Protection"`
-	// Set the Strict-Transport-Security header to HTTP responses.
-	// Unset if blank.
+	// Set the Strict-Transport-Security header to HTTP responses. Browsers
+	// ignore it on plain HTTP.
 	// https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Strict-Transport-Security
 	StrictTransportSecurity string `json:"Strict-Transport-Security"`
+	// Set the Referrer-Policy header to HTTP responses.
+	// https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Referrer-Policy
+	ReferrerPolicy string `json:"Referrer-Policy"`
+
+	// DisableDefaults only sets the headers which aren't blank.
+	DisableDefaults bool `json:"disableDefaults"`
+}
+
+// defaultHeaders are set unless configured otherwise. The Content-Security-Policy
+// only forbids framing the pages and injecting base URLs and plugins, so that
+// custom themes loading fonts, images or scripts from elsewhere keep working.
+var defaultHeaders = Headers{
+	ContentSecurityPolicy:   "frame-ancestors 'none'; base-uri 'self'; object-src 'none'",
+	XFrameOptions:           "DENY",
+	XContentTypeOptions:     "nosniff",
+	StrictTransportSecurity: "max-age=31536000",
+	ReferrerPolicy:          "same-origin",
 }
 
 func (h *Headers) ToHTTPHeader() http.Header {
 	if h == nil {
-		return make(map[string][]string)
+		h = &Headers{}
 	}
 	header := make(map[string][]string)
-	if h.ContentSecurityPolicy != "" {
-		header["Content-Security-Policy"] = []string{h.ContentSecurityPolicy}
-	}
-	if h.XFrameOptions != "" {
-		header["X-Frame-Options"] = []string{h.XFrameOptions}
-	}
-	if h.XContentTypeOptions != "" {
-		header["X-Content-Type-Options"] = []string{h.XContentTypeOptions}
-	}
-	if h.XXSSProtection != "" {
-		header["X-XSS-Protection"] = []string{h.XXSSProtection}
-	}
-	if h.StrictTransportSecurity != "" {
-		header["Strict-Transport-Security"] = []string{h.StrictTransportSecurity}
+	set := func(name, value, defaultValue string) {
+		if value == "" && !h.DisableDefaults {
+			value = defaultValue
+		}
+		if value != "" {
+			header[name] = []string{value}
+		}
 	}
+	set("Content-Security-Policy", h.ContentSecurityPolicy, defaultHeaders.ContentSecurityPolicy)
+	set("X-Frame-Options", h.XFrameOptions, defaultHeaders.XFrameOptions)
+	set("X-Content-Type-Options", h.XContentTypeOptions, defaultHeaders.XContentTypeOptions)
+	set("X-XSS-Protection", h.XXSSProtection, defaultHeaders.XXSSProtection)
+	set("Strict-Transport-Security", h.StrictTransportSecurity, defaultHeaders.StrictTransportSecurity)
+	set("Referrer-Policy", h.ReferrerPolicy, defaultHeaders.ReferrerPolicy)
 	return header
 }
 
@@ -238,6 +770,40 @@ type Telemetry struct {
 	HTTP string `json:"http"`
 	// EnableProfiling makes profiling endpoints available via web interface host:port/debug/pprof/
 	EnableProfiling bool `json:"enableProfiling"`
+	// Tracing exports OpenTelemetry traces of the requests, if set.
+	Tracing *Tracing `json:"tracing"`
+
+	// Bearer token required for the detailed health report at
+	// /healthz?verbose of the web server. If empty, the report isn't served.
+	HealthReportToken        string `json:"healthReportToken"`
+	HealthReportTokenFromEnv string `json:"healthReportTokenFromEnv"`
+}
+
+// healthReportToken returns the token of the detailed health report, reading
+// it from the environment if configured.
+func (t Telemetry) healthReportToken() string {
+	if t.HealthReportToken == "" && t.HealthReportTokenFromEnv != "" {
+		return os.Getenv(t.HealthReportTokenFromEnv)
+	}
+	return t.HealthReportToken
+}
+
+// Tracing is the config for exporting OpenTelemetry traces to an OTLP
+// collector.
+type Tracing struct {
+	// Endpoint of the collector, e.g. "otel-collector:4317".
+	Endpoint string `json:"endpoint"`
+	// Protocol is "grpc" or "http". Defaults to "grpc".
+	Protocol string `json:"protocol"`
+	// Insecure disables TLS for connections to the collector.
+	Insecure bool `json:"insecure"`
+	// Headers sent with every export, e.g. for authentication.
+	Headers map[string]string `json:"headers"`
+	// SampleRatio is the share of traces started by dex which are recorded.
+	// Traces started by callers follow their sampling decision. Defaults to 1.
+	SampleRatio *float64 `json:"sampleRatio"`
+	// ServiceName of the spans. Defaults to "dex".
+	ServiceName string `json:"serviceName"`
 }
 
 // GRPC is the config for the gRPC API.
@@ -249,13 +815,131 @@ type GRPC struct {
 	TLSClientCA   string `json:"tlsClientCA"`
 	TLSMinVersion string `json:"tlsMinVersion"`
 	TLSMaxVersion string `json:"tlsMaxVersion"`
-	Reflection    bool   `json:"reflection"`
+	// Certificates served instead of TLSCert to clients asking for their
+	// names (SNI).
+	TLSCertificates []TLSCertificate `json:"tlsCertificates"`
+	Reflection      bool             `json:"reflection"`
+	// Authentication of API callers. Without tokens or clients, the API is
+	// only protected by the network and TLS client certificates.
+	Auth GRPCAuth `json:"auth"`
+	// Rate limit of each caller. Calls aren't limited if unset.
+	RateLimit *GRPCRateLimit `json:"rateLimit"`
+}
+
+// certificates returns the certificates of the gRPC listener, the default
+// one first.
+func (g GRPC) certificates() []TLSCertificate {
+	return append([]TLSCertificate{{Cert: g.TLSCert, Key: g.TLSKey}}, g.TLSCertificates...)
+}
+
+// GRPCRateLimit is the config format for limiting the rate of API calls.
+type GRPCRateLimit struct {
+	RequestsPerSecond float64 `json:"requestsPerSecond"`
+	Burst             int     `json:"burst"`
+}
+
+// GRPCAuth is the config format for authenticating callers of the gRPC API
+// and the roles they're granted.
+type GRPCAuth struct {
+	// Static bearer tokens.
+	Tokens []GRPCAuthToken `json:"tokens"`
+}
+
+// GRPCAuthToken grants roles to a static bearer token.
+type GRPCAuthToken struct {
+	Token        string   `json:"token"`
+	TokenFromEnv string   `json:"tokenFromEnv"`
+	Roles        []string `json:"roles"`
+}
+
+// Enabled reports whether callers of the API must authenticate.
+func (a GRPCAuth) Enabled() bool {
+	return len(a.Tokens) > 0
+}
+
+// ToServerConfig converts the config into the format of the server package,
+// reading tokens from the environment.
+func (a GRPCAuth) ToServerConfig() server.APIAuthConfig {
+	var config server.APIAuthConfig
+	for _, t := range a.Tokens {
+		token := t.Token
+		if token == "" && t.TokenFromEnv != "" {
+			token = os.Getenv(t.TokenFromEnv)
+		}
+		config.Tokens = append(config.Tokens, server.APIToken{Token: token, Roles: toAPIRoles(t.Roles)})
+	}
+	return config
+}
+
+func toAPIRoles(roles []string) []server.APIRole {
+	apiRoles := make([]server.APIRole, 0, len(roles))
+	for _, role := range roles {
+		apiRoles = append(apiRoles, server.APIRole(role))
+	}
+	return apiRoles
 }
 
 // Storage holds app's storage configuration.
 type Storage struct {
 	Type   string        `json:"type"`
 	Config StorageConfig `json:"config"`
+
+	// Cache keeps the signing keys, clients and connectors in memory to save
+	// storage round trips on every request.
+	Cache *StorageCache `json:"cache"`
+
+	// SlowOperations logs storage operations taking longer than expected.
+	SlowOperations *StorageSlowOperations `json:"slowOperations"`
+}
+
+// StorageCache holds the configuration of the storage cache.
+type StorageCache struct {
+	// TTL is how long objects are cached. Changes made by other dex instances
+	// sharing the storage may take this long to be seen.
+	TTL string `json:"ttl"`
+}
+
+// StorageSlowOperations holds the thresholds above which storage operations
+// are logged.
+type StorageSlowOperations struct {
+	// Threshold applies to all operations not listed in Operations.
+	Threshold string `json:"threshold"`
+	// Operations overrides the threshold of operations by method name, e.g.
+	// "GarbageCollect".
+	Operations map[string]string `json:"operations"`
+}
+
+// storageOperations lists the methods of the storage interface, which name
+// the operations in metrics and logs.
+var storageOperations = reflect.TypeOf((*storage.Storage)(nil)).Elem()
+
+// ToStorageConfig parses the thresholds. A nil config disables logging.
+func (s *StorageSlowOperations) ToStorageConfig() (storage.SlowOperations, error) {
+	var slow storage.SlowOperations
+	if s == nil {
+		return slow, nil
+	}
+	if s.Threshold != "" {
+		threshold, err := time.ParseDuration(s.Threshold)
+		if err != nil {
+			return slow, fmt.Errorf("invalid slow operations threshold %q: %v", s.Threshold, err)
+		}
+		slow.Threshold = threshold
+	}
+	if len(s.Operations) > 0 {
+		slow.Operations = make(map[string]time.Duration, len(s.Operations))
+		for operation, value := range s.Operations {
+			if _, ok := storageOperations.MethodByName(operation); !ok {
+				return slow, fmt.Errorf("unknown storage operation %q", operation)
+			}
+			threshold, err := time.ParseDuration(value)
+			if err != nil {
+				return slow, fmt.Errorf("invalid slow operations threshold %q for %s: %v", value, operation, err)
+			}
+			slow.Operations[operation] = threshold
+		}
+	}
+	return slow, nil
 }
 
 // StorageConfig is a configuration that can create a storage.
@@ -264,11 +948,14 @@ type StorageConfig interface {
 }
 
 var (
+	_ StorageConfig = (*bolt.Config)(nil)
 	_ StorageConfig = (*etcd.Etcd)(nil)
+	_ StorageConfig = (*external.Config)(nil)
 	_ StorageConfig = (*kubernetes.Config)(nil)
 	_ StorageConfig = (*memory.Config)(nil)
 	_ StorageConfig = (*sql.SQLite3)(nil)
 	_ StorageConfig = (*sql.Postgres)(nil)
+	_ StorageConfig = (*sql.CockroachDB)(nil)
 	_ StorageConfig = (*sql.MySQL)(nil)
 	_ StorageConfig = (*ent.SQLite3)(nil)
 	_ StorageConfig = (*ent.Postgres)(nil)
@@ -306,12 +993,15 @@ func expandEnvInMap(m map[string]interface{}) {
 }
 
 var storages = map[string]func() StorageConfig{
+	"bolt":       func() StorageConfig { return new(bolt.Config) },
 	"etcd":       func() StorageConfig { return new(etcd.Etcd) },
+	"external":   func() StorageConfig { return new(external.Config) },
 	"kubernetes": func() StorageConfig { return new(kubernetes.Config) },
 	"memory":     func() StorageConfig { return new(memory.Config) },
 	"sqlite3":    getORMBasedSQLStorage(&sql.SQLite3{}, &ent.SQLite3{}),
 	"postgres":   getORMBasedSQLStorage(&sql.Postgres{}, &ent.Postgres{}),
 	"mysql":      getORMBasedSQLStorage(&sql.MySQL{}, &ent.MySQL{}),
+	"cockroach":  func() StorageConfig { return new(sql.CockroachDB) },
 }
 
 // UnmarshalJSON allows Storage to implement the unmarshaler interface to
@@ -320,6 +1010,9 @@ func (s *Storage) UnmarshalJSON(b []byte) error {
 	var store struct {
 		Type   string          `json:"type"`
 		Config json.RawMessage `json:"config"`
+		Cache  *StorageCache   `json:"cache"`
+
+		SlowOperations *StorageSlowOperations `json:"slowOperations"`
 	}
 	if err := json.Unmarshal(b, &store); err != nil {
 		return fmt.Errorf("parse storage: %v", err)
@@ -351,6 +1044,11 @@ func (s *Storage) UnmarshalJSON(b []byte) error {
 			data = expandedData
 		}
 
+		data, err := resolvePluginSecrets(data, "storage.config")
+		if err != nil {
+			return err
+		}
+
 		if err := json.Unmarshal(data, storageConfig); err != nil {
 			return fmt.Errorf("parse storage config: %v", err)
 		}
@@ -358,6 +1056,72 @@ func (s *Storage) UnmarshalJSON(b []byte) error {
 	*s = Storage{
 		Type:   store.Type,
 		Config: storageConfig,
+		Cache:  store.Cache,
+
+		SlowOperations: store.SlowOperations,
+	}
+	return nil
+}
+
+// Signer holds the configuration of an external signer. If not specified, dex
+// generates its own signing keys and keeps them in storage.
+type Signer struct {
+	Type   string        `json:"type"`
+	Config signer.Config `json:"config"`
+}
+
+var signers = map[string]func() signer.Config{
+	"pkcs11":        func() signer.Config { return new(signer.PKCS11Config) },
+	"awskms":        func() signer.Config { return new(signer.AWSKMSConfig) },
+	"gcpkms":        func() signer.Config { return new(signer.GCPKMSConfig) },
+	"azurekeyvault": func() signer.Config { return new(signer.AzureKeyVaultConfig) },
+	"vault":         func() signer.Config { return new(signer.VaultTransitConfig) },
+}
+
+// UnmarshalJSON allows Signer to implement the unmarshaler interface to
+// dynamically determine the type of the signer config.
+func (s *Signer) UnmarshalJSON(b []byte) error {
+	var sign struct {
+		Type   string          `json:"type"`
+		Config json.RawMessage `json:"config"`
+	}
+	if err := json.Unmarshal(b, &sign); err != nil {
+		return fmt.Errorf("parse signer: %v", err)
+	}
+	f, ok := signers[sign.Type]
+	if !ok {
+		return fmt.Errorf("unknown signer type %q", sign.Type)
+	}
+
+	signerConfig := f()
+	if len(sign.Config) != 0 {
+		data := []byte(sign.Config)
+		if featureflags.ExpandEnv.Enabled() {
+			var rawMap map[string]interface{}
+			if err := json.Unmarshal(sign.Config, &rawMap); err != nil {
+				return fmt.Errorf("unmarshal config for env expansion: %v", err)
+			}
+
+			// Recursively expand environment variables in the map to avoid
+			// issues with JSON special characters and escapes
+			expandEnvInMap(rawMap)
+
+			// Marshal the expanded map back to JSON
+			expandedData, err := json.Marshal(rawMap)
+			if err != nil {
+				return fmt.Errorf("marshal expanded config: %v", err)
+			}
+
+			data = expandedData
+		}
+
+		if err := json.Unmarshal(data, signerConfig); err != nil {
+			return fmt.Errorf("parse signer config: %v", err)
+		}
+	}
+	*s = Signer{
+		Type:   sign.Type,
+		Config: signerConfig,
 	}
 	return nil
 }
@@ -369,6 +1133,20 @@ type Connector struct {
 	Name string `json:"name"`
 	ID   string `json:"id"`
 
+	// Order of the connector on the login page, lower first.
+	Order int `json:"order"`
+	// Header of the section of the login page the connector is shown in.
+	Group string `json:"group"`
+	// Hidden connectors are only used if requested with connector_id.
+	Hidden bool `json:"hidden"`
+	// Icon and background color of the button on the login page.
+	IconURL     string `json:"iconURL"`
+	ButtonColor string `json:"buttonColor"`
+
+	// Middleware transforming the identities returned by the connector, in
+	// order, or denying logins.
+	Middleware []middleware.Config `json:"middleware"`
+
 	Config server.ConnectorConfig `json:"config"`
 }
 
@@ -380,11 +1158,22 @@ func (c *Connector) UnmarshalJSON(b []byte) error {
 		Name string `json:"name"`
 		ID   string `json:"id"`
 
+		Order       int    `json:"order"`
+		Group       string `json:"group"`
+		Hidden      bool   `json:"hidden"`
+		IconURL     string `json:"iconURL"`
+		ButtonColor string `json:"buttonColor"`
+
+		Middleware []middleware.Config `json:"middleware"`
+
 		Config json.RawMessage `json:"config"`
 	}
 	if err := json.Unmarshal(b, &conn); err != nil {
 		return fmt.Errorf("parse connector: %v", err)
 	}
+	if _, err := middleware.New(conn.Middleware); err != nil {
+		return fmt.Errorf("connector %q: %v", conn.ID, err)
+	}
 	f, ok := server.ConnectorsConfig[conn.Type]
 	if !ok {
 		return fmt.Errorf("unknown connector type %q", conn.Type)
@@ -412,16 +1201,27 @@ func (c *Connector) UnmarshalJSON(b []byte) error {
 			data = expandedData
 		}
 
+		data, err := resolvePluginSecrets(data, fmt.Sprintf("connectors[%s].config", conn.ID))
+		if err != nil {
+			return err
+		}
+
 		if err := json.Unmarshal(data, connConfig); err != nil {
 			return fmt.Errorf("parse connector config: %v", err)
 		}
 	}
 
 	*c = Connector{
-		Type:   conn.Type,
-		Name:   conn.Name,
-		ID:     conn.ID,
-		Config: connConfig,
+		Type:        conn.Type,
+		Name:        conn.Name,
+		ID:          conn.ID,
+		Order:       conn.Order,
+		Group:       conn.Group,
+		Hidden:      conn.Hidden,
+		IconURL:     conn.IconURL,
+		ButtonColor: conn.ButtonColor,
+		Middleware:  conn.Middleware,
+		Config:      connConfig,
 	}
 	return nil
 }
@@ -432,13 +1232,40 @@ func ToStorageConnector(c Connector) (storage.Connector, error) {
 	if err != nil {
 		return storage.Connector{}, fmt.Errorf("failed to marshal connector config: %v", err)
 	}
+	if len(c.Middleware) > 0 {
+		// The middleware is stored in the connector config, where the server
+		// reads it when opening the connector.
+		var config map[string]json.RawMessage
+		if err := json.Unmarshal(data, &config); err != nil {
+			return storage.Connector{}, fmt.Errorf("failed to marshal connector config: %v", err)
+		}
+		if config == nil {
+			config = make(map[string]json.RawMessage)
+		}
+		if config["middleware"], err = json.Marshal(c.Middleware); err != nil {
+			return storage.Connector{}, fmt.Errorf("failed to marshal connector middleware: %v", err)
+		}
+		if data, err = json.Marshal(config); err != nil {
+			return storage.Connector{}, fmt.Errorf("failed to marshal connector config: %v", err)
+		}
+	}
 
-	return storage.Connector{
+	sc := storage.Connector{
 		ID:     c.ID,
 		Type:   c.Type,
 		Name:   c.Name,
 		Config: data,
-	}, nil
+	}
+	if c.Order != 0 || c.Group != "" || c.Hidden || c.IconURL != "" || c.ButtonColor != "" {
+		sc.Display = &storage.ConnectorDisplay{
+			Order:       c.Order,
+			Group:       c.Group,
+			Hidden:      c.Hidden,
+			IconURL:     c.IconURL,
+			ButtonColor: c.ButtonColor,
+		}
+	}
+	return sc, nil
 }
 
 // Expiry holds configuration for the validity period of components.
@@ -446,6 +1273,10 @@ type Expiry struct {
 	// SigningKeys defines the duration of time after which the SigningKeys will be rotated.
 	SigningKeys string `json:"signingKeys"`
 
+	// VerificationKeys defines the duration of time for which a rotated signing key
+	// is still published to verify signatures. Defaults to the IdTokens expiry.
+	VerificationKeys string `json:"verificationKeys"`
+
 	// IdTokens defines the duration of time for which the IdTokens will be valid.
 	IDTokens string `json:"idTokens"`
 
@@ -459,6 +1290,412 @@ type Expiry struct {
 	RefreshTokens RefreshToken `json:"refreshTokens"`
 }
 
+// GC holds configuration for the garbage collection of expired objects.
+type GC struct {
+	// Interval between garbage collection runs. Defaults to 5m.
+	Interval string `json:"interval"`
+
+	// Intervals overrides Interval for some types of objects, which are then
+	// collected on their own schedule. Keys are "authRequests", "authCodes",
+	// "deviceRequests" and "deviceTokens".
+	Intervals map[string]string `json:"intervals"`
+
+	// Jitter is the maximum random delay added to each interval, so replicas
+	// sharing a storage don't collect garbage at the same time.
+	Jitter string `json:"jitter"`
+
+	// BatchSize limits the number of objects deleted at once, so large tables
+	// don't lock up while expired objects are deleted.
+	BatchSize int `json:"batchSize"`
+}
+
+// PasswordReset is the config format for resetting passwords of the password
+// database.
+type PasswordReset struct {
+	// ValidFor is how long reset links are valid. Defaults to 1h.
+	ValidFor string `json:"validFor"`
+
+	// Number of reset e-mails sent to an address, and of resets requested from
+	// an IP address, per hour.
+	EmailsPerHour   int `json:"emailsPerHour"`
+	RequestsPerHour int `json:"requestsPerHour"`
+}
+
+// PasswordLockout is the config format for locking out logins of the password
+// database after failures.
+type PasswordLockout struct {
+	// Consecutive failed logins at which a user is locked out, and failed
+	// logins from an IP address at which it's locked out. Default to 5 and 20.
+	// IP addresses are counted by every instance on its own.
+	Threshold              int `json:"threshold"`
+	PerInstanceIPThreshold int `json:"perInstanceIPThreshold"`
+
+	// How long the first lockout lasts, doubling with every further failure
+	// up to MaxDuration. Default to 1m and 1h.
+	Duration    string `json:"duration"`
+	MaxDuration string `json:"maxDuration"`
+}
+
+// PasswordPolicy is the config format for the policy of new passwords of the
+// password database.
+type PasswordPolicy struct {
+	// Minimum number of characters. Defaults to 8.
+	MinLength int `json:"minLength"`
+
+	// Number of character classes, of lowercase and uppercase letters, digits
+	// and symbols, the password must contain.
+	MinCharacterClasses int `json:"minCharacterClasses"`
+
+	// If set, the password must not contain the username or e-mail address.
+	DisallowUserInfo bool `json:"disallowUserInfo"`
+
+	// If set, passwords found in data breaches are rejected, checked with the
+	// k-anonymity range API at BreachedPasswordsURL, by default the one of
+	// Have I Been Pwned. BreachedPasswordsTimeout defaults to 5s.
+	CheckBreached            bool   `json:"checkBreached"`
+	BreachedPasswordsURL     string `json:"breachedPasswordsURL"`
+	BreachedPasswordsTimeout string `json:"breachedPasswordsTimeout"`
+}
+
+// Registration is the config format for registering users in the password
+// database.
+type Registration struct {
+	// E-mail domains which can register. If empty, any address can register.
+	AllowedDomains []string `json:"allowedDomains"`
+
+	// ValidFor is how long verification links are valid. Defaults to 1h.
+	ValidFor string `json:"validFor"`
+
+	// Number of verification e-mails sent to an address, and of registrations
+	// requested from an IP address, per hour.
+	EmailsPerHour   int `json:"emailsPerHour"`
+	RequestsPerHour int `json:"requestsPerHour"`
+}
+
+// EmailVerification is the config format for verifying the e-mail addresses
+// of the password database.
+type EmailVerification struct {
+	// ValidFor is how long verification links are valid. Defaults to 24h.
+	ValidFor string `json:"validFor"`
+
+	// Number of verification e-mails sent to an address per hour.
+	EmailsPerHour int `json:"emailsPerHour"`
+}
+
+// Captcha is the config format for challenging users on the password and
+// registration forms.
+type Captcha struct {
+	// Provider is "hcaptcha", "turnstile" or "proof-of-work".
+	Provider string `json:"provider"`
+
+	// Keys of the hCaptcha or Turnstile site.
+	SiteKey       string `json:"siteKey"`
+	Secret        string `json:"secret"`
+	SecretFromEnv string `json:"secretFromEnv"`
+
+	// Number of failed attempts per IP address or username within an hour
+	// before a challenge has to be solved.
+	Threshold int `json:"threshold"`
+
+	// Number of leading zero bits of the hashes solving proof-of-work
+	// challenges.
+	Difficulty int `json:"difficulty"`
+}
+
+// ToServerConfig converts the CAPTCHA settings, reading the secret from the
+// environment.
+func (c Captcha) ToServerConfig() *server.CaptchaConfig {
+	secret := c.Secret
+	if secret == "" && c.SecretFromEnv != "" {
+		secret = os.Getenv(c.SecretFromEnv)
+	}
+	return &server.CaptchaConfig{
+		Provider:   c.Provider,
+		SiteKey:    c.SiteKey,
+		Secret:     secret,
+		Threshold:  c.Threshold,
+		Difficulty: c.Difficulty,
+	}
+}
+
+// HomeRealmDiscovery is the config format for choosing the connector by the
+// e-mail domain of the user.
+type HomeRealmDiscovery struct {
+	// Maps e-mail domains to connector IDs.
+	Domains map[string]string `json:"domains"`
+}
+
+// ToServerConfig converts the home realm discovery settings.
+func (h HomeRealmDiscovery) ToServerConfig() *server.HomeRealmDiscoveryConfig {
+	return &server.HomeRealmDiscoveryConfig{Domains: h.Domains}
+}
+
+// SMTP is the config format for sending e-mails.
+type SMTP struct {
+	Host            string `json:"host"`
+	Port            int    `json:"port"`
+	Username        string `json:"username"`
+	Password        string `json:"password"`
+	PasswordFromEnv string `json:"passwordFromEnv"`
+	From            string `json:"from"`
+}
+
+// ToServerConfig parses the password reset settings.
+func (p PasswordReset) ToServerConfig(mailer server.Mailer) (*server.PasswordResetConfig, error) {
+	c := &server.PasswordResetConfig{
+		Mailer:          mailer,
+		EmailsPerHour:   p.EmailsPerHour,
+		RequestsPerHour: p.RequestsPerHour,
+	}
+	if p.ValidFor != "" {
+		validFor, err := time.ParseDuration(p.ValidFor)
+		if err != nil || validFor <= 0 {
+			return nil, fmt.Errorf("invalid config value %q for password reset link expiry", p.ValidFor)
+		}
+		c.ValidFor = validFor
+	}
+	return c, nil
+}
+
+// ToServerConfig parses the password lockout settings.
+func (p PasswordLockout) ToServerConfig() (*server.PasswordLockoutConfig, error) {
+	c := &server.PasswordLockoutConfig{
+		Threshold:              p.Threshold,
+		PerInstanceIPThreshold: p.PerInstanceIPThreshold,
+	}
+	for _, d := range []struct {
+		value string
+		name  string
+		dst   *time.Duration
+	}{
+		{p.Duration, "duration", &c.Duration},
+		{p.MaxDuration, "max duration", &c.MaxDuration},
+	} {
+		if d.value == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(d.value)
+		if err != nil || duration <= 0 {
+			return nil, fmt.Errorf("invalid config value %q for password lockout %s", d.value, d.name)
+		}
+		*d.dst = duration
+	}
+	return c, nil
+}
+
+// ToServerConfig parses the password policy settings.
+func (p PasswordPolicy) ToServerConfig() (*server.PasswordPolicyConfig, error) {
+	c := &server.PasswordPolicyConfig{
+		MinLength:            p.MinLength,
+		MinCharacterClasses:  p.MinCharacterClasses,
+		DisallowUserInfo:     p.DisallowUserInfo,
+		CheckBreached:        p.CheckBreached,
+		BreachedPasswordsURL: p.BreachedPasswordsURL,
+	}
+	if p.BreachedPasswordsTimeout != "" {
+		timeout, err := time.ParseDuration(p.BreachedPasswordsTimeout)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid config value %q for password policy breached passwords timeout", p.BreachedPasswordsTimeout)
+		}
+		c.BreachedPasswordsTimeout = timeout
+	}
+	return c, nil
+}
+
+// ToServerConfig parses the registration settings.
+func (r Registration) ToServerConfig(mailer server.Mailer) (*server.RegistrationConfig, error) {
+	c := &server.RegistrationConfig{
+		Mailer:          mailer,
+		AllowedDomains:  r.AllowedDomains,
+		EmailsPerHour:   r.EmailsPerHour,
+		RequestsPerHour: r.RequestsPerHour,
+	}
+	if r.ValidFor != "" {
+		validFor, err := time.ParseDuration(r.ValidFor)
+		if err != nil || validFor <= 0 {
+			return nil, fmt.Errorf("invalid config value %q for registration link expiry", r.ValidFor)
+		}
+		c.ValidFor = validFor
+	}
+	return c, nil
+}
+
+// ToServerConfig parses the e-mail verification settings.
+func (e EmailVerification) ToServerConfig(mailer server.Mailer) (*server.EmailVerificationConfig, error) {
+	c := &server.EmailVerificationConfig{
+		Mailer:        mailer,
+		EmailsPerHour: e.EmailsPerHour,
+	}
+	if e.ValidFor != "" {
+		validFor, err := time.ParseDuration(e.ValidFor)
+		if err != nil || validFor <= 0 {
+			return nil, fmt.Errorf("invalid config value %q for e-mail verification link expiry", e.ValidFor)
+		}
+		c.ValidFor = validFor
+	}
+	return c, nil
+}
+
+// NewMailer returns a mailer sending e-mails through the SMTP server, reading
+// the password from the environment.
+func (s SMTP) NewMailer() (server.Mailer, error) {
+	password := s.Password
+	if password == "" && s.PasswordFromEnv != "" {
+		password = os.Getenv(s.PasswordFromEnv)
+	}
+	mailer, err := server.NewSMTPMailer(server.SMTPConfig{
+		Host:     s.Host,
+		Port:     s.Port,
+		Username: s.Username,
+		Password: password,
+		From:     s.From,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid SMTP config: %v", err)
+	}
+	return mailer, nil
+}
+
+// SecurityEvents is the config format for the detection of security events.
+type SecurityEvents struct {
+	// Number of failed logins for a username or from an IP address within an
+	// hour at which a repeated_login_failures event is emitted.
+	FailedLoginThreshold int `json:"failedLoginThreshold"`
+}
+
+// RateLimit is the config format for limiting the rate of requests to the
+// HTTP endpoints. Limits which aren't set don't apply.
+type RateLimit struct {
+	PerIP         *RequestRate `json:"perIP"`
+	PerClient     *RequestRate `json:"perClient"`
+	PerDeviceCode *RequestRate `json:"perDeviceCode"`
+}
+
+// RequestRate is the config format of a sustained rate of requests with
+// bursts.
+type RequestRate struct {
+	RequestsPerSecond float64 `json:"requestsPerSecond"`
+	Burst             int     `json:"burst"`
+}
+
+func (r *RequestRate) toServerConfig() *server.RateLimit {
+	if r == nil {
+		return nil
+	}
+	return &server.RateLimit{RequestsPerSecond: r.RequestsPerSecond, Burst: r.Burst}
+}
+
+// ToServerConfig converts the rate limits into the server's format.
+func (r RateLimit) ToServerConfig() *server.RateLimitConfig {
+	return &server.RateLimitConfig{
+		PerIP:         r.PerIP.toServerConfig(),
+		PerClient:     r.PerClient.toServerConfig(),
+		PerDeviceCode: r.PerDeviceCode.toServerConfig(),
+	}
+}
+
+// AuditLog is the config format for the audit log.
+type AuditLog struct {
+	// Number of records buffered for each sink.
+	QueueSize int `json:"queueSize"`
+
+	Sinks []AuditSink `json:"sinks"`
+}
+
+// AuditSink is the config format of an audit log sink. Type is "file",
+// "syslog", "http", "kafka" or "nats", and the other fields apply to the sinks
+// of some types only.
+type AuditSink struct {
+	Type string `json:"type"`
+
+	// Path of the file records are appended to.
+	Path string `json:"path"`
+
+	// Network, address and message tag of the syslog server.
+	Network string `json:"network"`
+	Address string `json:"address"`
+	Tag     string `json:"tag"`
+
+	// URL records are posted to, or of the Kafka REST Proxy or the NATS
+	// server, and the headers of the requests.
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+
+	// Kafka topic records are published to.
+	Topic string `json:"topic"`
+
+	// NATS subject records are published to, with the event type appended,
+	// and the token to authenticate with.
+	Subject string `json:"subject"`
+	Token   string `json:"token"`
+}
+
+// ToServerConfig creates the sinks of the audit log.
+func (a AuditLog) ToServerConfig() (*server.AuditLogConfig, error) {
+	c := &server.AuditLogConfig{QueueSize: a.QueueSize}
+	for i, s := range a.Sinks {
+		sink, err := s.newSink()
+		if err != nil {
+			return nil, fmt.Errorf("invalid audit log sink %d: %v", i, err)
+		}
+		c.Sinks = append(c.Sinks, sink)
+	}
+	return c, nil
+}
+
+func (s AuditSink) newSink() (server.AuditSink, error) {
+	switch s.Type {
+	case "file":
+		return server.NewFileAuditSink(server.FileAuditSinkConfig{Path: s.Path})
+	case "syslog":
+		return server.NewSyslogAuditSink(server.SyslogAuditSinkConfig{Network: s.Network, Address: s.Address, Tag: s.Tag})
+	case "http":
+		return server.NewHTTPAuditSink(server.HTTPAuditSinkConfig{URL: s.URL, Headers: s.Headers})
+	case "kafka":
+		return server.NewKafkaAuditSink(server.KafkaAuditSinkConfig{URL: s.URL, Topic: s.Topic, Headers: s.Headers})
+	case "nats":
+		return server.NewNATSAuditSink(server.NATSAuditSinkConfig{URL: s.URL, Subject: s.Subject, Token: s.Token})
+	default:
+		return nil, fmt.Errorf("unknown type %q", s.Type)
+	}
+}
+
+// ApplyTo parses the garbage collection settings into a server config.
+func (g GC) ApplyTo(c *server.Config) error {
+	if g.Interval != "" {
+		interval, err := time.ParseDuration(g.Interval)
+		if err != nil || interval <= 0 {
+			return fmt.Errorf("invalid config value %q for gc interval", g.Interval)
+		}
+		c.GCFrequency = interval
+	}
+	for resource, value := range g.Intervals {
+		if !slices.Contains(storage.GCResources, storage.GCResource(resource)) {
+			return fmt.Errorf("unknown gc resource %q", resource)
+		}
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			return fmt.Errorf("invalid config value %q for gc interval of %s", value, resource)
+		}
+		if c.GCIntervals == nil {
+			c.GCIntervals = make(map[storage.GCResource]time.Duration)
+		}
+		c.GCIntervals[storage.GCResource(resource)] = interval
+	}
+	if g.Jitter != "" {
+		jitter, err := time.ParseDuration(g.Jitter)
+		if err != nil || jitter < 0 {
+			return fmt.Errorf("invalid config value %q for gc jitter", g.Jitter)
+		}
+		c.GCJitter = jitter
+	}
+	if g.BatchSize < 0 {
+		return fmt.Errorf("invalid config value %d for gc batch size", g.BatchSize)
+	}
+	c.GCBatchSize = g.BatchSize
+	return nil
+}
+
 // Logger holds configuration required to customize logging for dex.
 type Logger struct {
 	// Level sets logging level severity.
@@ -466,6 +1703,26 @@ type Logger struct {
 
 	// Format specifies the format to be used for logging.
 	Format string `json:"format"`
+
+	// Redact hashes or removes personal data from the logs. The audit log
+	// isn't affected.
+	Redact *LogRedaction `json:"redact"`
+}
+
+// LogRedaction is the config format for removing personal data from logs.
+type LogRedaction struct {
+	// Mode is "hash", replacing values by a keyed hash so that the lines of
+	// a user can still be correlated, or "remove". Defaults to "hash".
+	Mode string `json:"mode"`
+
+	// Fields to redact: "emails", "subjects" (user IDs and usernames) and
+	// "ips". Defaults to all of them.
+	Fields []string `json:"fields"`
+
+	// Key of the hashes. If empty, a random key is generated at startup, so
+	// hashes can't be correlated across restarts and replicas.
+	HashKey        string `json:"hashKey"`
+	HashKeyFromEnv string `json:"hashKeyFromEnv"`
 }
 
 type RefreshToken struct {
@@ -473,4 +1730,9 @@ type RefreshToken struct {
 	ReuseInterval     string `json:"reuseInterval"`
 	AbsoluteLifetime  string `json:"absoluteLifetime"`
 	ValidIfNotUsedFor string `json:"validIfNotUsedFor"`
+
+	// MaxSessionsPerClient limits the number of concurrent refresh tokens a user
+	// can hold for a single client. The oldest one is revoked when exceeded.
+	// Defaults to 1.
+	MaxSessionsPerClient int `json:"maxSessionsPerClient"`
 }
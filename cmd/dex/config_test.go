@@ -1,9 +1,15 @@
 package main
 
 import (
+	"io"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ghodss/yaml"
 	"github.com/kylelemons/godebug/pretty"
@@ -11,7 +17,9 @@ import (
 	"github.com/dexidp/dex/connector/mock"
 	"github.com/dexidp/dex/connector/oidc"
 	"github.com/dexidp/dex/server"
+	"github.com/dexidp/dex/server/signer"
 	"github.com/dexidp/dex/storage"
+	"github.com/dexidp/dex/storage/memory"
 	"github.com/dexidp/dex/storage/sql"
 )
 
@@ -60,6 +68,101 @@ func TestInvalidConfiguration(t *testing.T) {
 	}
 }
 
+func TestInvalidIssuersConfiguration(t *testing.T) {
+	configuration := Config{
+		Issuer: "http://127.0.0.1:5556/dex",
+		Storage: Storage{
+			Type:   "memory",
+			Config: &memory.Config{},
+		},
+		Web: Web{
+			HTTP: "127.0.0.1:5556",
+		},
+		Issuers: []Issuer{
+			{Issuer: "http://127.0.0.1:5556/tenant"},
+			{Issuer: "http://127.0.0.1:5556/dex"},
+			{},
+		},
+	}
+	err := configuration.Validate()
+	if err == nil {
+		t.Fatal("this configuration should be invalid")
+	}
+	got := err.Error()
+	wanted := `invalid Config:
+	-	issuer "http://127.0.0.1:5556/dex" specified more than once
+	-	no issuer specified for additional issuer`
+	if got != wanted {
+		t.Fatalf("Expected error message to be %q, got %q", wanted, got)
+	}
+}
+
+func TestInvalidWebListenersConfiguration(t *testing.T) {
+	configuration := Config{
+		Issuer: "http://127.0.0.1:5556/dex",
+		Storage: Storage{
+			Type:   "memory",
+			Config: &memory.Config{},
+		},
+		Web: Web{
+			Listeners: []WebListener{
+				{Name: "mesh", Addr: "127.0.0.1:5556"},
+				{Name: "mesh", Addr: "127.0.0.1:5557"},
+				{Name: "https", Addr: "0.0.0.0:443"},
+				{Name: "socket"},
+				{Name: "public", Addr: "0.0.0.0:443", TLSCertificates: []TLSCertificate{{Cert: "tls.crt"}}},
+			},
+		},
+	}
+	err := configuration.Validate()
+	if err == nil {
+		t.Fatal("this configuration should be invalid")
+	}
+	got := err.Error()
+	wanted := `invalid Config:
+	-	web listener name "mesh" is reserved or used more than once
+	-	web listener name "https" is reserved or used more than once
+	-	no address specified for web listener "socket"
+	-	TLS certificates of web listener "public" must specify both a cert and key`
+	if got != wanted {
+		t.Fatalf("Expected error message to be %q, got %q", wanted, got)
+	}
+}
+
+func TestInvalidWebTLSConfiguration(t *testing.T) {
+	configuration := Config{
+		Issuer: "http://127.0.0.1:5556/dex",
+		Storage: Storage{
+			Type:   "memory",
+			Config: &memory.Config{},
+		},
+		Web: Web{
+			HTTP:                "127.0.0.1:5556",
+			TLSClientCA:         "ca.crt",
+			TLSCipherSuites:     []string{"TLS_RSA_WITH_RC4_128_SHA"},
+			TLSCurvePreferences: []string{"P224"},
+			Listeners: []WebListener{
+				{Name: "mesh", Addr: "127.0.0.1:5557", TLSClientCA: "ca.crt"},
+				{Name: "public", Addr: "0.0.0.0:443", TLSCertificates: []TLSCertificate{{Cert: "tls.crt", Key: "tls.key"}}, TLSCipherSuites: []string{"TLS_AES_128_GCM_SHA256"}},
+			},
+		},
+	}
+	err := configuration.Validate()
+	if err == nil {
+		t.Fatal("this configuration should be invalid")
+	}
+	got := err.Error()
+	wanted := `invalid Config:
+	-	cannot specify a TLS client CA without an HTTPS address
+	-	unsupported TLS cipher suites, only the secure TLS 1.2 suites of Go are supported
+	-	unsupported TLS curves, supported are: X25519, P256, P384, P521
+	-	web listener "mesh": cannot specify a TLS client CA without TLS certificates
+	-	web listener "public": unsupported TLS cipher suites`
+	if got != wanted {
+		t.Fatalf("Expected error message to be %q, got %q", wanted, got)
+	}
+}
+
 func TestUnmarshalConfig(t *testing.T) {
 	rawConfig := []byte(`
 issuer: http://127.0.0.1:5556/dex
@@ -96,6 +199,11 @@ oauth2:
   grantTypes:
   - refresh_token
   - "urn:ietf:params:oauth:grant-type:token-exchange"
+  customScopes:
+  - name: employee
+    description: View your employee ID
+    claims:
+    - employee_id
 
 connectors:
 - type: mockCallback
@@ -104,6 +212,11 @@ connectors:
 - type: oidc
   id: google
   name: Google
+  order: 1
+  group: Partners
+  hidden: true
+  iconURL: https://example.com/google.svg
+  buttonColor: "#4285f4"
   config:
     issuer: https://accounts.google.com
     clientID: foo
@@ -122,9 +235,11 @@ staticPasswords:
   hash: "JDJhJDEwJDMzRU1UMGNWWVZsUHk2V0FNQ0xzY2VMWWpXaHVIcGJ6NXl1Wnh1L0dBRmowM0o5THl0anV5"
   username: "foo"
   userID: "41331323-6f44-45e6-b3b9-2c4b60c02be5"
+  emailVerified: false
 
 expiry:
   signingKeys: "7h"
+  verificationKeys: "30h"
   idTokens: "25h"
   authRequests: "25h"
   deviceRequests: "10m"
@@ -183,6 +298,13 @@ additionalFeatures: [
 				"refresh_token",
 				"urn:ietf:params:oauth:grant-type:token-exchange",
 			},
+			CustomScopes: []CustomScope{
+				{
+					Name:        "employee",
+					Description: "View your employee ID",
+					Claims:      []string{"employee_id"},
+				},
+			},
 		},
 		StaticConnectors: []Connector{
 			{
@@ -192,9 +314,14 @@ additionalFeatures: [
 				Config: &mock.CallbackConfig{},
 			},
 			{
-				Type: "oidc",
-				ID:   "google",
-				Name: "Google",
+				Type:        "oidc",
+				ID:          "google",
+				Name:        "Google",
+				Order:       1,
+				Group:       "Partners",
+				Hidden:      true,
+				IconURL:     "https://example.com/google.svg",
+				ButtonColor: "#4285f4",
 				Config: &oidc.Config{
 					Issuer:       "https://accounts.google.com",
 					ClientID:     "foo",
@@ -206,10 +333,11 @@ additionalFeatures: [
 		EnablePasswordDB: true,
 		StaticPasswords: []password{
 			{
-				Email:    "admin@example.com",
-				Hash:     []byte("$2a$10$33EMT0cVYVlPy6WAMCLsceLYjWhuHpbz5yuZxu/GAFj03J9Lytjuy"),
-				Username: "admin",
-				UserID:   "08a8684b-db88-4b73-90a9-3cd1661f5466",
+				Email:         "admin@example.com",
+				Hash:          []byte("$2a$10$33EMT0cVYVlPy6WAMCLsceLYjWhuHpbz5yuZxu/GAFj03J9Lytjuy"),
+				Username:      "admin",
+				UserID:        "08a8684b-db88-4b73-90a9-3cd1661f5466",
+				EmailVerified: true,
 			},
 			{
 				Email:    "foo@example.com",
@@ -219,10 +347,11 @@ additionalFeatures: [
 			},
 		},
 		Expiry: Expiry{
-			SigningKeys:    "7h",
-			IDTokens:       "25h",
-			AuthRequests:   "25h",
-			DeviceRequests: "10m",
+			SigningKeys:      "7h",
+			VerificationKeys: "30h",
+			IDTokens:         "25h",
+			AuthRequests:     "25h",
+			DeviceRequests:   "10m",
 		},
 		Logger: Logger{
 			Level:  slog.LevelDebug,
@@ -240,6 +369,459 @@ additionalFeatures: [
 	}
 }
 
+func TestUnmarshalSignerConfig(t *testing.T) {
+	rawConfig := []byte(`
+issuer: http://127.0.0.1:5556/dex
+storage:
+  type: memory
+signer:
+  type: pkcs11
+  config:
+    library: /usr/lib/softhsm/libsofthsm2.so
+    tokenLabel: dex
+    pin: "1234"
+    keyLabel: dex-signing-key
+    refreshInterval: 10m
+`)
+
+	want := Signer{
+		Type: "pkcs11",
+		Config: &signer.PKCS11Config{
+			Library:         "/usr/lib/softhsm/libsofthsm2.so",
+			TokenLabel:      "dex",
+			PIN:             "1234",
+			KeyLabel:        "dex-signing-key",
+			RefreshInterval: "10m",
+		},
+	}
+
+	var c Config
+	if err := yaml.Unmarshal(rawConfig, &c); err != nil {
+		t.Fatalf("failed to decode config: %v", err)
+	}
+
+	if diff := pretty.Compare(c.Signer, want); diff != "" {
+		t.Errorf("got!=want: %s", diff)
+	}
+
+	c.Expiry.SigningKeys = "6h"
+	c.Web.HTTP = "127.0.0.1:5556"
+	if err := c.Validate(); err == nil {
+		t.Error("expected an error when combining an external signer with signing keys expiry")
+	}
+}
+
+func TestUnmarshalStorageCacheConfig(t *testing.T) {
+	rawConfig := []byte(`
+storage:
+  type: memory
+  cache:
+    ttl: 10s
+`)
+
+	var c Config
+	if err := yaml.Unmarshal(rawConfig, &c); err != nil {
+		t.Fatalf("failed to decode config: %v", err)
+	}
+
+	want := Storage{
+		Type:   "memory",
+		Config: &memory.Config{},
+		Cache:  &StorageCache{TTL: "10s"},
+	}
+	if diff := pretty.Compare(c.Storage, want); diff != "" {
+		t.Errorf("got!=want: %s", diff)
+	}
+}
+
+func TestStorageSlowOperationsConfig(t *testing.T) {
+	rawConfig := []byte(`
+storage:
+  type: memory
+  slowOperations:
+    threshold: 250ms
+    operations:
+      GarbageCollect: 5s
+`)
+
+	var c Config
+	if err := yaml.Unmarshal(rawConfig, &c); err != nil {
+		t.Fatalf("failed to decode config: %v", err)
+	}
+
+	slow, err := c.Storage.SlowOperations.ToStorageConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := storage.SlowOperations{
+		Threshold:  250 * time.Millisecond,
+		Operations: map[string]time.Duration{"GarbageCollect": 5 * time.Second},
+	}
+	if diff := pretty.Compare(slow, want); diff != "" {
+		t.Errorf("got!=want: %s", diff)
+	}
+
+	c.Storage.SlowOperations.Operations = map[string]string{"GetClients": "1s"}
+	if _, err := c.Storage.SlowOperations.ToStorageConfig(); err == nil {
+		t.Error("expected an error for an unknown storage operation")
+	}
+}
+
+func TestGCConfig(t *testing.T) {
+	rawConfig := []byte(`
+gc:
+  interval: 10m
+  intervals:
+    deviceTokens: 1m
+  jitter: 30s
+  batchSize: 1000
+`)
+
+	var c Config
+	if err := yaml.Unmarshal(rawConfig, &c); err != nil {
+		t.Fatalf("failed to decode config: %v", err)
+	}
+
+	var got server.Config
+	if err := c.GC.ApplyTo(&got); err != nil {
+		t.Fatal(err)
+	}
+	want := server.Config{
+		GCFrequency: 10 * time.Minute,
+		GCIntervals: map[storage.GCResource]time.Duration{storage.GCDeviceTokens: time.Minute},
+		GCJitter:    30 * time.Second,
+		GCBatchSize: 1000,
+	}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("got!=want: %s", diff)
+	}
+
+	c.GC.Intervals = map[string]string{"refreshTokens": "1m"}
+	if err := c.GC.ApplyTo(&got); err == nil {
+		t.Error("expected an error for an unknown gc resource")
+	}
+}
+
+func TestAuditLogConfig(t *testing.T) {
+	rawConfig := []byte(`
+auditLog:
+  queueSize: 10
+  sinks:
+    - type: file
+      path: ` + filepath.Join(t.TempDir(), "audit.log") + `
+    - type: kafka
+      url: http://kafka-rest:8082
+      topic: dex-audit
+    - type: nats
+      url: nats://nats:4222
+      subject: dex.events
+`)
+
+	var c Config
+	if err := yaml.Unmarshal(rawConfig, &c); err != nil {
+		t.Fatalf("failed to decode config: %v", err)
+	}
+	got, err := c.AuditLog.ToServerConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.QueueSize != 10 || len(got.Sinks) != 3 {
+		t.Errorf("unexpected audit log config: %+v", got)
+	}
+
+	c.AuditLog.Sinks = append(c.AuditLog.Sinks, AuditSink{Type: "kafka", URL: "http://kafka-rest:8082"})
+	if _, err := c.AuditLog.ToServerConfig(); err == nil {
+		t.Error("expected an error for a kafka sink without a topic")
+	}
+	c.AuditLog.Sinks = []AuditSink{{Type: "database"}}
+	if _, err := c.AuditLog.ToServerConfig(); err == nil {
+		t.Error("expected an error for an unknown sink type")
+	}
+}
+
+func TestRateLimitConfig(t *testing.T) {
+	rawConfig := []byte(`
+rateLimit:
+  perIP:
+    requestsPerSecond: 20
+    burst: 50
+  perDeviceCode:
+    requestsPerSecond: 0.5
+`)
+
+	var c Config
+	if err := yaml.Unmarshal(rawConfig, &c); err != nil {
+		t.Fatalf("failed to decode config: %v", err)
+	}
+	want := &server.RateLimitConfig{
+		PerIP:         &server.RateLimit{RequestsPerSecond: 20, Burst: 50},
+		PerDeviceCode: &server.RateLimit{RequestsPerSecond: 0.5},
+	}
+	if diff := pretty.Compare(c.RateLimit.ToServerConfig(), want); diff != "" {
+		t.Errorf("unexpected rate limit config (-got +want):\n%s", diff)
+	}
+}
+
+func TestPasswordLockoutConfig(t *testing.T) {
+	rawConfig := []byte(`
+passwordLockout:
+  threshold: 3
+  duration: 30s
+`)
+
+	var c Config
+	if err := yaml.Unmarshal(rawConfig, &c); err != nil {
+		t.Fatalf("failed to decode config: %v", err)
+	}
+	got, err := c.PasswordLockout.ToServerConfig()
+	if err != nil {
+		t.Fatalf("failed to convert password lockout config: %v", err)
+	}
+	want := &server.PasswordLockoutConfig{Threshold: 3, Duration: 30 * time.Second}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("unexpected password lockout config (-got +want):\n%s", diff)
+	}
+
+	if _, err := (PasswordLockout{MaxDuration: "forever"}).ToServerConfig(); err == nil {
+		t.Error("expected an invalid max duration to be rejected")
+	}
+}
+
+func TestPasswordPolicyConfig(t *testing.T) {
+	rawConfig := []byte(`
+passwordPolicy:
+  minLength: 12
+  minCharacterClasses: 3
+  disallowUserInfo: true
+  checkBreached: true
+  breachedPasswordsTimeout: 2s
+`)
+
+	var c Config
+	if err := yaml.Unmarshal(rawConfig, &c); err != nil {
+		t.Fatalf("failed to decode config: %v", err)
+	}
+	got, err := c.PasswordPolicy.ToServerConfig()
+	if err != nil {
+		t.Fatalf("failed to convert password policy config: %v", err)
+	}
+	want := &server.PasswordPolicyConfig{
+		MinLength:                12,
+		MinCharacterClasses:      3,
+		DisallowUserInfo:         true,
+		CheckBreached:            true,
+		BreachedPasswordsTimeout: 2 * time.Second,
+	}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("unexpected password policy config (-got +want):\n%s", diff)
+	}
+
+	if _, err := (PasswordPolicy{BreachedPasswordsTimeout: "soon"}).ToServerConfig(); err == nil {
+		t.Error("expected an invalid breached passwords timeout to be rejected")
+	}
+}
+
+func TestCookiesConfig(t *testing.T) {
+	rawConfig := []byte(`
+web:
+  cookies:
+    sameSite: Strict
+    secure: true
+    domain: dex.example.com
+    maxAge: 1h
+  csrfProtection: true
+`)
+
+	var c Config
+	if err := yaml.Unmarshal(rawConfig, &c); err != nil {
+		t.Fatalf("failed to decode config: %v", err)
+	}
+	if !c.Web.CSRFProtection {
+		t.Error("expected csrf protection to be enabled")
+	}
+	got, err := c.Web.Cookies.ToServerConfig()
+	if err != nil {
+		t.Fatalf("failed to convert cookies config: %v", err)
+	}
+	secure := true
+	want := &server.CookieConfig{
+		SameSite: http.SameSiteStrictMode,
+		Secure:   &secure,
+		Domain:   "dex.example.com",
+		MaxAge:   time.Hour,
+	}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("unexpected cookies config (-got +want):\n%s", diff)
+	}
+
+	for _, k := range []Cookies{{SameSite: "always"}, {MaxAge: "forever"}, {MaxAge: "-1h"}} {
+		if _, err := k.ToServerConfig(); err == nil {
+			t.Errorf("expected cookies config %+v to be rejected", k)
+		}
+	}
+}
+
+func TestWebLimits(t *testing.T) {
+	rawConfig := []byte(`
+web:
+  limits:
+    readTimeout: 30s
+    writeTimeout: 1m
+    maxHeaderBytes: 65536
+    maxBodyBytes: 16
+`)
+
+	var c Config
+	if err := yaml.Unmarshal(rawConfig, &c); err != nil {
+		t.Fatalf("failed to decode config: %v", err)
+	}
+	var read string
+	srv, err := c.Web.Limits.httpServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		read = string(b)
+	}))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	if srv.ReadHeaderTimeout != 10*time.Second || srv.ReadTimeout != 30*time.Second || srv.WriteTimeout != time.Minute ||
+		srv.IdleTimeout != 2*time.Minute || srv.MaxHeaderBytes != 65536 {
+		t.Errorf("unexpected server limits: %+v", srv)
+	}
+
+	do := func(body io.Reader) int {
+		rr := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/token", body))
+		return rr.Code
+	}
+	if code := do(strings.NewReader("grant_type=code")); code != http.StatusOK || read != "grant_type=code" {
+		t.Errorf("expected small bodies to be read, got %d and %q", code, read)
+	}
+	if code := do(strings.NewReader(strings.Repeat("a", 17))); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected bodies over the limit to be rejected, got %d", code)
+	}
+	// Bodies of unknown length are cut off while they're read.
+	if code := do(io.MultiReader(strings.NewReader(strings.Repeat("a", 17)))); code != http.StatusBadRequest {
+		t.Errorf("expected reading a body over the limit to fail, got %d", code)
+	}
+
+	for _, l := range []WebLimits{{ReadTimeout: "soon"}, {IdleTimeout: "-1s"}} {
+		if _, err := l.httpServer(http.NotFoundHandler()); err == nil {
+			t.Errorf("expected web limits %+v to be rejected", l)
+		}
+	}
+}
+
+func TestHeadersConfig(t *testing.T) {
+	rawConfig := []byte(`
+web:
+  headers:
+    X-Frame-Options: SAMEORIGIN
+    Referrer-Policy: no-referrer
+`)
+
+	var c Config
+	if err := yaml.Unmarshal(rawConfig, &c); err != nil {
+		t.Fatalf("failed to decode config: %v", err)
+	}
+
+	want := http.Header{
+		"Content-Security-Policy":   {"frame-ancestors 'none'; base-uri 'self'; object-src 'none'"},
+		"X-Frame-Options":           {"SAMEORIGIN"},
+		"X-Content-Type-Options":    {"nosniff"},
+		"Strict-Transport-Security": {"max-age=31536000"},
+		"Referrer-Policy":           {"no-referrer"},
+	}
+	if diff := pretty.Compare(c.Web.Headers.ToHTTPHeader(), want); diff != "" {
+		t.Errorf("got!=want: %s", diff)
+	}
+
+	c.Web.Headers.DisableDefaults = true
+	want = http.Header{
+		"X-Frame-Options": {"SAMEORIGIN"},
+		"Referrer-Policy": {"no-referrer"},
+	}
+	if diff := pretty.Compare(c.Web.Headers.ToHTTPHeader(), want); diff != "" {
+		t.Errorf("got!=want: %s", diff)
+	}
+}
+
+func TestUnmarshalGRPCAuthConfig(t *testing.T) {
+	t.Setenv("DEX_API_TOKEN", "from-env")
+
+	rawConfig := []byte(`
+grpc:
+  addr: 127.0.0.1:5557
+  auth:
+    tokens:
+      - token: static
+        roles: [read-only]
+      - tokenFromEnv: DEX_API_TOKEN
+        roles: [client-admin, user-admin]
+`)
+
+	var c Config
+	if err := yaml.Unmarshal(rawConfig, &c); err != nil {
+		t.Fatalf("failed to decode config: %v", err)
+	}
+
+	want := server.APIAuthConfig{
+		Tokens: []server.APIToken{
+			{Token: "static", Roles: []server.APIRole{server.APIRoleReadOnly}},
+			{Token: "from-env", Roles: []server.APIRole{server.APIRoleClientAdmin, server.APIRoleUserAdmin}},
+		},
+	}
+	if diff := pretty.Compare(c.GRPC.Auth.ToServerConfig(), want); diff != "" {
+		t.Errorf("got!=want: %s", diff)
+	}
+}
+
+func TestConnectorMiddlewareConfig(t *testing.T) {
+	rawConfig := []byte(`
+connectors:
+- type: mockPassword
+  id: mock
+  name: Mock
+  middleware:
+  - type: groupRename
+    prefix: "mock:"
+  config:
+    username: foo
+    password: bar
+`)
+
+	var c Config
+	if err := yaml.Unmarshal(rawConfig, &c); err != nil {
+		t.Fatalf("failed to decode config: %v", err)
+	}
+	conn, err := ToStorageConnector(c.StaticConnectors[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"middleware":[{"type":"groupRename","prefix":"mock:"}],"password":"bar","username":"foo"}`
+	if string(conn.Config) != want {
+		t.Errorf("got config %s, want %s", conn.Config, want)
+	}
+
+	invalid := []byte(`
+connectors:
+- type: mockPassword
+  id: mock
+  name: Mock
+  middleware:
+  - type: groupFilter
+  config:
+    username: foo
+    password: bar
+`)
+	if err := yaml.Unmarshal(invalid, &c); err == nil {
+		t.Error("expected an error for a group filter without groups")
+	}
+}
+
 func TestUnmarshalConfigWithEnvNoExpand(t *testing.T) {
 	// If the env variable DEX_EXPAND_ENV is set and has a "falsy" value, os.ExpandEnv is disabled.
 	// ParseBool: "It accepts 1, t, T, TRUE, true, True, 0, f, F, FALSE, false, False."
@@ -420,16 +1002,18 @@ logger:
 		EnablePasswordDB: true,
 		StaticPasswords: []password{
 			{
-				Email:    "admin@example.com",
-				Hash:     []byte("$2a$10$33EMT0cVYVlPy6WAMCLsceLYjWhuHpbz5yuZxu/GAFj03J9Lytjuy"),
-				Username: "admin",
-				UserID:   "08a8684b-db88-4b73-90a9-3cd1661f5466",
+				Email:         "admin@example.com",
+				Hash:          []byte("$2a$10$33EMT0cVYVlPy6WAMCLsceLYjWhuHpbz5yuZxu/GAFj03J9Lytjuy"),
+				Username:      "admin",
+				UserID:        "08a8684b-db88-4b73-90a9-3cd1661f5466",
+				EmailVerified: true,
 			},
 			{
-				Email:    "foo@example.com",
-				Hash:     []byte("$2a$10$33EMT0cVYVlPy6WAMCLsceLYjWhuHpbz5yuZxu/GAFj03J9Lytjuy"),
-				Username: "foo",
-				UserID:   "41331323-6f44-45e6-b3b9-2c4b60c02be5",
+				Email:         "foo@example.com",
+				Hash:          []byte("$2a$10$33EMT0cVYVlPy6WAMCLsceLYjWhuHpbz5yuZxu/GAFj03J9Lytjuy"),
+				Username:      "foo",
+				UserID:        "41331323-6f44-45e6-b3b9-2c4b60c02be5",
+				EmailVerified: true,
 			},
 		},
 		Expiry: Expiry{
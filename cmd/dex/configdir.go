@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// configFragmentKeys are the fields identifying the items of the lists of a
+// config, which must be unique across fragments.
+var configFragmentKeys = map[string]string{
+	"connectors":      "id",
+	"staticClients":   "id",
+	"staticPasswords": "email",
+}
+
+// readConfigFile reads a config file, or the fragments of a config directory
+// merged into one config.
+func readConfigFile(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return os.ReadFile(path)
+	}
+	return mergeConfigDir(path)
+}
+
+// configWatchDir returns the directory to watch for changes of a config file
+// or directory.
+func configWatchDir(path string) string {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return path
+	}
+	return filepath.Dir(path)
+}
+
+// mergeConfigDir merges the .yaml, .yml and .json files of a directory in
+// lexical order, so a base config and the connectors and clients owned by
+// different teams can be kept in separate files, e.g. 00-base.yaml and
+// 50-team-a.yaml. Objects are merged recursively and lists are concatenated.
+// Setting a value in more than one file to different values, or defining a
+// connector, static client or static password in more than one file, is an
+// error. Hidden files and subdirectories are ignored, which skips the data
+// directories of mounted Kubernetes ConfigMaps.
+func mergeConfigDir(dir string) ([]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &configMerger{
+		config:     make(map[string]interface{}),
+		origins:    make(map[string]string),
+		itemOrigin: make(map[string]string),
+	}
+	found := false
+	for _, entry := range entries {
+		name := entry.Name()
+		switch filepath.Ext(name) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		// Follows symlinks, which ConfigMap files are.
+		if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		fragment, err := parseConfigFragment(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		if err := m.merge(m.config, fragment, "", path); err != nil {
+			return nil, err
+		}
+		found = true
+	}
+	if !found {
+		return nil, fmt.Errorf("no config files in directory %s", dir)
+	}
+	return json.Marshal(m.config)
+}
+
+func parseConfigFragment(data []byte) (map[string]interface{}, error) {
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(jsonData))
+	dec.UseNumber()
+	var fragment map[string]interface{}
+	if err := dec.Decode(&fragment); err != nil {
+		return nil, fmt.Errorf("expected an object: %v", err)
+	}
+	return fragment, nil
+}
+
+// configMerger merges config fragments, remembering which file set each
+// value to report conflicts.
+type configMerger struct {
+	config map[string]interface{}
+	// Files by the path of the values they set.
+	origins map[string]string
+	// Files by the list and key of the list items they define.
+	itemOrigin map[string]string
+}
+
+func (m *configMerger) merge(dst, src map[string]interface{}, path, file string) error {
+	for k, v := range src {
+		childPath := k
+		if path != "" {
+			childPath = path + "." + k
+		}
+
+		existing, ok := dst[k]
+		if !ok {
+			if err := m.addItems(childPath, v, file); err != nil {
+				return err
+			}
+			dst[k] = v
+			m.origins[childPath] = file
+			continue
+		}
+
+		switch vt := v.(type) {
+		case map[string]interface{}:
+			if existingMap, ok := existing.(map[string]interface{}); ok {
+				if err := m.merge(existingMap, vt, childPath, file); err != nil {
+					return err
+				}
+				continue
+			}
+		case []interface{}:
+			if existingList, ok := existing.([]interface{}); ok {
+				if err := m.addItems(childPath, v, file); err != nil {
+					return err
+				}
+				dst[k] = append(existingList, vt...)
+				continue
+			}
+		}
+		if !reflect.DeepEqual(existing, v) {
+			return fmt.Errorf("%s is set to different values in %s and %s", childPath, m.origins[childPath], file)
+		}
+	}
+	return nil
+}
+
+// addItems records the items of a list defined by a file, failing if another
+// file defined an item with the same key.
+func (m *configMerger) addItems(path string, v interface{}, file string) error {
+	keyField, ok := configFragmentKeys[path]
+	if !ok {
+		return nil
+	}
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+	for _, item := range items {
+		itemMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		key, ok := itemMap[keyField].(string)
+		if !ok {
+			continue
+		}
+		id := path + "/" + key
+		if other, ok := m.itemOrigin[id]; ok && other != file {
+			return fmt.Errorf("%s: %s %q is defined in %s and %s", path, keyField, key, other, file)
+		}
+		m.itemOrigin[id] = file
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigDir(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	return dir
+}
+
+func TestConfigDir(t *testing.T) {
+	dir := writeConfigDir(t, map[string]string{
+		"00-base.yaml": `
+issuer: http://127.0.0.1:5556/dex
+storage:
+  type: memory
+web:
+  http: 127.0.0.1:5556
+staticClients:
+- id: base-app
+  name: Base App
+  secret: base-secret
+`,
+		"10-team-a.yaml": `
+connectors:
+- type: mockCallback
+  id: team-a
+  name: Team A
+staticClients:
+- id: team-a-app
+  name: Team A App
+  secret: team-a-secret
+`,
+		"20-team-b.yml": `
+web:
+  allowedOrigins:
+  - https://team-b.example.com
+connectors:
+- type: mockCallback
+  id: team-b
+  name: Team B
+`,
+		"README.md":    "ignored",
+		".hidden.yaml": "issuer: ignored",
+	})
+
+	data, err := readConfigFile(dir)
+	require.NoError(t, err)
+	c, err := parseConfig(data, serveOptions{config: dir})
+	require.NoError(t, err)
+
+	require.Equal(t, "http://127.0.0.1:5556/dex", c.Issuer)
+	require.Equal(t, "127.0.0.1:5556", c.Web.HTTP)
+	require.Equal(t, []string{"https://team-b.example.com"}, c.Web.AllowedOrigins)
+	require.Len(t, c.StaticConnectors, 2)
+	require.Equal(t, "team-a", c.StaticConnectors[0].ID)
+	require.Equal(t, "team-b", c.StaticConnectors[1].ID)
+	require.Len(t, c.StaticClients, 2)
+	require.Equal(t, "base-app", c.StaticClients[0].ID)
+	require.Equal(t, "team-a-app", c.StaticClients[1].ID)
+
+	// Merging is deterministic.
+	again, err := readConfigFile(dir)
+	require.NoError(t, err)
+	require.Equal(t, data, again)
+}
+
+func TestConfigDirConflicts(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		err   string
+	}{
+		{
+			name: "different values",
+			files: map[string]string{
+				"a.yaml": "issuer: https://a.example.com\n",
+				"b.yaml": "issuer: https://b.example.com\n",
+			},
+			err: "issuer is set to different values",
+		},
+		{
+			name: "duplicate connector",
+			files: map[string]string{
+				"a.yaml": "connectors:\n- id: github\n  type: mockCallback\n",
+				"b.yaml": "connectors:\n- id: github\n  type: mockCallback\n",
+			},
+			err: `id "github" is defined in`,
+		},
+		{
+			name: "invalid fragment",
+			files: map[string]string{
+				"a.yaml": "- not an object\n",
+			},
+			err: "expected an object",
+		},
+		{
+			name:  "no files",
+			files: map[string]string{"README.md": "ignored"},
+			err:   "no config files",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := readConfigFile(writeConfigDir(t, tc.files))
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+
+	// Equal values may be repeated.
+	_, err := readConfigFile(writeConfigDir(t, map[string]string{
+		"a.yaml": "issuer: https://a.example.com\n",
+		"b.yaml": "issuer: https://a.example.com\n",
+	}))
+	require.NoError(t, err)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/dexidp/dex/api/v2"
+)
+
+type importOptions struct {
+	adminOptions
+
+	updateExisting bool
+	dryRun         bool
+}
+
+func commandImport() *cobra.Command {
+	options := importOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "import [flags] [file]",
+		Short: "Create clients and passwords from a file through the gRPC API",
+		Long: `Create clients and passwords from a YAML or JSON file with "clients" and
+"passwords" lists, in the format written by "dex export". Password hashes are
+base64 encoded. Nothing is stored if any client or password is rejected.`,
+		Example: "dex import --addr 127.0.0.1:5557 bootstrap.yaml",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return runImport(options, args[0])
+		},
+	}
+
+	options.addFlags(cmd)
+	flags := cmd.Flags()
+	flags.BoolVar(&options.updateExisting, "update-existing", false, "Replace existing clients and passwords")
+	flags.BoolVar(&options.dryRun, "dry-run", false, "Validate the file without storing anything")
+
+	return cmd
+}
+
+func commandExport() *cobra.Command {
+	options := adminOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "export",
+		Short:   "Write all clients and passwords, including secrets, as YAML through the gRPC API",
+		Example: "dex export --addr 127.0.0.1:5557 > backup.yaml",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cmd.SilenceUsage = true
+			return runAdmin(options, func(ctx context.Context, client api.DexClient) error {
+				resp, err := client.Export(ctx, &api.ExportReq{})
+				if err != nil {
+					return err
+				}
+				data, err := marshalBatch(resp)
+				if err != nil {
+					return err
+				}
+				_, err = os.Stdout.Write(data)
+				return err
+			})
+		},
+	}
+
+	options.addFlags(cmd)
+	return cmd
+}
+
+func runImport(options importOptions, path string) error {
+	var (
+		data []byte
+		err  error
+	)
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return fmt.Errorf("reading %s: %v", path, err)
+	}
+	batch, err := unmarshalBatch(data)
+	if err != nil {
+		return fmt.Errorf("parsing %s: %v", path, err)
+	}
+
+	return runAdmin(options.adminOptions, func(ctx context.Context, client api.DexClient) error {
+		resp, err := client.Import(ctx, &api.ImportReq{
+			Clients:        batch.Clients,
+			Passwords:      batch.Passwords,
+			UpdateExisting: options.updateExisting,
+			DryRun:         options.dryRun,
+		})
+		if err != nil {
+			return err
+		}
+		printImportResults("client", resp.Clients)
+		printImportResults("password", resp.Passwords)
+
+		switch {
+		case resp.Applied:
+			fmt.Println("Import applied")
+		case options.dryRun && !hasImportErrors(resp):
+			fmt.Println("Dry run succeeded, nothing was stored")
+		default:
+			return errors.New("import rejected, nothing was stored")
+		}
+		return nil
+	})
+}
+
+// unmarshalBatch parses clients and passwords written by marshalBatch.
+func unmarshalBatch(data []byte) (*api.ExportResp, error) {
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	batch := &api.ExportResp{}
+	if err := protojson.Unmarshal(jsonData, batch); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// marshalBatch writes clients and passwords as YAML.
+func marshalBatch(batch *api.ExportResp) ([]byte, error) {
+	jsonData, err := protojson.Marshal(batch)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.JSONToYAML(jsonData)
+}
+
+func printImportResults(kind string, results []*api.ImportResult) {
+	for _, r := range results {
+		switch {
+		case r.Error != "":
+			fmt.Printf("%s %q: rejected: %s\n", kind, r.Id, r.Error)
+		case r.Created:
+			fmt.Printf("%s %q: created\n", kind, r.Id)
+		case r.Updated:
+			fmt.Printf("%s %q: updated\n", kind, r.Id)
+		}
+	}
+}
+
+func hasImportErrors(resp *api.ImportResp) bool {
+	for _, r := range slices.Concat(resp.Clients, resp.Passwords) {
+		if r.Error != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/dexidp/dex/api/v2"
+)
+
+func TestUnmarshalBatch(t *testing.T) {
+	batch, err := unmarshalBatch([]byte(`
+clients:
+  - id: example-app
+    secret: ZXhhbXBsZS1hcHAtc2VjcmV0
+    redirectUris:
+      - http://127.0.0.1:5555/callback
+    name: Example App
+    refreshTokenPolicy:
+      absoluteLifetime: 720h
+passwords:
+  - email: admin@example.com
+    # base64 of the bcrypt hash of "password"
+    hash: JDJhJDEwJDJiMnNsMkZwS2ZTZ3FyTFRyNlRvV3VVbzdlVmhDaDFaRGlMdFV4NlVDNnFCSlU3WTQ4YnZp
+    username: admin
+    user_id: 08a8684b-db88-4b73-90a9-3cd1661f5466
+`))
+	require.NoError(t, err)
+
+	want := &api.ExportResp{
+		Clients: []*api.Client{{
+			Id:                 "example-app",
+			Secret:             "ZXhhbXBsZS1hcHAtc2VjcmV0",
+			RedirectUris:       []string{"http://127.0.0.1:5555/callback"},
+			Name:               "Example App",
+			RefreshTokenPolicy: &api.RefreshTokenPolicy{AbsoluteLifetime: "720h"},
+		}},
+		Passwords: []*api.Password{{
+			Email:    "admin@example.com",
+			Hash:     []byte("$2a$10$2b2sl2FpKfSgqrLTr6ToWuUo7eVhCh1ZDiLtUx6UC6qBJU7Y48bvi"),
+			Username: "admin",
+			UserId:   "08a8684b-db88-4b73-90a9-3cd1661f5466",
+		}},
+	}
+	require.True(t, proto.Equal(want, batch), "got %v", batch)
+
+	// What export writes can be imported again.
+	data, err := marshalBatch(batch)
+	require.NoError(t, err)
+	roundTrip, err := unmarshalBatch(data)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(batch, roundTrip), "got %v", roundTrip)
+
+	_, err = unmarshalBatch([]byte("clients: [{unknown: true}]"))
+	require.Error(t, err)
+}
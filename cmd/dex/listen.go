@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// unixAddrPrefix marks addresses of Unix domain sockets, e.g. "unix:/run/dex/grpc.sock".
+	unixAddrPrefix = "unix:"
+	// systemdAddrPrefix marks sockets passed by systemd socket activation,
+	// either by name (FileDescriptorName=) or by position, e.g. "systemd:grpc" or "systemd:0".
+	systemdAddrPrefix = "systemd:"
+
+	// systemdListenFDsStart is the first file descriptor passed by systemd.
+	systemdListenFDsStart = 3
+)
+
+// listen returns a listener for an address of the config. Addresses are TCP
+// addresses unless prefixed with "unix:" or "systemd:".
+func listen(addr string) (net.Listener, error) {
+	switch {
+	case strings.HasPrefix(addr, unixAddrPrefix):
+		return listenUnix(strings.TrimPrefix(addr, unixAddrPrefix))
+	case strings.HasPrefix(addr, systemdAddrPrefix):
+		return listenSystemd(strings.TrimPrefix(addr, systemdAddrPrefix), os.Getenv)
+	default:
+		return net.Listen("tcp", addr)
+	}
+}
+
+// listenUnix listens on a Unix domain socket, replacing the socket left
+// behind by a previous process.
+func listenUnix(path string) (net.Listener, error) {
+	if path == "" {
+		return nil, errors.New("no socket path specified")
+	}
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode().Type() != fs.ModeSocket {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("removing stale socket: %v", err)
+		}
+	}
+	return net.Listen("unix", path)
+}
+
+// listenSystemd returns a listener inherited through systemd socket activation.
+func listenSystemd(name string, getenv func(string) string) (net.Listener, error) {
+	fd, err := systemdListenFD(name, getenv)
+	if err != nil {
+		return nil, err
+	}
+	f := os.NewFile(uintptr(fd), name)
+	defer f.Close() // FileListener duplicates the descriptor.
+
+	l, err := net.FileListener(f)
+	if err != nil {
+		return nil, fmt.Errorf("inheriting socket %q: %v", name, err)
+	}
+	return l, nil
+}
+
+// systemdListenFD returns the file descriptor of a socket passed by systemd,
+// following the sd_listen_fds(3) protocol.
+func systemdListenFD(name string, getenv func(string) string) (int, error) {
+	if pid, err := strconv.Atoi(getenv("LISTEN_PID")); err != nil || pid != os.Getpid() {
+		return 0, errors.New("no sockets passed by systemd")
+	}
+	count, err := strconv.Atoi(getenv("LISTEN_FDS"))
+	if err != nil || count <= 0 {
+		return 0, errors.New("no sockets passed by systemd")
+	}
+
+	var names []string
+	if fdNames := getenv("LISTEN_FDNAMES"); fdNames != "" {
+		names = strings.Split(fdNames, ":")
+	}
+	for i, n := range names {
+		if i < count && n == name {
+			return systemdListenFDsStart + i, nil
+		}
+	}
+	if i, err := strconv.Atoi(name); err == nil {
+		if i < 0 || i >= count {
+			return 0, fmt.Errorf("socket %d not passed by systemd, got %d sockets", i, count)
+		}
+		return systemdListenFDsStart + i, nil
+	}
+	return 0, fmt.Errorf("no socket named %q passed by systemd", name)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dex.sock")
+
+	l, err := listen("unix:" + path)
+	require.NoError(t, err)
+	require.Equal(t, "unix", l.Addr().Network())
+
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	conn.Close()
+	l.Close()
+
+	// A socket left behind by a crashed process is replaced.
+	stale, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	l, err = listen("unix:" + path)
+	require.NoError(t, err)
+	l.Close()
+
+	// Regular files are never removed.
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(file, nil, 0o600))
+	_, err = listen("unix:" + file)
+	require.Error(t, err)
+	require.FileExists(t, file)
+}
+
+func TestSystemdListenFD(t *testing.T) {
+	pid := strconv.Itoa(os.Getpid())
+
+	tests := []struct {
+		name    string
+		socket  string
+		env     map[string]string
+		want    int
+		wantErr bool
+	}{
+		{
+			name:    "not activated",
+			socket:  "0",
+			env:     map[string]string{},
+			wantErr: true,
+		},
+		{
+			name:    "other process",
+			socket:  "0",
+			env:     map[string]string{"LISTEN_PID": "1", "LISTEN_FDS": "1"},
+			wantErr: true,
+		},
+		{
+			name:   "by position",
+			socket: "1",
+			env:    map[string]string{"LISTEN_PID": pid, "LISTEN_FDS": "2"},
+			want:   4,
+		},
+		{
+			name:   "by name",
+			socket: "grpc",
+			env:    map[string]string{"LISTEN_PID": pid, "LISTEN_FDS": "3", "LISTEN_FDNAMES": "http:grpc:telemetry"},
+			want:   4,
+		},
+		{
+			name:    "position out of range",
+			socket:  "1",
+			env:     map[string]string{"LISTEN_PID": pid, "LISTEN_FDS": "1"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown name",
+			socket:  "grpc",
+			env:     map[string]string{"LISTEN_PID": pid, "LISTEN_FDS": "1", "LISTEN_FDNAMES": "http"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fd, err := systemdListenFD(tc.socket, func(key string) string { return tc.env[key] })
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, fd)
+		})
+	}
+}
@@ -2,17 +2,25 @@ package main
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"log/slog"
 	"os"
 	"strings"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/dexidp/dex/server"
 )
 
 var logFormats = []string{"json", "text"}
 
-func newLogger(level slog.Level, format string) (*slog.Logger, error) {
+// newLogger returns a logger writing to stderr. If the redactor is set,
+// personal data is redacted from the log lines.
+func newLogger(level slog.Level, format string, redact *redactor) (*slog.Logger, error) {
 	var handler slog.Handler
 	switch strings.ToLower(format) {
 	case "", "text":
@@ -27,6 +35,10 @@ func newLogger(level slog.Level, format string) (*slog.Logger, error) {
 		return nil, fmt.Errorf("log format is not one of the supported values (%s): %s", strings.Join(logFormats, ", "), format)
 	}
 
+	if redact != nil {
+		handler = redactingHandler{handler: handler, redactor: redact}
+	}
+
 	return slog.New(newRequestContextHandler(handler)), nil
 }
 
@@ -55,6 +67,11 @@ func (h requestContextHandler) Handle(ctx context.Context, record slog.Record) e
 		record.AddAttrs(slog.String(string(server.RequestKeyRequestID), v))
 	}
 
+	// Correlate the logs of a request with its trace.
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		record.AddAttrs(slog.String("trace_id", sc.TraceID().String()))
+	}
+
 	return h.handler.Handle(ctx, record)
 }
 
@@ -65,3 +82,113 @@ func (h requestContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 func (h requestContextHandler) WithGroup(name string) slog.Handler {
 	return h.handler.WithGroup(name)
 }
+
+// redactedFields maps the fields of the redaction config to the keys of the
+// log attributes holding them.
+var redactedFields = map[string][]string{
+	"emails":   {"email"},
+	"subjects": {"user_id", "sub", "subject", "username", "preferred_username", "login"},
+	"ips":      {"ip", "remote_ip", string(server.RequestKeyRemoteIP)},
+}
+
+// redactor hashes or removes the values of log attributes holding personal
+// data.
+type redactor struct {
+	keys map[string]bool
+	// nil if values are removed rather than hashed.
+	hashKey []byte
+}
+
+// newRedactor returns the redactor of the config, or nil if unset.
+func newRedactor(c *LogRedaction) (*redactor, error) {
+	if c == nil {
+		return nil, nil
+	}
+	r := &redactor{keys: make(map[string]bool)}
+	switch c.Mode {
+	case "", "hash":
+		key := c.HashKey
+		if key == "" && c.HashKeyFromEnv != "" {
+			key = os.Getenv(c.HashKeyFromEnv)
+		}
+		r.hashKey = []byte(key)
+		if key == "" {
+			r.hashKey = make([]byte, 32)
+			if _, err := rand.Read(r.hashKey); err != nil {
+				return nil, fmt.Errorf("generate log hash key: %v", err)
+			}
+		}
+	case "remove":
+	default:
+		return nil, fmt.Errorf("log redaction mode is not one of the supported values (hash, remove): %s", c.Mode)
+	}
+
+	fields := c.Fields
+	if len(fields) == 0 {
+		fields = []string{"emails", "subjects", "ips"}
+	}
+	for _, field := range fields {
+		keys, ok := redactedFields[field]
+		if !ok {
+			return nil, fmt.Errorf("unknown log redaction field %q", field)
+		}
+		for _, key := range keys {
+			r.keys[key] = true
+		}
+	}
+	return r, nil
+}
+
+func (r *redactor) redact(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() == slog.KindGroup {
+		group := a.Value.Group()
+		attrs := make([]slog.Attr, 0, len(group))
+		for _, ga := range group {
+			attrs = append(attrs, r.redact(ga))
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(attrs...)}
+	}
+	if !r.keys[a.Key] || (a.Value.Kind() == slog.KindString && a.Value.String() == "") {
+		return a
+	}
+	if r.hashKey == nil {
+		return slog.String(a.Key, "REDACTED")
+	}
+	mac := hmac.New(sha256.New, r.hashKey)
+	mac.Write([]byte(a.Value.String()))
+	return slog.String(a.Key, "hmac:"+hex.EncodeToString(mac.Sum(nil))[:16])
+}
+
+var _ slog.Handler = redactingHandler{}
+
+// redactingHandler redacts personal data from the attributes of records.
+type redactingHandler struct {
+	handler  slog.Handler
+	redactor *redactor
+}
+
+func (h redactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h redactingHandler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+	record.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(h.redactor.redact(a))
+		return true
+	})
+	return h.handler.Handle(ctx, redacted)
+}
+
+func (h redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		redacted = append(redacted, h.redactor.redact(a))
+	}
+	return redactingHandler{h.handler.WithAttrs(redacted), h.redactor}
+}
+
+func (h redactingHandler) WithGroup(name string) slog.Handler {
+	return redactingHandler{h.handler.WithGroup(name), h.redactor}
+}
@@ -17,6 +17,10 @@ func commandRoot() *cobra.Command {
 	}
 	rootCmd.AddCommand(commandServe())
 	rootCmd.AddCommand(commandVersion())
+	rootCmd.AddCommand(commandAdmin())
+	rootCmd.AddCommand(commandImport())
+	rootCmd.AddCommand(commandExport())
+	rootCmd.AddCommand(commandStorage())
 	return rootCmd
 }
 
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	gosundheit "github.com/AppsFlyer/go-sundheit"
+	"github.com/fsnotify/fsnotify"
+	"github.com/ghodss/yaml"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/grpc"
+
+	"github.com/dexidp/dex/api/v2"
+	"github.com/dexidp/dex/server"
+	"github.com/dexidp/dex/server/signer"
+	"github.com/dexidp/dex/storage"
+)
+
+// configReloadSettle is how long the config file must not change before it's
+// reloaded, so that files written in several steps aren't read half-written.
+const configReloadSettle = time.Second
+
+// reloadGracePeriod is how long the servers built from a previous version of
+// the config keep running, so that requests in flight can finish.
+const reloadGracePeriod = time.Minute
+
+var (
+	configReloadSuccessful = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:      "config_last_reload_successful",
+		Namespace: "dex",
+		Help:      "Whether the last reload of the config succeeded.",
+	})
+	configReloadTimestamp = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:      "config_last_reload_success_timestamp_seconds",
+		Namespace: "dex",
+		Help:      "Timestamp of the last successful load of the config.",
+	})
+)
+
+// serverDeps are the components shared by the servers built from each
+// version of the config. Changing them requires a restart.
+type serverDeps struct {
+	logger        *slog.Logger
+	now           func() time.Time
+	healthChecker gosundheit.Health
+	isLeader      func() bool
+	signer        signer.Signer
+	events        *server.EventBroker
+}
+
+// servers are the servers built from one version of the config.
+type servers struct {
+	// serv is the server of the default issuer.
+	serv     *server.Server
+	handler  http.Handler
+	api      api.DexServer
+	registry *prometheus.Registry
+	// cancel stops the background work of the servers.
+	cancel context.CancelFunc
+}
+
+// configReloader rebuilds the servers from the config file and replaces the
+// running ones. Invalid configs are rejected, leaving the running servers in
+// place.
+type configReloader struct {
+	options serveOptions
+	storage storage.Storage
+	deps    serverDeps
+	logger  *slog.Logger
+
+	current atomic.Pointer[servers]
+
+	mu     sync.Mutex // guards data and config
+	data   []byte
+	config Config
+}
+
+// newConfigReloader builds the servers from the loaded config.
+func newConfigReloader(options serveOptions, data []byte, c Config, s storage.Storage, deps serverDeps) (*configReloader, error) {
+	r := &configReloader{
+		options: options,
+		storage: s,
+		deps:    deps,
+		logger:  deps.logger.With("component", "config-reloader"),
+	}
+	if err := r.apply(data, c); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// parseConfig parses a config file and applies the command line overrides.
+func parseConfig(data []byte, options serveOptions) (Config, error) {
+	var c Config
+	data, err := resolveConfigSecrets(data)
+	if err != nil {
+		return c, fmt.Errorf("config file %s: %v", options.config, err)
+	}
+	if err := yaml.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("error parse config file %s: %v", options.config, err)
+	}
+	applyConfigOverrides(options, &c)
+	return c, nil
+}
+
+// reload reads the config file and applies it. Unless forced, the config is
+// only applied if the file changed since it was last applied.
+func (r *configReloader) reload(force bool) error {
+	data, err := readConfigFile(r.options.config)
+	if err != nil {
+		return fmt.Errorf("failed to read config file %s: %v", r.options.config, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !force && bytes.Equal(data, r.data) {
+		return nil
+	}
+	c, err := parseConfig(data, r.options)
+	if err != nil {
+		return err
+	}
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	if sections := restartRequired(r.config, c); len(sections) > 0 {
+		r.logger.Warn("config changes require a restart", "sections", sections)
+	}
+	if err := r.apply(data, c); err != nil {
+		return err
+	}
+	r.logger.Info("config reloaded")
+	return nil
+}
+
+// apply builds the servers of a config and replaces the running ones.
+func (r *configReloader) apply(data []byte, c Config) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	srvs, err := newServers(ctx, &c, r.storage, r.deps)
+	if err != nil {
+		cancel()
+		return err
+	}
+	srvs.cancel = cancel
+
+	if old := r.current.Swap(srvs); old != nil {
+		time.AfterFunc(reloadGracePeriod, old.cancel)
+	}
+	r.data = data
+	r.config = c
+	configReloadSuccessful.Set(1)
+	configReloadTimestamp.SetToCurrentTime()
+	return nil
+}
+
+// restartRequired returns the sections of the config which changed but only
+// take effect after a restart.
+func restartRequired(old, c Config) []string {
+	type listeners struct {
+		HTTP, HTTPS, TLSMinVersion, TLSMaxVersion string
+		TLSCertificates                           []TLSCertificate
+		Listeners                                 []WebListener
+	}
+	webListeners := func(w Web) listeners {
+		return listeners{w.HTTP, w.HTTPS, w.TLSMinVersion, w.TLSMaxVersion, w.certificates(), w.Listeners}
+	}
+
+	var sections []string
+	for _, section := range []struct {
+		name     string
+		old, new interface{}
+	}{
+		{"issuer", old.Issuer, c.Issuer},
+		{"storage", old.Storage, c.Storage},
+		{"signer", old.Signer, c.Signer},
+		{"web", webListeners(old.Web), webListeners(c.Web)},
+		{"telemetry", old.Telemetry, c.Telemetry},
+		{"grpc", old.GRPC, c.GRPC},
+		{"logger", old.Logger, c.Logger},
+	} {
+		if !reflect.DeepEqual(section.old, section.new) {
+			sections = append(sections, section.name)
+		}
+	}
+	return sections
+}
+
+// ServeHTTP serves requests with the current servers.
+func (r *configReloader) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.current.Load().handler.ServeHTTP(w, req)
+}
+
+// readinessHandler is the readiness probe of the current servers.
+func (r *configReloader) readinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.current.Load().serv.ReadinessHandler().ServeHTTP(w, req)
+	})
+}
+
+// Gather collects the metrics of the current servers.
+func (r *configReloader) Gather() ([]*dto.MetricFamily, error) {
+	return r.current.Load().registry.Gather()
+}
+
+// apiServiceDesc describes the gRPC API served by the current servers.
+func (r *configReloader) apiServiceDesc() *grpc.ServiceDesc {
+	return reloadableServiceDesc(api.Dex_ServiceDesc, func() interface{} {
+		return r.current.Load().api
+	})
+}
+
+// reloadableServiceDesc returns a copy of a gRPC service description whose
+// methods are served by the implementation returned by current at the time
+// of each call.
+func reloadableServiceDesc(desc grpc.ServiceDesc, current func() interface{}) *grpc.ServiceDesc {
+	methods := make([]grpc.MethodDesc, len(desc.Methods))
+	for i, m := range desc.Methods {
+		handler := m.Handler
+		m.Handler = func(_ interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			return handler(current(), ctx, dec, interceptor)
+		}
+		methods[i] = m
+	}
+	streams := make([]grpc.StreamDesc, len(desc.Streams))
+	for i, s := range desc.Streams {
+		handler := s.Handler
+		s.Handler = func(_ interface{}, stream grpc.ServerStream) error {
+			return handler(current(), stream)
+		}
+		streams[i] = s
+	}
+	desc.Methods = methods
+	desc.Streams = streams
+	return &desc
+}
+
+// run reloads the config on SIGHUP and, if watch is set, when the config file
+// changes, until done is closed.
+func (r *configReloader) run(done <-chan struct{}, watch bool) error {
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGHUP)
+	defer signal.Stop(sigc)
+
+	var events <-chan fsnotify.Event
+	var watchErrors <-chan error
+	if watch {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			return fmt.Errorf("create watcher for config reloader: %v", err)
+		}
+		defer watcher.Close()
+		// Watch the dir to handle files replaced by renames, like mounted
+		// Kubernetes ConfigMaps.
+		if err := watcher.Add(configWatchDir(r.options.config)); err != nil {
+			return fmt.Errorf("watch dir for config reloader: %v", err)
+		}
+		events, watchErrors = watcher.Events, watcher.Errors
+	}
+
+	var settle <-chan time.Time
+	for {
+		force := false
+		select {
+		case <-done:
+			return nil
+		case sig := <-sigc:
+			r.logger.Info("reloading config from signal", "signal", sig)
+			force = true
+		case evt := <-events:
+			r.logger.Debug("config dir changed", "event", evt.Name, "operation", evt.Op.String())
+			settle = time.After(configReloadSettle)
+			continue
+		case err := <-watchErrors:
+			r.logger.Error("config reloader watch", "err", err)
+			continue
+		case <-settle:
+			settle = nil
+		}
+
+		if err := r.reload(force); err != nil {
+			configReloadSuccessful.Set(0)
+			r.logger.Error("failed to reload config, keeping the running config", "err", err)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gosundheit "github.com/AppsFlyer/go-sundheit"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/dexidp/dex/api/v2"
+	"github.com/dexidp/dex/server"
+	"github.com/dexidp/dex/storage/memory"
+)
+
+const reloadTestConfig = `
+issuer: http://127.0.0.1:5556/dex
+storage:
+  type: memory
+web:
+  http: 127.0.0.1:5556
+enablePasswordDB: true
+staticClients:
+- id: %s
+  name: Example App
+  secret: ZXhhbXBsZS1hcHAtc2VjcmV0
+  redirectURIs:
+  - http://127.0.0.1:5555/callback
+`
+
+func TestConfigReload(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig := func(config string) {
+		require.NoError(t, os.WriteFile(configFile, []byte(config), 0o600))
+	}
+	writeConfig(fmt.Sprintf(reloadTestConfig, "app-1"))
+
+	options := serveOptions{config: configFile}
+	data, err := os.ReadFile(configFile)
+	require.NoError(t, err)
+	c, err := parseConfig(data, options)
+	require.NoError(t, err)
+
+	s := memory.New(logger)
+	reloader, err := newConfigReloader(options, data, c, s, serverDeps{
+		logger:        logger,
+		now:           time.Now,
+		healthChecker: gosundheit.New(),
+		events:        server.NewEventBroker(),
+	})
+	require.NoError(t, err)
+
+	getClient := func(id string) error {
+		desc := reloader.apiServiceDesc()
+		for _, m := range desc.Methods {
+			if m.MethodName != "GetClient" {
+				continue
+			}
+			_, err := m.Handler(nil, context.Background(), func(req interface{}) error {
+				proto.Merge(req.(proto.Message), &api.GetClientReq{Id: id})
+				return nil
+			}, nil)
+			return err
+		}
+		t.Fatal("no GetClient method")
+		return nil
+	}
+	require.NoError(t, getClient("app-1"))
+
+	// Unchanged files aren't reloaded.
+	first := reloader.current.Load()
+	require.NoError(t, reloader.reload(false))
+	require.Same(t, first, reloader.current.Load())
+
+	// The gRPC API sees the static clients of the new config.
+	writeConfig(fmt.Sprintf(reloadTestConfig, "app-2"))
+	require.NoError(t, reloader.reload(false))
+	require.NoError(t, getClient("app-2"))
+	require.Error(t, getClient("app-1"))
+
+	// Invalid configs are rejected and the running servers are kept.
+	running := reloader.current.Load()
+	writeConfig("issuer: ''\n")
+	require.Error(t, reloader.reload(true))
+	require.Same(t, running, reloader.current.Load())
+	require.NoError(t, getClient("app-2"))
+}
+
+func TestRestartRequired(t *testing.T) {
+	old := Config{Issuer: "https://dex.example.com", Web: Web{HTTP: "0.0.0.0:5556"}}
+
+	c := old
+	c.Web.AllowedOrigins = []string{"https://app.example.com"}
+	c.Expiry.IDTokens = "1h"
+	require.Empty(t, restartRequired(old, c))
+
+	c.Web.HTTP = "0.0.0.0:8080"
+	c.GRPC.Addr = "0.0.0.0:5557"
+	require.Equal(t, []string{"web", "grpc"}, restartRequired(old, c))
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	vault "github.com/hashicorp/vault/api"
+
+	"github.com/dexidp/dex/pkg/featureflags"
+)
+
+// Prefixes of references to secrets stored in files and in HashiCorp Vault.
+const (
+	fileSecretPrefix  = "file://"
+	vaultSecretPrefix = "vault://"
+)
+
+// vaultSecretTimeout bounds reading a secret from Vault.
+const vaultSecretTimeout = 10 * time.Second
+
+// envSecretRef matches references to environment variables, $NAME or ${NAME}.
+var envSecretRef = regexp.MustCompile(`^\$(?:([A-Za-z_][A-Za-z0-9_]*)|\{([A-Za-z_][A-Za-z0-9_]*)\})$`)
+
+// pluginConfigPath matches the paths of storage and connector configs, whose
+// references are resolved when they're decoded, after environment variables
+// were expanded.
+var pluginConfigPath = regexp.MustCompile(`^(storage\.config|connectors\[\d+\]\.config)$`)
+
+// secretFieldNames are parts of the names of fields holding secrets, e.g.
+// clientSecret, bindPW, password or hashKey.
+var secretFieldNames = []string{"secret", "password", "passwd", "bindpw", "token", "key", "hash", "credential", "authorization"}
+
+func isSecretField(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range secretFieldNames {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// secretResolver resolves references to secrets in the values of secret
+// fields of the config, so that secrets needn't be kept in the config file:
+//
+//   - $NAME or ${NAME} is replaced with the environment variable NAME, unless
+//     environment variables aren't expanded (DEX_EXPAND_ENV=false).
+//   - file:///path is replaced with the contents of the file, without
+//     trailing newlines.
+//   - vault://path#key is replaced with the key of the secret at path in
+//     HashiCorp Vault, e.g. vault://secret/data/dex#clientSecret. Vault is
+//     configured with the environment variables of its CLI, like VAULT_ADDR
+//     and VAULT_TOKEN.
+type secretResolver struct {
+	vault *vault.Client
+	// Secrets read from Vault by path.
+	vaultSecrets map[string]map[string]interface{}
+}
+
+// resolveConfigSecrets resolves the secret references of a config file,
+// except in storage and connector configs. The file is returned unchanged if
+// it has no references.
+func resolveConfigSecrets(data []byte) ([]byte, error) {
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		// Reported when parsing the config.
+		return data, nil
+	}
+	return new(secretResolver).resolveJSON(jsonData, data, "", func(path string) bool {
+		return pluginConfigPath.MatchString(path)
+	})
+}
+
+// resolvePluginSecrets resolves the secret references of a storage or
+// connector config at path.
+func resolvePluginSecrets(data []byte, path string) ([]byte, error) {
+	return new(secretResolver).resolveJSON(data, data, path, func(string) bool { return false })
+}
+
+// resolveJSON resolves the references of a JSON document, returning orig if
+// there are none.
+func (r *secretResolver) resolveJSON(data, orig []byte, path string, skip func(path string) bool) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return orig, nil
+	}
+	v, changed, err := r.resolve(v, path, false, skip)
+	if err != nil || !changed {
+		return orig, err
+	}
+	return json.Marshal(v)
+}
+
+// resolve replaces the references in the secret fields of a decoded JSON
+// value, reporting if any was replaced.
+func (r *secretResolver) resolve(v interface{}, path string, secret bool, skip func(path string) bool) (interface{}, bool, error) {
+	changed := false
+	switch vt := v.(type) {
+	case string:
+		if !secret {
+			return v, false, nil
+		}
+		return r.resolveValue(vt, path)
+	case map[string]interface{}:
+		for k, child := range vt {
+			childPath := k
+			if path != "" {
+				childPath = path + "." + k
+			}
+			if skip(childPath) {
+				continue
+			}
+			resolved, ok, err := r.resolve(child, childPath, isSecretField(k), skip)
+			if err != nil {
+				return nil, false, err
+			}
+			vt[k] = resolved
+			changed = changed || ok
+		}
+	case []interface{}:
+		for i, item := range vt {
+			resolved, ok, err := r.resolve(item, fmt.Sprintf("%s[%d]", path, i), secret, skip)
+			if err != nil {
+				return nil, false, err
+			}
+			vt[i] = resolved
+			changed = changed || ok
+		}
+	}
+	return v, changed, nil
+}
+
+// resolveValue returns the secret a value refers to. Errors don't contain
+// secrets.
+func (r *secretResolver) resolveValue(value, path string) (interface{}, bool, error) {
+	switch {
+	case envSecretRef.MatchString(value) && featureflags.ExpandEnv.Enabled():
+		m := envSecretRef.FindStringSubmatch(value)
+		name := m[1] + m[2]
+		secret, ok := os.LookupEnv(name)
+		if !ok {
+			return nil, false, fmt.Errorf("%s: environment variable %s is not set", path, name)
+		}
+		return secret, true, nil
+	case strings.HasPrefix(value, fileSecretPrefix):
+		secret, err := os.ReadFile(strings.TrimPrefix(value, fileSecretPrefix))
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: read secret file: %v", path, err)
+		}
+		return strings.TrimRight(string(secret), "\r\n"), true, nil
+	case strings.HasPrefix(value, vaultSecretPrefix):
+		secretPath, key, ok := strings.Cut(strings.TrimPrefix(value, vaultSecretPrefix), "#")
+		if !ok || secretPath == "" || key == "" {
+			return nil, false, fmt.Errorf("%s: invalid Vault reference %q, expected vault://path#key", path, value)
+		}
+		secret, err := r.readVault(secretPath, key)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: %v", path, err)
+		}
+		return secret, true, nil
+	}
+	return value, false, nil
+}
+
+// readVault reads a key of a secret from Vault. Secrets of the KV version 2
+// engine, whose paths contain "data/", hold their keys in a nested object.
+func (r *secretResolver) readVault(path, key string) (string, error) {
+	data, ok := r.vaultSecrets[path]
+	if !ok {
+		if r.vault == nil {
+			cfg := vault.DefaultConfig()
+			if cfg.Error != nil {
+				return "", fmt.Errorf("vault: load config: %v", cfg.Error)
+			}
+			client, err := vault.NewClient(cfg)
+			if err != nil {
+				return "", fmt.Errorf("vault: create client: %v", err)
+			}
+			r.vault = client
+			r.vaultSecrets = make(map[string]map[string]interface{})
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), vaultSecretTimeout)
+		defer cancel()
+		secret, err := r.vault.Logical().ReadWithContext(ctx, path)
+		if err != nil {
+			return "", fmt.Errorf("vault: read %s: %v", path, err)
+		}
+		if secret == nil {
+			return "", fmt.Errorf("vault: no secret at %s", path)
+		}
+		data = secret.Data
+		if nested, ok := data["data"].(map[string]interface{}); ok {
+			if _, ok := data["metadata"]; ok {
+				data = nested
+			}
+		}
+		r.vaultSecrets[path] = data
+	}
+
+	value, ok := data[key].(string)
+	if !ok {
+		return "", fmt.Errorf("vault: secret at %s has no string key %q", path, key)
+	}
+	return value, nil
+}
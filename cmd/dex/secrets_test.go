@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dexidp/dex/connector/oidc"
+)
+
+const secretsTestConfig = `
+issuer: http://127.0.0.1:5556/dex
+storage:
+  type: memory
+staticClients:
+- id: example-app
+  name: Example App
+  secret: %[1]s
+  redirectURIs:
+  - http://127.0.0.1:5555/callback
+connectors:
+- type: oidc
+  id: oidc
+  name: OIDC
+  config:
+    issuer: https://accounts.example.com
+    clientID: dex
+    clientSecret: %[1]s
+    redirectURI: http://127.0.0.1:5556/dex/callback
+`
+
+func TestSecretReferences(t *testing.T) {
+	t.Setenv("EXAMPLE_APP_SECRET", "app-secret")
+
+	// A KV version 2 secret.
+	vaultServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/secret/data/dex" || r.Header.Get("X-Vault-Token") != "vault-token" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"data": {"oidc": "vault-secret"}, "metadata": {"version": 1}}}`))
+	}))
+	defer vaultServer.Close()
+	t.Setenv("VAULT_ADDR", vaultServer.URL)
+	t.Setenv("VAULT_TOKEN", "vault-token")
+
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(secretFile, []byte("file-secret\n"), 0o600))
+
+	tests := []struct {
+		name   string
+		ref    string
+		secret string
+		err    bool
+	}{
+		{name: "plain value", ref: "plain-secret", secret: "plain-secret"},
+		{name: "environment variable", ref: "$EXAMPLE_APP_SECRET", secret: "app-secret"},
+		{name: "file", ref: "file://" + secretFile, secret: "file-secret"},
+		{name: "vault", ref: "vault://secret/data/dex#oidc", secret: "vault-secret"},
+		{name: "unset environment variable", ref: "$NOT_SET_SECRET", err: true},
+		{name: "missing file", ref: "file:///does/not/exist", err: true},
+		{name: "missing vault key", ref: "vault://secret/data/dex#missing", err: true},
+		{name: "vault reference without key", ref: "vault://secret/data/dex", err: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := parseConfig([]byte(fmt.Sprintf(secretsTestConfig, tc.ref)), serveOptions{config: "config.yaml"})
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.secret, c.StaticClients[0].Secret)
+			require.Equal(t, tc.secret, c.StaticConnectors[0].Config.(*oidc.Config).ClientSecret)
+		})
+	}
+}
+
+func TestSecretReferencesOnlyInSecretFields(t *testing.T) {
+	t.Setenv("EXAMPLE_APP_NAME", "resolved")
+
+	c, err := parseConfig([]byte(`
+issuer: http://127.0.0.1:5556/dex
+storage:
+  type: memory
+staticClients:
+- id: example-app
+  name: $EXAMPLE_APP_NAME
+  secret: ZXhhbXBsZS1hcHAtc2VjcmV0
+  redirectURIs:
+  - http://127.0.0.1:5555/callback
+`), serveOptions{config: "config.yaml"})
+	require.NoError(t, err)
+	require.Equal(t, "$EXAMPLE_APP_NAME", c.StaticClients[0].Name)
+}
@@ -7,7 +7,6 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"net"
 	"net/http"
 	"net/http/pprof"
 	"os"
@@ -23,15 +22,19 @@ import (
 	"github.com/AppsFlyer/go-sundheit/checks"
 	gosundheithttp "github.com/AppsFlyer/go-sundheit/http"
 	"github.com/fsnotify/fsnotify"
-	"github.com/ghodss/yaml"
 	grpcprometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"github.com/oklog/run"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 
 	"github.com/dexidp/dex/api/v2"
@@ -48,6 +51,8 @@ type serveOptions struct {
 	webHTTPSAddr  string
 	telemetryAddr string
 	grpcAddr      string
+	watchConfig   bool
+	validate      bool
 }
 
 var buildInfo = prometheus.NewGaugeVec(
@@ -59,11 +64,20 @@ var buildInfo = prometheus.NewGaugeVec(
 	[]string{"version", "go_version", "platform"},
 )
 
+var tlsCertificateExpiry = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name:      "tls_certificate_expiry_timestamp_seconds",
+		Namespace: "dex",
+		Help:      "Expiry (notAfter) of the TLS certificate served by a listener as a Unix timestamp.",
+	},
+	[]string{"listener"},
+)
+
 func commandServe() *cobra.Command {
 	options := serveOptions{}
 
 	cmd := &cobra.Command{
-		Use:     "serve [flags] [config file]",
+		Use:     "serve [flags] [config file or directory]",
 		Short:   "Launch Dex",
 		Example: "dex serve config.yaml",
 		Args:    cobra.ExactArgs(1),
@@ -73,6 +87,9 @@ func commandServe() *cobra.Command {
 
 			options.config = args[0]
 
+			if options.validate {
+				return runValidate(options)
+			}
 			return runServe(options)
 		},
 	}
@@ -83,25 +100,29 @@ func commandServe() *cobra.Command {
 	flags.StringVar(&options.webHTTPSAddr, "web-https-addr", "", "Web HTTPS address")
 	flags.StringVar(&options.telemetryAddr, "telemetry-addr", "", "Telemetry address")
 	flags.StringVar(&options.grpcAddr, "grpc-addr", "", "gRPC API address")
+	flags.BoolVar(&options.watchConfig, "watch-config", false, "Reload the config file when it changes")
+	flags.BoolVar(&options.validate, "validate", false, "Check the config file, its connectors and the storage connection, then exit")
 
 	return cmd
 }
 
 func runServe(options serveOptions) error {
 	configFile := options.config
-	configData, err := os.ReadFile(configFile)
+	configData, err := readConfigFile(configFile)
 	if err != nil {
 		return fmt.Errorf("failed to read config file %s: %v", configFile, err)
 	}
 
-	var c Config
-	if err := yaml.Unmarshal(configData, &c); err != nil {
-		return fmt.Errorf("error parse config file %s: %v", configFile, err)
+	c, err := parseConfig(configData, options)
+	if err != nil {
+		return err
 	}
 
-	applyConfigOverrides(options, &c)
-
-	logger, err := newLogger(c.Logger.Level, c.Logger.Format)
+	redact, err := newRedactor(c.Logger.Redact)
+	if err != nil {
+		return fmt.Errorf("invalid config: %v", err)
+	}
+	logger, err := newLogger(c.Logger.Level, c.Logger.Format, redact)
 	if err != nil {
 		return fmt.Errorf("invalid config: %v", err)
 	}
@@ -119,6 +140,9 @@ func runServe(options serveOptions) error {
 	if c.Logger.Level != slog.LevelInfo {
 		logger.Info("config using log level", "level", c.Logger.Level)
 	}
+	if r := c.Logger.Redact; r != nil {
+		logger.Info("config log redaction", "mode", r.Mode, "fields", r.Fields)
+	}
 	if err := c.Validate(); err != nil {
 		return err
 	}
@@ -127,7 +151,7 @@ func runServe(options serveOptions) error {
 
 	prometheusRegistry := prometheus.NewRegistry()
 
-	prometheusRegistry.MustRegister(buildInfo)
+	prometheusRegistry.MustRegister(buildInfo, tlsCertificateExpiry, configReloadSuccessful, configReloadTimestamp)
 	recordBuildInfo()
 
 	err = prometheusRegistry.Register(collectors.NewGoCollector())
@@ -148,49 +172,35 @@ func runServe(options serveOptions) error {
 
 	var grpcOptions []grpc.ServerOption
 
-	allowedTLSCiphers := []uint16{
-		tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
-		tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
-		tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
-		tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
-		tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,
-		tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305,
-		tls.TLS_RSA_WITH_AES_128_GCM_SHA256,
-		tls.TLS_RSA_WITH_AES_256_GCM_SHA384,
-	}
-
-	allowedTLSVersions := map[string]int{
-		"1.2": tls.VersionTLS12,
-		"1.3": tls.VersionTLS13,
+	if c.Telemetry.Tracing != nil {
+		tracerProvider, err := newTracerProvider(context.Background(), *c.Telemetry.Tracing)
+		if err != nil {
+			return fmt.Errorf("invalid config: tracing: %v", err)
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := tracerProvider.Shutdown(ctx); err != nil {
+				logger.Error("failed to export remaining traces", "err", err)
+			}
+		}()
+		// Connectors and the HTTP and gRPC instrumentation use the global
+		// provider, and continue the traces of callers.
+		otel.SetTracerProvider(tracerProvider)
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+		grpcOptions = append(grpcOptions, grpc.StatsHandler(otelgrpc.NewServerHandler()))
+		logger.Info("config tracing", "endpoint", c.Telemetry.Tracing.Endpoint, "protocol", c.Telemetry.Tracing.Protocol)
 	}
 
 	if c.GRPC.TLSCert != "" {
-		tlsMinVersion := tls.VersionTLS12
-		if c.GRPC.TLSMinVersion != "" {
-			tlsMinVersion = allowedTLSVersions[c.GRPC.TLSMinVersion]
-		}
-		tlsMaxVersion := 0 // default for max is whatever Go defaults to
-		if c.GRPC.TLSMaxVersion != "" {
-			tlsMaxVersion = allowedTLSVersions[c.GRPC.TLSMaxVersion]
-		}
-		baseTLSConfig := &tls.Config{
-			MinVersion:               uint16(tlsMinVersion),
-			MaxVersion:               uint16(tlsMaxVersion),
-			CipherSuites:             allowedTLSCiphers,
-			PreferServerCipherSuites: true,
-		}
-
-		tlsConfig, err := newTLSReloader(logger, c.GRPC.TLSCert, c.GRPC.TLSKey, c.GRPC.TLSClientCA, baseTLSConfig)
+		baseTLSConfig, err := newBaseTLSConfig(c.GRPC.TLSMinVersion, c.GRPC.TLSMaxVersion, nil, nil)
 		if err != nil {
 			return fmt.Errorf("invalid config: get gRPC TLS: %v", err)
 		}
 
-		if c.GRPC.TLSClientCA != "" {
-			// Only add metrics if client auth is enabled
-			grpcOptions = append(grpcOptions,
-				grpc.StreamInterceptor(grpcMetrics.StreamServerInterceptor()),
-				grpc.UnaryInterceptor(grpcMetrics.UnaryServerInterceptor()),
-			)
+		tlsConfig, err := newTLSReloader(logger, "grpc", c.GRPC.certificates(), c.GRPC.TLSClientCA, baseTLSConfig)
+		if err != nil {
+			return fmt.Errorf("invalid config: get gRPC TLS: %v", err)
 		}
 
 		grpcOptions = append(grpcOptions, grpc.Creds(credentials.NewTLS(tlsConfig)))
@@ -204,81 +214,35 @@ func runServe(options serveOptions) error {
 
 	logger.Info("config storage", "storage_type", c.Storage.Type)
 
-	if len(c.StaticClients) > 0 {
-		for i, client := range c.StaticClients {
-			if client.Name == "" {
-				return fmt.Errorf("invalid config: Name field is required for a client")
-			}
-			if client.ID == "" && client.IDEnv == "" {
-				return fmt.Errorf("invalid config: ID or IDEnv field is required for a client")
-			}
-			if client.IDEnv != "" {
-				if client.ID != "" {
-					return fmt.Errorf("invalid config: ID and IDEnv fields are exclusive for client %q", client.ID)
-				}
-				c.StaticClients[i].ID = os.Getenv(client.IDEnv)
-			}
-			if client.Secret == "" && client.SecretEnv == "" && !client.Public {
-				return fmt.Errorf("invalid config: Secret or SecretEnv field is required for client %q", client.ID)
-			}
-			if client.SecretEnv != "" {
-				if client.Secret != "" {
-					return fmt.Errorf("invalid config: Secret and SecretEnv fields are exclusive for client %q", client.ID)
-				}
-				c.StaticClients[i].Secret = os.Getenv(client.SecretEnv)
-			}
-			logger.Info("config static client", "client_name", client.Name)
-		}
-		s = storage.WithStaticClients(s, c.StaticClients)
-	}
-	if len(c.StaticPasswords) > 0 {
-		passwords := make([]storage.Password, len(c.StaticPasswords))
-		for i, p := range c.StaticPasswords {
-			passwords[i] = storage.Password(p)
-		}
-		s = storage.WithStaticPasswords(s, passwords, logger)
+	// Storages wrapping s below don't implement optional interfaces.
+	var isLeader func() bool
+	if elector, ok := s.(storage.LeaderElector); ok {
+		isLeader = elector.IsLeader
 	}
+	changes, _ := s.(storage.ChangeNotifier)
 
-	storageConnectors := make([]storage.Connector, len(c.StaticConnectors))
-	for i, c := range c.StaticConnectors {
-		if c.ID == "" || c.Name == "" || c.Type == "" {
-			return fmt.Errorf("invalid config: ID, Type and Name fields are required for a connector")
-		}
-		if c.Config == nil {
-			return fmt.Errorf("invalid config: no config field for connector %q", c.ID)
-		}
-		logger.Info("config connector", "connector_id", c.ID)
-
-		// convert to a storage connector object
-		conn, err := ToStorageConnector(c)
-		if err != nil {
-			return fmt.Errorf("failed to initialize storage connectors: %v", err)
-		}
-		storageConnectors[i] = conn
-	}
-
-	if c.EnablePasswordDB {
-		storageConnectors = append(storageConnectors, storage.Connector{
-			ID:   server.LocalConnector,
-			Name: "Email",
-			Type: server.LocalConnector,
-		})
-		logger.Info("config connector: local passwords enabled")
-	}
-
-	s = storage.WithStaticConnectors(s, storageConnectors)
-
-	if len(c.OAuth2.ResponseTypes) > 0 {
-		logger.Info("config response types accepted", "response_types", c.OAuth2.ResponseTypes)
+	slowOperations, err := c.Storage.SlowOperations.ToStorageConfig()
+	if err != nil {
+		return fmt.Errorf("invalid config: %v", err)
 	}
-	if c.OAuth2.SkipApprovalScreen {
-		logger.Info("config skipping approval screen")
+	s, err = storage.WithMetrics(s, c.Storage.Type, prometheusRegistry, slowOperations, logger)
+	if err != nil {
+		return fmt.Errorf("failed to register storage metrics: %v", err)
 	}
-	if c.OAuth2.PasswordConnector != "" {
-		logger.Info("config using password grant connector", "password_connector", c.OAuth2.PasswordConnector)
+	if c.Telemetry.Tracing != nil {
+		s = storage.WithTracing(s, otel.GetTracerProvider())
 	}
-	if len(c.Web.AllowedOrigins) > 0 {
-		logger.Info("config allowed origins", "origins", c.Web.AllowedOrigins)
+
+	if c.Storage.Cache != nil {
+		ttl, err := time.ParseDuration(c.Storage.Cache.TTL)
+		if err != nil {
+			return fmt.Errorf("invalid config value %q for storage cache ttl: %v", c.Storage.Cache.TTL, err)
+		}
+		if ttl <= 0 {
+			return fmt.Errorf("invalid config value %q for storage cache ttl: must be positive", c.Storage.Cache.TTL)
+		}
+		logger.Info("config storage cache", "ttl", ttl, "change_notifications", changes != nil)
+		s = storage.WithCache(s, ttl, changes)
 	}
 
 	// explicitly convert to UTC.
@@ -286,98 +250,51 @@ func runServe(options serveOptions) error {
 
 	healthChecker := gosundheit.New()
 
-	serverConfig := server.Config{
-		AllowedGrantTypes:      c.OAuth2.GrantTypes,
-		SupportedResponseTypes: c.OAuth2.ResponseTypes,
-		SkipApprovalScreen:     c.OAuth2.SkipApprovalScreen,
-		AlwaysShowLoginScreen:  c.OAuth2.AlwaysShowLoginScreen,
-		PasswordConnector:      c.OAuth2.PasswordConnector,
-		Headers:                c.Web.Headers.ToHTTPHeader(),
-		AllowedOrigins:         c.Web.AllowedOrigins,
-		AllowedHeaders:         c.Web.AllowedHeaders,
-		Issuer:                 c.Issuer,
-		Storage:                s,
-		Web:                    c.Frontend,
-		Logger:                 logger,
-		Now:                    now,
-		PrometheusRegistry:     prometheusRegistry,
-		HealthChecker:          healthChecker,
-	}
-	if c.Expiry.SigningKeys != "" {
-		signingKeys, err := time.ParseDuration(c.Expiry.SigningKeys)
-		if err != nil {
-			return fmt.Errorf("invalid config value %q for signing keys expiry: %v", c.Expiry.SigningKeys, err)
-		}
-		logger.Info("config signing keys", "expire_after", signingKeys)
-		serverConfig.RotateKeysAfter = signingKeys
-	}
-	if c.Expiry.IDTokens != "" {
-		idTokens, err := time.ParseDuration(c.Expiry.IDTokens)
-		if err != nil {
-			return fmt.Errorf("invalid config value %q for id token expiry: %v", c.Expiry.IDTokens, err)
-		}
-		logger.Info("config id tokens", "valid_for", idTokens)
-		serverConfig.IDTokensValidFor = idTokens
+	deps := serverDeps{
+		logger:        logger,
+		now:           now,
+		healthChecker: healthChecker,
+		isLeader:      isLeader,
+		// Share audit events of all issuers with subscribers of the gRPC API.
+		events: server.NewEventBroker(),
 	}
-	if c.Expiry.AuthRequests != "" {
-		authRequests, err := time.ParseDuration(c.Expiry.AuthRequests)
-		if err != nil {
-			return fmt.Errorf("invalid config value %q for auth request expiry: %v", c.Expiry.AuthRequests, err)
-		}
-		logger.Info("config auth requests", "valid_for", authRequests)
-		serverConfig.AuthRequestsValidFor = authRequests
-	}
-	if c.Expiry.DeviceRequests != "" {
-		deviceRequests, err := time.ParseDuration(c.Expiry.DeviceRequests)
+	if c.Signer.Config != nil {
+		signer, err := c.Signer.Config.Open(context.Background(), logger)
 		if err != nil {
-			return fmt.Errorf("invalid config value %q for device request expiry: %v", c.Expiry.AuthRequests, err)
+			return fmt.Errorf("failed to initialize signer: %v", err)
 		}
-		logger.Info("config device requests", "valid_for", deviceRequests)
-		serverConfig.DeviceRequestsValidFor = deviceRequests
-	}
-	refreshTokenPolicy, err := server.NewRefreshTokenPolicy(
-		logger,
-		c.Expiry.RefreshTokens.DisableRotation,
-		c.Expiry.RefreshTokens.ValidIfNotUsedFor,
-		c.Expiry.RefreshTokens.AbsoluteLifetime,
-		c.Expiry.RefreshTokens.ReuseInterval,
-	)
-	if err != nil {
-		return fmt.Errorf("invalid refresh token expiration policy config: %v", err)
-	}
-
-	serverConfig.RefreshTokenPolicy = refreshTokenPolicy
-
-	serverConfig.RealIPHeader = c.Web.ClientRemoteIP.Header
-	serverConfig.TrustedRealIPCIDRs, err = c.Web.ClientRemoteIP.ParseTrustedProxies()
-	if err != nil {
-		return fmt.Errorf("failed to parse client remote IP settings: %v", err)
+		logger.Info("config signer", "signer_type", c.Signer.Type)
+		deps.signer = signer
 	}
 
-	serv, err := server.NewServer(context.Background(), serverConfig)
+	// The servers are rebuilt when the config is reloaded.
+	reloader, err := newConfigReloader(options, configData, c, s, deps)
 	if err != nil {
-		return fmt.Errorf("failed to initialize server: %v", err)
+		return err
 	}
+	var handler http.Handler = reloader
 
 	telemetryRouter := http.NewServeMux()
-	telemetryRouter.Handle("/metrics", promhttp.HandlerFor(prometheusRegistry, promhttp.HandlerOpts{}))
+	telemetryRouter.Handle("/metrics", promhttp.HandlerFor(prometheus.Gatherers{prometheusRegistry, reloader}, promhttp.HandlerOpts{}))
 
 	// Configure health checker
 	{
 		handler := gosundheithttp.HandleHealthJSON(healthChecker)
 		telemetryRouter.Handle("/healthz", handler)
 
-		// Kubernetes style health checks
+		// Kubernetes style health checks. Instances are live while they serve
+		// requests, and ready once the storage is healthy and tokens can be
+		// signed.
 		telemetryRouter.HandleFunc("/healthz/live", func(w http.ResponseWriter, _ *http.Request) {
 			_, _ = w.Write([]byte("ok"))
 		})
-		telemetryRouter.Handle("/healthz/ready", handler)
+		telemetryRouter.Handle("/healthz/ready", reloader.readinessHandler())
 	}
 
 	healthChecker.RegisterCheck(
 		&checks.CustomCheck{
 			CheckName: "storage",
-			CheckFunc: storage.NewCustomHealthCheckFunc(serverConfig.Storage, serverConfig.Now),
+			CheckFunc: storage.NewCustomHealthCheckFunc(s, now),
 		},
 		gosundheit.ExecutionPeriod(15*time.Second),
 		gosundheit.InitiallyPassing(true),
@@ -391,7 +308,7 @@ func runServe(options serveOptions) error {
 
 		logger.Info("listening on", "server", name, "address", c.Telemetry.HTTP)
 
-		l, err := net.Listen("tcp", c.Telemetry.HTTP)
+		l, err := listen(c.Telemetry.HTTP)
 		if err != nil {
 			return fmt.Errorf("listening (%s) on %s: %v", name, c.Telemetry.HTTP, err)
 		}
@@ -424,13 +341,14 @@ func runServe(options serveOptions) error {
 
 		logger.Info("listening on", "server", name, "address", c.Web.HTTP)
 
-		l, err := net.Listen("tcp", c.Web.HTTP)
+		l, err := listen(c.Web.HTTP)
 		if err != nil {
 			return fmt.Errorf("listening (%s) on %s: %v", name, c.Web.HTTP, err)
 		}
 
-		server := &http.Server{
-			Handler: serv,
+		server, err := c.Web.Limits.httpServer(handler)
+		if err != nil {
+			return fmt.Errorf("invalid config: %v", err)
 		}
 		defer server.Close()
 
@@ -453,40 +371,75 @@ func runServe(options serveOptions) error {
 
 		logger.Info("listening on", "server", name, "address", c.Web.HTTPS)
 
-		l, err := net.Listen("tcp", c.Web.HTTPS)
+		l, err := listen(c.Web.HTTPS)
 		if err != nil {
 			return fmt.Errorf("listening (%s) on %s: %v", name, c.Web.HTTPS, err)
 		}
 
-		tlsMinVersion := tls.VersionTLS12
-		if c.Web.TLSMinVersion != "" {
-			tlsMinVersion = allowedTLSVersions[c.Web.TLSMinVersion]
+		baseTLSConfig, err := newBaseTLSConfig(c.Web.TLSMinVersion, c.Web.TLSMaxVersion, c.Web.TLSCipherSuites, c.Web.TLSCurvePreferences)
+		if err != nil {
+			return fmt.Errorf("invalid config: get HTTP TLS: %v", err)
 		}
-		tlsMaxVersion := 0 // default for max is whatever Go defaults to
-		if c.Web.TLSMaxVersion != "" {
-			tlsMaxVersion = allowedTLSVersions[c.Web.TLSMaxVersion]
+
+		tlsConfig, err := newTLSReloader(logger, "web", c.Web.certificates(), c.Web.TLSClientCA, baseTLSConfig)
+		if err != nil {
+			return fmt.Errorf("invalid config: get HTTP TLS: %v", err)
 		}
 
-		baseTLSConfig := &tls.Config{
-			MinVersion:               uint16(tlsMinVersion),
-			MaxVersion:               uint16(tlsMaxVersion),
-			CipherSuites:             allowedTLSCiphers,
-			PreferServerCipherSuites: true,
+		server, err := c.Web.Limits.httpServer(handler)
+		if err != nil {
+			return fmt.Errorf("invalid config: %v", err)
 		}
+		server.TLSConfig = tlsConfig
+		defer server.Close()
+
+		group.Add(func() error {
+			return server.ServeTLS(l, "", "")
+		}, func(err error) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			logger.Debug("starting graceful shutdown", "server", name)
+			if err := server.Shutdown(ctx); err != nil {
+				logger.Error("graceful shutdown", "server", name, "err", err)
+			}
+		})
+	}
+
+	// Set up additional web servers
+	for _, listener := range c.Web.Listeners {
+		name := listener.Name
 
-		tlsConfig, err := newTLSReloader(logger, c.Web.TLSCert, c.Web.TLSKey, "", baseTLSConfig)
+		logger.Info("listening on", "server", name, "address", listener.Addr)
+
+		l, err := listen(listener.Addr)
 		if err != nil {
-			return fmt.Errorf("invalid config: get HTTP TLS: %v", err)
+			return fmt.Errorf("listening (%s) on %s: %v", name, listener.Addr, err)
 		}
 
-		server := &http.Server{
-			Handler:   serv,
-			TLSConfig: tlsConfig,
+		server, err := c.Web.Limits.httpServer(handler)
+		if err != nil {
+			return fmt.Errorf("invalid config: %v", err)
 		}
 		defer server.Close()
 
+		if len(listener.TLSCertificates) != 0 {
+			baseTLSConfig, err := newBaseTLSConfig(listener.TLSMinVersion, listener.TLSMaxVersion, listener.TLSCipherSuites, listener.TLSCurvePreferences)
+			if err != nil {
+				return fmt.Errorf("invalid config: get TLS of web listener %q: %v", name, err)
+			}
+
+			server.TLSConfig, err = newTLSReloader(logger, name, listener.TLSCertificates, listener.TLSClientCA, baseTLSConfig)
+			if err != nil {
+				return fmt.Errorf("invalid config: get TLS of web listener %q: %v", name, err)
+			}
+		}
+
 		group.Add(func() error {
-			return server.ServeTLS(l, "", "")
+			if server.TLSConfig != nil {
+				return server.ServeTLS(l, "", "")
+			}
+			return server.Serve(l)
 		}, func(err error) {
 			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 			defer cancel()
@@ -500,15 +453,57 @@ func runServe(options serveOptions) error {
 
 	// Set up grpc server
 	if c.GRPC.Addr != "" {
+		// Record latency and status codes of each method, including calls
+		// rejected by the authentication and rate limits, whose logs carry
+		// the request ID.
+		grpcMetrics.EnableHandlingTimeHistogram()
+		unaryRequestID, streamRequestID := server.NewAPIRequestIDInterceptors()
+		unaryInterceptors := []grpc.UnaryServerInterceptor{unaryRequestID, grpcMetrics.UnaryServerInterceptor()}
+		streamInterceptors := []grpc.StreamServerInterceptor{streamRequestID, grpcMetrics.StreamServerInterceptor()}
+
+		if c.GRPC.Auth.Enabled() {
+			unaryAuth, streamAuth, err := server.NewAPIAuthInterceptors(c.GRPC.Auth.ToServerConfig(), logger)
+			if err != nil {
+				return fmt.Errorf("invalid config: gRPC auth: %v", err)
+			}
+			if c.GRPC.TLSCert == "" {
+				logger.Warn("gRPC bearer tokens are sent in plain text without TLS")
+			}
+			unaryInterceptors = append(unaryInterceptors, unaryAuth)
+			streamInterceptors = append(streamInterceptors, streamAuth)
+		}
+
+		if c.GRPC.RateLimit != nil {
+			unaryLimit, streamLimit, err := server.NewAPIRateLimitInterceptors(server.APIRateLimit{
+				RequestsPerSecond: c.GRPC.RateLimit.RequestsPerSecond,
+				Burst:             c.GRPC.RateLimit.Burst,
+			}, logger)
+			if err != nil {
+				return fmt.Errorf("invalid config: gRPC rate limit: %v", err)
+			}
+			unaryInterceptors = append(unaryInterceptors, unaryLimit)
+			streamInterceptors = append(streamInterceptors, streamLimit)
+		}
+
+		grpcOptions = append(grpcOptions,
+			grpc.ChainUnaryInterceptor(unaryInterceptors...),
+			grpc.ChainStreamInterceptor(streamInterceptors...),
+		)
+
 		logger.Info("listening on", "server", "grpc", "address", c.GRPC.Addr)
 
-		grpcListener, err := net.Listen("tcp", c.GRPC.Addr)
+		grpcListener, err := listen(c.GRPC.Addr)
 		if err != nil {
 			return fmt.Errorf("listening (grcp) on %s: %w", c.GRPC.Addr, err)
 		}
 
 		grpcSrv := grpc.NewServer(grpcOptions...)
-		api.RegisterDexServer(grpcSrv, server.NewAPI(serverConfig.Storage, logger, version, serv))
+		grpcSrv.RegisterService(reloader.apiServiceDesc(), reloader.current.Load().api)
+
+		// Serve the standard health service so load balancers can probe the
+		// gRPC listener, reporting the same status as the readiness endpoint.
+		healthSrv := health.NewServer()
+		healthpb.RegisterHealthServer(grpcSrv, healthSrv)
 
 		grpcMetrics.InitializeMetrics(grpcSrv)
 		if c.GRPC.Reflection {
@@ -516,14 +511,58 @@ func runServe(options serveOptions) error {
 			reflection.Register(grpcSrv)
 		}
 
+		healthCtx, cancelHealth := context.WithCancel(context.Background())
+		group.Add(func() error {
+			updateGRPCHealth(healthCtx, healthSrv, healthChecker)
+			return nil
+		}, func(err error) {
+			cancelHealth()
+		})
+
 		group.Add(func() error {
 			return grpcSrv.Serve(grpcListener)
 		}, func(err error) {
 			logger.Debug("starting graceful shutdown", "server", "grpc")
+			healthSrv.Shutdown()
 			grpcSrv.GracefulStop()
 		})
 	}
 
+	// Rotate the signing key on demand when receiving SIGUSR1.
+	{
+		sigc := make(chan os.Signal, 1)
+		signal.Notify(sigc, syscall.SIGUSR1)
+		done := make(chan struct{})
+
+		group.Add(func() error {
+			for {
+				select {
+				case <-sigc:
+					logger.Info("rotating signing keys from signal")
+					if err := reloader.current.Load().serv.RotateKeys(context.Background()); err != nil {
+						logger.Error("failed to rotate signing keys", "err", err)
+					}
+				case <-done:
+					return nil
+				}
+			}
+		}, func(err error) {
+			signal.Stop(sigc)
+			close(done)
+		})
+	}
+
+	// Reload the config when receiving SIGHUP or, if enabled, when the file
+	// changes.
+	{
+		done := make(chan struct{})
+		group.Add(func() error {
+			return reloader.run(done, options.watchConfig)
+		}, func(err error) {
+			close(done)
+		})
+	}
+
 	group.Add(run.SignalHandler(context.Background(), os.Interrupt, syscall.SIGTERM))
 	if err := group.Run(); err != nil {
 		if _, ok := err.(run.SignalError); !ok {
@@ -534,6 +573,413 @@ func runServe(options serveOptions) error {
 	return nil
 }
 
+// newServers builds the servers of the issuers of a config on top of storage
+// s, wrapping it with the static clients, passwords and connectors of the
+// config. The servers stop their background work when ctx is canceled.
+func newServers(ctx context.Context, c *Config, s storage.Storage, deps serverDeps) (*servers, error) {
+	logger := deps.logger
+	// HTTP metrics of each version of the config are collected separately,
+	// since they can't be registered more than once.
+	registry := prometheus.NewRegistry()
+
+	if len(c.StaticClients) > 0 {
+		for i := range c.StaticClients {
+			client := &c.StaticClients[i]
+			if err := resolveStaticClient(client); err != nil {
+				return nil, fmt.Errorf("invalid config: %v", err)
+			}
+			logger.Info("config static client", "client_name", client.Name)
+		}
+		s = storage.WithStaticClients(s, c.StaticClients)
+	}
+	if len(c.StaticPasswords) > 0 {
+		passwords := make([]storage.Password, len(c.StaticPasswords))
+		for i, p := range c.StaticPasswords {
+			passwords[i] = storage.Password(p)
+		}
+		s = storage.WithStaticPasswords(s, passwords, logger)
+	}
+
+	storageConnectors := make([]storage.Connector, len(c.StaticConnectors))
+	for i, c := range c.StaticConnectors {
+		if c.ID == "" || c.Name == "" || c.Type == "" {
+			return nil, fmt.Errorf("invalid config: ID, Type and Name fields are required for a connector")
+		}
+		if c.Config == nil {
+			return nil, fmt.Errorf("invalid config: no config field for connector %q", c.ID)
+		}
+		logger.Info("config connector", "connector_id", c.ID)
+
+		// convert to a storage connector object
+		conn, err := ToStorageConnector(c)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize storage connectors: %v", err)
+		}
+		if err := server.ValidateConnectorDisplay(conn.Display); err != nil {
+			return nil, fmt.Errorf("invalid config: connector %q: %v", c.ID, err)
+		}
+		storageConnectors[i] = conn
+	}
+
+	if c.EnablePasswordDB {
+		storageConnectors = append(storageConnectors, storage.Connector{
+			ID:   server.LocalConnector,
+			Name: "Email",
+			Type: server.LocalConnector,
+		})
+		logger.Info("config connector: local passwords enabled")
+	}
+
+	s = storage.WithStaticConnectors(s, storageConnectors)
+
+	if len(c.OAuth2.ResponseTypes) > 0 {
+		logger.Info("config response types accepted", "response_types", c.OAuth2.ResponseTypes)
+	}
+	if c.OAuth2.SkipApprovalScreen {
+		logger.Info("config skipping approval screen")
+	}
+	if c.OAuth2.PasswordConnector != "" {
+		logger.Info("config using password grant connector", "password_connector", c.OAuth2.PasswordConnector)
+	}
+	for _, scope := range c.OAuth2.CustomScopes {
+		logger.Info("config custom scope", "scope", scope.Name, "claims", scope.Claims)
+	}
+	for _, transform := range c.OAuth2.ClaimsTransforms {
+		logger.Info("config claims transform", "claim", transform.Claim)
+	}
+	if len(c.Web.AllowedOrigins) > 0 {
+		logger.Info("config allowed origins", "origins", c.Web.AllowedOrigins)
+	}
+	if l := c.Web.Limits; l != (WebLimits{}) {
+		logger.Info("config web limits", "read_header_timeout", l.ReadHeaderTimeout, "read_timeout", l.ReadTimeout,
+			"write_timeout", l.WriteTimeout, "idle_timeout", l.IdleTimeout, "max_header_bytes", l.MaxHeaderBytes,
+			"max_body_bytes", l.MaxBodyBytes)
+	}
+
+	serverConfig := server.Config{
+		AllowedGrantTypes:      c.OAuth2.GrantTypes,
+		SupportedResponseTypes: c.OAuth2.ResponseTypes,
+		SkipApprovalScreen:     c.OAuth2.SkipApprovalScreen,
+		AlwaysShowLoginScreen:  c.OAuth2.AlwaysShowLoginScreen,
+		PasswordConnector:      c.OAuth2.PasswordConnector,
+		Headers:                c.Web.Headers.ToHTTPHeader(),
+		AllowedOrigins:         c.Web.AllowedOrigins,
+		AllowedHeaders:         c.Web.AllowedHeaders,
+		Issuer:                 c.Issuer,
+		Storage:                s,
+		Web:                    c.Frontend,
+		Logger:                 logger,
+		Now:                    deps.now,
+		PrometheusRegistry:     registry,
+		HealthChecker:          deps.healthChecker,
+		HealthReportToken:      c.Telemetry.healthReportToken(),
+		IsLeader:               deps.isLeader,
+		ClaimsTransforms:       c.OAuth2.ClaimsTransforms,
+		WebFingerDomains:       c.WebFinger.Domains,
+		DiscoveryFields:        c.Discovery.ExtraFields,
+		Signer:                 deps.signer,
+		Events:                 deps.events,
+	}
+	for _, scope := range c.OAuth2.CustomScopes {
+		serverConfig.CustomScopes = append(serverConfig.CustomScopes, server.CustomScope{
+			Name:        scope.Name,
+			Description: scope.Description,
+			Claims:      scope.Claims,
+		})
+	}
+	if c.OAuth2.TokenWebhook != nil {
+		tokenWebhook, err := c.OAuth2.TokenWebhook.ToServerConfig()
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("config token webhook", "url", tokenWebhook.URL, "fail_open", tokenWebhook.FailOpen)
+		serverConfig.TokenWebhook = tokenWebhook
+	}
+	if c.OAuth2.AuthorizationPolicy != nil {
+		policy, err := c.OAuth2.AuthorizationPolicy.ToServerConfig()
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("config authorization policy", "url", policy.URL, "fail_open", policy.FailOpen)
+		serverConfig.AuthorizationPolicy = policy
+	}
+	if c.Expiry.SigningKeys != "" {
+		signingKeys, err := time.ParseDuration(c.Expiry.SigningKeys)
+		if err != nil {
+			return nil, fmt.Errorf("invalid config value %q for signing keys expiry: %v", c.Expiry.SigningKeys, err)
+		}
+		logger.Info("config signing keys", "expire_after", signingKeys)
+		serverConfig.RotateKeysAfter = signingKeys
+	}
+	if c.Expiry.IDTokens != "" {
+		idTokens, err := time.ParseDuration(c.Expiry.IDTokens)
+		if err != nil {
+			return nil, fmt.Errorf("invalid config value %q for id token expiry: %v", c.Expiry.IDTokens, err)
+		}
+		logger.Info("config id tokens", "valid_for", idTokens)
+		serverConfig.IDTokensValidFor = idTokens
+	}
+	if c.Expiry.VerificationKeys != "" {
+		verificationKeys, err := time.ParseDuration(c.Expiry.VerificationKeys)
+		if err != nil {
+			return nil, fmt.Errorf("invalid config value %q for verification keys expiry: %v", c.Expiry.VerificationKeys, err)
+		}
+		idTokens := serverConfig.IDTokensValidFor
+		if idTokens == 0 {
+			idTokens = 24 * time.Hour
+		}
+		if verificationKeys < idTokens {
+			logger.Warn("verification keys expire before id tokens, tokens signed shortly before a key rotation will fail verification",
+				"verification_keys_valid_for", verificationKeys, "id_tokens_valid_for", idTokens)
+		}
+		logger.Info("config verification keys", "valid_for", verificationKeys)
+		serverConfig.VerificationKeysValidFor = verificationKeys
+	}
+	if c.Expiry.AuthRequests != "" {
+		authRequests, err := time.ParseDuration(c.Expiry.AuthRequests)
+		if err != nil {
+			return nil, fmt.Errorf("invalid config value %q for auth request expiry: %v", c.Expiry.AuthRequests, err)
+		}
+		logger.Info("config auth requests", "valid_for", authRequests)
+		serverConfig.AuthRequestsValidFor = authRequests
+	}
+	if c.Web.Cookies != nil {
+		cookies, err := c.Web.Cookies.ToServerConfig()
+		if err != nil {
+			return nil, err
+		}
+		serverConfig.Cookies = cookies
+		logger.Info("config cookies", "same_site", c.Web.Cookies.SameSite, "domain", c.Web.Cookies.Domain, "max_age", c.Web.Cookies.MaxAge)
+	}
+	if c.Web.CSRFProtection {
+		serverConfig.CSRFProtection = true
+		logger.Info("config csrf protection enabled")
+	}
+	if c.OAuth2.StatelessAuthRequests != nil {
+		stateless, err := c.OAuth2.StatelessAuthRequests.ToServerConfig()
+		if err != nil {
+			return nil, err
+		}
+		serverConfig.StatelessAuthRequests = stateless
+		logger.Info("config stateless auth requests", "keys", len(stateless.Keys))
+	}
+	if c.OAuth2.DeviceFlow.PollInterval != "" {
+		pollInterval, err := time.ParseDuration(c.OAuth2.DeviceFlow.PollInterval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid config value %q for device flow poll interval: %v", c.OAuth2.DeviceFlow.PollInterval, err)
+		}
+		logger.Info("config device flow", "poll_interval", pollInterval)
+		serverConfig.DevicePollInterval = pollInterval
+	}
+	serverConfig.DeviceUserCode = server.UserCodeFormat{
+		Length:  c.OAuth2.DeviceFlow.UserCodeLength,
+		Charset: c.OAuth2.DeviceFlow.UserCodeCharset,
+	}
+	if c.Expiry.DeviceRequests != "" {
+		deviceRequests, err := time.ParseDuration(c.Expiry.DeviceRequests)
+		if err != nil {
+			return nil, fmt.Errorf("invalid config value %q for device request expiry: %v", c.Expiry.AuthRequests, err)
+		}
+		logger.Info("config device requests", "valid_for", deviceRequests)
+		serverConfig.DeviceRequestsValidFor = deviceRequests
+	}
+	if err := c.GC.ApplyTo(&serverConfig); err != nil {
+		return nil, err
+	}
+	if c.GC.Interval != "" || len(c.GC.Intervals) > 0 || c.GC.Jitter != "" || c.GC.BatchSize > 0 {
+		logger.Info("config garbage collection", "interval", serverConfig.GCFrequency, "intervals", serverConfig.GCIntervals,
+			"jitter", serverConfig.GCJitter, "batch_size", serverConfig.GCBatchSize)
+	}
+	if c.SMTP != nil {
+		mailer, err := c.SMTP.NewMailer()
+		if err != nil {
+			return nil, err
+		}
+		if c.PasswordReset != nil {
+			if serverConfig.PasswordReset, err = c.PasswordReset.ToServerConfig(mailer); err != nil {
+				return nil, err
+			}
+		}
+		if c.Registration != nil {
+			if serverConfig.Registration, err = c.Registration.ToServerConfig(mailer); err != nil {
+				return nil, err
+			}
+		}
+		if c.EmailVerification != nil {
+			if serverConfig.EmailVerification, err = c.EmailVerification.ToServerConfig(mailer); err != nil {
+				return nil, err
+			}
+		}
+		logger.Info("config e-mails", "smtp_host", c.SMTP.Host, "password_reset", c.PasswordReset != nil,
+			"registration", c.Registration != nil, "email_verification", c.EmailVerification != nil)
+	}
+	if c.Captcha != nil {
+		serverConfig.Captcha = c.Captcha.ToServerConfig()
+		logger.Info("config captcha", "provider", c.Captcha.Provider)
+	}
+	if c.PasswordLockout != nil {
+		lockout, err := c.PasswordLockout.ToServerConfig()
+		if err != nil {
+			return nil, err
+		}
+		serverConfig.PasswordLockout = lockout
+		logger.Info("config password lockout", "threshold", c.PasswordLockout.Threshold,
+			"per_instance_ip_threshold", c.PasswordLockout.PerInstanceIPThreshold)
+	}
+	if c.PasswordPolicy != nil {
+		policy, err := c.PasswordPolicy.ToServerConfig()
+		if err != nil {
+			return nil, err
+		}
+		serverConfig.PasswordPolicy = policy
+		logger.Info("config password policy", "min_length", c.PasswordPolicy.MinLength,
+			"min_character_classes", c.PasswordPolicy.MinCharacterClasses, "check_breached", c.PasswordPolicy.CheckBreached)
+	}
+	if c.SecurityEvents.FailedLoginThreshold > 0 {
+		serverConfig.FailedLoginThreshold = c.SecurityEvents.FailedLoginThreshold
+		logger.Info("config failed login threshold", "threshold", c.SecurityEvents.FailedLoginThreshold)
+	}
+	if c.RateLimit != nil {
+		serverConfig.RateLimit = c.RateLimit.ToServerConfig()
+		logger.Info("config rate limit", "per_ip", c.RateLimit.PerIP != nil, "per_client", c.RateLimit.PerClient != nil,
+			"per_device_code", c.RateLimit.PerDeviceCode != nil)
+	}
+	if c.AuditLog != nil {
+		auditLog, err := c.AuditLog.ToServerConfig()
+		if err != nil {
+			return nil, err
+		}
+		serverConfig.AuditLog = auditLog
+		sinks := make([]string, 0, len(c.AuditLog.Sinks))
+		for _, sink := range c.AuditLog.Sinks {
+			sinks = append(sinks, sink.Type)
+		}
+		logger.Info("config audit log", "sinks", sinks)
+	}
+	if c.HomeRealmDiscovery != nil {
+		serverConfig.HomeRealmDiscovery = c.HomeRealmDiscovery.ToServerConfig()
+		logger.Info("config home realm discovery", "domains", len(c.HomeRealmDiscovery.Domains))
+	}
+	refreshTokenPolicy, err := server.NewRefreshTokenPolicy(
+		logger,
+		c.Expiry.RefreshTokens.DisableRotation,
+		c.Expiry.RefreshTokens.ValidIfNotUsedFor,
+		c.Expiry.RefreshTokens.AbsoluteLifetime,
+		c.Expiry.RefreshTokens.ReuseInterval,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid refresh token expiration policy config: %v", err)
+	}
+
+	serverConfig.RefreshTokenPolicy = refreshTokenPolicy
+
+	if c.Expiry.RefreshTokens.MaxSessionsPerClient != 0 {
+		if c.Expiry.RefreshTokens.MaxSessionsPerClient < 0 {
+			return nil, fmt.Errorf("invalid config value %d for refresh tokens max sessions per client", c.Expiry.RefreshTokens.MaxSessionsPerClient)
+		}
+		logger.Info("config refresh tokens", "max_sessions_per_client", c.Expiry.RefreshTokens.MaxSessionsPerClient)
+		serverConfig.MaxSessionsPerClient = c.Expiry.RefreshTokens.MaxSessionsPerClient
+	}
+
+	serverConfig.RealIPHeader = c.Web.ClientRemoteIP.Header
+	serverConfig.TrustedRealIPCIDRs, err = c.Web.ClientRemoteIP.ParseTrustedProxies()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse client remote IP settings: %v", err)
+	}
+
+	if len(c.Issuers) > 0 {
+		// The default issuer keeps its codes and tokens apart from those of
+		// the additional issuers too.
+		serverConfig.Storage = storage.WithIssuer(s, "", c.DefaultIssuer.Clients, c.DefaultIssuer.Connectors)
+		logger.Info("config default issuer", "clients", c.DefaultIssuer.Clients, "connectors", c.DefaultIssuer.Connectors)
+	}
+
+	serv, err := server.NewServer(ctx, serverConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize server: %v", err)
+	}
+
+	srvs := &servers{
+		serv:     serv,
+		handler:  serv,
+		api:      server.NewAPI(s, logger, version, serv),
+		registry: registry,
+	}
+	if len(c.Issuers) > 0 {
+		issuerServers := []*server.Server{serv}
+		for _, issuer := range c.Issuers {
+			issuerConfig := serverConfig
+			issuerConfig.Issuer = issuer.Issuer
+			issuerConfig.Storage = storage.WithIssuer(s, issuer.Issuer, issuer.Clients, issuer.Connectors)
+			if issuer.Frontend != nil {
+				issuerConfig.Web = *issuer.Frontend
+			}
+			issuerConfig.WebFingerDomains = issuer.WebFinger.Domains
+			// HTTP metrics are only collected for the default issuer, since
+			// they can't be registered more than once.
+			issuerConfig.PrometheusRegistry = nil
+
+			issuerServ, err := server.NewServer(ctx, issuerConfig)
+			if err != nil {
+				return nil, fmt.Errorf("failed to initialize server for issuer %q: %v", issuer.Issuer, err)
+			}
+			logger.Info("config issuer", "issuer", issuer.Issuer, "clients", issuer.Clients, "connectors", issuer.Connectors)
+			issuerServers = append(issuerServers, issuerServ)
+		}
+		srvs.handler = server.NewIssuerMux(issuerServers...)
+	}
+	if c.Web.ClientRemoteIP.ForwardedHeaders {
+		logger.Info("config forwarded headers", "trusted_proxies", c.Web.ClientRemoteIP.TrustedProxies)
+		srvs.handler = server.NewForwardedHandler(srvs.handler, serverConfig.TrustedRealIPCIDRs)
+	}
+	return srvs, nil
+}
+
+// resolveStaticClient reads the ID and secret of a static client from the
+// environment if configured, and validates the client.
+func resolveStaticClient(client *storage.Client) error {
+	if client.Name == "" {
+		return fmt.Errorf("Name field is required for a client")
+	}
+	if client.ID == "" && client.IDEnv == "" {
+		return fmt.Errorf("ID or IDEnv field is required for a client")
+	}
+	if client.IDEnv != "" {
+		if client.ID != "" {
+			return fmt.Errorf("ID and IDEnv fields are exclusive for client %q", client.ID)
+		}
+		client.ID = os.Getenv(client.IDEnv)
+	}
+	if client.Secret == "" && client.SecretEnv == "" && !client.Public {
+		return fmt.Errorf("Secret or SecretEnv field is required for client %q", client.ID)
+	}
+	if client.SecretEnv != "" {
+		if client.Secret != "" {
+			return fmt.Errorf("Secret and SecretEnv fields are exclusive for client %q", client.ID)
+		}
+		client.Secret = os.Getenv(client.SecretEnv)
+	}
+	if err := server.ValidateRefreshTokenPolicy(client.RefreshTokenPolicy); err != nil {
+		return fmt.Errorf("client %q: %v", client.ID, err)
+	}
+	if err := server.ValidateTokenExpiry(client.TokenExpiry); err != nil {
+		return fmt.Errorf("client %q: %v", client.ID, err)
+	}
+	if err := server.ValidateGroupsFilter(client.GroupsFilter); err != nil {
+		return fmt.Errorf("client %q: %v", client.ID, err)
+	}
+	if err := server.ValidateClaimsTransforms(client.ClaimsTransforms); err != nil {
+		return fmt.Errorf("client %q: %v", client.ID, err)
+	}
+	if err := server.ValidateRedirectURIPatterns(client.RedirectURIPatterns); err != nil {
+		return fmt.Errorf("client %q: %v", client.ID, err)
+	}
+	if err := server.ValidateAllowedOrigins(client.AllowedOrigins); err != nil {
+		return fmt.Errorf("client %q: %v", client.ID, err)
+	}
+	return nil
+}
+
 func applyConfigOverrides(options serveOptions, config *Config) {
 	if options.webHTTPAddr != "" {
 		config.Web.HTTP = options.webHTTPAddr
@@ -575,17 +1021,54 @@ func pprofHandler(router *http.ServeMux) {
 	router.HandleFunc("/debug/pprof/trace", pprof.Trace)
 }
 
-// newTLSReloader returns a [tls.Config] with GetCertificate or GetConfigForClient set
-// to reload certificates from the given paths on SIGHUP or on file creates (atomic update via rename).
-func newTLSReloader(logger *slog.Logger, certFile, keyFile, caFile string, baseConfig *tls.Config) (*tls.Config, error) {
+// tlsVersions are the supported TLS versions by their config name.
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// newBaseTLSConfig returns the TLS settings of a listener, which are TLS 1.2
+// or later and the default cipher suites unless configured otherwise.
+func newBaseTLSConfig(minVersion, maxVersion string, cipherSuites, curves []string) (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:               tls.VersionTLS12,
+		PreferServerCipherSuites: true,
+	}
+	if minVersion != "" {
+		config.MinVersion = tlsVersions[minVersion]
+	}
+	// The default max version is whatever Go defaults to.
+	if maxVersion != "" {
+		config.MaxVersion = tlsVersions[maxVersion]
+	}
+	var err error
+	if config.CipherSuites, err = tlsCipherSuites(cipherSuites); err != nil {
+		return nil, err
+	}
+	if config.CurvePreferences, err = tlsCurvePreferences(curves); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// tlsReloadSettle is how long the TLS reloader waits after files changed
+// before reloading them.
+const tlsReloadSettle = time.Second
+
+// newTLSReloader loads the TLS config of a listener and reloads it when the
+// files change or on SIGHUP, publishing the expiry of its certificates. The
+// first certificate is served unless clients ask for the name (SNI) of
+// another one.
+func newTLSReloader(logger *slog.Logger, listener string, certs []TLSCertificate, caFile string, baseConfig *tls.Config) (*tls.Config, error) {
 	// trigger reload on channel
 	sigc := make(chan os.Signal, 1)
 	signal.Notify(sigc, syscall.SIGHUP)
 
 	// files to watch
-	watchFiles := map[string]struct{}{
-		certFile: {},
-		keyFile:  {},
+	watchFiles := make(map[string]struct{})
+	for _, cert := range certs {
+		watchFiles[cert.Cert] = struct{}{}
+		watchFiles[cert.Key] = struct{}{}
 	}
 	if caFile != "" {
 		watchFiles[caFile] = struct{}{}
@@ -616,10 +1099,11 @@ func newTLSReloader(logger *slog.Logger, certFile, keyFile, caFile string, baseC
 	}
 
 	// load once outside the goroutine so we can return an error on misconfig
-	initialConfig, err := loadTLSConfig(certFile, keyFile, caFile, baseConfig)
+	initialConfig, err := loadTLSConfig(certs, caFile, baseConfig)
 	if err != nil {
 		return nil, fmt.Errorf("load TLS config: %v", err)
 	}
+	recordTLSCertificateExpiry(logger, listener, initialConfig)
 
 	// stored version of current tls config
 	ptr := &atomic.Pointer[tls.Config]{}
@@ -627,24 +1111,40 @@ func newTLSReloader(logger *slog.Logger, certFile, keyFile, caFile string, baseC
 
 	// start background worker to reload certs
 	go func() {
+		// Reloads wait for changes to settle, so that a cert and key written
+		// one after the other are loaded together.
+		var settle <-chan time.Time
 	loop:
 		for {
 			select {
 			case sig := <-sigc:
 				logger.Debug("reloading cert from signal", "signal", sig)
 			case evt := <-watcher.Events:
-				if _, ok := watchFiles[evt.Name]; !ok || !evt.Has(fsnotify.Create) {
+				// Kubernetes updates mounted secrets by swapping the ..data
+				// symlink, which cert-manager renewals go through.
+				_, watched := watchFiles[evt.Name]
+				if !watched && !strings.HasPrefix(filepath.Base(evt.Name), "..") {
+					continue loop
+				}
+				if !evt.Has(fsnotify.Create) && !evt.Has(fsnotify.Write) && !evt.Has(fsnotify.Rename) {
 					continue loop
 				}
 				logger.Debug("reloading cert from fsnotify", "event", evt.Name, "operation", evt.Op.String())
+				settle = time.After(tlsReloadSettle)
+				continue loop
+			case <-settle:
+				settle = nil
 			case err := <-watcher.Errors:
 				logger.Error("TLS reloader watch", "err", err)
 			}
 
-			loaded, err := loadTLSConfig(certFile, keyFile, caFile, baseConfig)
+			loaded, err := loadTLSConfig(certs, caFile, baseConfig)
 			if err != nil {
-				logger.Error("reload TLS config", "err", err)
+				// keep serving the previous certificate
+				logger.Error("reload TLS config", "listener", listener, "err", err)
+				continue loop
 			}
+			recordTLSCertificateExpiry(logger, listener, loaded)
 			ptr.Store(loaded)
 		}
 	}()
@@ -652,23 +1152,41 @@ func newTLSReloader(logger *slog.Logger, certFile, keyFile, caFile string, baseC
 	// https://pkg.go.dev/crypto/tls#baseConfig
 	// Server configurations must set one of Certificates, GetCertificate or GetConfigForClient.
 	if caFile != "" {
-		// grpc will use this via tls.Server for mTLS
+		// the client CA is part of the config, so mTLS listeners swap it
+		// whole, picking the certificate among its Certificates
 		initialConfig.GetConfigForClient = func(chi *tls.ClientHelloInfo) (*tls.Config, error) { return ptr.Load(), nil }
 	} else {
-		// net/http only uses Certificates or GetCertificate
-		initialConfig.GetCertificate = func(chi *tls.ClientHelloInfo) (*tls.Certificate, error) { return &ptr.Load().Certificates[0], nil }
+		initialConfig.GetCertificate = func(chi *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return selectCertificate(ptr.Load().Certificates, chi), nil
+		}
 	}
 	return initialConfig, nil
 }
 
-// loadTLSConfig loads the given file paths into a [tls.Config]
-func loadTLSConfig(certFile, keyFile, caFile string, baseConfig *tls.Config) (*tls.Config, error) {
-	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
-	if err != nil {
-		return nil, fmt.Errorf("loading TLS keypair: %v", err)
+// selectCertificate returns the first certificate supported by a client,
+// usually the one matching the server name it asked for, or the first
+// certificate.
+func selectCertificate(certs []tls.Certificate, chi *tls.ClientHelloInfo) *tls.Certificate {
+	if len(certs) > 1 {
+		for i := range certs {
+			if chi.SupportsCertificate(&certs[i]) == nil {
+				return &certs[i]
+			}
+		}
 	}
+	return &certs[0]
+}
+
+// loadTLSConfig loads the given file paths into a [tls.Config]
+func loadTLSConfig(certs []TLSCertificate, caFile string, baseConfig *tls.Config) (*tls.Config, error) {
 	loadedConfig := baseConfig.Clone() // copy
-	loadedConfig.Certificates = []tls.Certificate{cert}
+	for _, c := range certs {
+		cert, err := tls.LoadX509KeyPair(c.Cert, c.Key)
+		if err != nil {
+			return nil, fmt.Errorf("loading TLS keypair %s: %v", c.Cert, err)
+		}
+		loadedConfig.Certificates = append(loadedConfig.Certificates, cert)
+	}
 	if caFile != "" {
 		cPool := x509.NewCertPool()
 		clientCert, err := os.ReadFile(caFile)
@@ -685,7 +1203,51 @@ func loadTLSConfig(certFile, keyFile, caFile string, baseConfig *tls.Config) (*t
 	return loadedConfig, nil
 }
 
+// recordTLSCertificateExpiry publishes the earliest notAfter date of the
+// certificates served by a listener, so that alerts can fire before one
+// expires.
+func recordTLSCertificateExpiry(logger *slog.Logger, listener string, config *tls.Config) {
+	var expiry time.Time
+	for _, cert := range config.Certificates {
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			logger.Error("parse TLS certificate", "listener", listener, "err", err)
+			return
+		}
+		if expiry.IsZero() || leaf.NotAfter.Before(expiry) {
+			expiry = leaf.NotAfter
+		}
+	}
+	tlsCertificateExpiry.WithLabelValues(listener).Set(float64(expiry.Unix()))
+}
+
 // recordBuildInfo publishes information about Dex version and runtime info through an info metric (gauge).
 func recordBuildInfo() {
 	buildInfo.WithLabelValues(version, runtime.Version(), fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH)).Set(1)
 }
+
+// grpcHealthInterval is how often the gRPC health service picks up the result
+// of the health checks.
+const grpcHealthInterval = 5 * time.Second
+
+// updateGRPCHealth mirrors the health checks into the gRPC health service
+// until the context is canceled.
+func updateGRPCHealth(ctx context.Context, healthSrv *health.Server, healthChecker gosundheit.Health) {
+	ticker := time.NewTicker(grpcHealthInterval)
+	defer ticker.Stop()
+
+	for {
+		status := healthpb.HealthCheckResponse_NOT_SERVING
+		if healthChecker.IsHealthy() {
+			status = healthpb.HealthCheckResponse_SERVING
+		}
+		healthSrv.SetServingStatus("", status)
+		healthSrv.SetServingStatus(api.Dex_ServiceDesc.ServiceName, status)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
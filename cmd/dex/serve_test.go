@@ -1,29 +1,257 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io"
 	"log/slog"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	gosundheit "github.com/AppsFlyer/go-sundheit"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/dexidp/dex/api/v2"
+	"github.com/dexidp/dex/server"
 )
 
 func TestNewLogger(t *testing.T) {
 	t.Run("JSON", func(t *testing.T) {
-		logger, err := newLogger(slog.LevelInfo, "json")
+		logger, err := newLogger(slog.LevelInfo, "json", nil)
 		require.NoError(t, err)
 		require.NotEqual(t, (*slog.Logger)(nil), logger)
 	})
 
 	t.Run("Text", func(t *testing.T) {
-		logger, err := newLogger(slog.LevelError, "text")
+		logger, err := newLogger(slog.LevelError, "text", nil)
 		require.NoError(t, err)
 		require.NotEqual(t, (*slog.Logger)(nil), logger)
 	})
 
 	t.Run("Unknown", func(t *testing.T) {
-		logger, err := newLogger(slog.LevelError, "gofmt")
+		logger, err := newLogger(slog.LevelError, "gofmt", nil)
 		require.Error(t, err)
 		require.Equal(t, "log format is not one of the supported values (json, text): gofmt", err.Error())
 		require.Equal(t, (*slog.Logger)(nil), logger)
 	})
 }
+
+func TestLogRedaction(t *testing.T) {
+	logLine := func(t *testing.T, c LogRedaction) map[string]any {
+		redact, err := newRedactor(&c)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		logger := slog.New(newRequestContextHandler(redactingHandler{slog.NewJSONHandler(&buf, nil), redact}))
+		ctx := context.WithValue(context.Background(), server.RequestKeyRemoteIP, "192.0.2.1")
+		logger.With("user_id", "1").InfoContext(ctx, "login successful",
+			"email", "jane@example.com", "connector_id", "ldap", slog.Group("claims", "username", "jane"))
+
+		var line map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+		return line
+	}
+
+	t.Run("Hash", func(t *testing.T) {
+		line := logLine(t, LogRedaction{HashKey: "key"})
+		require.Regexp(t, `^hmac:[0-9a-f]{16}$`, line["email"])
+		require.Regexp(t, `^hmac:[0-9a-f]{16}$`, line["user_id"])
+		require.Regexp(t, `^hmac:[0-9a-f]{16}$`, line["client_remote_addr"])
+		require.Regexp(t, `^hmac:[0-9a-f]{16}$`, line["claims"].(map[string]any)["username"])
+		require.Equal(t, "ldap", line["connector_id"])
+
+		// Hashes with the same key can be correlated.
+		require.Equal(t, line["email"], logLine(t, LogRedaction{HashKey: "key"})["email"])
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		line := logLine(t, LogRedaction{Mode: "remove", Fields: []string{"emails"}})
+		require.Equal(t, "REDACTED", line["email"])
+		require.Equal(t, "1", line["user_id"])
+		require.Equal(t, "192.0.2.1", line["client_remote_addr"])
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := newRedactor(&LogRedaction{Mode: "encrypt"})
+		require.Error(t, err)
+		_, err = newRedactor(&LogRedaction{Fields: []string{"names"}})
+		require.Error(t, err)
+	})
+}
+
+func TestUpdateGRPCHealth(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	healthSrv := health.NewServer()
+	updateGRPCHealth(ctx, healthSrv, gosundheit.New())
+
+	for _, service := range []string{"", api.Dex_ServiceDesc.ServiceName} {
+		resp, err := healthSrv.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		require.NoError(t, err)
+		require.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status, service)
+	}
+}
+
+// writeTestCertificate writes a self-signed certificate for name expiring at
+// notAfter and its key to dir, replacing the files atomically.
+func writeTestCertificate(t *testing.T, dir, name string, notAfter time.Time) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	write := func(name, blockType string, b []byte) string {
+		path := filepath.Join(dir, name)
+		tmp := path + ".tmp"
+		require.NoError(t, os.WriteFile(tmp, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: b}), 0o600))
+		require.NoError(t, os.Rename(tmp, path))
+		return path
+	}
+	keyFile = write(name+".key", "EC PRIVATE KEY", keyDER)
+	certFile = write(name+".crt", "CERTIFICATE", der)
+	return certFile, keyFile
+}
+
+func TestTLSCertificateExpiry(t *testing.T) {
+	dir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	notAfter := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	certFile, keyFile := writeTestCertificate(t, dir, "localhost", notAfter)
+	_, err := newTLSReloader(logger, "test", []TLSCertificate{{Cert: certFile, Key: keyFile}}, "", &tls.Config{})
+	require.NoError(t, err)
+
+	gauge := tlsCertificateExpiry.WithLabelValues("test")
+	require.Equal(t, float64(notAfter.Unix()), testutil.ToFloat64(gauge))
+
+	// Renewed certificates are published once reloaded.
+	renewed := notAfter.Add(30 * 24 * time.Hour)
+	writeTestCertificate(t, dir, "localhost", renewed)
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(gauge) == float64(renewed.Unix())
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestTLSCertificateSNI(t *testing.T) {
+	dir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	notAfter := time.Now().Add(24 * time.Hour)
+	defaultCert, defaultKey := writeTestCertificate(t, dir, "dex.example.com", notAfter)
+	otherCert, otherKey := writeTestCertificate(t, dir, "dex.example.org", notAfter.Add(-time.Hour))
+	config, err := newTLSReloader(logger, "sni", []TLSCertificate{
+		{Cert: defaultCert, Key: defaultKey},
+		{Cert: otherCert, Key: otherKey},
+	}, "", &tls.Config{})
+	require.NoError(t, err)
+
+	serverName := func(name string) string {
+		cert, err := config.GetCertificate(&tls.ClientHelloInfo{
+			ServerName:        name,
+			SupportedVersions: []uint16{tls.VersionTLS13},
+			SignatureSchemes:  []tls.SignatureScheme{tls.ECDSAWithP256AndSHA256},
+		})
+		require.NoError(t, err)
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		require.NoError(t, err)
+		return leaf.DNSNames[0]
+	}
+	require.Equal(t, "dex.example.com", serverName("dex.example.com"))
+	require.Equal(t, "dex.example.org", serverName("dex.example.org"))
+	require.Equal(t, "dex.example.com", serverName("unknown.example.net"))
+
+	// The certificate expiring first is published.
+	gauge := tlsCertificateExpiry.WithLabelValues("sni")
+	require.Equal(t, float64(notAfter.Add(-time.Hour).Unix()), testutil.ToFloat64(gauge))
+}
+
+func TestTLSClientCA(t *testing.T) {
+	dir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	notAfter := time.Now().Add(24 * time.Hour)
+	serverCert, serverKey := writeTestCertificate(t, dir, "dex.example.com", notAfter)
+	// The self-signed certificate of the client is its own CA.
+	clientCert, clientKey := writeTestCertificate(t, dir, "client.example.com", notAfter)
+
+	baseConfig, err := newBaseTLSConfig("1.2", "1.2", []string{"TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256"}, []string{"P384"})
+	require.NoError(t, err)
+	config, err := newTLSReloader(logger, "mtls", []TLSCertificate{{Cert: serverCert, Key: serverKey}}, clientCert, baseConfig)
+	require.NoError(t, err)
+
+	handshake := func(certs []tls.Certificate, curves ...tls.CurveID) (tls.ConnectionState, error) {
+		clientConn, serverConn := net.Pipe()
+		defer clientConn.Close()
+		go func() {
+			defer serverConn.Close()
+			tls.Server(serverConn, config).Handshake()
+		}()
+		conn := tls.Client(clientConn, &tls.Config{
+			ServerName:         "dex.example.com",
+			InsecureSkipVerify: true,
+			Certificates:       certs,
+			CurvePreferences:   curves,
+		})
+		if err := conn.Handshake(); err != nil {
+			return tls.ConnectionState{}, err
+		}
+		return conn.ConnectionState(), nil
+	}
+
+	_, err = handshake(nil)
+	require.Error(t, err, "clients without a certificate are rejected")
+
+	cert, err := tls.LoadX509KeyPair(clientCert, clientKey)
+	require.NoError(t, err)
+	state, err := handshake([]tls.Certificate{cert})
+	require.NoError(t, err)
+	require.Equal(t, uint16(tls.VersionTLS12), state.Version)
+	require.Equal(t, tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305, state.CipherSuite)
+
+	_, err = handshake([]tls.Certificate{cert}, tls.X25519, tls.CurveP256)
+	require.Error(t, err, "clients without the configured curves are rejected")
+}
+
+func TestNewBaseTLSConfig(t *testing.T) {
+	config, err := newBaseTLSConfig("", "", nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, uint16(tls.VersionTLS12), config.MinVersion)
+	require.Equal(t, defaultTLSCipherSuites, config.CipherSuites)
+	require.Nil(t, config.CurvePreferences)
+
+	config, err = newBaseTLSConfig("1.3", "", []string{"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"}, []string{"X25519", "P256"})
+	require.NoError(t, err)
+	require.Equal(t, uint16(tls.VersionTLS13), config.MinVersion)
+	require.Equal(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384}, config.CipherSuites)
+	require.Equal(t, []tls.CurveID{tls.X25519, tls.CurveP256}, config.CurvePreferences)
+
+	for _, suites := range [][]string{{"TLS_RSA_WITH_RC4_128_SHA"}, {"TLS_AES_128_GCM_SHA256"}, {"unknown"}} {
+		_, err := newBaseTLSConfig("", "", suites, nil)
+		require.Error(t, err, "%v", suites)
+	}
+	_, err = newBaseTLSConfig("", "", nil, []string{"P224"})
+	require.Error(t, err)
+}
@@ -0,0 +1,479 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+
+	"github.com/dexidp/dex/storage"
+)
+
+// storageDumpVersion is the version of the format written by "dex storage
+// export". It is increased whenever a change breaks reading older dumps.
+const storageDumpVersion = 1
+
+var storageDumpFormats = []string{"ndjson", "json"}
+
+// storageDump holds the objects of a storage. Auth requests and auth codes are
+// short lived and not included.
+type storageDump struct {
+	Version int `json:"version"`
+
+	Keys            *storage.Keys             `json:"keys,omitempty"`
+	Connectors      []storage.Connector       `json:"connectors,omitempty"`
+	Clients         []storage.Client          `json:"clients,omitempty"`
+	Passwords       []storage.Password        `json:"passwords,omitempty"`
+	RefreshTokens   []storage.RefreshToken    `json:"refreshTokens,omitempty"`
+	OfflineSessions []storage.OfflineSessions `json:"offlineSessions,omitempty"`
+	Webhooks        []storage.Webhook         `json:"webhooks,omitempty"`
+	DeviceRequests  []storage.DeviceRequest   `json:"deviceRequests,omitempty"`
+	DeviceTokens    []storage.DeviceToken     `json:"deviceTokens,omitempty"`
+}
+
+// storageRecord is a line of a dump in the NDJSON format, following a line
+// with the version of the format.
+type storageRecord struct {
+	Kind   string          `json:"kind"`
+	Object json.RawMessage `json:"object"`
+}
+
+type storageExportOptions struct {
+	config string
+	output string
+	format string
+}
+
+type storageImportOptions struct {
+	config       string
+	input        string
+	skipExisting bool
+}
+
+func commandStorage() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "storage",
+		Short: "Export and import the objects of a storage, for example to migrate to another backend",
+		Run: func(cmd *cobra.Command, _ []string) {
+			cmd.Help()
+			os.Exit(2)
+		},
+	}
+	cmd.AddCommand(commandStorageExport())
+	cmd.AddCommand(commandStorageImport())
+	return cmd
+}
+
+func commandStorageExport() *cobra.Command {
+	options := storageExportOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "export [flags] [config file]",
+		Short: "Write all objects of the storage configured in a config file",
+		Long: `Write the keys, connectors, clients, passwords, refresh tokens, offline
+sessions, webhooks and device flow objects of the storage configured in a
+config file, including secrets. Static clients, connectors and passwords of
+the config file aren't included. Stop dex before exporting, so the dump is
+consistent.`,
+		Example: "dex storage export config.yaml > dex.ndjson",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			options.config = args[0]
+			return runStorageExport(options)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&options.output, "output", "o", "-", "File to write to, - for standard output")
+	flags.StringVar(&options.format, "format", "ndjson", "Format of the dump, ndjson or json")
+
+	return cmd
+}
+
+func commandStorageImport() *cobra.Command {
+	options := storageImportOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "import [flags] [config file] [dump file]",
+		Short: "Create the objects of a dump in the storage configured in a config file",
+		Long: `Create the objects written by "dex storage export" in the storage configured
+in a config file, which may use another backend than the exported one. Both
+formats are detected. The signing keys of the storage are replaced by the ones
+of the dump.`,
+		Example: "dex storage import config.yaml dex.ndjson",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			options.config = args[0]
+			options.input = args[1]
+			return runStorageImport(options)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVar(&options.skipExisting, "skip-existing", false, "Skip objects which already exist instead of failing")
+
+	return cmd
+}
+
+// openStorage opens the storage configured in a config file.
+func openStorage(configFile string) (storage.Storage, *slog.Logger, error) {
+	configData, err := readConfigFile(configFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read config file %s: %v", configFile, err)
+	}
+
+	var c Config
+	if err := yaml.Unmarshal(configData, &c); err != nil {
+		return nil, nil, fmt.Errorf("error parse config file %s: %v", configFile, err)
+	}
+
+	redact, err := newRedactor(c.Logger.Redact)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid config: %v", err)
+	}
+	logger, err := newLogger(c.Logger.Level, c.Logger.Format, redact)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid config: %v", err)
+	}
+	if c.Storage.Config == nil {
+		return nil, nil, errors.New("invalid config: no storage supplied in config file")
+	}
+
+	s, err := c.Storage.Config.Open(logger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize storage: %v", err)
+	}
+	return s, logger, nil
+}
+
+func runStorageExport(options storageExportOptions) error {
+	s, _, err := openStorage(options.config)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	dump, err := exportStorage(s)
+	if err != nil {
+		return err
+	}
+
+	w := io.Writer(os.Stdout)
+	if options.output != "-" {
+		f, err := os.Create(options.output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	bw := bufio.NewWriter(w)
+	if err := writeStorageDump(bw, dump, options.format); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+func runStorageImport(options storageImportOptions) error {
+	r := io.Reader(os.Stdin)
+	if options.input != "-" {
+		f, err := os.Open(options.input)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	dump, err := readStorageDump(bufio.NewReader(r))
+	if err != nil {
+		return fmt.Errorf("parsing %s: %v", options.input, err)
+	}
+
+	s, logger, err := openStorage(options.config)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	return importStorage(context.Background(), s, dump, options.skipExisting, logger)
+}
+
+// exportStorage reads all objects of a storage. Offline sessions and device
+// tokens can't be listed, so only the ones of refresh tokens and device
+// requests are included.
+func exportStorage(s storage.Storage) (*storageDump, error) {
+	dump := &storageDump{Version: storageDumpVersion}
+
+	keys, err := s.GetKeys()
+	if err != nil && err != storage.ErrNotFound {
+		return nil, fmt.Errorf("get keys: %v", err)
+	}
+	if keys.SigningKey != nil || len(keys.VerificationKeys) > 0 || len(keys.IssuerKeys) > 0 {
+		dump.Keys = &keys
+	}
+
+	if dump.Connectors, err = s.ListConnectors(); err != nil {
+		return nil, fmt.Errorf("list connectors: %v", err)
+	}
+	if dump.Clients, err = s.ListClients(); err != nil {
+		return nil, fmt.Errorf("list clients: %v", err)
+	}
+	if dump.Passwords, err = s.ListPasswords(); err != nil {
+		return nil, fmt.Errorf("list passwords: %v", err)
+	}
+	if dump.RefreshTokens, err = s.ListRefreshTokens(); err != nil {
+		return nil, fmt.Errorf("list refresh tokens: %v", err)
+	}
+	if dump.Webhooks, err = s.ListWebhooks(); err != nil {
+		return nil, fmt.Errorf("list webhooks: %v", err)
+	}
+	if dump.DeviceRequests, err = s.ListDeviceRequests(); err != nil {
+		return nil, fmt.Errorf("list device requests: %v", err)
+	}
+
+	type sessionKey struct{ userID, connID string }
+	seen := make(map[sessionKey]bool)
+	for _, r := range dump.RefreshTokens {
+		key := sessionKey{r.Claims.UserID, r.ConnectorID}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		session, err := s.GetOfflineSessions(key.userID, key.connID)
+		if err == storage.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("get offline sessions: %v", err)
+		}
+		dump.OfflineSessions = append(dump.OfflineSessions, session)
+	}
+
+	for _, d := range dump.DeviceRequests {
+		token, err := s.GetDeviceToken(d.DeviceCode)
+		if err == storage.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("get device token: %v", err)
+		}
+		dump.DeviceTokens = append(dump.DeviceTokens, token)
+	}
+
+	return dump, nil
+}
+
+// importStorage creates the objects of a dump in a storage. Objects which
+// already exist are skipped if skipExisting is set, otherwise they fail the
+// import.
+func importStorage(ctx context.Context, s storage.Storage, dump *storageDump, skipExisting bool, logger *slog.Logger) error {
+	var created, skipped int
+	create := func(kind, id string, err error) error {
+		switch {
+		case err == nil:
+			created++
+			return nil
+		case err == storage.ErrAlreadyExists && skipExisting:
+			logger.Info("skipping existing object", "kind", kind, "id", id)
+			skipped++
+			return nil
+		default:
+			return fmt.Errorf("create %s %q: %v", kind, id, err)
+		}
+	}
+
+	if dump.Keys != nil {
+		keys := *dump.Keys
+		if err := s.UpdateKeys(func(storage.Keys) (storage.Keys, error) { return keys, nil }); err != nil {
+			return fmt.Errorf("update keys: %v", err)
+		}
+	}
+	for _, c := range dump.Connectors {
+		if err := create("connector", c.ID, s.CreateConnector(ctx, c)); err != nil {
+			return err
+		}
+	}
+	for _, c := range dump.Clients {
+		if err := create("client", c.ID, s.CreateClient(ctx, c)); err != nil {
+			return err
+		}
+	}
+	for _, p := range dump.Passwords {
+		if err := create("password", p.Email, s.CreatePassword(ctx, p)); err != nil {
+			return err
+		}
+	}
+	for _, r := range dump.RefreshTokens {
+		if err := create("refresh token", r.ID, s.CreateRefresh(ctx, r)); err != nil {
+			return err
+		}
+	}
+	for _, o := range dump.OfflineSessions {
+		if err := create("offline sessions", o.UserID+"/"+o.ConnID, s.CreateOfflineSessions(ctx, o)); err != nil {
+			return err
+		}
+	}
+	for _, w := range dump.Webhooks {
+		if err := create("webhook", w.ID, s.CreateWebhook(ctx, w)); err != nil {
+			return err
+		}
+	}
+	for _, d := range dump.DeviceRequests {
+		if err := create("device request", d.UserCode, s.CreateDeviceRequest(ctx, d)); err != nil {
+			return err
+		}
+	}
+	for _, d := range dump.DeviceTokens {
+		if err := create("device token", d.DeviceCode, s.CreateDeviceToken(ctx, d)); err != nil {
+			return err
+		}
+	}
+
+	logger.Info("storage import done", "created", created, "skipped", skipped, "keys", dump.Keys != nil)
+	return nil
+}
+
+// writeStorageDump writes a dump as a single JSON object, or as one JSON
+// object per line for the version and each object.
+func writeStorageDump(w io.Writer, dump *storageDump, format string) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(dump)
+	case "ndjson":
+	default:
+		return fmt.Errorf("unknown format %q, must be one of %v", format, storageDumpFormats)
+	}
+
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(storageDump{Version: dump.Version}); err != nil {
+		return err
+	}
+	write := func(kind string, v interface{}) error {
+		object, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("encode %s: %v", kind, err)
+		}
+		return encoder.Encode(storageRecord{Kind: kind, Object: object})
+	}
+	if dump.Keys != nil {
+		if err := write("keys", dump.Keys); err != nil {
+			return err
+		}
+	}
+	for _, v := range dump.Connectors {
+		if err := write("connector", v); err != nil {
+			return err
+		}
+	}
+	for _, v := range dump.Clients {
+		if err := write("client", v); err != nil {
+			return err
+		}
+	}
+	for _, v := range dump.Passwords {
+		if err := write("password", v); err != nil {
+			return err
+		}
+	}
+	for _, v := range dump.RefreshTokens {
+		if err := write("refreshToken", v); err != nil {
+			return err
+		}
+	}
+	for _, v := range dump.OfflineSessions {
+		if err := write("offlineSessions", v); err != nil {
+			return err
+		}
+	}
+	for _, v := range dump.Webhooks {
+		if err := write("webhook", v); err != nil {
+			return err
+		}
+	}
+	for _, v := range dump.DeviceRequests {
+		if err := write("deviceRequest", v); err != nil {
+			return err
+		}
+	}
+	for _, v := range dump.DeviceTokens {
+		if err := write("deviceToken", v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readStorageDump reads a dump written by writeStorageDump in either format.
+// A dump in the JSON format is a single object, which is read like the first
+// line of the NDJSON format.
+func readStorageDump(r io.Reader) (*storageDump, error) {
+	decoder := json.NewDecoder(r)
+	dump := &storageDump{}
+	if err := decoder.Decode(dump); err != nil {
+		return nil, err
+	}
+	if dump.Version != storageDumpVersion {
+		return nil, fmt.Errorf("unsupported dump version %d, expected %d", dump.Version, storageDumpVersion)
+	}
+
+	for line := 2; ; line++ {
+		var record storageRecord
+		err := decoder.Decode(&record)
+		if err == io.EOF {
+			return dump, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		if err := dump.add(record); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+	}
+}
+
+// add decodes a record of a dump in the NDJSON format into the dump.
+func (d *storageDump) add(record storageRecord) error {
+	switch record.Kind {
+	case "keys":
+		d.Keys = &storage.Keys{}
+		return json.Unmarshal(record.Object, d.Keys)
+	case "connector":
+		return decodeAppend(record.Object, &d.Connectors)
+	case "client":
+		return decodeAppend(record.Object, &d.Clients)
+	case "password":
+		return decodeAppend(record.Object, &d.Passwords)
+	case "refreshToken":
+		return decodeAppend(record.Object, &d.RefreshTokens)
+	case "offlineSessions":
+		return decodeAppend(record.Object, &d.OfflineSessions)
+	case "webhook":
+		return decodeAppend(record.Object, &d.Webhooks)
+	case "deviceRequest":
+		return decodeAppend(record.Object, &d.DeviceRequests)
+	case "deviceToken":
+		return decodeAppend(record.Object, &d.DeviceTokens)
+	default:
+		return fmt.Errorf("unknown kind %q", record.Kind)
+	}
+}
+
+func decodeAppend[T any](data []byte, list *[]T) error {
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*list = append(*list, v)
+	return nil
+}
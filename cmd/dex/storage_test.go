@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dexidp/dex/storage"
+	"github.com/dexidp/dex/storage/memory"
+)
+
+func TestStorageExportImport(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	s := memory.New(logger)
+	require.NoError(t, s.UpdateKeys(func(storage.Keys) (storage.Keys, error) {
+		return storage.Keys{
+			SigningKey:    &jose.JSONWebKey{Key: key, KeyID: "signing", Algorithm: "RS256", Use: "sig"},
+			SigningKeyPub: &jose.JSONWebKey{Key: key.Public(), KeyID: "signing", Algorithm: "RS256", Use: "sig"},
+			NextRotation:  now,
+		}, nil
+	}))
+	require.NoError(t, s.CreateConnector(ctx, storage.Connector{ID: "ldap", Type: "ldap", Name: "LDAP", Config: []byte(`{"host":"ldap"}`)}))
+	require.NoError(t, s.CreateClient(ctx, storage.Client{ID: "app", Secret: "secret", RedirectURIs: []string{"https://app/callback"}}))
+	require.NoError(t, s.CreatePassword(ctx, storage.Password{Email: "jane@example.com", Hash: []byte("$2a$10$hash"), Username: "jane", UserID: "1"}))
+	require.NoError(t, s.CreateRefresh(ctx, storage.RefreshToken{
+		ID: "refresh", Token: "token", ClientID: "app", ConnectorID: "ldap",
+		Claims:    storage.Claims{UserID: "1", Username: "jane"},
+		CreatedAt: now, LastUsed: now,
+	}))
+	require.NoError(t, s.CreateOfflineSessions(ctx, storage.OfflineSessions{
+		UserID: "1", ConnID: "ldap",
+		Refresh: map[string]*storage.RefreshTokenRef{"app": {ID: "refresh", ClientID: "app", CreatedAt: now, LastUsed: now}},
+	}))
+	require.NoError(t, s.CreateDeviceRequest(ctx, storage.DeviceRequest{UserCode: "ABCD-EFGH", DeviceCode: "device", ClientID: "app", Expiry: now}))
+	require.NoError(t, s.CreateDeviceToken(ctx, storage.DeviceToken{DeviceCode: "device", Status: "pending", Expiry: now, LastRequestTime: now}))
+
+	dump, err := exportStorage(s)
+	require.NoError(t, err)
+	require.NotNil(t, dump.Keys)
+	require.Len(t, dump.OfflineSessions, 1)
+	require.Len(t, dump.DeviceTokens, 1)
+
+	for _, format := range storageDumpFormats {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, writeStorageDump(&buf, dump, format))
+			read, err := readStorageDump(&buf)
+			require.NoError(t, err)
+
+			imported := memory.New(logger)
+			require.NoError(t, importStorage(ctx, imported, read, false, logger))
+
+			got, err := exportStorage(imported)
+			require.NoError(t, err)
+			require.Equal(t, "signing", got.Keys.SigningKey.KeyID)
+			require.True(t, got.Keys.SigningKey.Valid())
+
+			var want, gotJSON bytes.Buffer
+			require.NoError(t, writeStorageDump(&want, dump, "json"))
+			require.NoError(t, writeStorageDump(&gotJSON, got, "json"))
+			require.JSONEq(t, want.String(), gotJSON.String())
+
+			// Importing again fails unless existing objects are skipped.
+			require.Error(t, importStorage(ctx, imported, read, false, logger))
+			require.NoError(t, importStorage(ctx, imported, read, true, logger))
+		})
+	}
+}
+
+func TestReadStorageDumpErrors(t *testing.T) {
+	_, err := readStorageDump(bytes.NewBufferString(`{"version":2}`))
+	require.ErrorContains(t, err, "unsupported dump version")
+
+	_, err = readStorageDump(bytes.NewBufferString(`{"version":1}
+{"kind":"client","object":{"id":"app"}}
+{"kind":"authCode","object":{}}
+`))
+	require.ErrorContains(t, err, `line 3: unknown kind "authCode"`)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTracerProvider(t *testing.T) {
+	ratio := func(r float64) *float64 { return &r }
+
+	tests := []struct {
+		name    string
+		config  Tracing
+		wantErr bool
+	}{
+		{name: "grpc", config: Tracing{Endpoint: "localhost:4317", Insecure: true}},
+		{name: "http", config: Tracing{Endpoint: "localhost:4318", Protocol: "http", SampleRatio: ratio(0.5)}},
+		{name: "no endpoint", config: Tracing{}, wantErr: true},
+		{name: "unknown protocol", config: Tracing{Endpoint: "localhost:4317", Protocol: "zipkin"}, wantErr: true},
+		{name: "invalid ratio", config: Tracing{Endpoint: "localhost:4317", SampleRatio: ratio(2)}, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tp, err := newTracerProvider(context.Background(), tc.config)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, tp.Shutdown(context.Background()))
+		})
+	}
+}
+
+func TestUnmarshalTracingConfig(t *testing.T) {
+	rawConfig := []byte(`
+http: 127.0.0.1:5558
+tracing:
+  endpoint: otel-collector:4317
+  insecure: true
+  headers:
+    authorization: Bearer token
+  sampleRatio: 0.1
+`)
+	var c Telemetry
+	require.NoError(t, yaml.Unmarshal(rawConfig, &c))
+	require.NotNil(t, c.Tracing)
+	require.Equal(t, "otel-collector:4317", c.Tracing.Endpoint)
+	require.True(t, c.Tracing.Insecure)
+	require.Equal(t, map[string]string{"authorization": "Bearer token"}, c.Tracing.Headers)
+	require.Equal(t, 0.1, *c.Tracing.SampleRatio)
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+
+	"github.com/dexidp/dex/server"
+)
+
+// runValidate checks a config file without serving, for CI pipelines: it
+// validates the config like serve does, reports unknown fields, opens the
+// static connectors and connects to the storage. All problems found are
+// returned in one error.
+func runValidate(options serveOptions) error {
+	configData, err := readConfigFile(options.config)
+	if err != nil {
+		return fmt.Errorf("failed to read config file %s: %v", options.config, err)
+	}
+	c, err := parseConfig(configData, options)
+	if err != nil {
+		return err
+	}
+
+	logger, err := newLogger(c.Logger.Level, c.Logger.Format, nil)
+	if err != nil {
+		return fmt.Errorf("invalid config: %v", err)
+	}
+
+	problems := validateConfig(configData, &c, logger)
+	if len(problems) != 0 {
+		return fmt.Errorf("invalid config file %s:\n\t-\t%s", options.config, strings.Join(problems, "\n\t-\t"))
+	}
+	fmt.Printf("config file %s is valid\n", options.config)
+	return nil
+}
+
+// validateConfig returns the problems of a config.
+func validateConfig(data []byte, c *Config, logger *slog.Logger) []string {
+	var problems []string
+	if err := c.Validate(); err != nil {
+		problems = append(problems, err.Error())
+	}
+	problems = append(problems, unknownConfigFields(data)...)
+
+	for i := range c.StaticClients {
+		if err := resolveStaticClient(&c.StaticClients[i]); err != nil {
+			problems = append(problems, fmt.Sprintf("static client %d: %v", i, err))
+		}
+	}
+
+	for _, conn := range c.StaticConnectors {
+		if conn.ID == "" || conn.Name == "" || conn.Type == "" {
+			problems = append(problems, "ID, Type and Name fields are required for a connector")
+			continue
+		}
+		if conn.Config == nil {
+			problems = append(problems, fmt.Sprintf("connector %q: no config field", conn.ID))
+			continue
+		}
+		sc, err := ToStorageConnector(conn)
+		if err == nil {
+			err = server.ValidateConnectorDisplay(sc.Display)
+		}
+		if err == nil {
+			err = server.ValidateConnector(logger, sc)
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("connector %q: %v", conn.ID, err))
+		}
+	}
+
+	if c.Storage.Config != nil {
+		s, err := c.Storage.Config.Open(logger)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("storage: %v", err))
+		} else {
+			s.Close()
+		}
+	}
+	return problems
+}
+
+// unknownConfigFields reports the fields of the config file, its storage
+// config and its connector configs which dex doesn't know, usually typos.
+func unknownConfigFields(data []byte) []string {
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil
+	}
+	var raw struct {
+		Storage struct {
+			Type   string                 `json:"type"`
+			Config map[string]interface{} `json:"config"`
+		} `json:"storage"`
+		Connectors []struct {
+			ID     string                 `json:"id"`
+			Type   string                 `json:"type"`
+			Config map[string]interface{} `json:"config"`
+		} `json:"connectors"`
+	}
+	var top map[string]interface{}
+	if json.Unmarshal(jsonData, &raw) != nil || json.Unmarshal(jsonData, &top) != nil {
+		// Parse errors are reported by the config parser.
+		return nil
+	}
+
+	var problems []string
+	for _, field := range unknownFields(top, Config{}) {
+		problems = append(problems, fmt.Sprintf("unknown field %q", field))
+	}
+	if f, ok := storages[raw.Storage.Type]; ok {
+		for _, field := range unknownFields(raw.Storage.Config, f()) {
+			problems = append(problems, fmt.Sprintf("storage: unknown field %q", field))
+		}
+	}
+	for _, conn := range raw.Connectors {
+		if f, ok := server.ConnectorsConfig[conn.Type]; ok {
+			for _, field := range unknownFields(conn.Config, f()) {
+				problems = append(problems, fmt.Sprintf("connector %q: unknown field %q", conn.ID, field))
+			}
+		}
+	}
+	return problems
+}
+
+// unknownFields returns the keys of a JSON object which don't match a field
+// of the struct v. Keys match fields regardless of case, like they do when
+// decoding. Types decoding themselves aren't checked.
+func unknownFields(raw map[string]interface{}, v interface{}) []string {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if _, ok := reflect.New(t).Interface().(json.Unmarshaler); ok || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	known := make(map[string]bool)
+	var collect func(t reflect.Type)
+	collect = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			switch {
+			case name == "-":
+			case f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct:
+				collect(f.Type)
+			case f.IsExported():
+				if name == "" {
+					name = f.Name
+				}
+				known[strings.ToLower(name)] = true
+			}
+		}
+	}
+	collect(t)
+
+	var unknown []string
+	for key := range raw {
+		if !known[strings.ToLower(key)] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateConfig(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	validate := func(config string) []string {
+		c, err := parseConfig([]byte(config), serveOptions{})
+		require.NoError(t, err)
+		return validateConfig([]byte(config), &c, logger)
+	}
+
+	require.Empty(t, validate(`
+issuer: http://127.0.0.1:5556/dex
+storage:
+  type: memory
+web:
+  http: 127.0.0.1:5556
+connectors:
+- type: mockPassword
+  id: mock
+  name: Example
+  config:
+    username: admin
+    password: password
+staticClients:
+- id: example-app
+  name: Example App
+  secret: ZXhhbXBsZS1hcHAtc2VjcmV0
+`))
+
+	require.Equal(t, []string{
+		"unknown field \"staticClient\"",
+		"connector \"mock\": unknown field \"user\"",
+		"static client 0: Secret or SecretEnv field is required for client \"example-app\"",
+		"connector \"mock\": failed to create connector mock: no username supplied",
+	}, validate(`
+issuer: http://127.0.0.1:5556/dex
+storage:
+  type: memory
+web:
+  http: 127.0.0.1:5556
+connectors:
+- type: mockPassword
+  id: mock
+  name: Example
+  config:
+    user: admin
+    password: password
+staticClients:
+- id: example-app
+  name: Example App
+staticClient: []
+`))
+}
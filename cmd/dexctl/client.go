@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dexidp/dex/api/v2"
+)
+
+func commandClient(options *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "client",
+		Short: "Manage OAuth2 clients",
+		Run: func(cmd *cobra.Command, _ []string) {
+			cmd.Help()
+			os.Exit(2)
+		},
+	}
+
+	var list api.ListClientsReq
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the clients",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cmd.SilenceUsage = true
+			return options.run(func(ctx context.Context, client api.DexClient) error {
+				all := &api.ListClientsResp{}
+				req := &api.ListClientsReq{IdFilter: list.IdFilter, NameFilter: list.NameFilter}
+				for {
+					resp, err := client.ListClients(ctx, req)
+					if err != nil {
+						return err
+					}
+					all.Clients = append(all.Clients, resp.Clients...)
+					if resp.NextPageToken == "" {
+						break
+					}
+					req.PageToken = resp.NextPageToken
+				}
+				return options.print(all, func() {
+					w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+					fmt.Fprintln(w, "ID\tNAME\tPUBLIC\tREDIRECT URIS\tLAST USED")
+					for _, c := range all.Clients {
+						fmt.Fprintf(w, "%s\t%s\t%t\t%s\t%s\n", c.Id, c.Name, c.Public, strings.Join(c.RedirectUris, ","), formatUnix(c.LastUsed))
+					}
+					w.Flush()
+				})
+			})
+		},
+	}
+	listCmd.Flags().StringVar(&list.IdFilter, "id-filter", "", "Only list clients whose ID contains the value")
+	listCmd.Flags().StringVar(&list.NameFilter, "name-filter", "", "Only list clients whose name contains the value")
+	cmd.AddCommand(listCmd)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "get [id]",
+		Short: "Show a client, including its secret",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return options.run(func(ctx context.Context, client api.DexClient) error {
+				resp, err := client.GetClient(ctx, &api.GetClientReq{Id: args[0]})
+				if err != nil {
+					return err
+				}
+				return options.print(resp.Client, func() {
+					printClient(resp.Client)
+				})
+			})
+		},
+	})
+
+	create := &api.Client{}
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a client",
+		Long: `Create a client. Unless the client is public, a secret is generated if none
+is given and printed.`,
+		Example: "dexctl client create --id example-app --name 'Example App' --redirect-uri http://127.0.0.1:5555/callback",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cmd.SilenceUsage = true
+			return options.run(func(ctx context.Context, client api.DexClient) error {
+				resp, err := client.CreateClient(ctx, &api.CreateClientReq{Client: create})
+				if err != nil {
+					return err
+				}
+				if resp.AlreadyExists {
+					return fmt.Errorf("client %q already exists", create.Id)
+				}
+				return options.print(resp.Client, func() {
+					printClient(resp.Client)
+				})
+			})
+		},
+	}
+	createFlags := createCmd.Flags()
+	createFlags.StringVar(&create.Id, "id", "", "ID of the client, generated if empty")
+	createFlags.StringVar(&create.Name, "name", "", "Name shown to users")
+	createFlags.StringVar(&create.Secret, "secret", "", "Secret of the client, generated if empty")
+	createFlags.StringSliceVar(&create.RedirectUris, "redirect-uri", nil, "Allowed redirect URI, may be repeated")
+	createFlags.StringSliceVar(&create.TrustedPeers, "trusted-peer", nil, "ID of a client allowed to issue tokens for this one, may be repeated")
+	createFlags.BoolVar(&create.Public, "public", false, "Public client without a secret, like a CLI or single page app")
+	createFlags.StringVar(&create.LogoUrl, "logo-url", "", "URL of the logo shown to users")
+	cmd.AddCommand(createCmd)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "delete [id]",
+		Short: "Delete a client",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return options.run(func(ctx context.Context, client api.DexClient) error {
+				resp, err := client.DeleteClient(ctx, &api.DeleteClientReq{Id: args[0]})
+				if err != nil {
+					return err
+				}
+				if resp.NotFound {
+					return fmt.Errorf("client %q not found", args[0])
+				}
+				fmt.Printf("Deleted client %s\n", args[0])
+				return nil
+			})
+		},
+	})
+
+	var (
+		newSecret   string
+		gracePeriod time.Duration
+	)
+	rotateCmd := &cobra.Command{
+		Use:   "rotate-secret [id]",
+		Short: "Replace the secret of a client",
+		Long: `Replace the secret of a client with a generated or given one. The previous
+secret stays valid for the grace period, so that the client can be updated
+without downtime.`,
+		Example: "dexctl client rotate-secret example-app --grace-period 24h",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return options.run(func(ctx context.Context, client api.DexClient) error {
+				resp, err := client.RotateClientSecret(ctx, &api.RotateClientSecretReq{
+					Id:                 args[0],
+					NewSecret:          newSecret,
+					GracePeriodSeconds: int64(gracePeriod / time.Second),
+				})
+				if err != nil {
+					return err
+				}
+				if resp.NotFound {
+					return fmt.Errorf("client %q not found", args[0])
+				}
+				return options.print(resp, func() {
+					fmt.Printf("Secret: %s\n", resp.Secret)
+					if resp.PreviousSecretExpiry != 0 {
+						fmt.Printf("Previous secret valid until: %s\n", formatUnix(resp.PreviousSecretExpiry))
+					}
+				})
+			})
+		},
+	}
+	rotateCmd.Flags().StringVar(&newSecret, "secret", "", "New secret, generated if empty")
+	rotateCmd.Flags().DurationVar(&gracePeriod, "grace-period", 0, "How long the previous secret remains valid")
+	cmd.AddCommand(rotateCmd)
+
+	return cmd
+}
+
+func printClient(c *api.Client) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "ID:\t%s\n", c.Id)
+	fmt.Fprintf(w, "Name:\t%s\n", c.Name)
+	fmt.Fprintf(w, "Public:\t%t\n", c.Public)
+	if !c.Public {
+		fmt.Fprintf(w, "Secret:\t%s\n", c.Secret)
+	}
+	fmt.Fprintf(w, "Redirect URIs:\t%s\n", strings.Join(c.RedirectUris, ", "))
+	if len(c.TrustedPeers) > 0 {
+		fmt.Fprintf(w, "Trusted peers:\t%s\n", strings.Join(c.TrustedPeers, ", "))
+	}
+	w.Flush()
+}
+
+// formatUnix formats a Unix time of the API, where zero means unknown.
+func formatUnix(sec int64) string {
+	if sec == 0 {
+		return "-"
+	}
+	return time.Unix(sec, 0).UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+
+	"github.com/dexidp/dex/pkg/apiclient"
+)
+
+// config is the config file of dexctl, listing the servers it talks to like
+// the contexts of a kubeconfig.
+type config struct {
+	CurrentContext string       `json:"currentContext,omitempty"`
+	Contexts       []apiContext `json:"contexts,omitempty"`
+}
+
+// apiContext is a Dex server and the credentials calling its API.
+type apiContext struct {
+	Name       string `json:"name"`
+	Addr       string `json:"addr"`
+	CACert     string `json:"caCert,omitempty"`
+	ClientCert string `json:"clientCert,omitempty"`
+	ClientKey  string `json:"clientKey,omitempty"`
+	// Token is a bearer token, or TokenFile a file containing one.
+	Token     string `json:"token,omitempty"`
+	TokenFile string `json:"tokenFile,omitempty"`
+}
+
+func (c apiContext) clientOptions() (apiclient.Options, error) {
+	token := c.Token
+	if c.TokenFile != "" {
+		b, err := os.ReadFile(c.TokenFile)
+		if err != nil {
+			return apiclient.Options{}, fmt.Errorf("context %q: reading token file: %v", c.Name, err)
+		}
+		token = strings.TrimSpace(string(b))
+	}
+	return apiclient.Options{
+		Addr:       c.Addr,
+		CACert:     c.CACert,
+		ClientCert: c.ClientCert,
+		ClientKey:  c.ClientKey,
+		Token:      token,
+	}, nil
+}
+
+func (c *config) find(name string) (*apiContext, bool) {
+	for i := range c.Contexts {
+		if c.Contexts[i].Name == name {
+			return &c.Contexts[i], true
+		}
+	}
+	return nil, false
+}
+
+// configPath returns the path of the config file: the flag, $DEXCTL_CONFIG
+// or dexctl/config.yaml in the user config dir.
+func (o *globalOptions) configPath() string {
+	if o.configFile != "" {
+		return o.configFile
+	}
+	if p := os.Getenv("DEXCTL_CONFIG"); p != "" {
+		return p
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "dexctl.yaml"
+	}
+	return filepath.Join(dir, "dexctl", "config.yaml")
+}
+
+// loadConfig reads a config file. A missing file is an empty config.
+func loadConfig(path string) (*config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %v", err)
+	}
+	var c config
+	if err := yaml.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("parsing config file %s: %v", path, err)
+	}
+	return &c, nil
+}
+
+// save writes a config file readable only by the user, since contexts may
+// contain tokens.
+func (c *config) save(path string) error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("creating config dir: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("writing config file: %v", err)
+	}
+	return nil
+}
+
+func commandContext(options *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "context",
+		Short: "Manage the contexts of the config file, each a Dex server",
+		Run: func(cmd *cobra.Command, _ []string) {
+			cmd.Help()
+			os.Exit(2)
+		},
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List the contexts",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := loadConfig(options.configPath())
+			if err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "CURRENT\tNAME\tADDRESS\tTLS")
+			for _, c := range cfg.Contexts {
+				current := ""
+				if c.Name == cfg.CurrentContext {
+					current = "*"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%t\n", current, c.Name, c.Addr, c.CACert != "")
+			}
+			return w.Flush()
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "current",
+		Short: "Print the name of the current context",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := loadConfig(options.configPath())
+			if err != nil {
+				return err
+			}
+			if cfg.CurrentContext == "" {
+				return errors.New("no current context")
+			}
+			fmt.Println(cfg.CurrentContext)
+			return nil
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "use [name]",
+		Short: "Set the current context",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return updateConfig(options, func(cfg *config) error {
+				if _, ok := cfg.find(args[0]); !ok {
+					return fmt.Errorf("no context %q", args[0])
+				}
+				cfg.CurrentContext = args[0]
+				return nil
+			})
+		},
+	})
+
+	var tokenFile string
+	setCmd := &cobra.Command{
+		Use:   "set [name]",
+		Short: "Create or update a context",
+		Long: `Create or update a context from the --addr, --ca-cert, --client-cert,
+--client-key, --token and --token-file flags. Only the flags given are
+changed. The first context created becomes the current one.`,
+		Example: "dexctl context set prod --addr dex.example.com:5557 --ca-cert ca.crt --token-file token",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return updateConfig(options, func(cfg *config) error {
+				c, ok := cfg.find(args[0])
+				if !ok {
+					cfg.Contexts = append(cfg.Contexts, apiContext{Name: args[0]})
+					c = &cfg.Contexts[len(cfg.Contexts)-1]
+				}
+				for _, f := range []struct {
+					name  string
+					value string
+					field *string
+				}{
+					{"addr", options.addr, &c.Addr},
+					{"ca-cert", options.caCert, &c.CACert},
+					{"client-cert", options.clientCert, &c.ClientCert},
+					{"client-key", options.clientKey, &c.ClientKey},
+					{"token", options.token, &c.Token},
+					{"token-file", tokenFile, &c.TokenFile},
+				} {
+					if cmd.Flag(f.name).Changed {
+						*f.field = f.value
+					}
+				}
+				if c.Addr == "" {
+					return errors.New("no API address specified, use --addr")
+				}
+				if cfg.CurrentContext == "" {
+					cfg.CurrentContext = c.Name
+				}
+				return nil
+			})
+		},
+	}
+	setCmd.Flags().StringVar(&tokenFile, "token-file", "", "File containing the bearer token")
+	cmd.AddCommand(setCmd)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "delete [name]",
+		Short: "Delete a context",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return updateConfig(options, func(cfg *config) error {
+				for i, c := range cfg.Contexts {
+					if c.Name == args[0] {
+						cfg.Contexts = append(cfg.Contexts[:i], cfg.Contexts[i+1:]...)
+						if cfg.CurrentContext == args[0] {
+							cfg.CurrentContext = ""
+						}
+						return nil
+					}
+				}
+				return fmt.Errorf("no context %q", args[0])
+			})
+		},
+	})
+
+	return cmd
+}
+
+// updateConfig loads the config file, applies a change and saves it.
+func updateConfig(options *globalOptions, update func(cfg *config) error) error {
+	path := options.configPath()
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return err
+	}
+	if err := update(cfg); err != nil {
+		return err
+	}
+	return cfg.save(path)
+}
@@ -0,0 +1,213 @@
+// Command dexctl manages Dex servers through the gRPC API.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/dexidp/dex/api/v2"
+	"github.com/dexidp/dex/pkg/apiclient"
+)
+
+var version = "DEV"
+
+// globalOptions select the server to talk to, from a context of the config
+// file and the flags overriding it.
+type globalOptions struct {
+	configFile string
+	context    string
+	output     string
+	timeout    time.Duration
+
+	addr       string
+	caCert     string
+	clientCert string
+	clientKey  string
+	token      string
+}
+
+func commandRoot() *cobra.Command {
+	options := &globalOptions{}
+
+	rootCmd := &cobra.Command{
+		Use:   "dexctl",
+		Short: "Manage Dex servers through the gRPC API",
+		Long: `Manage the clients, passwords and sessions of Dex servers through the gRPC
+API. Servers are configured as contexts in a config file, selected with
+--context or "dexctl context use".`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+			os.Exit(2)
+		},
+	}
+
+	flags := rootCmd.PersistentFlags()
+	flags.StringVar(&options.configFile, "config", "", "Config file with the contexts, defaults to $DEXCTL_CONFIG or the user config dir")
+	flags.StringVar(&options.context, "context", "", "Context to use instead of the current one")
+	flags.StringVarP(&options.output, "output", "o", "table", "Output format, table or json")
+	flags.DurationVar(&options.timeout, "timeout", time.Minute, "Timeout of the calls")
+	flags.StringVar(&options.addr, "addr", "", "Address of the gRPC API, overrides the context")
+	flags.StringVar(&options.caCert, "ca-cert", "", "CA certificate verifying the server, enables TLS")
+	flags.StringVar(&options.clientCert, "client-cert", "", "Client certificate for TLS client authentication")
+	flags.StringVar(&options.clientKey, "client-key", "", "Key of the client certificate")
+	flags.StringVar(&options.token, "token", "", "Bearer token, defaults to $DEX_API_TOKEN or the token of the context")
+
+	rootCmd.AddCommand(commandContext(options))
+	rootCmd.AddCommand(commandClient(options))
+	rootCmd.AddCommand(commandPassword(options))
+	rootCmd.AddCommand(commandSession(options))
+	rootCmd.AddCommand(commandGC(options))
+	rootCmd.AddCommand(commandRotateKeys(options))
+	rootCmd.AddCommand(commandVersion())
+	return rootCmd
+}
+
+// clientOptions returns the connection options of the selected context with
+// the flags applied.
+func (o *globalOptions) clientOptions() (apiclient.Options, error) {
+	cfg, err := loadConfig(o.configPath())
+	if err != nil {
+		return apiclient.Options{}, err
+	}
+	var c apiContext
+	switch {
+	case o.context != "":
+		ctx, ok := cfg.find(o.context)
+		if !ok {
+			return apiclient.Options{}, fmt.Errorf("no context %q in %s", o.context, o.configPath())
+		}
+		c = *ctx
+	case cfg.CurrentContext != "":
+		ctx, ok := cfg.find(cfg.CurrentContext)
+		if !ok {
+			return apiclient.Options{}, fmt.Errorf("current context %q not found in %s", cfg.CurrentContext, o.configPath())
+		}
+		c = *ctx
+	default:
+		c.Addr = "127.0.0.1:5557"
+	}
+
+	clientOptions, err := c.clientOptions()
+	if err != nil {
+		return apiclient.Options{}, err
+	}
+	for _, override := range []struct {
+		flag  string
+		field *string
+	}{
+		{o.addr, &clientOptions.Addr},
+		{o.caCert, &clientOptions.CACert},
+		{o.clientCert, &clientOptions.ClientCert},
+		{o.clientKey, &clientOptions.ClientKey},
+		{os.Getenv("DEX_API_TOKEN"), &clientOptions.Token},
+		{o.token, &clientOptions.Token},
+	} {
+		if override.flag != "" {
+			*override.field = override.flag
+		}
+	}
+	return clientOptions, nil
+}
+
+// run connects to the API and runs a call.
+func (o *globalOptions) run(call func(ctx context.Context, client api.DexClient) error) error {
+	clientOptions, err := o.clientOptions()
+	if err != nil {
+		return err
+	}
+	conn, err := apiclient.Dial(clientOptions)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), o.timeout)
+	defer cancel()
+	return call(clientOptions.Context(ctx), api.NewDexClient(conn))
+}
+
+// print writes a response as JSON if requested, or calls table otherwise.
+func (o *globalOptions) print(m proto.Message, table func()) error {
+	switch o.output {
+	case "json":
+		b, err := protojson.MarshalOptions{Multiline: true, EmitUnpopulated: true}.Marshal(m)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+	case "table":
+		table()
+	default:
+		return fmt.Errorf("unknown output format %q", o.output)
+	}
+	return nil
+}
+
+func commandGC(options *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "gc",
+		Short: "Delete expired auth requests, auth codes and device flow objects",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cmd.SilenceUsage = true
+			return options.run(func(ctx context.Context, client api.DexClient) error {
+				resp, err := client.RunGarbageCollection(ctx, &api.RunGarbageCollectionReq{})
+				if err != nil {
+					return err
+				}
+				return options.print(resp, func() {
+					fmt.Printf("Deleted %d auth requests, %d auth codes, %d device requests and %d device tokens\n",
+						resp.AuthRequests, resp.AuthCodes, resp.DeviceRequests, resp.DeviceTokens)
+				})
+			})
+		},
+	}
+}
+
+func commandRotateKeys(options *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rotate-keys",
+		Short: "Rotate the signing keys",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cmd.SilenceUsage = true
+			return options.run(func(ctx context.Context, client api.DexClient) error {
+				if _, err := client.RotateSigningKeys(ctx, &api.RotateSigningKeysReq{}); err != nil {
+					return err
+				}
+				fmt.Println("Rotated signing keys")
+				return nil
+			})
+		},
+	}
+}
+
+func commandVersion() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Print the version and exit",
+		Run: func(_ *cobra.Command, _ []string) {
+			fmt.Printf(
+				"dexctl Version: %s\nGo Version: %s\nGo OS/ARCH: %s %s\n",
+				version,
+				runtime.Version(),
+				runtime.GOOS,
+				runtime.GOARCH,
+			)
+		},
+	}
+}
+
+func main() {
+	if err := commandRoot().Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(2)
+	}
+}
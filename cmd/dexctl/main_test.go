@@ -0,0 +1,102 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/dexidp/dex/api/v2"
+	"github.com/dexidp/dex/server"
+	"github.com/dexidp/dex/storage/memory"
+)
+
+func runDexctl(t *testing.T, args ...string) error {
+	t.Helper()
+	cmd := commandRoot()
+	cmd.SetArgs(args)
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	return cmd.Execute()
+}
+
+func TestContexts(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "dexctl", "config.yaml")
+	t.Setenv("DEXCTL_CONFIG", configFile)
+	t.Setenv("DEX_API_TOKEN", "")
+
+	require.NoError(t, runDexctl(t, "context", "set", "prod", "--addr", "dex.example.com:5557", "--token", "prod-token"))
+	require.NoError(t, runDexctl(t, "context", "set", "staging", "--addr", "dex.staging.example.com:5557"))
+	require.Error(t, runDexctl(t, "context", "set", "broken"))
+
+	options := &globalOptions{}
+	clientOptions, err := options.clientOptions()
+	require.NoError(t, err)
+	require.Equal(t, "dex.example.com:5557", clientOptions.Addr, "the first context is the current one")
+	require.Equal(t, "prod-token", clientOptions.Token)
+
+	require.NoError(t, runDexctl(t, "context", "use", "staging"))
+	clientOptions, err = options.clientOptions()
+	require.NoError(t, err)
+	require.Equal(t, "dex.staging.example.com:5557", clientOptions.Addr)
+
+	// Flags override the context.
+	options = &globalOptions{context: "prod", addr: "127.0.0.1:5557", token: "flag-token"}
+	clientOptions, err = options.clientOptions()
+	require.NoError(t, err)
+	require.Equal(t, "127.0.0.1:5557", clientOptions.Addr)
+	require.Equal(t, "flag-token", clientOptions.Token)
+
+	require.NoError(t, runDexctl(t, "context", "delete", "staging"))
+	cfg, err := loadConfig(configFile)
+	require.NoError(t, err)
+	require.Len(t, cfg.Contexts, 1)
+	require.Empty(t, cfg.CurrentContext)
+	require.Error(t, runDexctl(t, "context", "use", "staging"))
+}
+
+func TestCommands(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	s := memory.New(logger)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	grpcSrv := grpc.NewServer()
+	api.RegisterDexServer(grpcSrv, server.NewAPI(s, logger, "test", nil))
+	go grpcSrv.Serve(l)
+	defer grpcSrv.Stop()
+
+	t.Setenv("DEXCTL_CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+	dexctl := func(args ...string) error {
+		return runDexctl(t, append([]string{"--addr", l.Addr().String()}, args...)...)
+	}
+
+	require.NoError(t, dexctl("client", "create", "--id", "example-app", "--name", "Example App", "--redirect-uri", "http://127.0.0.1:5555/callback"))
+	require.Error(t, dexctl("client", "create", "--id", "example-app", "--name", "Example App"))
+	client, err := s.GetClient("example-app")
+	require.NoError(t, err)
+	require.NotEmpty(t, client.Secret, "a secret is generated")
+
+	require.NoError(t, dexctl("client", "rotate-secret", "example-app", "--secret", "new-secret", "--grace-period", "1h"))
+	client, err = s.GetClient("example-app")
+	require.NoError(t, err)
+	require.Equal(t, "new-secret", client.Secret)
+
+	require.NoError(t, dexctl("client", "list", "-o", "json"))
+	require.NoError(t, dexctl("client", "delete", "example-app"))
+	require.Error(t, dexctl("client", "delete", "example-app"))
+
+	hash, err := readPasswordHash(strings.NewReader("password\n"))
+	require.NoError(t, err)
+	require.Equal(t, "$2a$", string(hash[:4]))
+	_, err = readPasswordHash(strings.NewReader(""))
+	require.Error(t, err)
+
+	require.NoError(t, dexctl("gc"))
+	require.Error(t, dexctl("session", "revoke", "user"), "a connector or client is required")
+}
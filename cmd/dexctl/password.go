@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/dexidp/dex/api/v2"
+)
+
+func commandPassword(options *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "password",
+		Short: "Manage the users of the local password database",
+		Long: `Manage the users of the local password database. Passwords are read from
+standard input and hashed with bcrypt before they're sent.`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			cmd.Help()
+			os.Exit(2)
+		},
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List the users",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cmd.SilenceUsage = true
+			return options.run(func(ctx context.Context, client api.DexClient) error {
+				resp, err := client.ListPasswords(ctx, &api.ListPasswordReq{})
+				if err != nil {
+					return err
+				}
+				for _, p := range resp.Passwords {
+					p.Hash = nil
+				}
+				return options.print(resp, func() {
+					w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+					fmt.Fprintln(w, "EMAIL\tUSERNAME\tUSER ID\tEMAIL VERIFIED")
+					for _, p := range resp.Passwords {
+						fmt.Fprintf(w, "%s\t%s\t%s\t%t\n", p.Email, p.Username, p.UserId, p.EmailVerified == nil || *p.EmailVerified)
+					}
+					w.Flush()
+				})
+			})
+		},
+	})
+
+	var create api.Password
+	createCmd := &cobra.Command{
+		Use:     "create [email]",
+		Short:   "Create a user, reading the password from standard input",
+		Example: "echo -n 'password' | dexctl password create admin@example.com --username admin",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			hash, err := readPasswordHash(os.Stdin)
+			if err != nil {
+				return err
+			}
+			password := &api.Password{
+				Email:    args[0],
+				Hash:     hash,
+				Username: create.Username,
+				UserId:   create.UserId,
+			}
+			if password.UserId == "" {
+				password.UserId = uuid.NewString()
+			}
+			return options.run(func(ctx context.Context, client api.DexClient) error {
+				resp, err := client.CreatePassword(ctx, &api.CreatePasswordReq{Password: password})
+				if err != nil {
+					return err
+				}
+				if resp.AlreadyExists {
+					return fmt.Errorf("user %q already exists", args[0])
+				}
+				fmt.Printf("Created user %s with ID %s\n", args[0], password.UserId)
+				return nil
+			})
+		},
+	}
+	createCmd.Flags().StringVar(&create.Username, "username", "", "Username shown to the user")
+	createCmd.Flags().StringVar(&create.UserId, "user-id", "", "ID of the user, generated if empty")
+	cmd.AddCommand(createCmd)
+
+	var (
+		newUsername   string
+		resetPassword bool
+	)
+	updateCmd := &cobra.Command{
+		Use:     "update [email]",
+		Short:   "Change the username or password of a user",
+		Example: "echo -n 'new password' | dexctl password update admin@example.com --password",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			req := &api.UpdatePasswordReq{Email: args[0], NewUsername: newUsername}
+			if resetPassword {
+				hash, err := readPasswordHash(os.Stdin)
+				if err != nil {
+					return err
+				}
+				req.NewHash = hash
+			}
+			if req.NewHash == nil && req.NewUsername == "" {
+				return errors.New("nothing to update, use --username or --password")
+			}
+			return options.run(func(ctx context.Context, client api.DexClient) error {
+				resp, err := client.UpdatePassword(ctx, req)
+				if err != nil {
+					return err
+				}
+				if resp.NotFound {
+					return fmt.Errorf("user %q not found", args[0])
+				}
+				fmt.Printf("Updated user %s\n", args[0])
+				return nil
+			})
+		},
+	}
+	updateCmd.Flags().StringVar(&newUsername, "username", "", "New username")
+	updateCmd.Flags().BoolVar(&resetPassword, "password", false, "Set a new password read from standard input")
+	cmd.AddCommand(updateCmd)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "delete [email]",
+		Short: "Delete a user",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return options.run(func(ctx context.Context, client api.DexClient) error {
+				resp, err := client.DeletePassword(ctx, &api.DeletePasswordReq{Email: args[0]})
+				if err != nil {
+					return err
+				}
+				if resp.NotFound {
+					return fmt.Errorf("user %q not found", args[0])
+				}
+				fmt.Printf("Deleted user %s\n", args[0])
+				return nil
+			})
+		},
+	})
+
+	return cmd
+}
+
+// readPasswordHash reads a password from the first line of r and hashes it.
+func readPasswordHash(r io.Reader) ([]byte, error) {
+	password, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("reading password: %v", err)
+	}
+	password = strings.TrimRight(password, "\r\n")
+	if password == "" {
+		return nil, errors.New("no password given on standard input")
+	}
+	return bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+}
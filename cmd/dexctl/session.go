@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dexidp/dex/api/v2"
+)
+
+func commandSession(options *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "session",
+		Short: "List and revoke the sessions (refresh tokens) of users",
+		Run: func(cmd *cobra.Command, _ []string) {
+			cmd.Help()
+			os.Exit(2)
+		},
+	}
+
+	var list api.ListUsersReq
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the users with sessions or passwords, and their sessions",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cmd.SilenceUsage = true
+			return options.run(func(ctx context.Context, client api.DexClient) error {
+				resp, err := client.ListUsers(ctx, &list)
+				if err != nil {
+					return err
+				}
+				return options.print(resp, func() {
+					w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+					fmt.Fprintln(w, "USER ID\tCONNECTOR\tEMAIL\tCLIENT\tCREATED\tLAST USED")
+					for _, u := range resp.Users {
+						if len(u.Sessions) == 0 {
+							fmt.Fprintf(w, "%s\t%s\t%s\t-\t-\t-\n", u.UserId, u.ConnectorId, u.Email)
+						}
+						for _, s := range u.Sessions {
+							fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", u.UserId, u.ConnectorId, u.Email, s.ClientId, formatUnix(s.CreatedAt), formatUnix(s.LastUsed))
+						}
+					}
+					w.Flush()
+				})
+			})
+		},
+	}
+	listCmd.Flags().StringVar(&list.EmailFilter, "email", "", "Only list users whose email contains the value")
+	listCmd.Flags().StringVar(&list.ConnectorId, "connector-id", "", "Only list users of the connector")
+	listCmd.Flags().StringVar(&list.ClientId, "client-id", "", "Only list sessions of the client")
+	cmd.AddCommand(listCmd)
+
+	var connectorID, clientID string
+	revokeCmd := &cobra.Command{
+		Use:   "revoke [user id]",
+		Short: "Revoke the sessions of a user",
+		Long: `Revoke all sessions of a user of a connector. With --client-id, only the
+session of that client is revoked and the user is identified by the "sub"
+claim of its ID tokens instead.`,
+		Example: `dexctl session revoke 0-385-28089-0 --connector-id ldap
+dexctl session revoke CgwwLTM4NS0yODA4OS0wEgRtb2Nr --client-id example-app`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if (connectorID == "") == (clientID == "") {
+				return errors.New("specify either --connector-id or --client-id")
+			}
+			return options.run(func(ctx context.Context, client api.DexClient) error {
+				if clientID != "" {
+					resp, err := client.RevokeRefresh(ctx, &api.RevokeRefreshReq{UserId: args[0], ClientId: clientID})
+					if err != nil {
+						return err
+					}
+					if resp.NotFound {
+						return fmt.Errorf("no session of %q for client %q", args[0], clientID)
+					}
+					fmt.Printf("Revoked the session of %s for client %s\n", args[0], clientID)
+					return nil
+				}
+
+				resp, err := client.RevokeUserSessions(ctx, &api.RevokeUserSessionsReq{UserId: args[0], ConnectorId: connectorID})
+				if err != nil {
+					return err
+				}
+				if resp.NotFound {
+					return fmt.Errorf("no sessions of %q of connector %q", args[0], connectorID)
+				}
+				fmt.Printf("Revoked %d refresh tokens of %s\n", resp.RevokedRefreshTokens, args[0])
+				return nil
+			})
+		},
+	}
+	revokeCmd.Flags().StringVar(&connectorID, "connector-id", "", "Connector of the user")
+	revokeCmd.Flags().StringVar(&clientID, "client-id", "", "Only revoke the session of this client")
+	cmd.AddCommand(revokeCmd)
+
+	return cmd
+}
@@ -6,6 +6,22 @@ import (
 	"net/http"
 )
 
+// ClaimSource is a source of aggregated or distributed claims.
+//
+// https://openid.net/specs/openid-connect-core-1_0.html#AggregatedDistributedClaims
+type ClaimSource struct {
+	// Claims provided by the source.
+	Claims []string
+
+	// JWT containing the claims, for aggregated claims.
+	JWT string
+
+	// Endpoint serving the claims and the access token used to query it, for
+	// distributed claims.
+	Endpoint    string
+	AccessToken string
+}
+
 // Connector is a mechanism for federating login to a remote identity service.
 //
 // Implementations are expected to implement either the PasswordConnector or
@@ -31,6 +47,17 @@ type Identity struct {
 
 	Groups []string
 
+	// CustomClaims holds additional claims about the user, such as a department
+	// or an employee number, keyed by claim name. They're only added to tokens
+	// if a client requests a custom scope which maps to them.
+	CustomClaims map[string]interface{}
+
+	// ClaimSources holds sources of aggregated and distributed claims, keyed
+	// by source name. Clients are pointed to these sources instead of the
+	// claims being included in ID tokens, which keeps tokens small when a
+	// claim, such as a large list of groups, is served by an upstream API.
+	ClaimSources map[string]ClaimSource
+
 	// ConnectorData holds data used by the connector for subsequent requests after initial
 	// authentication, such as access tokens for upstream provides.
 	//
@@ -103,3 +130,9 @@ type RefreshConnector interface {
 type TokenIdentityConnector interface {
 	TokenIdentity(ctx context.Context, subjectTokenType, subjectToken string) (Identity, error)
 }
+
+// HealthChecker is a connector that can check if its upstream identity
+// provider is reachable, for the detailed health report of the server.
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) error
+}
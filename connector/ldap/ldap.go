@@ -308,11 +308,17 @@ type ldapConnector struct {
 var (
 	_ connector.PasswordConnector = (*ldapConnector)(nil)
 	_ connector.RefreshConnector  = (*ldapConnector)(nil)
+	_ connector.HealthChecker     = (*ldapConnector)(nil)
 )
 
 // do initializes a connection to the LDAP directory and passes it to the
 // provided function. It then performs appropriate teardown or reuse before
 // returning.
+// CheckHealth connects and binds to the server.
+func (c *ldapConnector) CheckHealth(ctx context.Context) error {
+	return c.do(ctx, func(*ldap.Conn) error { return nil })
+}
+
 func (c *ldapConnector) do(_ context.Context, f func(c *ldap.Conn) error) error {
 	// TODO(ericchiang): support context here
 	var (
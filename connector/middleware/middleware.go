@@ -0,0 +1,262 @@
+// Package middleware implements generic transformations of the identities
+// returned by connectors, such as filtering groups or requiring MFA, which can
+// be chained in front of any connector.
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/dexidp/dex/connector"
+)
+
+// Types of middlewares.
+const (
+	TypeGroupFilter     = "groupFilter"
+	TypeGroupRename     = "groupRename"
+	TypeDomainAllowlist = "domainAllowlist"
+	TypeStaticClaims    = "staticClaims"
+	TypeRequireMFA      = "requireMFA"
+	TypeStarlark        = "starlark"
+)
+
+// Config is the config of a middleware. Which fields apply depends on the
+// type.
+type Config struct {
+	// Type of the middleware, e.g. "groupFilter".
+	Type string `json:"type"`
+
+	// groupFilter keeps the groups listed in Groups or matching Pattern, and
+	// staticClaims adds the groups listed in Groups.
+	Groups  []string `json:"groups,omitempty"`
+	Pattern string   `json:"pattern,omitempty"`
+
+	// groupRename renames the groups listed in Renames and then adds Prefix
+	// to all groups.
+	Renames map[string]string `json:"renames,omitempty"`
+	Prefix  string            `json:"prefix,omitempty"`
+
+	// domainAllowlist denies users whose e-mail address isn't in one of
+	// Domains, or isn't verified if RequireVerified is set.
+	Domains         []string `json:"domains,omitempty"`
+	RequireVerified bool     `json:"requireVerified,omitempty"`
+
+	// staticClaims sets the custom claims listed in Claims.
+	Claims map[string]interface{} `json:"claims,omitempty"`
+
+	// requireMFA denies users unless the custom claim Claim, "amr" by
+	// default, contains one of Values, "mfa" by default. The connector has to
+	// provide the claim, e.g. through the customClaims option of the OIDC
+	// connector.
+	Claim  string   `json:"claim,omitempty"`
+	Values []string `json:"values,omitempty"`
+
+	// starlark runs Script, or the script in File, for transformations too
+	// complex for the other middlewares. It must define a function
+	// transform(identity), which gets a dict with the keys user_id, username,
+	// preferred_username, email, email_verified, groups and claims, and
+	// returns it with its changes. It can call deny(reason) to deny the login.
+	// Scripts can't load modules, so they have no access to files or the
+	// network, and are stopped after MaxSteps execution steps, 1000000 by
+	// default, or after Timeout, "1s" by default.
+	Script   string `json:"script,omitempty"`
+	File     string `json:"file,omitempty"`
+	MaxSteps uint64 `json:"maxSteps,omitempty"`
+	Timeout  string `json:"timeout,omitempty"`
+}
+
+// Middleware transforms the identity of a user returned by a connector, or
+// denies the login with a DeniedError.
+type Middleware interface {
+	Process(ctx context.Context, identity connector.Identity) (connector.Identity, error)
+}
+
+// DeniedError is returned by connectors wrapped by middlewares when a
+// middleware denies a login. The reason can be shown to the user.
+type DeniedError struct {
+	Reason string
+}
+
+func (e *DeniedError) Error() string {
+	return "login denied: " + e.Reason
+}
+
+// New creates the middlewares of a list of configs.
+func New(configs []Config) ([]Middleware, error) {
+	middlewares := make([]Middleware, 0, len(configs))
+	for i, c := range configs {
+		m, err := newMiddleware(c)
+		if err != nil {
+			return nil, fmt.Errorf("middleware %d (%s): %v", i, c.Type, err)
+		}
+		middlewares = append(middlewares, m)
+	}
+	return middlewares, nil
+}
+
+func newMiddleware(c Config) (Middleware, error) {
+	switch c.Type {
+	case TypeGroupFilter:
+		if len(c.Groups) == 0 && c.Pattern == "" {
+			return nil, errors.New("no groups or pattern")
+		}
+		f := &groupFilter{groups: c.Groups}
+		if c.Pattern != "" {
+			re, err := regexp.Compile(c.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern: %v", err)
+			}
+			f.pattern = re
+		}
+		return f, nil
+	case TypeGroupRename:
+		if len(c.Renames) == 0 && c.Prefix == "" {
+			return nil, errors.New("no renames or prefix")
+		}
+		return &groupRename{renames: c.Renames, prefix: c.Prefix}, nil
+	case TypeDomainAllowlist:
+		if len(c.Domains) == 0 {
+			return nil, errors.New("no domains")
+		}
+		domains := make([]string, 0, len(c.Domains))
+		for _, d := range c.Domains {
+			domains = append(domains, strings.ToLower(d))
+		}
+		return &domainAllowlist{domains: domains, requireVerified: c.RequireVerified}, nil
+	case TypeStaticClaims:
+		if len(c.Claims) == 0 && len(c.Groups) == 0 {
+			return nil, errors.New("no claims or groups")
+		}
+		return &staticClaims{claims: c.Claims, groups: c.Groups}, nil
+	case TypeRequireMFA:
+		m := &requireMFA{claim: c.Claim, values: c.Values}
+		if m.claim == "" {
+			m.claim = "amr"
+		}
+		if len(m.values) == 0 {
+			m.values = []string{"mfa"}
+		}
+		return m, nil
+	case TypeStarlark:
+		return newStarlarkScript(c)
+	case "":
+		return nil, errors.New("no type")
+	default:
+		return nil, fmt.Errorf("unknown type %q", c.Type)
+	}
+}
+
+type groupFilter struct {
+	groups  []string
+	pattern *regexp.Regexp
+}
+
+func (f *groupFilter) Process(_ context.Context, identity connector.Identity) (connector.Identity, error) {
+	groups := []string{}
+	for _, g := range identity.Groups {
+		if slices.Contains(f.groups, g) || (f.pattern != nil && f.pattern.MatchString(g)) {
+			groups = append(groups, g)
+		}
+	}
+	identity.Groups = groups
+	return identity, nil
+}
+
+type groupRename struct {
+	renames map[string]string
+	prefix  string
+}
+
+func (r *groupRename) Process(_ context.Context, identity connector.Identity) (connector.Identity, error) {
+	groups := make([]string, 0, len(identity.Groups))
+	for _, g := range identity.Groups {
+		if renamed, ok := r.renames[g]; ok {
+			g = renamed
+		}
+		groups = append(groups, r.prefix+g)
+	}
+	identity.Groups = groups
+	return identity, nil
+}
+
+type domainAllowlist struct {
+	domains         []string
+	requireVerified bool
+}
+
+func (a *domainAllowlist) Process(_ context.Context, identity connector.Identity) (connector.Identity, error) {
+	_, domain, ok := strings.Cut(identity.Email, "@")
+	if !ok || !slices.Contains(a.domains, strings.ToLower(domain)) {
+		return identity, &DeniedError{Reason: "Your e-mail domain is not allowed."}
+	}
+	if a.requireVerified && !identity.EmailVerified {
+		return identity, &DeniedError{Reason: "Your e-mail address is not verified."}
+	}
+	return identity, nil
+}
+
+type staticClaims struct {
+	claims map[string]interface{}
+	groups []string
+}
+
+func (s *staticClaims) Process(_ context.Context, identity connector.Identity) (connector.Identity, error) {
+	if len(s.claims) > 0 {
+		// Copy the claims, the map may be shared with the connector.
+		claims := make(map[string]interface{}, len(identity.CustomClaims)+len(s.claims))
+		maps.Copy(claims, identity.CustomClaims)
+		maps.Copy(claims, s.claims)
+		identity.CustomClaims = claims
+	}
+	groups := slices.Clone(identity.Groups)
+	for _, g := range s.groups {
+		if !slices.Contains(groups, g) {
+			groups = append(groups, g)
+		}
+	}
+	identity.Groups = groups
+	return identity, nil
+}
+
+type requireMFA struct {
+	claim  string
+	values []string
+}
+
+func (m *requireMFA) Process(_ context.Context, identity connector.Identity) (connector.Identity, error) {
+	var got []string
+	switch v := identity.CustomClaims[m.claim].(type) {
+	case string:
+		got = []string{v}
+	case []string:
+		got = v
+	case []interface{}:
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				got = append(got, s)
+			}
+		}
+	}
+	for _, v := range got {
+		if slices.Contains(m.values, v) {
+			return identity, nil
+		}
+	}
+	return identity, &DeniedError{Reason: "Multi-factor authentication is required."}
+}
+
+// process runs an identity through a chain of middlewares.
+func process(ctx context.Context, middlewares []Middleware, identity connector.Identity) (connector.Identity, error) {
+	for _, m := range middlewares {
+		var err error
+		if identity, err = m.Process(ctx, identity); err != nil {
+			return identity, err
+		}
+	}
+	return identity, nil
+}
@@ -0,0 +1,220 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dexidp/dex/connector"
+	"github.com/dexidp/dex/connector/mock"
+)
+
+func TestMiddleware(t *testing.T) {
+	identity := connector.Identity{
+		UserID:        "1",
+		Email:         "jane@Example.com",
+		EmailVerified: true,
+		Groups:        []string{"eng-admins", "eng-dev", "staff"},
+		CustomClaims:  map[string]interface{}{"amr": []interface{}{"pwd", "otp"}},
+	}
+
+	tests := []struct {
+		name       string
+		configs    []Config
+		wantGroups []string
+		wantClaims map[string]interface{}
+		wantDenied bool
+	}{
+		{
+			name:       "group filter",
+			configs:    []Config{{Type: TypeGroupFilter, Groups: []string{"staff"}, Pattern: "-admins$"}},
+			wantGroups: []string{"eng-admins", "staff"},
+		},
+		{
+			name: "filter then rename",
+			configs: []Config{
+				{Type: TypeGroupFilter, Pattern: "^eng-"},
+				{Type: TypeGroupRename, Renames: map[string]string{"eng-admins": "admins"}, Prefix: "corp:"},
+			},
+			wantGroups: []string{"corp:admins", "corp:eng-dev"},
+		},
+		{
+			name:       "allowed domain",
+			configs:    []Config{{Type: TypeDomainAllowlist, Domains: []string{"example.com"}, RequireVerified: true}},
+			wantGroups: identity.Groups,
+		},
+		{
+			name:       "other domain",
+			configs:    []Config{{Type: TypeDomainAllowlist, Domains: []string{"example.org"}}},
+			wantDenied: true,
+		},
+		{
+			name:       "static claims",
+			configs:    []Config{{Type: TypeStaticClaims, Claims: map[string]interface{}{"tenant": "corp"}, Groups: []string{"staff", "corp"}}},
+			wantGroups: []string{"eng-admins", "eng-dev", "staff", "corp"},
+			wantClaims: map[string]interface{}{"amr": []interface{}{"pwd", "otp"}, "tenant": "corp"},
+		},
+		{
+			name:       "mfa",
+			configs:    []Config{{Type: TypeRequireMFA, Values: []string{"otp", "hwk"}}},
+			wantGroups: identity.Groups,
+		},
+		{
+			name:       "no mfa",
+			configs:    []Config{{Type: TypeRequireMFA}},
+			wantDenied: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			middlewares, err := New(tc.configs)
+			require.NoError(t, err)
+			got, err := process(context.Background(), middlewares, identity)
+			if tc.wantDenied {
+				var denied *DeniedError
+				require.True(t, errors.As(err, &denied), "got %v", err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantGroups, got.Groups)
+			if tc.wantClaims != nil {
+				require.Equal(t, tc.wantClaims, got.CustomClaims)
+			}
+		})
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	for _, c := range []Config{
+		{},
+		{Type: "unknown"},
+		{Type: TypeGroupFilter},
+		{Type: TypeGroupFilter, Pattern: "("},
+		{Type: TypeGroupRename},
+		{Type: TypeDomainAllowlist},
+		{Type: TypeStaticClaims},
+	} {
+		_, err := New([]Config{c})
+		require.Error(t, err, "%+v", c)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	middlewares, err := New([]Config{{Type: TypeGroupRename, Prefix: "mock:"}})
+	require.NoError(t, err)
+
+	require.Equal(t, connector.Connector(nil), Wrap(nil, middlewares))
+
+	callback := Wrap(mock.NewCallbackConnector(logger), middlewares)
+	require.Implements(t, (*connector.CallbackConnector)(nil), callback)
+	require.Implements(t, (*connector.RefreshConnector)(nil), callback)
+	require.Implements(t, (*connector.TokenIdentityConnector)(nil), callback)
+	_, ok := callback.(connector.PasswordConnector)
+	require.False(t, ok)
+	_, ok = callback.(connector.HealthChecker)
+	require.False(t, ok)
+
+	identity, err := callback.(connector.CallbackConnector).HandleCallback(connector.Scopes{}, httptest.NewRequest("GET", "/callback", nil))
+	require.NoError(t, err)
+	require.Equal(t, []string{"mock:authors"}, identity.Groups)
+	identity, err = callback.(connector.RefreshConnector).Refresh(context.Background(), connector.Scopes{}, identity)
+	require.NoError(t, err)
+	require.Equal(t, []string{"mock:authors"}, identity.Groups)
+
+	config := mock.PasswordConfig{Username: "jane", Password: "secret"}
+	conn, err := config.Open("mock", logger)
+	require.NoError(t, err)
+	password := Wrap(conn, []Middleware{&domainAllowlist{domains: []string{"example.com"}}})
+	require.Implements(t, (*connector.PasswordConnector)(nil), password)
+	require.Implements(t, (*connector.RefreshConnector)(nil), password)
+	_, ok = password.(connector.CallbackConnector)
+	require.False(t, ok)
+	_, ok = password.(connector.TokenIdentityConnector)
+	require.False(t, ok)
+
+	_, valid, err := password.(connector.PasswordConnector).Login(context.Background(), connector.Scopes{}, "jane", "wrong")
+	require.NoError(t, err)
+	require.False(t, valid)
+	_, valid, err = password.(connector.PasswordConnector).Login(context.Background(), connector.Scopes{}, "jane", "secret")
+	require.Error(t, err, "the e-mail domain of the mock user isn't allowed")
+	require.False(t, valid)
+}
+
+func TestStarlark(t *testing.T) {
+	script := `
+def transform(identity):
+    if "contractors" in identity["groups"]:
+        deny("Contractors can't log in.")
+    identity["username"] = identity["username"].lower()
+    if "eng" in identity["groups"] and identity["claims"].get("level", 0) >= 3:
+        identity["groups"].append("eng-senior")
+    identity["claims"]["source"] = "starlark"
+    return identity
+`
+	middlewares, err := New([]Config{{Type: TypeStarlark, Script: script}})
+	require.NoError(t, err)
+
+	identity := connector.Identity{
+		UserID:       "1",
+		Username:     "Jane",
+		Groups:       []string{"eng"},
+		CustomClaims: map[string]interface{}{"level": float64(3)},
+	}
+	got, err := process(context.Background(), middlewares, identity)
+	require.NoError(t, err)
+	require.Equal(t, "jane", got.Username)
+	require.Equal(t, "1", got.UserID)
+	require.Equal(t, []string{"eng", "eng-senior"}, got.Groups)
+	require.Equal(t, map[string]interface{}{"level": int64(3), "source": "starlark"}, got.CustomClaims)
+	require.Equal(t, []string{"eng"}, identity.Groups, "the identity passed in isn't modified")
+
+	identity.Groups = []string{"contractors"}
+	_, err = process(context.Background(), middlewares, identity)
+	var denied *DeniedError
+	require.True(t, errors.As(err, &denied), "got %v", err)
+	require.Equal(t, "Contractors can't log in.", denied.Reason)
+
+	loop := `
+def transform(identity):
+    for i in range(100000000):
+        pass
+    return identity
+`
+	middlewares, err = New([]Config{{Type: TypeStarlark, Script: loop, MaxSteps: 1000}})
+	require.NoError(t, err)
+	_, err = process(context.Background(), middlewares, identity)
+	require.Error(t, err, "the script is stopped after MaxSteps")
+	require.False(t, errors.As(err, &denied))
+}
+
+func TestStarlarkInvalid(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "script.star")
+	require.NoError(t, os.WriteFile(file, []byte("def transform(identity):\n    return identity\n"), 0o600))
+	_, err := New([]Config{{Type: TypeStarlark, File: file}})
+	require.NoError(t, err)
+
+	for _, c := range []Config{
+		{Type: TypeStarlark},
+		{Type: TypeStarlark, Script: "def transform(identity):\n    return identity\n", File: file},
+		{Type: TypeStarlark, Script: "def other(identity):\n    return identity\n"},
+		{Type: TypeStarlark, Script: "def transform(identity)\n"},
+		{Type: TypeStarlark, Script: "load('os.star', 'system')\n"},
+		{Type: TypeStarlark, File: file, Timeout: "soon"},
+	} {
+		_, err := New([]Config{c})
+		require.Error(t, err, "%+v", c)
+	}
+
+	middlewares, err := New([]Config{{Type: TypeStarlark, Script: "def transform(identity):\n    return identity['groups']\n"}})
+	require.NoError(t, err)
+	_, err = process(context.Background(), middlewares, connector.Identity{})
+	require.Error(t, err, "transform must return a dict")
+}
@@ -0,0 +1,319 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"go.starlark.net/starlark"
+
+	"github.com/dexidp/dex/connector"
+)
+
+// Limits of starlark scripts.
+const (
+	maxStarlarkScriptSize     = 1 << 20
+	defaultStarlarkMaxSteps   = 1000000
+	defaultStarlarkTimeout    = time.Second
+	starlarkTransformFunction = "transform"
+)
+
+type starlarkScript struct {
+	transform starlark.Callable
+	maxSteps  uint64
+	timeout   time.Duration
+}
+
+func newStarlarkScript(c Config) (*starlarkScript, error) {
+	filename, src := "script.star", []byte(c.Script)
+	switch {
+	case c.Script != "" && c.File != "":
+		return nil, errors.New("both script and file set")
+	case c.File != "":
+		info, err := os.Stat(c.File)
+		if err != nil {
+			return nil, err
+		}
+		if info.Size() > maxStarlarkScriptSize {
+			return nil, fmt.Errorf("script larger than %d bytes", maxStarlarkScriptSize)
+		}
+		if src, err = os.ReadFile(c.File); err != nil {
+			return nil, err
+		}
+		filename = c.File
+	case c.Script == "":
+		return nil, errors.New("no script or file")
+	}
+	if len(src) > maxStarlarkScriptSize {
+		return nil, fmt.Errorf("script larger than %d bytes", maxStarlarkScriptSize)
+	}
+
+	s := &starlarkScript{maxSteps: c.MaxSteps, timeout: defaultStarlarkTimeout}
+	if s.maxSteps == 0 {
+		s.maxSteps = defaultStarlarkMaxSteps
+	}
+	if c.Timeout != "" {
+		timeout, err := time.ParseDuration(c.Timeout)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid timeout %q", c.Timeout)
+		}
+		s.timeout = timeout
+	}
+
+	// The top level of the script runs once, with the same limits as calls.
+	thread := s.newThread()
+	stop := time.AfterFunc(s.timeout, func() { thread.Cancel("timeout") })
+	globals, err := starlark.ExecFile(thread, filename, src, starlarkBuiltins)
+	stop.Stop()
+	if err != nil {
+		return nil, err
+	}
+	transform, ok := globals[starlarkTransformFunction].(starlark.Callable)
+	if !ok {
+		return nil, fmt.Errorf("script doesn't define a function %s(identity)", starlarkTransformFunction)
+	}
+	s.transform = transform
+	return s, nil
+}
+
+// starlarkBuiltins are the functions available to scripts besides the
+// universe of starlark. Scripts can't load modules, so they have no access to
+// files or the network.
+var starlarkBuiltins = starlark.StringDict{
+	"deny": starlark.NewBuiltin("deny", func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var reason string
+		if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &reason); err != nil {
+			return nil, err
+		}
+		return nil, &DeniedError{Reason: reason}
+	}),
+}
+
+func (s *starlarkScript) newThread() *starlark.Thread {
+	thread := &starlark.Thread{
+		Name:  "middleware",
+		Print: func(*starlark.Thread, string) {},
+	}
+	thread.SetMaxExecutionSteps(s.maxSteps)
+	return thread
+}
+
+func (s *starlarkScript) Process(ctx context.Context, identity connector.Identity) (connector.Identity, error) {
+	arg, err := identityToStarlark(identity)
+	if err != nil {
+		return identity, err
+	}
+
+	thread := s.newThread()
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	stop := context.AfterFunc(ctx, func() { thread.Cancel(ctx.Err().Error()) })
+	defer stop()
+
+	result, err := starlark.Call(thread, s.transform, starlark.Tuple{arg}, nil)
+	if err != nil {
+		var denied *DeniedError
+		if errors.As(err, &denied) {
+			return identity, denied
+		}
+		return identity, fmt.Errorf("starlark: %v", err)
+	}
+	dict, ok := result.(*starlark.Dict)
+	if !ok {
+		return identity, fmt.Errorf("starlark: %s returned %s, not a dict", starlarkTransformFunction, result.Type())
+	}
+	if identity, err = identityFromStarlark(identity, dict); err != nil {
+		return identity, fmt.Errorf("starlark: %v", err)
+	}
+	return identity, nil
+}
+
+func identityToStarlark(identity connector.Identity) (*starlark.Dict, error) {
+	groups := make([]interface{}, 0, len(identity.Groups))
+	for _, g := range identity.Groups {
+		groups = append(groups, g)
+	}
+	claims := identity.CustomClaims
+	if claims == nil {
+		claims = map[string]interface{}{}
+	}
+	v, err := toStarlark(map[string]interface{}{
+		"user_id":            identity.UserID,
+		"username":           identity.Username,
+		"preferred_username": identity.PreferredUsername,
+		"email":              identity.Email,
+		"email_verified":     identity.EmailVerified,
+		"groups":             groups,
+		"claims":             claims,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*starlark.Dict), nil
+}
+
+// identityFromStarlark sets the fields of an identity present in the dict
+// returned by a script.
+func identityFromStarlark(identity connector.Identity, dict *starlark.Dict) (connector.Identity, error) {
+	v, err := fromStarlark(dict)
+	if err != nil {
+		return identity, err
+	}
+	values := v.(map[string]interface{})
+	for _, f := range []struct {
+		key   string
+		field *string
+	}{
+		{"user_id", &identity.UserID},
+		{"username", &identity.Username},
+		{"preferred_username", &identity.PreferredUsername},
+		{"email", &identity.Email},
+	} {
+		v, ok := values[f.key]
+		if !ok {
+			continue
+		}
+		s, ok := v.(string)
+		if !ok {
+			return identity, fmt.Errorf("%s must be a string", f.key)
+		}
+		*f.field = s
+	}
+	if v, ok := values["email_verified"]; ok {
+		verified, ok := v.(bool)
+		if !ok {
+			return identity, errors.New("email_verified must be a bool")
+		}
+		identity.EmailVerified = verified
+	}
+	if v, ok := values["groups"]; ok {
+		list, ok := v.([]interface{})
+		if !ok {
+			return identity, errors.New("groups must be a list")
+		}
+		groups := make([]string, 0, len(list))
+		for _, g := range list {
+			s, ok := g.(string)
+			if !ok {
+				return identity, errors.New("groups must be strings")
+			}
+			groups = append(groups, s)
+		}
+		identity.Groups = groups
+	}
+	if v, ok := values["claims"]; ok {
+		claims, ok := v.(map[string]interface{})
+		if !ok {
+			return identity, errors.New("claims must be a dict")
+		}
+		identity.CustomClaims = claims
+	}
+	return identity, nil
+}
+
+// toStarlark converts a JSON like value to starlark.
+func toStarlark(v interface{}) (starlark.Value, error) {
+	switch v := v.(type) {
+	case nil:
+		return starlark.None, nil
+	case bool:
+		return starlark.Bool(v), nil
+	case string:
+		return starlark.String(v), nil
+	case int:
+		return starlark.MakeInt(v), nil
+	case int64:
+		return starlark.MakeInt64(v), nil
+	case float64:
+		// Numbers decoded from JSON are floats.
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return starlark.MakeInt64(int64(v)), nil
+		}
+		return starlark.Float(v), nil
+	case []string:
+		list := make([]starlark.Value, 0, len(v))
+		for _, s := range v {
+			list = append(list, starlark.String(s))
+		}
+		return starlark.NewList(list), nil
+	case []interface{}:
+		list := make([]starlark.Value, 0, len(v))
+		for _, e := range v {
+			sv, err := toStarlark(e)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, sv)
+		}
+		return starlark.NewList(list), nil
+	case map[string]interface{}:
+		dict := starlark.NewDict(len(v))
+		for k, e := range v {
+			sv, err := toStarlark(e)
+			if err != nil {
+				return nil, err
+			}
+			if err := dict.SetKey(starlark.String(k), sv); err != nil {
+				return nil, err
+			}
+		}
+		return dict, nil
+	default:
+		return nil, fmt.Errorf("unsupported value of type %T", v)
+	}
+}
+
+// fromStarlark converts a starlark value to a JSON like value.
+func fromStarlark(v starlark.Value) (interface{}, error) {
+	switch v := v.(type) {
+	case starlark.NoneType:
+		return nil, nil
+	case starlark.Bool:
+		return bool(v), nil
+	case starlark.String:
+		return string(v), nil
+	case starlark.Int:
+		i, ok := v.Int64()
+		if !ok {
+			return nil, fmt.Errorf("integer %s out of range", v)
+		}
+		return i, nil
+	case starlark.Float:
+		return float64(v), nil
+	case *starlark.List:
+		return fromStarlarkIterable(v)
+	case starlark.Tuple:
+		return fromStarlarkIterable(v)
+	case *starlark.Dict:
+		m := make(map[string]interface{}, v.Len())
+		for _, item := range v.Items() {
+			k, ok := item[0].(starlark.String)
+			if !ok {
+				return nil, fmt.Errorf("dict key %s is not a string", item[0])
+			}
+			e, err := fromStarlark(item[1])
+			if err != nil {
+				return nil, err
+			}
+			m[string(k)] = e
+		}
+		return m, nil
+	default:
+		return nil, fmt.Errorf("unsupported value of type %s", v.Type())
+	}
+}
+
+func fromStarlarkIterable(v starlark.Indexable) ([]interface{}, error) {
+	list := make([]interface{}, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		e, err := fromStarlark(v.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+	return list, nil
+}
@@ -0,0 +1,329 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/dexidp/dex/connector"
+)
+
+// Wrap returns a connector which runs the identities returned by c through the
+// middlewares. It implements the same connector interfaces as c, so that the
+// server treats it like c. Connectors implementing none of the login
+// interfaces are returned unchanged.
+func Wrap(c connector.Connector, middlewares []Middleware) connector.Connector {
+	if len(middlewares) == 0 {
+		return c
+	}
+
+	var (
+		r             refreshFacet
+		t             tokenIdentityFacet
+		h             healthFacet
+		rOK, tOK, hOK bool
+	)
+	if conn, ok := c.(connector.RefreshConnector); ok {
+		r, rOK = refreshFacet{conn, middlewares}, true
+	}
+	if conn, ok := c.(connector.TokenIdentityConnector); ok {
+		t, tOK = tokenIdentityFacet{conn, middlewares}, true
+	}
+	if conn, ok := c.(connector.HealthChecker); ok {
+		h, hOK = healthFacet{conn}, true
+	}
+
+	// The server checks for the login interfaces in this order.
+	switch conn := c.(type) {
+	case connector.CallbackConnector:
+		return wrapCallback(callbackFacet{conn, middlewares}, r, t, h, rOK, tOK, hOK)
+	case connector.PasswordConnector:
+		return wrapPassword(passwordFacet{conn, middlewares}, r, t, h, rOK, tOK, hOK)
+	case connector.SAMLConnector:
+		return wrapSAML(samlFacet{conn, middlewares}, r, t, h, rOK, tOK, hOK)
+	}
+	return c
+}
+
+// The wrappers combine the facet of a login interface with the facets of the
+// optional interfaces the wrapped connector implements.
+
+func wrapCallback(l callbackFacet, r refreshFacet, t tokenIdentityFacet, h healthFacet, rOK, tOK, hOK bool) connector.Connector {
+	switch {
+	case rOK && tOK && hOK:
+		return callbackRefreshTokenIdentityHealth{l, r, t, h}
+	case rOK && tOK && !hOK:
+		return callbackRefreshTokenIdentity{l, r, t}
+	case rOK && !tOK && hOK:
+		return callbackRefreshHealth{l, r, h}
+	case rOK && !tOK && !hOK:
+		return callbackRefresh{l, r}
+	case !rOK && tOK && hOK:
+		return callbackTokenIdentityHealth{l, t, h}
+	case !rOK && tOK && !hOK:
+		return callbackTokenIdentity{l, t}
+	case !rOK && !tOK && hOK:
+		return callbackHealth{l, h}
+	default:
+		return callbackOnly{l}
+	}
+}
+
+func wrapPassword(l passwordFacet, r refreshFacet, t tokenIdentityFacet, h healthFacet, rOK, tOK, hOK bool) connector.Connector {
+	switch {
+	case rOK && tOK && hOK:
+		return passwordRefreshTokenIdentityHealth{l, r, t, h}
+	case rOK && tOK && !hOK:
+		return passwordRefreshTokenIdentity{l, r, t}
+	case rOK && !tOK && hOK:
+		return passwordRefreshHealth{l, r, h}
+	case rOK && !tOK && !hOK:
+		return passwordRefresh{l, r}
+	case !rOK && tOK && hOK:
+		return passwordTokenIdentityHealth{l, t, h}
+	case !rOK && tOK && !hOK:
+		return passwordTokenIdentity{l, t}
+	case !rOK && !tOK && hOK:
+		return passwordHealth{l, h}
+	default:
+		return passwordOnly{l}
+	}
+}
+
+func wrapSAML(l samlFacet, r refreshFacet, t tokenIdentityFacet, h healthFacet, rOK, tOK, hOK bool) connector.Connector {
+	switch {
+	case rOK && tOK && hOK:
+		return samlRefreshTokenIdentityHealth{l, r, t, h}
+	case rOK && tOK && !hOK:
+		return samlRefreshTokenIdentity{l, r, t}
+	case rOK && !tOK && hOK:
+		return samlRefreshHealth{l, r, h}
+	case rOK && !tOK && !hOK:
+		return samlRefresh{l, r}
+	case !rOK && tOK && hOK:
+		return samlTokenIdentityHealth{l, t, h}
+	case !rOK && tOK && !hOK:
+		return samlTokenIdentity{l, t}
+	case !rOK && !tOK && hOK:
+		return samlHealth{l, h}
+	default:
+		return samlOnly{l}
+	}
+}
+
+type callbackRefreshTokenIdentityHealth struct {
+	callbackFacet
+	refreshFacet
+	tokenIdentityFacet
+	healthFacet
+}
+
+type callbackRefreshTokenIdentity struct {
+	callbackFacet
+	refreshFacet
+	tokenIdentityFacet
+}
+
+type callbackRefreshHealth struct {
+	callbackFacet
+	refreshFacet
+	healthFacet
+}
+
+type callbackRefresh struct {
+	callbackFacet
+	refreshFacet
+}
+
+type callbackTokenIdentityHealth struct {
+	callbackFacet
+	tokenIdentityFacet
+	healthFacet
+}
+
+type callbackTokenIdentity struct {
+	callbackFacet
+	tokenIdentityFacet
+}
+
+type callbackHealth struct {
+	callbackFacet
+	healthFacet
+}
+
+type callbackOnly struct {
+	callbackFacet
+}
+
+type passwordRefreshTokenIdentityHealth struct {
+	passwordFacet
+	refreshFacet
+	tokenIdentityFacet
+	healthFacet
+}
+
+type passwordRefreshTokenIdentity struct {
+	passwordFacet
+	refreshFacet
+	tokenIdentityFacet
+}
+
+type passwordRefreshHealth struct {
+	passwordFacet
+	refreshFacet
+	healthFacet
+}
+
+type passwordRefresh struct {
+	passwordFacet
+	refreshFacet
+}
+
+type passwordTokenIdentityHealth struct {
+	passwordFacet
+	tokenIdentityFacet
+	healthFacet
+}
+
+type passwordTokenIdentity struct {
+	passwordFacet
+	tokenIdentityFacet
+}
+
+type passwordHealth struct {
+	passwordFacet
+	healthFacet
+}
+
+type passwordOnly struct {
+	passwordFacet
+}
+
+type samlRefreshTokenIdentityHealth struct {
+	samlFacet
+	refreshFacet
+	tokenIdentityFacet
+	healthFacet
+}
+
+type samlRefreshTokenIdentity struct {
+	samlFacet
+	refreshFacet
+	tokenIdentityFacet
+}
+
+type samlRefreshHealth struct {
+	samlFacet
+	refreshFacet
+	healthFacet
+}
+
+type samlRefresh struct {
+	samlFacet
+	refreshFacet
+}
+
+type samlTokenIdentityHealth struct {
+	samlFacet
+	tokenIdentityFacet
+	healthFacet
+}
+
+type samlTokenIdentity struct {
+	samlFacet
+	tokenIdentityFacet
+}
+
+type samlHealth struct {
+	samlFacet
+	healthFacet
+}
+
+type samlOnly struct {
+	samlFacet
+}
+
+type refreshFacet struct {
+	conn        connector.RefreshConnector
+	middlewares []Middleware
+}
+
+func (f refreshFacet) Refresh(ctx context.Context, s connector.Scopes, identity connector.Identity) (connector.Identity, error) {
+	identity, err := f.conn.Refresh(ctx, s, identity)
+	if err != nil {
+		return identity, err
+	}
+	return process(ctx, f.middlewares, identity)
+}
+
+type tokenIdentityFacet struct {
+	conn        connector.TokenIdentityConnector
+	middlewares []Middleware
+}
+
+func (f tokenIdentityFacet) TokenIdentity(ctx context.Context, subjectTokenType, subjectToken string) (connector.Identity, error) {
+	identity, err := f.conn.TokenIdentity(ctx, subjectTokenType, subjectToken)
+	if err != nil {
+		return identity, err
+	}
+	return process(ctx, f.middlewares, identity)
+}
+
+type healthFacet struct {
+	conn connector.HealthChecker
+}
+
+func (f healthFacet) CheckHealth(ctx context.Context) error {
+	return f.conn.CheckHealth(ctx)
+}
+
+type callbackFacet struct {
+	conn        connector.CallbackConnector
+	middlewares []Middleware
+}
+
+func (f callbackFacet) LoginURL(s connector.Scopes, callbackURL, state string) (string, error) {
+	return f.conn.LoginURL(s, callbackURL, state)
+}
+
+func (f callbackFacet) HandleCallback(s connector.Scopes, r *http.Request) (connector.Identity, error) {
+	identity, err := f.conn.HandleCallback(s, r)
+	if err != nil {
+		return identity, err
+	}
+	return process(r.Context(), f.middlewares, identity)
+}
+
+type passwordFacet struct {
+	conn        connector.PasswordConnector
+	middlewares []Middleware
+}
+
+func (f passwordFacet) Prompt() string {
+	return f.conn.Prompt()
+}
+
+func (f passwordFacet) Login(ctx context.Context, s connector.Scopes, username, password string) (connector.Identity, bool, error) {
+	identity, ok, err := f.conn.Login(ctx, s, username, password)
+	if err != nil || !ok {
+		return identity, ok, err
+	}
+	identity, err = process(ctx, f.middlewares, identity)
+	return identity, err == nil, err
+}
+
+type samlFacet struct {
+	conn        connector.SAMLConnector
+	middlewares []Middleware
+}
+
+func (f samlFacet) POSTData(s connector.Scopes, requestID string) (string, string, error) {
+	return f.conn.POSTData(s, requestID)
+}
+
+func (f samlFacet) HandlePOST(s connector.Scopes, samlResponse, inResponseTo string) (connector.Identity, error) {
+	identity, err := f.conn.HandlePOST(s, samlResponse, inResponseTo)
+	if err != nil {
+		return identity, err
+	}
+	return process(context.Background(), f.middlewares, identity)
+}
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/url"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -100,6 +101,15 @@ type Config struct {
 		GroupsKey string `json:"groups"` // defaults to "groups"
 	} `json:"claimMapping"`
 
+	// CustomClaims lists upstream claims which are kept as custom claims of the
+	// identity, so they can be released to clients through custom scopes.
+	CustomClaims []string `json:"customClaims"`
+
+	// ForwardDistributedClaims keeps the distributed and aggregated claims of
+	// the upstream provider, such as a group overage pointing at an API
+	// endpoint, so dex can forward them to clients instead of dropping them.
+	ForwardDistributedClaims bool `json:"forwardDistributedClaims"`
+
 	// ClaimMutations holds all claim mutations options
 	ClaimMutations struct {
 		NewGroupFromClaims []NewGroupFromClaims `json:"newGroupFromClaims"`
@@ -307,12 +317,15 @@ func (c *Config) Open(id string, logger *slog.Logger) (conn connector.Connector,
 		groupsKey:                 c.ClaimMapping.GroupsKey,
 		newGroupFromClaims:        c.ClaimMutations.NewGroupFromClaims,
 		groupsFilter:              groupsFilter,
+		customClaims:              c.CustomClaims,
+		forwardDistributedClaims:  c.ForwardDistributedClaims,
 	}, nil
 }
 
 var (
 	_ connector.CallbackConnector = (*oidcConnector)(nil)
 	_ connector.RefreshConnector  = (*oidcConnector)(nil)
+	_ connector.HealthChecker     = (*oidcConnector)(nil)
 )
 
 type oidcConnector struct {
@@ -337,6 +350,8 @@ type oidcConnector struct {
 	groupsKey                 string
 	newGroupFromClaims        []NewGroupFromClaims
 	groupsFilter              *regexp.Regexp
+	customClaims              []string
+	forwardDistributedClaims  bool
 }
 
 func (c *oidcConnector) Close() error {
@@ -344,6 +359,24 @@ func (c *oidcConnector) Close() error {
 	return nil
 }
 
+// CheckHealth requests the authorization endpoint of the provider. Any
+// response but a server error means the provider is reachable.
+func (c *oidcConnector) CheckHealth(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.oauth2Config.Endpoint.AuthURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("oidc: request authorization endpoint: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("oidc: authorization endpoint responded with %s", resp.Status)
+	}
+	return nil
+}
+
 func (c *oidcConnector) LoginURL(s connector.Scopes, callbackURL, state string) (string, error) {
 	if c.redirectURI != callbackURL {
 		return "", fmt.Errorf("expected callback URL %q did not match the URL in the config %q", callbackURL, c.redirectURI)
@@ -616,6 +649,19 @@ func (c *oidcConnector) createIdentity(ctx context.Context, identity connector.I
 		ConnectorData:     connData,
 	}
 
+	for _, claim := range c.customClaims {
+		if v, ok := claims[claim]; ok {
+			if identity.CustomClaims == nil {
+				identity.CustomClaims = make(map[string]interface{})
+			}
+			identity.CustomClaims[claim] = v
+		}
+	}
+
+	if c.forwardDistributedClaims {
+		identity.ClaimSources = claimSources(claims)
+	}
+
 	if c.userIDKey != "" {
 		userID, found := claims[c.userIDKey].(string)
 		if !found {
@@ -626,3 +672,39 @@ func (c *oidcConnector) createIdentity(ctx context.Context, identity connector.I
 
 	return identity, nil
 }
+
+// claimSources reads the sources of distributed and aggregated claims from
+// the _claim_names and _claim_sources claims.
+//
+// https://openid.net/specs/openid-connect-core-1_0.html#AggregatedDistributedClaims
+func claimSources(claims map[string]interface{}) map[string]connector.ClaimSource {
+	names, _ := claims["_claim_names"].(map[string]interface{})
+	sources, _ := claims["_claim_sources"].(map[string]interface{})
+
+	var claimSources map[string]connector.ClaimSource
+	for claim, v := range names {
+		name, _ := v.(string)
+		src, ok := sources[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		source, ok := claimSources[name]
+		if !ok {
+			source.JWT, _ = src["JWT"].(string)
+			source.Endpoint, _ = src["endpoint"].(string)
+			source.AccessToken, _ = src["access_token"].(string)
+			if source.JWT == "" && source.Endpoint == "" {
+				continue
+			}
+		}
+		source.Claims = append(source.Claims, claim)
+		if claimSources == nil {
+			claimSources = make(map[string]connector.ClaimSource)
+		}
+		claimSources[name] = source
+	}
+	for _, source := range claimSources {
+		sort.Strings(source.Claims)
+	}
+	return claimSources
+}
@@ -66,6 +66,10 @@ func TestHandleCallback(t *testing.T) {
 		token                     map[string]interface{}
 		groupsRegex               string
 		newGroupFromClaims        []NewGroupFromClaims
+		customClaims              []string
+		expectCustomClaims        map[string]interface{}
+		forwardDistributedClaims  bool
+		expectClaimSources        map[string]connector.ClaimSource
 	}{
 		{
 			name:               "simpleCase",
@@ -431,6 +435,59 @@ func TestHandleCallback(t *testing.T) {
 				"email_verified": true,
 			},
 		},
+		{
+			name:               "customClaims",
+			userIDKey:          "", // not configured
+			userNameKey:        "", // not configured
+			customClaims:       []string{"department", "employee_id", "cost_center"},
+			expectUserID:       "subvalue",
+			expectUserName:     "namevalue",
+			expectGroups:       []string{"group1", "group2"},
+			expectedEmailField: "emailvalue",
+			expectCustomClaims: map[string]interface{}{
+				"department":  "engineering",
+				"employee_id": "e-1234",
+			},
+			token: map[string]interface{}{
+				"sub":            "subvalue",
+				"name":           "namevalue",
+				"groups":         []string{"group1", "group2"},
+				"email":          "emailvalue",
+				"email_verified": true,
+				"department":     "engineering",
+				"employee_id":    "e-1234",
+				"office":         "berlin",
+			},
+		},
+		{
+			name:                     "distributedClaims",
+			forwardDistributedClaims: true,
+			expectUserID:             "subvalue",
+			expectUserName:           "namevalue",
+			expectedEmailField:       "emailvalue",
+			expectClaimSources: map[string]connector.ClaimSource{
+				"src1": {
+					Claims:      []string{"groups"},
+					Endpoint:    "https://graph.example.com/v1.0/users/1/getMemberObjects",
+					AccessToken: "graph-token",
+				},
+			},
+			token: map[string]interface{}{
+				"sub":            "subvalue",
+				"name":           "namevalue",
+				"email":          "emailvalue",
+				"email_verified": true,
+				"_claim_names": map[string]string{
+					"groups": "src1",
+				},
+				"_claim_sources": map[string]interface{}{
+					"src1": map[string]string{
+						"endpoint":     "https://graph.example.com/v1.0/users/1/getMemberObjects",
+						"access_token": "graph-token",
+					},
+				},
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -462,6 +519,8 @@ func TestHandleCallback(t *testing.T) {
 				InsecureEnableGroups:      true,
 				BasicAuthUnsupported:      &basicAuth,
 				OverrideClaimMapping:      tc.overrideClaimMapping,
+				CustomClaims:              tc.customClaims,
+				ForwardDistributedClaims:  tc.forwardDistributedClaims,
 			}
 			config.ClaimMapping.PreferredUsernameKey = tc.preferredUsernameKey
 			config.ClaimMapping.EmailKey = tc.emailKey
@@ -490,6 +549,8 @@ func TestHandleCallback(t *testing.T) {
 			expectEquals(t, identity.Email, tc.expectedEmailField)
 			expectEquals(t, identity.EmailVerified, true)
 			expectEquals(t, identity.Groups, tc.expectGroups)
+			expectEquals(t, identity.CustomClaims, tc.expectCustomClaims)
+			expectEquals(t, identity.ClaimSources, tc.expectClaimSources)
 		})
 	}
 }
@@ -688,6 +749,18 @@ func TestPromptType(t *testing.T) {
 	}
 }
 
+func TestCheckHealth(t *testing.T) {
+	testServer, err := setupServer(nil, true)
+	require.NoError(t, err)
+
+	conn, err := newConnector(Config{Issuer: testServer.URL, Scopes: []string{"openid"}})
+	require.NoError(t, err)
+	require.NoError(t, conn.CheckHealth(context.Background()))
+
+	testServer.Close()
+	require.Error(t, conn.CheckHealth(context.Background()))
+}
+
 func TestProviderOverride(t *testing.T) {
 	testServer, err := setupServer(map[string]any{
 		"sub":  "subvalue",
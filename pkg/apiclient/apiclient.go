@@ -0,0 +1,86 @@
+// Package apiclient connects to the gRPC API of a Dex server.
+package apiclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// Options configure the connection to the gRPC API.
+type Options struct {
+	// Addr is the address of the API.
+	Addr string
+	// CACert is a file with the CA certificates verifying the server. TLS is
+	// only used if it is set.
+	CACert string
+	// ClientCert and ClientKey are files with a certificate and key for TLS
+	// client authentication.
+	ClientCert string
+	ClientKey  string
+	// Token is a bearer token sent with each call.
+	Token string
+}
+
+// Dial connects to the API. Calls should use a context from Context to send
+// the token.
+func Dial(o Options) (*grpc.ClientConn, error) {
+	if o.Addr == "" {
+		return nil, errors.New("no API address specified")
+	}
+	creds, err := o.TransportCredentials()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := grpc.NewClient(o.Addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("connecting to %s: %v", o.Addr, err)
+	}
+	return conn, nil
+}
+
+// Context returns a context sending the bearer token of the options, if any.
+func (o Options) Context(ctx context.Context) context.Context {
+	if o.Token == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+o.Token)
+}
+
+// TransportCredentials returns the credentials of the connection, TLS if a CA
+// certificate is set and plain text otherwise.
+func (o Options) TransportCredentials() (credentials.TransportCredentials, error) {
+	if o.CACert == "" {
+		if o.ClientCert != "" || o.ClientKey != "" {
+			return nil, errors.New("cannot use a client certificate without a CA certificate")
+		}
+		return insecure.NewCredentials(), nil
+	}
+
+	caCert, err := os.ReadFile(o.CACert)
+	if err != nil {
+		return nil, fmt.Errorf("reading CA certificate: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("no certificates found in %s", o.CACert)
+	}
+	tlsConfig := &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+
+	if o.ClientCert != "" || o.ClientKey != "" {
+		cert, err := tls.LoadX509KeyPair(o.ClientCert, o.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
@@ -9,6 +9,8 @@ import (
 	"net/http"
 	"os"
 	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 func extractCAs(input []string) [][]byte {
@@ -46,8 +48,10 @@ func NewHTTPClient(rootCAs []string, insecureSkipVerify bool) (*http.Client, err
 		}
 	}
 
+	// Requests to upstream identity providers are traced as part of the login
+	// they're made for, with the global OpenTelemetry tracer provider.
 	return &http.Client{
-		Transport: &http.Transport{
+		Transport: otelhttp.NewTransport(&http.Transport{
 			TLSClientConfig: &tlsConfig,
 			Proxy:           http.ProxyFromEnvironment,
 			DialContext: (&net.Dialer{
@@ -59,6 +63,6 @@ func NewHTTPClient(rootCAs []string, insecureSkipVerify bool) (*http.Client, err
 			IdleConnTimeout:       90 * time.Second,
 			TLSHandshakeTimeout:   10 * time.Second,
 			ExpectContinueTimeout: 1 * time.Second,
-		},
+		}),
 	}, nil
 }
@@ -2,11 +2,16 @@ package server
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 
@@ -18,7 +23,7 @@ import (
 
 // apiVersion increases every time a new call is added to the API. Clients should use this info
 // to determine if the server supports specific features.
-const apiVersion = 2
+const apiVersion = 11
 
 const (
 	// recCost is the recommended bcrypt cost, which balances hash strength and
@@ -57,18 +62,82 @@ func (d dexAPI) GetClient(ctx context.Context, req *api.GetClientReq) (*api.GetC
 	}
 
 	return &api.GetClientResp{
-		Client: &api.Client{
-			Id:           c.ID,
-			Name:         c.Name,
-			Secret:       c.Secret,
-			RedirectUris: c.RedirectURIs,
-			TrustedPeers: c.TrustedPeers,
-			Public:       c.Public,
-			LogoUrl:      c.LogoURL,
-		},
+		Client: toAPIClient(c),
 	}, nil
 }
 
+func toAPIClient(c storage.Client) *api.Client {
+	return &api.Client{
+		Id:           c.ID,
+		Name:         c.Name,
+		Secret:       c.Secret,
+		RedirectUris: c.RedirectURIs,
+		TrustedPeers: c.TrustedPeers,
+		Public:       c.Public,
+		LogoUrl:      c.LogoURL,
+
+		RefreshTokenPolicy: toAPIRefreshTokenPolicy(c.RefreshTokenPolicy),
+		TokenExpiry:        toAPITokenExpiry(c.TokenExpiry),
+		GroupsFilter:       toAPIGroupsFilter(c.GroupsFilter),
+		ClaimsTransforms:   toAPIClaimsTransforms(c.ClaimsTransforms),
+
+		RedirectUriPatterns: c.RedirectURIPatterns,
+
+		PolicyUrl:         c.PolicyURL,
+		TermsOfServiceUrl: c.TermsOfServiceURL,
+
+		AllowedOrigins: c.AllowedOrigins,
+
+		ForwardClaimSourceTokens: c.ForwardClaimSourceTokens,
+	}
+}
+
+func toStorageClient(c *api.Client) storage.Client {
+	return storage.Client{
+		ID:           c.Id,
+		Secret:       c.Secret,
+		RedirectURIs: c.RedirectUris,
+		TrustedPeers: c.TrustedPeers,
+		Public:       c.Public,
+		Name:         c.Name,
+		LogoURL:      c.LogoUrl,
+
+		RefreshTokenPolicy: toStorageRefreshTokenPolicy(c.RefreshTokenPolicy),
+		TokenExpiry:        toStorageTokenExpiry(c.TokenExpiry),
+		GroupsFilter:       toStorageGroupsFilter(c.GroupsFilter),
+		ClaimsTransforms:   toStorageClaimsTransforms(c.ClaimsTransforms),
+
+		RedirectURIPatterns: c.RedirectUriPatterns,
+
+		PolicyURL:         c.PolicyUrl,
+		TermsOfServiceURL: c.TermsOfServiceUrl,
+
+		AllowedOrigins: c.AllowedOrigins,
+
+		ForwardClaimSourceTokens: c.ForwardClaimSourceTokens,
+	}
+}
+
+// validateClient checks the per-client overrides of a client.
+func validateClient(c storage.Client) error {
+	if err := ValidateRefreshTokenPolicy(c.RefreshTokenPolicy); err != nil {
+		return err
+	}
+	if err := ValidateTokenExpiry(c.TokenExpiry); err != nil {
+		return err
+	}
+	if err := ValidateGroupsFilter(c.GroupsFilter); err != nil {
+		return err
+	}
+	if err := ValidateClaimsTransforms(c.ClaimsTransforms); err != nil {
+		return err
+	}
+	if err := ValidateRedirectURIPatterns(c.RedirectURIPatterns); err != nil {
+		return err
+	}
+	return ValidateAllowedOrigins(c.AllowedOrigins)
+}
+
 func (d dexAPI) CreateClient(ctx context.Context, req *api.CreateClientReq) (*api.CreateClientResp, error) {
 	if req.Client == nil {
 		return nil, errors.New("no client supplied")
@@ -81,14 +150,10 @@ func (d dexAPI) CreateClient(ctx context.Context, req *api.CreateClientReq) (*ap
 		req.Client.Secret = storage.NewID() + storage.NewID()
 	}
 
-	c := storage.Client{
-		ID:           req.Client.Id,
-		Secret:       req.Client.Secret,
-		RedirectURIs: req.Client.RedirectUris,
-		TrustedPeers: req.Client.TrustedPeers,
-		Public:       req.Client.Public,
-		Name:         req.Client.Name,
-		LogoURL:      req.Client.LogoUrl,
+	c := toStorageClient(req.Client)
+	c.CreatedAt = time.Now()
+	if err := validateClient(c); err != nil {
+		return nil, fmt.Errorf("create client: %v", err)
 	}
 	if err := d.s.CreateClient(ctx, c); err != nil {
 		if err == storage.ErrAlreadyExists {
@@ -97,6 +162,7 @@ func (d dexAPI) CreateClient(ctx context.Context, req *api.CreateClientReq) (*ap
 		d.logger.Error("failed to create client", "err", err)
 		return nil, fmt.Errorf("create client: %v", err)
 	}
+	d.emitClientModified(ctx, c.ID, "create")
 
 	return &api.CreateClientResp{
 		Client: req.Client,
@@ -108,6 +174,29 @@ func (d dexAPI) UpdateClient(ctx context.Context, req *api.UpdateClientReq) (*ap
 		return nil, errors.New("update client: no client ID supplied")
 	}
 
+	policy := toStorageRefreshTokenPolicy(req.RefreshTokenPolicy)
+	if err := ValidateRefreshTokenPolicy(policy); err != nil {
+		return nil, fmt.Errorf("update client: %v", err)
+	}
+	tokenExpiry := toStorageTokenExpiry(req.TokenExpiry)
+	if err := ValidateTokenExpiry(tokenExpiry); err != nil {
+		return nil, fmt.Errorf("update client: %v", err)
+	}
+	groupsFilter := toStorageGroupsFilter(req.GroupsFilter)
+	if err := ValidateGroupsFilter(groupsFilter); err != nil {
+		return nil, fmt.Errorf("update client: %v", err)
+	}
+	claimsTransforms := toStorageClaimsTransforms(req.ClaimsTransforms)
+	if err := ValidateClaimsTransforms(claimsTransforms); err != nil {
+		return nil, fmt.Errorf("update client: %v", err)
+	}
+	if err := ValidateRedirectURIPatterns(req.RedirectUriPatterns); err != nil {
+		return nil, fmt.Errorf("update client: %v", err)
+	}
+	if err := ValidateAllowedOrigins(req.AllowedOrigins); err != nil {
+		return nil, fmt.Errorf("update client: %v", err)
+	}
+
 	err := d.s.UpdateClient(req.Id, func(old storage.Client) (storage.Client, error) {
 		if req.RedirectUris != nil {
 			old.RedirectURIs = req.RedirectUris
@@ -121,6 +210,33 @@ func (d dexAPI) UpdateClient(ctx context.Context, req *api.UpdateClientReq) (*ap
 		if req.LogoUrl != "" {
 			old.LogoURL = req.LogoUrl
 		}
+		if req.PolicyUrl != "" {
+			old.PolicyURL = req.PolicyUrl
+		}
+		if req.TermsOfServiceUrl != "" {
+			old.TermsOfServiceURL = req.TermsOfServiceUrl
+		}
+		if req.RefreshTokenPolicy != nil {
+			old.RefreshTokenPolicy = policy
+		}
+		if req.TokenExpiry != nil {
+			old.TokenExpiry = tokenExpiry
+		}
+		if req.GroupsFilter != nil {
+			old.GroupsFilter = groupsFilter
+		}
+		if req.ClaimsTransforms != nil {
+			old.ClaimsTransforms = claimsTransforms
+		}
+		if req.RedirectUriPatterns != nil {
+			old.RedirectURIPatterns = req.RedirectUriPatterns
+		}
+		if req.AllowedOrigins != nil {
+			old.AllowedOrigins = req.AllowedOrigins
+		}
+		if req.ForwardClaimSourceTokens != nil {
+			old.ForwardClaimSourceTokens = *req.ForwardClaimSourceTokens
+		}
 		return old, nil
 	})
 	if err != nil {
@@ -130,6 +246,7 @@ func (d dexAPI) UpdateClient(ctx context.Context, req *api.UpdateClientReq) (*ap
 		d.logger.Error("failed to update the client", "err", err)
 		return nil, fmt.Errorf("update client: %v", err)
 	}
+	d.emitClientModified(ctx, req.Id, "update")
 	return &api.UpdateClientResp{}, nil
 }
 
@@ -142,9 +259,232 @@ func (d dexAPI) DeleteClient(ctx context.Context, req *api.DeleteClientReq) (*ap
 		d.logger.Error("failed to delete client", "err", err)
 		return nil, fmt.Errorf("delete client: %v", err)
 	}
+	d.emitClientModified(ctx, req.Id, "delete")
 	return &api.DeleteClientResp{}, nil
 }
 
+// emitEvent publishes an audit event if the API is served along a server.
+func (d dexAPI) emitEvent(ctx context.Context, e auditEvent) {
+	if d.server != nil {
+		d.server.emitEvent(ctx, e)
+	}
+}
+
+func (d dexAPI) emitClientModified(ctx context.Context, clientID, operation string) {
+	d.emitEvent(ctx, auditEvent{
+		Type:     eventClientModified,
+		ClientID: clientID,
+		Details:  map[string]string{"operation": operation},
+	})
+}
+
+const (
+	// defaultClientsPageSize and maxClientsPageSize bound the number of
+	// clients returned by a single ListClients call.
+	defaultClientsPageSize = 100
+	maxClientsPageSize     = 1000
+)
+
+func (d dexAPI) ListClients(ctx context.Context, req *api.ListClientsReq) (*api.ListClientsResp, error) {
+	pageSize := int(req.PageSize)
+	switch {
+	case pageSize < 0:
+		return nil, errors.New("list clients: page size must not be negative")
+	case pageSize == 0:
+		pageSize = defaultClientsPageSize
+	case pageSize > maxClientsPageSize:
+		pageSize = maxClientsPageSize
+	}
+
+	// Page tokens hold the ID of the last client of the previous page.
+	var after string
+	if req.PageToken != "" {
+		id, err := base64.RawURLEncoding.DecodeString(req.PageToken)
+		if err != nil {
+			return nil, errors.New("list clients: invalid page token")
+		}
+		after = string(id)
+	}
+
+	clients, err := d.s.ListClients()
+	if err != nil {
+		d.logger.Error("failed to list clients", "err", err)
+		return nil, fmt.Errorf("list clients: %v", err)
+	}
+	sort.Slice(clients, func(i, j int) bool { return clients[i].ID < clients[j].ID })
+
+	nameFilter := strings.ToLower(req.NameFilter)
+	resp := &api.ListClientsResp{}
+	for _, c := range clients {
+		if req.PageToken != "" && c.ID <= after {
+			continue
+		}
+		if !strings.Contains(c.ID, req.IdFilter) || !strings.Contains(strings.ToLower(c.Name), nameFilter) {
+			continue
+		}
+		if len(resp.Clients) == pageSize {
+			last := resp.Clients[len(resp.Clients)-1].Id
+			resp.NextPageToken = base64.RawURLEncoding.EncodeToString([]byte(last))
+			break
+		}
+		resp.Clients = append(resp.Clients, &api.ClientInfo{
+			Id:           c.ID,
+			RedirectUris: c.RedirectURIs,
+			TrustedPeers: c.TrustedPeers,
+			Public:       c.Public,
+			Name:         c.Name,
+			LogoUrl:      c.LogoURL,
+			CreatedAt:    unixTime(c.CreatedAt),
+			LastUsed:     unixTime(c.LastUsed),
+		})
+	}
+	return resp, nil
+}
+
+// unixTime returns t as Unix time, or zero if t isn't set.
+func unixTime(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func (d dexAPI) RotateClientSecret(ctx context.Context, req *api.RotateClientSecretReq) (*api.RotateClientSecretResp, error) {
+	if req.Id == "" {
+		return nil, errors.New("rotate client secret: no client ID supplied")
+	}
+	if req.GracePeriodSeconds < 0 {
+		return nil, errors.New("rotate client secret: grace period must not be negative")
+	}
+
+	secret := req.NewSecret
+	if secret == "" {
+		secret = storage.NewID() + storage.NewID()
+	}
+	gracePeriod := time.Duration(req.GracePeriodSeconds) * time.Second
+
+	var previous *storage.PreviousSecret
+	err := d.s.UpdateClient(req.Id, func(old storage.Client) (storage.Client, error) {
+		c, err := rotateClientSecret(old, secret, gracePeriod, time.Now())
+		previous = c.PreviousSecret
+		return c, err
+	})
+	if err != nil {
+		if err == storage.ErrNotFound {
+			return &api.RotateClientSecretResp{NotFound: true}, nil
+		}
+		d.logger.Error("failed to rotate client secret", "err", err)
+		return nil, fmt.Errorf("rotate client secret: %v", err)
+	}
+	d.emitClientModified(ctx, req.Id, "rotate_secret")
+
+	resp := &api.RotateClientSecretResp{Secret: secret}
+	if previous != nil {
+		resp.PreviousSecretExpiry = previous.Expiry.Unix()
+	}
+	return resp, nil
+}
+
+// toStorageRefreshTokenPolicy converts the refresh token policy of a client.
+// Policies without any overrides are dropped.
+func toStorageRefreshTokenPolicy(p *api.RefreshTokenPolicy) *storage.RefreshTokenPolicy {
+	if p == nil || (p.DisableRotation == nil && p.ReuseInterval == "" && p.AbsoluteLifetime == "" && p.ValidIfNotUsedFor == "") {
+		return nil
+	}
+	return &storage.RefreshTokenPolicy{
+		DisableRotation:   p.DisableRotation,
+		ReuseInterval:     p.ReuseInterval,
+		AbsoluteLifetime:  p.AbsoluteLifetime,
+		ValidIfNotUsedFor: p.ValidIfNotUsedFor,
+	}
+}
+
+func toAPIRefreshTokenPolicy(p *storage.RefreshTokenPolicy) *api.RefreshTokenPolicy {
+	if p == nil {
+		return nil
+	}
+	return &api.RefreshTokenPolicy{
+		DisableRotation:   p.DisableRotation,
+		ReuseInterval:     p.ReuseInterval,
+		AbsoluteLifetime:  p.AbsoluteLifetime,
+		ValidIfNotUsedFor: p.ValidIfNotUsedFor,
+	}
+}
+
+// toStorageTokenExpiry converts the token expiry of a client. Empty values are
+// dropped.
+func toStorageTokenExpiry(e *api.TokenExpiry) *storage.TokenExpiry {
+	if e == nil || (e.IdTokens == "" && e.AccessTokens == "") {
+		return nil
+	}
+	return &storage.TokenExpiry{
+		IDTokens:     e.IdTokens,
+		AccessTokens: e.AccessTokens,
+	}
+}
+
+func toAPITokenExpiry(e *storage.TokenExpiry) *api.TokenExpiry {
+	if e == nil {
+		return nil
+	}
+	return &api.TokenExpiry{
+		IdTokens:     e.IDTokens,
+		AccessTokens: e.AccessTokens,
+	}
+}
+
+// toStorageGroupsFilter converts the groups filter of a client. Empty filters
+// are dropped.
+func toStorageGroupsFilter(f *api.GroupsFilter) *storage.GroupsFilter {
+	if f == nil || (len(f.AllowedGroups) == 0 && len(f.Prefixes) == 0 && len(f.Patterns) == 0) {
+		return nil
+	}
+	return &storage.GroupsFilter{
+		AllowedGroups: f.AllowedGroups,
+		Prefixes:      f.Prefixes,
+		Patterns:      f.Patterns,
+	}
+}
+
+func toAPIGroupsFilter(f *storage.GroupsFilter) *api.GroupsFilter {
+	if f == nil {
+		return nil
+	}
+	return &api.GroupsFilter{
+		AllowedGroups: f.AllowedGroups,
+		Prefixes:      f.Prefixes,
+		Patterns:      f.Patterns,
+	}
+}
+
+func toStorageClaimsTransforms(transforms []*api.ClaimsTransform) []storage.ClaimsTransform {
+	if transforms == nil {
+		return nil
+	}
+	converted := make([]storage.ClaimsTransform, 0, len(transforms))
+	for _, t := range transforms {
+		converted = append(converted, storage.ClaimsTransform{
+			Claim:      t.Claim,
+			Expression: t.Expression,
+		})
+	}
+	return converted
+}
+
+func toAPIClaimsTransforms(transforms []storage.ClaimsTransform) []*api.ClaimsTransform {
+	if transforms == nil {
+		return nil
+	}
+	converted := make([]*api.ClaimsTransform, 0, len(transforms))
+	for _, t := range transforms {
+		converted = append(converted, &api.ClaimsTransform{
+			Claim:      t.Claim,
+			Expression: t.Expression,
+		})
+	}
+	return converted
+}
+
 // checkCost returns an error if the hash provided does not meet lower or upper
 // bound cost requirements.
 func checkCost(hash []byte) error {
@@ -168,19 +508,32 @@ func (d dexAPI) CreatePassword(ctx context.Context, req *api.CreatePasswordReq)
 	if req.Password.UserId == "" {
 		return nil, errors.New("no user ID supplied")
 	}
-	if req.Password.Hash != nil {
-		if err := checkCost(req.Password.Hash); err != nil {
+	hash := req.Password.Hash
+	switch {
+	case hash != nil && req.PlainText != "":
+		return nil, errors.New("both a hash and a plain text password supplied")
+	case hash != nil:
+		if err := checkCost(hash); err != nil {
 			return nil, err
 		}
-	} else {
+	case req.PlainText != "":
+		if v := d.passwordPolicy().check(ctx, d.logger, req.PlainText, req.Password.Email, req.Password.Username); v != nil {
+			return &api.CreatePasswordResp{PolicyViolation: v.Error()}, nil
+		}
+		var err error
+		if hash, err = bcrypt.GenerateFromPassword([]byte(req.PlainText), bcrypt.DefaultCost); err != nil {
+			return nil, fmt.Errorf("hash password: %v", err)
+		}
+	default:
 		return nil, errors.New("no hash of password supplied")
 	}
 
 	p := storage.Password{
-		Email:    req.Password.Email,
-		Hash:     req.Password.Hash,
-		Username: req.Password.Username,
-		UserID:   req.Password.UserId,
+		Email:         req.Password.Email,
+		Hash:          hash,
+		Username:      req.Password.Username,
+		UserID:        req.Password.UserId,
+		EmailVerified: emailVerified(req.Password),
 	}
 	if err := d.s.CreatePassword(ctx, p); err != nil {
 		if err == storage.ErrAlreadyExists {
@@ -193,11 +546,27 @@ func (d dexAPI) CreatePassword(ctx context.Context, req *api.CreatePasswordReq)
 	return &api.CreatePasswordResp{}, nil
 }
 
+// passwordPolicy returns the policy plain text passwords have to meet, the
+// one of the server or the default one without a server.
+func (d dexAPI) passwordPolicy() *passwordPolicy {
+	if d.server != nil {
+		return d.server.passwordPolicy
+	}
+	p, _ := newPasswordPolicy(&PasswordPolicyConfig{})
+	return p
+}
+
+// emailVerified returns if the e-mail address of a password is verified, which
+// it is unless the request says otherwise.
+func emailVerified(p *api.Password) bool {
+	return p.EmailVerified == nil || *p.EmailVerified
+}
+
 func (d dexAPI) UpdatePassword(ctx context.Context, req *api.UpdatePasswordReq) (*api.UpdatePasswordResp, error) {
 	if req.Email == "" {
 		return nil, errors.New("no email supplied")
 	}
-	if req.NewHash == nil && req.NewUsername == "" {
+	if req.NewHash == nil && req.NewUsername == "" && req.NewEmailVerified == nil {
 		return nil, errors.New("nothing to update")
 	}
 
@@ -216,6 +585,10 @@ func (d dexAPI) UpdatePassword(ctx context.Context, req *api.UpdatePasswordReq)
 			old.Username = req.NewUsername
 		}
 
+		if req.NewEmailVerified != nil {
+			old.EmailVerified = *req.NewEmailVerified
+		}
+
 		return old, nil
 	}
 
@@ -246,6 +619,34 @@ func (d dexAPI) DeletePassword(ctx context.Context, req *api.DeletePasswordReq)
 	return &api.DeletePasswordResp{}, nil
 }
 
+func (d dexAPI) UnlockPassword(ctx context.Context, req *api.UnlockPasswordReq) (*api.UnlockPasswordResp, error) {
+	if req.Email == "" {
+		return nil, errors.New("no email supplied")
+	}
+
+	var unlocked storage.Password
+	err := d.s.UpdatePassword(req.Email, func(old storage.Password) (storage.Password, error) {
+		old.FailedAttempts = 0
+		old.LockedUntil = time.Time{}
+		unlocked = old
+		return old, nil
+	})
+	if err != nil {
+		if err == storage.ErrNotFound {
+			return &api.UnlockPasswordResp{NotFound: true}, nil
+		}
+		d.logger.Error("failed to unlock password", "err", err)
+		return nil, fmt.Errorf("unlock password: %v", err)
+	}
+	d.emitEvent(ctx, auditEvent{
+		Type:        eventPasswordUnlocked,
+		ConnectorID: LocalConnector,
+		UserID:      unlocked.UserID,
+		Username:    unlocked.Email,
+	})
+	return &api.UnlockPasswordResp{}, nil
+}
+
 func (d dexAPI) GetVersion(ctx context.Context, req *api.VersionReq) (*api.VersionResp, error) {
 	return &api.VersionResp{
 		Server: d.version,
@@ -254,7 +655,7 @@ func (d dexAPI) GetVersion(ctx context.Context, req *api.VersionReq) (*api.Versi
 }
 
 func (d dexAPI) GetDiscovery(ctx context.Context, req *api.DiscoveryReq) (*api.DiscoveryResp, error) {
-	discoveryDoc := d.server.constructDiscovery()
+	discoveryDoc := d.server.constructDiscovery(ctx)
 	data, err := json.Marshal(discoveryDoc)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal discovery data: %v", err)
@@ -277,9 +678,13 @@ func (d dexAPI) ListPasswords(ctx context.Context, req *api.ListPasswordReq) (*a
 	passwords := make([]*api.Password, 0, len(passwordList))
 	for _, password := range passwordList {
 		p := api.Password{
-			Email:    password.Email,
-			Username: password.Username,
-			UserId:   password.UserID,
+			Email:         password.Email,
+			Username:      password.Username,
+			UserId:        password.UserID,
+			EmailVerified: &password.EmailVerified,
+		}
+		if password.LockedUntil.After(time.Now()) {
+			p.LockedUntil = password.LockedUntil.Unix()
 		}
 		passwords = append(passwords, &p)
 	}
@@ -362,22 +767,24 @@ func (d dexAPI) RevokeRefresh(ctx context.Context, req *api.RevokeRefreshReq) (*
 	}
 
 	var (
-		refreshID string
-		notFound  bool
+		refreshIDs []string
+		notFound   bool
 	)
 	updater := func(old storage.OfflineSessions) (storage.OfflineSessions, error) {
-		refreshRef := old.Refresh[req.ClientId]
-		if refreshRef == nil || refreshRef.ID == "" {
+		refreshIDs = nil
+		for _, key := range clientRefreshTokenRefs(old, req.ClientId) {
+			if refreshID := old.Refresh[key].ID; refreshID != "" {
+				refreshIDs = append(refreshIDs, refreshID)
+			}
+			// Remove entry from Refresh list of the OfflineSession object.
+			delete(old.Refresh, key)
+		}
+		if len(refreshIDs) == 0 {
 			d.logger.Error("refresh token issued to client not found for deletion", "client_id", req.ClientId, "user_id", id.UserId)
 			notFound = true
 			return old, storage.ErrNotFound
 		}
 
-		refreshID = refreshRef.ID
-
-		// Remove entry from Refresh list of the OfflineSession object.
-		delete(old.Refresh, req.ClientId)
-
 		return old, nil
 	}
 
@@ -393,18 +800,175 @@ func (d dexAPI) RevokeRefresh(ctx context.Context, req *api.RevokeRefreshReq) (*
 		return &api.RevokeRefreshResp{NotFound: true}, nil
 	}
 
-	// Delete the refresh token from the storage
+	// Delete the refresh tokens from the storage
 	//
 	// TODO(ericchiang): we don't have any good recourse if this call fails.
 	// Consider garbage collection of refresh tokens with no associated ref.
-	if err := d.s.DeleteRefresh(refreshID); err != nil {
-		d.logger.Error("failed to delete refresh token", "err", err)
-		return nil, err
+	for _, refreshID := range refreshIDs {
+		if err := d.s.DeleteRefresh(refreshID); err != nil {
+			d.logger.Error("failed to delete refresh token", "err", err)
+			return nil, err
+		}
 	}
 
+	d.emitEvent(ctx, auditEvent{
+		Type:        eventRefreshRevoked,
+		ClientID:    req.ClientId,
+		ConnectorID: id.ConnId,
+		UserID:      id.UserId,
+		Details:     map[string]string{"revoked_by": "api"},
+	})
 	return &api.RevokeRefreshResp{}, nil
 }
 
+func (d dexAPI) ListUsers(ctx context.Context, req *api.ListUsersReq) (*api.ListUsersResp, error) {
+	type userKey struct{ userID, connID string }
+	users := make(map[userKey]*api.KnownUser)
+	user := func(userID, connID string) *api.KnownUser {
+		key := userKey{userID, connID}
+		if u, ok := users[key]; ok {
+			return u
+		}
+		u := &api.KnownUser{UserId: userID, ConnectorId: connID}
+		users[key] = u
+		return u
+	}
+
+	// Offline sessions can't be enumerated, so users with sessions are found
+	// through the refresh tokens backing them.
+	tokens, err := d.s.ListRefreshTokens()
+	if err != nil {
+		d.logger.Error("failed to list refresh tokens", "err", err)
+		return nil, fmt.Errorf("list users: %v", err)
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].LastUsed.Before(tokens[j].LastUsed) })
+	for _, t := range tokens {
+		u := user(t.Claims.UserID, t.ConnectorID)
+		// Tokens are sorted by last use, so the most recent claims win.
+		u.Email = t.Claims.Email
+		u.Username = t.Claims.Username
+		if req.ClientId != "" && t.ClientID != req.ClientId {
+			continue
+		}
+		u.Sessions = append(u.Sessions, &api.RefreshTokenRef{
+			Id:        t.ID,
+			ClientId:  t.ClientID,
+			CreatedAt: t.CreatedAt.Unix(),
+			LastUsed:  t.LastUsed.Unix(),
+		})
+	}
+
+	passwords, err := d.s.ListPasswords()
+	if err != nil {
+		d.logger.Error("failed to list passwords", "err", err)
+		return nil, fmt.Errorf("list users: %v", err)
+	}
+	for _, p := range passwords {
+		u := user(p.UserID, LocalConnector)
+		u.Email = p.Email
+		u.Username = p.Username
+	}
+
+	emailFilter := strings.ToLower(req.EmailFilter)
+	resp := &api.ListUsersResp{}
+	for _, u := range users {
+		if req.ConnectorId != "" && u.ConnectorId != req.ConnectorId {
+			continue
+		}
+		if req.ClientId != "" && len(u.Sessions) == 0 {
+			continue
+		}
+		if !strings.Contains(strings.ToLower(u.Email), emailFilter) {
+			continue
+		}
+		subject, err := internal.Marshal(&internal.IDTokenSubject{UserId: u.UserId, ConnId: u.ConnectorId})
+		if err != nil {
+			d.logger.Error("failed to marshal ID Token subject", "err", err)
+			return nil, fmt.Errorf("list users: %v", err)
+		}
+		u.Subject = subject
+		resp.Users = append(resp.Users, u)
+	}
+	sort.Slice(resp.Users, func(i, j int) bool {
+		a, b := resp.Users[i], resp.Users[j]
+		if a.ConnectorId != b.ConnectorId {
+			return a.ConnectorId < b.ConnectorId
+		}
+		return a.UserId < b.UserId
+	})
+	return resp, nil
+}
+
+func (d dexAPI) RevokeUserSessions(ctx context.Context, req *api.RevokeUserSessionsReq) (*api.RevokeUserSessionsResp, error) {
+	if req.UserId == "" || req.ConnectorId == "" {
+		return nil, errors.New("revoke user sessions: user ID and connector ID are required")
+	}
+
+	refreshIDs := make(map[string]bool)
+	sessionsFound := true
+	sessions, err := d.s.GetOfflineSessions(req.UserId, req.ConnectorId)
+	switch err {
+	case nil:
+		for _, ref := range sessions.Refresh {
+			if ref != nil && ref.ID != "" {
+				refreshIDs[ref.ID] = true
+			}
+		}
+	case storage.ErrNotFound:
+		sessionsFound = false
+	default:
+		d.logger.Error("failed to get offline sessions", "err", err)
+		return nil, fmt.Errorf("revoke user sessions: %v", err)
+	}
+
+	// Also pick up refresh tokens which aren't referenced by the offline
+	// sessions anymore.
+	tokens, err := d.s.ListRefreshTokens()
+	if err != nil {
+		d.logger.Error("failed to list refresh tokens", "err", err)
+		return nil, fmt.Errorf("revoke user sessions: %v", err)
+	}
+	for _, t := range tokens {
+		if t.Claims.UserID == req.UserId && t.ConnectorID == req.ConnectorId {
+			refreshIDs[t.ID] = true
+		}
+	}
+
+	if !sessionsFound && len(refreshIDs) == 0 {
+		return &api.RevokeUserSessionsResp{NotFound: true}, nil
+	}
+
+	// Delete the offline sessions first, so refreshes racing with the
+	// revocation fail to update them.
+	if sessionsFound {
+		if err := d.s.DeleteOfflineSessions(req.UserId, req.ConnectorId); err != nil && err != storage.ErrNotFound {
+			d.logger.Error("failed to delete offline sessions", "err", err)
+			return nil, fmt.Errorf("revoke user sessions: %v", err)
+		}
+	}
+
+	var revoked int32
+	for id := range refreshIDs {
+		if err := d.s.DeleteRefresh(id); err != nil {
+			if err == storage.ErrNotFound {
+				continue
+			}
+			d.logger.Error("failed to delete refresh token", "err", err)
+			return nil, fmt.Errorf("revoke user sessions: %v", err)
+		}
+		revoked++
+	}
+
+	d.logger.Info("revoked user sessions", "user_id", req.UserId, "connector_id", req.ConnectorId, "refresh_tokens", revoked)
+	d.emitEvent(ctx, auditEvent{
+		Type:        eventRefreshRevoked,
+		ConnectorID: req.ConnectorId,
+		UserID:      req.UserId,
+		Details:     map[string]string{"revoked_by": "api", "refresh_tokens": strconv.Itoa(int(revoked))},
+	})
+	return &api.RevokeUserSessionsResp{RevokedRefreshTokens: revoked}, nil
+}
+
 func (d dexAPI) CreateConnector(ctx context.Context, req *api.CreateConnectorReq) (*api.CreateConnectorResp, error) {
 	if !featureflags.APIConnectorsCRUD.Enabled() {
 		return nil, fmt.Errorf("%s feature flag is not enabled", featureflags.APIConnectorsCRUD.Name)
@@ -430,12 +994,18 @@ func (d dexAPI) CreateConnector(ctx context.Context, req *api.CreateConnectorReq
 		return nil, errors.New("invalid config supplied")
 	}
 
+	display := toStorageConnectorDisplay(req.Connector.Display)
+	if err := ValidateConnectorDisplay(display); err != nil {
+		return nil, fmt.Errorf("invalid display supplied: %v", err)
+	}
+
 	c := storage.Connector{
 		ID:              req.Connector.Id,
 		Name:            req.Connector.Name,
 		Type:            req.Connector.Type,
 		ResourceVersion: "1",
 		Config:          req.Connector.Config,
+		Display:         display,
 	}
 	if err := d.s.CreateConnector(ctx, c); err != nil {
 		if err == storage.ErrAlreadyExists {
@@ -457,14 +1027,19 @@ func (d dexAPI) UpdateConnector(_ context.Context, req *api.UpdateConnectorReq)
 		return nil, errors.New("no email supplied")
 	}
 
-	if len(req.NewConfig) == 0 && req.NewName == "" && req.NewType == "" {
+	if len(req.NewConfig) == 0 && req.NewName == "" && req.NewType == "" && req.NewDisplay == nil {
 		return nil, errors.New("nothing to update")
 	}
 
-	if !json.Valid(req.NewConfig) {
+	if len(req.NewConfig) != 0 && !json.Valid(req.NewConfig) {
 		return nil, errors.New("invalid config supplied")
 	}
 
+	display := toStorageConnectorDisplay(req.NewDisplay)
+	if err := ValidateConnectorDisplay(display); err != nil {
+		return nil, fmt.Errorf("invalid display supplied: %v", err)
+	}
+
 	updater := func(old storage.Connector) (storage.Connector, error) {
 		if req.NewType != "" {
 			old.Type = req.NewType
@@ -478,6 +1053,10 @@ func (d dexAPI) UpdateConnector(_ context.Context, req *api.UpdateConnectorReq)
 			old.Config = req.NewConfig
 		}
 
+		if req.NewDisplay != nil {
+			old.Display = display
+		}
+
 		if rev, err := strconv.Atoi(defaultTo(old.ResourceVersion, "0")); err == nil {
 			old.ResourceVersion = strconv.Itoa(rev + 1)
 		}
@@ -530,10 +1109,11 @@ func (d dexAPI) ListConnectors(ctx context.Context, req *api.ListConnectorReq) (
 	connectors := make([]*api.Connector, 0, len(connectorList))
 	for _, connector := range connectorList {
 		c := api.Connector{
-			Id:     connector.ID,
-			Name:   connector.Name,
-			Type:   connector.Type,
-			Config: connector.Config,
+			Id:      connector.ID,
+			Name:    connector.Name,
+			Type:    connector.Type,
+			Config:  connector.Config,
+			Display: toAPIConnectorDisplay(connector.Display),
 		}
 		connectors = append(connectors, &c)
 	}
@@ -543,6 +1123,32 @@ func (d dexAPI) ListConnectors(ctx context.Context, req *api.ListConnectorReq) (
 	}, nil
 }
 
+func toStorageConnectorDisplay(d *api.ConnectorDisplay) *storage.ConnectorDisplay {
+	if d == nil || (d.Order == 0 && d.Group == "" && !d.Hidden && d.IconUrl == "" && d.ButtonColor == "") {
+		return nil
+	}
+	return &storage.ConnectorDisplay{
+		Order:       int(d.Order),
+		Group:       d.Group,
+		Hidden:      d.Hidden,
+		IconURL:     d.IconUrl,
+		ButtonColor: d.ButtonColor,
+	}
+}
+
+func toAPIConnectorDisplay(d *storage.ConnectorDisplay) *api.ConnectorDisplay {
+	if d == nil {
+		return nil
+	}
+	return &api.ConnectorDisplay{
+		Order:       int32(d.Order),
+		Group:       d.Group,
+		Hidden:      d.Hidden,
+		IconUrl:     d.IconURL,
+		ButtonColor: d.ButtonColor,
+	}
+}
+
 func defaultTo[T comparable](v, def T) T {
 	var zeroT T
 	if v == zeroT {
@@ -550,3 +1156,468 @@ func defaultTo[T comparable](v, def T) T {
 	}
 	return v
 }
+
+func (d dexAPI) WatchEvents(req *api.WatchEventsReq, stream api.Dex_WatchEventsServer) error {
+	if d.server == nil {
+		return errors.New("watch events: not supported without a server")
+	}
+
+	events, unsubscribe := d.server.events.subscribe()
+	defer unsubscribe()
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e := <-events:
+			if len(req.Types) > 0 && !slices.Contains(req.Types, e.Type) {
+				continue
+			}
+			err := stream.Send(&api.Event{
+				Type:        e.Type,
+				Time:        e.Time.Unix(),
+				Issuer:      e.Issuer,
+				ClientId:    e.ClientID,
+				ConnectorId: e.ConnectorID,
+				UserId:      e.UserID,
+				Username:    e.Username,
+				Details:     e.Details,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (d dexAPI) RunGarbageCollection(ctx context.Context, req *api.RunGarbageCollectionReq) (*api.RunGarbageCollectionResp, error) {
+	var (
+		r   storage.GCResult
+		err error
+	)
+	if d.server != nil {
+		r, err = d.server.garbageCollect(time.Now(), storage.GCOptions{BatchSize: d.server.gcBatchSize})
+	} else {
+		r, err = d.s.GarbageCollect(time.Now(), storage.GCOptions{})
+	}
+	if err != nil {
+		d.logger.Error("garbage collection failed", "err", err)
+		return nil, fmt.Errorf("run garbage collection: %v", err)
+	}
+	d.logger.Info("garbage collection run from api",
+		"requests", r.AuthRequests, "auth_codes", r.AuthCodes,
+		"device_requests", r.DeviceRequests, "device_tokens", r.DeviceTokens)
+	return &api.RunGarbageCollectionResp{
+		AuthRequests:   r.AuthRequests,
+		AuthCodes:      r.AuthCodes,
+		DeviceRequests: r.DeviceRequests,
+		DeviceTokens:   r.DeviceTokens,
+	}, nil
+}
+
+func (d dexAPI) RotateSigningKeys(ctx context.Context, req *api.RotateSigningKeysReq) (*api.RotateSigningKeysResp, error) {
+	if d.server == nil {
+		return nil, errors.New("rotate signing keys: not supported without a server")
+	}
+	d.logger.Info("rotating signing keys from api")
+	if err := d.server.RotateKeys(ctx); err != nil {
+		d.logger.Error("failed to rotate signing keys", "err", err)
+		return nil, fmt.Errorf("rotate signing keys: %v", err)
+	}
+	return &api.RotateSigningKeysResp{}, nil
+}
+
+func (d dexAPI) ListDeviceRequests(ctx context.Context, req *api.ListDeviceRequestsReq) (*api.ListDeviceRequestsResp, error) {
+	reqs, err := d.s.ListDeviceRequests()
+	if err != nil {
+		d.logger.Error("failed to list device requests", "err", err)
+		return nil, fmt.Errorf("list device requests: %v", err)
+	}
+	sort.Slice(reqs, func(i, j int) bool { return reqs[i].Expiry.Before(reqs[j].Expiry) })
+
+	now := time.Now()
+	resp := &api.ListDeviceRequestsResp{}
+	for _, r := range reqs {
+		if now.After(r.Expiry) || (req.ClientId != "" && r.ClientID != req.ClientId) {
+			continue
+		}
+		// Skip requests which were already approved or denied.
+		token, err := d.s.GetDeviceToken(r.DeviceCode)
+		if err != nil {
+			if err == storage.ErrNotFound {
+				continue
+			}
+			d.logger.Error("failed to get device token", "err", err)
+			return nil, fmt.Errorf("list device requests: %v", err)
+		}
+		if token.Status != deviceTokenPending {
+			continue
+		}
+		resp.DeviceRequests = append(resp.DeviceRequests, &api.DeviceRequestInfo{
+			UserCode: r.UserCode,
+			ClientId: r.ClientID,
+			Scopes:   r.Scopes,
+			Expiry:   r.Expiry.Unix(),
+		})
+	}
+	return resp, nil
+}
+
+func (d dexAPI) ApproveDeviceRequest(ctx context.Context, req *api.ApproveDeviceRequestReq) (*api.ApproveDeviceRequestResp, error) {
+	if d.server == nil {
+		return nil, errors.New("approve device request: not supported without a server")
+	}
+	if req.UserCode == "" || req.ConnectorId == "" || req.UserId == "" {
+		return nil, errors.New("approve device request: user code, connector ID and user ID are required")
+	}
+	if _, err := d.s.GetConnector(req.ConnectorId); err != nil {
+		if err == storage.ErrNotFound {
+			return nil, fmt.Errorf("approve device request: connector %q does not exist", req.ConnectorId)
+		}
+		d.logger.Error("failed to get connector", "err", err)
+		return nil, fmt.Errorf("approve device request: %v", err)
+	}
+
+	claims := storage.Claims{
+		UserID:            req.UserId,
+		Username:          req.Username,
+		PreferredUsername: req.PreferredUsername,
+		Email:             req.Email,
+		EmailVerified:     req.EmailVerified,
+		Groups:            req.Groups,
+	}
+	err := d.server.approveDeviceRequest(ctx, req.UserCode, req.ConnectorId, claims)
+	if err != nil {
+		if err == storage.ErrNotFound || err == errDeviceRequestDecided {
+			return &api.ApproveDeviceRequestResp{NotFound: true}, nil
+		}
+		d.logger.Error("failed to approve device request", "err", err)
+		return nil, fmt.Errorf("approve device request: %v", err)
+	}
+	d.logger.Info("approved device request", "user_id", req.UserId, "connector_id", req.ConnectorId)
+	return &api.ApproveDeviceRequestResp{}, nil
+}
+
+func (d dexAPI) DenyDeviceRequest(ctx context.Context, req *api.DenyDeviceRequestReq) (*api.DenyDeviceRequestResp, error) {
+	if d.server == nil {
+		return nil, errors.New("deny device request: not supported without a server")
+	}
+	if req.UserCode == "" {
+		return nil, errors.New("deny device request: no user code supplied")
+	}
+	if err := d.server.denyDeviceRequest(req.UserCode); err != nil {
+		if err == storage.ErrNotFound || err == errDeviceRequestDecided {
+			return &api.DenyDeviceRequestResp{NotFound: true}, nil
+		}
+		d.logger.Error("failed to deny device request", "err", err)
+		return nil, fmt.Errorf("deny device request: %v", err)
+	}
+	return &api.DenyDeviceRequestResp{}, nil
+}
+
+// importOp stores an item of an import, and undoes it if a later item fails.
+type importOp struct {
+	apply func() error
+	undo  func() error
+	// done is called once the whole import is stored.
+	done func()
+}
+
+func (d dexAPI) Import(ctx context.Context, req *api.ImportReq) (*api.ImportResp, error) {
+	resp := &api.ImportResp{}
+	var ops []importOp
+	rejected := false
+
+	clientIDs := make(map[string]bool, len(req.Clients))
+	for _, c := range req.Clients {
+		result, op, err := d.planClientImport(ctx, c, req.UpdateExisting, clientIDs)
+		if err != nil {
+			d.logger.Error("failed to import clients", "err", err)
+			return nil, fmt.Errorf("import: %v", err)
+		}
+		resp.Clients = append(resp.Clients, result)
+		if result.Error != "" {
+			rejected = true
+			continue
+		}
+		ops = append(ops, op)
+	}
+
+	emails := make(map[string]bool, len(req.Passwords))
+	for _, p := range req.Passwords {
+		result, op, err := d.planPasswordImport(ctx, p, req.UpdateExisting, emails)
+		if err != nil {
+			d.logger.Error("failed to import passwords", "err", err)
+			return nil, fmt.Errorf("import: %v", err)
+		}
+		resp.Passwords = append(resp.Passwords, result)
+		if result.Error != "" {
+			rejected = true
+			continue
+		}
+		ops = append(ops, op)
+	}
+
+	if rejected || req.DryRun {
+		return resp, nil
+	}
+
+	for i, op := range ops {
+		if err := op.apply(); err != nil {
+			// Storages don't support transactions, so undo the items stored
+			// so far in reverse order.
+			for j := i - 1; j >= 0; j-- {
+				if err := ops[j].undo(); err != nil {
+					d.logger.Error("failed to roll back import", "err", err)
+				}
+			}
+			d.logger.Error("failed to import", "err", err)
+			return nil, fmt.Errorf("import: %v", err)
+		}
+	}
+	for _, op := range ops {
+		if op.done != nil {
+			op.done()
+		}
+	}
+	d.logger.Info("imported clients and passwords", "clients", len(req.Clients), "passwords", len(req.Passwords))
+
+	resp.Applied = true
+	return resp, nil
+}
+
+// planClientImport validates a client of an import. Rejections are reported
+// in the result, and errors are only returned if the storage fails.
+func (d dexAPI) planClientImport(ctx context.Context, c *api.Client, updateExisting bool, seen map[string]bool) (*api.ImportResult, importOp, error) {
+	if c == nil {
+		return &api.ImportResult{Error: "no client supplied"}, importOp{}, nil
+	}
+	result := &api.ImportResult{Id: c.Id}
+	switch {
+	case c.Id == "":
+		result.Error = "no client ID supplied"
+	case c.Secret == "" && !c.Public:
+		result.Error = "no secret supplied for a confidential client"
+	case seen[c.Id]:
+		result.Error = "duplicate client"
+	}
+	if result.Error != "" {
+		return result, importOp{}, nil
+	}
+	seen[c.Id] = true
+
+	client := toStorageClient(c)
+	if err := validateClient(client); err != nil {
+		result.Error = err.Error()
+		return result, importOp{}, nil
+	}
+
+	old, err := d.s.GetClient(client.ID)
+	if err == storage.ErrNotFound {
+		result.Created = true
+		client.CreatedAt = time.Now()
+		return result, importOp{
+			apply: func() error { return d.s.CreateClient(ctx, client) },
+			undo:  func() error { return d.s.DeleteClient(client.ID) },
+			done:  func() { d.emitClientModified(ctx, client.ID, "create") },
+		}, nil
+	}
+	if err != nil {
+		return nil, importOp{}, err
+	}
+	if !updateExisting {
+		result.Error = "client already exists"
+		return result, importOp{}, nil
+	}
+
+	result.Updated = true
+	client.CreatedAt = old.CreatedAt
+	client.LastUsed = old.LastUsed
+	client.PreviousSecret = old.PreviousSecret
+	return result, importOp{
+		apply: func() error {
+			return d.s.UpdateClient(client.ID, func(storage.Client) (storage.Client, error) { return client, nil })
+		},
+		undo: func() error {
+			return d.s.UpdateClient(client.ID, func(storage.Client) (storage.Client, error) { return old, nil })
+		},
+		done: func() { d.emitClientModified(ctx, client.ID, "update") },
+	}, nil
+}
+
+// planPasswordImport validates a password of an import like planClientImport.
+func (d dexAPI) planPasswordImport(ctx context.Context, p *api.Password, updateExisting bool, seen map[string]bool) (*api.ImportResult, importOp, error) {
+	if p == nil {
+		return &api.ImportResult{Error: "no password supplied"}, importOp{}, nil
+	}
+	result := &api.ImportResult{Id: p.Email}
+	email := strings.ToLower(p.Email)
+	switch {
+	case p.Email == "":
+		result.Error = "no email supplied"
+	case p.UserId == "":
+		result.Error = "no user ID supplied"
+	case p.Hash == nil:
+		result.Error = "no hash of password supplied"
+	case seen[email]:
+		result.Error = "duplicate password"
+	}
+	if result.Error == "" {
+		if err := checkCost(p.Hash); err != nil {
+			result.Error = err.Error()
+		}
+	}
+	if result.Error != "" {
+		return result, importOp{}, nil
+	}
+	seen[email] = true
+
+	password := storage.Password{
+		Email:         p.Email,
+		Hash:          p.Hash,
+		Username:      p.Username,
+		UserID:        p.UserId,
+		EmailVerified: emailVerified(p),
+	}
+
+	old, err := d.s.GetPassword(password.Email)
+	if err == storage.ErrNotFound {
+		result.Created = true
+		return result, importOp{
+			apply: func() error { return d.s.CreatePassword(ctx, password) },
+			undo:  func() error { return d.s.DeletePassword(password.Email) },
+		}, nil
+	}
+	if err != nil {
+		return nil, importOp{}, err
+	}
+	if !updateExisting {
+		result.Error = "password already exists"
+		return result, importOp{}, nil
+	}
+
+	result.Updated = true
+	// Keep the stored email, which may differ in case.
+	password.Email = old.Email
+	return result, importOp{
+		apply: func() error {
+			return d.s.UpdatePassword(password.Email, func(storage.Password) (storage.Password, error) { return password, nil })
+		},
+		undo: func() error {
+			return d.s.UpdatePassword(password.Email, func(storage.Password) (storage.Password, error) { return old, nil })
+		},
+	}, nil
+}
+
+func (d dexAPI) Export(ctx context.Context, req *api.ExportReq) (*api.ExportResp, error) {
+	clients, err := d.s.ListClients()
+	if err != nil {
+		d.logger.Error("failed to list clients", "err", err)
+		return nil, fmt.Errorf("export: %v", err)
+	}
+	passwords, err := d.s.ListPasswords()
+	if err != nil {
+		d.logger.Error("failed to list passwords", "err", err)
+		return nil, fmt.Errorf("export: %v", err)
+	}
+	sort.Slice(clients, func(i, j int) bool { return clients[i].ID < clients[j].ID })
+	sort.Slice(passwords, func(i, j int) bool { return passwords[i].Email < passwords[j].Email })
+
+	resp := &api.ExportResp{
+		Clients:   make([]*api.Client, 0, len(clients)),
+		Passwords: make([]*api.Password, 0, len(passwords)),
+	}
+	for _, c := range clients {
+		resp.Clients = append(resp.Clients, toAPIClient(c))
+	}
+	for _, p := range passwords {
+		resp.Passwords = append(resp.Passwords, &api.Password{
+			Email:         p.Email,
+			Hash:          p.Hash,
+			Username:      p.Username,
+			UserId:        p.UserID,
+			EmailVerified: &p.EmailVerified,
+		})
+	}
+	d.logger.Info("exported clients and passwords", "clients", len(clients), "passwords", len(passwords))
+	return resp, nil
+}
+
+func toAPIWebhook(w storage.Webhook) *api.Webhook {
+	webhook := &api.Webhook{
+		Id:        w.ID,
+		Url:       w.URL,
+		Events:    w.Events,
+		CreatedAt: w.CreatedAt.Unix(),
+	}
+	if w.RetryPolicy != nil {
+		webhook.MaxAttempts = int32(w.RetryPolicy.MaxAttempts)
+		webhook.Backoff = w.RetryPolicy.Backoff
+	}
+	return webhook
+}
+
+func (d dexAPI) CreateWebhook(ctx context.Context, req *api.CreateWebhookReq) (*api.CreateWebhookResp, error) {
+	if req.Webhook == nil {
+		return nil, errors.New("no webhook supplied")
+	}
+
+	w := storage.Webhook{
+		ID:        storage.NewID(),
+		URL:       req.Webhook.Url,
+		Secret:    req.Webhook.Secret,
+		Events:    req.Webhook.Events,
+		CreatedAt: time.Now(),
+	}
+	if w.Secret == "" {
+		w.Secret = storage.NewID() + storage.NewID()
+	}
+	if req.Webhook.MaxAttempts != 0 || req.Webhook.Backoff != "" {
+		w.RetryPolicy = &storage.WebhookRetryPolicy{
+			MaxAttempts: int(req.Webhook.MaxAttempts),
+			Backoff:     req.Webhook.Backoff,
+		}
+	}
+	if err := validateWebhook(w); err != nil {
+		return nil, fmt.Errorf("create webhook: %v", err)
+	}
+	if err := d.s.CreateWebhook(ctx, w); err != nil {
+		d.logger.Error("failed to create webhook", "err", err)
+		return nil, fmt.Errorf("create webhook: %v", err)
+	}
+	d.logger.Info("webhook created", "id", w.ID, "url", w.URL)
+
+	webhook := toAPIWebhook(w)
+	webhook.Secret = w.Secret
+	return &api.CreateWebhookResp{Webhook: webhook}, nil
+}
+
+func (d dexAPI) ListWebhooks(ctx context.Context, req *api.ListWebhooksReq) (*api.ListWebhooksResp, error) {
+	webhooks, err := d.s.ListWebhooks()
+	if err != nil {
+		d.logger.Error("failed to list webhooks", "err", err)
+		return nil, fmt.Errorf("list webhooks: %v", err)
+	}
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt) })
+
+	resp := &api.ListWebhooksResp{
+		Webhooks: make([]*api.Webhook, 0, len(webhooks)),
+	}
+	for _, w := range webhooks {
+		resp.Webhooks = append(resp.Webhooks, toAPIWebhook(w))
+	}
+	return resp, nil
+}
+
+func (d dexAPI) DeleteWebhook(ctx context.Context, req *api.DeleteWebhookReq) (*api.DeleteWebhookResp, error) {
+	if err := d.s.DeleteWebhook(req.Id); err != nil {
+		if err == storage.ErrNotFound {
+			return &api.DeleteWebhookResp{NotFound: true}, nil
+		}
+		d.logger.Error("failed to delete webhook", "err", err)
+		return nil, fmt.Errorf("delete webhook: %v", err)
+	}
+	d.logger.Info("webhook deleted", "id", req.Id)
+	return &api.DeleteWebhookResp{}, nil
+}
@@ -2,14 +2,20 @@ package server
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"io"
 	"log/slog"
 	"net"
 	"os"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 
@@ -32,13 +38,19 @@ type apiClient struct {
 
 // newAPI constructs a gRCP client connected to a backing server.
 func newAPI(s storage.Storage, logger *slog.Logger, t *testing.T) *apiClient {
+	return newServerAPI(s, logger, nil, t)
+}
+
+// newServerAPI is like newAPI, but serves the API along an OpenID Connect
+// server.
+func newServerAPI(s storage.Storage, logger *slog.Logger, server *Server, t *testing.T) *apiClient {
 	l, err := net.Listen("tcp", "127.0.0.1:0")
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	serv := grpc.NewServer()
-	api.RegisterDexServer(serv, NewAPI(s, logger, "test", nil))
+	api.RegisterDexServer(serv, NewAPI(s, logger, "test", server))
 	go serv.Serve(l)
 
 	// NewClient will retry automatically if the serv.Serve() goroutine
@@ -158,6 +170,36 @@ func TestPassword(t *testing.T) {
 		t.Fatalf("UpdatePassword failed. Expected username %s retrieved %s", updateReq.NewUsername, pass.Username)
 	}
 
+	lockedUntil := time.Now().Add(time.Hour)
+	if err := s.UpdatePassword(email, func(old storage.Password) (storage.Password, error) {
+		old.FailedAttempts = 5
+		old.LockedUntil = lockedUntil
+		return old, nil
+	}); err != nil {
+		t.Fatalf("Unable to lock password: %v", err)
+	}
+	listResp, err := client.ListPasswords(ctx, &api.ListPasswordReq{})
+	if err != nil {
+		t.Fatalf("Unable to list passwords: %v", err)
+	}
+	if got := listResp.Passwords[0].LockedUntil; got != lockedUntil.Unix() {
+		t.Fatalf("ListPasswords returned locked until %d, expected %d", got, lockedUntil.Unix())
+	}
+
+	if resp, err := client.UnlockPassword(ctx, &api.UnlockPasswordReq{Email: email}); err != nil || resp.NotFound {
+		t.Fatalf("Unable to unlock password: %v", err)
+	}
+	pass, err = s.GetPassword(email)
+	if err != nil {
+		t.Fatalf("Unable to retrieve password: %v", err)
+	}
+	if pass.FailedAttempts != 0 || !pass.LockedUntil.IsZero() {
+		t.Fatalf("UnlockPassword failed. Failed attempts %d, locked until %v", pass.FailedAttempts, pass.LockedUntil)
+	}
+	if resp, err := client.UnlockPassword(ctx, &api.UnlockPasswordReq{Email: "somewrongaddress@email.com"}); err != nil || !resp.NotFound {
+		t.Fatalf("expected not found response for unlocking an unknown password: %v", err)
+	}
+
 	deleteReq := api.DeletePasswordReq{
 		Email: "test@example.com",
 	}
@@ -167,6 +209,46 @@ func TestPassword(t *testing.T) {
 	}
 }
 
+func TestCreatePlainTextPassword(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, server := newTestServer(ctx, t, func(c *Config) {
+		c.PasswordPolicy = &PasswordPolicyConfig{MinLength: 12, DisallowUserInfo: true}
+	})
+	defer httpServer.Close()
+
+	client := newServerAPI(server.storage, logger, server, t)
+	defer client.Close()
+
+	p := &api.Password{Email: "jane@example.com", Username: "jane", UserId: "1"}
+	resp, err := client.CreatePassword(ctx, &api.CreatePasswordReq{Password: p, PlainText: "jane-password"})
+	require.NoError(t, err)
+	require.Equal(t, "The password must not contain your username or e-mail address.", resp.PolicyViolation)
+	_, err = server.storage.GetPassword("jane@example.com")
+	require.ErrorIs(t, err, storage.ErrNotFound)
+
+	resp, err = client.CreatePassword(ctx, &api.CreatePasswordReq{Password: p, PlainText: "short"})
+	require.NoError(t, err)
+	require.Equal(t, "The password must be at least 12 characters long.", resp.PolicyViolation)
+
+	resp, err = client.CreatePassword(ctx, &api.CreatePasswordReq{Password: p, PlainText: "correct horse battery staple"})
+	require.NoError(t, err)
+	require.Empty(t, resp.PolicyViolation)
+	got, err := server.storage.GetPassword("jane@example.com")
+	require.NoError(t, err)
+	require.NoError(t, bcrypt.CompareHashAndPassword(got.Hash, []byte("correct horse battery staple")))
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("short"), bcrypt.DefaultCost)
+	require.NoError(t, err)
+	p = &api.Password{Email: "john@example.com", Username: "john", UserId: "2", Hash: hash}
+	_, err = client.CreatePassword(ctx, &api.CreatePasswordReq{Password: p, PlainText: "correct horse battery staple"})
+	require.Error(t, err, "a hash and a plain text password are mutually exclusive")
+	resp, err = client.CreatePassword(ctx, &api.CreatePasswordReq{Password: p})
+	require.NoError(t, err, "hashes can't be checked against the policy")
+	require.Empty(t, resp.PolicyViolation)
+}
+
 // Ensures checkCost returns expected values
 func TestCheckCost(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
@@ -330,6 +412,144 @@ func TestRefreshToken(t *testing.T) {
 	}
 }
 
+func TestListUsers(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	client := newAPI(s, logger, t)
+	defer client.Close()
+	ctx := context.Background()
+
+	now := time.Now().UTC().Round(time.Second)
+	for _, r := range []storage.RefreshToken{
+		{ID: "jane-a", ClientID: "app-a", ConnectorID: "ldap", Claims: storage.Claims{UserID: "1", Email: "jane@example.com"}, LastUsed: now},
+		{ID: "jane-b", ClientID: "app-b", ConnectorID: "ldap", Claims: storage.Claims{UserID: "1", Email: "jane@example.com"}, LastUsed: now},
+		{ID: "joe-a", ClientID: "app-a", ConnectorID: "github", Claims: storage.Claims{UserID: "2", Email: "joe@example.org"}, LastUsed: now},
+		{ID: "admin-a", ClientID: "app-a", ConnectorID: LocalConnector, Claims: storage.Claims{UserID: "3", Email: "admin@example.com"}, LastUsed: now},
+	} {
+		r.Token = "token"
+		require.NoError(t, s.CreateRefresh(ctx, r))
+	}
+	for _, p := range []storage.Password{
+		{Email: "admin@example.com", Username: "admin", UserID: "3", Hash: []byte("hash")},
+		{Email: "ops@example.com", Username: "ops", UserID: "4", Hash: []byte("hash")},
+	} {
+		require.NoError(t, s.CreatePassword(ctx, p))
+	}
+
+	type user struct {
+		id       string
+		sessions []string
+	}
+	list := func(t *testing.T, req *api.ListUsersReq) []user {
+		resp, err := client.ListUsers(ctx, req)
+		require.NoError(t, err)
+		users := []user{}
+		for _, u := range resp.Users {
+			var sessions []string
+			for _, s := range u.Sessions {
+				sessions = append(sessions, s.Id)
+			}
+			sort.Strings(sessions)
+			users = append(users, user{u.ConnectorId + "/" + u.UserId, sessions})
+		}
+		return users
+	}
+
+	require.Equal(t, []user{
+		{id: "github/2", sessions: []string{"joe-a"}},
+		{id: "ldap/1", sessions: []string{"jane-a", "jane-b"}},
+		{id: "local/3", sessions: []string{"admin-a"}},
+		{id: "local/4"},
+	}, list(t, &api.ListUsersReq{}))
+
+	require.Equal(t, []user{
+		{id: "ldap/1", sessions: []string{"jane-b"}},
+	}, list(t, &api.ListUsersReq{ClientId: "app-b"}))
+
+	require.Equal(t, []user{
+		{id: "ldap/1", sessions: []string{"jane-a", "jane-b"}},
+		{id: "local/3", sessions: []string{"admin-a"}},
+		{id: "local/4"},
+	}, list(t, &api.ListUsersReq{EmailFilter: "@EXAMPLE.COM"}))
+
+	require.Equal(t, []user{
+		{id: "local/4"},
+	}, list(t, &api.ListUsersReq{ConnectorId: LocalConnector, EmailFilter: "ops"}))
+
+	resp, err := client.ListUsers(ctx, &api.ListUsersReq{ConnectorId: "github"})
+	require.NoError(t, err)
+	require.Len(t, resp.Users, 1)
+	require.Equal(t, "joe@example.org", resp.Users[0].Email)
+
+	refresh, err := client.ListRefresh(ctx, &api.ListRefreshReq{UserId: resp.Users[0].Subject})
+	require.NoError(t, err)
+	require.Empty(t, refresh.RefreshTokens)
+}
+
+func TestRevokeUserSessions(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	client := newAPI(s, logger, t)
+	defer client.Close()
+	ctx := context.Background()
+
+	newRefresh := func(id, clientID, userID, connID string) storage.RefreshToken {
+		r := storage.RefreshToken{
+			ID:          id,
+			Token:       "token",
+			ClientID:    clientID,
+			ConnectorID: connID,
+			CreatedAt:   time.Now().UTC().Round(time.Millisecond),
+			LastUsed:    time.Now().UTC().Round(time.Millisecond),
+			Claims:      storage.Claims{UserID: userID},
+		}
+		require.NoError(t, s.CreateRefresh(ctx, r))
+		return r
+	}
+
+	a := newRefresh("a", "app-a", "1", "ldap")
+	b := newRefresh("b", "app-b", "1", "ldap")
+	// Not referenced by the offline sessions anymore.
+	newRefresh("orphan", "app-c", "1", "ldap")
+	// Tokens of other users and connectors are kept.
+	newRefresh("other-user", "app-a", "2", "ldap")
+	newRefresh("other-connector", "app-a", "1", "github")
+
+	require.NoError(t, s.CreateOfflineSessions(ctx, storage.OfflineSessions{
+		UserID: "1",
+		ConnID: "ldap",
+		Refresh: map[string]*storage.RefreshTokenRef{
+			a.ClientID: {ID: a.ID, ClientID: a.ClientID},
+			b.ClientID: {ID: b.ID, ClientID: b.ClientID},
+		},
+	}))
+
+	resp, err := client.RevokeUserSessions(ctx, &api.RevokeUserSessionsReq{UserId: "1", ConnectorId: "ldap"})
+	require.NoError(t, err)
+	require.False(t, resp.NotFound)
+	require.Equal(t, int32(3), resp.RevokedRefreshTokens)
+
+	_, err = s.GetOfflineSessions("1", "ldap")
+	require.Equal(t, storage.ErrNotFound, err)
+
+	tokens, err := s.ListRefreshTokens()
+	require.NoError(t, err)
+	var remaining []string
+	for _, tok := range tokens {
+		remaining = append(remaining, tok.ID)
+	}
+	require.ElementsMatch(t, []string{"other-user", "other-connector"}, remaining)
+
+	resp, err = client.RevokeUserSessions(ctx, &api.RevokeUserSessionsReq{UserId: "1", ConnectorId: "ldap"})
+	require.NoError(t, err)
+	require.True(t, resp.NotFound)
+
+	_, err = client.RevokeUserSessions(ctx, &api.RevokeUserSessionsReq{UserId: "1"})
+	require.Error(t, err)
+}
+
 func TestUpdateClient(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
 
@@ -378,6 +598,8 @@ func TestUpdateClient(t *testing.T) {
 		}
 	}
 
+	disableRotation := true
+
 	tests := map[string]struct {
 		setup   func(t *testing.T, clientId string)
 		cleanup func(t *testing.T, clientId string)
@@ -394,6 +616,9 @@ func TestUpdateClient(t *testing.T) {
 				TrustedPeers: []string{"test"},
 				Name:         "test",
 				LogoUrl:      "https://logout",
+
+				PolicyUrl:         "https://example.com/privacy",
+				TermsOfServiceUrl: "https://example.com/terms",
 			},
 			wantErr: false,
 			want: &api.UpdateClientResp{
@@ -415,6 +640,68 @@ func TestUpdateClient(t *testing.T) {
 				NotFound: false,
 			},
 		},
+		"update client refresh token policy": {
+			setup:   createClient,
+			cleanup: deleteClient,
+			req: &api.UpdateClientReq{
+				Id:   "test",
+				Name: "test",
+				RefreshTokenPolicy: &api.RefreshTokenPolicy{
+					DisableRotation:  &disableRotation,
+					AbsoluteLifetime: "720h",
+				},
+			},
+			wantErr: false,
+			want: &api.UpdateClientResp{
+				NotFound: false,
+			},
+		},
+		"update client with invalid refresh token policy": {
+			setup:   createClient,
+			cleanup: deleteClient,
+			req: &api.UpdateClientReq{
+				Id:   "test",
+				Name: "test",
+				RefreshTokenPolicy: &api.RefreshTokenPolicy{
+					ReuseInterval: "forever",
+				},
+			},
+			wantErr: true,
+			want: &api.UpdateClientResp{
+				NotFound: false,
+			},
+		},
+		"update client token expiry": {
+			setup:   createClient,
+			cleanup: deleteClient,
+			req: &api.UpdateClientReq{
+				Id:   "test",
+				Name: "test",
+				TokenExpiry: &api.TokenExpiry{
+					IdTokens:     "10m",
+					AccessTokens: "5m",
+				},
+			},
+			wantErr: false,
+			want: &api.UpdateClientResp{
+				NotFound: false,
+			},
+		},
+		"update client with invalid token expiry": {
+			setup:   createClient,
+			cleanup: deleteClient,
+			req: &api.UpdateClientReq{
+				Id:   "test",
+				Name: "test",
+				TokenExpiry: &api.TokenExpiry{
+					IdTokens: "-10m",
+				},
+			},
+			wantErr: true,
+			want: &api.UpdateClientResp{
+				NotFound: false,
+			},
+		},
 		"update client which not exists ": {
 			req: &api.UpdateClientReq{
 				Id:           "test",
@@ -463,6 +750,12 @@ func TestUpdateClient(t *testing.T) {
 				if tc.req.LogoUrl != client.LogoURL {
 					t.Errorf("expected stored client with LogoURL: %s, found %s", tc.req.LogoUrl, client.LogoURL)
 				}
+				if tc.req.PolicyUrl != "" && tc.req.PolicyUrl != client.PolicyURL {
+					t.Errorf("expected stored client with PolicyURL: %s, found %s", tc.req.PolicyUrl, client.PolicyURL)
+				}
+				if tc.req.TermsOfServiceUrl != "" && tc.req.TermsOfServiceUrl != client.TermsOfServiceURL {
+					t.Errorf("expected stored client with TermsOfServiceURL: %s, found %s", tc.req.TermsOfServiceUrl, client.TermsOfServiceURL)
+				}
 				for _, redirectURI := range tc.req.RedirectUris {
 					found := find(redirectURI, client.RedirectURIs)
 					if !found {
@@ -475,6 +768,18 @@ func TestUpdateClient(t *testing.T) {
 						t.Errorf("expected trusted peer: %s", peer)
 					}
 				}
+				if tc.req.RefreshTokenPolicy != nil {
+					want := toStorageRefreshTokenPolicy(tc.req.RefreshTokenPolicy)
+					if !reflect.DeepEqual(want, client.RefreshTokenPolicy) {
+						t.Errorf("expected stored client with refresh token policy: %+v, found %+v", want, client.RefreshTokenPolicy)
+					}
+				}
+				if tc.req.TokenExpiry != nil {
+					want := toStorageTokenExpiry(tc.req.TokenExpiry)
+					if !reflect.DeepEqual(want, client.TokenExpiry) {
+						t.Errorf("expected stored client with token expiry: %+v, found %+v", want, client.TokenExpiry)
+					}
+				}
 			}
 
 			if tc.cleanup != nil {
@@ -484,6 +789,109 @@ func TestUpdateClient(t *testing.T) {
 	}
 }
 
+func TestListClients(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	client := newAPI(s, logger, t)
+	defer client.Close()
+	ctx := context.Background()
+
+	for _, c := range []*api.Client{
+		{Id: "app-b", Name: "Billing"},
+		{Id: "app-a", Name: "Accounting"},
+		{Id: "cli", Name: "Command line", Public: true},
+		{Id: "app-c", Name: "Billing admin"},
+	} {
+		_, err := client.CreateClient(ctx, &api.CreateClientReq{Client: c})
+		require.NoError(t, err)
+	}
+
+	list := func(t *testing.T, req *api.ListClientsReq) ([]string, string) {
+		resp, err := client.ListClients(ctx, req)
+		require.NoError(t, err)
+		ids := []string{}
+		for _, c := range resp.Clients {
+			require.NotZero(t, c.CreatedAt)
+			require.Zero(t, c.LastUsed)
+			ids = append(ids, c.Id)
+		}
+		return ids, resp.NextPageToken
+	}
+
+	ids, next := list(t, &api.ListClientsReq{})
+	require.Equal(t, []string{"app-a", "app-b", "app-c", "cli"}, ids)
+	require.Empty(t, next)
+
+	ids, next = list(t, &api.ListClientsReq{PageSize: 3})
+	require.Equal(t, []string{"app-a", "app-b", "app-c"}, ids)
+	require.NotEmpty(t, next)
+	ids, next = list(t, &api.ListClientsReq{PageSize: 3, PageToken: next})
+	require.Equal(t, []string{"cli"}, ids)
+	require.Empty(t, next)
+
+	ids, _ = list(t, &api.ListClientsReq{NameFilter: "billing"})
+	require.Equal(t, []string{"app-b", "app-c"}, ids)
+	ids, _ = list(t, &api.ListClientsReq{IdFilter: "app", NameFilter: "ACC"})
+	require.Equal(t, []string{"app-a"}, ids)
+
+	_, err := client.ListClients(ctx, &api.ListClientsReq{PageToken: "not base64!"})
+	require.Error(t, err)
+}
+
+func TestRotateClientSecret(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	client := newAPI(s, logger, t)
+	defer client.Close()
+	ctx := context.Background()
+
+	_, err := client.CreateClient(ctx, &api.CreateClientReq{
+		Client: &api.Client{Id: "confidential", Secret: "old-secret"},
+	})
+	require.NoError(t, err)
+	_, err = client.CreateClient(ctx, &api.CreateClientReq{
+		Client: &api.Client{Id: "public", Public: true},
+	})
+	require.NoError(t, err)
+
+	before := time.Now()
+	resp, err := client.RotateClientSecret(ctx, &api.RotateClientSecretReq{
+		Id:                 "confidential",
+		NewSecret:          "new-secret",
+		GracePeriodSeconds: 3600,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "new-secret", resp.Secret)
+	require.GreaterOrEqual(t, resp.PreviousSecretExpiry, before.Add(time.Hour).Unix())
+
+	c, err := s.GetClient("confidential")
+	require.NoError(t, err)
+	require.Equal(t, "new-secret", c.Secret)
+	require.NotNil(t, c.PreviousSecret)
+	require.NoError(t, bcrypt.CompareHashAndPassword(c.PreviousSecret.Hash, []byte("old-secret")))
+
+	// Rotating without a grace period drops the previous secret.
+	resp, err = client.RotateClientSecret(ctx, &api.RotateClientSecretReq{Id: "confidential"})
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.Secret)
+	require.NotEqual(t, "new-secret", resp.Secret)
+	require.Zero(t, resp.PreviousSecretExpiry)
+
+	c, err = s.GetClient("confidential")
+	require.NoError(t, err)
+	require.Equal(t, resp.Secret, c.Secret)
+	require.Nil(t, c.PreviousSecret)
+
+	resp, err = client.RotateClientSecret(ctx, &api.RotateClientSecretReq{Id: "unknown"})
+	require.NoError(t, err)
+	require.True(t, resp.NotFound)
+
+	_, err = client.RotateClientSecret(ctx, &api.RotateClientSecretReq{Id: "public"})
+	require.Error(t, err)
+}
+
 func find(item string, items []string) bool {
 	for _, i := range items {
 		if item == i {
@@ -601,6 +1009,25 @@ func TestUpdateConnector(t *testing.T) {
 		}
 	}
 
+	// Display settings can be updated on their own.
+	displayReq := api.UpdateConnectorReq{
+		Id:         connectorID,
+		NewDisplay: &api.ConnectorDisplay{Order: 1, Group: "Partners", Hidden: true},
+	}
+	if _, err := client.UpdateConnector(ctx, &displayReq); err != nil {
+		t.Fatalf("Unable to update connector display: %v", err)
+	}
+	conn, err := s.GetConnector(connectorID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := (&storage.ConnectorDisplay{Order: 1, Group: "Partners", Hidden: true}); !reflect.DeepEqual(conn.Display, want) {
+		t.Fatalf("connector display should have been updated, got %+v", conn.Display)
+	}
+	if string(conn.Config) != string(newConnectorConfig) {
+		t.Fatal("connector config should have been kept")
+	}
+
 	updateReq.NewConfig = []byte("invalid_json")
 
 	// Test invalid JSON config in update request
@@ -734,3 +1161,272 @@ func TestMissingConnectorsCRUDFeatureFlag(t *testing.T) {
 		t.Fatal("ListConnectors should have returned an error")
 	}
 }
+
+func TestRunGarbageCollection(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	client := newAPI(s, logger, t)
+	defer client.Close()
+	ctx := context.Background()
+
+	require.NoError(t, s.CreateAuthRequest(ctx, storage.AuthRequest{
+		ID:       "expired",
+		ClientID: "app",
+		Expiry:   time.Now().Add(-time.Minute),
+	}))
+	require.NoError(t, s.CreateAuthRequest(ctx, storage.AuthRequest{
+		ID:       "valid",
+		ClientID: "app",
+		Expiry:   time.Now().Add(time.Hour),
+	}))
+
+	resp, err := client.RunGarbageCollection(ctx, &api.RunGarbageCollectionReq{})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), resp.AuthRequests)
+
+	_, err = s.GetAuthRequest("expired")
+	require.Equal(t, storage.ErrNotFound, err)
+	_, err = s.GetAuthRequest("valid")
+	require.NoError(t, err)
+}
+
+func TestRotateSigningKeys(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, server := newTestServer(ctx, t, nil)
+	defer httpServer.Close()
+
+	client := newServerAPI(server.storage, logger, server, t)
+	defer client.Close()
+
+	before, err := server.storage.GetKeys()
+	require.NoError(t, err)
+
+	_, err = client.RotateSigningKeys(ctx, &api.RotateSigningKeysReq{})
+	require.NoError(t, err)
+
+	after, err := server.storage.GetKeys()
+	require.NoError(t, err)
+	require.NotEqual(t, before.SigningKey.KeyID, after.SigningKey.KeyID)
+}
+
+func TestDeviceRequestApproval(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, server := newTestServer(ctx, t, nil)
+	defer httpServer.Close()
+
+	client := newServerAPI(server.storage, logger, server, t)
+	defer client.Close()
+
+	newDeviceRequest := func(userCode, deviceCode string) {
+		expiry := time.Now().Add(5 * time.Minute).Round(time.Second)
+		require.NoError(t, server.storage.CreateDeviceRequest(ctx, storage.DeviceRequest{
+			UserCode:   userCode,
+			DeviceCode: deviceCode,
+			ClientID:   "kiosk",
+			Scopes:     []string{"openid", "email", "offline_access"},
+			Expiry:     expiry,
+		}))
+		require.NoError(t, server.storage.CreateDeviceToken(ctx, storage.DeviceToken{
+			DeviceCode: deviceCode,
+			Status:     deviceTokenPending,
+			Expiry:     expiry,
+		}))
+	}
+	newDeviceRequest("ABCD-WXYZ", "approved")
+	newDeviceRequest("EFGH-STUV", "denied")
+
+	list, err := client.ListDeviceRequests(ctx, &api.ListDeviceRequestsReq{ClientId: "kiosk"})
+	require.NoError(t, err)
+	require.Len(t, list.DeviceRequests, 2)
+
+	approve := &api.ApproveDeviceRequestReq{
+		UserCode:    "abcd-wxyz",
+		ConnectorId: "mock",
+		UserId:      "1",
+		Email:       "jane@example.com",
+	}
+	resp, err := client.ApproveDeviceRequest(ctx, approve)
+	require.NoError(t, err)
+	require.False(t, resp.NotFound)
+
+	token, err := server.storage.GetDeviceToken("approved")
+	require.NoError(t, err)
+	require.Equal(t, deviceTokenComplete, token.Status)
+	var tokenResp accessTokenResponse
+	require.NoError(t, json.Unmarshal([]byte(token.Token), &tokenResp))
+	require.NotEmpty(t, tokenResp.IDToken)
+	require.Empty(t, tokenResp.RefreshToken)
+
+	// Decided requests can't be approved again.
+	resp, err = client.ApproveDeviceRequest(ctx, approve)
+	require.NoError(t, err)
+	require.True(t, resp.NotFound)
+
+	denyResp, err := client.DenyDeviceRequest(ctx, &api.DenyDeviceRequestReq{UserCode: "EFGH-STUV"})
+	require.NoError(t, err)
+	require.False(t, denyResp.NotFound)
+
+	token, err = server.storage.GetDeviceToken("denied")
+	require.NoError(t, err)
+	require.Equal(t, deviceTokenDenied, token.Status)
+
+	list, err = client.ListDeviceRequests(ctx, &api.ListDeviceRequestsReq{})
+	require.NoError(t, err)
+	require.Empty(t, list.DeviceRequests)
+
+	_, err = client.ApproveDeviceRequest(ctx, &api.ApproveDeviceRequestReq{UserCode: "ABCD-WXYZ", ConnectorId: "unknown", UserId: "1"})
+	require.Error(t, err)
+}
+
+// failingPasswordStorage fails to create passwords.
+type failingPasswordStorage struct {
+	storage.Storage
+}
+
+func (s failingPasswordStorage) CreatePassword(context.Context, storage.Password) error {
+	return errors.New("storage unavailable")
+}
+
+func TestImportExport(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	client := newAPI(s, logger, t)
+	defer client.Close()
+	ctx := context.Background()
+
+	// bcrypt hash of the value "test1" with cost 10
+	hash := []byte("$2a$10$XVMN/Fid.Ks4CXgzo8fpR.iU1khOMsP5g9xQeXuBm1wXjRX8pjUtO")
+	require.NoError(t, s.CreateClient(ctx, storage.Client{ID: "existing", Secret: "old", Name: "Old"}))
+
+	req := &api.ImportReq{
+		Clients: []*api.Client{
+			{Id: "app", Secret: "secret", RedirectUris: []string{"https://app.example.com/callback"}},
+			{Id: "existing", Secret: "new", Name: "New"},
+			{Id: "cli", Public: true},
+		},
+		Passwords: []*api.Password{
+			{Email: "jane@example.com", Hash: hash, Username: "jane", UserId: "1"},
+		},
+	}
+
+	// Existing clients are rejected unless updates are allowed, and nothing is stored.
+	resp, err := client.Import(ctx, req)
+	require.NoError(t, err)
+	require.False(t, resp.Applied)
+	require.Equal(t, "client already exists", resp.Clients[1].Error)
+	require.True(t, resp.Clients[0].Created)
+	_, err = s.GetClient("app")
+	require.Equal(t, storage.ErrNotFound, err)
+
+	req.UpdateExisting = true
+	req.DryRun = true
+	resp, err = client.Import(ctx, req)
+	require.NoError(t, err)
+	require.False(t, resp.Applied)
+	_, err = s.GetClient("app")
+	require.Equal(t, storage.ErrNotFound, err)
+
+	req.DryRun = false
+	resp, err = client.Import(ctx, req)
+	require.NoError(t, err)
+	require.True(t, resp.Applied)
+	require.True(t, resp.Clients[1].Updated)
+	require.True(t, resp.Passwords[0].Created)
+
+	existing, err := s.GetClient("existing")
+	require.NoError(t, err)
+	require.Equal(t, "New", existing.Name)
+	_, err = s.GetPassword("jane@example.com")
+	require.NoError(t, err)
+
+	// Invalid items are reported one by one.
+	resp, err = client.Import(ctx, &api.ImportReq{
+		Clients: []*api.Client{
+			{Id: "confidential"},
+			{Id: "other-cli", Public: true},
+			{Id: "other-cli", Public: true},
+		},
+		Passwords: []*api.Password{
+			{Email: "weak@example.com", Hash: []byte("$2a$04$TFbdzvaOAGHbB5OXlcUQSOJrUFn8QYBCSrk1J0ewcVGLqE3OEJCSq"), UserId: "2"},
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, resp.Applied)
+	require.Equal(t, "no secret supplied for a confidential client", resp.Clients[0].Error)
+	require.Empty(t, resp.Clients[1].Error)
+	require.Equal(t, "duplicate client", resp.Clients[2].Error)
+	require.NotEmpty(t, resp.Passwords[0].Error)
+
+	exported, err := client.Export(ctx, &api.ExportReq{})
+	require.NoError(t, err)
+	require.Len(t, exported.Clients, 3)
+	require.Equal(t, "app", exported.Clients[0].Id)
+	require.Equal(t, "secret", exported.Clients[0].Secret)
+	require.Len(t, exported.Passwords, 1)
+	require.Equal(t, hash, exported.Passwords[0].Hash)
+
+	// Items stored before a storage failure are rolled back.
+	failing := NewAPI(failingPasswordStorage{s}, logger, "test", nil)
+	_, err = failing.Import(ctx, &api.ImportReq{
+		Clients:   []*api.Client{{Id: "rolled-back", Secret: "secret"}},
+		Passwords: []*api.Password{{Email: "john@example.com", Hash: hash, UserId: "3"}},
+	})
+	require.Error(t, err)
+	_, err = s.GetClient("rolled-back")
+	require.Equal(t, storage.ErrNotFound, err)
+}
+
+func TestWebhooks(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	client := newAPI(s, logger, t)
+	defer client.Close()
+	ctx := context.Background()
+
+	for _, w := range []*api.Webhook{
+		{Url: "ftp://hooks.example.com"},
+		{Url: "/relative"},
+		{Url: "https://hooks.example.com", Events: []string{"unknown"}},
+		{Url: "https://hooks.example.com", Backoff: "soon"},
+		{Url: "https://hooks.example.com", MaxAttempts: -1},
+	} {
+		_, err := client.CreateWebhook(ctx, &api.CreateWebhookReq{Webhook: w})
+		require.Error(t, err, "url %q", w.Url)
+	}
+
+	created, err := client.CreateWebhook(ctx, &api.CreateWebhookReq{Webhook: &api.Webhook{
+		Url:         "https://hooks.example.com/dex",
+		Events:      []string{eventLoginSucceeded, eventClientModified},
+		MaxAttempts: 3,
+		Backoff:     "2s",
+	}})
+	require.NoError(t, err)
+	require.NotEmpty(t, created.Webhook.Id)
+	require.NotEmpty(t, created.Webhook.Secret, "secret must be generated")
+
+	stored, err := s.GetWebhook(created.Webhook.Id)
+	require.NoError(t, err)
+	require.Equal(t, created.Webhook.Secret, stored.Secret)
+	require.Equal(t, &storage.WebhookRetryPolicy{MaxAttempts: 3, Backoff: "2s"}, stored.RetryPolicy)
+
+	list, err := client.ListWebhooks(ctx, &api.ListWebhooksReq{})
+	require.NoError(t, err)
+	require.Len(t, list.Webhooks, 1)
+	require.Equal(t, "https://hooks.example.com/dex", list.Webhooks[0].Url)
+	require.Empty(t, list.Webhooks[0].Secret, "secrets must not be listed")
+
+	resp, err := client.DeleteWebhook(ctx, &api.DeleteWebhookReq{Id: created.Webhook.Id})
+	require.NoError(t, err)
+	require.False(t, resp.NotFound)
+
+	resp, err = client.DeleteWebhook(ctx, &api.DeleteWebhookReq{Id: created.Webhook.Id})
+	require.NoError(t, err)
+	require.True(t, resp.NotFound)
+}
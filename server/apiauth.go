@@ -0,0 +1,213 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/dexidp/dex/api/v2"
+	"github.com/dexidp/dex/storage"
+)
+
+// APIRole is a coarse permission on the gRPC API.
+type APIRole string
+
+const (
+	// APIRoleReadOnly allows reading clients, connectors, passwords and users.
+	APIRoleReadOnly APIRole = "read-only"
+	// APIRoleClientAdmin allows managing clients and connectors, and running
+	// maintenance actions.
+	APIRoleClientAdmin APIRole = "client-admin"
+	// APIRoleUserAdmin allows managing passwords and user sessions.
+	APIRoleUserAdmin APIRole = "user-admin"
+)
+
+// apiMethodRoles maps the methods of the gRPC API to the roles they require.
+// Both admin roles imply the read-only role. Methods missing from the map are
+// denied to everyone.
+var apiMethodRoles = map[string][]APIRole{
+	api.Dex_GetClient_FullMethodName:          {APIRoleReadOnly},
+	api.Dex_ListClients_FullMethodName:        {APIRoleReadOnly},
+	api.Dex_ListPasswords_FullMethodName:      {APIRoleReadOnly},
+	api.Dex_ListConnectors_FullMethodName:     {APIRoleReadOnly},
+	api.Dex_GetVersion_FullMethodName:         {APIRoleReadOnly},
+	api.Dex_GetDiscovery_FullMethodName:       {APIRoleReadOnly},
+	api.Dex_ListRefresh_FullMethodName:        {APIRoleReadOnly},
+	api.Dex_ListUsers_FullMethodName:          {APIRoleReadOnly},
+	api.Dex_WatchEvents_FullMethodName:        {APIRoleReadOnly},
+	api.Dex_ListDeviceRequests_FullMethodName: {APIRoleReadOnly},
+	api.Dex_ListWebhooks_FullMethodName:       {APIRoleReadOnly},
+	api.Dex_CreateClient_FullMethodName:       {APIRoleClientAdmin},
+	api.Dex_UpdateClient_FullMethodName:       {APIRoleClientAdmin},
+	api.Dex_DeleteClient_FullMethodName:       {APIRoleClientAdmin},
+	api.Dex_RotateClientSecret_FullMethodName: {APIRoleClientAdmin},
+	api.Dex_CreateConnector_FullMethodName:    {APIRoleClientAdmin},
+	api.Dex_UpdateConnector_FullMethodName:    {APIRoleClientAdmin},
+	api.Dex_DeleteConnector_FullMethodName:    {APIRoleClientAdmin},
+	api.Dex_CreateWebhook_FullMethodName:      {APIRoleClientAdmin},
+	api.Dex_DeleteWebhook_FullMethodName:      {APIRoleClientAdmin},
+	// Maintenance actions count as configuration changes.
+	api.Dex_RunGarbageCollection_FullMethodName: {APIRoleClientAdmin},
+	api.Dex_RotateSigningKeys_FullMethodName:    {APIRoleClientAdmin},
+	api.Dex_CreatePassword_FullMethodName:       {APIRoleUserAdmin},
+	api.Dex_UpdatePassword_FullMethodName:       {APIRoleUserAdmin},
+	api.Dex_DeletePassword_FullMethodName:       {APIRoleUserAdmin},
+	api.Dex_UnlockPassword_FullMethodName:       {APIRoleUserAdmin},
+	api.Dex_RevokeRefresh_FullMethodName:        {APIRoleUserAdmin},
+	api.Dex_RevokeUserSessions_FullMethodName:   {APIRoleUserAdmin},
+	api.Dex_ApproveDeviceRequest_FullMethodName: {APIRoleUserAdmin},
+	api.Dex_DenyDeviceRequest_FullMethodName:    {APIRoleUserAdmin},
+	// Verifying passwords allows guessing them, so it's not a read.
+	api.Dex_VerifyPassword_FullMethodName: {APIRoleUserAdmin},
+	// Bulk operations touch, or reveal the secrets of, both clients and users.
+	api.Dex_Import_FullMethodName: {APIRoleClientAdmin, APIRoleUserAdmin},
+	api.Dex_Export_FullMethodName: {APIRoleClientAdmin, APIRoleUserAdmin},
+}
+
+// APIToken grants roles to callers presenting a static bearer token.
+type APIToken struct {
+	Token string
+	Roles []APIRole
+}
+
+// APIAuthConfig configures the authentication of the gRPC API.
+//
+// Only static tokens are supported. Tokens issued by the server don't prove
+// that the caller authenticated as a client rather than a user logging in
+// through it, since there's no client credentials grant.
+type APIAuthConfig struct {
+	Tokens []APIToken
+}
+
+type apiAuthenticator struct {
+	tokens []APIToken
+	logger *slog.Logger
+}
+
+func validateAPIRoles(roles []APIRole) error {
+	if len(roles) == 0 {
+		return fmt.Errorf("no roles specified")
+	}
+	for _, role := range roles {
+		switch role {
+		case APIRoleReadOnly, APIRoleClientAdmin, APIRoleUserAdmin:
+		default:
+			return fmt.Errorf("unknown role %q", role)
+		}
+	}
+	return nil
+}
+
+// NewAPIAuthInterceptors returns unary and stream interceptors authenticating
+// callers of the gRPC API with a bearer token in the "authorization" metadata
+// and enforcing their roles. Services other than the Dex API, such as health
+// checks, are served unauthenticated.
+func NewAPIAuthInterceptors(config APIAuthConfig, logger *slog.Logger) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor, error) {
+	a := &apiAuthenticator{
+		tokens: config.Tokens,
+		logger: logger.With("component", "api-auth"),
+	}
+	for i, t := range config.Tokens {
+		if t.Token == "" {
+			return nil, nil, fmt.Errorf("token %d: no token specified", i)
+		}
+		if err := validateAPIRoles(t.Roles); err != nil {
+			return nil, nil, fmt.Errorf("token %d: %v", i, err)
+		}
+	}
+	return a.intercept, a.interceptStream, nil
+}
+
+func (a *apiAuthenticator) intercept(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := a.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *apiAuthenticator) interceptStream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authorize(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &contextServerStream{ServerStream: stream, ctx: ctx})
+}
+
+// authorize checks whether the caller may call a method, and returns a
+// context holding the name of the caller, which is also recorded as the actor
+// of storage changes.
+func (a *apiAuthenticator) authorize(ctx context.Context, method string) (context.Context, error) {
+	if !strings.HasPrefix(method, "/"+api.Dex_ServiceDesc.ServiceName+"/") {
+		return ctx, nil
+	}
+
+	caller, roles, err := a.authenticate(ctx)
+	if err != nil {
+		return ctx, err
+	}
+
+	required, ok := apiMethodRoles[method]
+	if !ok || !hasAPIRoles(roles, required) {
+		a.logger.WarnContext(ctx, "permission denied", "method", method, "caller", caller)
+		return ctx, status.Errorf(codes.PermissionDenied, "permission denied for %s", method)
+	}
+	return storage.WithActor(context.WithValue(ctx, apiCallerKey{}, caller), caller), nil
+}
+
+// authenticate returns the name and the roles of the caller. Static tokens
+// are named by their position in the config, so they don't end up in logs.
+func (a *apiAuthenticator) authenticate(ctx context.Context) (string, []APIRole, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) != 1 {
+		return "", nil, status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+	scheme, token, ok := strings.Cut(values[0], " ")
+	if !ok || !strings.EqualFold(scheme, "bearer") || token == "" {
+		return "", nil, status.Error(codes.Unauthenticated, "malformed authorization metadata")
+	}
+
+	for i, t := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(t.Token), []byte(token)) == 1 {
+			return fmt.Sprintf("token:%d", i), t.Roles, nil
+		}
+	}
+	return "", nil, status.Error(codes.Unauthenticated, "invalid bearer token")
+}
+
+// apiCallerKey is the context key of the name of an authenticated caller.
+type apiCallerKey struct{}
+
+// contextServerStream overrides the context of a stream.
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextServerStream) Context() context.Context { return s.ctx }
+
+// hasAPIRoles reports whether roles include all the required roles.
+func hasAPIRoles(roles, required []APIRole) bool {
+	for _, r := range required {
+		if !hasAPIRole(roles, r) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasAPIRole(roles []APIRole, required APIRole) bool {
+	if slices.Contains(roles, required) {
+		return true
+	}
+	return required == APIRoleReadOnly && (slices.Contains(roles, APIRoleClientAdmin) || slices.Contains(roles, APIRoleUserAdmin))
+}
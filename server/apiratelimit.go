@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/dexidp/dex/api/v2"
+)
+
+// apiLimiterIdleTimeout is how long the limiter of a caller is kept after its
+// last call.
+const apiLimiterIdleTimeout = 10 * time.Minute
+
+// APIRateLimit configures the rate at which each caller may call the gRPC API.
+type APIRateLimit struct {
+	// Sustained number of calls per second.
+	RequestsPerSecond float64
+	// Number of calls allowed at once, defaults to one second worth of calls.
+	Burst int
+}
+
+type apiLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+type apiRateLimiter struct {
+	limit rate.Limit
+	burst int
+	now   func() time.Time
+
+	mu        sync.Mutex
+	limiters  map[string]*apiLimiter
+	lastSweep time.Time
+
+	logger *slog.Logger
+}
+
+// NewAPIRateLimitInterceptors returns unary and stream interceptors limiting
+// the rate of calls of each caller. Callers are told apart by the name the
+// authentication interceptor gave them, their TLS client certificate, or
+// their IP address, in that order. The authentication interceptors must run
+// first for the name to be used.
+func NewAPIRateLimitInterceptors(config APIRateLimit, logger *slog.Logger) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor, error) {
+	if config.RequestsPerSecond <= 0 {
+		return nil, nil, fmt.Errorf("requests per second must be positive")
+	}
+	if config.Burst < 0 {
+		return nil, nil, fmt.Errorf("burst must not be negative")
+	}
+	burst := config.Burst
+	if burst == 0 {
+		burst = max(int(config.RequestsPerSecond), 1)
+	}
+	l := &apiRateLimiter{
+		limit:    rate.Limit(config.RequestsPerSecond),
+		burst:    burst,
+		now:      time.Now,
+		limiters: make(map[string]*apiLimiter),
+		logger:   logger.With("component", "api-ratelimit"),
+	}
+	return l.intercept, l.interceptStream, nil
+}
+
+func (l *apiRateLimiter) intercept(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := l.check(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (l *apiRateLimiter) interceptStream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := l.check(stream.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, stream)
+}
+
+// check rejects calls exceeding the rate of the caller. Services other than
+// the Dex API, such as health checks, aren't limited.
+func (l *apiRateLimiter) check(ctx context.Context, method string) error {
+	if !strings.HasPrefix(method, "/"+api.Dex_ServiceDesc.ServiceName+"/") {
+		return nil
+	}
+	caller := apiCaller(ctx)
+	if !l.allow(caller) {
+		l.logger.WarnContext(ctx, "rate limit exceeded", "method", method, "caller", caller)
+		return status.Errorf(codes.ResourceExhausted, "rate limit exceeded for %s", method)
+	}
+	return nil
+}
+
+func (l *apiRateLimiter) allow(caller string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) > apiLimiterIdleTimeout {
+		for name, limiter := range l.limiters {
+			if now.Sub(limiter.lastSeen) > apiLimiterIdleTimeout {
+				delete(l.limiters, name)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	limiter, ok := l.limiters[caller]
+	if !ok {
+		limiter = &apiLimiter{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.limiters[caller] = limiter
+	}
+	limiter.lastSeen = now
+	return limiter.limiter.AllowN(now, 1)
+}
+
+// apiCaller returns the name of the caller of the gRPC API.
+func apiCaller(ctx context.Context) string {
+	if caller, ok := ctx.Value(apiCallerKey{}).(string); ok {
+		return caller
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.PeerCertificates) > 0 {
+		return "cert:" + tlsInfo.State.PeerCertificates[0].Subject.String()
+	}
+	if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+		return "ip:" + host
+	}
+	return "ip:" + p.Addr.String()
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/dexidp/dex/api/v2"
+)
+
+func TestAPIRateLimitInterceptor(t *testing.T) {
+	intercept, _, err := NewAPIRateLimitInterceptors(APIRateLimit{RequestsPerSecond: 1, Burst: 2}, logger)
+	require.NoError(t, err)
+
+	fromIP := func(ip string) context.Context {
+		return peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 1234}})
+	}
+	call := func(ctx context.Context, method string) codes.Code {
+		_, err := intercept(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(context.Context, interface{}) (interface{}, error) {
+			return nil, nil
+		})
+		return status.Code(err)
+	}
+
+	a := fromIP("10.0.0.1")
+	require.Equal(t, codes.OK, call(a, api.Dex_CreateClient_FullMethodName))
+	require.Equal(t, codes.OK, call(a, api.Dex_CreateClient_FullMethodName))
+	require.Equal(t, codes.ResourceExhausted, call(a, api.Dex_CreateClient_FullMethodName))
+
+	// Health checks aren't limited.
+	require.Equal(t, codes.OK, call(a, "/grpc.health.v1.Health/Check"))
+
+	// Callers are limited separately.
+	require.Equal(t, codes.OK, call(fromIP("10.0.0.2"), api.Dex_CreateClient_FullMethodName))
+
+	// Authenticated callers are told apart by name rather than address.
+	authenticated := context.WithValue(a, apiCallerKey{}, "token:0")
+	require.Equal(t, codes.OK, call(authenticated, api.Dex_CreateClient_FullMethodName))
+}
+
+func TestAPIRateLimiterSweep(t *testing.T) {
+	now := time.Now()
+	l := &apiRateLimiter{
+		limit:    1,
+		burst:    1,
+		now:      func() time.Time { return now },
+		limiters: make(map[string]*apiLimiter),
+	}
+	require.True(t, l.allow("a"))
+	require.False(t, l.allow("a"))
+
+	now = now.Add(apiLimiterIdleTimeout + time.Second)
+	require.True(t, l.allow("b"))
+	require.NotContains(t, l.limiters, "a")
+}
+
+func TestNewAPIRateLimitInterceptorsValidation(t *testing.T) {
+	_, _, err := NewAPIRateLimitInterceptors(APIRateLimit{}, logger)
+	require.Error(t, err)
+	_, _, err = NewAPIRateLimitInterceptors(APIRateLimit{RequestsPerSecond: 1, Burst: -1}, logger)
+	require.Error(t, err)
+}
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/peer"
+
+	"github.com/dexidp/dex/storage"
+)
+
+// defaultAuditQueueSize is the number of records buffered for each sink.
+const defaultAuditQueueSize = 1000
+
+// auditAttempts and auditBackoff bound the delivery of a record to a sink.
+const (
+	auditAttempts = 5
+	auditBackoff  = time.Second
+)
+
+// auditGuaranteedEvents are the security relevant events which are never
+// dropped: requests emitting them wait for room in the queues of the sinks.
+var auditGuaranteedEvents = map[string]bool{
+	eventLoginSucceeded: true,
+	eventLoginFailed:    true,
+	eventConsentGranted: true,
+	eventConsentDenied:  true,
+	eventTokenIssued:    true,
+	eventTokenRefreshed: true,
+	eventRefreshRevoked: true,
+	eventClientModified: true,
+	eventPasswordReset:  true,
+
+	eventRepeatedLoginFailures: true,
+	eventRefreshReused:         true,
+	eventPasswordLocked:        true,
+	eventPasswordUnlocked:      true,
+}
+
+// AuditLogConfig writes a structured record of every audit event to sinks.
+type AuditLogConfig struct {
+	Sinks []AuditSink
+
+	// Number of records buffered for each sink. Defaults to 1000.
+	QueueSize int
+}
+
+// AuditSink writes audit records, for example to a file or a message queue.
+// Writes failing with an error are retried. The servers of several issuers
+// may share a sink, so it must be safe for concurrent use.
+type AuditSink interface {
+	// Name identifies the sink in logs.
+	Name() string
+	WriteAuditRecord(ctx context.Context, record AuditRecord) error
+}
+
+// AuditRecord is an audit event with the request it happened in.
+type AuditRecord struct {
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"`
+	Outcome string    `json:"outcome"`
+	Issuer  string    `json:"issuer"`
+
+	// Who: the user the event is about, and the caller of the gRPC API
+	// making the change, if any.
+	UserID   string `json:"user_id,omitempty"`
+	Username string `json:"username,omitempty"`
+	Actor    string `json:"actor,omitempty"`
+
+	ClientID    string   `json:"client_id,omitempty"`
+	ConnectorID string   `json:"connector_id,omitempty"`
+	Scopes      []string `json:"scopes,omitempty"`
+
+	RemoteIP  string `json:"remote_ip,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+
+	Details map[string]string `json:"details,omitempty"`
+}
+
+type auditLog struct {
+	sinks []*auditSinkQueue
+}
+
+type auditSinkQueue struct {
+	sink    AuditSink
+	records chan AuditRecord
+}
+
+func newAuditLog(c *AuditLogConfig) (*auditLog, error) {
+	if len(c.Sinks) == 0 {
+		return nil, errors.New("no audit log sinks configured")
+	}
+	if c.QueueSize < 0 {
+		return nil, errors.New("audit log queue size must not be negative")
+	}
+	size := c.QueueSize
+	if size == 0 {
+		size = defaultAuditQueueSize
+	}
+	l := &auditLog{}
+	for _, sink := range c.Sinks {
+		l.sinks = append(l.sinks, &auditSinkQueue{sink: sink, records: make(chan AuditRecord, size)})
+	}
+	return l, nil
+}
+
+// auditRequestKey is the context key of the client of an HTTP request, which
+// is recorded in audit records and counted by failed login detection.
+type auditRequestKey struct{}
+
+type auditRequest struct {
+	remoteIP  string
+	userAgent string
+}
+
+// withAuditRequest remembers the client of a request for the audit records
+// of the events it causes.
+func withAuditRequest(r *http.Request) context.Context {
+	return context.WithValue(r.Context(), auditRequestKey{}, auditRequest{clientIP(r), r.UserAgent()})
+}
+
+// newAuditRecord returns the record of an event emitted while handling the
+// HTTP or gRPC request of a context.
+func newAuditRecord(ctx context.Context, e auditEvent) AuditRecord {
+	outcome := "success"
+	if e.Type == eventLoginFailed || e.Type == eventConsentDenied {
+		outcome = "failure"
+	}
+	record := AuditRecord{
+		Time:        e.Time,
+		Type:        e.Type,
+		Outcome:     outcome,
+		Issuer:      e.Issuer,
+		UserID:      e.UserID,
+		Username:    e.Username,
+		Actor:       storage.ActorFromContext(ctx),
+		ClientID:    e.ClientID,
+		ConnectorID: e.ConnectorID,
+		Scopes:      e.Scopes,
+		Details:     e.Details,
+	}
+	if req, ok := ctx.Value(auditRequestKey{}).(auditRequest); ok {
+		record.RemoteIP = req.remoteIP
+		record.UserAgent = req.userAgent
+	} else if p, ok := peer.FromContext(ctx); ok {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			record.RemoteIP = host
+		}
+	}
+	return record
+}
+
+// recordAudit queues the record of an event for every sink. Records of
+// guaranteed events wait for room in the queues until the request is
+// canceled, other records are dropped if a queue is full.
+func (s *Server) recordAudit(ctx context.Context, e auditEvent) {
+	record := newAuditRecord(ctx, e)
+	for _, q := range s.auditLog.sinks {
+		if !auditGuaranteedEvents[e.Type] {
+			select {
+			case q.records <- record:
+			default:
+				s.logger.WarnContext(ctx, "audit log queue full, dropping record", "sink", q.sink.Name(), "type", e.Type)
+			}
+			continue
+		}
+		select {
+		case q.records <- record:
+		case <-ctx.Done():
+			// Don't lose the record, even if the sink is stuck.
+			s.logger.ErrorContext(ctx, "audit log queue full, logging record instead", "sink", q.sink.Name(), "record", record)
+		}
+	}
+}
+
+// startAuditLog writes the queued records to the sinks until the context is
+// canceled.
+func (s *Server) startAuditLog(ctx context.Context) {
+	for _, q := range s.auditLog.sinks {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case record := <-q.records:
+					s.writeAuditRecord(ctx, q.sink, record)
+				}
+			}
+		}()
+	}
+}
+
+// writeAuditRecord writes a record to a sink, retrying with exponential
+// backoff. Records the sink doesn't accept are logged instead.
+func (s *Server) writeAuditRecord(ctx context.Context, sink AuditSink, record AuditRecord) {
+	backoff := auditBackoff
+	for attempt := 1; ; attempt++ {
+		err := sink.WriteAuditRecord(ctx, record)
+		if err == nil {
+			return
+		}
+		if attempt >= auditAttempts || ctx.Err() != nil {
+			s.logger.ErrorContext(ctx, "failed to write audit record, logging it instead",
+				"sink", sink.Name(), "attempts", attempt, "err", err, "record", record)
+			return
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
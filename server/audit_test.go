@@ -0,0 +1,280 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dexidp/dex/storage"
+)
+
+type chanAuditSink chan AuditRecord
+
+func (c chanAuditSink) Name() string { return "chan" }
+
+func (c chanAuditSink) WriteAuditRecord(_ context.Context, record AuditRecord) error {
+	c <- record
+	return nil
+}
+
+func nextAuditRecord(t *testing.T, records <-chan AuditRecord) AuditRecord {
+	t.Helper()
+	select {
+	case record := <-records:
+		return record
+	case <-time.After(5 * time.Second):
+		t.Fatal("no audit record written")
+		return AuditRecord{}
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	records := make(chanAuditSink, 10)
+	httpServer, s := newTestServer(ctx, t, func(c *Config) {
+		c.AuditLog = &AuditLogConfig{Sinks: []AuditSink{records}}
+	})
+	defer httpServer.Close()
+
+	require.NoError(t, s.storage.CreateClient(ctx, storage.Client{
+		ID:           "app",
+		Secret:       "secret",
+		RedirectURIs: []string{"https://app.example.com/callback"},
+	}))
+	require.NoError(t, s.storage.CreateAuthRequest(ctx, storage.AuthRequest{
+		ID:            "login",
+		ClientID:      "app",
+		ConnectorID:   "mock",
+		RedirectURI:   "https://app.example.com/callback",
+		Scopes:        []string{"openid", "email"},
+		Expiry:        time.Now().Add(time.Minute),
+		ResponseTypes: []string{responseTypeCode},
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/callback?state=login", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.Header.Set("User-Agent", "test-agent")
+	rr := httptest.NewRecorder()
+	s.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusSeeOther, rr.Code)
+
+	record := nextAuditRecord(t, records)
+	require.Equal(t, eventLoginSucceeded, record.Type)
+	require.Equal(t, "success", record.Outcome)
+	require.Equal(t, s.issuerURL.String(), record.Issuer)
+	require.Equal(t, "app", record.ClientID)
+	require.Equal(t, "mock", record.ConnectorID)
+	require.Equal(t, "0-385-28089-0", record.UserID)
+	require.Equal(t, []string{"openid", "email"}, record.Scopes)
+	require.Equal(t, "192.0.2.1", record.RemoteIP)
+	require.Equal(t, "test-agent", record.UserAgent)
+
+	u, err := url.Parse(rr.Header().Get("Location"))
+	require.NoError(t, err)
+	form := url.Values{
+		"grant_type":   {grantTypeAuthorizationCode},
+		"code":         {u.Query().Get("code")},
+		"redirect_uri": {"https://app.example.com/callback"},
+	}
+	req = httptest.NewRequest(http.MethodPost, "/token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("app", "secret")
+	rr = httptest.NewRecorder()
+	s.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	tokenTypes := map[string]bool{}
+	for range 2 {
+		record := nextAuditRecord(t, records)
+		require.Equal(t, eventTokenIssued, record.Type)
+		require.Equal(t, "app", record.ClientID)
+		require.Equal(t, []string{"openid", "email"}, record.Scopes)
+		tokenTypes[record.Details["token_type"]] = true
+	}
+	require.Equal(t, map[string]bool{"access_token": true, "id_token": true}, tokenTypes)
+
+	require.NoError(t, s.storage.CreateAuthRequest(ctx, storage.AuthRequest{
+		ID:            "consent",
+		ClientID:      "app",
+		ConnectorID:   "mock",
+		RedirectURI:   "https://app.example.com/callback",
+		Scopes:        []string{"openid"},
+		LoggedIn:      true,
+		Claims:        storage.Claims{UserID: "1", Username: "jane"},
+		HMACKey:       []byte("key"),
+		Expiry:        time.Now().Add(time.Minute),
+		ResponseTypes: []string{responseTypeCode},
+	}))
+	h := hmac.New(sha256.New, []byte("key"))
+	h.Write([]byte("consent"))
+	approval := url.Values{"req": {"consent"}, "hmac": {base64.RawURLEncoding.EncodeToString(h.Sum(nil))}, "approval": {"rejected"}}
+	req = httptest.NewRequest(http.MethodPost, "/approval", strings.NewReader(approval.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	s.ServeHTTP(httptest.NewRecorder(), req)
+
+	record = nextAuditRecord(t, records)
+	require.Equal(t, eventConsentDenied, record.Type)
+	require.Equal(t, "failure", record.Outcome)
+	require.Equal(t, "1", record.UserID)
+	require.Equal(t, []string{"openid"}, record.Scopes)
+}
+
+func TestAuditSinks(t *testing.T) {
+	ctx := context.Background()
+	record := AuditRecord{
+		Time:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Type:    eventLoginFailed,
+		Outcome: "failure",
+		UserID:  "1",
+	}
+
+	t.Run("file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.log")
+		sink, err := NewFileAuditSink(FileAuditSinkConfig{Path: path})
+		require.NoError(t, err)
+		require.NoError(t, sink.WriteAuditRecord(ctx, record))
+		require.NoError(t, sink.WriteAuditRecord(ctx, record))
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		require.Len(t, lines, 2)
+		var got AuditRecord
+		require.NoError(t, json.Unmarshal([]byte(lines[1]), &got))
+		require.Equal(t, record, got)
+	})
+
+	t.Run("http", func(t *testing.T) {
+		var got AuditRecord
+		endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		}))
+		defer endpoint.Close()
+
+		sink, err := NewHTTPAuditSink(HTTPAuditSinkConfig{URL: endpoint.URL})
+		require.NoError(t, err)
+		require.Error(t, sink.WriteAuditRecord(ctx, record))
+
+		sink, err = NewHTTPAuditSink(HTTPAuditSinkConfig{URL: endpoint.URL, Headers: map[string]string{"Authorization": "Bearer token"}})
+		require.NoError(t, err)
+		require.NoError(t, sink.WriteAuditRecord(ctx, record))
+		require.Equal(t, record, got)
+	})
+
+	t.Run("kafka", func(t *testing.T) {
+		var path, contentType string
+		var body []byte
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path, contentType = r.URL.Path, r.Header.Get("Content-Type")
+			body, _ = io.ReadAll(r.Body)
+		}))
+		defer proxy.Close()
+
+		sink, err := NewKafkaAuditSink(KafkaAuditSinkConfig{URL: proxy.URL, Topic: "audit"})
+		require.NoError(t, err)
+		require.NoError(t, sink.WriteAuditRecord(ctx, record))
+		require.Equal(t, "/topics/audit", path)
+		require.Equal(t, "application/vnd.kafka.json.v2+json", contentType)
+
+		var got struct {
+			Records []struct {
+				Key   string      `json:"key"`
+				Value AuditRecord `json:"value"`
+			} `json:"records"`
+		}
+		require.NoError(t, json.Unmarshal(body, &got))
+		require.Len(t, got.Records, 1)
+		require.Equal(t, "1", got.Records[0].Key)
+		require.Equal(t, record, got.Records[0].Value)
+	})
+
+	t.Run("nats", func(t *testing.T) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer l.Close()
+
+		type message struct {
+			connect string
+			subject string
+			payload []byte
+		}
+		messages := make(chan message, 1)
+		go func() {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			r := bufio.NewReader(conn)
+			io.WriteString(conn, `INFO {"server_id":"test","auth_required":true}`+"\r\n")
+			var m message
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				cmd, args, _ := strings.Cut(strings.TrimSpace(line), " ")
+				switch cmd {
+				case "CONNECT":
+					m.connect = args
+				case "PUB":
+					var size int
+					fields := strings.Fields(args)
+					m.subject = fields[0]
+					fmt.Sscan(fields[1], &size)
+					m.payload = make([]byte, size+2)
+					io.ReadFull(r, m.payload)
+					m.payload = m.payload[:size]
+				case "PING":
+					if m.subject != "" {
+						messages <- m
+						m.subject = ""
+					}
+					io.WriteString(conn, "PONG\r\n")
+				}
+			}
+		}()
+
+		sink, err := NewNATSAuditSink(NATSAuditSinkConfig{URL: "nats://" + l.Addr().String(), Subject: "dex.events", Token: "secret"})
+		require.NoError(t, err)
+		require.NoError(t, sink.WriteAuditRecord(ctx, record))
+
+		m := <-messages
+		require.Equal(t, "dex.events.login_failed", m.subject)
+		require.Contains(t, m.connect, `"auth_token":"secret"`)
+		var got AuditRecord
+		require.NoError(t, json.Unmarshal(m.payload, &got))
+		require.Equal(t, record, got)
+	})
+
+	_, err := NewKafkaAuditSink(KafkaAuditSinkConfig{URL: "http://kafka-rest:8082"})
+	require.Error(t, err)
+	_, err = NewNATSAuditSink(NATSAuditSinkConfig{URL: "http://nats:4222", Subject: "dex"})
+	require.Error(t, err)
+	_, err = NewNATSAuditSink(NATSAuditSinkConfig{URL: "nats://nats:4222", Subject: "dex.*"})
+	require.Error(t, err)
+	_, err = NewHTTPAuditSink(HTTPAuditSinkConfig{URL: "ftp://example.com"})
+	require.Error(t, err)
+}
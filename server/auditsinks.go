@@ -0,0 +1,416 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/syslog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// auditSinkTimeout bounds a request of the HTTP, Kafka and NATS sinks.
+const auditSinkTimeout = 10 * time.Second
+
+// FileAuditSinkConfig appends audit records to a file, one JSON object per
+// line.
+type FileAuditSinkConfig struct {
+	Path string
+}
+
+type fileAuditSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileAuditSink returns a sink appending records to a file, creating it if
+// it doesn't exist.
+func NewFileAuditSink(c FileAuditSinkConfig) (AuditSink, error) {
+	if c.Path == "" {
+		return nil, errors.New("no audit log file specified")
+	}
+	f, err := os.OpenFile(c.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open audit log file: %v", err)
+	}
+	return &fileAuditSink{file: f}, nil
+}
+
+func (s *fileAuditSink) Name() string { return "file" }
+
+func (s *fileAuditSink) WriteAuditRecord(_ context.Context, record AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.file.Write(append(line, '\n'))
+	return err
+}
+
+// SyslogAuditSinkConfig sends audit records to syslog.
+type SyslogAuditSinkConfig struct {
+	// Network and address of the syslog server, e.g. "udp" and
+	// "syslog.example.com:514". If empty, the local syslog daemon is used.
+	Network string
+	Address string
+
+	// Tag of the messages. Defaults to "dex".
+	Tag string
+}
+
+type syslogAuditSink struct {
+	writer *syslog.Writer
+}
+
+// NewSyslogAuditSink returns a sink sending records as JSON messages with the
+// facility auth and the severity info.
+func NewSyslogAuditSink(c SyslogAuditSinkConfig) (AuditSink, error) {
+	w, err := syslog.Dial(c.Network, c.Address, syslog.LOG_AUTH|syslog.LOG_INFO, defaultTo(c.Tag, "dex"))
+	if err != nil {
+		return nil, fmt.Errorf("connect to syslog: %v", err)
+	}
+	return &syslogAuditSink{writer: w}, nil
+}
+
+func (s *syslogAuditSink) Name() string { return "syslog" }
+
+func (s *syslogAuditSink) WriteAuditRecord(_ context.Context, record AuditRecord) error {
+	msg, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	// The writer reconnects if the connection was lost.
+	return s.writer.Info(string(msg))
+}
+
+// HTTPAuditSinkConfig posts audit records to an HTTP endpoint.
+type HTTPAuditSinkConfig struct {
+	// URL every record is posted to as a JSON object.
+	URL string
+
+	// Headers added to the requests, e.g. for authentication.
+	Headers map[string]string
+}
+
+type httpAuditSink struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewHTTPAuditSink returns a sink posting records to an HTTP endpoint. Records
+// are retried unless the endpoint responds with a 2xx status.
+func NewHTTPAuditSink(c HTTPAuditSinkConfig) (AuditSink, error) {
+	if err := validateAuditSinkURL(c.URL); err != nil {
+		return nil, err
+	}
+	return &httpAuditSink{
+		url:     c.URL,
+		headers: c.Headers,
+		client:  &http.Client{Timeout: auditSinkTimeout},
+	}, nil
+}
+
+func (s *httpAuditSink) Name() string { return "http" }
+
+func (s *httpAuditSink) WriteAuditRecord(ctx context.Context, record AuditRecord) error {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return postAuditRecord(ctx, s.client, s.url, "application/json", s.headers, body)
+}
+
+// KafkaAuditSinkConfig publishes audit records to a Kafka topic through a
+// Kafka REST Proxy.
+type KafkaAuditSinkConfig struct {
+	// URL of the REST Proxy, e.g. "http://kafka-rest:8082".
+	URL   string
+	Topic string
+
+	// Headers added to the requests, e.g. for authentication.
+	Headers map[string]string
+}
+
+type kafkaAuditSink struct {
+	url     string
+	topic   string
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewKafkaAuditSink returns a sink producing records with the v2 API of the
+// Kafka REST Proxy. Records are keyed by the user ID, so the records of a
+// user keep their order.
+func NewKafkaAuditSink(c KafkaAuditSinkConfig) (AuditSink, error) {
+	if err := validateAuditSinkURL(c.URL); err != nil {
+		return nil, err
+	}
+	if c.Topic == "" {
+		return nil, errors.New("no kafka topic specified")
+	}
+	return &kafkaAuditSink{
+		url:     c.URL,
+		topic:   c.Topic,
+		headers: c.Headers,
+		client:  &http.Client{Timeout: auditSinkTimeout},
+	}, nil
+}
+
+func (s *kafkaAuditSink) Name() string { return "kafka" }
+
+type kafkaRecord struct {
+	Key   *string     `json:"key"`
+	Value AuditRecord `json:"value"`
+}
+
+func (s *kafkaAuditSink) WriteAuditRecord(ctx context.Context, record AuditRecord) error {
+	r := kafkaRecord{Value: record}
+	if record.UserID != "" {
+		r.Key = &record.UserID
+	}
+	body, err := json.Marshal(struct {
+		Records []kafkaRecord `json:"records"`
+	}{[]kafkaRecord{r}})
+	if err != nil {
+		return err
+	}
+	u, err := url.JoinPath(s.url, "topics", s.topic)
+	if err != nil {
+		return err
+	}
+	return postAuditRecord(ctx, s.client, u, "application/vnd.kafka.json.v2+json", s.headers, body)
+}
+
+// NATSAuditSinkConfig publishes audit records to NATS.
+type NATSAuditSinkConfig struct {
+	// URL of the NATS server, e.g. "nats://nats:4222". The "tls" scheme
+	// requires TLS, and credentials can be set as user info of the URL.
+	URL string
+
+	// Subject records are published to, with the type of the event appended,
+	// e.g. "dex.events.token_issued", so subscribers can filter events with
+	// wildcards.
+	Subject string
+
+	// Token to authenticate with, if the server requires one.
+	Token string
+}
+
+type natsAuditSink struct {
+	addr     string
+	tls      *tls.Config
+	subject  string
+	token    string
+	username string
+	password string
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewNATSAuditSink returns a sink publishing records as JSON messages with the
+// NATS client protocol. A message is only considered written once the server
+// confirmed it.
+func NewNATSAuditSink(c NATSAuditSinkConfig) (AuditSink, error) {
+	if c.URL == "" {
+		return nil, errors.New("no nats URL specified")
+	}
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid nats URL %q: %v", c.URL, err)
+	}
+	if u.Scheme != "nats" && u.Scheme != "tls" {
+		return nil, fmt.Errorf("invalid nats URL %q: scheme must be nats or tls", c.URL)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid nats URL %q: no host", c.URL)
+	}
+	if c.Subject == "" || strings.ContainsAny(c.Subject, " \t\r\n*>") {
+		return nil, fmt.Errorf("invalid nats subject %q", c.Subject)
+	}
+	s := &natsAuditSink{
+		addr:    net.JoinHostPort(u.Hostname(), defaultTo(u.Port(), "4222")),
+		subject: strings.TrimSuffix(c.Subject, "."),
+		token:   c.Token,
+	}
+	if u.Scheme == "tls" {
+		s.tls = &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}
+	}
+	if u.User != nil {
+		s.username = u.User.Username()
+		s.password, _ = u.User.Password()
+	}
+	return s, nil
+}
+
+func (s *natsAuditSink) Name() string { return "nats" }
+
+func (s *natsAuditSink) WriteAuditRecord(ctx context.Context, record AuditRecord) error {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		if err := s.connect(ctx); err != nil {
+			return fmt.Errorf("connect to nats: %v", err)
+		}
+	}
+	// The PING is answered once the server processed the message, or with an
+	// error if it refused it.
+	msg := fmt.Sprintf("PUB %s.%s %d\r\n%s\r\nPING\r\n", s.subject, record.Type, len(payload), payload)
+	if err := s.roundTrip(msg); err != nil {
+		s.conn.Close()
+		s.conn = nil
+		return err
+	}
+	return nil
+}
+
+// connect opens a connection to the server, upgrading it to TLS if required.
+func (s *natsAuditSink) connect(ctx context.Context) error {
+	d := net.Dialer{Timeout: auditSinkTimeout}
+	conn, err := d.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(auditSinkTimeout))
+	reader := bufio.NewReader(conn)
+
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	var info struct {
+		TLSRequired bool `json:"tls_required"`
+	}
+	infoJSON, ok := strings.CutPrefix(strings.TrimSpace(line), "INFO ")
+	if !ok || json.Unmarshal([]byte(infoJSON), &info) != nil {
+		conn.Close()
+		return fmt.Errorf("unexpected greeting %q", strings.TrimSpace(line))
+	}
+
+	tlsConfig := s.tls
+	if tlsConfig == nil && info.TLSRequired {
+		host, _, _ := net.SplitHostPort(s.addr)
+		tlsConfig = &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+	}
+	if tlsConfig != nil {
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return err
+		}
+		conn = tlsConn
+		reader = bufio.NewReader(conn)
+	}
+
+	options, err := json.Marshal(struct {
+		Verbose     bool   `json:"verbose"`
+		Pedantic    bool   `json:"pedantic"`
+		TLSRequired bool   `json:"tls_required"`
+		Name        string `json:"name"`
+		Lang        string `json:"lang"`
+		Token       string `json:"auth_token,omitempty"`
+		User        string `json:"user,omitempty"`
+		Pass        string `json:"pass,omitempty"`
+	}{
+		TLSRequired: tlsConfig != nil,
+		Name:        "dex",
+		Lang:        "go",
+		Token:       s.token,
+		User:        s.username,
+		Pass:        s.password,
+	})
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	s.conn, s.reader = conn, reader
+	if err := s.roundTrip("CONNECT " + string(options) + "\r\nPING\r\n"); err != nil {
+		conn.Close()
+		s.conn = nil
+		return err
+	}
+	return nil
+}
+
+// roundTrip writes commands ending with a PING and waits for the PONG.
+func (s *natsAuditSink) roundTrip(commands string) error {
+	s.conn.SetDeadline(time.Now().Add(auditSinkTimeout))
+	if _, err := io.WriteString(s.conn, commands); err != nil {
+		return err
+	}
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := io.WriteString(s.conn, "PONG\r\n"); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("nats: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+		// Other messages, e.g. +OK or an updated INFO, are ignored.
+	}
+}
+
+func validateAuditSinkURL(u string) error {
+	if u == "" {
+		return errors.New("no audit log URL specified")
+	}
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return fmt.Errorf("invalid audit log URL %q: %v", u, err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("invalid audit log URL %q: scheme must be http or https", u)
+	}
+	return nil
+}
+
+func postAuditRecord(ctx context.Context, client *http.Client, u, contentType string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
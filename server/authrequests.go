@@ -0,0 +1,207 @@
+package server
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dexidp/dex/storage"
+)
+
+// statelessAuthRequestPrefix starts the IDs of stateless auth requests. The
+// IDs of stored ones are lowercase, so both can be told apart while stored
+// auth requests created before enabling stateless ones are still completed.
+const statelessAuthRequestPrefix = "S"
+
+// StatelessAuthRequestsConfig makes the server keep auth requests out of the
+// storage until the login completes. Auth requests are encrypted into their
+// IDs instead, which are passed between the pages of the login and as the
+// state of the connectors, and a new ID is issued when the user logs in.
+//
+// Abandoned logins then cost no writes at all, and completed ones a single
+// write, a marker preventing the auth request from being used again, which is
+// looked up on every page of the login. The IDs
+// grow to several hundred bytes though, and to a few kilobytes with the
+// claims of users in many groups, which some SAML identity providers and
+// proxies limiting the length of URLs reject.
+type StatelessAuthRequestsConfig struct {
+	// AES keys of 16, 24 or 32 bytes. The first encrypts auth requests, and all
+	// decrypt them, so that keys can be rotated. Servers of the same issuer
+	// must share them.
+	Keys [][]byte
+}
+
+// authRequestSealer encrypts auth requests into their IDs.
+type authRequestSealer struct {
+	aeads []cipher.AEAD
+	// Auth requests are bound to the issuer, as servers of several issuers
+	// may share the keys.
+	issuer string
+	now    func() time.Time
+}
+
+func newAuthRequestSealer(c *StatelessAuthRequestsConfig, issuer string, now func() time.Time) (*authRequestSealer, error) {
+	if len(c.Keys) == 0 {
+		return nil, errors.New("stateless auth requests: no keys")
+	}
+	a := &authRequestSealer{issuer: issuer, now: now}
+	for i, key := range c.Keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("stateless auth requests: key %d: %v", i, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("stateless auth requests: key %d: %v", i, err)
+		}
+		a.aeads = append(a.aeads, aead)
+	}
+	return a, nil
+}
+
+// seal returns the ID of an auth request, which is the encrypted request. The
+// ID of the request itself identifies it once it's completed.
+func (a *authRequestSealer) seal(req storage.AuthRequest) (string, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	aead := a.aeads[0]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(payload)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, payload, []byte(a.issuer))
+	return statelessAuthRequestPrefix + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// open decrypts an auth request from its ID, returning storage.ErrNotFound if
+// it's invalid or expired, like a stored auth request garbage collected.
+func (a *authRequestSealer) open(id string) (storage.AuthRequest, error) {
+	sealed, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(id, statelessAuthRequestPrefix))
+	if err != nil {
+		return storage.AuthRequest{}, storage.ErrNotFound
+	}
+	for _, aead := range a.aeads {
+		if len(sealed) < aead.NonceSize() {
+			break
+		}
+		payload, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(a.issuer))
+		if err != nil {
+			continue
+		}
+		var req storage.AuthRequest
+		if err := json.Unmarshal(payload, &req); err != nil {
+			return storage.AuthRequest{}, fmt.Errorf("decode auth request: %v", err)
+		}
+		if a.now().After(req.Expiry) {
+			return storage.AuthRequest{}, storage.ErrNotFound
+		}
+		return req, nil
+	}
+	return storage.AuthRequest{}, storage.ErrNotFound
+}
+
+// stateless returns if an auth request ID is the encrypted request.
+func (s *Server) stateless(id string) bool {
+	return s.authRequestSealer != nil && strings.HasPrefix(id, statelessAuthRequestPrefix)
+}
+
+// createAuthRequest creates an auth request, setting its ID to the encrypted
+// request if auth requests are stateless.
+func (s *Server) createAuthRequest(ctx context.Context, req *storage.AuthRequest) error {
+	if s.authRequestSealer == nil {
+		return s.storage.CreateAuthRequest(ctx, *req)
+	}
+	id, err := s.authRequestSealer.seal(*req)
+	if err != nil {
+		return err
+	}
+	req.ID = id
+	return nil
+}
+
+// openAuthRequest decrypts a stateless auth request, returning
+// storage.ErrNotFound if it's invalid, expired or was completed, so that it
+// behaves like a stored auth request.
+func (s *Server) openAuthRequest(id string) (storage.AuthRequest, error) {
+	req, err := s.authRequestSealer.open(id)
+	if err != nil {
+		return storage.AuthRequest{}, err
+	}
+	switch _, err := s.storage.GetAuthRequest(req.ID); {
+	case err == nil:
+		return storage.AuthRequest{}, storage.ErrNotFound
+	case !errors.Is(err, storage.ErrNotFound):
+		return storage.AuthRequest{}, err
+	}
+	return req, nil
+}
+
+// getAuthRequest returns an auth request by its ID.
+func (s *Server) getAuthRequest(id string) (storage.AuthRequest, error) {
+	if !s.stateless(id) {
+		return s.storage.GetAuthRequest(id)
+	}
+	req, err := s.openAuthRequest(id)
+	if err != nil {
+		return storage.AuthRequest{}, err
+	}
+	req.ID = id
+	return req, nil
+}
+
+// updateAuthRequest updates an auth request, returning the updated request.
+// The ID of stateless auth requests changes with every update.
+func (s *Server) updateAuthRequest(id string, updater func(storage.AuthRequest) (storage.AuthRequest, error)) (storage.AuthRequest, error) {
+	if !s.stateless(id) {
+		var updated storage.AuthRequest
+		err := s.storage.UpdateAuthRequest(id, func(old storage.AuthRequest) (storage.AuthRequest, error) {
+			var err error
+			updated, err = updater(old)
+			return updated, err
+		})
+		return updated, err
+	}
+	old, err := s.openAuthRequest(id)
+	if err != nil {
+		return storage.AuthRequest{}, err
+	}
+	updated, err := updater(old)
+	if err != nil {
+		return storage.AuthRequest{}, err
+	}
+	if updated.ID, err = s.authRequestSealer.seal(updated); err != nil {
+		return storage.AuthRequest{}, err
+	}
+	return updated, nil
+}
+
+// deleteAuthRequest deletes an auth request once the login ends. Stateless
+// auth requests can't be deleted, so a marker with the ID of the request is
+// stored until it expires instead, and storage.ErrNotFound is returned if it
+// already exists.
+func (s *Server) deleteAuthRequest(ctx context.Context, id string) error {
+	if !s.stateless(id) {
+		return s.storage.DeleteAuthRequest(id)
+	}
+	req, err := s.authRequestSealer.open(id)
+	if err != nil {
+		return err
+	}
+	marker := storage.AuthRequest{ID: req.ID, ClientID: req.ClientID, Expiry: req.Expiry}
+	if err := s.storage.CreateAuthRequest(ctx, marker); err != nil {
+		if errors.Is(err, storage.ErrAlreadyExists) {
+			return storage.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
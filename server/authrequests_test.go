@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dexidp/dex/storage"
+)
+
+func TestStatelessAuthRequests(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	key := []byte("0123456789abcdef0123456789abcdef")
+	for _, skipApproval := range []bool{true, false} {
+		httpServer, s := newTestServer(ctx, t, func(c *Config) {
+			c.SkipApprovalScreen = skipApproval
+			c.StatelessAuthRequests = &StatelessAuthRequestsConfig{Keys: [][]byte{key}}
+		})
+		defer httpServer.Close()
+
+		require.NoError(t, s.storage.CreateClient(ctx, storage.Client{
+			ID:           "app",
+			Name:         "Example App",
+			RedirectURIs: []string{"https://app.example.com/callback"},
+		}))
+		do := func(method, path string, form url.Values) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rr := httptest.NewRecorder()
+			s.ServeHTTP(rr, req)
+			return rr
+		}
+
+		q := url.Values{
+			"client_id":     {"app"},
+			"redirect_uri":  {"https://app.example.com/callback"},
+			"response_type": {"code"},
+			"scope":         {"openid"},
+			"state":         {"xyz"},
+		}
+		rr := do(http.MethodGet, "/auth/mock?"+q.Encode(), nil)
+		require.Equal(t, http.StatusFound, rr.Code)
+		callback, err := url.Parse(rr.Header().Get("Location"))
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(callback.Query().Get("state"), statelessAuthRequestPrefix))
+
+		rr = do(http.MethodGet, callback.RequestURI(), nil)
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+		if !skipApproval {
+			approval, err := url.Parse(rr.Header().Get("Location"))
+			require.NoError(t, err)
+			require.Equal(t, "/approval", approval.Path)
+			require.NotEqual(t, callback.Query().Get("state"), approval.Query().Get("req"), "the ID changes once the user logged in")
+
+			rr = do(http.MethodGet, approval.RequestURI(), nil)
+			require.Equal(t, http.StatusOK, rr.Code)
+			require.Regexp(t, regexp.MustCompile(`name="req" value="S`), rr.Body.String())
+
+			rr = do(http.MethodPost, approval.RequestURI(), url.Values{"approval": {"approve"}})
+			require.Equal(t, http.StatusSeeOther, rr.Code)
+		}
+		redirect, err := url.Parse(rr.Header().Get("Location"))
+		require.NoError(t, err)
+		require.Equal(t, "app.example.com", redirect.Host)
+		require.NotEmpty(t, redirect.Query().Get("code"))
+		require.Equal(t, "xyz", redirect.Query().Get("state"))
+
+		// Completed auth requests can't be used again.
+		rr = do(http.MethodGet, callback.RequestURI(), nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+
+		// Only the marker of the completed auth request was stored.
+		result, err := s.storage.GarbageCollect(time.Now().Add(48*time.Hour), storage.GCOptions{})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), result.AuthRequests)
+	}
+}
+
+func TestAuthRequestSealer(t *testing.T) {
+	now := time.Now()
+	newSealer := func(issuer string, keys ...string) *authRequestSealer {
+		c := &StatelessAuthRequestsConfig{}
+		for _, k := range keys {
+			c.Keys = append(c.Keys, []byte(k))
+		}
+		a, err := newAuthRequestSealer(c, issuer, func() time.Time { return now })
+		require.NoError(t, err)
+		return a
+	}
+	oldKey, newKey := "0123456789abcdef", "fedcba9876543210"
+
+	req := storage.AuthRequest{
+		ID:       storage.NewID(),
+		ClientID: "app",
+		Scopes:   []string{"openid"},
+		Expiry:   now.Add(time.Hour).UTC(),
+		HMACKey:  []byte("secret"),
+		Claims:   storage.Claims{UserID: "1", Groups: []string{"admins"}},
+	}
+	id, err := newSealer("https://dex.example.com", oldKey).seal(req)
+	require.NoError(t, err)
+	require.NotContains(t, id, "admins", "auth requests are encrypted")
+
+	// Auth requests encrypted with older keys can still be opened.
+	got, err := newSealer("https://dex.example.com", newKey, oldKey).open(id)
+	require.NoError(t, err)
+	require.Equal(t, req, got)
+
+	_, err = newSealer("https://dex.example.com", newKey).open(id)
+	require.Equal(t, storage.ErrNotFound, err)
+	_, err = newSealer("https://other.example.com", oldKey).open(id)
+	require.Equal(t, storage.ErrNotFound, err, "auth requests are bound to the issuer")
+	_, err = newSealer("https://dex.example.com", oldKey).open(id[:len(id)-4])
+	require.Equal(t, storage.ErrNotFound, err)
+	_, err = newSealer("https://dex.example.com", oldKey).open("S!")
+	require.Equal(t, storage.ErrNotFound, err)
+
+	now = now.Add(2 * time.Hour)
+	_, err = newSealer("https://dex.example.com", oldKey).open(id)
+	require.Equal(t, storage.ErrNotFound, err, "expired auth requests can't be opened")
+
+	for _, keys := range [][][]byte{nil, {[]byte("short")}} {
+		_, err := newAuthRequestSealer(&StatelessAuthRequestsConfig{Keys: keys}, "https://dex.example.com", time.Now)
+		require.Error(t, err)
+	}
+}
@@ -0,0 +1,231 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/dexidp/dex/storage"
+)
+
+// Actions authorization policies are evaluated for.
+const (
+	policyActionAuthorize = "authorize"
+	policyActionToken     = "token"
+)
+
+// AuthorizationPolicyConfig is an external policy, usually an Open Policy
+// Agent, evaluated when users are authorized for a client and before tokens
+// are signed.
+//
+// The policy is queried with the data API of OPA: the input is posted as
+// {"input": ...} and the result is either a boolean or an object with the
+// "allow", "reason" and "claims" fields.
+type AuthorizationPolicyConfig struct {
+	// URL of the policy decision, e.g. "http://localhost:8181/v1/data/dex/authz".
+	URL string
+
+	// Bearer token sent to the policy agent, if it requires authentication.
+	Token string
+
+	// How long to wait for a decision. Defaults to 5 seconds.
+	Timeout time.Duration
+
+	// If set, requests are allowed when the policy agent can't be reached or
+	// fails, instead of being denied.
+	FailOpen bool
+}
+
+type authzPolicy struct {
+	url      string
+	token    string
+	failOpen bool
+	client   *http.Client
+}
+
+func newAuthzPolicy(c *AuthorizationPolicyConfig) (*authzPolicy, error) {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return nil, fmt.Errorf("authorization policy: invalid url: %v", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("authorization policy: invalid url %q: must be an absolute http or https url", c.URL)
+	}
+	if c.Timeout < 0 {
+		return nil, errors.New("authorization policy: timeout must not be negative")
+	}
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+	return &authzPolicy{
+		url:      c.URL,
+		token:    c.Token,
+		failOpen: c.FailOpen,
+		client:   &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// policyInput is the input of authorization policies.
+type policyInput struct {
+	// Action is "authorize" when a user is authorized for a client at the end
+	// of a login, or "token" when a token is about to be signed.
+	Action    string `json:"action"`
+	TokenType string `json:"token_type,omitempty"`
+	Issuer    string `json:"issuer"`
+
+	Client      policyClient           `json:"client"`
+	ConnectorID string                 `json:"connector_id"`
+	User        map[string]interface{} `json:"user"`
+	Groups      []string               `json:"groups"`
+	Scopes      []string               `json:"scopes"`
+
+	// Resource lists the audiences of the tokens, the client itself and the
+	// peers of cross-client scopes.
+	Resource []string `json:"resource"`
+
+	// Claims of the token, for the "token" action.
+	Claims map[string]interface{} `json:"claims,omitempty"`
+}
+
+type policyClient struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name,omitempty"`
+	Public       bool     `json:"public"`
+	TrustedPeers []string `json:"trusted_peers,omitempty"`
+}
+
+// policyDecision is the result of an authorization policy. Claims are set on
+// the token, or removed if null, after all other claims.
+type policyDecision struct {
+	Allow  bool                   `json:"allow"`
+	Reason string                 `json:"reason"`
+	Claims map[string]interface{} `json:"claims"`
+}
+
+func (s *Server) newPolicyInput(action string, client *storage.Client, clientID string, claims storage.Claims, scopes []string, connID string) policyInput {
+	input := policyInput{
+		Action:      action,
+		Issuer:      s.issuerURL.String(),
+		Client:      policyClient{ID: clientID},
+		ConnectorID: connID,
+		User:        identityValues(claims),
+		Groups:      append([]string{}, claims.Groups...),
+		Scopes:      append([]string{}, scopes...),
+		Resource:    getAudience(clientID, scopes),
+	}
+	if client != nil {
+		input.Client.Name = client.Name
+		input.Client.Public = client.Public
+		input.Client.TrustedPeers = client.TrustedPeers
+	}
+	return input
+}
+
+// evaluatePolicy queries the authorization policy, returning a
+// tokenDeniedError if it denies the request.
+func (s *Server) evaluatePolicy(ctx context.Context, input policyInput) (*policyDecision, error) {
+	decision, err := s.authzPolicy.evaluate(ctx, input)
+	switch {
+	case err != nil && s.authzPolicy.failOpen:
+		s.logger.WarnContext(ctx, "authorization policy failed, allowing request", "action", input.Action,
+			"client_id", input.Client.ID, "err", err)
+		return &policyDecision{Allow: true}, nil
+	case err != nil:
+		s.logger.ErrorContext(ctx, "authorization policy failed", "action", input.Action, "client_id", input.Client.ID, "err", err)
+		return nil, fmt.Errorf("authorization policy: %v", err)
+	case !decision.Allow:
+		s.logger.InfoContext(ctx, "authorization policy denied request", "action", input.Action, "client_id", input.Client.ID,
+			"connector_id", input.ConnectorID, "user_id", input.User["user_id"], "reason", decision.Reason)
+		return nil, &tokenDeniedError{source: "authorization policy", reason: decision.Reason}
+	}
+	return decision, nil
+}
+
+// authorizeByPolicy evaluates the authorization policy for a user who logged
+// in to a client.
+func (s *Server) authorizeByPolicy(ctx context.Context, authReq storage.AuthRequest) error {
+	client := s.tokenClient(ctx, authReq.ClientID)
+	input := s.newPolicyInput(policyActionAuthorize, client, authReq.ClientID, authReq.Claims, authReq.Scopes, authReq.ConnectorID)
+	_, err := s.evaluatePolicy(ctx, input)
+	return err
+}
+
+// applyTokenPolicy evaluates the authorization policy for a token about to be
+// signed, and returns its claims with the constraints of the policy applied.
+func (s *Server) applyTokenPolicy(ctx context.Context, tokenType string, payload []byte, client *storage.Client, clientID string, claims storage.Claims, scopes []string, connID string) ([]byte, error) {
+	var tokenClaims map[string]interface{}
+	if err := json.Unmarshal(payload, &tokenClaims); err != nil {
+		return nil, err
+	}
+	input := s.newPolicyInput(policyActionToken, client, clientID, claims, scopes, connID)
+	input.TokenType = tokenType
+	input.Claims = tokenClaims
+
+	decision, err := s.evaluatePolicy(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if len(decision.Claims) == 0 {
+		return payload, nil
+	}
+	if err := setClaims(tokenClaims, decision.Claims); err != nil {
+		return nil, fmt.Errorf("authorization policy: %v", err)
+	}
+	return json.Marshal(tokenClaims)
+}
+
+func (p *authzPolicy) evaluate(ctx context.Context, input policyInput) (*policyDecision, error) {
+	body, err := json.Marshal(struct {
+		Input policyInput `json:"input"`
+	}{input})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		io.Copy(io.Discard, resp.Body)
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var result struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxTokenWebhookResponse)).Decode(&result); err != nil {
+		return nil, fmt.Errorf("invalid response: %v", err)
+	}
+	// An undefined decision, e.g. if no rule of the policy matched, denies
+	// the request.
+	if len(result.Result) == 0 || string(result.Result) == "null" {
+		return &policyDecision{Reason: "undefined policy decision"}, nil
+	}
+	var allow bool
+	if err := json.Unmarshal(result.Result, &allow); err == nil {
+		return &policyDecision{Allow: allow}, nil
+	}
+	var decision policyDecision
+	if err := json.Unmarshal(result.Result, &decision); err != nil {
+		return nil, fmt.Errorf("invalid policy result: %v", err)
+	}
+	return &decision, nil
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dexidp/dex/storage"
+)
+
+func TestAuthorizationPolicy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var inputs []policyInput
+	agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer opa-token", r.Header.Get("Authorization"))
+		var req struct {
+			Input policyInput `json:"input"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		inputs = append(inputs, req.Input)
+
+		switch req.Input.User["username"] {
+		case "jane":
+			w.Write([]byte(`{"result": {"allow": true, "claims": {"tier": "gold", "email": null}}}`))
+		case "jim":
+			w.Write([]byte(`{"result": true}`))
+		case "john":
+			w.Write([]byte(`{"result": {"allow": false, "reason": "Contractors can't use this app."}}`))
+		case "joe":
+			w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer agent.Close()
+
+	httpServer, s := newTestServer(ctx, t, func(c *Config) {
+		c.AuthorizationPolicy = &AuthorizationPolicyConfig{URL: agent.URL, Token: "opa-token"}
+	})
+	defer httpServer.Close()
+
+	require.NoError(t, s.storage.CreateClient(ctx, storage.Client{ID: "app", Name: "App", Public: true}))
+	require.NoError(t, s.storage.CreateClient(ctx, storage.Client{ID: "api", TrustedPeers: []string{"app"}}))
+	scopes := []string{"openid", "email", "audience:server:client_id:api"}
+
+	token, _, err := s.newIDToken(ctx, "app", storage.Claims{UserID: "1", Username: "jane", Email: "jane@example.com", Groups: []string{"staff"}}, scopes, nil, "", "", "", "mock")
+	require.NoError(t, err)
+	jws, err := jose.ParseSigned(token, []jose.SignatureAlgorithm{jose.RS256})
+	require.NoError(t, err)
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal(jws.UnsafePayloadWithoutVerification(), &got))
+	require.Equal(t, "gold", got["tier"])
+	require.NotContains(t, got, "email")
+
+	require.Len(t, inputs, 1)
+	input := inputs[0]
+	require.Equal(t, policyActionToken, input.Action)
+	require.Equal(t, "id_token", input.TokenType)
+	require.Equal(t, policyClient{ID: "app", Name: "App", Public: true}, input.Client)
+	require.Equal(t, "mock", input.ConnectorID)
+	require.Equal(t, []string{"staff"}, input.Groups)
+	require.Equal(t, scopes, input.Scopes)
+	require.Equal(t, []string{"api", "app"}, input.Resource)
+	require.Equal(t, "jane@example.com", input.Claims["email"])
+
+	_, _, err = s.newAccessToken(ctx, "app", storage.Claims{UserID: "2", Username: "jim"}, scopes, nil, "", "mock")
+	require.NoError(t, err)
+
+	var denied *tokenDeniedError
+	err = s.authorizeByPolicy(ctx, storage.AuthRequest{ClientID: "app", ConnectorID: "mock", Claims: storage.Claims{UserID: "3", Username: "john"}})
+	require.True(t, errors.As(err, &denied))
+	require.Equal(t, "Contractors can't use this app.", denied.reason)
+	require.Equal(t, policyActionAuthorize, inputs[len(inputs)-1].Action)
+	require.Nil(t, inputs[len(inputs)-1].Claims)
+
+	_, _, err = s.newIDToken(ctx, "app", storage.Claims{UserID: "4", Username: "joe"}, scopes, nil, "", "", "", "mock")
+	require.True(t, errors.As(err, &denied), "an undefined decision denies the request")
+
+	_, _, err = s.newIDToken(ctx, "app", storage.Claims{UserID: "5", Username: "eve"}, scopes, nil, "", "", "", "mock")
+	require.Error(t, err)
+	require.False(t, errors.As(err, &denied))
+
+	s.authzPolicy.failOpen = true
+	_, _, err = s.newIDToken(ctx, "app", storage.Claims{UserID: "5", Username: "eve"}, scopes, nil, "", "", "", "mock")
+	require.NoError(t, err, "requests are allowed if the policy fails open")
+}
+
+func TestNewAuthzPolicy(t *testing.T) {
+	_, err := newAuthzPolicy(&AuthorizationPolicyConfig{URL: "http://127.0.0.1:8181/v1/data/dex/authz"})
+	require.NoError(t, err)
+
+	for _, c := range []AuthorizationPolicyConfig{
+		{URL: "127.0.0.1:8181/v1/data/dex/authz"},
+		{URL: "http://127.0.0.1:8181/v1/data/dex/authz", Timeout: -1},
+	} {
+		_, err := newAuthzPolicy(&c)
+		require.Error(t, err, "%+v", c)
+	}
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/dexidp/dex/storage"
+)
+
+// cancelLink is the link on the login and error pages which sends users back
+// to the client, ending the login with an access_denied error.
+type cancelLink struct {
+	URL        string
+	ClientName string
+}
+
+// cancelTarget is the client redirect a canceled login ends with, and the
+// auth request to delete, if any was created yet.
+type cancelTarget struct {
+	authReqID   string
+	client      storage.Client
+	redirectURI string
+	state       string
+}
+
+// cancelTarget finds the login to cancel: either the parameters of an auth
+// request before a connector was chosen, or an auth request by its ID, which
+// is the "req" or "state" parameter of the later pages.
+func (s *Server) cancelTarget(r *http.Request) (cancelTarget, bool) {
+	// Errors are handled by the handlers which called ParseForm before.
+	r.ParseForm()
+	q := r.Form
+	if clientID, redirectURI := q.Get("client_id"), q.Get("redirect_uri"); clientID != "" && redirectURI != "" {
+		client, err := s.storage.GetClient(clientID)
+		if err != nil || !validateRedirectURI(client, redirectURI) {
+			return cancelTarget{}, false
+		}
+		return cancelTarget{client: client, redirectURI: redirectURI, state: q.Get("state")}, true
+	}
+
+	id := q.Get("req")
+	if id == "" {
+		id = q.Get("state")
+	}
+	if id == "" {
+		return cancelTarget{}, false
+	}
+	authReq, err := s.getAuthRequest(id)
+	if err != nil {
+		return cancelTarget{}, false
+	}
+	client, err := s.storage.GetClient(authReq.ClientID)
+	if err != nil {
+		return cancelTarget{}, false
+	}
+	return cancelTarget{authReqID: authReq.ID, client: client, redirectURI: authReq.RedirectURI, state: authReq.State}, true
+}
+
+// cancelLink returns the link canceling the login a page belongs to, or nil
+// if it isn't part of a login.
+func (s *Server) cancelLink(r *http.Request) *cancelLink {
+	t, ok := s.cancelTarget(r)
+	if !ok {
+		return nil
+	}
+	q := url.Values{}
+	if t.authReqID != "" {
+		q.Set("req", t.authReqID)
+	} else {
+		q.Set("client_id", t.client.ID)
+		q.Set("redirect_uri", t.redirectURI)
+		q.Set("state", t.state)
+	}
+	u := url.URL{Path: s.absPath("/cancel"), RawQuery: q.Encode()}
+	return &cancelLink{URL: u.String(), ClientName: defaultTo(t.client.Name, t.client.ID)}
+}
+
+// handleCancel ends a login with an access_denied error and sends the user
+// back to the client.
+func (s *Server) handleCancel(w http.ResponseWriter, r *http.Request) {
+	t, ok := s.cancelTarget(r)
+	if !ok {
+		s.renderError(r, w, http.StatusBadRequest, "Invalid request")
+		return
+	}
+	if t.authReqID != "" {
+		if err := s.deleteAuthRequest(r.Context(), t.authReqID); err != nil && err != storage.ErrNotFound {
+			s.logger.ErrorContext(r.Context(), "failed to delete canceled auth request", "err", err)
+		}
+	}
+	s.logger.InfoContext(r.Context(), "login canceled by user", "client_id", t.client.ID)
+	err := &redirectedAuthErr{t.state, t.redirectURI, errAccessDenied, "The user canceled the login.", s.issuerURL.String()}
+	err.Handler().ServeHTTP(w, r)
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dexidp/dex/storage"
+)
+
+func TestCancelLogin(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, s := newTestServer(ctx, t, nil)
+	defer httpServer.Close()
+
+	require.NoError(t, s.storage.CreateClient(ctx, storage.Client{
+		ID:           "app",
+		Name:         "Example App",
+		RedirectURIs: []string{"https://app.example.com/callback"},
+	}))
+	require.NoError(t, s.storage.CreateConnector(ctx, storage.Connector{ID: "other", Type: "mockCallback", Name: "Other"}))
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		s.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		return rr
+	}
+	requireCanceled := func(rr *httptest.ResponseRecorder, state string) {
+		t.Helper()
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+		u, err := url.Parse(rr.Header().Get("Location"))
+		require.NoError(t, err)
+		require.Equal(t, "app.example.com", u.Host)
+		require.Equal(t, errAccessDenied, u.Query().Get("error"))
+		require.Equal(t, state, u.Query().Get("state"))
+	}
+
+	q := url.Values{
+		"client_id":     {"app"},
+		"redirect_uri":  {"https://app.example.com/callback"},
+		"response_type": {"code"},
+		"state":         {"xyz"},
+	}
+	rr := get("/auth?" + q.Encode())
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), "Cancel and return to Example App")
+
+	m := regexp.MustCompile(`href="([^"]*/cancel\?[^"]*)"`).FindStringSubmatch(rr.Body.String())
+	require.Len(t, m, 2)
+	requireCanceled(get(html.UnescapeString(m[1])), "xyz")
+
+	// Once an auth request was created, canceling deletes it.
+	require.NoError(t, s.storage.CreateAuthRequest(ctx, storage.AuthRequest{
+		ID:            "test",
+		ClientID:      "app",
+		ConnectorID:   "mock",
+		RedirectURI:   "https://app.example.com/callback",
+		State:         "abc",
+		Expiry:        time.Now().Add(time.Minute),
+		ResponseTypes: []string{responseTypeCode},
+	}))
+	rr = get("/callback?state=unknown")
+	require.NotContains(t, rr.Body.String(), "Cancel and return to")
+	requireCanceled(get("/cancel?req=test"), "abc")
+	_, err := s.storage.GetAuthRequest("test")
+	require.Equal(t, storage.ErrNotFound, err)
+
+	// Users can't be sent to URLs the client didn't register.
+	q.Set("redirect_uri", "https://evil.example.com/callback")
+	rr = get("/cancel?" + q.Encode())
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	rr = get("/auth?" + q.Encode())
+	require.NotContains(t, rr.Body.String(), "Cancel and return to")
+}
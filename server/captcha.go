@@ -0,0 +1,286 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/bits"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dexidp/dex/storage"
+)
+
+// Providers of CAPTCHAs.
+const (
+	captchaHCaptcha    = "hcaptcha"
+	captchaTurnstile   = "turnstile"
+	captchaProofOfWork = "proof-of-work"
+)
+
+// proofOfWorkTokenType tells proof-of-work challenges apart from the other
+// payloads signed by the server.
+const proofOfWorkTokenType = "proof_of_work"
+
+// proofOfWorkValidFor is how long users have to solve a proof-of-work
+// challenge and submit the form.
+const proofOfWorkValidFor = 10 * time.Minute
+
+// CaptchaConfig challenges users with a CAPTCHA on the password and
+// registration forms once there were several failed attempts, to blunt
+// credential stuffing.
+type CaptchaConfig struct {
+	// Provider of the challenges: "hcaptcha", "turnstile" or "proof-of-work".
+	// Proof-of-work challenges are solved by the browser, without involving a
+	// third party.
+	Provider string
+
+	// Keys of the hCaptcha or Turnstile site.
+	SiteKey string
+	Secret  string
+
+	// Number of failed logins per IP address or username, and of registrations
+	// per IP address, within an hour before a challenge has to be solved.
+	// Defaults to 3.
+	Threshold int
+
+	// Number of leading zero bits of the hashes solving proof-of-work
+	// challenges. Every bit doubles the work. Defaults to 16.
+	Difficulty int
+}
+
+type captcha struct {
+	provider   string
+	siteKey    string
+	secret     string
+	verifyURL  string
+	difficulty int
+	client     *http.Client
+
+	attempts *hourlyLimiter
+
+	// Nonces of solved proof-of-work challenges, until they expire, so that a
+	// solution is only accepted once.
+	mu     sync.Mutex
+	solved map[string]time.Time
+}
+
+func newCaptcha(c *CaptchaConfig, now func() time.Time) (*captcha, error) {
+	if c.Threshold < 0 || c.Difficulty < 0 || c.Difficulty > 32 {
+		return nil, errors.New("captcha threshold must not be negative and difficulty must be between 0 and 32")
+	}
+	threshold := c.Threshold
+	if threshold == 0 {
+		threshold = 3
+	}
+	difficulty := c.Difficulty
+	if difficulty == 0 {
+		difficulty = 16
+	}
+	ca := &captcha{
+		provider:   c.Provider,
+		siteKey:    c.SiteKey,
+		secret:     c.Secret,
+		difficulty: difficulty,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		attempts:   newHourlyLimiter(threshold, now),
+		solved:     make(map[string]time.Time),
+	}
+	switch c.Provider {
+	case captchaHCaptcha:
+		ca.verifyURL = "https://api.hcaptcha.com/siteverify"
+	case captchaTurnstile:
+		ca.verifyURL = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+	case captchaProofOfWork:
+		return ca, nil
+	default:
+		return nil, fmt.Errorf("unknown captcha provider %q", c.Provider)
+	}
+	if c.SiteKey == "" || c.Secret == "" {
+		return nil, fmt.Errorf("%s captchas require a site key and a secret", c.Provider)
+	}
+	return ca, nil
+}
+
+// captchaKeys returns the keys attempts are counted by: the IP address of the
+// user agent and, if set, the username.
+func captchaKeys(r *http.Request, username string) []string {
+	keys := []string{"ip:" + clientIP(r)}
+	if username != "" {
+		keys = append(keys, "user:"+strings.ToLower(username))
+	}
+	return keys
+}
+
+// recordAttempt counts a failed login or a registration.
+func (c *captcha) recordAttempt(keys []string) {
+	for _, key := range keys {
+		c.attempts.allow(key)
+	}
+}
+
+// required reports if a challenge has to be solved for any of the keys.
+func (c *captcha) required(keys []string) bool {
+	for _, key := range keys {
+		if c.attempts.exhausted(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// captchaChallenge is the challenge rendered on a form.
+type captchaChallenge struct {
+	Provider string
+	SiteKey  string
+
+	// Signed proof-of-work challenge and the number of leading zero bits of
+	// the hash solving it.
+	Challenge  string
+	Difficulty int
+
+	// Set if the form was submitted without solving the previous challenge.
+	Failed bool
+}
+
+type proofOfWork struct {
+	Type       string `json:"typ"`
+	Nonce      string `json:"nonce"`
+	Difficulty int    `json:"bits"`
+	Expiry     int64  `json:"exp"`
+}
+
+// captchaChallenge returns the challenge to render on a form, or nil if none
+// has to be solved for the keys.
+func (s *Server) captchaChallenge(ctx context.Context, keys []string, failed bool) (*captchaChallenge, error) {
+	if s.captcha == nil || (!failed && !s.captcha.required(keys)) {
+		return nil, nil
+	}
+	c := &captchaChallenge{
+		Provider: s.captcha.provider,
+		SiteKey:  s.captcha.siteKey,
+		Failed:   failed,
+	}
+	if s.captcha.provider != captchaProofOfWork {
+		return c, nil
+	}
+	payload, err := json.Marshal(proofOfWork{
+		Type:       proofOfWorkTokenType,
+		Nonce:      storage.NewID(),
+		Difficulty: s.captcha.difficulty,
+		Expiry:     s.now().Add(proofOfWorkValidFor).Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if c.Challenge, err = s.signer.Sign(ctx, payload); err != nil {
+		return nil, fmt.Errorf("sign proof-of-work challenge: %v", err)
+	}
+	c.Difficulty = s.captcha.difficulty
+	return c, nil
+}
+
+// verifyCaptcha checks the solution of the challenge submitted with a form.
+func (s *Server) verifyCaptcha(r *http.Request) error {
+	switch s.captcha.provider {
+	case captchaHCaptcha:
+		return s.captcha.verifyResponse(r.Context(), r.PostFormValue("h-captcha-response"), clientIP(r))
+	case captchaTurnstile:
+		return s.captcha.verifyResponse(r.Context(), r.PostFormValue("cf-turnstile-response"), clientIP(r))
+	default:
+		return s.verifyProofOfWork(r.Context(), r.PostFormValue("pow-challenge"), r.PostFormValue("pow-solution"))
+	}
+}
+
+// verifyResponse checks the response of an hCaptcha or Turnstile widget with
+// the provider.
+func (c *captcha) verifyResponse(ctx context.Context, response, remoteIP string) error {
+	if response == "" {
+		return errors.New("no captcha response")
+	}
+	form := url.Values{"secret": {c.secret}, "response": {response}, "remoteip": {remoteIP}}
+	if c.provider == captchaHCaptcha {
+		form.Set("sitekey", c.siteKey)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.verifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("verify captcha: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("verify captcha: unexpected status %s", resp.Status)
+	}
+	var result struct {
+		Success    bool     `json:"success"`
+		ErrorCodes []string `json:"error-codes"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("verify captcha: decode response: %v", err)
+	}
+	if !result.Success {
+		return fmt.Errorf("captcha response rejected: %s", strings.Join(result.ErrorCodes, ", "))
+	}
+	return nil
+}
+
+// verifyProofOfWork checks that the SHA-256 hash of the challenge, a colon and
+// the solution has enough leading zero bits, and that the challenge wasn't
+// solved before.
+func (s *Server) verifyProofOfWork(ctx context.Context, challenge, solution string) error {
+	if challenge == "" || solution == "" {
+		return errors.New("no proof-of-work solution")
+	}
+	payload, err := (&signerKeySet{s.signer}).VerifySignature(ctx, challenge)
+	if err != nil {
+		return fmt.Errorf("verify signature: %v", err)
+	}
+	var pow proofOfWork
+	if err := json.Unmarshal(payload, &pow); err != nil {
+		return fmt.Errorf("decode challenge: %v", err)
+	}
+	if pow.Type != proofOfWorkTokenType {
+		return fmt.Errorf("not a %s challenge", proofOfWorkTokenType)
+	}
+	now := s.now()
+	if now.Unix() > pow.Expiry {
+		return errors.New("challenge expired")
+	}
+	if leadingZeroBits(sha256.Sum256([]byte(challenge+":"+solution))) < pow.Difficulty {
+		return errors.New("wrong proof-of-work solution")
+	}
+
+	c := s.captcha
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for nonce, expiry := range c.solved {
+		if now.After(expiry) {
+			delete(c.solved, nonce)
+		}
+	}
+	if _, ok := c.solved[pow.Nonce]; ok {
+		return errors.New("challenge solved before")
+	}
+	c.solved[pow.Nonce] = time.Unix(pow.Expiry, 0)
+	return nil
+}
+
+func leadingZeroBits(sum [sha256.Size]byte) int {
+	n := 0
+	for _, b := range sum {
+		if b != 0 {
+			return n + bits.LeadingZeros8(b)
+		}
+		n += 8
+	}
+	return n
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/dexidp/dex/storage"
+)
+
+// newCaptchaTestServer returns a server with a local password "jane" and an
+// auth request "test" for the local connector.
+func newCaptchaTestServer(ctx context.Context, t *testing.T, c *CaptchaConfig) (*httptest.Server, *Server) {
+	httpServer, s := newTestServer(ctx, t, func(config *Config) {
+		config.Captcha = c
+	})
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
+	require.NoError(t, err)
+	require.NoError(t, s.storage.CreatePassword(ctx, storage.Password{
+		Email:         "jane@example.com",
+		Hash:          hash,
+		Username:      "jane",
+		UserID:        "1",
+		EmailVerified: true,
+	}))
+	sc := storage.Connector{ID: LocalConnector, Type: LocalConnector, Name: "Email", ResourceVersion: "1"}
+	require.NoError(t, s.storage.CreateConnector(ctx, sc))
+	_, err = s.OpenConnector(sc)
+	require.NoError(t, err)
+	require.NoError(t, s.storage.CreateAuthRequest(ctx, storage.AuthRequest{
+		ID:            "test",
+		ConnectorID:   LocalConnector,
+		RedirectURI:   "cb",
+		Expiry:        time.Now().Add(time.Minute),
+		ResponseTypes: []string{responseTypeCode},
+	}))
+	return httpServer, s
+}
+
+func postLogin(s *Server, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/auth/local/login?state=test", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr := httptest.NewRecorder()
+	s.ServeHTTP(rr, req)
+	return rr
+}
+
+func solveProofOfWork(challenge string, difficulty int) string {
+	for i := 0; ; i++ {
+		solution := strconv.Itoa(i)
+		if leadingZeroBits(sha256.Sum256([]byte(challenge+":"+solution))) >= difficulty {
+			return solution
+		}
+	}
+}
+
+func TestCaptchaProofOfWork(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, s := newCaptchaTestServer(ctx, t, &CaptchaConfig{Provider: captchaProofOfWork, Threshold: 2, Difficulty: 8})
+	defer httpServer.Close()
+
+	wrong := url.Values{"login": {"jane@example.com"}, "password": {"wrong"}}
+	rr := postLogin(s, wrong)
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+	require.NotContains(t, rr.Body.String(), "pow-challenge")
+
+	// The form shows a challenge once the failures reach the threshold.
+	rr = postLogin(s, wrong)
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+	require.Contains(t, rr.Body.String(), "pow-challenge")
+
+	// Even the right password is rejected without solving it.
+	rr = postLogin(s, url.Values{"login": {"jane@example.com"}, "password": {"password"}})
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "Please complete the challenge.")
+
+	m := regexp.MustCompile(`name="pow-challenge" value="([^"]+)"`).FindStringSubmatch(rr.Body.String())
+	require.Len(t, m, 2)
+	challenge := m[1]
+
+	rr = postLogin(s, url.Values{"login": {"jane@example.com"}, "password": {"password"}, "pow-challenge": {challenge}, "pow-solution": {"wrong"}})
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	solution := solveProofOfWork(challenge, 8)
+	rr = postLogin(s, url.Values{"login": {"jane@example.com"}, "password": {"password"}, "pow-challenge": {challenge}, "pow-solution": {solution}})
+	require.Equal(t, http.StatusSeeOther, rr.Code)
+
+	// Solutions are only accepted once.
+	require.Error(t, s.verifyProofOfWork(ctx, challenge, solution))
+
+	// Other payloads signed by the server aren't challenges.
+	token, err := s.signEmailToken(ctx, emailToken{
+		Type:   passwordResetTokenType,
+		Email:  "jane@example.com",
+		Expiry: time.Now().Add(time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+	require.Error(t, s.verifyProofOfWork(ctx, token, solveProofOfWork(token, 8)))
+}
+
+func TestCaptchaHCaptcha(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	verifier := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.PostFormValue("secret") == "secret" && r.PostFormValue("sitekey") == "site" && r.PostFormValue("response") == "solved" {
+			w.Write([]byte(`{"success": true}`))
+			return
+		}
+		w.Write([]byte(`{"success": false, "error-codes": ["invalid-input-response"]}`))
+	}))
+	defer verifier.Close()
+
+	httpServer, s := newCaptchaTestServer(ctx, t, &CaptchaConfig{Provider: captchaHCaptcha, SiteKey: "site", Secret: "secret", Threshold: 1})
+	defer httpServer.Close()
+	s.captcha.verifyURL = verifier.URL
+
+	rr := postLogin(s, url.Values{"login": {"jane@example.com"}, "password": {"wrong"}})
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+	require.Contains(t, rr.Body.String(), `class="h-captcha" data-sitekey="site"`)
+
+	rr = postLogin(s, url.Values{"login": {"jane@example.com"}, "password": {"password"}, "h-captcha-response": {"forged"}})
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = postLogin(s, url.Values{"login": {"jane@example.com"}, "password": {"password"}, "h-captcha-response": {"solved"}})
+	require.Equal(t, http.StatusSeeOther, rr.Code)
+}
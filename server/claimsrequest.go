@@ -0,0 +1,115 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/dexidp/dex/storage"
+)
+
+// claimsRequest is the OIDC claims request parameter, which lets clients ask
+// for individual claims in the ID token or from the userinfo endpoint.
+//
+// https://openid.net/specs/openid-connect-core-1_0.html#ClaimsParameter
+type claimsRequest struct {
+	UserInfo map[string]*claimRequest `json:"userinfo,omitempty"`
+	IDToken  map[string]*claimRequest `json:"id_token,omitempty"`
+}
+
+// claimRequest holds the options of a single requested claim. A nil
+// claimRequest requests the claim with the default behavior.
+type claimRequest struct {
+	Essential bool          `json:"essential,omitempty"`
+	Value     interface{}   `json:"value,omitempty"`
+	Values    []interface{} `json:"values,omitempty"`
+}
+
+// parseClaimsRequest validates the claims request parameter and returns it
+// in the form kept in storage.
+func parseClaimsRequest(param string) ([]byte, error) {
+	if param == "" {
+		return nil, nil
+	}
+	var req claimsRequest
+	if err := json.Unmarshal([]byte(param), &req); err != nil {
+		return nil, fmt.Errorf("invalid claims parameter: %v", err)
+	}
+	if len(req.UserInfo) == 0 && len(req.IDToken) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(req)
+}
+
+// decodeClaimsRequest decodes a claims request kept in storage. Claims
+// requests are validated before they are stored, so decoding errors are
+// treated as if no claims were requested.
+func decodeClaimsRequest(data []byte) claimsRequest {
+	var req claimsRequest
+	if len(data) > 0 {
+		json.Unmarshal(data, &req)
+	}
+	return req
+}
+
+// matches reports whether a claim value satisfies the requested value, if
+// the client asked for a particular one.
+func (r *claimRequest) matches(v interface{}) bool {
+	if r == nil || (r.Value == nil && len(r.Values) == 0) {
+		return true
+	}
+	got, err := json.Marshal(v)
+	if err != nil {
+		return false
+	}
+	for _, want := range append([]interface{}{r.Value}, r.Values...) {
+		if want == nil {
+			continue
+		}
+		if data, err := json.Marshal(want); err == nil && bytes.Equal(got, data) {
+			return true
+		}
+	}
+	return false
+}
+
+// requestedClaims returns the values of the individually requested claims
+// the user has. Claims set by dex itself can't be requested.
+func requestedClaims(claims storage.Claims, requested map[string]*claimRequest) map[string]interface{} {
+	values := make(map[string]interface{})
+	for claim, req := range requested {
+		if reservedClaims[claim] {
+			continue
+		}
+		if v, ok := claimValue(claims, claim); ok && req.matches(v) {
+			values[claim] = v
+		}
+	}
+	return values
+}
+
+// claimDescriptions describes the individually requested claims for the
+// approval page.
+func (r claimsRequest) claimDescriptions() []string {
+	essential := make(map[string]bool)
+	for _, requested := range []map[string]*claimRequest{r.IDToken, r.UserInfo} {
+		for claim, req := range requested {
+			if reservedClaims[claim] {
+				continue
+			}
+			essential[claim] = essential[claim] || (req != nil && req.Essential)
+		}
+	}
+
+	var descriptions []string
+	for claim, isEssential := range essential {
+		if isEssential {
+			descriptions = append(descriptions, fmt.Sprintf("View your %s (required)", claim))
+		} else {
+			descriptions = append(descriptions, fmt.Sprintf("View your %s", claim))
+		}
+	}
+	sort.Strings(descriptions)
+	return descriptions
+}
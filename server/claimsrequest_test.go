@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dexidp/dex/storage"
+)
+
+func TestParseClaimsRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		param   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:  "no claims",
+			param: `{}`,
+		},
+		{
+			name:  "id token and userinfo claims",
+			param: `{"id_token": {"email": {"essential": true}, "name": null}, "userinfo": {"groups": null}}`,
+			want:  `{"userinfo":{"groups":null},"id_token":{"email":{"essential":true},"name":null}}`,
+		},
+		{
+			name:    "not an object",
+			param:   `["email"]`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid claim request",
+			param:   `{"id_token": {"email": true}}`,
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseClaimsRequest(tc.param)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, string(got))
+		})
+	}
+}
+
+func TestRequestedClaims(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, s := newTestServer(ctx, t, nil)
+	defer httpServer.Close()
+
+	claims := storage.Claims{
+		UserID:        "1",
+		Username:      "jane",
+		Email:         "jane.doe@example.com",
+		EmailVerified: true,
+		Groups:        []string{"a", "b"},
+		CustomClaims: map[string]interface{}{
+			"department": "engineering",
+		},
+	}
+
+	req := decodeClaimsRequest([]byte(`{
+		"id_token": {
+			"email": {"essential": true},
+			"department": {"value": "engineering"},
+			"groups": {"values": [["c"]]},
+			"sub": {"value": "someone-else"},
+			"phone_number": null
+		},
+		"userinfo": {"name": null}
+	}`))
+
+	idToken, _, err := s.newIDToken(ctx, "test", claims, []string{"openid"}, req.IDToken, "", "", "", "mock")
+	require.NoError(t, err)
+	got := unsafeTokenClaims(t, idToken)
+	require.Equal(t, "jane.doe@example.com", got["email"])
+	require.Equal(t, "engineering", got["department"])
+	require.NotEqual(t, "someone-else", got["sub"])
+	require.NotContains(t, got, "groups")
+	require.NotContains(t, got, "phone_number")
+	require.NotContains(t, got, "name")
+
+	accessToken, _, err := s.newAccessToken(ctx, "test", claims, []string{"openid"}, req.UserInfo, "", "mock")
+	require.NoError(t, err)
+	got = unsafeTokenClaims(t, accessToken)
+	require.Equal(t, "jane", got["name"])
+	require.NotContains(t, got, "email")
+
+	require.Equal(t, []string{
+		"View your department",
+		"View your email (required)",
+		"View your groups",
+		"View your name",
+		"View your phone_number",
+	}, req.claimDescriptions())
+}
+
+func unsafeTokenClaims(t *testing.T, token string) map[string]interface{} {
+	jws, err := jose.ParseSigned(token, []jose.SignatureAlgorithm{jose.RS256})
+	require.NoError(t, err)
+	var claims map[string]interface{}
+	require.NoError(t, json.Unmarshal(jws.UnsafePayloadWithoutVerification(), &claims))
+	return claims
+}
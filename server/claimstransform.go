@@ -0,0 +1,142 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/ext"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/dexidp/dex/storage"
+)
+
+// claimsTransformCostLimit bounds the work a single claims transform may do,
+// so a misconfigured expression can't stall token issuance.
+const claimsTransformCostLimit = 1000000
+
+// claimsTransformEnv declares the variables available to claims transforms:
+//
+//	claims        the claims of the token, including earlier transforms
+//	identity      the user's identity as returned by the connector
+//	connector_id  the connector the user logged in with
+//	client_id     the client the token is issued to
+//	scopes        the scopes granted to the client
+//
+// The CEL string extensions are available for computing values.
+var claimsTransformEnv = func() *cel.Env {
+	env, err := cel.NewEnv(
+		cel.Variable("claims", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("identity", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("connector_id", cel.StringType),
+		cel.Variable("client_id", cel.StringType),
+		cel.Variable("scopes", cel.ListType(cel.StringType)),
+		ext.Strings(),
+	)
+	if err != nil {
+		panic(fmt.Sprintf("server: failed to create CEL environment: %v", err))
+	}
+	return env
+}()
+
+type claimsTransform struct {
+	claim   string
+	program cel.Program
+}
+
+func newClaimsTransforms(transforms []storage.ClaimsTransform) ([]claimsTransform, error) {
+	compiled := make([]claimsTransform, 0, len(transforms))
+	for _, t := range transforms {
+		if t.Claim == "" {
+			return nil, fmt.Errorf("claims transform has no claim")
+		}
+		if reservedClaims[t.Claim] {
+			return nil, fmt.Errorf("claims transform: claim %q is reserved", t.Claim)
+		}
+		ast, issues := claimsTransformEnv.Compile(t.Expression)
+		if issues != nil && issues.Err() != nil {
+			return nil, fmt.Errorf("claims transform for %q: %v", t.Claim, issues.Err())
+		}
+		program, err := claimsTransformEnv.Program(ast, cel.CostLimit(claimsTransformCostLimit))
+		if err != nil {
+			return nil, fmt.Errorf("claims transform for %q: %v", t.Claim, err)
+		}
+		compiled = append(compiled, claimsTransform{claim: t.Claim, program: program})
+	}
+	return compiled, nil
+}
+
+// ValidateClaimsTransforms checks the claims transforms of a client.
+func ValidateClaimsTransforms(transforms []storage.ClaimsTransform) error {
+	_, err := newClaimsTransforms(transforms)
+	return err
+}
+
+// transformClaims applies the server wide claims transforms, followed by the
+// ones of the client, to a serialized set of token claims.
+func (s *Server) transformClaims(payload []byte, client *storage.Client, clientID string, claims storage.Claims, scopes []string, connID string) ([]byte, error) {
+	transforms := s.claimsTransforms
+	if client != nil && len(client.ClaimsTransforms) > 0 {
+		clientTransforms, err := newClaimsTransforms(client.ClaimsTransforms)
+		if err != nil {
+			return nil, err
+		}
+		transforms = append(transforms[:len(transforms):len(transforms)], clientTransforms...)
+	}
+	if len(transforms) == 0 {
+		return payload, nil
+	}
+
+	var tokenClaims map[string]interface{}
+	if err := json.Unmarshal(payload, &tokenClaims); err != nil {
+		return nil, err
+	}
+
+	identity := identityValues(claims)
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	for _, t := range transforms {
+		out, _, err := t.program.Eval(map[string]interface{}{
+			"claims":       tokenClaims,
+			"identity":     identity,
+			"connector_id": connID,
+			"client_id":    clientID,
+			"scopes":       scopes,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("claims transform for %q: %v", t.claim, err)
+		}
+		if out == types.NullValue {
+			delete(tokenClaims, t.claim)
+			continue
+		}
+		v, err := out.ConvertToNative(reflect.TypeOf(&structpb.Value{}))
+		if err != nil {
+			return nil, fmt.Errorf("claims transform for %q: %v", t.claim, err)
+		}
+		tokenClaims[t.claim] = v.(*structpb.Value).AsInterface()
+	}
+	return json.Marshal(tokenClaims)
+}
+
+// identityValues returns the identity of the user as exposed to claims
+// transforms and the token webhook.
+func identityValues(claims storage.Claims) map[string]interface{} {
+	identity := map[string]interface{}{
+		"user_id":            claims.UserID,
+		"username":           claims.Username,
+		"preferred_username": claims.PreferredUsername,
+		"email":              claims.Email,
+		"email_verified":     claims.EmailVerified,
+		"groups":             append([]string{}, claims.Groups...),
+		"custom_claims":      map[string]interface{}{},
+	}
+	if claims.CustomClaims != nil {
+		identity["custom_claims"] = claims.CustomClaims
+	}
+	return identity
+}
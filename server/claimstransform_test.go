@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dexidp/dex/storage"
+)
+
+func TestClaimsTransforms(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, s := newTestServer(ctx, t, func(c *Config) {
+		c.ClaimsTransforms = []storage.ClaimsTransform{
+			{Claim: "roles", Expression: `identity.groups.filter(g, g.startsWith("role-")).map(g, g.substring(5))`},
+			{Claim: "login", Expression: `connector_id + "/" + identity.username`},
+		}
+	})
+	defer httpServer.Close()
+
+	require.NoError(t, s.storage.CreateClient(ctx, storage.Client{
+		ID: "transformed",
+		ClaimsTransforms: []storage.ClaimsTransform{
+			{Claim: "email", Expression: "null"},
+			{Claim: "mail", Expression: `"email" in scopes ? identity.email : null`},
+			{Claim: "admin", Expression: `"admin" in claims.roles && client_id == "transformed"`},
+		},
+	}))
+
+	claims := storage.Claims{
+		UserID:        "1",
+		Username:      "jane",
+		Email:         "jane@example.com",
+		EmailVerified: true,
+		Groups:        []string{"role-admin", "role-dev", "staff"},
+	}
+
+	decode := func(t *testing.T, token string) map[string]interface{} {
+		jws, err := jose.ParseSigned(token, []jose.SignatureAlgorithm{jose.RS256})
+		require.NoError(t, err)
+		var got map[string]interface{}
+		require.NoError(t, json.Unmarshal(jws.UnsafePayloadWithoutVerification(), &got))
+		return got
+	}
+
+	token, _, err := s.newIDToken(ctx, "other", claims, []string{"openid", "email"}, nil, "", "", "", "mock")
+	require.NoError(t, err)
+	got := decode(t, token)
+	require.Equal(t, []interface{}{"admin", "dev"}, got["roles"])
+	require.Equal(t, "mock/jane", got["login"])
+	require.Equal(t, "jane@example.com", got["email"])
+	require.NotContains(t, got, "admin")
+
+	token, _, err = s.newAccessToken(ctx, "transformed", claims, []string{"openid", "email"}, nil, "", "mock")
+	require.NoError(t, err)
+	got = decode(t, token)
+	require.Equal(t, []interface{}{"admin", "dev"}, got["roles"])
+	require.NotContains(t, got, "email")
+	require.Equal(t, "jane@example.com", got["mail"])
+	require.Equal(t, true, got["admin"])
+}
+
+func TestValidateClaimsTransforms(t *testing.T) {
+	require.NoError(t, ValidateClaimsTransforms(nil))
+	require.NoError(t, ValidateClaimsTransforms([]storage.ClaimsTransform{
+		{Claim: "department", Expression: `identity.custom_claims.department`},
+	}))
+
+	tests := []struct {
+		transform storage.ClaimsTransform
+		wantErr   string
+	}{
+		{transform: storage.ClaimsTransform{Expression: `"x"`}, wantErr: "no claim"},
+		{transform: storage.ClaimsTransform{Claim: "sub", Expression: `"x"`}, wantErr: "reserved"},
+		{transform: storage.ClaimsTransform{Claim: "x", Expression: `identity.`}, wantErr: "Syntax error"},
+		{transform: storage.ClaimsTransform{Claim: "x", Expression: `unknown.value`}, wantErr: "undeclared reference"},
+	}
+	for _, tc := range tests {
+		err := ValidateClaimsTransforms([]storage.ClaimsTransform{tc.transform})
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("transform %+v: expected error containing %q, got %v", tc.transform, tc.wantErr, err)
+		}
+	}
+}
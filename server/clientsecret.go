@@ -0,0 +1,44 @@
+package server
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/dexidp/dex/storage"
+)
+
+// validClientSecret reports whether a secret authenticates a client. Besides
+// the current secret, the secret replaced by the last rotation is accepted
+// until its grace period ends.
+func (s *Server) validClientSecret(client storage.Client, secret string) bool {
+	if subtle.ConstantTimeCompare([]byte(client.Secret), []byte(secret)) == 1 {
+		return true
+	}
+	prev := client.PreviousSecret
+	if prev == nil || secret == "" || !s.now().Before(prev.Expiry) {
+		return false
+	}
+	return bcrypt.CompareHashAndPassword(prev.Hash, []byte(secret)) == nil
+}
+
+// rotateClientSecret replaces the secret of a client, keeping the current one
+// valid for the grace period. Without a grace period, the current secret is
+// invalidated immediately.
+func rotateClientSecret(c storage.Client, secret string, gracePeriod time.Duration, now time.Time) (storage.Client, error) {
+	if c.Public {
+		return c, fmt.Errorf("public clients don't have a secret")
+	}
+	c.PreviousSecret = nil
+	if gracePeriod > 0 && c.Secret != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(c.Secret), bcrypt.DefaultCost)
+		if err != nil {
+			return c, fmt.Errorf("hash secret: %v", err)
+		}
+		c.PreviousSecret = &storage.PreviousSecret{Hash: hash, Expiry: now.Add(gracePeriod)}
+	}
+	c.Secret = secret
+	return c, nil
+}
@@ -0,0 +1,35 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dexidp/dex/storage"
+)
+
+func TestValidClientSecret(t *testing.T) {
+	now := time.Now()
+	s := &Server{now: func() time.Time { return now }}
+
+	c, err := rotateClientSecret(storage.Client{ID: "app", Secret: "old"}, "new", time.Hour, now)
+	require.NoError(t, err)
+
+	require.True(t, s.validClientSecret(c, "new"))
+	require.True(t, s.validClientSecret(c, "old"))
+	require.False(t, s.validClientSecret(c, "other"))
+	require.False(t, s.validClientSecret(c, ""))
+
+	now = now.Add(time.Hour)
+	require.True(t, s.validClientSecret(c, "new"))
+	require.False(t, s.validClientSecret(c, "old"))
+
+	c, err = rotateClientSecret(c, "newer", 0, now)
+	require.NoError(t, err)
+	require.Nil(t, c.PreviousSecret)
+	require.False(t, s.validClientSecret(c, "new"))
+
+	_, err = rotateClientSecret(storage.Client{ID: "public", Public: true}, "secret", time.Hour, now)
+	require.Error(t, err)
+}
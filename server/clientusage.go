@@ -0,0 +1,34 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/dexidp/dex/storage"
+)
+
+// clientLastUsedInterval limits how often the last use of a client is
+// written to storage.
+const clientLastUsedInterval = time.Minute
+
+// recordClientUse updates the last use of a client which authenticated at the
+// token endpoint. Attempts are throttled in memory too, since static clients
+// can't be updated.
+func (s *Server) recordClientUse(ctx context.Context, client storage.Client) {
+	now := s.now()
+	if now.Sub(client.LastUsed) < clientLastUsedInterval {
+		return
+	}
+	if last, ok := s.clientsLastUsed.Load(client.ID); ok && now.Sub(last.(time.Time)) < clientLastUsedInterval {
+		return
+	}
+	s.clientsLastUsed.Store(client.ID, now)
+
+	err := s.storage.UpdateClient(client.ID, func(old storage.Client) (storage.Client, error) {
+		old.LastUsed = now
+		return old, nil
+	})
+	if err != nil {
+		s.logger.DebugContext(ctx, "failed to record client use", "client_id", client.ID, "err", err)
+	}
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dexidp/dex/storage"
+)
+
+func TestRecordClientUse(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	now := time.Now().UTC().Round(time.Second)
+	httpServer, s := newTestServer(ctx, t, func(c *Config) {
+		c.Now = func() time.Time { return now }
+	})
+	defer httpServer.Close()
+
+	require.NoError(t, s.storage.CreateClient(ctx, storage.Client{ID: "app"}))
+	lastUsed := func() time.Time {
+		c, err := s.storage.GetClient("app")
+		require.NoError(t, err)
+		return c.LastUsed
+	}
+
+	client, err := s.storage.GetClient("app")
+	require.NoError(t, err)
+	s.recordClientUse(ctx, client)
+	require.Equal(t, now, lastUsed())
+
+	// Uses within the interval aren't written.
+	first := now
+	now = now.Add(clientLastUsedInterval / 2)
+	s.recordClientUse(ctx, client)
+	require.Equal(t, first, lastUsed())
+
+	now = first.Add(clientLastUsedInterval)
+	s.recordClientUse(ctx, client)
+	require.Equal(t, now, lastUsed())
+}
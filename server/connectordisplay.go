@@ -0,0 +1,42 @@
+package server
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/dexidp/dex/storage"
+)
+
+var buttonColorRe = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3,4}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+
+// ValidateConnectorDisplay checks the display settings of a connector. Icons
+// have to be HTTP(S) or relative URLs, or data URLs of images, and button
+// colors CSS hex colors.
+func ValidateConnectorDisplay(d *storage.ConnectorDisplay) error {
+	if d == nil {
+		return nil
+	}
+	if d.IconURL != "" && !validIconURL(d.IconURL) {
+		return fmt.Errorf("invalid icon URL %q", d.IconURL)
+	}
+	if d.ButtonColor != "" && !buttonColorRe.MatchString(d.ButtonColor) {
+		return fmt.Errorf("invalid button color %q, expected a hex color like \"#2f2f2f\"", d.ButtonColor)
+	}
+	return nil
+}
+
+func validIconURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https":
+		return true
+	case "data":
+		return strings.HasPrefix(strings.ToLower(u.Opaque), "image/")
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dexidp/dex/storage"
+)
+
+func TestValidateConnectorDisplay(t *testing.T) {
+	tests := []struct {
+		name    string
+		display *storage.ConnectorDisplay
+		wantErr bool
+	}{
+		{name: "none"},
+		{name: "https icon", display: &storage.ConnectorDisplay{IconURL: "https://example.com/icon.svg"}},
+		{name: "relative icon", display: &storage.ConnectorDisplay{IconURL: "/static/icon.png"}},
+		{name: "data icon", display: &storage.ConnectorDisplay{IconURL: "data:image/svg+xml;base64,PHN2Zz48L3N2Zz4="}},
+		{name: "javascript icon", display: &storage.ConnectorDisplay{IconURL: "javascript:alert(1)"}, wantErr: true},
+		{name: "data icon of html", display: &storage.ConnectorDisplay{IconURL: "data:text/html,<script></script>"}, wantErr: true},
+		{name: "short color", display: &storage.ConnectorDisplay{ButtonColor: "#fff"}},
+		{name: "color", display: &storage.ConnectorDisplay{ButtonColor: "#2F2F2F"}},
+		{name: "named color", display: &storage.ConnectorDisplay{ButtonColor: "red"}, wantErr: true},
+		{name: "css injection", display: &storage.ConnectorDisplay{ButtonColor: "#fff; background-image: url(x)"}, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateConnectorDisplay(tc.display)
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
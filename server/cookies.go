@@ -0,0 +1,143 @@
+package server
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/dexidp/dex/storage"
+)
+
+// CookieConfig sets the attributes of all cookies the server sets.
+type CookieConfig struct {
+	// SameSite attribute. Defaults to http.SameSiteLaxMode.
+	SameSite http.SameSite
+
+	// If set, overrides the Secure attribute, which is set if the issuer is
+	// an https URL.
+	Secure *bool
+
+	// Domain attribute. Defaults to none, which limits cookies to the host of
+	// the issuer.
+	Domain string
+
+	// How long cookies last. Defaults to zero, making them session cookies
+	// deleted when the browser is closed.
+	MaxAge time.Duration
+}
+
+type cookies struct {
+	path     string
+	domain   string
+	sameSite http.SameSite
+	secure   bool
+	maxAge   time.Duration
+}
+
+func newCookies(c *CookieConfig, issuerURL url.URL) (*cookies, error) {
+	if c.MaxAge < 0 {
+		return nil, errors.New("cookies: max age must not be negative")
+	}
+	k := &cookies{
+		path:     issuerURL.Path,
+		domain:   c.Domain,
+		sameSite: c.SameSite,
+		secure:   issuerURL.Scheme == "https",
+		maxAge:   c.MaxAge,
+	}
+	if k.path == "" {
+		k.path = "/"
+	}
+	if k.sameSite == 0 || k.sameSite == http.SameSiteDefaultMode {
+		k.sameSite = http.SameSiteLaxMode
+	}
+	if c.Secure != nil {
+		k.secure = *c.Secure
+	}
+	if k.sameSite == http.SameSiteNoneMode && !k.secure {
+		return nil, errors.New("cookies: SameSite=None requires the Secure attribute")
+	}
+	return k, nil
+}
+
+// set sets a cookie scoped to the issuer, which scripts can't read.
+func (k *cookies) set(w http.ResponseWriter, name, value string) {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     k.path,
+		Domain:   k.domain,
+		SameSite: k.sameSite,
+		Secure:   k.secure,
+		HttpOnly: true,
+	}
+	if k.maxAge > 0 {
+		cookie.MaxAge = int(k.maxAge.Seconds())
+	}
+	http.SetCookie(w, cookie)
+}
+
+// clear deletes a cookie set with set.
+func (k *cookies) clear(w http.ResponseWriter, name string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Path:     k.path,
+		Domain:   k.domain,
+		SameSite: k.sameSite,
+		Secure:   k.secure,
+		HttpOnly: true,
+		MaxAge:   -1,
+	})
+}
+
+const (
+	csrfCookieName = "dex_csrf"
+	csrfFormField  = "csrf_token"
+)
+
+// csrfToken returns the token the password, approval and device forms must
+// submit, the value of the CSRF cookie, which is set if the request doesn't
+// have it yet. It must be called before the response header is written. The
+// token is empty if CSRF protection is disabled.
+func (s *Server) csrfToken(w http.ResponseWriter, r *http.Request) string {
+	if !s.csrfProtection {
+		return ""
+	}
+	return s.requireCSRFToken(w, r)
+}
+
+// requireCSRFToken returns the CSRF token like csrfToken, even if CSRF
+// protection is disabled for the login forms. Forms authenticated by a cookie
+// always need it.
+func (s *Server) requireCSRFToken(w http.ResponseWriter, r *http.Request) string {
+	if c, err := r.Cookie(csrfCookieName); err == nil && c.Value != "" {
+		return c.Value
+	}
+	token := storage.NewID()
+	s.cookies.set(w, csrfCookieName, token)
+	return token
+}
+
+// checkCSRF returns if a form was submitted with the token of the CSRF
+// cookie, rendering an error if it wasn't. Cross-site requests can't read the
+// cookie to submit it (double-submit).
+func (s *Server) checkCSRF(w http.ResponseWriter, r *http.Request) bool {
+	if !s.csrfProtection {
+		return true
+	}
+	return s.requireCSRF(w, r)
+}
+
+// requireCSRF checks the token of a form like checkCSRF, even if CSRF
+// protection is disabled for the login forms.
+func (s *Server) requireCSRF(w http.ResponseWriter, r *http.Request) bool {
+	c, err := r.Cookie(csrfCookieName)
+	if err == nil && c.Value != "" && subtle.ConstantTimeCompare([]byte(c.Value), []byte(r.PostFormValue(csrfFormField))) == 1 {
+		return true
+	}
+	s.logger.InfoContext(r.Context(), "rejected form without a valid csrf token", "path", r.URL.Path)
+	s.renderError(r, w, http.StatusForbidden, "The form has expired, please try again.")
+	return false
+}
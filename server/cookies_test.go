@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSRFProtection(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, s := newTestServer(ctx, t, func(c *Config) {
+		c.CSRFProtection = true
+		c.Cookies = &CookieConfig{SameSite: http.SameSiteStrictMode, MaxAge: time.Hour}
+	})
+	defer httpServer.Close()
+
+	rr := httptest.NewRecorder()
+	s.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/device", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	cookies := rr.Result().Cookies()
+	require.Len(t, cookies, 1)
+	cookie := cookies[0]
+	require.Equal(t, csrfCookieName, cookie.Name)
+	require.Equal(t, http.SameSiteStrictMode, cookie.SameSite)
+	require.Equal(t, 3600, cookie.MaxAge)
+	require.True(t, cookie.HttpOnly)
+	require.False(t, cookie.Secure, "the issuer of the test server is an http URL")
+	require.Regexp(t, regexp.MustCompile(`name="csrf_token" value="`+cookie.Value+`"`), rr.Body.String())
+
+	verify := func(token string, withCookie bool) *httptest.ResponseRecorder {
+		form := url.Values{"user_code": {"ABCD-EFGH"}}
+		if token != "" {
+			form.Set(csrfFormField, token)
+		}
+		req := httptest.NewRequest(http.MethodPost, "/device/auth/verify_code", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if withCookie {
+			req.AddCookie(&http.Cookie{Name: csrfCookieName, Value: cookie.Value})
+		}
+		rr := httptest.NewRecorder()
+		s.ServeHTTP(rr, req)
+		return rr
+	}
+	require.Equal(t, http.StatusForbidden, verify("", true).Code)
+	require.Equal(t, http.StatusForbidden, verify(cookie.Value, false).Code)
+	require.Equal(t, http.StatusForbidden, verify("forged", true).Code)
+
+	// The unknown user code is rejected once the form is accepted, and the
+	// cookie is kept.
+	rr = verify(cookie.Value, true)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Empty(t, rr.Result().Cookies())
+	require.Contains(t, rr.Body.String(), cookie.Value)
+}
+
+func TestNewCookies(t *testing.T) {
+	issuer := url.URL{Scheme: "https", Host: "dex.example.com", Path: "/dex"}
+	k, err := newCookies(&CookieConfig{}, issuer)
+	require.NoError(t, err)
+	require.Equal(t, &cookies{path: "/dex", sameSite: http.SameSiteLaxMode, secure: true}, k)
+
+	insecure := false
+	for _, c := range []CookieConfig{
+		{MaxAge: -time.Second},
+		{SameSite: http.SameSiteNoneMode, Secure: &insecure},
+	} {
+		_, err := newCookies(&c, issuer)
+		require.Error(t, err, "%+v", c)
+	}
+}
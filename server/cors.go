@@ -0,0 +1,131 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gorilla/handlers"
+
+	"github.com/dexidp/dex/storage"
+)
+
+// clientOriginsValidFor is how long the origins of all clients are cached for
+// checking preflight requests.
+const clientOriginsValidFor = time.Minute
+
+// ValidateAllowedOrigins checks the web origins of a client, which must be
+// http or https URLs without a path, like "https://app.example.com".
+func ValidateAllowedOrigins(origins []string) error {
+	for _, origin := range origins {
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User != nil ||
+			u.Path != "" || u.RawQuery != "" || u.Fragment != "" || u.String() != origin {
+			return fmt.Errorf("invalid allowed origin %q: must be an http or https origin like https://app.example.com", origin)
+		}
+	}
+	return nil
+}
+
+// clientOrigins caches the union of the web origins of all clients. Preflight
+// requests carry no credentials, so they're allowed for origins of any
+// client.
+type clientOrigins struct {
+	storage storage.Storage
+	now     func() time.Time
+
+	mu      sync.Mutex
+	origins map[string]bool
+	expiry  time.Time
+}
+
+func (o *clientOrigins) allowed(origin string) (bool, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if now := o.now(); now.After(o.expiry) {
+		clients, err := o.storage.ListClients()
+		if err != nil {
+			return false, err
+		}
+		o.origins = make(map[string]bool)
+		for _, client := range clients {
+			for _, origin := range client.AllowedOrigins {
+				o.origins[origin] = true
+			}
+		}
+		o.expiry = now.Add(clientOriginsValidFor)
+	}
+	return o.origins[origin], nil
+}
+
+// configuredOrigin reports whether an origin is allowed by the server wide
+// configuration.
+func (s *Server) configuredOrigin(_ *http.Request, origin string) bool {
+	return slices.Contains(s.allowedOrigins, origin) || slices.Contains(s.allowedOrigins, "*")
+}
+
+// originAllowed reports whether CORS requests from an origin are allowed,
+// because it's one of the configured origins or of any client.
+func (s *Server) originAllowed(r *http.Request, origin string) bool {
+	if s.configuredOrigin(r, origin) {
+		return true
+	}
+	allowed, err := s.clientOrigins.allowed(origin)
+	if err != nil {
+		s.logger.ErrorContext(r.Context(), "failed to list clients for cors", "err", err)
+	}
+	return allowed
+}
+
+// tokenRequestOriginAllowed reports whether the response to a token request
+// may be read by an origin, which must be one of the configured origins or
+// of the client making the request.
+func (s *Server) tokenRequestOriginAllowed(r *http.Request, origin string) bool {
+	if s.configuredOrigin(r, origin) {
+		return true
+	}
+	clientID, _, ok := r.BasicAuth()
+	if ok {
+		clientID, _ = url.QueryUnescape(clientID)
+	} else {
+		clientID = r.PostFormValue("client_id")
+	}
+	client, err := s.storage.GetClient(clientID)
+	return err == nil && slices.Contains(client.AllowedOrigins, origin)
+}
+
+// withCORS adds CORS headers to the responses of a handler for the origins
+// allowed by the allowed function, and to preflight requests for the origins
+// allowed by the preflight function. Requests from other origins are handled
+// without them.
+func (s *Server) withCORS(h http.Handler, allowed, preflight func(r *http.Request, origin string) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+		check := allowed
+		// Preflight requests carry no credentials identifying the client.
+		if r.Method == http.MethodOptions {
+			check = preflight
+		}
+		if !check(r, origin) {
+			h.ServeHTTP(w, r)
+			return
+		}
+		opts := []handlers.CORSOption{
+			handlers.AllowedOriginValidator(func(string) bool { return true }),
+			handlers.AllowedHeaders(s.allowedHeaders),
+		}
+		if slices.Contains(s.allowedOrigins, "*") {
+			// Allow any origin with "*" rather than echoing the origin.
+			opts = append(opts, handlers.AllowedOrigins([]string{"*"}))
+		}
+		handlers.CORS(opts...)(h).ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dexidp/dex/storage"
+)
+
+func TestClientAllowedOrigins(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, s := newTestServer(ctx, t, nil)
+	defer httpServer.Close()
+
+	for _, c := range []storage.Client{
+		{ID: "spa", Public: true, RedirectURIs: []string{"https://spa.example.com/callback"}, AllowedOrigins: []string{"https://spa.example.com"}},
+		{ID: "other", Public: true, RedirectURIs: []string{"https://other.example.com/callback"}, AllowedOrigins: []string{"https://other.example.com"}},
+	} {
+		require.NoError(t, s.storage.CreateClient(ctx, c))
+	}
+
+	do := func(method, path, origin string, form url.Values) http.Header {
+		req := httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Origin", origin)
+		if method == http.MethodOptions {
+			req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			req.Header.Set("Access-Control-Request-Headers", "Authorization")
+		}
+		rr := httptest.NewRecorder()
+		s.ServeHTTP(rr, req)
+		return rr.Header()
+	}
+
+	h := do(http.MethodOptions, "/token", "https://spa.example.com", nil)
+	require.Equal(t, "https://spa.example.com", h.Get("Access-Control-Allow-Origin"))
+	require.Equal(t, "Authorization", h.Get("Access-Control-Allow-Headers"))
+	require.Empty(t, do(http.MethodOptions, "/token", "https://evil.example.com", nil).Get("Access-Control-Allow-Origin"))
+
+	for _, path := range []string{"/keys", "/userinfo", "/.well-known/openid-configuration"} {
+		h := do(http.MethodGet, path, "https://other.example.com", nil)
+		require.Equal(t, "https://other.example.com", h.Get("Access-Control-Allow-Origin"), path)
+		require.Equal(t, "Origin", h.Get("Vary"), path)
+		require.Empty(t, do(http.MethodGet, path, "https://evil.example.com", nil).Get("Access-Control-Allow-Origin"), path)
+	}
+
+	// Token responses are only readable by the origins of the client.
+	form := url.Values{"grant_type": {"authorization_code"}, "code": {"unknown"}, "client_id": {"spa"}}
+	require.Equal(t, "https://spa.example.com", do(http.MethodPost, "/token", "https://spa.example.com", form).Get("Access-Control-Allow-Origin"))
+	require.Empty(t, do(http.MethodPost, "/token", "https://other.example.com", form).Get("Access-Control-Allow-Origin"))
+
+	// Sessions are only managed from the configured origins.
+	require.Empty(t, do(http.MethodOptions, "/sessions", "https://spa.example.com", nil).Get("Access-Control-Allow-Origin"))
+	require.Empty(t, do(http.MethodGet, "/sessions", "https://spa.example.com", nil).Get("Access-Control-Allow-Origin"))
+}
+
+func TestConfiguredAllowedOrigins(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, s := newTestServer(ctx, t, func(c *Config) {
+		c.AllowedOrigins = []string{"*"}
+	})
+	defer httpServer.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/keys", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	rr := httptest.NewRecorder()
+	s.ServeHTTP(rr, req)
+	require.Equal(t, "*", rr.Header().Get("Access-Control-Allow-Origin"))
+
+	req = httptest.NewRequest(http.MethodGet, "/sessions", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	rr = httptest.NewRecorder()
+	s.ServeHTTP(rr, req)
+	require.Equal(t, "*", rr.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestValidateAllowedOrigins(t *testing.T) {
+	require.NoError(t, ValidateAllowedOrigins([]string{"https://app.example.com", "http://localhost:3000"}))
+	for _, origin := range []string{
+		"app.example.com",
+		"https://app.example.com/",
+		"https://app.example.com/callback",
+		"ftp://app.example.com",
+		"https://user@app.example.com",
+		"*",
+	} {
+		require.Error(t, ValidateAllowedOrigins([]string{origin}), origin)
+	}
+}
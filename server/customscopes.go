@@ -0,0 +1,131 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/dexidp/dex/storage"
+)
+
+// CustomScope is an additional scope clients can request. Requesting it adds
+// the listed claims to ID tokens, taken either from the standard claims dex
+// knows about or from the custom claims provided by the connector.
+type CustomScope struct {
+	// Name of the scope, as requested by clients.
+	Name string
+	// Description shown to users on the approval page.
+	Description string
+	// Claims released by the scope.
+	Claims []string
+}
+
+// reservedClaims can't be released through custom scopes, since dex sets
+// them itself.
+var reservedClaims = map[string]bool{
+	"iss":       true,
+	"sub":       true,
+	"aud":       true,
+	"exp":       true,
+	"iat":       true,
+	"nbf":       true,
+	"nonce":     true,
+	"at_hash":   true,
+	"c_hash":    true,
+	"azp":       true,
+	"auth_time": true,
+	"acr":       true,
+	"amr":       true,
+	"jti":       true,
+	"typ":       true,
+
+	"federated_claims": true,
+}
+
+func isBuiltinScope(scope string) bool {
+	switch scope {
+	case scopeOpenID, scopeOfflineAccess, scopeEmail, scopeProfile, scopeGroups, scopeFederatedID, scopeSessions:
+		return true
+	}
+	_, ok := parseCrossClientScope(scope)
+	return ok
+}
+
+func newCustomScopes(scopes []CustomScope) (map[string]CustomScope, error) {
+	customScopes := make(map[string]CustomScope, len(scopes))
+	for _, scope := range scopes {
+		if scope.Name == "" {
+			return nil, fmt.Errorf("custom scope has no name")
+		}
+		if isBuiltinScope(scope.Name) {
+			return nil, fmt.Errorf("custom scope %q conflicts with a built-in scope", scope.Name)
+		}
+		if _, ok := customScopes[scope.Name]; ok {
+			return nil, fmt.Errorf("custom scope %q defined more than once", scope.Name)
+		}
+		if len(scope.Claims) == 0 {
+			return nil, fmt.Errorf("custom scope %q has no claims", scope.Name)
+		}
+		for _, claim := range scope.Claims {
+			if reservedClaims[claim] {
+				return nil, fmt.Errorf("custom scope %q: claim %q is reserved", scope.Name, claim)
+			}
+		}
+		customScopes[scope.Name] = scope
+	}
+	return customScopes, nil
+}
+
+// customScopeClaims returns the claims released by the custom scopes among
+// the requested scopes. Claims the user doesn't have are omitted.
+func (s *Server) customScopeClaims(claims storage.Claims, scopes []string) map[string]interface{} {
+	values := make(map[string]interface{})
+	for _, name := range scopes {
+		scope, ok := s.customScopes[name]
+		if !ok {
+			continue
+		}
+		for _, claim := range scope.Claims {
+			if v, ok := claimValue(claims, claim); ok {
+				values[claim] = v
+			}
+		}
+	}
+	return values
+}
+
+func claimValue(claims storage.Claims, claim string) (interface{}, bool) {
+	switch claim {
+	case "email":
+		return claims.Email, claims.Email != ""
+	case "email_verified":
+		return claims.EmailVerified, true
+	case "groups":
+		return claims.Groups, len(claims.Groups) > 0
+	case "name":
+		return claims.Username, claims.Username != ""
+	case "preferred_username":
+		return claims.PreferredUsername, claims.PreferredUsername != ""
+	}
+	v, ok := claims.CustomClaims[claim]
+	return v, ok
+}
+
+// mergeClaims adds extra claims to a serialized set of claims, and removes
+// the omitted ones.
+func mergeClaims(payload []byte, extra map[string]interface{}, omit []string) ([]byte, error) {
+	var merged map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &merged); err != nil {
+		return nil, err
+	}
+	for claim, v := range extra {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("claim %q: %v", claim, err)
+		}
+		merged[claim] = data
+	}
+	for _, claim := range omit {
+		delete(merged, claim)
+	}
+	return json.Marshal(merged)
+}
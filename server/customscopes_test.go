@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dexidp/dex/storage"
+)
+
+func TestNewCustomScopes(t *testing.T) {
+	tests := []struct {
+		name    string
+		scopes  []CustomScope
+		wantErr bool
+	}{
+		{
+			name:   "valid",
+			scopes: []CustomScope{{Name: "employee", Claims: []string{"department", "email"}}},
+		},
+		{
+			name:    "no name",
+			scopes:  []CustomScope{{Claims: []string{"department"}}},
+			wantErr: true,
+		},
+		{
+			name:    "no claims",
+			scopes:  []CustomScope{{Name: "employee"}},
+			wantErr: true,
+		},
+		{
+			name:    "built-in scope",
+			scopes:  []CustomScope{{Name: "groups", Claims: []string{"department"}}},
+			wantErr: true,
+		},
+		{
+			name:    "cross client scope",
+			scopes:  []CustomScope{{Name: "audience:server:client_id:foo", Claims: []string{"department"}}},
+			wantErr: true,
+		},
+		{
+			name: "duplicate",
+			scopes: []CustomScope{
+				{Name: "employee", Claims: []string{"department"}},
+				{Name: "employee", Claims: []string{"employee_id"}},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "reserved claim",
+			scopes:  []CustomScope{{Name: "employee", Claims: []string{"sub"}}},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := newCustomScopes(tc.scopes)
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCustomScopeClaims(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, s := newTestServer(ctx, t, func(c *Config) {
+		c.CustomScopes = []CustomScope{
+			{Name: "employee", Description: "View your employee details", Claims: []string{"department", "employee_id"}},
+			{Name: "contact", Claims: []string{"email", "phone_number"}},
+		}
+	})
+	defer httpServer.Close()
+
+	claims := storage.Claims{
+		UserID:        "1",
+		Username:      "jane",
+		Email:         "jane.doe@example.com",
+		EmailVerified: true,
+		CustomClaims: map[string]interface{}{
+			"department":  "engineering",
+			"employee_id": "e-1234",
+			"office":      "berlin",
+		},
+	}
+
+	tests := []struct {
+		name   string
+		scopes []string
+		want   map[string]interface{}
+		absent []string
+	}{
+		{
+			name:   "no custom scopes",
+			scopes: []string{"openid"},
+			absent: []string{"department", "employee_id", "email", "office"},
+		},
+		{
+			name:   "custom claims",
+			scopes: []string{"openid", "employee"},
+			want: map[string]interface{}{
+				"department":  "engineering",
+				"employee_id": "e-1234",
+			},
+			absent: []string{"email", "office"},
+		},
+		{
+			name:   "standard claims",
+			scopes: []string{"openid", "contact"},
+			want: map[string]interface{}{
+				"email": "jane.doe@example.com",
+			},
+			absent: []string{"phone_number", "department"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			idToken, _, err := s.newIDToken(ctx, "test", claims, tc.scopes, nil, "", "", "", "mock")
+			require.NoError(t, err)
+
+			got := unsafeTokenClaims(t, idToken)
+
+			require.Equal(t, s.issuerURL.String(), got["iss"])
+			for claim, v := range tc.want {
+				require.Equal(t, v, got[claim], claim)
+			}
+			for _, claim := range tc.absent {
+				require.NotContains(t, got, claim)
+			}
+		})
+	}
+
+	d := s.constructDiscovery(ctx)
+	require.Contains(t, d.Scopes, "employee")
+	require.Contains(t, d.Scopes, "contact")
+	require.Contains(t, d.Claims, "department")
+	require.Contains(t, d.Claims, "phone_number")
+
+	require.Equal(t, "View your employee details", s.templates.scopeDescriptions["employee"])
+}
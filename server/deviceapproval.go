@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dexidp/dex/storage"
+)
+
+// errDeviceRequestDecided is returned when approving or denying a device
+// request which was already approved or denied.
+var errDeviceRequestDecided = errors.New("device request was already approved or denied")
+
+// pendingDeviceRequest returns a device request, or storage.ErrNotFound if it
+// expired. User codes are matched ignoring case, like on the device page.
+func (s *Server) pendingDeviceRequest(userCode string) (storage.DeviceRequest, error) {
+	deviceReq, err := s.storage.GetDeviceRequest(strings.ToUpper(userCode))
+	if err != nil {
+		return deviceReq, err
+	}
+	if s.now().After(deviceReq.Expiry) {
+		return deviceReq, storage.ErrNotFound
+	}
+	return deviceReq, nil
+}
+
+// approveDeviceRequest completes a device request on behalf of a user, as if
+// they logged in through the browser. No refresh token is issued, since there
+// is no connector session to refresh.
+func (s *Server) approveDeviceRequest(ctx context.Context, userCode, connID string, claims storage.Claims) error {
+	deviceReq, err := s.pendingDeviceRequest(userCode)
+	if err != nil {
+		return err
+	}
+
+	scopes := make([]string, 0, len(deviceReq.Scopes))
+	for _, scope := range deviceReq.Scopes {
+		if scope != scopeOfflineAccess {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	accessToken, expiry, err := s.newAccessToken(ctx, deviceReq.ClientID, claims, scopes, nil, "", connID)
+	if err != nil {
+		return fmt.Errorf("create access token: %v", err)
+	}
+	idToken, _, err := s.newIDToken(ctx, deviceReq.ClientID, claims, scopes, nil, "", accessToken, "", connID)
+	if err != nil {
+		return fmt.Errorf("create ID token: %v", err)
+	}
+	resp, err := json.MarshalIndent(s.toAccessTokenResponse(idToken, accessToken, "", expiry), "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal device token response: %v", err)
+	}
+
+	return s.decideDeviceRequest(deviceReq, deviceTokenComplete, string(resp))
+}
+
+// denyDeviceRequest rejects a device request, so the device stops polling.
+func (s *Server) denyDeviceRequest(userCode string) error {
+	deviceReq, err := s.pendingDeviceRequest(userCode)
+	if err != nil {
+		return err
+	}
+	return s.decideDeviceRequest(deviceReq, deviceTokenDenied, "")
+}
+
+func (s *Server) decideDeviceRequest(deviceReq storage.DeviceRequest, status, token string) error {
+	err := s.storage.UpdateDeviceToken(deviceReq.DeviceCode, func(old storage.DeviceToken) (storage.DeviceToken, error) {
+		if old.Status != deviceTokenPending {
+			return old, errDeviceRequestDecided
+		}
+		if s.now().After(old.Expiry) {
+			return old, storage.ErrNotFound
+		}
+		old.Status = status
+		old.Token = token
+		return old, nil
+	})
+	if err == nil {
+		s.metrics.observeDeviceCompletion(status)
+	}
+	return err
+}
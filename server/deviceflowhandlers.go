@@ -4,14 +4,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"net/url"
 	"path"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
-	"golang.org/x/net/html"
+	"github.com/skip2/go-qrcode"
 
 	"github.com/dexidp/dex/storage"
 )
@@ -31,10 +33,102 @@ type deviceCodeResponse struct {
 	PollInterval int `json:"interval"`
 }
 
+// UserCodeFormat describes the user codes of the device flow, which users
+// type into the verification page.
+type UserCodeFormat struct {
+	// Number of characters, not counting the dashes separating groups of four.
+	// Defaults to 8.
+	Length int
+	// Characters user codes are made of. Only upper case letters and digits
+	// are allowed, since entered codes are upper cased. Defaults to upper case
+	// consonants, so no words are formed by accident.
+	Charset string
+}
+
+// minUserCodeEntropy is the minimum number of bits of entropy of user codes,
+// as user codes are short lived and attempts to redeem them are limited.
+const minUserCodeEntropy = 20
+
+func (f UserCodeFormat) withDefaults() (UserCodeFormat, error) {
+	if f.Length == 0 {
+		f.Length = 8
+	}
+	if f.Charset == "" {
+		f.Charset = storage.UserCodeCharset
+	}
+
+	seen := make(map[rune]bool, len(f.Charset))
+	for _, c := range f.Charset {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return f, fmt.Errorf("invalid user code character %q: only upper case letters and digits are allowed", c)
+		}
+		if seen[c] {
+			return f, fmt.Errorf("user code character %q listed more than once", c)
+		}
+		seen[c] = true
+	}
+	if f.Length < 0 || float64(f.Length)*math.Log2(float64(len(f.Charset))) < minUserCodeEntropy {
+		return f, fmt.Errorf("user codes of %d characters out of %d have less than %d bits of entropy", f.Length, len(f.Charset), minUserCodeEntropy)
+	}
+	return f, nil
+}
+
+// placeholder returns the placeholder of the user code input, e.g.
+// "XXXX-XXXX".
+func (f UserCodeFormat) placeholder() string {
+	return storage.NewUserCodeFromCharset(f.Length, "X")
+}
+
+// normalizeUserCode brings a user code typed in by users into the format of
+// the stored ones: upper case, with groups of four characters separated by
+// dashes.
+func normalizeUserCode(userCode string) string {
+	userCode = strings.Map(func(r rune) rune {
+		if r == '-' || unicode.IsSpace(r) {
+			return -1
+		}
+		return unicode.ToUpper(r)
+	}, userCode)
+	var b strings.Builder
+	for i, r := range []rune(userCode) {
+		if i > 0 && i%4 == 0 {
+			b.WriteByte('-')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
 func (s *Server) getDeviceVerificationURI() string {
 	return path.Join(s.issuerURL.Path, "/device/auth/verify_code")
 }
 
+// deviceVerificationURIs returns the URL users verify device requests at,
+// and the same URL with the user code filled in.
+func (s *Server) deviceVerificationURIs(userCode string) (verificationURI, verificationURIComplete string) {
+	u := s.issuerURL
+	u.Path = path.Join(u.Path, "device")
+	verificationURI = u.String()
+
+	q := u.Query()
+	q.Set("user_code", userCode)
+	u.RawQuery = q.Encode()
+	return verificationURI, u.String()
+}
+
+// deviceQRCodeURL returns the URL of the QR code image for a user code, if the
+// user code belongs to a pending device request.
+func (s *Server) deviceQRCodeURL(userCode string) string {
+	if userCode == "" {
+		return ""
+	}
+	deviceRequest, err := s.storage.GetDeviceRequest(normalizeUserCode(userCode))
+	if err != nil || s.now().After(deviceRequest.Expiry) {
+		return ""
+	}
+	return path.Join(s.issuerURL.Path, "device/qr") + "?" + url.Values{"user_code": {deviceRequest.UserCode}}.Encode()
+}
+
 func (s *Server) handleDeviceExchange(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
@@ -47,7 +141,18 @@ func (s *Server) handleDeviceExchange(w http.ResponseWriter, r *http.Request) {
 		if err != nil {
 			invalidAttempt = false
 		}
-		if err := s.templates.device(r, w, s.getDeviceVerificationURI(), userCode, invalidAttempt); err != nil {
+		if !invalidAttempt && userCode != "" && s.templates.devicePage.AutoSubmit {
+			deviceRequest, err := s.storage.GetDeviceRequest(normalizeUserCode(userCode))
+			if err == nil && !s.now().After(deviceRequest.Expiry) {
+				s.redirectToDeviceAuth(w, r, deviceRequest)
+				return
+			}
+		}
+		var qrCodeURL string
+		if !invalidAttempt {
+			qrCodeURL = s.deviceQRCodeURL(userCode)
+		}
+		if err := s.templates.device(r, w, s.getDeviceVerificationURI(), userCode, s.deviceUserCode.placeholder(), qrCodeURL, invalidAttempt, s.csrfToken(w, r)); err != nil {
 			s.logger.ErrorContext(r.Context(), "server template error", "err", err)
 			s.renderError(r, w, http.StatusNotFound, "Page not found")
 		}
@@ -56,9 +161,40 @@ func (s *Server) handleDeviceExchange(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleDeviceQRCode renders a QR code of the verification_uri_complete of a
+// pending device request, so users can scan it to continue on another device.
+func (s *Server) handleDeviceQRCode(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.renderError(r, w, http.StatusBadRequest, "Requested resource does not exist.")
+		return
+	}
+
+	userCode := strings.ToUpper(r.URL.Query().Get("user_code"))
+	deviceRequest, err := s.storage.GetDeviceRequest(userCode)
+	if err != nil || s.now().After(deviceRequest.Expiry) {
+		if err != nil && err != storage.ErrNotFound {
+			s.logger.ErrorContext(r.Context(), "failed to get device request", "err", err)
+		}
+		http.NotFound(w, r)
+		return
+	}
+
+	_, verificationURIComplete := s.deviceVerificationURIs(deviceRequest.UserCode)
+	png, err := qrcode.Encode(verificationURIComplete, qrcode.Medium, 256)
+	if err != nil {
+		s.logger.ErrorContext(r.Context(), "failed to render QR code", "err", err)
+		s.renderError(r, w, http.StatusInternalServerError, "")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(png)
+}
+
 func (s *Server) handleDeviceCode(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	pollIntervalSeconds := 5
+	pollIntervalSeconds := s.devicePollInterval
 
 	switch r.Method {
 	case http.MethodPost:
@@ -91,7 +227,7 @@ func (s *Server) handleDeviceCode(w http.ResponseWriter, r *http.Request) {
 		deviceCode := storage.NewDeviceCode()
 
 		// make user code
-		userCode := storage.NewUserCode()
+		userCode := storage.NewUserCodeFromCharset(s.deviceUserCode.Length, s.deviceUserCode.Charset)
 
 		// Generate the expire time
 		expireTime := time.Now().Add(s.deviceRequestsValidFor)
@@ -118,7 +254,7 @@ func (s *Server) handleDeviceCode(w http.ResponseWriter, r *http.Request) {
 			Status:              deviceTokenPending,
 			Expiry:              expireTime,
 			LastRequestTime:     s.now(),
-			PollIntervalSeconds: 0,
+			PollIntervalSeconds: pollIntervalSeconds,
 			PKCE: storage.PKCE{
 				CodeChallenge:       codeChallenge,
 				CodeChallengeMethod: codeChallengeMethod,
@@ -131,19 +267,7 @@ func (s *Server) handleDeviceCode(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		u, err := url.Parse(s.issuerURL.String())
-		if err != nil {
-			s.logger.ErrorContext(r.Context(), "could not parse issuer URL", "err", err)
-			s.tokenErrHelper(w, errInvalidRequest, "", http.StatusInternalServerError)
-			return
-		}
-		u.Path = path.Join(u.Path, "device")
-		vURI := u.String()
-
-		q := u.Query()
-		q.Set("user_code", userCode)
-		u.RawQuery = q.Encode()
-		vURIComplete := u.String()
+		vURI, vURIComplete := s.deviceVerificationURIs(userCode)
 
 		code := deviceCodeResponse{
 			DeviceCode:              deviceCode,
@@ -174,14 +298,14 @@ func (s *Server) handleDeviceCode(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleDeviceTokenDeprecated(w http.ResponseWriter, r *http.Request) {
-	s.logger.Warn(`the /device/token endpoint was called. It will be removed, use /token instead.`, "deprecated", true)
+	s.logger.WarnContext(r.Context(), `the /device/token endpoint was called. It will be removed, use /token instead.`, "deprecated", true)
 
 	w.Header().Set("Content-Type", "application/json")
 	switch r.Method {
 	case http.MethodPost:
 		err := r.ParseForm()
 		if err != nil {
-			s.logger.Warn("could not parse Device Token Request body", "err", err)
+			s.logger.WarnContext(r.Context(), "could not parse Device Token Request body", "err", err)
 			s.tokenErrHelper(w, errInvalidRequest, "", http.StatusBadRequest)
 			return
 		}
@@ -222,14 +346,13 @@ func (s *Server) handleDeviceToken(w http.ResponseWriter, r *http.Request) {
 
 	// Rate Limiting check
 	slowDown := false
-	pollInterval := deviceToken.PollIntervalSeconds
+	pollInterval := max(deviceToken.PollIntervalSeconds, s.devicePollInterval)
 	minRequestTime := deviceToken.LastRequestTime.Add(time.Second * time.Duration(pollInterval))
 	if now.Before(minRequestTime) {
 		slowDown = true
-		// Continually increase the poll interval until the user waits the proper time
+		// Polling too fast increases the interval by 5 seconds for this and all
+		// subsequent requests, see RFC 8628 section 3.5.
 		pollInterval += 5
-	} else {
-		pollInterval = 5
 	}
 
 	switch deviceToken.Status {
@@ -276,6 +399,8 @@ func (s *Server) handleDeviceToken(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		w.Write([]byte(deviceToken.Token))
+	case deviceTokenDenied:
+		s.tokenErrHelper(w, errAccessDenied, "The device request was denied.", http.StatusBadRequest)
 	}
 }
 
@@ -293,9 +418,8 @@ func (s *Server) handleDeviceCallback(w http.ResponseWriter, r *http.Request) {
 
 		// Authorization redirect callback from OAuth2 auth flow.
 		if errMsg := r.FormValue("error"); errMsg != "" {
-			// escape the message to prevent cross-site scripting
-			msg := html.EscapeString(errMsg + ": " + r.FormValue("error_description"))
-			http.Error(w, msg, http.StatusBadRequest)
+			s.logger.ErrorContext(ctx, "authorization failed", "error", errMsg, "error_description", r.FormValue("error_description"))
+			s.renderError(r, w, http.StatusBadRequest, "Authorization failed.")
 			return
 		}
 
@@ -332,7 +456,7 @@ func (s *Server) handleDeviceCallback(w http.ResponseWriter, r *http.Request) {
 			}
 			return
 		}
-		if client.Secret != deviceReq.ClientSecret {
+		if !s.validClientSecret(client, deviceReq.ClientSecret) {
 			s.tokenErrHelper(w, errInvalidClient, "Invalid client credentials.", http.StatusUnauthorized)
 			return
 		}
@@ -378,14 +502,15 @@ func (s *Server) handleDeviceCallback(w http.ResponseWriter, r *http.Request) {
 			s.renderError(r, w, http.StatusBadRequest, "")
 			return
 		}
+		s.metrics.observeDeviceCompletion(deviceTokenComplete)
 
-		if err := s.templates.deviceSuccess(r, w, client.Name); err != nil {
+		if err := s.templates.forClient(client.ID).deviceSuccess(r, w, client.Name); err != nil {
 			s.logger.ErrorContext(r.Context(), "Server template error", "err", err)
 			s.renderError(r, w, http.StatusNotFound, "Page not found")
 		}
 
 	default:
-		http.Error(w, fmt.Sprintf("method not implemented: %s", r.Method), http.StatusBadRequest)
+		s.renderError(r, w, http.StatusBadRequest, "Unsupported request method.")
 		return
 	}
 }
@@ -395,10 +520,13 @@ func (s *Server) verifyUserCode(w http.ResponseWriter, r *http.Request) {
 	case http.MethodPost:
 		err := r.ParseForm()
 		if err != nil {
-			s.logger.Warn("could not parse user code verification request body", "err", err)
+			s.logger.WarnContext(r.Context(), "could not parse user code verification request body", "err", err)
 			s.renderError(r, w, http.StatusBadRequest, "")
 			return
 		}
+		if !s.checkCSRF(w, r) {
+			return
+		}
 
 		userCode := r.Form.Get("user_code")
 		if userCode == "" {
@@ -406,7 +534,7 @@ func (s *Server) verifyUserCode(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		userCode = strings.ToUpper(userCode)
+		userCode = normalizeUserCode(userCode)
 
 		// Find the user code in the available requests
 		deviceRequest, err := s.storage.GetDeviceRequest(userCode)
@@ -414,32 +542,37 @@ func (s *Server) verifyUserCode(w http.ResponseWriter, r *http.Request) {
 			if err != nil && err != storage.ErrNotFound {
 				s.logger.ErrorContext(r.Context(), "failed to get device request", "err", err)
 			}
-			if err := s.templates.device(r, w, s.getDeviceVerificationURI(), userCode, true); err != nil {
+			if err := s.templates.device(r, w, s.getDeviceVerificationURI(), userCode, s.deviceUserCode.placeholder(), "", true, s.csrfToken(w, r)); err != nil {
 				s.logger.ErrorContext(r.Context(), "Server template error", "err", err)
 				s.renderError(r, w, http.StatusNotFound, "Page not found")
 			}
 			return
 		}
 
-		// Redirect to Dex Auth Endpoint
-		authURL := path.Join(s.issuerURL.Path, "/auth")
-		u, err := url.Parse(authURL)
-		if err != nil {
-			s.renderError(r, w, http.StatusInternalServerError, "Invalid auth URI.")
-			return
-		}
-		q := u.Query()
-		q.Set("client_id", deviceRequest.ClientID)
-		q.Set("client_secret", deviceRequest.ClientSecret)
-		q.Set("state", deviceRequest.UserCode)
-		q.Set("response_type", "code")
-		q.Set("redirect_uri", "/device/callback")
-		q.Set("scope", strings.Join(deviceRequest.Scopes, " "))
-		u.RawQuery = q.Encode()
-
-		http.Redirect(w, r, u.String(), http.StatusFound)
+		s.redirectToDeviceAuth(w, r, deviceRequest)
 
 	default:
 		s.renderError(r, w, http.StatusBadRequest, "Requested resource does not exist.")
 	}
 }
+
+// redirectToDeviceAuth redirects to the auth endpoint to log in and approve
+// a device request.
+func (s *Server) redirectToDeviceAuth(w http.ResponseWriter, r *http.Request, deviceRequest storage.DeviceRequest) {
+	authURL := path.Join(s.issuerURL.Path, "/auth")
+	u, err := url.Parse(authURL)
+	if err != nil {
+		s.renderError(r, w, http.StatusInternalServerError, "Invalid auth URI.")
+		return
+	}
+	q := u.Query()
+	q.Set("client_id", deviceRequest.ClientID)
+	q.Set("client_secret", deviceRequest.ClientSecret)
+	q.Set("state", deviceRequest.UserCode)
+	q.Set("response_type", "code")
+	q.Set("redirect_uri", "/device/callback")
+	q.Set("scope", strings.Join(deviceRequest.Scopes, " "))
+	u.RawQuery = q.Encode()
+
+	http.Redirect(w, r, u.String(), http.StatusFound)
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/dexidp/dex/connector"
+	"github.com/dexidp/dex/storage"
+)
+
+// claimSource is a source of distributed or aggregated claims, as serialized
+// in the _claim_sources claim.
+//
+// https://openid.net/specs/openid-connect-core-1_0.html#AggregatedDistributedClaims
+type claimSource struct {
+	JWT         string `json:"JWT,omitempty"`
+	Endpoint    string `json:"endpoint,omitempty"`
+	AccessToken string `json:"access_token,omitempty"`
+}
+
+// distributedClaims returns the _claim_names and _claim_sources claims for
+// the released claims the connector provided through claim sources, along
+// with the names of those claims, whose values are left out of the token.
+//
+// The groups held by a source can't be filtered, so they aren't released
+// through it to clients with a groups filter. The access tokens of the
+// sources are only forwarded to clients which opted in.
+func (s *Server) distributedClaims(client *storage.Client, claims storage.Claims, scopes []string, requested map[string]*claimRequest) (map[string]interface{}, []string) {
+	if len(claims.ClaimSources) == 0 {
+		return nil, nil
+	}
+
+	released := make(map[string]bool)
+	for _, scope := range scopes {
+		switch scope {
+		case scopeEmail:
+			released["email"] = true
+			released["email_verified"] = true
+		case scopeGroups:
+			released["groups"] = true
+		case scopeProfile:
+			released["name"] = true
+			released["preferred_username"] = true
+		}
+		for _, claim := range s.customScopes[scope].Claims {
+			released[claim] = true
+		}
+	}
+	for claim := range requested {
+		released[claim] = true
+	}
+	if client != nil && client.GroupsFilter != nil {
+		delete(released, "groups")
+	}
+	forwardTokens := client != nil && client.ForwardClaimSourceTokens
+
+	claimNames := make(map[string]string)
+	claimSources := make(map[string]claimSource)
+	var covered []string
+	for _, name := range slices.Sorted(maps.Keys(claims.ClaimSources)) {
+		source := claims.ClaimSources[name]
+		for _, claim := range source.Claims {
+			if !released[claim] || reservedClaims[claim] {
+				continue
+			}
+			if _, ok := claimNames[claim]; ok {
+				continue
+			}
+			claimNames[claim] = name
+			cs := claimSource{JWT: source.JWT, Endpoint: source.Endpoint}
+			if forwardTokens {
+				cs.AccessToken = source.AccessToken
+			}
+			claimSources[name] = cs
+			covered = append(covered, claim)
+		}
+	}
+	if len(claimNames) == 0 {
+		return nil, nil
+	}
+
+	return map[string]interface{}{
+		"_claim_names":   claimNames,
+		"_claim_sources": claimSources,
+	}, covered
+}
+
+// storageClaimSources converts the claim sources of an identity. Their
+// access tokens are dropped unless the client the claims are stored for
+// forwards them.
+func storageClaimSources(sources map[string]connector.ClaimSource, withAccessTokens bool) map[string]storage.ClaimSource {
+	if sources == nil {
+		return nil
+	}
+	converted := make(map[string]storage.ClaimSource, len(sources))
+	for name, source := range sources {
+		cs := storage.ClaimSource{
+			Claims:   source.Claims,
+			JWT:      source.JWT,
+			Endpoint: source.Endpoint,
+		}
+		if withAccessTokens {
+			cs.AccessToken = source.AccessToken
+		}
+		converted[name] = cs
+	}
+	return converted
+}
+
+func connectorClaimSources(sources map[string]storage.ClaimSource) map[string]connector.ClaimSource {
+	if sources == nil {
+		return nil
+	}
+	converted := make(map[string]connector.ClaimSource, len(sources))
+	for name, source := range sources {
+		converted[name] = connector.ClaimSource{
+			Claims:      source.Claims,
+			JWT:         source.JWT,
+			Endpoint:    source.Endpoint,
+			AccessToken: source.AccessToken,
+		}
+	}
+	return converted
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dexidp/dex/connector"
+	"github.com/dexidp/dex/storage"
+)
+
+func TestDistributedClaims(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, s := newTestServer(ctx, t, func(c *Config) {
+		c.CustomScopes = []CustomScope{
+			{Name: "roles", Claims: []string{"roles"}},
+		}
+	})
+	defer httpServer.Close()
+
+	for _, c := range []storage.Client{
+		{ID: "forward", ForwardClaimSourceTokens: true},
+		{ID: "filtered", GroupsFilter: &storage.GroupsFilter{AllowedGroups: []string{"a"}}},
+	} {
+		require.NoError(t, s.storage.CreateClient(ctx, c))
+	}
+
+	claims := storage.Claims{
+		UserID:        "1",
+		Username:      "jane",
+		Email:         "jane.doe@example.com",
+		EmailVerified: true,
+		Groups:        []string{"a", "b"},
+		ClaimSources: map[string]storage.ClaimSource{
+			"src1": {
+				Claims:      []string{"groups"},
+				Endpoint:    "https://graph.example.com/groups",
+				AccessToken: "token",
+			},
+			"src2": {
+				Claims: []string{"roles"},
+				JWT:    "header.payload.signature",
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		clientID    string
+		scopes      []string
+		requested   map[string]*claimRequest
+		wantNames   map[string]interface{}
+		wantSources map[string]interface{}
+		wantGroups  interface{}
+	}{
+		{
+			name:   "no released claims",
+			scopes: []string{"openid", "email"},
+		},
+		{
+			name:      "distributed claims",
+			scopes:    []string{"openid", "groups"},
+			wantNames: map[string]interface{}{"groups": "src1"},
+			wantSources: map[string]interface{}{
+				"src1": map[string]interface{}{"endpoint": "https://graph.example.com/groups"},
+			},
+		},
+		{
+			name:      "forwarded access tokens",
+			clientID:  "forward",
+			scopes:    []string{"openid", "groups"},
+			wantNames: map[string]interface{}{"groups": "src1"},
+			wantSources: map[string]interface{}{
+				"src1": map[string]interface{}{
+					"endpoint":     "https://graph.example.com/groups",
+					"access_token": "token",
+				},
+			},
+		},
+		{
+			name:       "groups filter",
+			clientID:   "filtered",
+			scopes:     []string{"openid", "groups"},
+			wantGroups: []interface{}{"a"},
+		},
+		{
+			name:      "aggregated claims",
+			scopes:    []string{"openid", "roles"},
+			wantNames: map[string]interface{}{"roles": "src2"},
+			wantSources: map[string]interface{}{
+				"src2": map[string]interface{}{"JWT": "header.payload.signature"},
+			},
+		},
+		{
+			name:      "requested claims",
+			scopes:    []string{"openid"},
+			requested: map[string]*claimRequest{"groups": nil},
+			wantNames: map[string]interface{}{"groups": "src1"},
+			wantSources: map[string]interface{}{
+				"src1": map[string]interface{}{"endpoint": "https://graph.example.com/groups"},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			clientID := tc.clientID
+			if clientID == "" {
+				clientID = "test"
+			}
+			idToken, _, err := s.newIDToken(ctx, clientID, claims, tc.scopes, tc.requested, "", "", "", "mock")
+			require.NoError(t, err)
+
+			got := unsafeTokenClaims(t, idToken)
+			require.Equal(t, tc.wantGroups, got["groups"])
+			if tc.wantNames == nil {
+				require.NotContains(t, got, "_claim_names")
+				require.NotContains(t, got, "_claim_sources")
+				return
+			}
+			require.Equal(t, tc.wantNames, got["_claim_names"])
+			require.Equal(t, tc.wantSources, got["_claim_sources"])
+		})
+	}
+}
+
+func TestStorageClaimSources(t *testing.T) {
+	sources := map[string]connector.ClaimSource{
+		"src1": {Claims: []string{"groups"}, Endpoint: "https://graph.example.com/groups", AccessToken: "token"},
+	}
+	require.Empty(t, storageClaimSources(sources, false)["src1"].AccessToken)
+	require.Equal(t, "token", storageClaimSources(sources, true)["src1"].AccessToken)
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dexidp/dex/connector"
+	"github.com/dexidp/dex/storage"
+)
+
+// EmailVerificationConfig lets users of the local password database verify
+// their e-mail address with a link sent to them by e-mail. Until they do, the
+// email_verified claim of their tokens is false.
+type EmailVerificationConfig struct {
+	// Sends the e-mails with the verification links.
+	Mailer Mailer
+
+	// How long verification links are valid. Defaults to 24 hours.
+	ValidFor time.Duration
+
+	// Number of verification e-mails sent to an address per hour. Defaults to 3.
+	EmailsPerHour int
+}
+
+type emailVerification struct {
+	mailer   Mailer
+	validFor time.Duration
+
+	emailLimiter *hourlyLimiter
+}
+
+func newEmailVerification(c *EmailVerificationConfig, now func() time.Time) (*emailVerification, error) {
+	if c.Mailer == nil {
+		return nil, errors.New("no mailer configured for e-mail verification")
+	}
+	if c.ValidFor < 0 || c.EmailsPerHour < 0 {
+		return nil, errors.New("e-mail verification limits must not be negative")
+	}
+	emailsPerHour := c.EmailsPerHour
+	if emailsPerHour == 0 {
+		emailsPerHour = 3
+	}
+	return &emailVerification{
+		mailer:       c.Mailer,
+		validFor:     value(c.ValidFor, 24*time.Hour),
+		emailLimiter: newHourlyLimiter(emailsPerHour, now),
+	}, nil
+}
+
+// sendVerificationEmail e-mails a verification link to a user of the local
+// password database who logged in with an unverified address, if e-mail
+// verification is enabled.
+func (s *Server) sendVerificationEmail(r *http.Request, identity connector.Identity) {
+	if s.emailVerification == nil {
+		return
+	}
+	ctx := r.Context()
+	email := strings.ToLower(identity.Email)
+	if !s.emailVerification.emailLimiter.allow(email) {
+		s.logger.WarnContext(ctx, "e-mail verification rate limit exceeded", "user_id", identity.UserID)
+		return
+	}
+
+	token, err := s.signEmailToken(ctx, emailToken{
+		Type:   emailVerificationTokenType,
+		Email:  email,
+		UserID: identity.UserID,
+		Expiry: s.now().Add(s.emailVerification.validFor).Unix(),
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to sign e-mail verification token", "err", err)
+		return
+	}
+	link := s.issuerURL
+	link.Path = s.absPath("/verify")
+	link.RawQuery = url.Values{"token": {token}}.Encode()
+
+	cat := s.templates.catalog
+	lang := cat.requestLanguage(r)
+	subject := cat.translate(lang, "Verify your e-mail address")
+	// Links are usually valid for hours, but may be valid for less than one.
+	msg := "Please confirm that this is the e-mail address of your account at %s by opening the following link within %d hours:\n\n%s\n\nIf you don't have an account there, you can ignore this e-mail.\n"
+	validFor := int(s.emailVerification.validFor.Hours())
+	if s.emailVerification.validFor%time.Hour != 0 {
+		msg = "Please confirm that this is the e-mail address of your account at %s by opening the following link within %d minutes:\n\n%s\n\nIf you don't have an account there, you can ignore this e-mail.\n"
+		validFor = int(s.emailVerification.validFor.Minutes())
+	}
+	body := cat.translate(lang, msg, s.issuerURL.String(), validFor, link.String())
+
+	s.sendMailInBackground(ctx, s.emailVerification.mailer, email, subject, body)
+}
+
+// handleEmailVerification shows the page confirming an e-mail address,
+// reached through the link of a verification e-mail, and marks the address as
+// verified. Confirming takes a POST, so links opened by mail scanners don't
+// verify addresses.
+func (s *Server) handleEmailVerification(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tmpls := s.templates
+	postURL := s.absPath("/verify")
+	rawToken := r.FormValue("token")
+
+	token, err := s.verifyEmailToken(ctx, emailVerificationTokenType, rawToken)
+	if err != nil {
+		s.logger.InfoContext(ctx, "invalid e-mail verification token", "err", err)
+		s.renderError(r, w, http.StatusBadRequest, "Invalid or expired verification link.")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if err := tmpls.verifyEmail(r, w, postURL, rawToken, token.Email, false); err != nil {
+			s.logger.ErrorContext(ctx, "server template error", "err", err)
+		}
+	case http.MethodPost:
+		var p storage.Password
+		err := s.storage.UpdatePassword(token.Email, func(old storage.Password) (storage.Password, error) {
+			// The account may have been deleted and created again since.
+			if old.UserID != token.UserID {
+				return old, errUserChanged
+			}
+			old.EmailVerified = true
+			p = old
+			return old, nil
+		})
+		if err != nil {
+			if errors.Is(err, errUserChanged) || err == storage.ErrNotFound {
+				s.renderError(r, w, http.StatusBadRequest, "Invalid or expired verification link.")
+				return
+			}
+			s.logger.ErrorContext(ctx, "failed to update password", "err", err)
+			s.renderError(r, w, http.StatusInternalServerError, "Internal server error.")
+			return
+		}
+
+		s.logger.InfoContext(ctx, "e-mail address verified", "user_id", p.UserID)
+		s.emitEvent(ctx, auditEvent{
+			Type:        eventEmailVerified,
+			ConnectorID: LocalConnector,
+			UserID:      p.UserID,
+			Username:    p.Username,
+			Details:     map[string]string{"remote_ip": clientIP(r)},
+		})
+		if err := tmpls.verifyEmail(r, w, postURL, "", token.Email, true); err != nil {
+			s.logger.ErrorContext(ctx, "server template error", "err", err)
+		}
+	default:
+		s.renderError(r, w, http.StatusBadRequest, "Unsupported request method.")
+	}
+}
+
+var errUserChanged = errors.New("user changed")
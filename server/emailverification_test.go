@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/dexidp/dex/connector"
+	"github.com/dexidp/dex/storage"
+)
+
+func TestEmailVerification(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mailer := make(fakeMailer, 10)
+	httpServer, s := newTestServer(ctx, t, func(c *Config) {
+		c.EmailVerification = &EmailVerificationConfig{Mailer: mailer}
+	})
+	defer httpServer.Close()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
+	require.NoError(t, err)
+	require.NoError(t, s.storage.CreatePassword(ctx, storage.Password{
+		Email:    "jane@example.com",
+		Hash:     hash,
+		Username: "jane",
+		UserID:   "1",
+	}))
+	sc := storage.Connector{ID: LocalConnector, Type: LocalConnector, Name: "Email", ResourceVersion: "1"}
+	require.NoError(t, s.storage.CreateConnector(ctx, sc))
+	_, err = s.OpenConnector(sc)
+	require.NoError(t, err)
+	require.NoError(t, s.storage.CreateAuthRequest(ctx, storage.AuthRequest{
+		ID:            "test",
+		ConnectorID:   LocalConnector,
+		RedirectURI:   "cb",
+		Expiry:        time.Now().Add(time.Minute),
+		ResponseTypes: []string{responseTypeCode},
+	}))
+
+	post := func(path string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		s.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := post("/auth/local/login?state=test", url.Values{"login": {"jane@example.com"}, "password": {"password"}})
+	require.Equal(t, http.StatusSeeOther, rr.Code)
+
+	var m sentMail
+	select {
+	case m = <-mailer:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no verification e-mail sent")
+	}
+	require.Equal(t, "jane@example.com", m.to)
+	require.Contains(t, m.body, "within 24 hours")
+
+	// Links valid for less than an hour are described in minutes.
+	s.emailVerification.validFor = 30 * time.Minute
+	s.sendVerificationEmail(httptest.NewRequest(http.MethodGet, "/", nil), connector.Identity{UserID: "2", Email: "john@example.com"})
+	select {
+	case other := <-mailer:
+		require.Contains(t, other.body, "within 30 minutes")
+	case <-time.After(5 * time.Second):
+		t.Fatal("no verification e-mail sent")
+	}
+	s.emailVerification.validFor = 24 * time.Hour
+
+	link := regexp.MustCompile(`https?://\S+/verify\S*`).FindString(m.body)
+	require.NotEmpty(t, link)
+	u, err := url.Parse(link)
+	require.NoError(t, err)
+	token := u.Query().Get("token")
+
+	// Opening the link doesn't verify the address yet.
+	req := httptest.NewRequest(http.MethodGet, u.RequestURI(), nil)
+	rr = httptest.NewRecorder()
+	s.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), "jane@example.com")
+	p, err := s.storage.GetPassword("jane@example.com")
+	require.NoError(t, err)
+	require.False(t, p.EmailVerified)
+
+	events, unsubscribe := s.events.subscribe()
+	defer unsubscribe()
+
+	rr = post("/verify", url.Values{"token": {token}})
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), "Your email address has been verified.")
+	require.Equal(t, eventEmailVerified, (<-events).Type)
+
+	p, err = s.storage.GetPassword("jane@example.com")
+	require.NoError(t, err)
+	require.True(t, p.EmailVerified)
+
+	// Links don't verify accounts created again for the same address.
+	otherToken, err := s.signEmailToken(ctx, emailToken{
+		Type:   emailVerificationTokenType,
+		Email:  "jane@example.com",
+		UserID: "2",
+		Expiry: time.Now().Add(time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+	rr = post("/verify", url.Values{"token": {otherToken}})
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// Registration tokens aren't accepted as verification tokens.
+	registrationToken, err := s.signEmailToken(ctx, emailToken{
+		Type:   registrationTokenType,
+		Email:  "jane@example.com",
+		Expiry: time.Now().Add(time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+	rr = post("/verify", url.Values{"token": {registrationToken}})
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
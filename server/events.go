@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Types of audit events.
+const (
+	eventLoginSucceeded = "login_succeeded"
+	eventLoginFailed    = "login_failed"
+	eventConsentGranted = "consent_granted"
+	eventConsentDenied  = "consent_denied"
+	eventTokenIssued    = "token_issued"
+	eventTokenRefreshed = "token_refreshed"
+	eventRefreshRevoked = "refresh_revoked"
+	eventClientModified = "client_modified"
+
+	// Security events, derived from the other events or detected while
+	// handling requests.
+	eventRepeatedLoginFailures = "repeated_login_failures"
+	eventRefreshReused         = "refresh_token_reused"
+	eventPasswordLocked        = "password_locked"
+	eventPasswordUnlocked      = "password_unlocked"
+
+	eventPasswordResetRequested = "password_reset_requested"
+	eventPasswordReset          = "password_reset"
+	eventUserRegistered         = "user_registered"
+	eventEmailVerified          = "email_verified"
+)
+
+// eventSubscriberQueue is the number of events buffered for each subscriber.
+const eventSubscriberQueue = 64
+
+// auditEvent is a security relevant event streamed to subscribers of the
+// gRPC API.
+type auditEvent struct {
+	Type        string
+	Time        time.Time
+	Issuer      string
+	ClientID    string
+	ConnectorID string
+	UserID      string
+	Username    string
+	Scopes      []string
+	Details     map[string]string
+}
+
+// EventBroker fans out audit events to subscribers. Events aren't stored, so
+// only current subscribers receive them. A broker can be shared by the
+// servers of several issuers.
+type EventBroker struct {
+	mu          sync.Mutex
+	subscribers map[chan auditEvent]struct{}
+}
+
+// NewEventBroker returns a broker without subscribers.
+func NewEventBroker() *EventBroker {
+	return &EventBroker{subscribers: make(map[chan auditEvent]struct{})}
+}
+
+// subscribe returns a channel receiving events and a function to unsubscribe.
+func (b *EventBroker) subscribe() (<-chan auditEvent, func()) {
+	ch := make(chan auditEvent, eventSubscriberQueue)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		delete(b.subscribers, ch)
+		b.mu.Unlock()
+	}
+}
+
+// publish sends an event to all subscribers, dropping it for subscribers
+// whose queue is full rather than blocking the request emitting it. It
+// returns the number of subscribers the event was dropped for.
+func (b *EventBroker) publish(e auditEvent) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	dropped := 0
+	for ch := range b.subscribers {
+		select {
+		case ch <- e:
+		default:
+			dropped++
+		}
+	}
+	return dropped
+}
+
+// emitEvent publishes an audit event of the server.
+func (s *Server) emitEvent(ctx context.Context, e auditEvent) {
+	e.Time = s.now()
+	e.Issuer = s.issuerURL.String()
+	switch e.Type {
+	case eventLoginSucceeded:
+		s.metrics.observeLogin(e.ConnectorID, true)
+	case eventLoginFailed:
+		s.metrics.observeLogin(e.ConnectorID, false)
+		defer s.detectRepeatedLoginFailures(ctx, e)
+	}
+	if s.auditLog != nil {
+		s.recordAudit(ctx, e)
+	}
+	if dropped := s.events.publish(e); dropped > 0 {
+		s.logger.WarnContext(ctx, "dropped audit event for slow subscribers", "type", e.Type, "subscribers", dropped)
+	}
+}
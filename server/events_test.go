@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dexidp/dex/api/v2"
+)
+
+func TestEventBroker(t *testing.T) {
+	b := NewEventBroker()
+	require.Equal(t, 0, b.publish(auditEvent{Type: eventLoginFailed}))
+
+	events, unsubscribe := b.subscribe()
+	require.Equal(t, 0, b.publish(auditEvent{Type: eventLoginSucceeded}))
+	require.Equal(t, eventLoginSucceeded, (<-events).Type)
+
+	// Events are dropped rather than blocking when the queue is full.
+	for i := 0; i < eventSubscriberQueue; i++ {
+		require.Equal(t, 0, b.publish(auditEvent{Type: eventTokenIssued}))
+	}
+	require.Equal(t, 1, b.publish(auditEvent{Type: eventTokenIssued}))
+
+	unsubscribe()
+	require.Equal(t, 0, b.publish(auditEvent{Type: eventTokenIssued}))
+}
+
+func TestWatchEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, server := newTestServer(ctx, t, nil)
+	defer httpServer.Close()
+
+	client := newServerAPI(server.storage, logger, server, t)
+	defer client.Close()
+
+	subscribers := func() int {
+		server.events.mu.Lock()
+		defer server.events.mu.Unlock()
+		return len(server.events.subscribers)
+	}
+	// The webhook dispatcher is subscribed already.
+	before := subscribers()
+
+	stream, err := client.WatchEvents(ctx, &api.WatchEventsReq{Types: []string{eventClientModified}})
+	require.NoError(t, err)
+
+	// Wait for the subscription, since events aren't replayed.
+	require.Eventually(t, func() bool {
+		return subscribers() == before+1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Filtered out.
+	server.emitEvent(ctx, auditEvent{Type: eventLoginSucceeded, UserID: "1"})
+
+	_, err = client.CreateClient(ctx, &api.CreateClientReq{Client: &api.Client{Id: "app", Name: "App"}})
+	require.NoError(t, err)
+
+	e, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, eventClientModified, e.Type)
+	require.Equal(t, "app", e.ClientId)
+	require.Equal(t, server.issuerURL.String(), e.Issuer)
+	require.Equal(t, map[string]string{"operation": "create"}, e.Details)
+}
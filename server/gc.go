@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/dexidp/dex/storage"
+)
+
+// gcMetrics are the metrics of garbage collection runs.
+type gcMetrics struct {
+	deleted  *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+func newGCMetrics() *gcMetrics {
+	return &gcMetrics{
+		deleted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "dex_gc_deleted_total",
+			Help: "Number of expired objects deleted by garbage collection.",
+		}, []string{"resource"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "dex_gc_duration_seconds",
+			Help:    "Duration of garbage collection runs.",
+			Buckets: []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60},
+		}, []string{"outcome"}),
+	}
+}
+
+func (m *gcMetrics) observe(r storage.GCResult, err error, duration time.Duration) {
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	m.duration.WithLabelValues(outcome).Observe(duration.Seconds())
+
+	// Objects are deleted even if a run fails part way through.
+	m.deleted.WithLabelValues(string(storage.GCAuthRequests)).Add(float64(r.AuthRequests))
+	m.deleted.WithLabelValues(string(storage.GCAuthCodes)).Add(float64(r.AuthCodes))
+	m.deleted.WithLabelValues(string(storage.GCDeviceRequests)).Add(float64(r.DeviceRequests))
+	m.deleted.WithLabelValues(string(storage.GCDeviceTokens)).Add(float64(r.DeviceTokens))
+}
+
+// gcSchedule is a set of resources garbage collected at the same interval.
+type gcSchedule struct {
+	interval  time.Duration
+	resources []storage.GCResource
+}
+
+// gcSchedules groups the resources by the interval they are garbage
+// collected at, which is frequency unless it is overridden by intervals.
+func gcSchedules(frequency time.Duration, intervals map[storage.GCResource]time.Duration) []gcSchedule {
+	var schedules []gcSchedule
+	for _, resource := range storage.GCResources {
+		interval := frequency
+		if i := intervals[resource]; i > 0 {
+			interval = i
+		}
+		n := slices.IndexFunc(schedules, func(s gcSchedule) bool { return s.interval == interval })
+		if n < 0 {
+			schedules = append(schedules, gcSchedule{interval: interval})
+			n = len(schedules) - 1
+		}
+		schedules[n].resources = append(schedules[n].resources, resource)
+	}
+	return schedules
+}
+
+// withJitter adds a random delay of up to jitter to an interval.
+func withJitter(interval, jitter time.Duration) time.Duration {
+	if jitter <= 0 {
+		return interval
+	}
+	return interval + rand.N(jitter)
+}
+
+func (s *Server) startGarbageCollection(ctx context.Context, schedules []gcSchedule, jitter time.Duration, now func() time.Time) {
+	for _, schedule := range schedules {
+		opts := storage.GCOptions{
+			Resources: schedule.resources,
+			BatchSize: s.gcBatchSize,
+		}
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(withJitter(schedule.interval, jitter)):
+					if !s.isLeader() {
+						continue
+					}
+					if r, err := s.garbageCollect(now(), opts); err != nil {
+						s.logger.ErrorContext(ctx, "garbage collection failed", "err", err)
+					} else if !r.IsEmpty() {
+						s.logger.InfoContext(ctx, "garbage collection run, delete auth",
+							"requests", r.AuthRequests, "auth_codes", r.AuthCodes,
+							"device_requests", r.DeviceRequests, "device_tokens", r.DeviceTokens)
+					}
+				}
+			}
+		}()
+	}
+}
+
+// garbageCollect deletes the objects which expired before now and records
+// the metrics of the run.
+func (s *Server) garbageCollect(now time.Time, opts storage.GCOptions) (storage.GCResult, error) {
+	start := time.Now()
+	r, err := s.storage.GarbageCollect(now, opts)
+	if s.gcMetrics != nil {
+		s.gcMetrics.observe(r, err, time.Since(start))
+	}
+	return r, err
+}
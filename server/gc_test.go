@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dexidp/dex/storage"
+	"github.com/dexidp/dex/storage/memory"
+)
+
+func TestGCSchedules(t *testing.T) {
+	schedules := gcSchedules(5*time.Minute, nil)
+	require.Equal(t, []gcSchedule{{5 * time.Minute, storage.GCResources}}, schedules)
+
+	schedules = gcSchedules(5*time.Minute, map[storage.GCResource]time.Duration{
+		storage.GCDeviceTokens: time.Minute,
+		storage.GCAuthCodes:    time.Minute,
+	})
+	require.Equal(t, []gcSchedule{
+		{5 * time.Minute, []storage.GCResource{storage.GCAuthRequests, storage.GCDeviceRequests}},
+		{time.Minute, []storage.GCResource{storage.GCAuthCodes, storage.GCDeviceTokens}},
+	}, schedules)
+}
+
+func TestWithJitter(t *testing.T) {
+	require.Equal(t, time.Minute, withJitter(time.Minute, 0))
+	for i := 0; i < 100; i++ {
+		d := withJitter(time.Minute, 10*time.Second)
+		require.GreaterOrEqual(t, d, time.Minute)
+		require.Less(t, d, time.Minute+10*time.Second)
+	}
+}
+
+func TestGarbageCollectMetrics(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New(logger)
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, s.CreateDeviceToken(ctx, storage.DeviceToken{
+			DeviceCode: storage.NewID(),
+			Status:     "pending",
+			Expiry:     now.Add(-time.Minute),
+		}))
+	}
+	require.NoError(t, s.CreateAuthRequest(ctx, storage.AuthRequest{
+		ID:     storage.NewID(),
+		Expiry: now.Add(-time.Minute),
+	}))
+
+	server := &Server{storage: s, gcMetrics: newGCMetrics()}
+	r, err := server.garbageCollect(now, storage.GCOptions{Resources: []storage.GCResource{storage.GCDeviceTokens}})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), r.DeviceTokens)
+	require.Equal(t, int64(0), r.AuthRequests)
+
+	require.Equal(t, 2.0, testutil.ToFloat64(server.gcMetrics.deleted.WithLabelValues("deviceTokens")))
+	require.Equal(t, 0.0, testutil.ToFloat64(server.gcMetrics.deleted.WithLabelValues("authRequests")))
+	require.Equal(t, 1, testutil.CollectAndCount(server.gcMetrics.duration))
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/dexidp/dex/storage"
+)
+
+// groupsMatcher decides which groups are released to a client.
+type groupsMatcher struct {
+	allowed  map[string]bool
+	prefixes []string
+	patterns []*regexp.Regexp
+}
+
+func newGroupsMatcher(filter *storage.GroupsFilter) (*groupsMatcher, error) {
+	m := &groupsMatcher{
+		allowed:  make(map[string]bool, len(filter.AllowedGroups)),
+		prefixes: filter.Prefixes,
+	}
+	for _, group := range filter.AllowedGroups {
+		m.allowed[group] = true
+	}
+	for _, pattern := range filter.Patterns {
+		// Patterns have to match the whole group name.
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid groups pattern %q: %v", pattern, err)
+		}
+		m.patterns = append(m.patterns, re)
+	}
+	return m, nil
+}
+
+func (m *groupsMatcher) match(group string) bool {
+	if m.allowed[group] {
+		return true
+	}
+	for _, prefix := range m.prefixes {
+		if strings.HasPrefix(group, prefix) {
+			return true
+		}
+	}
+	for _, re := range m.patterns {
+		if re.MatchString(group) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateGroupsFilter checks the groups filter of a client.
+func ValidateGroupsFilter(filter *storage.GroupsFilter) error {
+	if filter == nil {
+		return nil
+	}
+	_, err := newGroupsMatcher(filter)
+	return err
+}
+
+// filterClientGroups returns the groups which may be released to a client.
+// Clients without a groups filter get all groups. If the filter is invalid
+// no groups are released.
+func (s *Server) filterClientGroups(ctx context.Context, client *storage.Client, groups []string) []string {
+	if client == nil || client.GroupsFilter == nil || len(groups) == 0 {
+		return groups
+	}
+
+	m, err := newGroupsMatcher(client.GroupsFilter)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "invalid client groups filter, releasing no groups", "client_id", client.ID, "err", err)
+		return nil
+	}
+
+	filtered := make([]string, 0, len(groups))
+	for _, group := range groups {
+		if m.match(group) {
+			filtered = append(filtered, group)
+		}
+	}
+	return filtered
+}
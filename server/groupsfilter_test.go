@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dexidp/dex/storage"
+)
+
+func TestFilterClientGroups(t *testing.T) {
+	ctx := context.Background()
+	s := &Server{logger: logger}
+
+	groups := []string{"admins", "app-read", "app-write", "team-a", "team-b2", "users"}
+
+	tests := []struct {
+		name   string
+		client *storage.Client
+		want   []string
+	}{
+		{
+			name: "no client",
+			want: groups,
+		},
+		{
+			name:   "no filter",
+			client: &storage.Client{ID: "c"},
+			want:   groups,
+		},
+		{
+			name:   "allowed groups",
+			client: &storage.Client{ID: "c", GroupsFilter: &storage.GroupsFilter{AllowedGroups: []string{"admins", "missing"}}},
+			want:   []string{"admins"},
+		},
+		{
+			name:   "prefixes",
+			client: &storage.Client{ID: "c", GroupsFilter: &storage.GroupsFilter{Prefixes: []string{"app-"}}},
+			want:   []string{"app-read", "app-write"},
+		},
+		{
+			name:   "patterns match whole groups",
+			client: &storage.Client{ID: "c", GroupsFilter: &storage.GroupsFilter{Patterns: []string{"team-[a-z]", "user"}}},
+			want:   []string{"team-a"},
+		},
+		{
+			name: "combined",
+			client: &storage.Client{ID: "c", GroupsFilter: &storage.GroupsFilter{
+				AllowedGroups: []string{"users"},
+				Prefixes:      []string{"app-w"},
+				Patterns:      []string{"team-.*"},
+			}},
+			want: []string{"app-write", "team-a", "team-b2", "users"},
+		},
+		{
+			name:   "invalid pattern",
+			client: &storage.Client{ID: "c", GroupsFilter: &storage.GroupsFilter{Patterns: []string{"("}}},
+			want:   nil,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, s.filterClientGroups(ctx, tc.client, groups))
+		})
+	}
+}
+
+func TestValidateGroupsFilter(t *testing.T) {
+	require.NoError(t, ValidateGroupsFilter(nil))
+	require.NoError(t, ValidateGroupsFilter(&storage.GroupsFilter{Patterns: []string{"team-.*"}}))
+	require.Error(t, ValidateGroupsFilter(&storage.GroupsFilter{Patterns: []string{"team-("}}))
+}
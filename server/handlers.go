@@ -4,14 +4,17 @@ import (
 	"context"
 	"crypto/hmac"
 	"crypto/sha256"
-	"crypto/subtle"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"hash/crc32"
 	"html/template"
+	"maps"
 	"net/http"
 	"net/url"
 	"path"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -22,6 +25,7 @@ import (
 	"github.com/gorilla/mux"
 
 	"github.com/dexidp/dex/connector"
+	"github.com/dexidp/dex/connector/middleware"
 	"github.com/dexidp/dex/server/internal"
 	"github.com/dexidp/dex/storage"
 )
@@ -31,27 +35,31 @@ const (
 	codeChallengeMethodS256  = "S256"
 )
 
+// rotationScheduler is implemented by signers which know when their key set changes next.
+type rotationScheduler interface {
+	NextRotation(ctx context.Context) (time.Time, error)
+}
+
 func (s *Server) handlePublicKeys(w http.ResponseWriter, r *http.Request) {
 	// TODO(ericchiang): Cache this.
-	keys, err := s.storage.GetKeys()
+	keys, err := s.signer.ValidationKeys(r.Context())
 	if err != nil {
 		s.logger.ErrorContext(r.Context(), "failed to get keys", "err", err)
 		s.renderError(r, w, http.StatusInternalServerError, "Internal server error.")
 		return
 	}
 
-	if keys.SigningKeyPub == nil {
+	if len(keys) == 0 {
 		s.logger.ErrorContext(r.Context(), "no public keys found.")
 		s.renderError(r, w, http.StatusInternalServerError, "Internal server error.")
 		return
 	}
 
 	jwks := jose.JSONWebKeySet{
-		Keys: make([]jose.JSONWebKey, len(keys.VerificationKeys)+1),
+		Keys: make([]jose.JSONWebKey, len(keys)),
 	}
-	jwks.Keys[0] = *keys.SigningKeyPub
-	for i, verificationKey := range keys.VerificationKeys {
-		jwks.Keys[i+1] = *verificationKey.PublicKey
+	for i, key := range keys {
+		jwks.Keys[i] = *key
 	}
 
 	data, err := json.MarshalIndent(jwks, "", "  ")
@@ -60,7 +68,16 @@ func (s *Server) handlePublicKeys(w http.ResponseWriter, r *http.Request) {
 		s.renderError(r, w, http.StatusInternalServerError, "Internal server error.")
 		return
 	}
-	maxAge := keys.NextRotation.Sub(s.now())
+
+	var maxAge time.Duration
+	if scheduler, ok := s.signer.(rotationScheduler); ok {
+		nextRotation, err := scheduler.NextRotation(r.Context())
+		if err != nil {
+			s.logger.ErrorContext(r.Context(), "failed to get next key rotation", "err", err)
+		} else {
+			maxAge = nextRotation.Sub(s.now())
+		}
+	}
 	if maxAge < (time.Minute * 2) {
 		maxAge = time.Minute * 2
 	}
@@ -87,24 +104,114 @@ type discovery struct {
 	Scopes            []string `json:"scopes_supported"`
 	AuthMethods       []string `json:"token_endpoint_auth_methods_supported"`
 	Claims            []string `json:"claims_supported"`
+	ClaimsParameter   bool     `json:"claims_parameter_supported"`
+	AuthResponseIss   bool     `json:"authorization_response_iss_parameter_supported"`
+	PromptValues      []string `json:"prompt_values_supported,omitempty"`
 }
 
-func (s *Server) discoveryHandler() (http.HandlerFunc, error) {
-	d := s.constructDiscovery()
+func (s *Server) discoveryHandler(ctx context.Context) (http.HandlerFunc, error) {
+	d := s.constructDiscovery(ctx)
 
 	data, err := json.MarshalIndent(d, "", "  ")
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal discovery data: %v", err)
 	}
+	if len(s.discoveryFields) > 0 {
+		if data, err = withDiscoveryFields(data, s.discoveryFields); err != nil {
+			return nil, fmt.Errorf("failed to marshal discovery data: %v", err)
+		}
+	}
+	return staticJSONHandler(data), nil
+}
+
+// withDiscoveryFields adds fields to a discovery document, replacing the
+// generated ones. Fields set to null are removed.
+func withDiscoveryFields(data []byte, fields map[string]interface{}) ([]byte, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	for name, value := range fields {
+		if value == nil {
+			delete(doc, name)
+			continue
+		}
+		doc[name] = value
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
 
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+// validateDiscoveryFields checks fields configured to be added to the
+// discovery document.
+func validateDiscoveryFields(fields map[string]interface{}) error {
+	if _, ok := fields["issuer"]; ok {
+		return fmt.Errorf("the issuer of the discovery document can't be overridden")
+	}
+	if _, err := json.Marshal(fields); err != nil {
+		return fmt.Errorf("invalid discovery fields: %v", err)
+	}
+	return nil
+}
+
+func staticJSONHandler(data []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
 		w.Write(data)
-	}), nil
+	}
+}
+
+// authorizationServerMetadataPath is the well-known path of the OAuth 2.0
+// Authorization Server Metadata document.
+const authorizationServerMetadataPath = "/.well-known/oauth-authorization-server"
+
+// authorizationServerMetadata is the OAuth 2.0 Authorization Server Metadata
+// document defined by RFC 8414, for clients which don't use OpenID Connect
+// discovery.
+type authorizationServerMetadata struct {
+	Issuer                string   `json:"issuer"`
+	Auth                  string   `json:"authorization_endpoint"`
+	Token                 string   `json:"token_endpoint"`
+	Keys                  string   `json:"jwks_uri"`
+	DeviceEndpoint        string   `json:"device_authorization_endpoint"`
+	Introspect            string   `json:"introspection_endpoint"`
+	GrantTypes            []string `json:"grant_types_supported"`
+	ResponseTypes         []string `json:"response_types_supported"`
+	CodeChallengeAlgs     []string `json:"code_challenge_methods_supported"`
+	Scopes                []string `json:"scopes_supported"`
+	AuthMethods           []string `json:"token_endpoint_auth_methods_supported"`
+	IntrospectAuthMethods []string `json:"introspection_endpoint_auth_methods_supported"`
+	AuthResponseIss       bool     `json:"authorization_response_iss_parameter_supported"`
 }
 
-func (s *Server) constructDiscovery() discovery {
+func (s *Server) authorizationServerMetadataHandler(ctx context.Context) (http.HandlerFunc, error) {
+	d := s.constructDiscovery(ctx)
+	m := authorizationServerMetadata{
+		Issuer:            d.Issuer,
+		Auth:              d.Auth,
+		Token:             d.Token,
+		Keys:              d.Keys,
+		DeviceEndpoint:    d.DeviceEndpoint,
+		Introspect:        d.Introspect,
+		GrantTypes:        d.GrantTypes,
+		ResponseTypes:     d.ResponseTypes,
+		CodeChallengeAlgs: d.CodeChallengeAlgs,
+		Scopes:            d.Scopes,
+		AuthMethods:       d.AuthMethods,
+		AuthResponseIss:   d.AuthResponseIss,
+		// Tokens are introspected by presenting them, no client
+		// authentication is required.
+		IntrospectAuthMethods: []string{"none"},
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal authorization server metadata: %v", err)
+	}
+	return staticJSONHandler(data), nil
+}
+
+func (s *Server) constructDiscovery(ctx context.Context) discovery {
 	d := discovery{
 		Issuer:            s.issuerURL.String(),
 		Auth:              s.absURL("/auth"),
@@ -118,17 +225,39 @@ func (s *Server) constructDiscovery() discovery {
 		CodeChallengeAlgs: []string{codeChallengeMethodS256, codeChallengeMethodPlain},
 		Scopes:            []string{"openid", "email", "groups", "profile", "offline_access"},
 		AuthMethods:       []string{"client_secret_basic", "client_secret_post"},
+		ClaimsParameter:   true,
+		AuthResponseIss:   true,
 		Claims: []string{
 			"iss", "sub", "aud", "iat", "exp", "email", "email_verified",
 			"locale", "name", "preferred_username", "at_hash",
 		},
 	}
 
+	// Advertise the algorithm of the configured signer. Fall back to RS256,
+	// which OIDC mandates, if the keys aren't available yet.
+	if alg, err := s.signer.Algorithm(ctx); err == nil {
+		d.IDTokenAlgs = []string{string(alg)}
+	}
+
 	for responseType := range s.supportedResponseTypes {
 		d.ResponseTypes = append(d.ResponseTypes, responseType)
 	}
 	sort.Strings(d.ResponseTypes)
 
+	for _, name := range slices.Sorted(maps.Keys(s.customScopes)) {
+		d.Scopes = append(d.Scopes, name)
+		for _, claim := range s.customScopes[name].Claims {
+			if !slices.Contains(d.Claims, claim) {
+				d.Claims = append(d.Claims, claim)
+			}
+		}
+	}
+
+	// prompt=create leads to the registration form.
+	if s.registration != nil {
+		d.PromptValues = []string{"create"}
+	}
+
 	d.GrantTypes = s.supportedGrantTypes
 	return d
 }
@@ -139,7 +268,7 @@ func (s *Server) handleAuthorization(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
 		s.logger.ErrorContext(r.Context(), "failed to parse arguments", "err", err)
 
-		s.renderError(r, w, http.StatusBadRequest, err.Error())
+		s.renderError(r, w, http.StatusBadRequest, "Invalid request")
 		return
 	}
 
@@ -155,11 +284,47 @@ func (s *Server) handleAuthorization(w http.ResponseWriter, r *http.Request) {
 	// We don't need connector_id any more
 	r.Form.Del("connector_id")
 
+	// Fields of the home realm discovery page.
+	email := r.PostForm.Get("email")
+	showConnectors := r.Form.Get("show_connectors") == "true"
+	if s.homeRealms != nil {
+		r.Form.Del("email")
+		r.Form.Del("show_connectors")
+	}
+
 	// Construct a URL with all of the arguments in its query
 	connURL := url.URL{
 		RawQuery: r.Form.Encode(),
 	}
 
+	// Users asked to sign up go to the local password database, unless the
+	// client chose a connector.
+	if connectorID == "" && s.promptCreate(r.Form) && slices.ContainsFunc(connectors, func(c storage.Connector) bool {
+		return c.ID == LocalConnector
+	}) {
+		connectorID = LocalConnector
+	}
+
+	// Ask for the e-mail address of the user, unless the client passed it as
+	// login_hint, and use the connector of its domain. Users of other domains
+	// choose a connector.
+	if s.homeRealms != nil && connectorID == "" && !showConnectors {
+		if email == "" {
+			email = r.Form.Get("login_hint")
+		}
+		if email == "" {
+			postURL := url.URL{Path: s.absPath("/auth"), RawQuery: connURL.RawQuery}
+			connectorsQuery := maps.Clone(r.Form)
+			connectorsQuery.Set("show_connectors", "true")
+			connectorsURL := url.URL{Path: s.absPath("/auth"), RawQuery: connectorsQuery.Encode()}
+			if err := s.templates.forClient(r.Form.Get("client_id")).homeRealm(r, w, postURL.String(), connectorsURL.String(), s.cancelLink(r)); err != nil {
+				s.logger.ErrorContext(r.Context(), "server template error", "err", err)
+			}
+			return
+		}
+		connectorID, _ = s.homeRealms.connectorFor(email)
+	}
+
 	// Redirect if a client chooses a specific connector_id
 	if connectorID != "" {
 		for _, c := range connectors {
@@ -173,13 +338,22 @@ func (s *Server) handleAuthorization(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(connectors) == 1 && !s.alwaysShowLogin {
-		connURL.Path = s.absPath("/auth", url.PathEscape(connectors[0].ID))
+	// Hidden connectors can only be chosen with connector_id.
+	visible := make([]storage.Connector, 0, len(connectors))
+	for _, conn := range connectors {
+		if conn.Display == nil || !conn.Display.Hidden {
+			visible = append(visible, conn)
+		}
+	}
+
+	if len(visible) == 1 && !s.alwaysShowLogin {
+		connURL.Path = s.absPath("/auth", url.PathEscape(visible[0].ID))
 		http.Redirect(w, r, connURL.String(), http.StatusFound)
+		return
 	}
 
-	connectorInfos := make([]connectorInfo, len(connectors))
-	for index, conn := range connectors {
+	connectorInfos := make([]connectorInfo, len(visible))
+	for index, conn := range visible {
 		connURL.Path = s.absPath("/auth", url.PathEscape(conn.ID))
 		connectorInfos[index] = connectorInfo{
 			ID:   conn.ID,
@@ -187,9 +361,19 @@ func (s *Server) handleAuthorization(w http.ResponseWriter, r *http.Request) {
 			Type: conn.Type,
 			URL:  template.URL(connURL.String()),
 		}
+		if d := conn.Display; d != nil {
+			connectorInfos[index].Order = d.Order
+			connectorInfos[index].Group = d.Group
+			// The API validates the display settings, but the storage may
+			// have been written to directly.
+			if ValidateConnectorDisplay(d) == nil {
+				connectorInfos[index].IconURL = template.URL(d.IconURL)
+				connectorInfos[index].ButtonColor = d.ButtonColor
+			}
+		}
 	}
 
-	if err := s.templates.login(r, w, connectorInfos); err != nil {
+	if err := s.templates.forClient(r.Form.Get("client_id")).login(r, w, connectorInfos, s.cancelLink(r)); err != nil {
 		s.logger.ErrorContext(r.Context(), "server template error", "err", err)
 	}
 }
@@ -238,7 +422,7 @@ func (s *Server) handleConnectorLogin(w http.ResponseWriter, r *http.Request) {
 
 	// Actually create the auth request
 	authReq.Expiry = s.now().Add(s.authRequestsValidFor)
-	if err := s.storage.CreateAuthRequest(ctx, *authReq); err != nil {
+	if err := s.createAuthRequest(ctx, authReq); err != nil {
 		s.logger.ErrorContext(r.Context(), "failed to create authorization request", "err", err)
 		s.renderError(r, w, http.StatusInternalServerError, "Failed to connect to the database.")
 		return
@@ -277,8 +461,17 @@ func (s *Server) handleConnectorLogin(w http.ResponseWriter, r *http.Request) {
 			q := loginURL.Query()
 			q.Set("state", authReq.ID)
 			q.Set("back", backLink)
+			// Show the login form in the language requested by the client.
+			if uiLocales := r.Form.Get("ui_locales"); uiLocales != "" {
+				q.Set("ui_locales", uiLocales)
+			}
 			loginURL.RawQuery = q.Encode()
 
+			// The registration form leads back to the login form.
+			if connID == LocalConnector && s.promptCreate(r.Form) {
+				http.Redirect(w, r, s.registrationURL(connID, &loginURL), http.StatusFound)
+				return
+			}
 			http.Redirect(w, r, loginURL.String(), http.StatusFound)
 		case connector.SAMLConnector:
 			action, value, err := conn.POSTData(scopes, authReq.ID)
@@ -322,7 +515,7 @@ func (s *Server) handlePasswordLogin(w http.ResponseWriter, r *http.Request) {
 
 	backLink := r.URL.Query().Get("back")
 
-	authReq, err := s.storage.GetAuthRequest(authID)
+	authReq, err := s.getAuthRequest(authID)
 	if err != nil {
 		if err == storage.ErrNotFound {
 			s.logger.ErrorContext(r.Context(), "invalid 'state' parameter provided", "err", err)
@@ -359,43 +552,78 @@ func (s *Server) handlePasswordLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	switch r.Method {
-	case http.MethodGet:
-		if err := s.templates.password(r, w, r.URL.String(), "", usernamePrompt(pwConn), false, backLink); err != nil {
+	renderPassword := func(username string, invalid, failedCaptcha bool) {
+		challenge, err := s.captchaChallenge(r.Context(), captchaKeys(r, username), failedCaptcha)
+		if err != nil {
+			s.logger.ErrorContext(r.Context(), "failed to create captcha challenge", "err", err)
+			s.renderError(r, w, http.StatusInternalServerError, "Internal server error.")
+			return
+		}
+		if err := s.templates.forClient(authReq.ClientID).password(r, w, r.URL.String(), username, usernamePrompt(pwConn), invalid, backLink, s.passwordResetURL(authReq.ConnectorID, r.URL), s.registrationURL(authReq.ConnectorID, r.URL), challenge, s.cancelLink(r), s.csrfToken(w, r)); err != nil {
 			s.logger.ErrorContext(r.Context(), "server template error", "err", err)
 		}
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		renderPassword("", false, false)
 	case http.MethodPost:
+		if !s.checkCSRF(w, r) {
+			return
+		}
 		username := r.FormValue("login")
 		password := r.FormValue("password")
 		scopes := parseScopes(authReq.Scopes)
 
-		identity, ok, err := pwConn.Login(r.Context(), scopes, username, password)
+		if s.captcha != nil && s.captcha.required(captchaKeys(r, username)) {
+			if err := s.verifyCaptcha(r); err != nil {
+				s.logger.InfoContext(r.Context(), "captcha verification failed", "err", err)
+				renderPassword(username, false, true)
+				return
+			}
+		}
+
+		ctx, done := s.startConnectorOperation(r.Context(), authReq.ConnectorID, "Login")
+		identity, ok, err := pwConn.Login(ctx, scopes, username, password)
+		done(err)
+		if err != nil || !ok {
+			s.emitEvent(r.Context(), auditEvent{
+				Type:        eventLoginFailed,
+				ClientID:    authReq.ClientID,
+				ConnectorID: authReq.ConnectorID,
+				Username:    username,
+				Scopes:      authReq.Scopes,
+			})
+		}
 		if err != nil {
+			if reason, ok := loginDenied(err); ok {
+				s.logger.InfoContext(r.Context(), "login denied", "user", username, "err", err)
+				s.renderError(r, w, http.StatusForbidden, reason)
+				return
+			}
 			s.logger.ErrorContext(r.Context(), "failed to login user", "err", err)
-			s.renderError(r, w, http.StatusInternalServerError, fmt.Sprintf("Login error: %v", err))
+			s.renderError(r, w, http.StatusInternalServerError, "Login error.")
 			return
 		}
 		if !ok {
-			if err := s.templates.password(r, w, r.URL.String(), username, usernamePrompt(pwConn), true, backLink); err != nil {
-				s.logger.ErrorContext(r.Context(), "server template error", "err", err)
+			if s.captcha != nil {
+				s.captcha.recordAttempt(captchaKeys(r, username))
 			}
+			renderPassword(username, true, false)
 			s.logger.ErrorContext(r.Context(), "failed login attempt: Invalid credentials.", "user", username)
 			return
 		}
-		redirectURL, canSkipApproval, err := s.finalizeLogin(r.Context(), identity, authReq, conn.Connector)
+		authReq, redirectURL, canSkipApproval, err := s.finalizeLogin(r.Context(), identity, authReq, conn.Connector)
 		if err != nil {
 			s.logger.ErrorContext(r.Context(), "failed to finalize login", "err", err)
 			s.renderError(r, w, http.StatusInternalServerError, "Login error.")
 			return
 		}
+		if authReq.ConnectorID == LocalConnector && !identity.EmailVerified {
+			s.sendVerificationEmail(r, identity)
+		}
 
 		if canSkipApproval {
-			authReq, err = s.storage.GetAuthRequest(authReq.ID)
-			if err != nil {
-				s.logger.ErrorContext(r.Context(), "failed to get finalized auth request", "err", err)
-				s.renderError(r, w, http.StatusInternalServerError, "Login error.")
-				return
-			}
 			s.sendCodeResponse(w, r, authReq)
 			return
 		}
@@ -425,7 +653,7 @@ func (s *Server) handleConnectorCallback(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	authReq, err := s.storage.GetAuthRequest(authID)
+	authReq, err := s.getAuthRequest(authID)
 	if err != nil {
 		if err == storage.ErrNotFound {
 			s.logger.ErrorContext(r.Context(), "invalid 'state' parameter provided", "err", err)
@@ -463,26 +691,41 @@ func (s *Server) handleConnectorCallback(w http.ResponseWriter, r *http.Request)
 			s.renderError(r, w, http.StatusBadRequest, "Invalid request")
 			return
 		}
-		identity, err = conn.HandleCallback(parseScopes(authReq.Scopes), r)
+		callbackCtx, done := s.startConnectorOperation(ctx, authReq.ConnectorID, "HandleCallback")
+		identity, err = conn.HandleCallback(parseScopes(authReq.Scopes), r.WithContext(callbackCtx))
+		done(err)
 	case connector.SAMLConnector:
 		if r.Method != http.MethodPost {
 			s.logger.ErrorContext(r.Context(), "OAuth2 request mapped to SAML connector")
 			s.renderError(r, w, http.StatusBadRequest, "Invalid request")
 			return
 		}
+		_, done := s.startConnectorOperation(ctx, authReq.ConnectorID, "HandlePOST")
 		identity, err = conn.HandlePOST(parseScopes(authReq.Scopes), r.PostFormValue("SAMLResponse"), authReq.ID)
+		done(err)
 	default:
 		s.renderError(r, w, http.StatusInternalServerError, "Requested resource does not exist.")
 		return
 	}
 
 	if err != nil {
+		s.emitEvent(ctx, auditEvent{
+			Type:        eventLoginFailed,
+			ClientID:    authReq.ClientID,
+			ConnectorID: authReq.ConnectorID,
+			Scopes:      authReq.Scopes,
+		})
+		if reason, ok := loginDenied(err); ok {
+			s.logger.InfoContext(r.Context(), "login denied by connector middleware", "err", err)
+			s.renderError(r, w, http.StatusForbidden, reason)
+			return
+		}
 		s.logger.ErrorContext(r.Context(), "failed to authenticate", "err", err)
-		s.renderError(r, w, http.StatusInternalServerError, fmt.Sprintf("Failed to authenticate: %v", err))
+		s.renderError(r, w, http.StatusInternalServerError, "Failed to authenticate.")
 		return
 	}
 
-	redirectURL, canSkipApproval, err := s.finalizeLogin(ctx, identity, authReq, conn.Connector)
+	authReq, redirectURL, canSkipApproval, err := s.finalizeLogin(ctx, identity, authReq, conn.Connector)
 	if err != nil {
 		s.logger.ErrorContext(r.Context(), "failed to finalize login", "err", err)
 		s.renderError(r, w, http.StatusInternalServerError, "Login error.")
@@ -490,12 +733,6 @@ func (s *Server) handleConnectorCallback(w http.ResponseWriter, r *http.Request)
 	}
 
 	if canSkipApproval {
-		authReq, err = s.storage.GetAuthRequest(authReq.ID)
-		if err != nil {
-			s.logger.ErrorContext(r.Context(), "failed to get finalized auth request", "err", err)
-			s.renderError(r, w, http.StatusInternalServerError, "Login error.")
-			return
-		}
 		s.sendCodeResponse(w, r, authReq)
 		return
 	}
@@ -504,8 +741,9 @@ func (s *Server) handleConnectorCallback(w http.ResponseWriter, r *http.Request)
 }
 
 // finalizeLogin associates the user's identity with the current AuthRequest, then returns
-// the approval page's path.
-func (s *Server) finalizeLogin(ctx context.Context, identity connector.Identity, authReq storage.AuthRequest, conn connector.Connector) (string, bool, error) {
+// the updated AuthRequest and the approval page's path.
+func (s *Server) finalizeLogin(ctx context.Context, identity connector.Identity, authReq storage.AuthRequest, conn connector.Connector) (storage.AuthRequest, string, bool, error) {
+	client := s.tokenClient(ctx, authReq.ClientID)
 	claims := storage.Claims{
 		UserID:            identity.UserID,
 		Username:          identity.Username,
@@ -513,6 +751,8 @@ func (s *Server) finalizeLogin(ctx context.Context, identity connector.Identity,
 		Email:             identity.Email,
 		EmailVerified:     identity.EmailVerified,
 		Groups:            identity.Groups,
+		CustomClaims:      identity.CustomClaims,
+		ClaimSources:      storageClaimSources(identity.ClaimSources, client != nil && client.ForwardClaimSourceTokens),
 	}
 
 	updater := func(a storage.AuthRequest) (storage.AuthRequest, error) {
@@ -521,8 +761,9 @@ func (s *Server) finalizeLogin(ctx context.Context, identity connector.Identity,
 		a.ConnectorData = identity.ConnectorData
 		return a, nil
 	}
-	if err := s.storage.UpdateAuthRequest(authReq.ID, updater); err != nil {
-		return "", false, fmt.Errorf("failed to update auth request: %v", err)
+	authReq, err := s.updateAuthRequest(authReq.ID, updater)
+	if err != nil {
+		return authReq, "", false, fmt.Errorf("failed to update auth request: %v", err)
 	}
 
 	email := claims.Email
@@ -533,6 +774,14 @@ func (s *Server) finalizeLogin(ctx context.Context, identity connector.Identity,
 	s.logger.InfoContext(ctx, "login successful",
 		"connector_id", authReq.ConnectorID, "username", claims.Username,
 		"preferred_username", claims.PreferredUsername, "email", email, "groups", claims.Groups)
+	s.emitEvent(ctx, auditEvent{
+		Type:        eventLoginSucceeded,
+		ClientID:    authReq.ClientID,
+		ConnectorID: authReq.ConnectorID,
+		UserID:      claims.UserID,
+		Username:    claims.Username,
+		Scopes:      authReq.Scopes,
+	})
 
 	offlineAccessRequested := false
 	for _, scope := range authReq.Scopes {
@@ -559,7 +808,7 @@ func (s *Server) finalizeLogin(ctx context.Context, identity connector.Identity,
 			// the newly received refreshtoken.
 			if err := s.storage.CreateOfflineSessions(ctx, offlineSessions); err != nil {
 				s.logger.ErrorContext(ctx, "failed to create offline session", "err", err)
-				return "", false, err
+				return authReq, "", false, err
 			}
 		case err == nil:
 			// Update existing OfflineSession obj with new RefreshTokenRef.
@@ -570,17 +819,17 @@ func (s *Server) finalizeLogin(ctx context.Context, identity connector.Identity,
 				return old, nil
 			}); err != nil {
 				s.logger.ErrorContext(ctx, "failed to update offline session", "err", err)
-				return "", false, err
+				return authReq, "", false, err
 			}
 		default:
 			s.logger.ErrorContext(ctx, "failed to get offline session", "err", err)
-			return "", false, err
+			return authReq, "", false, err
 		}
 	}
 
 	// we can skip the redirect to /approval and go ahead and send code if it's not required
 	if s.skipApproval && !authReq.ForceApprovalPrompt {
-		return "", true, nil
+		return authReq, "", true, nil
 	}
 
 	// an HMAC is used here to ensure that the request ID is unpredictable, ensuring that an attacker who intercepted the original
@@ -590,7 +839,7 @@ func (s *Server) finalizeLogin(ctx context.Context, identity connector.Identity,
 	mac := h.Sum(nil)
 
 	returnURL := path.Join(s.issuerURL.Path, "/approval") + "?req=" + authReq.ID + "&hmac=" + base64.RawURLEncoding.EncodeToString(mac)
-	return returnURL, false, nil
+	return authReq, returnURL, false, nil
 }
 
 func (s *Server) handleApproval(w http.ResponseWriter, r *http.Request) {
@@ -605,7 +854,7 @@ func (s *Server) handleApproval(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	authReq, err := s.storage.GetAuthRequest(r.FormValue("req"))
+	authReq, err := s.getAuthRequest(r.FormValue("req"))
 	if err != nil {
 		s.logger.ErrorContext(r.Context(), "failed to get auth request", "err", err)
 		s.renderError(r, w, http.StatusInternalServerError, "Database error.")
@@ -635,14 +884,30 @@ func (s *Server) handleApproval(w http.ResponseWriter, r *http.Request) {
 			s.renderError(r, w, http.StatusInternalServerError, "Failed to retrieve client.")
 			return
 		}
-		if err := s.templates.approval(r, w, authReq.ID, authReq.Claims.Username, client.Name, authReq.Scopes); err != nil {
+		claimsReq := decodeClaimsRequest(authReq.ClaimsRequest)
+		if err := s.templates.forClient(authReq.ClientID).approval(r, w, authReq.ID, authReq.Claims.Username, client, authReq.Scopes, claimsReq.claimDescriptions(), s.csrfToken(w, r)); err != nil {
 			s.logger.ErrorContext(r.Context(), "server template error", "err", err)
 		}
 	case http.MethodPost:
+		if !s.checkCSRF(w, r) {
+			return
+		}
+		consent := auditEvent{
+			Type:        eventConsentGranted,
+			ClientID:    authReq.ClientID,
+			ConnectorID: authReq.ConnectorID,
+			UserID:      authReq.Claims.UserID,
+			Username:    authReq.Claims.Username,
+			Scopes:      authReq.Scopes,
+		}
 		if r.FormValue("approval") != "approve" {
+			consent.Type = eventConsentDenied
+			s.emitEvent(r.Context(), consent)
+			s.metrics.observeApprovalDenial(authReq.ClientID)
 			s.renderError(r, w, http.StatusInternalServerError, "Approval rejected.")
 			return
 		}
+		s.emitEvent(r.Context(), consent)
 		s.sendCodeResponse(w, r, authReq)
 	}
 }
@@ -654,7 +919,7 @@ func (s *Server) sendCodeResponse(w http.ResponseWriter, r *http.Request, authRe
 		return
 	}
 
-	if err := s.storage.DeleteAuthRequest(authReq.ID); err != nil {
+	if err := s.deleteAuthRequest(ctx, authReq.ID); err != nil {
 		if err != storage.ErrNotFound {
 			s.logger.ErrorContext(r.Context(), "Failed to delete authorization request", "err", err)
 			s.renderError(r, w, http.StatusInternalServerError, "Internal server error.")
@@ -669,6 +934,22 @@ func (s *Server) sendCodeResponse(w http.ResponseWriter, r *http.Request, authRe
 		return
 	}
 
+	if s.authzPolicy != nil {
+		if err := s.authorizeByPolicy(ctx, authReq); err != nil {
+			var denied *tokenDeniedError
+			switch {
+			case !errors.As(err, &denied):
+				s.renderError(r, w, http.StatusInternalServerError, "Internal server error.")
+			case authReq.RedirectURI == redirectURIOOB:
+				s.renderError(r, w, http.StatusForbidden, "Access denied.")
+			default:
+				err := &redirectedAuthErr{authReq.State, authReq.RedirectURI, errAccessDenied, denied.reason, s.issuerURL.String()}
+				err.Handler().ServeHTTP(w, r)
+			}
+			return
+		}
+	}
+
 	var (
 		// Was the initial request using the implicit or hybrid flow instead of
 		// the "normal" code flow?
@@ -679,8 +960,8 @@ func (s *Server) sendCodeResponse(w http.ResponseWriter, r *http.Request, authRe
 
 		// ID token returned immediately if the response_type includes "id_token".
 		// Only valid for implicit and hybrid flows.
-		idToken       string
-		idTokenExpiry time.Time
+		idToken           string
+		accessTokenExpiry time.Time
 
 		// Access token
 		accessToken string
@@ -695,6 +976,7 @@ func (s *Server) sendCodeResponse(w http.ResponseWriter, r *http.Request, authRe
 				ConnectorID:   authReq.ConnectorID,
 				Nonce:         authReq.Nonce,
 				Scopes:        authReq.Scopes,
+				ClaimsRequest: authReq.ClaimsRequest,
 				Claims:        authReq.Claims,
 				Expiry:        s.now().Add(time.Minute * 30),
 				RedirectURI:   authReq.RedirectURI,
@@ -710,7 +992,7 @@ func (s *Server) sendCodeResponse(w http.ResponseWriter, r *http.Request, authRe
 			// Implicit and hybrid flows that try to use the OOB redirect URI are
 			// rejected earlier. If we got here we're using the code flow.
 			if authReq.RedirectURI == redirectURIOOB {
-				if err := s.templates.oob(r, w, code.ID); err != nil {
+				if err := s.templates.forClient(authReq.ClientID).oob(r, w, code.ID); err != nil {
 					s.logger.ErrorContext(r.Context(), "server template error", "err", err)
 				}
 				return
@@ -721,17 +1003,18 @@ func (s *Server) sendCodeResponse(w http.ResponseWriter, r *http.Request, authRe
 			implicitOrHybrid = true
 			var err error
 
-			accessToken, _, err = s.newAccessToken(r.Context(), authReq.ClientID, authReq.Claims, authReq.Scopes, authReq.Nonce, authReq.ConnectorID)
+			claimsReq := decodeClaimsRequest(authReq.ClaimsRequest)
+			accessToken, accessTokenExpiry, err = s.newAccessToken(r.Context(), authReq.ClientID, authReq.Claims, authReq.Scopes, claimsReq.UserInfo, authReq.Nonce, authReq.ConnectorID)
 			if err != nil {
 				s.logger.ErrorContext(r.Context(), "failed to create new access token", "err", err)
-				s.tokenErrHelper(w, errServerError, "", http.StatusInternalServerError)
+				s.tokenIssueErrHelper(w, err)
 				return
 			}
 
-			idToken, idTokenExpiry, err = s.newIDToken(r.Context(), authReq.ClientID, authReq.Claims, authReq.Scopes, authReq.Nonce, accessToken, code.ID, authReq.ConnectorID)
+			idToken, _, err = s.newIDToken(r.Context(), authReq.ClientID, authReq.Claims, authReq.Scopes, claimsReq.IDToken, authReq.Nonce, accessToken, code.ID, authReq.ConnectorID)
 			if err != nil {
 				s.logger.ErrorContext(r.Context(), "failed to create ID token", "err", err)
-				s.tokenErrHelper(w, errServerError, "", http.StatusInternalServerError)
+				s.tokenIssueErrHelper(w, err)
 				return
 			}
 		}
@@ -742,6 +1025,7 @@ func (s *Server) sendCodeResponse(w http.ResponseWriter, r *http.Request, authRe
 		v.Set("access_token", accessToken)
 		v.Set("token_type", "bearer")
 		v.Set("state", authReq.State)
+		v.Set("iss", s.issuerURL.String())
 		if idToken != "" {
 			v.Set("id_token", idToken)
 			// The hybrid flow with only "code token" or "code id_token" doesn't return an
@@ -750,7 +1034,7 @@ func (s *Server) sendCodeResponse(w http.ResponseWriter, r *http.Request, authRe
 			//
 			// https://openid.net/specs/openid-connect-core-1_0.html#HybridAuthResponse
 			if code.ID == "" {
-				v.Set("expires_in", strconv.Itoa(int(idTokenExpiry.Sub(s.now()).Seconds())))
+				v.Set("expires_in", strconv.Itoa(int(accessTokenExpiry.Sub(s.now()).Seconds())))
 			}
 		}
 		if code.ID != "" {
@@ -766,6 +1050,7 @@ func (s *Server) sendCodeResponse(w http.ResponseWriter, r *http.Request, authRe
 		//     &id_token=eyJ0 ... NiJ9.eyJ1c ... I6IjIifX0.DeWt4Qu ... ZXso
 		//     &expires_in=3600
 		//     &state=af0ifjsldkj
+		//     &iss=https%3A%2F%2Fdex.example.com
 		//
 		u.Fragment = v.Encode()
 	} else {
@@ -775,10 +1060,14 @@ func (s *Server) sendCodeResponse(w http.ResponseWriter, r *http.Request, authRe
 		//   Location: https://client.example.org/cb?
 		//     code=SplxlOBeZQQYbYS6WxSbIA
 		//     &state=af0ifjsldkj
+		//     &iss=https%3A%2F%2Fdex.example.com
 		//
+		// The issuer identifies Dex to clients talking to several authorization
+		// servers, protecting them from mix-up attacks (RFC 9207).
 		q := u.Query()
 		q.Set("code", code.ID)
 		q.Set("state", authReq.State)
+		q.Set("iss", s.issuerURL.String())
 		u.RawQuery = q.Encode()
 	}
 
@@ -813,7 +1102,7 @@ func (s *Server) withClientFromStorage(w http.ResponseWriter, r *http.Request, h
 		return
 	}
 
-	if subtle.ConstantTimeCompare([]byte(client.Secret), []byte(clientSecret)) != 1 {
+	if !s.validClientSecret(client, clientSecret) {
 		if clientSecret == "" {
 			s.logger.InfoContext(r.Context(), "missing client_secret on token request", "client_id", client.ID)
 		} else {
@@ -822,6 +1111,7 @@ func (s *Server) withClientFromStorage(w http.ResponseWriter, r *http.Request, h
 		s.tokenErrHelper(w, errInvalidClient, "Invalid client credentials.", http.StatusUnauthorized)
 		return
 	}
+	s.recordClientUse(r.Context(), client)
 
 	handler(w, r, client)
 }
@@ -846,6 +1136,14 @@ func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
 		s.tokenErrHelper(w, errUnsupportedGrantType, "", http.StatusBadRequest)
 		return
 	}
+	sw := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	defer func() {
+		if sw.status == http.StatusOK {
+			s.metrics.observeTokenGrant(grantType)
+		}
+	}()
+	w = sw
+
 	switch grantType {
 	case grantTypeDeviceCode:
 		s.handleDeviceToken(w, r)
@@ -936,17 +1234,18 @@ func (s *Server) handleAuthCode(w http.ResponseWriter, r *http.Request, client s
 }
 
 func (s *Server) exchangeAuthCode(ctx context.Context, w http.ResponseWriter, authCode storage.AuthCode, client storage.Client) (*accessTokenResponse, error) {
-	accessToken, _, err := s.newAccessToken(ctx, client.ID, authCode.Claims, authCode.Scopes, authCode.Nonce, authCode.ConnectorID)
+	claimsReq := decodeClaimsRequest(authCode.ClaimsRequest)
+	accessToken, expiry, err := s.newAccessToken(ctx, client.ID, authCode.Claims, authCode.Scopes, claimsReq.UserInfo, authCode.Nonce, authCode.ConnectorID)
 	if err != nil {
 		s.logger.ErrorContext(ctx, "failed to create new access token", "err", err)
-		s.tokenErrHelper(w, errServerError, "", http.StatusInternalServerError)
+		s.tokenIssueErrHelper(w, err)
 		return nil, err
 	}
 
-	idToken, expiry, err := s.newIDToken(ctx, client.ID, authCode.Claims, authCode.Scopes, authCode.Nonce, accessToken, authCode.ID, authCode.ConnectorID)
+	idToken, _, err := s.newIDToken(ctx, client.ID, authCode.Claims, authCode.Scopes, claimsReq.IDToken, authCode.Nonce, accessToken, authCode.ID, authCode.ConnectorID)
 	if err != nil {
 		s.logger.ErrorContext(ctx, "failed to create ID token", "err", err)
-		s.tokenErrHelper(w, errServerError, "", http.StatusInternalServerError)
+		s.tokenIssueErrHelper(w, err)
 		return nil, err
 	}
 
@@ -987,6 +1286,7 @@ func (s *Server) exchangeAuthCode(ctx context.Context, w http.ResponseWriter, au
 			ClientID:      authCode.ClientID,
 			ConnectorID:   authCode.ConnectorID,
 			Scopes:        authCode.Scopes,
+			ClaimsRequest: authCode.ClaimsRequest,
 			Claims:        authCode.Claims,
 			Nonce:         authCode.Nonce,
 			ConnectorData: authCode.ConnectorData,
@@ -1044,7 +1344,7 @@ func (s *Server) exchangeAuthCode(ctx context.Context, w http.ResponseWriter, au
 				ConnID:  refresh.ConnectorID,
 				Refresh: make(map[string]*storage.RefreshTokenRef),
 			}
-			offlineSessions.Refresh[tokenRef.ClientID] = &tokenRef
+			offlineSessions.Refresh[s.refreshTokenRefKey(tokenRef)] = &tokenRef
 
 			// Create a new OfflineSession object for the user and add a reference object for
 			// the newly received refreshtoken.
@@ -1055,9 +1355,10 @@ func (s *Server) exchangeAuthCode(ctx context.Context, w http.ResponseWriter, au
 				return nil, err
 			}
 		} else {
-			if oldTokenRef, ok := session.Refresh[tokenRef.ClientID]; ok {
+			evicted := s.evictedRefreshTokenRefs(session, tokenRef.ClientID)
+			for _, key := range evicted {
 				// Delete old refresh token from storage.
-				if err := s.storage.DeleteRefresh(oldTokenRef.ID); err != nil && err != storage.ErrNotFound {
+				if err := s.storage.DeleteRefresh(session.Refresh[key].ID); err != nil && err != storage.ErrNotFound {
 					s.logger.ErrorContext(ctx, "failed to delete refresh token", "err", err)
 					s.tokenErrHelper(w, errServerError, "", http.StatusInternalServerError)
 					deleteToken = true
@@ -1067,7 +1368,10 @@ func (s *Server) exchangeAuthCode(ctx context.Context, w http.ResponseWriter, au
 
 			// Update existing OfflineSession obj with new RefreshTokenRef.
 			if err := s.storage.UpdateOfflineSessions(session.UserID, session.ConnID, func(old storage.OfflineSessions) (storage.OfflineSessions, error) {
-				old.Refresh[tokenRef.ClientID] = &tokenRef
+				for _, key := range evicted {
+					delete(old.Refresh, key)
+				}
+				old.Refresh[s.refreshTokenRefKey(tokenRef)] = &tokenRef
 				return old, nil
 			}); err != nil {
 				s.logger.ErrorContext(ctx, "failed to update offline session", "err", err)
@@ -1092,7 +1396,7 @@ func (s *Server) handleUserInfo(w http.ResponseWriter, r *http.Request) {
 	}
 	rawIDToken := auth[len(prefix):]
 
-	verifier := oidc.NewVerifier(s.issuerURL.String(), &storageKeySet{s.storage}, &oidc.Config{SkipClientIDCheck: true})
+	verifier := oidc.NewVerifier(s.issuerURL.String(), &signerKeySet{s.signer}, &oidc.Config{SkipClientIDCheck: true})
 	idToken, err := verifier.Verify(ctx, rawIDToken)
 	if err != nil {
 		s.tokenErrHelper(w, errAccessDenied, err.Error(), http.StatusForbidden)
@@ -1132,8 +1436,11 @@ func (s *Server) handlePasswordGrant(w http.ResponseWriter, r *http.Request, cli
 		switch scope {
 		case scopeOpenID:
 			hasOpenIDScope = true
-		case scopeOfflineAccess, scopeEmail, scopeProfile, scopeGroups, scopeFederatedID:
+		case scopeOfflineAccess, scopeEmail, scopeProfile, scopeGroups, scopeFederatedID, scopeSessions:
 		default:
+			if _, ok := s.customScopes[scope]; ok {
+				continue
+			}
 			peerID, ok := parseCrossClientScope(scope)
 			if !ok {
 				unrecognized = append(unrecognized, scope)
@@ -1180,7 +1487,23 @@ func (s *Server) handlePasswordGrant(w http.ResponseWriter, r *http.Request, cli
 	// Login
 	username := q.Get("username")
 	password := q.Get("password")
-	identity, ok, err := passwordConnector.Login(ctx, parseScopes(scopes), username, password)
+	loginCtx, done := s.startConnectorOperation(ctx, connID, "Login")
+	identity, ok, err := passwordConnector.Login(loginCtx, parseScopes(scopes), username, password)
+	done(err)
+	if err != nil || !ok {
+		s.emitEvent(ctx, auditEvent{
+			Type:        eventLoginFailed,
+			ClientID:    client.ID,
+			ConnectorID: connID,
+			Username:    username,
+			Scopes:      scopes,
+		})
+	}
+	if reason, denied := loginDenied(err); denied {
+		s.logger.InfoContext(r.Context(), "login denied", "user", username, "err", err)
+		s.tokenErrHelper(w, errAccessDenied, reason, http.StatusForbidden)
+		return
+	}
 	if err != nil {
 		s.logger.ErrorContext(r.Context(), "failed to login user", "err", err)
 		s.tokenErrHelper(w, errInvalidRequest, "Could not login user", http.StatusBadRequest)
@@ -1191,6 +1514,15 @@ func (s *Server) handlePasswordGrant(w http.ResponseWriter, r *http.Request, cli
 		return
 	}
 
+	s.emitEvent(ctx, auditEvent{
+		Type:        eventLoginSucceeded,
+		ClientID:    client.ID,
+		ConnectorID: connID,
+		UserID:      identity.UserID,
+		Username:    identity.Username,
+		Scopes:      scopes,
+	})
+
 	// Build the claims to send the id token
 	claims := storage.Claims{
 		UserID:            identity.UserID,
@@ -1199,19 +1531,21 @@ func (s *Server) handlePasswordGrant(w http.ResponseWriter, r *http.Request, cli
 		Email:             identity.Email,
 		EmailVerified:     identity.EmailVerified,
 		Groups:            identity.Groups,
+		CustomClaims:      identity.CustomClaims,
+		ClaimSources:      storageClaimSources(identity.ClaimSources, client.ForwardClaimSourceTokens),
 	}
 
-	accessToken, _, err := s.newAccessToken(r.Context(), client.ID, claims, scopes, nonce, connID)
+	accessToken, expiry, err := s.newAccessToken(r.Context(), client.ID, claims, scopes, nil, nonce, connID)
 	if err != nil {
 		s.logger.ErrorContext(r.Context(), "password grant failed to create new access token", "err", err)
-		s.tokenErrHelper(w, errServerError, "", http.StatusInternalServerError)
+		s.tokenIssueErrHelper(w, err)
 		return
 	}
 
-	idToken, expiry, err := s.newIDToken(r.Context(), client.ID, claims, scopes, nonce, accessToken, "", connID)
+	idToken, _, err := s.newIDToken(r.Context(), client.ID, claims, scopes, nil, nonce, accessToken, "", connID)
 	if err != nil {
 		s.logger.ErrorContext(r.Context(), "password grant failed to create new ID token", "err", err)
-		s.tokenErrHelper(w, errServerError, "", http.StatusInternalServerError)
+		s.tokenIssueErrHelper(w, err)
 		return
 	}
 
@@ -1297,7 +1631,7 @@ func (s *Server) handlePasswordGrant(w http.ResponseWriter, r *http.Request, cli
 				Refresh:       make(map[string]*storage.RefreshTokenRef),
 				ConnectorData: identity.ConnectorData,
 			}
-			offlineSessions.Refresh[tokenRef.ClientID] = &tokenRef
+			offlineSessions.Refresh[s.refreshTokenRefKey(tokenRef)] = &tokenRef
 
 			// Create a new OfflineSession object for the user and add a reference object for
 			// the newly received refreshtoken.
@@ -1308,11 +1642,13 @@ func (s *Server) handlePasswordGrant(w http.ResponseWriter, r *http.Request, cli
 				return
 			}
 		} else {
-			if oldTokenRef, ok := session.Refresh[tokenRef.ClientID]; ok {
+			evicted := s.evictedRefreshTokenRefs(session, tokenRef.ClientID)
+			for _, key := range evicted {
 				// Delete old refresh token from storage.
+				oldTokenRef := session.Refresh[key]
 				if err := s.storage.DeleteRefresh(oldTokenRef.ID); err != nil {
 					if err == storage.ErrNotFound {
-						s.logger.Warn("database inconsistent, refresh token missing", "token_id", oldTokenRef.ID)
+						s.logger.WarnContext(r.Context(), "database inconsistent, refresh token missing", "token_id", oldTokenRef.ID)
 					} else {
 						s.logger.ErrorContext(r.Context(), "failed to delete refresh token", "err", err)
 						s.tokenErrHelper(w, errServerError, "", http.StatusInternalServerError)
@@ -1324,7 +1660,10 @@ func (s *Server) handlePasswordGrant(w http.ResponseWriter, r *http.Request, cli
 
 			// Update existing OfflineSession obj with new RefreshTokenRef.
 			if err := s.storage.UpdateOfflineSessions(session.UserID, session.ConnID, func(old storage.OfflineSessions) (storage.OfflineSessions, error) {
-				old.Refresh[tokenRef.ClientID] = &tokenRef
+				for _, key := range evicted {
+					delete(old.Refresh, key)
+				}
+				old.Refresh[s.refreshTokenRefKey(tokenRef)] = &tokenRef
 				old.ConnectorData = identity.ConnectorData
 				return old, nil
 			}); err != nil {
@@ -1397,6 +1736,8 @@ func (s *Server) handleTokenExchange(w http.ResponseWriter, r *http.Request, cli
 		Email:             identity.Email,
 		EmailVerified:     identity.EmailVerified,
 		Groups:            identity.Groups,
+		CustomClaims:      identity.CustomClaims,
+		ClaimSources:      storageClaimSources(identity.ClaimSources, client.ForwardClaimSourceTokens),
 	}
 	resp := accessTokenResponse{
 		IssuedTokenType: requestedTokenType,
@@ -1405,16 +1746,16 @@ func (s *Server) handleTokenExchange(w http.ResponseWriter, r *http.Request, cli
 	var expiry time.Time
 	switch requestedTokenType {
 	case tokenTypeID:
-		resp.AccessToken, expiry, err = s.newIDToken(r.Context(), client.ID, claims, scopes, "", "", "", connID)
+		resp.AccessToken, expiry, err = s.newIDToken(r.Context(), client.ID, claims, scopes, nil, "", "", "", connID)
 	case tokenTypeAccess:
-		resp.AccessToken, expiry, err = s.newAccessToken(r.Context(), client.ID, claims, scopes, "", connID)
+		resp.AccessToken, expiry, err = s.newAccessToken(r.Context(), client.ID, claims, scopes, nil, "", connID)
 	default:
 		s.tokenErrHelper(w, errRequestNotSupported, "Invalid requested_token_type.", http.StatusBadRequest)
 		return
 	}
 	if err != nil {
 		s.logger.ErrorContext(r.Context(), "token exchange failed to create new token", "requested_token_type", requestedTokenType, "err", err)
-		s.tokenErrHelper(w, errServerError, "", http.StatusInternalServerError)
+		s.tokenIssueErrHelper(w, err)
 		return
 	}
 	resp.ExpiresIn = int(time.Until(expiry).Seconds())
@@ -1463,12 +1804,33 @@ func (s *Server) writeAccessToken(w http.ResponseWriter, resp *accessTokenRespon
 	w.Write(data)
 }
 
+// renderError shows the error page. Besides the description, the page only
+// shows an error code and the ID of the request, so that users can report the
+// error and operators can find the details in the logs.
 func (s *Server) renderError(r *http.Request, w http.ResponseWriter, status int, description string) {
-	if err := s.templates.err(r, w, status, description); err != nil {
+	code := errorCode(status, description)
+	s.logger.DebugContext(r.Context(), "rendering error page", "status", status, "error_code", code, "description", description)
+	if err := s.templates.err(r, w, status, description, code, requestID(r.Context()), s.cancelLink(r)); err != nil {
 		s.logger.ErrorContext(r.Context(), "server template error", "err", err)
 	}
 }
 
+// errorCode identifies the description of an error page, so that it stays the
+// same across releases and translations.
+func errorCode(status int, description string) string {
+	return fmt.Sprintf("%d-%06x", status, crc32.ChecksumIEEE([]byte(description))&0xffffff)
+}
+
+// loginDenied returns the reason shown to users if a connector middleware
+// denied their login.
+func loginDenied(err error) (string, bool) {
+	var denied *middleware.DeniedError
+	if errors.As(err, &denied) {
+		return denied.Reason, true
+	}
+	return "", false
+}
+
 func (s *Server) tokenErrHelper(w http.ResponseWriter, typ string, description string, statusCode int) {
 	if err := tokenErr(w, typ, description, statusCode); err != nil {
 		// TODO(nabokihms): error with context
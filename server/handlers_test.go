@@ -104,9 +104,83 @@ func TestHandleDiscovery(t *testing.T) {
 			"preferred_username",
 			"at_hash",
 		},
+		ClaimsParameter: true,
+		AuthResponseIss: true,
 	}, res)
 }
 
+func TestHandleDiscoveryFields(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, server := newTestServer(ctx, t, func(c *Config) {
+		c.DiscoveryFields = map[string]interface{}{
+			"op_policy_uri":                 "https://example.com/policy",
+			"claims_supported":              []string{"sub", "email", "groups"},
+			"claims_parameter_supported":    nil,
+			"x_example_extension_supported": true,
+		}
+	})
+	defer httpServer.Close()
+
+	rr := httptest.NewRecorder()
+	server.ServeHTTP(rr, httptest.NewRequest("GET", "/.well-known/openid-configuration", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var res map[string]interface{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	require.Equal(t, httpServer.URL, res["issuer"])
+	require.Equal(t, "https://example.com/policy", res["op_policy_uri"])
+	require.Equal(t, []interface{}{"sub", "email", "groups"}, res["claims_supported"])
+	require.Equal(t, true, res["x_example_extension_supported"])
+	require.NotContains(t, res, "claims_parameter_supported")
+
+	require.Error(t, validateDiscoveryFields(map[string]interface{}{"issuer": "https://example.com"}))
+}
+
+func TestHandleAuthorizationServerMetadata(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, server := newTestServer(ctx, t, func(c *Config) {
+		c.Issuer += "/non-root-path"
+	})
+	defer httpServer.Close()
+
+	issuer := server.issuerURL.String()
+	for _, p := range []string{
+		"/non-root-path/.well-known/oauth-authorization-server",
+		"/.well-known/oauth-authorization-server/non-root-path",
+	} {
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, httptest.NewRequest("GET", p, nil))
+		require.Equal(t, http.StatusOK, rr.Code, p)
+
+		var res authorizationServerMetadata
+		require.NoError(t, json.NewDecoder(rr.Result().Body).Decode(&res))
+		require.Equal(t, authorizationServerMetadata{
+			Issuer:         issuer,
+			Auth:           issuer + "/auth",
+			Token:          issuer + "/token",
+			Keys:           issuer + "/keys",
+			DeviceEndpoint: issuer + "/device/code",
+			Introspect:     issuer + "/token/introspect",
+			GrantTypes: []string{
+				"authorization_code",
+				"refresh_token",
+				"urn:ietf:params:oauth:grant-type:device_code",
+				"urn:ietf:params:oauth:grant-type:token-exchange",
+			},
+			ResponseTypes:         []string{"code"},
+			CodeChallengeAlgs:     []string{"S256", "plain"},
+			Scopes:                []string{"openid", "email", "groups", "profile", "offline_access"},
+			AuthMethods:           []string{"client_secret_basic", "client_secret_post"},
+			IntrospectAuthMethods: []string{"none"},
+			AuthResponseIss:       true,
+		}, res, p)
+	}
+}
+
 func TestHandleHealthFailure(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -134,6 +208,89 @@ func TestHandleHealthFailure(t *testing.T) {
 	}
 }
 
+func TestHandleErrorPage(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, server := newTestServer(ctx, t, func(c *Config) {
+		c.Web.SupportText = "Please contact support@example.com."
+	})
+	defer httpServer.Close()
+
+	rr := httptest.NewRecorder()
+	server.ServeHTTP(rr, httptest.NewRequest("GET", "/auth/local/login", nil))
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	body := rr.Body.String()
+	require.Contains(t, body, "User session error.")
+	require.Contains(t, body, "Please contact support@example.com.")
+	require.Contains(t, body, "Error code: "+errorCode(http.StatusBadRequest, "User session error."))
+	require.Regexp(t, `Request ID: [0-9a-f-]{36}`, body)
+
+	require.NotEqual(t, errorCode(http.StatusBadRequest, "User session error."), errorCode(http.StatusInternalServerError, "User session error."))
+}
+
+func TestHandleAuthorizationConnectorDisplay(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, server := newTestServer(ctx, t, nil)
+	defer httpServer.Close()
+
+	for _, c := range []storage.Connector{
+		{ID: "partner", Type: "mockCallback", Name: "Acme", Display: &storage.ConnectorDisplay{Order: 1, Group: "Partners", IconURL: "https://acme.example.com/icon.svg", ButtonColor: "#2f2f2f"}},
+		{ID: "broken", Type: "mockCallback", Name: "Broken", Display: &storage.ConnectorDisplay{Order: 3, IconURL: "javascript:alert(1)"}},
+		{ID: "staff", Type: "mockCallback", Name: "Corporate SSO", Display: &storage.ConnectorDisplay{Group: "Employees"}},
+		{ID: "contractor", Type: "mockCallback", Name: "Contractors", Display: &storage.ConnectorDisplay{Order: 2, Group: "Employees"}},
+		{ID: "break-glass", Type: "mockCallback", Name: "Break glass", Display: &storage.ConnectorDisplay{Hidden: true}},
+	} {
+		require.NoError(t, server.storage.CreateConnector(ctx, c))
+	}
+
+	rr := httptest.NewRecorder()
+	server.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/auth?response_type=code", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	body := rr.Body.String()
+	require.NotContains(t, body, "Break glass")
+	// Ungrouped connectors come first, then the groups in the order of their
+	// first connector.
+	var last int
+	for _, want := range []string{"Mock", "Employees", "Corporate SSO", "Contractors", "Partners", "Acme"} {
+		i := strings.Index(body, want)
+		require.Greater(t, i, last, "%q out of order", want)
+		last = i
+	}
+
+	require.Contains(t, body, `style="background-color: #2f2f2f"`)
+	require.Contains(t, body, `src="https://acme.example.com/icon.svg"`)
+	require.Contains(t, body, "dex-btn-icon--mockCallback")
+	require.NotContains(t, body, "javascript:")
+
+	// Hidden connectors can still be chosen explicitly.
+	rr = httptest.NewRecorder()
+	server.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/auth?response_type=code&connector_id=break-glass", nil))
+	require.Equal(t, http.StatusFound, rr.Code)
+	require.Equal(t, "/auth/break-glass", strings.Split(rr.Header().Get("Location"), "?")[0])
+}
+
+func TestHandleAuthorizationSingleVisibleConnector(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, server := newTestServer(ctx, t, nil)
+	defer httpServer.Close()
+
+	require.NoError(t, server.storage.CreateConnector(ctx, storage.Connector{
+		ID: "break-glass", Type: "mockCallback", Name: "Break glass", Display: &storage.ConnectorDisplay{Hidden: true},
+	}))
+
+	rr := httptest.NewRecorder()
+	server.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/auth?response_type=code", nil))
+	require.Equal(t, http.StatusFound, rr.Code)
+	require.Equal(t, "/auth/mock", strings.Split(rr.Header().Get("Location"), "?")[0])
+}
+
 type emptyStorage struct {
 	storage.Storage
 }
@@ -269,6 +426,7 @@ func TestHandleAuthCode(t *testing.T) {
 
 				q := r.URL.Query()
 				require.Equal(t, q.Get("error"), "", q.Get("error_description"))
+				require.Equal(t, s.issuerURL.String(), q.Get("iss"))
 
 				code := q.Get("code")
 				tc.handleCode(t, ctx, oauth2Client.config, code)
@@ -822,3 +980,52 @@ func setNonEmpty(vals url.Values, key, value string) {
 		vals.Set(key, value)
 	}
 }
+
+func TestHandlePasswordLoginWithMiddleware(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, s := newTestServer(ctx, t, func(c *Config) {
+		c.SkipApprovalScreen = true
+	})
+	defer httpServer.Close()
+
+	for _, tc := range []struct {
+		connID   string
+		domains  string
+		wantCode int
+	}{
+		{connID: "allowed", domains: `["kilgore.trout"]`, wantCode: http.StatusSeeOther},
+		{connID: "denied", domains: `["example.com"]`, wantCode: http.StatusForbidden},
+	} {
+		sc := storage.Connector{
+			ID:              tc.connID,
+			Type:            "mockPassword",
+			Name:            "MockPassword",
+			ResourceVersion: "1",
+			Config: []byte(`{"username": "foo", "password": "password", "middleware": [
+				{"type": "domainAllowlist", "domains": ` + tc.domains + `},
+				{"type": "staticClaims", "groups": ["mock"]}
+			]}`),
+		}
+		require.NoError(t, s.storage.CreateConnector(ctx, sc))
+		_, err := s.OpenConnector(sc)
+		require.NoError(t, err)
+		require.NoError(t, s.storage.CreateAuthRequest(ctx, storage.AuthRequest{
+			ID:            tc.connID,
+			ConnectorID:   tc.connID,
+			RedirectURI:   "cb",
+			Expiry:        time.Now().Add(time.Minute),
+			ResponseTypes: []string{responseTypeCode},
+			Scopes:        []string{"openid", "groups"},
+		}))
+
+		rr := httptest.NewRecorder()
+		path := fmt.Sprintf("/auth/%s/login?state=%s&back=&login=foo&password=password", tc.connID, tc.connID)
+		s.handlePasswordLogin(rr, httptest.NewRequest("POST", path, nil))
+		require.Equal(t, tc.wantCode, rr.Code, tc.connID)
+	}
+
+	sc := storage.Connector{ID: "invalid", Type: "mockPassword", Config: []byte(`{"username": "foo", "password": "password", "middleware": [{"type": "unknown"}]}`)}
+	require.Error(t, ValidateConnector(s.logger, sc))
+}
@@ -0,0 +1,242 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dexidp/dex/connector"
+	"github.com/dexidp/dex/storage"
+)
+
+// Statuses of the components in the detailed health report. Connectors which
+// can't check their upstream identity provider are reported as unknown.
+const (
+	healthOK      = "ok"
+	healthError   = "error"
+	healthUnknown = "unknown"
+)
+
+// healthCheckTimeout bounds each check of the detailed health report.
+const healthCheckTimeout = 5 * time.Second
+
+// signingKeyRotationGrace is how long a rotation of the signing key may be
+// overdue before the signer is reported unhealthy.
+const signingKeyRotationGrace = 10 * time.Minute
+
+// componentHealth is the status of a component in the detailed health report.
+type componentHealth struct {
+	Status  string            `json:"status"`
+	Error   string            `json:"error,omitempty"`
+	Latency string            `json:"latency,omitempty"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+type healthReport struct {
+	Status     string                     `json:"status"`
+	Storage    componentHealth            `json:"storage"`
+	Signer     componentHealth            `json:"signer"`
+	Connectors map[string]componentHealth `json:"connectors"`
+}
+
+// handleHealth reports if the storage is healthy. With the verbose
+// parameter, it reports the status of every component as JSON to callers
+// presenting the health report token.
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if _, verbose := r.URL.Query()["verbose"]; !verbose {
+		if !s.healthChecker.IsHealthy() {
+			s.renderError(r, w, http.StatusInternalServerError, "Health check failed.")
+			return
+		}
+		fmt.Fprintf(w, "Health check passed")
+		return
+	}
+
+	if !s.authorizedHealthReport(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	report := s.healthReport(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.Status != healthOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		s.logger.ErrorContext(r.Context(), "failed to write health report", "err", err)
+	}
+}
+
+// handleLive is the liveness probe: the server handles requests.
+func (s *Server) handleLive(w http.ResponseWriter, _ *http.Request) {
+	fmt.Fprintf(w, "ok")
+}
+
+// handleReady is the readiness probe: the storage is healthy and tokens can
+// be signed. Upstream identity providers aren't considered, since instances
+// being taken out of rotation doesn't help if they're down.
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	if err := s.ready(r.Context()); err != nil {
+		s.logger.WarnContext(r.Context(), "not ready", "err", err)
+		http.Error(w, "not ready", http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprintf(w, "ok")
+}
+
+// ReadinessHandler returns the readiness probe of the server, e.g. to serve
+// it on another listener.
+func (s *Server) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(s.handleReady)
+}
+
+func (s *Server) ready(ctx context.Context) error {
+	if !s.healthChecker.IsHealthy() {
+		return errors.New("storage is unhealthy")
+	}
+	keys, err := s.signer.ValidationKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("get signing keys: %v", err)
+	}
+	if len(keys) == 0 {
+		return errors.New("no signing keys")
+	}
+	return nil
+}
+
+func (s *Server) authorizedHealthReport(r *http.Request) bool {
+	if s.healthReportToken == "" {
+		return false
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.healthReportToken)) == 1
+}
+
+// healthReport checks the storage, the signer and the upstream identity
+// providers of the connectors concurrently.
+func (s *Server) healthReport(ctx context.Context) healthReport {
+	report := healthReport{Status: healthOK, Connectors: make(map[string]componentHealth)}
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	check := func(f func(context.Context) componentHealth, set func(componentHealth)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+			defer cancel()
+			h := f(ctx)
+
+			mu.Lock()
+			defer mu.Unlock()
+			set(h)
+			if h.Status == healthError {
+				report.Status = healthError
+			}
+		}()
+	}
+
+	check(s.storageHealth, func(h componentHealth) { report.Storage = h })
+	check(s.signerHealth, func(h componentHealth) { report.Signer = h })
+
+	connectors, err := s.storage.ListConnectors()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to list connectors", "err", err)
+		report.Status = healthError
+	}
+	for _, c := range connectors {
+		check(func(ctx context.Context) componentHealth {
+			return s.connectorHealth(ctx, c.ID)
+		}, func(h componentHealth) { report.Connectors[c.ID] = h })
+	}
+
+	wg.Wait()
+	return report
+}
+
+// storageHealth creates and deletes an object, reporting the round-trip time.
+func (s *Server) storageHealth(ctx context.Context) componentHealth {
+	start := time.Now()
+	_, err := storage.NewCustomHealthCheckFunc(s.storage, s.now)(ctx)
+	return componentStatus(time.Since(start), err, nil)
+}
+
+// signerHealth reports the published keys, the age of the signing key and
+// its next rotation. Signers without keys or whose rotation is overdue are
+// unhealthy.
+func (s *Server) signerHealth(ctx context.Context) componentHealth {
+	start := time.Now()
+	details := make(map[string]string)
+	err := func() error {
+		alg, err := s.signer.Algorithm(ctx)
+		if err != nil {
+			return fmt.Errorf("get algorithm: %v", err)
+		}
+		details["algorithm"] = string(alg)
+
+		keys, err := s.signer.ValidationKeys(ctx)
+		if err != nil {
+			return fmt.Errorf("get keys: %v", err)
+		}
+		details["validation_keys"] = strconv.Itoa(len(keys))
+		if len(keys) == 0 {
+			return errors.New("no signing keys")
+		}
+
+		now := s.now()
+		if ager, ok := s.signer.(signingKeyAger); ok {
+			since, err := ager.SigningKeyActiveSince(ctx)
+			if err != nil {
+				return fmt.Errorf("get signing key age: %v", err)
+			}
+			details["signing_key_age"] = now.Sub(since).Round(time.Second).String()
+		}
+		if scheduler, ok := s.signer.(rotationScheduler); ok {
+			next, err := scheduler.NextRotation(ctx)
+			if err != nil {
+				return fmt.Errorf("get next rotation: %v", err)
+			}
+			details["next_rotation"] = next.UTC().Format(time.RFC3339)
+			if now.Sub(next) > signingKeyRotationGrace {
+				return fmt.Errorf("signing key rotation overdue since %s", next.UTC().Format(time.RFC3339))
+			}
+		}
+		return nil
+	}()
+	return componentStatus(time.Since(start), err, details)
+}
+
+// connectorHealth opens a connector and, if it supports it, checks that its
+// upstream identity provider is reachable.
+func (s *Server) connectorHealth(ctx context.Context, id string) componentHealth {
+	conn, err := s.getConnector(id)
+	if err != nil {
+		return componentHealth{Status: healthError, Error: err.Error()}
+	}
+	checker, ok := conn.Connector.(connector.HealthChecker)
+	if !ok {
+		return componentHealth{Status: healthUnknown}
+	}
+	start := time.Now()
+	err = checker.CheckHealth(ctx)
+	return componentStatus(time.Since(start), err, nil)
+}
+
+func componentStatus(latency time.Duration, err error, details map[string]string) componentHealth {
+	h := componentHealth{Status: healthOK, Latency: latency.String(), Details: details}
+	if err != nil {
+		h.Status = healthError
+		h.Error = err.Error()
+	}
+	return h
+}
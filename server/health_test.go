@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dexidp/dex/storage"
+)
+
+func TestHealthReport(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, s := newTestServer(ctx, t, func(c *Config) {
+		c.HealthReportToken = "secret"
+	})
+	defer httpServer.Close()
+
+	get := func(path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		s.ServeHTTP(rr, req)
+		return rr
+	}
+
+	require.Equal(t, http.StatusOK, get("/healthz/live", "").Code)
+	require.Equal(t, http.StatusOK, get("/healthz/ready", "").Code)
+	require.Equal(t, http.StatusOK, get("/healthz", "").Code)
+	require.Equal(t, http.StatusUnauthorized, get("/healthz?verbose", "").Code)
+	require.Equal(t, http.StatusUnauthorized, get("/healthz?verbose", "wrong").Code)
+
+	rr := get("/healthz?verbose", "secret")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var report healthReport
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+	require.Equal(t, healthOK, report.Status)
+	require.Equal(t, healthOK, report.Storage.Status)
+	require.NotEmpty(t, report.Storage.Latency)
+	require.Equal(t, healthOK, report.Signer.Status)
+	require.NotEqual(t, "0", report.Signer.Details["validation_keys"])
+	// The mock connector can't check an upstream identity provider.
+	require.Equal(t, healthUnknown, report.Connectors["mock"].Status)
+
+	// Connectors which can't be opened make the report fail.
+	require.NoError(t, s.storage.CreateConnector(ctx, storage.Connector{ID: "broken", Type: "unknown", Name: "Broken"}))
+	rr = get("/healthz?verbose", "secret")
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+	require.Equal(t, healthError, report.Status)
+	require.Equal(t, healthError, report.Connectors["broken"].Status)
+	require.Equal(t, healthOK, report.Storage.Status)
+}
+
+func TestHealthReportDisabled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, s := newTestServer(ctx, t, nil)
+	defer httpServer.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz?verbose", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	rr := httptest.NewRecorder()
+	s.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+// HomeRealmDiscoveryConfig asks users for their e-mail address before showing
+// the connectors, and sends them straight to the connector of their domain.
+type HomeRealmDiscoveryConfig struct {
+	// Maps e-mail domains, e.g. "example.com", to the IDs of the connectors
+	// their users log in with. Subdomains use the connector of their parent
+	// domain unless they're mapped themselves. Users of other domains get to
+	// choose a connector.
+	Domains map[string]string
+}
+
+type homeRealmDiscovery struct {
+	domains map[string]string
+}
+
+func newHomeRealmDiscovery(c *HomeRealmDiscoveryConfig) (*homeRealmDiscovery, error) {
+	if len(c.Domains) == 0 {
+		return nil, errors.New("no domains configured for home realm discovery")
+	}
+	domains := make(map[string]string, len(c.Domains))
+	for domain, connID := range c.Domains {
+		if domain == "" || connID == "" {
+			return nil, fmt.Errorf("invalid home realm discovery domain %q: domain and connector must not be empty", domain)
+		}
+		domains[strings.ToLower(strings.TrimPrefix(domain, "@"))] = connID
+	}
+	return &homeRealmDiscovery{domains: domains}, nil
+}
+
+// connectorFor returns the ID of the connector of the domain of an e-mail
+// address.
+func (h *homeRealmDiscovery) connectorFor(email string) (string, bool) {
+	addr, err := mail.ParseAddress(strings.TrimSpace(email))
+	if err != nil {
+		return "", false
+	}
+	_, domain, ok := strings.Cut(addr.Address, "@")
+	if !ok {
+		return "", false
+	}
+	domain = strings.ToLower(domain)
+	for {
+		if connID, ok := h.domains[domain]; ok {
+			return connID, true
+		}
+		_, parent, ok := strings.Cut(domain, ".")
+		if !ok {
+			return "", false
+		}
+		domain = parent
+	}
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dexidp/dex/storage"
+)
+
+func TestHomeRealmDiscovery(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, s := newTestServer(ctx, t, func(c *Config) {
+		c.HomeRealmDiscovery = &HomeRealmDiscoveryConfig{Domains: map[string]string{"Example.com": "corp"}}
+	})
+	defer httpServer.Close()
+
+	require.NoError(t, s.storage.CreateConnector(ctx, storage.Connector{
+		ID: "corp", Type: "mockCallback", Name: "Corporate SSO", Display: &storage.ConnectorDisplay{Hidden: true},
+	}))
+	require.NoError(t, s.storage.CreateConnector(ctx, storage.Connector{
+		ID: "other", Type: "mockCallback", Name: "Other",
+	}))
+
+	do := func(method, target string, form url.Values) *httptest.ResponseRecorder {
+		var req *http.Request
+		if form != nil {
+			req = httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		} else {
+			req = httptest.NewRequest(method, target, nil)
+		}
+		rr := httptest.NewRecorder()
+		s.ServeHTTP(rr, req)
+		return rr
+	}
+	location := func(rr *httptest.ResponseRecorder) *url.URL {
+		u, err := url.Parse(rr.Header().Get("Location"))
+		require.NoError(t, err)
+		return u
+	}
+
+	// Users are asked for their e-mail address first.
+	rr := do(http.MethodGet, "/auth?client_id=app&response_type=code", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), `name="email"`)
+	require.Contains(t, rr.Body.String(), "show_connectors=true")
+	require.NotContains(t, rr.Body.String(), "Log in with Other")
+
+	// Addresses of a mapped domain or its subdomains go to its connector.
+	rr = do(http.MethodPost, "/auth?client_id=app&response_type=code", url.Values{"email": {"jane@Sales.example.com"}})
+	require.Equal(t, http.StatusFound, rr.Code)
+	u := location(rr)
+	require.Equal(t, "/auth/corp", u.Path)
+	require.Equal(t, "app", u.Query().Get("client_id"))
+	require.False(t, u.Query().Has("email"))
+
+	// Users of other domains choose a connector.
+	rr = do(http.MethodPost, "/auth?client_id=app&response_type=code", url.Values{"email": {"joe@example.org"}})
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), "Log in with Other")
+	require.NotContains(t, rr.Body.String(), "joe@example.org")
+
+	// Clients can pass the address as login_hint.
+	rr = do(http.MethodGet, "/auth?client_id=app&response_type=code&login_hint=jane%40example.com", nil)
+	require.Equal(t, http.StatusFound, rr.Code)
+	u = location(rr)
+	require.Equal(t, "/auth/corp", u.Path)
+	require.Equal(t, "jane@example.com", u.Query().Get("login_hint"))
+
+	// Users can skip the question.
+	rr = do(http.MethodGet, "/auth?client_id=app&response_type=code&show_connectors=true", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), "Log in with Other")
+	require.NotContains(t, rr.Body.String(), "show_connectors")
+}
+
+func TestHomeRealmDiscoveryConfig(t *testing.T) {
+	_, err := newHomeRealmDiscovery(&HomeRealmDiscoveryConfig{})
+	require.Error(t, err)
+	_, err = newHomeRealmDiscovery(&HomeRealmDiscoveryConfig{Domains: map[string]string{"example.com": ""}})
+	require.Error(t, err)
+
+	h, err := newHomeRealmDiscovery(&HomeRealmDiscoveryConfig{Domains: map[string]string{"@example.com": "corp", "eu.example.com": "eu"}})
+	require.NoError(t, err)
+	for email, want := range map[string]string{
+		"jane@example.com":         "corp",
+		"Jane <jane@example.com>":  "corp",
+		"jane@us.example.com":      "corp",
+		"jane@eu.example.com":      "eu",
+		"jane@dev.eu.example.com":  "eu",
+		"jane@example.com.evil.io": "",
+		"jane":                     "",
+	} {
+		got, _ := h.connectorFor(email)
+		require.Equal(t, want, got, email)
+	}
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+)
+
+// NewIssuerMux returns a handler serving several issuers from one listener.
+// Requests are routed to the server whose issuer URL matches the Host header
+// and has the longest path prefix in common with the request. Requests for
+// hosts no issuer serves are answered with 404, so proxies in front must pass
+// the Host header on.
+//
+// WebFinger requests are routed to the first issuer serving the domain of the
+// requested resource.
+func NewIssuerMux(servers ...*Server) http.Handler {
+	return issuerMux(servers)
+}
+
+type issuerMux []*Server
+
+func (m issuerMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s := m.match(r); s != nil {
+		s.ServeHTTP(w, r)
+		return
+	}
+	http.NotFound(w, r)
+}
+
+func (m issuerMux) match(r *http.Request) *Server {
+	candidates := make([]*Server, 0, len(m))
+	for _, s := range m {
+		if strings.EqualFold(s.issuerURL.Host, r.Host) {
+			candidates = append(candidates, s)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	if r.URL.Path == webFingerPath {
+		return matchWebFinger(candidates, r)
+	}
+
+	// The authorization server metadata of RFC 8414 is served in front of
+	// the path of the issuer.
+	p := r.URL.Path
+	if issuerPath, ok := strings.CutPrefix(p, authorizationServerMetadataPath); ok && issuerPath != "" {
+		p = issuerPath
+	}
+
+	var match *Server
+	for _, s := range candidates {
+		if !hasPathPrefix(p, s.issuerURL.Path) {
+			continue
+		}
+		if match == nil || len(s.issuerURL.Path) > len(match.issuerURL.Path) {
+			match = s
+		}
+	}
+	return match
+}
+
+// matchWebFinger returns the server to answer a WebFinger request. Issuers
+// with the domain of the resource listed explicitly take precedence over ones
+// serving any domain.
+func matchWebFinger(candidates []*Server, r *http.Request) *Server {
+	domain, ok := webFingerDomain(r.URL.Query().Get("resource"))
+	if !ok {
+		// Let the first issuer reject the request.
+		return candidates[0]
+	}
+	var fallback *Server
+	for _, s := range candidates {
+		if len(s.webFingerDomains) == 0 {
+			if fallback == nil {
+				fallback = s
+			}
+			continue
+		}
+		if s.servesWebFingerDomain(domain) {
+			return s
+		}
+	}
+	return fallback
+}
+
+// hasPathPrefix reports whether p is prefix or lies below it.
+func hasPathPrefix(p, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix == "" {
+		return true
+	}
+	return p == prefix || strings.HasPrefix(p, prefix+"/")
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	gosundheit "github.com/AppsFlyer/go-sundheit"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dexidp/dex/storage"
+	"github.com/dexidp/dex/storage/memory"
+)
+
+func TestIssuerMux(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	backing := memory.New(logger)
+	require.NoError(t, backing.CreateConnector(ctx, storage.Connector{
+		ID:              "mock",
+		Type:            "mockCallback",
+		Name:            "Mock",
+		ResourceVersion: "1",
+	}))
+
+	newIssuer := func(issuer string, s storage.Storage) *Server {
+		server, err := newServer(ctx, Config{
+			Issuer:        issuer,
+			Storage:       s,
+			Web:           WebConfig{Dir: "../web"},
+			Logger:        logger,
+			HealthChecker: gosundheit.New(),
+		}, staticRotationStrategy(testKey))
+		require.NoError(t, err)
+		return server
+	}
+
+	const (
+		defaultIssuer = "http://dex.example.com/dex"
+		pathIssuer    = "http://dex.example.com/tenant"
+		hostIssuer    = "http://tenant.example.com"
+	)
+	pathStorage := storage.WithIssuer(backing, pathIssuer, nil, nil)
+	mux := NewIssuerMux(
+		newIssuer(defaultIssuer, storage.WithIssuer(backing, "", nil, nil)),
+		newIssuer(pathIssuer, pathStorage),
+		newIssuer(hostIssuer, storage.WithIssuer(backing, hostIssuer, nil, nil)),
+	)
+
+	discover := func(t *testing.T, url string) (int, string) {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, url, nil))
+		if rr.Code != http.StatusOK {
+			return rr.Code, ""
+		}
+		var doc struct {
+			Issuer string `json:"issuer"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &doc))
+		return rr.Code, doc.Issuer
+	}
+
+	tests := []struct {
+		url        string
+		wantCode   int
+		wantIssuer string
+	}{
+		{url: defaultIssuer + "/.well-known/openid-configuration", wantCode: http.StatusOK, wantIssuer: defaultIssuer},
+		{url: pathIssuer + "/.well-known/openid-configuration", wantCode: http.StatusOK, wantIssuer: pathIssuer},
+		{url: hostIssuer + "/.well-known/openid-configuration", wantCode: http.StatusOK, wantIssuer: hostIssuer},
+		{url: "http://localhost:5556/tenant/.well-known/openid-configuration", wantCode: http.StatusNotFound},
+		{url: "http://dex.example.com/other/.well-known/openid-configuration", wantCode: http.StatusNotFound},
+		{url: "http://dex.example.com/.well-known/oauth-authorization-server/tenant", wantCode: http.StatusOK, wantIssuer: pathIssuer},
+		{url: "http://dex.example.com/.well-known/oauth-authorization-server/dex", wantCode: http.StatusOK, wantIssuer: defaultIssuer},
+	}
+	for _, tc := range tests {
+		t.Run(tc.url, func(t *testing.T) {
+			code, issuer := discover(t, tc.url)
+			require.Equal(t, tc.wantCode, code)
+			require.Equal(t, tc.wantIssuer, issuer)
+		})
+	}
+
+	// Auth codes are only redeemed at the issuer which issued them.
+	require.NoError(t, backing.CreateClient(ctx, storage.Client{ID: "app", Secret: "secret", RedirectURIs: []string{"http://app.example.com/callback"}}))
+	require.NoError(t, pathStorage.CreateAuthCode(ctx, storage.AuthCode{
+		ID:          "code",
+		ClientID:    "app",
+		RedirectURI: "http://app.example.com/callback",
+		ConnectorID: "mock",
+		Scopes:      []string{"openid"},
+		Expiry:      time.Now().Add(time.Minute),
+	}))
+	redeem := func(issuer string) *httptest.ResponseRecorder {
+		form := url.Values{"grant_type": {grantTypeAuthorizationCode}, "code": {"code"}, "redirect_uri": {"http://app.example.com/callback"}}
+		req := httptest.NewRequest(http.MethodPost, issuer+"/token", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth("app", "secret")
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		return rr
+	}
+	rr := redeem(defaultIssuer)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), errInvalidGrant)
+	require.Equal(t, http.StatusOK, redeem(pathIssuer).Code)
+
+	keys, err := backing.GetKeys()
+	require.NoError(t, err)
+	require.NotNil(t, keys.SigningKey)
+	require.Len(t, keys.IssuerKeys, 2)
+	require.NotEqual(t, keys.SigningKey.KeyID, keys.IssuerKeys[pathIssuer].SigningKey.KeyID)
+	require.NotEqual(t, keys.IssuerKeys[pathIssuer].SigningKey.KeyID, keys.IssuerKeys[hostIssuer].SigningKey.KeyID)
+}
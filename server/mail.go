@@ -0,0 +1,80 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// Mailer sends e-mails to users, such as the links to reset their password.
+type Mailer interface {
+	SendMail(ctx context.Context, to, subject, body string) error
+}
+
+// SMTPConfig configures sending e-mails through an SMTP server.
+type SMTPConfig struct {
+	Host string
+	// Defaults to 587. STARTTLS is used if the server supports it.
+	Port int
+
+	// Credentials of the account sending e-mails, if the server requires them.
+	// They are only sent over encrypted connections or to localhost.
+	Username string
+	Password string
+
+	// Address e-mails are sent from, e.g. "Dex <dex@example.com>".
+	From string
+}
+
+type smtpMailer struct {
+	addr string
+	auth smtp.Auth
+	from *mail.Address
+}
+
+// NewSMTPMailer returns a mailer sending e-mails through an SMTP server.
+func NewSMTPMailer(c SMTPConfig) (Mailer, error) {
+	if c.Host == "" {
+		return nil, errors.New("no SMTP host specified")
+	}
+	from, err := mail.ParseAddress(c.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from address %q: %v", c.From, err)
+	}
+	port := c.Port
+	if port == 0 {
+		port = 587
+	}
+	m := &smtpMailer{
+		addr: net.JoinHostPort(c.Host, strconv.Itoa(port)),
+		from: from,
+	}
+	if c.Username != "" {
+		m.auth = smtp.PlainAuth("", c.Username, c.Password, c.Host)
+	}
+	return m, nil
+}
+
+func (m *smtpMailer) SendMail(_ context.Context, to, subject, body string) error {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", m.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", (&mail.Address{Address: to}).String())
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	msg.WriteString(body)
+
+	if err := smtp.SendMail(m.addr, m.auth, m.from.Address, []string{to}, msg.Bytes()); err != nil {
+		return fmt.Errorf("send mail: %v", err)
+	}
+	return nil
+}
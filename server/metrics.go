@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// identityMetrics are the metrics of logins and token grants, in addition to
+// the metrics of the HTTP handlers. Its methods do nothing on a nil receiver,
+// so they can be called if the server doesn't export metrics.
+type identityMetrics struct {
+	logins            *prometheus.CounterVec
+	tokenGrants       *prometheus.CounterVec
+	refreshRotations  prometheus.Counter
+	deviceCompletions *prometheus.CounterVec
+	approvalDenials   *prometheus.CounterVec
+	connectorDuration *prometheus.HistogramVec
+	rateLimited       *prometheus.CounterVec
+}
+
+func newIdentityMetrics() *identityMetrics {
+	return &identityMetrics{
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "dex_logins_total",
+			Help: "Number of logins by connector and outcome.",
+		}, []string{"connector", "outcome"}),
+		tokenGrants: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "dex_token_grants_total",
+			Help: "Number of successful token requests by grant type.",
+		}, []string{"grant_type"}),
+		refreshRotations: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "dex_refresh_token_rotations_total",
+			Help: "Number of refresh tokens replaced by a new one when used.",
+		}),
+		deviceCompletions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "dex_device_flow_completions_total",
+			Help: "Number of device requests approved or denied by users.",
+		}, []string{"outcome"}),
+		approvalDenials: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "dex_approval_denials_total",
+			Help: "Number of authorization requests denied on the approval page, by client.",
+		}, []string{"client"}),
+		connectorDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "dex_connector_operation_duration_seconds",
+			Help:    "A histogram of latencies of connector operations, which usually call the upstream identity provider.",
+			Buckets: []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+		}, []string{"connector", "operation", "outcome"}),
+		rateLimited: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "dex_rate_limited_requests_total",
+			Help: "Number of requests rejected by rate limits, by endpoint and limit.",
+		}, []string{"endpoint", "limit"}),
+	}
+}
+
+func (m *identityMetrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.logins, m.tokenGrants, m.refreshRotations, m.deviceCompletions, m.approvalDenials, m.connectorDuration, m.rateLimited}
+}
+
+func (m *identityMetrics) observeLogin(connID string, succeeded bool) {
+	if m == nil {
+		return
+	}
+	outcome := "success"
+	if !succeeded {
+		outcome = "failure"
+	}
+	m.logins.WithLabelValues(connID, outcome).Inc()
+}
+
+func (m *identityMetrics) observeTokenGrant(grantType string) {
+	if m == nil {
+		return
+	}
+	m.tokenGrants.WithLabelValues(grantType).Inc()
+}
+
+func (m *identityMetrics) observeRefreshRotation() {
+	if m == nil {
+		return
+	}
+	m.refreshRotations.Inc()
+}
+
+func (m *identityMetrics) observeDeviceCompletion(status string) {
+	if m == nil {
+		return
+	}
+	outcome := "approved"
+	if status == deviceTokenDenied {
+		outcome = "denied"
+	}
+	m.deviceCompletions.WithLabelValues(outcome).Inc()
+}
+
+func (m *identityMetrics) observeApprovalDenial(clientID string) {
+	if m == nil {
+		return
+	}
+	m.approvalDenials.WithLabelValues(clientID).Inc()
+}
+
+func (m *identityMetrics) observeRateLimited(endpoint, limit string) {
+	if m == nil {
+		return
+	}
+	m.rateLimited.WithLabelValues(endpoint, limit).Inc()
+}
+
+func (m *identityMetrics) observeConnectorOperation(connID, operation string, duration time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	m.connectorDuration.WithLabelValues(connID, operation, outcome).Observe(duration.Seconds())
+}
+
+// startConnectorOperation traces and times a call to a connector. The
+// returned function must be called with the result of the call.
+func (s *Server) startConnectorOperation(ctx context.Context, connID, operation string) (context.Context, func(error)) {
+	ctx, span := s.startSpan(ctx, "connector."+operation, attrConnectorID.String(connID))
+	start := time.Now()
+	return ctx, func(err error) {
+		endSpan(span, err)
+		s.metrics.observeConnectorOperation(connID, operation, time.Since(start), err)
+	}
+}
+
+// statusRecorder remembers the status code of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusRecorder) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dexidp/dex/storage"
+)
+
+func TestIdentityMetrics(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, s := newTestServer(ctx, t, nil)
+	defer httpServer.Close()
+	m := s.metrics
+
+	require.NoError(t, s.storage.CreateClient(ctx, storage.Client{
+		ID:           "app",
+		Secret:       "secret",
+		RedirectURIs: []string{"https://app.example.com/callback"},
+	}))
+	newAuthRequest := func(id string, loggedIn bool) {
+		require.NoError(t, s.storage.CreateAuthRequest(ctx, storage.AuthRequest{
+			ID:            id,
+			ClientID:      "app",
+			ConnectorID:   "mock",
+			RedirectURI:   "https://app.example.com/callback",
+			Scopes:        []string{"openid"},
+			LoggedIn:      loggedIn,
+			HMACKey:       []byte("key"),
+			Expiry:        time.Now().Add(time.Minute),
+			ResponseTypes: []string{responseTypeCode},
+		}))
+	}
+
+	// A login through the connector.
+	newAuthRequest("login", false)
+	rr := httptest.NewRecorder()
+	s.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/callback?state=login", nil))
+	require.Equal(t, http.StatusSeeOther, rr.Code)
+	require.Equal(t, 1.0, testutil.ToFloat64(m.logins.WithLabelValues("mock", "success")))
+	require.Equal(t, 1, testutil.CollectAndCount(m.connectorDuration))
+
+	// Exchanging the code is a token grant, using it again isn't.
+	u, err := url.Parse(rr.Header().Get("Location"))
+	require.NoError(t, err)
+	form := url.Values{
+		"grant_type":   {grantTypeAuthorizationCode},
+		"code":         {u.Query().Get("code")},
+		"redirect_uri": {"https://app.example.com/callback"},
+	}
+	for range 2 {
+		req := httptest.NewRequest(http.MethodPost, "/token", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth("app", "secret")
+		s.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	require.Equal(t, 1.0, testutil.ToFloat64(m.tokenGrants.WithLabelValues(grantTypeAuthorizationCode)))
+
+	// Denying the approval.
+	newAuthRequest("denied", true)
+	h := hmac.New(sha256.New, []byte("key"))
+	h.Write([]byte("denied"))
+	approval := url.Values{"req": {"denied"}, "hmac": {base64.RawURLEncoding.EncodeToString(h.Sum(nil))}, "approval": {"rejected"}}
+	req := httptest.NewRequest(http.MethodPost, "/approval", strings.NewReader(approval.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	s.ServeHTTP(httptest.NewRecorder(), req)
+	require.Equal(t, 1.0, testutil.ToFloat64(m.approvalDenials.WithLabelValues("app")))
+
+	// Denying a device request.
+	expiry := time.Now().Add(time.Minute)
+	require.NoError(t, s.storage.CreateDeviceRequest(ctx, storage.DeviceRequest{UserCode: "ABCD-WXYZ", DeviceCode: "device", ClientID: "app", Expiry: expiry}))
+	require.NoError(t, s.storage.CreateDeviceToken(ctx, storage.DeviceToken{DeviceCode: "device", Status: deviceTokenPending, Expiry: expiry}))
+	require.NoError(t, s.denyDeviceRequest("ABCD-WXYZ"))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.deviceCompletions.WithLabelValues("denied")))
+}
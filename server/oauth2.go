@@ -14,9 +14,11 @@ import (
 	"fmt"
 	"hash"
 	"io"
+	"maps"
 	"net"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -25,6 +27,7 @@ import (
 
 	"github.com/dexidp/dex/connector"
 	"github.com/dexidp/dex/server/internal"
+	"github.com/dexidp/dex/server/signer"
 	"github.com/dexidp/dex/storage"
 )
 
@@ -52,6 +55,9 @@ type redirectedAuthErr struct {
 	RedirectURI string
 	Type        string
 	Description string
+
+	// Issuer identifying the authorization server in the response, see RFC 9207.
+	Issuer string
 }
 
 func (err *redirectedAuthErr) Error() string {
@@ -66,6 +72,9 @@ func (err *redirectedAuthErr) Handler() http.Handler {
 		if err.Description != "" {
 			v.Add("error_description", err.Description)
 		}
+		if err.Issuer != "" {
+			v.Add("iss", err.Issuer)
+		}
 		var redirectURI string
 		if strings.Contains(err.RedirectURI, "?") {
 			redirectURI = err.RedirectURI + "&" + v.Encode()
@@ -115,6 +124,7 @@ const (
 	scopeEmail             = "email"
 	scopeProfile           = "profile"
 	scopeFederatedID       = "federated:id"
+	scopeSessions          = "dex:sessions" // Manage the sessions of the user.
 	scopeCrossClientPrefix = "audience:server:client_id:"
 )
 
@@ -158,6 +168,7 @@ const (
 const (
 	deviceTokenPending  = "authorization_pending"
 	deviceTokenComplete = "complete"
+	deviceTokenDenied   = "access_denied"
 	deviceTokenSlowDown = "slow_down"
 	deviceTokenExpired  = "expired_token"
 )
@@ -210,9 +221,19 @@ func signatureAlgorithm(jwk *jose.JSONWebKey) (alg jose.SignatureAlgorithm, err
 }
 
 func signPayload(key *jose.JSONWebKey, alg jose.SignatureAlgorithm, payload []byte) (jws string, err error) {
+	return signTypedPayload(key, alg, "", payload)
+}
+
+// signTypedPayload signs a payload, setting the "typ" header unless typ is
+// empty.
+func signTypedPayload(key *jose.JSONWebKey, alg jose.SignatureAlgorithm, typ string, payload []byte) (jws string, err error) {
 	signingKey := jose.SigningKey{Key: key, Algorithm: alg}
 
-	signer, err := jose.NewSigner(signingKey, &jose.SignerOptions{})
+	opts := &jose.SignerOptions{}
+	if typ != "" {
+		opts = opts.WithType(jose.ContentType(typ))
+	}
+	signer, err := jose.NewSigner(signingKey, opts)
 	if err != nil {
 		return "", fmt.Errorf("new signer: %v", err)
 	}
@@ -303,8 +324,34 @@ type federatedIDClaims struct {
 	UserID      string `json:"user_id,omitempty"`
 }
 
-func (s *Server) newAccessToken(ctx context.Context, clientID string, claims storage.Claims, scopes []string, nonce, connID string) (accessToken string, expiry time.Time, err error) {
-	return s.newIDToken(ctx, clientID, claims, scopes, nonce, storage.NewID(), "", connID)
+// newAccessToken creates an access token. Since the userinfo endpoint returns
+// the claims of the access token, requested should hold the claims requested
+// for the userinfo endpoint.
+func (s *Server) newAccessToken(ctx context.Context, clientID string, claims storage.Claims, scopes []string, requested map[string]*claimRequest, nonce, connID string) (accessToken string, expiry time.Time, err error) {
+	ctx, span := s.startSpan(ctx, "token.IssueAccessToken", attrClientID.String(clientID), attrConnectorID.String(connID))
+	defer func() { endSpan(span, err) }()
+
+	client := s.tokenClient(ctx, clientID)
+	claims.Groups = s.filterClientGroups(ctx, client, claims.Groups)
+	validFor := s.clientTokenLifetimes(ctx, client).accessTokens
+	accessToken, expiry, err = s.newToken(ctx, "access_token", client, validFor, clientID, claims, scopes, requested, nonce, storage.NewID(), "", connID)
+	if err == nil {
+		s.emitTokenIssued(ctx, "access_token", clientID, claims, scopes, connID)
+	}
+	return accessToken, expiry, err
+}
+
+// tokenClient returns the client tokens are issued to, or nil if it can't be
+// found. Tokens for unknown clients are issued with the server defaults.
+func (s *Server) tokenClient(ctx context.Context, clientID string) *storage.Client {
+	client, err := s.storage.GetClient(clientID)
+	if err != nil {
+		if err != storage.ErrNotFound {
+			s.logger.ErrorContext(ctx, "failed to get client, using token defaults", "client_id", clientID, "err", err)
+		}
+		return nil
+	}
+	return &client
 }
 
 func getClientID(aud audience, azp string) (string, error) {
@@ -350,24 +397,44 @@ func genSubject(userID string, connID string) (string, error) {
 	return internal.Marshal(sub)
 }
 
-func (s *Server) newIDToken(ctx context.Context, clientID string, claims storage.Claims, scopes []string, nonce, accessToken, code, connID string) (idToken string, expiry time.Time, err error) {
-	keys, err := s.storage.GetKeys()
-	if err != nil {
-		s.logger.ErrorContext(ctx, "failed to get keys", "err", err)
-		return "", expiry, err
-	}
+func (s *Server) newIDToken(ctx context.Context, clientID string, claims storage.Claims, scopes []string, requested map[string]*claimRequest, nonce, accessToken, code, connID string) (idToken string, expiry time.Time, err error) {
+	ctx, span := s.startSpan(ctx, "token.IssueIDToken", attrClientID.String(clientID), attrConnectorID.String(connID))
+	defer func() { endSpan(span, err) }()
 
-	signingKey := keys.SigningKey
-	if signingKey == nil {
-		return "", expiry, fmt.Errorf("no key to sign payload with")
+	client := s.tokenClient(ctx, clientID)
+	claims.Groups = s.filterClientGroups(ctx, client, claims.Groups)
+	validFor := s.clientTokenLifetimes(ctx, client).idTokens
+	idToken, expiry, err = s.newToken(ctx, "id_token", client, validFor, clientID, claims, scopes, requested, nonce, accessToken, code, connID)
+	if err == nil {
+		s.emitTokenIssued(ctx, "id_token", clientID, claims, scopes, connID)
 	}
-	signingAlg, err := signatureAlgorithm(signingKey)
+	return idToken, expiry, err
+}
+
+func (s *Server) emitTokenIssued(ctx context.Context, tokenType, clientID string, claims storage.Claims, scopes []string, connID string) {
+	s.emitEvent(ctx, auditEvent{
+		Type:        eventTokenIssued,
+		ClientID:    clientID,
+		ConnectorID: connID,
+		UserID:      claims.UserID,
+		Username:    claims.Username,
+		Scopes:      scopes,
+		Details:     map[string]string{"token_type": tokenType},
+	})
+}
+
+// newToken creates a signed token in the format of an ID token, which expires
+// after validFor. The client is nil if it couldn't be found. The token type,
+// "id_token" or "access_token", is passed on to the token webhook.
+func (s *Server) newToken(ctx context.Context, tokenType string, client *storage.Client, validFor time.Duration, clientID string, claims storage.Claims, scopes []string, requested map[string]*claimRequest, nonce, accessToken, code, connID string) (idToken string, expiry time.Time, err error) {
+	signingAlg, err := s.signer.Algorithm(ctx)
 	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to get signing algorithm", "err", err)
 		return "", expiry, err
 	}
 
 	issuedAt := s.now()
-	expiry = issuedAt.Add(s.idTokensValidFor)
+	expiry = issuedAt.Add(validFor)
 
 	subjectString, err := genSubject(claims.UserID, connID)
 	if err != nil {
@@ -435,6 +502,11 @@ func (s *Server) newIDToken(ctx context.Context, clientID string, claims storage
 	}
 
 	tok.Audience = getAudience(clientID, scopes)
+	// Access tokens for the sessions endpoint name it as their audience, so
+	// that access tokens given to other parties can't be used there.
+	if tokenType == "access_token" && slices.Contains(scopes, scopeSessions) {
+		tok.Audience = append(tok.Audience, s.sessionsAudience())
+	}
 	if len(tok.Audience) > 1 {
 		// The current client becomes the authorizing party.
 		tok.AuthorizingParty = clientID
@@ -445,12 +517,60 @@ func (s *Server) newIDToken(ctx context.Context, clientID string, claims storage
 		return "", expiry, fmt.Errorf("could not serialize claims: %v", err)
 	}
 
-	if idToken, err = signPayload(signingKey, signingAlg, payload); err != nil {
+	extra := s.customScopeClaims(claims, scopes)
+	maps.Copy(extra, requestedClaims(claims, requested))
+	distributed, covered := s.distributedClaims(client, claims, scopes, requested)
+	maps.Copy(extra, distributed)
+	if len(extra) > 0 {
+		if payload, err = mergeClaims(payload, extra, covered); err != nil {
+			return "", expiry, fmt.Errorf("could not serialize custom claims: %v", err)
+		}
+	}
+
+	if payload, err = s.transformClaims(payload, client, clientID, claims, scopes, connID); err != nil {
+		s.logger.ErrorContext(ctx, "failed to transform claims", "client_id", clientID, "err", err)
+		return "", expiry, fmt.Errorf("could not transform claims: %v", err)
+	}
+
+	if s.tokenWebhook != nil {
+		if payload, err = s.callTokenWebhook(ctx, tokenType, payload, clientID, claims, scopes, connID); err != nil {
+			return "", expiry, err
+		}
+	}
+
+	if s.authzPolicy != nil {
+		if payload, err = s.applyTokenPolicy(ctx, tokenType, payload, client, clientID, claims, scopes, connID); err != nil {
+			return "", expiry, err
+		}
+	}
+
+	// Access tokens carry a type, so that ID tokens given to relying parties
+	// can't be used in their place where only access tokens are accepted.
+	if tokenType == "access_token" {
+		idToken, err = s.signer.SignTyped(ctx, accessTokenHeaderType, payload)
+	} else {
+		idToken, err = s.signer.Sign(ctx, payload)
+	}
+	if err != nil {
 		return "", expiry, fmt.Errorf("failed to sign payload: %v", err)
 	}
 	return idToken, expiry, nil
 }
 
+// accessTokenHeaderType is the "typ" header of access tokens (RFC 9068).
+const accessTokenHeaderType = "at+jwt"
+
+// jwsHeaderType returns the "typ" header of a JWS, or an empty string if it
+// has none or can't be parsed.
+func jwsHeaderType(rawJWS string) string {
+	jws, err := jose.ParseSigned(rawJWS, []jose.SignatureAlgorithm{jose.RS256, jose.RS384, jose.RS512, jose.ES256, jose.ES384, jose.ES512})
+	if err != nil || len(jws.Signatures) != 1 {
+		return ""
+	}
+	typ, _ := jws.Signatures[0].Header.ExtraHeaders[jose.HeaderType].(string)
+	return typ
+}
+
 // parse the initial request from the OAuth2 client.
 func (s *Server) parseAuthorizationRequest(r *http.Request) (*storage.AuthRequest, error) {
 	if err := r.ParseForm(); err != nil {
@@ -495,7 +615,7 @@ func (s *Server) parseAuthorizationRequest(r *http.Request) (*storage.AuthReques
 
 	// From here on out, we want to redirect back to the client with an error.
 	newRedirectedErr := func(typ, format string, a ...interface{}) *redirectedAuthErr {
-		return &redirectedAuthErr{state, redirectURI, typ, fmt.Sprintf(format, a...)}
+		return &redirectedAuthErr{state, redirectURI, typ, fmt.Sprintf(format, a...), s.issuerURL.String()}
 	}
 
 	if connectorID != "" {
@@ -515,6 +635,11 @@ func (s *Server) parseAuthorizationRequest(r *http.Request) (*storage.AuthReques
 		return nil, newRedirectedErr(errRequestNotSupported, "Server does not support request parameter.")
 	}
 
+	claimsReq, err := parseClaimsRequest(q.Get("claims"))
+	if err != nil {
+		return nil, newRedirectedErr(errInvalidRequest, "Invalid claims parameter.")
+	}
+
 	if codeChallengeMethod != codeChallengeMethodS256 && codeChallengeMethod != codeChallengeMethodPlain {
 		description := fmt.Sprintf("Unsupported PKCE challenge method (%q).", codeChallengeMethod)
 		return nil, newRedirectedErr(errInvalidRequest, description)
@@ -529,8 +654,11 @@ func (s *Server) parseAuthorizationRequest(r *http.Request) (*storage.AuthReques
 		switch scope {
 		case scopeOpenID:
 			hasOpenIDScope = true
-		case scopeOfflineAccess, scopeEmail, scopeProfile, scopeGroups, scopeFederatedID:
+		case scopeOfflineAccess, scopeEmail, scopeProfile, scopeGroups, scopeFederatedID, scopeSessions:
 		default:
+			if _, ok := s.customScopes[scope]; ok {
+				continue
+			}
 			peerID, ok := parseCrossClientScope(scope)
 			if !ok {
 				unrecognized = append(unrecognized, scope)
@@ -612,6 +740,7 @@ func (s *Server) parseAuthorizationRequest(r *http.Request) (*storage.AuthReques
 		Nonce:               nonce,
 		ForceApprovalPrompt: q.Get("approval_prompt") == "force",
 		Scopes:              scopes,
+		ClaimsRequest:       claimsReq,
 		RedirectURI:         redirectURI,
 		ResponseTypes:       responseTypes,
 		ConnectorID:         connectorID,
@@ -658,9 +787,24 @@ func validateRedirectURI(client storage.Client, redirectURI string) bool {
 			return true
 		}
 	}
+	for _, pattern := range client.RedirectURIPatterns {
+		if matchRedirectURIPattern(pattern, redirectURI) {
+			return true
+		}
+	}
+	// Native apps listen on an ephemeral port of the loopback interface, so
+	// the port of registered loopback redirect URIs is ignored for public
+	// clients, as recommended by RFC 8252 section 7.3.
+	if client.Public {
+		for _, uri := range client.RedirectURIs {
+			if matchLoopbackRedirectURI(uri, redirectURI) {
+				return true
+			}
+		}
+	}
 	// For non-public clients or when RedirectURIs is set, we allow only explicitly named RedirectURIs.
 	// Otherwise, we check below for special URIs used for desktop or mobile apps.
-	if !client.Public || len(client.RedirectURIs) > 0 {
+	if !client.Public || len(client.RedirectURIs) > 0 || len(client.RedirectURIPatterns) > 0 {
 		return false
 	}
 
@@ -680,6 +824,108 @@ func validateRedirectURI(client storage.Client, redirectURI string) bool {
 	return isHostLocal(u.Host)
 }
 
+// ValidateRedirectURIPatterns checks the redirect URI patterns of a client.
+// Patterns must use https, must not contain userinfo or a fragment, and may
+// only have a single wildcard as the leftmost label of a host with at least
+// two further labels, so "https://*.com/" is rejected.
+func ValidateRedirectURIPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := parseRedirectURIPattern(pattern); err != nil {
+			return fmt.Errorf("invalid redirect URI pattern %q: %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+func parseRedirectURIPattern(pattern string) (*url.URL, error) {
+	if strings.Count(pattern, "*") != 1 {
+		return nil, errors.New("pattern must contain exactly one wildcard")
+	}
+	u, err := url.Parse(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "https" {
+		return nil, errors.New("only https redirect URIs may contain a wildcard")
+	}
+	if u.User != nil {
+		return nil, errors.New("userinfo is not allowed")
+	}
+	if u.Fragment != "" || strings.HasSuffix(pattern, "#") {
+		return nil, errors.New("fragments are not allowed")
+	}
+	labels := strings.Split(u.Hostname(), ".")
+	if labels[0] != "*" {
+		return nil, errors.New("the wildcard must be the leftmost label of the host")
+	}
+	if len(labels) < 3 {
+		return nil, errors.New("the wildcard must be followed by at least two labels")
+	}
+	for _, label := range labels[1:] {
+		if !isDNSLabel(label) {
+			return nil, fmt.Errorf("invalid host label %q", label)
+		}
+	}
+	return u, nil
+}
+
+// matchRedirectURIPattern reports whether a redirect URI matches a pattern
+// registered by a client. The wildcard matches exactly one DNS label, every
+// other part of the URI has to match exactly.
+func matchRedirectURIPattern(pattern, redirectURI string) bool {
+	p, err := parseRedirectURIPattern(pattern)
+	if err != nil {
+		return false
+	}
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return false
+	}
+	if u.Scheme != p.Scheme || u.User != nil || u.Fragment != "" || u.Opaque != "" {
+		return false
+	}
+	if u.Port() != p.Port() || u.EscapedPath() != p.EscapedPath() || u.RawQuery != p.RawQuery {
+		return false
+	}
+
+	suffix := strings.ToLower(strings.TrimPrefix(p.Hostname(), "*"))
+	host := strings.ToLower(u.Hostname())
+	if !strings.HasSuffix(host, suffix) {
+		return false
+	}
+	return isDNSLabel(strings.TrimSuffix(host, suffix))
+}
+
+// isDNSLabel reports whether s is a valid hostname label.
+func isDNSLabel(s string) bool {
+	if len(s) == 0 || len(s) > 63 || s[0] == '-' || s[len(s)-1] == '-' {
+		return false
+	}
+	for _, c := range s {
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// matchLoopbackRedirectURI reports whether a redirect URI matches a registered
+// loopback IP redirect URI, like "http://127.0.0.1/callback" or
+// "http://[::1]/callback", on any port.
+func matchLoopbackRedirectURI(registered, redirectURI string) bool {
+	r, err := url.Parse(registered)
+	if err != nil || r.Scheme != "http" || !net.ParseIP(r.Hostname()).IsLoopback() {
+		return false
+	}
+	u, err := url.Parse(redirectURI)
+	if err != nil || u.Scheme != "http" || u.User != nil || u.Fragment != "" {
+		return false
+	}
+	return u.Hostname() == r.Hostname() &&
+		u.EscapedPath() == r.EscapedPath() &&
+		u.RawQuery == r.RawQuery
+}
+
 func isHostLocal(host string) bool {
 	if host == "localhost" || net.ParseIP(host).IsLoopback() {
 		return true
@@ -702,12 +948,12 @@ func validateConnectorID(connectors []storage.Connector, connectorID string) boo
 	return false
 }
 
-// storageKeySet implements the oidc.KeySet interface backed by Dex storage
-type storageKeySet struct {
-	storage.Storage
+// signerKeySet implements the oidc.KeySet interface backed by the server's signer
+type signerKeySet struct {
+	signer signer.Signer
 }
 
-func (s *storageKeySet) VerifySignature(_ context.Context, jwt string) (payload []byte, err error) {
+func (s *signerKeySet) VerifySignature(ctx context.Context, jwt string) (payload []byte, err error) {
 	jws, err := jose.ParseSigned(jwt, []jose.SignatureAlgorithm{jose.RS256, jose.RS384, jose.RS512, jose.ES256, jose.ES384, jose.ES512})
 	if err != nil {
 		return nil, err
@@ -719,16 +965,11 @@ func (s *storageKeySet) VerifySignature(_ context.Context, jwt string) (payload
 		break
 	}
 
-	skeys, err := s.Storage.GetKeys()
+	keys, err := s.signer.ValidationKeys(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	keys := []*jose.JSONWebKey{skeys.SigningKeyPub}
-	for _, vk := range skeys.VerificationKeys {
-		keys = append(keys, vk.PublicKey)
-	}
-
 	for _, key := range keys {
 		if keyID == "" || key.KeyID == keyID {
 			if payload, err := jws.Verify(key); err == nil {
@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-jose/go-jose/v4"
 	"github.com/stretchr/testify/require"
@@ -363,6 +364,9 @@ func TestParseAuthorizationRequest(t *testing.T) {
 					if e.RedirectURI != tc.queryParams["redirect_uri"] {
 						t.Errorf("%s: expected error to be returned in redirect to %v", tc.name, tc.queryParams["redirect_uri"])
 					}
+					if e.Issuer != server.issuerURL.String() {
+						t.Errorf("%s: expected error to identify issuer %v, got %v", tc.name, server.issuerURL.String(), e.Issuer)
+					}
 				case *displayedAuthErr:
 					e, ok := err.(*displayedAuthErr)
 					if !ok {
@@ -572,6 +576,62 @@ func TestValidRedirectURI(t *testing.T) {
 			redirectURI: "http://localhost",
 			wantValid:   true,
 		},
+		// Registered loopback IP URIs of public clients match any port.
+		{
+			client: storage.Client{
+				Public:       true,
+				RedirectURIs: []string{"http://127.0.0.1/callback"},
+			},
+			redirectURI: "http://127.0.0.1:49152/callback",
+			wantValid:   true,
+		},
+		{
+			client: storage.Client{
+				Public:       true,
+				RedirectURIs: []string{"http://127.0.0.1:8080/callback"},
+			},
+			redirectURI: "http://127.0.0.1:49152/callback",
+			wantValid:   true,
+		},
+		{
+			client: storage.Client{
+				Public:       true,
+				RedirectURIs: []string{"http://[::1]/callback"},
+			},
+			redirectURI: "http://[::1]:49152/callback",
+			wantValid:   true,
+		},
+		{
+			client: storage.Client{
+				Public:       true,
+				RedirectURIs: []string{"http://127.0.0.1/callback"},
+			},
+			redirectURI: "http://127.0.0.1:49152/other",
+			wantValid:   false,
+		},
+		{
+			client: storage.Client{
+				Public:       true,
+				RedirectURIs: []string{"http://127.0.0.1/callback"},
+			},
+			redirectURI: "http://[::1]:49152/callback",
+			wantValid:   false,
+		},
+		{
+			client: storage.Client{
+				Public:       true,
+				RedirectURIs: []string{"http://127.0.0.1/callback"},
+			},
+			redirectURI: "https://127.0.0.1:49152/callback",
+			wantValid:   false,
+		},
+		{
+			client: storage.Client{
+				RedirectURIs: []string{"http://127.0.0.1/callback"},
+			},
+			redirectURI: "http://127.0.0.1:49152/callback",
+			wantValid:   false,
+		},
 		// Non-localhost URIs are not allowed implicitly.
 		{
 			client: storage.Client{
@@ -587,6 +647,86 @@ func TestValidRedirectURI(t *testing.T) {
 			redirectURI: "http://localhost.localhost:8080/",
 			wantValid:   false,
 		},
+		// Redirect URI patterns match exactly one label in place of the wildcard.
+		{
+			client: storage.Client{
+				RedirectURIPatterns: []string{"https://*.preview.example.com/callback"},
+			},
+			redirectURI: "https://pr-123.preview.example.com/callback",
+			wantValid:   true,
+		},
+		{
+			client: storage.Client{
+				RedirectURIPatterns: []string{"https://*.preview.example.com/callback"},
+			},
+			redirectURI: "https://PR-123.Preview.Example.com/callback",
+			wantValid:   true,
+		},
+		{
+			client: storage.Client{
+				RedirectURIPatterns: []string{"https://*.preview.example.com/callback"},
+			},
+			redirectURI: "https://a.b.preview.example.com/callback",
+			wantValid:   false,
+		},
+		{
+			client: storage.Client{
+				RedirectURIPatterns: []string{"https://*.preview.example.com/callback"},
+			},
+			redirectURI: "https://preview.example.com/callback",
+			wantValid:   false,
+		},
+		{
+			client: storage.Client{
+				RedirectURIPatterns: []string{"https://*.preview.example.com/callback"},
+			},
+			redirectURI: "http://pr-123.preview.example.com/callback",
+			wantValid:   false,
+		},
+		{
+			client: storage.Client{
+				RedirectURIPatterns: []string{"https://*.preview.example.com/callback"},
+			},
+			redirectURI: "https://user@pr-123.preview.example.com/callback",
+			wantValid:   false,
+		},
+		{
+			client: storage.Client{
+				RedirectURIPatterns: []string{"https://*.preview.example.com/callback"},
+			},
+			redirectURI: "https://pr-123.preview.example.com:8443/callback",
+			wantValid:   false,
+		},
+		{
+			client: storage.Client{
+				RedirectURIPatterns: []string{"https://*.preview.example.com/callback"},
+			},
+			redirectURI: "https://pr-123.preview.example.com/callback/other",
+			wantValid:   false,
+		},
+		{
+			client: storage.Client{
+				RedirectURIPatterns: []string{"https://*.preview.example.com/callback"},
+			},
+			redirectURI: "https://evil.com#.preview.example.com/callback",
+			wantValid:   false,
+		},
+		{
+			client: storage.Client{
+				Public:              true,
+				RedirectURIPatterns: []string{"https://*.preview.example.com/callback"},
+			},
+			redirectURI: "http://localhost:8080/",
+			wantValid:   false,
+		},
+		// Invalid patterns never match.
+		{
+			client: storage.Client{
+				RedirectURIPatterns: []string{"https://*.com/callback"},
+			},
+			redirectURI: "https://example.com/callback",
+			wantValid:   false,
+		},
 	}
 	for _, test := range tests {
 		got := validateRedirectURI(test.client, test.redirectURI)
@@ -597,7 +737,7 @@ func TestValidRedirectURI(t *testing.T) {
 	}
 }
 
-func TestStorageKeySet(t *testing.T) {
+func TestSignerKeySet(t *testing.T) {
 	s := memory.New(logger)
 	if err := s.UpdateKeys(func(keys storage.Keys) (storage.Keys, error) {
 		keys.SigningKey = &jose.JSONWebKey{
@@ -671,7 +811,7 @@ func TestStorageKeySet(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			keySet := &storageKeySet{s}
+			keySet := &signerKeySet{newLocalSigner(s, staticRotationStrategy(testKey), time.Now, logger)}
 
 			_, err = keySet.VerifySignature(context.Background(), jwt)
 			if (err != nil && !tc.wantErr) || (err == nil && tc.wantErr) {
@@ -680,3 +820,28 @@ func TestStorageKeySet(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateRedirectURIPatterns(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		wantValid bool
+	}{
+		{pattern: "https://*.preview.example.com/callback", wantValid: true},
+		{pattern: "https://*.example.com:8443/callback?tenant=a", wantValid: true},
+		{pattern: "https://pr-*.example.com/callback", wantValid: false},
+		{pattern: "https://*.com/callback", wantValid: false},
+		{pattern: "https://*.*.example.com/callback", wantValid: false},
+		{pattern: "https://app.*.example.com/callback", wantValid: false},
+		{pattern: "https://*.example.com/*", wantValid: false},
+		{pattern: "http://*.example.com/callback", wantValid: false},
+		{pattern: "https://user@*.example.com/callback", wantValid: false},
+		{pattern: "https://*.example.com/callback#fragment", wantValid: false},
+		{pattern: "https://example.com/callback", wantValid: false},
+	}
+	for _, test := range tests {
+		err := ValidateRedirectURIPatterns([]string{test.pattern})
+		if valid := err == nil; valid != test.wantValid {
+			t.Errorf("pattern=%q, wanted valid=%t, got err=%v", test.pattern, test.wantValid, err)
+		}
+	}
+}
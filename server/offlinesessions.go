@@ -0,0 +1,59 @@
+package server
+
+import (
+	"sort"
+
+	"github.com/dexidp/dex/storage"
+)
+
+// refreshTokenRefKey returns the key of a new refresh token reference in an
+// offline session. With a single session per client, references are keyed by
+// client ID, so a new refresh token takes the place of the previous one.
+// Otherwise they are keyed by refresh token ID.
+func (s *Server) refreshTokenRefKey(ref storage.RefreshTokenRef) string {
+	if s.maxSessionsPerClient <= 1 {
+		return ref.ClientID
+	}
+	return ref.ID
+}
+
+// evictedRefreshTokenRefs returns the keys of the oldest refresh token
+// references of a client, which have to be removed from an offline session
+// to make room for a new refresh token.
+func (s *Server) evictedRefreshTokenRefs(session storage.OfflineSessions, clientID string) []string {
+	keys := clientRefreshTokenRefs(session, clientID)
+	if n := len(keys) - s.maxSessionsPerClient + 1; n > 0 {
+		return keys[:n]
+	}
+	return nil
+}
+
+// clientRefreshTokenRefs returns the keys of the refresh token references of
+// a client in an offline session, oldest first.
+func clientRefreshTokenRefs(session storage.OfflineSessions, clientID string) []string {
+	var keys []string
+	for key, ref := range session.Refresh {
+		if ref != nil && ref.ClientID == clientID {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := session.Refresh[keys[i]], session.Refresh[keys[j]]
+		if a.CreatedAt.Equal(b.CreatedAt) {
+			return a.ID < b.ID
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+	return keys
+}
+
+// findRefreshTokenRef returns the reference to a refresh token in an offline
+// session, or nil if the session doesn't hold it.
+func findRefreshTokenRef(session storage.OfflineSessions, refresh *storage.RefreshToken) *storage.RefreshTokenRef {
+	for _, ref := range session.Refresh {
+		if ref != nil && ref.ID == refresh.ID && ref.ClientID == refresh.ClientID {
+			return ref
+		}
+	}
+	return nil
+}
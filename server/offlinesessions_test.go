@@ -0,0 +1,80 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dexidp/dex/storage"
+)
+
+func TestEvictedRefreshTokenRefs(t *testing.T) {
+	t0 := time.Now()
+	session := storage.OfflineSessions{
+		UserID: "1",
+		ConnID: "mock",
+		Refresh: map[string]*storage.RefreshTokenRef{
+			// Sessions created before the limit was raised are keyed by client ID.
+			"client1": {ID: "a", ClientID: "client1", CreatedAt: t0},
+			"c":       {ID: "c", ClientID: "client1", CreatedAt: t0.Add(2 * time.Minute)},
+			"b":       {ID: "b", ClientID: "client1", CreatedAt: t0.Add(time.Minute)},
+			"client2": {ID: "d", ClientID: "client2", CreatedAt: t0},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		max      int
+		clientID string
+		want     []string
+	}{
+		{
+			name:     "single session",
+			max:      1,
+			clientID: "client1",
+			want:     []string{"client1", "b", "c"},
+		},
+		{
+			name:     "oldest sessions",
+			max:      2,
+			clientID: "client1",
+			want:     []string{"client1", "b"},
+		},
+		{
+			name:     "below the limit",
+			max:      4,
+			clientID: "client1",
+		},
+		{
+			name:     "other client",
+			max:      1,
+			clientID: "client2",
+			want:     []string{"client2"},
+		},
+		{
+			name:     "new client",
+			max:      1,
+			clientID: "client3",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &Server{maxSessionsPerClient: tc.max}
+			require.Equal(t, tc.want, s.evictedRefreshTokenRefs(session, tc.clientID))
+		})
+	}
+
+	require.Equal(t, session.Refresh["b"], findRefreshTokenRef(session, &storage.RefreshToken{ID: "b", ClientID: "client1"}))
+	require.Nil(t, findRefreshTokenRef(session, &storage.RefreshToken{ID: "b", ClientID: "client2"}))
+}
+
+func TestRefreshTokenRefKey(t *testing.T) {
+	ref := storage.RefreshTokenRef{ID: "a", ClientID: "client1"}
+
+	s := &Server{maxSessionsPerClient: 1}
+	require.Equal(t, "client1", s.refreshTokenRefKey(ref))
+
+	s = &Server{maxSessionsPerClient: 3}
+	require.Equal(t, "a", s.refreshTokenRefKey(ref))
+}
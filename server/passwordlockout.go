@@ -0,0 +1,265 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dexidp/dex/storage"
+)
+
+// PasswordLockoutConfig protects the local password database against password
+// guessing. After Threshold consecutive failed logins, logins of a user are
+// rejected for Duration, which doubles with every further failure up to
+// MaxDuration. IP addresses failing PerInstanceIPThreshold logins at one
+// instance of the server are locked out the same way. Locked out logins fail
+// like wrong passwords, so that lockouts don't reveal which accounts exist.
+type PasswordLockoutConfig struct {
+	// Consecutive failed logins at which a user is locked out. Defaults to 5.
+	// The failures of static passwords, which can't be updated in storage, are
+	// counted in memory like those of IP addresses.
+	Threshold int
+
+	// Failed logins from an IP address at which it's locked out. Defaults to
+	// 20, as many users may share an address. Unlike the failures of users,
+	// which are kept in storage, the failures of IP addresses are counted in
+	// memory by every instance on its own, so with N instances an address may
+	// fail up to N times as many logins. They're forgotten after MaxDuration
+	// without failures.
+	PerInstanceIPThreshold int
+
+	// How long the first lockout lasts. Defaults to 1 minute.
+	Duration time.Duration
+
+	// Longest lockout. Defaults to 1 hour, or Duration if it's longer.
+	MaxDuration time.Duration
+}
+
+// passwordLockedError is returned by check while the user or the IP address
+// is locked out.
+type passwordLockedError struct {
+	until time.Time
+}
+
+func (e *passwordLockedError) Error() string {
+	return "too many failed logins, locked until " + e.until.Format(time.RFC3339)
+}
+
+type passwordLockout struct {
+	threshold   int
+	ipThreshold int
+	duration    time.Duration
+	maxDuration time.Duration
+
+	now       func() time.Time
+	logger    *slog.Logger
+	emitEvent func(ctx context.Context, e auditEvent)
+
+	mu sync.Mutex
+	// Failures counted in memory, of IP addresses and of static passwords by
+	// lower cased e-mail address.
+	ips       map[string]*memoryFailures
+	users     map[string]*memoryFailures
+	lastSweep time.Time
+}
+
+// memoryFailures are the failed logins counted in memory.
+type memoryFailures struct {
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+func newPasswordLockout(c *PasswordLockoutConfig, now func() time.Time, logger *slog.Logger, emitEvent func(context.Context, auditEvent)) (*passwordLockout, error) {
+	if c.Threshold < 0 || c.PerInstanceIPThreshold < 0 || c.Duration < 0 || c.MaxDuration < 0 {
+		return nil, errors.New("password lockout: limits must not be negative")
+	}
+	duration := value(c.Duration, time.Minute)
+	l := &passwordLockout{
+		threshold:   c.Threshold,
+		ipThreshold: c.PerInstanceIPThreshold,
+		duration:    duration,
+		maxDuration: value(c.MaxDuration, max(duration, time.Hour)),
+		now:         now,
+		logger:      logger,
+		emitEvent:   emitEvent,
+		ips:         make(map[string]*memoryFailures),
+		users:       make(map[string]*memoryFailures),
+	}
+	if l.threshold == 0 {
+		l.threshold = 5
+	}
+	if l.ipThreshold == 0 {
+		l.ipThreshold = 20
+	}
+	if l.maxDuration < l.duration {
+		return nil, errors.New("password lockout: max duration must not be shorter than the duration")
+	}
+	return l, nil
+}
+
+// lockDuration returns how long to lock out after a number of failures, zero
+// if they're below the threshold.
+func (l *passwordLockout) lockDuration(failures, threshold int) time.Duration {
+	if failures < threshold {
+		return 0
+	}
+	d := l.duration
+	for i := threshold; i < failures && d < l.maxDuration; i++ {
+		d *= 2
+	}
+	return min(d, l.maxDuration)
+}
+
+// check returns a passwordLockedError if logins of a user, or from an IP
+// address, are locked out. The user is nil if the login is for an unknown
+// e-mail address.
+func (l *passwordLockout) check(ip string, p *storage.Password) error {
+	if l == nil {
+		return nil
+	}
+	now := l.now()
+	if p != nil && p.LockedUntil.After(now) {
+		return &passwordLockedError{until: p.LockedUntil}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if f, ok := l.ips[ip]; ok && f.lockedUntil.After(now) {
+		return &passwordLockedError{until: f.lockedUntil}
+	}
+	if p != nil {
+		if f, ok := l.users[strings.ToLower(p.Email)]; ok && f.lockedUntil.After(now) {
+			return &passwordLockedError{until: f.lockedUntil}
+		}
+	}
+	return nil
+}
+
+// failed counts a failed login for a user, unless the e-mail address is
+// unknown, and from an IP address, locking them out once they reach their
+// thresholds.
+func (l *passwordLockout) failed(ctx context.Context, s storage.Storage, ip string, p *storage.Password) error {
+	if l == nil {
+		return nil
+	}
+	if ip != "" {
+		if failures, lockedUntil, locked := l.memoryFailed(l.ips, ip, l.ipThreshold); locked {
+			l.logger.WarnContext(ctx, "password logins from IP address locked out", "ip", ip, "failures", failures,
+				"locked_until", lockedUntil)
+			details := lockedDetails("remote_ip", failures, lockedUntil)
+			details["remote_ip"] = ip
+			l.emitEvent(ctx, auditEvent{
+				Type:        eventPasswordLocked,
+				ConnectorID: LocalConnector,
+				Details:     details,
+			})
+		}
+	}
+	if p == nil {
+		return nil
+	}
+
+	var locked storage.Password
+	err := s.UpdatePassword(p.Email, func(old storage.Password) (storage.Password, error) {
+		old.FailedAttempts++
+		locked = storage.Password{}
+		if d := l.lockDuration(old.FailedAttempts, l.threshold); d > 0 {
+			old.LockedUntil = l.now().Add(d)
+			locked = old
+		}
+		return old, nil
+	})
+	if errors.Is(err, storage.ErrReadOnly) {
+		// Static passwords can't be updated, so their failures are counted in
+		// memory instead.
+		locked = storage.Password{}
+		if failures, lockedUntil, ok := l.memoryFailed(l.users, strings.ToLower(p.Email), l.threshold); ok {
+			locked = *p
+			locked.FailedAttempts, locked.LockedUntil = failures, lockedUntil
+		}
+	} else if err != nil {
+		return err
+	}
+	if locked.Email != "" {
+		l.logger.WarnContext(ctx, "password login locked out", "email", locked.Email, "failures", locked.FailedAttempts,
+			"locked_until", locked.LockedUntil)
+		l.emitEvent(ctx, auditEvent{
+			Type:        eventPasswordLocked,
+			ConnectorID: LocalConnector,
+			UserID:      locked.UserID,
+			Username:    locked.Email,
+			Details:     lockedDetails("username", locked.FailedAttempts, locked.LockedUntil),
+		})
+	}
+	return nil
+}
+
+// memoryFailed counts a failure in memory, reporting the failures and if it
+// locked the key out.
+func (l *passwordLockout) memoryFailed(m map[string]*memoryFailures, key string, threshold int) (int, time.Time, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	// Failures are forgotten after the longest lockout without failures.
+	if now.Sub(l.lastSweep) > l.maxDuration {
+		for _, failures := range []map[string]*memoryFailures{l.ips, l.users} {
+			for k, f := range failures {
+				if now.Sub(f.lastFailure) > l.maxDuration && !f.lockedUntil.After(now) {
+					delete(failures, k)
+				}
+			}
+		}
+		l.lastSweep = now
+	}
+	f, ok := m[key]
+	if !ok || now.Sub(f.lastFailure) > l.maxDuration {
+		f = &memoryFailures{}
+		m[key] = f
+	}
+	f.failures++
+	f.lastFailure = now
+	d := l.lockDuration(f.failures, threshold)
+	if d > 0 {
+		f.lockedUntil = now.Add(d)
+	}
+	return f.failures, f.lockedUntil, d > 0
+}
+
+// succeeded resets the failed logins of a user after a successful login.
+func (l *passwordLockout) succeeded(s storage.Storage, p storage.Password) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	delete(l.users, strings.ToLower(p.Email))
+	l.mu.Unlock()
+	if p.FailedAttempts == 0 && p.LockedUntil.IsZero() {
+		return nil
+	}
+	return s.UpdatePassword(p.Email, func(old storage.Password) (storage.Password, error) {
+		old.FailedAttempts = 0
+		old.LockedUntil = time.Time{}
+		return old, nil
+	})
+}
+
+func lockedDetails(key string, failures int, until time.Time) map[string]string {
+	return map[string]string{
+		"key":          key,
+		"failures":     strconv.Itoa(failures),
+		"locked_until": until.UTC().Format(time.RFC3339),
+	}
+}
+
+// requestRemoteIP returns the IP address of the client of the HTTP request a
+// context belongs to, or an empty string.
+func requestRemoteIP(ctx context.Context) string {
+	if req, ok := ctx.Value(auditRequestKey{}).(auditRequest); ok {
+		return req.remoteIP
+	}
+	return ""
+}
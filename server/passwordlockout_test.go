@@ -0,0 +1,197 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/dexidp/dex/connector"
+	"github.com/dexidp/dex/storage"
+)
+
+func TestPasswordLockout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	httpServer, s := newTestServer(ctx, t, func(c *Config) {
+		c.Now = func() time.Time { return now }
+		c.PasswordLockout = &PasswordLockoutConfig{Threshold: 3, PerInstanceIPThreshold: 5, Duration: time.Minute, MaxDuration: 3 * time.Minute}
+	})
+	defer httpServer.Close()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.DefaultCost)
+	require.NoError(t, err)
+	require.NoError(t, s.storage.CreatePassword(ctx, storage.Password{Email: "jane@example.com", Hash: hash, Username: "jane", UserID: "1"}))
+
+	events, unsubscribe := s.events.subscribe()
+	defer unsubscribe()
+	lockedEvents := func() []auditEvent {
+		var got []auditEvent
+		for {
+			select {
+			case e := <-events:
+				if e.Type == eventPasswordLocked {
+					got = append(got, e)
+				}
+			case <-time.After(100 * time.Millisecond):
+				return got
+			}
+		}
+	}
+
+	db := passwordDB{s: s.storage, lockout: s.passwordLockout}
+	login := func(email, password, ip string) (bool, error) {
+		req := httptest.NewRequest(http.MethodPost, "/auth/local/login", nil)
+		req.RemoteAddr = ip + ":1234"
+		_, ok, err := db.Login(withAuditRequest(req), connector.Scopes{}, email, password)
+		return ok, err
+	}
+
+	for i := 0; i < 2; i++ {
+		ok, err := login("jane@example.com", "wrong", "192.0.2.1")
+		require.NoError(t, err)
+		require.False(t, ok)
+	}
+	require.Empty(t, lockedEvents())
+
+	// A successful login resets the failures.
+	ok, err := login("jane@example.com", "secret", "192.0.2.1")
+	require.NoError(t, err)
+	require.True(t, ok)
+	p, err := s.storage.GetPassword("jane@example.com")
+	require.NoError(t, err)
+	require.Zero(t, p.FailedAttempts)
+
+	// The third consecutive failure locks the user out.
+	for i := 0; i < 3; i++ {
+		ok, err := login("jane@example.com", "wrong", "192.0.2.2")
+		require.NoError(t, err)
+		require.False(t, ok)
+	}
+	got := lockedEvents()
+	require.Len(t, got, 1)
+	require.Equal(t, "1", got[0].UserID)
+	require.Equal(t, map[string]string{"key": "username", "failures": "3", "locked_until": "2024-01-01T00:01:00Z"}, got[0].Details)
+
+	// The correct password is rejected during a lockout, the same way as a
+	// wrong one, so that lockouts don't reveal which accounts exist.
+	ok, err = login("jane@example.com", "secret", "192.0.2.3")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// Every further failure doubles the lockout, up to the maximum.
+	for _, want := range []time.Duration{2 * time.Minute, 3 * time.Minute, 3 * time.Minute} {
+		now = now.Add(4 * time.Minute)
+		ok, err := login("jane@example.com", "wrong", "192.0.2.4")
+		require.NoError(t, err)
+		require.False(t, ok)
+		p, err := s.storage.GetPassword("jane@example.com")
+		require.NoError(t, err)
+		require.Equal(t, now.Add(want), p.LockedUntil)
+	}
+	require.Len(t, lockedEvents(), 3)
+
+	now = now.Add(4 * time.Minute)
+	ok, err = login("jane@example.com", "secret", "192.0.2.4")
+	require.NoError(t, err)
+	require.True(t, ok)
+	p, err = s.storage.GetPassword("jane@example.com")
+	require.NoError(t, err)
+	require.Zero(t, p.FailedAttempts)
+	require.True(t, p.LockedUntil.IsZero())
+
+	// IP addresses are locked out after failures for any user, including
+	// unknown ones.
+	for i := 0; i < 5; i++ {
+		ok, err := login("nobody@example.com", "wrong", "192.0.2.5")
+		require.NoError(t, err)
+		require.False(t, ok)
+	}
+	got = lockedEvents()
+	require.Len(t, got, 1)
+	require.Equal(t, "192.0.2.5", got[0].Details["remote_ip"])
+	ok, err = login("jane@example.com", "secret", "192.0.2.5")
+	require.NoError(t, err)
+	require.False(t, ok)
+	ok, err = login("jane@example.com", "secret", "192.0.2.6")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	now = now.Add(time.Minute)
+	ok, err = login("jane@example.com", "secret", "192.0.2.5")
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestPasswordLockoutStaticPasswords(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	httpServer, s := newTestServer(ctx, t, func(c *Config) {
+		c.Now = func() time.Time { return now }
+		c.PasswordLockout = &PasswordLockoutConfig{Threshold: 2, PerInstanceIPThreshold: 3, Duration: time.Minute, MaxDuration: 3 * time.Minute}
+	})
+	defer httpServer.Close()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.DefaultCost)
+	require.NoError(t, err)
+	static := storage.WithStaticPasswords(s.storage, []storage.Password{
+		{Email: "admin@example.com", Hash: hash, Username: "admin", UserID: "1"},
+	}, s.logger)
+
+	db := passwordDB{s: static, lockout: s.passwordLockout}
+	login := func(email, password, ip string) (bool, error) {
+		req := httptest.NewRequest(http.MethodPost, "/auth/local/login", nil)
+		req.RemoteAddr = ip + ":1234"
+		_, ok, err := db.Login(withAuditRequest(req), connector.Scopes{}, email, password)
+		return ok, err
+	}
+
+	// Static passwords can't be updated, so their failures are counted in
+	// memory.
+	for i := 0; i < 2; i++ {
+		ok, err := login("admin@example.com", "wrong", "192.0.2.1")
+		require.NoError(t, err)
+		require.False(t, ok)
+	}
+	ok, err := login("Admin@example.com", "secret", "192.0.2.2")
+	require.NoError(t, err)
+	require.False(t, ok, "the correct password is rejected during a lockout")
+
+	// The failures of the IP address are counted too.
+	ok, err = login("nobody@example.com", "wrong", "192.0.2.1")
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Error(t, s.passwordLockout.check("192.0.2.1", nil))
+
+	now = now.Add(time.Minute)
+	ok, err = login("admin@example.com", "secret", "192.0.2.2")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// A successful login resets the failures.
+	ok, err = login("admin@example.com", "wrong", "192.0.2.2")
+	require.NoError(t, err)
+	require.False(t, ok)
+	ok, err = login("admin@example.com", "secret", "192.0.2.2")
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestNewPasswordLockout(t *testing.T) {
+	for _, c := range []PasswordLockoutConfig{
+		{Threshold: -1},
+		{Duration: -time.Minute},
+		{Duration: time.Hour, MaxDuration: time.Minute},
+	} {
+		_, err := newPasswordLockout(&c, time.Now, nil, nil)
+		require.Error(t, err, "%+v", c)
+	}
+}
@@ -0,0 +1,214 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// defaultBreachedPasswordsURL is the range API of Have I Been Pwned.
+const defaultBreachedPasswordsURL = "https://api.pwnedpasswords.com/range/"
+
+// PasswordPolicyConfig is the policy passwords chosen by users of the local
+// password database have to meet, when they register, reset their password, or
+// when a plain text password is created through the gRPC API.
+type PasswordPolicyConfig struct {
+	// Minimum number of characters. Defaults to 8. Passwords can't be longer
+	// than 72 bytes, the most bcrypt uses.
+	MinLength int
+
+	// Number of character classes, of lowercase and uppercase letters, digits
+	// and symbols, the password must contain. Defaults to none.
+	MinCharacterClasses int
+
+	// If set, the password must not contain the username or the e-mail
+	// address, or its local part, of the user.
+	DisallowUserInfo bool
+
+	// If set, passwords found in data breaches are rejected. Only the first 5
+	// characters of the SHA-1 hash of the password are sent to the range API
+	// at BreachedPasswordsURL, which returns the suffixes of the hashes of
+	// breached passwords starting with them (k-anonymity). Passwords are
+	// accepted if the API can't be reached.
+	CheckBreached bool
+
+	// URL of the range API, to which the prefix of the hash is appended.
+	// Defaults to the API of Have I Been Pwned.
+	BreachedPasswordsURL string
+
+	// How long to wait for the range API. Defaults to 5 seconds.
+	BreachedPasswordsTimeout time.Duration
+}
+
+// passwordViolation is why a password doesn't meet the password policy. The
+// message can be translated and shown to the user.
+type passwordViolation struct {
+	msg  string
+	args []interface{}
+}
+
+func (v *passwordViolation) Error() string {
+	return fmt.Sprintf(v.msg, v.args...)
+}
+
+type passwordPolicy struct {
+	minLength           int
+	minCharacterClasses int
+	disallowUserInfo    bool
+
+	// Empty if breached passwords aren't checked.
+	breachedURL string
+	client      *http.Client
+}
+
+func newPasswordPolicy(c *PasswordPolicyConfig) (*passwordPolicy, error) {
+	if c.MinLength < 0 || c.MinLength > 72 {
+		return nil, errors.New("password policy: min length must be between 0 and 72")
+	}
+	if c.MinCharacterClasses < 0 || c.MinCharacterClasses > 4 {
+		return nil, errors.New("password policy: min character classes must be between 0 and 4")
+	}
+	if c.BreachedPasswordsTimeout < 0 {
+		return nil, errors.New("password policy: breached passwords timeout must not be negative")
+	}
+	p := &passwordPolicy{
+		minLength:           c.MinLength,
+		minCharacterClasses: c.MinCharacterClasses,
+		disallowUserInfo:    c.DisallowUserInfo,
+	}
+	if p.minLength == 0 {
+		p.minLength = 8
+	}
+	if c.CheckBreached {
+		p.breachedURL = c.BreachedPasswordsURL
+		if p.breachedURL == "" {
+			p.breachedURL = defaultBreachedPasswordsURL
+		}
+		u, err := url.Parse(p.breachedURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("password policy: invalid breached passwords url %q: must be an absolute http or https url", p.breachedURL)
+		}
+		p.client = &http.Client{Timeout: value(c.BreachedPasswordsTimeout, 5*time.Second)}
+	}
+	return p, nil
+}
+
+// check returns why a new password of a user doesn't meet the policy, or nil.
+func (p *passwordPolicy) check(ctx context.Context, logger *slog.Logger, password, email, username string) *passwordViolation {
+	switch {
+	case utf8.RuneCountInString(password) < p.minLength:
+		return &passwordViolation{msg: "The password must be at least %d characters long.", args: []interface{}{p.minLength}}
+	case len(password) > 72:
+		// bcrypt only uses the first 72 bytes.
+		return &passwordViolation{msg: "The password must be at most 72 characters long."}
+	case characterClasses(password) < p.minCharacterClasses:
+		return &passwordViolation{
+			msg:  "The password must contain at least %d of lowercase letters, uppercase letters, digits and symbols.",
+			args: []interface{}{p.minCharacterClasses},
+		}
+	case p.disallowUserInfo && containsUserInfo(password, email, username):
+		return &passwordViolation{msg: "The password must not contain your username or e-mail address."}
+	}
+
+	if p.breachedURL != "" {
+		breached, err := p.breached(ctx, password)
+		if err != nil {
+			logger.WarnContext(ctx, "failed to check for breached password, accepting it", "err", err)
+		} else if breached {
+			return &passwordViolation{msg: "This password has appeared in a data breach, please choose another one."}
+		}
+	}
+	return nil
+}
+
+// characterClasses returns how many of lowercase letters, uppercase letters,
+// digits and other characters a password contains.
+func characterClasses(password string) int {
+	var lower, upper, digit, symbol int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+	return lower + upper + digit + symbol
+}
+
+// containsUserInfo returns if a password contains the username, the e-mail
+// address or its local part, ignoring case. Parts shorter than 3 characters
+// are ignored, as they'd reject too many passwords.
+func containsUserInfo(password, email, username string) bool {
+	password = strings.ToLower(password)
+	local, _, _ := strings.Cut(email, "@")
+	for _, s := range []string{username, email, local} {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if utf8.RuneCountInString(s) >= 3 && strings.Contains(password, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// breached queries the range API for the hash of a password, returning if
+// it's been found in a breach.
+func (p *passwordPolicy) breached(ctx context.Context, password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.breachedURL+prefix, nil)
+	if err != nil {
+		return false, err
+	}
+	// Padding hides the number of suffixes returned for the prefix.
+	req.Header.Set("Add-Padding", "true")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		// Lines are "SUFFIX:COUNT", padding has a count of 0.
+		s, count, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if ok && strings.EqualFold(s, suffix) {
+			return count != "0", nil
+		}
+	}
+	return false, scanner.Err()
+}
+
+// checkNewPassword returns why the password chosen by a user is rejected,
+// translated to the language of the request, or an empty string if it's
+// accepted.
+func (s *Server) checkNewPassword(r *http.Request, password, confirm, email, username string) string {
+	cat := s.templates.catalog
+	lang := cat.requestLanguage(r)
+	if v := s.passwordPolicy.check(r.Context(), s.logger, password, email, username); v != nil {
+		return cat.translate(lang, v.msg, v.args...)
+	}
+	if password != confirm {
+		return cat.translate(lang, "The passwords don't match.")
+	}
+	return ""
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordPolicy(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	ctx := context.Background()
+
+	// SHA-1 of "correct horse battery staple" is
+	// ABF7AAD6438836DBE526AA231ABDE2D0EEF74D42.
+	var prefixes []string
+	rangeAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "true", r.Header.Get("Add-Padding"))
+		if strings.HasPrefix(r.URL.Path, "/unavailable/") {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		prefixes = append(prefixes, strings.TrimPrefix(r.URL.Path, "/range/"))
+		fmt.Fprint(w, "0018A45C4D1DEF81644B54AB7F969B88D65:1\r\nAD6438836DBE526AA231ABDE2D0EEF74D42:3645\r\n00D4F6E8FA6EECAD2A3AA415EEC418D38EC:0\r\n")
+	}))
+	defer rangeAPI.Close()
+
+	p, err := newPasswordPolicy(&PasswordPolicyConfig{
+		MinLength:            10,
+		MinCharacterClasses:  3,
+		DisallowUserInfo:     true,
+		CheckBreached:        true,
+		BreachedPasswordsURL: rangeAPI.URL + "/range/",
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		password string
+		want     string
+	}{
+		{"Sh0rt!", "The password must be at least 10 characters long."},
+		{strings.Repeat("Aa1!", 19), "The password must be at most 72 characters long."},
+		{"onlylowercase1", "The password must contain at least 3 of lowercase letters, uppercase letters, digits and symbols."},
+		{"My-Jane.Doe-2024", "The password must not contain your username or e-mail address."},
+		{"Example.com-2024", ""},
+		{"correct horse battery staple", "The password must contain at least 3 of lowercase letters, uppercase letters, digits and symbols."},
+		{"Tr0ub4dor&3xyz", ""},
+	}
+	for _, tc := range tests {
+		v := p.check(ctx, logger, tc.password, "jane.doe@example.com", "jdoe")
+		if tc.want == "" {
+			require.Nil(t, v, tc.password)
+			continue
+		}
+		require.NotNil(t, v, tc.password)
+		require.Equal(t, tc.want, v.Error())
+	}
+
+	p.minCharacterClasses = 0
+	v := p.check(ctx, logger, "correct horse battery staple", "jane.doe@example.com", "jdoe")
+	require.NotNil(t, v)
+	require.Equal(t, "This password has appeared in a data breach, please choose another one.", v.Error())
+	require.Equal(t, "ABF7A", prefixes[len(prefixes)-1], "only the prefix of the hash is sent")
+
+	// Passwords are accepted if the range API fails.
+	p.breachedURL = rangeAPI.URL + "/unavailable/"
+	require.Nil(t, p.check(ctx, logger, "correct horse battery staple", "jane.doe@example.com", "jdoe"))
+}
+
+func TestNewPasswordPolicy(t *testing.T) {
+	p, err := newPasswordPolicy(&PasswordPolicyConfig{CheckBreached: true})
+	require.NoError(t, err)
+	require.Equal(t, 8, p.minLength)
+	require.Equal(t, defaultBreachedPasswordsURL, p.breachedURL)
+
+	for _, c := range []PasswordPolicyConfig{
+		{MinLength: -1},
+		{MinLength: 73},
+		{MinCharacterClasses: 5},
+		{CheckBreached: true, BreachedPasswordsURL: "api.pwnedpasswords.com/range/"},
+		{CheckBreached: true, BreachedPasswordsTimeout: -time.Second},
+	} {
+		_, err := newPasswordPolicy(&c)
+		require.Error(t, err, "%+v", c)
+	}
+}
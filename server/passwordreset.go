@@ -0,0 +1,425 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/time/rate"
+
+	"github.com/dexidp/dex/storage"
+)
+
+// Types of the tokens sent by e-mail, telling them apart from the other
+// payloads signed by the server.
+const (
+	passwordResetTokenType     = "password_reset"
+	registrationTokenType      = "registration"
+	emailVerificationTokenType = "email_verification"
+)
+
+// emailTokenPaths are the pages the links of each type of e-mail token lead
+// to, whose URLs are the audiences of the tokens.
+var emailTokenPaths = map[string]string{
+	passwordResetTokenType:     "/reset/confirm",
+	registrationTokenType:      "/register/confirm",
+	emailVerificationTokenType: "/verify",
+}
+
+// PasswordResetConfig lets users of the local password database reset their
+// password with a link sent to them by e-mail.
+type PasswordResetConfig struct {
+	// Sends the e-mails with the reset links.
+	Mailer Mailer
+
+	// How long reset links are valid. Defaults to 1 hour.
+	ValidFor time.Duration
+
+	// Number of reset e-mails sent to an address per hour. Defaults to 3.
+	EmailsPerHour int
+
+	// Number of resets requested from an IP address per hour. Defaults to 30.
+	RequestsPerHour int
+}
+
+type passwordReset struct {
+	mailer   Mailer
+	validFor time.Duration
+
+	emailLimiter *hourlyLimiter
+	ipLimiter    *hourlyLimiter
+}
+
+func newPasswordReset(c *PasswordResetConfig, now func() time.Time) (*passwordReset, error) {
+	if c.Mailer == nil {
+		return nil, errors.New("no mailer configured for password resets")
+	}
+	if c.ValidFor < 0 || c.EmailsPerHour < 0 || c.RequestsPerHour < 0 {
+		return nil, errors.New("password reset limits must not be negative")
+	}
+	emailsPerHour := c.EmailsPerHour
+	if emailsPerHour == 0 {
+		emailsPerHour = 3
+	}
+	requestsPerHour := c.RequestsPerHour
+	if requestsPerHour == 0 {
+		requestsPerHour = 30
+	}
+	return &passwordReset{
+		mailer:       c.Mailer,
+		validFor:     value(c.ValidFor, time.Hour),
+		emailLimiter: newHourlyLimiter(emailsPerHour, now),
+		ipLimiter:    newHourlyLimiter(requestsPerHour, now),
+	}, nil
+}
+
+// hourlyLimiter limits the number of events per key and hour.
+type hourlyLimiter struct {
+	limit rate.Limit
+	burst int
+	now   func() time.Time
+
+	mu        sync.Mutex
+	limiters  map[string]*apiLimiter
+	lastSweep time.Time
+}
+
+func newHourlyLimiter(perHour int, now func() time.Time) *hourlyLimiter {
+	return &hourlyLimiter{
+		limit:    rate.Every(time.Hour / time.Duration(perHour)),
+		burst:    perHour,
+		now:      now,
+		limiters: make(map[string]*apiLimiter),
+	}
+}
+
+func (l *hourlyLimiter) allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Limiters idle for an hour are full again and can be dropped.
+	now := l.now()
+	if now.Sub(l.lastSweep) > time.Hour {
+		for k, limiter := range l.limiters {
+			if now.Sub(limiter.lastSeen) > time.Hour {
+				delete(l.limiters, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	limiter, ok := l.limiters[key]
+	if !ok {
+		limiter = &apiLimiter{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.limiters[key] = limiter
+	}
+	limiter.lastSeen = now
+	return limiter.limiter.AllowN(now, 1)
+}
+
+// exhausted reports if events for key are currently not allowed, without
+// counting an event.
+func (l *hourlyLimiter) exhausted(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limiter, ok := l.limiters[key]
+	return ok && limiter.limiter.TokensAt(l.now()) < 1
+}
+
+// emailToken is the signed payload of the links sent by e-mail. Reset tokens
+// carry a fingerprint of the password hash, so that a link stops working once
+// the password was changed. Verification tokens carry the user ID, so that a
+// link doesn't verify an account created again for the same address.
+//
+// The tokens are signed with the keys of ID tokens, so they're bound to their
+// purpose by the "typ" header of the JWS, which ID tokens don't have, and by
+// their audience, the URL of the page of the link.
+type emailToken struct {
+	Type        string `json:"typ"`
+	Audience    string `json:"aud"`
+	Email       string `json:"email"`
+	UserID      string `json:"sub,omitempty"`
+	Fingerprint string `json:"fp,omitempty"`
+	Expiry      int64  `json:"exp"`
+}
+
+func passwordFingerprint(hash []byte) string {
+	sum := sha256.Sum256(hash)
+	return base64.RawURLEncoding.EncodeToString(sum[:16])
+}
+
+// emailTokenHeaderType returns the "typ" header of e-mail tokens of a type.
+func emailTokenHeaderType(typ string) string {
+	return "dex-" + strings.ReplaceAll(typ, "_", "-") + "+jwt"
+}
+
+func (s *Server) signEmailToken(ctx context.Context, token emailToken) (string, error) {
+	token.Audience = s.absURL(emailTokenPaths[token.Type])
+	payload, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+	return s.signer.SignTyped(ctx, emailTokenHeaderType(token.Type), payload)
+}
+
+func (s *Server) verifyEmailToken(ctx context.Context, typ, rawToken string) (emailToken, error) {
+	if jwsHeaderType(rawToken) != emailTokenHeaderType(typ) {
+		return emailToken{}, fmt.Errorf("not a %s token", typ)
+	}
+	payload, err := (&signerKeySet{s.signer}).VerifySignature(ctx, rawToken)
+	if err != nil {
+		return emailToken{}, fmt.Errorf("verify signature: %v", err)
+	}
+	var token emailToken
+	if err := json.Unmarshal(payload, &token); err != nil {
+		return emailToken{}, fmt.Errorf("decode token: %v", err)
+	}
+	if token.Type != typ || token.Audience != s.absURL(emailTokenPaths[typ]) {
+		return emailToken{}, fmt.Errorf("not a %s token", typ)
+	}
+	if s.now().Unix() > token.Expiry {
+		return emailToken{}, errors.New("token expired")
+	}
+	return token, nil
+}
+
+// verifyPasswordResetToken returns the password a reset token was issued for.
+func (s *Server) verifyPasswordResetToken(ctx context.Context, rawToken string) (storage.Password, error) {
+	token, err := s.verifyEmailToken(ctx, passwordResetTokenType, rawToken)
+	if err != nil {
+		return storage.Password{}, err
+	}
+	p, err := s.storage.GetPassword(token.Email)
+	if err != nil {
+		return storage.Password{}, fmt.Errorf("get password: %v", err)
+	}
+	if subtle.ConstantTimeCompare([]byte(passwordFingerprint(p.Hash)), []byte(token.Fingerprint)) != 1 {
+		return storage.Password{}, errors.New("password changed since the token was issued")
+	}
+	return p, nil
+}
+
+// loginBackLink returns the page users return to after a reset or a
+// registration, which has to be a login page of this server.
+func (s *Server) loginBackLink(r *http.Request) string {
+	back := r.FormValue("back")
+	u, err := url.Parse(back)
+	if err != nil || u.Scheme != "" || u.Host != "" || !strings.HasPrefix(u.Path, s.absPath("/auth")+"/") {
+		return ""
+	}
+	return back
+}
+
+// passwordResetURL returns the link to the reset page shown on the login form
+// of the local password database, if resets are enabled.
+func (s *Server) passwordResetURL(connID string, loginURL *url.URL) string {
+	if s.passwordReset == nil || connID != LocalConnector {
+		return ""
+	}
+	u := url.URL{
+		Path:     s.absPath("/reset"),
+		RawQuery: url.Values{"back": {loginURL.String()}}.Encode(),
+	}
+	return u.String()
+}
+
+// clientIP returns the IP address of the user agent sending the request.
+func clientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(RequestKeyRemoteIP).(string); ok {
+		return ip
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// handlePasswordResetRequest shows the form to request a reset link and
+// e-mails the link. The response doesn't reveal if an account exists.
+func (s *Server) handlePasswordResetRequest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tmpls := s.templates
+	backLink := s.loginBackLink(r)
+	postURL := s.absPath("/reset")
+
+	switch r.Method {
+	case http.MethodGet:
+		if err := tmpls.passwordReset(r, w, postURL, backLink, "", false, false, ""); err != nil {
+			s.logger.ErrorContext(ctx, "server template error", "err", err)
+		}
+	case http.MethodPost:
+		email := strings.TrimSpace(r.PostFormValue("email"))
+		if !s.passwordReset.ipLimiter.allow(clientIP(r)) {
+			s.logger.WarnContext(ctx, "password reset rate limit exceeded", "ip", clientIP(r))
+			w.WriteHeader(http.StatusTooManyRequests)
+			if err := tmpls.passwordReset(r, w, postURL, backLink, "", false, false, "Too many requests, please try again later."); err != nil {
+				s.logger.ErrorContext(ctx, "server template error", "err", err)
+			}
+			return
+		}
+		if email != "" {
+			s.sendPasswordResetEmail(r, email)
+		}
+		if err := tmpls.passwordReset(r, w, postURL, backLink, "", true, false, ""); err != nil {
+			s.logger.ErrorContext(ctx, "server template error", "err", err)
+		}
+	default:
+		s.renderError(r, w, http.StatusBadRequest, "Unsupported request method.")
+	}
+}
+
+// sendPasswordResetEmail e-mails a reset link if the address has a password.
+// The e-mail is sent in the background, so response times don't tell if the
+// account exists either.
+func (s *Server) sendPasswordResetEmail(r *http.Request, email string) {
+	ctx := r.Context()
+	p, err := s.storage.GetPassword(email)
+	if err != nil {
+		if err != storage.ErrNotFound {
+			s.logger.ErrorContext(ctx, "failed to get password", "err", err)
+		}
+		return
+	}
+	if !s.passwordReset.emailLimiter.allow(strings.ToLower(p.Email)) {
+		s.logger.WarnContext(ctx, "password reset e-mail rate limit exceeded", "user_id", p.UserID)
+		return
+	}
+
+	token, err := s.signEmailToken(ctx, emailToken{
+		Type:        passwordResetTokenType,
+		Email:       p.Email,
+		Fingerprint: passwordFingerprint(p.Hash),
+		Expiry:      s.now().Add(s.passwordReset.validFor).Unix(),
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to sign password reset token", "err", err)
+		return
+	}
+	link := s.issuerURL
+	link.Path = s.absPath("/reset/confirm")
+	link.RawQuery = url.Values{"token": {token}, "back": {s.loginBackLink(r)}}.Encode()
+
+	cat := s.templates.catalog
+	lang := cat.requestLanguage(r)
+	subject := cat.translate(lang, "Reset your password")
+	body := cat.translate(lang, "A password reset was requested for your account at %s. Open the following link within %d minutes to choose a new password:\n\n%s\n\nIf you didn't request this, you can ignore this e-mail.\n",
+		s.issuerURL.String(), int(s.passwordReset.validFor.Minutes()), link.String())
+
+	s.emitEvent(ctx, auditEvent{
+		Type:        eventPasswordResetRequested,
+		ConnectorID: LocalConnector,
+		UserID:      p.UserID,
+		Username:    p.Username,
+		Details:     map[string]string{"remote_ip": clientIP(r)},
+	})
+
+	s.sendMailInBackground(ctx, s.passwordReset.mailer, p.Email, subject, body)
+}
+
+// sendMailInBackground sends an e-mail without delaying the response.
+func (s *Server) sendMailInBackground(ctx context.Context, mailer Mailer, to, subject, body string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Minute)
+		defer cancel()
+		if err := mailer.SendMail(ctx, to, subject, body); err != nil {
+			s.logger.ErrorContext(ctx, "failed to send e-mail", "subject", subject, "err", err)
+		}
+	}()
+}
+
+// handlePasswordReset shows the form to choose a new password, reached
+// through the link of a reset e-mail, and stores the new password.
+func (s *Server) handlePasswordReset(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tmpls := s.templates
+	backLink := s.loginBackLink(r)
+	postURL := s.absPath("/reset/confirm")
+	rawToken := r.FormValue("token")
+
+	p, err := s.verifyPasswordResetToken(ctx, rawToken)
+	if err != nil {
+		s.logger.InfoContext(ctx, "invalid password reset token", "err", err)
+		s.renderError(r, w, http.StatusBadRequest, "Invalid or expired password reset link.")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if err := tmpls.passwordReset(r, w, postURL, backLink, rawToken, false, false, ""); err != nil {
+			s.logger.ErrorContext(ctx, "server template error", "err", err)
+		}
+	case http.MethodPost:
+		password := r.PostFormValue("password")
+		if invalid := s.checkNewPassword(r, password, r.PostFormValue("confirm"), p.Email, p.Username); invalid != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			if err := tmpls.passwordReset(r, w, postURL, backLink, rawToken, false, false, invalid); err != nil {
+				s.logger.ErrorContext(ctx, "server template error", "err", err)
+			}
+			return
+		}
+
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to hash password", "err", err)
+			s.renderError(r, w, http.StatusInternalServerError, "Internal server error.")
+			return
+		}
+		fingerprint := passwordFingerprint(p.Hash)
+		err = s.storage.UpdatePassword(p.Email, func(old storage.Password) (storage.Password, error) {
+			// The link may only be used once.
+			if passwordFingerprint(old.Hash) != fingerprint {
+				return old, errPasswordChanged
+			}
+			old.Hash = hash
+			// Following the link proved control of the address.
+			old.EmailVerified = true
+			// A new password ends a lockout.
+			old.FailedAttempts = 0
+			old.LockedUntil = time.Time{}
+			return old, nil
+		})
+		if err != nil {
+			if errors.Is(err, errPasswordChanged) || err == storage.ErrNotFound {
+				s.renderError(r, w, http.StatusBadRequest, "Invalid or expired password reset link.")
+				return
+			}
+			// Static passwords are set in the configuration.
+			if errors.Is(err, storage.ErrReadOnly) {
+				s.logger.InfoContext(ctx, "cannot reset static password", "user_id", p.UserID)
+				s.renderError(r, w, http.StatusBadRequest, "This password is managed by your administrator and can't be reset.")
+				return
+			}
+			s.logger.ErrorContext(ctx, "failed to update password", "err", err)
+			s.renderError(r, w, http.StatusInternalServerError, "Internal server error.")
+			return
+		}
+
+		s.logger.InfoContext(ctx, "password reset", "user_id", p.UserID)
+		s.emitEvent(ctx, auditEvent{
+			Type:        eventPasswordReset,
+			ConnectorID: LocalConnector,
+			UserID:      p.UserID,
+			Username:    p.Username,
+			Details:     map[string]string{"remote_ip": clientIP(r)},
+		})
+		if err := tmpls.passwordReset(r, w, postURL, backLink, "", false, true, ""); err != nil {
+			s.logger.ErrorContext(ctx, "server template error", "err", err)
+		}
+	default:
+		s.renderError(r, w, http.StatusBadRequest, "Unsupported request method.")
+	}
+}
+
+var errPasswordChanged = errors.New("password changed")
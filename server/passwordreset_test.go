@@ -0,0 +1,182 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/dexidp/dex/storage"
+)
+
+type sentMail struct {
+	to, subject, body string
+}
+
+type fakeMailer chan sentMail
+
+func (m fakeMailer) SendMail(_ context.Context, to, subject, body string) error {
+	m <- sentMail{to, subject, body}
+	return nil
+}
+
+func TestPasswordReset(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mailer := make(fakeMailer, 10)
+	httpServer, s := newTestServer(ctx, t, func(c *Config) {
+		c.PasswordReset = &PasswordResetConfig{Mailer: mailer, EmailsPerHour: 2}
+	})
+	defer httpServer.Close()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("old-password"), bcrypt.DefaultCost)
+	require.NoError(t, err)
+	require.NoError(t, s.storage.CreatePassword(ctx, storage.Password{
+		Email:    "jane@example.com",
+		Hash:     hash,
+		Username: "jane",
+		UserID:   "1",
+	}))
+
+	events, unsubscribe := s.events.subscribe()
+	defer unsubscribe()
+
+	post := func(path string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		s.ServeHTTP(rr, req)
+		return rr
+	}
+
+	back := "/auth/local/login?state=abc"
+	requestReset := func(email string) *httptest.ResponseRecorder {
+		return post("/reset", url.Values{"email": {email}, "back": {back}})
+	}
+
+	// Unknown addresses get the same answer, but no e-mail.
+	rr := requestReset("john@example.com")
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), "If an account exists")
+
+	rr = requestReset("jane@example.com")
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), "If an account exists")
+
+	var m sentMail
+	select {
+	case m = <-mailer:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reset e-mail sent")
+	}
+	require.Equal(t, "jane@example.com", m.to)
+	require.Len(t, mailer, 0)
+	require.Equal(t, eventPasswordResetRequested, (<-events).Type)
+
+	link := regexp.MustCompile(`https?://\S+/reset/confirm\S*`).FindString(m.body)
+	require.NotEmpty(t, link)
+	u, err := url.Parse(link)
+	require.NoError(t, err)
+	require.Equal(t, "/reset/confirm", u.Path)
+	require.Equal(t, back, u.Query().Get("back"))
+	token := u.Query().Get("token")
+
+	req := httptest.NewRequest(http.MethodGet, u.RequestURI(), nil)
+	rr = httptest.NewRecorder()
+	s.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), `name="token"`)
+
+	resetPassword := func(token, password, confirm string) *httptest.ResponseRecorder {
+		return post("/reset/confirm", url.Values{"token": {token}, "password": {password}, "confirm": {confirm}})
+	}
+
+	rr = resetPassword(token, "short", "short")
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "at least 8 characters")
+
+	rr = resetPassword(token, "new-password", "other-password")
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "don&#39;t match")
+
+	rr = resetPassword("invalid", "new-password", "new-password")
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = resetPassword(token, "new-password", "new-password")
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), "Your password has been reset.")
+	require.Equal(t, eventPasswordReset, (<-events).Type)
+
+	p, err := s.storage.GetPassword("jane@example.com")
+	require.NoError(t, err)
+	require.NoError(t, bcrypt.CompareHashAndPassword(p.Hash, []byte("new-password")))
+
+	// Links can only be used once.
+	rr = resetPassword(token, "third-password", "third-password")
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// The second e-mail of the hour is sent, the third isn't.
+	requestReset("jane@example.com")
+	requestReset("jane@example.com")
+	require.Len(t, events, 1)
+	require.Equal(t, eventPasswordResetRequested, (<-events).Type)
+}
+
+func TestPasswordResetStaticPassword(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("old-password"), bcrypt.DefaultCost)
+	require.NoError(t, err)
+	static := storage.Password{Email: "admin@example.com", Hash: hash, Username: "admin", UserID: "1"}
+
+	httpServer, s := newTestServer(ctx, t, func(c *Config) {
+		c.PasswordReset = &PasswordResetConfig{Mailer: make(fakeMailer, 1)}
+		c.Storage = storage.WithStaticPasswords(c.Storage, []storage.Password{static}, c.Logger)
+	})
+	defer httpServer.Close()
+
+	token, err := s.signEmailToken(ctx, emailToken{
+		Type:        passwordResetTokenType,
+		Email:       static.Email,
+		Fingerprint: passwordFingerprint(static.Hash),
+		Expiry:      s.now().Add(time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+
+	form := url.Values{"token": {token}, "password": {"new-password"}, "confirm": {"new-password"}}
+	req := httptest.NewRequest(http.MethodPost, "/reset/confirm", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr := httptest.NewRecorder()
+	s.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "managed by your administrator")
+}
+
+func TestLoginBackLink(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, s := newTestServer(ctx, t, func(c *Config) {
+		c.PasswordReset = &PasswordResetConfig{Mailer: make(fakeMailer, 1)}
+	})
+	defer httpServer.Close()
+
+	for back, want := range map[string]string{
+		"/auth/local/login?state=abc":     "/auth/local/login?state=abc",
+		"https://evil.example.com/auth/x": "",
+		"//evil.example.com/auth/x":       "",
+		"/token":                          "",
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/reset?"+url.Values{"back": {back}}.Encode(), nil)
+		require.Equal(t, want, s.loginBackLink(r), back)
+	}
+}
@@ -0,0 +1,174 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// Headers set by reverse proxies.
+const (
+	forwardedHeader       = "Forwarded"
+	forwardedHostHeader   = "X-Forwarded-Host"
+	forwardedProtoHeader  = "X-Forwarded-Proto"
+	forwardedPrefixHeader = "X-Forwarded-Prefix"
+)
+
+// trustsProxy reports whether requests from addr are forwarded by a trusted
+// proxy. Without trusted proxies, all requests are trusted.
+func trustsProxy(trusted []netip.Prefix, addr netip.Addr) bool {
+	if len(trusted) == 0 {
+		return true
+	}
+	for _, n := range trusted {
+		if n.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteAddr returns the IP address of the peer of a request.
+func remoteAddr(r *http.Request) (netip.Addr, error) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	return addr.Unmap(), nil
+}
+
+// forwardedClientIP returns the IP address of the client of a request, read
+// from a header listing the addresses a request was forwarded for, like
+// X-Forwarded-For, X-Real-IP or the Forwarded header of RFC 7239. Addresses
+// are read from the right, where the closest proxy appended them, until one
+// which isn't a trusted proxy, so clients can't spoof their address by
+// sending the header themselves.
+func forwardedClientIP(r *http.Request, header string, trusted []netip.Prefix) (string, error) {
+	client, err := remoteAddr(r)
+	if err != nil {
+		return "", err
+	}
+	if !trustsProxy(trusted, client) {
+		return client.String(), nil
+	}
+
+	var hops []string
+	if strings.EqualFold(header, forwardedHeader) {
+		for _, e := range parseForwarded(r.Header.Values(forwardedHeader)) {
+			hops = append(hops, e["for"])
+		}
+	} else {
+		for _, v := range r.Header.Values(header) {
+			hops = append(hops, strings.Split(v, ",")...)
+		}
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, ok := parseForwardedAddr(hops[i])
+		if !ok {
+			// Obfuscated or malformed, the last proxy knew the client.
+			break
+		}
+		client = addr
+		if len(trusted) == 0 || !trustsProxy(trusted, addr) {
+			break
+		}
+	}
+	return client.String(), nil
+}
+
+// parseForwardedAddr parses an address of a forwarded header, which may have
+// a port and IPv6 addresses may be in brackets.
+func parseForwardedAddr(s string) (netip.Addr, bool) {
+	s = strings.Trim(strings.TrimSpace(s), `"`)
+	if addrPort, err := netip.ParseAddrPort(s); err == nil {
+		return addrPort.Addr().Unmap(), true
+	}
+	addr, err := netip.ParseAddr(strings.TrimSuffix(strings.TrimPrefix(s, "["), "]"))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+// parseForwarded parses the elements of Forwarded headers, mapping the
+// lowercase parameters of each element to their unquoted values.
+func parseForwarded(values []string) []map[string]string {
+	var elements []map[string]string
+	for _, v := range values {
+		for _, element := range strings.Split(v, ",") {
+			params := make(map[string]string)
+			for _, pair := range strings.Split(element, ";") {
+				key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if !ok {
+					continue
+				}
+				params[strings.ToLower(key)] = strings.Trim(value, `"`)
+			}
+			elements = append(elements, params)
+		}
+	}
+	return elements
+}
+
+// NewForwardedHandler returns a handler applying the host, scheme and path
+// prefix forwarded by trusted proxies to requests before serving them with h.
+// Issuers are matched by the host clients requested and proxies may strip the
+// path of the issuer. The Forwarded header of RFC 7239 takes precedence over
+// X-Forwarded-Host, X-Forwarded-Proto and X-Forwarded-Prefix. The headers of
+// untrusted peers are ignored.
+func NewForwardedHandler(h http.Handler, trusted []netip.Prefix) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if addr, err := remoteAddr(r); err == nil && len(trusted) != 0 && trustsProxy(trusted, addr) {
+			r = applyForwarded(r)
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// applyForwarded returns a copy of a request with the host, scheme and path
+// prefix set by the proxy closest to the server. Proxies appending to the
+// headers keep the values sent by the client on the left, so only the
+// rightmost values are used.
+func applyForwarded(r *http.Request) *http.Request {
+	lastValue := func(header string) string {
+		values := r.Header.Values(header)
+		if len(values) == 0 {
+			return ""
+		}
+		v := values[len(values)-1]
+		if i := strings.LastIndex(v, ","); i >= 0 {
+			v = v[i+1:]
+		}
+		return strings.TrimSpace(v)
+	}
+	host, proto := lastValue(forwardedHostHeader), lastValue(forwardedProtoHeader)
+	if elements := parseForwarded(r.Header.Values(forwardedHeader)); len(elements) > 0 {
+		last := elements[len(elements)-1]
+		host, proto = last["host"], last["proto"]
+	}
+	prefix := strings.TrimSuffix(lastValue(forwardedPrefixHeader), "/")
+
+	u := *r.URL
+	r = r.WithContext(r.Context())
+	r.URL = &u
+	if host != "" && !strings.ContainsAny(host, "/\\@ ") {
+		r.Host = host
+		r.URL.Host = host
+	}
+	if proto == "http" || proto == "https" {
+		r.URL.Scheme = proto
+	}
+	if strings.HasPrefix(prefix, "/") && !hasPathPrefix(r.URL.Path, prefix) {
+		r.URL.Path = prefix + r.URL.Path
+		if r.URL.RawPath != "" {
+			r.URL.RawPath = prefix + r.URL.RawPath
+		}
+	}
+	return r
+}
@@ -0,0 +1,149 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestForwardedClientIP(t *testing.T) {
+	proxies := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		header     string
+		values     []string
+		trusted    []netip.Prefix
+		want       string
+	}{
+		{
+			name:       "untrusted peer",
+			remoteAddr: "192.0.2.1:1234",
+			header:     "X-Forwarded-For",
+			values:     []string{"198.51.100.1"},
+			trusted:    proxies,
+			want:       "192.0.2.1",
+		},
+		{
+			name:       "trusted peer",
+			remoteAddr: "10.0.0.1:1234",
+			header:     "X-Forwarded-For",
+			values:     []string{"198.51.100.1"},
+			trusted:    proxies,
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "spoofed address before the client",
+			remoteAddr: "10.0.0.1:1234",
+			header:     "X-Forwarded-For",
+			values:     []string{"203.0.113.7, 198.51.100.1, 10.0.0.2"},
+			trusted:    proxies,
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "several header lines",
+			remoteAddr: "10.0.0.1:1234",
+			header:     "X-Forwarded-For",
+			values:     []string{"198.51.100.1", "10.0.0.2"},
+			trusted:    proxies,
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "single value without trusted proxies",
+			remoteAddr: "192.0.2.1:1234",
+			header:     "X-Real-IP",
+			values:     []string{"198.51.100.1"},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "forwarded",
+			remoteAddr: "10.0.0.1:1234",
+			header:     "Forwarded",
+			values:     []string{`for="[2001:db8::1]:4711";proto=https, for=10.0.0.2;by=10.0.0.1`},
+			trusted:    proxies,
+			want:       "2001:db8::1",
+		},
+		{
+			name:       "obfuscated",
+			remoteAddr: "10.0.0.1:1234",
+			header:     "Forwarded",
+			values:     []string{"for=_hidden, for=10.0.0.2"},
+			trusted:    proxies,
+			want:       "10.0.0.2",
+		},
+		{
+			name:       "no header",
+			remoteAddr: "10.0.0.1:1234",
+			header:     "X-Forwarded-For",
+			trusted:    proxies,
+			want:       "10.0.0.1",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tc.remoteAddr
+			for _, v := range tc.values {
+				r.Header.Add(tc.header, v)
+			}
+			ip, err := forwardedClientIP(r, tc.header, tc.trusted)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, ip)
+		})
+	}
+}
+
+func TestForwardedHandler(t *testing.T) {
+	var got *http.Request
+	h := NewForwardedHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+	}), []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")})
+
+	serve := func(remoteAddr string, header http.Header) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "http://dex.internal/auth", nil)
+		r.RemoteAddr = remoteAddr
+		for k, v := range header {
+			r.Header[k] = v
+		}
+		h.ServeHTTP(httptest.NewRecorder(), r)
+		return got
+	}
+
+	r := serve("10.0.0.1:1234", http.Header{
+		"X-Forwarded-Host":   {"dex.example.com"},
+		"X-Forwarded-Proto":  {"https"},
+		"X-Forwarded-Prefix": {"/dex/"},
+	})
+	require.Equal(t, "dex.example.com", r.Host)
+	require.Equal(t, "https", r.URL.Scheme)
+	require.Equal(t, "/dex/auth", r.URL.Path)
+
+	// The Forwarded header takes precedence.
+	r = serve("10.0.0.1:1234", http.Header{
+		"Forwarded":        {`for=198.51.100.1;host="login.example.com";proto=https`},
+		"X-Forwarded-Host": {"dex.example.com"},
+	})
+	require.Equal(t, "login.example.com", r.Host)
+	require.Equal(t, "/auth", r.URL.Path)
+
+	// Values sent by the client and appended to by the proxy are ignored.
+	r = serve("10.0.0.1:1234", http.Header{
+		"X-Forwarded-Host":  {"evil.example.com, dex.example.com"},
+		"X-Forwarded-Proto": {"http", "https"},
+	})
+	require.Equal(t, "dex.example.com", r.Host)
+	require.Equal(t, "https", r.URL.Scheme)
+	r = serve("10.0.0.1:1234", http.Header{
+		"Forwarded": {`host=evil.example.com`, `for=198.51.100.1;host="login.example.com"`},
+	})
+	require.Equal(t, "login.example.com", r.Host)
+
+	// Untrusted peers can't change the host.
+	r = serve("192.0.2.1:1234", http.Header{"X-Forwarded-Host": {"evil.example.com"}})
+	require.Equal(t, "dex.internal", r.Host)
+	require.Equal(t, "http", r.URL.Scheme)
+}
@@ -0,0 +1,191 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Rate limits of the HTTP endpoints, as labeled in logs and metrics.
+const (
+	rateLimitIP         = "ip"
+	rateLimitClient     = "client"
+	rateLimitDeviceCode = "device_code"
+)
+
+// RateLimitConfig limits the rate of requests to the authorization, token and
+// device endpoints, so that misbehaving clients can't overload the storage.
+// Requests over a limit are answered with 429 Too Many Requests and a
+// Retry-After header. Limits which aren't set don't apply.
+type RateLimitConfig struct {
+	// Requests from each client IP address.
+	PerIP *RateLimit
+
+	// Requests of each client from each IP address. Requests are limited
+	// before clients are authenticated, so they're counted by the client ID
+	// the request claims together with the IP address, which keeps forged
+	// requests from other addresses from throttling a client.
+	PerClient *RateLimit
+
+	// Token polls of each device request, by its device code.
+	PerDeviceCode *RateLimit
+}
+
+// RateLimit is a sustained rate of requests with bursts.
+type RateLimit struct {
+	RequestsPerSecond float64
+
+	// Number of requests allowed at once, defaults to one second worth of
+	// requests.
+	Burst int
+}
+
+type httpRateLimiter struct {
+	perIP         *keyedLimiter
+	perClient     *keyedLimiter
+	perDeviceCode *keyedLimiter
+}
+
+func newHTTPRateLimiter(c *RateLimitConfig, now func() time.Time) (*httpRateLimiter, error) {
+	l := &httpRateLimiter{}
+	for _, limit := range []struct {
+		name    string
+		config  *RateLimit
+		limiter **keyedLimiter
+	}{
+		{rateLimitIP, c.PerIP, &l.perIP},
+		{rateLimitClient, c.PerClient, &l.perClient},
+		{rateLimitDeviceCode, c.PerDeviceCode, &l.perDeviceCode},
+	} {
+		if limit.config == nil {
+			continue
+		}
+		limiter, err := newKeyedLimiter(*limit.config, now)
+		if err != nil {
+			return nil, fmt.Errorf("rate limit per %s: %v", limit.name, err)
+		}
+		*limit.limiter = limiter
+	}
+	return l, nil
+}
+
+// check counts a request against the limits, and returns the limit it
+// exceeds and how long the client should wait, if any.
+func (l *httpRateLimiter) check(r *http.Request) (string, time.Duration) {
+	ip := clientIP(r)
+	if l.perIP != nil {
+		if wait := l.perIP.reserve(ip); wait > 0 {
+			return rateLimitIP, wait
+		}
+	}
+	if l.perClient != nil {
+		clientID, _, ok := r.BasicAuth()
+		if !ok {
+			clientID = r.FormValue("client_id")
+		}
+		if clientID != "" {
+			if wait := l.perClient.reserve(clientID + " " + ip); wait > 0 {
+				return rateLimitClient, wait
+			}
+		}
+	}
+	if l.perDeviceCode != nil && r.Method == http.MethodPost {
+		if deviceCode := r.PostFormValue("device_code"); deviceCode != "" {
+			if wait := l.perDeviceCode.reserve(deviceCode); wait > 0 {
+				return rateLimitDeviceCode, wait
+			}
+		}
+	}
+	return "", 0
+}
+
+// rateLimited applies the rate limits to the handler of an endpoint.
+func (s *Server) rateLimited(endpoint string, h http.HandlerFunc) http.HandlerFunc {
+	if s.rateLimiter == nil {
+		return h
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit, wait := s.rateLimiter.check(r)
+		if wait == 0 {
+			h(w, r)
+			return
+		}
+		s.metrics.observeRateLimited(endpoint, limit)
+		s.logger.WarnContext(r.Context(), "rate limit exceeded", "endpoint", endpoint, "limit", limit, "ip", clientIP(r))
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		if endpoint == "/auth" {
+			s.renderError(r, w, http.StatusTooManyRequests, "Too many requests, please try again later.")
+			return
+		}
+		s.tokenErrHelper(w, errTemporarilyUnavailable, "Rate limit exceeded.", http.StatusTooManyRequests)
+	}
+}
+
+// keyedLimiter limits the rate of requests per key, e.g. per IP address.
+type keyedLimiter struct {
+	limit rate.Limit
+	burst int
+	// Limiters idle for this long are full again and can be dropped.
+	idle time.Duration
+	now  func() time.Time
+
+	mu        sync.Mutex
+	limiters  map[string]*apiLimiter
+	lastSweep time.Time
+}
+
+func newKeyedLimiter(c RateLimit, now func() time.Time) (*keyedLimiter, error) {
+	if c.RequestsPerSecond <= 0 {
+		return nil, errors.New("requests per second must be positive")
+	}
+	if c.Burst < 0 {
+		return nil, errors.New("burst must not be negative")
+	}
+	burst := c.Burst
+	if burst == 0 {
+		burst = max(int(c.RequestsPerSecond), 1)
+	}
+	return &keyedLimiter{
+		limit:    rate.Limit(c.RequestsPerSecond),
+		burst:    burst,
+		idle:     time.Duration(float64(burst) / c.RequestsPerSecond * float64(time.Second)),
+		now:      now,
+		limiters: make(map[string]*apiLimiter),
+	}, nil
+}
+
+// reserve counts a request for key, returning zero if it's allowed and how
+// long to wait until it would be allowed otherwise.
+func (l *keyedLimiter) reserve(key string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) > l.idle {
+		for k, limiter := range l.limiters {
+			if now.Sub(limiter.lastSeen) > l.idle {
+				delete(l.limiters, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	limiter, ok := l.limiters[key]
+	if !ok {
+		limiter = &apiLimiter{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.limiters[key] = limiter
+	}
+	limiter.lastSeen = now
+	reservation := limiter.limiter.ReserveN(now, 1)
+	if wait := reservation.DelayFrom(now); wait > 0 {
+		reservation.CancelAt(now)
+		return wait
+	}
+	return 0
+}
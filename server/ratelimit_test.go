@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, s := newTestServer(ctx, t, func(c *Config) {
+		c.RateLimit = &RateLimitConfig{
+			PerIP:         &RateLimit{RequestsPerSecond: 0.1, Burst: 3},
+			PerClient:     &RateLimit{RequestsPerSecond: 0.1, Burst: 2},
+			PerDeviceCode: &RateLimit{RequestsPerSecond: 0.1, Burst: 1},
+		}
+	})
+	defer httpServer.Close()
+
+	post := func(path, ip string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.RemoteAddr = ip + ":1234"
+		rr := httptest.NewRecorder()
+		s.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Requests of a client from one IP address.
+	form := url.Values{"client_id": {"app"}}
+	require.NotEqual(t, http.StatusTooManyRequests, post("/token", "192.0.2.1", form).Code)
+	require.NotEqual(t, http.StatusTooManyRequests, post("/token", "192.0.2.1", form).Code)
+	rr := post("/token", "192.0.2.1", form)
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	require.Equal(t, "10", rr.Header().Get("Retry-After"))
+	require.Contains(t, rr.Body.String(), errTemporarilyUnavailable)
+	require.Equal(t, 1.0, testutil.ToFloat64(s.metrics.rateLimited.WithLabelValues("/token", rateLimitClient)))
+
+	// Requests forging the client ID, with or without basic auth, don't
+	// throttle the client at other IP addresses.
+	for range 2 {
+		req := httptest.NewRequest(http.MethodPost, "/token", nil)
+		req.SetBasicAuth("real", "forged")
+		req.RemoteAddr = "192.0.2.2:1234"
+		s.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	require.Equal(t, http.StatusTooManyRequests, post("/token", "192.0.2.2", url.Values{"client_id": {"real"}}).Code)
+	require.NotEqual(t, http.StatusTooManyRequests, post("/token", "192.0.2.3", url.Values{"client_id": {"real"}}).Code)
+
+	// Requests of other clients from one IP address.
+	for i, clientID := range []string{"a", "b"} {
+		rr := post("/device/code", "192.0.2.4", url.Values{"client_id": {clientID}})
+		require.NotEqual(t, http.StatusTooManyRequests, rr.Code, "request %d", i)
+	}
+	require.NotEqual(t, http.StatusTooManyRequests, post("/device/token", "192.0.2.4", url.Values{"device_code": {"1"}}).Code)
+	require.Equal(t, http.StatusTooManyRequests, post("/device/token", "192.0.2.4", url.Values{"device_code": {"2"}}).Code)
+	require.Equal(t, 1.0, testutil.ToFloat64(s.metrics.rateLimited.WithLabelValues("/device/token", rateLimitIP)))
+
+	// Polls of a device request.
+	require.Equal(t, http.StatusTooManyRequests, post("/device/token", "192.0.2.5", url.Values{"device_code": {"1"}}).Code)
+	require.Equal(t, 1.0, testutil.ToFloat64(s.metrics.rateLimited.WithLabelValues("/device/token", rateLimitDeviceCode)))
+
+	// Endpoints without limits.
+	for range 5 {
+		require.NotEqual(t, http.StatusTooManyRequests, post("/token/introspect", "192.0.2.4", nil).Code)
+	}
+}
+
+func TestKeyedLimiter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l, err := newKeyedLimiter(RateLimit{RequestsPerSecond: 2}, func() time.Time { return now })
+	require.NoError(t, err)
+
+	require.Zero(t, l.reserve("a"))
+	require.Zero(t, l.reserve("a"))
+	require.Equal(t, 500*time.Millisecond, l.reserve("a"))
+	require.Equal(t, 500*time.Millisecond, l.reserve("a"), "rejected requests aren't counted")
+	require.Zero(t, l.reserve("b"))
+
+	now = now.Add(time.Minute)
+	require.Zero(t, l.reserve("b"))
+	require.Len(t, l.limiters, 1, "idle limiters are dropped")
+
+	for _, c := range []RateLimit{{}, {RequestsPerSecond: 1, Burst: -1}} {
+		_, err := newKeyedLimiter(c, time.Now)
+		require.Error(t, err, "%+v", c)
+	}
+}
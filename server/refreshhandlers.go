@@ -40,6 +40,17 @@ func newBadRequestError(desc string) *refreshError {
 	return &refreshError{msg: errInvalidRequest, desc: desc, code: http.StatusBadRequest}
 }
 
+// newTokenIssueError returns the error of a refresh failing to issue a token:
+// access_denied if the token webhook or the authorization policy denied it, a
+// server error otherwise.
+func newTokenIssueError(err error) *refreshError {
+	var denied *tokenDeniedError
+	if errors.As(err, &denied) {
+		return &refreshError{msg: errAccessDenied, desc: denied.reason, code: http.StatusForbidden}
+	}
+	return newInternalServerError()
+}
+
 var (
 	invalidErr = newBadRequestError("Refresh token is invalid or has already been claimed by another client.")
 	expiredErr = newBadRequestError("Refresh token expired.")
@@ -76,6 +87,12 @@ type refreshContext struct {
 	connector     Connector
 	connectorData []byte
 
+	// policy is the refresh token policy of the token's client.
+	policy *RefreshTokenPolicy
+	// forwardClaimSourceTokens is set if the token's client keeps the
+	// access tokens of claim sources.
+	forwardClaimSourceTokens bool
+
 	scopes []string
 }
 
@@ -101,24 +118,50 @@ func (s *Server) getRefreshTokenFromStorage(ctx context.Context, clientID *strin
 		return nil, &refreshError{msg: errInvalidGrant, desc: invalidErr.desc, code: http.StatusBadRequest}
 	}
 
+	client, err := s.storage.GetClient(refresh.ClientID)
+	if err != nil {
+		if err != storage.ErrNotFound {
+			s.logger.ErrorContext(ctx, "failed to get client", "err", err)
+			return nil, newInternalServerError()
+		}
+		return nil, invalidErr
+	}
+
+	policy, err := s.refreshTokenPolicy.ForClient(client.RefreshTokenPolicy)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "invalid refresh token policy", "client_id", client.ID, "err", err)
+		return nil, newInternalServerError()
+	}
+	refreshCtx.policy = policy
+	refreshCtx.forwardClaimSourceTokens = client.ForwardClaimSourceTokens
+
 	if refresh.Token != token.Token {
 		switch {
-		case !s.refreshTokenPolicy.AllowedToReuse(refresh.LastUsed):
+		case !policy.AllowedToReuse(refresh.LastUsed):
 			fallthrough
 		case refresh.ObsoleteToken != token.Token:
 			fallthrough
 		case refresh.ObsoleteToken == "":
 			s.logger.ErrorContext(ctx, "refresh token claimed twice", "token_id", refresh.ID)
+			// A token used again after it was rotated may have been stolen.
+			s.emitEvent(ctx, auditEvent{
+				Type:        eventRefreshReused,
+				ClientID:    refresh.ClientID,
+				ConnectorID: refresh.ConnectorID,
+				UserID:      refresh.Claims.UserID,
+				Username:    refresh.Claims.Username,
+				Details:     map[string]string{"refresh_token_id": refresh.ID},
+			})
 			return nil, invalidErr
 		}
 	}
 
-	if s.refreshTokenPolicy.CompletelyExpired(refresh.CreatedAt) {
+	if policy.CompletelyExpired(refresh.CreatedAt) {
 		s.logger.ErrorContext(ctx, "refresh token expired", "token_id", refresh.ID)
 		return nil, expiredErr
 	}
 
-	if s.refreshTokenPolicy.ExpiredBecauseUnused(refresh.LastUsed) {
+	if policy.ExpiredBecauseUnused(refresh.LastUsed) {
 		s.logger.ErrorContext(ctx, "refresh token expired due to inactivity", "token_id", refresh.ID)
 		return nil, expiredErr
 	}
@@ -191,9 +234,15 @@ func (s *Server) refreshWithConnector(ctx context.Context, rCtx *refreshContext,
 	if refreshConn, ok := rCtx.connector.Connector.(connector.RefreshConnector); ok {
 		// Set connector data to the one received from an offline session
 		ident.ConnectorData = rCtx.connectorData
-		s.logger.Debug("connector data before refresh", "connector_data", ident.ConnectorData)
-
-		newIdent, err := refreshConn.Refresh(ctx, parseScopes(rCtx.scopes), ident)
+		s.logger.DebugContext(ctx, "connector data before refresh", "connector_data", ident.ConnectorData)
+
+		refreshCtx, done := s.startConnectorOperation(ctx, rCtx.storageToken.ConnectorID, "Refresh")
+		newIdent, err := refreshConn.Refresh(refreshCtx, parseScopes(rCtx.scopes), ident)
+		done(err)
+		if reason, ok := loginDenied(err); ok {
+			s.logger.InfoContext(ctx, "refresh denied by connector middleware", "err", err)
+			return ident, &refreshError{msg: errAccessDenied, desc: reason, code: http.StatusForbidden}
+		}
 		if err != nil {
 			s.logger.ErrorContext(ctx, "failed to refresh identity", "err", err)
 			return ident, newInternalServerError()
@@ -207,11 +256,12 @@ func (s *Server) refreshWithConnector(ctx context.Context, rCtx *refreshContext,
 // updateOfflineSession updates offline session in the storage
 func (s *Server) updateOfflineSession(ctx context.Context, refresh *storage.RefreshToken, ident connector.Identity, lastUsed time.Time) *refreshError {
 	offlineSessionUpdater := func(old storage.OfflineSessions) (storage.OfflineSessions, error) {
-		if old.Refresh[refresh.ClientID].ID != refresh.ID {
+		ref := findRefreshTokenRef(old, refresh)
+		if ref == nil {
 			return old, errors.New("refresh token invalid")
 		}
 
-		old.Refresh[refresh.ClientID].LastUsed = lastUsed
+		ref.LastUsed = lastUsed
 		if len(ident.ConnectorData) > 0 {
 			old.ConnectorData = ident.ConnectorData
 		}
@@ -250,11 +300,13 @@ func (s *Server) updateRefreshToken(ctx context.Context, rCtx *refreshContext) (
 		Email:             rCtx.storageToken.Claims.Email,
 		EmailVerified:     rCtx.storageToken.Claims.EmailVerified,
 		Groups:            rCtx.storageToken.Claims.Groups,
+		CustomClaims:      rCtx.storageToken.Claims.CustomClaims,
+		ClaimSources:      connectorClaimSources(rCtx.storageToken.Claims.ClaimSources),
 	}
 
 	refreshTokenUpdater := func(old storage.RefreshToken) (storage.RefreshToken, error) {
-		rotationEnabled := s.refreshTokenPolicy.RotationEnabled()
-		reusingAllowed := s.refreshTokenPolicy.AllowedToReuse(old.LastUsed)
+		rotationEnabled := rCtx.policy.RotationEnabled()
+		reusingAllowed := rCtx.policy.AllowedToReuse(old.LastUsed)
 
 		switch {
 		case !rotationEnabled && reusingAllowed:
@@ -309,6 +361,8 @@ func (s *Server) updateRefreshToken(ctx context.Context, rCtx *refreshContext) (
 		old.Claims.Email = ident.Email
 		old.Claims.EmailVerified = ident.EmailVerified
 		old.Claims.Groups = ident.Groups
+		old.Claims.CustomClaims = ident.CustomClaims
+		old.Claims.ClaimSources = storageClaimSources(ident.ClaimSources, rCtx.forwardClaimSourceTokens)
 
 		return old, nil
 	}
@@ -319,6 +373,9 @@ func (s *Server) updateRefreshToken(ctx context.Context, rCtx *refreshContext) (
 		s.logger.ErrorContext(ctx, "failed to update refresh token", "err", err)
 		return nil, ident, newInternalServerError()
 	}
+	if newToken.Token != rCtx.requestToken.Token && newToken.Token != rCtx.storageToken.Token {
+		s.metrics.observeRefreshRotation()
+	}
 
 	rerr = s.updateOfflineSession(ctx, rCtx.storageToken, ident, lastUsed)
 	if rerr != nil {
@@ -362,19 +419,22 @@ func (s *Server) handleRefreshToken(w http.ResponseWriter, r *http.Request, clie
 		Email:             ident.Email,
 		EmailVerified:     ident.EmailVerified,
 		Groups:            ident.Groups,
+		CustomClaims:      ident.CustomClaims,
+		ClaimSources:      storageClaimSources(ident.ClaimSources, client.ForwardClaimSourceTokens),
 	}
 
-	accessToken, _, err := s.newAccessToken(r.Context(), client.ID, claims, rCtx.scopes, rCtx.storageToken.Nonce, rCtx.storageToken.ConnectorID)
+	claimsReq := decodeClaimsRequest(rCtx.storageToken.ClaimsRequest)
+	accessToken, expiry, err := s.newAccessToken(r.Context(), client.ID, claims, rCtx.scopes, claimsReq.UserInfo, rCtx.storageToken.Nonce, rCtx.storageToken.ConnectorID)
 	if err != nil {
 		s.logger.ErrorContext(r.Context(), "failed to create new access token", "err", err)
-		s.refreshTokenErrHelper(w, newInternalServerError())
+		s.refreshTokenErrHelper(w, newTokenIssueError(err))
 		return
 	}
 
-	idToken, expiry, err := s.newIDToken(r.Context(), client.ID, claims, rCtx.scopes, rCtx.storageToken.Nonce, accessToken, "", rCtx.storageToken.ConnectorID)
+	idToken, _, err := s.newIDToken(r.Context(), client.ID, claims, rCtx.scopes, claimsReq.IDToken, rCtx.storageToken.Nonce, accessToken, "", rCtx.storageToken.ConnectorID)
 	if err != nil {
 		s.logger.ErrorContext(r.Context(), "failed to create ID token", "err", err)
-		s.refreshTokenErrHelper(w, newInternalServerError())
+		s.refreshTokenErrHelper(w, newTokenIssueError(err))
 		return
 	}
 
@@ -385,6 +445,15 @@ func (s *Server) handleRefreshToken(w http.ResponseWriter, r *http.Request, clie
 		return
 	}
 
+	s.emitEvent(r.Context(), auditEvent{
+		Type:        eventTokenRefreshed,
+		ClientID:    client.ID,
+		ConnectorID: rCtx.storageToken.ConnectorID,
+		UserID:      ident.UserID,
+		Username:    ident.Username,
+		Scopes:      rCtx.scopes,
+	})
+
 	resp := s.toAccessTokenResponse(idToken, accessToken, rawNewToken, expiry)
 	s.writeAccessToken(w, resp)
 }
@@ -81,11 +81,13 @@ func mockRefreshTokenTestStorage(t *testing.T, s storage.Storage, useObsolete bo
 
 func TestRefreshTokenExpirationScenarios(t *testing.T) {
 	t0 := time.Now()
+	disableRotation := true
 	tests := []struct {
-		name        string
-		policy      *RefreshTokenPolicy
-		useObsolete bool
-		error       string
+		name         string
+		policy       *RefreshTokenPolicy
+		clientPolicy *storage.RefreshTokenPolicy
+		useObsolete  bool
+		error        string
 	}{
 		{
 			name:   "Normal",
@@ -149,6 +151,21 @@ func TestRefreshTokenExpirationScenarios(t *testing.T) {
 			},
 			error: `{"error":"invalid_request","error_description":"Refresh token expired."}`,
 		},
+		{
+			name:         "Rotation disabled for the client",
+			policy:       &RefreshTokenPolicy{rotateRefreshTokens: true},
+			clientPolicy: &storage.RefreshTokenPolicy{DisableRotation: &disableRotation},
+			error:        ``,
+		},
+		{
+			name: "Absolutely expired for the client",
+			policy: &RefreshTokenPolicy{
+				rotateRefreshTokens: true,
+				now:                 func() time.Time { return t0.Add(time.Hour) },
+			},
+			clientPolicy: &storage.RefreshTokenPolicy{AbsoluteLifetime: "1m"},
+			error:        `{"error":"invalid_request","error_description":"Refresh token expired."}`,
+		},
 	}
 
 	for _, tc := range tests {
@@ -164,6 +181,13 @@ func TestRefreshTokenExpirationScenarios(t *testing.T) {
 			defer httpServer.Close()
 
 			mockRefreshTokenTestStorage(t, s.storage, tc.useObsolete)
+			if tc.clientPolicy != nil {
+				err := s.storage.UpdateClient("test", func(old storage.Client) (storage.Client, error) {
+					old.RefreshTokenPolicy = tc.clientPolicy
+					return old, nil
+				})
+				require.NoError(t, err)
+			}
 
 			u, err := url.Parse(s.issuerURL.String())
 			require.NoError(t, err)
@@ -197,7 +221,10 @@ func TestRefreshTokenExpirationScenarios(t *testing.T) {
 			err = json.Unmarshal(rr.Body.Bytes(), &ref)
 			require.NoError(t, err)
 
-			if tc.policy.rotateRefreshTokens == false {
+			policy, err := tc.policy.ForClient(tc.clientPolicy)
+			require.NoError(t, err)
+
+			if policy.rotateRefreshTokens == false {
 				require.Equal(t, tokenData, ref.Token)
 			} else {
 				require.NotEqual(t, tokenData, ref.Token)
@@ -0,0 +1,289 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/dexidp/dex/storage"
+)
+
+// RegistrationConfig lets users create accounts in the local password
+// database themselves, after verifying their e-mail address.
+type RegistrationConfig struct {
+	// Sends the e-mails with the verification links.
+	Mailer Mailer
+
+	// E-mail domains which can register, e.g. "example.com". If empty, any
+	// address can register.
+	AllowedDomains []string
+
+	// How long verification links are valid. Defaults to 1 hour.
+	ValidFor time.Duration
+
+	// Number of verification e-mails sent to an address per hour. Defaults to 3.
+	EmailsPerHour int
+
+	// Number of registrations requested from an IP address per hour. Defaults
+	// to 30.
+	RequestsPerHour int
+}
+
+type registration struct {
+	mailer         Mailer
+	allowedDomains []string
+	validFor       time.Duration
+
+	emailLimiter *hourlyLimiter
+	ipLimiter    *hourlyLimiter
+}
+
+func newRegistration(c *RegistrationConfig, now func() time.Time) (*registration, error) {
+	if c.Mailer == nil {
+		return nil, errors.New("no mailer configured for registrations")
+	}
+	if c.ValidFor < 0 || c.EmailsPerHour < 0 || c.RequestsPerHour < 0 {
+		return nil, errors.New("registration limits must not be negative")
+	}
+	emailsPerHour := c.EmailsPerHour
+	if emailsPerHour == 0 {
+		emailsPerHour = 3
+	}
+	requestsPerHour := c.RequestsPerHour
+	if requestsPerHour == 0 {
+		requestsPerHour = 30
+	}
+	allowedDomains := make([]string, 0, len(c.AllowedDomains))
+	for _, domain := range c.AllowedDomains {
+		allowedDomains = append(allowedDomains, strings.ToLower(domain))
+	}
+	return &registration{
+		mailer:         c.Mailer,
+		allowedDomains: allowedDomains,
+		validFor:       value(c.ValidFor, time.Hour),
+		emailLimiter:   newHourlyLimiter(emailsPerHour, now),
+		ipLimiter:      newHourlyLimiter(requestsPerHour, now),
+	}, nil
+}
+
+// allowed reports if an address may register.
+func (reg *registration) allowed(email string) bool {
+	if len(reg.allowedDomains) == 0 {
+		return true
+	}
+	_, domain, ok := strings.Cut(email, "@")
+	return ok && slices.Contains(reg.allowedDomains, strings.ToLower(domain))
+}
+
+// registrationURL returns the link to the registration page shown on the
+// login form of the local password database, if registrations are enabled.
+func (s *Server) registrationURL(connID string, loginURL *url.URL) string {
+	if s.registration == nil || connID != LocalConnector {
+		return ""
+	}
+	u := url.URL{
+		Path:     s.absPath("/register"),
+		RawQuery: url.Values{"back": {loginURL.String()}}.Encode(),
+	}
+	return u.String()
+}
+
+// promptCreate reports if an authorization request asks for the sign-up
+// screen with prompt=create, which is the registration form of the local
+// password database if registrations are enabled.
+func (s *Server) promptCreate(form url.Values) bool {
+	return s.registration != nil && slices.Contains(strings.Fields(form.Get("prompt")), "create")
+}
+
+// handleRegistrationRequest shows the form to register an e-mail address and
+// e-mails the link verifying it. The response doesn't reveal if the address
+// is registered already.
+func (s *Server) handleRegistrationRequest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tmpls := s.templates
+	backLink := s.loginBackLink(r)
+	postURL := s.absPath("/register")
+
+	keys := captchaKeys(r, "")
+	renderForm := func(errMsg string, failedCaptcha bool) {
+		challenge, err := s.captchaChallenge(ctx, keys, failedCaptcha)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to create captcha challenge", "err", err)
+			s.renderError(r, w, http.StatusInternalServerError, "Internal server error.")
+			return
+		}
+		if err := tmpls.register(r, w, postURL, backLink, "", "", false, false, errMsg, challenge); err != nil {
+			s.logger.ErrorContext(ctx, "server template error", "err", err)
+		}
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		renderForm("", false)
+	case http.MethodPost:
+		if !s.registration.ipLimiter.allow(clientIP(r)) {
+			s.logger.WarnContext(ctx, "registration rate limit exceeded", "ip", clientIP(r))
+			w.WriteHeader(http.StatusTooManyRequests)
+			renderForm("Too many requests, please try again later.", false)
+			return
+		}
+		if s.captcha != nil {
+			if s.captcha.required(keys) {
+				if err := s.verifyCaptcha(r); err != nil {
+					s.logger.InfoContext(ctx, "captcha verification failed", "err", err)
+					w.WriteHeader(http.StatusBadRequest)
+					renderForm("", true)
+					return
+				}
+			}
+			s.captcha.recordAttempt(keys)
+		}
+
+		addr, err := mail.ParseAddress(strings.TrimSpace(r.PostFormValue("email")))
+		var invalid string
+		switch {
+		case err != nil || addr.Name != "":
+			invalid = "Invalid e-mail address."
+		case !s.registration.allowed(addr.Address):
+			invalid = "This e-mail address can't be registered."
+		}
+		if invalid != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			renderForm(invalid, false)
+			return
+		}
+
+		s.sendRegistrationEmail(r, strings.ToLower(addr.Address))
+		if err := tmpls.register(r, w, postURL, backLink, "", "", true, false, "", nil); err != nil {
+			s.logger.ErrorContext(ctx, "server template error", "err", err)
+		}
+	default:
+		s.renderError(r, w, http.StatusBadRequest, "Unsupported request method.")
+	}
+}
+
+// sendRegistrationEmail e-mails a verification link unless the address is
+// registered already.
+func (s *Server) sendRegistrationEmail(r *http.Request, email string) {
+	ctx := r.Context()
+	if _, err := s.storage.GetPassword(email); err != storage.ErrNotFound {
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to get password", "err", err)
+		}
+		return
+	}
+	if !s.registration.emailLimiter.allow(email) {
+		s.logger.WarnContext(ctx, "registration e-mail rate limit exceeded")
+		return
+	}
+
+	token, err := s.signEmailToken(ctx, emailToken{
+		Type:   registrationTokenType,
+		Email:  email,
+		Expiry: s.now().Add(s.registration.validFor).Unix(),
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to sign registration token", "err", err)
+		return
+	}
+	link := s.issuerURL
+	link.Path = s.absPath("/register/confirm")
+	link.RawQuery = url.Values{"token": {token}, "back": {s.loginBackLink(r)}}.Encode()
+
+	cat := s.templates.catalog
+	lang := cat.requestLanguage(r)
+	subject := cat.translate(lang, "Verify your e-mail address")
+	body := cat.translate(lang, "An account was requested for this e-mail address at %s. Open the following link within %d minutes to complete the registration:\n\n%s\n\nIf you didn't request this, you can ignore this e-mail.\n",
+		s.issuerURL.String(), int(s.registration.validFor.Minutes()), link.String())
+
+	s.sendMailInBackground(ctx, s.registration.mailer, email, subject, body)
+}
+
+// handleRegistration shows the form to complete a registration, reached
+// through the link of a verification e-mail, and creates the password.
+func (s *Server) handleRegistration(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tmpls := s.templates
+	backLink := s.loginBackLink(r)
+	postURL := s.absPath("/register/confirm")
+	rawToken := r.FormValue("token")
+
+	token, err := s.verifyEmailToken(ctx, registrationTokenType, rawToken)
+	if err != nil {
+		s.logger.InfoContext(ctx, "invalid registration token", "err", err)
+		s.renderError(r, w, http.StatusBadRequest, "Invalid or expired registration link.")
+		return
+	}
+	// The allowed domains may have changed since the link was sent.
+	if !s.registration.allowed(token.Email) {
+		s.logger.InfoContext(ctx, "registration of a disallowed e-mail address")
+		s.renderError(r, w, http.StatusBadRequest, "This e-mail address can't be registered.")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if err := tmpls.register(r, w, postURL, backLink, rawToken, token.Email, false, false, "", nil); err != nil {
+			s.logger.ErrorContext(ctx, "server template error", "err", err)
+		}
+	case http.MethodPost:
+		username := strings.TrimSpace(r.PostFormValue("username"))
+		password := r.PostFormValue("password")
+		invalid := "Please enter a username."
+		if username != "" {
+			invalid = s.checkNewPassword(r, password, r.PostFormValue("confirm"), token.Email, username)
+		}
+		if invalid != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			if err := tmpls.register(r, w, postURL, backLink, rawToken, token.Email, false, false, invalid, nil); err != nil {
+				s.logger.ErrorContext(ctx, "server template error", "err", err)
+			}
+			return
+		}
+
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to hash password", "err", err)
+			s.renderError(r, w, http.StatusInternalServerError, "Internal server error.")
+			return
+		}
+		p := storage.Password{
+			Email:    token.Email,
+			Hash:     hash,
+			Username: username,
+			UserID:   storage.NewID(),
+			// The address was verified by following the link.
+			EmailVerified: true,
+		}
+		if err := s.storage.CreatePassword(ctx, p); err != nil {
+			// The link may only be used once.
+			if errors.Is(err, storage.ErrAlreadyExists) {
+				s.renderError(r, w, http.StatusBadRequest, "Invalid or expired registration link.")
+				return
+			}
+			s.logger.ErrorContext(ctx, "failed to create password", "err", err)
+			s.renderError(r, w, http.StatusInternalServerError, "Internal server error.")
+			return
+		}
+
+		s.logger.InfoContext(ctx, "user registered", "user_id", p.UserID)
+		s.emitEvent(ctx, auditEvent{
+			Type:        eventUserRegistered,
+			ConnectorID: LocalConnector,
+			UserID:      p.UserID,
+			Username:    p.Username,
+			Details:     map[string]string{"remote_ip": clientIP(r)},
+		})
+		if err := tmpls.register(r, w, postURL, backLink, "", token.Email, false, true, "", nil); err != nil {
+			s.logger.ErrorContext(ctx, "server template error", "err", err)
+		}
+	default:
+		s.renderError(r, w, http.StatusBadRequest, "Unsupported request method.")
+	}
+}
@@ -0,0 +1,198 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/dexidp/dex/storage"
+)
+
+func TestRegistration(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mailer := make(fakeMailer, 10)
+	httpServer, s := newTestServer(ctx, t, func(c *Config) {
+		c.Registration = &RegistrationConfig{Mailer: mailer, AllowedDomains: []string{"Example.com"}}
+	})
+	defer httpServer.Close()
+
+	require.NoError(t, s.storage.CreatePassword(ctx, storage.Password{
+		Email:    "john@example.com",
+		Hash:     []byte("$2a$10$33EMT0cVYVlPy6WAMCLsceLYjWhuHpbz5yuZxu/GAFj03J9Lytjuy"),
+		Username: "john",
+		UserID:   "1",
+	}))
+
+	events, unsubscribe := s.events.subscribe()
+	defer unsubscribe()
+
+	post := func(path string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		s.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := post("/register", url.Values{"email": {"jane@other.com"}})
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "can&#39;t be registered")
+
+	rr = post("/register", url.Values{"email": {"not an address"}})
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// Registered addresses get the same answer, but no e-mail.
+	rr = post("/register", url.Values{"email": {"john@example.com"}})
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), "If the address can be registered")
+
+	rr = post("/register", url.Values{"email": {"Jane@Example.com"}})
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var m sentMail
+	select {
+	case m = <-mailer:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no verification e-mail sent")
+	}
+	require.Equal(t, "jane@example.com", m.to)
+	require.Len(t, mailer, 0)
+
+	link := regexp.MustCompile(`https?://\S+/register/confirm\S*`).FindString(m.body)
+	require.NotEmpty(t, link)
+	u, err := url.Parse(link)
+	require.NoError(t, err)
+	token := u.Query().Get("token")
+
+	req := httptest.NewRequest(http.MethodGet, u.RequestURI(), nil)
+	rr = httptest.NewRecorder()
+	s.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), "jane@example.com")
+
+	register := func(username, password string) *httptest.ResponseRecorder {
+		return post("/register/confirm", url.Values{"token": {token}, "username": {username}, "password": {password}, "confirm": {password}})
+	}
+
+	rr = register("", "password")
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = register("jane", "password")
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), "Your account has been created.")
+	require.Equal(t, eventUserRegistered, (<-events).Type)
+
+	p, err := s.storage.GetPassword("jane@example.com")
+	require.NoError(t, err)
+	require.Equal(t, "jane", p.Username)
+	require.NotEmpty(t, p.UserID)
+	require.NoError(t, bcrypt.CompareHashAndPassword(p.Hash, []byte("password")))
+
+	// Links can only be used once.
+	rr = register("mallory", "password2")
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// Reset tokens aren't accepted as registration tokens.
+	resetToken, err := s.signEmailToken(ctx, emailToken{
+		Type:   passwordResetTokenType,
+		Email:  "jim@example.com",
+		Expiry: time.Now().Add(time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+	rr = post("/register/confirm", url.Values{"token": {resetToken}, "username": {"jim"}, "password": {"password"}, "confirm": {"password"}})
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// Neither are other tokens signed by the server with the same claims,
+	// like ID tokens releasing an upstream "typ" claim.
+	payload := `{"typ":"registration","aud":"` + s.absURL("/register/confirm") + `","email":"jim@example.com","exp":` + strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10) + `}`
+	idToken, err := s.signer.Sign(ctx, []byte(payload))
+	require.NoError(t, err)
+	rr = post("/register/confirm", url.Values{"token": {idToken}, "username": {"jim"}, "password": {"password"}, "confirm": {"password"}})
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// Links of addresses which aren't allowed anymore can't be used.
+	otherToken, err := s.signEmailToken(ctx, emailToken{
+		Type:   registrationTokenType,
+		Email:  "jim@other.com",
+		Expiry: time.Now().Add(time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+	rr = post("/register/confirm", url.Values{"token": {otherToken}, "username": {"jim"}, "password": {"password"}, "confirm": {"password"}})
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	_, err = s.storage.GetPassword("jim@other.com")
+	require.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestRegistrationPromptCreate(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, s := newTestServer(ctx, t, func(c *Config) {
+		c.Registration = &RegistrationConfig{Mailer: make(fakeMailer, 1)}
+	})
+	defer httpServer.Close()
+
+	sc := storage.Connector{ID: LocalConnector, Type: LocalConnector, Name: "Email", ResourceVersion: "1"}
+	require.NoError(t, s.storage.CreateConnector(ctx, sc))
+	_, err := s.OpenConnector(sc)
+	require.NoError(t, err)
+	require.NoError(t, s.storage.CreateClient(ctx, storage.Client{
+		ID:           "app",
+		Secret:       "secret",
+		RedirectURIs: []string{"https://app.example.com/callback"},
+	}))
+
+	get := func(target string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		s.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+		return rr
+	}
+	query := url.Values{
+		"client_id":     {"app"},
+		"redirect_uri":  {"https://app.example.com/callback"},
+		"response_type": {"code"},
+		"scope":         {"openid"},
+		"prompt":        {"create"},
+	}
+
+	rr := get("/auth?" + query.Encode())
+	require.Equal(t, http.StatusFound, rr.Code)
+	connURL, err := url.Parse(rr.Header().Get("Location"))
+	require.NoError(t, err)
+	require.Equal(t, "/auth/local", connURL.Path)
+
+	rr = get(connURL.RequestURI())
+	require.Equal(t, http.StatusFound, rr.Code)
+	registerURL, err := url.Parse(rr.Header().Get("Location"))
+	require.NoError(t, err)
+	require.Equal(t, "/register", registerURL.Path)
+	back, err := url.Parse(registerURL.Query().Get("back"))
+	require.NoError(t, err)
+	require.Equal(t, "/auth/local/login", back.Path)
+	require.NotEmpty(t, back.Query().Get("state"))
+
+	// Without prompt=create, the user logs in.
+	query.Del("prompt")
+	rr = get("/auth/local?" + query.Encode())
+	require.Equal(t, http.StatusFound, rr.Code)
+	require.True(t, strings.HasPrefix(rr.Header().Get("Location"), "/auth/local/login?"))
+
+	// Discovery advertises prompt=create.
+	rr = get("/.well-known/openid-configuration")
+	require.Equal(t, http.StatusOK, rr.Code)
+	var d discovery
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &d))
+	require.Equal(t, []string{"create"}, d.PromptValues)
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// requestIDHeader carries the ID of a request, set by a load balancer or the
+// client, and returns it in responses. gRPC calls carry it in the metadata.
+const (
+	requestIDHeader   = "X-Request-Id"
+	requestIDMetadata = "x-request-id"
+)
+
+// maxRequestIDLength bounds the length of incoming request IDs.
+const maxRequestIDLength = 128
+
+// validRequestID reports if an incoming request ID can be used. IDs are
+// logged and shown on error pages, so only short IDs of characters commonly
+// used in IDs are accepted.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':', c == '/', c == '+', c == '=':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// withIncomingRequestID sets the ID of a request to the incoming ID if it's
+// valid, and generates one otherwise.
+func withIncomingRequestID(ctx context.Context, incoming string) (context.Context, string) {
+	id := incoming
+	if !validRequestID(id) {
+		id = uuid.NewString()
+	}
+	return context.WithValue(ctx, RequestKeyRequestID, id), id
+}
+
+// requestID returns the ID of the request of a context, if any.
+func requestID(ctx context.Context) string {
+	id, _ := ctx.Value(RequestKeyRequestID).(string)
+	return id
+}
+
+// NewAPIRequestIDInterceptors returns unary and stream interceptors setting
+// the request ID of gRPC calls, which is included in the log lines of the
+// call. The ID is taken from the "x-request-id" metadata if it's valid and
+// generated otherwise, and returned in the header metadata of the response.
+// The interceptors should run first, so that the other interceptors log it.
+func NewAPIRequestIDInterceptors() (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	unary := func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(withAPIRequestID(ctx), req)
+	}
+	stream := func(srv interface{}, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &contextServerStream{ServerStream: stream, ctx: withAPIRequestID(stream.Context())})
+	}
+	return unary, stream
+}
+
+func withAPIRequestID(ctx context.Context) context.Context {
+	var incoming string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(requestIDMetadata); len(ids) > 0 {
+			incoming = ids[0]
+		}
+	}
+	ctx, id := withIncomingRequestID(ctx, incoming)
+	// Fails only if the call ended or its header was sent already.
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadata, id))
+	return ctx
+}
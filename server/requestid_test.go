@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestValidRequestID(t *testing.T) {
+	for id, want := range map[string]bool{
+		"":                                     false,
+		"f47ac10b-58cc-4372-a567-0e02b2c3d479": true,
+		"Root=1-67891233-abcdef012345678912345678": true,
+		"lb/1234:5_6.7+8":                          true,
+		"<script>":                                 false,
+		"two words":                                false,
+		strings.Repeat("a", maxRequestIDLength):    true,
+		strings.Repeat("a", maxRequestIDLength+1):  false,
+	} {
+		require.Equal(t, want, validRequestID(id), id)
+	}
+}
+
+func TestRequestIDHeader(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, s := newTestServer(ctx, t, nil)
+	defer httpServer.Close()
+
+	// Incoming IDs are honored and shown on error pages.
+	req := httptest.NewRequest(http.MethodGet, "/auth/local/login", nil)
+	req.Header.Set(requestIDHeader, "lb-1234")
+	rr := httptest.NewRecorder()
+	s.ServeHTTP(rr, req)
+	require.Equal(t, "lb-1234", rr.Header().Get(requestIDHeader))
+	require.Contains(t, rr.Body.String(), "Request ID: lb-1234")
+
+	// Invalid IDs are replaced.
+	req = httptest.NewRequest(http.MethodGet, "/auth/local/login", nil)
+	req.Header.Set(requestIDHeader, "<script>")
+	rr = httptest.NewRecorder()
+	s.ServeHTTP(rr, req)
+	require.Regexp(t, `^[0-9a-f-]{36}$`, rr.Header().Get(requestIDHeader))
+	require.NotContains(t, rr.Body.String(), "<script>")
+}
+
+func TestAPIRequestIDInterceptors(t *testing.T) {
+	unary, _ := NewAPIRequestIDInterceptors()
+	call := func(ctx context.Context) string {
+		var got string
+		_, err := unary(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, _ interface{}) (interface{}, error) {
+			got = requestID(ctx)
+			return nil, nil
+		})
+		require.NoError(t, err)
+		return got
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(requestIDMetadata, "caller-1"))
+	require.Equal(t, "caller-1", call(ctx))
+	require.Regexp(t, `^[0-9a-f-]{36}$`, call(context.Background()))
+}
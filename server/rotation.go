@@ -12,7 +12,9 @@ import (
 	"time"
 
 	"github.com/go-jose/go-jose/v4"
+	"github.com/prometheus/client_golang/prometheus"
 
+	"github.com/dexidp/dex/server/signer"
 	"github.com/dexidp/dex/storage"
 )
 
@@ -26,7 +28,7 @@ type rotationStrategy struct {
 
 	// After being rotated how long should the key be kept around for validating
 	// signatures?
-	idTokenValidFor time.Duration
+	verificationKeysValidFor time.Duration
 
 	// Keys are always RSA keys. Though cryptopasta recommends ECDSA keys, not every
 	// client may support these (e.g. github.com/coreos/go-oidc/oidc).
@@ -37,18 +39,18 @@ type rotationStrategy struct {
 func staticRotationStrategy(key *rsa.PrivateKey) rotationStrategy {
 	return rotationStrategy{
 		// Setting these values to 100 years is easier than having a flag indicating no rotation.
-		rotationFrequency: time.Hour * 8760 * 100,
-		idTokenValidFor:   time.Hour * 8760 * 100,
-		key:               func() (*rsa.PrivateKey, error) { return key, nil },
+		rotationFrequency:        time.Hour * 8760 * 100,
+		verificationKeysValidFor: time.Hour * 8760 * 100,
+		key:                      func() (*rsa.PrivateKey, error) { return key, nil },
 	}
 }
 
 // defaultRotationStrategy returns a strategy which rotates keys every provided period,
 // holding onto the public parts for some specified amount of time.
-func defaultRotationStrategy(rotationFrequency, idTokenValidFor time.Duration) rotationStrategy {
+func defaultRotationStrategy(rotationFrequency, verificationKeysValidFor time.Duration) rotationStrategy {
 	return rotationStrategy{
-		rotationFrequency: rotationFrequency,
-		idTokenValidFor:   idTokenValidFor,
+		rotationFrequency:        rotationFrequency,
+		verificationKeysValidFor: verificationKeysValidFor,
 		key: func() (*rsa.PrivateKey, error) {
 			return rsa.GenerateKey(rand.Reader, 2048)
 		},
@@ -64,19 +66,36 @@ type keyRotator struct {
 	logger *slog.Logger
 }
 
-// startKeyRotation begins key rotation in a new goroutine, closing once the context is canceled.
+// localSigner is the default signer. It keeps the signing keys in storage and
+// rotates them according to the rotation strategy.
+type localSigner struct {
+	storage  storage.Storage
+	strategy rotationStrategy
+	now      func() time.Time
+	logger   *slog.Logger
+
+	// isLeader reports whether this instance rotates the keys periodically.
+	// If nil, it always does.
+	isLeader func() bool
+}
+
+func newLocalSigner(s storage.Storage, strategy rotationStrategy, now func() time.Time, logger *slog.Logger) *localSigner {
+	return &localSigner{storage: s, strategy: strategy, now: now, logger: logger}
+}
+
+// Start begins key rotation in a new goroutine, closing once the context is canceled.
 //
 // The method blocks until after the first attempt to rotate keys has completed. That way
 // healthy storages will return from this call with valid keys.
-func (s *Server) startKeyRotation(ctx context.Context, strategy rotationStrategy, now func() time.Time) {
-	rotator := keyRotator{s.storage, strategy, now, s.logger}
+func (l *localSigner) Start(ctx context.Context) {
+	rotator := keyRotator{l.storage, l.strategy, l.now, l.logger}
 
 	// Try to rotate immediately so properly configured storages will have keys.
-	if err := rotator.rotate(); err != nil {
+	if err := rotator.rotate(false); err != nil {
 		if err == errAlreadyRotated {
-			s.logger.Info("key rotation not needed", "err", err)
+			l.logger.Info("key rotation not needed", "err", err)
 		} else {
-			s.logger.Error("failed to rotate keys", "err", err)
+			l.logger.Error("failed to rotate keys", "err", err)
 		}
 	}
 
@@ -86,23 +105,110 @@ func (s *Server) startKeyRotation(ctx context.Context, strategy rotationStrategy
 			case <-ctx.Done():
 				return
 			case <-time.After(time.Second * 30):
-				if err := rotator.rotate(); err != nil {
-					s.logger.Error("failed to rotate keys", "err", err)
+				if l.isLeader != nil && !l.isLeader() {
+					continue
+				}
+				if err := rotator.rotate(false); err != nil {
+					l.logger.Error("failed to rotate keys", "err", err)
 				}
 			}
 		}
 	}()
 }
 
-func (k keyRotator) rotate() error {
+func (l *localSigner) signingKey() (*jose.JSONWebKey, error) {
+	keys, err := l.storage.GetKeys()
+	if err != nil {
+		return nil, fmt.Errorf("get keys: %v", err)
+	}
+	if keys.SigningKey == nil {
+		return nil, errors.New("no key to sign payload with")
+	}
+	return keys.SigningKey, nil
+}
+
+func (l *localSigner) Sign(ctx context.Context, payload []byte) (string, error) {
+	return l.SignTyped(ctx, "", payload)
+}
+
+func (l *localSigner) SignTyped(_ context.Context, typ string, payload []byte) (string, error) {
+	signingKey, err := l.signingKey()
+	if err != nil {
+		return "", err
+	}
+	signingAlg, err := signatureAlgorithm(signingKey)
+	if err != nil {
+		return "", err
+	}
+	return signTypedPayload(signingKey, signingAlg, typ, payload)
+}
+
+func (l *localSigner) Algorithm(_ context.Context) (jose.SignatureAlgorithm, error) {
+	signingKey, err := l.signingKey()
+	if err != nil {
+		return "", err
+	}
+	return signatureAlgorithm(signingKey)
+}
+
+func (l *localSigner) ValidationKeys(_ context.Context) ([]*jose.JSONWebKey, error) {
+	keys, err := l.storage.GetKeys()
+	if err != nil {
+		return nil, fmt.Errorf("get keys: %v", err)
+	}
+	if keys.SigningKeyPub == nil {
+		return nil, errors.New("no public keys found")
+	}
+
+	jwks := make([]*jose.JSONWebKey, 0, len(keys.VerificationKeys)+1)
+	jwks = append(jwks, keys.SigningKeyPub)
+	for _, verificationKey := range keys.VerificationKeys {
+		jwks = append(jwks, verificationKey.PublicKey)
+	}
+	return jwks, nil
+}
+
+// NextRotation reports when the signing key is rotated next.
+func (l *localSigner) NextRotation(_ context.Context) (time.Time, error) {
+	keys, err := l.storage.GetKeys()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("get keys: %v", err)
+	}
+	return keys.NextRotation, nil
+}
+
+// SigningKeyActiveSince reports when the current signing key was created.
+func (l *localSigner) SigningKeyActiveSince(ctx context.Context) (time.Time, error) {
+	next, err := l.NextRotation(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return next.Add(-l.strategy.rotationFrequency), nil
+}
+
+// Rotate replaces the signing key immediately, regardless of when it's due
+// to be rotated. The previous signing key remains a verification key for the
+// configured grace period.
+func (l *localSigner) Rotate(_ context.Context) error {
+	rotator := keyRotator{l.storage, l.strategy, l.now, l.logger}
+	return rotator.rotate(true)
+}
+
+// rotate replaces the signing key if it's due to be rotated, or unconditionally
+// if force is set.
+func (k keyRotator) rotate(force bool) error {
 	keys, err := k.GetKeys()
 	if err != nil && err != storage.ErrNotFound {
 		return fmt.Errorf("get keys: %v", err)
 	}
-	if k.now().Before(keys.NextRotation) {
+	if !force && k.now().Before(keys.NextRotation) {
 		return nil
 	}
-	k.logger.Info("keys expired, rotating")
+	if force {
+		k.logger.Info("rotating keys on demand")
+	} else {
+		k.logger.Info("keys expired, rotating")
+	}
 
 	// Generate the key outside of a storage transaction.
 	key, err := k.strategy.key()
@@ -133,7 +239,7 @@ func (k keyRotator) rotate() error {
 
 		// if you are running multiple instances of dex, another instance
 		// could have already rotated the keys.
-		if tNow.Before(keys.NextRotation) {
+		if !force && tNow.Before(keys.NextRotation) {
 			return storage.Keys{}, errAlreadyRotated
 		}
 
@@ -157,10 +263,10 @@ func (k keyRotator) rotate() error {
 			verificationKey := storage.VerificationKey{
 				PublicKey: keys.SigningKeyPub,
 				// After demoting the signing key, keep the token around for at least
-				// the amount of time an ID Token is valid for. This ensures the
-				// verification key won't expire until all ID Tokens it's signed
-				// expired as well.
-				Expiry: tNow.Add(k.strategy.idTokenValidFor),
+				// the amount of time an ID Token is valid for, unless configured
+				// otherwise. This ensures the verification key won't expire until
+				// all ID Tokens it's signed expired as well.
+				Expiry: tNow.Add(k.strategy.verificationKeysValidFor),
 			}
 			keys.VerificationKeys = append(keys.VerificationKeys, verificationKey)
 		}
@@ -178,6 +284,73 @@ func (k keyRotator) rotate() error {
 	return nil
 }
 
+// signingKeyAger is implemented by signers which know how long the current
+// signing key has been in use.
+type signingKeyAger interface {
+	SigningKeyActiveSince(ctx context.Context) (time.Time, error)
+}
+
+var (
+	signingKeyAgeDesc = prometheus.NewDesc(
+		"dex_signing_key_age_seconds",
+		"Time since the current signing key was created.",
+		nil, nil,
+	)
+	signingKeyNextRotationDesc = prometheus.NewDesc(
+		"dex_signing_key_next_rotation_timestamp_seconds",
+		"Unix time at which the signing key is rotated next, or for external signers when keys are refreshed next.",
+		nil, nil,
+	)
+	validationKeysDesc = prometheus.NewDesc(
+		"dex_validation_keys",
+		"Number of published keys which can verify signatures, including the signing key.",
+		nil, nil,
+	)
+)
+
+// signingKeyCollector exports metrics about the signing keys so operators can
+// alert when keys aren't rotated as expected.
+type signingKeyCollector struct {
+	signer signer.Signer
+	now    func() time.Time
+	logger *slog.Logger
+}
+
+func (c *signingKeyCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- signingKeyAgeDesc
+	ch <- signingKeyNextRotationDesc
+	ch <- validationKeysDesc
+}
+
+func (c *signingKeyCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx := context.Background()
+
+	if ager, ok := c.signer.(signingKeyAger); ok {
+		since, err := ager.SigningKeyActiveSince(ctx)
+		if err != nil {
+			c.logger.Error("failed to get signing key age", "err", err)
+		} else {
+			ch <- prometheus.MustNewConstMetric(signingKeyAgeDesc, prometheus.GaugeValue, c.now().Sub(since).Seconds())
+		}
+	}
+
+	if scheduler, ok := c.signer.(rotationScheduler); ok {
+		next, err := scheduler.NextRotation(ctx)
+		if err != nil {
+			c.logger.Error("failed to get next key rotation", "err", err)
+		} else {
+			ch <- prometheus.MustNewConstMetric(signingKeyNextRotationDesc, prometheus.GaugeValue, float64(next.Unix()))
+		}
+	}
+
+	keys, err := c.signer.ValidationKeys(ctx)
+	if err != nil {
+		c.logger.Error("failed to get keys", "err", err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(validationKeysDesc, prometheus.GaugeValue, float64(len(keys)))
+}
+
 type RefreshTokenPolicy struct {
 	rotateRefreshTokens bool // enable rotation
 
@@ -247,3 +420,46 @@ func (r *RefreshTokenPolicy) AllowedToReuse(lastUsed time.Time) bool {
 	}
 	return !r.now().After(lastUsed.Add(r.reuseInterval))
 }
+
+// ForClient returns the policy with the overrides of a client applied.
+func (r *RefreshTokenPolicy) ForClient(overrides *storage.RefreshTokenPolicy) (*RefreshTokenPolicy, error) {
+	if overrides == nil {
+		return r, nil
+	}
+
+	p := *r
+	var err error
+
+	if overrides.DisableRotation != nil {
+		p.rotateRefreshTokens = !*overrides.DisableRotation
+	}
+
+	if overrides.ValidIfNotUsedFor != "" {
+		p.validIfNotUsedFor, err = time.ParseDuration(overrides.ValidIfNotUsedFor)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for refresh token valid if not used for: %v", overrides.ValidIfNotUsedFor, err)
+		}
+	}
+
+	if overrides.AbsoluteLifetime != "" {
+		p.absoluteLifetime, err = time.ParseDuration(overrides.AbsoluteLifetime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for refresh tokens absolute lifetime: %v", overrides.AbsoluteLifetime, err)
+		}
+	}
+
+	if overrides.ReuseInterval != "" {
+		p.reuseInterval, err = time.ParseDuration(overrides.ReuseInterval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for refresh tokens reuse interval: %v", overrides.ReuseInterval, err)
+		}
+	}
+
+	return &p, nil
+}
+
+// ValidateRefreshTokenPolicy checks the refresh token policy overrides of a client.
+func ValidateRefreshTokenPolicy(overrides *storage.RefreshTokenPolicy) error {
+	_, err := (&RefreshTokenPolicy{}).ForClient(overrides)
+	return err
+}
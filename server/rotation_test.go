@@ -1,12 +1,15 @@
 package server
 
 import (
+	"context"
 	"io"
 	"log/slog"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 
 	"github.com/dexidp/dex/storage"
@@ -81,7 +84,7 @@ func TestKeyRotator(t *testing.T) {
 
 	for i := 0; i < 10; i++ {
 		now = now.Add(rotationFrequency + delta)
-		if err := r.rotate(); err != nil {
+		if err := r.rotate(false); err != nil {
 			t.Fatal(err)
 		}
 
@@ -98,6 +101,56 @@ func TestKeyRotator(t *testing.T) {
 	}
 }
 
+func TestLocalSignerRotate(t *testing.T) {
+	now := time.Now()
+	nowFunc := func() time.Time { return now }
+
+	l := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	s := memory.New(l)
+
+	// The grace period is shorter than the rotation frequency, so verification
+	// keys expire before the next scheduled rotation.
+	signer := newLocalSigner(newKeyCacher(s, nowFunc), defaultRotationStrategy(time.Hour, 10*time.Minute), nowFunc, l)
+
+	ctx := context.Background()
+	require.NoError(t, signer.Rotate(ctx))
+	firstKeyID := signingKeyID(t, s)
+
+	since, err := signer.SigningKeyActiveSince(ctx)
+	require.NoError(t, err)
+	require.True(t, since.Equal(now), "expected signing key to be active since %v, got %v", now, since)
+
+	// Rotating on demand replaces the key although it isn't due yet.
+	now = now.Add(time.Minute)
+	require.NoError(t, signer.Rotate(ctx))
+	require.NotEqual(t, firstKeyID, signingKeyID(t, s))
+
+	keys, err := s.GetKeys()
+	require.NoError(t, err)
+	require.Len(t, keys.VerificationKeys, 1)
+	require.Equal(t, firstKeyID, keys.VerificationKeys[0].PublicKey.KeyID)
+	require.True(t, keys.VerificationKeys[0].Expiry.Equal(now.Add(10*time.Minute)))
+
+	// The cached keys reflect the rotation immediately.
+	jwks, err := signer.ValidationKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, jwks, 2)
+	require.Equal(t, signingKeyID(t, s), jwks[0].KeyID)
+
+	now = now.Add(30 * time.Minute)
+	expected := `
+# HELP dex_signing_key_age_seconds Time since the current signing key was created.
+# TYPE dex_signing_key_age_seconds gauge
+dex_signing_key_age_seconds 1800
+# HELP dex_validation_keys Number of published keys which can verify signatures, including the signing key.
+# TYPE dex_validation_keys gauge
+dex_validation_keys 2
+`
+	collector := &signingKeyCollector{signer, nowFunc, l}
+	err = testutil.CollectAndCompare(collector, strings.NewReader(expected), "dex_signing_key_age_seconds", "dex_validation_keys")
+	require.NoError(t, err)
+}
+
 func TestRefreshTokenPolicy(t *testing.T) {
 	lastTime := time.Now()
 	l := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
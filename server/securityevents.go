@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultFailedLoginThreshold is the number of failed logins per hour for a
+// username or from an IP address at which a repeated_login_failures event is
+// emitted.
+const defaultFailedLoginThreshold = 5
+
+// failedLogins counts failed logins by username and by IP address to detect
+// password guessing.
+type failedLogins struct {
+	threshold int
+	counts    *hourlyLimiter
+}
+
+func newFailedLogins(threshold int, now func() time.Time) *failedLogins {
+	if threshold == 0 {
+		threshold = defaultFailedLoginThreshold
+	}
+	return &failedLogins{threshold: threshold, counts: newHourlyLimiter(threshold, now)}
+}
+
+// record counts a failed login for a key and reports if the failures within
+// the last hour just reached the threshold. Failures beyond the threshold
+// aren't reported again until the count dropped below it, so that an ongoing
+// attack yields at most one report per hour and threshold.
+func (f *failedLogins) record(key string) bool {
+	return f.counts.allow(key) && f.counts.exhausted(key)
+}
+
+// detectRepeatedLoginFailures emits a repeated_login_failures event if the
+// failed logins for the username of a login_failed event or from the IP
+// address of its request reached the threshold.
+func (s *Server) detectRepeatedLoginFailures(ctx context.Context, failed auditEvent) {
+	details := func(key string) map[string]string {
+		return map[string]string{"key": key, "failures": strconv.Itoa(s.failedLogins.threshold), "window": "1h"}
+	}
+	if failed.Username != "" && s.failedLogins.record("user:"+failed.ConnectorID+":"+strings.ToLower(failed.Username)) {
+		s.logger.WarnContext(ctx, "repeated failed logins for user", "connector_id", failed.ConnectorID, "username", failed.Username)
+		s.emitEvent(ctx, auditEvent{
+			Type:        eventRepeatedLoginFailures,
+			ConnectorID: failed.ConnectorID,
+			Username:    failed.Username,
+			Details:     details("username"),
+		})
+	}
+	if req, ok := ctx.Value(auditRequestKey{}).(auditRequest); ok && s.failedLogins.record("ip:"+req.remoteIP) {
+		s.logger.WarnContext(ctx, "repeated failed logins from IP address", "ip", req.remoteIP)
+		d := details("remote_ip")
+		d["remote_ip"] = req.remoteIP
+		s.emitEvent(ctx, auditEvent{
+			Type:    eventRepeatedLoginFailures,
+			Details: d,
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dexidp/dex/server/internal"
+)
+
+// securityEvents returns the security events received until no event was
+// received for a while.
+func securityEvents(events <-chan auditEvent) []auditEvent {
+	var got []auditEvent
+	for {
+		select {
+		case e := <-events:
+			if e.Type == eventRepeatedLoginFailures || e.Type == eventRefreshReused {
+				got = append(got, e)
+			}
+		case <-time.After(100 * time.Millisecond):
+			return got
+		}
+	}
+}
+
+func TestRepeatedLoginFailuresEvent(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, s := newTestServer(ctx, t, func(c *Config) {
+		c.FailedLoginThreshold = 2
+	})
+	defer httpServer.Close()
+
+	events, unsubscribe := s.events.subscribe()
+	defer unsubscribe()
+
+	failLogin := func(username, ip string) {
+		req := httptest.NewRequest(http.MethodPost, "/auth/local/login", nil)
+		req.RemoteAddr = ip + ":1234"
+		s.emitEvent(withAuditRequest(req), auditEvent{Type: eventLoginFailed, ConnectorID: LocalConnector, Username: username})
+	}
+
+	failLogin("jane", "192.0.2.1")
+	require.Empty(t, securityEvents(events))
+
+	// The second failure reaches the threshold for the user and the address.
+	failLogin("Jane", "192.0.2.1")
+	got := securityEvents(events)
+	require.Len(t, got, 2)
+	require.Equal(t, "Jane", got[0].Username)
+	require.Equal(t, map[string]string{"key": "username", "failures": "2", "window": "1h"}, got[0].Details)
+	require.Equal(t, map[string]string{"key": "remote_ip", "remote_ip": "192.0.2.1", "failures": "2", "window": "1h"}, got[1].Details)
+
+	// Further failures aren't reported again.
+	failLogin("jane", "192.0.2.1")
+	require.Empty(t, securityEvents(events))
+
+	failLogin("john", "192.0.2.2")
+	require.Empty(t, securityEvents(events))
+}
+
+func TestRefreshTokenReuseEvent(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, s := newTestServer(ctx, t, func(c *Config) {
+		c.RefreshTokenPolicy = &RefreshTokenPolicy{rotateRefreshTokens: true}
+	})
+	defer httpServer.Close()
+
+	// The token "bar" was rotated to "testtest".
+	mockRefreshTokenTestStorage(t, s.storage, true)
+
+	events, unsubscribe := s.events.subscribe()
+	defer unsubscribe()
+
+	tokenData, err := internal.Marshal(&internal.RefreshToken{RefreshId: "test", Token: "bar"})
+	require.NoError(t, err)
+	form := url.Values{"grant_type": {grantTypeRefreshToken}, "refresh_token": {tokenData}}
+	req := httptest.NewRequest(http.MethodPost, "/token", bytes.NewBufferString(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("test", "barfoo")
+	rr := httptest.NewRecorder()
+	s.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	got := securityEvents(events)
+	require.Len(t, got, 1)
+	require.Equal(t, eventRefreshReused, got[0].Type)
+	require.Equal(t, "test", got[0].ClientID)
+	require.Equal(t, "1", got[0].UserID)
+	require.Equal(t, map[string]string{"refresh_token_id": "test"}, got[0].Details)
+}
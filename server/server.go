@@ -8,7 +8,8 @@ import (
 	"fmt"
 	"io/fs"
 	"log/slog"
-	"net"
+	"maps"
+	"math"
 	"net/http"
 	"net/netip"
 	"net/url"
@@ -22,10 +23,12 @@ import (
 
 	gosundheit "github.com/AppsFlyer/go-sundheit"
 	"github.com/google/uuid"
-	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/dexidp/dex/connector"
@@ -40,11 +43,13 @@ import (
 	"github.com/dexidp/dex/connector/ldap"
 	"github.com/dexidp/dex/connector/linkedin"
 	"github.com/dexidp/dex/connector/microsoft"
+	"github.com/dexidp/dex/connector/middleware"
 	"github.com/dexidp/dex/connector/mock"
 	"github.com/dexidp/dex/connector/oauth"
 	"github.com/dexidp/dex/connector/oidc"
 	"github.com/dexidp/dex/connector/openshift"
 	"github.com/dexidp/dex/connector/saml"
+	"github.com/dexidp/dex/server/signer"
 	"github.com/dexidp/dex/storage"
 	"github.com/dexidp/dex/web"
 )
@@ -68,6 +73,10 @@ type Config struct {
 	// The backing persistence layer.
 	Storage storage.Storage
 
+	// Signer used to sign tokens. If nil, the server generates signing keys
+	// itself, keeps them in storage and rotates them every RotateKeysAfter.
+	Signer signer.Signer
+
 	AllowedGrantTypes []string
 
 	// Valid values are "code" to enable the code flow and "token" to enable the implicit
@@ -77,18 +86,28 @@ type Config struct {
 	// Headers is a map of headers to be added to the all responses.
 	Headers http.Header
 
-	// Header to extract real ip from.
+	// Header to extract real ip from, like X-Forwarded-For or Forwarded.
+	// Only the headers of requests from TrustedRealIPCIDRs are read, or of
+	// all requests if none are set.
 	RealIPHeader       string
 	TrustedRealIPCIDRs []netip.Prefix
 
 	// List of allowed origins for CORS requests on discovery, token and keys endpoint.
-	// If none are indicated, CORS requests are disabled. Passing in "*" will allow any
-	// domain.
+	// Passing in "*" will allow any domain. Clients can allow their own origins
+	// in addition to these.
 	AllowedOrigins []string
 
 	// List of allowed headers for CORS requests on discovery, token, and keys endpoint.
 	AllowedHeaders []string
 
+	// Attributes of the cookies set by the server.
+	Cookies *CookieConfig
+
+	// If enabled, the password, approval and device forms must be submitted
+	// with the token of a cookie, protecting them against cross-site request
+	// forgery. Custom templates must include the csrf_token field.
+	CSRFProtection bool
+
 	// If enabled, the server won't prompt the user to approve authorization requests.
 	// Logging in implies approval.
 	SkipApprovalScreen bool
@@ -101,14 +120,117 @@ type Config struct {
 	AuthRequestsValidFor   time.Duration // Defaults to 24 hours
 	DeviceRequestsValidFor time.Duration // Defaults to 5 minutes
 
+	// If set, auth requests are encrypted into their IDs instead of being
+	// written to the storage.
+	StatelessAuthRequests *StatelessAuthRequestsConfig
+
+	// How long devices have to wait between polling for tokens in the device
+	// flow. Rounded up to whole seconds. Defaults to 5 seconds.
+	DevicePollInterval time.Duration
+
+	// Format of the user codes of the device flow.
+	DeviceUserCode UserCodeFormat
+
+	// How long a signing key keeps being published for verifying signatures after
+	// it has been rotated. Defaults to IDTokensValidFor.
+	VerificationKeysValidFor time.Duration
+
 	// Refresh token expiration settings
 	RefreshTokenPolicy *RefreshTokenPolicy
 
+	// Maximum number of concurrent refresh tokens a user can hold for a single
+	// client. When exceeded, the oldest refresh token is revoked. Defaults to 1.
+	MaxSessionsPerClient int
+
+	// Additional scopes clients can request, each adding a set of claims to
+	// ID tokens.
+	CustomScopes []CustomScope
+
+	// CEL expressions setting claims of ID and access tokens, applied right
+	// before tokens are signed. Clients can add their own transforms, which
+	// run after these.
+	ClaimsTransforms []storage.ClaimsTransform
+
+	// If set, this webhook is called before every token is signed and can
+	// add or override claims, or deny issuing the token.
+	TokenWebhook *TokenWebhookConfig
+
+	// If set, this policy is evaluated when users are authorized for a client
+	// and before every token is signed, and can deny the request or constrain
+	// the claims of tokens.
+	AuthorizationPolicy *AuthorizationPolicyConfig
+
+	// If set, limits the rate of requests to the authorization, token and
+	// device endpoints.
+	RateLimit *RateLimitConfig
+
+	// E-mail domains of the users of this issuer, for WebFinger issuer
+	// discovery. If empty, the issuer is returned for any user.
+	WebFingerDomains []string
+
+	// Fields added to the OpenID Connect discovery document, replacing the
+	// ones generated by the server. Fields set to nil are removed.
+	DiscoveryFields map[string]interface{}
+
+	// Broker audit events are published to. Servers of several issuers may
+	// share one, and a new one is created if unset.
+	Events *EventBroker
+
+	// If set, audit events are written to the sinks of the audit log.
+	AuditLog *AuditLogConfig
+
+	// Number of failed logins for a username or from an IP address within an
+	// hour at which a repeated_login_failures event is emitted. Defaults to 5.
+	FailedLoginThreshold int
+
 	// If set, the server will use this connector to handle password grants
 	PasswordConnector string
 
+	// If set, users of the local password database can reset their password.
+	PasswordReset *PasswordResetConfig
+
+	// If set, logins of the local password database are locked out after
+	// repeated failures.
+	PasswordLockout *PasswordLockoutConfig
+
+	// Policy new passwords of the local password database have to meet. If
+	// unset, passwords must be 8 to 72 characters long.
+	PasswordPolicy *PasswordPolicyConfig
+
+	// If set, users can create accounts in the local password database.
+	Registration *RegistrationConfig
+
+	// If set, users of the local password database with an unverified e-mail
+	// address are sent a link to verify it when they log in.
+	EmailVerification *EmailVerificationConfig
+
+	// If set, the password and registration forms challenge users with a
+	// CAPTCHA after repeated failed attempts.
+	Captcha *CaptchaConfig
+
+	// If set, the login page asks users for their e-mail address and sends
+	// them to the connector of its domain.
+	HomeRealmDiscovery *HomeRealmDiscoveryConfig
+
 	GCFrequency time.Duration // Defaults to 5 minutes
 
+	// GCIntervals overrides GCFrequency for some types of objects, which are
+	// then garbage collected on their own schedule.
+	GCIntervals map[storage.GCResource]time.Duration
+
+	// GCJitter is the maximum random delay added to each garbage collection
+	// interval, so instances sharing a storage don't all collect at once.
+	GCJitter time.Duration
+
+	// GCBatchSize limits the number of objects deleted at once by garbage
+	// collection. If zero, the storage deletes all expired objects at once.
+	GCBatchSize int
+
+	// IsLeader reports whether this instance runs the background work that
+	// only one of the instances sharing the storage needs to do, such as
+	// garbage collection and key rotation. If nil, every instance runs it.
+	IsLeader func() bool
+
 	// If specified, the server will use this function for determining time.
 	Now func() time.Time
 
@@ -118,7 +240,47 @@ type Config struct {
 
 	PrometheusRegistry *prometheus.Registry
 
+	// TracerProvider creates the OpenTelemetry spans of the HTTP handlers,
+	// connector logins and token issuance. Defaults to the global provider.
+	TracerProvider trace.TracerProvider
+
 	HealthChecker gosundheit.Health
+
+	// Bearer token required for the detailed health report at
+	// /healthz?verbose. If empty, the report isn't served.
+	HealthReportToken string
+
+	// Routes are extra HTTP routes, for projects embedding dex. They are
+	// served like the routes of dex, with the configured headers, request IDs,
+	// metrics and tracing. Routes matching a path of dex are never reached.
+	Routes []Route
+
+	// Middleware wraps the handlers of all routes, the first one being the
+	// outermost. It runs after the request ID and the client IP were added to
+	// the request context.
+	Middleware []func(http.Handler) http.Handler
+
+	// Router the routes are added to, so that projects embedding dex can serve
+	// their own routes with it. It's set to skip cleaning paths and to match
+	// encoded paths, which dex relies on. Defaults to a new router.
+	Router *mux.Router
+}
+
+// Route is an extra HTTP route of the server.
+type Route struct {
+	// Path relative to the issuer URL, e.g. "/admin/{page}". Variables are
+	// available through mux.Vars.
+	Path string
+
+	// If set, all paths beginning with Path are routed to the handler, with
+	// the prefix stripped like for the static files of dex.
+	Prefix bool
+
+	Handler http.Handler
+
+	// If set, cross-origin requests are allowed from the allowed origins, like
+	// for the discovery and token endpoints.
+	CORS bool
 }
 
 // WebConfig holds the server's frontend templates and asset configuration.
@@ -150,6 +312,59 @@ type WebConfig struct {
 
 	// Map of extra values passed into the templates
 	Extra map[string]string
+
+	// Colors overriding the ones of the theme, e.g. "#2fc98e".
+	PrimaryColor    string
+	BackgroundColor string
+
+	// Text shown at the bottom of every page.
+	Footer string
+
+	// Text shown on error pages, e.g. how to contact support.
+	SupportText string
+
+	// Customization of the page users enter the user codes of the device
+	// flow at.
+	Device DevicePage
+
+	// Descriptions of scopes shown on the approval page, keyed by scope. They
+	// replace the built-in descriptions and the ones of custom scopes.
+	ScopeDescriptions map[string]string
+
+	// Branding of the pages shown while logging in to a client, keyed by
+	// client ID. Unset values fall back to the ones above.
+	Clients map[string]Branding
+}
+
+// DevicePage customizes the page users enter the user codes of the device flow
+// at.
+type DevicePage struct {
+	// Instructions shown above the user code, e.g. where to find it.
+	Instructions string
+
+	// Link to help, e.g. a support page, and its text. The text defaults to
+	// "Need help?".
+	HelpURL  string
+	HelpText string
+
+	// Continue right away if the verification link contains the user code of
+	// a pending request, without asking users to confirm the code. Users can
+	// then be tricked into authorizing devices of others with a link, so this
+	// is best combined with the approval screen.
+	AutoSubmit bool
+}
+
+// Branding overrides the frontend of a single client, so that one instance can
+// serve products with their own look.
+type Branding struct {
+	LogoURL         string
+	Issuer          string
+	PrimaryColor    string
+	BackgroundColor string
+	Footer          string
+
+	// Merged with the extra values of the frontend.
+	Extra map[string]string
 }
 
 func value(val, defaultValue time.Duration) time.Duration {
@@ -170,6 +385,8 @@ type Server struct {
 
 	storage storage.Storage
 
+	signer signer.Signer
+
 	mux http.Handler
 
 	templates *templates
@@ -183,18 +400,90 @@ type Server struct {
 	// Used for password grant
 	passwordConnector string
 
+	// nil if users can't reset their password, register or verify their
+	// e-mail address.
+	passwordReset     *passwordReset
+	registration      *registration
+	emailVerification *emailVerification
+
+	// nil if forms don't challenge users with a CAPTCHA.
+	captcha *captcha
+
+	// nil if users choose a connector without entering their e-mail address.
+	homeRealms *homeRealmDiscovery
+
 	supportedResponseTypes map[string]bool
 
 	supportedGrantTypes []string
 
 	now func() time.Time
 
+	// isLeader reports whether this instance runs garbage collection.
+	isLeader func() bool
+
+	gcBatchSize int
+	// gcMetrics and metrics are nil if the server doesn't export metrics.
+	gcMetrics *gcMetrics
+	metrics   *identityMetrics
+
 	idTokensValidFor       time.Duration
 	authRequestsValidFor   time.Duration
 	deviceRequestsValidFor time.Duration
 
+	// nil unless auth requests are stateless.
+	authRequestSealer *authRequestSealer
+
+	// device flow polling interval in seconds and user code format
+	devicePollInterval int
+	deviceUserCode     UserCodeFormat
+
 	refreshTokenPolicy *RefreshTokenPolicy
 
+	maxSessionsPerClient int
+
+	customScopes map[string]CustomScope
+
+	claimsTransforms []claimsTransform
+
+	tokenWebhook *tokenWebhook
+
+	authzPolicy *authzPolicy
+
+	rateLimiter *httpRateLimiter
+
+	webFingerDomains []string
+
+	discoveryFields map[string]interface{}
+
+	// Origins of CORS requests allowed server wide, in addition to the
+	// origins of clients.
+	allowedOrigins []string
+	allowedHeaders []string
+	clientOrigins  *clientOrigins
+
+	// Client IDs mapped to when their last use was recorded.
+	clientsLastUsed sync.Map
+
+	events *EventBroker
+
+	// nil if audit events aren't written to an audit log.
+	auditLog *auditLog
+
+	failedLogins *failedLogins
+
+	// nil if local password logins aren't locked out after failures.
+	passwordLockout *passwordLockout
+
+	passwordPolicy *passwordPolicy
+
+	cookies        *cookies
+	csrfProtection bool
+
+	healthChecker     gosundheit.Health
+	healthReportToken string
+
+	tracer trace.Tracer
+
 	logger *slog.Logger
 }
 
@@ -202,7 +491,7 @@ type Server struct {
 func NewServer(ctx context.Context, c Config) (*Server, error) {
 	return newServer(ctx, c, defaultRotationStrategy(
 		value(c.RotateKeysAfter, 6*time.Hour),
-		value(c.IDTokensValidFor, 24*time.Hour),
+		value(c.VerificationKeysValidFor, value(c.IDTokensValidFor, 24*time.Hour)),
 	))
 }
 
@@ -223,6 +512,15 @@ func newServer(ctx context.Context, c Config, rotationStrategy rotationStrategy)
 		return nil, errors.New("server: storage cannot be nil")
 	}
 
+	for _, route := range c.Routes {
+		if !strings.HasPrefix(route.Path, "/") {
+			return nil, fmt.Errorf("server: invalid route %q: path must start with a slash", route.Path)
+		}
+		if route.Handler == nil {
+			return nil, fmt.Errorf("server: invalid route %q: no handler", route.Path)
+		}
+	}
+
 	if len(c.SupportedResponseTypes) == 0 {
 		c.SupportedResponseTypes = []string{responseTypeCode}
 	}
@@ -284,6 +582,13 @@ func newServer(ctx context.Context, c Config, rotationStrategy rotationStrategy)
 		issuer:    c.Web.Issuer,
 		theme:     c.Web.Theme,
 		extra:     c.Web.Extra,
+
+		primaryColor:    c.Web.PrimaryColor,
+		backgroundColor: c.Web.BackgroundColor,
+		footer:          c.Web.Footer,
+		supportText:     c.Web.SupportText,
+		devicePage:      c.Web.Device,
+		clients:         c.Web.Clients,
 	}
 
 	static, theme, robots, tmpls, err := loadWebConfig(web)
@@ -291,28 +596,185 @@ func newServer(ctx context.Context, c Config, rotationStrategy rotationStrategy)
 		return nil, fmt.Errorf("server: failed to load web static: %v", err)
 	}
 
+	customScopes, err := newCustomScopes(c.CustomScopes)
+	if err != nil {
+		return nil, fmt.Errorf("server: %v", err)
+	}
+	for _, scope := range customScopes {
+		if scope.Description != "" {
+			tmpls.scopeDescriptions[scope.Name] = scope.Description
+		}
+	}
+	maps.Copy(tmpls.scopeDescriptions, c.Web.ScopeDescriptions)
+
+	claimsTransforms, err := newClaimsTransforms(c.ClaimsTransforms)
+	if err != nil {
+		return nil, fmt.Errorf("server: %v", err)
+	}
+
+	if c.DevicePollInterval < 0 {
+		return nil, fmt.Errorf("server: device poll interval must not be negative")
+	}
+	deviceUserCode, err := c.DeviceUserCode.withDefaults()
+	if err != nil {
+		return nil, fmt.Errorf("server: %v", err)
+	}
+
+	if err := validateDiscoveryFields(c.DiscoveryFields); err != nil {
+		return nil, fmt.Errorf("server: %v", err)
+	}
+
+	webFingerDomains := make([]string, 0, len(c.WebFingerDomains))
+	for _, domain := range c.WebFingerDomains {
+		webFingerDomains = append(webFingerDomains, strings.ToLower(domain))
+	}
+
 	now := c.Now
 	if now == nil {
 		now = time.Now
 	}
 
+	events := c.Events
+	if events == nil {
+		events = NewEventBroker()
+	}
+
+	tracerProvider := c.TracerProvider
+	if tracerProvider == nil {
+		tracerProvider = otel.GetTracerProvider()
+	}
+
 	s := &Server{
 		issuerURL:              *issuerURL,
 		connectors:             make(map[string]Connector),
-		storage:                newKeyCacher(c.Storage, now),
+		storage:                newKeyCacher(storage.WithStaticClients(c.Storage, []storage.Client{sessionsClient(*issuerURL)}), now),
 		supportedResponseTypes: supportedRes,
 		supportedGrantTypes:    supportedGrants,
 		idTokensValidFor:       value(c.IDTokensValidFor, 24*time.Hour),
 		authRequestsValidFor:   value(c.AuthRequestsValidFor, 24*time.Hour),
 		deviceRequestsValidFor: value(c.DeviceRequestsValidFor, 5*time.Minute),
+		devicePollInterval:     int(math.Ceil(value(c.DevicePollInterval, 5*time.Second).Seconds())),
+		deviceUserCode:         deviceUserCode,
 		refreshTokenPolicy:     c.RefreshTokenPolicy,
+		maxSessionsPerClient:   max(c.MaxSessionsPerClient, 1),
+		customScopes:           customScopes,
+		claimsTransforms:       claimsTransforms,
+		webFingerDomains:       webFingerDomains,
+		discoveryFields:        c.DiscoveryFields,
+		allowedOrigins:         c.AllowedOrigins,
+		allowedHeaders:         c.AllowedHeaders,
+		events:                 events,
+		tracer:                 tracerProvider.Tracer("github.com/dexidp/dex/server"),
 		skipApproval:           c.SkipApprovalScreen,
 		alwaysShowLogin:        c.AlwaysShowLoginScreen,
 		now:                    now,
 		templates:              tmpls,
 		passwordConnector:      c.PasswordConnector,
+		gcBatchSize:            c.GCBatchSize,
+		healthChecker:          c.HealthChecker,
+		healthReportToken:      c.HealthReportToken,
 		logger:                 c.Logger,
 	}
+	s.clientOrigins = &clientOrigins{storage: s.storage, now: now}
+
+	if c.PasswordReset != nil {
+		if tmpls.passwordResetTmpl == nil {
+			return nil, fmt.Errorf("server: password resets require the %s template", tmplPasswordReset)
+		}
+		if s.passwordReset, err = newPasswordReset(c.PasswordReset, now); err != nil {
+			return nil, fmt.Errorf("server: %v", err)
+		}
+	}
+	if c.Registration != nil {
+		if tmpls.registerTmpl == nil {
+			return nil, fmt.Errorf("server: registrations require the %s template", tmplRegister)
+		}
+		if s.registration, err = newRegistration(c.Registration, now); err != nil {
+			return nil, fmt.Errorf("server: %v", err)
+		}
+	}
+	if c.EmailVerification != nil {
+		if tmpls.verifyEmailTmpl == nil {
+			return nil, fmt.Errorf("server: e-mail verification requires the %s template", tmplVerifyEmail)
+		}
+		if s.emailVerification, err = newEmailVerification(c.EmailVerification, now); err != nil {
+			return nil, fmt.Errorf("server: %v", err)
+		}
+	}
+	if c.Captcha != nil {
+		if s.captcha, err = newCaptcha(c.Captcha, now); err != nil {
+			return nil, fmt.Errorf("server: %v", err)
+		}
+	}
+	if c.TokenWebhook != nil {
+		if s.tokenWebhook, err = newTokenWebhook(c.TokenWebhook); err != nil {
+			return nil, fmt.Errorf("server: %v", err)
+		}
+	}
+	if c.AuthorizationPolicy != nil {
+		if s.authzPolicy, err = newAuthzPolicy(c.AuthorizationPolicy); err != nil {
+			return nil, fmt.Errorf("server: %v", err)
+		}
+	}
+	if c.RateLimit != nil {
+		if s.rateLimiter, err = newHTTPRateLimiter(c.RateLimit, now); err != nil {
+			return nil, fmt.Errorf("server: %v", err)
+		}
+	}
+	if c.FailedLoginThreshold < 0 {
+		return nil, errors.New("server: failed login threshold must not be negative")
+	}
+	s.failedLogins = newFailedLogins(c.FailedLoginThreshold, now)
+	if c.PasswordLockout != nil {
+		if s.passwordLockout, err = newPasswordLockout(c.PasswordLockout, now, s.logger, s.emitEvent); err != nil {
+			return nil, fmt.Errorf("server: %v", err)
+		}
+	}
+	if c.StatelessAuthRequests != nil {
+		if s.authRequestSealer, err = newAuthRequestSealer(c.StatelessAuthRequests, s.issuerURL.String(), now); err != nil {
+			return nil, fmt.Errorf("server: %v", err)
+		}
+	}
+	passwordPolicy := c.PasswordPolicy
+	if passwordPolicy == nil {
+		passwordPolicy = &PasswordPolicyConfig{}
+	}
+	if s.passwordPolicy, err = newPasswordPolicy(passwordPolicy); err != nil {
+		return nil, fmt.Errorf("server: %v", err)
+	}
+	cookieConfig := c.Cookies
+	if cookieConfig == nil {
+		cookieConfig = &CookieConfig{}
+	}
+	if s.cookies, err = newCookies(cookieConfig, s.issuerURL); err != nil {
+		return nil, fmt.Errorf("server: %v", err)
+	}
+	s.csrfProtection = c.CSRFProtection
+	if c.AuditLog != nil {
+		if s.auditLog, err = newAuditLog(c.AuditLog); err != nil {
+			return nil, fmt.Errorf("server: %v", err)
+		}
+	}
+	if c.HomeRealmDiscovery != nil {
+		if tmpls.homeRealmTmpl == nil {
+			return nil, fmt.Errorf("server: home realm discovery requires the %s template", tmplHomeRealm)
+		}
+		if s.homeRealms, err = newHomeRealmDiscovery(c.HomeRealmDiscovery); err != nil {
+			return nil, fmt.Errorf("server: %v", err)
+		}
+	}
+
+	s.isLeader = c.IsLeader
+	if s.isLeader == nil {
+		s.isLeader = func() bool { return true }
+	}
+
+	s.signer = c.Signer
+	if s.signer == nil {
+		localSigner := newLocalSigner(s.storage, rotationStrategy, now, s.logger)
+		localSigner.isLeader = s.isLeader
+		s.signer = localSigner
+	}
 
 	// Retrieves connector objects in backend storage. This list includes the static connectors
 	// defined in the ConfigMap and dynamic connectors retrieved from the storage.
@@ -353,7 +815,13 @@ func newServer(ctx context.Context, c Config, rotationStrategy rotationStrategy)
 			Buckets: []float64{200, 500, 900, 1500},
 		}, []string{"code", "method", "handler"})
 
-		c.PrometheusRegistry.MustRegister(requestCounter, durationHist, sizeHist)
+		c.PrometheusRegistry.MustRegister(requestCounter, durationHist, sizeHist, &signingKeyCollector{s.signer, now, s.logger})
+
+		s.gcMetrics = newGCMetrics()
+		c.PrometheusRegistry.MustRegister(s.gcMetrics.deleted, s.gcMetrics.duration)
+
+		s.metrics = newIdentityMetrics()
+		c.PrometheusRegistry.MustRegister(s.metrics.collectors()...)
 
 		instrumentHandler = func(handlerName string, handler http.Handler) http.HandlerFunc {
 			return promhttp.InstrumentHandlerDuration(durationHist.MustCurryWith(prometheus.Labels{"handler": handlerName}),
@@ -364,85 +832,96 @@ func newServer(ctx context.Context, c Config, rotationStrategy rotationStrategy)
 		}
 	}
 
-	parseRealIP := func(r *http.Request) (string, error) {
-		remoteAddr, _, err := net.SplitHostPort(r.RemoteAddr)
-		if err != nil {
-			return "", err
+	withMiddleware := func(handler http.Handler) http.Handler {
+		for i := len(c.Middleware) - 1; i >= 0; i-- {
+			handler = c.Middleware[i](handler)
 		}
-
-		remoteIP, err := netip.ParseAddr(remoteAddr)
-		if err != nil {
-			return "", err
-		}
-
-		for _, n := range c.TrustedRealIPCIDRs {
-			if !n.Contains(remoteIP) {
-				return remoteAddr, nil // Fallback to the address from the request if the header is provided
-			}
-		}
-
-		ipVal := r.Header.Get(c.RealIPHeader)
-		if ipVal != "" {
-			ip, err := netip.ParseAddr(ipVal)
-			if err == nil {
-				return ip.String(), nil
-			}
-		}
-
-		return remoteAddr, nil
+		return handler
 	}
 
 	handlerWithHeaders := func(handlerName string, handler http.Handler) http.HandlerFunc {
+		handler = withMiddleware(handler)
 		return func(w http.ResponseWriter, r *http.Request) {
 			for k, v := range c.Headers {
 				w.Header()[k] = v
 			}
 
 			// Context values are used for logging purposes with the log/slog logger.
-			rCtx := r.Context()
-			rCtx = WithRequestID(rCtx)
+			// The request ID is also returned to the client and added to the
+			// span, so that the logs and the trace of a request can be found.
+			rCtx, reqID := withIncomingRequestID(r.Context(), r.Header.Get(requestIDHeader))
+			w.Header().Set(requestIDHeader, reqID)
+			trace.SpanFromContext(rCtx).SetAttributes(attrRequestID.String(reqID))
 
 			if c.RealIPHeader != "" {
-				realIP, err := parseRealIP(r)
+				realIP, err := forwardedClientIP(r, c.RealIPHeader, c.TrustedRealIPCIDRs)
 				if err == nil {
 					rCtx = WithRemoteIP(rCtx, realIP)
 				}
 			}
 
 			r = r.WithContext(rCtx)
+			r = r.WithContext(withAuditRequest(r))
 			instrumentHandler(handlerName, handler)(w, r)
 		}
 	}
+	handlerWithTracing := func(handlerName string, handler http.Handler) http.Handler {
+		return otelhttp.NewHandler(handlerWithHeaders(handlerName, handler), handlerName,
+			otelhttp.WithTracerProvider(tracerProvider),
+			otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+				return r.Method + " " + handlerName
+			}),
+		)
+	}
 
-	r := mux.NewRouter().SkipClean(true).UseEncodedPath()
+	r := c.Router
+	if r == nil {
+		r = mux.NewRouter()
+	}
+	r.SkipClean(true).UseEncodedPath()
 	handle := func(p string, h http.Handler) {
-		r.Handle(path.Join(issuerURL.Path, p), handlerWithHeaders(p, h))
+		r.Handle(path.Join(issuerURL.Path, p), handlerWithTracing(p, h))
 	}
 	handleFunc := func(p string, h http.HandlerFunc) {
 		handle(p, h)
 	}
 	handlePrefix := func(p string, h http.Handler) {
 		prefix := path.Join(issuerURL.Path, p)
-		r.PathPrefix(prefix).Handler(http.StripPrefix(prefix, h))
+		r.PathPrefix(prefix).Handler(http.StripPrefix(prefix, withMiddleware(h)))
+	}
+	// Public endpoints are readable by the origins of all clients.
+	withCORS := func(h http.HandlerFunc) http.Handler {
+		return s.withCORS(h, s.originAllowed, s.originAllowed)
 	}
 	handleWithCORS := func(p string, h http.HandlerFunc) {
-		var handler http.Handler = h
-		if len(c.AllowedOrigins) > 0 {
-			cors := handlers.CORS(
-				handlers.AllowedOrigins(c.AllowedOrigins),
-				handlers.AllowedHeaders(c.AllowedHeaders),
-			)
-			handler = cors(handler)
-		}
-		r.Handle(path.Join(issuerURL.Path, p), handlerWithHeaders(p, handler))
+		r.Handle(path.Join(issuerURL.Path, p), handlerWithTracing(p, withCORS(h)))
+	}
+	if r.NotFoundHandler == nil {
+		r.NotFoundHandler = http.NotFoundHandler()
 	}
-	r.NotFoundHandler = http.NotFoundHandler()
 
-	discoveryHandler, err := s.discoveryHandler()
+	// Start signing before building the discovery document, which advertises
+	// the signing algorithm.
+	s.signer.Start(ctx)
+
+	discoveryHandler, err := s.discoveryHandler(ctx)
 	if err != nil {
 		return nil, err
 	}
 	handleWithCORS("/.well-known/openid-configuration", discoveryHandler)
+
+	metadataHandler, err := s.authorizationServerMetadataHandler(ctx)
+	if err != nil {
+		return nil, err
+	}
+	handleWithCORS(authorizationServerMetadataPath, metadataHandler)
+	if issuerPath := strings.TrimSuffix(issuerURL.Path, "/"); issuerPath != "" {
+		// RFC 8414 inserts the well-known path between the host and the path
+		// of the issuer.
+		r.Handle(authorizationServerMetadataPath+issuerPath,
+			handlerWithTracing(authorizationServerMetadataPath, withCORS(metadataHandler)))
+	}
+	r.Handle(webFingerPath, handlerWithTracing(webFingerPath, http.HandlerFunc(s.handleWebFinger)))
 	// Handle the root path for the better user experience.
 	handleWithCORS("/", func(w http.ResponseWriter, r *http.Request) {
 		_, err := fmt.Fprintf(w, `<!DOCTYPE html>
@@ -452,25 +931,28 @@ func newServer(ctx context.Context, c Config, rotationStrategy rotationStrategy)
 			<p><a href=%q>Discovery</a></p>`,
 			s.issuerURL.String()+"/.well-known/openid-configuration")
 		if err != nil {
-			s.logger.Error("failed to write response", "err", err)
+			s.logger.ErrorContext(r.Context(), "failed to write response", "err", err)
 			s.renderError(r, w, http.StatusInternalServerError, "Handling the / path error.")
 			return
 		}
 	})
 
-	// TODO(ericchiang): rate limit certain paths based on IP.
-	handleWithCORS("/token", s.handleToken)
+	// Token responses are only readable by the origins of the client making
+	// the request.
+	r.Handle(path.Join(issuerURL.Path, "/token"), handlerWithTracing("/token",
+		s.withCORS(s.rateLimited("/token", s.handleToken), s.tokenRequestOriginAllowed, s.originAllowed)))
 	handleWithCORS("/keys", s.handlePublicKeys)
 	handleWithCORS("/userinfo", s.handleUserInfo)
 	handleWithCORS("/token/introspect", s.handleIntrospect)
-	handleFunc("/auth", s.handleAuthorization)
+	handleFunc("/auth", s.rateLimited("/auth", s.handleAuthorization))
 	handleFunc("/auth/{connector}", s.handleConnectorLogin)
 	handleFunc("/auth/{connector}/login", s.handlePasswordLogin)
 	handleFunc("/device", s.handleDeviceExchange)
 	handleFunc("/device/auth/verify_code", s.verifyUserCode)
-	handleFunc("/device/code", s.handleDeviceCode)
+	handleFunc("/device/code", s.rateLimited("/device/code", s.handleDeviceCode))
+	handleFunc("/device/qr", s.handleDeviceQRCode)
 	// TODO(nabokihms): "/device/token" endpoint is deprecated, consider using /token endpoint instead
-	handleFunc("/device/token", s.handleDeviceTokenDeprecated)
+	handleFunc("/device/token", s.rateLimited("/device/token", s.handleDeviceTokenDeprecated))
 	handleFunc(deviceCallbackURI, s.handleDeviceCallback)
 	handleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
 		// Strip the X-Remote-* headers to prevent security issues on
@@ -486,22 +968,51 @@ func newServer(ctx context.Context, c Config, rotationStrategy rotationStrategy)
 	// "authproxy" connector.
 	handleFunc("/callback/{connector}", s.handleConnectorCallback)
 	handleFunc("/approval", s.handleApproval)
-	handle("/healthz", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if !c.HealthChecker.IsHealthy() {
-			s.renderError(r, w, http.StatusInternalServerError, "Health check failed.")
-			return
-		}
-		fmt.Fprintf(w, "Health check passed")
-	}))
+	handleFunc("/cancel", s.handleCancel)
+	if s.passwordReset != nil {
+		handleFunc("/reset", s.handlePasswordResetRequest)
+		handleFunc("/reset/confirm", s.handlePasswordReset)
+	}
+	if s.registration != nil {
+		handleFunc("/register", s.handleRegistrationRequest)
+		handleFunc("/register/confirm", s.handleRegistration)
+	}
+	if s.emailVerification != nil {
+		handleFunc("/verify", s.handleEmailVerification)
+	}
+	// Sessions of users are only listed and revoked from the configured
+	// origins, not from those of any client.
+	r.Handle(path.Join(issuerURL.Path, "/sessions"), handlerWithTracing("/sessions",
+		s.withCORS(http.HandlerFunc(s.handleSessions), s.configuredOrigin, s.configuredOrigin)))
+	handleFunc(sessionsCallbackPath, s.handleSessionsCallback)
+	handleFunc("/healthz", s.handleHealth)
+	handleFunc("/healthz/live", s.handleLive)
+	handleFunc("/healthz/ready", s.handleReady)
 
 	handlePrefix("/static", static)
 	handlePrefix("/theme", theme)
 	handleFunc("/robots.txt", robots)
 
+	for _, route := range c.Routes {
+		h := route.Handler
+		if route.CORS {
+			h = withCORS(h.ServeHTTP)
+		}
+		if route.Prefix {
+			prefix := path.Join(issuerURL.Path, route.Path)
+			r.PathPrefix(prefix).Handler(http.StripPrefix(prefix, handlerWithTracing(route.Path, h)))
+			continue
+		}
+		handle(route.Path, h)
+	}
+
 	s.mux = r
 
-	s.startKeyRotation(ctx, rotationStrategy, now)
-	s.startGarbageCollection(ctx, value(c.GCFrequency, 5*time.Minute), now)
+	s.startGarbageCollection(ctx, gcSchedules(value(c.GCFrequency, 5*time.Minute), c.GCIntervals), c.GCJitter, now)
+	s.startWebhookDispatcher(ctx)
+	if s.auditLog != nil {
+		s.startAuditLog(ctx)
+	}
 
 	return s, nil
 }
@@ -527,19 +1038,37 @@ func newPasswordDB(s storage.Storage) interface {
 	connector.Connector
 	connector.PasswordConnector
 } {
-	return passwordDB{s}
+	return passwordDB{s: s}
 }
 
 type passwordDB struct {
 	s storage.Storage
+
+	// nil unless logins are locked out after repeated failures.
+	lockout *passwordLockout
 }
 
 func (db passwordDB) Login(ctx context.Context, s connector.Scopes, email, password string) (connector.Identity, bool, error) {
+	ip := requestRemoteIP(ctx)
 	p, err := db.s.GetPassword(email)
 	if err != nil {
 		if err != storage.ErrNotFound {
 			return connector.Identity{}, false, fmt.Errorf("get password: %v", err)
 		}
+		if err := db.lockout.check(ip, nil); err != nil {
+			db.lockout.logger.InfoContext(ctx, "password login locked out", "email", email, "err", err)
+			return connector.Identity{}, false, nil
+		}
+		if err := db.lockout.failed(ctx, db.s, ip, nil); err != nil {
+			return connector.Identity{}, false, fmt.Errorf("record failed login: %v", err)
+		}
+		return connector.Identity{}, false, nil
+	}
+	// Locked out logins are rejected without checking the password, so that
+	// guessing doesn't continue during a lockout. They fail like wrong
+	// passwords, which doesn't reveal that the account exists.
+	if err := db.lockout.check(ip, &p); err != nil {
+		db.lockout.logger.InfoContext(ctx, "password login locked out", "email", email, "err", err)
 		return connector.Identity{}, false, nil
 	}
 	// This check prevents dex users from logging in using static passwords
@@ -548,13 +1077,19 @@ func (db passwordDB) Login(ctx context.Context, s connector.Scopes, email, passw
 		return connector.Identity{}, false, err
 	}
 	if err := bcrypt.CompareHashAndPassword(p.Hash, []byte(password)); err != nil {
+		if err := db.lockout.failed(ctx, db.s, ip, &p); err != nil {
+			return connector.Identity{}, false, fmt.Errorf("record failed login: %v", err)
+		}
 		return connector.Identity{}, false, nil
 	}
+	if err := db.lockout.succeeded(db.s, p); err != nil {
+		return connector.Identity{}, false, fmt.Errorf("reset failed logins: %v", err)
+	}
 	return connector.Identity{
 		UserID:        p.UserID,
 		Username:      p.Username,
 		Email:         p.Email,
-		EmailVerified: true,
+		EmailVerified: p.EmailVerified,
 	}, true, nil
 }
 
@@ -573,12 +1108,13 @@ func (db passwordDB) Refresh(ctx context.Context, s connector.Scopes, identity c
 		return connector.Identity{}, errors.New("user not found")
 	}
 
-	// If a user has updated their username, that will be reflected in the
-	// refreshed token.
+	// If a user has updated their username or verified their email, that will
+	// be reflected in the refreshed token.
 	//
 	// No other fields are expected to be refreshable as email is effectively used
 	// as an ID and this implementation doesn't deal with groups.
 	identity.Username = p.Username
+	identity.EmailVerified = p.EmailVerified
 
 	return identity, nil
 }
@@ -595,17 +1131,26 @@ func newKeyCacher(s storage.Storage, now func() time.Time) storage.Storage {
 	return &keyCacher{Storage: s, now: now}
 }
 
+// keyCacheTTL bounds how long keys are cached, so keys rotated on demand by
+// another instance are picked up well before the scheduled rotation.
+const keyCacheTTL = 30 * time.Second
+
 type keyCacher struct {
 	storage.Storage
 
 	now  func() time.Time
-	keys atomic.Value // Always holds nil or type *storage.Keys.
+	keys atomic.Value // Always holds nil or type *cachedKeys.
+}
+
+type cachedKeys struct {
+	keys   storage.Keys
+	expiry time.Time
 }
 
 func (k *keyCacher) GetKeys() (storage.Keys, error) {
-	keys, ok := k.keys.Load().(*storage.Keys)
-	if ok && keys != nil && k.now().Before(keys.NextRotation) {
-		return *keys, nil
+	cached, ok := k.keys.Load().(*cachedKeys)
+	if ok && cached != nil && k.now().Before(cached.expiry) {
+		return cached.keys, nil
 	}
 
 	storageKeys, err := k.Storage.GetKeys()
@@ -614,28 +1159,29 @@ func (k *keyCacher) GetKeys() (storage.Keys, error) {
 	}
 
 	if k.now().Before(storageKeys.NextRotation) {
-		k.keys.Store(&storageKeys)
+		expiry := k.now().Add(keyCacheTTL)
+		if storageKeys.NextRotation.Before(expiry) {
+			expiry = storageKeys.NextRotation
+		}
+		k.keys.Store(&cachedKeys{keys: storageKeys, expiry: expiry})
 	}
 	return storageKeys, nil
 }
 
-func (s *Server) startGarbageCollection(ctx context.Context, frequency time.Duration, now func() time.Time) {
-	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-time.After(frequency):
-				if r, err := s.storage.GarbageCollect(now()); err != nil {
-					s.logger.ErrorContext(ctx, "garbage collection failed", "err", err)
-				} else if !r.IsEmpty() {
-					s.logger.InfoContext(ctx, "garbage collection run, delete auth",
-						"requests", r.AuthRequests, "auth_codes", r.AuthCodes,
-						"device_requests", r.DeviceRequests, "device_tokens", r.DeviceTokens)
-				}
-			}
-		}
-	}()
+// UpdateKeys drops the cached keys so the update is visible immediately.
+func (k *keyCacher) UpdateKeys(updater func(old storage.Keys) (storage.Keys, error)) error {
+	defer k.keys.Store((*cachedKeys)(nil))
+	return k.Storage.UpdateKeys(updater)
+}
+
+// RotateKeys rotates the signing key immediately instead of waiting for the
+// next scheduled rotation, for example after a key may have been compromised.
+func (s *Server) RotateKeys(ctx context.Context) error {
+	rotator, ok := s.signer.(signer.Rotator)
+	if !ok {
+		return errors.New("signer does not support rotating keys on demand")
+	}
+	return rotator.Rotate(ctx)
 }
 
 // ConnectorConfig is a configuration that can open a connector.
@@ -689,7 +1235,34 @@ func openConnector(logger *slog.Logger, conn storage.Connector) (connector.Conne
 		return c, fmt.Errorf("failed to create connector %s: %v", conn.ID, err)
 	}
 
-	return c, nil
+	return wrapConnector(c, conn)
+}
+
+// wrapConnector wraps a connector by the middlewares listed in the
+// "middleware" field of its config.
+func wrapConnector(c connector.Connector, conn storage.Connector) (connector.Connector, error) {
+	if len(conn.Config) == 0 {
+		return c, nil
+	}
+	var config struct {
+		Middleware []middleware.Config `json:"middleware"`
+	}
+	if err := json.Unmarshal(conn.Config, &config); err != nil {
+		return c, fmt.Errorf("parse connector config: %v", err)
+	}
+	middlewares, err := middleware.New(config.Middleware)
+	if err != nil {
+		return c, fmt.Errorf("connector %s: %v", conn.ID, err)
+	}
+	return middleware.Wrap(c, middlewares), nil
+}
+
+// ValidateConnector checks that a connector can be opened with its config.
+// Opening some connectors contacts their identity provider, e.g. for OpenID
+// Connect discovery.
+func ValidateConnector(logger *slog.Logger, conn storage.Connector) error {
+	_, err := openConnector(logger, conn)
+	return err
 }
 
 // OpenConnector updates server connector map with specified connector object.
@@ -697,7 +1270,11 @@ func (s *Server) OpenConnector(conn storage.Connector) (Connector, error) {
 	var c connector.Connector
 
 	if conn.Type == LocalConnector {
-		c = newPasswordDB(s.storage)
+		var err error
+		c, err = wrapConnector(passwordDB{s: s.storage, lockout: s.passwordLockout}, conn)
+		if err != nil {
+			return Connector{}, fmt.Errorf("failed to open connector: %v", err)
+		}
 	} else {
 		var err error
 		c, err = openConnector(s.logger, conn)
@@ -24,6 +24,7 @@ import (
 	gosundheit "github.com/AppsFlyer/go-sundheit"
 	"github.com/coreos/go-oidc/v3/oidc"
 	"github.com/go-jose/go-jose/v4"
+	"github.com/gorilla/mux"
 	"github.com/kylelemons/godebug/pretty"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/require"
@@ -704,6 +705,19 @@ func makeOAuth2Tests(clientID string, clientSecret string, now func() time.Time)
 					return nil
 				},
 			},
+			{
+				name: "Invalid claims parameter in authorization query",
+				authCodeOptions: []oauth2.AuthCodeOption{
+					oauth2.SetAuthURLParam("claims", `{"id_token": ["email"]}`),
+				},
+				authError: &OAuth2ErrorResponse{
+					Error:            errInvalidRequest,
+					ErrorDescription: "Invalid claims parameter.",
+				},
+				handleToken: func(ctx context.Context, p *oidc.Provider, config *oauth2.Config, token *oauth2.Token, conn *mock.Callback) error {
+					return nil
+				},
+			},
 		},
 	}
 }
@@ -1283,9 +1297,16 @@ func TestPasswordDB(t *testing.T) {
 	}
 
 	s.CreatePassword(ctx, storage.Password{
-		Email:    "jane@example.com",
-		Username: "jane",
-		UserID:   "foobar",
+		Email:         "jane@example.com",
+		Username:      "jane",
+		UserID:        "foobar",
+		Hash:          h,
+		EmailVerified: true,
+	})
+	s.CreatePassword(ctx, storage.Password{
+		Email:    "john@example.com",
+		Username: "john",
+		UserID:   "barfoo",
 		Hash:     h,
 	})
 
@@ -1308,9 +1329,19 @@ func TestPasswordDB(t *testing.T) {
 				EmailVerified: true,
 			},
 		},
+		{
+			name:     "unverified email",
+			username: "john@example.com",
+			password: pw,
+			wantIdentity: connector.Identity{
+				Email:    "john@example.com",
+				Username: "john",
+				UserID:   "barfoo",
+			},
+		},
 		{
 			name:        "unknown user",
-			username:    "john@example.com",
+			username:    "jim@example.com",
 			password:    pw,
 			wantInvalid: true,
 		},
@@ -1816,3 +1847,90 @@ func TestHeaders(t *testing.T) {
 
 	require.Equal(t, "max-age=31536000; includeSubDomains", resp.Header.Get("Strict-Transport-Security"))
 }
+
+func TestEmbedding(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/app", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("app"))
+	})
+
+	var requestIDs []string
+	httpServer, _ := newTestServer(ctx, t, func(c *Config) {
+		c.Router = router
+		c.Routes = []Route{
+			{
+				Path: "/admin/{page}",
+				Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Write([]byte("admin " + mux.Vars(r)["page"]))
+				}),
+			},
+			{
+				Path:   "/files",
+				Prefix: true,
+				Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Write([]byte("file " + r.URL.Path))
+				}),
+			},
+		}
+		c.Middleware = []func(http.Handler) http.Handler{
+			func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("X-Embedded", "yes")
+					next.ServeHTTP(w, r)
+				})
+			},
+			func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					requestIDs = append(requestIDs, w.Header().Get(requestIDHeader))
+					next.ServeHTTP(w, r)
+				})
+			},
+		}
+	})
+	defer httpServer.Close()
+
+	get := func(p string) (*http.Response, string) {
+		resp, err := http.Get(httpServer.URL + p)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(body)
+	}
+
+	resp, body := get("/admin/users")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "admin users", body)
+	require.Equal(t, "yes", resp.Header.Get("X-Embedded"))
+	require.Equal(t, []string{resp.Header.Get(requestIDHeader)}, requestIDs)
+
+	resp, body = get("/files/a/b.txt")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "file /a/b.txt", body)
+
+	resp, _ = get("/.well-known/openid-configuration")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "yes", resp.Header.Get("X-Embedded"), "middleware wraps the routes of dex")
+
+	resp, body = get("/app")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "app", body, "routes of the injected router are kept")
+	require.Empty(t, resp.Header.Get("X-Embedded"))
+}
+
+func TestInvalidRoutes(t *testing.T) {
+	for _, route := range []Route{
+		{Path: "admin", Handler: http.NotFoundHandler()},
+		{Path: "/admin"},
+	} {
+		_, err := newServer(context.Background(), Config{
+			Issuer:  "http://127.0.0.1/dex",
+			Storage: memory.New(logger),
+			Routes:  []Route{route},
+		}, staticRotationStrategy(testKey))
+		require.Error(t, err, "%+v", route)
+	}
+}
@@ -0,0 +1,317 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+
+	"github.com/dexidp/dex/server/internal"
+	"github.com/dexidp/dex/storage"
+)
+
+// sessionInfo describes an offline session of a user, which is backed by a
+// refresh token.
+type sessionInfo struct {
+	ID          string    `json:"id"`
+	ClientID    string    `json:"client_id"`
+	ClientName  string    `json:"client_name,omitempty"`
+	ConnectorID string    `json:"connector_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastUsed    time.Time `json:"last_used"`
+}
+
+// sessionsAudience is the audience of access tokens for the sessions
+// endpoint, issued for the dex:sessions scope.
+func (s *Server) sessionsAudience() string {
+	return s.absURL("/sessions")
+}
+
+// handleSessions lets users list and revoke their own offline sessions. The
+// user is identified by an access token issued by dex for the dex:sessions
+// scope. ID tokens, which relying parties may pass on, and access tokens for
+// other scopes aren't accepted.
+//
+// Sessions are listed with a GET request and revoked by posting the ID of the
+// session in the id parameter. Clients send the access token as a bearer token
+// and get JSON responses. If the theme provides a sessions page, browsers
+// without a token log in with the built-in sessions client instead, which keeps
+// the access token in a cookie, and are served the page.
+func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		s.tokenErrHelper(w, errInvalidRequest, "Unsupported request method.", http.StatusMethodNotAllowed)
+		return
+	}
+
+	rawToken, ok := sessionsBearerToken(r)
+	html := false
+	if !ok && s.templates.sessionsTmpl != nil {
+		if c, err := r.Cookie(sessionsCookieName); err == nil && c.Value != "" {
+			rawToken, ok, html = c.Value, true, true
+		} else if r.Method == http.MethodGet && strings.Contains(r.Header.Get("Accept"), "text/html") {
+			s.startSessionsLogin(w, r)
+			return
+		}
+	}
+	if !ok {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		s.tokenErrHelper(w, errAccessDenied, "Invalid bearer token.", http.StatusUnauthorized)
+		return
+	}
+
+	sub, err := s.verifySessionsToken(ctx, rawToken)
+	if err != nil {
+		if html {
+			// Log in again once the token in the cookie expired.
+			s.cookies.clear(w, sessionsCookieName)
+			if r.Method == http.MethodGet {
+				s.startSessionsLogin(w, r)
+				return
+			}
+		}
+		s.tokenErrHelper(w, errAccessDenied, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		// Cookies are sent along with cross-site form posts.
+		if html && !s.requireCSRF(w, r) {
+			return
+		}
+		id := r.PostFormValue("id")
+		if id == "" {
+			s.tokenErrHelper(w, errInvalidRequest, "No session ID provided.", http.StatusBadRequest)
+			return
+		}
+		if err := s.revokeSession(sub.UserId, sub.ConnId, id); err != nil {
+			if err == storage.ErrNotFound {
+				s.tokenErrHelper(w, errInvalidRequest, "Session not found.", http.StatusNotFound)
+				return
+			}
+			s.logger.ErrorContext(ctx, "failed to revoke session", "err", err)
+			s.tokenErrHelper(w, errServerError, "", http.StatusInternalServerError)
+			return
+		}
+		s.logger.InfoContext(ctx, "session revoked by user", "user_id", sub.UserId, "connector_id", sub.ConnId, "token_id", id)
+		s.emitEvent(ctx, auditEvent{
+			Type:        eventRefreshRevoked,
+			ConnectorID: sub.ConnId,
+			UserID:      sub.UserId,
+			Details:     map[string]string{"refresh_token_id": id, "revoked_by": "user"},
+		})
+
+		if html {
+			http.Redirect(w, r, s.absPath("/sessions"), http.StatusSeeOther)
+		} else {
+			w.WriteHeader(http.StatusNoContent)
+		}
+		return
+	}
+
+	sessions, err := s.listSessions(sub.UserId, sub.ConnId)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to list sessions", "err", err)
+		s.tokenErrHelper(w, errServerError, "", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	if html {
+		csrfToken := s.requireCSRFToken(w, r)
+		if err := s.templates.sessions(r, w, s.absPath("/sessions"), csrfToken, sessions); err != nil {
+			s.logger.ErrorContext(ctx, "server template error", "err", err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		Sessions []sessionInfo `json:"sessions"`
+	}{sessions})
+}
+
+// verifySessionsToken verifies an access token for the sessions endpoint and
+// returns the user it was issued for.
+func (s *Server) verifySessionsToken(ctx context.Context, rawToken string) (*internal.IDTokenSubject, error) {
+	if jwsHeaderType(rawToken) != accessTokenHeaderType {
+		return nil, errors.New("not an access token")
+	}
+	verifier := oidc.NewVerifier(s.issuerURL.String(), &signerKeySet{s.signer}, &oidc.Config{ClientID: s.sessionsAudience()})
+	token, err := verifier.Verify(ctx, rawToken)
+	if err != nil {
+		return nil, err
+	}
+	var sub internal.IDTokenSubject
+	if err := internal.Unmarshal(token.Subject, &sub); err != nil {
+		return nil, errors.New("invalid subject")
+	}
+	return &sub, nil
+}
+
+func sessionsBearerToken(r *http.Request) (string, bool) {
+	const prefix = "Bearer "
+
+	auth := r.Header.Get("Authorization")
+	if len(auth) <= len(prefix) || !strings.EqualFold(prefix, auth[:len(prefix)]) {
+		return "", false
+	}
+	return auth[len(prefix):], true
+}
+
+const (
+	// sessionsClientID is the built-in client users log in with to manage
+	// their sessions in the browser.
+	sessionsClientID = "dex-sessions"
+	// sessionsCallbackPath is the redirect URI of the sessions client, which
+	// redeems the code itself.
+	sessionsCallbackPath = "/sessions/callback"
+
+	// Cookies holding the access token of the sessions page and the state of
+	// the login leading to it.
+	sessionsCookieName      = "dex_sessions"
+	sessionsStateCookieName = "dex_sessions_state"
+)
+
+// sessionsClient returns the built-in client of the sessions page. Codes are
+// never redeemed at the token endpoint, so its secret isn't known to anyone.
+func sessionsClient(issuerURL url.URL) storage.Client {
+	issuerURL.Path = path.Join(issuerURL.Path, sessionsCallbackPath)
+	return storage.Client{
+		ID:           sessionsClientID,
+		Secret:       storage.NewID() + storage.NewID(),
+		RedirectURIs: []string{issuerURL.String()},
+		Name:         "Dex",
+	}
+}
+
+// startSessionsLogin sends the browser to log in with the sessions client.
+func (s *Server) startSessionsLogin(w http.ResponseWriter, r *http.Request) {
+	state := storage.NewID()
+	s.cookies.set(w, sessionsStateCookieName, state)
+	q := url.Values{
+		"client_id":     {sessionsClientID},
+		"redirect_uri":  {s.absURL(sessionsCallbackPath)},
+		"response_type": {responseTypeCode},
+		"scope":         {scopeOpenID + " " + scopeSessions},
+		"state":         {state},
+	}
+	http.Redirect(w, r, s.absPath("/auth")+"?"+q.Encode(), http.StatusFound)
+}
+
+// handleSessionsCallback completes the login of the sessions page, keeping an
+// access token for the sessions endpoint in a cookie.
+func (s *Server) handleSessionsCallback(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	state, err := r.Cookie(sessionsStateCookieName)
+	if err != nil || state.Value == "" || subtle.ConstantTimeCompare([]byte(state.Value), []byte(r.FormValue("state"))) != 1 {
+		s.renderError(r, w, http.StatusBadRequest, "User session error.")
+		return
+	}
+	s.cookies.clear(w, sessionsStateCookieName)
+
+	code := r.FormValue("code")
+	if code == "" {
+		s.renderError(r, w, http.StatusBadRequest, "Login error.")
+		return
+	}
+	authCode, err := s.storage.GetAuthCode(code)
+	if err != nil || s.now().After(authCode.Expiry) || authCode.ClientID != sessionsClientID {
+		if err != nil && err != storage.ErrNotFound {
+			s.logger.ErrorContext(ctx, "failed to get auth code", "err", err)
+			s.renderError(r, w, http.StatusInternalServerError, "Internal server error.")
+			return
+		}
+		s.renderError(r, w, http.StatusBadRequest, "Login error.")
+		return
+	}
+	if err := s.storage.DeleteAuthCode(authCode.ID); err != nil {
+		s.logger.ErrorContext(ctx, "failed to delete auth code", "err", err)
+		s.renderError(r, w, http.StatusInternalServerError, "Internal server error.")
+		return
+	}
+
+	accessToken, _, err := s.newAccessToken(ctx, sessionsClientID, authCode.Claims, authCode.Scopes, nil, "", authCode.ConnectorID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to create new access token", "err", err)
+		s.renderError(r, w, http.StatusInternalServerError, "Internal server error.")
+		return
+	}
+	s.cookies.set(w, sessionsCookieName, accessToken)
+	http.Redirect(w, r, s.absPath("/sessions"), http.StatusSeeOther)
+}
+
+// listSessions returns the offline sessions of a user, most recently used
+// first.
+func (s *Server) listSessions(userID, connID string) ([]sessionInfo, error) {
+	offlineSessions, err := s.storage.GetOfflineSessions(userID, connID)
+	if err != nil {
+		if err == storage.ErrNotFound {
+			return []sessionInfo{}, nil
+		}
+		return nil, err
+	}
+
+	clientNames := make(map[string]string)
+	sessions := make([]sessionInfo, 0, len(offlineSessions.Refresh))
+	for _, ref := range offlineSessions.Refresh {
+		if ref == nil || ref.ID == "" {
+			continue
+		}
+		name, ok := clientNames[ref.ClientID]
+		if !ok {
+			if client, err := s.storage.GetClient(ref.ClientID); err == nil {
+				name = client.Name
+			}
+			clientNames[ref.ClientID] = name
+		}
+		sessions = append(sessions, sessionInfo{
+			ID:          ref.ID,
+			ClientID:    ref.ClientID,
+			ClientName:  name,
+			ConnectorID: connID,
+			CreatedAt:   ref.CreatedAt,
+			LastUsed:    ref.LastUsed,
+		})
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastUsed.After(sessions[j].LastUsed)
+	})
+	return sessions, nil
+}
+
+// revokeSession removes a refresh token from the offline sessions of a user
+// and deletes it.
+func (s *Server) revokeSession(userID, connID, refreshID string) error {
+	err := s.storage.UpdateOfflineSessions(userID, connID, func(old storage.OfflineSessions) (storage.OfflineSessions, error) {
+		found := false
+		for key, ref := range old.Refresh {
+			if ref != nil && ref.ID == refreshID {
+				delete(old.Refresh, key)
+				found = true
+			}
+		}
+		if !found {
+			return old, storage.ErrNotFound
+		}
+		return old, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.storage.DeleteRefresh(refreshID); err != nil && err != storage.ErrNotFound {
+		return err
+	}
+	return nil
+}
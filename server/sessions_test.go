@@ -0,0 +1,189 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dexidp/dex/storage"
+)
+
+func TestHandleSessions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, s := newTestServer(ctx, t, nil)
+	defer httpServer.Close()
+
+	t0 := time.Now().UTC().Round(time.Second)
+	require.NoError(t, s.storage.CreateClient(ctx, storage.Client{
+		ID:     "client1",
+		Secret: "secret",
+		Name:   "Client One",
+	}))
+	for _, ref := range []storage.RefreshTokenRef{
+		{ID: "refresh1", ClientID: "client1", CreatedAt: t0, LastUsed: t0},
+		{ID: "refresh2", ClientID: "client2", CreatedAt: t0, LastUsed: t0.Add(time.Minute)},
+	} {
+		require.NoError(t, s.storage.CreateRefresh(ctx, storage.RefreshToken{
+			ID:          ref.ID,
+			Token:       "token",
+			ClientID:    ref.ClientID,
+			ConnectorID: "mock",
+			CreatedAt:   ref.CreatedAt,
+			LastUsed:    ref.LastUsed,
+			Claims:      storage.Claims{UserID: "1"},
+		}))
+	}
+	require.NoError(t, s.storage.CreateOfflineSessions(ctx, storage.OfflineSessions{
+		UserID: "1",
+		ConnID: "mock",
+		Refresh: map[string]*storage.RefreshTokenRef{
+			"client1":  {ID: "refresh1", ClientID: "client1", CreatedAt: t0, LastUsed: t0},
+			"refresh2": {ID: "refresh2", ClientID: "client2", CreatedAt: t0, LastUsed: t0.Add(time.Minute)},
+		},
+	}))
+
+	accessToken, _, err := s.newAccessToken(ctx, "client1", storage.Claims{UserID: "1"}, []string{"openid", scopeSessions}, nil, "", "mock")
+	require.NoError(t, err)
+
+	listSessions := func(t *testing.T) []sessionInfo {
+		req := httptest.NewRequest(http.MethodGet, "/sessions", nil)
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		rr := httptest.NewRecorder()
+		s.handleSessions(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var resp struct {
+			Sessions []sessionInfo `json:"sessions"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		return resp.Sessions
+	}
+
+	sessions := listSessions(t)
+	require.Len(t, sessions, 2)
+	require.Equal(t, "refresh2", sessions[0].ID)
+	require.Equal(t, "refresh1", sessions[1].ID)
+	require.Equal(t, "Client One", sessions[1].ClientName)
+	require.Equal(t, "mock", sessions[1].ConnectorID)
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		s.handleSessions(rr, httptest.NewRequest(http.MethodGet, "/sessions", nil))
+		require.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("id token", func(t *testing.T) {
+		idToken, _, err := s.newIDToken(ctx, "client1", storage.Claims{UserID: "1"}, []string{"openid"}, nil, "", "", "", "mock")
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodGet, "/sessions", nil)
+		req.Header.Set("Authorization", "Bearer "+idToken)
+		rr := httptest.NewRecorder()
+		s.handleSessions(rr, req)
+		require.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("access token for other scopes", func(t *testing.T) {
+		otherToken, _, err := s.newAccessToken(ctx, "client1", storage.Claims{UserID: "1"}, []string{"openid"}, nil, "", "mock")
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodGet, "/sessions", nil)
+		req.Header.Set("Authorization", "Bearer "+otherToken)
+		rr := httptest.NewRecorder()
+		s.handleSessions(rr, req)
+		require.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("unknown session", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/sessions", strings.NewReader(url.Values{"id": {"unknown"}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		rr := httptest.NewRecorder()
+		s.handleSessions(rr, req)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("revoke from page", func(t *testing.T) {
+		do := func(req *http.Request, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+			for _, c := range cookies {
+				req.AddCookie(c)
+			}
+			rr := httptest.NewRecorder()
+			s.ServeHTTP(rr, req)
+			return rr
+		}
+		cookieNamed := func(rr *httptest.ResponseRecorder, name string) *http.Cookie {
+			for _, c := range rr.Result().Cookies() {
+				if c.Name == name {
+					return c
+				}
+			}
+			t.Fatalf("no %s cookie set", name)
+			return nil
+		}
+
+		// Browsers without a token log in with the sessions client.
+		req := httptest.NewRequest(http.MethodGet, "/sessions", nil)
+		req.Header.Set("Accept", "text/html")
+		rr := do(req)
+		require.Equal(t, http.StatusFound, rr.Code)
+		loginURL, err := url.Parse(rr.Header().Get("Location"))
+		require.NoError(t, err)
+		require.Equal(t, "/auth", loginURL.Path)
+		require.Equal(t, sessionsClientID, loginURL.Query().Get("client_id"))
+		stateCookie := cookieNamed(rr, sessionsStateCookieName)
+		require.Equal(t, stateCookie.Value, loginURL.Query().Get("state"))
+
+		// The login request is accepted, which renders the connector.
+		rr = do(httptest.NewRequest(http.MethodGet, loginURL.RequestURI(), nil))
+		require.Less(t, rr.Code, http.StatusBadRequest, rr.Body.String())
+
+		require.NoError(t, s.storage.CreateAuthCode(ctx, storage.AuthCode{
+			ID:          "sessions-code",
+			ClientID:    sessionsClientID,
+			RedirectURI: s.absURL(sessionsCallbackPath),
+			Scopes:      []string{"openid", scopeSessions},
+			ConnectorID: "mock",
+			Claims:      storage.Claims{UserID: "1"},
+			Expiry:      time.Now().Add(time.Minute),
+		}))
+		callback := sessionsCallbackPath + "?" + url.Values{"code": {"sessions-code"}, "state": {stateCookie.Value}}.Encode()
+		require.Equal(t, http.StatusBadRequest, do(httptest.NewRequest(http.MethodGet, callback, nil)).Code, "login without the state cookie")
+		rr = do(httptest.NewRequest(http.MethodGet, callback, nil), stateCookie)
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+		sessionCookie := cookieNamed(rr, sessionsCookieName)
+		require.True(t, sessionCookie.HttpOnly)
+
+		req = httptest.NewRequest(http.MethodGet, "/sessions", nil)
+		rr = do(req, sessionCookie)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Contains(t, rr.Body.String(), "Your Sessions")
+		require.Contains(t, rr.Body.String(), "Client One")
+		require.NotContains(t, rr.Body.String(), sessionCookie.Value)
+		csrfCookie := cookieNamed(rr, csrfCookieName)
+
+		revoke := func(csrfToken string) *httptest.ResponseRecorder {
+			form := url.Values{"id": {"refresh1"}, csrfFormField: {csrfToken}}
+			req := httptest.NewRequest(http.MethodPost, "/sessions", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			return do(req, sessionCookie, csrfCookie)
+		}
+		require.Equal(t, http.StatusForbidden, revoke("forged").Code)
+		rr = revoke(csrfCookie.Value)
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+
+		_, err = s.storage.GetRefresh("refresh1")
+		require.Equal(t, storage.ErrNotFound, err)
+
+		sessions := listSessions(t)
+		require.Len(t, sessions, 1)
+		require.Equal(t, "refresh2", sessions[0].ID)
+	})
+}
@@ -0,0 +1,213 @@
+package signer
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/go-jose/go-jose/v4"
+)
+
+// AWSKMSConfig holds the configuration for signing with asymmetric AWS KMS keys.
+//
+// Credentials are resolved with the default AWS credential chain: environment
+// variables, shared configuration files, web identity tokens (e.g. IAM roles
+// for service accounts) and the instance metadata service.
+//
+// Tokens are signed with the key referenced by KeyID, which is typically an
+// alias. Keys are rotated by pointing the alias at a new key and moving the
+// previous key to VerificationKeyIDs until all tokens it signed have expired.
+type AWSKMSConfig struct {
+	// Region of the keys. Defaults to the region of the AWS configuration.
+	Region string `json:"region"`
+
+	// Regions to fall back to if KMS in Region is unavailable. Keys must be
+	// multi-region keys replicated to these regions, and aliases must exist
+	// in every region.
+	FailoverRegions []string `json:"failoverRegions"`
+
+	// Key ID, ARN, alias name or alias ARN of the key used to sign tokens.
+	KeyID string `json:"keyID"`
+
+	// Keys which are no longer used for signing, but are still published
+	// to verify previously issued tokens.
+	VerificationKeyIDs []string `json:"verificationKeyIDs"`
+
+	// Profile in the shared AWS configuration files to use.
+	Profile string `json:"profile"`
+
+	// IAM role to assume before calling KMS.
+	AssumeRoleARN string `json:"assumeRoleARN"`
+
+	// Custom KMS endpoint, such as a VPC endpoint.
+	Endpoint string `json:"endpoint"`
+
+	// How often keys are read from KMS. Defaults to 5 minutes.
+	RefreshInterval string `json:"refreshInterval"`
+}
+
+// Open resolves AWS credentials and returns a signer backed by KMS.
+func (c *AWSKMSConfig) Open(ctx context.Context, logger *slog.Logger) (Signer, error) {
+	if c.KeyID == "" {
+		return nil, errors.New("awskms: no key ID specified")
+	}
+
+	var opts []func(*config.LoadOptions) error
+	if c.Region != "" {
+		opts = append(opts, config.WithRegion(c.Region))
+	}
+	if c.Profile != "" {
+		opts = append(opts, config.WithSharedConfigProfile(c.Profile))
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("awskms: load AWS config: %v", err)
+	}
+	if cfg.Region == "" {
+		return nil, errors.New("awskms: no region specified")
+	}
+	if c.AssumeRoleARN != "" {
+		provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(cfg), c.AssumeRoleARN)
+		cfg.Credentials = aws.NewCredentialsCache(provider)
+	}
+
+	store := &awsKMSKeyStore{
+		signingKeyID:       c.KeyID,
+		verificationKeyIDs: c.VerificationKeyIDs,
+	}
+	for _, region := range append([]string{cfg.Region}, c.FailoverRegions...) {
+		client := kms.NewFromConfig(cfg, func(o *kms.Options) {
+			o.Region = region
+			if c.Endpoint != "" {
+				o.BaseEndpoint = aws.String(c.Endpoint)
+			}
+		})
+		store.clients = append(store.clients, client)
+	}
+	return newRemoteSigner(store, c.RefreshInterval, logger.With("signer", "awskms"))
+}
+
+// kmsClient is the subset of the KMS API used by the signer.
+type kmsClient interface {
+	GetPublicKey(ctx context.Context, params *kms.GetPublicKeyInput, optFns ...func(*kms.Options)) (*kms.GetPublicKeyOutput, error)
+	Sign(ctx context.Context, params *kms.SignInput, optFns ...func(*kms.Options)) (*kms.SignOutput, error)
+}
+
+type awsKMSKeyStore struct {
+	// Clients for the primary region followed by the failover regions.
+	clients []kmsClient
+
+	signingKeyID       string
+	verificationKeyIDs []string
+}
+
+// withFailover calls f with the client of each region in order until one succeeds.
+func (s *awsKMSKeyStore) withFailover(f func(client kmsClient) error) error {
+	var errs []error
+	for _, client := range s.clients {
+		err := f(client)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// keyIDFromARN returns the key ID from a key ARN, which is the same for all
+// replicas of a multi-region key.
+func keyIDFromARN(arn string) string {
+	if i := strings.LastIndex(arn, ":key/"); i >= 0 {
+		return arn[i+len(":key/"):]
+	}
+	return arn
+}
+
+func (s *awsKMSKeyStore) publicKey(ctx context.Context, keyID string) (*jose.JSONWebKey, error) {
+	var out *kms.GetPublicKeyOutput
+	err := s.withFailover(func(client kmsClient) (err error) {
+		out, err = client.GetPublicKey(ctx, &kms.GetPublicKeyInput{KeyId: aws.String(keyID)})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("awskms: get public key %q: %v", keyID, err)
+	}
+	if out.KeyUsage != types.KeyUsageTypeSignVerify {
+		return nil, fmt.Errorf("awskms: key %q cannot be used for signing", keyID)
+	}
+
+	pub, err := x509.ParsePKIXPublicKey(out.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("awskms: parse public key %q: %v", keyID, err)
+	}
+	jwk, err := publicJWK(keyIDFromARN(aws.ToString(out.KeyId)), pub, "")
+	if err != nil {
+		return nil, fmt.Errorf("awskms: key %q: %v", keyID, err)
+	}
+	return jwk, nil
+}
+
+func (s *awsKMSKeyStore) keys(ctx context.Context) (string, []*jose.JSONWebKey, error) {
+	signingKey, err := s.publicKey(ctx, s.signingKeyID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	keys := []*jose.JSONWebKey{signingKey}
+	for _, keyID := range s.verificationKeyIDs {
+		key, err := s.publicKey(ctx, keyID)
+		if err != nil {
+			return "", nil, err
+		}
+		if key.KeyID == signingKey.KeyID {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return signingKey.KeyID, keys, nil
+}
+
+var kmsSigningAlgorithms = map[jose.SignatureAlgorithm]types.SigningAlgorithmSpec{
+	jose.RS256: types.SigningAlgorithmSpecRsassaPkcs1V15Sha256,
+	jose.RS384: types.SigningAlgorithmSpecRsassaPkcs1V15Sha384,
+	jose.RS512: types.SigningAlgorithmSpecRsassaPkcs1V15Sha512,
+	jose.ES256: types.SigningAlgorithmSpecEcdsaSha256,
+	jose.ES384: types.SigningAlgorithmSpecEcdsaSha384,
+	jose.ES512: types.SigningAlgorithmSpecEcdsaSha512,
+}
+
+func (s *awsKMSKeyStore) sign(ctx context.Context, keyID string, alg jose.SignatureAlgorithm, digest []byte) ([]byte, error) {
+	spec, ok := kmsSigningAlgorithms[alg]
+	if !ok {
+		return nil, fmt.Errorf("unsupported signature algorithm %s", alg)
+	}
+
+	var out *kms.SignOutput
+	err := s.withFailover(func(client kmsClient) (err error) {
+		out, err = client.Sign(ctx, &kms.SignInput{
+			KeyId:            aws.String(keyID),
+			Message:          digest,
+			MessageType:      types.MessageTypeDigest,
+			SigningAlgorithm: spec,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch alg {
+	case jose.ES256, jose.ES384, jose.ES512:
+		return ecdsaSignatureFromASN1(out.Signature, alg)
+	}
+	return out.Signature, nil
+}
@@ -0,0 +1,125 @@
+package signer
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKMS serves keys from memory. Aliases map to key IDs.
+type fakeKMS struct {
+	region      string
+	unavailable bool
+	aliases     map[string]string
+	privateKeys map[string]crypto.Signer
+}
+
+func (f *fakeKMS) resolve(keyID string) (string, crypto.Signer, error) {
+	if f.unavailable {
+		return "", nil, errors.New("service unavailable")
+	}
+	if id, ok := f.aliases[keyID]; ok {
+		keyID = id
+	}
+	key, ok := f.privateKeys[keyID]
+	if !ok {
+		return "", nil, errors.New("not found")
+	}
+	return keyID, key, nil
+}
+
+func (f *fakeKMS) GetPublicKey(_ context.Context, params *kms.GetPublicKeyInput, _ ...func(*kms.Options)) (*kms.GetPublicKeyOutput, error) {
+	keyID, key, err := f.resolve(aws.ToString(params.KeyId))
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &kms.GetPublicKeyOutput{
+		KeyId:     aws.String("arn:aws:kms:" + f.region + ":111122223333:key/" + keyID),
+		KeyUsage:  types.KeyUsageTypeSignVerify,
+		PublicKey: der,
+	}, nil
+}
+
+func (f *fakeKMS) Sign(_ context.Context, params *kms.SignInput, _ ...func(*kms.Options)) (*kms.SignOutput, error) {
+	_, key, err := f.resolve(aws.ToString(params.KeyId))
+	if err != nil {
+		return nil, err
+	}
+	var hash crypto.Hash
+	for alg, spec := range kmsSigningAlgorithms {
+		if spec == params.SigningAlgorithm {
+			hash = hashForAlgorithm[alg]
+		}
+	}
+	sig, err := key.Sign(rand.Reader, params.Message, hash)
+	if err != nil {
+		return nil, err
+	}
+	return &kms.SignOutput{Signature: sig}, nil
+}
+
+func TestAWSKMSSigner(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	newKMS := func(region string) *fakeKMS {
+		return &fakeKMS{
+			region:  region,
+			aliases: map[string]string{"alias/dex": "mrk-ec"},
+			privateKeys: map[string]crypto.Signer{
+				"mrk-ec":  ecKey,
+				"mrk-rsa": rsaKey,
+			},
+		}
+	}
+	primary, replica := newKMS("us-east-1"), newKMS("eu-west-1")
+
+	store := &awsKMSKeyStore{
+		clients:            []kmsClient{primary, replica},
+		signingKeyID:       "alias/dex",
+		verificationKeyIDs: []string{"mrk-rsa"},
+	}
+	s, err := newRemoteSigner(store, "", logger)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for _, unavailable := range []bool{false, true} {
+		primary.unavailable = unavailable
+
+		keys, err := s.ValidationKeys(ctx)
+		require.NoError(t, err)
+		require.Len(t, keys, 2)
+		require.Equal(t, "mrk-ec", keys[0].KeyID)
+		require.Equal(t, "mrk-rsa", keys[1].KeyID)
+
+		token, err := s.Sign(ctx, []byte(`{"sub":"foo"}`))
+		require.NoError(t, err)
+
+		jws, err := jose.ParseSigned(token, []jose.SignatureAlgorithm{jose.ES256})
+		require.NoError(t, err)
+		_, err = jws.Verify(keys[0])
+		require.NoError(t, err)
+	}
+
+	replica.unavailable = true
+	_, err = s.Sign(ctx, []byte(`{"sub":"foo"}`))
+	require.Error(t, err)
+}
@@ -0,0 +1,193 @@
+package signer
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azkeys"
+	"github.com/go-jose/go-jose/v4"
+)
+
+// AzureKeyVaultConfig holds the configuration for signing with Azure Key Vault
+// or Managed HSM keys.
+//
+// Credentials are resolved with the default Azure credential chain: environment
+// variables, workload identity, managed identity and the Azure CLI.
+//
+// All enabled versions of the key are published for verification and the most
+// recently created one is used for signing, so keys are rotated by creating a
+// new key version, manually or with a rotation policy, and retired by
+// disabling old versions.
+type AzureKeyVaultConfig struct {
+	// URL of the vault, for example https://myvault.vault.azure.net.
+	VaultURL string `json:"vaultURL"`
+
+	// Name of the key used to sign tokens.
+	KeyName string `json:"keyName"`
+
+	// How often keys are read from the vault. Defaults to 5 minutes.
+	RefreshInterval string `json:"refreshInterval"`
+}
+
+// Open resolves Azure credentials and returns a signer backed by Key Vault.
+func (c *AzureKeyVaultConfig) Open(ctx context.Context, logger *slog.Logger) (Signer, error) {
+	if c.VaultURL == "" {
+		return nil, errors.New("azurekeyvault: no vault URL specified")
+	}
+	if c.KeyName == "" {
+		return nil, errors.New("azurekeyvault: no key name specified")
+	}
+
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf("azurekeyvault: load credentials: %v", err)
+	}
+	client, err := azkeys.NewClient(c.VaultURL, cred, nil)
+	if err != nil {
+		return nil, fmt.Errorf("azurekeyvault: create client: %v", err)
+	}
+
+	store := &azureKeyVaultKeyStore{
+		client:  client,
+		keyName: c.KeyName,
+		now:     time.Now,
+	}
+	return newRemoteSigner(store, c.RefreshInterval, logger.With("signer", "azurekeyvault"))
+}
+
+// azureKeyVaultClient is the subset of the Key Vault API used by the signer.
+type azureKeyVaultClient interface {
+	NewListKeyPropertiesVersionsPager(name string, options *azkeys.ListKeyPropertiesVersionsOptions) *runtime.Pager[azkeys.ListKeyPropertiesVersionsResponse]
+	GetKey(ctx context.Context, name string, version string, options *azkeys.GetKeyOptions) (azkeys.GetKeyResponse, error)
+	Sign(ctx context.Context, name string, version string, parameters azkeys.SignParameters, options *azkeys.SignOptions) (azkeys.SignResponse, error)
+}
+
+type azureKeyVaultKeyStore struct {
+	client  azureKeyVaultClient
+	keyName string
+
+	now func() time.Time
+}
+
+// active reports whether a key version can currently be used.
+func (s *azureKeyVaultKeyStore) active(attrs *azkeys.KeyAttributes) bool {
+	if attrs == nil || attrs.Enabled == nil || !*attrs.Enabled {
+		return false
+	}
+	now := s.now()
+	if attrs.NotBefore != nil && now.Before(*attrs.NotBefore) {
+		return false
+	}
+	if attrs.Expires != nil && now.After(*attrs.Expires) {
+		return false
+	}
+	return true
+}
+
+func (s *azureKeyVaultKeyStore) keys(ctx context.Context) (string, []*jose.JSONWebKey, error) {
+	var (
+		keys        []*jose.JSONWebKey
+		signingID   string
+		signingTime time.Time
+	)
+
+	pager := s.client.NewListKeyPropertiesVersionsPager(s.keyName, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return "", nil, fmt.Errorf("azurekeyvault: list key versions: %v", err)
+		}
+		for _, props := range page.Value {
+			if props.KID == nil || !s.active(props.Attributes) {
+				continue
+			}
+			version := props.KID.Version()
+
+			resp, err := s.client.GetKey(ctx, s.keyName, version, nil)
+			if err != nil {
+				return "", nil, fmt.Errorf("azurekeyvault: get key version %q: %v", version, err)
+			}
+			pub, err := azurePublicKey(resp.Key)
+			if err != nil {
+				return "", nil, fmt.Errorf("azurekeyvault: key version %q: %v", version, err)
+			}
+			jwk, err := publicJWK(version, pub, "")
+			if err != nil {
+				return "", nil, fmt.Errorf("azurekeyvault: key version %q: %v", version, err)
+			}
+			keys = append(keys, jwk)
+
+			if created := props.Attributes.Created; created != nil && created.After(signingTime) {
+				signingID, signingTime = version, *created
+			}
+		}
+	}
+	if len(keys) == 0 {
+		return "", nil, fmt.Errorf("azurekeyvault: no enabled versions of key %q", s.keyName)
+	}
+	if signingID == "" {
+		// The newest version signs, which can't be told without creation times.
+		return "", nil, fmt.Errorf("azurekeyvault: no usable key version of key %q: no version has a creation time", s.keyName)
+	}
+	return signingID, keys, nil
+}
+
+// azurePublicKey converts the public part of a Key Vault key.
+func azurePublicKey(key *azkeys.JSONWebKey) (crypto.PublicKey, error) {
+	if key == nil || key.Kty == nil {
+		return nil, errors.New("no key material")
+	}
+	switch *key.Kty {
+	case azkeys.KeyTypeRSA, azkeys.KeyTypeRSAHSM:
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(key.N),
+			E: int(new(big.Int).SetBytes(key.E).Int64()),
+		}, nil
+	case azkeys.KeyTypeEC, azkeys.KeyTypeECHSM:
+		if key.Crv == nil {
+			return nil, errors.New("no curve specified")
+		}
+		var curve elliptic.Curve
+		switch *key.Crv {
+		case azkeys.CurveNameP256:
+			curve = elliptic.P256()
+		case azkeys.CurveNameP384:
+			curve = elliptic.P384()
+		case azkeys.CurveNameP521:
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", *key.Crv)
+		}
+		return &ecdsa.PublicKey{
+			Curve: curve,
+			X:     new(big.Int).SetBytes(key.X),
+			Y:     new(big.Int).SetBytes(key.Y),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", *key.Kty)
+	}
+}
+
+func (s *azureKeyVaultKeyStore) sign(ctx context.Context, keyID string, alg jose.SignatureAlgorithm, digest []byte) ([]byte, error) {
+	// Key Vault uses the JWS algorithm names and returns ECDSA signatures in the
+	// R || S form, so no conversion is needed.
+	sigAlg := azkeys.SignatureAlgorithm(alg)
+	resp, err := s.client.Sign(ctx, s.keyName, keyID, azkeys.SignParameters{
+		Algorithm: &sigAlg,
+		Value:     digest,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Result, nil
+}
@@ -0,0 +1,147 @@
+package signer
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azkeys"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeKeyVaultVersion struct {
+	version string
+	enabled bool
+	created time.Time
+	key     crypto.Signer
+}
+
+// fakeKeyVault serves the versions of a single key from memory.
+type fakeKeyVault struct {
+	versions []fakeKeyVaultVersion
+}
+
+func (f *fakeKeyVault) find(version string) (fakeKeyVaultVersion, error) {
+	for _, v := range f.versions {
+		if v.version == version {
+			return v, nil
+		}
+	}
+	return fakeKeyVaultVersion{}, errors.New("not found")
+}
+
+func (f *fakeKeyVault) NewListKeyPropertiesVersionsPager(name string, _ *azkeys.ListKeyPropertiesVersionsOptions) *runtime.Pager[azkeys.ListKeyPropertiesVersionsResponse] {
+	return runtime.NewPager(runtime.PagingHandler[azkeys.ListKeyPropertiesVersionsResponse]{
+		More: func(azkeys.ListKeyPropertiesVersionsResponse) bool { return false },
+		Fetcher: func(context.Context, *azkeys.ListKeyPropertiesVersionsResponse) (azkeys.ListKeyPropertiesVersionsResponse, error) {
+			var resp azkeys.ListKeyPropertiesVersionsResponse
+			for _, v := range f.versions {
+				id := azkeys.ID("https://dex.vault.azure.net/keys/" + name + "/" + v.version)
+				attrs := &azkeys.KeyAttributes{Enabled: &v.enabled}
+				if !v.created.IsZero() {
+					attrs.Created = &v.created
+				}
+				resp.Value = append(resp.Value, &azkeys.KeyProperties{KID: &id, Attributes: attrs})
+			}
+			return resp, nil
+		},
+	})
+}
+
+func (f *fakeKeyVault) GetKey(_ context.Context, _ string, version string, _ *azkeys.GetKeyOptions) (azkeys.GetKeyResponse, error) {
+	v, err := f.find(version)
+	if err != nil {
+		return azkeys.GetKeyResponse{}, err
+	}
+
+	var key azkeys.JSONWebKey
+	switch pub := v.key.Public().(type) {
+	case *rsa.PublicKey:
+		kty := azkeys.KeyTypeRSAHSM
+		key = azkeys.JSONWebKey{Kty: &kty, N: pub.N.Bytes(), E: big.NewInt(int64(pub.E)).Bytes()}
+	case *ecdsa.PublicKey:
+		kty, crv := azkeys.KeyTypeEC, azkeys.CurveNameP384
+		key = azkeys.JSONWebKey{Kty: &kty, Crv: &crv, X: pub.X.Bytes(), Y: pub.Y.Bytes()}
+	}
+	return azkeys.GetKeyResponse{KeyBundle: azkeys.KeyBundle{Key: &key}}, nil
+}
+
+func (f *fakeKeyVault) Sign(_ context.Context, _ string, version string, parameters azkeys.SignParameters, _ *azkeys.SignOptions) (azkeys.SignResponse, error) {
+	v, err := f.find(version)
+	if err != nil {
+		return azkeys.SignResponse{}, err
+	}
+	alg := jose.SignatureAlgorithm(*parameters.Algorithm)
+	sig, err := v.key.Sign(rand.Reader, parameters.Value, hashForAlgorithm[alg])
+	if err != nil {
+		return azkeys.SignResponse{}, err
+	}
+	if _, ok := v.key.(*ecdsa.PrivateKey); ok {
+		if sig, err = ecdsaSignatureFromASN1(sig, alg); err != nil {
+			return azkeys.SignResponse{}, err
+		}
+	}
+	return azkeys.SignResponse{KeyOperationResult: azkeys.KeyOperationResult{Result: sig}}, nil
+}
+
+func TestAzureKeyVaultSigner(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	now := time.Now()
+	vault := &fakeKeyVault{
+		versions: []fakeKeyVaultVersion{
+			{"v0", false, now.Add(-3 * time.Hour), oldKey},
+			{"v1", true, now.Add(-2 * time.Hour), rsaKey},
+			{"v2", true, now.Add(-time.Hour), ecKey},
+		},
+	}
+	store := &azureKeyVaultKeyStore{
+		client:  vault,
+		keyName: "dex",
+		now:     func() time.Time { return now },
+	}
+	s, err := newRemoteSigner(store, "", logger)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	keys, err := s.ValidationKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	require.Equal(t, "v2", keys[0].KeyID)
+	require.Equal(t, "v1", keys[1].KeyID)
+
+	token, err := s.Sign(ctx, []byte(`{"sub":"foo"}`))
+	require.NoError(t, err)
+
+	jws, err := jose.ParseSigned(token, []jose.SignatureAlgorithm{jose.ES384})
+	require.NoError(t, err)
+	_, err = jws.Verify(keys[0])
+	require.NoError(t, err)
+}
+
+func TestAzureKeyVaultWithoutCreationTimes(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	store := &azureKeyVaultKeyStore{
+		client:  &fakeKeyVault{versions: []fakeKeyVaultVersion{{"v1", true, time.Time{}, key}}},
+		keyName: "dex",
+		now:     time.Now,
+	}
+	_, _, err = store.keys(context.Background())
+	require.ErrorContains(t, err, "no usable key version")
+}
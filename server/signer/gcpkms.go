@@ -0,0 +1,233 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	gcpKMSEndpoint = "https://cloudkms.googleapis.com/v1/"
+	gcpKMSScope    = "https://www.googleapis.com/auth/cloudkms"
+)
+
+// GCPKMSConfig holds the configuration for signing with asymmetric Google Cloud KMS keys.
+//
+// All enabled versions of the key are published for verification and the most
+// recently created one is used for signing, so keys are rotated by creating a
+// new key version, and retired by disabling or destroying old versions.
+type GCPKMSConfig struct {
+	// Resource name of the key, in the form
+	// projects/*/locations/*/keyRings/*/cryptoKeys/*.
+	Key string `json:"key"`
+
+	// Path to a service account key file. If not specified, application default
+	// credentials are used.
+	ServiceAccountFilePath string `json:"serviceAccountFilePath"`
+
+	// Custom KMS endpoint, such as a Private Service Connect endpoint.
+	Endpoint string `json:"endpoint"`
+
+	// How often keys are read from KMS. Defaults to 5 minutes.
+	RefreshInterval string `json:"refreshInterval"`
+}
+
+// Open resolves Google Cloud credentials and returns a signer backed by KMS.
+func (c *GCPKMSConfig) Open(ctx context.Context, logger *slog.Logger) (Signer, error) {
+	if c.Key == "" {
+		return nil, errors.New("gcpkms: no key specified")
+	}
+
+	var creds *google.Credentials
+	if c.ServiceAccountFilePath != "" {
+		data, err := os.ReadFile(c.ServiceAccountFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("gcpkms: read service account file: %v", err)
+		}
+		if creds, err = google.CredentialsFromJSON(ctx, data, gcpKMSScope); err != nil {
+			return nil, fmt.Errorf("gcpkms: parse service account file: %v", err)
+		}
+	} else {
+		var err error
+		if creds, err = google.FindDefaultCredentials(ctx, gcpKMSScope); err != nil {
+			return nil, fmt.Errorf("gcpkms: find default credentials: %v", err)
+		}
+	}
+
+	endpoint := gcpKMSEndpoint
+	if c.Endpoint != "" {
+		endpoint = strings.TrimSuffix(c.Endpoint, "/") + "/v1/"
+	}
+
+	store := &gcpKMSKeyStore{
+		client:   oauth2.NewClient(ctx, creds.TokenSource),
+		endpoint: endpoint,
+		key:      c.Key,
+	}
+	return newRemoteSigner(store, c.RefreshInterval, logger.With("signer", "gcpkms"))
+}
+
+type gcpKMSKeyStore struct {
+	client   *http.Client
+	endpoint string
+	key      string
+}
+
+// do sends a request to the KMS REST API and decodes the JSON response into v.
+func (s *gcpKMSKeyStore) do(ctx context.Context, method, path string, body, v interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.endpoint+path, reqBody)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", resp.Status, data)
+	}
+	return json.Unmarshal(data, v)
+}
+
+// gcpKMSAlgorithms maps the KMS algorithms which can be used for JWS to the JWS algorithm.
+var gcpKMSAlgorithms = map[string]jose.SignatureAlgorithm{
+	"RSA_SIGN_PKCS1_2048_SHA256": jose.RS256,
+	"RSA_SIGN_PKCS1_3072_SHA256": jose.RS256,
+	"RSA_SIGN_PKCS1_4096_SHA256": jose.RS256,
+	"RSA_SIGN_PKCS1_4096_SHA512": jose.RS512,
+	"EC_SIGN_P256_SHA256":        jose.ES256,
+	"EC_SIGN_P384_SHA384":        jose.ES384,
+}
+
+type gcpKMSKeyVersion struct {
+	Name       string    `json:"name"`
+	Algorithm  string    `json:"algorithm"`
+	CreateTime time.Time `json:"createTime"`
+}
+
+func (s *gcpKMSKeyStore) keys(ctx context.Context) (string, []*jose.JSONWebKey, error) {
+	var (
+		versions  []gcpKMSKeyVersion
+		pageToken string
+	)
+	for {
+		query := url.Values{"filter": {"state=ENABLED"}}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+		var resp struct {
+			CryptoKeyVersions []gcpKMSKeyVersion `json:"cryptoKeyVersions"`
+			NextPageToken     string             `json:"nextPageToken"`
+		}
+		if err := s.do(ctx, http.MethodGet, s.key+"/cryptoKeyVersions?"+query.Encode(), nil, &resp); err != nil {
+			return "", nil, fmt.Errorf("gcpkms: list key versions: %v", err)
+		}
+		versions = append(versions, resp.CryptoKeyVersions...)
+		if pageToken = resp.NextPageToken; pageToken == "" {
+			break
+		}
+	}
+	if len(versions) == 0 {
+		return "", nil, fmt.Errorf("gcpkms: no enabled versions of key %q", s.key)
+	}
+
+	var (
+		keys    []*jose.JSONWebKey
+		signing gcpKMSKeyVersion
+	)
+	for _, version := range versions {
+		alg, ok := gcpKMSAlgorithms[version.Algorithm]
+		if !ok {
+			return "", nil, fmt.Errorf("gcpkms: key version %q: unsupported algorithm %s", version.Name, version.Algorithm)
+		}
+
+		var resp struct {
+			PEM string `json:"pem"`
+		}
+		if err := s.do(ctx, http.MethodGet, version.Name+"/publicKey", nil, &resp); err != nil {
+			return "", nil, fmt.Errorf("gcpkms: get public key %q: %v", version.Name, err)
+		}
+		block, _ := pem.Decode([]byte(resp.PEM))
+		if block == nil {
+			return "", nil, fmt.Errorf("gcpkms: key version %q: invalid public key PEM", version.Name)
+		}
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return "", nil, fmt.Errorf("gcpkms: key version %q: parse public key: %v", version.Name, err)
+		}
+		jwk, err := publicJWK(version.Name, pub, alg)
+		if err != nil {
+			return "", nil, fmt.Errorf("gcpkms: key version %q: %v", version.Name, err)
+		}
+		keys = append(keys, jwk)
+
+		if version.CreateTime.After(signing.CreateTime) {
+			signing = version
+		}
+	}
+	return signing.Name, keys, nil
+}
+
+var gcpKMSDigestNames = map[jose.SignatureAlgorithm]string{
+	jose.RS256: "sha256",
+	jose.RS512: "sha512",
+	jose.ES256: "sha256",
+	jose.ES384: "sha384",
+}
+
+func (s *gcpKMSKeyStore) sign(ctx context.Context, keyID string, alg jose.SignatureAlgorithm, digest []byte) ([]byte, error) {
+	digestName, ok := gcpKMSDigestNames[alg]
+	if !ok {
+		return nil, fmt.Errorf("unsupported signature algorithm %s", alg)
+	}
+
+	req := map[string]interface{}{
+		"digest": map[string][]byte{digestName: digest},
+	}
+	var resp struct {
+		Signature []byte `json:"signature"`
+	}
+	if err := s.do(ctx, http.MethodPost, keyID+":asymmetricSign", req, &resp); err != nil {
+		return nil, err
+	}
+
+	switch alg {
+	case jose.ES256, jose.ES384:
+		return ecdsaSignatureFromASN1(resp.Signature, alg)
+	}
+	return resp.Signature, nil
+}
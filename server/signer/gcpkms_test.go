@@ -0,0 +1,103 @@
+package signer
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGCPKMSSigner(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	const key = "projects/p/locations/global/keyRings/dex/cryptoKeys/signing"
+	versions := []struct {
+		name       string
+		algorithm  string
+		createTime string
+		key        crypto.Signer
+	}{
+		{key + "/cryptoKeyVersions/1", "RSA_SIGN_PKCS1_2048_SHA256", "2024-01-01T00:00:00Z", rsaKey},
+		{key + "/cryptoKeyVersions/2", "EC_SIGN_P256_SHA256", "2024-06-01T00:00:00.5Z", ecKey},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/"+key+"/cryptoKeyVersions", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "state=ENABLED", r.URL.Query().Get("filter"))
+		var resp struct {
+			CryptoKeyVersions []map[string]string `json:"cryptoKeyVersions"`
+		}
+		for _, v := range versions {
+			resp.CryptoKeyVersions = append(resp.CryptoKeyVersions, map[string]string{
+				"name":       v.name,
+				"algorithm":  v.algorithm,
+				"createTime": v.createTime,
+			})
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+	for _, v := range versions {
+		v := v
+		mux.HandleFunc("GET /v1/"+v.name+"/publicKey", func(w http.ResponseWriter, r *http.Request) {
+			der, err := x509.MarshalPKIXPublicKey(v.key.Public())
+			require.NoError(t, err)
+			json.NewEncoder(w).Encode(map[string]string{
+				"pem": string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+			})
+		})
+		mux.HandleFunc("POST /v1/"+v.name+":asymmetricSign", func(w http.ResponseWriter, r *http.Request) {
+			var req struct {
+				Digest struct {
+					SHA256 []byte `json:"sha256"`
+				} `json:"digest"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			sig, err := v.key.Sign(rand.Reader, req.Digest.SHA256, crypto.SHA256)
+			require.NoError(t, err)
+			json.NewEncoder(w).Encode(map[string][]byte{"signature": sig})
+		})
+	}
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	store := &gcpKMSKeyStore{
+		client:   srv.Client(),
+		endpoint: srv.URL + "/v1/",
+		key:      key,
+	}
+	s, err := newRemoteSigner(store, "", logger)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	keys, err := s.ValidationKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	require.True(t, strings.HasSuffix(keys[0].KeyID, "/cryptoKeyVersions/2"))
+
+	alg, err := s.Algorithm(ctx)
+	require.NoError(t, err)
+	require.Equal(t, jose.ES256, alg)
+
+	token, err := s.Sign(ctx, []byte(`{"sub":"foo"}`))
+	require.NoError(t, err)
+
+	jws, err := jose.ParseSigned(token, []jose.SignatureAlgorithm{jose.ES256})
+	require.NoError(t, err)
+	_, err = jws.Verify(keys[0])
+	require.NoError(t, err)
+}
@@ -0,0 +1,294 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/asn1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sync"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/miekg/pkcs11"
+)
+
+// PKCS11Config holds the configuration for signing with keys stored on a
+// PKCS#11 token, such as a hardware security module. The private keys never
+// leave the token.
+//
+// Keys are looked up by label. All key pairs sharing the configured label are
+// published for verification and the one with the highest CKA_ID is used for
+// signing, so keys are rotated by generating a new key pair with the same label
+// and a higher ID on the token, and retired by deleting them from it.
+type PKCS11Config struct {
+	// Path to the PKCS#11 module provided by the HSM vendor.
+	Library string `json:"library"`
+
+	// Label of the token holding the keys.
+	TokenLabel string `json:"tokenLabel"`
+
+	// PIN of the token user.
+	PIN string `json:"pin"`
+
+	// Label of the key pairs used to sign tokens.
+	KeyLabel string `json:"keyLabel"`
+
+	// How often keys are read from the token. Defaults to 5 minutes.
+	RefreshInterval string `json:"refreshInterval"`
+}
+
+// Open logs in to the token and returns a signer backed by it.
+func (c *PKCS11Config) Open(ctx context.Context, logger *slog.Logger) (Signer, error) {
+	if c.Library == "" {
+		return nil, errors.New("pkcs11: no library specified")
+	}
+	if c.TokenLabel == "" {
+		return nil, errors.New("pkcs11: no token label specified")
+	}
+	if c.KeyLabel == "" {
+		return nil, errors.New("pkcs11: no key label specified")
+	}
+
+	p := pkcs11.New(c.Library)
+	if p == nil {
+		return nil, fmt.Errorf("pkcs11: failed to load library %q", c.Library)
+	}
+	if err := p.Initialize(); err != nil {
+		return nil, fmt.Errorf("pkcs11: initialize: %v", err)
+	}
+
+	slot, err := findTokenSlot(p, c.TokenLabel)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := p.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION)
+	if err != nil {
+		return nil, fmt.Errorf("pkcs11: open session: %v", err)
+	}
+	if err := p.Login(session, pkcs11.CKU_USER, c.PIN); err != nil {
+		return nil, fmt.Errorf("pkcs11: login: %v", err)
+	}
+
+	store := &pkcs11KeyStore{
+		ctx:      p,
+		session:  session,
+		keyLabel: c.KeyLabel,
+	}
+	return newRemoteSigner(store, c.RefreshInterval, logger.With("signer", "pkcs11"))
+}
+
+func findTokenSlot(p *pkcs11.Ctx, label string) (uint, error) {
+	slots, err := p.GetSlotList(true)
+	if err != nil {
+		return 0, fmt.Errorf("pkcs11: list slots: %v", err)
+	}
+	for _, slot := range slots {
+		info, err := p.GetTokenInfo(slot)
+		if err != nil {
+			return 0, fmt.Errorf("pkcs11: get token info: %v", err)
+		}
+		if info.Label == label {
+			return slot, nil
+		}
+	}
+	return 0, fmt.Errorf("pkcs11: no token with label %q", label)
+}
+
+type pkcs11KeyStore struct {
+	ctx      *pkcs11.Ctx
+	keyLabel string
+
+	// PKCS#11 sessions must not be used concurrently.
+	mu      sync.Mutex
+	session pkcs11.SessionHandle
+}
+
+func (s *pkcs11KeyStore) findObjects(template []*pkcs11.Attribute) ([]pkcs11.ObjectHandle, error) {
+	if err := s.ctx.FindObjectsInit(s.session, template); err != nil {
+		return nil, err
+	}
+	defer s.ctx.FindObjectsFinal(s.session)
+
+	var handles []pkcs11.ObjectHandle
+	for {
+		found, _, err := s.ctx.FindObjects(s.session, 16)
+		if err != nil {
+			return nil, err
+		}
+		if len(found) == 0 {
+			return handles, nil
+		}
+		handles = append(handles, found...)
+	}
+}
+
+func (s *pkcs11KeyStore) keys(ctx context.Context) (string, []*jose.JSONWebKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	handles, err := s.findObjects([]*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PUBLIC_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, s.keyLabel),
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("pkcs11: find public keys: %v", err)
+	}
+	if len(handles) == 0 {
+		return "", nil, fmt.Errorf("pkcs11: no public keys with label %q", s.keyLabel)
+	}
+
+	var (
+		keys      []*jose.JSONWebKey
+		signingID []byte
+	)
+	for _, handle := range handles {
+		attrs, err := s.ctx.GetAttributeValue(s.session, handle, []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_ID, nil),
+			pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, nil),
+		})
+		if err != nil {
+			return "", nil, fmt.Errorf("pkcs11: get key attributes: %v", err)
+		}
+		id, keyType := attrs[0].Value, attrs[1].Value
+
+		pub, err := s.publicKey(handle, keyType)
+		if err != nil {
+			return "", nil, fmt.Errorf("pkcs11: key %x: %v", id, err)
+		}
+		jwk, err := publicJWK(hex.EncodeToString(id), pub, "")
+		if err != nil {
+			return "", nil, fmt.Errorf("pkcs11: key %x: %v", id, err)
+		}
+		keys = append(keys, jwk)
+
+		if signingID == nil || bytes.Compare(id, signingID) > 0 {
+			signingID = id
+		}
+	}
+	return hex.EncodeToString(signingID), keys, nil
+}
+
+func (s *pkcs11KeyStore) publicKey(handle pkcs11.ObjectHandle, keyType []byte) (crypto.PublicKey, error) {
+	switch {
+	case bytes.Equal(keyType, pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_RSA).Value):
+		attrs, err := s.ctx.GetAttributeValue(s.session, handle, []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_MODULUS, nil),
+			pkcs11.NewAttribute(pkcs11.CKA_PUBLIC_EXPONENT, nil),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(attrs[0].Value),
+			E: int(new(big.Int).SetBytes(attrs[1].Value).Int64()),
+		}, nil
+	case bytes.Equal(keyType, pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_EC).Value):
+		attrs, err := s.ctx.GetAttributeValue(s.session, handle, []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, nil),
+			pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return parsePKCS11ECPoint(attrs[0].Value, attrs[1].Value)
+	default:
+		return nil, errors.New("unsupported key type")
+	}
+}
+
+var (
+	oidNamedCurveP256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+	oidNamedCurveP384 = asn1.ObjectIdentifier{1, 3, 132, 0, 34}
+	oidNamedCurveP521 = asn1.ObjectIdentifier{1, 3, 132, 0, 35}
+)
+
+// parsePKCS11ECPoint decodes the CKA_EC_PARAMS and CKA_EC_POINT attributes of an EC public key.
+func parsePKCS11ECPoint(params, point []byte) (*ecdsa.PublicKey, error) {
+	var oid asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(params, &oid); err != nil {
+		return nil, fmt.Errorf("parse curve: %v", err)
+	}
+
+	var curve elliptic.Curve
+	switch {
+	case oid.Equal(oidNamedCurveP256):
+		curve = elliptic.P256()
+	case oid.Equal(oidNamedCurveP384):
+		curve = elliptic.P384()
+	case oid.Equal(oidNamedCurveP521):
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %s", oid)
+	}
+
+	// The point is a DER encoded OCTET STRING, though some tokens return it raw.
+	var raw []byte
+	if _, err := asn1.Unmarshal(point, &raw); err != nil {
+		raw = point
+	}
+
+	x, y := elliptic.Unmarshal(curve, raw)
+	if x == nil {
+		return nil, errors.New("invalid curve point")
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+// DigestInfo prefixes for PKCS #1 v1.5 signatures, see RFC 8017 section 9.2.
+var pkcs1DigestInfoPrefix = map[jose.SignatureAlgorithm][]byte{
+	jose.RS256: {0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20},
+	jose.RS384: {0x30, 0x41, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x02, 0x05, 0x00, 0x04, 0x30},
+	jose.RS512: {0x30, 0x51, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x03, 0x05, 0x00, 0x04, 0x40},
+}
+
+func (s *pkcs11KeyStore) sign(ctx context.Context, keyID string, alg jose.SignatureAlgorithm, digest []byte) ([]byte, error) {
+	id, err := hex.DecodeString(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key ID: %v", err)
+	}
+
+	var (
+		mechanism *pkcs11.Mechanism
+		data      []byte
+	)
+	switch alg {
+	case jose.RS256, jose.RS384, jose.RS512:
+		// CKM_RSA_PKCS expects the DER encoded DigestInfo rather than the bare digest.
+		mechanism = pkcs11.NewMechanism(pkcs11.CKM_RSA_PKCS, nil)
+		data = append(append([]byte{}, pkcs1DigestInfoPrefix[alg]...), digest...)
+	case jose.ES256, jose.ES384, jose.ES512:
+		// CKM_ECDSA already produces the R || S form used by JWS.
+		mechanism = pkcs11.NewMechanism(pkcs11.CKM_ECDSA, nil)
+		data = digest
+	default:
+		return nil, fmt.Errorf("unsupported signature algorithm %s", alg)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	handles, err := s.findObjects([]*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PRIVATE_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, s.keyLabel),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, id),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("find private key: %v", err)
+	}
+	if len(handles) != 1 {
+		return nil, fmt.Errorf("expected one private key, found %d", len(handles))
+	}
+
+	if err := s.ctx.SignInit(s.session, []*pkcs11.Mechanism{mechanism}, handles[0]); err != nil {
+		return nil, fmt.Errorf("sign init: %v", err)
+	}
+	return s.ctx.Sign(s.session, data)
+}
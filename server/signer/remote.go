@@ -0,0 +1,293 @@
+package signer
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+const defaultRefreshInterval = 5 * time.Minute
+
+// keyStore is an external system which holds the private keys and signs on dex's behalf.
+//
+// Rotation is driven by the key store: dex only reads the set of keys and uses
+// whichever one the store reports as current for signing.
+type keyStore interface {
+	// keys returns the public parts of all keys which can still be used to verify
+	// signatures, and the ID of the key that should be used for signing.
+	keys(ctx context.Context) (signingKeyID string, keys []*jose.JSONWebKey, err error)
+
+	// sign signs the digest with the given key and returns the signature in the
+	// format described by RFC 7518 for the algorithm.
+	sign(ctx context.Context, keyID string, alg jose.SignatureAlgorithm, digest []byte) ([]byte, error)
+}
+
+// keyStoreStarter is implemented by key stores which need background work,
+// such as renewing credentials, while the signer is running.
+type keyStoreStarter interface {
+	start(ctx context.Context)
+}
+
+// remoteSigner implements Signer on top of a keyStore, caching the public keys
+// and refreshing them periodically.
+type remoteSigner struct {
+	store           keyStore
+	refreshInterval time.Duration
+	now             func() time.Time
+	logger          *slog.Logger
+
+	mu          sync.RWMutex
+	signingKey  *jose.JSONWebKey
+	keys        []*jose.JSONWebKey
+	nextRefresh time.Time
+
+	// When the current signing key was first loaded.
+	signingKeySince time.Time
+}
+
+func newRemoteSigner(store keyStore, refreshInterval string, logger *slog.Logger) (*remoteSigner, error) {
+	interval := defaultRefreshInterval
+	if refreshInterval != "" {
+		var err error
+		if interval, err = time.ParseDuration(refreshInterval); err != nil {
+			return nil, fmt.Errorf("invalid refresh interval %q: %v", refreshInterval, err)
+		}
+	}
+	return &remoteSigner{
+		store:           store,
+		refreshInterval: interval,
+		now:             time.Now,
+		logger:          logger,
+	}, nil
+}
+
+func (r *remoteSigner) Start(ctx context.Context) {
+	if starter, ok := r.store.(keyStoreStarter); ok {
+		starter.start(ctx)
+	}
+
+	// Load the keys immediately so properly configured signers are ready to sign.
+	if err := r.refresh(ctx); err != nil {
+		r.logger.Error("failed to load signing keys", "err", err)
+	}
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(r.refreshInterval):
+				if err := r.refresh(ctx); err != nil {
+					r.logger.Error("failed to refresh signing keys", "err", err)
+				}
+			}
+		}
+	}()
+}
+
+func (r *remoteSigner) refresh(ctx context.Context) error {
+	signingKeyID, keys, err := r.store.keys(ctx)
+	if err != nil {
+		return err
+	}
+
+	var signingKey *jose.JSONWebKey
+	ordered := make([]*jose.JSONWebKey, 0, len(keys))
+	for _, key := range keys {
+		if key.KeyID == signingKeyID {
+			signingKey = key
+			continue
+		}
+		ordered = append(ordered, key)
+	}
+	if signingKey == nil {
+		return fmt.Errorf("signing key %q not found in key store", signingKeyID)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.signingKey == nil || r.signingKey.KeyID != signingKey.KeyID {
+		r.logger.Info("signing key loaded", "key_id", signingKey.KeyID, "algorithm", signingKey.Algorithm)
+		r.signingKeySince = r.now()
+	}
+	r.signingKey = signingKey
+	r.keys = append([]*jose.JSONWebKey{signingKey}, ordered...)
+	r.nextRefresh = r.now().Add(r.refreshInterval)
+	return nil
+}
+
+func (r *remoteSigner) current(ctx context.Context) (*jose.JSONWebKey, []*jose.JSONWebKey, error) {
+	r.mu.RLock()
+	signingKey, keys := r.signingKey, r.keys
+	r.mu.RUnlock()
+
+	if signingKey != nil {
+		return signingKey, keys, nil
+	}
+
+	// Keys failed to load on start, try again before giving up.
+	if err := r.refresh(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.signingKey, r.keys, nil
+}
+
+func (r *remoteSigner) ValidationKeys(ctx context.Context) ([]*jose.JSONWebKey, error) {
+	_, keys, err := r.current(ctx)
+	return keys, err
+}
+
+func (r *remoteSigner) Algorithm(ctx context.Context) (jose.SignatureAlgorithm, error) {
+	signingKey, _, err := r.current(ctx)
+	if err != nil {
+		return "", err
+	}
+	return jose.SignatureAlgorithm(signingKey.Algorithm), nil
+}
+
+// NextRotation reports when the key set will next be read from the key store.
+func (r *remoteSigner) NextRotation(ctx context.Context) (time.Time, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.nextRefresh, nil
+}
+
+// SigningKeyActiveSince reports when the current signing key was first loaded
+// from the key store. Key stores don't reliably expose when a key was created,
+// so this is a lower bound of the key's age.
+func (r *remoteSigner) SigningKeyActiveSince(ctx context.Context) (time.Time, error) {
+	if _, _, err := r.current(ctx); err != nil {
+		return time.Time{}, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.signingKeySince, nil
+}
+
+// Rotate reloads the keys from the key store. Keys are rotated by the key store
+// itself, so this makes a key rotated there take effect without waiting for the
+// next refresh.
+func (r *remoteSigner) Rotate(ctx context.Context) error {
+	return r.refresh(ctx)
+}
+
+func (r *remoteSigner) Sign(ctx context.Context, payload []byte) (string, error) {
+	return r.SignTyped(ctx, "", payload)
+}
+
+func (r *remoteSigner) SignTyped(ctx context.Context, typ string, payload []byte) (string, error) {
+	signingKey, _, err := r.current(ctx)
+	if err != nil {
+		return "", err
+	}
+	alg := jose.SignatureAlgorithm(signingKey.Algorithm)
+
+	header, err := json.Marshal(struct {
+		Algorithm string `json:"alg"`
+		KeyID     string `json:"kid"`
+		Type      string `json:"typ,omitempty"`
+	}{string(alg), signingKey.KeyID, typ})
+	if err != nil {
+		return "", fmt.Errorf("marshal JWS header: %v", err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	hash, ok := hashForAlgorithm[alg]
+	if !ok {
+		return "", fmt.Errorf("unsupported signature algorithm %s", alg)
+	}
+	h := hash.New()
+	h.Write([]byte(signingInput))
+
+	signature, err := r.store.sign(ctx, signingKey.KeyID, alg, h.Sum(nil))
+	if err != nil {
+		return "", fmt.Errorf("sign payload with key %q: %v", signingKey.KeyID, err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+var hashForAlgorithm = map[jose.SignatureAlgorithm]crypto.Hash{
+	jose.RS256: crypto.SHA256,
+	jose.RS384: crypto.SHA384,
+	jose.RS512: crypto.SHA512,
+	jose.ES256: crypto.SHA256,
+	jose.ES384: crypto.SHA384,
+	jose.ES512: crypto.SHA512,
+}
+
+// publicJWK builds the JSON Web Key published for a public key held in an
+// external key store. If alg is empty, it is derived from the key type.
+func publicJWK(keyID string, pub crypto.PublicKey, alg jose.SignatureAlgorithm) (*jose.JSONWebKey, error) {
+	if alg == "" {
+		switch key := pub.(type) {
+		case *rsa.PublicKey:
+			alg = jose.RS256
+		case *ecdsa.PublicKey:
+			switch key.Curve {
+			case elliptic.P256():
+				alg = jose.ES256
+			case elliptic.P384():
+				alg = jose.ES384
+			case elliptic.P521():
+				alg = jose.ES512
+			default:
+				return nil, errors.New("unsupported ecdsa curve")
+			}
+		default:
+			return nil, fmt.Errorf("unsupported public key type %T", pub)
+		}
+	}
+	return &jose.JSONWebKey{
+		Key:       pub,
+		KeyID:     keyID,
+		Algorithm: string(alg),
+		Use:       "sig",
+	}, nil
+}
+
+// ecdsaSignatureFromASN1 converts an ASN.1 DER encoded ECDSA signature, as
+// returned by most key management services, into the fixed size R || S form
+// required by JWS.
+func ecdsaSignatureFromASN1(der []byte, alg jose.SignatureAlgorithm) ([]byte, error) {
+	var sig struct {
+		R, S *big.Int
+	}
+	if _, err := asn1.Unmarshal(der, &sig); err != nil {
+		return nil, fmt.Errorf("parse ecdsa signature: %v", err)
+	}
+
+	var size int
+	switch alg {
+	case jose.ES256:
+		size = 32
+	case jose.ES384:
+		size = 48
+	case jose.ES512:
+		size = 66
+	default:
+		return nil, fmt.Errorf("unsupported ecdsa algorithm %s", alg)
+	}
+
+	out := make([]byte, 2*size)
+	sig.R.FillBytes(out[:size])
+	sig.S.FillBytes(out[size:])
+	return out, nil
+}
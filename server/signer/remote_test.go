@@ -0,0 +1,176 @@
+package signer
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/require"
+)
+
+var logger = slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+// memoryKeyStore holds private keys in memory and signs like an external key store would.
+type memoryKeyStore struct {
+	signingKeyID string
+	privateKeys  map[string]crypto.Signer
+}
+
+func (m *memoryKeyStore) keys(_ context.Context) (string, []*jose.JSONWebKey, error) {
+	var keys []*jose.JSONWebKey
+	for id, key := range m.privateKeys {
+		jwk, err := publicJWK(id, key.Public(), "")
+		if err != nil {
+			return "", nil, err
+		}
+		keys = append(keys, jwk)
+	}
+	return m.signingKeyID, keys, nil
+}
+
+func (m *memoryKeyStore) sign(_ context.Context, keyID string, alg jose.SignatureAlgorithm, digest []byte) ([]byte, error) {
+	key, ok := m.privateKeys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", keyID)
+	}
+	sig, err := key.Sign(rand.Reader, digest, hashForAlgorithm[alg])
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := key.(*ecdsa.PrivateKey); ok {
+		return ecdsaSignatureFromASN1(sig, alg)
+	}
+	return sig, nil
+}
+
+func TestRemoteSigner(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	p521Key, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		signingKeyID string
+		wantAlg      jose.SignatureAlgorithm
+	}{
+		{"rsa", jose.RS256},
+		{"p256", jose.ES256},
+		{"p384", jose.ES384},
+		{"p521", jose.ES512},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.signingKeyID, func(t *testing.T) {
+			store := &memoryKeyStore{
+				signingKeyID: tc.signingKeyID,
+				privateKeys: map[string]crypto.Signer{
+					"rsa":  rsaKey,
+					"p256": p256Key,
+					"p384": p384Key,
+					"p521": p521Key,
+				},
+			}
+			s, err := newRemoteSigner(store, "", logger)
+			require.NoError(t, err)
+
+			ctx := context.Background()
+			alg, err := s.Algorithm(ctx)
+			require.NoError(t, err)
+			require.Equal(t, tc.wantAlg, alg)
+
+			keys, err := s.ValidationKeys(ctx)
+			require.NoError(t, err)
+			require.Len(t, keys, 4)
+			require.Equal(t, tc.signingKeyID, keys[0].KeyID)
+
+			payload := []byte(`{"sub":"foo"}`)
+			token, err := s.Sign(ctx, payload)
+			require.NoError(t, err)
+
+			jws, err := jose.ParseSigned(token, []jose.SignatureAlgorithm{tc.wantAlg})
+			require.NoError(t, err)
+			require.Equal(t, tc.signingKeyID, jws.Signatures[0].Header.KeyID)
+
+			got, err := jws.Verify(keys[0])
+			require.NoError(t, err)
+			require.Equal(t, payload, got)
+			require.NotContains(t, jws.Signatures[0].Header.ExtraHeaders, jose.HeaderType)
+
+			token, err = s.SignTyped(ctx, "example+jwt", payload)
+			require.NoError(t, err)
+			jws, err = jose.ParseSigned(token, []jose.SignatureAlgorithm{tc.wantAlg})
+			require.NoError(t, err)
+			require.Equal(t, "example+jwt", jws.Signatures[0].Header.ExtraHeaders[jose.HeaderType])
+			_, err = jws.Verify(keys[0])
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRemoteSignerMissingSigningKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	store := &memoryKeyStore{
+		signingKeyID: "missing",
+		privateKeys:  map[string]crypto.Signer{"rsa": rsaKey},
+	}
+	s, err := newRemoteSigner(store, "1m", logger)
+	require.NoError(t, err)
+
+	_, err = s.Sign(context.Background(), []byte("{}"))
+	require.Error(t, err)
+}
+
+func TestRemoteSignerRotate(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	store := &memoryKeyStore{
+		signingKeyID: "old",
+		privateKeys:  map[string]crypto.Signer{"old": oldKey, "new": newKey},
+	}
+	s, err := newRemoteSigner(store, "1h", logger)
+	require.NoError(t, err)
+
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	since, err := s.SigningKeyActiveSince(ctx)
+	require.NoError(t, err)
+	require.Equal(t, now, since)
+
+	// Rotate the key in the key store, the signer only notices on refresh.
+	store.signingKeyID = "new"
+	now = now.Add(time.Minute)
+
+	alg, err := s.Algorithm(ctx)
+	require.NoError(t, err)
+	require.Equal(t, jose.RS256, alg)
+
+	require.NoError(t, s.Rotate(ctx))
+
+	alg, err = s.Algorithm(ctx)
+	require.NoError(t, err)
+	require.Equal(t, jose.ES256, alg)
+
+	since, err = s.SigningKeyActiveSince(ctx)
+	require.NoError(t, err)
+	require.Equal(t, now, since)
+}
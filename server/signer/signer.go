@@ -0,0 +1,44 @@
+// Package signer provides the interface used by the server to sign tokens and
+// implementations that keep the private keys in external key stores.
+package signer
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+// Signer signs the payloads of ID tokens and access tokens and exposes the
+// public keys which can be used to verify these signatures.
+type Signer interface {
+	// Sign signs the payload and returns the JWS in its compact serialization.
+	Sign(ctx context.Context, payload []byte) (string, error)
+
+	// SignTyped signs the payload like Sign, setting the "typ" header of the
+	// JWS, so that tokens signed for different purposes can't be used in
+	// place of each other.
+	SignTyped(ctx context.Context, typ string, payload []byte) (string, error)
+
+	// ValidationKeys returns the public keys which can be used to verify signatures.
+	// The key currently used for signing is always the first one.
+	ValidationKeys(ctx context.Context) ([]*jose.JSONWebKey, error)
+
+	// Algorithm returns the algorithm used to sign payloads.
+	Algorithm(ctx context.Context) (jose.SignatureAlgorithm, error)
+
+	// Start begins any background work, such as key rotation or refreshing keys
+	// from an external key store, until the context is canceled.
+	Start(ctx context.Context)
+}
+
+// Rotator is implemented by signers which can rotate their signing key on demand,
+// ahead of their regular schedule.
+type Rotator interface {
+	Rotate(ctx context.Context) error
+}
+
+// Config is a configuration that can open a signer.
+type Config interface {
+	Open(ctx context.Context, logger *slog.Logger) (Signer, error)
+}
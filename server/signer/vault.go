@@ -0,0 +1,308 @@
+package signer
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	vault "github.com/hashicorp/vault/api"
+)
+
+const defaultVaultTransitMount = "transit"
+
+// VaultTransitConfig holds the configuration for signing with keys of the
+// HashiCorp Vault transit secrets engine.
+//
+// All versions of the key which Vault still accepts for verification, that is
+// versions above the key's min_decryption_version, are published, and the
+// latest version is used for signing. Keys are rotated with the transit rotate
+// endpoint or an auto rotation period, and retired by raising min_decryption_version.
+type VaultTransitConfig struct {
+	// Addresses of the Vault servers, tried in order. Defaults to VAULT_ADDR.
+	Addresses []string `json:"addresses"`
+
+	// Token used to authenticate to Vault. Defaults to VAULT_TOKEN. Renewable
+	// tokens are renewed for as long as dex is running.
+	Token string `json:"token"`
+
+	// Vault Enterprise namespace of the transit engine.
+	Namespace string `json:"namespace"`
+
+	// Path the transit engine is mounted at. Defaults to "transit".
+	MountPath string `json:"mountPath"`
+
+	// Name of the transit key used to sign tokens.
+	KeyName string `json:"keyName"`
+
+	// Path to a PEM encoded CA certificate used to verify the Vault servers.
+	CACert string `json:"caCert"`
+
+	// How often keys are read from Vault. Defaults to 5 minutes.
+	RefreshInterval string `json:"refreshInterval"`
+}
+
+// Open creates Vault clients and returns a signer backed by the transit engine.
+func (c *VaultTransitConfig) Open(ctx context.Context, logger *slog.Logger) (Signer, error) {
+	if c.KeyName == "" {
+		return nil, errors.New("vault: no key name specified")
+	}
+
+	cfg := vault.DefaultConfig()
+	if cfg.Error != nil {
+		return nil, fmt.Errorf("vault: load config: %v", cfg.Error)
+	}
+	if c.CACert != "" {
+		if err := cfg.ConfigureTLS(&vault.TLSConfig{CACert: c.CACert}); err != nil {
+			return nil, fmt.Errorf("vault: configure TLS: %v", err)
+		}
+	}
+
+	client, err := vault.NewClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("vault: create client: %v", err)
+	}
+	if c.Token != "" {
+		client.SetToken(c.Token)
+	}
+	if c.Namespace != "" {
+		client.SetNamespace(c.Namespace)
+	}
+
+	addresses := c.Addresses
+	if len(addresses) == 0 {
+		addresses = []string{cfg.Address}
+	}
+
+	logger = logger.With("signer", "vault")
+	store := &vaultTransitKeyStore{
+		mountPath: strings.Trim(c.MountPath, "/"),
+		keyName:   c.KeyName,
+		logger:    logger,
+	}
+	if store.mountPath == "" {
+		store.mountPath = defaultVaultTransitMount
+	}
+	for _, addr := range addresses {
+		clone, err := client.Clone()
+		if err != nil {
+			return nil, fmt.Errorf("vault: create client: %v", err)
+		}
+		if err := clone.SetAddress(addr); err != nil {
+			return nil, fmt.Errorf("vault: invalid address %q: %v", addr, err)
+		}
+		// Clone does not copy the token or namespace headers.
+		clone.SetToken(client.Token())
+		if c.Namespace != "" {
+			clone.SetNamespace(c.Namespace)
+		}
+		store.clients = append(store.clients, clone)
+	}
+	return newRemoteSigner(store, c.RefreshInterval, logger)
+}
+
+type vaultTransitKeyStore struct {
+	// Clients for each of the Vault addresses, in order.
+	clients []*vault.Client
+
+	mountPath string
+	keyName   string
+
+	logger *slog.Logger
+}
+
+// withFailover calls f with the client of each address in order until one succeeds.
+func (s *vaultTransitKeyStore) withFailover(f func(client *vault.Client) error) error {
+	var errs []error
+	for _, client := range s.clients {
+		err := f(client)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func (s *vaultTransitKeyStore) start(ctx context.Context) {
+	go func() {
+		for {
+			next, err := s.renewToken(ctx)
+			if err != nil {
+				s.logger.Error("failed to renew vault token", "err", err)
+				next = time.Minute
+			}
+			if next == 0 {
+				// Token isn't renewable, nothing left to do.
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(next):
+			}
+		}
+	}()
+}
+
+// renewToken renews the Vault token and returns when it should be renewed
+// next, or zero if the token cannot be renewed.
+func (s *vaultTransitKeyStore) renewToken(ctx context.Context) (time.Duration, error) {
+	var secret *vault.Secret
+	err := s.withFailover(func(client *vault.Client) (err error) {
+		secret, err = client.Auth().Token().RenewSelfWithContext(ctx, 0)
+		return err
+	})
+	if err != nil {
+		// Tokens such as root tokens have no TTL and can't be renewed.
+		if strings.Contains(err.Error(), "lease is not renewable") {
+			return 0, nil
+		}
+		return 0, err
+	}
+	if secret == nil || secret.Auth == nil || !secret.Auth.Renewable || secret.Auth.LeaseDuration == 0 {
+		return 0, nil
+	}
+
+	ttl := time.Duration(secret.Auth.LeaseDuration) * time.Second
+	s.logger.Debug("vault token renewed", "ttl", ttl)
+	return ttl / 2, nil
+}
+
+func (s *vaultTransitKeyStore) read(ctx context.Context, path string) (*vault.Secret, error) {
+	var secret *vault.Secret
+	err := s.withFailover(func(client *vault.Client) (err error) {
+		secret, err = client.Logical().ReadWithContext(ctx, path)
+		if err == nil && secret == nil {
+			err = fmt.Errorf("%s not found", path)
+		}
+		return err
+	})
+	return secret, err
+}
+
+func (s *vaultTransitKeyStore) write(ctx context.Context, path string, data map[string]interface{}) (*vault.Secret, error) {
+	var secret *vault.Secret
+	err := s.withFailover(func(client *vault.Client) (err error) {
+		secret, err = client.Logical().WriteWithContext(ctx, path, data)
+		if err == nil && secret == nil {
+			err = fmt.Errorf("empty response from %s", path)
+		}
+		return err
+	})
+	return secret, err
+}
+
+// vaultKeyID returns the key ID published for a version of the transit key.
+func (s *vaultTransitKeyStore) vaultKeyID(version int) string {
+	return s.keyName + ":v" + strconv.Itoa(version)
+}
+
+func (s *vaultTransitKeyStore) keys(ctx context.Context) (string, []*jose.JSONWebKey, error) {
+	secret, err := s.read(ctx, s.mountPath+"/keys/"+s.keyName)
+	if err != nil {
+		return "", nil, fmt.Errorf("vault: read key %q: %v", s.keyName, err)
+	}
+
+	var key struct {
+		Type                 string `json:"type"`
+		LatestVersion        int    `json:"latest_version"`
+		MinDecryptionVersion int    `json:"min_decryption_version"`
+		Keys                 map[string]struct {
+			PublicKey string `json:"public_key"`
+		} `json:"keys"`
+	}
+	data, err := json.Marshal(secret.Data)
+	if err != nil {
+		return "", nil, fmt.Errorf("vault: read key %q: %v", s.keyName, err)
+	}
+	if err := json.Unmarshal(data, &key); err != nil {
+		return "", nil, fmt.Errorf("vault: parse key %q: %v", s.keyName, err)
+	}
+
+	var keys []*jose.JSONWebKey
+	for v, version := range key.Keys {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return "", nil, fmt.Errorf("vault: key %q: invalid version %q", s.keyName, v)
+		}
+		if n < key.MinDecryptionVersion {
+			continue
+		}
+
+		block, _ := pem.Decode([]byte(version.PublicKey))
+		if block == nil {
+			return "", nil, fmt.Errorf("vault: key %q version %d: no public key, key type %s cannot be used for signing", s.keyName, n, key.Type)
+		}
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return "", nil, fmt.Errorf("vault: key %q version %d: parse public key: %v", s.keyName, n, err)
+		}
+		jwk, err := publicJWK(s.vaultKeyID(n), pub, "")
+		if err != nil {
+			return "", nil, fmt.Errorf("vault: key %q version %d: %v", s.keyName, n, err)
+		}
+		keys = append(keys, jwk)
+	}
+	return s.vaultKeyID(key.LatestVersion), keys, nil
+}
+
+var vaultHashAlgorithms = map[jose.SignatureAlgorithm]string{
+	jose.RS256: "sha2-256",
+	jose.RS384: "sha2-384",
+	jose.RS512: "sha2-512",
+	jose.ES256: "sha2-256",
+	jose.ES384: "sha2-384",
+	jose.ES512: "sha2-512",
+}
+
+func (s *vaultTransitKeyStore) sign(ctx context.Context, keyID string, alg jose.SignatureAlgorithm, digest []byte) ([]byte, error) {
+	hashAlg, ok := vaultHashAlgorithms[alg]
+	if !ok {
+		return nil, fmt.Errorf("unsupported signature algorithm %s", alg)
+	}
+	i := strings.LastIndex(keyID, ":v")
+	if i < 0 {
+		return nil, fmt.Errorf("invalid key ID %q", keyID)
+	}
+	version, err := strconv.Atoi(keyID[i+len(":v"):])
+	if err != nil {
+		return nil, fmt.Errorf("invalid key ID %q", keyID)
+	}
+
+	secret, err := s.write(ctx, s.mountPath+"/sign/"+s.keyName+"/"+hashAlg, map[string]interface{}{
+		"input":               base64.StdEncoding.EncodeToString(digest),
+		"prehashed":           true,
+		"key_version":         version,
+		"signature_algorithm": "pkcs1v15",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Signatures have the form "vault:v<version>:<base64 signature>".
+	signature, _ := secret.Data["signature"].(string)
+	parts := strings.SplitN(signature, ":", 3)
+	if len(parts) != 3 || parts[0] != "vault" {
+		return nil, fmt.Errorf("unexpected signature format %q", signature)
+	}
+	sig, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("decode signature: %v", err)
+	}
+
+	switch alg {
+	case jose.ES256, jose.ES384, jose.ES512:
+		return ecdsaSignatureFromASN1(sig, alg)
+	}
+	return sig, nil
+}
@@ -0,0 +1,133 @@
+package signer
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-jose/go-jose/v4"
+	vault "github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeVault serves a transit engine with a single key.
+func newFakeVault(t *testing.T, versions map[int]crypto.Signer, minDecryptionVersion int) *httptest.Server {
+	latest := 0
+	for v := range versions {
+		latest = max(latest, v)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/transit/keys/dex", func(w http.ResponseWriter, r *http.Request) {
+		keys := map[string]interface{}{}
+		for v, key := range versions {
+			der, err := x509.MarshalPKIXPublicKey(key.Public())
+			require.NoError(t, err)
+			keys[strconv.Itoa(v)] = map[string]string{
+				"public_key": string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"type":                   "ecdsa-p256",
+				"latest_version":         latest,
+				"min_decryption_version": minDecryptionVersion,
+				"keys":                   keys,
+			},
+		})
+	})
+	mux.HandleFunc("/v1/transit/sign/dex/sha2-256", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Input      string `json:"input"`
+			Prehashed  bool   `json:"prehashed"`
+			KeyVersion int    `json:"key_version"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.True(t, req.Prehashed)
+
+		digest, err := base64.StdEncoding.DecodeString(req.Input)
+		require.NoError(t, err)
+		sig, err := versions[req.KeyVersion].Sign(rand.Reader, digest, crypto.SHA256)
+		require.NoError(t, err)
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]string{
+				"signature": "vault:v" + strconv.Itoa(req.KeyVersion) + ":" + base64.StdEncoding.EncodeToString(sig),
+			},
+		})
+	})
+	mux.HandleFunc("/v1/auth/token/renew-self", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"auth": map[string]interface{}{
+				"renewable":      true,
+				"lease_duration": 3600,
+			},
+		})
+	})
+	return httptest.NewServer(mux)
+}
+
+func newVaultClient(t *testing.T, addr string) *vault.Client {
+	cfg := vault.DefaultConfig()
+	cfg.Address = addr
+	cfg.MaxRetries = 0
+	client, err := vault.NewClient(cfg)
+	require.NoError(t, err)
+	client.SetToken("token")
+	return client
+}
+
+func TestVaultTransitSigner(t *testing.T) {
+	retiredKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	srv := newFakeVault(t, map[int]crypto.Signer{1: retiredKey, 2: rsaKey, 3: ecKey}, 2)
+	defer srv.Close()
+
+	// The first address is unreachable, so requests fail over to the second one.
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	store := &vaultTransitKeyStore{
+		clients:   []*vault.Client{newVaultClient(t, down.URL), newVaultClient(t, srv.URL)},
+		mountPath: defaultVaultTransitMount,
+		keyName:   "dex",
+		logger:    logger,
+	}
+	s, err := newRemoteSigner(store, "", logger)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	keys, err := s.ValidationKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	require.Equal(t, "dex:v3", keys[0].KeyID)
+	require.Equal(t, "dex:v2", keys[1].KeyID)
+
+	token, err := s.Sign(ctx, []byte(`{"sub":"foo"}`))
+	require.NoError(t, err)
+
+	jws, err := jose.ParseSigned(token, []jose.SignatureAlgorithm{jose.ES256})
+	require.NoError(t, err)
+	_, err = jws.Verify(keys[0])
+	require.NoError(t, err)
+
+	next, err := store.renewToken(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "30m0s", next.String())
+}
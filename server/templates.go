@@ -5,13 +5,17 @@ import (
 	"html/template"
 	"io"
 	"io/fs"
+	"maps"
 	"net/http"
 	"net/url"
 	"path"
+	"regexp"
 	"sort"
 	"strings"
 
 	"github.com/Masterminds/sprig/v3"
+
+	"github.com/dexidp/dex/storage"
 )
 
 const (
@@ -22,6 +26,15 @@ const (
 	tmplError         = "error.html"
 	tmplDevice        = "device.html"
 	tmplDeviceSuccess = "device_success.html"
+
+	// The sessions, password reset, registration, e-mail verification and
+	// home realm discovery pages are optional, so themes which predate them
+	// keep working.
+	tmplSessions      = "sessions.html"
+	tmplPasswordReset = "password_reset.html"
+	tmplRegister      = "register.html"
+	tmplVerifyEmail   = "verify_email.html"
+	tmplHomeRealm     = "home_realm.html"
 )
 
 var requiredTmpls = []string{
@@ -42,6 +55,32 @@ type templates struct {
 	errorTmpl         *template.Template
 	deviceTmpl        *template.Template
 	deviceSuccessTmpl *template.Template
+	sessionsTmpl      *template.Template
+	passwordResetTmpl *template.Template
+	registerTmpl      *template.Template
+	verifyEmailTmpl   *template.Template
+	homeRealmTmpl     *template.Template
+
+	// Descriptions of the scopes shown on the approval page.
+	scopeDescriptions map[string]string
+
+	// Customization of the device page.
+	devicePage DevicePage
+
+	// Translations of the messages shown by the templates.
+	catalog *catalog
+
+	// Templates of the clients with their own branding.
+	clients map[string]*templates
+}
+
+// forClient returns the templates rendering the pages of a client, falling
+// back to the global ones.
+func (t *templates) forClient(clientID string) *templates {
+	if ct, ok := t.clients[clientID]; ok {
+		return ct
+	}
+	return t
 }
 
 type webConfig struct {
@@ -51,9 +90,61 @@ type webConfig struct {
 	theme     string
 	issuerURL string
 	extra     map[string]string
+
+	primaryColor    string
+	backgroundColor string
+	footer          string
+	supportText     string
+	devicePage      DevicePage
+	clients         map[string]Branding
+}
+
+// cssColor matches the colors which can be set by the branding: hex colors
+// and color names.
+var cssColor = regexp.MustCompile(`^(#[0-9a-fA-F]{3,8}|[a-zA-Z]+)$`)
+
+// withBranding returns the config of a client, overriding the values set by
+// its branding.
+func (c webConfig) withBranding(b Branding) (webConfig, error) {
+	if err := checkColors(b.PrimaryColor, b.BackgroundColor); err != nil {
+		return c, err
+	}
+	if b.LogoURL != "" {
+		c.logoURL = b.LogoURL
+	}
+	if b.Issuer != "" {
+		c.issuer = b.Issuer
+	}
+	if b.PrimaryColor != "" {
+		c.primaryColor = b.PrimaryColor
+	}
+	if b.BackgroundColor != "" {
+		c.backgroundColor = b.BackgroundColor
+	}
+	if b.Footer != "" {
+		c.footer = b.Footer
+	}
+	if len(b.Extra) > 0 {
+		c.extra = maps.Clone(c.extra)
+		if c.extra == nil {
+			c.extra = make(map[string]string)
+		}
+		maps.Copy(c.extra, b.Extra)
+	}
+	c.clients = nil
+	return c, nil
+}
+
+func checkColors(colors ...string) error {
+	for _, color := range colors {
+		if color != "" && !cssColor.MatchString(color) {
+			return fmt.Errorf("invalid color %q", color)
+		}
+	}
+	return nil
 }
 
-func getFuncMap(c webConfig) (template.FuncMap, error) {
+func getFuncMap(c webConfig, cat *catalog) (template.FuncMap, error) {
 	funcs := sprig.FuncMap()
 
 	issuerURL, err := url.Parse(c.issuerURL)
@@ -65,6 +156,12 @@ func getFuncMap(c webConfig) (template.FuncMap, error) {
 		"extra":  func(k string) string { return c.extra[k] },
 		"issuer": func() string { return c.issuer },
 		"logo":   func() string { return c.logoURL },
+		"t":      cat.translate,
+
+		"primaryColor":    func() string { return c.primaryColor },
+		"backgroundColor": func() string { return c.backgroundColor },
+		"footer":          func() string { return c.footer },
+		"supportText":     func() string { return c.supportText },
 		"url": func(reqPath, assetPath string) string {
 			return relativeURL(issuerURL.Path, reqPath, assetPath)
 		},
@@ -102,6 +199,9 @@ func loadWebConfig(c webConfig) (http.Handler, http.Handler, http.HandlerFunc, *
 	if c.logoURL == "" {
 		c.logoURL = "theme/logo.png"
 	}
+	if err := checkColors(c.primaryColor, c.backgroundColor); err != nil {
+		return nil, nil, nil, nil, err
+	}
 
 	staticFiles, err := fs.Sub(c.webFS, "static")
 	if err != nil {
@@ -143,7 +243,12 @@ func loadTemplates(c webConfig, templatesDir string) (*templates, error) {
 		return nil, fmt.Errorf("no files in template dir %q", templatesDir)
 	}
 
-	funcs, err := getFuncMap(c)
+	cat, err := loadCatalog(c.webFS, "translations")
+	if err != nil {
+		return nil, fmt.Errorf("load translations: %v", err)
+	}
+
+	funcs, err := getFuncMap(c, cat)
 	if err != nil {
 		return nil, err
 	}
@@ -161,7 +266,7 @@ func loadTemplates(c webConfig, templatesDir string) (*templates, error) {
 	if len(missingTmpls) > 0 {
 		return nil, fmt.Errorf("missing template(s): %s", missingTmpls)
 	}
-	return &templates{
+	t := &templates{
 		loginTmpl:         tmpls.Lookup(tmplLogin),
 		approvalTmpl:      tmpls.Lookup(tmplApproval),
 		passwordTmpl:      tmpls.Lookup(tmplPassword),
@@ -169,7 +274,35 @@ func loadTemplates(c webConfig, templatesDir string) (*templates, error) {
 		errorTmpl:         tmpls.Lookup(tmplError),
 		deviceTmpl:        tmpls.Lookup(tmplDevice),
 		deviceSuccessTmpl: tmpls.Lookup(tmplDeviceSuccess),
-	}, nil
+		sessionsTmpl:      tmpls.Lookup(tmplSessions),
+		passwordResetTmpl: tmpls.Lookup(tmplPasswordReset),
+		registerTmpl:      tmpls.Lookup(tmplRegister),
+		verifyEmailTmpl:   tmpls.Lookup(tmplVerifyEmail),
+		homeRealmTmpl:     tmpls.Lookup(tmplHomeRealm),
+		scopeDescriptions: maps.Clone(scopeDescriptions),
+		devicePage:        c.devicePage,
+		catalog:           cat,
+	}
+
+	// Templates are parsed again for every branded client, since functions
+	// can't be replaced once a template was executed.
+	for clientID, b := range c.clients {
+		cc, err := c.withBranding(b)
+		if err != nil {
+			return nil, fmt.Errorf("branding of client %q: %v", clientID, err)
+		}
+		ct, err := loadTemplates(cc, templatesDir)
+		if err != nil {
+			return nil, fmt.Errorf("branding of client %q: %v", clientID, err)
+		}
+		// Share the scope descriptions, custom scopes are added after loading.
+		ct.scopeDescriptions = t.scopeDescriptions
+		if t.clients == nil {
+			t.clients = make(map[string]*templates)
+		}
+		t.clients[clientID] = ct
+	}
+	return t, nil
 }
 
 // relativeURL returns the URL of the asset relative to the URL of the request path.
@@ -245,32 +378,76 @@ var scopeDescriptions = map[string]string{
 	"email":          "View your email address",
 	// 'groups' is not a standard OIDC scope, and Dex only returns groups only if the upstream provider does too.
 	// This warning is added for convenience to show that the user may expose some sensitive data to the application.
-	"groups": "View your groups",
+	"groups":       "View your groups",
+	"dex:sessions": "Manage your sessions",
 }
 
 type connectorInfo struct {
-	ID   string
-	Name string
-	URL  template.URL
-	Type string
+	ID          string
+	Name        string
+	URL         template.URL
+	Type        string
+	Order       int
+	Group       string
+	IconURL     template.URL
+	ButtonColor string
 }
 
-type byName []connectorInfo
+type byOrder []connectorInfo
 
-func (n byName) Len() int           { return len(n) }
-func (n byName) Less(i, j int) bool { return n[i].Name < n[j].Name }
-func (n byName) Swap(i, j int)      { n[i], n[j] = n[j], n[i] }
+func (n byOrder) Len() int { return len(n) }
+func (n byOrder) Less(i, j int) bool {
+	if n[i].Order != n[j].Order {
+		return n[i].Order < n[j].Order
+	}
+	return n[i].Name < n[j].Name
+}
+func (n byOrder) Swap(i, j int) { n[i], n[j] = n[j], n[i] }
+
+// connectorGroup is a section of the login page.
+type connectorGroup struct {
+	Name       string
+	Connectors []connectorInfo
+}
+
+// groupConnectors splits sorted connectors into sections. Connectors without
+// a group come first, the groups follow in the order of their first
+// connector.
+func groupConnectors(connectors []connectorInfo) []connectorGroup {
+	groups := []connectorGroup{{}}
+	index := map[string]int{"": 0}
+	for _, c := range connectors {
+		i, ok := index[c.Group]
+		if !ok {
+			i = len(groups)
+			index[c.Group] = i
+			groups = append(groups, connectorGroup{Name: c.Group})
+		}
+		groups[i].Connectors = append(groups[i].Connectors, c)
+	}
+	if len(groups[0].Connectors) == 0 {
+		groups = groups[1:]
+	}
+	return groups
+}
 
-func (t *templates) device(r *http.Request, w http.ResponseWriter, postURL string, userCode string, lastWasInvalid bool) error {
+func (t *templates) device(r *http.Request, w http.ResponseWriter, postURL string, userCode string, placeholder string, qrCodeURL string, lastWasInvalid bool, csrfToken string) error {
 	if lastWasInvalid {
 		w.WriteHeader(http.StatusBadRequest)
 	}
 	data := struct {
-		PostURL  string
-		UserCode string
-		Invalid  bool
-		ReqPath  string
-	}{postURL, userCode, lastWasInvalid, r.URL.Path}
+		PostURL      string
+		UserCode     string
+		Placeholder  string
+		QRCodeURL    string
+		Invalid      bool
+		Instructions string
+		HelpURL      string
+		HelpText     string
+		CSRFToken    string
+		ReqPath      string
+		Lang         string
+	}{postURL, userCode, placeholder, qrCodeURL, lastWasInvalid, t.devicePage.Instructions, t.devicePage.HelpURL, t.devicePage.HelpText, csrfToken, r.URL.Path, t.catalog.requestLanguage(r)}
 	return renderTemplate(w, t.deviceTmpl, data)
 }
 
@@ -278,50 +455,87 @@ func (t *templates) deviceSuccess(r *http.Request, w http.ResponseWriter, client
 	data := struct {
 		ClientName string
 		ReqPath    string
-	}{clientName, r.URL.Path}
+		Lang       string
+	}{clientName, r.URL.Path, t.catalog.requestLanguage(r)}
 	return renderTemplate(w, t.deviceSuccessTmpl, data)
 }
 
-func (t *templates) login(r *http.Request, w http.ResponseWriter, connectors []connectorInfo) error {
-	sort.Sort(byName(connectors))
+func (t *templates) login(r *http.Request, w http.ResponseWriter, connectors []connectorInfo, cancel *cancelLink) error {
+	sort.Sort(byOrder(connectors))
 	data := struct {
 		Connectors []connectorInfo
+		Groups     []connectorGroup
+		Cancel     *cancelLink
 		ReqPath    string
-	}{connectors, r.URL.Path}
+		Lang       string
+	}{connectors, groupConnectors(connectors), cancel, r.URL.Path, t.catalog.requestLanguage(r)}
 	return renderTemplate(w, t.loginTmpl, data)
 }
 
-func (t *templates) password(r *http.Request, w http.ResponseWriter, postURL, lastUsername, usernamePrompt string, lastWasInvalid bool, backLink string) error {
-	if lastWasInvalid {
+// homeRealm renders the form asking for the e-mail address of the user to
+// find their connector.
+func (t *templates) homeRealm(r *http.Request, w http.ResponseWriter, postURL, connectorsURL string, cancel *cancelLink) error {
+	data := struct {
+		PostURL       string
+		ConnectorsURL string
+		Cancel        *cancelLink
+		ReqPath       string
+		Lang          string
+	}{postURL, connectorsURL, cancel, r.URL.Path, t.catalog.requestLanguage(r)}
+	return renderTemplate(w, t.homeRealmTmpl, data)
+}
+
+func (t *templates) password(r *http.Request, w http.ResponseWriter, postURL, lastUsername, usernamePrompt string, lastWasInvalid bool, backLink, resetURL, registerURL string, challenge *captchaChallenge, cancel *cancelLink, csrfToken string) error {
+	switch {
+	case lastWasInvalid:
 		w.WriteHeader(http.StatusUnauthorized)
+	case challenge != nil && challenge.Failed:
+		w.WriteHeader(http.StatusBadRequest)
 	}
 	data := struct {
 		PostURL        string
 		BackLink       string
+		ResetURL       string
+		RegisterURL    string
 		Username       string
 		UsernamePrompt string
 		Invalid        bool
+		Captcha        *captchaChallenge
+		Cancel         *cancelLink
+		CSRFToken      string
 		ReqPath        string
-	}{postURL, backLink, lastUsername, usernamePrompt, lastWasInvalid, r.URL.Path}
+		Lang           string
+	}{postURL, backLink, resetURL, registerURL, lastUsername, usernamePrompt, lastWasInvalid, challenge, cancel, csrfToken, r.URL.Path, t.catalog.requestLanguage(r)}
 	return renderTemplate(w, t.passwordTmpl, data)
 }
 
-func (t *templates) approval(r *http.Request, w http.ResponseWriter, authReqID, username, clientName string, scopes []string) error {
+func (t *templates) approval(r *http.Request, w http.ResponseWriter, authReqID, username string, client storage.Client, scopes, claims []string, csrfToken string) error {
+	lang := t.catalog.requestLanguage(r)
 	accesses := []string{}
 	for _, scope := range scopes {
-		access, ok := scopeDescriptions[scope]
+		access, ok := t.scopeDescriptions[scope]
 		if ok {
-			accesses = append(accesses, access)
+			accesses = append(accesses, t.catalog.translate(lang, access))
 		}
 	}
 	sort.Strings(accesses)
+	for _, claim := range claims {
+		accesses = append(accesses, t.catalog.translate(lang, claim))
+	}
 	data := struct {
 		User      string
 		Client    string
 		AuthReqID string
 		Scopes    []string
+		CSRFToken string
 		ReqPath   string
-	}{username, clientName, authReqID, accesses, r.URL.Path}
+		Lang      string
+
+		// Metadata of the client.
+		ClientLogoURL     string
+		PolicyURL         string
+		TermsOfServiceURL string
+	}{username, client.Name, authReqID, accesses, csrfToken, r.URL.Path, lang, client.LogoURL, client.PolicyURL, client.TermsOfServiceURL}
 	return renderTemplate(w, t.approvalTmpl, data)
 }
 
@@ -329,17 +543,84 @@ func (t *templates) oob(r *http.Request, w http.ResponseWriter, code string) err
 	data := struct {
 		Code    string
 		ReqPath string
-	}{code, r.URL.Path}
+		Lang    string
+	}{code, r.URL.Path, t.catalog.requestLanguage(r)}
 	return renderTemplate(w, t.oobTmpl, data)
 }
 
-func (t *templates) err(r *http.Request, w http.ResponseWriter, errCode int, errMsg string) error {
-	w.WriteHeader(errCode)
+func (t *templates) sessions(r *http.Request, w http.ResponseWriter, postURL, csrfToken string, sessions []sessionInfo) error {
 	data := struct {
-		ErrType string
-		ErrMsg  string
+		PostURL   string
+		CSRFToken string
+		Sessions  []sessionInfo
+		ReqPath   string
+		Lang      string
+	}{postURL, csrfToken, sessions, r.URL.Path, t.catalog.requestLanguage(r)}
+	return renderTemplate(w, t.sessionsTmpl, data)
+}
+
+// passwordReset renders the form requesting a reset link, the form choosing
+// a new password if token is set, or the result of either.
+func (t *templates) passwordReset(r *http.Request, w http.ResponseWriter, postURL, backLink, token string, sent, done bool, errMsg string) error {
+	data := struct {
+		PostURL  string
+		BackLink string
+		Token    string
+		Sent     bool
+		Done     bool
+		Error    string
+		ReqPath  string
+		Lang     string
+	}{postURL, backLink, token, sent, done, errMsg, r.URL.Path, t.catalog.requestLanguage(r)}
+	return renderTemplate(w, t.passwordResetTmpl, data)
+}
+
+// register renders the form requesting a verification link, the form
+// completing the registration of email if token is set, or the result of
+// either.
+func (t *templates) register(r *http.Request, w http.ResponseWriter, postURL, backLink, token, email string, sent, done bool, errMsg string, challenge *captchaChallenge) error {
+	data := struct {
+		PostURL  string
+		BackLink string
+		Token    string
+		Email    string
+		Sent     bool
+		Done     bool
+		Error    string
+		Captcha  *captchaChallenge
+		ReqPath  string
+		Lang     string
+	}{postURL, backLink, token, email, sent, done, errMsg, challenge, r.URL.Path, t.catalog.requestLanguage(r)}
+	return renderTemplate(w, t.registerTmpl, data)
+}
+
+// verifyEmail renders the page confirming that email is verified, or the
+// result if done.
+func (t *templates) verifyEmail(r *http.Request, w http.ResponseWriter, postURL, token, email string, done bool) error {
+	data := struct {
+		PostURL string
+		Token   string
+		Email   string
+		Done    bool
 		ReqPath string
-	}{http.StatusText(errCode), errMsg, r.URL.Path}
+		Lang    string
+	}{postURL, token, email, done, r.URL.Path, t.catalog.requestLanguage(r)}
+	return renderTemplate(w, t.verifyEmailTmpl, data)
+}
+
+func (t *templates) err(r *http.Request, w http.ResponseWriter, errCode int, errMsg, code, requestID string, cancel *cancelLink) error {
+	w.WriteHeader(errCode)
+	lang := t.catalog.requestLanguage(r)
+	data := struct {
+		ErrType   string
+		ErrMsg    string
+		Status    int
+		Code      string
+		RequestID string
+		Cancel    *cancelLink
+		ReqPath   string
+		Lang      string
+	}{t.catalog.translate(lang, http.StatusText(errCode)), t.catalog.translate(lang, errMsg), errCode, code, requestID, cancel, r.URL.Path, lang}
 	if err := t.errorTmpl.Execute(w, data); err != nil {
 		return fmt.Errorf("rendering template %s failed: %s", t.errorTmpl.Name(), err)
 	}
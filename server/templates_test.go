@@ -1,6 +1,16 @@
 package server
 
-import "testing"
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dexidp/dex/storage"
+)
 
 func TestRelativeURL(t *testing.T) {
 	tests := []struct {
@@ -49,3 +59,159 @@ func TestRelativeURL(t *testing.T) {
 		})
 	}
 }
+
+func TestClientBranding(t *testing.T) {
+	c := webConfig{
+		webFS:        os.DirFS("../web"),
+		issuer:       "Example",
+		issuerURL:    "https://example.com/dex",
+		primaryColor: "#333333",
+		footer:       "Example Inc.",
+		clients: map[string]Branding{
+			"acme": {
+				Issuer:       "Acme",
+				LogoURL:      "https://acme.example.com/logo.png",
+				PrimaryColor: "#ff6600",
+			},
+		},
+	}
+	_, _, _, tmpls, err := loadWebConfig(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	render := func(clientID string) string {
+		r := httptest.NewRequest(http.MethodGet, "/dex/auth", nil)
+		w := httptest.NewRecorder()
+		if err := tmpls.forClient(clientID).login(r, w, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		return w.Body.String()
+	}
+
+	page := render("other")
+	for _, want := range []string{"<title>Example</title>", "#333333", "Example Inc.", "theme/logo.png"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("expected the global page to contain %q", want)
+		}
+	}
+
+	page = render("acme")
+	for _, want := range []string{"<title>Acme</title>", "#ff6600", "Example Inc.", "https://acme.example.com/logo.png"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("expected the page of the client to contain %q", want)
+		}
+	}
+
+	c.clients["acme"] = Branding{PrimaryColor: "red; } body { display: none"}
+	if _, _, _, _, err := loadWebConfig(c); err == nil {
+		t.Error("expected an invalid color to be rejected")
+	}
+}
+
+func TestTranslations(t *testing.T) {
+	c := webConfig{
+		webFS:     os.DirFS("../web"),
+		issuer:    "Example",
+		issuerURL: "https://example.com/dex",
+	}
+	_, _, _, tmpls, err := loadWebConfig(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		target         string
+		acceptLanguage string
+		want           string
+	}{
+		{"default", "/dex/auth", "", "Log in to Example"},
+		{"browser", "/dex/auth", "de-DE,de;q=0.9,en;q=0.8", "Bei Example anmelden"},
+		{"unsupported", "/dex/auth", "ja", "Log in to Example"},
+		{"ui-locales", "/dex/auth?ui_locales=ja+fr-CA", "de", "Se connecter à Example"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			r.Header.Set("Accept-Language", tc.acceptLanguage)
+			w := httptest.NewRecorder()
+			if err := tmpls.login(r, w, nil, nil); err != nil {
+				t.Fatal(err)
+			}
+			if page := w.Body.String(); !strings.Contains(page, tc.want) {
+				t.Errorf("expected the page to contain %q, got %s", tc.want, page)
+			}
+		})
+	}
+}
+
+func TestLoadCatalog(t *testing.T) {
+	webFS := fstest.MapFS{
+		"translations/en.json": {Data: []byte(`{"Login": "Sign in"}`)},
+		"translations/nl.json": {Data: []byte(`{"Login": "Inloggen", "Hello %s": "Hallo %s"}`)},
+	}
+	cat, err := loadCatalog(webFS, "translations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		lang, msg string
+		args      []interface{}
+		want      string
+	}{
+		{"en", "Login", nil, "Sign in"},
+		{"nl", "Login", nil, "Inloggen"},
+		{"nl", "Hello %s", []interface{}{"Jane"}, "Hallo Jane"},
+		{"nl", "Cancel", nil, "Cancel"},
+	} {
+		if got := cat.translate(tc.lang, tc.msg, tc.args...); got != tc.want {
+			t.Errorf("translate(%q, %q): expected %q, got %q", tc.lang, tc.msg, tc.want, got)
+		}
+	}
+
+	if _, err := loadCatalog(fstest.MapFS{}, "translations"); err != nil {
+		t.Errorf("expected a missing translations directory to be ignored, got %v", err)
+	}
+	if _, err := loadCatalog(fstest.MapFS{"translations/nl.json": {Data: []byte(`[]`)}}, "translations"); err == nil {
+		t.Error("expected an invalid translation to be rejected")
+	}
+}
+
+func TestApprovalPage(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, s := newTestServer(ctx, t, func(c *Config) {
+		c.Web.ScopeDescriptions = map[string]string{"offline_access": "Stay signed in"}
+	})
+	defer httpServer.Close()
+
+	client := storage.Client{
+		Name:              "Example App",
+		LogoURL:           "https://example.com/logo.png",
+		PolicyURL:         "https://example.com/privacy",
+		TermsOfServiceURL: "https://example.com/terms",
+	}
+	r := httptest.NewRequest(http.MethodGet, "/approval", nil)
+	w := httptest.NewRecorder()
+	if err := s.templates.approval(r, w, "req", "jane", client, []string{"openid", "email", "offline_access"}, nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	page := w.Body.String()
+	for _, want := range []string{
+		"Example App would like to:",
+		"Stay signed in",
+		"View your email address",
+		`src="https://example.com/logo.png"`,
+		`href="https://example.com/privacy"`,
+		`href="https://example.com/terms"`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("expected the approval page to contain %q, got %s", want, page)
+		}
+	}
+	if strings.Contains(page, "Have offline access") {
+		t.Error("expected the built-in description to be replaced")
+	}
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dexidp/dex/storage"
+)
+
+// tokenLifetimes holds how long ID and access tokens are valid.
+type tokenLifetimes struct {
+	idTokens     time.Duration
+	accessTokens time.Duration
+}
+
+// withOverrides returns the lifetimes with the token expiry overrides of a
+// client applied.
+func (l tokenLifetimes) withOverrides(overrides *storage.TokenExpiry) (tokenLifetimes, error) {
+	if overrides == nil {
+		return l, nil
+	}
+
+	parse := func(name, value string, d *time.Duration) error {
+		if value == "" {
+			return nil
+		}
+		v, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid value %q for %s expiry: %v", value, name, err)
+		}
+		if v <= 0 {
+			return fmt.Errorf("invalid value %q for %s expiry: must be positive", value, name)
+		}
+		*d = v
+		return nil
+	}
+
+	if err := parse("id tokens", overrides.IDTokens, &l.idTokens); err != nil {
+		return l, err
+	}
+	if err := parse("access tokens", overrides.AccessTokens, &l.accessTokens); err != nil {
+		return l, err
+	}
+	return l, nil
+}
+
+// ValidateTokenExpiry checks the token expiry overrides of a client.
+func ValidateTokenExpiry(overrides *storage.TokenExpiry) error {
+	_, err := tokenLifetimes{}.withOverrides(overrides)
+	return err
+}
+
+// clientTokenLifetimes returns how long tokens issued to a client are valid.
+// Unless the client overrides them, ID and access tokens are valid for the
+// server wide ID token lifetime. A nil client gets the defaults.
+func (s *Server) clientTokenLifetimes(ctx context.Context, client *storage.Client) tokenLifetimes {
+	lifetimes := tokenLifetimes{
+		idTokens:     s.idTokensValidFor,
+		accessTokens: s.idTokensValidFor,
+	}
+	if client == nil {
+		return lifetimes
+	}
+
+	clientLifetimes, err := lifetimes.withOverrides(client.TokenExpiry)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "invalid client token expiry, using default", "client_id", client.ID, "err", err)
+		return lifetimes
+	}
+	return clientLifetimes
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dexidp/dex/storage"
+)
+
+func TestClientTokenLifetimes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	now := time.Now().UTC().Round(time.Second)
+	httpServer, s := newTestServer(ctx, t, func(c *Config) {
+		c.IDTokensValidFor = time.Hour
+		c.Now = func() time.Time { return now }
+	})
+	defer httpServer.Close()
+
+	for _, client := range []storage.Client{
+		{ID: "default"},
+		{ID: "short", TokenExpiry: &storage.TokenExpiry{IDTokens: "10m", AccessTokens: "5m"}},
+		{ID: "access-only", TokenExpiry: &storage.TokenExpiry{AccessTokens: "2h"}},
+		{ID: "invalid", TokenExpiry: &storage.TokenExpiry{IDTokens: "soon"}},
+	} {
+		require.NoError(t, s.storage.CreateClient(ctx, client))
+	}
+
+	tests := []struct {
+		clientID         string
+		wantIDTokens     time.Duration
+		wantAccessTokens time.Duration
+	}{
+		{clientID: "default", wantIDTokens: time.Hour, wantAccessTokens: time.Hour},
+		{clientID: "short", wantIDTokens: 10 * time.Minute, wantAccessTokens: 5 * time.Minute},
+		{clientID: "access-only", wantIDTokens: time.Hour, wantAccessTokens: 2 * time.Hour},
+		{clientID: "invalid", wantIDTokens: time.Hour, wantAccessTokens: time.Hour},
+		{clientID: "unknown", wantIDTokens: time.Hour, wantAccessTokens: time.Hour},
+	}
+	for _, tc := range tests {
+		t.Run(tc.clientID, func(t *testing.T) {
+			claims := storage.Claims{UserID: "1"}
+
+			_, expiry, err := s.newIDToken(ctx, tc.clientID, claims, []string{"openid"}, nil, "", "", "", "mock")
+			require.NoError(t, err)
+			require.Equal(t, now.Add(tc.wantIDTokens), expiry)
+
+			_, expiry, err = s.newAccessToken(ctx, tc.clientID, claims, []string{"openid"}, nil, "", "mock")
+			require.NoError(t, err)
+			require.Equal(t, now.Add(tc.wantAccessTokens), expiry)
+		})
+	}
+}
+
+func TestValidateTokenExpiry(t *testing.T) {
+	require.NoError(t, ValidateTokenExpiry(nil))
+	require.NoError(t, ValidateTokenExpiry(&storage.TokenExpiry{IDTokens: "1h", AccessTokens: "30m"}))
+	require.Error(t, ValidateTokenExpiry(&storage.TokenExpiry{IDTokens: "1 hour"}))
+	require.Error(t, ValidateTokenExpiry(&storage.TokenExpiry{AccessTokens: "0s"}))
+}
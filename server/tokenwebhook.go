@@ -0,0 +1,200 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/dexidp/dex/storage"
+)
+
+// tokenWebhookEvent is the value of the event header of token webhook requests.
+const tokenWebhookEvent = "token_issuance"
+
+// maxTokenWebhookResponse bounds the size of token webhook responses.
+const maxTokenWebhookResponse = 1 << 20
+
+// TokenWebhookConfig is a webhook called right before tokens are signed, for
+// example by an entitlement system adding the roles of users to their tokens.
+type TokenWebhookConfig struct {
+	// URL requests are posted to.
+	URL string
+
+	// Secret the requests are signed with, like the requests of event webhooks.
+	Secret string
+
+	// How long to wait for a response. Defaults to 5 seconds.
+	Timeout time.Duration
+
+	// If set, tokens are issued without the changes of the webhook when it
+	// can't be reached or fails, instead of not being issued.
+	FailOpen bool
+}
+
+type tokenWebhook struct {
+	url      string
+	secret   string
+	failOpen bool
+	client   *http.Client
+}
+
+func newTokenWebhook(c *TokenWebhookConfig) (*tokenWebhook, error) {
+	if err := validateWebhook(storage.Webhook{URL: c.URL, Secret: c.Secret}); err != nil {
+		return nil, fmt.Errorf("token webhook: %v", err)
+	}
+	if c.Timeout < 0 {
+		return nil, errors.New("token webhook: timeout must not be negative")
+	}
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+	return &tokenWebhook{
+		url:      c.URL,
+		secret:   c.Secret,
+		failOpen: c.FailOpen,
+		client:   &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// tokenWebhookRequest is the body of a token webhook request.
+type tokenWebhookRequest struct {
+	TokenType   string                 `json:"token_type"`
+	Issuer      string                 `json:"issuer"`
+	ClientID    string                 `json:"client_id"`
+	ConnectorID string                 `json:"connector_id"`
+	Scopes      []string               `json:"scopes"`
+	Identity    map[string]interface{} `json:"identity"`
+	Claims      map[string]interface{} `json:"claims"`
+}
+
+// tokenWebhookResponse is the body of a token webhook response. Claims set
+// to null are removed from the token.
+type tokenWebhookResponse struct {
+	Deny   bool                   `json:"deny"`
+	Reason string                 `json:"reason"`
+	Claims map[string]interface{} `json:"claims"`
+}
+
+// tokenDeniedError is returned when the token webhook or the authorization
+// policy denies issuing a token.
+type tokenDeniedError struct {
+	source string
+	reason string
+}
+
+func (e *tokenDeniedError) Error() string {
+	if e.reason == "" {
+		return e.source + " denied issuing the token"
+	}
+	return fmt.Sprintf("%s denied issuing the token: %s", e.source, e.reason)
+}
+
+// callTokenWebhook sends the claims of a token to the token webhook and
+// returns them with its changes applied.
+func (s *Server) callTokenWebhook(ctx context.Context, tokenType string, payload []byte, clientID string, claims storage.Claims, scopes []string, connID string) ([]byte, error) {
+	result, err := s.tokenWebhook.call(ctx, tokenType, s.issuerURL.String(), payload, clientID, claims, scopes, connID)
+	var denied *tokenDeniedError
+	switch {
+	case errors.As(err, &denied):
+		s.logger.InfoContext(ctx, "token webhook denied token", "client_id", clientID, "connector_id", connID,
+			"user_id", claims.UserID, "reason", denied.reason)
+		return nil, err
+	case err != nil && s.tokenWebhook.failOpen:
+		s.logger.WarnContext(ctx, "token webhook failed, issuing token unchanged", "client_id", clientID, "err", err)
+		return payload, nil
+	case err != nil:
+		s.logger.ErrorContext(ctx, "token webhook failed", "client_id", clientID, "err", err)
+		return nil, fmt.Errorf("token webhook: %v", err)
+	}
+	return result, nil
+}
+
+func (w *tokenWebhook) call(ctx context.Context, tokenType, issuer string, payload []byte, clientID string, claims storage.Claims, scopes []string, connID string) ([]byte, error) {
+	var tokenClaims map[string]interface{}
+	if err := json.Unmarshal(payload, &tokenClaims); err != nil {
+		return nil, err
+	}
+	if scopes == nil {
+		scopes = []string{}
+	}
+	body, err := json.Marshal(tokenWebhookRequest{
+		TokenType:   tokenType,
+		Issuer:      issuer,
+		ClientID:    clientID,
+		ConnectorID: connID,
+		Scopes:      scopes,
+		Identity:    identityValues(claims),
+		Claims:      tokenClaims,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhookEventHeader, tokenWebhookEvent)
+	req.Header.Set(webhookDeliveryHeader, storage.NewID())
+	req.Header.Set(webhookSignatureHeader, signWebhookPayload(w.secret, body))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		io.Copy(io.Discard, resp.Body)
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var result tokenWebhookResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxTokenWebhookResponse)).Decode(&result); err != nil {
+		return nil, fmt.Errorf("invalid response: %v", err)
+	}
+	if result.Deny {
+		return nil, &tokenDeniedError{source: "token webhook", reason: result.Reason}
+	}
+	if len(result.Claims) == 0 {
+		return payload, nil
+	}
+	if err := setClaims(tokenClaims, result.Claims); err != nil {
+		return nil, fmt.Errorf("invalid response: %v", err)
+	}
+	return json.Marshal(tokenClaims)
+}
+
+// setClaims sets claims of a token, removing the ones set to nil. Reserved
+// claims can't be changed.
+func setClaims(tokenClaims, changes map[string]interface{}) error {
+	for claim, value := range changes {
+		if reservedClaims[claim] {
+			return fmt.Errorf("claim %q is reserved", claim)
+		}
+		if value == nil {
+			delete(tokenClaims, claim)
+			continue
+		}
+		tokenClaims[claim] = value
+	}
+	return nil
+}
+
+// tokenIssueErrHelper writes the response of a token request failing to issue
+// a token: access_denied if the token webhook or the authorization policy
+// denied it, a server error otherwise.
+func (s *Server) tokenIssueErrHelper(w http.ResponseWriter, err error) {
+	var denied *tokenDeniedError
+	if errors.As(err, &denied) {
+		s.tokenErrHelper(w, errAccessDenied, denied.reason, http.StatusForbidden)
+		return
+	}
+	s.tokenErrHelper(w, errServerError, "", http.StatusInternalServerError)
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dexidp/dex/storage"
+)
+
+func TestTokenWebhook(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var requests []tokenWebhookRequest
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, signWebhookPayload("secret", body), r.Header.Get(webhookSignatureHeader))
+		require.Equal(t, tokenWebhookEvent, r.Header.Get(webhookEventHeader))
+
+		var req tokenWebhookRequest
+		require.NoError(t, json.Unmarshal(body, &req))
+		requests = append(requests, req)
+
+		switch req.Identity["username"] {
+		case "jane":
+			w.Write([]byte(`{"claims": {"roles": ["admin"], "email": null}}`))
+		case "john":
+			w.Write([]byte(`{"deny": true, "reason": "No license."}`))
+		case "mallory":
+			w.Write([]byte(`{"claims": {"sub": "admin"}}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer webhook.Close()
+
+	httpServer, s := newTestServer(ctx, t, func(c *Config) {
+		c.TokenWebhook = &TokenWebhookConfig{URL: webhook.URL, Secret: "secret"}
+	})
+	defer httpServer.Close()
+
+	scopes := []string{"openid", "email"}
+	token, _, err := s.newIDToken(ctx, "test", storage.Claims{UserID: "1", Username: "jane", Email: "jane@example.com"}, scopes, nil, "", "", "", "mock")
+	require.NoError(t, err)
+	jws, err := jose.ParseSigned(token, []jose.SignatureAlgorithm{jose.RS256})
+	require.NoError(t, err)
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal(jws.UnsafePayloadWithoutVerification(), &got))
+	require.Equal(t, []interface{}{"admin"}, got["roles"])
+	require.NotContains(t, got, "email")
+
+	require.Len(t, requests, 1)
+	require.Equal(t, "id_token", requests[0].TokenType)
+	require.Equal(t, "test", requests[0].ClientID)
+	require.Equal(t, "mock", requests[0].ConnectorID)
+	require.Equal(t, scopes, requests[0].Scopes)
+	require.Equal(t, "jane@example.com", requests[0].Claims["email"])
+
+	_, _, err = s.newAccessToken(ctx, "test", storage.Claims{UserID: "2", Username: "john"}, scopes, nil, "", "mock")
+	var denied *tokenDeniedError
+	require.True(t, errors.As(err, &denied))
+	require.Equal(t, "access_token", requests[1].TokenType)
+
+	rr := httptest.NewRecorder()
+	s.tokenIssueErrHelper(rr, err)
+	require.Equal(t, http.StatusForbidden, rr.Code)
+	require.Contains(t, rr.Body.String(), errAccessDenied)
+	require.Contains(t, rr.Body.String(), "No license.")
+
+	_, _, err = s.newIDToken(ctx, "test", storage.Claims{UserID: "3", Username: "mallory"}, scopes, nil, "", "", "", "mock")
+	require.ErrorContains(t, err, "reserved")
+	require.False(t, errors.As(err, &denied))
+
+	_, _, err = s.newIDToken(ctx, "test", storage.Claims{UserID: "4", Username: "eve"}, scopes, nil, "", "", "", "mock")
+	require.ErrorContains(t, err, "unexpected status")
+
+	s.tokenWebhook.failOpen = true
+	_, _, err = s.newIDToken(ctx, "test", storage.Claims{UserID: "4", Username: "eve"}, scopes, nil, "", "", "", "mock")
+	require.NoError(t, err, "the token is issued unchanged if the webhook fails open")
+	_, _, err = s.newIDToken(ctx, "test", storage.Claims{UserID: "2", Username: "john"}, scopes, nil, "", "", "", "mock")
+	require.Error(t, err, "a denial is honored even if the webhook fails open")
+}
+
+func TestNewTokenWebhook(t *testing.T) {
+	_, err := newTokenWebhook(&TokenWebhookConfig{URL: "https://example.com/hook", Secret: "secret"})
+	require.NoError(t, err)
+
+	for _, c := range []TokenWebhookConfig{
+		{URL: "example.com/hook", Secret: "secret"},
+		{URL: "https://example.com/hook"},
+		{URL: "https://example.com/hook", Secret: "secret", Timeout: -1},
+	} {
+		_, err := newTokenWebhook(&c)
+		require.Error(t, err, "%+v", c)
+	}
+}
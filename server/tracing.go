@@ -0,0 +1,32 @@
+package server
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attributes of the spans of the server, in addition to the ones of the HTTP
+// instrumentation.
+const (
+	attrClientID    = attribute.Key("dex.client.id")
+	attrConnectorID = attribute.Key("dex.connector.id")
+	attrRequestID   = attribute.Key("dex.request.id")
+)
+
+// startSpan starts the span of a step of a request which may be slow, such as
+// calling a connector.
+func (s *Server) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return s.tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan ends a span, marking it as failed if the step returned an error.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+	}
+	span.End()
+}
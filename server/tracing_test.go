@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/dexidp/dex/storage"
+)
+
+func TestTracing(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	httpServer, s := newTestServer(ctx, t, func(c *Config) {
+		c.TracerProvider = tp
+		c.Storage = storage.WithTracing(c.Storage, tp)
+	})
+	defer httpServer.Close()
+
+	require.NoError(t, s.storage.CreateClient(ctx, storage.Client{
+		ID:           "app",
+		RedirectURIs: []string{"https://app.example.com/callback"},
+	}))
+	require.NoError(t, s.storage.CreateAuthRequest(ctx, storage.AuthRequest{
+		ID:            "test",
+		ClientID:      "app",
+		ConnectorID:   "mock",
+		RedirectURI:   "https://app.example.com/callback",
+		Expiry:        time.Now().Add(time.Minute),
+		ResponseTypes: []string{responseTypeCode},
+	}))
+	exporter.Reset()
+
+	rr := httptest.NewRecorder()
+	s.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/callback?state=test", nil))
+	require.Equal(t, http.StatusSeeOther, rr.Code)
+
+	spans := make(map[string]tracetest.SpanStub)
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+	root, ok := spans["GET /callback"]
+	require.True(t, ok, "no span of the handler in %v", spans)
+
+	// The connector and the storage are traced as part of the request.
+	for _, name := range []string{"connector.HandleCallback", "storage.CreateAuthCode"} {
+		span, ok := spans[name]
+		require.True(t, ok, "no %s span", name)
+		require.Equal(t, root.SpanContext.TraceID(), span.SpanContext.TraceID())
+		require.Equal(t, root.SpanContext.SpanID(), span.Parent.SpanID())
+	}
+	require.Contains(t, spans["connector.HandleCallback"].Attributes, attrConnectorID.String("mock"))
+}
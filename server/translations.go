@@ -0,0 +1,100 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// defaultLanguage is the language the templates are written in.
+var defaultLanguage = language.English
+
+// catalog holds the translations of the messages shown by the frontend. The
+// messages are keyed by their English text, so that untranslated messages
+// are shown in English.
+type catalog struct {
+	matcher language.Matcher
+	// tags are the supported languages, the first one is the default.
+	tags     []language.Tag
+	messages []map[string]string
+}
+
+// loadCatalog reads the translations from the JSON files in dir, one per
+// language, e.g. "de.json". A missing directory isn't an error, so web
+// directories which predate translations keep working.
+func loadCatalog(webFS fs.FS, dir string) (*catalog, error) {
+	c := &catalog{
+		tags:     []language.Tag{defaultLanguage},
+		messages: []map[string]string{nil},
+	}
+
+	files, err := fs.ReadDir(webFS, dir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("read dir: %v", err)
+	}
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || path.Ext(name) != ".json" {
+			continue
+		}
+		tag, err := language.Parse(strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			return nil, fmt.Errorf("translation %q: invalid language: %v", name, err)
+		}
+		data, err := fs.ReadFile(webFS, path.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("read translation %q: %v", name, err)
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return nil, fmt.Errorf("parse translation %q: %v", name, err)
+		}
+		if tag == defaultLanguage {
+			// Allows rewording the default messages.
+			c.messages[0] = messages
+			continue
+		}
+		c.tags = append(c.tags, tag)
+		c.messages = append(c.messages, messages)
+	}
+
+	c.matcher = language.NewMatcher(c.tags)
+	return c, nil
+}
+
+// requestLanguage picks the language of the page requested by r. Locales set
+// by the client through the "ui_locales" parameter take precedence over the
+// ones of the browser.
+func (c *catalog) requestLanguage(r *http.Request) string {
+	var preferred []string
+	// ui_locales is a space separated list of tags, ordered by preference.
+	if uiLocales := r.FormValue("ui_locales"); uiLocales != "" {
+		preferred = append(preferred, strings.Join(strings.Fields(uiLocales), ","))
+	}
+	preferred = append(preferred, r.Header.Get("Accept-Language"))
+
+	_, i := language.MatchStrings(c.matcher, preferred...)
+	return c.tags[i].String()
+}
+
+// translate returns the message in the given language, formatted with args.
+func (c *catalog) translate(lang, msg string, args ...interface{}) string {
+	for i, tag := range c.tags {
+		if tag.String() == lang {
+			if translated, ok := c.messages[i][msg]; ok && translated != "" {
+				msg = translated
+			}
+			break
+		}
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// webFingerPath is the well-known path of WebFinger, which is always served
+// at the root of the host regardless of the path of the issuer.
+const webFingerPath = "/.well-known/webfinger"
+
+// webFingerIssuerRel is the link relation OpenID Connect Discovery uses to
+// look up the issuer of a user.
+const webFingerIssuerRel = "http://openid.net/specs/connect/1.0/issuer"
+
+type webFingerLink struct {
+	Rel  string `json:"rel"`
+	Href string `json:"href"`
+}
+
+// webFingerResponse is a JSON Resource Descriptor as defined by RFC 7033.
+type webFingerResponse struct {
+	Subject string          `json:"subject"`
+	Links   []webFingerLink `json:"links"`
+}
+
+// webFingerDomain returns the host a WebFinger resource belongs to. Resources
+// are either "acct:" URIs, e-mail addresses or URLs.
+func webFingerDomain(resource string) (string, bool) {
+	if acct, ok := strings.CutPrefix(resource, "acct:"); ok {
+		resource = acct
+	} else if u, err := url.Parse(resource); err == nil && u.Scheme != "" && u.Host != "" {
+		return strings.ToLower(u.Hostname()), true
+	}
+	i := strings.LastIndex(resource, "@")
+	if i <= 0 || i == len(resource)-1 {
+		return "", false
+	}
+	return strings.ToLower(resource[i+1:]), true
+}
+
+// servesWebFingerDomain reports whether the server is the issuer of users of
+// a domain. Without configured domains, it claims all of them.
+func (s *Server) servesWebFingerDomain(domain string) bool {
+	return len(s.webFingerDomains) == 0 || slices.Contains(s.webFingerDomains, domain)
+}
+
+// handleWebFinger implements issuer discovery from OpenID Connect Discovery,
+// letting clients find the issuer of a user by their e-mail address.
+func (s *Server) handleWebFinger(w http.ResponseWriter, r *http.Request) {
+	// WebFinger responses are public and must be accessible from any origin.
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	q := r.URL.Query()
+	resource := q.Get("resource")
+	if resource == "" {
+		http.Error(w, "missing resource parameter", http.StatusBadRequest)
+		return
+	}
+	domain, ok := webFingerDomain(resource)
+	if !ok {
+		http.Error(w, "invalid resource parameter", http.StatusBadRequest)
+		return
+	}
+	if !s.servesWebFingerDomain(domain) {
+		http.NotFound(w, r)
+		return
+	}
+
+	resp := webFingerResponse{Subject: resource, Links: []webFingerLink{}}
+	if rels := q["rel"]; len(rels) == 0 || slices.Contains(rels, webFingerIssuerRel) {
+		resp.Links = append(resp.Links, webFingerLink{Rel: webFingerIssuerRel, Href: s.issuerURL.String()})
+	}
+
+	data, err := json.Marshal(resp)
+	if err != nil {
+		s.logger.ErrorContext(r.Context(), "failed to marshal webfinger response", "err", err)
+		s.renderError(r, w, http.StatusInternalServerError, "Internal server error.")
+		return
+	}
+	w.Header().Set("Content-Type", "application/jrd+json")
+	w.Write(data)
+}
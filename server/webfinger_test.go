@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	gosundheit "github.com/AppsFlyer/go-sundheit"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dexidp/dex/storage"
+	"github.com/dexidp/dex/storage/memory"
+)
+
+func TestWebFingerDomain(t *testing.T) {
+	tests := []struct {
+		resource   string
+		wantDomain string
+		wantOK     bool
+	}{
+		{resource: "acct:jane@Example.com", wantDomain: "example.com", wantOK: true},
+		{resource: "jane@example.com", wantDomain: "example.com", wantOK: true},
+		{resource: "https://example.com:8080/jane", wantDomain: "example.com", wantOK: true},
+		{resource: "acct:jane", wantOK: false},
+		{resource: "acct:@example.com", wantOK: false},
+		{resource: "jane@", wantOK: false},
+	}
+	for _, tc := range tests {
+		domain, ok := webFingerDomain(tc.resource)
+		require.Equal(t, tc.wantOK, ok, tc.resource)
+		require.Equal(t, tc.wantDomain, domain, tc.resource)
+	}
+}
+
+func TestHandleWebFinger(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, server := newTestServer(ctx, t, func(c *Config) {
+		c.WebFingerDomains = []string{"Example.com"}
+	})
+	defer httpServer.Close()
+
+	query := func(resource string, rels ...string) *httptest.ResponseRecorder {
+		q := url.Values{}
+		if resource != "" {
+			q.Set("resource", resource)
+		}
+		for _, rel := range rels {
+			q.Add("rel", rel)
+		}
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, webFingerPath+"?"+q.Encode(), nil))
+		return rr
+	}
+
+	rr := query("acct:jane@example.com", webFingerIssuerRel)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "application/jrd+json", rr.Header().Get("Content-Type"))
+	require.Equal(t, "*", rr.Header().Get("Access-Control-Allow-Origin"))
+
+	var resp webFingerResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Equal(t, webFingerResponse{
+		Subject: "acct:jane@example.com",
+		Links:   []webFingerLink{{Rel: webFingerIssuerRel, Href: server.issuerURL.String()}},
+	}, resp)
+
+	rr = query("acct:jane@example.com", "http://webfinger.net/rel/avatar")
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Empty(t, resp.Links)
+
+	require.Equal(t, http.StatusNotFound, query("acct:jane@other.com").Code)
+	require.Equal(t, http.StatusBadRequest, query("").Code)
+	require.Equal(t, http.StatusBadRequest, query("acct:jane").Code)
+}
+
+func TestIssuerMuxWebFinger(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	backing := memory.New(logger)
+	require.NoError(t, backing.CreateConnector(ctx, storage.Connector{
+		ID:              "mock",
+		Type:            "mockCallback",
+		Name:            "Mock",
+		ResourceVersion: "1",
+	}))
+
+	newIssuer := func(issuer string, s storage.Storage, domains ...string) *Server {
+		server, err := newServer(ctx, Config{
+			Issuer:           issuer,
+			Storage:          s,
+			Web:              WebConfig{Dir: "../web"},
+			Logger:           logger,
+			HealthChecker:    gosundheit.New(),
+			WebFingerDomains: domains,
+		}, staticRotationStrategy(testKey))
+		require.NoError(t, err)
+		return server
+	}
+
+	const (
+		defaultIssuer = "http://dex.example.com/dex"
+		tenantIssuer  = "http://dex.example.com/tenant"
+	)
+	mux := NewIssuerMux(
+		newIssuer(defaultIssuer, backing),
+		newIssuer(tenantIssuer, storage.WithIssuer(backing, tenantIssuer, nil, nil), "tenant.com"),
+	)
+
+	tests := []struct {
+		resource   string
+		wantIssuer string
+	}{
+		{resource: "acct:jane@tenant.com", wantIssuer: tenantIssuer},
+		{resource: "acct:jane@example.com", wantIssuer: defaultIssuer},
+	}
+	for _, tc := range tests {
+		t.Run(tc.resource, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet,
+				"http://dex.example.com"+webFingerPath+"?resource="+url.QueryEscape(tc.resource), nil))
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			var resp webFingerResponse
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Len(t, resp.Links, 1)
+			require.Equal(t, tc.wantIssuer, resp.Links[0].Href)
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/dexidp/dex/storage"
+)
+
+const (
+	// defaultWebhookMaxAttempts and defaultWebhookBackoff apply to webhooks
+	// without a retry policy.
+	defaultWebhookMaxAttempts = 5
+	defaultWebhookBackoff     = time.Second
+	// maxWebhookBackoff bounds the doubling time between attempts.
+	maxWebhookBackoff = time.Minute
+
+	webhookTimeout = 10 * time.Second
+)
+
+// Headers of webhook requests.
+const (
+	webhookSignatureHeader = "X-Dex-Signature"
+	webhookEventHeader     = "X-Dex-Event"
+	webhookDeliveryHeader  = "X-Dex-Delivery"
+)
+
+// webhookEventTypes are the events webhooks can subscribe to.
+var webhookEventTypes = map[string]bool{
+	eventLoginSucceeded: true,
+	eventLoginFailed:    true,
+	eventConsentGranted: true,
+	eventConsentDenied:  true,
+	eventTokenIssued:    true,
+	eventTokenRefreshed: true,
+	eventRefreshRevoked: true,
+	eventClientModified: true,
+
+	eventRepeatedLoginFailures: true,
+	eventRefreshReused:         true,
+	eventPasswordLocked:        true,
+	eventPasswordUnlocked:      true,
+}
+
+// webhookPayload is the body of a webhook request, in the same shape as the
+// events streamed by the gRPC API.
+type webhookPayload struct {
+	Type        string            `json:"type"`
+	Time        int64             `json:"time"`
+	Issuer      string            `json:"issuer"`
+	ClientID    string            `json:"client_id,omitempty"`
+	ConnectorID string            `json:"connector_id,omitempty"`
+	UserID      string            `json:"user_id,omitempty"`
+	Username    string            `json:"username,omitempty"`
+	Details     map[string]string `json:"details,omitempty"`
+}
+
+// validateWebhook checks the URL, the event types and the retry policy of a webhook.
+func validateWebhook(w storage.Webhook) error {
+	u, err := url.Parse(w.URL)
+	if err != nil {
+		return fmt.Errorf("invalid url: %v", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid url %q: must be an absolute http or https url", w.URL)
+	}
+	if w.Secret == "" {
+		return errors.New("no secret")
+	}
+	for _, e := range w.Events {
+		if !webhookEventTypes[e] {
+			return fmt.Errorf("unknown event type %q", e)
+		}
+	}
+	if p := w.RetryPolicy; p != nil {
+		if p.MaxAttempts < 0 {
+			return errors.New("max attempts must not be negative")
+		}
+		if p.Backoff != "" {
+			backoff, err := time.ParseDuration(p.Backoff)
+			if err != nil {
+				return fmt.Errorf("invalid backoff: %v", err)
+			}
+			if backoff <= 0 {
+				return errors.New("backoff must be positive")
+			}
+		}
+	}
+	return nil
+}
+
+// webhookRetry returns the number of attempts and the time before the first
+// retry of a webhook.
+func webhookRetry(p *storage.WebhookRetryPolicy) (int, time.Duration) {
+	attempts, backoff := defaultWebhookMaxAttempts, defaultWebhookBackoff
+	if p == nil {
+		return attempts, backoff
+	}
+	if p.MaxAttempts > 0 {
+		attempts = p.MaxAttempts
+	}
+	if d, err := time.ParseDuration(p.Backoff); err == nil && d > 0 {
+		backoff = d
+	}
+	return attempts, backoff
+}
+
+// subscribesTo reports if a webhook receives events of a type.
+func subscribesTo(w storage.Webhook, eventType string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// signWebhookPayload returns the value of the signature header for a body.
+func signWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// startWebhookDispatcher delivers the events of the server to the webhooks
+// in storage until the context is canceled.
+func (s *Server) startWebhookDispatcher(ctx context.Context) {
+	events, unsubscribe := s.events.subscribe()
+	issuer := s.issuerURL.String()
+
+	go func() {
+		defer unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e := <-events:
+				// The broker may be shared with the servers of other issuers.
+				if e.Issuer != issuer {
+					continue
+				}
+				s.dispatchWebhooks(ctx, e)
+			}
+		}
+	}()
+}
+
+// dispatchWebhooks starts delivering an event to the webhooks subscribed to it.
+func (s *Server) dispatchWebhooks(ctx context.Context, e auditEvent) {
+	webhooks, err := s.storage.ListWebhooks()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to list webhooks", "err", err)
+		return
+	}
+
+	var body []byte
+	for _, w := range webhooks {
+		if !subscribesTo(w, e.Type) {
+			continue
+		}
+		if body == nil {
+			body, err = json.Marshal(webhookPayload{
+				Type:        e.Type,
+				Time:        e.Time.Unix(),
+				Issuer:      e.Issuer,
+				ClientID:    e.ClientID,
+				ConnectorID: e.ConnectorID,
+				UserID:      e.UserID,
+				Username:    e.Username,
+				Details:     e.Details,
+			})
+			if err != nil {
+				s.logger.ErrorContext(ctx, "failed to marshal webhook payload", "err", err)
+				return
+			}
+		}
+		go s.deliverWebhook(ctx, w, e.Type, body)
+	}
+}
+
+// deliverWebhook posts an event to a webhook, retrying with exponential
+// backoff until the webhook accepts it or the attempts are exhausted.
+func (s *Server) deliverWebhook(ctx context.Context, w storage.Webhook, eventType string, body []byte) {
+	attempts, backoff := webhookRetry(w.RetryPolicy)
+	delivery := storage.NewID()
+
+	for attempt := 1; ; attempt++ {
+		err := s.postWebhook(ctx, w, eventType, delivery, body)
+		if err == nil {
+			return
+		}
+		if attempt >= attempts {
+			s.logger.ErrorContext(ctx, "webhook delivery failed, dropping event",
+				"webhook", w.ID, "type", eventType, "attempts", attempt, "err", err)
+			return
+		}
+		s.logger.WarnContext(ctx, "webhook delivery failed, retrying",
+			"webhook", w.ID, "type", eventType, "attempt", attempt, "retry_in", backoff, "err", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxWebhookBackoff)
+	}
+}
+
+func (s *Server) postWebhook(ctx context.Context, w storage.Webhook, eventType, delivery string, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhookEventHeader, eventType)
+	req.Header.Set(webhookDeliveryHeader, delivery)
+	req.Header.Set(webhookSignatureHeader, signWebhookPayload(w.Secret, body))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dexidp/dex/storage"
+)
+
+func TestWebhookRetry(t *testing.T) {
+	tests := []struct {
+		name         string
+		policy       *storage.WebhookRetryPolicy
+		wantAttempts int
+		wantBackoff  time.Duration
+	}{
+		{
+			name:         "defaults",
+			wantAttempts: defaultWebhookMaxAttempts,
+			wantBackoff:  defaultWebhookBackoff,
+		},
+		{
+			name:         "partial policy",
+			policy:       &storage.WebhookRetryPolicy{MaxAttempts: 2},
+			wantAttempts: 2,
+			wantBackoff:  defaultWebhookBackoff,
+		},
+		{
+			name:         "full policy",
+			policy:       &storage.WebhookRetryPolicy{MaxAttempts: 1, Backoff: "30s"},
+			wantAttempts: 1,
+			wantBackoff:  30 * time.Second,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			attempts, backoff := webhookRetry(tc.policy)
+			require.Equal(t, tc.wantAttempts, attempts)
+			require.Equal(t, tc.wantBackoff, backoff)
+		})
+	}
+}
+
+type webhookRequest struct {
+	event     string
+	signature string
+	payload   webhookPayload
+	body      []byte
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		requests []webhookRequest
+	)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		req := webhookRequest{
+			event:     r.Header.Get(webhookEventHeader),
+			signature: r.Header.Get(webhookSignatureHeader),
+			body:      body,
+		}
+		require.NoError(t, json.Unmarshal(body, &req.payload))
+
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, req)
+		// Fail the first attempt to exercise retries.
+		if len(requests) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer receiver.Close()
+
+	httpServer, server := newTestServer(ctx, t, nil)
+	defer httpServer.Close()
+
+	require.NoError(t, server.storage.CreateWebhook(ctx, storage.Webhook{
+		ID:          "clients",
+		URL:         receiver.URL,
+		Secret:      "secret",
+		Events:      []string{eventClientModified},
+		RetryPolicy: &storage.WebhookRetryPolicy{MaxAttempts: 3, Backoff: "1ms"},
+	}))
+	require.NoError(t, server.storage.CreateWebhook(ctx, storage.Webhook{
+		ID:     "logins",
+		URL:    receiver.URL + "/logins",
+		Secret: "secret",
+		Events: []string{eventLoginSucceeded},
+	}))
+
+	server.emitEvent(ctx, auditEvent{
+		Type:     eventClientModified,
+		ClientID: "app",
+		Details:  map[string]string{"operation": "create"},
+	})
+	// Events of other issuers sharing the broker are ignored.
+	server.events.publish(auditEvent{Type: eventClientModified, Issuer: "https://other.example.com"})
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(requests) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, req := range requests {
+		require.Equal(t, eventClientModified, req.event)
+		require.Equal(t, signWebhookPayload("secret", req.body), req.signature)
+		require.Equal(t, webhookPayload{
+			Type:     eventClientModified,
+			Time:     req.payload.Time,
+			Issuer:   server.issuerURL.String(),
+			ClientID: "app",
+			Details:  map[string]string{"operation": "create"},
+		}, req.payload)
+	}
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	// echo -n '{"type":"login_failed"}' | openssl dgst -sha256 -hmac secret
+	require.Equal(t,
+		"sha256=debbdb5b64e285c51ba35f0143f6414110809af033ec1668023406f9582f5b8e",
+		signWebhookPayload("secret", []byte(`{"type":"login_failed"}`)))
+}
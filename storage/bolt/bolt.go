@@ -0,0 +1,418 @@
+// Package bolt provides a storage implementation embedded in dex, persisted
+// to a single file with bbolt.
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/dexidp/dex/storage"
+)
+
+// Each kind of object is kept in its own bucket, keyed by its ID and encoded
+// as JSON.
+var (
+	clientBucket         = []byte("client")
+	authCodeBucket       = []byte("auth_code")
+	refreshTokenBucket   = []byte("refresh_token")
+	authRequestBucket    = []byte("auth_req")
+	passwordBucket       = []byte("password")
+	offlineSessionBucket = []byte("offline_session")
+	connectorBucket      = []byte("connector")
+	keysBucket           = []byte("keys")
+	deviceRequestBucket  = []byte("device_req")
+	deviceTokenBucket    = []byte("device_token")
+	webhookBucket        = []byte("webhook")
+
+	buckets = [][]byte{
+		clientBucket,
+		authCodeBucket,
+		refreshTokenBucket,
+		authRequestBucket,
+		passwordBucket,
+		offlineSessionBucket,
+		connectorBucket,
+		keysBucket,
+		deviceRequestBucket,
+		deviceTokenBucket,
+		webhookBucket,
+	}
+)
+
+// keysName is the key of the signing keys in the keys bucket.
+const keysName = "openid-connect-keys"
+
+var _ storage.Storage = (*conn)(nil)
+
+type conn struct {
+	db *bbolt.DB
+}
+
+func (c *conn) Close() error {
+	return c.db.Close()
+}
+
+// create stores a new object, failing if the key is taken.
+func create(db *bbolt.DB, bucket []byte, key string, v interface{}) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(bucket)
+		if b.Get([]byte(key)) != nil {
+			return storage.ErrAlreadyExists
+		}
+		return b.Put([]byte(key), value)
+	})
+}
+
+// get reads an object, returning storage.ErrNotFound if it doesn't exist.
+func get[T any](db *bbolt.DB, bucket []byte, key string) (v T, err error) {
+	err = db.View(func(tx *bbolt.Tx) error {
+		value := tx.Bucket(bucket).Get([]byte(key))
+		if value == nil {
+			return storage.ErrNotFound
+		}
+		return json.Unmarshal(value, &v)
+	})
+	return v, err
+}
+
+// list reads all objects of a bucket.
+func list[T any](db *bbolt.DB, bucket []byte) (objects []T, err error) {
+	err = db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(bucket).ForEach(func(_, value []byte) error {
+			var v T
+			if err := json.Unmarshal(value, &v); err != nil {
+				return err
+			}
+			objects = append(objects, v)
+			return nil
+		})
+	})
+	return objects, err
+}
+
+// update replaces an object with the result of the updater, returning
+// storage.ErrNotFound if it doesn't exist. The updater runs outside of a
+// transaction, so it may use the storage. The object is only replaced if it
+// didn't change in the meantime.
+func update[T any](db *bbolt.DB, bucket []byte, key string, updater func(old T) (T, error)) error {
+	return compareAndSwap(db, bucket, key, func(value []byte) ([]byte, error) {
+		if value == nil {
+			return nil, storage.ErrNotFound
+		}
+		var old T
+		if err := json.Unmarshal(value, &old); err != nil {
+			return nil, err
+		}
+		updated, err := updater(old)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(updated)
+	})
+}
+
+// compareAndSwap replaces the value of a key with the result of fn, failing
+// if the value was changed while fn was running.
+func compareAndSwap(db *bbolt.DB, bucket []byte, key string, fn func(value []byte) ([]byte, error)) error {
+	var current []byte
+	err := db.View(func(tx *bbolt.Tx) error {
+		// Values are only valid during the transaction.
+		if value := tx.Bucket(bucket).Get([]byte(key)); value != nil {
+			current = bytes.Clone(value)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	updated, err := fn(current)
+	if err != nil {
+		return err
+	}
+
+	return db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(bucket)
+		if !bytes.Equal(b.Get([]byte(key)), current) {
+			return fmt.Errorf("failed to update key=%q: %w", key, storage.ErrConflict)
+		}
+		return b.Put([]byte(key), updated)
+	})
+}
+
+// remove deletes an object, returning storage.ErrNotFound if it doesn't exist.
+func remove(db *bbolt.DB, bucket []byte, key string) error {
+	return db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(bucket)
+		if b.Get([]byte(key)) == nil {
+			return storage.ErrNotFound
+		}
+		return b.Delete([]byte(key))
+	})
+}
+
+// collect deletes the expired objects of a bucket.
+func collect[T any](tx *bbolt.Tx, bucket []byte, now time.Time, expiry func(T) time.Time) (int64, error) {
+	var deleted int64
+	cursor := tx.Bucket(bucket).Cursor()
+	for key, value := cursor.First(); key != nil; {
+		var v T
+		if err := json.Unmarshal(value, &v); err != nil {
+			return deleted, err
+		}
+		if !now.After(expiry(v)) {
+			key, value = cursor.Next()
+			continue
+		}
+		// Deleting moves the cursor to the next item.
+		if err := cursor.Delete(); err != nil {
+			return deleted, err
+		}
+		deleted++
+		key, value = cursor.Seek(key)
+	}
+	return deleted, nil
+}
+
+// offlineSessionKey joins the IDs of the user and the connector. Neither
+// contains a NUL byte.
+func offlineSessionKey(userID, connID string) string {
+	return userID + "\x00" + connID
+}
+
+func (c *conn) GarbageCollect(now time.Time, opts storage.GCOptions) (result storage.GCResult, err error) {
+	err = c.db.Update(func(tx *bbolt.Tx) error {
+		var err error
+		if opts.Includes(storage.GCAuthRequests) {
+			result.AuthRequests, err = collect(tx, authRequestBucket, now, func(a storage.AuthRequest) time.Time { return a.Expiry })
+			if err != nil {
+				return err
+			}
+		}
+		if opts.Includes(storage.GCAuthCodes) {
+			result.AuthCodes, err = collect(tx, authCodeBucket, now, func(a storage.AuthCode) time.Time { return a.Expiry })
+			if err != nil {
+				return err
+			}
+		}
+		if opts.Includes(storage.GCDeviceRequests) {
+			result.DeviceRequests, err = collect(tx, deviceRequestBucket, now, func(d storage.DeviceRequest) time.Time { return d.Expiry })
+			if err != nil {
+				return err
+			}
+		}
+		if opts.Includes(storage.GCDeviceTokens) {
+			result.DeviceTokens, err = collect(tx, deviceTokenBucket, now, func(d storage.DeviceToken) time.Time { return d.Expiry })
+		}
+		return err
+	})
+	if err != nil {
+		return storage.GCResult{}, err
+	}
+	return result, nil
+}
+
+func (c *conn) CreateAuthRequest(ctx context.Context, a storage.AuthRequest) error {
+	return create(c.db, authRequestBucket, a.ID, a)
+}
+
+func (c *conn) GetAuthRequest(id string) (storage.AuthRequest, error) {
+	return get[storage.AuthRequest](c.db, authRequestBucket, id)
+}
+
+func (c *conn) UpdateAuthRequest(id string, updater func(a storage.AuthRequest) (storage.AuthRequest, error)) error {
+	return update(c.db, authRequestBucket, id, updater)
+}
+
+func (c *conn) DeleteAuthRequest(id string) error {
+	return remove(c.db, authRequestBucket, id)
+}
+
+func (c *conn) CreateAuthCode(ctx context.Context, a storage.AuthCode) error {
+	return create(c.db, authCodeBucket, a.ID, a)
+}
+
+func (c *conn) GetAuthCode(id string) (storage.AuthCode, error) {
+	return get[storage.AuthCode](c.db, authCodeBucket, id)
+}
+
+func (c *conn) DeleteAuthCode(id string) error {
+	return remove(c.db, authCodeBucket, id)
+}
+
+func (c *conn) CreateRefresh(ctx context.Context, r storage.RefreshToken) error {
+	return create(c.db, refreshTokenBucket, r.ID, r)
+}
+
+func (c *conn) GetRefresh(id string) (storage.RefreshToken, error) {
+	return get[storage.RefreshToken](c.db, refreshTokenBucket, id)
+}
+
+func (c *conn) UpdateRefreshToken(id string, updater func(old storage.RefreshToken) (storage.RefreshToken, error)) error {
+	return update(c.db, refreshTokenBucket, id, updater)
+}
+
+func (c *conn) DeleteRefresh(id string) error {
+	return remove(c.db, refreshTokenBucket, id)
+}
+
+func (c *conn) ListRefreshTokens() ([]storage.RefreshToken, error) {
+	return list[storage.RefreshToken](c.db, refreshTokenBucket)
+}
+
+func (c *conn) CreateClient(ctx context.Context, cli storage.Client) error {
+	return create(c.db, clientBucket, cli.ID, cli)
+}
+
+func (c *conn) GetClient(id string) (storage.Client, error) {
+	return get[storage.Client](c.db, clientBucket, id)
+}
+
+func (c *conn) UpdateClient(id string, updater func(old storage.Client) (storage.Client, error)) error {
+	return update(c.db, clientBucket, id, updater)
+}
+
+func (c *conn) DeleteClient(id string) error {
+	return remove(c.db, clientBucket, id)
+}
+
+func (c *conn) ListClients() ([]storage.Client, error) {
+	return list[storage.Client](c.db, clientBucket)
+}
+
+// Emails are case insensitive, so passwords are keyed by the lower case email.
+
+func (c *conn) CreatePassword(ctx context.Context, p storage.Password) error {
+	return create(c.db, passwordBucket, strings.ToLower(p.Email), p)
+}
+
+func (c *conn) GetPassword(email string) (storage.Password, error) {
+	return get[storage.Password](c.db, passwordBucket, strings.ToLower(email))
+}
+
+func (c *conn) UpdatePassword(email string, updater func(p storage.Password) (storage.Password, error)) error {
+	return update(c.db, passwordBucket, strings.ToLower(email), updater)
+}
+
+func (c *conn) DeletePassword(email string) error {
+	return remove(c.db, passwordBucket, strings.ToLower(email))
+}
+
+func (c *conn) ListPasswords() ([]storage.Password, error) {
+	return list[storage.Password](c.db, passwordBucket)
+}
+
+func (c *conn) CreateOfflineSessions(ctx context.Context, s storage.OfflineSessions) error {
+	return create(c.db, offlineSessionBucket, offlineSessionKey(s.UserID, s.ConnID), s)
+}
+
+func (c *conn) GetOfflineSessions(userID string, connID string) (storage.OfflineSessions, error) {
+	return get[storage.OfflineSessions](c.db, offlineSessionBucket, offlineSessionKey(userID, connID))
+}
+
+func (c *conn) UpdateOfflineSessions(userID string, connID string, updater func(s storage.OfflineSessions) (storage.OfflineSessions, error)) error {
+	return update(c.db, offlineSessionBucket, offlineSessionKey(userID, connID), updater)
+}
+
+func (c *conn) DeleteOfflineSessions(userID string, connID string) error {
+	return remove(c.db, offlineSessionBucket, offlineSessionKey(userID, connID))
+}
+
+func (c *conn) CreateConnector(ctx context.Context, connector storage.Connector) error {
+	return create(c.db, connectorBucket, connector.ID, connector)
+}
+
+func (c *conn) GetConnector(id string) (storage.Connector, error) {
+	return get[storage.Connector](c.db, connectorBucket, id)
+}
+
+func (c *conn) UpdateConnector(id string, updater func(s storage.Connector) (storage.Connector, error)) error {
+	return update(c.db, connectorBucket, id, updater)
+}
+
+func (c *conn) DeleteConnector(id string) error {
+	return remove(c.db, connectorBucket, id)
+}
+
+func (c *conn) ListConnectors() ([]storage.Connector, error) {
+	return list[storage.Connector](c.db, connectorBucket)
+}
+
+func (c *conn) GetKeys() (storage.Keys, error) {
+	keys, err := get[storage.Keys](c.db, keysBucket, keysName)
+	if err == storage.ErrNotFound {
+		// Keys are created by the first update.
+		return storage.Keys{}, nil
+	}
+	return keys, err
+}
+
+func (c *conn) UpdateKeys(updater func(old storage.Keys) (storage.Keys, error)) error {
+	return compareAndSwap(c.db, keysBucket, keysName, func(value []byte) ([]byte, error) {
+		var old storage.Keys
+		if value != nil {
+			if err := json.Unmarshal(value, &old); err != nil {
+				return nil, err
+			}
+		}
+		updated, err := updater(old)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(updated)
+	})
+}
+
+func (c *conn) CreateDeviceRequest(ctx context.Context, d storage.DeviceRequest) error {
+	return create(c.db, deviceRequestBucket, d.UserCode, d)
+}
+
+func (c *conn) GetDeviceRequest(userCode string) (storage.DeviceRequest, error) {
+	return get[storage.DeviceRequest](c.db, deviceRequestBucket, userCode)
+}
+
+func (c *conn) ListDeviceRequests() ([]storage.DeviceRequest, error) {
+	return list[storage.DeviceRequest](c.db, deviceRequestBucket)
+}
+
+func (c *conn) CreateDeviceToken(ctx context.Context, t storage.DeviceToken) error {
+	return create(c.db, deviceTokenBucket, t.DeviceCode, t)
+}
+
+func (c *conn) GetDeviceToken(deviceCode string) (storage.DeviceToken, error) {
+	return get[storage.DeviceToken](c.db, deviceTokenBucket, deviceCode)
+}
+
+func (c *conn) UpdateDeviceToken(deviceCode string, updater func(old storage.DeviceToken) (storage.DeviceToken, error)) error {
+	return update(c.db, deviceTokenBucket, deviceCode, updater)
+}
+
+func (c *conn) CreateWebhook(ctx context.Context, w storage.Webhook) error {
+	return create(c.db, webhookBucket, w.ID, w)
+}
+
+func (c *conn) GetWebhook(id string) (storage.Webhook, error) {
+	return get[storage.Webhook](c.db, webhookBucket, id)
+}
+
+func (c *conn) ListWebhooks() ([]storage.Webhook, error) {
+	return list[storage.Webhook](c.db, webhookBucket)
+}
+
+func (c *conn) DeleteWebhook(id string) error {
+	return remove(c.db, webhookBucket, id)
+}
+
+func (c *conn) UpdateWebhook(id string, updater func(w storage.Webhook) (storage.Webhook, error)) error {
+	return update(c.db, webhookBucket, id, updater)
+}
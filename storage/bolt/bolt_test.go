@@ -0,0 +1,47 @@
+package bolt
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dexidp/dex/storage"
+	"github.com/dexidp/dex/storage/conformance"
+)
+
+func TestStorage(t *testing.T) {
+	newStorage := func() storage.Storage {
+		c := &Config{File: filepath.Join(t.TempDir(), "dex.db")}
+		s, err := c.open()
+		require.NoError(t, err)
+		return s
+	}
+	conformance.RunTests(t, newStorage)
+	conformance.RunTransactionTests(t, newStorage)
+	conformance.RunConcurrencyTests(t, newStorage)
+}
+
+func TestReopen(t *testing.T) {
+	c := &Config{File: filepath.Join(t.TempDir(), "dex.db")}
+
+	s, err := c.open()
+	require.NoError(t, err)
+	client := storage.Client{ID: "app", Secret: "secret", Name: "App"}
+	require.NoError(t, s.CreateClient(context.Background(), client))
+
+	// The file is locked while it's open.
+	_, err = (&Config{File: c.File}).open()
+	require.Error(t, err)
+
+	require.NoError(t, s.Close())
+
+	s, err = c.open()
+	require.NoError(t, err)
+	defer s.Close()
+
+	got, err := s.GetClient("app")
+	require.NoError(t, err)
+	require.Equal(t, client.Secret, got.Secret)
+}
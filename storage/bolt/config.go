@@ -0,0 +1,58 @@
+package bolt
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/dexidp/dex/storage"
+)
+
+// lockTimeout bounds the wait for the lock on the database file, which is
+// held by another process if several instances are started with the same file.
+const lockTimeout = 5 * time.Second
+
+// Config options for an embedded database persisted to a single file.
+//
+// Every write is flushed to disk before it returns, so the database survives
+// crashes, but only a single dex instance can use the file at a time.
+type Config struct {
+	// File is the path of the database. It is created if it doesn't exist.
+	File string `json:"file" yaml:"file"`
+}
+
+// Open creates a new storage implementation backed by the file.
+func (c *Config) Open(logger *slog.Logger) (storage.Storage, error) {
+	return c.open()
+}
+
+func (c *Config) open() (*conn, error) {
+	if c.File == "" {
+		return nil, errors.New("no database file specified")
+	}
+	db, err := bbolt.Open(c.File, 0o600, &bbolt.Options{Timeout: lockTimeout})
+	if err != nil {
+		if errors.Is(err, bbolt.ErrTimeout) {
+			return nil, fmt.Errorf("open %s: file is locked by another process", c.File)
+		}
+		return nil, fmt.Errorf("open %s: %v", c.File, err)
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, bucket := range buckets {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create buckets: %v", err)
+	}
+
+	return &conn{db: db}, nil
+}
@@ -0,0 +1,217 @@
+package storage
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Tests for this code are in the "memory" package, since this package doesn't
+// define a concrete storage implementation.
+
+// cacheEntry is a cached object and the time it expires.
+type cacheEntry[T any] struct {
+	value  T
+	expiry time.Time
+}
+
+// cachedStorage is a storage that caches the objects read on every request:
+// the signing keys, clients and connectors. Writes through this storage
+// invalidate the cached objects right away, writes by other dex instances
+// sharing the backing storage are only seen once the cached objects expire,
+// unless the backing storage notifies them.
+type cachedStorage struct {
+	Storage
+
+	ttl time.Duration
+
+	mu sync.Mutex
+	// generation is incremented on every invalidation, so that a read
+	// started before a write doesn't cache the object as it was before it.
+	generation    uint64
+	keys          *cacheEntry[Keys]
+	clients       map[string]cacheEntry[Client]
+	connectors    map[string]cacheEntry[Connector]
+	connectorList *cacheEntry[[]Connector]
+}
+
+// WithCache caches the signing keys, clients and connectors of the underlying
+// storage for the given duration. If changes isn't nil, objects it notifies
+// changes of are dropped from the cache right away.
+func WithCache(s Storage, ttl time.Duration, changes ChangeNotifier) Storage {
+	c := &cachedStorage{
+		Storage:    s,
+		ttl:        ttl,
+		clients:    make(map[string]cacheEntry[Client]),
+		connectors: make(map[string]cacheEntry[Connector]),
+	}
+	if changes != nil {
+		changes.OnChange(c.applyChange)
+	}
+	return c
+}
+
+// applyChange drops the cached copy of an object changed by any instance.
+func (s *cachedStorage) applyChange(change Change) {
+	s.invalidate(func() {
+		switch change.Kind {
+		case ChangeKeys:
+			s.keys = nil
+		case ChangeClient:
+			delete(s.clients, change.ID)
+		case ChangeConnector:
+			s.invalidateConnector(change.ID)()
+		default:
+			s.keys = nil
+			clear(s.clients)
+			clear(s.connectors)
+			s.connectorList = nil
+		}
+	})
+}
+
+// valid reports if an entry expiring at expiry can still be served.
+func (s *cachedStorage) valid(expiry time.Time) bool {
+	return time.Now().Before(expiry)
+}
+
+// invalidate drops cached objects. The function passed is called with the
+// lock held.
+func (s *cachedStorage) invalidate(drop func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.generation++
+	drop()
+}
+
+// fill caches an object unless the cache was invalidated since generation.
+// The function passed is called with the lock held.
+func (s *cachedStorage) fill(generation uint64, store func(expiry time.Time)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.generation != generation {
+		return
+	}
+	store(time.Now().Add(s.ttl))
+}
+
+func (s *cachedStorage) GetKeys() (Keys, error) {
+	s.mu.Lock()
+	if s.keys != nil && s.valid(s.keys.expiry) {
+		keys := s.keys.value
+		s.mu.Unlock()
+		return keys, nil
+	}
+	generation := s.generation
+	s.mu.Unlock()
+
+	keys, err := s.Storage.GetKeys()
+	if err != nil {
+		return keys, err
+	}
+	s.fill(generation, func(expiry time.Time) {
+		s.keys = &cacheEntry[Keys]{keys, expiry}
+	})
+	return keys, nil
+}
+
+func (s *cachedStorage) UpdateKeys(updater func(old Keys) (Keys, error)) error {
+	defer s.invalidate(func() { s.keys = nil })
+	return s.Storage.UpdateKeys(updater)
+}
+
+func (s *cachedStorage) GetClient(id string) (Client, error) {
+	s.mu.Lock()
+	if e, ok := s.clients[id]; ok && s.valid(e.expiry) {
+		s.mu.Unlock()
+		return e.value, nil
+	}
+	generation := s.generation
+	s.mu.Unlock()
+
+	client, err := s.Storage.GetClient(id)
+	if err != nil {
+		return client, err
+	}
+	s.fill(generation, func(expiry time.Time) {
+		s.clients[id] = cacheEntry[Client]{client, expiry}
+	})
+	return client, nil
+}
+
+func (s *cachedStorage) CreateClient(ctx context.Context, c Client) error {
+	defer s.invalidate(func() { delete(s.clients, c.ID) })
+	return s.Storage.CreateClient(ctx, c)
+}
+
+func (s *cachedStorage) UpdateClient(id string, updater func(old Client) (Client, error)) error {
+	defer s.invalidate(func() { delete(s.clients, id) })
+	return s.Storage.UpdateClient(id, updater)
+}
+
+func (s *cachedStorage) DeleteClient(id string) error {
+	defer s.invalidate(func() { delete(s.clients, id) })
+	return s.Storage.DeleteClient(id)
+}
+
+func (s *cachedStorage) GetConnector(id string) (Connector, error) {
+	s.mu.Lock()
+	if e, ok := s.connectors[id]; ok && s.valid(e.expiry) {
+		s.mu.Unlock()
+		return e.value, nil
+	}
+	generation := s.generation
+	s.mu.Unlock()
+
+	connector, err := s.Storage.GetConnector(id)
+	if err != nil {
+		return connector, err
+	}
+	s.fill(generation, func(expiry time.Time) {
+		s.connectors[id] = cacheEntry[Connector]{connector, expiry}
+	})
+	return connector, nil
+}
+
+func (s *cachedStorage) ListConnectors() ([]Connector, error) {
+	s.mu.Lock()
+	if s.connectorList != nil && s.valid(s.connectorList.expiry) {
+		connectors := slices.Clone(s.connectorList.value)
+		s.mu.Unlock()
+		return connectors, nil
+	}
+	generation := s.generation
+	s.mu.Unlock()
+
+	connectors, err := s.Storage.ListConnectors()
+	if err != nil {
+		return nil, err
+	}
+	s.fill(generation, func(expiry time.Time) {
+		s.connectorList = &cacheEntry[[]Connector]{slices.Clone(connectors), expiry}
+	})
+	return connectors, nil
+}
+
+func (s *cachedStorage) invalidateConnector(id string) func() {
+	return func() {
+		delete(s.connectors, id)
+		s.connectorList = nil
+	}
+}
+
+func (s *cachedStorage) CreateConnector(ctx context.Context, c Connector) error {
+	defer s.invalidate(s.invalidateConnector(c.ID))
+	return s.Storage.CreateConnector(ctx, c)
+}
+
+func (s *cachedStorage) UpdateConnector(id string, updater func(c Connector) (Connector, error)) error {
+	defer s.invalidate(s.invalidateConnector(id))
+	return s.Storage.UpdateConnector(id, updater)
+}
+
+func (s *cachedStorage) DeleteConnector(id string) error {
+	defer s.invalidate(s.invalidateConnector(id))
+	return s.Storage.DeleteConnector(id)
+}
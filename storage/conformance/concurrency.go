@@ -0,0 +1,144 @@
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/dexidp/dex/storage"
+)
+
+// concurrentUpdates is the number of updates racing each other.
+const concurrentUpdates = 10
+
+// RunConcurrencyTests runs a test suite which races concurrent updates of the
+// same object against each other, retried with storage.RetryOnConflict. Every
+// update has to either be stored or fail with storage.ErrConflict, updates
+// must not overwrite each other. The storage returned by newStorage will be
+// closed at the end of each test run.
+//
+// Unlike RunTransactionTests, updates aren't nested, so storages which
+// serialize all updates pass as well.
+func RunConcurrencyTests(t *testing.T, newStorage func() storage.Storage) {
+	runTests(t, newStorage, []subTest{
+		{"AuthRequestConcurrentUpdates", testAuthRequestConcurrentUpdates},
+		{"RefreshTokenConcurrentUpdates", testRefreshTokenConcurrentUpdates},
+	})
+}
+
+// raceUpdates calls update concurrently with the numbers 0 to
+// concurrentUpdates-1 and returns the numbers of the updates which succeeded.
+func raceUpdates(t *testing.T, update func(n int) error) []int {
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded []int
+	)
+	for n := 0; n < concurrentUpdates; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := storage.RetryOnConflict(context.Background(), func() error { return update(n) })
+			if err != nil {
+				if !errors.Is(err, storage.ErrConflict) {
+					t.Errorf("update %d: expected success or storage.ErrConflict, got %v", n, err)
+				}
+				return
+			}
+			mu.Lock()
+			succeeded = append(succeeded, n)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if len(succeeded) == 0 {
+		t.Errorf("none of %d concurrent updates succeeded", concurrentUpdates)
+	}
+	slices.Sort(succeeded)
+	return succeeded
+}
+
+// updateScopes returns the numbers of the updates stored in scopes.
+func updateScopes(scopes []string) []int {
+	var updates []int
+	for _, scope := range scopes {
+		var n int
+		if _, err := fmt.Sscanf(scope, "update-%d", &n); err == nil {
+			updates = append(updates, n)
+		}
+	}
+	slices.Sort(updates)
+	return updates
+}
+
+func testAuthRequestConcurrentUpdates(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	a := storage.AuthRequest{
+		ID:            storage.NewID(),
+		ClientID:      "foobar",
+		ResponseTypes: []string{"code"},
+		Scopes:        []string{"openid"},
+		RedirectURI:   "https://localhost:80/callback",
+		Expiry:        neverExpire,
+		HMACKey:       []byte("hmac_key"),
+	}
+	if err := s.CreateAuthRequest(ctx, a); err != nil {
+		t.Fatalf("create auth request: %v", err)
+	}
+
+	succeeded := raceUpdates(t, func(n int) error {
+		return s.UpdateAuthRequest(a.ID, func(old storage.AuthRequest) (storage.AuthRequest, error) {
+			old.Scopes = append(old.Scopes, fmt.Sprintf("update-%d", n))
+			return old, nil
+		})
+	})
+
+	got, err := s.GetAuthRequest(a.ID)
+	if err != nil {
+		t.Fatalf("get auth request: %v", err)
+	}
+	if stored := updateScopes(got.Scopes); !slices.Equal(stored, succeeded) {
+		t.Errorf("expected the updates %v to be stored, got %v", succeeded, stored)
+	}
+}
+
+func testRefreshTokenConcurrentUpdates(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	r := storage.RefreshToken{
+		ID:          storage.NewID(),
+		Token:       "bar",
+		Nonce:       "foo",
+		ClientID:    "client_id",
+		ConnectorID: "client_secret",
+		Scopes:      []string{"openid"},
+		CreatedAt:   neverExpire.AddDate(-100, 0, 0),
+		LastUsed:    neverExpire.AddDate(-100, 0, 0),
+		Claims: storage.Claims{
+			UserID:   "1",
+			Username: "jane",
+			Email:    "jane.doe@example.com",
+		},
+	}
+	if err := s.CreateRefresh(ctx, r); err != nil {
+		t.Fatalf("create refresh token: %v", err)
+	}
+
+	succeeded := raceUpdates(t, func(n int) error {
+		return s.UpdateRefreshToken(r.ID, func(old storage.RefreshToken) (storage.RefreshToken, error) {
+			old.Scopes = append(old.Scopes, fmt.Sprintf("update-%d", n))
+			return old, nil
+		})
+	})
+
+	got, err := s.GetRefresh(r.ID)
+	if err != nil {
+		t.Fatalf("get refresh token: %v", err)
+	}
+	if stored := updateScopes(got.Scopes); !slices.Equal(stored, succeeded) {
+		t.Errorf("expected the updates %v to be stored, got %v", succeeded, stored)
+	}
+}
@@ -48,9 +48,11 @@ func RunTests(t *testing.T, newStorage func() storage.Storage) {
 		{"OfflineSessionCRUD", testOfflineSessionCRUD},
 		{"ConnectorCRUD", testConnectorCRUD},
 		{"GarbageCollection", testGC},
+		{"GarbageCollectionOptions", testGCOptions},
 		{"TimezoneSupport", testTimezones},
 		{"DeviceRequestCRUD", testDeviceRequestCRUD},
 		{"DeviceTokenCRUD", testDeviceTokenCRUD},
+		{"WebhookCRUD", testWebhookCRUD},
 	})
 }
 
@@ -106,12 +108,22 @@ func testAuthRequestCRUD(t *testing.T, s storage.Storage) {
 			Email:         "jane.doe@example.com",
 			EmailVerified: true,
 			Groups:        []string{"a", "b"},
+			CustomClaims:  map[string]interface{}{"department": "engineering"},
+			ClaimSources: map[string]storage.ClaimSource{
+				"src1": {Claims: []string{"groups"}, Endpoint: "https://graph.example.com/groups", AccessToken: "token"},
+			},
 		},
-		PKCE:    codeChallenge,
-		HMACKey: []byte("hmac_key"),
+		ClaimsRequest: []byte(`{"id_token":{"email":{"essential":true}}}`),
+		PKCE:          codeChallenge,
+		HMACKey:       []byte("hmac_key"),
 	}
 
-	identity := storage.Claims{Email: "foobar"}
+	identity := storage.Claims{
+		Email: "foobar",
+		ClaimSources: map[string]storage.ClaimSource{
+			"src1": {Claims: []string{"groups"}, JWT: "header.payload.signature"},
+		},
+	}
 
 	if err := s.CreateAuthRequest(ctx, a1); err != nil {
 		t.Fatalf("failed creating auth request: %v", err)
@@ -168,6 +180,10 @@ func testAuthRequestCRUD(t *testing.T, s storage.Storage) {
 		t.Fatalf("storage does not support PKCE, wanted challenge=%#v got %#v", codeChallenge, got.PKCE)
 	}
 
+	if string(got.ClaimsRequest) != string(a1.ClaimsRequest) {
+		t.Fatalf("storage does not support claims requests, wanted %q got %q", a1.ClaimsRequest, got.ClaimsRequest)
+	}
+
 	if err := s.DeleteAuthRequest(a1.ID); err != nil {
 		t.Fatalf("failed to delete auth request: %v", err)
 	}
@@ -201,7 +217,13 @@ func testAuthCodeCRUD(t *testing.T, s storage.Storage) {
 			Email:         "jane.doe@example.com",
 			EmailVerified: true,
 			Groups:        []string{"a", "b"},
+			CustomClaims:  map[string]interface{}{"department": "engineering"},
+			ClaimSources: map[string]storage.ClaimSource{
+				"src1": {Claims: []string{"groups"}, Endpoint: "https://graph.example.com/groups", AccessToken: "token"},
+			},
 		},
+		ClaimsRequest: []byte(`{"id_token":{"email":{"essential":true}}}`),
+		Issuer:        "https://tenant.example.com/dex",
 	}
 
 	if err := s.CreateAuthCode(ctx, a1); err != nil {
@@ -267,6 +289,10 @@ func testClientCRUD(t *testing.T, s storage.Storage) {
 		RedirectURIs: []string{"foo://bar.com/", "https://auth.example.com"},
 		Name:         "dex client",
 		LogoURL:      "https://goo.gl/JIyzIC",
+		CreatedAt:    time.Now().UTC().Round(time.Millisecond),
+
+		PolicyURL:         "https://example.com/privacy",
+		TermsOfServiceURL: "https://example.com/terms",
 	}
 	err := s.DeleteClient(id1)
 	mustBeErrNotFound(t, "client", err)
@@ -306,14 +332,55 @@ func testClientCRUD(t *testing.T, s storage.Storage) {
 	getAndCompare(id1, c1)
 
 	newSecret := "barfoo"
+	disableRotation := true
+	newPolicy := &storage.RefreshTokenPolicy{
+		DisableRotation:  &disableRotation,
+		AbsoluteLifetime: "24h",
+	}
+	newTokenExpiry := &storage.TokenExpiry{IDTokens: "10m", AccessTokens: "5m"}
+	newGroupsFilter := &storage.GroupsFilter{
+		AllowedGroups: []string{"admins"},
+		Prefixes:      []string{"app-"},
+		Patterns:      []string{"team-[a-z]+"},
+	}
+	newRedirectURIPatterns := []string{"https://*.preview.example.com/callback"}
+	newAllowedOrigins := []string{"https://app.example.com"}
+	newLastUsed := time.Now().UTC().Round(time.Millisecond)
+	newPreviousSecret := &storage.PreviousSecret{
+		Hash:   []byte("$2a$10$33EMT0cVYVlPy6WAMCLsceLYjWhuHpbz5yuZxu/GAFj03J9Lytjuy"),
+		Expiry: time.Now().UTC().Round(time.Millisecond),
+	}
+	newClaimsTransforms := []storage.ClaimsTransform{
+		{Claim: "roles", Expression: `identity.groups.filter(g, g.startsWith("role-"))`},
+	}
 	err = s.UpdateClient(id1, func(old storage.Client) (storage.Client, error) {
 		old.Secret = newSecret
+		old.RefreshTokenPolicy = newPolicy
+		old.TokenExpiry = newTokenExpiry
+		old.GroupsFilter = newGroupsFilter
+		old.ClaimsTransforms = newClaimsTransforms
+		old.RedirectURIPatterns = newRedirectURIPatterns
+		old.AllowedOrigins = newAllowedOrigins
+		old.ForwardClaimSourceTokens = true
+		old.PreviousSecret = newPreviousSecret
+		old.LastUsed = newLastUsed
+		old.PolicyURL = "https://example.com/privacy-v2"
 		return old, nil
 	})
 	if err != nil {
 		t.Errorf("update client: %v", err)
 	}
 	c1.Secret = newSecret
+	c1.RefreshTokenPolicy = newPolicy
+	c1.TokenExpiry = newTokenExpiry
+	c1.GroupsFilter = newGroupsFilter
+	c1.ClaimsTransforms = newClaimsTransforms
+	c1.RedirectURIPatterns = newRedirectURIPatterns
+	c1.AllowedOrigins = newAllowedOrigins
+	c1.ForwardClaimSourceTokens = true
+	c1.PreviousSecret = newPreviousSecret
+	c1.LastUsed = newLastUsed
+	c1.PolicyURL = "https://example.com/privacy-v2"
 	getAndCompare(id1, c1)
 
 	if err := s.DeleteClient(id1); err != nil {
@@ -347,8 +414,14 @@ func testRefreshTokenCRUD(t *testing.T, s storage.Storage) {
 			Email:         "jane.doe@example.com",
 			EmailVerified: true,
 			Groups:        []string{"a", "b"},
+			CustomClaims:  map[string]interface{}{"department": "engineering"},
+			ClaimSources: map[string]storage.ClaimSource{
+				"src1": {Claims: []string{"groups"}, Endpoint: "https://graph.example.com/groups", AccessToken: "token"},
+			},
 		},
+		ClaimsRequest: []byte(`{"id_token":{"email":{"essential":true}}}`),
 		ConnectorData: []byte(`{"some":"data"}`),
+		Issuer:        "https://tenant.example.com/dex",
 	}
 	if err := s.CreateRefresh(ctx, refresh); err != nil {
 		t.Fatalf("create refresh token: %v", err)
@@ -475,10 +548,11 @@ func testPasswordCRUD(t *testing.T, s storage.Storage) {
 	}
 
 	password2 := storage.Password{
-		Email:    "john@example.com",
-		Hash:     passwordHash2,
-		Username: "john",
-		UserID:   "barfoo",
+		Email:         "john@example.com",
+		Hash:          passwordHash2,
+		Username:      "john",
+		UserID:        "barfoo",
+		EmailVerified: true,
 	}
 	if err := s.CreatePassword(ctx, password2); err != nil {
 		t.Fatalf("create password token: %v", err)
@@ -498,14 +572,21 @@ func testPasswordCRUD(t *testing.T, s storage.Storage) {
 	getAndCompare("jane@example.com", password1)
 	getAndCompare("JANE@example.com", password1) // Emails should be case insensitive
 
+	lockedUntil := time.Now().UTC().Round(time.Millisecond)
 	if err := s.UpdatePassword(password1.Email, func(old storage.Password) (storage.Password, error) {
 		old.Username = "jane doe"
+		old.EmailVerified = true
+		old.FailedAttempts = 5
+		old.LockedUntil = lockedUntil
 		return old, nil
 	}); err != nil {
 		t.Fatalf("failed to update auth request: %v", err)
 	}
 
 	password1.Username = "jane doe"
+	password1.EmailVerified = true
+	password1.FailedAttempts = 5
+	password1.LockedUntil = lockedUntil
 	getAndCompare("jane@example.com", password1)
 
 	var passwordList []storage.Password
@@ -639,6 +720,12 @@ func testConnectorCRUD(t *testing.T, s storage.Storage) {
 		Type:   "Mock",
 		Name:   "Mock",
 		Config: config2,
+		Display: &storage.ConnectorDisplay{
+			Order:       2,
+			Group:       "Partners",
+			IconURL:     "https://example.com/icon.svg",
+			ButtonColor: "#2f2f2f",
+		},
 	}
 
 	if err := s.CreateConnector(ctx, c2); err != nil {
@@ -662,12 +749,14 @@ func testConnectorCRUD(t *testing.T, s storage.Storage) {
 
 	if err := s.UpdateConnector(c1.ID, func(old storage.Connector) (storage.Connector, error) {
 		old.Type = "oidc"
+		old.Display = &storage.ConnectorDisplay{Hidden: true}
 		return old, nil
 	}); err != nil {
 		t.Fatalf("failed to update Connector: %v", err)
 	}
 
 	c1.Type = "oidc"
+	c1.Display = &storage.ConnectorDisplay{Hidden: true}
 	getAndCompare(id1, c1)
 
 	connectorList := []storage.Connector{c1, c2}
@@ -702,6 +791,83 @@ func testConnectorCRUD(t *testing.T, s storage.Storage) {
 	mustBeErrNotFound(t, "connector", err)
 }
 
+func testWebhookCRUD(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	w1 := storage.Webhook{
+		ID:        storage.NewID(),
+		URL:       "https://hooks.example.com/dex",
+		Secret:    "secret",
+		Events:    []string{"login_succeeded", "refresh_revoked"},
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	}
+	err := s.DeleteWebhook(w1.ID)
+	mustBeErrNotFound(t, "webhook", err)
+
+	if err := s.CreateWebhook(ctx, w1); err != nil {
+		t.Fatalf("create webhook with ID = %s: %v", w1.ID, err)
+	}
+
+	// Attempt to create same Webhook twice.
+	err = s.CreateWebhook(ctx, w1)
+	mustBeErrAlreadyExists(t, "webhook", err)
+
+	w2 := storage.Webhook{
+		ID:          storage.NewID(),
+		URL:         "https://audit.example.com/events",
+		Secret:      "other secret",
+		RetryPolicy: &storage.WebhookRetryPolicy{MaxAttempts: 3, Backoff: "10s"},
+		CreatedAt:   time.Now().UTC().Round(time.Millisecond),
+	}
+	if err := s.CreateWebhook(ctx, w2); err != nil {
+		t.Fatalf("create webhook with ID = %s: %v", w2.ID, err)
+	}
+
+	getAndCompare := func(id string, want storage.Webhook) {
+		got, err := s.GetWebhook(id)
+		if err != nil {
+			t.Errorf("get webhook: %v", err)
+			return
+		}
+		if diff := pretty.Compare(want, got); diff != "" {
+			t.Errorf("webhook retrieved from storage did not match: %s", diff)
+		}
+	}
+
+	getAndCompare(w1.ID, w1)
+	getAndCompare(w2.ID, w2)
+
+	if err := s.UpdateWebhook(w1.ID, func(old storage.Webhook) (storage.Webhook, error) {
+		old.Events = []string{"client_modified"}
+		old.RetryPolicy = &storage.WebhookRetryPolicy{MaxAttempts: 1}
+		return old, nil
+	}); err != nil {
+		t.Fatalf("failed to update webhook: %v", err)
+	}
+
+	w1.Events = []string{"client_modified"}
+	w1.RetryPolicy = &storage.WebhookRetryPolicy{MaxAttempts: 1}
+	getAndCompare(w1.ID, w1)
+
+	webhooks, err := s.ListWebhooks()
+	if err != nil {
+		t.Fatalf("list webhooks: %v", err)
+	}
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].URL > webhooks[j].URL })
+	if diff := pretty.Compare([]storage.Webhook{w1, w2}, webhooks); diff != "" {
+		t.Errorf("webhook list retrieved from storage did not match: %s", diff)
+	}
+
+	if err := s.DeleteWebhook(w1.ID); err != nil {
+		t.Fatalf("failed to delete webhook: %v", err)
+	}
+	if err := s.DeleteWebhook(w2.ID); err != nil {
+		t.Fatalf("failed to delete webhook: %v", err)
+	}
+
+	_, err = s.GetWebhook(w1.ID)
+	mustBeErrNotFound(t, "webhook", err)
+}
+
 func testKeysCRUD(t *testing.T, s storage.Storage) {
 	updateAndCompare := func(k storage.Keys) {
 		err := s.UpdateKeys(func(oldKeys storage.Keys) (storage.Keys, error) {
@@ -747,8 +913,18 @@ func testKeysCRUD(t *testing.T, s storage.Storage) {
 		},
 	}
 
+	keys3 := keys2
+	keys3.IssuerKeys = map[string]storage.Keys{
+		"https://tenant.example.com/dex": {
+			SigningKey:    jsonWebKeys[1].Private,
+			SigningKeyPub: jsonWebKeys[1].Public,
+			NextRotation:  n.Add(time.Hour),
+		},
+	}
+
 	updateAndCompare(keys1)
 	updateAndCompare(keys2)
+	updateAndCompare(keys3)
 }
 
 func testGC(t *testing.T, s storage.Storage) {
@@ -786,7 +962,7 @@ func testGC(t *testing.T, s storage.Storage) {
 	}
 
 	for _, tz := range []*time.Location{time.UTC, est, pst} {
-		result, err := s.GarbageCollect(expiry.Add(-time.Hour).In(tz))
+		result, err := s.GarbageCollect(expiry.Add(-time.Hour).In(tz), storage.GCOptions{})
 		if err != nil {
 			t.Errorf("garbage collection failed: %v", err)
 		} else if result.AuthCodes != 0 || result.AuthRequests != 0 {
@@ -797,7 +973,7 @@ func testGC(t *testing.T, s storage.Storage) {
 		}
 	}
 
-	if r, err := s.GarbageCollect(expiry.Add(time.Hour)); err != nil {
+	if r, err := s.GarbageCollect(expiry.Add(time.Hour), storage.GCOptions{}); err != nil {
 		t.Errorf("garbage collection failed: %v", err)
 	} else if r.AuthCodes != 1 {
 		t.Errorf("expected to garbage collect 1 objects, got %d", r.AuthCodes)
@@ -837,7 +1013,7 @@ func testGC(t *testing.T, s storage.Storage) {
 	}
 
 	for _, tz := range []*time.Location{time.UTC, est, pst} {
-		result, err := s.GarbageCollect(expiry.Add(-time.Hour).In(tz))
+		result, err := s.GarbageCollect(expiry.Add(-time.Hour).In(tz), storage.GCOptions{})
 		if err != nil {
 			t.Errorf("garbage collection failed: %v", err)
 		} else if result.AuthCodes != 0 || result.AuthRequests != 0 {
@@ -848,7 +1024,7 @@ func testGC(t *testing.T, s storage.Storage) {
 		}
 	}
 
-	if r, err := s.GarbageCollect(expiry.Add(time.Hour)); err != nil {
+	if r, err := s.GarbageCollect(expiry.Add(time.Hour), storage.GCOptions{}); err != nil {
 		t.Errorf("garbage collection failed: %v", err)
 	} else if r.AuthRequests != 1 {
 		t.Errorf("expected to garbage collect 1 objects, got %d", r.AuthRequests)
@@ -874,7 +1050,7 @@ func testGC(t *testing.T, s storage.Storage) {
 	}
 
 	for _, tz := range []*time.Location{time.UTC, est, pst} {
-		result, err := s.GarbageCollect(expiry.Add(-time.Hour).In(tz))
+		result, err := s.GarbageCollect(expiry.Add(-time.Hour).In(tz), storage.GCOptions{})
 		if err != nil {
 			t.Errorf("garbage collection failed: %v", err)
 		} else if result.DeviceRequests != 0 {
@@ -884,7 +1060,7 @@ func testGC(t *testing.T, s storage.Storage) {
 			t.Errorf("expected to be able to get auth request after GC: %v", err)
 		}
 	}
-	if r, err := s.GarbageCollect(expiry.Add(time.Hour)); err != nil {
+	if r, err := s.GarbageCollect(expiry.Add(time.Hour), storage.GCOptions{}); err != nil {
 		t.Errorf("garbage collection failed: %v", err)
 	} else if r.DeviceRequests != 1 {
 		t.Errorf("expected to garbage collect 1 device request, got %d", r.DeviceRequests)
@@ -914,7 +1090,7 @@ func testGC(t *testing.T, s storage.Storage) {
 	}
 
 	for _, tz := range []*time.Location{time.UTC, est, pst} {
-		result, err := s.GarbageCollect(expiry.Add(-time.Hour).In(tz))
+		result, err := s.GarbageCollect(expiry.Add(-time.Hour).In(tz), storage.GCOptions{})
 		if err != nil {
 			t.Errorf("garbage collection failed: %v", err)
 		} else if result.DeviceTokens != 0 {
@@ -924,7 +1100,7 @@ func testGC(t *testing.T, s storage.Storage) {
 			t.Errorf("expected to be able to get device token after GC: %v", err)
 		}
 	}
-	if r, err := s.GarbageCollect(expiry.Add(time.Hour)); err != nil {
+	if r, err := s.GarbageCollect(expiry.Add(time.Hour), storage.GCOptions{}); err != nil {
 		t.Errorf("garbage collection failed: %v", err)
 	} else if r.DeviceTokens != 1 {
 		t.Errorf("expected to garbage collect 1 device token, got %d", r.DeviceTokens)
@@ -937,6 +1113,76 @@ func testGC(t *testing.T, s storage.Storage) {
 	}
 }
 
+func testGCOptions(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	expiry := time.Now()
+
+	var deviceCodes []string
+	for i := 0; i < 3; i++ {
+		dt := storage.DeviceToken{
+			DeviceCode:      storage.NewID(),
+			Status:          "pending",
+			Expiry:          expiry,
+			LastRequestTime: expiry,
+		}
+		if err := s.CreateDeviceToken(ctx, dt); err != nil {
+			t.Fatalf("failed creating device token: %v", err)
+		}
+		deviceCodes = append(deviceCodes, dt.DeviceCode)
+	}
+
+	a := storage.AuthRequest{
+		ID:            storage.NewID(),
+		ClientID:      "foobar",
+		ResponseTypes: []string{"code"},
+		Scopes:        []string{"openid"},
+		RedirectURI:   "https://localhost:80/callback",
+		Expiry:        expiry,
+		HMACKey:       []byte("hmac_key"),
+	}
+	if err := s.CreateAuthRequest(ctx, a); err != nil {
+		t.Fatalf("failed creating auth request: %v", err)
+	}
+
+	opts := storage.GCOptions{
+		Resources: []storage.GCResource{storage.GCDeviceTokens},
+		BatchSize: 2,
+	}
+	// Storages may only delete a batch of each resource per run.
+	var deleted int64
+	for i := 0; i < len(deviceCodes); i++ {
+		r, err := s.GarbageCollect(expiry.Add(time.Hour), opts)
+		if err != nil {
+			t.Fatalf("garbage collection failed: %v", err)
+		}
+		if r.AuthRequests != 0 {
+			t.Errorf("expected auth requests not to be garbage collected, got %d", r.AuthRequests)
+		}
+		if r.IsEmpty() {
+			break
+		}
+		deleted += r.DeviceTokens
+	}
+	if deleted != int64(len(deviceCodes)) {
+		t.Errorf("expected to garbage collect %d device tokens, got %d", len(deviceCodes), deleted)
+	}
+
+	for _, deviceCode := range deviceCodes {
+		if _, err := s.GetDeviceToken(deviceCode); err != storage.ErrNotFound {
+			t.Errorf("expected device token to be GC'd, got %v", err)
+		}
+	}
+	if _, err := s.GetAuthRequest(a.ID); err != nil {
+		t.Errorf("expected to be able to get auth request after GC of device tokens: %v", err)
+	}
+
+	if r, err := s.GarbageCollect(expiry.Add(time.Hour), storage.GCOptions{}); err != nil {
+		t.Errorf("garbage collection failed: %v", err)
+	} else if r.AuthRequests != 1 {
+		t.Errorf("expected to garbage collect 1 auth request, got %d", r.AuthRequests)
+	}
+}
+
 // testTimezones tests that backends either fully support timezones or
 // do the correct standardization.
 func testTimezones(t *testing.T, s storage.Storage) {
@@ -1010,6 +1256,12 @@ func testDeviceRequestCRUD(t *testing.T, s storage.Storage) {
 
 	require.Equal(t, d1, got)
 
+	reqs, err := s.ListDeviceRequests()
+	if err != nil {
+		t.Fatalf("failed to list device requests: %v", err)
+	}
+	require.Contains(t, reqs, d1)
+
 	// No manual deletes for device requests, will be handled by garbage collection routines
 	// see testGC
 }
@@ -12,6 +12,7 @@ func (d *Database) CreateAuthCode(ctx context.Context, code storage.AuthCode) er
 		SetID(code.ID).
 		SetClientID(code.ClientID).
 		SetScopes(code.Scopes).
+		SetClaimsRequest(code.ClaimsRequest).
 		SetRedirectURI(code.RedirectURI).
 		SetNonce(code.Nonce).
 		SetClaimsUserID(code.Claims.UserID).
@@ -20,12 +21,15 @@ func (d *Database) CreateAuthCode(ctx context.Context, code storage.AuthCode) er
 		SetClaimsUsername(code.Claims.Username).
 		SetClaimsPreferredUsername(code.Claims.PreferredUsername).
 		SetClaimsGroups(code.Claims.Groups).
+		SetClaimsSources(code.Claims.ClaimSources).
+		SetClaimsCustom(code.Claims.CustomClaims).
 		SetCodeChallenge(code.PKCE.CodeChallenge).
 		SetCodeChallengeMethod(code.PKCE.CodeChallengeMethod).
 		// Save utc time into database because ent doesn't support comparing dates with different timezones
 		SetExpiry(code.Expiry.UTC()).
 		SetConnectorID(code.ConnectorID).
 		SetConnectorData(code.ConnectorData).
+		SetIssuer(code.Issuer).
 		Save(ctx)
 	if err != nil {
 		return convertDBError("create auth code: %w", err)
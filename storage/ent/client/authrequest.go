@@ -13,6 +13,7 @@ func (d *Database) CreateAuthRequest(ctx context.Context, authRequest storage.Au
 		SetID(authRequest.ID).
 		SetClientID(authRequest.ClientID).
 		SetScopes(authRequest.Scopes).
+		SetClaimsRequest(authRequest.ClaimsRequest).
 		SetResponseTypes(authRequest.ResponseTypes).
 		SetRedirectURI(authRequest.RedirectURI).
 		SetState(authRequest.State).
@@ -25,6 +26,8 @@ func (d *Database) CreateAuthRequest(ctx context.Context, authRequest storage.Au
 		SetClaimsUsername(authRequest.Claims.Username).
 		SetClaimsPreferredUsername(authRequest.Claims.PreferredUsername).
 		SetClaimsGroups(authRequest.Claims.Groups).
+		SetClaimsSources(authRequest.Claims.ClaimSources).
+		SetClaimsCustom(authRequest.Claims.CustomClaims).
 		SetCodeChallenge(authRequest.PKCE.CodeChallenge).
 		SetCodeChallengeMethod(authRequest.PKCE.CodeChallengeMethod).
 		// Save utc time into database because ent doesn't support comparing dates with different timezones
@@ -77,6 +80,7 @@ func (d *Database) UpdateAuthRequest(id string, updater func(old storage.AuthReq
 	_, err = tx.AuthRequest.UpdateOneID(newAuthRequest.ID).
 		SetClientID(newAuthRequest.ClientID).
 		SetScopes(newAuthRequest.Scopes).
+		SetClaimsRequest(newAuthRequest.ClaimsRequest).
 		SetResponseTypes(newAuthRequest.ResponseTypes).
 		SetRedirectURI(newAuthRequest.RedirectURI).
 		SetState(newAuthRequest.State).
@@ -89,6 +93,8 @@ func (d *Database) UpdateAuthRequest(id string, updater func(old storage.AuthReq
 		SetClaimsUsername(newAuthRequest.Claims.Username).
 		SetClaimsPreferredUsername(newAuthRequest.Claims.PreferredUsername).
 		SetClaimsGroups(newAuthRequest.Claims.Groups).
+		SetClaimsSources(newAuthRequest.Claims.ClaimSources).
+		SetClaimsCustom(newAuthRequest.Claims.CustomClaims).
 		SetCodeChallenge(newAuthRequest.PKCE.CodeChallenge).
 		SetCodeChallengeMethod(newAuthRequest.PKCE.CodeChallengeMethod).
 		// Save utc time into database because ent doesn't support comparing dates with different timezones
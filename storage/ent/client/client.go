@@ -2,20 +2,53 @@ package client
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/dexidp/dex/storage"
+	"github.com/dexidp/dex/storage/ent/db/oauth2client"
 )
 
 // CreateClient saves provided oauth2 client settings into the database.
 func (d *Database) CreateClient(ctx context.Context, client storage.Client) error {
+	err := d.createClient(ctx, client)
+	if errors.Is(err, storage.ErrAlreadyExists) {
+		// A soft deleted client is replaced by a new one with the same id.
+		n, derr := d.client.OAuth2Client.Delete().
+			Where(oauth2client.ID(client.ID), oauth2client.DeletedAtNotNil()).
+			Exec(ctx)
+		if derr != nil {
+			return convertDBError("create client purge deleted: %w", derr)
+		}
+		if n > 0 {
+			err = d.createClient(ctx, client)
+		}
+	}
+	return err
+}
+
+func (d *Database) createClient(ctx context.Context, client storage.Client) error {
 	_, err := d.client.OAuth2Client.Create().
 		SetID(client.ID).
 		SetName(client.Name).
 		SetSecret(client.Secret).
 		SetPublic(client.Public).
 		SetLogoURL(client.LogoURL).
+		SetPolicyURL(client.PolicyURL).
+		SetTermsOfServiceURL(client.TermsOfServiceURL).
+		SetRefreshTokenPolicy(client.RefreshTokenPolicy).
+		SetTokenExpiry(client.TokenExpiry).
+		SetGroupsFilter(client.GroupsFilter).
+		SetClaimsTransforms(client.ClaimsTransforms).
+		SetRedirectURIPatterns(client.RedirectURIPatterns).
+		SetAllowedOrigins(client.AllowedOrigins).
+		SetForwardClaimSourceTokens(client.ForwardClaimSourceTokens).
+		SetPreviousSecret(client.PreviousSecret).
+		SetCreatedAt(client.CreatedAt).
+		SetLastUsed(client.LastUsed).
 		SetRedirectUris(client.RedirectURIs).
 		SetTrustedPeers(client.TrustedPeers).
+		SetCreatedBy(storage.ActorFromContext(ctx)).
 		Save(ctx)
 	if err != nil {
 		return convertDBError("create oauth2 client: %w", err)
@@ -25,7 +58,9 @@ func (d *Database) CreateClient(ctx context.Context, client storage.Client) erro
 
 // ListClients extracts an array of oauth2 clients from the database.
 func (d *Database) ListClients() ([]storage.Client, error) {
-	clients, err := d.client.OAuth2Client.Query().All(context.TODO())
+	clients, err := d.client.OAuth2Client.Query().
+		Where(oauth2client.DeletedAtIsNil()).
+		All(context.TODO())
 	if err != nil {
 		return nil, convertDBError("list clients: %w", err)
 	}
@@ -39,19 +74,38 @@ func (d *Database) ListClients() ([]storage.Client, error) {
 
 // GetClient extracts an oauth2 client from the database by id.
 func (d *Database) GetClient(id string) (storage.Client, error) {
-	client, err := d.client.OAuth2Client.Get(context.TODO(), id)
+	client, err := d.client.OAuth2Client.Query().
+		Where(oauth2client.ID(id), oauth2client.DeletedAtIsNil()).
+		Only(context.TODO())
 	if err != nil {
 		return storage.Client{}, convertDBError("get client: %w", err)
 	}
 	return toStorageClient(client), nil
 }
 
-// DeleteClient deletes an oauth2 client from the database by id.
+// DeleteClient deletes an oauth2 client from the database by id, or marks it
+// as deleted if soft deletes are enabled.
 func (d *Database) DeleteClient(id string) error {
-	err := d.client.OAuth2Client.DeleteOneID(id).Exec(context.TODO())
+	var (
+		n   int
+		err error
+	)
+	if d.softDelete {
+		n, err = d.client.OAuth2Client.Update().
+			Where(oauth2client.ID(id), oauth2client.DeletedAtIsNil()).
+			SetDeletedAt(time.Now().UTC()).
+			Save(context.TODO())
+	} else {
+		n, err = d.client.OAuth2Client.Delete().
+			Where(oauth2client.ID(id), oauth2client.DeletedAtIsNil()).
+			Exec(context.TODO())
+	}
 	if err != nil {
 		return convertDBError("delete client: %w", err)
 	}
+	if n == 0 {
+		return storage.ErrNotFound
+	}
 	return nil
 }
 
@@ -62,7 +116,9 @@ func (d *Database) UpdateClient(id string, updater func(old storage.Client) (sto
 		return convertDBError("update client tx: %w", err)
 	}
 
-	client, err := tx.OAuth2Client.Get(context.TODO(), id)
+	client, err := tx.OAuth2Client.Query().
+		Where(oauth2client.ID(id), oauth2client.DeletedAtIsNil()).
+		Only(context.TODO())
 	if err != nil {
 		return rollback(tx, "update client database: %w", err)
 	}
@@ -77,8 +133,21 @@ func (d *Database) UpdateClient(id string, updater func(old storage.Client) (sto
 		SetSecret(newClient.Secret).
 		SetPublic(newClient.Public).
 		SetLogoURL(newClient.LogoURL).
+		SetPolicyURL(newClient.PolicyURL).
+		SetTermsOfServiceURL(newClient.TermsOfServiceURL).
+		SetRefreshTokenPolicy(newClient.RefreshTokenPolicy).
+		SetTokenExpiry(newClient.TokenExpiry).
+		SetGroupsFilter(newClient.GroupsFilter).
+		SetClaimsTransforms(newClient.ClaimsTransforms).
+		SetRedirectURIPatterns(newClient.RedirectURIPatterns).
+		SetAllowedOrigins(newClient.AllowedOrigins).
+		SetForwardClaimSourceTokens(newClient.ForwardClaimSourceTokens).
+		SetPreviousSecret(newClient.PreviousSecret).
+		SetCreatedAt(newClient.CreatedAt).
+		SetLastUsed(newClient.LastUsed).
 		SetRedirectUris(newClient.RedirectURIs).
 		SetTrustedPeers(newClient.TrustedPeers).
+		SetUpdatedAt(time.Now().UTC()).
 		Save(context.TODO())
 	if err != nil {
 		return rollback(tx, "update client uploading: %w", err)
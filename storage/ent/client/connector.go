@@ -2,18 +2,40 @@ package client
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/dexidp/dex/storage"
+	"github.com/dexidp/dex/storage/ent/db/connector"
 )
 
 // CreateConnector saves a connector into the database.
-func (d *Database) CreateConnector(ctx context.Context, connector storage.Connector) error {
+func (d *Database) CreateConnector(ctx context.Context, conn storage.Connector) error {
+	err := d.createConnector(ctx, conn)
+	if errors.Is(err, storage.ErrAlreadyExists) {
+		// A soft deleted connector is replaced by a new one with the same id.
+		n, derr := d.client.Connector.Delete().
+			Where(connector.ID(conn.ID), connector.DeletedAtNotNil()).
+			Exec(ctx)
+		if derr != nil {
+			return convertDBError("create connector purge deleted: %w", derr)
+		}
+		if n > 0 {
+			err = d.createConnector(ctx, conn)
+		}
+	}
+	return err
+}
+
+func (d *Database) createConnector(ctx context.Context, conn storage.Connector) error {
 	_, err := d.client.Connector.Create().
-		SetID(connector.ID).
-		SetName(connector.Name).
-		SetType(connector.Type).
-		SetResourceVersion(connector.ResourceVersion).
-		SetConfig(connector.Config).
+		SetID(conn.ID).
+		SetName(conn.Name).
+		SetType(conn.Type).
+		SetResourceVersion(conn.ResourceVersion).
+		SetConfig(conn.Config).
+		SetDisplay(conn.Display).
+		SetCreatedBy(storage.ActorFromContext(ctx)).
 		Save(ctx)
 	if err != nil {
 		return convertDBError("create connector: %w", err)
@@ -23,7 +45,9 @@ func (d *Database) CreateConnector(ctx context.Context, connector storage.Connec
 
 // ListConnectors extracts an array of connectors from the database.
 func (d *Database) ListConnectors() ([]storage.Connector, error) {
-	connectors, err := d.client.Connector.Query().All(context.TODO())
+	connectors, err := d.client.Connector.Query().
+		Where(connector.DeletedAtIsNil()).
+		All(context.TODO())
 	if err != nil {
 		return nil, convertDBError("list connectors: %w", err)
 	}
@@ -37,19 +61,38 @@ func (d *Database) ListConnectors() ([]storage.Connector, error) {
 
 // GetConnector extracts a connector from the database by id.
 func (d *Database) GetConnector(id string) (storage.Connector, error) {
-	connector, err := d.client.Connector.Get(context.TODO(), id)
+	conn, err := d.client.Connector.Query().
+		Where(connector.ID(id), connector.DeletedAtIsNil()).
+		Only(context.TODO())
 	if err != nil {
 		return storage.Connector{}, convertDBError("get connector: %w", err)
 	}
-	return toStorageConnector(connector), nil
+	return toStorageConnector(conn), nil
 }
 
-// DeleteConnector deletes a connector from the database by id.
+// DeleteConnector deletes a connector from the database by id, or marks it as
+// deleted if soft deletes are enabled.
 func (d *Database) DeleteConnector(id string) error {
-	err := d.client.Connector.DeleteOneID(id).Exec(context.TODO())
+	var (
+		n   int
+		err error
+	)
+	if d.softDelete {
+		n, err = d.client.Connector.Update().
+			Where(connector.ID(id), connector.DeletedAtIsNil()).
+			SetDeletedAt(time.Now().UTC()).
+			Save(context.TODO())
+	} else {
+		n, err = d.client.Connector.Delete().
+			Where(connector.ID(id), connector.DeletedAtIsNil()).
+			Exec(context.TODO())
+	}
 	if err != nil {
 		return convertDBError("delete connector: %w", err)
 	}
+	if n == 0 {
+		return storage.ErrNotFound
+	}
 	return nil
 }
 
@@ -60,12 +103,14 @@ func (d *Database) UpdateConnector(id string, updater func(old storage.Connector
 		return convertDBError("update connector tx: %w", err)
 	}
 
-	connector, err := tx.Connector.Get(context.TODO(), id)
+	conn, err := tx.Connector.Query().
+		Where(connector.ID(id), connector.DeletedAtIsNil()).
+		Only(context.TODO())
 	if err != nil {
 		return rollback(tx, "update connector database: %w", err)
 	}
 
-	newConnector, err := updater(toStorageConnector(connector))
+	newConnector, err := updater(toStorageConnector(conn))
 	if err != nil {
 		return rollback(tx, "update connector updating: %w", err)
 	}
@@ -75,6 +120,8 @@ func (d *Database) UpdateConnector(id string, updater func(old storage.Connector
 		SetType(newConnector.Type).
 		SetResourceVersion(newConnector.ResourceVersion).
 		SetConfig(newConnector.Config).
+		SetDisplay(newConnector.Display).
+		SetUpdatedAt(time.Now().UTC()).
 		Save(context.TODO())
 	if err != nil {
 		return rollback(tx, "update connector uploading: %w", err)
@@ -34,3 +34,17 @@ func (d *Database) GetDeviceRequest(userCode string) (storage.DeviceRequest, err
 	}
 	return toStorageDeviceRequest(deviceRequest), nil
 }
+
+// ListDeviceRequests extracts all device requests from the database.
+func (d *Database) ListDeviceRequests() ([]storage.DeviceRequest, error) {
+	deviceRequests, err := d.client.DeviceRequest.Query().All(context.TODO())
+	if err != nil {
+		return nil, convertDBError("list device requests: %w", err)
+	}
+
+	storageDeviceRequests := make([]storage.DeviceRequest, 0, len(deviceRequests))
+	for _, r := range deviceRequests {
+		storageDeviceRequests = append(storageDeviceRequests, toStorageDeviceRequest(r))
+	}
+	return storageDeviceRequests, nil
+}
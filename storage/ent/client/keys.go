@@ -53,6 +53,7 @@ func (d *Database) UpdateKeys(updater func(old storage.Keys) (storage.Keys, erro
 			SetSigningKey(*newKeys.SigningKey).
 			SetSigningKeyPub(*newKeys.SigningKeyPub).
 			SetVerificationKeys(newKeys.VerificationKeys).
+			SetIssuerKeys(newKeys.IssuerKeys).
 			Save(context.TODO())
 		if err != nil {
 			return rollback(tx, "create keys: %w", err)
@@ -68,6 +69,7 @@ func (d *Database) UpdateKeys(updater func(old storage.Keys) (storage.Keys, erro
 		SetSigningKey(*newKeys.SigningKey).
 		SetSigningKeyPub(*newKeys.SigningKeyPub).
 		SetVerificationKeys(newKeys.VerificationKeys).
+		SetIssuerKeys(newKeys.IssuerKeys).
 		Exec(context.TODO())
 	if err != nil {
 		return rollback(tx, "update keys uploading: %w", err)
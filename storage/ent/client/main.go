@@ -18,8 +18,9 @@ import (
 var _ storage.Storage = (*Database)(nil)
 
 type Database struct {
-	client    *db.Client
-	txOptions *sql.TxOptions
+	client     *db.Client
+	txOptions  *sql.TxOptions
+	softDelete bool
 
 	hasher func() hash.Hash
 }
@@ -54,6 +55,14 @@ func WithTxIsolationLevel(level sql.IsolationLevel) func(*Database) {
 	}
 }
 
+// WithSoftDelete marks deleted clients and connectors as deleted instead of
+// removing them, so that they can be recovered by clearing deleted_at.
+func WithSoftDelete(enabled bool) func(*Database) {
+	return func(s *Database) {
+		s.softDelete = enabled
+	}
+}
+
 // Schema exposes migration schema to perform migrations.
 func (d *Database) Schema() *migrate.Schema {
 	return d.client.Schema
@@ -70,41 +79,88 @@ func (d *Database) BeginTx(ctx context.Context) (*db.Tx, error) {
 }
 
 // GarbageCollect removes expired entities from the database.
-func (d *Database) GarbageCollect(now time.Time) (storage.GCResult, error) {
+func (d *Database) GarbageCollect(now time.Time, opts storage.GCOptions) (storage.GCResult, error) {
 	result := storage.GCResult{}
 	utcNow := now.UTC()
-
-	q, err := d.client.AuthRequest.Delete().
-		Where(authrequest.ExpiryLT(utcNow)).
-		Exec(context.TODO())
-	if err != nil {
-		return result, convertDBError("gc auth request: %w", err)
+	ctx := context.TODO()
+
+	var err error
+	if opts.Includes(storage.GCAuthRequests) {
+		result.AuthRequests, err = deleteInBatches(opts.BatchSize, func() (int, error) {
+			if opts.BatchSize <= 0 {
+				return d.client.AuthRequest.Delete().Where(authrequest.ExpiryLT(utcNow)).Exec(ctx)
+			}
+			ids, err := d.client.AuthRequest.Query().Where(authrequest.ExpiryLT(utcNow)).Limit(opts.BatchSize).IDs(ctx)
+			if err != nil {
+				return 0, err
+			}
+			return d.client.AuthRequest.Delete().Where(authrequest.IDIn(ids...)).Exec(ctx)
+		})
+		if err != nil {
+			return result, convertDBError("gc auth request: %w", err)
+		}
 	}
-	result.AuthRequests = int64(q)
 
-	q, err = d.client.AuthCode.Delete().
-		Where(authcode.ExpiryLT(utcNow)).
-		Exec(context.TODO())
-	if err != nil {
-		return result, convertDBError("gc auth code: %w", err)
+	if opts.Includes(storage.GCAuthCodes) {
+		result.AuthCodes, err = deleteInBatches(opts.BatchSize, func() (int, error) {
+			if opts.BatchSize <= 0 {
+				return d.client.AuthCode.Delete().Where(authcode.ExpiryLT(utcNow)).Exec(ctx)
+			}
+			ids, err := d.client.AuthCode.Query().Where(authcode.ExpiryLT(utcNow)).Limit(opts.BatchSize).IDs(ctx)
+			if err != nil {
+				return 0, err
+			}
+			return d.client.AuthCode.Delete().Where(authcode.IDIn(ids...)).Exec(ctx)
+		})
+		if err != nil {
+			return result, convertDBError("gc auth code: %w", err)
+		}
 	}
-	result.AuthCodes = int64(q)
 
-	q, err = d.client.DeviceRequest.Delete().
-		Where(devicerequest.ExpiryLT(utcNow)).
-		Exec(context.TODO())
-	if err != nil {
-		return result, convertDBError("gc device request: %w", err)
+	if opts.Includes(storage.GCDeviceRequests) {
+		result.DeviceRequests, err = deleteInBatches(opts.BatchSize, func() (int, error) {
+			if opts.BatchSize <= 0 {
+				return d.client.DeviceRequest.Delete().Where(devicerequest.ExpiryLT(utcNow)).Exec(ctx)
+			}
+			ids, err := d.client.DeviceRequest.Query().Where(devicerequest.ExpiryLT(utcNow)).Limit(opts.BatchSize).IDs(ctx)
+			if err != nil {
+				return 0, err
+			}
+			return d.client.DeviceRequest.Delete().Where(devicerequest.IDIn(ids...)).Exec(ctx)
+		})
+		if err != nil {
+			return result, convertDBError("gc device request: %w", err)
+		}
 	}
-	result.DeviceRequests = int64(q)
 
-	q, err = d.client.DeviceToken.Delete().
-		Where(devicetoken.ExpiryLT(utcNow)).
-		Exec(context.TODO())
-	if err != nil {
-		return result, convertDBError("gc device token: %w", err)
+	if opts.Includes(storage.GCDeviceTokens) {
+		result.DeviceTokens, err = deleteInBatches(opts.BatchSize, func() (int, error) {
+			if opts.BatchSize <= 0 {
+				return d.client.DeviceToken.Delete().Where(devicetoken.ExpiryLT(utcNow)).Exec(ctx)
+			}
+			ids, err := d.client.DeviceToken.Query().Where(devicetoken.ExpiryLT(utcNow)).Limit(opts.BatchSize).IDs(ctx)
+			if err != nil {
+				return 0, err
+			}
+			return d.client.DeviceToken.Delete().Where(devicetoken.IDIn(ids...)).Exec(ctx)
+		})
+		if err != nil {
+			return result, convertDBError("gc device token: %w", err)
+		}
 	}
-	result.DeviceTokens = int64(q)
 
 	return result, err
 }
+
+// deleteInBatches calls deleteBatch until it deletes fewer than batchSize
+// entities, or once if batchSize is zero, and returns the number deleted.
+func deleteInBatches(batchSize int, deleteBatch func() (int, error)) (int64, error) {
+	var deleted int64
+	for {
+		n, err := deleteBatch()
+		deleted += int64(n)
+		if err != nil || batchSize <= 0 || n < batchSize {
+			return deleted, err
+		}
+	}
+}
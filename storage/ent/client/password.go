@@ -15,6 +15,9 @@ func (d *Database) CreatePassword(ctx context.Context, password storage.Password
 		SetHash(password.Hash).
 		SetUsername(password.Username).
 		SetUserID(password.UserID).
+		SetEmailVerified(password.EmailVerified).
+		SetFailedAttempts(password.FailedAttempts).
+		SetLockedUntil(password.LockedUntil).
 		Save(ctx)
 	if err != nil {
 		return convertDBError("create password: %w", err)
@@ -87,6 +90,9 @@ func (d *Database) UpdatePassword(email string, updater func(old storage.Passwor
 		SetHash(newPassword.Hash).
 		SetUsername(newPassword.Username).
 		SetUserID(newPassword.UserID).
+		SetEmailVerified(newPassword.EmailVerified).
+		SetFailedAttempts(newPassword.FailedAttempts).
+		SetLockedUntil(newPassword.LockedUntil).
 		Save(context.TODO())
 	if err != nil {
 		return rollback(tx, "update password uploading: %w", err)
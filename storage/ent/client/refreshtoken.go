@@ -12,6 +12,7 @@ func (d *Database) CreateRefresh(ctx context.Context, refresh storage.RefreshTok
 		SetID(refresh.ID).
 		SetClientID(refresh.ClientID).
 		SetScopes(refresh.Scopes).
+		SetClaimsRequest(refresh.ClaimsRequest).
 		SetNonce(refresh.Nonce).
 		SetClaimsUserID(refresh.Claims.UserID).
 		SetClaimsEmail(refresh.Claims.Email).
@@ -19,10 +20,13 @@ func (d *Database) CreateRefresh(ctx context.Context, refresh storage.RefreshTok
 		SetClaimsUsername(refresh.Claims.Username).
 		SetClaimsPreferredUsername(refresh.Claims.PreferredUsername).
 		SetClaimsGroups(refresh.Claims.Groups).
+		SetClaimsSources(refresh.Claims.ClaimSources).
+		SetClaimsCustom(refresh.Claims.CustomClaims).
 		SetConnectorID(refresh.ConnectorID).
 		SetConnectorData(refresh.ConnectorData).
 		SetToken(refresh.Token).
 		SetObsoleteToken(refresh.ObsoleteToken).
+		SetIssuer(refresh.Issuer).
 		// Save utc time into database because ent doesn't support comparing dates with different timezones
 		SetLastUsed(refresh.LastUsed.UTC()).
 		SetCreatedAt(refresh.CreatedAt.UTC()).
@@ -85,6 +89,7 @@ func (d *Database) UpdateRefreshToken(id string, updater func(old storage.Refres
 	_, err = tx.RefreshToken.UpdateOneID(newtToken.ID).
 		SetClientID(newtToken.ClientID).
 		SetScopes(newtToken.Scopes).
+		SetClaimsRequest(newtToken.ClaimsRequest).
 		SetNonce(newtToken.Nonce).
 		SetClaimsUserID(newtToken.Claims.UserID).
 		SetClaimsEmail(newtToken.Claims.Email).
@@ -92,10 +97,13 @@ func (d *Database) UpdateRefreshToken(id string, updater func(old storage.Refres
 		SetClaimsUsername(newtToken.Claims.Username).
 		SetClaimsPreferredUsername(newtToken.Claims.PreferredUsername).
 		SetClaimsGroups(newtToken.Claims.Groups).
+		SetClaimsSources(newtToken.Claims.ClaimSources).
+		SetClaimsCustom(newtToken.Claims.CustomClaims).
 		SetConnectorID(newtToken.ConnectorID).
 		SetConnectorData(newtToken.ConnectorData).
 		SetToken(newtToken.Token).
 		SetObsoleteToken(newtToken.ObsoleteToken).
+		SetIssuer(newtToken.Issuer).
 		// Save utc time into database because ent doesn't support comparing dates with different timezones
 		SetLastUsed(newtToken.LastUsed.UTC()).
 		SetCreatedAt(newtToken.CreatedAt.UTC()).
@@ -16,6 +16,7 @@ func toStorageKeys(keys *db.Keys) storage.Keys {
 		SigningKeyPub:    &keys.SigningKeyPub,
 		VerificationKeys: keys.VerificationKeys,
 		NextRotation:     keys.NextRotation,
+		IssuerKeys:       keys.IssuerKeys,
 	}
 }
 
@@ -25,6 +26,7 @@ func toStorageAuthRequest(a *db.AuthRequest) storage.AuthRequest {
 		ClientID:            a.ClientID,
 		ResponseTypes:       a.ResponseTypes,
 		Scopes:              a.Scopes,
+		ClaimsRequest:       bytesValue(a.ClaimsRequest),
 		RedirectURI:         a.RedirectURI,
 		Nonce:               a.Nonce,
 		State:               a.State,
@@ -40,6 +42,8 @@ func toStorageAuthRequest(a *db.AuthRequest) storage.AuthRequest {
 			Email:             a.ClaimsEmail,
 			EmailVerified:     a.ClaimsEmailVerified,
 			Groups:            a.ClaimsGroups,
+			CustomClaims:      a.ClaimsCustom,
+			ClaimSources:      a.ClaimsSources,
 		},
 		PKCE: storage.PKCE{
 			CodeChallenge:       a.CodeChallenge,
@@ -54,6 +58,7 @@ func toStorageAuthCode(a *db.AuthCode) storage.AuthCode {
 		ID:            a.ID,
 		ClientID:      a.ClientID,
 		Scopes:        a.Scopes,
+		ClaimsRequest: bytesValue(a.ClaimsRequest),
 		RedirectURI:   a.RedirectURI,
 		Nonce:         a.Nonce,
 		ConnectorID:   a.ConnectorID,
@@ -66,11 +71,14 @@ func toStorageAuthCode(a *db.AuthCode) storage.AuthCode {
 			Email:             a.ClaimsEmail,
 			EmailVerified:     a.ClaimsEmailVerified,
 			Groups:            a.ClaimsGroups,
+			CustomClaims:      a.ClaimsCustom,
+			ClaimSources:      a.ClaimsSources,
 		},
 		PKCE: storage.PKCE{
 			CodeChallenge:       a.CodeChallenge,
 			CodeChallengeMethod: a.CodeChallengeMethod,
 		},
+		Issuer: a.Issuer,
 	}
 }
 
@@ -83,15 +91,31 @@ func toStorageClient(c *db.OAuth2Client) storage.Client {
 		Public:       c.Public,
 		Name:         c.Name,
 		LogoURL:      c.LogoURL,
+
+		PolicyURL:         c.PolicyURL,
+		TermsOfServiceURL: c.TermsOfServiceURL,
+
+		RefreshTokenPolicy:  c.RefreshTokenPolicy,
+		TokenExpiry:         c.TokenExpiry,
+		GroupsFilter:        c.GroupsFilter,
+		ClaimsTransforms:    c.ClaimsTransforms,
+		RedirectURIPatterns: c.RedirectURIPatterns,
+		AllowedOrigins:      c.AllowedOrigins,
+		PreviousSecret:      c.PreviousSecret,
+		CreatedAt:           c.CreatedAt,
+		LastUsed:            c.LastUsed,
+
+		ForwardClaimSourceTokens: c.ForwardClaimSourceTokens,
 	}
 }
 
 func toStorageConnector(c *db.Connector) storage.Connector {
 	return storage.Connector{
-		ID:     c.ID,
-		Type:   c.Type,
-		Name:   c.Name,
-		Config: c.Config,
+		ID:      c.ID,
+		Type:    c.Type,
+		Name:    c.Name,
+		Config:  c.Config,
+		Display: c.Display,
 	}
 }
 
@@ -125,6 +149,7 @@ func toStorageRefreshToken(r *db.RefreshToken) storage.RefreshToken {
 		ConnectorID:   r.ConnectorID,
 		ConnectorData: *r.ConnectorData,
 		Scopes:        r.Scopes,
+		ClaimsRequest: bytesValue(r.ClaimsRequest),
 		Nonce:         r.Nonce,
 		Claims: storage.Claims{
 			UserID:            r.ClaimsUserID,
@@ -133,16 +158,22 @@ func toStorageRefreshToken(r *db.RefreshToken) storage.RefreshToken {
 			Email:             r.ClaimsEmail,
 			EmailVerified:     r.ClaimsEmailVerified,
 			Groups:            r.ClaimsGroups,
+			CustomClaims:      r.ClaimsCustom,
+			ClaimSources:      r.ClaimsSources,
 		},
+		Issuer: r.Issuer,
 	}
 }
 
 func toStoragePassword(p *db.Password) storage.Password {
 	return storage.Password{
-		Email:    p.Email,
-		Hash:     p.Hash,
-		Username: p.Username,
-		UserID:   p.UserID,
+		Email:          p.Email,
+		Hash:           p.Hash,
+		Username:       p.Username,
+		UserID:         p.UserID,
+		EmailVerified:  p.EmailVerified,
+		FailedAttempts: p.FailedAttempts,
+		LockedUntil:    p.LockedUntil,
 	}
 }
 
@@ -171,3 +202,23 @@ func toStorageDeviceToken(t *db.DeviceToken) storage.DeviceToken {
 		},
 	}
 }
+
+// bytesValue dereferences an optional bytes field, which is nil for rows
+// written before the field was added.
+func bytesValue(b *[]byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return *b
+}
+
+func toStorageWebhook(w *db.Webhook) storage.Webhook {
+	return storage.Webhook{
+		ID:          w.ID,
+		URL:         w.URL,
+		Secret:      w.Secret,
+		Events:      w.Events,
+		RetryPolicy: w.RetryPolicy,
+		CreatedAt:   w.CreatedAt,
+	}
+}
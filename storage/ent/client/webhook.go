@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+
+	"github.com/dexidp/dex/storage"
+)
+
+// CreateWebhook saves a webhook into the database.
+func (d *Database) CreateWebhook(ctx context.Context, webhook storage.Webhook) error {
+	_, err := d.client.Webhook.Create().
+		SetID(webhook.ID).
+		SetURL(webhook.URL).
+		SetSecret(webhook.Secret).
+		SetEvents(webhook.Events).
+		SetRetryPolicy(webhook.RetryPolicy).
+		SetCreatedAt(webhook.CreatedAt).
+		Save(ctx)
+	if err != nil {
+		return convertDBError("create webhook: %w", err)
+	}
+	return nil
+}
+
+// ListWebhooks extracts an array of webhooks from the database.
+func (d *Database) ListWebhooks() ([]storage.Webhook, error) {
+	webhooks, err := d.client.Webhook.Query().All(context.TODO())
+	if err != nil {
+		return nil, convertDBError("list webhooks: %w", err)
+	}
+
+	storageWebhooks := make([]storage.Webhook, 0, len(webhooks))
+	for _, w := range webhooks {
+		storageWebhooks = append(storageWebhooks, toStorageWebhook(w))
+	}
+	return storageWebhooks, nil
+}
+
+// GetWebhook extracts a webhook from the database by id.
+func (d *Database) GetWebhook(id string) (storage.Webhook, error) {
+	webhook, err := d.client.Webhook.Get(context.TODO(), id)
+	if err != nil {
+		return storage.Webhook{}, convertDBError("get webhook: %w", err)
+	}
+	return toStorageWebhook(webhook), nil
+}
+
+// DeleteWebhook deletes a webhook from the database by id.
+func (d *Database) DeleteWebhook(id string) error {
+	err := d.client.Webhook.DeleteOneID(id).Exec(context.TODO())
+	if err != nil {
+		return convertDBError("delete webhook: %w", err)
+	}
+	return nil
+}
+
+// UpdateWebhook changes a webhook by id using an updater function and saves it to the database.
+func (d *Database) UpdateWebhook(id string, updater func(old storage.Webhook) (storage.Webhook, error)) error {
+	tx, err := d.BeginTx(context.TODO())
+	if err != nil {
+		return convertDBError("update webhook tx: %w", err)
+	}
+
+	webhook, err := tx.Webhook.Get(context.TODO(), id)
+	if err != nil {
+		return rollback(tx, "update webhook database: %w", err)
+	}
+
+	newWebhook, err := updater(toStorageWebhook(webhook))
+	if err != nil {
+		return rollback(tx, "update webhook updating: %w", err)
+	}
+
+	_, err = tx.Webhook.UpdateOneID(newWebhook.ID).
+		SetURL(newWebhook.URL).
+		SetSecret(newWebhook.Secret).
+		SetEvents(newWebhook.Events).
+		SetRetryPolicy(newWebhook.RetryPolicy).
+		Save(context.TODO())
+	if err != nil {
+		return rollback(tx, "update webhook uploading: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return rollback(tx, "update webhook commit: %w", err)
+	}
+
+	return nil
+}
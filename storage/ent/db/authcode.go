@@ -10,6 +10,7 @@ import (
 
 	"entgo.io/ent"
 	"entgo.io/ent/dialect/sql"
+	"github.com/dexidp/dex/storage"
 	"github.com/dexidp/dex/storage/ent/db/authcode"
 )
 
@@ -48,7 +49,15 @@ type AuthCode struct {
 	CodeChallenge string `json:"code_challenge,omitempty"`
 	// CodeChallengeMethod holds the value of the "code_challenge_method" field.
 	CodeChallengeMethod string `json:"code_challenge_method,omitempty"`
-	selectValues        sql.SelectValues
+	// ClaimsCustom holds the value of the "claims_custom" field.
+	ClaimsCustom map[string]interface{} `json:"claims_custom,omitempty"`
+	// ClaimsRequest holds the value of the "claims_request" field.
+	ClaimsRequest *[]byte `json:"claims_request,omitempty"`
+	// ClaimsSources holds the value of the "claims_sources" field.
+	ClaimsSources map[string]storage.ClaimSource `json:"claims_sources,omitempty"`
+	// Issuer holds the value of the "issuer" field.
+	Issuer       string `json:"issuer,omitempty"`
+	selectValues sql.SelectValues
 }
 
 // scanValues returns the types for scanning values from sql.Rows.
@@ -56,11 +65,11 @@ func (*AuthCode) scanValues(columns []string) ([]any, error) {
 	values := make([]any, len(columns))
 	for i := range columns {
 		switch columns[i] {
-		case authcode.FieldScopes, authcode.FieldClaimsGroups, authcode.FieldConnectorData:
+		case authcode.FieldScopes, authcode.FieldClaimsGroups, authcode.FieldConnectorData, authcode.FieldClaimsCustom, authcode.FieldClaimsRequest, authcode.FieldClaimsSources:
 			values[i] = new([]byte)
 		case authcode.FieldClaimsEmailVerified:
 			values[i] = new(sql.NullBool)
-		case authcode.FieldID, authcode.FieldClientID, authcode.FieldNonce, authcode.FieldRedirectURI, authcode.FieldClaimsUserID, authcode.FieldClaimsUsername, authcode.FieldClaimsEmail, authcode.FieldClaimsPreferredUsername, authcode.FieldConnectorID, authcode.FieldCodeChallenge, authcode.FieldCodeChallengeMethod:
+		case authcode.FieldID, authcode.FieldClientID, authcode.FieldNonce, authcode.FieldRedirectURI, authcode.FieldClaimsUserID, authcode.FieldClaimsUsername, authcode.FieldClaimsEmail, authcode.FieldClaimsPreferredUsername, authcode.FieldConnectorID, authcode.FieldCodeChallenge, authcode.FieldCodeChallengeMethod, authcode.FieldIssuer:
 			values[i] = new(sql.NullString)
 		case authcode.FieldExpiry:
 			values[i] = new(sql.NullTime)
@@ -179,6 +188,34 @@ func (ac *AuthCode) assignValues(columns []string, values []any) error {
 			} else if value.Valid {
 				ac.CodeChallengeMethod = value.String
 			}
+		case authcode.FieldClaimsCustom:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field claims_custom", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &ac.ClaimsCustom); err != nil {
+					return fmt.Errorf("unmarshal field claims_custom: %w", err)
+				}
+			}
+		case authcode.FieldClaimsRequest:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field claims_request", values[i])
+			} else if value != nil {
+				ac.ClaimsRequest = value
+			}
+		case authcode.FieldClaimsSources:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field claims_sources", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &ac.ClaimsSources); err != nil {
+					return fmt.Errorf("unmarshal field claims_sources: %w", err)
+				}
+			}
+		case authcode.FieldIssuer:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field issuer", values[i])
+			} else if value.Valid {
+				ac.Issuer = value.String
+			}
 		default:
 			ac.selectValues.Set(columns[i], values[i])
 		}
@@ -261,6 +298,20 @@ func (ac *AuthCode) String() string {
 	builder.WriteString(", ")
 	builder.WriteString("code_challenge_method=")
 	builder.WriteString(ac.CodeChallengeMethod)
+	builder.WriteString(", ")
+	builder.WriteString("claims_custom=")
+	builder.WriteString(fmt.Sprintf("%v", ac.ClaimsCustom))
+	builder.WriteString(", ")
+	if v := ac.ClaimsRequest; v != nil {
+		builder.WriteString("claims_request=")
+		builder.WriteString(fmt.Sprintf("%v", *v))
+	}
+	builder.WriteString(", ")
+	builder.WriteString("claims_sources=")
+	builder.WriteString(fmt.Sprintf("%v", ac.ClaimsSources))
+	builder.WriteString(", ")
+	builder.WriteString("issuer=")
+	builder.WriteString(ac.Issuer)
 	builder.WriteByte(')')
 	return builder.String()
 }
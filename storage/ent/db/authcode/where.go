@@ -129,6 +129,16 @@ func CodeChallengeMethod(v string) predicate.AuthCode {
 	return predicate.AuthCode(sql.FieldEQ(FieldCodeChallengeMethod, v))
 }
 
+// ClaimsRequest applies equality check predicate on the "claims_request" field. It's identical to ClaimsRequestEQ.
+func ClaimsRequest(v []byte) predicate.AuthCode {
+	return predicate.AuthCode(sql.FieldEQ(FieldClaimsRequest, v))
+}
+
+// Issuer applies equality check predicate on the "issuer" field. It's identical to IssuerEQ.
+func Issuer(v string) predicate.AuthCode {
+	return predicate.AuthCode(sql.FieldEQ(FieldIssuer, v))
+}
+
 // ClientIDEQ applies the EQ predicate on the "client_id" field.
 func ClientIDEQ(v string) predicate.AuthCode {
 	return predicate.AuthCode(sql.FieldEQ(FieldClientID, v))
@@ -899,6 +909,141 @@ func CodeChallengeMethodContainsFold(v string) predicate.AuthCode {
 	return predicate.AuthCode(sql.FieldContainsFold(FieldCodeChallengeMethod, v))
 }
 
+// ClaimsCustomIsNil applies the IsNil predicate on the "claims_custom" field.
+func ClaimsCustomIsNil() predicate.AuthCode {
+	return predicate.AuthCode(sql.FieldIsNull(FieldClaimsCustom))
+}
+
+// ClaimsCustomNotNil applies the NotNil predicate on the "claims_custom" field.
+func ClaimsCustomNotNil() predicate.AuthCode {
+	return predicate.AuthCode(sql.FieldNotNull(FieldClaimsCustom))
+}
+
+// ClaimsRequestEQ applies the EQ predicate on the "claims_request" field.
+func ClaimsRequestEQ(v []byte) predicate.AuthCode {
+	return predicate.AuthCode(sql.FieldEQ(FieldClaimsRequest, v))
+}
+
+// ClaimsRequestNEQ applies the NEQ predicate on the "claims_request" field.
+func ClaimsRequestNEQ(v []byte) predicate.AuthCode {
+	return predicate.AuthCode(sql.FieldNEQ(FieldClaimsRequest, v))
+}
+
+// ClaimsRequestIn applies the In predicate on the "claims_request" field.
+func ClaimsRequestIn(vs ...[]byte) predicate.AuthCode {
+	return predicate.AuthCode(sql.FieldIn(FieldClaimsRequest, vs...))
+}
+
+// ClaimsRequestNotIn applies the NotIn predicate on the "claims_request" field.
+func ClaimsRequestNotIn(vs ...[]byte) predicate.AuthCode {
+	return predicate.AuthCode(sql.FieldNotIn(FieldClaimsRequest, vs...))
+}
+
+// ClaimsRequestGT applies the GT predicate on the "claims_request" field.
+func ClaimsRequestGT(v []byte) predicate.AuthCode {
+	return predicate.AuthCode(sql.FieldGT(FieldClaimsRequest, v))
+}
+
+// ClaimsRequestGTE applies the GTE predicate on the "claims_request" field.
+func ClaimsRequestGTE(v []byte) predicate.AuthCode {
+	return predicate.AuthCode(sql.FieldGTE(FieldClaimsRequest, v))
+}
+
+// ClaimsRequestLT applies the LT predicate on the "claims_request" field.
+func ClaimsRequestLT(v []byte) predicate.AuthCode {
+	return predicate.AuthCode(sql.FieldLT(FieldClaimsRequest, v))
+}
+
+// ClaimsRequestLTE applies the LTE predicate on the "claims_request" field.
+func ClaimsRequestLTE(v []byte) predicate.AuthCode {
+	return predicate.AuthCode(sql.FieldLTE(FieldClaimsRequest, v))
+}
+
+// ClaimsRequestIsNil applies the IsNil predicate on the "claims_request" field.
+func ClaimsRequestIsNil() predicate.AuthCode {
+	return predicate.AuthCode(sql.FieldIsNull(FieldClaimsRequest))
+}
+
+// ClaimsRequestNotNil applies the NotNil predicate on the "claims_request" field.
+func ClaimsRequestNotNil() predicate.AuthCode {
+	return predicate.AuthCode(sql.FieldNotNull(FieldClaimsRequest))
+}
+
+// ClaimsSourcesIsNil applies the IsNil predicate on the "claims_sources" field.
+func ClaimsSourcesIsNil() predicate.AuthCode {
+	return predicate.AuthCode(sql.FieldIsNull(FieldClaimsSources))
+}
+
+// ClaimsSourcesNotNil applies the NotNil predicate on the "claims_sources" field.
+func ClaimsSourcesNotNil() predicate.AuthCode {
+	return predicate.AuthCode(sql.FieldNotNull(FieldClaimsSources))
+}
+
+// IssuerEQ applies the EQ predicate on the "issuer" field.
+func IssuerEQ(v string) predicate.AuthCode {
+	return predicate.AuthCode(sql.FieldEQ(FieldIssuer, v))
+}
+
+// IssuerNEQ applies the NEQ predicate on the "issuer" field.
+func IssuerNEQ(v string) predicate.AuthCode {
+	return predicate.AuthCode(sql.FieldNEQ(FieldIssuer, v))
+}
+
+// IssuerIn applies the In predicate on the "issuer" field.
+func IssuerIn(vs ...string) predicate.AuthCode {
+	return predicate.AuthCode(sql.FieldIn(FieldIssuer, vs...))
+}
+
+// IssuerNotIn applies the NotIn predicate on the "issuer" field.
+func IssuerNotIn(vs ...string) predicate.AuthCode {
+	return predicate.AuthCode(sql.FieldNotIn(FieldIssuer, vs...))
+}
+
+// IssuerGT applies the GT predicate on the "issuer" field.
+func IssuerGT(v string) predicate.AuthCode {
+	return predicate.AuthCode(sql.FieldGT(FieldIssuer, v))
+}
+
+// IssuerGTE applies the GTE predicate on the "issuer" field.
+func IssuerGTE(v string) predicate.AuthCode {
+	return predicate.AuthCode(sql.FieldGTE(FieldIssuer, v))
+}
+
+// IssuerLT applies the LT predicate on the "issuer" field.
+func IssuerLT(v string) predicate.AuthCode {
+	return predicate.AuthCode(sql.FieldLT(FieldIssuer, v))
+}
+
+// IssuerLTE applies the LTE predicate on the "issuer" field.
+func IssuerLTE(v string) predicate.AuthCode {
+	return predicate.AuthCode(sql.FieldLTE(FieldIssuer, v))
+}
+
+// IssuerContains applies the Contains predicate on the "issuer" field.
+func IssuerContains(v string) predicate.AuthCode {
+	return predicate.AuthCode(sql.FieldContains(FieldIssuer, v))
+}
+
+// IssuerHasPrefix applies the HasPrefix predicate on the "issuer" field.
+func IssuerHasPrefix(v string) predicate.AuthCode {
+	return predicate.AuthCode(sql.FieldHasPrefix(FieldIssuer, v))
+}
+
+// IssuerHasSuffix applies the HasSuffix predicate on the "issuer" field.
+func IssuerHasSuffix(v string) predicate.AuthCode {
+	return predicate.AuthCode(sql.FieldHasSuffix(FieldIssuer, v))
+}
+
+// IssuerEqualFold applies the EqualFold predicate on the "issuer" field.
+func IssuerEqualFold(v string) predicate.AuthCode {
+	return predicate.AuthCode(sql.FieldEqualFold(FieldIssuer, v))
+}
+
+// IssuerContainsFold applies the ContainsFold predicate on the "issuer" field.
+func IssuerContainsFold(v string) predicate.AuthCode {
+	return predicate.AuthCode(sql.FieldContainsFold(FieldIssuer, v))
+}
+
 // And groups predicates with the AND operator between them.
 func And(predicates ...predicate.AuthCode) predicate.AuthCode {
 	return predicate.AuthCode(sql.AndPredicates(predicates...))
@@ -10,6 +10,7 @@ import (
 
 	"entgo.io/ent/dialect/sql/sqlgraph"
 	"entgo.io/ent/schema/field"
+	"github.com/dexidp/dex/storage"
 	"github.com/dexidp/dex/storage/ent/db/authcode"
 )
 
@@ -134,6 +135,38 @@ func (acc *AuthCodeCreate) SetNillableCodeChallengeMethod(s *string) *AuthCodeCr
 	return acc
 }
 
+// SetClaimsCustom sets the "claims_custom" field.
+func (acc *AuthCodeCreate) SetClaimsCustom(m map[string]interface{}) *AuthCodeCreate {
+	acc.mutation.SetClaimsCustom(m)
+	return acc
+}
+
+// SetClaimsRequest sets the "claims_request" field.
+func (acc *AuthCodeCreate) SetClaimsRequest(b []byte) *AuthCodeCreate {
+	acc.mutation.SetClaimsRequest(b)
+	return acc
+}
+
+// SetClaimsSources sets the "claims_sources" field.
+func (acc *AuthCodeCreate) SetClaimsSources(m map[string]storage.ClaimSource) *AuthCodeCreate {
+	acc.mutation.SetClaimsSources(m)
+	return acc
+}
+
+// SetIssuer sets the "issuer" field.
+func (acc *AuthCodeCreate) SetIssuer(s string) *AuthCodeCreate {
+	acc.mutation.SetIssuer(s)
+	return acc
+}
+
+// SetNillableIssuer sets the "issuer" field if the given value is not nil.
+func (acc *AuthCodeCreate) SetNillableIssuer(s *string) *AuthCodeCreate {
+	if s != nil {
+		acc.SetIssuer(*s)
+	}
+	return acc
+}
+
 // SetID sets the "id" field.
 func (acc *AuthCodeCreate) SetID(s string) *AuthCodeCreate {
 	acc.mutation.SetID(s)
@@ -187,6 +220,10 @@ func (acc *AuthCodeCreate) defaults() {
 		v := authcode.DefaultCodeChallengeMethod
 		acc.mutation.SetCodeChallengeMethod(v)
 	}
+	if _, ok := acc.mutation.Issuer(); !ok {
+		v := authcode.DefaultIssuer
+		acc.mutation.SetIssuer(v)
+	}
 }
 
 // check runs all checks and user-defined validators on the builder.
@@ -262,6 +299,9 @@ func (acc *AuthCodeCreate) check() error {
 	if _, ok := acc.mutation.CodeChallengeMethod(); !ok {
 		return &ValidationError{Name: "code_challenge_method", err: errors.New(`db: missing required field "AuthCode.code_challenge_method"`)}
 	}
+	if _, ok := acc.mutation.Issuer(); !ok {
+		return &ValidationError{Name: "issuer", err: errors.New(`db: missing required field "AuthCode.issuer"`)}
+	}
 	if v, ok := acc.mutation.ID(); ok {
 		if err := authcode.IDValidator(v); err != nil {
 			return &ValidationError{Name: "id", err: fmt.Errorf(`db: validator failed for field "AuthCode.id": %w`, err)}
@@ -362,6 +402,22 @@ func (acc *AuthCodeCreate) createSpec() (*AuthCode, *sqlgraph.CreateSpec) {
 		_spec.SetField(authcode.FieldCodeChallengeMethod, field.TypeString, value)
 		_node.CodeChallengeMethod = value
 	}
+	if value, ok := acc.mutation.ClaimsCustom(); ok {
+		_spec.SetField(authcode.FieldClaimsCustom, field.TypeJSON, value)
+		_node.ClaimsCustom = value
+	}
+	if value, ok := acc.mutation.ClaimsRequest(); ok {
+		_spec.SetField(authcode.FieldClaimsRequest, field.TypeBytes, value)
+		_node.ClaimsRequest = &value
+	}
+	if value, ok := acc.mutation.ClaimsSources(); ok {
+		_spec.SetField(authcode.FieldClaimsSources, field.TypeJSON, value)
+		_node.ClaimsSources = value
+	}
+	if value, ok := acc.mutation.Issuer(); ok {
+		_spec.SetField(authcode.FieldIssuer, field.TypeString, value)
+		_node.Issuer = value
+	}
 	return _node, _spec
 }
 
@@ -12,6 +12,7 @@ import (
 	"entgo.io/ent/dialect/sql/sqlgraph"
 	"entgo.io/ent/dialect/sql/sqljson"
 	"entgo.io/ent/schema/field"
+	"github.com/dexidp/dex/storage"
 	"github.com/dexidp/dex/storage/ent/db/authcode"
 	"github.com/dexidp/dex/storage/ent/db/predicate"
 )
@@ -245,6 +246,56 @@ func (acu *AuthCodeUpdate) SetNillableCodeChallengeMethod(s *string) *AuthCodeUp
 	return acu
 }
 
+// SetClaimsCustom sets the "claims_custom" field.
+func (acu *AuthCodeUpdate) SetClaimsCustom(m map[string]interface{}) *AuthCodeUpdate {
+	acu.mutation.SetClaimsCustom(m)
+	return acu
+}
+
+// ClearClaimsCustom clears the value of the "claims_custom" field.
+func (acu *AuthCodeUpdate) ClearClaimsCustom() *AuthCodeUpdate {
+	acu.mutation.ClearClaimsCustom()
+	return acu
+}
+
+// SetClaimsRequest sets the "claims_request" field.
+func (acu *AuthCodeUpdate) SetClaimsRequest(b []byte) *AuthCodeUpdate {
+	acu.mutation.SetClaimsRequest(b)
+	return acu
+}
+
+// ClearClaimsRequest clears the value of the "claims_request" field.
+func (acu *AuthCodeUpdate) ClearClaimsRequest() *AuthCodeUpdate {
+	acu.mutation.ClearClaimsRequest()
+	return acu
+}
+
+// SetClaimsSources sets the "claims_sources" field.
+func (acu *AuthCodeUpdate) SetClaimsSources(m map[string]storage.ClaimSource) *AuthCodeUpdate {
+	acu.mutation.SetClaimsSources(m)
+	return acu
+}
+
+// ClearClaimsSources clears the value of the "claims_sources" field.
+func (acu *AuthCodeUpdate) ClearClaimsSources() *AuthCodeUpdate {
+	acu.mutation.ClearClaimsSources()
+	return acu
+}
+
+// SetIssuer sets the "issuer" field.
+func (acu *AuthCodeUpdate) SetIssuer(s string) *AuthCodeUpdate {
+	acu.mutation.SetIssuer(s)
+	return acu
+}
+
+// SetNillableIssuer sets the "issuer" field if the given value is not nil.
+func (acu *AuthCodeUpdate) SetNillableIssuer(s *string) *AuthCodeUpdate {
+	if s != nil {
+		acu.SetIssuer(*s)
+	}
+	return acu
+}
+
 // Mutation returns the AuthCodeMutation object of the builder.
 func (acu *AuthCodeUpdate) Mutation() *AuthCodeMutation {
 	return acu.mutation
@@ -393,6 +444,27 @@ func (acu *AuthCodeUpdate) sqlSave(ctx context.Context) (n int, err error) {
 	if value, ok := acu.mutation.CodeChallengeMethod(); ok {
 		_spec.SetField(authcode.FieldCodeChallengeMethod, field.TypeString, value)
 	}
+	if value, ok := acu.mutation.ClaimsCustom(); ok {
+		_spec.SetField(authcode.FieldClaimsCustom, field.TypeJSON, value)
+	}
+	if acu.mutation.ClaimsCustomCleared() {
+		_spec.ClearField(authcode.FieldClaimsCustom, field.TypeJSON)
+	}
+	if value, ok := acu.mutation.ClaimsRequest(); ok {
+		_spec.SetField(authcode.FieldClaimsRequest, field.TypeBytes, value)
+	}
+	if acu.mutation.ClaimsRequestCleared() {
+		_spec.ClearField(authcode.FieldClaimsRequest, field.TypeBytes)
+	}
+	if value, ok := acu.mutation.ClaimsSources(); ok {
+		_spec.SetField(authcode.FieldClaimsSources, field.TypeJSON, value)
+	}
+	if acu.mutation.ClaimsSourcesCleared() {
+		_spec.ClearField(authcode.FieldClaimsSources, field.TypeJSON)
+	}
+	if value, ok := acu.mutation.Issuer(); ok {
+		_spec.SetField(authcode.FieldIssuer, field.TypeString, value)
+	}
 	if n, err = sqlgraph.UpdateNodes(ctx, acu.driver, _spec); err != nil {
 		if _, ok := err.(*sqlgraph.NotFoundError); ok {
 			err = &NotFoundError{authcode.Label}
@@ -629,6 +701,56 @@ func (acuo *AuthCodeUpdateOne) SetNillableCodeChallengeMethod(s *string) *AuthCo
 	return acuo
 }
 
+// SetClaimsCustom sets the "claims_custom" field.
+func (acuo *AuthCodeUpdateOne) SetClaimsCustom(m map[string]interface{}) *AuthCodeUpdateOne {
+	acuo.mutation.SetClaimsCustom(m)
+	return acuo
+}
+
+// ClearClaimsCustom clears the value of the "claims_custom" field.
+func (acuo *AuthCodeUpdateOne) ClearClaimsCustom() *AuthCodeUpdateOne {
+	acuo.mutation.ClearClaimsCustom()
+	return acuo
+}
+
+// SetClaimsRequest sets the "claims_request" field.
+func (acuo *AuthCodeUpdateOne) SetClaimsRequest(b []byte) *AuthCodeUpdateOne {
+	acuo.mutation.SetClaimsRequest(b)
+	return acuo
+}
+
+// ClearClaimsRequest clears the value of the "claims_request" field.
+func (acuo *AuthCodeUpdateOne) ClearClaimsRequest() *AuthCodeUpdateOne {
+	acuo.mutation.ClearClaimsRequest()
+	return acuo
+}
+
+// SetClaimsSources sets the "claims_sources" field.
+func (acuo *AuthCodeUpdateOne) SetClaimsSources(m map[string]storage.ClaimSource) *AuthCodeUpdateOne {
+	acuo.mutation.SetClaimsSources(m)
+	return acuo
+}
+
+// ClearClaimsSources clears the value of the "claims_sources" field.
+func (acuo *AuthCodeUpdateOne) ClearClaimsSources() *AuthCodeUpdateOne {
+	acuo.mutation.ClearClaimsSources()
+	return acuo
+}
+
+// SetIssuer sets the "issuer" field.
+func (acuo *AuthCodeUpdateOne) SetIssuer(s string) *AuthCodeUpdateOne {
+	acuo.mutation.SetIssuer(s)
+	return acuo
+}
+
+// SetNillableIssuer sets the "issuer" field if the given value is not nil.
+func (acuo *AuthCodeUpdateOne) SetNillableIssuer(s *string) *AuthCodeUpdateOne {
+	if s != nil {
+		acuo.SetIssuer(*s)
+	}
+	return acuo
+}
+
 // Mutation returns the AuthCodeMutation object of the builder.
 func (acuo *AuthCodeUpdateOne) Mutation() *AuthCodeMutation {
 	return acuo.mutation
@@ -807,6 +929,27 @@ func (acuo *AuthCodeUpdateOne) sqlSave(ctx context.Context) (_node *AuthCode, er
 	if value, ok := acuo.mutation.CodeChallengeMethod(); ok {
 		_spec.SetField(authcode.FieldCodeChallengeMethod, field.TypeString, value)
 	}
+	if value, ok := acuo.mutation.ClaimsCustom(); ok {
+		_spec.SetField(authcode.FieldClaimsCustom, field.TypeJSON, value)
+	}
+	if acuo.mutation.ClaimsCustomCleared() {
+		_spec.ClearField(authcode.FieldClaimsCustom, field.TypeJSON)
+	}
+	if value, ok := acuo.mutation.ClaimsRequest(); ok {
+		_spec.SetField(authcode.FieldClaimsRequest, field.TypeBytes, value)
+	}
+	if acuo.mutation.ClaimsRequestCleared() {
+		_spec.ClearField(authcode.FieldClaimsRequest, field.TypeBytes)
+	}
+	if value, ok := acuo.mutation.ClaimsSources(); ok {
+		_spec.SetField(authcode.FieldClaimsSources, field.TypeJSON, value)
+	}
+	if acuo.mutation.ClaimsSourcesCleared() {
+		_spec.ClearField(authcode.FieldClaimsSources, field.TypeJSON)
+	}
+	if value, ok := acuo.mutation.Issuer(); ok {
+		_spec.SetField(authcode.FieldIssuer, field.TypeString, value)
+	}
 	_node = &AuthCode{config: acuo.config}
 	_spec.Assign = _node.assignValues
 	_spec.ScanValues = _node.scanValues
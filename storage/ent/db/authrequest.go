@@ -10,6 +10,7 @@ import (
 
 	"entgo.io/ent"
 	"entgo.io/ent/dialect/sql"
+	"github.com/dexidp/dex/storage"
 	"github.com/dexidp/dex/storage/ent/db/authrequest"
 )
 
@@ -57,8 +58,14 @@ type AuthRequest struct {
 	// CodeChallengeMethod holds the value of the "code_challenge_method" field.
 	CodeChallengeMethod string `json:"code_challenge_method,omitempty"`
 	// HmacKey holds the value of the "hmac_key" field.
-	HmacKey      []byte `json:"hmac_key,omitempty"`
-	selectValues sql.SelectValues
+	HmacKey []byte `json:"hmac_key,omitempty"`
+	// ClaimsCustom holds the value of the "claims_custom" field.
+	ClaimsCustom map[string]interface{} `json:"claims_custom,omitempty"`
+	// ClaimsRequest holds the value of the "claims_request" field.
+	ClaimsRequest *[]byte `json:"claims_request,omitempty"`
+	// ClaimsSources holds the value of the "claims_sources" field.
+	ClaimsSources map[string]storage.ClaimSource `json:"claims_sources,omitempty"`
+	selectValues  sql.SelectValues
 }
 
 // scanValues returns the types for scanning values from sql.Rows.
@@ -66,7 +73,7 @@ func (*AuthRequest) scanValues(columns []string) ([]any, error) {
 	values := make([]any, len(columns))
 	for i := range columns {
 		switch columns[i] {
-		case authrequest.FieldScopes, authrequest.FieldResponseTypes, authrequest.FieldClaimsGroups, authrequest.FieldConnectorData, authrequest.FieldHmacKey:
+		case authrequest.FieldScopes, authrequest.FieldResponseTypes, authrequest.FieldClaimsGroups, authrequest.FieldConnectorData, authrequest.FieldHmacKey, authrequest.FieldClaimsCustom, authrequest.FieldClaimsRequest, authrequest.FieldClaimsSources:
 			values[i] = new([]byte)
 		case authrequest.FieldForceApprovalPrompt, authrequest.FieldLoggedIn, authrequest.FieldClaimsEmailVerified:
 			values[i] = new(sql.NullBool)
@@ -221,6 +228,28 @@ func (ar *AuthRequest) assignValues(columns []string, values []any) error {
 			} else if value != nil {
 				ar.HmacKey = *value
 			}
+		case authrequest.FieldClaimsCustom:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field claims_custom", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &ar.ClaimsCustom); err != nil {
+					return fmt.Errorf("unmarshal field claims_custom: %w", err)
+				}
+			}
+		case authrequest.FieldClaimsRequest:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field claims_request", values[i])
+			} else if value != nil {
+				ar.ClaimsRequest = value
+			}
+		case authrequest.FieldClaimsSources:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field claims_sources", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &ar.ClaimsSources); err != nil {
+					return fmt.Errorf("unmarshal field claims_sources: %w", err)
+				}
+			}
 		default:
 			ar.selectValues.Set(columns[i], values[i])
 		}
@@ -318,6 +347,17 @@ func (ar *AuthRequest) String() string {
 	builder.WriteString(", ")
 	builder.WriteString("hmac_key=")
 	builder.WriteString(fmt.Sprintf("%v", ar.HmacKey))
+	builder.WriteString(", ")
+	builder.WriteString("claims_custom=")
+	builder.WriteString(fmt.Sprintf("%v", ar.ClaimsCustom))
+	builder.WriteString(", ")
+	if v := ar.ClaimsRequest; v != nil {
+		builder.WriteString("claims_request=")
+		builder.WriteString(fmt.Sprintf("%v", *v))
+	}
+	builder.WriteString(", ")
+	builder.WriteString("claims_sources=")
+	builder.WriteString(fmt.Sprintf("%v", ar.ClaimsSources))
 	builder.WriteByte(')')
 	return builder.String()
 }
@@ -51,6 +51,12 @@ const (
 	FieldCodeChallengeMethod = "code_challenge_method"
 	// FieldHmacKey holds the string denoting the hmac_key field in the database.
 	FieldHmacKey = "hmac_key"
+	// FieldClaimsCustom holds the string denoting the claims_custom field in the database.
+	FieldClaimsCustom = "claims_custom"
+	// FieldClaimsRequest holds the string denoting the claims_request field in the database.
+	FieldClaimsRequest = "claims_request"
+	// FieldClaimsSources holds the string denoting the claims_sources field in the database.
+	FieldClaimsSources = "claims_sources"
 	// Table holds the table name of the authrequest in the database.
 	Table = "auth_requests"
 )
@@ -78,6 +84,9 @@ var Columns = []string{
 	FieldCodeChallenge,
 	FieldCodeChallengeMethod,
 	FieldHmacKey,
+	FieldClaimsCustom,
+	FieldClaimsRequest,
+	FieldClaimsSources,
 }
 
 // ValidColumn reports if the column name is valid (part of the table columns).
@@ -149,6 +149,11 @@ func HmacKey(v []byte) predicate.AuthRequest {
 	return predicate.AuthRequest(sql.FieldEQ(FieldHmacKey, v))
 }
 
+// ClaimsRequest applies equality check predicate on the "claims_request" field. It's identical to ClaimsRequestEQ.
+func ClaimsRequest(v []byte) predicate.AuthRequest {
+	return predicate.AuthRequest(sql.FieldEQ(FieldClaimsRequest, v))
+}
+
 // ClientIDEQ applies the EQ predicate on the "client_id" field.
 func ClientIDEQ(v string) predicate.AuthRequest {
 	return predicate.AuthRequest(sql.FieldEQ(FieldClientID, v))
@@ -1054,6 +1059,76 @@ func HmacKeyLTE(v []byte) predicate.AuthRequest {
 	return predicate.AuthRequest(sql.FieldLTE(FieldHmacKey, v))
 }
 
+// ClaimsCustomIsNil applies the IsNil predicate on the "claims_custom" field.
+func ClaimsCustomIsNil() predicate.AuthRequest {
+	return predicate.AuthRequest(sql.FieldIsNull(FieldClaimsCustom))
+}
+
+// ClaimsCustomNotNil applies the NotNil predicate on the "claims_custom" field.
+func ClaimsCustomNotNil() predicate.AuthRequest {
+	return predicate.AuthRequest(sql.FieldNotNull(FieldClaimsCustom))
+}
+
+// ClaimsRequestEQ applies the EQ predicate on the "claims_request" field.
+func ClaimsRequestEQ(v []byte) predicate.AuthRequest {
+	return predicate.AuthRequest(sql.FieldEQ(FieldClaimsRequest, v))
+}
+
+// ClaimsRequestNEQ applies the NEQ predicate on the "claims_request" field.
+func ClaimsRequestNEQ(v []byte) predicate.AuthRequest {
+	return predicate.AuthRequest(sql.FieldNEQ(FieldClaimsRequest, v))
+}
+
+// ClaimsRequestIn applies the In predicate on the "claims_request" field.
+func ClaimsRequestIn(vs ...[]byte) predicate.AuthRequest {
+	return predicate.AuthRequest(sql.FieldIn(FieldClaimsRequest, vs...))
+}
+
+// ClaimsRequestNotIn applies the NotIn predicate on the "claims_request" field.
+func ClaimsRequestNotIn(vs ...[]byte) predicate.AuthRequest {
+	return predicate.AuthRequest(sql.FieldNotIn(FieldClaimsRequest, vs...))
+}
+
+// ClaimsRequestGT applies the GT predicate on the "claims_request" field.
+func ClaimsRequestGT(v []byte) predicate.AuthRequest {
+	return predicate.AuthRequest(sql.FieldGT(FieldClaimsRequest, v))
+}
+
+// ClaimsRequestGTE applies the GTE predicate on the "claims_request" field.
+func ClaimsRequestGTE(v []byte) predicate.AuthRequest {
+	return predicate.AuthRequest(sql.FieldGTE(FieldClaimsRequest, v))
+}
+
+// ClaimsRequestLT applies the LT predicate on the "claims_request" field.
+func ClaimsRequestLT(v []byte) predicate.AuthRequest {
+	return predicate.AuthRequest(sql.FieldLT(FieldClaimsRequest, v))
+}
+
+// ClaimsRequestLTE applies the LTE predicate on the "claims_request" field.
+func ClaimsRequestLTE(v []byte) predicate.AuthRequest {
+	return predicate.AuthRequest(sql.FieldLTE(FieldClaimsRequest, v))
+}
+
+// ClaimsRequestIsNil applies the IsNil predicate on the "claims_request" field.
+func ClaimsRequestIsNil() predicate.AuthRequest {
+	return predicate.AuthRequest(sql.FieldIsNull(FieldClaimsRequest))
+}
+
+// ClaimsRequestNotNil applies the NotNil predicate on the "claims_request" field.
+func ClaimsRequestNotNil() predicate.AuthRequest {
+	return predicate.AuthRequest(sql.FieldNotNull(FieldClaimsRequest))
+}
+
+// ClaimsSourcesIsNil applies the IsNil predicate on the "claims_sources" field.
+func ClaimsSourcesIsNil() predicate.AuthRequest {
+	return predicate.AuthRequest(sql.FieldIsNull(FieldClaimsSources))
+}
+
+// ClaimsSourcesNotNil applies the NotNil predicate on the "claims_sources" field.
+func ClaimsSourcesNotNil() predicate.AuthRequest {
+	return predicate.AuthRequest(sql.FieldNotNull(FieldClaimsSources))
+}
+
 // And groups predicates with the AND operator between them.
 func And(predicates ...predicate.AuthRequest) predicate.AuthRequest {
 	return predicate.AuthRequest(sql.AndPredicates(predicates...))
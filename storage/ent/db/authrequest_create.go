@@ -10,6 +10,7 @@ import (
 
 	"entgo.io/ent/dialect/sql/sqlgraph"
 	"entgo.io/ent/schema/field"
+	"github.com/dexidp/dex/storage"
 	"github.com/dexidp/dex/storage/ent/db/authrequest"
 )
 
@@ -164,6 +165,24 @@ func (arc *AuthRequestCreate) SetHmacKey(b []byte) *AuthRequestCreate {
 	return arc
 }
 
+// SetClaimsCustom sets the "claims_custom" field.
+func (arc *AuthRequestCreate) SetClaimsCustom(m map[string]interface{}) *AuthRequestCreate {
+	arc.mutation.SetClaimsCustom(m)
+	return arc
+}
+
+// SetClaimsRequest sets the "claims_request" field.
+func (arc *AuthRequestCreate) SetClaimsRequest(b []byte) *AuthRequestCreate {
+	arc.mutation.SetClaimsRequest(b)
+	return arc
+}
+
+// SetClaimsSources sets the "claims_sources" field.
+func (arc *AuthRequestCreate) SetClaimsSources(m map[string]storage.ClaimSource) *AuthRequestCreate {
+	arc.mutation.SetClaimsSources(m)
+	return arc
+}
+
 // SetID sets the "id" field.
 func (arc *AuthRequestCreate) SetID(s string) *AuthRequestCreate {
 	arc.mutation.SetID(s)
@@ -389,6 +408,18 @@ func (arc *AuthRequestCreate) createSpec() (*AuthRequest, *sqlgraph.CreateSpec)
 		_spec.SetField(authrequest.FieldHmacKey, field.TypeBytes, value)
 		_node.HmacKey = value
 	}
+	if value, ok := arc.mutation.ClaimsCustom(); ok {
+		_spec.SetField(authrequest.FieldClaimsCustom, field.TypeJSON, value)
+		_node.ClaimsCustom = value
+	}
+	if value, ok := arc.mutation.ClaimsRequest(); ok {
+		_spec.SetField(authrequest.FieldClaimsRequest, field.TypeBytes, value)
+		_node.ClaimsRequest = &value
+	}
+	if value, ok := arc.mutation.ClaimsSources(); ok {
+		_spec.SetField(authrequest.FieldClaimsSources, field.TypeJSON, value)
+		_node.ClaimsSources = value
+	}
 	return _node, _spec
 }
 
@@ -12,6 +12,7 @@ import (
 	"entgo.io/ent/dialect/sql/sqlgraph"
 	"entgo.io/ent/dialect/sql/sqljson"
 	"entgo.io/ent/schema/field"
+	"github.com/dexidp/dex/storage"
 	"github.com/dexidp/dex/storage/ent/db/authrequest"
 	"github.com/dexidp/dex/storage/ent/db/predicate"
 )
@@ -311,6 +312,42 @@ func (aru *AuthRequestUpdate) SetHmacKey(b []byte) *AuthRequestUpdate {
 	return aru
 }
 
+// SetClaimsCustom sets the "claims_custom" field.
+func (aru *AuthRequestUpdate) SetClaimsCustom(m map[string]interface{}) *AuthRequestUpdate {
+	aru.mutation.SetClaimsCustom(m)
+	return aru
+}
+
+// ClearClaimsCustom clears the value of the "claims_custom" field.
+func (aru *AuthRequestUpdate) ClearClaimsCustom() *AuthRequestUpdate {
+	aru.mutation.ClearClaimsCustom()
+	return aru
+}
+
+// SetClaimsRequest sets the "claims_request" field.
+func (aru *AuthRequestUpdate) SetClaimsRequest(b []byte) *AuthRequestUpdate {
+	aru.mutation.SetClaimsRequest(b)
+	return aru
+}
+
+// ClearClaimsRequest clears the value of the "claims_request" field.
+func (aru *AuthRequestUpdate) ClearClaimsRequest() *AuthRequestUpdate {
+	aru.mutation.ClearClaimsRequest()
+	return aru
+}
+
+// SetClaimsSources sets the "claims_sources" field.
+func (aru *AuthRequestUpdate) SetClaimsSources(m map[string]storage.ClaimSource) *AuthRequestUpdate {
+	aru.mutation.SetClaimsSources(m)
+	return aru
+}
+
+// ClearClaimsSources clears the value of the "claims_sources" field.
+func (aru *AuthRequestUpdate) ClearClaimsSources() *AuthRequestUpdate {
+	aru.mutation.ClearClaimsSources()
+	return aru
+}
+
 // Mutation returns the AuthRequestMutation object of the builder.
 func (aru *AuthRequestUpdate) Mutation() *AuthRequestMutation {
 	return aru.mutation
@@ -439,6 +476,24 @@ func (aru *AuthRequestUpdate) sqlSave(ctx context.Context) (n int, err error) {
 	if value, ok := aru.mutation.HmacKey(); ok {
 		_spec.SetField(authrequest.FieldHmacKey, field.TypeBytes, value)
 	}
+	if value, ok := aru.mutation.ClaimsCustom(); ok {
+		_spec.SetField(authrequest.FieldClaimsCustom, field.TypeJSON, value)
+	}
+	if aru.mutation.ClaimsCustomCleared() {
+		_spec.ClearField(authrequest.FieldClaimsCustom, field.TypeJSON)
+	}
+	if value, ok := aru.mutation.ClaimsRequest(); ok {
+		_spec.SetField(authrequest.FieldClaimsRequest, field.TypeBytes, value)
+	}
+	if aru.mutation.ClaimsRequestCleared() {
+		_spec.ClearField(authrequest.FieldClaimsRequest, field.TypeBytes)
+	}
+	if value, ok := aru.mutation.ClaimsSources(); ok {
+		_spec.SetField(authrequest.FieldClaimsSources, field.TypeJSON, value)
+	}
+	if aru.mutation.ClaimsSourcesCleared() {
+		_spec.ClearField(authrequest.FieldClaimsSources, field.TypeJSON)
+	}
 	if n, err = sqlgraph.UpdateNodes(ctx, aru.driver, _spec); err != nil {
 		if _, ok := err.(*sqlgraph.NotFoundError); ok {
 			err = &NotFoundError{authrequest.Label}
@@ -741,6 +796,42 @@ func (aruo *AuthRequestUpdateOne) SetHmacKey(b []byte) *AuthRequestUpdateOne {
 	return aruo
 }
 
+// SetClaimsCustom sets the "claims_custom" field.
+func (aruo *AuthRequestUpdateOne) SetClaimsCustom(m map[string]interface{}) *AuthRequestUpdateOne {
+	aruo.mutation.SetClaimsCustom(m)
+	return aruo
+}
+
+// ClearClaimsCustom clears the value of the "claims_custom" field.
+func (aruo *AuthRequestUpdateOne) ClearClaimsCustom() *AuthRequestUpdateOne {
+	aruo.mutation.ClearClaimsCustom()
+	return aruo
+}
+
+// SetClaimsRequest sets the "claims_request" field.
+func (aruo *AuthRequestUpdateOne) SetClaimsRequest(b []byte) *AuthRequestUpdateOne {
+	aruo.mutation.SetClaimsRequest(b)
+	return aruo
+}
+
+// ClearClaimsRequest clears the value of the "claims_request" field.
+func (aruo *AuthRequestUpdateOne) ClearClaimsRequest() *AuthRequestUpdateOne {
+	aruo.mutation.ClearClaimsRequest()
+	return aruo
+}
+
+// SetClaimsSources sets the "claims_sources" field.
+func (aruo *AuthRequestUpdateOne) SetClaimsSources(m map[string]storage.ClaimSource) *AuthRequestUpdateOne {
+	aruo.mutation.SetClaimsSources(m)
+	return aruo
+}
+
+// ClearClaimsSources clears the value of the "claims_sources" field.
+func (aruo *AuthRequestUpdateOne) ClearClaimsSources() *AuthRequestUpdateOne {
+	aruo.mutation.ClearClaimsSources()
+	return aruo
+}
+
 // Mutation returns the AuthRequestMutation object of the builder.
 func (aruo *AuthRequestUpdateOne) Mutation() *AuthRequestMutation {
 	return aruo.mutation
@@ -899,6 +990,24 @@ func (aruo *AuthRequestUpdateOne) sqlSave(ctx context.Context) (_node *AuthReque
 	if value, ok := aruo.mutation.HmacKey(); ok {
 		_spec.SetField(authrequest.FieldHmacKey, field.TypeBytes, value)
 	}
+	if value, ok := aruo.mutation.ClaimsCustom(); ok {
+		_spec.SetField(authrequest.FieldClaimsCustom, field.TypeJSON, value)
+	}
+	if aruo.mutation.ClaimsCustomCleared() {
+		_spec.ClearField(authrequest.FieldClaimsCustom, field.TypeJSON)
+	}
+	if value, ok := aruo.mutation.ClaimsRequest(); ok {
+		_spec.SetField(authrequest.FieldClaimsRequest, field.TypeBytes, value)
+	}
+	if aruo.mutation.ClaimsRequestCleared() {
+		_spec.ClearField(authrequest.FieldClaimsRequest, field.TypeBytes)
+	}
+	if value, ok := aruo.mutation.ClaimsSources(); ok {
+		_spec.SetField(authrequest.FieldClaimsSources, field.TypeJSON, value)
+	}
+	if aruo.mutation.ClaimsSourcesCleared() {
+		_spec.ClearField(authrequest.FieldClaimsSources, field.TypeJSON)
+	}
 	_node = &AuthRequest{config: aruo.config}
 	_spec.Assign = _node.assignValues
 	_spec.ScanValues = _node.scanValues
@@ -19,6 +19,14 @@ const (
 	FieldResourceVersion = "resource_version"
 	// FieldConfig holds the string denoting the config field in the database.
 	FieldConfig = "config"
+	// FieldCreatedBy holds the string denoting the created_by field in the database.
+	FieldCreatedBy = "created_by"
+	// FieldUpdatedAt holds the string denoting the updated_at field in the database.
+	FieldUpdatedAt = "updated_at"
+	// FieldDeletedAt holds the string denoting the deleted_at field in the database.
+	FieldDeletedAt = "deleted_at"
+	// FieldDisplay holds the string denoting the display field in the database.
+	FieldDisplay = "display"
 	// Table holds the table name of the connector in the database.
 	Table = "connectors"
 )
@@ -30,6 +38,10 @@ var Columns = []string{
 	FieldName,
 	FieldResourceVersion,
 	FieldConfig,
+	FieldCreatedBy,
+	FieldUpdatedAt,
+	FieldDeletedAt,
+	FieldDisplay,
 }
 
 // ValidColumn reports if the column name is valid (part of the table columns).
@@ -73,3 +85,18 @@ func ByName(opts ...sql.OrderTermOption) OrderOption {
 func ByResourceVersion(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldResourceVersion, opts...).ToFunc()
 }
+
+// ByCreatedBy orders the results by the created_by field.
+func ByCreatedBy(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldCreatedBy, opts...).ToFunc()
+}
+
+// ByUpdatedAt orders the results by the updated_at field.
+func ByUpdatedAt(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldUpdatedAt, opts...).ToFunc()
+}
+
+// ByDeletedAt orders the results by the deleted_at field.
+func ByDeletedAt(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldDeletedAt, opts...).ToFunc()
+}
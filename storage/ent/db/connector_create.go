@@ -6,9 +6,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"entgo.io/ent/dialect/sql/sqlgraph"
 	"entgo.io/ent/schema/field"
+	"github.com/dexidp/dex/storage"
 	"github.com/dexidp/dex/storage/ent/db/connector"
 )
 
@@ -43,6 +45,54 @@ func (cc *ConnectorCreate) SetConfig(b []byte) *ConnectorCreate {
 	return cc
 }
 
+// SetCreatedBy sets the "created_by" field.
+func (cc *ConnectorCreate) SetCreatedBy(s string) *ConnectorCreate {
+	cc.mutation.SetCreatedBy(s)
+	return cc
+}
+
+// SetNillableCreatedBy sets the "created_by" field if the given value is not nil.
+func (cc *ConnectorCreate) SetNillableCreatedBy(s *string) *ConnectorCreate {
+	if s != nil {
+		cc.SetCreatedBy(*s)
+	}
+	return cc
+}
+
+// SetUpdatedAt sets the "updated_at" field.
+func (cc *ConnectorCreate) SetUpdatedAt(t time.Time) *ConnectorCreate {
+	cc.mutation.SetUpdatedAt(t)
+	return cc
+}
+
+// SetNillableUpdatedAt sets the "updated_at" field if the given value is not nil.
+func (cc *ConnectorCreate) SetNillableUpdatedAt(t *time.Time) *ConnectorCreate {
+	if t != nil {
+		cc.SetUpdatedAt(*t)
+	}
+	return cc
+}
+
+// SetDeletedAt sets the "deleted_at" field.
+func (cc *ConnectorCreate) SetDeletedAt(t time.Time) *ConnectorCreate {
+	cc.mutation.SetDeletedAt(t)
+	return cc
+}
+
+// SetNillableDeletedAt sets the "deleted_at" field if the given value is not nil.
+func (cc *ConnectorCreate) SetNillableDeletedAt(t *time.Time) *ConnectorCreate {
+	if t != nil {
+		cc.SetDeletedAt(*t)
+	}
+	return cc
+}
+
+// SetDisplay sets the "display" field.
+func (cc *ConnectorCreate) SetDisplay(sd *storage.ConnectorDisplay) *ConnectorCreate {
+	cc.mutation.SetDisplay(sd)
+	return cc
+}
+
 // SetID sets the "id" field.
 func (cc *ConnectorCreate) SetID(s string) *ConnectorCreate {
 	cc.mutation.SetID(s)
@@ -161,6 +211,22 @@ func (cc *ConnectorCreate) createSpec() (*Connector, *sqlgraph.CreateSpec) {
 		_spec.SetField(connector.FieldConfig, field.TypeBytes, value)
 		_node.Config = value
 	}
+	if value, ok := cc.mutation.CreatedBy(); ok {
+		_spec.SetField(connector.FieldCreatedBy, field.TypeString, value)
+		_node.CreatedBy = value
+	}
+	if value, ok := cc.mutation.UpdatedAt(); ok {
+		_spec.SetField(connector.FieldUpdatedAt, field.TypeTime, value)
+		_node.UpdatedAt = value
+	}
+	if value, ok := cc.mutation.DeletedAt(); ok {
+		_spec.SetField(connector.FieldDeletedAt, field.TypeTime, value)
+		_node.DeletedAt = value
+	}
+	if value, ok := cc.mutation.Display(); ok {
+		_spec.SetField(connector.FieldDisplay, field.TypeJSON, value)
+		_node.Display = value
+	}
 	return _node, _spec
 }
 
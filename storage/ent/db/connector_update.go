@@ -6,10 +6,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"entgo.io/ent/dialect/sql"
 	"entgo.io/ent/dialect/sql/sqlgraph"
 	"entgo.io/ent/schema/field"
+	"github.com/dexidp/dex/storage"
 	"github.com/dexidp/dex/storage/ent/db/connector"
 	"github.com/dexidp/dex/storage/ent/db/predicate"
 )
@@ -75,6 +77,78 @@ func (cu *ConnectorUpdate) SetConfig(b []byte) *ConnectorUpdate {
 	return cu
 }
 
+// SetCreatedBy sets the "created_by" field.
+func (cu *ConnectorUpdate) SetCreatedBy(s string) *ConnectorUpdate {
+	cu.mutation.SetCreatedBy(s)
+	return cu
+}
+
+// SetNillableCreatedBy sets the "created_by" field if the given value is not nil.
+func (cu *ConnectorUpdate) SetNillableCreatedBy(s *string) *ConnectorUpdate {
+	if s != nil {
+		cu.SetCreatedBy(*s)
+	}
+	return cu
+}
+
+// ClearCreatedBy clears the value of the "created_by" field.
+func (cu *ConnectorUpdate) ClearCreatedBy() *ConnectorUpdate {
+	cu.mutation.ClearCreatedBy()
+	return cu
+}
+
+// SetUpdatedAt sets the "updated_at" field.
+func (cu *ConnectorUpdate) SetUpdatedAt(t time.Time) *ConnectorUpdate {
+	cu.mutation.SetUpdatedAt(t)
+	return cu
+}
+
+// SetNillableUpdatedAt sets the "updated_at" field if the given value is not nil.
+func (cu *ConnectorUpdate) SetNillableUpdatedAt(t *time.Time) *ConnectorUpdate {
+	if t != nil {
+		cu.SetUpdatedAt(*t)
+	}
+	return cu
+}
+
+// ClearUpdatedAt clears the value of the "updated_at" field.
+func (cu *ConnectorUpdate) ClearUpdatedAt() *ConnectorUpdate {
+	cu.mutation.ClearUpdatedAt()
+	return cu
+}
+
+// SetDeletedAt sets the "deleted_at" field.
+func (cu *ConnectorUpdate) SetDeletedAt(t time.Time) *ConnectorUpdate {
+	cu.mutation.SetDeletedAt(t)
+	return cu
+}
+
+// SetNillableDeletedAt sets the "deleted_at" field if the given value is not nil.
+func (cu *ConnectorUpdate) SetNillableDeletedAt(t *time.Time) *ConnectorUpdate {
+	if t != nil {
+		cu.SetDeletedAt(*t)
+	}
+	return cu
+}
+
+// ClearDeletedAt clears the value of the "deleted_at" field.
+func (cu *ConnectorUpdate) ClearDeletedAt() *ConnectorUpdate {
+	cu.mutation.ClearDeletedAt()
+	return cu
+}
+
+// SetDisplay sets the "display" field.
+func (cu *ConnectorUpdate) SetDisplay(sd *storage.ConnectorDisplay) *ConnectorUpdate {
+	cu.mutation.SetDisplay(sd)
+	return cu
+}
+
+// ClearDisplay clears the value of the "display" field.
+func (cu *ConnectorUpdate) ClearDisplay() *ConnectorUpdate {
+	cu.mutation.ClearDisplay()
+	return cu
+}
+
 // Mutation returns the ConnectorMutation object of the builder.
 func (cu *ConnectorUpdate) Mutation() *ConnectorMutation {
 	return cu.mutation
@@ -146,6 +220,30 @@ func (cu *ConnectorUpdate) sqlSave(ctx context.Context) (n int, err error) {
 	if value, ok := cu.mutation.Config(); ok {
 		_spec.SetField(connector.FieldConfig, field.TypeBytes, value)
 	}
+	if value, ok := cu.mutation.CreatedBy(); ok {
+		_spec.SetField(connector.FieldCreatedBy, field.TypeString, value)
+	}
+	if cu.mutation.CreatedByCleared() {
+		_spec.ClearField(connector.FieldCreatedBy, field.TypeString)
+	}
+	if value, ok := cu.mutation.UpdatedAt(); ok {
+		_spec.SetField(connector.FieldUpdatedAt, field.TypeTime, value)
+	}
+	if cu.mutation.UpdatedAtCleared() {
+		_spec.ClearField(connector.FieldUpdatedAt, field.TypeTime)
+	}
+	if value, ok := cu.mutation.DeletedAt(); ok {
+		_spec.SetField(connector.FieldDeletedAt, field.TypeTime, value)
+	}
+	if cu.mutation.DeletedAtCleared() {
+		_spec.ClearField(connector.FieldDeletedAt, field.TypeTime)
+	}
+	if value, ok := cu.mutation.Display(); ok {
+		_spec.SetField(connector.FieldDisplay, field.TypeJSON, value)
+	}
+	if cu.mutation.DisplayCleared() {
+		_spec.ClearField(connector.FieldDisplay, field.TypeJSON)
+	}
 	if n, err = sqlgraph.UpdateNodes(ctx, cu.driver, _spec); err != nil {
 		if _, ok := err.(*sqlgraph.NotFoundError); ok {
 			err = &NotFoundError{connector.Label}
@@ -214,6 +312,78 @@ func (cuo *ConnectorUpdateOne) SetConfig(b []byte) *ConnectorUpdateOne {
 	return cuo
 }
 
+// SetCreatedBy sets the "created_by" field.
+func (cuo *ConnectorUpdateOne) SetCreatedBy(s string) *ConnectorUpdateOne {
+	cuo.mutation.SetCreatedBy(s)
+	return cuo
+}
+
+// SetNillableCreatedBy sets the "created_by" field if the given value is not nil.
+func (cuo *ConnectorUpdateOne) SetNillableCreatedBy(s *string) *ConnectorUpdateOne {
+	if s != nil {
+		cuo.SetCreatedBy(*s)
+	}
+	return cuo
+}
+
+// ClearCreatedBy clears the value of the "created_by" field.
+func (cuo *ConnectorUpdateOne) ClearCreatedBy() *ConnectorUpdateOne {
+	cuo.mutation.ClearCreatedBy()
+	return cuo
+}
+
+// SetUpdatedAt sets the "updated_at" field.
+func (cuo *ConnectorUpdateOne) SetUpdatedAt(t time.Time) *ConnectorUpdateOne {
+	cuo.mutation.SetUpdatedAt(t)
+	return cuo
+}
+
+// SetNillableUpdatedAt sets the "updated_at" field if the given value is not nil.
+func (cuo *ConnectorUpdateOne) SetNillableUpdatedAt(t *time.Time) *ConnectorUpdateOne {
+	if t != nil {
+		cuo.SetUpdatedAt(*t)
+	}
+	return cuo
+}
+
+// ClearUpdatedAt clears the value of the "updated_at" field.
+func (cuo *ConnectorUpdateOne) ClearUpdatedAt() *ConnectorUpdateOne {
+	cuo.mutation.ClearUpdatedAt()
+	return cuo
+}
+
+// SetDeletedAt sets the "deleted_at" field.
+func (cuo *ConnectorUpdateOne) SetDeletedAt(t time.Time) *ConnectorUpdateOne {
+	cuo.mutation.SetDeletedAt(t)
+	return cuo
+}
+
+// SetNillableDeletedAt sets the "deleted_at" field if the given value is not nil.
+func (cuo *ConnectorUpdateOne) SetNillableDeletedAt(t *time.Time) *ConnectorUpdateOne {
+	if t != nil {
+		cuo.SetDeletedAt(*t)
+	}
+	return cuo
+}
+
+// ClearDeletedAt clears the value of the "deleted_at" field.
+func (cuo *ConnectorUpdateOne) ClearDeletedAt() *ConnectorUpdateOne {
+	cuo.mutation.ClearDeletedAt()
+	return cuo
+}
+
+// SetDisplay sets the "display" field.
+func (cuo *ConnectorUpdateOne) SetDisplay(sd *storage.ConnectorDisplay) *ConnectorUpdateOne {
+	cuo.mutation.SetDisplay(sd)
+	return cuo
+}
+
+// ClearDisplay clears the value of the "display" field.
+func (cuo *ConnectorUpdateOne) ClearDisplay() *ConnectorUpdateOne {
+	cuo.mutation.ClearDisplay()
+	return cuo
+}
+
 // Mutation returns the ConnectorMutation object of the builder.
 func (cuo *ConnectorUpdateOne) Mutation() *ConnectorMutation {
 	return cuo.mutation
@@ -315,6 +485,30 @@ func (cuo *ConnectorUpdateOne) sqlSave(ctx context.Context) (_node *Connector, e
 	if value, ok := cuo.mutation.Config(); ok {
 		_spec.SetField(connector.FieldConfig, field.TypeBytes, value)
 	}
+	if value, ok := cuo.mutation.CreatedBy(); ok {
+		_spec.SetField(connector.FieldCreatedBy, field.TypeString, value)
+	}
+	if cuo.mutation.CreatedByCleared() {
+		_spec.ClearField(connector.FieldCreatedBy, field.TypeString)
+	}
+	if value, ok := cuo.mutation.UpdatedAt(); ok {
+		_spec.SetField(connector.FieldUpdatedAt, field.TypeTime, value)
+	}
+	if cuo.mutation.UpdatedAtCleared() {
+		_spec.ClearField(connector.FieldUpdatedAt, field.TypeTime)
+	}
+	if value, ok := cuo.mutation.DeletedAt(); ok {
+		_spec.SetField(connector.FieldDeletedAt, field.TypeTime, value)
+	}
+	if cuo.mutation.DeletedAtCleared() {
+		_spec.ClearField(connector.FieldDeletedAt, field.TypeTime)
+	}
+	if value, ok := cuo.mutation.Display(); ok {
+		_spec.SetField(connector.FieldDisplay, field.TypeJSON, value)
+	}
+	if cuo.mutation.DisplayCleared() {
+		_spec.ClearField(connector.FieldDisplay, field.TypeJSON)
+	}
 	_node = &Connector{config: cuo.config}
 	_spec.Assign = _node.assignValues
 	_spec.ScanValues = _node.scanValues
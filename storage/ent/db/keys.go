@@ -28,6 +28,8 @@ type Keys struct {
 	SigningKeyPub jose.JSONWebKey `json:"signing_key_pub,omitempty"`
 	// NextRotation holds the value of the "next_rotation" field.
 	NextRotation time.Time `json:"next_rotation,omitempty"`
+	// IssuerKeys holds the value of the "issuer_keys" field.
+	IssuerKeys   map[string]storage.Keys `json:"issuer_keys,omitempty"`
 	selectValues sql.SelectValues
 }
 
@@ -36,7 +38,7 @@ func (*Keys) scanValues(columns []string) ([]any, error) {
 	values := make([]any, len(columns))
 	for i := range columns {
 		switch columns[i] {
-		case keys.FieldVerificationKeys, keys.FieldSigningKey, keys.FieldSigningKeyPub:
+		case keys.FieldVerificationKeys, keys.FieldSigningKey, keys.FieldSigningKeyPub, keys.FieldIssuerKeys:
 			values[i] = new([]byte)
 		case keys.FieldID:
 			values[i] = new(sql.NullString)
@@ -93,6 +95,14 @@ func (k *Keys) assignValues(columns []string, values []any) error {
 			} else if value.Valid {
 				k.NextRotation = value.Time
 			}
+		case keys.FieldIssuerKeys:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field issuer_keys", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &k.IssuerKeys); err != nil {
+					return fmt.Errorf("unmarshal field issuer_keys: %w", err)
+				}
+			}
 		default:
 			k.selectValues.Set(columns[i], values[i])
 		}
@@ -140,6 +150,9 @@ func (k *Keys) String() string {
 	builder.WriteString(", ")
 	builder.WriteString("next_rotation=")
 	builder.WriteString(k.NextRotation.Format(time.ANSIC))
+	builder.WriteString(", ")
+	builder.WriteString("issuer_keys=")
+	builder.WriteString(fmt.Sprintf("%v", k.IssuerKeys))
 	builder.WriteByte(')')
 	return builder.String()
 }
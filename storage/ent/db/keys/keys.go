@@ -19,6 +19,8 @@ const (
 	FieldSigningKeyPub = "signing_key_pub"
 	// FieldNextRotation holds the string denoting the next_rotation field in the database.
 	FieldNextRotation = "next_rotation"
+	// FieldIssuerKeys holds the string denoting the issuer_keys field in the database.
+	FieldIssuerKeys = "issuer_keys"
 	// Table holds the table name of the keys in the database.
 	Table = "keys"
 )
@@ -30,6 +32,7 @@ var Columns = []string{
 	FieldSigningKey,
 	FieldSigningKeyPub,
 	FieldNextRotation,
+	FieldIssuerKeys,
 }
 
 // ValidColumn reports if the column name is valid (part of the table columns).
@@ -109,6 +109,16 @@ func NextRotationLTE(v time.Time) predicate.Keys {
 	return predicate.Keys(sql.FieldLTE(FieldNextRotation, v))
 }
 
+// IssuerKeysIsNil applies the IsNil predicate on the "issuer_keys" field.
+func IssuerKeysIsNil() predicate.Keys {
+	return predicate.Keys(sql.FieldIsNull(FieldIssuerKeys))
+}
+
+// IssuerKeysNotNil applies the NotNil predicate on the "issuer_keys" field.
+func IssuerKeysNotNil() predicate.Keys {
+	return predicate.Keys(sql.FieldNotNull(FieldIssuerKeys))
+}
+
 // And groups predicates with the AND operator between them.
 func And(predicates ...predicate.Keys) predicate.Keys {
 	return predicate.Keys(sql.AndPredicates(predicates...))
@@ -46,6 +46,12 @@ func (kc *KeysCreate) SetNextRotation(t time.Time) *KeysCreate {
 	return kc
 }
 
+// SetIssuerKeys sets the "issuer_keys" field.
+func (kc *KeysCreate) SetIssuerKeys(m map[string]storage.Keys) *KeysCreate {
+	kc.mutation.SetIssuerKeys(m)
+	return kc
+}
+
 // SetID sets the "id" field.
 func (kc *KeysCreate) SetID(s string) *KeysCreate {
 	kc.mutation.SetID(s)
@@ -154,6 +160,10 @@ func (kc *KeysCreate) createSpec() (*Keys, *sqlgraph.CreateSpec) {
 		_spec.SetField(keys.FieldNextRotation, field.TypeTime, value)
 		_node.NextRotation = value
 	}
+	if value, ok := kc.mutation.IssuerKeys(); ok {
+		_spec.SetField(keys.FieldIssuerKeys, field.TypeJSON, value)
+		_node.IssuerKeys = value
+	}
 	return _node, _spec
 }
 
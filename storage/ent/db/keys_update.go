@@ -85,6 +85,18 @@ func (ku *KeysUpdate) SetNillableNextRotation(t *time.Time) *KeysUpdate {
 	return ku
 }
 
+// SetIssuerKeys sets the "issuer_keys" field.
+func (ku *KeysUpdate) SetIssuerKeys(m map[string]storage.Keys) *KeysUpdate {
+	ku.mutation.SetIssuerKeys(m)
+	return ku
+}
+
+// ClearIssuerKeys clears the value of the "issuer_keys" field.
+func (ku *KeysUpdate) ClearIssuerKeys() *KeysUpdate {
+	ku.mutation.ClearIssuerKeys()
+	return ku
+}
+
 // Mutation returns the KeysMutation object of the builder.
 func (ku *KeysUpdate) Mutation() *KeysMutation {
 	return ku.mutation
@@ -143,6 +155,12 @@ func (ku *KeysUpdate) sqlSave(ctx context.Context) (n int, err error) {
 	if value, ok := ku.mutation.NextRotation(); ok {
 		_spec.SetField(keys.FieldNextRotation, field.TypeTime, value)
 	}
+	if value, ok := ku.mutation.IssuerKeys(); ok {
+		_spec.SetField(keys.FieldIssuerKeys, field.TypeJSON, value)
+	}
+	if ku.mutation.IssuerKeysCleared() {
+		_spec.ClearField(keys.FieldIssuerKeys, field.TypeJSON)
+	}
 	if n, err = sqlgraph.UpdateNodes(ctx, ku.driver, _spec); err != nil {
 		if _, ok := err.(*sqlgraph.NotFoundError); ok {
 			err = &NotFoundError{keys.Label}
@@ -217,6 +235,18 @@ func (kuo *KeysUpdateOne) SetNillableNextRotation(t *time.Time) *KeysUpdateOne {
 	return kuo
 }
 
+// SetIssuerKeys sets the "issuer_keys" field.
+func (kuo *KeysUpdateOne) SetIssuerKeys(m map[string]storage.Keys) *KeysUpdateOne {
+	kuo.mutation.SetIssuerKeys(m)
+	return kuo
+}
+
+// ClearIssuerKeys clears the value of the "issuer_keys" field.
+func (kuo *KeysUpdateOne) ClearIssuerKeys() *KeysUpdateOne {
+	kuo.mutation.ClearIssuerKeys()
+	return kuo
+}
+
 // Mutation returns the KeysMutation object of the builder.
 func (kuo *KeysUpdateOne) Mutation() *KeysMutation {
 	return kuo.mutation
@@ -305,6 +335,12 @@ func (kuo *KeysUpdateOne) sqlSave(ctx context.Context) (_node *Keys, err error)
 	if value, ok := kuo.mutation.NextRotation(); ok {
 		_spec.SetField(keys.FieldNextRotation, field.TypeTime, value)
 	}
+	if value, ok := kuo.mutation.IssuerKeys(); ok {
+		_spec.SetField(keys.FieldIssuerKeys, field.TypeJSON, value)
+	}
+	if kuo.mutation.IssuerKeysCleared() {
+		_spec.ClearField(keys.FieldIssuerKeys, field.TypeJSON)
+	}
 	_node = &Keys{config: kuo.config}
 	_spec.Assign = _node.assignValues
 	_spec.ScanValues = _node.scanValues
@@ -23,6 +23,7 @@ import (
 	"github.com/dexidp/dex/storage/ent/db/password"
 	"github.com/dexidp/dex/storage/ent/db/predicate"
 	"github.com/dexidp/dex/storage/ent/db/refreshtoken"
+	"github.com/dexidp/dex/storage/ent/db/webhook"
 	jose "github.com/go-jose/go-jose/v4"
 )
 
@@ -45,6 +46,7 @@ const (
 	TypeOfflineSession = "OfflineSession"
 	TypePassword       = "Password"
 	TypeRefreshToken   = "RefreshToken"
+	TypeWebhook        = "Webhook"
 )
 
 // AuthCodeMutation represents an operation that mutates the AuthCode nodes in the graph.
@@ -70,6 +72,10 @@ type AuthCodeMutation struct {
 	expiry                    *time.Time
 	code_challenge            *string
 	code_challenge_method     *string
+	claims_custom             *map[string]interface{}
+	claims_request            *[]byte
+	claims_sources            *map[string]storage.ClaimSource
+	issuer                    *string
 	clearedFields             map[string]struct{}
 	done                      bool
 	oldValue                  func(context.Context) (*AuthCode, error)
@@ -791,6 +797,189 @@ func (m *AuthCodeMutation) ResetCodeChallengeMethod() {
 	m.code_challenge_method = nil
 }
 
+// SetClaimsCustom sets the "claims_custom" field.
+func (m *AuthCodeMutation) SetClaimsCustom(value map[string]interface{}) {
+	m.claims_custom = &value
+}
+
+// ClaimsCustom returns the value of the "claims_custom" field in the mutation.
+func (m *AuthCodeMutation) ClaimsCustom() (r map[string]interface{}, exists bool) {
+	v := m.claims_custom
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldClaimsCustom returns the old "claims_custom" field's value of the AuthCode entity.
+// If the AuthCode object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *AuthCodeMutation) OldClaimsCustom(ctx context.Context) (v map[string]interface{}, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldClaimsCustom is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldClaimsCustom requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldClaimsCustom: %w", err)
+	}
+	return oldValue.ClaimsCustom, nil
+}
+
+// ClearClaimsCustom clears the value of the "claims_custom" field.
+func (m *AuthCodeMutation) ClearClaimsCustom() {
+	m.claims_custom = nil
+	m.clearedFields[authcode.FieldClaimsCustom] = struct{}{}
+}
+
+// ClaimsCustomCleared returns if the "claims_custom" field was cleared in this mutation.
+func (m *AuthCodeMutation) ClaimsCustomCleared() bool {
+	_, ok := m.clearedFields[authcode.FieldClaimsCustom]
+	return ok
+}
+
+// ResetClaimsCustom resets all changes to the "claims_custom" field.
+func (m *AuthCodeMutation) ResetClaimsCustom() {
+	m.claims_custom = nil
+	delete(m.clearedFields, authcode.FieldClaimsCustom)
+}
+
+// SetClaimsRequest sets the "claims_request" field.
+func (m *AuthCodeMutation) SetClaimsRequest(b []byte) {
+	m.claims_request = &b
+}
+
+// ClaimsRequest returns the value of the "claims_request" field in the mutation.
+func (m *AuthCodeMutation) ClaimsRequest() (r []byte, exists bool) {
+	v := m.claims_request
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldClaimsRequest returns the old "claims_request" field's value of the AuthCode entity.
+// If the AuthCode object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *AuthCodeMutation) OldClaimsRequest(ctx context.Context) (v *[]byte, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldClaimsRequest is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldClaimsRequest requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldClaimsRequest: %w", err)
+	}
+	return oldValue.ClaimsRequest, nil
+}
+
+// ClearClaimsRequest clears the value of the "claims_request" field.
+func (m *AuthCodeMutation) ClearClaimsRequest() {
+	m.claims_request = nil
+	m.clearedFields[authcode.FieldClaimsRequest] = struct{}{}
+}
+
+// ClaimsRequestCleared returns if the "claims_request" field was cleared in this mutation.
+func (m *AuthCodeMutation) ClaimsRequestCleared() bool {
+	_, ok := m.clearedFields[authcode.FieldClaimsRequest]
+	return ok
+}
+
+// ResetClaimsRequest resets all changes to the "claims_request" field.
+func (m *AuthCodeMutation) ResetClaimsRequest() {
+	m.claims_request = nil
+	delete(m.clearedFields, authcode.FieldClaimsRequest)
+}
+
+// SetClaimsSources sets the "claims_sources" field.
+func (m *AuthCodeMutation) SetClaimsSources(value map[string]storage.ClaimSource) {
+	m.claims_sources = &value
+}
+
+// ClaimsSources returns the value of the "claims_sources" field in the mutation.
+func (m *AuthCodeMutation) ClaimsSources() (r map[string]storage.ClaimSource, exists bool) {
+	v := m.claims_sources
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldClaimsSources returns the old "claims_sources" field's value of the AuthCode entity.
+// If the AuthCode object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *AuthCodeMutation) OldClaimsSources(ctx context.Context) (v map[string]storage.ClaimSource, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldClaimsSources is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldClaimsSources requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldClaimsSources: %w", err)
+	}
+	return oldValue.ClaimsSources, nil
+}
+
+// ClearClaimsSources clears the value of the "claims_sources" field.
+func (m *AuthCodeMutation) ClearClaimsSources() {
+	m.claims_sources = nil
+	m.clearedFields[authcode.FieldClaimsSources] = struct{}{}
+}
+
+// ClaimsSourcesCleared returns if the "claims_sources" field was cleared in this mutation.
+func (m *AuthCodeMutation) ClaimsSourcesCleared() bool {
+	_, ok := m.clearedFields[authcode.FieldClaimsSources]
+	return ok
+}
+
+// ResetClaimsSources resets all changes to the "claims_sources" field.
+func (m *AuthCodeMutation) ResetClaimsSources() {
+	m.claims_sources = nil
+	delete(m.clearedFields, authcode.FieldClaimsSources)
+}
+
+// SetIssuer sets the "issuer" field.
+func (m *AuthCodeMutation) SetIssuer(s string) {
+	m.issuer = &s
+}
+
+// Issuer returns the value of the "issuer" field in the mutation.
+func (m *AuthCodeMutation) Issuer() (r string, exists bool) {
+	v := m.issuer
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldIssuer returns the old "issuer" field's value of the AuthCode entity.
+// If the AuthCode object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *AuthCodeMutation) OldIssuer(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldIssuer is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldIssuer requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldIssuer: %w", err)
+	}
+	return oldValue.Issuer, nil
+}
+
+// ResetIssuer resets all changes to the "issuer" field.
+func (m *AuthCodeMutation) ResetIssuer() {
+	m.issuer = nil
+}
+
 // Where appends a list predicates to the AuthCodeMutation builder.
 func (m *AuthCodeMutation) Where(ps ...predicate.AuthCode) {
 	m.predicates = append(m.predicates, ps...)
@@ -871,6 +1060,18 @@ func (m *AuthCodeMutation) Fields() []string {
 	if m.code_challenge_method != nil {
 		fields = append(fields, authcode.FieldCodeChallengeMethod)
 	}
+	if m.claims_custom != nil {
+		fields = append(fields, authcode.FieldClaimsCustom)
+	}
+	if m.claims_request != nil {
+		fields = append(fields, authcode.FieldClaimsRequest)
+	}
+	if m.claims_sources != nil {
+		fields = append(fields, authcode.FieldClaimsSources)
+	}
+	if m.issuer != nil {
+		fields = append(fields, authcode.FieldIssuer)
+	}
 	return fields
 }
 
@@ -909,6 +1110,14 @@ func (m *AuthCodeMutation) Field(name string) (ent.Value, bool) {
 		return m.CodeChallenge()
 	case authcode.FieldCodeChallengeMethod:
 		return m.CodeChallengeMethod()
+	case authcode.FieldClaimsCustom:
+		return m.ClaimsCustom()
+	case authcode.FieldClaimsRequest:
+		return m.ClaimsRequest()
+	case authcode.FieldClaimsSources:
+		return m.ClaimsSources()
+	case authcode.FieldIssuer:
+		return m.Issuer()
 	}
 	return nil, false
 }
@@ -948,6 +1157,14 @@ func (m *AuthCodeMutation) OldField(ctx context.Context, name string) (ent.Value
 		return m.OldCodeChallenge(ctx)
 	case authcode.FieldCodeChallengeMethod:
 		return m.OldCodeChallengeMethod(ctx)
+	case authcode.FieldClaimsCustom:
+		return m.OldClaimsCustom(ctx)
+	case authcode.FieldClaimsRequest:
+		return m.OldClaimsRequest(ctx)
+	case authcode.FieldClaimsSources:
+		return m.OldClaimsSources(ctx)
+	case authcode.FieldIssuer:
+		return m.OldIssuer(ctx)
 	}
 	return nil, fmt.Errorf("unknown AuthCode field %s", name)
 }
@@ -1062,6 +1279,34 @@ func (m *AuthCodeMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetCodeChallengeMethod(v)
 		return nil
+	case authcode.FieldClaimsCustom:
+		v, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetClaimsCustom(v)
+		return nil
+	case authcode.FieldClaimsRequest:
+		v, ok := value.([]byte)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetClaimsRequest(v)
+		return nil
+	case authcode.FieldClaimsSources:
+		v, ok := value.(map[string]storage.ClaimSource)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetClaimsSources(v)
+		return nil
+	case authcode.FieldIssuer:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetIssuer(v)
+		return nil
 	}
 	return fmt.Errorf("unknown AuthCode field %s", name)
 }
@@ -1101,6 +1346,15 @@ func (m *AuthCodeMutation) ClearedFields() []string {
 	if m.FieldCleared(authcode.FieldConnectorData) {
 		fields = append(fields, authcode.FieldConnectorData)
 	}
+	if m.FieldCleared(authcode.FieldClaimsCustom) {
+		fields = append(fields, authcode.FieldClaimsCustom)
+	}
+	if m.FieldCleared(authcode.FieldClaimsRequest) {
+		fields = append(fields, authcode.FieldClaimsRequest)
+	}
+	if m.FieldCleared(authcode.FieldClaimsSources) {
+		fields = append(fields, authcode.FieldClaimsSources)
+	}
 	return fields
 }
 
@@ -1124,6 +1378,15 @@ func (m *AuthCodeMutation) ClearField(name string) error {
 	case authcode.FieldConnectorData:
 		m.ClearConnectorData()
 		return nil
+	case authcode.FieldClaimsCustom:
+		m.ClearClaimsCustom()
+		return nil
+	case authcode.FieldClaimsRequest:
+		m.ClearClaimsRequest()
+		return nil
+	case authcode.FieldClaimsSources:
+		m.ClearClaimsSources()
+		return nil
 	}
 	return fmt.Errorf("unknown AuthCode nullable field %s", name)
 }
@@ -1177,6 +1440,18 @@ func (m *AuthCodeMutation) ResetField(name string) error {
 	case authcode.FieldCodeChallengeMethod:
 		m.ResetCodeChallengeMethod()
 		return nil
+	case authcode.FieldClaimsCustom:
+		m.ResetClaimsCustom()
+		return nil
+	case authcode.FieldClaimsRequest:
+		m.ResetClaimsRequest()
+		return nil
+	case authcode.FieldClaimsSources:
+		m.ResetClaimsSources()
+		return nil
+	case authcode.FieldIssuer:
+		m.ResetIssuer()
+		return nil
 	}
 	return fmt.Errorf("unknown AuthCode field %s", name)
 }
@@ -1258,6 +1533,9 @@ type AuthRequestMutation struct {
 	code_challenge            *string
 	code_challenge_method     *string
 	hmac_key                  *[]byte
+	claims_custom             *map[string]interface{}
+	claims_request            *[]byte
+	claims_sources            *map[string]storage.ClaimSource
 	clearedFields             map[string]struct{}
 	done                      bool
 	oldValue                  func(context.Context) (*AuthRequest, error)
@@ -2188,6 +2466,153 @@ func (m *AuthRequestMutation) ResetHmacKey() {
 	m.hmac_key = nil
 }
 
+// SetClaimsCustom sets the "claims_custom" field.
+func (m *AuthRequestMutation) SetClaimsCustom(value map[string]interface{}) {
+	m.claims_custom = &value
+}
+
+// ClaimsCustom returns the value of the "claims_custom" field in the mutation.
+func (m *AuthRequestMutation) ClaimsCustom() (r map[string]interface{}, exists bool) {
+	v := m.claims_custom
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldClaimsCustom returns the old "claims_custom" field's value of the AuthRequest entity.
+// If the AuthRequest object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *AuthRequestMutation) OldClaimsCustom(ctx context.Context) (v map[string]interface{}, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldClaimsCustom is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldClaimsCustom requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldClaimsCustom: %w", err)
+	}
+	return oldValue.ClaimsCustom, nil
+}
+
+// ClearClaimsCustom clears the value of the "claims_custom" field.
+func (m *AuthRequestMutation) ClearClaimsCustom() {
+	m.claims_custom = nil
+	m.clearedFields[authrequest.FieldClaimsCustom] = struct{}{}
+}
+
+// ClaimsCustomCleared returns if the "claims_custom" field was cleared in this mutation.
+func (m *AuthRequestMutation) ClaimsCustomCleared() bool {
+	_, ok := m.clearedFields[authrequest.FieldClaimsCustom]
+	return ok
+}
+
+// ResetClaimsCustom resets all changes to the "claims_custom" field.
+func (m *AuthRequestMutation) ResetClaimsCustom() {
+	m.claims_custom = nil
+	delete(m.clearedFields, authrequest.FieldClaimsCustom)
+}
+
+// SetClaimsRequest sets the "claims_request" field.
+func (m *AuthRequestMutation) SetClaimsRequest(b []byte) {
+	m.claims_request = &b
+}
+
+// ClaimsRequest returns the value of the "claims_request" field in the mutation.
+func (m *AuthRequestMutation) ClaimsRequest() (r []byte, exists bool) {
+	v := m.claims_request
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldClaimsRequest returns the old "claims_request" field's value of the AuthRequest entity.
+// If the AuthRequest object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *AuthRequestMutation) OldClaimsRequest(ctx context.Context) (v *[]byte, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldClaimsRequest is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldClaimsRequest requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldClaimsRequest: %w", err)
+	}
+	return oldValue.ClaimsRequest, nil
+}
+
+// ClearClaimsRequest clears the value of the "claims_request" field.
+func (m *AuthRequestMutation) ClearClaimsRequest() {
+	m.claims_request = nil
+	m.clearedFields[authrequest.FieldClaimsRequest] = struct{}{}
+}
+
+// ClaimsRequestCleared returns if the "claims_request" field was cleared in this mutation.
+func (m *AuthRequestMutation) ClaimsRequestCleared() bool {
+	_, ok := m.clearedFields[authrequest.FieldClaimsRequest]
+	return ok
+}
+
+// ResetClaimsRequest resets all changes to the "claims_request" field.
+func (m *AuthRequestMutation) ResetClaimsRequest() {
+	m.claims_request = nil
+	delete(m.clearedFields, authrequest.FieldClaimsRequest)
+}
+
+// SetClaimsSources sets the "claims_sources" field.
+func (m *AuthRequestMutation) SetClaimsSources(value map[string]storage.ClaimSource) {
+	m.claims_sources = &value
+}
+
+// ClaimsSources returns the value of the "claims_sources" field in the mutation.
+func (m *AuthRequestMutation) ClaimsSources() (r map[string]storage.ClaimSource, exists bool) {
+	v := m.claims_sources
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldClaimsSources returns the old "claims_sources" field's value of the AuthRequest entity.
+// If the AuthRequest object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *AuthRequestMutation) OldClaimsSources(ctx context.Context) (v map[string]storage.ClaimSource, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldClaimsSources is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldClaimsSources requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldClaimsSources: %w", err)
+	}
+	return oldValue.ClaimsSources, nil
+}
+
+// ClearClaimsSources clears the value of the "claims_sources" field.
+func (m *AuthRequestMutation) ClearClaimsSources() {
+	m.claims_sources = nil
+	m.clearedFields[authrequest.FieldClaimsSources] = struct{}{}
+}
+
+// ClaimsSourcesCleared returns if the "claims_sources" field was cleared in this mutation.
+func (m *AuthRequestMutation) ClaimsSourcesCleared() bool {
+	_, ok := m.clearedFields[authrequest.FieldClaimsSources]
+	return ok
+}
+
+// ResetClaimsSources resets all changes to the "claims_sources" field.
+func (m *AuthRequestMutation) ResetClaimsSources() {
+	m.claims_sources = nil
+	delete(m.clearedFields, authrequest.FieldClaimsSources)
+}
+
 // Where appends a list predicates to the AuthRequestMutation builder.
 func (m *AuthRequestMutation) Where(ps ...predicate.AuthRequest) {
 	m.predicates = append(m.predicates, ps...)
@@ -2283,6 +2708,15 @@ func (m *AuthRequestMutation) Fields() []string {
 	if m.hmac_key != nil {
 		fields = append(fields, authrequest.FieldHmacKey)
 	}
+	if m.claims_custom != nil {
+		fields = append(fields, authrequest.FieldClaimsCustom)
+	}
+	if m.claims_request != nil {
+		fields = append(fields, authrequest.FieldClaimsRequest)
+	}
+	if m.claims_sources != nil {
+		fields = append(fields, authrequest.FieldClaimsSources)
+	}
 	return fields
 }
 
@@ -2331,6 +2765,12 @@ func (m *AuthRequestMutation) Field(name string) (ent.Value, bool) {
 		return m.CodeChallengeMethod()
 	case authrequest.FieldHmacKey:
 		return m.HmacKey()
+	case authrequest.FieldClaimsCustom:
+		return m.ClaimsCustom()
+	case authrequest.FieldClaimsRequest:
+		return m.ClaimsRequest()
+	case authrequest.FieldClaimsSources:
+		return m.ClaimsSources()
 	}
 	return nil, false
 }
@@ -2380,6 +2820,12 @@ func (m *AuthRequestMutation) OldField(ctx context.Context, name string) (ent.Va
 		return m.OldCodeChallengeMethod(ctx)
 	case authrequest.FieldHmacKey:
 		return m.OldHmacKey(ctx)
+	case authrequest.FieldClaimsCustom:
+		return m.OldClaimsCustom(ctx)
+	case authrequest.FieldClaimsRequest:
+		return m.OldClaimsRequest(ctx)
+	case authrequest.FieldClaimsSources:
+		return m.OldClaimsSources(ctx)
 	}
 	return nil, fmt.Errorf("unknown AuthRequest field %s", name)
 }
@@ -2529,6 +2975,27 @@ func (m *AuthRequestMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetHmacKey(v)
 		return nil
+	case authrequest.FieldClaimsCustom:
+		v, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetClaimsCustom(v)
+		return nil
+	case authrequest.FieldClaimsRequest:
+		v, ok := value.([]byte)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetClaimsRequest(v)
+		return nil
+	case authrequest.FieldClaimsSources:
+		v, ok := value.(map[string]storage.ClaimSource)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetClaimsSources(v)
+		return nil
 	}
 	return fmt.Errorf("unknown AuthRequest field %s", name)
 }
@@ -2571,6 +3038,15 @@ func (m *AuthRequestMutation) ClearedFields() []string {
 	if m.FieldCleared(authrequest.FieldConnectorData) {
 		fields = append(fields, authrequest.FieldConnectorData)
 	}
+	if m.FieldCleared(authrequest.FieldClaimsCustom) {
+		fields = append(fields, authrequest.FieldClaimsCustom)
+	}
+	if m.FieldCleared(authrequest.FieldClaimsRequest) {
+		fields = append(fields, authrequest.FieldClaimsRequest)
+	}
+	if m.FieldCleared(authrequest.FieldClaimsSources) {
+		fields = append(fields, authrequest.FieldClaimsSources)
+	}
 	return fields
 }
 
@@ -2597,6 +3073,15 @@ func (m *AuthRequestMutation) ClearField(name string) error {
 	case authrequest.FieldConnectorData:
 		m.ClearConnectorData()
 		return nil
+	case authrequest.FieldClaimsCustom:
+		m.ClearClaimsCustom()
+		return nil
+	case authrequest.FieldClaimsRequest:
+		m.ClearClaimsRequest()
+		return nil
+	case authrequest.FieldClaimsSources:
+		m.ClearClaimsSources()
+		return nil
 	}
 	return fmt.Errorf("unknown AuthRequest nullable field %s", name)
 }
@@ -2665,6 +3150,15 @@ func (m *AuthRequestMutation) ResetField(name string) error {
 	case authrequest.FieldHmacKey:
 		m.ResetHmacKey()
 		return nil
+	case authrequest.FieldClaimsCustom:
+		m.ResetClaimsCustom()
+		return nil
+	case authrequest.FieldClaimsRequest:
+		m.ResetClaimsRequest()
+		return nil
+	case authrequest.FieldClaimsSources:
+		m.ResetClaimsSources()
+		return nil
 	}
 	return fmt.Errorf("unknown AuthRequest field %s", name)
 }
@@ -2727,6 +3221,10 @@ type ConnectorMutation struct {
 	name             *string
 	resource_version *string
 	_config          *[]byte
+	created_by       *string
+	updated_at       *time.Time
+	deleted_at       *time.Time
+	display          **storage.ConnectorDisplay
 	clearedFields    map[string]struct{}
 	done             bool
 	oldValue         func(context.Context) (*Connector, error)
@@ -2981,30 +3479,226 @@ func (m *ConnectorMutation) ResetConfig() {
 	m._config = nil
 }
 
-// Where appends a list predicates to the ConnectorMutation builder.
-func (m *ConnectorMutation) Where(ps ...predicate.Connector) {
-	m.predicates = append(m.predicates, ps...)
+// SetCreatedBy sets the "created_by" field.
+func (m *ConnectorMutation) SetCreatedBy(s string) {
+	m.created_by = &s
 }
 
-// WhereP appends storage-level predicates to the ConnectorMutation builder. Using this method,
-// users can use type-assertion to append predicates that do not depend on any generated package.
-func (m *ConnectorMutation) WhereP(ps ...func(*sql.Selector)) {
-	p := make([]predicate.Connector, len(ps))
-	for i := range ps {
-		p[i] = ps[i]
+// CreatedBy returns the value of the "created_by" field in the mutation.
+func (m *ConnectorMutation) CreatedBy() (r string, exists bool) {
+	v := m.created_by
+	if v == nil {
+		return
 	}
-	m.Where(p...)
-}
-
-// Op returns the operation name.
-func (m *ConnectorMutation) Op() Op {
-	return m.op
+	return *v, true
 }
 
-// SetOp allows setting the mutation operation.
-func (m *ConnectorMutation) SetOp(op Op) {
-	m.op = op
-}
+// OldCreatedBy returns the old "created_by" field's value of the Connector entity.
+// If the Connector object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *ConnectorMutation) OldCreatedBy(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldCreatedBy is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldCreatedBy requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldCreatedBy: %w", err)
+	}
+	return oldValue.CreatedBy, nil
+}
+
+// ClearCreatedBy clears the value of the "created_by" field.
+func (m *ConnectorMutation) ClearCreatedBy() {
+	m.created_by = nil
+	m.clearedFields[connector.FieldCreatedBy] = struct{}{}
+}
+
+// CreatedByCleared returns if the "created_by" field was cleared in this mutation.
+func (m *ConnectorMutation) CreatedByCleared() bool {
+	_, ok := m.clearedFields[connector.FieldCreatedBy]
+	return ok
+}
+
+// ResetCreatedBy resets all changes to the "created_by" field.
+func (m *ConnectorMutation) ResetCreatedBy() {
+	m.created_by = nil
+	delete(m.clearedFields, connector.FieldCreatedBy)
+}
+
+// SetUpdatedAt sets the "updated_at" field.
+func (m *ConnectorMutation) SetUpdatedAt(t time.Time) {
+	m.updated_at = &t
+}
+
+// UpdatedAt returns the value of the "updated_at" field in the mutation.
+func (m *ConnectorMutation) UpdatedAt() (r time.Time, exists bool) {
+	v := m.updated_at
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldUpdatedAt returns the old "updated_at" field's value of the Connector entity.
+// If the Connector object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *ConnectorMutation) OldUpdatedAt(ctx context.Context) (v time.Time, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldUpdatedAt is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldUpdatedAt requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldUpdatedAt: %w", err)
+	}
+	return oldValue.UpdatedAt, nil
+}
+
+// ClearUpdatedAt clears the value of the "updated_at" field.
+func (m *ConnectorMutation) ClearUpdatedAt() {
+	m.updated_at = nil
+	m.clearedFields[connector.FieldUpdatedAt] = struct{}{}
+}
+
+// UpdatedAtCleared returns if the "updated_at" field was cleared in this mutation.
+func (m *ConnectorMutation) UpdatedAtCleared() bool {
+	_, ok := m.clearedFields[connector.FieldUpdatedAt]
+	return ok
+}
+
+// ResetUpdatedAt resets all changes to the "updated_at" field.
+func (m *ConnectorMutation) ResetUpdatedAt() {
+	m.updated_at = nil
+	delete(m.clearedFields, connector.FieldUpdatedAt)
+}
+
+// SetDeletedAt sets the "deleted_at" field.
+func (m *ConnectorMutation) SetDeletedAt(t time.Time) {
+	m.deleted_at = &t
+}
+
+// DeletedAt returns the value of the "deleted_at" field in the mutation.
+func (m *ConnectorMutation) DeletedAt() (r time.Time, exists bool) {
+	v := m.deleted_at
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldDeletedAt returns the old "deleted_at" field's value of the Connector entity.
+// If the Connector object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *ConnectorMutation) OldDeletedAt(ctx context.Context) (v time.Time, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldDeletedAt is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldDeletedAt requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldDeletedAt: %w", err)
+	}
+	return oldValue.DeletedAt, nil
+}
+
+// ClearDeletedAt clears the value of the "deleted_at" field.
+func (m *ConnectorMutation) ClearDeletedAt() {
+	m.deleted_at = nil
+	m.clearedFields[connector.FieldDeletedAt] = struct{}{}
+}
+
+// DeletedAtCleared returns if the "deleted_at" field was cleared in this mutation.
+func (m *ConnectorMutation) DeletedAtCleared() bool {
+	_, ok := m.clearedFields[connector.FieldDeletedAt]
+	return ok
+}
+
+// ResetDeletedAt resets all changes to the "deleted_at" field.
+func (m *ConnectorMutation) ResetDeletedAt() {
+	m.deleted_at = nil
+	delete(m.clearedFields, connector.FieldDeletedAt)
+}
+
+// SetDisplay sets the "display" field.
+func (m *ConnectorMutation) SetDisplay(sd *storage.ConnectorDisplay) {
+	m.display = &sd
+}
+
+// Display returns the value of the "display" field in the mutation.
+func (m *ConnectorMutation) Display() (r *storage.ConnectorDisplay, exists bool) {
+	v := m.display
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldDisplay returns the old "display" field's value of the Connector entity.
+// If the Connector object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *ConnectorMutation) OldDisplay(ctx context.Context) (v *storage.ConnectorDisplay, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldDisplay is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldDisplay requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldDisplay: %w", err)
+	}
+	return oldValue.Display, nil
+}
+
+// ClearDisplay clears the value of the "display" field.
+func (m *ConnectorMutation) ClearDisplay() {
+	m.display = nil
+	m.clearedFields[connector.FieldDisplay] = struct{}{}
+}
+
+// DisplayCleared returns if the "display" field was cleared in this mutation.
+func (m *ConnectorMutation) DisplayCleared() bool {
+	_, ok := m.clearedFields[connector.FieldDisplay]
+	return ok
+}
+
+// ResetDisplay resets all changes to the "display" field.
+func (m *ConnectorMutation) ResetDisplay() {
+	m.display = nil
+	delete(m.clearedFields, connector.FieldDisplay)
+}
+
+// Where appends a list predicates to the ConnectorMutation builder.
+func (m *ConnectorMutation) Where(ps ...predicate.Connector) {
+	m.predicates = append(m.predicates, ps...)
+}
+
+// WhereP appends storage-level predicates to the ConnectorMutation builder. Using this method,
+// users can use type-assertion to append predicates that do not depend on any generated package.
+func (m *ConnectorMutation) WhereP(ps ...func(*sql.Selector)) {
+	p := make([]predicate.Connector, len(ps))
+	for i := range ps {
+		p[i] = ps[i]
+	}
+	m.Where(p...)
+}
+
+// Op returns the operation name.
+func (m *ConnectorMutation) Op() Op {
+	return m.op
+}
+
+// SetOp allows setting the mutation operation.
+func (m *ConnectorMutation) SetOp(op Op) {
+	m.op = op
+}
 
 // Type returns the node type of this mutation (Connector).
 func (m *ConnectorMutation) Type() string {
@@ -3015,7 +3709,7 @@ func (m *ConnectorMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *ConnectorMutation) Fields() []string {
-	fields := make([]string, 0, 4)
+	fields := make([]string, 0, 8)
 	if m._type != nil {
 		fields = append(fields, connector.FieldType)
 	}
@@ -3028,6 +3722,18 @@ func (m *ConnectorMutation) Fields() []string {
 	if m._config != nil {
 		fields = append(fields, connector.FieldConfig)
 	}
+	if m.created_by != nil {
+		fields = append(fields, connector.FieldCreatedBy)
+	}
+	if m.updated_at != nil {
+		fields = append(fields, connector.FieldUpdatedAt)
+	}
+	if m.deleted_at != nil {
+		fields = append(fields, connector.FieldDeletedAt)
+	}
+	if m.display != nil {
+		fields = append(fields, connector.FieldDisplay)
+	}
 	return fields
 }
 
@@ -3044,6 +3750,14 @@ func (m *ConnectorMutation) Field(name string) (ent.Value, bool) {
 		return m.ResourceVersion()
 	case connector.FieldConfig:
 		return m.Config()
+	case connector.FieldCreatedBy:
+		return m.CreatedBy()
+	case connector.FieldUpdatedAt:
+		return m.UpdatedAt()
+	case connector.FieldDeletedAt:
+		return m.DeletedAt()
+	case connector.FieldDisplay:
+		return m.Display()
 	}
 	return nil, false
 }
@@ -3061,6 +3775,14 @@ func (m *ConnectorMutation) OldField(ctx context.Context, name string) (ent.Valu
 		return m.OldResourceVersion(ctx)
 	case connector.FieldConfig:
 		return m.OldConfig(ctx)
+	case connector.FieldCreatedBy:
+		return m.OldCreatedBy(ctx)
+	case connector.FieldUpdatedAt:
+		return m.OldUpdatedAt(ctx)
+	case connector.FieldDeletedAt:
+		return m.OldDeletedAt(ctx)
+	case connector.FieldDisplay:
+		return m.OldDisplay(ctx)
 	}
 	return nil, fmt.Errorf("unknown Connector field %s", name)
 }
@@ -3098,6 +3820,34 @@ func (m *ConnectorMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetConfig(v)
 		return nil
+	case connector.FieldCreatedBy:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetCreatedBy(v)
+		return nil
+	case connector.FieldUpdatedAt:
+		v, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetUpdatedAt(v)
+		return nil
+	case connector.FieldDeletedAt:
+		v, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetDeletedAt(v)
+		return nil
+	case connector.FieldDisplay:
+		v, ok := value.(*storage.ConnectorDisplay)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetDisplay(v)
+		return nil
 	}
 	return fmt.Errorf("unknown Connector field %s", name)
 }
@@ -3127,7 +3877,20 @@ func (m *ConnectorMutation) AddField(name string, value ent.Value) error {
 // ClearedFields returns all nullable fields that were cleared during this
 // mutation.
 func (m *ConnectorMutation) ClearedFields() []string {
-	return nil
+	var fields []string
+	if m.FieldCleared(connector.FieldCreatedBy) {
+		fields = append(fields, connector.FieldCreatedBy)
+	}
+	if m.FieldCleared(connector.FieldUpdatedAt) {
+		fields = append(fields, connector.FieldUpdatedAt)
+	}
+	if m.FieldCleared(connector.FieldDeletedAt) {
+		fields = append(fields, connector.FieldDeletedAt)
+	}
+	if m.FieldCleared(connector.FieldDisplay) {
+		fields = append(fields, connector.FieldDisplay)
+	}
+	return fields
 }
 
 // FieldCleared returns a boolean indicating if a field with the given name was
@@ -3140,6 +3903,20 @@ func (m *ConnectorMutation) FieldCleared(name string) bool {
 // ClearField clears the value of the field with the given name. It returns an
 // error if the field is not defined in the schema.
 func (m *ConnectorMutation) ClearField(name string) error {
+	switch name {
+	case connector.FieldCreatedBy:
+		m.ClearCreatedBy()
+		return nil
+	case connector.FieldUpdatedAt:
+		m.ClearUpdatedAt()
+		return nil
+	case connector.FieldDeletedAt:
+		m.ClearDeletedAt()
+		return nil
+	case connector.FieldDisplay:
+		m.ClearDisplay()
+		return nil
+	}
 	return fmt.Errorf("unknown Connector nullable field %s", name)
 }
 
@@ -3159,6 +3936,18 @@ func (m *ConnectorMutation) ResetField(name string) error {
 	case connector.FieldConfig:
 		m.ResetConfig()
 		return nil
+	case connector.FieldCreatedBy:
+		m.ResetCreatedBy()
+		return nil
+	case connector.FieldUpdatedAt:
+		m.ResetUpdatedAt()
+		return nil
+	case connector.FieldDeletedAt:
+		m.ResetDeletedAt()
+		return nil
+	case connector.FieldDisplay:
+		m.ResetDisplay()
+		return nil
 	}
 	return fmt.Errorf("unknown Connector field %s", name)
 }
@@ -4619,6 +5408,7 @@ type KeysMutation struct {
 	signing_key             *jose.JSONWebKey
 	signing_key_pub         *jose.JSONWebKey
 	next_rotation           *time.Time
+	issuer_keys             *map[string]storage.Keys
 	clearedFields           map[string]struct{}
 	done                    bool
 	oldValue                func(context.Context) (*Keys, error)
@@ -4888,6 +5678,55 @@ func (m *KeysMutation) ResetNextRotation() {
 	m.next_rotation = nil
 }
 
+// SetIssuerKeys sets the "issuer_keys" field.
+func (m *KeysMutation) SetIssuerKeys(value map[string]storage.Keys) {
+	m.issuer_keys = &value
+}
+
+// IssuerKeys returns the value of the "issuer_keys" field in the mutation.
+func (m *KeysMutation) IssuerKeys() (r map[string]storage.Keys, exists bool) {
+	v := m.issuer_keys
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldIssuerKeys returns the old "issuer_keys" field's value of the Keys entity.
+// If the Keys object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *KeysMutation) OldIssuerKeys(ctx context.Context) (v map[string]storage.Keys, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldIssuerKeys is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldIssuerKeys requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldIssuerKeys: %w", err)
+	}
+	return oldValue.IssuerKeys, nil
+}
+
+// ClearIssuerKeys clears the value of the "issuer_keys" field.
+func (m *KeysMutation) ClearIssuerKeys() {
+	m.issuer_keys = nil
+	m.clearedFields[keys.FieldIssuerKeys] = struct{}{}
+}
+
+// IssuerKeysCleared returns if the "issuer_keys" field was cleared in this mutation.
+func (m *KeysMutation) IssuerKeysCleared() bool {
+	_, ok := m.clearedFields[keys.FieldIssuerKeys]
+	return ok
+}
+
+// ResetIssuerKeys resets all changes to the "issuer_keys" field.
+func (m *KeysMutation) ResetIssuerKeys() {
+	m.issuer_keys = nil
+	delete(m.clearedFields, keys.FieldIssuerKeys)
+}
+
 // Where appends a list predicates to the KeysMutation builder.
 func (m *KeysMutation) Where(ps ...predicate.Keys) {
 	m.predicates = append(m.predicates, ps...)
@@ -4922,7 +5761,7 @@ func (m *KeysMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *KeysMutation) Fields() []string {
-	fields := make([]string, 0, 4)
+	fields := make([]string, 0, 5)
 	if m.verification_keys != nil {
 		fields = append(fields, keys.FieldVerificationKeys)
 	}
@@ -4935,6 +5774,9 @@ func (m *KeysMutation) Fields() []string {
 	if m.next_rotation != nil {
 		fields = append(fields, keys.FieldNextRotation)
 	}
+	if m.issuer_keys != nil {
+		fields = append(fields, keys.FieldIssuerKeys)
+	}
 	return fields
 }
 
@@ -4951,6 +5793,8 @@ func (m *KeysMutation) Field(name string) (ent.Value, bool) {
 		return m.SigningKeyPub()
 	case keys.FieldNextRotation:
 		return m.NextRotation()
+	case keys.FieldIssuerKeys:
+		return m.IssuerKeys()
 	}
 	return nil, false
 }
@@ -4968,6 +5812,8 @@ func (m *KeysMutation) OldField(ctx context.Context, name string) (ent.Value, er
 		return m.OldSigningKeyPub(ctx)
 	case keys.FieldNextRotation:
 		return m.OldNextRotation(ctx)
+	case keys.FieldIssuerKeys:
+		return m.OldIssuerKeys(ctx)
 	}
 	return nil, fmt.Errorf("unknown Keys field %s", name)
 }
@@ -5005,6 +5851,13 @@ func (m *KeysMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetNextRotation(v)
 		return nil
+	case keys.FieldIssuerKeys:
+		v, ok := value.(map[string]storage.Keys)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetIssuerKeys(v)
+		return nil
 	}
 	return fmt.Errorf("unknown Keys field %s", name)
 }
@@ -5034,7 +5887,11 @@ func (m *KeysMutation) AddField(name string, value ent.Value) error {
 // ClearedFields returns all nullable fields that were cleared during this
 // mutation.
 func (m *KeysMutation) ClearedFields() []string {
-	return nil
+	var fields []string
+	if m.FieldCleared(keys.FieldIssuerKeys) {
+		fields = append(fields, keys.FieldIssuerKeys)
+	}
+	return fields
 }
 
 // FieldCleared returns a boolean indicating if a field with the given name was
@@ -5047,6 +5904,11 @@ func (m *KeysMutation) FieldCleared(name string) bool {
 // ClearField clears the value of the field with the given name. It returns an
 // error if the field is not defined in the schema.
 func (m *KeysMutation) ClearField(name string) error {
+	switch name {
+	case keys.FieldIssuerKeys:
+		m.ClearIssuerKeys()
+		return nil
+	}
 	return fmt.Errorf("unknown Keys nullable field %s", name)
 }
 
@@ -5066,6 +5928,9 @@ func (m *KeysMutation) ResetField(name string) error {
 	case keys.FieldNextRotation:
 		m.ResetNextRotation()
 		return nil
+	case keys.FieldIssuerKeys:
+		m.ResetIssuerKeys()
+		return nil
 	}
 	return fmt.Errorf("unknown Keys field %s", name)
 }
@@ -5121,21 +5986,39 @@ func (m *KeysMutation) ResetEdge(name string) error {
 // OAuth2ClientMutation represents an operation that mutates the OAuth2Client nodes in the graph.
 type OAuth2ClientMutation struct {
 	config
-	op                  Op
-	typ                 string
-	id                  *string
-	secret              *string
-	redirect_uris       *[]string
-	appendredirect_uris []string
-	trusted_peers       *[]string
-	appendtrusted_peers []string
-	public              *bool
-	name                *string
-	logo_url            *string
-	clearedFields       map[string]struct{}
-	done                bool
-	oldValue            func(context.Context) (*OAuth2Client, error)
-	predicates          []predicate.OAuth2Client
+	op                          Op
+	typ                         string
+	id                          *string
+	secret                      *string
+	redirect_uris               *[]string
+	appendredirect_uris         []string
+	trusted_peers               *[]string
+	appendtrusted_peers         []string
+	public                      *bool
+	name                        *string
+	logo_url                    *string
+	refresh_token_policy        **storage.RefreshTokenPolicy
+	token_expiry                **storage.TokenExpiry
+	groups_filter               **storage.GroupsFilter
+	claims_transforms           *[]storage.ClaimsTransform
+	appendclaims_transforms     []storage.ClaimsTransform
+	redirect_uri_patterns       *[]string
+	appendredirect_uri_patterns []string
+	previous_secret             **storage.PreviousSecret
+	created_at                  *time.Time
+	last_used                   *time.Time
+	created_by                  *string
+	updated_at                  *time.Time
+	deleted_at                  *time.Time
+	policy_url                  *string
+	terms_of_service_url        *string
+	allowed_origins             *[]string
+	appendallowed_origins       []string
+	forward_claim_source_tokens *bool
+	clearedFields               map[string]struct{}
+	done                        bool
+	oldValue                    func(context.Context) (*OAuth2Client, error)
+	predicates                  []predicate.OAuth2Client
 }
 
 var _ ent.Mutation = (*OAuth2ClientMutation)(nil)
@@ -5516,571 +6399,785 @@ func (m *OAuth2ClientMutation) ResetLogoURL() {
 	m.logo_url = nil
 }
 
-// Where appends a list predicates to the OAuth2ClientMutation builder.
-func (m *OAuth2ClientMutation) Where(ps ...predicate.OAuth2Client) {
-	m.predicates = append(m.predicates, ps...)
+// SetRefreshTokenPolicy sets the "refresh_token_policy" field.
+func (m *OAuth2ClientMutation) SetRefreshTokenPolicy(stp *storage.RefreshTokenPolicy) {
+	m.refresh_token_policy = &stp
 }
 
-// WhereP appends storage-level predicates to the OAuth2ClientMutation builder. Using this method,
-// users can use type-assertion to append predicates that do not depend on any generated package.
-func (m *OAuth2ClientMutation) WhereP(ps ...func(*sql.Selector)) {
-	p := make([]predicate.OAuth2Client, len(ps))
-	for i := range ps {
-		p[i] = ps[i]
+// RefreshTokenPolicy returns the value of the "refresh_token_policy" field in the mutation.
+func (m *OAuth2ClientMutation) RefreshTokenPolicy() (r *storage.RefreshTokenPolicy, exists bool) {
+	v := m.refresh_token_policy
+	if v == nil {
+		return
 	}
-	m.Where(p...)
+	return *v, true
 }
 
-// Op returns the operation name.
-func (m *OAuth2ClientMutation) Op() Op {
-	return m.op
+// OldRefreshTokenPolicy returns the old "refresh_token_policy" field's value of the OAuth2Client entity.
+// If the OAuth2Client object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *OAuth2ClientMutation) OldRefreshTokenPolicy(ctx context.Context) (v *storage.RefreshTokenPolicy, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldRefreshTokenPolicy is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldRefreshTokenPolicy requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldRefreshTokenPolicy: %w", err)
+	}
+	return oldValue.RefreshTokenPolicy, nil
 }
 
-// SetOp allows setting the mutation operation.
-func (m *OAuth2ClientMutation) SetOp(op Op) {
-	m.op = op
+// ClearRefreshTokenPolicy clears the value of the "refresh_token_policy" field.
+func (m *OAuth2ClientMutation) ClearRefreshTokenPolicy() {
+	m.refresh_token_policy = nil
+	m.clearedFields[oauth2client.FieldRefreshTokenPolicy] = struct{}{}
 }
 
-// Type returns the node type of this mutation (OAuth2Client).
-func (m *OAuth2ClientMutation) Type() string {
-	return m.typ
+// RefreshTokenPolicyCleared returns if the "refresh_token_policy" field was cleared in this mutation.
+func (m *OAuth2ClientMutation) RefreshTokenPolicyCleared() bool {
+	_, ok := m.clearedFields[oauth2client.FieldRefreshTokenPolicy]
+	return ok
 }
 
-// Fields returns all fields that were changed during this mutation. Note that in
-// order to get all numeric fields that were incremented/decremented, call
-// AddedFields().
-func (m *OAuth2ClientMutation) Fields() []string {
-	fields := make([]string, 0, 6)
-	if m.secret != nil {
-		fields = append(fields, oauth2client.FieldSecret)
+// ResetRefreshTokenPolicy resets all changes to the "refresh_token_policy" field.
+func (m *OAuth2ClientMutation) ResetRefreshTokenPolicy() {
+	m.refresh_token_policy = nil
+	delete(m.clearedFields, oauth2client.FieldRefreshTokenPolicy)
+}
+
+// SetTokenExpiry sets the "token_expiry" field.
+func (m *OAuth2ClientMutation) SetTokenExpiry(ste *storage.TokenExpiry) {
+	m.token_expiry = &ste
+}
+
+// TokenExpiry returns the value of the "token_expiry" field in the mutation.
+func (m *OAuth2ClientMutation) TokenExpiry() (r *storage.TokenExpiry, exists bool) {
+	v := m.token_expiry
+	if v == nil {
+		return
 	}
-	if m.redirect_uris != nil {
-		fields = append(fields, oauth2client.FieldRedirectUris)
+	return *v, true
+}
+
+// OldTokenExpiry returns the old "token_expiry" field's value of the OAuth2Client entity.
+// If the OAuth2Client object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *OAuth2ClientMutation) OldTokenExpiry(ctx context.Context) (v *storage.TokenExpiry, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldTokenExpiry is only allowed on UpdateOne operations")
 	}
-	if m.trusted_peers != nil {
-		fields = append(fields, oauth2client.FieldTrustedPeers)
-	}
-	if m.public != nil {
-		fields = append(fields, oauth2client.FieldPublic)
-	}
-	if m.name != nil {
-		fields = append(fields, oauth2client.FieldName)
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldTokenExpiry requires an ID field in the mutation")
 	}
-	if m.logo_url != nil {
-		fields = append(fields, oauth2client.FieldLogoURL)
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldTokenExpiry: %w", err)
 	}
-	return fields
+	return oldValue.TokenExpiry, nil
 }
 
-// Field returns the value of a field with the given name. The second boolean
-// return value indicates that this field was not set, or was not defined in the
-// schema.
-func (m *OAuth2ClientMutation) Field(name string) (ent.Value, bool) {
-	switch name {
-	case oauth2client.FieldSecret:
-		return m.Secret()
-	case oauth2client.FieldRedirectUris:
-		return m.RedirectUris()
-	case oauth2client.FieldTrustedPeers:
-		return m.TrustedPeers()
-	case oauth2client.FieldPublic:
-		return m.Public()
-	case oauth2client.FieldName:
-		return m.Name()
-	case oauth2client.FieldLogoURL:
-		return m.LogoURL()
-	}
-	return nil, false
+// ClearTokenExpiry clears the value of the "token_expiry" field.
+func (m *OAuth2ClientMutation) ClearTokenExpiry() {
+	m.token_expiry = nil
+	m.clearedFields[oauth2client.FieldTokenExpiry] = struct{}{}
 }
 
-// OldField returns the old value of the field from the database. An error is
-// returned if the mutation operation is not UpdateOne, or the query to the
-// database failed.
-func (m *OAuth2ClientMutation) OldField(ctx context.Context, name string) (ent.Value, error) {
-	switch name {
-	case oauth2client.FieldSecret:
-		return m.OldSecret(ctx)
-	case oauth2client.FieldRedirectUris:
-		return m.OldRedirectUris(ctx)
-	case oauth2client.FieldTrustedPeers:
-		return m.OldTrustedPeers(ctx)
-	case oauth2client.FieldPublic:
-		return m.OldPublic(ctx)
-	case oauth2client.FieldName:
-		return m.OldName(ctx)
-	case oauth2client.FieldLogoURL:
-		return m.OldLogoURL(ctx)
+// TokenExpiryCleared returns if the "token_expiry" field was cleared in this mutation.
+func (m *OAuth2ClientMutation) TokenExpiryCleared() bool {
+	_, ok := m.clearedFields[oauth2client.FieldTokenExpiry]
+	return ok
+}
+
+// ResetTokenExpiry resets all changes to the "token_expiry" field.
+func (m *OAuth2ClientMutation) ResetTokenExpiry() {
+	m.token_expiry = nil
+	delete(m.clearedFields, oauth2client.FieldTokenExpiry)
+}
+
+// SetGroupsFilter sets the "groups_filter" field.
+func (m *OAuth2ClientMutation) SetGroupsFilter(sf *storage.GroupsFilter) {
+	m.groups_filter = &sf
+}
+
+// GroupsFilter returns the value of the "groups_filter" field in the mutation.
+func (m *OAuth2ClientMutation) GroupsFilter() (r *storage.GroupsFilter, exists bool) {
+	v := m.groups_filter
+	if v == nil {
+		return
 	}
-	return nil, fmt.Errorf("unknown OAuth2Client field %s", name)
+	return *v, true
 }
 
-// SetField sets the value of a field with the given name. It returns an error if
-// the field is not defined in the schema, or if the type mismatched the field
-// type.
-func (m *OAuth2ClientMutation) SetField(name string, value ent.Value) error {
-	switch name {
-	case oauth2client.FieldSecret:
-		v, ok := value.(string)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetSecret(v)
-		return nil
-	case oauth2client.FieldRedirectUris:
-		v, ok := value.([]string)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetRedirectUris(v)
-		return nil
-	case oauth2client.FieldTrustedPeers:
-		v, ok := value.([]string)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetTrustedPeers(v)
-		return nil
-	case oauth2client.FieldPublic:
-		v, ok := value.(bool)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetPublic(v)
-		return nil
-	case oauth2client.FieldName:
-		v, ok := value.(string)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetName(v)
-		return nil
-	case oauth2client.FieldLogoURL:
-		v, ok := value.(string)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetLogoURL(v)
-		return nil
+// OldGroupsFilter returns the old "groups_filter" field's value of the OAuth2Client entity.
+// If the OAuth2Client object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *OAuth2ClientMutation) OldGroupsFilter(ctx context.Context) (v *storage.GroupsFilter, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldGroupsFilter is only allowed on UpdateOne operations")
 	}
-	return fmt.Errorf("unknown OAuth2Client field %s", name)
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldGroupsFilter requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldGroupsFilter: %w", err)
+	}
+	return oldValue.GroupsFilter, nil
 }
 
-// AddedFields returns all numeric fields that were incremented/decremented during
-// this mutation.
-func (m *OAuth2ClientMutation) AddedFields() []string {
-	return nil
+// ClearGroupsFilter clears the value of the "groups_filter" field.
+func (m *OAuth2ClientMutation) ClearGroupsFilter() {
+	m.groups_filter = nil
+	m.clearedFields[oauth2client.FieldGroupsFilter] = struct{}{}
 }
 
-// AddedField returns the numeric value that was incremented/decremented on a field
-// with the given name. The second boolean return value indicates that this field
-// was not set, or was not defined in the schema.
-func (m *OAuth2ClientMutation) AddedField(name string) (ent.Value, bool) {
-	return nil, false
+// GroupsFilterCleared returns if the "groups_filter" field was cleared in this mutation.
+func (m *OAuth2ClientMutation) GroupsFilterCleared() bool {
+	_, ok := m.clearedFields[oauth2client.FieldGroupsFilter]
+	return ok
 }
 
-// AddField adds the value to the field with the given name. It returns an error if
-// the field is not defined in the schema, or if the type mismatched the field
-// type.
-func (m *OAuth2ClientMutation) AddField(name string, value ent.Value) error {
-	switch name {
+// ResetGroupsFilter resets all changes to the "groups_filter" field.
+func (m *OAuth2ClientMutation) ResetGroupsFilter() {
+	m.groups_filter = nil
+	delete(m.clearedFields, oauth2client.FieldGroupsFilter)
+}
+
+// SetClaimsTransforms sets the "claims_transforms" field.
+func (m *OAuth2ClientMutation) SetClaimsTransforms(ct []storage.ClaimsTransform) {
+	m.claims_transforms = &ct
+	m.appendclaims_transforms = nil
+}
+
+// ClaimsTransforms returns the value of the "claims_transforms" field in the mutation.
+func (m *OAuth2ClientMutation) ClaimsTransforms() (r []storage.ClaimsTransform, exists bool) {
+	v := m.claims_transforms
+	if v == nil {
+		return
 	}
-	return fmt.Errorf("unknown OAuth2Client numeric field %s", name)
+	return *v, true
 }
 
-// ClearedFields returns all nullable fields that were cleared during this
-// mutation.
-func (m *OAuth2ClientMutation) ClearedFields() []string {
-	var fields []string
-	if m.FieldCleared(oauth2client.FieldRedirectUris) {
-		fields = append(fields, oauth2client.FieldRedirectUris)
+// OldClaimsTransforms returns the old "claims_transforms" field's value of the OAuth2Client entity.
+// If the OAuth2Client object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *OAuth2ClientMutation) OldClaimsTransforms(ctx context.Context) (v []storage.ClaimsTransform, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldClaimsTransforms is only allowed on UpdateOne operations")
 	}
-	if m.FieldCleared(oauth2client.FieldTrustedPeers) {
-		fields = append(fields, oauth2client.FieldTrustedPeers)
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldClaimsTransforms requires an ID field in the mutation")
 	}
-	return fields
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldClaimsTransforms: %w", err)
+	}
+	return oldValue.ClaimsTransforms, nil
 }
 
-// FieldCleared returns a boolean indicating if a field with the given name was
-// cleared in this mutation.
-func (m *OAuth2ClientMutation) FieldCleared(name string) bool {
-	_, ok := m.clearedFields[name]
-	return ok
+// AppendClaimsTransforms adds ct to the "claims_transforms" field.
+func (m *OAuth2ClientMutation) AppendClaimsTransforms(ct []storage.ClaimsTransform) {
+	m.appendclaims_transforms = append(m.appendclaims_transforms, ct...)
 }
 
-// ClearField clears the value of the field with the given name. It returns an
-// error if the field is not defined in the schema.
-func (m *OAuth2ClientMutation) ClearField(name string) error {
-	switch name {
-	case oauth2client.FieldRedirectUris:
-		m.ClearRedirectUris()
-		return nil
-	case oauth2client.FieldTrustedPeers:
-		m.ClearTrustedPeers()
-		return nil
+// AppendedClaimsTransforms returns the list of values that were appended to the "claims_transforms" field in this mutation.
+func (m *OAuth2ClientMutation) AppendedClaimsTransforms() ([]storage.ClaimsTransform, bool) {
+	if len(m.appendclaims_transforms) == 0 {
+		return nil, false
 	}
-	return fmt.Errorf("unknown OAuth2Client nullable field %s", name)
+	return m.appendclaims_transforms, true
 }
 
-// ResetField resets all changes in the mutation for the field with the given name.
-// It returns an error if the field is not defined in the schema.
-func (m *OAuth2ClientMutation) ResetField(name string) error {
-	switch name {
-	case oauth2client.FieldSecret:
-		m.ResetSecret()
-		return nil
-	case oauth2client.FieldRedirectUris:
-		m.ResetRedirectUris()
-		return nil
-	case oauth2client.FieldTrustedPeers:
-		m.ResetTrustedPeers()
-		return nil
-	case oauth2client.FieldPublic:
-		m.ResetPublic()
-		return nil
-	case oauth2client.FieldName:
-		m.ResetName()
-		return nil
-	case oauth2client.FieldLogoURL:
-		m.ResetLogoURL()
-		return nil
+// ClearClaimsTransforms clears the value of the "claims_transforms" field.
+func (m *OAuth2ClientMutation) ClearClaimsTransforms() {
+	m.claims_transforms = nil
+	m.appendclaims_transforms = nil
+	m.clearedFields[oauth2client.FieldClaimsTransforms] = struct{}{}
+}
+
+// ClaimsTransformsCleared returns if the "claims_transforms" field was cleared in this mutation.
+func (m *OAuth2ClientMutation) ClaimsTransformsCleared() bool {
+	_, ok := m.clearedFields[oauth2client.FieldClaimsTransforms]
+	return ok
+}
+
+// ResetClaimsTransforms resets all changes to the "claims_transforms" field.
+func (m *OAuth2ClientMutation) ResetClaimsTransforms() {
+	m.claims_transforms = nil
+	m.appendclaims_transforms = nil
+	delete(m.clearedFields, oauth2client.FieldClaimsTransforms)
+}
+
+// SetRedirectURIPatterns sets the "redirect_uri_patterns" field.
+func (m *OAuth2ClientMutation) SetRedirectURIPatterns(s []string) {
+	m.redirect_uri_patterns = &s
+	m.appendredirect_uri_patterns = nil
+}
+
+// RedirectURIPatterns returns the value of the "redirect_uri_patterns" field in the mutation.
+func (m *OAuth2ClientMutation) RedirectURIPatterns() (r []string, exists bool) {
+	v := m.redirect_uri_patterns
+	if v == nil {
+		return
 	}
-	return fmt.Errorf("unknown OAuth2Client field %s", name)
+	return *v, true
 }
 
-// AddedEdges returns all edge names that were set/added in this mutation.
-func (m *OAuth2ClientMutation) AddedEdges() []string {
-	edges := make([]string, 0, 0)
-	return edges
+// OldRedirectURIPatterns returns the old "redirect_uri_patterns" field's value of the OAuth2Client entity.
+// If the OAuth2Client object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *OAuth2ClientMutation) OldRedirectURIPatterns(ctx context.Context) (v []string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldRedirectURIPatterns is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldRedirectURIPatterns requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldRedirectURIPatterns: %w", err)
+	}
+	return oldValue.RedirectURIPatterns, nil
 }
 
-// AddedIDs returns all IDs (to other nodes) that were added for the given edge
-// name in this mutation.
-func (m *OAuth2ClientMutation) AddedIDs(name string) []ent.Value {
-	return nil
+// AppendRedirectURIPatterns adds s to the "redirect_uri_patterns" field.
+func (m *OAuth2ClientMutation) AppendRedirectURIPatterns(s []string) {
+	m.appendredirect_uri_patterns = append(m.appendredirect_uri_patterns, s...)
 }
 
-// RemovedEdges returns all edge names that were removed in this mutation.
-func (m *OAuth2ClientMutation) RemovedEdges() []string {
-	edges := make([]string, 0, 0)
-	return edges
+// AppendedRedirectURIPatterns returns the list of values that were appended to the "redirect_uri_patterns" field in this mutation.
+func (m *OAuth2ClientMutation) AppendedRedirectURIPatterns() ([]string, bool) {
+	if len(m.appendredirect_uri_patterns) == 0 {
+		return nil, false
+	}
+	return m.appendredirect_uri_patterns, true
 }
 
-// RemovedIDs returns all IDs (to other nodes) that were removed for the edge with
-// the given name in this mutation.
-func (m *OAuth2ClientMutation) RemovedIDs(name string) []ent.Value {
-	return nil
+// ClearRedirectURIPatterns clears the value of the "redirect_uri_patterns" field.
+func (m *OAuth2ClientMutation) ClearRedirectURIPatterns() {
+	m.redirect_uri_patterns = nil
+	m.appendredirect_uri_patterns = nil
+	m.clearedFields[oauth2client.FieldRedirectURIPatterns] = struct{}{}
 }
 
-// ClearedEdges returns all edge names that were cleared in this mutation.
-func (m *OAuth2ClientMutation) ClearedEdges() []string {
-	edges := make([]string, 0, 0)
-	return edges
+// RedirectURIPatternsCleared returns if the "redirect_uri_patterns" field was cleared in this mutation.
+func (m *OAuth2ClientMutation) RedirectURIPatternsCleared() bool {
+	_, ok := m.clearedFields[oauth2client.FieldRedirectURIPatterns]
+	return ok
 }
 
-// EdgeCleared returns a boolean which indicates if the edge with the given name
-// was cleared in this mutation.
-func (m *OAuth2ClientMutation) EdgeCleared(name string) bool {
-	return false
+// ResetRedirectURIPatterns resets all changes to the "redirect_uri_patterns" field.
+func (m *OAuth2ClientMutation) ResetRedirectURIPatterns() {
+	m.redirect_uri_patterns = nil
+	m.appendredirect_uri_patterns = nil
+	delete(m.clearedFields, oauth2client.FieldRedirectURIPatterns)
 }
 
-// ClearEdge clears the value of the edge with the given name. It returns an error
-// if that edge is not defined in the schema.
-func (m *OAuth2ClientMutation) ClearEdge(name string) error {
-	return fmt.Errorf("unknown OAuth2Client unique edge %s", name)
+// SetPreviousSecret sets the "previous_secret" field.
+func (m *OAuth2ClientMutation) SetPreviousSecret(sf *storage.PreviousSecret) {
+	m.previous_secret = &sf
 }
 
-// ResetEdge resets all changes to the edge with the given name in this mutation.
-// It returns an error if the edge is not defined in the schema.
-func (m *OAuth2ClientMutation) ResetEdge(name string) error {
-	return fmt.Errorf("unknown OAuth2Client edge %s", name)
+// PreviousSecret returns the value of the "previous_secret" field in the mutation.
+func (m *OAuth2ClientMutation) PreviousSecret() (r *storage.PreviousSecret, exists bool) {
+	v := m.previous_secret
+	if v == nil {
+		return
+	}
+	return *v, true
 }
 
-// OfflineSessionMutation represents an operation that mutates the OfflineSession nodes in the graph.
-type OfflineSessionMutation struct {
-	config
-	op             Op
-	typ            string
-	id             *string
-	user_id        *string
-	conn_id        *string
-	refresh        *[]byte
-	connector_data *[]byte
-	clearedFields  map[string]struct{}
-	done           bool
-	oldValue       func(context.Context) (*OfflineSession, error)
-	predicates     []predicate.OfflineSession
+// OldPreviousSecret returns the old "previous_secret" field's value of the OAuth2Client entity.
+// If the OAuth2Client object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *OAuth2ClientMutation) OldPreviousSecret(ctx context.Context) (v *storage.PreviousSecret, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldPreviousSecret is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldPreviousSecret requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldPreviousSecret: %w", err)
+	}
+	return oldValue.PreviousSecret, nil
 }
 
-var _ ent.Mutation = (*OfflineSessionMutation)(nil)
+// ClearPreviousSecret clears the value of the "previous_secret" field.
+func (m *OAuth2ClientMutation) ClearPreviousSecret() {
+	m.previous_secret = nil
+	m.clearedFields[oauth2client.FieldPreviousSecret] = struct{}{}
+}
 
-// offlinesessionOption allows management of the mutation configuration using functional options.
-type offlinesessionOption func(*OfflineSessionMutation)
+// PreviousSecretCleared returns if the "previous_secret" field was cleared in this mutation.
+func (m *OAuth2ClientMutation) PreviousSecretCleared() bool {
+	_, ok := m.clearedFields[oauth2client.FieldPreviousSecret]
+	return ok
+}
 
-// newOfflineSessionMutation creates new mutation for the OfflineSession entity.
-func newOfflineSessionMutation(c config, op Op, opts ...offlinesessionOption) *OfflineSessionMutation {
-	m := &OfflineSessionMutation{
-		config:        c,
-		op:            op,
-		typ:           TypeOfflineSession,
-		clearedFields: make(map[string]struct{}),
-	}
-	for _, opt := range opts {
-		opt(m)
-	}
-	return m
+// ResetPreviousSecret resets all changes to the "previous_secret" field.
+func (m *OAuth2ClientMutation) ResetPreviousSecret() {
+	m.previous_secret = nil
+	delete(m.clearedFields, oauth2client.FieldPreviousSecret)
 }
 
-// withOfflineSessionID sets the ID field of the mutation.
-func withOfflineSessionID(id string) offlinesessionOption {
-	return func(m *OfflineSessionMutation) {
-		var (
-			err   error
-			once  sync.Once
-			value *OfflineSession
-		)
-		m.oldValue = func(ctx context.Context) (*OfflineSession, error) {
-			once.Do(func() {
-				if m.done {
-					err = errors.New("querying old values post mutation is not allowed")
-				} else {
-					value, err = m.Client().OfflineSession.Get(ctx, id)
-				}
-			})
-			return value, err
-		}
-		m.id = &id
+// SetCreatedAt sets the "created_at" field.
+func (m *OAuth2ClientMutation) SetCreatedAt(t time.Time) {
+	m.created_at = &t
+}
+
+// CreatedAt returns the value of the "created_at" field in the mutation.
+func (m *OAuth2ClientMutation) CreatedAt() (r time.Time, exists bool) {
+	v := m.created_at
+	if v == nil {
+		return
 	}
+	return *v, true
 }
 
-// withOfflineSession sets the old OfflineSession of the mutation.
-func withOfflineSession(node *OfflineSession) offlinesessionOption {
-	return func(m *OfflineSessionMutation) {
-		m.oldValue = func(context.Context) (*OfflineSession, error) {
-			return node, nil
-		}
-		m.id = &node.ID
+// OldCreatedAt returns the old "created_at" field's value of the OAuth2Client entity.
+// If the OAuth2Client object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *OAuth2ClientMutation) OldCreatedAt(ctx context.Context) (v time.Time, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldCreatedAt is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldCreatedAt requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldCreatedAt: %w", err)
 	}
+	return oldValue.CreatedAt, nil
 }
 
-// Client returns a new `ent.Client` from the mutation. If the mutation was
-// executed in a transaction (ent.Tx), a transactional client is returned.
-func (m OfflineSessionMutation) Client() *Client {
-	client := &Client{config: m.config}
-	client.init()
-	return client
+// ClearCreatedAt clears the value of the "created_at" field.
+func (m *OAuth2ClientMutation) ClearCreatedAt() {
+	m.created_at = nil
+	m.clearedFields[oauth2client.FieldCreatedAt] = struct{}{}
 }
 
-// Tx returns an `ent.Tx` for mutations that were executed in transactions;
-// it returns an error otherwise.
-func (m OfflineSessionMutation) Tx() (*Tx, error) {
-	if _, ok := m.driver.(*txDriver); !ok {
-		return nil, errors.New("db: mutation is not running in a transaction")
-	}
-	tx := &Tx{config: m.config}
-	tx.init()
-	return tx, nil
+// CreatedAtCleared returns if the "created_at" field was cleared in this mutation.
+func (m *OAuth2ClientMutation) CreatedAtCleared() bool {
+	_, ok := m.clearedFields[oauth2client.FieldCreatedAt]
+	return ok
 }
 
-// SetID sets the value of the id field. Note that this
-// operation is only accepted on creation of OfflineSession entities.
-func (m *OfflineSessionMutation) SetID(id string) {
-	m.id = &id
+// ResetCreatedAt resets all changes to the "created_at" field.
+func (m *OAuth2ClientMutation) ResetCreatedAt() {
+	m.created_at = nil
+	delete(m.clearedFields, oauth2client.FieldCreatedAt)
 }
 
-// ID returns the ID value in the mutation. Note that the ID is only available
-// if it was provided to the builder or after it was returned from the database.
-func (m *OfflineSessionMutation) ID() (id string, exists bool) {
-	if m.id == nil {
+// SetLastUsed sets the "last_used" field.
+func (m *OAuth2ClientMutation) SetLastUsed(t time.Time) {
+	m.last_used = &t
+}
+
+// LastUsed returns the value of the "last_used" field in the mutation.
+func (m *OAuth2ClientMutation) LastUsed() (r time.Time, exists bool) {
+	v := m.last_used
+	if v == nil {
 		return
 	}
-	return *m.id, true
+	return *v, true
 }
 
-// IDs queries the database and returns the entity ids that match the mutation's predicate.
-// That means, if the mutation is applied within a transaction with an isolation level such
-// as sql.LevelSerializable, the returned ids match the ids of the rows that will be updated
-// or updated by the mutation.
-func (m *OfflineSessionMutation) IDs(ctx context.Context) ([]string, error) {
-	switch {
-	case m.op.Is(OpUpdateOne | OpDeleteOne):
-		id, exists := m.ID()
-		if exists {
-			return []string{id}, nil
-		}
-		fallthrough
-	case m.op.Is(OpUpdate | OpDelete):
-		return m.Client().OfflineSession.Query().Where(m.predicates...).IDs(ctx)
-	default:
-		return nil, fmt.Errorf("IDs is not allowed on %s operations", m.op)
+// OldLastUsed returns the old "last_used" field's value of the OAuth2Client entity.
+// If the OAuth2Client object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *OAuth2ClientMutation) OldLastUsed(ctx context.Context) (v time.Time, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldLastUsed is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldLastUsed requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldLastUsed: %w", err)
 	}
+	return oldValue.LastUsed, nil
 }
 
-// SetUserID sets the "user_id" field.
-func (m *OfflineSessionMutation) SetUserID(s string) {
-	m.user_id = &s
+// ClearLastUsed clears the value of the "last_used" field.
+func (m *OAuth2ClientMutation) ClearLastUsed() {
+	m.last_used = nil
+	m.clearedFields[oauth2client.FieldLastUsed] = struct{}{}
 }
 
-// UserID returns the value of the "user_id" field in the mutation.
-func (m *OfflineSessionMutation) UserID() (r string, exists bool) {
-	v := m.user_id
+// LastUsedCleared returns if the "last_used" field was cleared in this mutation.
+func (m *OAuth2ClientMutation) LastUsedCleared() bool {
+	_, ok := m.clearedFields[oauth2client.FieldLastUsed]
+	return ok
+}
+
+// ResetLastUsed resets all changes to the "last_used" field.
+func (m *OAuth2ClientMutation) ResetLastUsed() {
+	m.last_used = nil
+	delete(m.clearedFields, oauth2client.FieldLastUsed)
+}
+
+// SetCreatedBy sets the "created_by" field.
+func (m *OAuth2ClientMutation) SetCreatedBy(s string) {
+	m.created_by = &s
+}
+
+// CreatedBy returns the value of the "created_by" field in the mutation.
+func (m *OAuth2ClientMutation) CreatedBy() (r string, exists bool) {
+	v := m.created_by
 	if v == nil {
 		return
 	}
 	return *v, true
 }
 
-// OldUserID returns the old "user_id" field's value of the OfflineSession entity.
-// If the OfflineSession object wasn't provided to the builder, the object is fetched from the database.
+// OldCreatedBy returns the old "created_by" field's value of the OAuth2Client entity.
+// If the OAuth2Client object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *OfflineSessionMutation) OldUserID(ctx context.Context) (v string, err error) {
+func (m *OAuth2ClientMutation) OldCreatedBy(ctx context.Context) (v string, err error) {
 	if !m.op.Is(OpUpdateOne) {
-		return v, errors.New("OldUserID is only allowed on UpdateOne operations")
+		return v, errors.New("OldCreatedBy is only allowed on UpdateOne operations")
 	}
 	if m.id == nil || m.oldValue == nil {
-		return v, errors.New("OldUserID requires an ID field in the mutation")
+		return v, errors.New("OldCreatedBy requires an ID field in the mutation")
 	}
 	oldValue, err := m.oldValue(ctx)
 	if err != nil {
-		return v, fmt.Errorf("querying old value for OldUserID: %w", err)
+		return v, fmt.Errorf("querying old value for OldCreatedBy: %w", err)
 	}
-	return oldValue.UserID, nil
+	return oldValue.CreatedBy, nil
 }
 
-// ResetUserID resets all changes to the "user_id" field.
-func (m *OfflineSessionMutation) ResetUserID() {
-	m.user_id = nil
+// ClearCreatedBy clears the value of the "created_by" field.
+func (m *OAuth2ClientMutation) ClearCreatedBy() {
+	m.created_by = nil
+	m.clearedFields[oauth2client.FieldCreatedBy] = struct{}{}
 }
 
-// SetConnID sets the "conn_id" field.
-func (m *OfflineSessionMutation) SetConnID(s string) {
-	m.conn_id = &s
+// CreatedByCleared returns if the "created_by" field was cleared in this mutation.
+func (m *OAuth2ClientMutation) CreatedByCleared() bool {
+	_, ok := m.clearedFields[oauth2client.FieldCreatedBy]
+	return ok
 }
 
-// ConnID returns the value of the "conn_id" field in the mutation.
-func (m *OfflineSessionMutation) ConnID() (r string, exists bool) {
-	v := m.conn_id
+// ResetCreatedBy resets all changes to the "created_by" field.
+func (m *OAuth2ClientMutation) ResetCreatedBy() {
+	m.created_by = nil
+	delete(m.clearedFields, oauth2client.FieldCreatedBy)
+}
+
+// SetUpdatedAt sets the "updated_at" field.
+func (m *OAuth2ClientMutation) SetUpdatedAt(t time.Time) {
+	m.updated_at = &t
+}
+
+// UpdatedAt returns the value of the "updated_at" field in the mutation.
+func (m *OAuth2ClientMutation) UpdatedAt() (r time.Time, exists bool) {
+	v := m.updated_at
 	if v == nil {
 		return
 	}
 	return *v, true
 }
 
-// OldConnID returns the old "conn_id" field's value of the OfflineSession entity.
-// If the OfflineSession object wasn't provided to the builder, the object is fetched from the database.
+// OldUpdatedAt returns the old "updated_at" field's value of the OAuth2Client entity.
+// If the OAuth2Client object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *OfflineSessionMutation) OldConnID(ctx context.Context) (v string, err error) {
+func (m *OAuth2ClientMutation) OldUpdatedAt(ctx context.Context) (v time.Time, err error) {
 	if !m.op.Is(OpUpdateOne) {
-		return v, errors.New("OldConnID is only allowed on UpdateOne operations")
+		return v, errors.New("OldUpdatedAt is only allowed on UpdateOne operations")
 	}
 	if m.id == nil || m.oldValue == nil {
-		return v, errors.New("OldConnID requires an ID field in the mutation")
+		return v, errors.New("OldUpdatedAt requires an ID field in the mutation")
 	}
 	oldValue, err := m.oldValue(ctx)
 	if err != nil {
-		return v, fmt.Errorf("querying old value for OldConnID: %w", err)
+		return v, fmt.Errorf("querying old value for OldUpdatedAt: %w", err)
 	}
-	return oldValue.ConnID, nil
+	return oldValue.UpdatedAt, nil
 }
 
-// ResetConnID resets all changes to the "conn_id" field.
-func (m *OfflineSessionMutation) ResetConnID() {
-	m.conn_id = nil
+// ClearUpdatedAt clears the value of the "updated_at" field.
+func (m *OAuth2ClientMutation) ClearUpdatedAt() {
+	m.updated_at = nil
+	m.clearedFields[oauth2client.FieldUpdatedAt] = struct{}{}
 }
 
-// SetRefresh sets the "refresh" field.
-func (m *OfflineSessionMutation) SetRefresh(b []byte) {
-	m.refresh = &b
+// UpdatedAtCleared returns if the "updated_at" field was cleared in this mutation.
+func (m *OAuth2ClientMutation) UpdatedAtCleared() bool {
+	_, ok := m.clearedFields[oauth2client.FieldUpdatedAt]
+	return ok
 }
 
-// Refresh returns the value of the "refresh" field in the mutation.
-func (m *OfflineSessionMutation) Refresh() (r []byte, exists bool) {
-	v := m.refresh
+// ResetUpdatedAt resets all changes to the "updated_at" field.
+func (m *OAuth2ClientMutation) ResetUpdatedAt() {
+	m.updated_at = nil
+	delete(m.clearedFields, oauth2client.FieldUpdatedAt)
+}
+
+// SetDeletedAt sets the "deleted_at" field.
+func (m *OAuth2ClientMutation) SetDeletedAt(t time.Time) {
+	m.deleted_at = &t
+}
+
+// DeletedAt returns the value of the "deleted_at" field in the mutation.
+func (m *OAuth2ClientMutation) DeletedAt() (r time.Time, exists bool) {
+	v := m.deleted_at
 	if v == nil {
 		return
 	}
 	return *v, true
 }
 
-// OldRefresh returns the old "refresh" field's value of the OfflineSession entity.
-// If the OfflineSession object wasn't provided to the builder, the object is fetched from the database.
+// OldDeletedAt returns the old "deleted_at" field's value of the OAuth2Client entity.
+// If the OAuth2Client object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *OfflineSessionMutation) OldRefresh(ctx context.Context) (v []byte, err error) {
+func (m *OAuth2ClientMutation) OldDeletedAt(ctx context.Context) (v time.Time, err error) {
 	if !m.op.Is(OpUpdateOne) {
-		return v, errors.New("OldRefresh is only allowed on UpdateOne operations")
+		return v, errors.New("OldDeletedAt is only allowed on UpdateOne operations")
 	}
 	if m.id == nil || m.oldValue == nil {
-		return v, errors.New("OldRefresh requires an ID field in the mutation")
+		return v, errors.New("OldDeletedAt requires an ID field in the mutation")
 	}
 	oldValue, err := m.oldValue(ctx)
 	if err != nil {
-		return v, fmt.Errorf("querying old value for OldRefresh: %w", err)
+		return v, fmt.Errorf("querying old value for OldDeletedAt: %w", err)
 	}
-	return oldValue.Refresh, nil
+	return oldValue.DeletedAt, nil
 }
 
-// ResetRefresh resets all changes to the "refresh" field.
-func (m *OfflineSessionMutation) ResetRefresh() {
-	m.refresh = nil
+// ClearDeletedAt clears the value of the "deleted_at" field.
+func (m *OAuth2ClientMutation) ClearDeletedAt() {
+	m.deleted_at = nil
+	m.clearedFields[oauth2client.FieldDeletedAt] = struct{}{}
 }
 
-// SetConnectorData sets the "connector_data" field.
-func (m *OfflineSessionMutation) SetConnectorData(b []byte) {
-	m.connector_data = &b
+// DeletedAtCleared returns if the "deleted_at" field was cleared in this mutation.
+func (m *OAuth2ClientMutation) DeletedAtCleared() bool {
+	_, ok := m.clearedFields[oauth2client.FieldDeletedAt]
+	return ok
 }
 
-// ConnectorData returns the value of the "connector_data" field in the mutation.
-func (m *OfflineSessionMutation) ConnectorData() (r []byte, exists bool) {
-	v := m.connector_data
+// ResetDeletedAt resets all changes to the "deleted_at" field.
+func (m *OAuth2ClientMutation) ResetDeletedAt() {
+	m.deleted_at = nil
+	delete(m.clearedFields, oauth2client.FieldDeletedAt)
+}
+
+// SetPolicyURL sets the "policy_url" field.
+func (m *OAuth2ClientMutation) SetPolicyURL(s string) {
+	m.policy_url = &s
+}
+
+// PolicyURL returns the value of the "policy_url" field in the mutation.
+func (m *OAuth2ClientMutation) PolicyURL() (r string, exists bool) {
+	v := m.policy_url
 	if v == nil {
 		return
 	}
 	return *v, true
 }
 
-// OldConnectorData returns the old "connector_data" field's value of the OfflineSession entity.
-// If the OfflineSession object wasn't provided to the builder, the object is fetched from the database.
+// OldPolicyURL returns the old "policy_url" field's value of the OAuth2Client entity.
+// If the OAuth2Client object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *OfflineSessionMutation) OldConnectorData(ctx context.Context) (v *[]byte, err error) {
+func (m *OAuth2ClientMutation) OldPolicyURL(ctx context.Context) (v string, err error) {
 	if !m.op.Is(OpUpdateOne) {
-		return v, errors.New("OldConnectorData is only allowed on UpdateOne operations")
+		return v, errors.New("OldPolicyURL is only allowed on UpdateOne operations")
 	}
 	if m.id == nil || m.oldValue == nil {
-		return v, errors.New("OldConnectorData requires an ID field in the mutation")
+		return v, errors.New("OldPolicyURL requires an ID field in the mutation")
 	}
 	oldValue, err := m.oldValue(ctx)
 	if err != nil {
-		return v, fmt.Errorf("querying old value for OldConnectorData: %w", err)
+		return v, fmt.Errorf("querying old value for OldPolicyURL: %w", err)
 	}
-	return oldValue.ConnectorData, nil
+	return oldValue.PolicyURL, nil
 }
 
-// ClearConnectorData clears the value of the "connector_data" field.
-func (m *OfflineSessionMutation) ClearConnectorData() {
-	m.connector_data = nil
-	m.clearedFields[offlinesession.FieldConnectorData] = struct{}{}
+// ClearPolicyURL clears the value of the "policy_url" field.
+func (m *OAuth2ClientMutation) ClearPolicyURL() {
+	m.policy_url = nil
+	m.clearedFields[oauth2client.FieldPolicyURL] = struct{}{}
 }
 
-// ConnectorDataCleared returns if the "connector_data" field was cleared in this mutation.
-func (m *OfflineSessionMutation) ConnectorDataCleared() bool {
-	_, ok := m.clearedFields[offlinesession.FieldConnectorData]
+// PolicyURLCleared returns if the "policy_url" field was cleared in this mutation.
+func (m *OAuth2ClientMutation) PolicyURLCleared() bool {
+	_, ok := m.clearedFields[oauth2client.FieldPolicyURL]
 	return ok
 }
 
-// ResetConnectorData resets all changes to the "connector_data" field.
-func (m *OfflineSessionMutation) ResetConnectorData() {
-	m.connector_data = nil
-	delete(m.clearedFields, offlinesession.FieldConnectorData)
+// ResetPolicyURL resets all changes to the "policy_url" field.
+func (m *OAuth2ClientMutation) ResetPolicyURL() {
+	m.policy_url = nil
+	delete(m.clearedFields, oauth2client.FieldPolicyURL)
 }
 
-// Where appends a list predicates to the OfflineSessionMutation builder.
-func (m *OfflineSessionMutation) Where(ps ...predicate.OfflineSession) {
-	m.predicates = append(m.predicates, ps...)
+// SetTermsOfServiceURL sets the "terms_of_service_url" field.
+func (m *OAuth2ClientMutation) SetTermsOfServiceURL(s string) {
+	m.terms_of_service_url = &s
 }
 
-// WhereP appends storage-level predicates to the OfflineSessionMutation builder. Using this method,
+// TermsOfServiceURL returns the value of the "terms_of_service_url" field in the mutation.
+func (m *OAuth2ClientMutation) TermsOfServiceURL() (r string, exists bool) {
+	v := m.terms_of_service_url
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldTermsOfServiceURL returns the old "terms_of_service_url" field's value of the OAuth2Client entity.
+// If the OAuth2Client object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *OAuth2ClientMutation) OldTermsOfServiceURL(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldTermsOfServiceURL is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldTermsOfServiceURL requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldTermsOfServiceURL: %w", err)
+	}
+	return oldValue.TermsOfServiceURL, nil
+}
+
+// ClearTermsOfServiceURL clears the value of the "terms_of_service_url" field.
+func (m *OAuth2ClientMutation) ClearTermsOfServiceURL() {
+	m.terms_of_service_url = nil
+	m.clearedFields[oauth2client.FieldTermsOfServiceURL] = struct{}{}
+}
+
+// TermsOfServiceURLCleared returns if the "terms_of_service_url" field was cleared in this mutation.
+func (m *OAuth2ClientMutation) TermsOfServiceURLCleared() bool {
+	_, ok := m.clearedFields[oauth2client.FieldTermsOfServiceURL]
+	return ok
+}
+
+// ResetTermsOfServiceURL resets all changes to the "terms_of_service_url" field.
+func (m *OAuth2ClientMutation) ResetTermsOfServiceURL() {
+	m.terms_of_service_url = nil
+	delete(m.clearedFields, oauth2client.FieldTermsOfServiceURL)
+}
+
+// SetAllowedOrigins sets the "allowed_origins" field.
+func (m *OAuth2ClientMutation) SetAllowedOrigins(s []string) {
+	m.allowed_origins = &s
+	m.appendallowed_origins = nil
+}
+
+// AllowedOrigins returns the value of the "allowed_origins" field in the mutation.
+func (m *OAuth2ClientMutation) AllowedOrigins() (r []string, exists bool) {
+	v := m.allowed_origins
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldAllowedOrigins returns the old "allowed_origins" field's value of the OAuth2Client entity.
+// If the OAuth2Client object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *OAuth2ClientMutation) OldAllowedOrigins(ctx context.Context) (v []string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldAllowedOrigins is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldAllowedOrigins requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldAllowedOrigins: %w", err)
+	}
+	return oldValue.AllowedOrigins, nil
+}
+
+// AppendAllowedOrigins adds s to the "allowed_origins" field.
+func (m *OAuth2ClientMutation) AppendAllowedOrigins(s []string) {
+	m.appendallowed_origins = append(m.appendallowed_origins, s...)
+}
+
+// AppendedAllowedOrigins returns the list of values that were appended to the "allowed_origins" field in this mutation.
+func (m *OAuth2ClientMutation) AppendedAllowedOrigins() ([]string, bool) {
+	if len(m.appendallowed_origins) == 0 {
+		return nil, false
+	}
+	return m.appendallowed_origins, true
+}
+
+// ClearAllowedOrigins clears the value of the "allowed_origins" field.
+func (m *OAuth2ClientMutation) ClearAllowedOrigins() {
+	m.allowed_origins = nil
+	m.appendallowed_origins = nil
+	m.clearedFields[oauth2client.FieldAllowedOrigins] = struct{}{}
+}
+
+// AllowedOriginsCleared returns if the "allowed_origins" field was cleared in this mutation.
+func (m *OAuth2ClientMutation) AllowedOriginsCleared() bool {
+	_, ok := m.clearedFields[oauth2client.FieldAllowedOrigins]
+	return ok
+}
+
+// ResetAllowedOrigins resets all changes to the "allowed_origins" field.
+func (m *OAuth2ClientMutation) ResetAllowedOrigins() {
+	m.allowed_origins = nil
+	m.appendallowed_origins = nil
+	delete(m.clearedFields, oauth2client.FieldAllowedOrigins)
+}
+
+// SetForwardClaimSourceTokens sets the "forward_claim_source_tokens" field.
+func (m *OAuth2ClientMutation) SetForwardClaimSourceTokens(b bool) {
+	m.forward_claim_source_tokens = &b
+}
+
+// ForwardClaimSourceTokens returns the value of the "forward_claim_source_tokens" field in the mutation.
+func (m *OAuth2ClientMutation) ForwardClaimSourceTokens() (r bool, exists bool) {
+	v := m.forward_claim_source_tokens
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldForwardClaimSourceTokens returns the old "forward_claim_source_tokens" field's value of the OAuth2Client entity.
+// If the OAuth2Client object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *OAuth2ClientMutation) OldForwardClaimSourceTokens(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldForwardClaimSourceTokens is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldForwardClaimSourceTokens requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldForwardClaimSourceTokens: %w", err)
+	}
+	return oldValue.ForwardClaimSourceTokens, nil
+}
+
+// ResetForwardClaimSourceTokens resets all changes to the "forward_claim_source_tokens" field.
+func (m *OAuth2ClientMutation) ResetForwardClaimSourceTokens() {
+	m.forward_claim_source_tokens = nil
+}
+
+// Where appends a list predicates to the OAuth2ClientMutation builder.
+func (m *OAuth2ClientMutation) Where(ps ...predicate.OAuth2Client) {
+	m.predicates = append(m.predicates, ps...)
+}
+
+// WhereP appends storage-level predicates to the OAuth2ClientMutation builder. Using this method,
 // users can use type-assertion to append predicates that do not depend on any generated package.
-func (m *OfflineSessionMutation) WhereP(ps ...func(*sql.Selector)) {
-	p := make([]predicate.OfflineSession, len(ps))
+func (m *OAuth2ClientMutation) WhereP(ps ...func(*sql.Selector)) {
+	p := make([]predicate.OAuth2Client, len(ps))
 	for i := range ps {
 		p[i] = ps[i]
 	}
@@ -6088,36 +7185,87 @@ func (m *OfflineSessionMutation) WhereP(ps ...func(*sql.Selector)) {
 }
 
 // Op returns the operation name.
-func (m *OfflineSessionMutation) Op() Op {
+func (m *OAuth2ClientMutation) Op() Op {
 	return m.op
 }
 
 // SetOp allows setting the mutation operation.
-func (m *OfflineSessionMutation) SetOp(op Op) {
+func (m *OAuth2ClientMutation) SetOp(op Op) {
 	m.op = op
 }
 
-// Type returns the node type of this mutation (OfflineSession).
-func (m *OfflineSessionMutation) Type() string {
+// Type returns the node type of this mutation (OAuth2Client).
+func (m *OAuth2ClientMutation) Type() string {
 	return m.typ
 }
 
 // Fields returns all fields that were changed during this mutation. Note that in
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
-func (m *OfflineSessionMutation) Fields() []string {
-	fields := make([]string, 0, 4)
-	if m.user_id != nil {
-		fields = append(fields, offlinesession.FieldUserID)
+func (m *OAuth2ClientMutation) Fields() []string {
+	fields := make([]string, 0, 21)
+	if m.secret != nil {
+		fields = append(fields, oauth2client.FieldSecret)
 	}
-	if m.conn_id != nil {
-		fields = append(fields, offlinesession.FieldConnID)
+	if m.redirect_uris != nil {
+		fields = append(fields, oauth2client.FieldRedirectUris)
 	}
-	if m.refresh != nil {
-		fields = append(fields, offlinesession.FieldRefresh)
+	if m.trusted_peers != nil {
+		fields = append(fields, oauth2client.FieldTrustedPeers)
 	}
-	if m.connector_data != nil {
-		fields = append(fields, offlinesession.FieldConnectorData)
+	if m.public != nil {
+		fields = append(fields, oauth2client.FieldPublic)
+	}
+	if m.name != nil {
+		fields = append(fields, oauth2client.FieldName)
+	}
+	if m.logo_url != nil {
+		fields = append(fields, oauth2client.FieldLogoURL)
+	}
+	if m.refresh_token_policy != nil {
+		fields = append(fields, oauth2client.FieldRefreshTokenPolicy)
+	}
+	if m.token_expiry != nil {
+		fields = append(fields, oauth2client.FieldTokenExpiry)
+	}
+	if m.groups_filter != nil {
+		fields = append(fields, oauth2client.FieldGroupsFilter)
+	}
+	if m.claims_transforms != nil {
+		fields = append(fields, oauth2client.FieldClaimsTransforms)
+	}
+	if m.redirect_uri_patterns != nil {
+		fields = append(fields, oauth2client.FieldRedirectURIPatterns)
+	}
+	if m.previous_secret != nil {
+		fields = append(fields, oauth2client.FieldPreviousSecret)
+	}
+	if m.created_at != nil {
+		fields = append(fields, oauth2client.FieldCreatedAt)
+	}
+	if m.last_used != nil {
+		fields = append(fields, oauth2client.FieldLastUsed)
+	}
+	if m.created_by != nil {
+		fields = append(fields, oauth2client.FieldCreatedBy)
+	}
+	if m.updated_at != nil {
+		fields = append(fields, oauth2client.FieldUpdatedAt)
+	}
+	if m.deleted_at != nil {
+		fields = append(fields, oauth2client.FieldDeletedAt)
+	}
+	if m.policy_url != nil {
+		fields = append(fields, oauth2client.FieldPolicyURL)
+	}
+	if m.terms_of_service_url != nil {
+		fields = append(fields, oauth2client.FieldTermsOfServiceURL)
+	}
+	if m.allowed_origins != nil {
+		fields = append(fields, oauth2client.FieldAllowedOrigins)
+	}
+	if m.forward_claim_source_tokens != nil {
+		fields = append(fields, oauth2client.FieldForwardClaimSourceTokens)
 	}
 	return fields
 }
@@ -6125,16 +7273,50 @@ func (m *OfflineSessionMutation) Fields() []string {
 // Field returns the value of a field with the given name. The second boolean
 // return value indicates that this field was not set, or was not defined in the
 // schema.
-func (m *OfflineSessionMutation) Field(name string) (ent.Value, bool) {
+func (m *OAuth2ClientMutation) Field(name string) (ent.Value, bool) {
 	switch name {
-	case offlinesession.FieldUserID:
-		return m.UserID()
-	case offlinesession.FieldConnID:
-		return m.ConnID()
-	case offlinesession.FieldRefresh:
-		return m.Refresh()
-	case offlinesession.FieldConnectorData:
-		return m.ConnectorData()
+	case oauth2client.FieldSecret:
+		return m.Secret()
+	case oauth2client.FieldRedirectUris:
+		return m.RedirectUris()
+	case oauth2client.FieldTrustedPeers:
+		return m.TrustedPeers()
+	case oauth2client.FieldPublic:
+		return m.Public()
+	case oauth2client.FieldName:
+		return m.Name()
+	case oauth2client.FieldLogoURL:
+		return m.LogoURL()
+	case oauth2client.FieldRefreshTokenPolicy:
+		return m.RefreshTokenPolicy()
+	case oauth2client.FieldTokenExpiry:
+		return m.TokenExpiry()
+	case oauth2client.FieldGroupsFilter:
+		return m.GroupsFilter()
+	case oauth2client.FieldClaimsTransforms:
+		return m.ClaimsTransforms()
+	case oauth2client.FieldRedirectURIPatterns:
+		return m.RedirectURIPatterns()
+	case oauth2client.FieldPreviousSecret:
+		return m.PreviousSecret()
+	case oauth2client.FieldCreatedAt:
+		return m.CreatedAt()
+	case oauth2client.FieldLastUsed:
+		return m.LastUsed()
+	case oauth2client.FieldCreatedBy:
+		return m.CreatedBy()
+	case oauth2client.FieldUpdatedAt:
+		return m.UpdatedAt()
+	case oauth2client.FieldDeletedAt:
+		return m.DeletedAt()
+	case oauth2client.FieldPolicyURL:
+		return m.PolicyURL()
+	case oauth2client.FieldTermsOfServiceURL:
+		return m.TermsOfServiceURL()
+	case oauth2client.FieldAllowedOrigins:
+		return m.AllowedOrigins()
+	case oauth2client.FieldForwardClaimSourceTokens:
+		return m.ForwardClaimSourceTokens()
 	}
 	return nil, false
 }
@@ -6142,202 +7324,496 @@ func (m *OfflineSessionMutation) Field(name string) (ent.Value, bool) {
 // OldField returns the old value of the field from the database. An error is
 // returned if the mutation operation is not UpdateOne, or the query to the
 // database failed.
-func (m *OfflineSessionMutation) OldField(ctx context.Context, name string) (ent.Value, error) {
+func (m *OAuth2ClientMutation) OldField(ctx context.Context, name string) (ent.Value, error) {
 	switch name {
-	case offlinesession.FieldUserID:
-		return m.OldUserID(ctx)
-	case offlinesession.FieldConnID:
-		return m.OldConnID(ctx)
-	case offlinesession.FieldRefresh:
-		return m.OldRefresh(ctx)
-	case offlinesession.FieldConnectorData:
-		return m.OldConnectorData(ctx)
+	case oauth2client.FieldSecret:
+		return m.OldSecret(ctx)
+	case oauth2client.FieldRedirectUris:
+		return m.OldRedirectUris(ctx)
+	case oauth2client.FieldTrustedPeers:
+		return m.OldTrustedPeers(ctx)
+	case oauth2client.FieldPublic:
+		return m.OldPublic(ctx)
+	case oauth2client.FieldName:
+		return m.OldName(ctx)
+	case oauth2client.FieldLogoURL:
+		return m.OldLogoURL(ctx)
+	case oauth2client.FieldRefreshTokenPolicy:
+		return m.OldRefreshTokenPolicy(ctx)
+	case oauth2client.FieldTokenExpiry:
+		return m.OldTokenExpiry(ctx)
+	case oauth2client.FieldGroupsFilter:
+		return m.OldGroupsFilter(ctx)
+	case oauth2client.FieldClaimsTransforms:
+		return m.OldClaimsTransforms(ctx)
+	case oauth2client.FieldRedirectURIPatterns:
+		return m.OldRedirectURIPatterns(ctx)
+	case oauth2client.FieldPreviousSecret:
+		return m.OldPreviousSecret(ctx)
+	case oauth2client.FieldCreatedAt:
+		return m.OldCreatedAt(ctx)
+	case oauth2client.FieldLastUsed:
+		return m.OldLastUsed(ctx)
+	case oauth2client.FieldCreatedBy:
+		return m.OldCreatedBy(ctx)
+	case oauth2client.FieldUpdatedAt:
+		return m.OldUpdatedAt(ctx)
+	case oauth2client.FieldDeletedAt:
+		return m.OldDeletedAt(ctx)
+	case oauth2client.FieldPolicyURL:
+		return m.OldPolicyURL(ctx)
+	case oauth2client.FieldTermsOfServiceURL:
+		return m.OldTermsOfServiceURL(ctx)
+	case oauth2client.FieldAllowedOrigins:
+		return m.OldAllowedOrigins(ctx)
+	case oauth2client.FieldForwardClaimSourceTokens:
+		return m.OldForwardClaimSourceTokens(ctx)
 	}
-	return nil, fmt.Errorf("unknown OfflineSession field %s", name)
+	return nil, fmt.Errorf("unknown OAuth2Client field %s", name)
 }
 
 // SetField sets the value of a field with the given name. It returns an error if
 // the field is not defined in the schema, or if the type mismatched the field
 // type.
-func (m *OfflineSessionMutation) SetField(name string, value ent.Value) error {
+func (m *OAuth2ClientMutation) SetField(name string, value ent.Value) error {
 	switch name {
-	case offlinesession.FieldUserID:
+	case oauth2client.FieldSecret:
 		v, ok := value.(string)
 		if !ok {
 			return fmt.Errorf("unexpected type %T for field %s", value, name)
 		}
-		m.SetUserID(v)
+		m.SetSecret(v)
 		return nil
-	case offlinesession.FieldConnID:
+	case oauth2client.FieldRedirectUris:
+		v, ok := value.([]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetRedirectUris(v)
+		return nil
+	case oauth2client.FieldTrustedPeers:
+		v, ok := value.([]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetTrustedPeers(v)
+		return nil
+	case oauth2client.FieldPublic:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetPublic(v)
+		return nil
+	case oauth2client.FieldName:
 		v, ok := value.(string)
 		if !ok {
 			return fmt.Errorf("unexpected type %T for field %s", value, name)
 		}
-		m.SetConnID(v)
+		m.SetName(v)
 		return nil
-	case offlinesession.FieldRefresh:
-		v, ok := value.([]byte)
+	case oauth2client.FieldLogoURL:
+		v, ok := value.(string)
 		if !ok {
 			return fmt.Errorf("unexpected type %T for field %s", value, name)
 		}
-		m.SetRefresh(v)
+		m.SetLogoURL(v)
 		return nil
-	case offlinesession.FieldConnectorData:
-		v, ok := value.([]byte)
+	case oauth2client.FieldRefreshTokenPolicy:
+		v, ok := value.(*storage.RefreshTokenPolicy)
 		if !ok {
 			return fmt.Errorf("unexpected type %T for field %s", value, name)
 		}
-		m.SetConnectorData(v)
+		m.SetRefreshTokenPolicy(v)
 		return nil
-	}
-	return fmt.Errorf("unknown OfflineSession field %s", name)
-}
-
-// AddedFields returns all numeric fields that were incremented/decremented during
-// this mutation.
-func (m *OfflineSessionMutation) AddedFields() []string {
-	return nil
-}
-
-// AddedField returns the numeric value that was incremented/decremented on a field
-// with the given name. The second boolean return value indicates that this field
-// was not set, or was not defined in the schema.
-func (m *OfflineSessionMutation) AddedField(name string) (ent.Value, bool) {
-	return nil, false
-}
-
-// AddField adds the value to the field with the given name. It returns an error if
-// the field is not defined in the schema, or if the type mismatched the field
-// type.
-func (m *OfflineSessionMutation) AddField(name string, value ent.Value) error {
-	switch name {
-	}
-	return fmt.Errorf("unknown OfflineSession numeric field %s", name)
-}
-
-// ClearedFields returns all nullable fields that were cleared during this
-// mutation.
-func (m *OfflineSessionMutation) ClearedFields() []string {
+	case oauth2client.FieldTokenExpiry:
+		v, ok := value.(*storage.TokenExpiry)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetTokenExpiry(v)
+		return nil
+	case oauth2client.FieldGroupsFilter:
+		v, ok := value.(*storage.GroupsFilter)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetGroupsFilter(v)
+		return nil
+	case oauth2client.FieldClaimsTransforms:
+		v, ok := value.([]storage.ClaimsTransform)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetClaimsTransforms(v)
+		return nil
+	case oauth2client.FieldRedirectURIPatterns:
+		v, ok := value.([]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetRedirectURIPatterns(v)
+		return nil
+	case oauth2client.FieldPreviousSecret:
+		v, ok := value.(*storage.PreviousSecret)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetPreviousSecret(v)
+		return nil
+	case oauth2client.FieldCreatedAt:
+		v, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetCreatedAt(v)
+		return nil
+	case oauth2client.FieldLastUsed:
+		v, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetLastUsed(v)
+		return nil
+	case oauth2client.FieldCreatedBy:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetCreatedBy(v)
+		return nil
+	case oauth2client.FieldUpdatedAt:
+		v, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetUpdatedAt(v)
+		return nil
+	case oauth2client.FieldDeletedAt:
+		v, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetDeletedAt(v)
+		return nil
+	case oauth2client.FieldPolicyURL:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetPolicyURL(v)
+		return nil
+	case oauth2client.FieldTermsOfServiceURL:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetTermsOfServiceURL(v)
+		return nil
+	case oauth2client.FieldAllowedOrigins:
+		v, ok := value.([]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetAllowedOrigins(v)
+		return nil
+	case oauth2client.FieldForwardClaimSourceTokens:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetForwardClaimSourceTokens(v)
+		return nil
+	}
+	return fmt.Errorf("unknown OAuth2Client field %s", name)
+}
+
+// AddedFields returns all numeric fields that were incremented/decremented during
+// this mutation.
+func (m *OAuth2ClientMutation) AddedFields() []string {
+	return nil
+}
+
+// AddedField returns the numeric value that was incremented/decremented on a field
+// with the given name. The second boolean return value indicates that this field
+// was not set, or was not defined in the schema.
+func (m *OAuth2ClientMutation) AddedField(name string) (ent.Value, bool) {
+	return nil, false
+}
+
+// AddField adds the value to the field with the given name. It returns an error if
+// the field is not defined in the schema, or if the type mismatched the field
+// type.
+func (m *OAuth2ClientMutation) AddField(name string, value ent.Value) error {
+	switch name {
+	}
+	return fmt.Errorf("unknown OAuth2Client numeric field %s", name)
+}
+
+// ClearedFields returns all nullable fields that were cleared during this
+// mutation.
+func (m *OAuth2ClientMutation) ClearedFields() []string {
 	var fields []string
-	if m.FieldCleared(offlinesession.FieldConnectorData) {
-		fields = append(fields, offlinesession.FieldConnectorData)
+	if m.FieldCleared(oauth2client.FieldRedirectUris) {
+		fields = append(fields, oauth2client.FieldRedirectUris)
+	}
+	if m.FieldCleared(oauth2client.FieldTrustedPeers) {
+		fields = append(fields, oauth2client.FieldTrustedPeers)
+	}
+	if m.FieldCleared(oauth2client.FieldRefreshTokenPolicy) {
+		fields = append(fields, oauth2client.FieldRefreshTokenPolicy)
+	}
+	if m.FieldCleared(oauth2client.FieldTokenExpiry) {
+		fields = append(fields, oauth2client.FieldTokenExpiry)
+	}
+	if m.FieldCleared(oauth2client.FieldGroupsFilter) {
+		fields = append(fields, oauth2client.FieldGroupsFilter)
+	}
+	if m.FieldCleared(oauth2client.FieldClaimsTransforms) {
+		fields = append(fields, oauth2client.FieldClaimsTransforms)
+	}
+	if m.FieldCleared(oauth2client.FieldRedirectURIPatterns) {
+		fields = append(fields, oauth2client.FieldRedirectURIPatterns)
+	}
+	if m.FieldCleared(oauth2client.FieldPreviousSecret) {
+		fields = append(fields, oauth2client.FieldPreviousSecret)
+	}
+	if m.FieldCleared(oauth2client.FieldCreatedAt) {
+		fields = append(fields, oauth2client.FieldCreatedAt)
+	}
+	if m.FieldCleared(oauth2client.FieldLastUsed) {
+		fields = append(fields, oauth2client.FieldLastUsed)
+	}
+	if m.FieldCleared(oauth2client.FieldCreatedBy) {
+		fields = append(fields, oauth2client.FieldCreatedBy)
+	}
+	if m.FieldCleared(oauth2client.FieldUpdatedAt) {
+		fields = append(fields, oauth2client.FieldUpdatedAt)
+	}
+	if m.FieldCleared(oauth2client.FieldDeletedAt) {
+		fields = append(fields, oauth2client.FieldDeletedAt)
+	}
+	if m.FieldCleared(oauth2client.FieldPolicyURL) {
+		fields = append(fields, oauth2client.FieldPolicyURL)
+	}
+	if m.FieldCleared(oauth2client.FieldTermsOfServiceURL) {
+		fields = append(fields, oauth2client.FieldTermsOfServiceURL)
+	}
+	if m.FieldCleared(oauth2client.FieldAllowedOrigins) {
+		fields = append(fields, oauth2client.FieldAllowedOrigins)
 	}
 	return fields
 }
 
 // FieldCleared returns a boolean indicating if a field with the given name was
 // cleared in this mutation.
-func (m *OfflineSessionMutation) FieldCleared(name string) bool {
+func (m *OAuth2ClientMutation) FieldCleared(name string) bool {
 	_, ok := m.clearedFields[name]
 	return ok
 }
 
 // ClearField clears the value of the field with the given name. It returns an
 // error if the field is not defined in the schema.
-func (m *OfflineSessionMutation) ClearField(name string) error {
+func (m *OAuth2ClientMutation) ClearField(name string) error {
 	switch name {
-	case offlinesession.FieldConnectorData:
-		m.ClearConnectorData()
+	case oauth2client.FieldRedirectUris:
+		m.ClearRedirectUris()
+		return nil
+	case oauth2client.FieldTrustedPeers:
+		m.ClearTrustedPeers()
+		return nil
+	case oauth2client.FieldRefreshTokenPolicy:
+		m.ClearRefreshTokenPolicy()
+		return nil
+	case oauth2client.FieldTokenExpiry:
+		m.ClearTokenExpiry()
+		return nil
+	case oauth2client.FieldGroupsFilter:
+		m.ClearGroupsFilter()
+		return nil
+	case oauth2client.FieldClaimsTransforms:
+		m.ClearClaimsTransforms()
+		return nil
+	case oauth2client.FieldRedirectURIPatterns:
+		m.ClearRedirectURIPatterns()
+		return nil
+	case oauth2client.FieldPreviousSecret:
+		m.ClearPreviousSecret()
+		return nil
+	case oauth2client.FieldCreatedAt:
+		m.ClearCreatedAt()
+		return nil
+	case oauth2client.FieldLastUsed:
+		m.ClearLastUsed()
+		return nil
+	case oauth2client.FieldCreatedBy:
+		m.ClearCreatedBy()
+		return nil
+	case oauth2client.FieldUpdatedAt:
+		m.ClearUpdatedAt()
+		return nil
+	case oauth2client.FieldDeletedAt:
+		m.ClearDeletedAt()
+		return nil
+	case oauth2client.FieldPolicyURL:
+		m.ClearPolicyURL()
+		return nil
+	case oauth2client.FieldTermsOfServiceURL:
+		m.ClearTermsOfServiceURL()
+		return nil
+	case oauth2client.FieldAllowedOrigins:
+		m.ClearAllowedOrigins()
 		return nil
 	}
-	return fmt.Errorf("unknown OfflineSession nullable field %s", name)
+	return fmt.Errorf("unknown OAuth2Client nullable field %s", name)
 }
 
 // ResetField resets all changes in the mutation for the field with the given name.
 // It returns an error if the field is not defined in the schema.
-func (m *OfflineSessionMutation) ResetField(name string) error {
+func (m *OAuth2ClientMutation) ResetField(name string) error {
 	switch name {
-	case offlinesession.FieldUserID:
-		m.ResetUserID()
+	case oauth2client.FieldSecret:
+		m.ResetSecret()
 		return nil
-	case offlinesession.FieldConnID:
-		m.ResetConnID()
+	case oauth2client.FieldRedirectUris:
+		m.ResetRedirectUris()
 		return nil
-	case offlinesession.FieldRefresh:
-		m.ResetRefresh()
+	case oauth2client.FieldTrustedPeers:
+		m.ResetTrustedPeers()
 		return nil
-	case offlinesession.FieldConnectorData:
-		m.ResetConnectorData()
+	case oauth2client.FieldPublic:
+		m.ResetPublic()
+		return nil
+	case oauth2client.FieldName:
+		m.ResetName()
+		return nil
+	case oauth2client.FieldLogoURL:
+		m.ResetLogoURL()
+		return nil
+	case oauth2client.FieldRefreshTokenPolicy:
+		m.ResetRefreshTokenPolicy()
+		return nil
+	case oauth2client.FieldTokenExpiry:
+		m.ResetTokenExpiry()
+		return nil
+	case oauth2client.FieldGroupsFilter:
+		m.ResetGroupsFilter()
+		return nil
+	case oauth2client.FieldClaimsTransforms:
+		m.ResetClaimsTransforms()
+		return nil
+	case oauth2client.FieldRedirectURIPatterns:
+		m.ResetRedirectURIPatterns()
+		return nil
+	case oauth2client.FieldPreviousSecret:
+		m.ResetPreviousSecret()
+		return nil
+	case oauth2client.FieldCreatedAt:
+		m.ResetCreatedAt()
+		return nil
+	case oauth2client.FieldLastUsed:
+		m.ResetLastUsed()
+		return nil
+	case oauth2client.FieldCreatedBy:
+		m.ResetCreatedBy()
+		return nil
+	case oauth2client.FieldUpdatedAt:
+		m.ResetUpdatedAt()
+		return nil
+	case oauth2client.FieldDeletedAt:
+		m.ResetDeletedAt()
+		return nil
+	case oauth2client.FieldPolicyURL:
+		m.ResetPolicyURL()
+		return nil
+	case oauth2client.FieldTermsOfServiceURL:
+		m.ResetTermsOfServiceURL()
+		return nil
+	case oauth2client.FieldAllowedOrigins:
+		m.ResetAllowedOrigins()
+		return nil
+	case oauth2client.FieldForwardClaimSourceTokens:
+		m.ResetForwardClaimSourceTokens()
 		return nil
 	}
-	return fmt.Errorf("unknown OfflineSession field %s", name)
+	return fmt.Errorf("unknown OAuth2Client field %s", name)
 }
 
 // AddedEdges returns all edge names that were set/added in this mutation.
-func (m *OfflineSessionMutation) AddedEdges() []string {
+func (m *OAuth2ClientMutation) AddedEdges() []string {
 	edges := make([]string, 0, 0)
 	return edges
 }
 
 // AddedIDs returns all IDs (to other nodes) that were added for the given edge
 // name in this mutation.
-func (m *OfflineSessionMutation) AddedIDs(name string) []ent.Value {
+func (m *OAuth2ClientMutation) AddedIDs(name string) []ent.Value {
 	return nil
 }
 
 // RemovedEdges returns all edge names that were removed in this mutation.
-func (m *OfflineSessionMutation) RemovedEdges() []string {
+func (m *OAuth2ClientMutation) RemovedEdges() []string {
 	edges := make([]string, 0, 0)
 	return edges
 }
 
 // RemovedIDs returns all IDs (to other nodes) that were removed for the edge with
 // the given name in this mutation.
-func (m *OfflineSessionMutation) RemovedIDs(name string) []ent.Value {
+func (m *OAuth2ClientMutation) RemovedIDs(name string) []ent.Value {
 	return nil
 }
 
 // ClearedEdges returns all edge names that were cleared in this mutation.
-func (m *OfflineSessionMutation) ClearedEdges() []string {
+func (m *OAuth2ClientMutation) ClearedEdges() []string {
 	edges := make([]string, 0, 0)
 	return edges
 }
 
 // EdgeCleared returns a boolean which indicates if the edge with the given name
 // was cleared in this mutation.
-func (m *OfflineSessionMutation) EdgeCleared(name string) bool {
+func (m *OAuth2ClientMutation) EdgeCleared(name string) bool {
 	return false
 }
 
 // ClearEdge clears the value of the edge with the given name. It returns an error
 // if that edge is not defined in the schema.
-func (m *OfflineSessionMutation) ClearEdge(name string) error {
-	return fmt.Errorf("unknown OfflineSession unique edge %s", name)
+func (m *OAuth2ClientMutation) ClearEdge(name string) error {
+	return fmt.Errorf("unknown OAuth2Client unique edge %s", name)
 }
 
 // ResetEdge resets all changes to the edge with the given name in this mutation.
 // It returns an error if the edge is not defined in the schema.
-func (m *OfflineSessionMutation) ResetEdge(name string) error {
-	return fmt.Errorf("unknown OfflineSession edge %s", name)
+func (m *OAuth2ClientMutation) ResetEdge(name string) error {
+	return fmt.Errorf("unknown OAuth2Client edge %s", name)
 }
 
-// PasswordMutation represents an operation that mutates the Password nodes in the graph.
-type PasswordMutation struct {
+// OfflineSessionMutation represents an operation that mutates the OfflineSession nodes in the graph.
+type OfflineSessionMutation struct {
 	config
-	op            Op
-	typ           string
-	id            *int
-	email         *string
-	hash          *[]byte
-	username      *string
-	user_id       *string
-	clearedFields map[string]struct{}
-	done          bool
-	oldValue      func(context.Context) (*Password, error)
-	predicates    []predicate.Password
+	op             Op
+	typ            string
+	id             *string
+	user_id        *string
+	conn_id        *string
+	refresh        *[]byte
+	connector_data *[]byte
+	clearedFields  map[string]struct{}
+	done           bool
+	oldValue       func(context.Context) (*OfflineSession, error)
+	predicates     []predicate.OfflineSession
 }
 
-var _ ent.Mutation = (*PasswordMutation)(nil)
+var _ ent.Mutation = (*OfflineSessionMutation)(nil)
 
-// passwordOption allows management of the mutation configuration using functional options.
-type passwordOption func(*PasswordMutation)
+// offlinesessionOption allows management of the mutation configuration using functional options.
+type offlinesessionOption func(*OfflineSessionMutation)
 
-// newPasswordMutation creates new mutation for the Password entity.
-func newPasswordMutation(c config, op Op, opts ...passwordOption) *PasswordMutation {
-	m := &PasswordMutation{
+// newOfflineSessionMutation creates new mutation for the OfflineSession entity.
+func newOfflineSessionMutation(c config, op Op, opts ...offlinesessionOption) *OfflineSessionMutation {
+	m := &OfflineSessionMutation{
 		config:        c,
 		op:            op,
-		typ:           TypePassword,
+		typ:           TypeOfflineSession,
 		clearedFields: make(map[string]struct{}),
 	}
 	for _, opt := range opts {
@@ -6346,20 +7822,20 @@ func newPasswordMutation(c config, op Op, opts ...passwordOption) *PasswordMutat
 	return m
 }
 
-// withPasswordID sets the ID field of the mutation.
-func withPasswordID(id int) passwordOption {
-	return func(m *PasswordMutation) {
+// withOfflineSessionID sets the ID field of the mutation.
+func withOfflineSessionID(id string) offlinesessionOption {
+	return func(m *OfflineSessionMutation) {
 		var (
 			err   error
 			once  sync.Once
-			value *Password
+			value *OfflineSession
 		)
-		m.oldValue = func(ctx context.Context) (*Password, error) {
+		m.oldValue = func(ctx context.Context) (*OfflineSession, error) {
 			once.Do(func() {
 				if m.done {
 					err = errors.New("querying old values post mutation is not allowed")
 				} else {
-					value, err = m.Client().Password.Get(ctx, id)
+					value, err = m.Client().OfflineSession.Get(ctx, id)
 				}
 			})
 			return value, err
@@ -6368,10 +7844,10 @@ func withPasswordID(id int) passwordOption {
 	}
 }
 
-// withPassword sets the old Password of the mutation.
-func withPassword(node *Password) passwordOption {
-	return func(m *PasswordMutation) {
-		m.oldValue = func(context.Context) (*Password, error) {
+// withOfflineSession sets the old OfflineSession of the mutation.
+func withOfflineSession(node *OfflineSession) offlinesessionOption {
+	return func(m *OfflineSessionMutation) {
+		m.oldValue = func(context.Context) (*OfflineSession, error) {
 			return node, nil
 		}
 		m.id = &node.ID
@@ -6380,7 +7856,7 @@ func withPassword(node *Password) passwordOption {
 
 // Client returns a new `ent.Client` from the mutation. If the mutation was
 // executed in a transaction (ent.Tx), a transactional client is returned.
-func (m PasswordMutation) Client() *Client {
+func (m OfflineSessionMutation) Client() *Client {
 	client := &Client{config: m.config}
 	client.init()
 	return client
@@ -6388,7 +7864,7 @@ func (m PasswordMutation) Client() *Client {
 
 // Tx returns an `ent.Tx` for mutations that were executed in transactions;
 // it returns an error otherwise.
-func (m PasswordMutation) Tx() (*Tx, error) {
+func (m OfflineSessionMutation) Tx() (*Tx, error) {
 	if _, ok := m.driver.(*txDriver); !ok {
 		return nil, errors.New("db: mutation is not running in a transaction")
 	}
@@ -6397,9 +7873,15 @@ func (m PasswordMutation) Tx() (*Tx, error) {
 	return tx, nil
 }
 
+// SetID sets the value of the id field. Note that this
+// operation is only accepted on creation of OfflineSession entities.
+func (m *OfflineSessionMutation) SetID(id string) {
+	m.id = &id
+}
+
 // ID returns the ID value in the mutation. Note that the ID is only available
 // if it was provided to the builder or after it was returned from the database.
-func (m *PasswordMutation) ID() (id int, exists bool) {
+func (m *OfflineSessionMutation) ID() (id string, exists bool) {
 	if m.id == nil {
 		return
 	}
@@ -6410,174 +7892,187 @@ func (m *PasswordMutation) ID() (id int, exists bool) {
 // That means, if the mutation is applied within a transaction with an isolation level such
 // as sql.LevelSerializable, the returned ids match the ids of the rows that will be updated
 // or updated by the mutation.
-func (m *PasswordMutation) IDs(ctx context.Context) ([]int, error) {
+func (m *OfflineSessionMutation) IDs(ctx context.Context) ([]string, error) {
 	switch {
 	case m.op.Is(OpUpdateOne | OpDeleteOne):
 		id, exists := m.ID()
 		if exists {
-			return []int{id}, nil
+			return []string{id}, nil
 		}
 		fallthrough
 	case m.op.Is(OpUpdate | OpDelete):
-		return m.Client().Password.Query().Where(m.predicates...).IDs(ctx)
+		return m.Client().OfflineSession.Query().Where(m.predicates...).IDs(ctx)
 	default:
 		return nil, fmt.Errorf("IDs is not allowed on %s operations", m.op)
 	}
 }
 
-// SetEmail sets the "email" field.
-func (m *PasswordMutation) SetEmail(s string) {
-	m.email = &s
+// SetUserID sets the "user_id" field.
+func (m *OfflineSessionMutation) SetUserID(s string) {
+	m.user_id = &s
 }
 
-// Email returns the value of the "email" field in the mutation.
-func (m *PasswordMutation) Email() (r string, exists bool) {
-	v := m.email
+// UserID returns the value of the "user_id" field in the mutation.
+func (m *OfflineSessionMutation) UserID() (r string, exists bool) {
+	v := m.user_id
 	if v == nil {
 		return
 	}
 	return *v, true
 }
 
-// OldEmail returns the old "email" field's value of the Password entity.
-// If the Password object wasn't provided to the builder, the object is fetched from the database.
+// OldUserID returns the old "user_id" field's value of the OfflineSession entity.
+// If the OfflineSession object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *PasswordMutation) OldEmail(ctx context.Context) (v string, err error) {
+func (m *OfflineSessionMutation) OldUserID(ctx context.Context) (v string, err error) {
 	if !m.op.Is(OpUpdateOne) {
-		return v, errors.New("OldEmail is only allowed on UpdateOne operations")
+		return v, errors.New("OldUserID is only allowed on UpdateOne operations")
 	}
 	if m.id == nil || m.oldValue == nil {
-		return v, errors.New("OldEmail requires an ID field in the mutation")
+		return v, errors.New("OldUserID requires an ID field in the mutation")
 	}
 	oldValue, err := m.oldValue(ctx)
 	if err != nil {
-		return v, fmt.Errorf("querying old value for OldEmail: %w", err)
+		return v, fmt.Errorf("querying old value for OldUserID: %w", err)
 	}
-	return oldValue.Email, nil
+	return oldValue.UserID, nil
 }
 
-// ResetEmail resets all changes to the "email" field.
-func (m *PasswordMutation) ResetEmail() {
-	m.email = nil
+// ResetUserID resets all changes to the "user_id" field.
+func (m *OfflineSessionMutation) ResetUserID() {
+	m.user_id = nil
 }
 
-// SetHash sets the "hash" field.
-func (m *PasswordMutation) SetHash(b []byte) {
-	m.hash = &b
+// SetConnID sets the "conn_id" field.
+func (m *OfflineSessionMutation) SetConnID(s string) {
+	m.conn_id = &s
 }
 
-// Hash returns the value of the "hash" field in the mutation.
-func (m *PasswordMutation) Hash() (r []byte, exists bool) {
-	v := m.hash
+// ConnID returns the value of the "conn_id" field in the mutation.
+func (m *OfflineSessionMutation) ConnID() (r string, exists bool) {
+	v := m.conn_id
 	if v == nil {
 		return
 	}
 	return *v, true
 }
 
-// OldHash returns the old "hash" field's value of the Password entity.
-// If the Password object wasn't provided to the builder, the object is fetched from the database.
+// OldConnID returns the old "conn_id" field's value of the OfflineSession entity.
+// If the OfflineSession object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *PasswordMutation) OldHash(ctx context.Context) (v []byte, err error) {
+func (m *OfflineSessionMutation) OldConnID(ctx context.Context) (v string, err error) {
 	if !m.op.Is(OpUpdateOne) {
-		return v, errors.New("OldHash is only allowed on UpdateOne operations")
+		return v, errors.New("OldConnID is only allowed on UpdateOne operations")
 	}
 	if m.id == nil || m.oldValue == nil {
-		return v, errors.New("OldHash requires an ID field in the mutation")
+		return v, errors.New("OldConnID requires an ID field in the mutation")
 	}
 	oldValue, err := m.oldValue(ctx)
 	if err != nil {
-		return v, fmt.Errorf("querying old value for OldHash: %w", err)
+		return v, fmt.Errorf("querying old value for OldConnID: %w", err)
 	}
-	return oldValue.Hash, nil
+	return oldValue.ConnID, nil
 }
 
-// ResetHash resets all changes to the "hash" field.
-func (m *PasswordMutation) ResetHash() {
-	m.hash = nil
+// ResetConnID resets all changes to the "conn_id" field.
+func (m *OfflineSessionMutation) ResetConnID() {
+	m.conn_id = nil
 }
 
-// SetUsername sets the "username" field.
-func (m *PasswordMutation) SetUsername(s string) {
-	m.username = &s
+// SetRefresh sets the "refresh" field.
+func (m *OfflineSessionMutation) SetRefresh(b []byte) {
+	m.refresh = &b
 }
 
-// Username returns the value of the "username" field in the mutation.
-func (m *PasswordMutation) Username() (r string, exists bool) {
-	v := m.username
+// Refresh returns the value of the "refresh" field in the mutation.
+func (m *OfflineSessionMutation) Refresh() (r []byte, exists bool) {
+	v := m.refresh
 	if v == nil {
 		return
 	}
 	return *v, true
 }
 
-// OldUsername returns the old "username" field's value of the Password entity.
-// If the Password object wasn't provided to the builder, the object is fetched from the database.
+// OldRefresh returns the old "refresh" field's value of the OfflineSession entity.
+// If the OfflineSession object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *PasswordMutation) OldUsername(ctx context.Context) (v string, err error) {
+func (m *OfflineSessionMutation) OldRefresh(ctx context.Context) (v []byte, err error) {
 	if !m.op.Is(OpUpdateOne) {
-		return v, errors.New("OldUsername is only allowed on UpdateOne operations")
+		return v, errors.New("OldRefresh is only allowed on UpdateOne operations")
 	}
 	if m.id == nil || m.oldValue == nil {
-		return v, errors.New("OldUsername requires an ID field in the mutation")
+		return v, errors.New("OldRefresh requires an ID field in the mutation")
 	}
 	oldValue, err := m.oldValue(ctx)
 	if err != nil {
-		return v, fmt.Errorf("querying old value for OldUsername: %w", err)
+		return v, fmt.Errorf("querying old value for OldRefresh: %w", err)
 	}
-	return oldValue.Username, nil
+	return oldValue.Refresh, nil
 }
 
-// ResetUsername resets all changes to the "username" field.
-func (m *PasswordMutation) ResetUsername() {
-	m.username = nil
+// ResetRefresh resets all changes to the "refresh" field.
+func (m *OfflineSessionMutation) ResetRefresh() {
+	m.refresh = nil
 }
 
-// SetUserID sets the "user_id" field.
-func (m *PasswordMutation) SetUserID(s string) {
-	m.user_id = &s
+// SetConnectorData sets the "connector_data" field.
+func (m *OfflineSessionMutation) SetConnectorData(b []byte) {
+	m.connector_data = &b
 }
 
-// UserID returns the value of the "user_id" field in the mutation.
-func (m *PasswordMutation) UserID() (r string, exists bool) {
-	v := m.user_id
+// ConnectorData returns the value of the "connector_data" field in the mutation.
+func (m *OfflineSessionMutation) ConnectorData() (r []byte, exists bool) {
+	v := m.connector_data
 	if v == nil {
 		return
 	}
 	return *v, true
 }
 
-// OldUserID returns the old "user_id" field's value of the Password entity.
-// If the Password object wasn't provided to the builder, the object is fetched from the database.
+// OldConnectorData returns the old "connector_data" field's value of the OfflineSession entity.
+// If the OfflineSession object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *PasswordMutation) OldUserID(ctx context.Context) (v string, err error) {
+func (m *OfflineSessionMutation) OldConnectorData(ctx context.Context) (v *[]byte, err error) {
 	if !m.op.Is(OpUpdateOne) {
-		return v, errors.New("OldUserID is only allowed on UpdateOne operations")
+		return v, errors.New("OldConnectorData is only allowed on UpdateOne operations")
 	}
 	if m.id == nil || m.oldValue == nil {
-		return v, errors.New("OldUserID requires an ID field in the mutation")
+		return v, errors.New("OldConnectorData requires an ID field in the mutation")
 	}
 	oldValue, err := m.oldValue(ctx)
 	if err != nil {
-		return v, fmt.Errorf("querying old value for OldUserID: %w", err)
+		return v, fmt.Errorf("querying old value for OldConnectorData: %w", err)
 	}
-	return oldValue.UserID, nil
+	return oldValue.ConnectorData, nil
 }
 
-// ResetUserID resets all changes to the "user_id" field.
-func (m *PasswordMutation) ResetUserID() {
-	m.user_id = nil
+// ClearConnectorData clears the value of the "connector_data" field.
+func (m *OfflineSessionMutation) ClearConnectorData() {
+	m.connector_data = nil
+	m.clearedFields[offlinesession.FieldConnectorData] = struct{}{}
 }
 
-// Where appends a list predicates to the PasswordMutation builder.
-func (m *PasswordMutation) Where(ps ...predicate.Password) {
+// ConnectorDataCleared returns if the "connector_data" field was cleared in this mutation.
+func (m *OfflineSessionMutation) ConnectorDataCleared() bool {
+	_, ok := m.clearedFields[offlinesession.FieldConnectorData]
+	return ok
+}
+
+// ResetConnectorData resets all changes to the "connector_data" field.
+func (m *OfflineSessionMutation) ResetConnectorData() {
+	m.connector_data = nil
+	delete(m.clearedFields, offlinesession.FieldConnectorData)
+}
+
+// Where appends a list predicates to the OfflineSessionMutation builder.
+func (m *OfflineSessionMutation) Where(ps ...predicate.OfflineSession) {
 	m.predicates = append(m.predicates, ps...)
 }
 
-// WhereP appends storage-level predicates to the PasswordMutation builder. Using this method,
+// WhereP appends storage-level predicates to the OfflineSessionMutation builder. Using this method,
 // users can use type-assertion to append predicates that do not depend on any generated package.
-func (m *PasswordMutation) WhereP(ps ...func(*sql.Selector)) {
-	p := make([]predicate.Password, len(ps))
+func (m *OfflineSessionMutation) WhereP(ps ...func(*sql.Selector)) {
+	p := make([]predicate.OfflineSession, len(ps))
 	for i := range ps {
 		p[i] = ps[i]
 	}
@@ -6585,36 +8080,36 @@ func (m *PasswordMutation) WhereP(ps ...func(*sql.Selector)) {
 }
 
 // Op returns the operation name.
-func (m *PasswordMutation) Op() Op {
+func (m *OfflineSessionMutation) Op() Op {
 	return m.op
 }
 
 // SetOp allows setting the mutation operation.
-func (m *PasswordMutation) SetOp(op Op) {
+func (m *OfflineSessionMutation) SetOp(op Op) {
 	m.op = op
 }
 
-// Type returns the node type of this mutation (Password).
-func (m *PasswordMutation) Type() string {
+// Type returns the node type of this mutation (OfflineSession).
+func (m *OfflineSessionMutation) Type() string {
 	return m.typ
 }
 
 // Fields returns all fields that were changed during this mutation. Note that in
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
-func (m *PasswordMutation) Fields() []string {
+func (m *OfflineSessionMutation) Fields() []string {
 	fields := make([]string, 0, 4)
-	if m.email != nil {
-		fields = append(fields, password.FieldEmail)
+	if m.user_id != nil {
+		fields = append(fields, offlinesession.FieldUserID)
 	}
-	if m.hash != nil {
-		fields = append(fields, password.FieldHash)
+	if m.conn_id != nil {
+		fields = append(fields, offlinesession.FieldConnID)
 	}
-	if m.username != nil {
-		fields = append(fields, password.FieldUsername)
+	if m.refresh != nil {
+		fields = append(fields, offlinesession.FieldRefresh)
 	}
-	if m.user_id != nil {
-		fields = append(fields, password.FieldUserID)
+	if m.connector_data != nil {
+		fields = append(fields, offlinesession.FieldConnectorData)
 	}
 	return fields
 }
@@ -6622,16 +8117,16 @@ func (m *PasswordMutation) Fields() []string {
 // Field returns the value of a field with the given name. The second boolean
 // return value indicates that this field was not set, or was not defined in the
 // schema.
-func (m *PasswordMutation) Field(name string) (ent.Value, bool) {
+func (m *OfflineSessionMutation) Field(name string) (ent.Value, bool) {
 	switch name {
-	case password.FieldEmail:
-		return m.Email()
-	case password.FieldHash:
-		return m.Hash()
-	case password.FieldUsername:
-		return m.Username()
-	case password.FieldUserID:
+	case offlinesession.FieldUserID:
 		return m.UserID()
+	case offlinesession.FieldConnID:
+		return m.ConnID()
+	case offlinesession.FieldRefresh:
+		return m.Refresh()
+	case offlinesession.FieldConnectorData:
+		return m.ConnectorData()
 	}
 	return nil, false
 }
@@ -6639,206 +8134,206 @@ func (m *PasswordMutation) Field(name string) (ent.Value, bool) {
 // OldField returns the old value of the field from the database. An error is
 // returned if the mutation operation is not UpdateOne, or the query to the
 // database failed.
-func (m *PasswordMutation) OldField(ctx context.Context, name string) (ent.Value, error) {
+func (m *OfflineSessionMutation) OldField(ctx context.Context, name string) (ent.Value, error) {
 	switch name {
-	case password.FieldEmail:
-		return m.OldEmail(ctx)
-	case password.FieldHash:
-		return m.OldHash(ctx)
-	case password.FieldUsername:
-		return m.OldUsername(ctx)
-	case password.FieldUserID:
+	case offlinesession.FieldUserID:
 		return m.OldUserID(ctx)
+	case offlinesession.FieldConnID:
+		return m.OldConnID(ctx)
+	case offlinesession.FieldRefresh:
+		return m.OldRefresh(ctx)
+	case offlinesession.FieldConnectorData:
+		return m.OldConnectorData(ctx)
 	}
-	return nil, fmt.Errorf("unknown Password field %s", name)
+	return nil, fmt.Errorf("unknown OfflineSession field %s", name)
 }
 
 // SetField sets the value of a field with the given name. It returns an error if
 // the field is not defined in the schema, or if the type mismatched the field
 // type.
-func (m *PasswordMutation) SetField(name string, value ent.Value) error {
+func (m *OfflineSessionMutation) SetField(name string, value ent.Value) error {
 	switch name {
-	case password.FieldEmail:
+	case offlinesession.FieldUserID:
 		v, ok := value.(string)
 		if !ok {
 			return fmt.Errorf("unexpected type %T for field %s", value, name)
 		}
-		m.SetEmail(v)
-		return nil
-	case password.FieldHash:
-		v, ok := value.([]byte)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetHash(v)
+		m.SetUserID(v)
 		return nil
-	case password.FieldUsername:
+	case offlinesession.FieldConnID:
 		v, ok := value.(string)
 		if !ok {
 			return fmt.Errorf("unexpected type %T for field %s", value, name)
 		}
-		m.SetUsername(v)
+		m.SetConnID(v)
 		return nil
-	case password.FieldUserID:
-		v, ok := value.(string)
+	case offlinesession.FieldRefresh:
+		v, ok := value.([]byte)
 		if !ok {
 			return fmt.Errorf("unexpected type %T for field %s", value, name)
 		}
-		m.SetUserID(v)
+		m.SetRefresh(v)
+		return nil
+	case offlinesession.FieldConnectorData:
+		v, ok := value.([]byte)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetConnectorData(v)
 		return nil
 	}
-	return fmt.Errorf("unknown Password field %s", name)
+	return fmt.Errorf("unknown OfflineSession field %s", name)
 }
 
 // AddedFields returns all numeric fields that were incremented/decremented during
 // this mutation.
-func (m *PasswordMutation) AddedFields() []string {
+func (m *OfflineSessionMutation) AddedFields() []string {
 	return nil
 }
 
 // AddedField returns the numeric value that was incremented/decremented on a field
 // with the given name. The second boolean return value indicates that this field
 // was not set, or was not defined in the schema.
-func (m *PasswordMutation) AddedField(name string) (ent.Value, bool) {
+func (m *OfflineSessionMutation) AddedField(name string) (ent.Value, bool) {
 	return nil, false
 }
 
 // AddField adds the value to the field with the given name. It returns an error if
 // the field is not defined in the schema, or if the type mismatched the field
 // type.
-func (m *PasswordMutation) AddField(name string, value ent.Value) error {
+func (m *OfflineSessionMutation) AddField(name string, value ent.Value) error {
 	switch name {
 	}
-	return fmt.Errorf("unknown Password numeric field %s", name)
+	return fmt.Errorf("unknown OfflineSession numeric field %s", name)
 }
 
 // ClearedFields returns all nullable fields that were cleared during this
 // mutation.
-func (m *PasswordMutation) ClearedFields() []string {
-	return nil
+func (m *OfflineSessionMutation) ClearedFields() []string {
+	var fields []string
+	if m.FieldCleared(offlinesession.FieldConnectorData) {
+		fields = append(fields, offlinesession.FieldConnectorData)
+	}
+	return fields
 }
 
 // FieldCleared returns a boolean indicating if a field with the given name was
 // cleared in this mutation.
-func (m *PasswordMutation) FieldCleared(name string) bool {
+func (m *OfflineSessionMutation) FieldCleared(name string) bool {
 	_, ok := m.clearedFields[name]
 	return ok
 }
 
 // ClearField clears the value of the field with the given name. It returns an
 // error if the field is not defined in the schema.
-func (m *PasswordMutation) ClearField(name string) error {
-	return fmt.Errorf("unknown Password nullable field %s", name)
+func (m *OfflineSessionMutation) ClearField(name string) error {
+	switch name {
+	case offlinesession.FieldConnectorData:
+		m.ClearConnectorData()
+		return nil
+	}
+	return fmt.Errorf("unknown OfflineSession nullable field %s", name)
 }
 
 // ResetField resets all changes in the mutation for the field with the given name.
 // It returns an error if the field is not defined in the schema.
-func (m *PasswordMutation) ResetField(name string) error {
+func (m *OfflineSessionMutation) ResetField(name string) error {
 	switch name {
-	case password.FieldEmail:
-		m.ResetEmail()
+	case offlinesession.FieldUserID:
+		m.ResetUserID()
 		return nil
-	case password.FieldHash:
-		m.ResetHash()
+	case offlinesession.FieldConnID:
+		m.ResetConnID()
 		return nil
-	case password.FieldUsername:
-		m.ResetUsername()
+	case offlinesession.FieldRefresh:
+		m.ResetRefresh()
 		return nil
-	case password.FieldUserID:
-		m.ResetUserID()
+	case offlinesession.FieldConnectorData:
+		m.ResetConnectorData()
 		return nil
 	}
-	return fmt.Errorf("unknown Password field %s", name)
+	return fmt.Errorf("unknown OfflineSession field %s", name)
 }
 
 // AddedEdges returns all edge names that were set/added in this mutation.
-func (m *PasswordMutation) AddedEdges() []string {
+func (m *OfflineSessionMutation) AddedEdges() []string {
 	edges := make([]string, 0, 0)
 	return edges
 }
 
 // AddedIDs returns all IDs (to other nodes) that were added for the given edge
 // name in this mutation.
-func (m *PasswordMutation) AddedIDs(name string) []ent.Value {
+func (m *OfflineSessionMutation) AddedIDs(name string) []ent.Value {
 	return nil
 }
 
 // RemovedEdges returns all edge names that were removed in this mutation.
-func (m *PasswordMutation) RemovedEdges() []string {
+func (m *OfflineSessionMutation) RemovedEdges() []string {
 	edges := make([]string, 0, 0)
 	return edges
 }
 
 // RemovedIDs returns all IDs (to other nodes) that were removed for the edge with
 // the given name in this mutation.
-func (m *PasswordMutation) RemovedIDs(name string) []ent.Value {
+func (m *OfflineSessionMutation) RemovedIDs(name string) []ent.Value {
 	return nil
 }
 
 // ClearedEdges returns all edge names that were cleared in this mutation.
-func (m *PasswordMutation) ClearedEdges() []string {
+func (m *OfflineSessionMutation) ClearedEdges() []string {
 	edges := make([]string, 0, 0)
 	return edges
 }
 
 // EdgeCleared returns a boolean which indicates if the edge with the given name
 // was cleared in this mutation.
-func (m *PasswordMutation) EdgeCleared(name string) bool {
+func (m *OfflineSessionMutation) EdgeCleared(name string) bool {
 	return false
 }
 
 // ClearEdge clears the value of the edge with the given name. It returns an error
 // if that edge is not defined in the schema.
-func (m *PasswordMutation) ClearEdge(name string) error {
-	return fmt.Errorf("unknown Password unique edge %s", name)
+func (m *OfflineSessionMutation) ClearEdge(name string) error {
+	return fmt.Errorf("unknown OfflineSession unique edge %s", name)
 }
 
 // ResetEdge resets all changes to the edge with the given name in this mutation.
 // It returns an error if the edge is not defined in the schema.
-func (m *PasswordMutation) ResetEdge(name string) error {
-	return fmt.Errorf("unknown Password edge %s", name)
+func (m *OfflineSessionMutation) ResetEdge(name string) error {
+	return fmt.Errorf("unknown OfflineSession edge %s", name)
 }
 
-// RefreshTokenMutation represents an operation that mutates the RefreshToken nodes in the graph.
-type RefreshTokenMutation struct {
+// PasswordMutation represents an operation that mutates the Password nodes in the graph.
+type PasswordMutation struct {
 	config
-	op                        Op
-	typ                       string
-	id                        *string
-	client_id                 *string
-	scopes                    *[]string
-	appendscopes              []string
-	nonce                     *string
-	claims_user_id            *string
-	claims_username           *string
-	claims_email              *string
-	claims_email_verified     *bool
-	claims_groups             *[]string
-	appendclaims_groups       []string
-	claims_preferred_username *string
-	connector_id              *string
-	connector_data            *[]byte
-	token                     *string
-	obsolete_token            *string
-	created_at                *time.Time
-	last_used                 *time.Time
-	clearedFields             map[string]struct{}
-	done                      bool
-	oldValue                  func(context.Context) (*RefreshToken, error)
-	predicates                []predicate.RefreshToken
+	op                 Op
+	typ                string
+	id                 *int
+	email              *string
+	hash               *[]byte
+	username           *string
+	user_id            *string
+	email_verified     *bool
+	failed_attempts    *int
+	addfailed_attempts *int
+	locked_until       *time.Time
+	clearedFields      map[string]struct{}
+	done               bool
+	oldValue           func(context.Context) (*Password, error)
+	predicates         []predicate.Password
 }
 
-var _ ent.Mutation = (*RefreshTokenMutation)(nil)
+var _ ent.Mutation = (*PasswordMutation)(nil)
 
-// refreshtokenOption allows management of the mutation configuration using functional options.
-type refreshtokenOption func(*RefreshTokenMutation)
+// passwordOption allows management of the mutation configuration using functional options.
+type passwordOption func(*PasswordMutation)
 
-// newRefreshTokenMutation creates new mutation for the RefreshToken entity.
-func newRefreshTokenMutation(c config, op Op, opts ...refreshtokenOption) *RefreshTokenMutation {
-	m := &RefreshTokenMutation{
+// newPasswordMutation creates new mutation for the Password entity.
+func newPasswordMutation(c config, op Op, opts ...passwordOption) *PasswordMutation {
+	m := &PasswordMutation{
 		config:        c,
 		op:            op,
-		typ:           TypeRefreshToken,
+		typ:           TypePassword,
 		clearedFields: make(map[string]struct{}),
 	}
 	for _, opt := range opts {
@@ -6847,20 +8342,20 @@ func newRefreshTokenMutation(c config, op Op, opts ...refreshtokenOption) *Refre
 	return m
 }
 
-// withRefreshTokenID sets the ID field of the mutation.
-func withRefreshTokenID(id string) refreshtokenOption {
-	return func(m *RefreshTokenMutation) {
+// withPasswordID sets the ID field of the mutation.
+func withPasswordID(id int) passwordOption {
+	return func(m *PasswordMutation) {
 		var (
 			err   error
 			once  sync.Once
-			value *RefreshToken
+			value *Password
 		)
-		m.oldValue = func(ctx context.Context) (*RefreshToken, error) {
+		m.oldValue = func(ctx context.Context) (*Password, error) {
 			once.Do(func() {
 				if m.done {
 					err = errors.New("querying old values post mutation is not allowed")
 				} else {
-					value, err = m.Client().RefreshToken.Get(ctx, id)
+					value, err = m.Client().Password.Get(ctx, id)
 				}
 			})
 			return value, err
@@ -6869,10 +8364,10 @@ func withRefreshTokenID(id string) refreshtokenOption {
 	}
 }
 
-// withRefreshToken sets the old RefreshToken of the mutation.
-func withRefreshToken(node *RefreshToken) refreshtokenOption {
-	return func(m *RefreshTokenMutation) {
-		m.oldValue = func(context.Context) (*RefreshToken, error) {
+// withPassword sets the old Password of the mutation.
+func withPassword(node *Password) passwordOption {
+	return func(m *PasswordMutation) {
+		m.oldValue = func(context.Context) (*Password, error) {
 			return node, nil
 		}
 		m.id = &node.ID
@@ -6881,7 +8376,7 @@ func withRefreshToken(node *RefreshToken) refreshtokenOption {
 
 // Client returns a new `ent.Client` from the mutation. If the mutation was
 // executed in a transaction (ent.Tx), a transactional client is returned.
-func (m RefreshTokenMutation) Client() *Client {
+func (m PasswordMutation) Client() *Client {
 	client := &Client{config: m.config}
 	client.init()
 	return client
@@ -6889,7 +8384,7 @@ func (m RefreshTokenMutation) Client() *Client {
 
 // Tx returns an `ent.Tx` for mutations that were executed in transactions;
 // it returns an error otherwise.
-func (m RefreshTokenMutation) Tx() (*Tx, error) {
+func (m PasswordMutation) Tx() (*Tx, error) {
 	if _, ok := m.driver.(*txDriver); !ok {
 		return nil, errors.New("db: mutation is not running in a transaction")
 	}
@@ -6898,15 +8393,9 @@ func (m RefreshTokenMutation) Tx() (*Tx, error) {
 	return tx, nil
 }
 
-// SetID sets the value of the id field. Note that this
-// operation is only accepted on creation of RefreshToken entities.
-func (m *RefreshTokenMutation) SetID(id string) {
-	m.id = &id
-}
-
 // ID returns the ID value in the mutation. Note that the ID is only available
 // if it was provided to the builder or after it was returned from the database.
-func (m *RefreshTokenMutation) ID() (id string, exists bool) {
+func (m *PasswordMutation) ID() (id int, exists bool) {
 	if m.id == nil {
 		return
 	}
@@ -6917,567 +8406,2381 @@ func (m *RefreshTokenMutation) ID() (id string, exists bool) {
 // That means, if the mutation is applied within a transaction with an isolation level such
 // as sql.LevelSerializable, the returned ids match the ids of the rows that will be updated
 // or updated by the mutation.
-func (m *RefreshTokenMutation) IDs(ctx context.Context) ([]string, error) {
+func (m *PasswordMutation) IDs(ctx context.Context) ([]int, error) {
 	switch {
 	case m.op.Is(OpUpdateOne | OpDeleteOne):
 		id, exists := m.ID()
 		if exists {
-			return []string{id}, nil
+			return []int{id}, nil
 		}
 		fallthrough
 	case m.op.Is(OpUpdate | OpDelete):
-		return m.Client().RefreshToken.Query().Where(m.predicates...).IDs(ctx)
+		return m.Client().Password.Query().Where(m.predicates...).IDs(ctx)
 	default:
 		return nil, fmt.Errorf("IDs is not allowed on %s operations", m.op)
 	}
 }
 
-// SetClientID sets the "client_id" field.
-func (m *RefreshTokenMutation) SetClientID(s string) {
-	m.client_id = &s
+// SetEmail sets the "email" field.
+func (m *PasswordMutation) SetEmail(s string) {
+	m.email = &s
 }
 
-// ClientID returns the value of the "client_id" field in the mutation.
-func (m *RefreshTokenMutation) ClientID() (r string, exists bool) {
-	v := m.client_id
+// Email returns the value of the "email" field in the mutation.
+func (m *PasswordMutation) Email() (r string, exists bool) {
+	v := m.email
 	if v == nil {
 		return
 	}
 	return *v, true
 }
 
-// OldClientID returns the old "client_id" field's value of the RefreshToken entity.
-// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
+// OldEmail returns the old "email" field's value of the Password entity.
+// If the Password object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *RefreshTokenMutation) OldClientID(ctx context.Context) (v string, err error) {
+func (m *PasswordMutation) OldEmail(ctx context.Context) (v string, err error) {
 	if !m.op.Is(OpUpdateOne) {
-		return v, errors.New("OldClientID is only allowed on UpdateOne operations")
+		return v, errors.New("OldEmail is only allowed on UpdateOne operations")
 	}
 	if m.id == nil || m.oldValue == nil {
-		return v, errors.New("OldClientID requires an ID field in the mutation")
+		return v, errors.New("OldEmail requires an ID field in the mutation")
 	}
 	oldValue, err := m.oldValue(ctx)
 	if err != nil {
-		return v, fmt.Errorf("querying old value for OldClientID: %w", err)
+		return v, fmt.Errorf("querying old value for OldEmail: %w", err)
 	}
-	return oldValue.ClientID, nil
+	return oldValue.Email, nil
 }
 
-// ResetClientID resets all changes to the "client_id" field.
-func (m *RefreshTokenMutation) ResetClientID() {
-	m.client_id = nil
+// ResetEmail resets all changes to the "email" field.
+func (m *PasswordMutation) ResetEmail() {
+	m.email = nil
 }
 
-// SetScopes sets the "scopes" field.
-func (m *RefreshTokenMutation) SetScopes(s []string) {
-	m.scopes = &s
-	m.appendscopes = nil
+// SetHash sets the "hash" field.
+func (m *PasswordMutation) SetHash(b []byte) {
+	m.hash = &b
 }
 
-// Scopes returns the value of the "scopes" field in the mutation.
-func (m *RefreshTokenMutation) Scopes() (r []string, exists bool) {
-	v := m.scopes
+// Hash returns the value of the "hash" field in the mutation.
+func (m *PasswordMutation) Hash() (r []byte, exists bool) {
+	v := m.hash
 	if v == nil {
 		return
 	}
 	return *v, true
 }
 
-// OldScopes returns the old "scopes" field's value of the RefreshToken entity.
-// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
+// OldHash returns the old "hash" field's value of the Password entity.
+// If the Password object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *RefreshTokenMutation) OldScopes(ctx context.Context) (v []string, err error) {
+func (m *PasswordMutation) OldHash(ctx context.Context) (v []byte, err error) {
 	if !m.op.Is(OpUpdateOne) {
-		return v, errors.New("OldScopes is only allowed on UpdateOne operations")
+		return v, errors.New("OldHash is only allowed on UpdateOne operations")
 	}
 	if m.id == nil || m.oldValue == nil {
-		return v, errors.New("OldScopes requires an ID field in the mutation")
+		return v, errors.New("OldHash requires an ID field in the mutation")
 	}
 	oldValue, err := m.oldValue(ctx)
 	if err != nil {
-		return v, fmt.Errorf("querying old value for OldScopes: %w", err)
+		return v, fmt.Errorf("querying old value for OldHash: %w", err)
 	}
-	return oldValue.Scopes, nil
-}
-
-// AppendScopes adds s to the "scopes" field.
-func (m *RefreshTokenMutation) AppendScopes(s []string) {
-	m.appendscopes = append(m.appendscopes, s...)
+	return oldValue.Hash, nil
 }
 
-// AppendedScopes returns the list of values that were appended to the "scopes" field in this mutation.
-func (m *RefreshTokenMutation) AppendedScopes() ([]string, bool) {
-	if len(m.appendscopes) == 0 {
-		return nil, false
-	}
-	return m.appendscopes, true
+// ResetHash resets all changes to the "hash" field.
+func (m *PasswordMutation) ResetHash() {
+	m.hash = nil
 }
 
-// ClearScopes clears the value of the "scopes" field.
-func (m *RefreshTokenMutation) ClearScopes() {
-	m.scopes = nil
-	m.appendscopes = nil
-	m.clearedFields[refreshtoken.FieldScopes] = struct{}{}
+// SetUsername sets the "username" field.
+func (m *PasswordMutation) SetUsername(s string) {
+	m.username = &s
 }
 
-// ScopesCleared returns if the "scopes" field was cleared in this mutation.
-func (m *RefreshTokenMutation) ScopesCleared() bool {
-	_, ok := m.clearedFields[refreshtoken.FieldScopes]
-	return ok
+// Username returns the value of the "username" field in the mutation.
+func (m *PasswordMutation) Username() (r string, exists bool) {
+	v := m.username
+	if v == nil {
+		return
+	}
+	return *v, true
 }
 
-// ResetScopes resets all changes to the "scopes" field.
-func (m *RefreshTokenMutation) ResetScopes() {
-	m.scopes = nil
-	m.appendscopes = nil
-	delete(m.clearedFields, refreshtoken.FieldScopes)
-}
-
-// SetNonce sets the "nonce" field.
-func (m *RefreshTokenMutation) SetNonce(s string) {
-	m.nonce = &s
-}
-
-// Nonce returns the value of the "nonce" field in the mutation.
-func (m *RefreshTokenMutation) Nonce() (r string, exists bool) {
-	v := m.nonce
-	if v == nil {
-		return
-	}
-	return *v, true
-}
-
-// OldNonce returns the old "nonce" field's value of the RefreshToken entity.
-// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
+// OldUsername returns the old "username" field's value of the Password entity.
+// If the Password object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *RefreshTokenMutation) OldNonce(ctx context.Context) (v string, err error) {
+func (m *PasswordMutation) OldUsername(ctx context.Context) (v string, err error) {
 	if !m.op.Is(OpUpdateOne) {
-		return v, errors.New("OldNonce is only allowed on UpdateOne operations")
+		return v, errors.New("OldUsername is only allowed on UpdateOne operations")
 	}
 	if m.id == nil || m.oldValue == nil {
-		return v, errors.New("OldNonce requires an ID field in the mutation")
+		return v, errors.New("OldUsername requires an ID field in the mutation")
 	}
 	oldValue, err := m.oldValue(ctx)
 	if err != nil {
-		return v, fmt.Errorf("querying old value for OldNonce: %w", err)
+		return v, fmt.Errorf("querying old value for OldUsername: %w", err)
 	}
-	return oldValue.Nonce, nil
+	return oldValue.Username, nil
 }
 
-// ResetNonce resets all changes to the "nonce" field.
-func (m *RefreshTokenMutation) ResetNonce() {
-	m.nonce = nil
+// ResetUsername resets all changes to the "username" field.
+func (m *PasswordMutation) ResetUsername() {
+	m.username = nil
 }
 
-// SetClaimsUserID sets the "claims_user_id" field.
-func (m *RefreshTokenMutation) SetClaimsUserID(s string) {
-	m.claims_user_id = &s
+// SetUserID sets the "user_id" field.
+func (m *PasswordMutation) SetUserID(s string) {
+	m.user_id = &s
 }
 
-// ClaimsUserID returns the value of the "claims_user_id" field in the mutation.
-func (m *RefreshTokenMutation) ClaimsUserID() (r string, exists bool) {
-	v := m.claims_user_id
+// UserID returns the value of the "user_id" field in the mutation.
+func (m *PasswordMutation) UserID() (r string, exists bool) {
+	v := m.user_id
 	if v == nil {
 		return
 	}
 	return *v, true
 }
 
-// OldClaimsUserID returns the old "claims_user_id" field's value of the RefreshToken entity.
-// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
+// OldUserID returns the old "user_id" field's value of the Password entity.
+// If the Password object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *RefreshTokenMutation) OldClaimsUserID(ctx context.Context) (v string, err error) {
+func (m *PasswordMutation) OldUserID(ctx context.Context) (v string, err error) {
 	if !m.op.Is(OpUpdateOne) {
-		return v, errors.New("OldClaimsUserID is only allowed on UpdateOne operations")
+		return v, errors.New("OldUserID is only allowed on UpdateOne operations")
 	}
 	if m.id == nil || m.oldValue == nil {
-		return v, errors.New("OldClaimsUserID requires an ID field in the mutation")
+		return v, errors.New("OldUserID requires an ID field in the mutation")
 	}
 	oldValue, err := m.oldValue(ctx)
 	if err != nil {
-		return v, fmt.Errorf("querying old value for OldClaimsUserID: %w", err)
+		return v, fmt.Errorf("querying old value for OldUserID: %w", err)
 	}
-	return oldValue.ClaimsUserID, nil
+	return oldValue.UserID, nil
 }
 
-// ResetClaimsUserID resets all changes to the "claims_user_id" field.
-func (m *RefreshTokenMutation) ResetClaimsUserID() {
-	m.claims_user_id = nil
+// ResetUserID resets all changes to the "user_id" field.
+func (m *PasswordMutation) ResetUserID() {
+	m.user_id = nil
 }
 
-// SetClaimsUsername sets the "claims_username" field.
-func (m *RefreshTokenMutation) SetClaimsUsername(s string) {
-	m.claims_username = &s
+// SetEmailVerified sets the "email_verified" field.
+func (m *PasswordMutation) SetEmailVerified(b bool) {
+	m.email_verified = &b
 }
 
-// ClaimsUsername returns the value of the "claims_username" field in the mutation.
-func (m *RefreshTokenMutation) ClaimsUsername() (r string, exists bool) {
-	v := m.claims_username
+// EmailVerified returns the value of the "email_verified" field in the mutation.
+func (m *PasswordMutation) EmailVerified() (r bool, exists bool) {
+	v := m.email_verified
 	if v == nil {
 		return
 	}
 	return *v, true
 }
 
-// OldClaimsUsername returns the old "claims_username" field's value of the RefreshToken entity.
-// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
+// OldEmailVerified returns the old "email_verified" field's value of the Password entity.
+// If the Password object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *RefreshTokenMutation) OldClaimsUsername(ctx context.Context) (v string, err error) {
+func (m *PasswordMutation) OldEmailVerified(ctx context.Context) (v bool, err error) {
 	if !m.op.Is(OpUpdateOne) {
-		return v, errors.New("OldClaimsUsername is only allowed on UpdateOne operations")
+		return v, errors.New("OldEmailVerified is only allowed on UpdateOne operations")
 	}
 	if m.id == nil || m.oldValue == nil {
-		return v, errors.New("OldClaimsUsername requires an ID field in the mutation")
+		return v, errors.New("OldEmailVerified requires an ID field in the mutation")
 	}
 	oldValue, err := m.oldValue(ctx)
 	if err != nil {
-		return v, fmt.Errorf("querying old value for OldClaimsUsername: %w", err)
+		return v, fmt.Errorf("querying old value for OldEmailVerified: %w", err)
 	}
-	return oldValue.ClaimsUsername, nil
+	return oldValue.EmailVerified, nil
 }
 
-// ResetClaimsUsername resets all changes to the "claims_username" field.
-func (m *RefreshTokenMutation) ResetClaimsUsername() {
-	m.claims_username = nil
+// ResetEmailVerified resets all changes to the "email_verified" field.
+func (m *PasswordMutation) ResetEmailVerified() {
+	m.email_verified = nil
 }
 
-// SetClaimsEmail sets the "claims_email" field.
-func (m *RefreshTokenMutation) SetClaimsEmail(s string) {
-	m.claims_email = &s
+// SetFailedAttempts sets the "failed_attempts" field.
+func (m *PasswordMutation) SetFailedAttempts(i int) {
+	m.failed_attempts = &i
+	m.addfailed_attempts = nil
 }
 
-// ClaimsEmail returns the value of the "claims_email" field in the mutation.
-func (m *RefreshTokenMutation) ClaimsEmail() (r string, exists bool) {
-	v := m.claims_email
+// FailedAttempts returns the value of the "failed_attempts" field in the mutation.
+func (m *PasswordMutation) FailedAttempts() (r int, exists bool) {
+	v := m.failed_attempts
 	if v == nil {
 		return
 	}
 	return *v, true
 }
 
-// OldClaimsEmail returns the old "claims_email" field's value of the RefreshToken entity.
-// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
+// OldFailedAttempts returns the old "failed_attempts" field's value of the Password entity.
+// If the Password object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *RefreshTokenMutation) OldClaimsEmail(ctx context.Context) (v string, err error) {
+func (m *PasswordMutation) OldFailedAttempts(ctx context.Context) (v int, err error) {
 	if !m.op.Is(OpUpdateOne) {
-		return v, errors.New("OldClaimsEmail is only allowed on UpdateOne operations")
+		return v, errors.New("OldFailedAttempts is only allowed on UpdateOne operations")
 	}
 	if m.id == nil || m.oldValue == nil {
-		return v, errors.New("OldClaimsEmail requires an ID field in the mutation")
+		return v, errors.New("OldFailedAttempts requires an ID field in the mutation")
 	}
 	oldValue, err := m.oldValue(ctx)
 	if err != nil {
-		return v, fmt.Errorf("querying old value for OldClaimsEmail: %w", err)
+		return v, fmt.Errorf("querying old value for OldFailedAttempts: %w", err)
 	}
-	return oldValue.ClaimsEmail, nil
+	return oldValue.FailedAttempts, nil
 }
 
-// ResetClaimsEmail resets all changes to the "claims_email" field.
-func (m *RefreshTokenMutation) ResetClaimsEmail() {
-	m.claims_email = nil
+// AddFailedAttempts adds i to the "failed_attempts" field.
+func (m *PasswordMutation) AddFailedAttempts(i int) {
+	if m.addfailed_attempts != nil {
+		*m.addfailed_attempts += i
+	} else {
+		m.addfailed_attempts = &i
+	}
 }
 
-// SetClaimsEmailVerified sets the "claims_email_verified" field.
-func (m *RefreshTokenMutation) SetClaimsEmailVerified(b bool) {
-	m.claims_email_verified = &b
+// AddedFailedAttempts returns the value that was added to the "failed_attempts" field in this mutation.
+func (m *PasswordMutation) AddedFailedAttempts() (r int, exists bool) {
+	v := m.addfailed_attempts
+	if v == nil {
+		return
+	}
+	return *v, true
 }
 
-// ClaimsEmailVerified returns the value of the "claims_email_verified" field in the mutation.
-func (m *RefreshTokenMutation) ClaimsEmailVerified() (r bool, exists bool) {
-	v := m.claims_email_verified
+// ResetFailedAttempts resets all changes to the "failed_attempts" field.
+func (m *PasswordMutation) ResetFailedAttempts() {
+	m.failed_attempts = nil
+	m.addfailed_attempts = nil
+}
+
+// SetLockedUntil sets the "locked_until" field.
+func (m *PasswordMutation) SetLockedUntil(t time.Time) {
+	m.locked_until = &t
+}
+
+// LockedUntil returns the value of the "locked_until" field in the mutation.
+func (m *PasswordMutation) LockedUntil() (r time.Time, exists bool) {
+	v := m.locked_until
 	if v == nil {
 		return
 	}
 	return *v, true
 }
 
-// OldClaimsEmailVerified returns the old "claims_email_verified" field's value of the RefreshToken entity.
-// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
+// OldLockedUntil returns the old "locked_until" field's value of the Password entity.
+// If the Password object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *RefreshTokenMutation) OldClaimsEmailVerified(ctx context.Context) (v bool, err error) {
+func (m *PasswordMutation) OldLockedUntil(ctx context.Context) (v time.Time, err error) {
 	if !m.op.Is(OpUpdateOne) {
-		return v, errors.New("OldClaimsEmailVerified is only allowed on UpdateOne operations")
+		return v, errors.New("OldLockedUntil is only allowed on UpdateOne operations")
 	}
 	if m.id == nil || m.oldValue == nil {
-		return v, errors.New("OldClaimsEmailVerified requires an ID field in the mutation")
+		return v, errors.New("OldLockedUntil requires an ID field in the mutation")
 	}
 	oldValue, err := m.oldValue(ctx)
 	if err != nil {
-		return v, fmt.Errorf("querying old value for OldClaimsEmailVerified: %w", err)
+		return v, fmt.Errorf("querying old value for OldLockedUntil: %w", err)
 	}
-	return oldValue.ClaimsEmailVerified, nil
+	return oldValue.LockedUntil, nil
 }
 
-// ResetClaimsEmailVerified resets all changes to the "claims_email_verified" field.
-func (m *RefreshTokenMutation) ResetClaimsEmailVerified() {
-	m.claims_email_verified = nil
+// ClearLockedUntil clears the value of the "locked_until" field.
+func (m *PasswordMutation) ClearLockedUntil() {
+	m.locked_until = nil
+	m.clearedFields[password.FieldLockedUntil] = struct{}{}
 }
 
-// SetClaimsGroups sets the "claims_groups" field.
-func (m *RefreshTokenMutation) SetClaimsGroups(s []string) {
-	m.claims_groups = &s
-	m.appendclaims_groups = nil
+// LockedUntilCleared returns if the "locked_until" field was cleared in this mutation.
+func (m *PasswordMutation) LockedUntilCleared() bool {
+	_, ok := m.clearedFields[password.FieldLockedUntil]
+	return ok
 }
 
-// ClaimsGroups returns the value of the "claims_groups" field in the mutation.
-func (m *RefreshTokenMutation) ClaimsGroups() (r []string, exists bool) {
-	v := m.claims_groups
-	if v == nil {
-		return
-	}
-	return *v, true
+// ResetLockedUntil resets all changes to the "locked_until" field.
+func (m *PasswordMutation) ResetLockedUntil() {
+	m.locked_until = nil
+	delete(m.clearedFields, password.FieldLockedUntil)
 }
 
-// OldClaimsGroups returns the old "claims_groups" field's value of the RefreshToken entity.
-// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
-// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *RefreshTokenMutation) OldClaimsGroups(ctx context.Context) (v []string, err error) {
-	if !m.op.Is(OpUpdateOne) {
-		return v, errors.New("OldClaimsGroups is only allowed on UpdateOne operations")
-	}
-	if m.id == nil || m.oldValue == nil {
-		return v, errors.New("OldClaimsGroups requires an ID field in the mutation")
-	}
-	oldValue, err := m.oldValue(ctx)
-	if err != nil {
-		return v, fmt.Errorf("querying old value for OldClaimsGroups: %w", err)
+// Where appends a list predicates to the PasswordMutation builder.
+func (m *PasswordMutation) Where(ps ...predicate.Password) {
+	m.predicates = append(m.predicates, ps...)
+}
+
+// WhereP appends storage-level predicates to the PasswordMutation builder. Using this method,
+// users can use type-assertion to append predicates that do not depend on any generated package.
+func (m *PasswordMutation) WhereP(ps ...func(*sql.Selector)) {
+	p := make([]predicate.Password, len(ps))
+	for i := range ps {
+		p[i] = ps[i]
 	}
-	return oldValue.ClaimsGroups, nil
+	m.Where(p...)
 }
 
-// AppendClaimsGroups adds s to the "claims_groups" field.
-func (m *RefreshTokenMutation) AppendClaimsGroups(s []string) {
-	m.appendclaims_groups = append(m.appendclaims_groups, s...)
+// Op returns the operation name.
+func (m *PasswordMutation) Op() Op {
+	return m.op
 }
 
-// AppendedClaimsGroups returns the list of values that were appended to the "claims_groups" field in this mutation.
-func (m *RefreshTokenMutation) AppendedClaimsGroups() ([]string, bool) {
-	if len(m.appendclaims_groups) == 0 {
-		return nil, false
+// SetOp allows setting the mutation operation.
+func (m *PasswordMutation) SetOp(op Op) {
+	m.op = op
+}
+
+// Type returns the node type of this mutation (Password).
+func (m *PasswordMutation) Type() string {
+	return m.typ
+}
+
+// Fields returns all fields that were changed during this mutation. Note that in
+// order to get all numeric fields that were incremented/decremented, call
+// AddedFields().
+func (m *PasswordMutation) Fields() []string {
+	fields := make([]string, 0, 5)
+	if m.email != nil {
+		fields = append(fields, password.FieldEmail)
 	}
-	return m.appendclaims_groups, true
+	if m.hash != nil {
+		fields = append(fields, password.FieldHash)
+	}
+	if m.username != nil {
+		fields = append(fields, password.FieldUsername)
+	}
+	if m.user_id != nil {
+		fields = append(fields, password.FieldUserID)
+	}
+	if m.email_verified != nil {
+		fields = append(fields, password.FieldEmailVerified)
+	}
+	if m.failed_attempts != nil {
+		fields = append(fields, password.FieldFailedAttempts)
+	}
+	if m.locked_until != nil {
+		fields = append(fields, password.FieldLockedUntil)
+	}
+	return fields
 }
 
-// ClearClaimsGroups clears the value of the "claims_groups" field.
-func (m *RefreshTokenMutation) ClearClaimsGroups() {
-	m.claims_groups = nil
-	m.appendclaims_groups = nil
-	m.clearedFields[refreshtoken.FieldClaimsGroups] = struct{}{}
+// Field returns the value of a field with the given name. The second boolean
+// return value indicates that this field was not set, or was not defined in the
+// schema.
+func (m *PasswordMutation) Field(name string) (ent.Value, bool) {
+	switch name {
+	case password.FieldEmail:
+		return m.Email()
+	case password.FieldHash:
+		return m.Hash()
+	case password.FieldUsername:
+		return m.Username()
+	case password.FieldUserID:
+		return m.UserID()
+	case password.FieldEmailVerified:
+		return m.EmailVerified()
+	case password.FieldFailedAttempts:
+		return m.FailedAttempts()
+	case password.FieldLockedUntil:
+		return m.LockedUntil()
+	}
+	return nil, false
 }
 
-// ClaimsGroupsCleared returns if the "claims_groups" field was cleared in this mutation.
-func (m *RefreshTokenMutation) ClaimsGroupsCleared() bool {
-	_, ok := m.clearedFields[refreshtoken.FieldClaimsGroups]
-	return ok
+// OldField returns the old value of the field from the database. An error is
+// returned if the mutation operation is not UpdateOne, or the query to the
+// database failed.
+func (m *PasswordMutation) OldField(ctx context.Context, name string) (ent.Value, error) {
+	switch name {
+	case password.FieldEmail:
+		return m.OldEmail(ctx)
+	case password.FieldHash:
+		return m.OldHash(ctx)
+	case password.FieldUsername:
+		return m.OldUsername(ctx)
+	case password.FieldUserID:
+		return m.OldUserID(ctx)
+	case password.FieldEmailVerified:
+		return m.OldEmailVerified(ctx)
+	case password.FieldFailedAttempts:
+		return m.OldFailedAttempts(ctx)
+	case password.FieldLockedUntil:
+		return m.OldLockedUntil(ctx)
+	}
+	return nil, fmt.Errorf("unknown Password field %s", name)
 }
 
-// ResetClaimsGroups resets all changes to the "claims_groups" field.
-func (m *RefreshTokenMutation) ResetClaimsGroups() {
-	m.claims_groups = nil
-	m.appendclaims_groups = nil
-	delete(m.clearedFields, refreshtoken.FieldClaimsGroups)
+// SetField sets the value of a field with the given name. It returns an error if
+// the field is not defined in the schema, or if the type mismatched the field
+// type.
+func (m *PasswordMutation) SetField(name string, value ent.Value) error {
+	switch name {
+	case password.FieldEmail:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetEmail(v)
+		return nil
+	case password.FieldHash:
+		v, ok := value.([]byte)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetHash(v)
+		return nil
+	case password.FieldUsername:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetUsername(v)
+		return nil
+	case password.FieldUserID:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetUserID(v)
+		return nil
+	case password.FieldEmailVerified:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetEmailVerified(v)
+		return nil
+	case password.FieldFailedAttempts:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetFailedAttempts(v)
+		return nil
+	case password.FieldLockedUntil:
+		v, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetLockedUntil(v)
+		return nil
+	}
+	return fmt.Errorf("unknown Password field %s", name)
+}
+
+// AddedFields returns all numeric fields that were incremented/decremented during
+// this mutation.
+func (m *PasswordMutation) AddedFields() []string {
+	var fields []string
+	if m.addfailed_attempts != nil {
+		fields = append(fields, password.FieldFailedAttempts)
+	}
+	return fields
+}
+
+// AddedField returns the numeric value that was incremented/decremented on a field
+// with the given name. The second boolean return value indicates that this field
+// was not set, or was not defined in the schema.
+func (m *PasswordMutation) AddedField(name string) (ent.Value, bool) {
+	switch name {
+	case password.FieldFailedAttempts:
+		return m.AddedFailedAttempts()
+	}
+	return nil, false
+}
+
+// AddField adds the value to the field with the given name. It returns an error if
+// the field is not defined in the schema, or if the type mismatched the field
+// type.
+func (m *PasswordMutation) AddField(name string, value ent.Value) error {
+	switch name {
+	case password.FieldFailedAttempts:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddFailedAttempts(v)
+		return nil
+	}
+	return fmt.Errorf("unknown Password numeric field %s", name)
+}
+
+// ClearedFields returns all nullable fields that were cleared during this
+// mutation.
+func (m *PasswordMutation) ClearedFields() []string {
+	var fields []string
+	if m.FieldCleared(password.FieldLockedUntil) {
+		fields = append(fields, password.FieldLockedUntil)
+	}
+	return fields
+}
+
+// FieldCleared returns a boolean indicating if a field with the given name was
+// cleared in this mutation.
+func (m *PasswordMutation) FieldCleared(name string) bool {
+	_, ok := m.clearedFields[name]
+	return ok
+}
+
+// ClearField clears the value of the field with the given name. It returns an
+// error if the field is not defined in the schema.
+func (m *PasswordMutation) ClearField(name string) error {
+	switch name {
+	case password.FieldLockedUntil:
+		m.ClearLockedUntil()
+		return nil
+	}
+	return fmt.Errorf("unknown Password nullable field %s", name)
+}
+
+// ResetField resets all changes in the mutation for the field with the given name.
+// It returns an error if the field is not defined in the schema.
+func (m *PasswordMutation) ResetField(name string) error {
+	switch name {
+	case password.FieldEmail:
+		m.ResetEmail()
+		return nil
+	case password.FieldHash:
+		m.ResetHash()
+		return nil
+	case password.FieldUsername:
+		m.ResetUsername()
+		return nil
+	case password.FieldUserID:
+		m.ResetUserID()
+		return nil
+	case password.FieldEmailVerified:
+		m.ResetEmailVerified()
+		return nil
+	case password.FieldFailedAttempts:
+		m.ResetFailedAttempts()
+		return nil
+	case password.FieldLockedUntil:
+		m.ResetLockedUntil()
+		return nil
+	}
+	return fmt.Errorf("unknown Password field %s", name)
+}
+
+// AddedEdges returns all edge names that were set/added in this mutation.
+func (m *PasswordMutation) AddedEdges() []string {
+	edges := make([]string, 0, 0)
+	return edges
+}
+
+// AddedIDs returns all IDs (to other nodes) that were added for the given edge
+// name in this mutation.
+func (m *PasswordMutation) AddedIDs(name string) []ent.Value {
+	return nil
+}
+
+// RemovedEdges returns all edge names that were removed in this mutation.
+func (m *PasswordMutation) RemovedEdges() []string {
+	edges := make([]string, 0, 0)
+	return edges
+}
+
+// RemovedIDs returns all IDs (to other nodes) that were removed for the edge with
+// the given name in this mutation.
+func (m *PasswordMutation) RemovedIDs(name string) []ent.Value {
+	return nil
+}
+
+// ClearedEdges returns all edge names that were cleared in this mutation.
+func (m *PasswordMutation) ClearedEdges() []string {
+	edges := make([]string, 0, 0)
+	return edges
+}
+
+// EdgeCleared returns a boolean which indicates if the edge with the given name
+// was cleared in this mutation.
+func (m *PasswordMutation) EdgeCleared(name string) bool {
+	return false
+}
+
+// ClearEdge clears the value of the edge with the given name. It returns an error
+// if that edge is not defined in the schema.
+func (m *PasswordMutation) ClearEdge(name string) error {
+	return fmt.Errorf("unknown Password unique edge %s", name)
+}
+
+// ResetEdge resets all changes to the edge with the given name in this mutation.
+// It returns an error if the edge is not defined in the schema.
+func (m *PasswordMutation) ResetEdge(name string) error {
+	return fmt.Errorf("unknown Password edge %s", name)
+}
+
+// RefreshTokenMutation represents an operation that mutates the RefreshToken nodes in the graph.
+type RefreshTokenMutation struct {
+	config
+	op                        Op
+	typ                       string
+	id                        *string
+	client_id                 *string
+	scopes                    *[]string
+	appendscopes              []string
+	nonce                     *string
+	claims_user_id            *string
+	claims_username           *string
+	claims_email              *string
+	claims_email_verified     *bool
+	claims_groups             *[]string
+	appendclaims_groups       []string
+	claims_preferred_username *string
+	connector_id              *string
+	connector_data            *[]byte
+	token                     *string
+	obsolete_token            *string
+	created_at                *time.Time
+	last_used                 *time.Time
+	claims_custom             *map[string]interface{}
+	claims_request            *[]byte
+	claims_sources            *map[string]storage.ClaimSource
+	issuer                    *string
+	clearedFields             map[string]struct{}
+	done                      bool
+	oldValue                  func(context.Context) (*RefreshToken, error)
+	predicates                []predicate.RefreshToken
+}
+
+var _ ent.Mutation = (*RefreshTokenMutation)(nil)
+
+// refreshtokenOption allows management of the mutation configuration using functional options.
+type refreshtokenOption func(*RefreshTokenMutation)
+
+// newRefreshTokenMutation creates new mutation for the RefreshToken entity.
+func newRefreshTokenMutation(c config, op Op, opts ...refreshtokenOption) *RefreshTokenMutation {
+	m := &RefreshTokenMutation{
+		config:        c,
+		op:            op,
+		typ:           TypeRefreshToken,
+		clearedFields: make(map[string]struct{}),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// withRefreshTokenID sets the ID field of the mutation.
+func withRefreshTokenID(id string) refreshtokenOption {
+	return func(m *RefreshTokenMutation) {
+		var (
+			err   error
+			once  sync.Once
+			value *RefreshToken
+		)
+		m.oldValue = func(ctx context.Context) (*RefreshToken, error) {
+			once.Do(func() {
+				if m.done {
+					err = errors.New("querying old values post mutation is not allowed")
+				} else {
+					value, err = m.Client().RefreshToken.Get(ctx, id)
+				}
+			})
+			return value, err
+		}
+		m.id = &id
+	}
+}
+
+// withRefreshToken sets the old RefreshToken of the mutation.
+func withRefreshToken(node *RefreshToken) refreshtokenOption {
+	return func(m *RefreshTokenMutation) {
+		m.oldValue = func(context.Context) (*RefreshToken, error) {
+			return node, nil
+		}
+		m.id = &node.ID
+	}
+}
+
+// Client returns a new `ent.Client` from the mutation. If the mutation was
+// executed in a transaction (ent.Tx), a transactional client is returned.
+func (m RefreshTokenMutation) Client() *Client {
+	client := &Client{config: m.config}
+	client.init()
+	return client
+}
+
+// Tx returns an `ent.Tx` for mutations that were executed in transactions;
+// it returns an error otherwise.
+func (m RefreshTokenMutation) Tx() (*Tx, error) {
+	if _, ok := m.driver.(*txDriver); !ok {
+		return nil, errors.New("db: mutation is not running in a transaction")
+	}
+	tx := &Tx{config: m.config}
+	tx.init()
+	return tx, nil
+}
+
+// SetID sets the value of the id field. Note that this
+// operation is only accepted on creation of RefreshToken entities.
+func (m *RefreshTokenMutation) SetID(id string) {
+	m.id = &id
+}
+
+// ID returns the ID value in the mutation. Note that the ID is only available
+// if it was provided to the builder or after it was returned from the database.
+func (m *RefreshTokenMutation) ID() (id string, exists bool) {
+	if m.id == nil {
+		return
+	}
+	return *m.id, true
+}
+
+// IDs queries the database and returns the entity ids that match the mutation's predicate.
+// That means, if the mutation is applied within a transaction with an isolation level such
+// as sql.LevelSerializable, the returned ids match the ids of the rows that will be updated
+// or updated by the mutation.
+func (m *RefreshTokenMutation) IDs(ctx context.Context) ([]string, error) {
+	switch {
+	case m.op.Is(OpUpdateOne | OpDeleteOne):
+		id, exists := m.ID()
+		if exists {
+			return []string{id}, nil
+		}
+		fallthrough
+	case m.op.Is(OpUpdate | OpDelete):
+		return m.Client().RefreshToken.Query().Where(m.predicates...).IDs(ctx)
+	default:
+		return nil, fmt.Errorf("IDs is not allowed on %s operations", m.op)
+	}
+}
+
+// SetClientID sets the "client_id" field.
+func (m *RefreshTokenMutation) SetClientID(s string) {
+	m.client_id = &s
+}
+
+// ClientID returns the value of the "client_id" field in the mutation.
+func (m *RefreshTokenMutation) ClientID() (r string, exists bool) {
+	v := m.client_id
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldClientID returns the old "client_id" field's value of the RefreshToken entity.
+// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *RefreshTokenMutation) OldClientID(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldClientID is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldClientID requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldClientID: %w", err)
+	}
+	return oldValue.ClientID, nil
+}
+
+// ResetClientID resets all changes to the "client_id" field.
+func (m *RefreshTokenMutation) ResetClientID() {
+	m.client_id = nil
+}
+
+// SetScopes sets the "scopes" field.
+func (m *RefreshTokenMutation) SetScopes(s []string) {
+	m.scopes = &s
+	m.appendscopes = nil
+}
+
+// Scopes returns the value of the "scopes" field in the mutation.
+func (m *RefreshTokenMutation) Scopes() (r []string, exists bool) {
+	v := m.scopes
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldScopes returns the old "scopes" field's value of the RefreshToken entity.
+// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *RefreshTokenMutation) OldScopes(ctx context.Context) (v []string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldScopes is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldScopes requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldScopes: %w", err)
+	}
+	return oldValue.Scopes, nil
+}
+
+// AppendScopes adds s to the "scopes" field.
+func (m *RefreshTokenMutation) AppendScopes(s []string) {
+	m.appendscopes = append(m.appendscopes, s...)
+}
+
+// AppendedScopes returns the list of values that were appended to the "scopes" field in this mutation.
+func (m *RefreshTokenMutation) AppendedScopes() ([]string, bool) {
+	if len(m.appendscopes) == 0 {
+		return nil, false
+	}
+	return m.appendscopes, true
+}
+
+// ClearScopes clears the value of the "scopes" field.
+func (m *RefreshTokenMutation) ClearScopes() {
+	m.scopes = nil
+	m.appendscopes = nil
+	m.clearedFields[refreshtoken.FieldScopes] = struct{}{}
+}
+
+// ScopesCleared returns if the "scopes" field was cleared in this mutation.
+func (m *RefreshTokenMutation) ScopesCleared() bool {
+	_, ok := m.clearedFields[refreshtoken.FieldScopes]
+	return ok
+}
+
+// ResetScopes resets all changes to the "scopes" field.
+func (m *RefreshTokenMutation) ResetScopes() {
+	m.scopes = nil
+	m.appendscopes = nil
+	delete(m.clearedFields, refreshtoken.FieldScopes)
+}
+
+// SetNonce sets the "nonce" field.
+func (m *RefreshTokenMutation) SetNonce(s string) {
+	m.nonce = &s
+}
+
+// Nonce returns the value of the "nonce" field in the mutation.
+func (m *RefreshTokenMutation) Nonce() (r string, exists bool) {
+	v := m.nonce
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldNonce returns the old "nonce" field's value of the RefreshToken entity.
+// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *RefreshTokenMutation) OldNonce(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldNonce is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldNonce requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldNonce: %w", err)
+	}
+	return oldValue.Nonce, nil
+}
+
+// ResetNonce resets all changes to the "nonce" field.
+func (m *RefreshTokenMutation) ResetNonce() {
+	m.nonce = nil
+}
+
+// SetClaimsUserID sets the "claims_user_id" field.
+func (m *RefreshTokenMutation) SetClaimsUserID(s string) {
+	m.claims_user_id = &s
+}
+
+// ClaimsUserID returns the value of the "claims_user_id" field in the mutation.
+func (m *RefreshTokenMutation) ClaimsUserID() (r string, exists bool) {
+	v := m.claims_user_id
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldClaimsUserID returns the old "claims_user_id" field's value of the RefreshToken entity.
+// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *RefreshTokenMutation) OldClaimsUserID(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldClaimsUserID is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldClaimsUserID requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldClaimsUserID: %w", err)
+	}
+	return oldValue.ClaimsUserID, nil
+}
+
+// ResetClaimsUserID resets all changes to the "claims_user_id" field.
+func (m *RefreshTokenMutation) ResetClaimsUserID() {
+	m.claims_user_id = nil
+}
+
+// SetClaimsUsername sets the "claims_username" field.
+func (m *RefreshTokenMutation) SetClaimsUsername(s string) {
+	m.claims_username = &s
+}
+
+// ClaimsUsername returns the value of the "claims_username" field in the mutation.
+func (m *RefreshTokenMutation) ClaimsUsername() (r string, exists bool) {
+	v := m.claims_username
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldClaimsUsername returns the old "claims_username" field's value of the RefreshToken entity.
+// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *RefreshTokenMutation) OldClaimsUsername(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldClaimsUsername is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldClaimsUsername requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldClaimsUsername: %w", err)
+	}
+	return oldValue.ClaimsUsername, nil
+}
+
+// ResetClaimsUsername resets all changes to the "claims_username" field.
+func (m *RefreshTokenMutation) ResetClaimsUsername() {
+	m.claims_username = nil
+}
+
+// SetClaimsEmail sets the "claims_email" field.
+func (m *RefreshTokenMutation) SetClaimsEmail(s string) {
+	m.claims_email = &s
+}
+
+// ClaimsEmail returns the value of the "claims_email" field in the mutation.
+func (m *RefreshTokenMutation) ClaimsEmail() (r string, exists bool) {
+	v := m.claims_email
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldClaimsEmail returns the old "claims_email" field's value of the RefreshToken entity.
+// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *RefreshTokenMutation) OldClaimsEmail(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldClaimsEmail is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldClaimsEmail requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldClaimsEmail: %w", err)
+	}
+	return oldValue.ClaimsEmail, nil
+}
+
+// ResetClaimsEmail resets all changes to the "claims_email" field.
+func (m *RefreshTokenMutation) ResetClaimsEmail() {
+	m.claims_email = nil
+}
+
+// SetClaimsEmailVerified sets the "claims_email_verified" field.
+func (m *RefreshTokenMutation) SetClaimsEmailVerified(b bool) {
+	m.claims_email_verified = &b
+}
+
+// ClaimsEmailVerified returns the value of the "claims_email_verified" field in the mutation.
+func (m *RefreshTokenMutation) ClaimsEmailVerified() (r bool, exists bool) {
+	v := m.claims_email_verified
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldClaimsEmailVerified returns the old "claims_email_verified" field's value of the RefreshToken entity.
+// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *RefreshTokenMutation) OldClaimsEmailVerified(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldClaimsEmailVerified is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldClaimsEmailVerified requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldClaimsEmailVerified: %w", err)
+	}
+	return oldValue.ClaimsEmailVerified, nil
+}
+
+// ResetClaimsEmailVerified resets all changes to the "claims_email_verified" field.
+func (m *RefreshTokenMutation) ResetClaimsEmailVerified() {
+	m.claims_email_verified = nil
+}
+
+// SetClaimsGroups sets the "claims_groups" field.
+func (m *RefreshTokenMutation) SetClaimsGroups(s []string) {
+	m.claims_groups = &s
+	m.appendclaims_groups = nil
+}
+
+// ClaimsGroups returns the value of the "claims_groups" field in the mutation.
+func (m *RefreshTokenMutation) ClaimsGroups() (r []string, exists bool) {
+	v := m.claims_groups
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldClaimsGroups returns the old "claims_groups" field's value of the RefreshToken entity.
+// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *RefreshTokenMutation) OldClaimsGroups(ctx context.Context) (v []string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldClaimsGroups is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldClaimsGroups requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldClaimsGroups: %w", err)
+	}
+	return oldValue.ClaimsGroups, nil
+}
+
+// AppendClaimsGroups adds s to the "claims_groups" field.
+func (m *RefreshTokenMutation) AppendClaimsGroups(s []string) {
+	m.appendclaims_groups = append(m.appendclaims_groups, s...)
+}
+
+// AppendedClaimsGroups returns the list of values that were appended to the "claims_groups" field in this mutation.
+func (m *RefreshTokenMutation) AppendedClaimsGroups() ([]string, bool) {
+	if len(m.appendclaims_groups) == 0 {
+		return nil, false
+	}
+	return m.appendclaims_groups, true
+}
+
+// ClearClaimsGroups clears the value of the "claims_groups" field.
+func (m *RefreshTokenMutation) ClearClaimsGroups() {
+	m.claims_groups = nil
+	m.appendclaims_groups = nil
+	m.clearedFields[refreshtoken.FieldClaimsGroups] = struct{}{}
+}
+
+// ClaimsGroupsCleared returns if the "claims_groups" field was cleared in this mutation.
+func (m *RefreshTokenMutation) ClaimsGroupsCleared() bool {
+	_, ok := m.clearedFields[refreshtoken.FieldClaimsGroups]
+	return ok
+}
+
+// ResetClaimsGroups resets all changes to the "claims_groups" field.
+func (m *RefreshTokenMutation) ResetClaimsGroups() {
+	m.claims_groups = nil
+	m.appendclaims_groups = nil
+	delete(m.clearedFields, refreshtoken.FieldClaimsGroups)
+}
+
+// SetClaimsPreferredUsername sets the "claims_preferred_username" field.
+func (m *RefreshTokenMutation) SetClaimsPreferredUsername(s string) {
+	m.claims_preferred_username = &s
+}
+
+// ClaimsPreferredUsername returns the value of the "claims_preferred_username" field in the mutation.
+func (m *RefreshTokenMutation) ClaimsPreferredUsername() (r string, exists bool) {
+	v := m.claims_preferred_username
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldClaimsPreferredUsername returns the old "claims_preferred_username" field's value of the RefreshToken entity.
+// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *RefreshTokenMutation) OldClaimsPreferredUsername(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldClaimsPreferredUsername is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldClaimsPreferredUsername requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldClaimsPreferredUsername: %w", err)
+	}
+	return oldValue.ClaimsPreferredUsername, nil
+}
+
+// ResetClaimsPreferredUsername resets all changes to the "claims_preferred_username" field.
+func (m *RefreshTokenMutation) ResetClaimsPreferredUsername() {
+	m.claims_preferred_username = nil
+}
+
+// SetConnectorID sets the "connector_id" field.
+func (m *RefreshTokenMutation) SetConnectorID(s string) {
+	m.connector_id = &s
+}
+
+// ConnectorID returns the value of the "connector_id" field in the mutation.
+func (m *RefreshTokenMutation) ConnectorID() (r string, exists bool) {
+	v := m.connector_id
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldConnectorID returns the old "connector_id" field's value of the RefreshToken entity.
+// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *RefreshTokenMutation) OldConnectorID(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldConnectorID is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldConnectorID requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldConnectorID: %w", err)
+	}
+	return oldValue.ConnectorID, nil
+}
+
+// ResetConnectorID resets all changes to the "connector_id" field.
+func (m *RefreshTokenMutation) ResetConnectorID() {
+	m.connector_id = nil
+}
+
+// SetConnectorData sets the "connector_data" field.
+func (m *RefreshTokenMutation) SetConnectorData(b []byte) {
+	m.connector_data = &b
+}
+
+// ConnectorData returns the value of the "connector_data" field in the mutation.
+func (m *RefreshTokenMutation) ConnectorData() (r []byte, exists bool) {
+	v := m.connector_data
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldConnectorData returns the old "connector_data" field's value of the RefreshToken entity.
+// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *RefreshTokenMutation) OldConnectorData(ctx context.Context) (v *[]byte, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldConnectorData is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldConnectorData requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldConnectorData: %w", err)
+	}
+	return oldValue.ConnectorData, nil
+}
+
+// ClearConnectorData clears the value of the "connector_data" field.
+func (m *RefreshTokenMutation) ClearConnectorData() {
+	m.connector_data = nil
+	m.clearedFields[refreshtoken.FieldConnectorData] = struct{}{}
+}
+
+// ConnectorDataCleared returns if the "connector_data" field was cleared in this mutation.
+func (m *RefreshTokenMutation) ConnectorDataCleared() bool {
+	_, ok := m.clearedFields[refreshtoken.FieldConnectorData]
+	return ok
+}
+
+// ResetConnectorData resets all changes to the "connector_data" field.
+func (m *RefreshTokenMutation) ResetConnectorData() {
+	m.connector_data = nil
+	delete(m.clearedFields, refreshtoken.FieldConnectorData)
+}
+
+// SetToken sets the "token" field.
+func (m *RefreshTokenMutation) SetToken(s string) {
+	m.token = &s
+}
+
+// Token returns the value of the "token" field in the mutation.
+func (m *RefreshTokenMutation) Token() (r string, exists bool) {
+	v := m.token
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldToken returns the old "token" field's value of the RefreshToken entity.
+// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *RefreshTokenMutation) OldToken(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldToken is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldToken requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldToken: %w", err)
+	}
+	return oldValue.Token, nil
+}
+
+// ResetToken resets all changes to the "token" field.
+func (m *RefreshTokenMutation) ResetToken() {
+	m.token = nil
+}
+
+// SetObsoleteToken sets the "obsolete_token" field.
+func (m *RefreshTokenMutation) SetObsoleteToken(s string) {
+	m.obsolete_token = &s
+}
+
+// ObsoleteToken returns the value of the "obsolete_token" field in the mutation.
+func (m *RefreshTokenMutation) ObsoleteToken() (r string, exists bool) {
+	v := m.obsolete_token
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldObsoleteToken returns the old "obsolete_token" field's value of the RefreshToken entity.
+// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *RefreshTokenMutation) OldObsoleteToken(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldObsoleteToken is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldObsoleteToken requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldObsoleteToken: %w", err)
+	}
+	return oldValue.ObsoleteToken, nil
+}
+
+// ResetObsoleteToken resets all changes to the "obsolete_token" field.
+func (m *RefreshTokenMutation) ResetObsoleteToken() {
+	m.obsolete_token = nil
+}
+
+// SetCreatedAt sets the "created_at" field.
+func (m *RefreshTokenMutation) SetCreatedAt(t time.Time) {
+	m.created_at = &t
+}
+
+// CreatedAt returns the value of the "created_at" field in the mutation.
+func (m *RefreshTokenMutation) CreatedAt() (r time.Time, exists bool) {
+	v := m.created_at
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldCreatedAt returns the old "created_at" field's value of the RefreshToken entity.
+// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *RefreshTokenMutation) OldCreatedAt(ctx context.Context) (v time.Time, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldCreatedAt is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldCreatedAt requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldCreatedAt: %w", err)
+	}
+	return oldValue.CreatedAt, nil
+}
+
+// ResetCreatedAt resets all changes to the "created_at" field.
+func (m *RefreshTokenMutation) ResetCreatedAt() {
+	m.created_at = nil
+}
+
+// SetLastUsed sets the "last_used" field.
+func (m *RefreshTokenMutation) SetLastUsed(t time.Time) {
+	m.last_used = &t
+}
+
+// LastUsed returns the value of the "last_used" field in the mutation.
+func (m *RefreshTokenMutation) LastUsed() (r time.Time, exists bool) {
+	v := m.last_used
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldLastUsed returns the old "last_used" field's value of the RefreshToken entity.
+// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *RefreshTokenMutation) OldLastUsed(ctx context.Context) (v time.Time, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldLastUsed is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldLastUsed requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldLastUsed: %w", err)
+	}
+	return oldValue.LastUsed, nil
+}
+
+// ResetLastUsed resets all changes to the "last_used" field.
+func (m *RefreshTokenMutation) ResetLastUsed() {
+	m.last_used = nil
+}
+
+// SetClaimsCustom sets the "claims_custom" field.
+func (m *RefreshTokenMutation) SetClaimsCustom(value map[string]interface{}) {
+	m.claims_custom = &value
+}
+
+// ClaimsCustom returns the value of the "claims_custom" field in the mutation.
+func (m *RefreshTokenMutation) ClaimsCustom() (r map[string]interface{}, exists bool) {
+	v := m.claims_custom
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldClaimsCustom returns the old "claims_custom" field's value of the RefreshToken entity.
+// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *RefreshTokenMutation) OldClaimsCustom(ctx context.Context) (v map[string]interface{}, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldClaimsCustom is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldClaimsCustom requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldClaimsCustom: %w", err)
+	}
+	return oldValue.ClaimsCustom, nil
+}
+
+// ClearClaimsCustom clears the value of the "claims_custom" field.
+func (m *RefreshTokenMutation) ClearClaimsCustom() {
+	m.claims_custom = nil
+	m.clearedFields[refreshtoken.FieldClaimsCustom] = struct{}{}
+}
+
+// ClaimsCustomCleared returns if the "claims_custom" field was cleared in this mutation.
+func (m *RefreshTokenMutation) ClaimsCustomCleared() bool {
+	_, ok := m.clearedFields[refreshtoken.FieldClaimsCustom]
+	return ok
+}
+
+// ResetClaimsCustom resets all changes to the "claims_custom" field.
+func (m *RefreshTokenMutation) ResetClaimsCustom() {
+	m.claims_custom = nil
+	delete(m.clearedFields, refreshtoken.FieldClaimsCustom)
+}
+
+// SetClaimsRequest sets the "claims_request" field.
+func (m *RefreshTokenMutation) SetClaimsRequest(b []byte) {
+	m.claims_request = &b
+}
+
+// ClaimsRequest returns the value of the "claims_request" field in the mutation.
+func (m *RefreshTokenMutation) ClaimsRequest() (r []byte, exists bool) {
+	v := m.claims_request
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldClaimsRequest returns the old "claims_request" field's value of the RefreshToken entity.
+// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *RefreshTokenMutation) OldClaimsRequest(ctx context.Context) (v *[]byte, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldClaimsRequest is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldClaimsRequest requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldClaimsRequest: %w", err)
+	}
+	return oldValue.ClaimsRequest, nil
+}
+
+// ClearClaimsRequest clears the value of the "claims_request" field.
+func (m *RefreshTokenMutation) ClearClaimsRequest() {
+	m.claims_request = nil
+	m.clearedFields[refreshtoken.FieldClaimsRequest] = struct{}{}
+}
+
+// ClaimsRequestCleared returns if the "claims_request" field was cleared in this mutation.
+func (m *RefreshTokenMutation) ClaimsRequestCleared() bool {
+	_, ok := m.clearedFields[refreshtoken.FieldClaimsRequest]
+	return ok
+}
+
+// ResetClaimsRequest resets all changes to the "claims_request" field.
+func (m *RefreshTokenMutation) ResetClaimsRequest() {
+	m.claims_request = nil
+	delete(m.clearedFields, refreshtoken.FieldClaimsRequest)
+}
+
+// SetClaimsSources sets the "claims_sources" field.
+func (m *RefreshTokenMutation) SetClaimsSources(value map[string]storage.ClaimSource) {
+	m.claims_sources = &value
+}
+
+// ClaimsSources returns the value of the "claims_sources" field in the mutation.
+func (m *RefreshTokenMutation) ClaimsSources() (r map[string]storage.ClaimSource, exists bool) {
+	v := m.claims_sources
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldClaimsSources returns the old "claims_sources" field's value of the RefreshToken entity.
+// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *RefreshTokenMutation) OldClaimsSources(ctx context.Context) (v map[string]storage.ClaimSource, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldClaimsSources is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldClaimsSources requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldClaimsSources: %w", err)
+	}
+	return oldValue.ClaimsSources, nil
+}
+
+// ClearClaimsSources clears the value of the "claims_sources" field.
+func (m *RefreshTokenMutation) ClearClaimsSources() {
+	m.claims_sources = nil
+	m.clearedFields[refreshtoken.FieldClaimsSources] = struct{}{}
+}
+
+// ClaimsSourcesCleared returns if the "claims_sources" field was cleared in this mutation.
+func (m *RefreshTokenMutation) ClaimsSourcesCleared() bool {
+	_, ok := m.clearedFields[refreshtoken.FieldClaimsSources]
+	return ok
+}
+
+// ResetClaimsSources resets all changes to the "claims_sources" field.
+func (m *RefreshTokenMutation) ResetClaimsSources() {
+	m.claims_sources = nil
+	delete(m.clearedFields, refreshtoken.FieldClaimsSources)
+}
+
+// SetIssuer sets the "issuer" field.
+func (m *RefreshTokenMutation) SetIssuer(s string) {
+	m.issuer = &s
+}
+
+// Issuer returns the value of the "issuer" field in the mutation.
+func (m *RefreshTokenMutation) Issuer() (r string, exists bool) {
+	v := m.issuer
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldIssuer returns the old "issuer" field's value of the RefreshToken entity.
+// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *RefreshTokenMutation) OldIssuer(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldIssuer is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldIssuer requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldIssuer: %w", err)
+	}
+	return oldValue.Issuer, nil
+}
+
+// ResetIssuer resets all changes to the "issuer" field.
+func (m *RefreshTokenMutation) ResetIssuer() {
+	m.issuer = nil
+}
+
+// Where appends a list predicates to the RefreshTokenMutation builder.
+func (m *RefreshTokenMutation) Where(ps ...predicate.RefreshToken) {
+	m.predicates = append(m.predicates, ps...)
+}
+
+// WhereP appends storage-level predicates to the RefreshTokenMutation builder. Using this method,
+// users can use type-assertion to append predicates that do not depend on any generated package.
+func (m *RefreshTokenMutation) WhereP(ps ...func(*sql.Selector)) {
+	p := make([]predicate.RefreshToken, len(ps))
+	for i := range ps {
+		p[i] = ps[i]
+	}
+	m.Where(p...)
+}
+
+// Op returns the operation name.
+func (m *RefreshTokenMutation) Op() Op {
+	return m.op
+}
+
+// SetOp allows setting the mutation operation.
+func (m *RefreshTokenMutation) SetOp(op Op) {
+	m.op = op
+}
+
+// Type returns the node type of this mutation (RefreshToken).
+func (m *RefreshTokenMutation) Type() string {
+	return m.typ
+}
+
+// Fields returns all fields that were changed during this mutation. Note that in
+// order to get all numeric fields that were incremented/decremented, call
+// AddedFields().
+func (m *RefreshTokenMutation) Fields() []string {
+	fields := make([]string, 0, 15)
+	if m.client_id != nil {
+		fields = append(fields, refreshtoken.FieldClientID)
+	}
+	if m.scopes != nil {
+		fields = append(fields, refreshtoken.FieldScopes)
+	}
+	if m.nonce != nil {
+		fields = append(fields, refreshtoken.FieldNonce)
+	}
+	if m.claims_user_id != nil {
+		fields = append(fields, refreshtoken.FieldClaimsUserID)
+	}
+	if m.claims_username != nil {
+		fields = append(fields, refreshtoken.FieldClaimsUsername)
+	}
+	if m.claims_email != nil {
+		fields = append(fields, refreshtoken.FieldClaimsEmail)
+	}
+	if m.claims_email_verified != nil {
+		fields = append(fields, refreshtoken.FieldClaimsEmailVerified)
+	}
+	if m.claims_groups != nil {
+		fields = append(fields, refreshtoken.FieldClaimsGroups)
+	}
+	if m.claims_preferred_username != nil {
+		fields = append(fields, refreshtoken.FieldClaimsPreferredUsername)
+	}
+	if m.connector_id != nil {
+		fields = append(fields, refreshtoken.FieldConnectorID)
+	}
+	if m.connector_data != nil {
+		fields = append(fields, refreshtoken.FieldConnectorData)
+	}
+	if m.token != nil {
+		fields = append(fields, refreshtoken.FieldToken)
+	}
+	if m.obsolete_token != nil {
+		fields = append(fields, refreshtoken.FieldObsoleteToken)
+	}
+	if m.created_at != nil {
+		fields = append(fields, refreshtoken.FieldCreatedAt)
+	}
+	if m.last_used != nil {
+		fields = append(fields, refreshtoken.FieldLastUsed)
+	}
+	if m.claims_custom != nil {
+		fields = append(fields, refreshtoken.FieldClaimsCustom)
+	}
+	if m.claims_request != nil {
+		fields = append(fields, refreshtoken.FieldClaimsRequest)
+	}
+	if m.claims_sources != nil {
+		fields = append(fields, refreshtoken.FieldClaimsSources)
+	}
+	if m.issuer != nil {
+		fields = append(fields, refreshtoken.FieldIssuer)
+	}
+	return fields
+}
+
+// Field returns the value of a field with the given name. The second boolean
+// return value indicates that this field was not set, or was not defined in the
+// schema.
+func (m *RefreshTokenMutation) Field(name string) (ent.Value, bool) {
+	switch name {
+	case refreshtoken.FieldClientID:
+		return m.ClientID()
+	case refreshtoken.FieldScopes:
+		return m.Scopes()
+	case refreshtoken.FieldNonce:
+		return m.Nonce()
+	case refreshtoken.FieldClaimsUserID:
+		return m.ClaimsUserID()
+	case refreshtoken.FieldClaimsUsername:
+		return m.ClaimsUsername()
+	case refreshtoken.FieldClaimsEmail:
+		return m.ClaimsEmail()
+	case refreshtoken.FieldClaimsEmailVerified:
+		return m.ClaimsEmailVerified()
+	case refreshtoken.FieldClaimsGroups:
+		return m.ClaimsGroups()
+	case refreshtoken.FieldClaimsPreferredUsername:
+		return m.ClaimsPreferredUsername()
+	case refreshtoken.FieldConnectorID:
+		return m.ConnectorID()
+	case refreshtoken.FieldConnectorData:
+		return m.ConnectorData()
+	case refreshtoken.FieldToken:
+		return m.Token()
+	case refreshtoken.FieldObsoleteToken:
+		return m.ObsoleteToken()
+	case refreshtoken.FieldCreatedAt:
+		return m.CreatedAt()
+	case refreshtoken.FieldLastUsed:
+		return m.LastUsed()
+	case refreshtoken.FieldClaimsCustom:
+		return m.ClaimsCustom()
+	case refreshtoken.FieldClaimsRequest:
+		return m.ClaimsRequest()
+	case refreshtoken.FieldClaimsSources:
+		return m.ClaimsSources()
+	case refreshtoken.FieldIssuer:
+		return m.Issuer()
+	}
+	return nil, false
+}
+
+// OldField returns the old value of the field from the database. An error is
+// returned if the mutation operation is not UpdateOne, or the query to the
+// database failed.
+func (m *RefreshTokenMutation) OldField(ctx context.Context, name string) (ent.Value, error) {
+	switch name {
+	case refreshtoken.FieldClientID:
+		return m.OldClientID(ctx)
+	case refreshtoken.FieldScopes:
+		return m.OldScopes(ctx)
+	case refreshtoken.FieldNonce:
+		return m.OldNonce(ctx)
+	case refreshtoken.FieldClaimsUserID:
+		return m.OldClaimsUserID(ctx)
+	case refreshtoken.FieldClaimsUsername:
+		return m.OldClaimsUsername(ctx)
+	case refreshtoken.FieldClaimsEmail:
+		return m.OldClaimsEmail(ctx)
+	case refreshtoken.FieldClaimsEmailVerified:
+		return m.OldClaimsEmailVerified(ctx)
+	case refreshtoken.FieldClaimsGroups:
+		return m.OldClaimsGroups(ctx)
+	case refreshtoken.FieldClaimsPreferredUsername:
+		return m.OldClaimsPreferredUsername(ctx)
+	case refreshtoken.FieldConnectorID:
+		return m.OldConnectorID(ctx)
+	case refreshtoken.FieldConnectorData:
+		return m.OldConnectorData(ctx)
+	case refreshtoken.FieldToken:
+		return m.OldToken(ctx)
+	case refreshtoken.FieldObsoleteToken:
+		return m.OldObsoleteToken(ctx)
+	case refreshtoken.FieldCreatedAt:
+		return m.OldCreatedAt(ctx)
+	case refreshtoken.FieldLastUsed:
+		return m.OldLastUsed(ctx)
+	case refreshtoken.FieldClaimsCustom:
+		return m.OldClaimsCustom(ctx)
+	case refreshtoken.FieldClaimsRequest:
+		return m.OldClaimsRequest(ctx)
+	case refreshtoken.FieldClaimsSources:
+		return m.OldClaimsSources(ctx)
+	case refreshtoken.FieldIssuer:
+		return m.OldIssuer(ctx)
+	}
+	return nil, fmt.Errorf("unknown RefreshToken field %s", name)
+}
+
+// SetField sets the value of a field with the given name. It returns an error if
+// the field is not defined in the schema, or if the type mismatched the field
+// type.
+func (m *RefreshTokenMutation) SetField(name string, value ent.Value) error {
+	switch name {
+	case refreshtoken.FieldClientID:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetClientID(v)
+		return nil
+	case refreshtoken.FieldScopes:
+		v, ok := value.([]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetScopes(v)
+		return nil
+	case refreshtoken.FieldNonce:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetNonce(v)
+		return nil
+	case refreshtoken.FieldClaimsUserID:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetClaimsUserID(v)
+		return nil
+	case refreshtoken.FieldClaimsUsername:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetClaimsUsername(v)
+		return nil
+	case refreshtoken.FieldClaimsEmail:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetClaimsEmail(v)
+		return nil
+	case refreshtoken.FieldClaimsEmailVerified:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetClaimsEmailVerified(v)
+		return nil
+	case refreshtoken.FieldClaimsGroups:
+		v, ok := value.([]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetClaimsGroups(v)
+		return nil
+	case refreshtoken.FieldClaimsPreferredUsername:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetClaimsPreferredUsername(v)
+		return nil
+	case refreshtoken.FieldConnectorID:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetConnectorID(v)
+		return nil
+	case refreshtoken.FieldConnectorData:
+		v, ok := value.([]byte)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetConnectorData(v)
+		return nil
+	case refreshtoken.FieldToken:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetToken(v)
+		return nil
+	case refreshtoken.FieldObsoleteToken:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetObsoleteToken(v)
+		return nil
+	case refreshtoken.FieldCreatedAt:
+		v, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetCreatedAt(v)
+		return nil
+	case refreshtoken.FieldLastUsed:
+		v, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetLastUsed(v)
+		return nil
+	case refreshtoken.FieldClaimsCustom:
+		v, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetClaimsCustom(v)
+		return nil
+	case refreshtoken.FieldClaimsRequest:
+		v, ok := value.([]byte)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetClaimsRequest(v)
+		return nil
+	case refreshtoken.FieldClaimsSources:
+		v, ok := value.(map[string]storage.ClaimSource)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetClaimsSources(v)
+		return nil
+	case refreshtoken.FieldIssuer:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetIssuer(v)
+		return nil
+	}
+	return fmt.Errorf("unknown RefreshToken field %s", name)
+}
+
+// AddedFields returns all numeric fields that were incremented/decremented during
+// this mutation.
+func (m *RefreshTokenMutation) AddedFields() []string {
+	return nil
+}
+
+// AddedField returns the numeric value that was incremented/decremented on a field
+// with the given name. The second boolean return value indicates that this field
+// was not set, or was not defined in the schema.
+func (m *RefreshTokenMutation) AddedField(name string) (ent.Value, bool) {
+	return nil, false
+}
+
+// AddField adds the value to the field with the given name. It returns an error if
+// the field is not defined in the schema, or if the type mismatched the field
+// type.
+func (m *RefreshTokenMutation) AddField(name string, value ent.Value) error {
+	switch name {
+	}
+	return fmt.Errorf("unknown RefreshToken numeric field %s", name)
+}
+
+// ClearedFields returns all nullable fields that were cleared during this
+// mutation.
+func (m *RefreshTokenMutation) ClearedFields() []string {
+	var fields []string
+	if m.FieldCleared(refreshtoken.FieldScopes) {
+		fields = append(fields, refreshtoken.FieldScopes)
+	}
+	if m.FieldCleared(refreshtoken.FieldClaimsGroups) {
+		fields = append(fields, refreshtoken.FieldClaimsGroups)
+	}
+	if m.FieldCleared(refreshtoken.FieldConnectorData) {
+		fields = append(fields, refreshtoken.FieldConnectorData)
+	}
+	if m.FieldCleared(refreshtoken.FieldClaimsCustom) {
+		fields = append(fields, refreshtoken.FieldClaimsCustom)
+	}
+	if m.FieldCleared(refreshtoken.FieldClaimsRequest) {
+		fields = append(fields, refreshtoken.FieldClaimsRequest)
+	}
+	if m.FieldCleared(refreshtoken.FieldClaimsSources) {
+		fields = append(fields, refreshtoken.FieldClaimsSources)
+	}
+	return fields
+}
+
+// FieldCleared returns a boolean indicating if a field with the given name was
+// cleared in this mutation.
+func (m *RefreshTokenMutation) FieldCleared(name string) bool {
+	_, ok := m.clearedFields[name]
+	return ok
+}
+
+// ClearField clears the value of the field with the given name. It returns an
+// error if the field is not defined in the schema.
+func (m *RefreshTokenMutation) ClearField(name string) error {
+	switch name {
+	case refreshtoken.FieldScopes:
+		m.ClearScopes()
+		return nil
+	case refreshtoken.FieldClaimsGroups:
+		m.ClearClaimsGroups()
+		return nil
+	case refreshtoken.FieldConnectorData:
+		m.ClearConnectorData()
+		return nil
+	case refreshtoken.FieldClaimsCustom:
+		m.ClearClaimsCustom()
+		return nil
+	case refreshtoken.FieldClaimsRequest:
+		m.ClearClaimsRequest()
+		return nil
+	case refreshtoken.FieldClaimsSources:
+		m.ClearClaimsSources()
+		return nil
+	}
+	return fmt.Errorf("unknown RefreshToken nullable field %s", name)
+}
+
+// ResetField resets all changes in the mutation for the field with the given name.
+// It returns an error if the field is not defined in the schema.
+func (m *RefreshTokenMutation) ResetField(name string) error {
+	switch name {
+	case refreshtoken.FieldClientID:
+		m.ResetClientID()
+		return nil
+	case refreshtoken.FieldScopes:
+		m.ResetScopes()
+		return nil
+	case refreshtoken.FieldNonce:
+		m.ResetNonce()
+		return nil
+	case refreshtoken.FieldClaimsUserID:
+		m.ResetClaimsUserID()
+		return nil
+	case refreshtoken.FieldClaimsUsername:
+		m.ResetClaimsUsername()
+		return nil
+	case refreshtoken.FieldClaimsEmail:
+		m.ResetClaimsEmail()
+		return nil
+	case refreshtoken.FieldClaimsEmailVerified:
+		m.ResetClaimsEmailVerified()
+		return nil
+	case refreshtoken.FieldClaimsGroups:
+		m.ResetClaimsGroups()
+		return nil
+	case refreshtoken.FieldClaimsPreferredUsername:
+		m.ResetClaimsPreferredUsername()
+		return nil
+	case refreshtoken.FieldConnectorID:
+		m.ResetConnectorID()
+		return nil
+	case refreshtoken.FieldConnectorData:
+		m.ResetConnectorData()
+		return nil
+	case refreshtoken.FieldToken:
+		m.ResetToken()
+		return nil
+	case refreshtoken.FieldObsoleteToken:
+		m.ResetObsoleteToken()
+		return nil
+	case refreshtoken.FieldCreatedAt:
+		m.ResetCreatedAt()
+		return nil
+	case refreshtoken.FieldLastUsed:
+		m.ResetLastUsed()
+		return nil
+	case refreshtoken.FieldClaimsCustom:
+		m.ResetClaimsCustom()
+		return nil
+	case refreshtoken.FieldClaimsRequest:
+		m.ResetClaimsRequest()
+		return nil
+	case refreshtoken.FieldClaimsSources:
+		m.ResetClaimsSources()
+		return nil
+	case refreshtoken.FieldIssuer:
+		m.ResetIssuer()
+		return nil
+	}
+	return fmt.Errorf("unknown RefreshToken field %s", name)
+}
+
+// AddedEdges returns all edge names that were set/added in this mutation.
+func (m *RefreshTokenMutation) AddedEdges() []string {
+	edges := make([]string, 0, 0)
+	return edges
+}
+
+// AddedIDs returns all IDs (to other nodes) that were added for the given edge
+// name in this mutation.
+func (m *RefreshTokenMutation) AddedIDs(name string) []ent.Value {
+	return nil
+}
+
+// RemovedEdges returns all edge names that were removed in this mutation.
+func (m *RefreshTokenMutation) RemovedEdges() []string {
+	edges := make([]string, 0, 0)
+	return edges
+}
+
+// RemovedIDs returns all IDs (to other nodes) that were removed for the edge with
+// the given name in this mutation.
+func (m *RefreshTokenMutation) RemovedIDs(name string) []ent.Value {
+	return nil
+}
+
+// ClearedEdges returns all edge names that were cleared in this mutation.
+func (m *RefreshTokenMutation) ClearedEdges() []string {
+	edges := make([]string, 0, 0)
+	return edges
+}
+
+// EdgeCleared returns a boolean which indicates if the edge with the given name
+// was cleared in this mutation.
+func (m *RefreshTokenMutation) EdgeCleared(name string) bool {
+	return false
+}
+
+// ClearEdge clears the value of the edge with the given name. It returns an error
+// if that edge is not defined in the schema.
+func (m *RefreshTokenMutation) ClearEdge(name string) error {
+	return fmt.Errorf("unknown RefreshToken unique edge %s", name)
+}
+
+// ResetEdge resets all changes to the edge with the given name in this mutation.
+// It returns an error if the edge is not defined in the schema.
+func (m *RefreshTokenMutation) ResetEdge(name string) error {
+	return fmt.Errorf("unknown RefreshToken edge %s", name)
+}
+
+// WebhookMutation represents an operation that mutates the Webhook nodes in the graph.
+type WebhookMutation struct {
+	config
+	op            Op
+	typ           string
+	id            *string
+	secret        *string
+	events        *[]string
+	appendevents  []string
+	url           *string
+	retry_policy  **storage.WebhookRetryPolicy
+	created_at    *time.Time
+	clearedFields map[string]struct{}
+	done          bool
+	oldValue      func(context.Context) (*Webhook, error)
+	predicates    []predicate.Webhook
+}
+
+var _ ent.Mutation = (*WebhookMutation)(nil)
+
+// webhookOption allows management of the mutation configuration using functional options.
+type webhookOption func(*WebhookMutation)
+
+// newWebhookMutation creates new mutation for the Webhook entity.
+func newWebhookMutation(c config, op Op, opts ...webhookOption) *WebhookMutation {
+	m := &WebhookMutation{
+		config:        c,
+		op:            op,
+		typ:           TypeWebhook,
+		clearedFields: make(map[string]struct{}),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// withWebhookID sets the ID field of the mutation.
+func withWebhookID(id string) webhookOption {
+	return func(m *WebhookMutation) {
+		var (
+			err   error
+			once  sync.Once
+			value *Webhook
+		)
+		m.oldValue = func(ctx context.Context) (*Webhook, error) {
+			once.Do(func() {
+				if m.done {
+					err = errors.New("querying old values post mutation is not allowed")
+				} else {
+					value, err = m.Client().Webhook.Get(ctx, id)
+				}
+			})
+			return value, err
+		}
+		m.id = &id
+	}
+}
+
+// withWebhook sets the old Webhook of the mutation.
+func withWebhook(node *Webhook) webhookOption {
+	return func(m *WebhookMutation) {
+		m.oldValue = func(context.Context) (*Webhook, error) {
+			return node, nil
+		}
+		m.id = &node.ID
+	}
+}
+
+// Client returns a new `ent.Client` from the mutation. If the mutation was
+// executed in a transaction (ent.Tx), a transactional client is returned.
+func (m WebhookMutation) Client() *Client {
+	client := &Client{config: m.config}
+	client.init()
+	return client
 }
 
-// SetClaimsPreferredUsername sets the "claims_preferred_username" field.
-func (m *RefreshTokenMutation) SetClaimsPreferredUsername(s string) {
-	m.claims_preferred_username = &s
+// Tx returns an `ent.Tx` for mutations that were executed in transactions;
+// it returns an error otherwise.
+func (m WebhookMutation) Tx() (*Tx, error) {
+	if _, ok := m.driver.(*txDriver); !ok {
+		return nil, errors.New("db: mutation is not running in a transaction")
+	}
+	tx := &Tx{config: m.config}
+	tx.init()
+	return tx, nil
 }
 
-// ClaimsPreferredUsername returns the value of the "claims_preferred_username" field in the mutation.
-func (m *RefreshTokenMutation) ClaimsPreferredUsername() (r string, exists bool) {
-	v := m.claims_preferred_username
-	if v == nil {
-		return
-	}
-	return *v, true
+// SetID sets the value of the id field. Note that this
+// operation is only accepted on creation of Webhook entities.
+func (m *WebhookMutation) SetID(id string) {
+	m.id = &id
 }
 
-// OldClaimsPreferredUsername returns the old "claims_preferred_username" field's value of the RefreshToken entity.
-// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
-// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *RefreshTokenMutation) OldClaimsPreferredUsername(ctx context.Context) (v string, err error) {
-	if !m.op.Is(OpUpdateOne) {
-		return v, errors.New("OldClaimsPreferredUsername is only allowed on UpdateOne operations")
-	}
-	if m.id == nil || m.oldValue == nil {
-		return v, errors.New("OldClaimsPreferredUsername requires an ID field in the mutation")
-	}
-	oldValue, err := m.oldValue(ctx)
-	if err != nil {
-		return v, fmt.Errorf("querying old value for OldClaimsPreferredUsername: %w", err)
+// ID returns the ID value in the mutation. Note that the ID is only available
+// if it was provided to the builder or after it was returned from the database.
+func (m *WebhookMutation) ID() (id string, exists bool) {
+	if m.id == nil {
+		return
 	}
-	return oldValue.ClaimsPreferredUsername, nil
+	return *m.id, true
 }
 
-// ResetClaimsPreferredUsername resets all changes to the "claims_preferred_username" field.
-func (m *RefreshTokenMutation) ResetClaimsPreferredUsername() {
-	m.claims_preferred_username = nil
+// IDs queries the database and returns the entity ids that match the mutation's predicate.
+// That means, if the mutation is applied within a transaction with an isolation level such
+// as sql.LevelSerializable, the returned ids match the ids of the rows that will be updated
+// or updated by the mutation.
+func (m *WebhookMutation) IDs(ctx context.Context) ([]string, error) {
+	switch {
+	case m.op.Is(OpUpdateOne | OpDeleteOne):
+		id, exists := m.ID()
+		if exists {
+			return []string{id}, nil
+		}
+		fallthrough
+	case m.op.Is(OpUpdate | OpDelete):
+		return m.Client().Webhook.Query().Where(m.predicates...).IDs(ctx)
+	default:
+		return nil, fmt.Errorf("IDs is not allowed on %s operations", m.op)
+	}
 }
 
-// SetConnectorID sets the "connector_id" field.
-func (m *RefreshTokenMutation) SetConnectorID(s string) {
-	m.connector_id = &s
+// SetSecret sets the "secret" field.
+func (m *WebhookMutation) SetSecret(s string) {
+	m.secret = &s
 }
 
-// ConnectorID returns the value of the "connector_id" field in the mutation.
-func (m *RefreshTokenMutation) ConnectorID() (r string, exists bool) {
-	v := m.connector_id
+// Secret returns the value of the "secret" field in the mutation.
+func (m *WebhookMutation) Secret() (r string, exists bool) {
+	v := m.secret
 	if v == nil {
 		return
 	}
 	return *v, true
 }
 
-// OldConnectorID returns the old "connector_id" field's value of the RefreshToken entity.
-// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
+// OldSecret returns the old "secret" field's value of the Webhook entity.
+// If the Webhook object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *RefreshTokenMutation) OldConnectorID(ctx context.Context) (v string, err error) {
+func (m *WebhookMutation) OldSecret(ctx context.Context) (v string, err error) {
 	if !m.op.Is(OpUpdateOne) {
-		return v, errors.New("OldConnectorID is only allowed on UpdateOne operations")
+		return v, errors.New("OldSecret is only allowed on UpdateOne operations")
 	}
 	if m.id == nil || m.oldValue == nil {
-		return v, errors.New("OldConnectorID requires an ID field in the mutation")
+		return v, errors.New("OldSecret requires an ID field in the mutation")
 	}
 	oldValue, err := m.oldValue(ctx)
 	if err != nil {
-		return v, fmt.Errorf("querying old value for OldConnectorID: %w", err)
+		return v, fmt.Errorf("querying old value for OldSecret: %w", err)
 	}
-	return oldValue.ConnectorID, nil
+	return oldValue.Secret, nil
 }
 
-// ResetConnectorID resets all changes to the "connector_id" field.
-func (m *RefreshTokenMutation) ResetConnectorID() {
-	m.connector_id = nil
+// ResetSecret resets all changes to the "secret" field.
+func (m *WebhookMutation) ResetSecret() {
+	m.secret = nil
 }
 
-// SetConnectorData sets the "connector_data" field.
-func (m *RefreshTokenMutation) SetConnectorData(b []byte) {
-	m.connector_data = &b
+// SetEvents sets the "events" field.
+func (m *WebhookMutation) SetEvents(s []string) {
+	m.events = &s
+	m.appendevents = nil
 }
 
-// ConnectorData returns the value of the "connector_data" field in the mutation.
-func (m *RefreshTokenMutation) ConnectorData() (r []byte, exists bool) {
-	v := m.connector_data
+// Events returns the value of the "events" field in the mutation.
+func (m *WebhookMutation) Events() (r []string, exists bool) {
+	v := m.events
 	if v == nil {
 		return
 	}
 	return *v, true
 }
 
-// OldConnectorData returns the old "connector_data" field's value of the RefreshToken entity.
-// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
+// OldEvents returns the old "events" field's value of the Webhook entity.
+// If the Webhook object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *RefreshTokenMutation) OldConnectorData(ctx context.Context) (v *[]byte, err error) {
+func (m *WebhookMutation) OldEvents(ctx context.Context) (v []string, err error) {
 	if !m.op.Is(OpUpdateOne) {
-		return v, errors.New("OldConnectorData is only allowed on UpdateOne operations")
+		return v, errors.New("OldEvents is only allowed on UpdateOne operations")
 	}
 	if m.id == nil || m.oldValue == nil {
-		return v, errors.New("OldConnectorData requires an ID field in the mutation")
+		return v, errors.New("OldEvents requires an ID field in the mutation")
 	}
 	oldValue, err := m.oldValue(ctx)
 	if err != nil {
-		return v, fmt.Errorf("querying old value for OldConnectorData: %w", err)
+		return v, fmt.Errorf("querying old value for OldEvents: %w", err)
 	}
-	return oldValue.ConnectorData, nil
+	return oldValue.Events, nil
 }
 
-// ClearConnectorData clears the value of the "connector_data" field.
-func (m *RefreshTokenMutation) ClearConnectorData() {
-	m.connector_data = nil
-	m.clearedFields[refreshtoken.FieldConnectorData] = struct{}{}
+// AppendEvents adds s to the "events" field.
+func (m *WebhookMutation) AppendEvents(s []string) {
+	m.appendevents = append(m.appendevents, s...)
 }
 
-// ConnectorDataCleared returns if the "connector_data" field was cleared in this mutation.
-func (m *RefreshTokenMutation) ConnectorDataCleared() bool {
-	_, ok := m.clearedFields[refreshtoken.FieldConnectorData]
+// AppendedEvents returns the list of values that were appended to the "events" field in this mutation.
+func (m *WebhookMutation) AppendedEvents() ([]string, bool) {
+	if len(m.appendevents) == 0 {
+		return nil, false
+	}
+	return m.appendevents, true
+}
+
+// ClearEvents clears the value of the "events" field.
+func (m *WebhookMutation) ClearEvents() {
+	m.events = nil
+	m.appendevents = nil
+	m.clearedFields[webhook.FieldEvents] = struct{}{}
+}
+
+// EventsCleared returns if the "events" field was cleared in this mutation.
+func (m *WebhookMutation) EventsCleared() bool {
+	_, ok := m.clearedFields[webhook.FieldEvents]
 	return ok
 }
 
-// ResetConnectorData resets all changes to the "connector_data" field.
-func (m *RefreshTokenMutation) ResetConnectorData() {
-	m.connector_data = nil
-	delete(m.clearedFields, refreshtoken.FieldConnectorData)
+// ResetEvents resets all changes to the "events" field.
+func (m *WebhookMutation) ResetEvents() {
+	m.events = nil
+	m.appendevents = nil
+	delete(m.clearedFields, webhook.FieldEvents)
 }
 
-// SetToken sets the "token" field.
-func (m *RefreshTokenMutation) SetToken(s string) {
-	m.token = &s
+// SetURL sets the "url" field.
+func (m *WebhookMutation) SetURL(s string) {
+	m.url = &s
 }
 
-// Token returns the value of the "token" field in the mutation.
-func (m *RefreshTokenMutation) Token() (r string, exists bool) {
-	v := m.token
+// URL returns the value of the "url" field in the mutation.
+func (m *WebhookMutation) URL() (r string, exists bool) {
+	v := m.url
 	if v == nil {
 		return
 	}
 	return *v, true
 }
 
-// OldToken returns the old "token" field's value of the RefreshToken entity.
-// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
+// OldURL returns the old "url" field's value of the Webhook entity.
+// If the Webhook object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *RefreshTokenMutation) OldToken(ctx context.Context) (v string, err error) {
+func (m *WebhookMutation) OldURL(ctx context.Context) (v string, err error) {
 	if !m.op.Is(OpUpdateOne) {
-		return v, errors.New("OldToken is only allowed on UpdateOne operations")
+		return v, errors.New("OldURL is only allowed on UpdateOne operations")
 	}
 	if m.id == nil || m.oldValue == nil {
-		return v, errors.New("OldToken requires an ID field in the mutation")
+		return v, errors.New("OldURL requires an ID field in the mutation")
 	}
 	oldValue, err := m.oldValue(ctx)
 	if err != nil {
-		return v, fmt.Errorf("querying old value for OldToken: %w", err)
+		return v, fmt.Errorf("querying old value for OldURL: %w", err)
 	}
-	return oldValue.Token, nil
+	return oldValue.URL, nil
 }
 
-// ResetToken resets all changes to the "token" field.
-func (m *RefreshTokenMutation) ResetToken() {
-	m.token = nil
+// ResetURL resets all changes to the "url" field.
+func (m *WebhookMutation) ResetURL() {
+	m.url = nil
 }
 
-// SetObsoleteToken sets the "obsolete_token" field.
-func (m *RefreshTokenMutation) SetObsoleteToken(s string) {
-	m.obsolete_token = &s
+// SetRetryPolicy sets the "retry_policy" field.
+func (m *WebhookMutation) SetRetryPolicy(srp *storage.WebhookRetryPolicy) {
+	m.retry_policy = &srp
 }
 
-// ObsoleteToken returns the value of the "obsolete_token" field in the mutation.
-func (m *RefreshTokenMutation) ObsoleteToken() (r string, exists bool) {
-	v := m.obsolete_token
+// RetryPolicy returns the value of the "retry_policy" field in the mutation.
+func (m *WebhookMutation) RetryPolicy() (r *storage.WebhookRetryPolicy, exists bool) {
+	v := m.retry_policy
 	if v == nil {
 		return
 	}
 	return *v, true
 }
 
-// OldObsoleteToken returns the old "obsolete_token" field's value of the RefreshToken entity.
-// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
+// OldRetryPolicy returns the old "retry_policy" field's value of the Webhook entity.
+// If the Webhook object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *RefreshTokenMutation) OldObsoleteToken(ctx context.Context) (v string, err error) {
+func (m *WebhookMutation) OldRetryPolicy(ctx context.Context) (v *storage.WebhookRetryPolicy, err error) {
 	if !m.op.Is(OpUpdateOne) {
-		return v, errors.New("OldObsoleteToken is only allowed on UpdateOne operations")
+		return v, errors.New("OldRetryPolicy is only allowed on UpdateOne operations")
 	}
 	if m.id == nil || m.oldValue == nil {
-		return v, errors.New("OldObsoleteToken requires an ID field in the mutation")
+		return v, errors.New("OldRetryPolicy requires an ID field in the mutation")
 	}
 	oldValue, err := m.oldValue(ctx)
 	if err != nil {
-		return v, fmt.Errorf("querying old value for OldObsoleteToken: %w", err)
+		return v, fmt.Errorf("querying old value for OldRetryPolicy: %w", err)
 	}
-	return oldValue.ObsoleteToken, nil
+	return oldValue.RetryPolicy, nil
 }
 
-// ResetObsoleteToken resets all changes to the "obsolete_token" field.
-func (m *RefreshTokenMutation) ResetObsoleteToken() {
-	m.obsolete_token = nil
+// ClearRetryPolicy clears the value of the "retry_policy" field.
+func (m *WebhookMutation) ClearRetryPolicy() {
+	m.retry_policy = nil
+	m.clearedFields[webhook.FieldRetryPolicy] = struct{}{}
+}
+
+// RetryPolicyCleared returns if the "retry_policy" field was cleared in this mutation.
+func (m *WebhookMutation) RetryPolicyCleared() bool {
+	_, ok := m.clearedFields[webhook.FieldRetryPolicy]
+	return ok
+}
+
+// ResetRetryPolicy resets all changes to the "retry_policy" field.
+func (m *WebhookMutation) ResetRetryPolicy() {
+	m.retry_policy = nil
+	delete(m.clearedFields, webhook.FieldRetryPolicy)
 }
 
 // SetCreatedAt sets the "created_at" field.
-func (m *RefreshTokenMutation) SetCreatedAt(t time.Time) {
+func (m *WebhookMutation) SetCreatedAt(t time.Time) {
 	m.created_at = &t
 }
 
 // CreatedAt returns the value of the "created_at" field in the mutation.
-func (m *RefreshTokenMutation) CreatedAt() (r time.Time, exists bool) {
+func (m *WebhookMutation) CreatedAt() (r time.Time, exists bool) {
 	v := m.created_at
 	if v == nil {
 		return
@@ -7485,10 +10788,10 @@ func (m *RefreshTokenMutation) CreatedAt() (r time.Time, exists bool) {
 	return *v, true
 }
 
-// OldCreatedAt returns the old "created_at" field's value of the RefreshToken entity.
-// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
+// OldCreatedAt returns the old "created_at" field's value of the Webhook entity.
+// If the Webhook object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *RefreshTokenMutation) OldCreatedAt(ctx context.Context) (v time.Time, err error) {
+func (m *WebhookMutation) OldCreatedAt(ctx context.Context) (v time.Time, err error) {
 	if !m.op.Is(OpUpdateOne) {
 		return v, errors.New("OldCreatedAt is only allowed on UpdateOne operations")
 	}
@@ -7502,126 +10805,73 @@ func (m *RefreshTokenMutation) OldCreatedAt(ctx context.Context) (v time.Time, e
 	return oldValue.CreatedAt, nil
 }
 
-// ResetCreatedAt resets all changes to the "created_at" field.
-func (m *RefreshTokenMutation) ResetCreatedAt() {
+// ClearCreatedAt clears the value of the "created_at" field.
+func (m *WebhookMutation) ClearCreatedAt() {
 	m.created_at = nil
+	m.clearedFields[webhook.FieldCreatedAt] = struct{}{}
 }
 
-// SetLastUsed sets the "last_used" field.
-func (m *RefreshTokenMutation) SetLastUsed(t time.Time) {
-	m.last_used = &t
-}
-
-// LastUsed returns the value of the "last_used" field in the mutation.
-func (m *RefreshTokenMutation) LastUsed() (r time.Time, exists bool) {
-	v := m.last_used
-	if v == nil {
-		return
-	}
-	return *v, true
-}
-
-// OldLastUsed returns the old "last_used" field's value of the RefreshToken entity.
-// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
-// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *RefreshTokenMutation) OldLastUsed(ctx context.Context) (v time.Time, err error) {
-	if !m.op.Is(OpUpdateOne) {
-		return v, errors.New("OldLastUsed is only allowed on UpdateOne operations")
-	}
-	if m.id == nil || m.oldValue == nil {
-		return v, errors.New("OldLastUsed requires an ID field in the mutation")
-	}
-	oldValue, err := m.oldValue(ctx)
-	if err != nil {
-		return v, fmt.Errorf("querying old value for OldLastUsed: %w", err)
-	}
-	return oldValue.LastUsed, nil
-}
-
-// ResetLastUsed resets all changes to the "last_used" field.
-func (m *RefreshTokenMutation) ResetLastUsed() {
-	m.last_used = nil
-}
-
-// Where appends a list predicates to the RefreshTokenMutation builder.
-func (m *RefreshTokenMutation) Where(ps ...predicate.RefreshToken) {
-	m.predicates = append(m.predicates, ps...)
-}
-
-// WhereP appends storage-level predicates to the RefreshTokenMutation builder. Using this method,
-// users can use type-assertion to append predicates that do not depend on any generated package.
-func (m *RefreshTokenMutation) WhereP(ps ...func(*sql.Selector)) {
-	p := make([]predicate.RefreshToken, len(ps))
-	for i := range ps {
-		p[i] = ps[i]
-	}
-	m.Where(p...)
-}
-
-// Op returns the operation name.
-func (m *RefreshTokenMutation) Op() Op {
-	return m.op
-}
-
-// SetOp allows setting the mutation operation.
-func (m *RefreshTokenMutation) SetOp(op Op) {
-	m.op = op
+// CreatedAtCleared returns if the "created_at" field was cleared in this mutation.
+func (m *WebhookMutation) CreatedAtCleared() bool {
+	_, ok := m.clearedFields[webhook.FieldCreatedAt]
+	return ok
 }
 
-// Type returns the node type of this mutation (RefreshToken).
-func (m *RefreshTokenMutation) Type() string {
-	return m.typ
+// ResetCreatedAt resets all changes to the "created_at" field.
+func (m *WebhookMutation) ResetCreatedAt() {
+	m.created_at = nil
+	delete(m.clearedFields, webhook.FieldCreatedAt)
 }
-
-// Fields returns all fields that were changed during this mutation. Note that in
-// order to get all numeric fields that were incremented/decremented, call
-// AddedFields().
-func (m *RefreshTokenMutation) Fields() []string {
-	fields := make([]string, 0, 15)
-	if m.client_id != nil {
-		fields = append(fields, refreshtoken.FieldClientID)
-	}
-	if m.scopes != nil {
-		fields = append(fields, refreshtoken.FieldScopes)
-	}
-	if m.nonce != nil {
-		fields = append(fields, refreshtoken.FieldNonce)
-	}
-	if m.claims_user_id != nil {
-		fields = append(fields, refreshtoken.FieldClaimsUserID)
-	}
-	if m.claims_username != nil {
-		fields = append(fields, refreshtoken.FieldClaimsUsername)
-	}
-	if m.claims_email != nil {
-		fields = append(fields, refreshtoken.FieldClaimsEmail)
-	}
-	if m.claims_email_verified != nil {
-		fields = append(fields, refreshtoken.FieldClaimsEmailVerified)
-	}
-	if m.claims_groups != nil {
-		fields = append(fields, refreshtoken.FieldClaimsGroups)
-	}
-	if m.claims_preferred_username != nil {
-		fields = append(fields, refreshtoken.FieldClaimsPreferredUsername)
+
+// Where appends a list predicates to the WebhookMutation builder.
+func (m *WebhookMutation) Where(ps ...predicate.Webhook) {
+	m.predicates = append(m.predicates, ps...)
+}
+
+// WhereP appends storage-level predicates to the WebhookMutation builder. Using this method,
+// users can use type-assertion to append predicates that do not depend on any generated package.
+func (m *WebhookMutation) WhereP(ps ...func(*sql.Selector)) {
+	p := make([]predicate.Webhook, len(ps))
+	for i := range ps {
+		p[i] = ps[i]
 	}
-	if m.connector_id != nil {
-		fields = append(fields, refreshtoken.FieldConnectorID)
+	m.Where(p...)
+}
+
+// Op returns the operation name.
+func (m *WebhookMutation) Op() Op {
+	return m.op
+}
+
+// SetOp allows setting the mutation operation.
+func (m *WebhookMutation) SetOp(op Op) {
+	m.op = op
+}
+
+// Type returns the node type of this mutation (Webhook).
+func (m *WebhookMutation) Type() string {
+	return m.typ
+}
+
+// Fields returns all fields that were changed during this mutation. Note that in
+// order to get all numeric fields that were incremented/decremented, call
+// AddedFields().
+func (m *WebhookMutation) Fields() []string {
+	fields := make([]string, 0, 6)
+	if m.secret != nil {
+		fields = append(fields, webhook.FieldSecret)
 	}
-	if m.connector_data != nil {
-		fields = append(fields, refreshtoken.FieldConnectorData)
+	if m.events != nil {
+		fields = append(fields, webhook.FieldEvents)
 	}
-	if m.token != nil {
-		fields = append(fields, refreshtoken.FieldToken)
+	if m.url != nil {
+		fields = append(fields, webhook.FieldURL)
 	}
-	if m.obsolete_token != nil {
-		fields = append(fields, refreshtoken.FieldObsoleteToken)
+	if m.retry_policy != nil {
+		fields = append(fields, webhook.FieldRetryPolicy)
 	}
 	if m.created_at != nil {
-		fields = append(fields, refreshtoken.FieldCreatedAt)
-	}
-	if m.last_used != nil {
-		fields = append(fields, refreshtoken.FieldLastUsed)
+		fields = append(fields, webhook.FieldCreatedAt)
 	}
 	return fields
 }
@@ -7629,38 +10879,18 @@ func (m *RefreshTokenMutation) Fields() []string {
 // Field returns the value of a field with the given name. The second boolean
 // return value indicates that this field was not set, or was not defined in the
 // schema.
-func (m *RefreshTokenMutation) Field(name string) (ent.Value, bool) {
+func (m *WebhookMutation) Field(name string) (ent.Value, bool) {
 	switch name {
-	case refreshtoken.FieldClientID:
-		return m.ClientID()
-	case refreshtoken.FieldScopes:
-		return m.Scopes()
-	case refreshtoken.FieldNonce:
-		return m.Nonce()
-	case refreshtoken.FieldClaimsUserID:
-		return m.ClaimsUserID()
-	case refreshtoken.FieldClaimsUsername:
-		return m.ClaimsUsername()
-	case refreshtoken.FieldClaimsEmail:
-		return m.ClaimsEmail()
-	case refreshtoken.FieldClaimsEmailVerified:
-		return m.ClaimsEmailVerified()
-	case refreshtoken.FieldClaimsGroups:
-		return m.ClaimsGroups()
-	case refreshtoken.FieldClaimsPreferredUsername:
-		return m.ClaimsPreferredUsername()
-	case refreshtoken.FieldConnectorID:
-		return m.ConnectorID()
-	case refreshtoken.FieldConnectorData:
-		return m.ConnectorData()
-	case refreshtoken.FieldToken:
-		return m.Token()
-	case refreshtoken.FieldObsoleteToken:
-		return m.ObsoleteToken()
-	case refreshtoken.FieldCreatedAt:
+	case webhook.FieldSecret:
+		return m.Secret()
+	case webhook.FieldEvents:
+		return m.Events()
+	case webhook.FieldURL:
+		return m.URL()
+	case webhook.FieldRetryPolicy:
+		return m.RetryPolicy()
+	case webhook.FieldCreatedAt:
 		return m.CreatedAt()
-	case refreshtoken.FieldLastUsed:
-		return m.LastUsed()
 	}
 	return nil, false
 }
@@ -7668,315 +10898,195 @@ func (m *RefreshTokenMutation) Field(name string) (ent.Value, bool) {
 // OldField returns the old value of the field from the database. An error is
 // returned if the mutation operation is not UpdateOne, or the query to the
 // database failed.
-func (m *RefreshTokenMutation) OldField(ctx context.Context, name string) (ent.Value, error) {
+func (m *WebhookMutation) OldField(ctx context.Context, name string) (ent.Value, error) {
 	switch name {
-	case refreshtoken.FieldClientID:
-		return m.OldClientID(ctx)
-	case refreshtoken.FieldScopes:
-		return m.OldScopes(ctx)
-	case refreshtoken.FieldNonce:
-		return m.OldNonce(ctx)
-	case refreshtoken.FieldClaimsUserID:
-		return m.OldClaimsUserID(ctx)
-	case refreshtoken.FieldClaimsUsername:
-		return m.OldClaimsUsername(ctx)
-	case refreshtoken.FieldClaimsEmail:
-		return m.OldClaimsEmail(ctx)
-	case refreshtoken.FieldClaimsEmailVerified:
-		return m.OldClaimsEmailVerified(ctx)
-	case refreshtoken.FieldClaimsGroups:
-		return m.OldClaimsGroups(ctx)
-	case refreshtoken.FieldClaimsPreferredUsername:
-		return m.OldClaimsPreferredUsername(ctx)
-	case refreshtoken.FieldConnectorID:
-		return m.OldConnectorID(ctx)
-	case refreshtoken.FieldConnectorData:
-		return m.OldConnectorData(ctx)
-	case refreshtoken.FieldToken:
-		return m.OldToken(ctx)
-	case refreshtoken.FieldObsoleteToken:
-		return m.OldObsoleteToken(ctx)
-	case refreshtoken.FieldCreatedAt:
+	case webhook.FieldSecret:
+		return m.OldSecret(ctx)
+	case webhook.FieldEvents:
+		return m.OldEvents(ctx)
+	case webhook.FieldURL:
+		return m.OldURL(ctx)
+	case webhook.FieldRetryPolicy:
+		return m.OldRetryPolicy(ctx)
+	case webhook.FieldCreatedAt:
 		return m.OldCreatedAt(ctx)
-	case refreshtoken.FieldLastUsed:
-		return m.OldLastUsed(ctx)
 	}
-	return nil, fmt.Errorf("unknown RefreshToken field %s", name)
+	return nil, fmt.Errorf("unknown Webhook field %s", name)
 }
 
 // SetField sets the value of a field with the given name. It returns an error if
 // the field is not defined in the schema, or if the type mismatched the field
 // type.
-func (m *RefreshTokenMutation) SetField(name string, value ent.Value) error {
+func (m *WebhookMutation) SetField(name string, value ent.Value) error {
 	switch name {
-	case refreshtoken.FieldClientID:
-		v, ok := value.(string)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetClientID(v)
-		return nil
-	case refreshtoken.FieldScopes:
-		v, ok := value.([]string)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetScopes(v)
-		return nil
-	case refreshtoken.FieldNonce:
-		v, ok := value.(string)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetNonce(v)
-		return nil
-	case refreshtoken.FieldClaimsUserID:
-		v, ok := value.(string)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetClaimsUserID(v)
-		return nil
-	case refreshtoken.FieldClaimsUsername:
-		v, ok := value.(string)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetClaimsUsername(v)
-		return nil
-	case refreshtoken.FieldClaimsEmail:
+	case webhook.FieldSecret:
 		v, ok := value.(string)
 		if !ok {
 			return fmt.Errorf("unexpected type %T for field %s", value, name)
 		}
-		m.SetClaimsEmail(v)
-		return nil
-	case refreshtoken.FieldClaimsEmailVerified:
-		v, ok := value.(bool)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetClaimsEmailVerified(v)
+		m.SetSecret(v)
 		return nil
-	case refreshtoken.FieldClaimsGroups:
+	case webhook.FieldEvents:
 		v, ok := value.([]string)
 		if !ok {
 			return fmt.Errorf("unexpected type %T for field %s", value, name)
 		}
-		m.SetClaimsGroups(v)
-		return nil
-	case refreshtoken.FieldClaimsPreferredUsername:
-		v, ok := value.(string)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetClaimsPreferredUsername(v)
-		return nil
-	case refreshtoken.FieldConnectorID:
-		v, ok := value.(string)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetConnectorID(v)
-		return nil
-	case refreshtoken.FieldConnectorData:
-		v, ok := value.([]byte)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetConnectorData(v)
+		m.SetEvents(v)
 		return nil
-	case refreshtoken.FieldToken:
+	case webhook.FieldURL:
 		v, ok := value.(string)
 		if !ok {
 			return fmt.Errorf("unexpected type %T for field %s", value, name)
 		}
-		m.SetToken(v)
+		m.SetURL(v)
 		return nil
-	case refreshtoken.FieldObsoleteToken:
-		v, ok := value.(string)
+	case webhook.FieldRetryPolicy:
+		v, ok := value.(*storage.WebhookRetryPolicy)
 		if !ok {
 			return fmt.Errorf("unexpected type %T for field %s", value, name)
 		}
-		m.SetObsoleteToken(v)
+		m.SetRetryPolicy(v)
 		return nil
-	case refreshtoken.FieldCreatedAt:
+	case webhook.FieldCreatedAt:
 		v, ok := value.(time.Time)
 		if !ok {
 			return fmt.Errorf("unexpected type %T for field %s", value, name)
 		}
 		m.SetCreatedAt(v)
 		return nil
-	case refreshtoken.FieldLastUsed:
-		v, ok := value.(time.Time)
-		if !ok {
-			return fmt.Errorf("unexpected type %T for field %s", value, name)
-		}
-		m.SetLastUsed(v)
-		return nil
 	}
-	return fmt.Errorf("unknown RefreshToken field %s", name)
+	return fmt.Errorf("unknown Webhook field %s", name)
 }
 
 // AddedFields returns all numeric fields that were incremented/decremented during
 // this mutation.
-func (m *RefreshTokenMutation) AddedFields() []string {
+func (m *WebhookMutation) AddedFields() []string {
 	return nil
 }
 
 // AddedField returns the numeric value that was incremented/decremented on a field
 // with the given name. The second boolean return value indicates that this field
 // was not set, or was not defined in the schema.
-func (m *RefreshTokenMutation) AddedField(name string) (ent.Value, bool) {
+func (m *WebhookMutation) AddedField(name string) (ent.Value, bool) {
 	return nil, false
 }
 
 // AddField adds the value to the field with the given name. It returns an error if
 // the field is not defined in the schema, or if the type mismatched the field
 // type.
-func (m *RefreshTokenMutation) AddField(name string, value ent.Value) error {
+func (m *WebhookMutation) AddField(name string, value ent.Value) error {
 	switch name {
 	}
-	return fmt.Errorf("unknown RefreshToken numeric field %s", name)
+	return fmt.Errorf("unknown Webhook numeric field %s", name)
 }
 
 // ClearedFields returns all nullable fields that were cleared during this
 // mutation.
-func (m *RefreshTokenMutation) ClearedFields() []string {
+func (m *WebhookMutation) ClearedFields() []string {
 	var fields []string
-	if m.FieldCleared(refreshtoken.FieldScopes) {
-		fields = append(fields, refreshtoken.FieldScopes)
+	if m.FieldCleared(webhook.FieldEvents) {
+		fields = append(fields, webhook.FieldEvents)
 	}
-	if m.FieldCleared(refreshtoken.FieldClaimsGroups) {
-		fields = append(fields, refreshtoken.FieldClaimsGroups)
+	if m.FieldCleared(webhook.FieldRetryPolicy) {
+		fields = append(fields, webhook.FieldRetryPolicy)
 	}
-	if m.FieldCleared(refreshtoken.FieldConnectorData) {
-		fields = append(fields, refreshtoken.FieldConnectorData)
+	if m.FieldCleared(webhook.FieldCreatedAt) {
+		fields = append(fields, webhook.FieldCreatedAt)
 	}
 	return fields
 }
 
 // FieldCleared returns a boolean indicating if a field with the given name was
 // cleared in this mutation.
-func (m *RefreshTokenMutation) FieldCleared(name string) bool {
+func (m *WebhookMutation) FieldCleared(name string) bool {
 	_, ok := m.clearedFields[name]
 	return ok
 }
 
 // ClearField clears the value of the field with the given name. It returns an
 // error if the field is not defined in the schema.
-func (m *RefreshTokenMutation) ClearField(name string) error {
+func (m *WebhookMutation) ClearField(name string) error {
 	switch name {
-	case refreshtoken.FieldScopes:
-		m.ClearScopes()
+	case webhook.FieldEvents:
+		m.ClearEvents()
 		return nil
-	case refreshtoken.FieldClaimsGroups:
-		m.ClearClaimsGroups()
+	case webhook.FieldRetryPolicy:
+		m.ClearRetryPolicy()
 		return nil
-	case refreshtoken.FieldConnectorData:
-		m.ClearConnectorData()
+	case webhook.FieldCreatedAt:
+		m.ClearCreatedAt()
 		return nil
 	}
-	return fmt.Errorf("unknown RefreshToken nullable field %s", name)
+	return fmt.Errorf("unknown Webhook nullable field %s", name)
 }
 
 // ResetField resets all changes in the mutation for the field with the given name.
 // It returns an error if the field is not defined in the schema.
-func (m *RefreshTokenMutation) ResetField(name string) error {
+func (m *WebhookMutation) ResetField(name string) error {
 	switch name {
-	case refreshtoken.FieldClientID:
-		m.ResetClientID()
-		return nil
-	case refreshtoken.FieldScopes:
-		m.ResetScopes()
-		return nil
-	case refreshtoken.FieldNonce:
-		m.ResetNonce()
-		return nil
-	case refreshtoken.FieldClaimsUserID:
-		m.ResetClaimsUserID()
-		return nil
-	case refreshtoken.FieldClaimsUsername:
-		m.ResetClaimsUsername()
-		return nil
-	case refreshtoken.FieldClaimsEmail:
-		m.ResetClaimsEmail()
-		return nil
-	case refreshtoken.FieldClaimsEmailVerified:
-		m.ResetClaimsEmailVerified()
-		return nil
-	case refreshtoken.FieldClaimsGroups:
-		m.ResetClaimsGroups()
-		return nil
-	case refreshtoken.FieldClaimsPreferredUsername:
-		m.ResetClaimsPreferredUsername()
-		return nil
-	case refreshtoken.FieldConnectorID:
-		m.ResetConnectorID()
+	case webhook.FieldSecret:
+		m.ResetSecret()
 		return nil
-	case refreshtoken.FieldConnectorData:
-		m.ResetConnectorData()
+	case webhook.FieldEvents:
+		m.ResetEvents()
 		return nil
-	case refreshtoken.FieldToken:
-		m.ResetToken()
+	case webhook.FieldURL:
+		m.ResetURL()
 		return nil
-	case refreshtoken.FieldObsoleteToken:
-		m.ResetObsoleteToken()
+	case webhook.FieldRetryPolicy:
+		m.ResetRetryPolicy()
 		return nil
-	case refreshtoken.FieldCreatedAt:
+	case webhook.FieldCreatedAt:
 		m.ResetCreatedAt()
 		return nil
-	case refreshtoken.FieldLastUsed:
-		m.ResetLastUsed()
-		return nil
 	}
-	return fmt.Errorf("unknown RefreshToken field %s", name)
+	return fmt.Errorf("unknown Webhook field %s", name)
 }
 
 // AddedEdges returns all edge names that were set/added in this mutation.
-func (m *RefreshTokenMutation) AddedEdges() []string {
+func (m *WebhookMutation) AddedEdges() []string {
 	edges := make([]string, 0, 0)
 	return edges
 }
 
 // AddedIDs returns all IDs (to other nodes) that were added for the given edge
 // name in this mutation.
-func (m *RefreshTokenMutation) AddedIDs(name string) []ent.Value {
+func (m *WebhookMutation) AddedIDs(name string) []ent.Value {
 	return nil
 }
 
 // RemovedEdges returns all edge names that were removed in this mutation.
-func (m *RefreshTokenMutation) RemovedEdges() []string {
+func (m *WebhookMutation) RemovedEdges() []string {
 	edges := make([]string, 0, 0)
 	return edges
 }
 
 // RemovedIDs returns all IDs (to other nodes) that were removed for the edge with
 // the given name in this mutation.
-func (m *RefreshTokenMutation) RemovedIDs(name string) []ent.Value {
+func (m *WebhookMutation) RemovedIDs(name string) []ent.Value {
 	return nil
 }
 
 // ClearedEdges returns all edge names that were cleared in this mutation.
-func (m *RefreshTokenMutation) ClearedEdges() []string {
+func (m *WebhookMutation) ClearedEdges() []string {
 	edges := make([]string, 0, 0)
 	return edges
 }
 
 // EdgeCleared returns a boolean which indicates if the edge with the given name
 // was cleared in this mutation.
-func (m *RefreshTokenMutation) EdgeCleared(name string) bool {
+func (m *WebhookMutation) EdgeCleared(name string) bool {
 	return false
 }
 
 // ClearEdge clears the value of the edge with the given name. It returns an error
 // if that edge is not defined in the schema.
-func (m *RefreshTokenMutation) ClearEdge(name string) error {
-	return fmt.Errorf("unknown RefreshToken unique edge %s", name)
+func (m *WebhookMutation) ClearEdge(name string) error {
+	return fmt.Errorf("unknown Webhook unique edge %s", name)
 }
 
 // ResetEdge resets all changes to the edge with the given name in this mutation.
 // It returns an error if the edge is not defined in the schema.
-func (m *RefreshTokenMutation) ResetEdge(name string) error {
-	return fmt.Errorf("unknown RefreshToken edge %s", name)
+func (m *WebhookMutation) ResetEdge(name string) error {
+	return fmt.Errorf("unknown Webhook edge %s", name)
 }
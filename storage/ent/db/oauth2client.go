@@ -6,9 +6,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"entgo.io/ent"
 	"entgo.io/ent/dialect/sql"
+	"github.com/dexidp/dex/storage"
 	"github.com/dexidp/dex/storage/ent/db/oauth2client"
 )
 
@@ -28,8 +30,38 @@ type OAuth2Client struct {
 	// Name holds the value of the "name" field.
 	Name string `json:"name,omitempty"`
 	// LogoURL holds the value of the "logo_url" field.
-	LogoURL      string `json:"logo_url,omitempty"`
-	selectValues sql.SelectValues
+	LogoURL string `json:"logo_url,omitempty"`
+	// RefreshTokenPolicy holds the value of the "refresh_token_policy" field.
+	RefreshTokenPolicy *storage.RefreshTokenPolicy `json:"refresh_token_policy,omitempty"`
+	// TokenExpiry holds the value of the "token_expiry" field.
+	TokenExpiry *storage.TokenExpiry `json:"token_expiry,omitempty"`
+	// GroupsFilter holds the value of the "groups_filter" field.
+	GroupsFilter *storage.GroupsFilter `json:"groups_filter,omitempty"`
+	// ClaimsTransforms holds the value of the "claims_transforms" field.
+	ClaimsTransforms []storage.ClaimsTransform `json:"claims_transforms,omitempty"`
+	// RedirectURIPatterns holds the value of the "redirect_uri_patterns" field.
+	RedirectURIPatterns []string `json:"redirect_uri_patterns,omitempty"`
+	// PreviousSecret holds the value of the "previous_secret" field.
+	PreviousSecret *storage.PreviousSecret `json:"previous_secret,omitempty"`
+	// CreatedAt holds the value of the "created_at" field.
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	// LastUsed holds the value of the "last_used" field.
+	LastUsed time.Time `json:"last_used,omitempty"`
+	// CreatedBy holds the value of the "created_by" field.
+	CreatedBy string `json:"created_by,omitempty"`
+	// UpdatedAt holds the value of the "updated_at" field.
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+	// DeletedAt holds the value of the "deleted_at" field.
+	DeletedAt time.Time `json:"deleted_at,omitempty"`
+	// PolicyURL holds the value of the "policy_url" field.
+	PolicyURL string `json:"policy_url,omitempty"`
+	// TermsOfServiceURL holds the value of the "terms_of_service_url" field.
+	TermsOfServiceURL string `json:"terms_of_service_url,omitempty"`
+	// AllowedOrigins holds the value of the "allowed_origins" field.
+	AllowedOrigins []string `json:"allowed_origins,omitempty"`
+	// ForwardClaimSourceTokens holds the value of the "forward_claim_source_tokens" field.
+	ForwardClaimSourceTokens bool `json:"forward_claim_source_tokens,omitempty"`
+	selectValues             sql.SelectValues
 }
 
 // scanValues returns the types for scanning values from sql.Rows.
@@ -37,11 +69,13 @@ func (*OAuth2Client) scanValues(columns []string) ([]any, error) {
 	values := make([]any, len(columns))
 	for i := range columns {
 		switch columns[i] {
-		case oauth2client.FieldRedirectUris, oauth2client.FieldTrustedPeers:
+		case oauth2client.FieldRedirectUris, oauth2client.FieldTrustedPeers, oauth2client.FieldRefreshTokenPolicy, oauth2client.FieldTokenExpiry, oauth2client.FieldGroupsFilter, oauth2client.FieldClaimsTransforms, oauth2client.FieldRedirectURIPatterns, oauth2client.FieldPreviousSecret, oauth2client.FieldAllowedOrigins:
 			values[i] = new([]byte)
-		case oauth2client.FieldPublic:
+		case oauth2client.FieldPublic, oauth2client.FieldForwardClaimSourceTokens:
 			values[i] = new(sql.NullBool)
-		case oauth2client.FieldID, oauth2client.FieldSecret, oauth2client.FieldName, oauth2client.FieldLogoURL:
+		case oauth2client.FieldCreatedAt, oauth2client.FieldLastUsed, oauth2client.FieldUpdatedAt, oauth2client.FieldDeletedAt:
+			values[i] = new(sql.NullTime)
+		case oauth2client.FieldID, oauth2client.FieldSecret, oauth2client.FieldName, oauth2client.FieldLogoURL, oauth2client.FieldCreatedBy, oauth2client.FieldPolicyURL, oauth2client.FieldTermsOfServiceURL:
 			values[i] = new(sql.NullString)
 		default:
 			values[i] = new(sql.UnknownType)
@@ -104,6 +138,110 @@ func (o *OAuth2Client) assignValues(columns []string, values []any) error {
 			} else if value.Valid {
 				o.LogoURL = value.String
 			}
+		case oauth2client.FieldRefreshTokenPolicy:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field refresh_token_policy", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &o.RefreshTokenPolicy); err != nil {
+					return fmt.Errorf("unmarshal field refresh_token_policy: %w", err)
+				}
+			}
+		case oauth2client.FieldTokenExpiry:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field token_expiry", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &o.TokenExpiry); err != nil {
+					return fmt.Errorf("unmarshal field token_expiry: %w", err)
+				}
+			}
+		case oauth2client.FieldGroupsFilter:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field groups_filter", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &o.GroupsFilter); err != nil {
+					return fmt.Errorf("unmarshal field groups_filter: %w", err)
+				}
+			}
+		case oauth2client.FieldClaimsTransforms:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field claims_transforms", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &o.ClaimsTransforms); err != nil {
+					return fmt.Errorf("unmarshal field claims_transforms: %w", err)
+				}
+			}
+		case oauth2client.FieldRedirectURIPatterns:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field redirect_uri_patterns", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &o.RedirectURIPatterns); err != nil {
+					return fmt.Errorf("unmarshal field redirect_uri_patterns: %w", err)
+				}
+			}
+		case oauth2client.FieldPreviousSecret:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field previous_secret", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &o.PreviousSecret); err != nil {
+					return fmt.Errorf("unmarshal field previous_secret: %w", err)
+				}
+			}
+		case oauth2client.FieldCreatedAt:
+			if value, ok := values[i].(*sql.NullTime); !ok {
+				return fmt.Errorf("unexpected type %T for field created_at", values[i])
+			} else if value.Valid {
+				o.CreatedAt = value.Time
+			}
+		case oauth2client.FieldLastUsed:
+			if value, ok := values[i].(*sql.NullTime); !ok {
+				return fmt.Errorf("unexpected type %T for field last_used", values[i])
+			} else if value.Valid {
+				o.LastUsed = value.Time
+			}
+		case oauth2client.FieldCreatedBy:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field created_by", values[i])
+			} else if value.Valid {
+				o.CreatedBy = value.String
+			}
+		case oauth2client.FieldUpdatedAt:
+			if value, ok := values[i].(*sql.NullTime); !ok {
+				return fmt.Errorf("unexpected type %T for field updated_at", values[i])
+			} else if value.Valid {
+				o.UpdatedAt = value.Time
+			}
+		case oauth2client.FieldDeletedAt:
+			if value, ok := values[i].(*sql.NullTime); !ok {
+				return fmt.Errorf("unexpected type %T for field deleted_at", values[i])
+			} else if value.Valid {
+				o.DeletedAt = value.Time
+			}
+		case oauth2client.FieldPolicyURL:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field policy_url", values[i])
+			} else if value.Valid {
+				o.PolicyURL = value.String
+			}
+		case oauth2client.FieldTermsOfServiceURL:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field terms_of_service_url", values[i])
+			} else if value.Valid {
+				o.TermsOfServiceURL = value.String
+			}
+		case oauth2client.FieldAllowedOrigins:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field allowed_origins", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &o.AllowedOrigins); err != nil {
+					return fmt.Errorf("unmarshal field allowed_origins: %w", err)
+				}
+			}
+		case oauth2client.FieldForwardClaimSourceTokens:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field forward_claim_source_tokens", values[i])
+			} else if value.Valid {
+				o.ForwardClaimSourceTokens = value.Bool
+			}
 		default:
 			o.selectValues.Set(columns[i], values[i])
 		}
@@ -157,6 +295,51 @@ func (o *OAuth2Client) String() string {
 	builder.WriteString(", ")
 	builder.WriteString("logo_url=")
 	builder.WriteString(o.LogoURL)
+	builder.WriteString(", ")
+	builder.WriteString("refresh_token_policy=")
+	builder.WriteString(fmt.Sprintf("%v", o.RefreshTokenPolicy))
+	builder.WriteString(", ")
+	builder.WriteString("token_expiry=")
+	builder.WriteString(fmt.Sprintf("%v", o.TokenExpiry))
+	builder.WriteString(", ")
+	builder.WriteString("groups_filter=")
+	builder.WriteString(fmt.Sprintf("%v", o.GroupsFilter))
+	builder.WriteString(", ")
+	builder.WriteString("claims_transforms=")
+	builder.WriteString(fmt.Sprintf("%v", o.ClaimsTransforms))
+	builder.WriteString(", ")
+	builder.WriteString("redirect_uri_patterns=")
+	builder.WriteString(fmt.Sprintf("%v", o.RedirectURIPatterns))
+	builder.WriteString(", ")
+	builder.WriteString("previous_secret=")
+	builder.WriteString(fmt.Sprintf("%v", o.PreviousSecret))
+	builder.WriteString(", ")
+	builder.WriteString("created_at=")
+	builder.WriteString(o.CreatedAt.Format(time.ANSIC))
+	builder.WriteString(", ")
+	builder.WriteString("last_used=")
+	builder.WriteString(o.LastUsed.Format(time.ANSIC))
+	builder.WriteString(", ")
+	builder.WriteString("created_by=")
+	builder.WriteString(o.CreatedBy)
+	builder.WriteString(", ")
+	builder.WriteString("updated_at=")
+	builder.WriteString(o.UpdatedAt.Format(time.ANSIC))
+	builder.WriteString(", ")
+	builder.WriteString("deleted_at=")
+	builder.WriteString(o.DeletedAt.Format(time.ANSIC))
+	builder.WriteString(", ")
+	builder.WriteString("policy_url=")
+	builder.WriteString(o.PolicyURL)
+	builder.WriteString(", ")
+	builder.WriteString("terms_of_service_url=")
+	builder.WriteString(o.TermsOfServiceURL)
+	builder.WriteString(", ")
+	builder.WriteString("allowed_origins=")
+	builder.WriteString(fmt.Sprintf("%v", o.AllowedOrigins))
+	builder.WriteString(", ")
+	builder.WriteString("forward_claim_source_tokens=")
+	builder.WriteString(fmt.Sprintf("%v", o.ForwardClaimSourceTokens))
 	builder.WriteByte(')')
 	return builder.String()
 }
@@ -23,6 +23,36 @@ const (
 	FieldName = "name"
 	// FieldLogoURL holds the string denoting the logo_url field in the database.
 	FieldLogoURL = "logo_url"
+	// FieldRefreshTokenPolicy holds the string denoting the refresh_token_policy field in the database.
+	FieldRefreshTokenPolicy = "refresh_token_policy"
+	// FieldTokenExpiry holds the string denoting the token_expiry field in the database.
+	FieldTokenExpiry = "token_expiry"
+	// FieldGroupsFilter holds the string denoting the groups_filter field in the database.
+	FieldGroupsFilter = "groups_filter"
+	// FieldClaimsTransforms holds the string denoting the claims_transforms field in the database.
+	FieldClaimsTransforms = "claims_transforms"
+	// FieldRedirectURIPatterns holds the string denoting the redirect_uri_patterns field in the database.
+	FieldRedirectURIPatterns = "redirect_uri_patterns"
+	// FieldPreviousSecret holds the string denoting the previous_secret field in the database.
+	FieldPreviousSecret = "previous_secret"
+	// FieldCreatedAt holds the string denoting the created_at field in the database.
+	FieldCreatedAt = "created_at"
+	// FieldLastUsed holds the string denoting the last_used field in the database.
+	FieldLastUsed = "last_used"
+	// FieldCreatedBy holds the string denoting the created_by field in the database.
+	FieldCreatedBy = "created_by"
+	// FieldUpdatedAt holds the string denoting the updated_at field in the database.
+	FieldUpdatedAt = "updated_at"
+	// FieldDeletedAt holds the string denoting the deleted_at field in the database.
+	FieldDeletedAt = "deleted_at"
+	// FieldPolicyURL holds the string denoting the policy_url field in the database.
+	FieldPolicyURL = "policy_url"
+	// FieldTermsOfServiceURL holds the string denoting the terms_of_service_url field in the database.
+	FieldTermsOfServiceURL = "terms_of_service_url"
+	// FieldAllowedOrigins holds the string denoting the allowed_origins field in the database.
+	FieldAllowedOrigins = "allowed_origins"
+	// FieldForwardClaimSourceTokens holds the string denoting the forward_claim_source_tokens field in the database.
+	FieldForwardClaimSourceTokens = "forward_claim_source_tokens"
 	// Table holds the table name of the oauth2client in the database.
 	Table = "oauth2clients"
 )
@@ -36,6 +66,21 @@ var Columns = []string{
 	FieldPublic,
 	FieldName,
 	FieldLogoURL,
+	FieldRefreshTokenPolicy,
+	FieldTokenExpiry,
+	FieldGroupsFilter,
+	FieldClaimsTransforms,
+	FieldRedirectURIPatterns,
+	FieldPreviousSecret,
+	FieldCreatedAt,
+	FieldLastUsed,
+	FieldCreatedBy,
+	FieldUpdatedAt,
+	FieldDeletedAt,
+	FieldPolicyURL,
+	FieldTermsOfServiceURL,
+	FieldAllowedOrigins,
+	FieldForwardClaimSourceTokens,
 }
 
 // ValidColumn reports if the column name is valid (part of the table columns).
@@ -55,6 +100,8 @@ var (
 	NameValidator func(string) error
 	// LogoURLValidator is a validator for the "logo_url" field. It is called by the builders before save.
 	LogoURLValidator func(string) error
+	// DefaultForwardClaimSourceTokens holds the default value on creation for the "forward_claim_source_tokens" field.
+	DefaultForwardClaimSourceTokens bool
 	// IDValidator is a validator for the "id" field. It is called by the builders before save.
 	IDValidator func(string) error
 )
@@ -86,3 +133,43 @@ func ByName(opts ...sql.OrderTermOption) OrderOption {
 func ByLogoURL(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldLogoURL, opts...).ToFunc()
 }
+
+// ByCreatedAt orders the results by the created_at field.
+func ByCreatedAt(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldCreatedAt, opts...).ToFunc()
+}
+
+// ByLastUsed orders the results by the last_used field.
+func ByLastUsed(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldLastUsed, opts...).ToFunc()
+}
+
+// ByCreatedBy orders the results by the created_by field.
+func ByCreatedBy(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldCreatedBy, opts...).ToFunc()
+}
+
+// ByUpdatedAt orders the results by the updated_at field.
+func ByUpdatedAt(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldUpdatedAt, opts...).ToFunc()
+}
+
+// ByDeletedAt orders the results by the deleted_at field.
+func ByDeletedAt(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldDeletedAt, opts...).ToFunc()
+}
+
+// ByPolicyURL orders the results by the policy_url field.
+func ByPolicyURL(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldPolicyURL, opts...).ToFunc()
+}
+
+// ByTermsOfServiceURL orders the results by the terms_of_service_url field.
+func ByTermsOfServiceURL(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldTermsOfServiceURL, opts...).ToFunc()
+}
+
+// ByForwardClaimSourceTokens orders the results by the forward_claim_source_tokens field.
+func ByForwardClaimSourceTokens(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldForwardClaimSourceTokens, opts...).ToFunc()
+}
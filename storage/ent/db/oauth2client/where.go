@@ -3,6 +3,8 @@
 package oauth2client
 
 import (
+	"time"
+
 	"entgo.io/ent/dialect/sql"
 	"github.com/dexidp/dex/storage/ent/db/predicate"
 )
@@ -82,6 +84,46 @@ func LogoURL(v string) predicate.OAuth2Client {
 	return predicate.OAuth2Client(sql.FieldEQ(FieldLogoURL, v))
 }
 
+// CreatedAt applies equality check predicate on the "created_at" field. It's identical to CreatedAtEQ.
+func CreatedAt(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldEQ(FieldCreatedAt, v))
+}
+
+// LastUsed applies equality check predicate on the "last_used" field. It's identical to LastUsedEQ.
+func LastUsed(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldEQ(FieldLastUsed, v))
+}
+
+// CreatedBy applies equality check predicate on the "created_by" field. It's identical to CreatedByEQ.
+func CreatedBy(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldEQ(FieldCreatedBy, v))
+}
+
+// UpdatedAt applies equality check predicate on the "updated_at" field. It's identical to UpdatedAtEQ.
+func UpdatedAt(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldEQ(FieldUpdatedAt, v))
+}
+
+// DeletedAt applies equality check predicate on the "deleted_at" field. It's identical to DeletedAtEQ.
+func DeletedAt(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldEQ(FieldDeletedAt, v))
+}
+
+// PolicyURL applies equality check predicate on the "policy_url" field. It's identical to PolicyURLEQ.
+func PolicyURL(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldEQ(FieldPolicyURL, v))
+}
+
+// TermsOfServiceURL applies equality check predicate on the "terms_of_service_url" field. It's identical to TermsOfServiceURLEQ.
+func TermsOfServiceURL(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldEQ(FieldTermsOfServiceURL, v))
+}
+
+// ForwardClaimSourceTokens applies equality check predicate on the "forward_claim_source_tokens" field. It's identical to ForwardClaimSourceTokensEQ.
+func ForwardClaimSourceTokens(v bool) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldEQ(FieldForwardClaimSourceTokens, v))
+}
+
 // SecretEQ applies the EQ predicate on the "secret" field.
 func SecretEQ(v string) predicate.OAuth2Client {
 	return predicate.OAuth2Client(sql.FieldEQ(FieldSecret, v))
@@ -307,6 +349,511 @@ func LogoURLContainsFold(v string) predicate.OAuth2Client {
 	return predicate.OAuth2Client(sql.FieldContainsFold(FieldLogoURL, v))
 }
 
+// RefreshTokenPolicyIsNil applies the IsNil predicate on the "refresh_token_policy" field.
+func RefreshTokenPolicyIsNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldIsNull(FieldRefreshTokenPolicy))
+}
+
+// RefreshTokenPolicyNotNil applies the NotNil predicate on the "refresh_token_policy" field.
+func RefreshTokenPolicyNotNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNotNull(FieldRefreshTokenPolicy))
+}
+
+// TokenExpiryIsNil applies the IsNil predicate on the "token_expiry" field.
+func TokenExpiryIsNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldIsNull(FieldTokenExpiry))
+}
+
+// TokenExpiryNotNil applies the NotNil predicate on the "token_expiry" field.
+func TokenExpiryNotNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNotNull(FieldTokenExpiry))
+}
+
+// GroupsFilterIsNil applies the IsNil predicate on the "groups_filter" field.
+func GroupsFilterIsNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldIsNull(FieldGroupsFilter))
+}
+
+// GroupsFilterNotNil applies the NotNil predicate on the "groups_filter" field.
+func GroupsFilterNotNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNotNull(FieldGroupsFilter))
+}
+
+// ClaimsTransformsIsNil applies the IsNil predicate on the "claims_transforms" field.
+func ClaimsTransformsIsNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldIsNull(FieldClaimsTransforms))
+}
+
+// ClaimsTransformsNotNil applies the NotNil predicate on the "claims_transforms" field.
+func ClaimsTransformsNotNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNotNull(FieldClaimsTransforms))
+}
+
+// RedirectURIPatternsIsNil applies the IsNil predicate on the "redirect_uri_patterns" field.
+func RedirectURIPatternsIsNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldIsNull(FieldRedirectURIPatterns))
+}
+
+// RedirectURIPatternsNotNil applies the NotNil predicate on the "redirect_uri_patterns" field.
+func RedirectURIPatternsNotNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNotNull(FieldRedirectURIPatterns))
+}
+
+// PreviousSecretIsNil applies the IsNil predicate on the "previous_secret" field.
+func PreviousSecretIsNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldIsNull(FieldPreviousSecret))
+}
+
+// PreviousSecretNotNil applies the NotNil predicate on the "previous_secret" field.
+func PreviousSecretNotNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNotNull(FieldPreviousSecret))
+}
+
+// CreatedAtEQ applies the EQ predicate on the "created_at" field.
+func CreatedAtEQ(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldEQ(FieldCreatedAt, v))
+}
+
+// CreatedAtNEQ applies the NEQ predicate on the "created_at" field.
+func CreatedAtNEQ(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNEQ(FieldCreatedAt, v))
+}
+
+// CreatedAtIn applies the In predicate on the "created_at" field.
+func CreatedAtIn(vs ...time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldIn(FieldCreatedAt, vs...))
+}
+
+// CreatedAtNotIn applies the NotIn predicate on the "created_at" field.
+func CreatedAtNotIn(vs ...time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNotIn(FieldCreatedAt, vs...))
+}
+
+// CreatedAtGT applies the GT predicate on the "created_at" field.
+func CreatedAtGT(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldGT(FieldCreatedAt, v))
+}
+
+// CreatedAtGTE applies the GTE predicate on the "created_at" field.
+func CreatedAtGTE(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldGTE(FieldCreatedAt, v))
+}
+
+// CreatedAtLT applies the LT predicate on the "created_at" field.
+func CreatedAtLT(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldLT(FieldCreatedAt, v))
+}
+
+// CreatedAtLTE applies the LTE predicate on the "created_at" field.
+func CreatedAtLTE(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldLTE(FieldCreatedAt, v))
+}
+
+// CreatedAtIsNil applies the IsNil predicate on the "created_at" field.
+func CreatedAtIsNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldIsNull(FieldCreatedAt))
+}
+
+// CreatedAtNotNil applies the NotNil predicate on the "created_at" field.
+func CreatedAtNotNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNotNull(FieldCreatedAt))
+}
+
+// LastUsedEQ applies the EQ predicate on the "last_used" field.
+func LastUsedEQ(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldEQ(FieldLastUsed, v))
+}
+
+// LastUsedNEQ applies the NEQ predicate on the "last_used" field.
+func LastUsedNEQ(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNEQ(FieldLastUsed, v))
+}
+
+// LastUsedIn applies the In predicate on the "last_used" field.
+func LastUsedIn(vs ...time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldIn(FieldLastUsed, vs...))
+}
+
+// LastUsedNotIn applies the NotIn predicate on the "last_used" field.
+func LastUsedNotIn(vs ...time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNotIn(FieldLastUsed, vs...))
+}
+
+// LastUsedGT applies the GT predicate on the "last_used" field.
+func LastUsedGT(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldGT(FieldLastUsed, v))
+}
+
+// LastUsedGTE applies the GTE predicate on the "last_used" field.
+func LastUsedGTE(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldGTE(FieldLastUsed, v))
+}
+
+// LastUsedLT applies the LT predicate on the "last_used" field.
+func LastUsedLT(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldLT(FieldLastUsed, v))
+}
+
+// LastUsedLTE applies the LTE predicate on the "last_used" field.
+func LastUsedLTE(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldLTE(FieldLastUsed, v))
+}
+
+// LastUsedIsNil applies the IsNil predicate on the "last_used" field.
+func LastUsedIsNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldIsNull(FieldLastUsed))
+}
+
+// LastUsedNotNil applies the NotNil predicate on the "last_used" field.
+func LastUsedNotNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNotNull(FieldLastUsed))
+}
+
+// CreatedByEQ applies the EQ predicate on the "created_by" field.
+func CreatedByEQ(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldEQ(FieldCreatedBy, v))
+}
+
+// CreatedByNEQ applies the NEQ predicate on the "created_by" field.
+func CreatedByNEQ(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNEQ(FieldCreatedBy, v))
+}
+
+// CreatedByIn applies the In predicate on the "created_by" field.
+func CreatedByIn(vs ...string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldIn(FieldCreatedBy, vs...))
+}
+
+// CreatedByNotIn applies the NotIn predicate on the "created_by" field.
+func CreatedByNotIn(vs ...string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNotIn(FieldCreatedBy, vs...))
+}
+
+// CreatedByGT applies the GT predicate on the "created_by" field.
+func CreatedByGT(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldGT(FieldCreatedBy, v))
+}
+
+// CreatedByGTE applies the GTE predicate on the "created_by" field.
+func CreatedByGTE(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldGTE(FieldCreatedBy, v))
+}
+
+// CreatedByLT applies the LT predicate on the "created_by" field.
+func CreatedByLT(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldLT(FieldCreatedBy, v))
+}
+
+// CreatedByLTE applies the LTE predicate on the "created_by" field.
+func CreatedByLTE(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldLTE(FieldCreatedBy, v))
+}
+
+// CreatedByContains applies the Contains predicate on the "created_by" field.
+func CreatedByContains(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldContains(FieldCreatedBy, v))
+}
+
+// CreatedByHasPrefix applies the HasPrefix predicate on the "created_by" field.
+func CreatedByHasPrefix(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldHasPrefix(FieldCreatedBy, v))
+}
+
+// CreatedByHasSuffix applies the HasSuffix predicate on the "created_by" field.
+func CreatedByHasSuffix(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldHasSuffix(FieldCreatedBy, v))
+}
+
+// CreatedByIsNil applies the IsNil predicate on the "created_by" field.
+func CreatedByIsNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldIsNull(FieldCreatedBy))
+}
+
+// CreatedByNotNil applies the NotNil predicate on the "created_by" field.
+func CreatedByNotNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNotNull(FieldCreatedBy))
+}
+
+// CreatedByEqualFold applies the EqualFold predicate on the "created_by" field.
+func CreatedByEqualFold(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldEqualFold(FieldCreatedBy, v))
+}
+
+// CreatedByContainsFold applies the ContainsFold predicate on the "created_by" field.
+func CreatedByContainsFold(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldContainsFold(FieldCreatedBy, v))
+}
+
+// UpdatedAtEQ applies the EQ predicate on the "updated_at" field.
+func UpdatedAtEQ(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldEQ(FieldUpdatedAt, v))
+}
+
+// UpdatedAtNEQ applies the NEQ predicate on the "updated_at" field.
+func UpdatedAtNEQ(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNEQ(FieldUpdatedAt, v))
+}
+
+// UpdatedAtIn applies the In predicate on the "updated_at" field.
+func UpdatedAtIn(vs ...time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldIn(FieldUpdatedAt, vs...))
+}
+
+// UpdatedAtNotIn applies the NotIn predicate on the "updated_at" field.
+func UpdatedAtNotIn(vs ...time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNotIn(FieldUpdatedAt, vs...))
+}
+
+// UpdatedAtGT applies the GT predicate on the "updated_at" field.
+func UpdatedAtGT(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldGT(FieldUpdatedAt, v))
+}
+
+// UpdatedAtGTE applies the GTE predicate on the "updated_at" field.
+func UpdatedAtGTE(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldGTE(FieldUpdatedAt, v))
+}
+
+// UpdatedAtLT applies the LT predicate on the "updated_at" field.
+func UpdatedAtLT(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldLT(FieldUpdatedAt, v))
+}
+
+// UpdatedAtLTE applies the LTE predicate on the "updated_at" field.
+func UpdatedAtLTE(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldLTE(FieldUpdatedAt, v))
+}
+
+// UpdatedAtIsNil applies the IsNil predicate on the "updated_at" field.
+func UpdatedAtIsNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldIsNull(FieldUpdatedAt))
+}
+
+// UpdatedAtNotNil applies the NotNil predicate on the "updated_at" field.
+func UpdatedAtNotNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNotNull(FieldUpdatedAt))
+}
+
+// DeletedAtEQ applies the EQ predicate on the "deleted_at" field.
+func DeletedAtEQ(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldEQ(FieldDeletedAt, v))
+}
+
+// DeletedAtNEQ applies the NEQ predicate on the "deleted_at" field.
+func DeletedAtNEQ(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNEQ(FieldDeletedAt, v))
+}
+
+// DeletedAtIn applies the In predicate on the "deleted_at" field.
+func DeletedAtIn(vs ...time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldIn(FieldDeletedAt, vs...))
+}
+
+// DeletedAtNotIn applies the NotIn predicate on the "deleted_at" field.
+func DeletedAtNotIn(vs ...time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNotIn(FieldDeletedAt, vs...))
+}
+
+// DeletedAtGT applies the GT predicate on the "deleted_at" field.
+func DeletedAtGT(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldGT(FieldDeletedAt, v))
+}
+
+// DeletedAtGTE applies the GTE predicate on the "deleted_at" field.
+func DeletedAtGTE(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldGTE(FieldDeletedAt, v))
+}
+
+// DeletedAtLT applies the LT predicate on the "deleted_at" field.
+func DeletedAtLT(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldLT(FieldDeletedAt, v))
+}
+
+// DeletedAtLTE applies the LTE predicate on the "deleted_at" field.
+func DeletedAtLTE(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldLTE(FieldDeletedAt, v))
+}
+
+// DeletedAtIsNil applies the IsNil predicate on the "deleted_at" field.
+func DeletedAtIsNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldIsNull(FieldDeletedAt))
+}
+
+// DeletedAtNotNil applies the NotNil predicate on the "deleted_at" field.
+func DeletedAtNotNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNotNull(FieldDeletedAt))
+}
+
+// PolicyURLEQ applies the EQ predicate on the "policy_url" field.
+func PolicyURLEQ(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldEQ(FieldPolicyURL, v))
+}
+
+// PolicyURLNEQ applies the NEQ predicate on the "policy_url" field.
+func PolicyURLNEQ(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNEQ(FieldPolicyURL, v))
+}
+
+// PolicyURLIn applies the In predicate on the "policy_url" field.
+func PolicyURLIn(vs ...string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldIn(FieldPolicyURL, vs...))
+}
+
+// PolicyURLNotIn applies the NotIn predicate on the "policy_url" field.
+func PolicyURLNotIn(vs ...string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNotIn(FieldPolicyURL, vs...))
+}
+
+// PolicyURLGT applies the GT predicate on the "policy_url" field.
+func PolicyURLGT(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldGT(FieldPolicyURL, v))
+}
+
+// PolicyURLGTE applies the GTE predicate on the "policy_url" field.
+func PolicyURLGTE(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldGTE(FieldPolicyURL, v))
+}
+
+// PolicyURLLT applies the LT predicate on the "policy_url" field.
+func PolicyURLLT(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldLT(FieldPolicyURL, v))
+}
+
+// PolicyURLLTE applies the LTE predicate on the "policy_url" field.
+func PolicyURLLTE(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldLTE(FieldPolicyURL, v))
+}
+
+// PolicyURLContains applies the Contains predicate on the "policy_url" field.
+func PolicyURLContains(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldContains(FieldPolicyURL, v))
+}
+
+// PolicyURLHasPrefix applies the HasPrefix predicate on the "policy_url" field.
+func PolicyURLHasPrefix(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldHasPrefix(FieldPolicyURL, v))
+}
+
+// PolicyURLHasSuffix applies the HasSuffix predicate on the "policy_url" field.
+func PolicyURLHasSuffix(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldHasSuffix(FieldPolicyURL, v))
+}
+
+// PolicyURLIsNil applies the IsNil predicate on the "policy_url" field.
+func PolicyURLIsNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldIsNull(FieldPolicyURL))
+}
+
+// PolicyURLNotNil applies the NotNil predicate on the "policy_url" field.
+func PolicyURLNotNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNotNull(FieldPolicyURL))
+}
+
+// PolicyURLEqualFold applies the EqualFold predicate on the "policy_url" field.
+func PolicyURLEqualFold(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldEqualFold(FieldPolicyURL, v))
+}
+
+// PolicyURLContainsFold applies the ContainsFold predicate on the "policy_url" field.
+func PolicyURLContainsFold(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldContainsFold(FieldPolicyURL, v))
+}
+
+// TermsOfServiceURLEQ applies the EQ predicate on the "terms_of_service_url" field.
+func TermsOfServiceURLEQ(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldEQ(FieldTermsOfServiceURL, v))
+}
+
+// TermsOfServiceURLNEQ applies the NEQ predicate on the "terms_of_service_url" field.
+func TermsOfServiceURLNEQ(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNEQ(FieldTermsOfServiceURL, v))
+}
+
+// TermsOfServiceURLIn applies the In predicate on the "terms_of_service_url" field.
+func TermsOfServiceURLIn(vs ...string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldIn(FieldTermsOfServiceURL, vs...))
+}
+
+// TermsOfServiceURLNotIn applies the NotIn predicate on the "terms_of_service_url" field.
+func TermsOfServiceURLNotIn(vs ...string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNotIn(FieldTermsOfServiceURL, vs...))
+}
+
+// TermsOfServiceURLGT applies the GT predicate on the "terms_of_service_url" field.
+func TermsOfServiceURLGT(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldGT(FieldTermsOfServiceURL, v))
+}
+
+// TermsOfServiceURLGTE applies the GTE predicate on the "terms_of_service_url" field.
+func TermsOfServiceURLGTE(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldGTE(FieldTermsOfServiceURL, v))
+}
+
+// TermsOfServiceURLLT applies the LT predicate on the "terms_of_service_url" field.
+func TermsOfServiceURLLT(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldLT(FieldTermsOfServiceURL, v))
+}
+
+// TermsOfServiceURLLTE applies the LTE predicate on the "terms_of_service_url" field.
+func TermsOfServiceURLLTE(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldLTE(FieldTermsOfServiceURL, v))
+}
+
+// TermsOfServiceURLContains applies the Contains predicate on the "terms_of_service_url" field.
+func TermsOfServiceURLContains(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldContains(FieldTermsOfServiceURL, v))
+}
+
+// TermsOfServiceURLHasPrefix applies the HasPrefix predicate on the "terms_of_service_url" field.
+func TermsOfServiceURLHasPrefix(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldHasPrefix(FieldTermsOfServiceURL, v))
+}
+
+// TermsOfServiceURLHasSuffix applies the HasSuffix predicate on the "terms_of_service_url" field.
+func TermsOfServiceURLHasSuffix(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldHasSuffix(FieldTermsOfServiceURL, v))
+}
+
+// TermsOfServiceURLIsNil applies the IsNil predicate on the "terms_of_service_url" field.
+func TermsOfServiceURLIsNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldIsNull(FieldTermsOfServiceURL))
+}
+
+// TermsOfServiceURLNotNil applies the NotNil predicate on the "terms_of_service_url" field.
+func TermsOfServiceURLNotNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNotNull(FieldTermsOfServiceURL))
+}
+
+// TermsOfServiceURLEqualFold applies the EqualFold predicate on the "terms_of_service_url" field.
+func TermsOfServiceURLEqualFold(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldEqualFold(FieldTermsOfServiceURL, v))
+}
+
+// TermsOfServiceURLContainsFold applies the ContainsFold predicate on the "terms_of_service_url" field.
+func TermsOfServiceURLContainsFold(v string) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldContainsFold(FieldTermsOfServiceURL, v))
+}
+
+// AllowedOriginsIsNil applies the IsNil predicate on the "allowed_origins" field.
+func AllowedOriginsIsNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldIsNull(FieldAllowedOrigins))
+}
+
+// AllowedOriginsNotNil applies the NotNil predicate on the "allowed_origins" field.
+func AllowedOriginsNotNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNotNull(FieldAllowedOrigins))
+}
+
+// ForwardClaimSourceTokensEQ applies the EQ predicate on the "forward_claim_source_tokens" field.
+func ForwardClaimSourceTokensEQ(v bool) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldEQ(FieldForwardClaimSourceTokens, v))
+}
+
+// ForwardClaimSourceTokensNEQ applies the NEQ predicate on the "forward_claim_source_tokens" field.
+func ForwardClaimSourceTokensNEQ(v bool) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNEQ(FieldForwardClaimSourceTokens, v))
+}
+
 // And groups predicates with the AND operator between them.
 func And(predicates ...predicate.OAuth2Client) predicate.OAuth2Client {
 	return predicate.OAuth2Client(sql.AndPredicates(predicates...))
@@ -6,9 +6,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"entgo.io/ent/dialect/sql/sqlgraph"
 	"entgo.io/ent/schema/field"
+	"github.com/dexidp/dex/storage"
 	"github.com/dexidp/dex/storage/ent/db/oauth2client"
 )
 
@@ -55,6 +57,160 @@ func (oc *OAuth2ClientCreate) SetLogoURL(s string) *OAuth2ClientCreate {
 	return oc
 }
 
+// SetRefreshTokenPolicy sets the "refresh_token_policy" field.
+func (oc *OAuth2ClientCreate) SetRefreshTokenPolicy(stp *storage.RefreshTokenPolicy) *OAuth2ClientCreate {
+	oc.mutation.SetRefreshTokenPolicy(stp)
+	return oc
+}
+
+// SetTokenExpiry sets the "token_expiry" field.
+func (oc *OAuth2ClientCreate) SetTokenExpiry(ste *storage.TokenExpiry) *OAuth2ClientCreate {
+	oc.mutation.SetTokenExpiry(ste)
+	return oc
+}
+
+// SetGroupsFilter sets the "groups_filter" field.
+func (oc *OAuth2ClientCreate) SetGroupsFilter(sf *storage.GroupsFilter) *OAuth2ClientCreate {
+	oc.mutation.SetGroupsFilter(sf)
+	return oc
+}
+
+// SetClaimsTransforms sets the "claims_transforms" field.
+func (oc *OAuth2ClientCreate) SetClaimsTransforms(ct []storage.ClaimsTransform) *OAuth2ClientCreate {
+	oc.mutation.SetClaimsTransforms(ct)
+	return oc
+}
+
+// SetRedirectURIPatterns sets the "redirect_uri_patterns" field.
+func (oc *OAuth2ClientCreate) SetRedirectURIPatterns(s []string) *OAuth2ClientCreate {
+	oc.mutation.SetRedirectURIPatterns(s)
+	return oc
+}
+
+// SetPreviousSecret sets the "previous_secret" field.
+func (oc *OAuth2ClientCreate) SetPreviousSecret(sf *storage.PreviousSecret) *OAuth2ClientCreate {
+	oc.mutation.SetPreviousSecret(sf)
+	return oc
+}
+
+// SetCreatedAt sets the "created_at" field.
+func (oc *OAuth2ClientCreate) SetCreatedAt(t time.Time) *OAuth2ClientCreate {
+	oc.mutation.SetCreatedAt(t)
+	return oc
+}
+
+// SetNillableCreatedAt sets the "created_at" field if the given value is not nil.
+func (oc *OAuth2ClientCreate) SetNillableCreatedAt(t *time.Time) *OAuth2ClientCreate {
+	if t != nil {
+		oc.SetCreatedAt(*t)
+	}
+	return oc
+}
+
+// SetLastUsed sets the "last_used" field.
+func (oc *OAuth2ClientCreate) SetLastUsed(t time.Time) *OAuth2ClientCreate {
+	oc.mutation.SetLastUsed(t)
+	return oc
+}
+
+// SetNillableLastUsed sets the "last_used" field if the given value is not nil.
+func (oc *OAuth2ClientCreate) SetNillableLastUsed(t *time.Time) *OAuth2ClientCreate {
+	if t != nil {
+		oc.SetLastUsed(*t)
+	}
+	return oc
+}
+
+// SetCreatedBy sets the "created_by" field.
+func (oc *OAuth2ClientCreate) SetCreatedBy(s string) *OAuth2ClientCreate {
+	oc.mutation.SetCreatedBy(s)
+	return oc
+}
+
+// SetNillableCreatedBy sets the "created_by" field if the given value is not nil.
+func (oc *OAuth2ClientCreate) SetNillableCreatedBy(s *string) *OAuth2ClientCreate {
+	if s != nil {
+		oc.SetCreatedBy(*s)
+	}
+	return oc
+}
+
+// SetUpdatedAt sets the "updated_at" field.
+func (oc *OAuth2ClientCreate) SetUpdatedAt(t time.Time) *OAuth2ClientCreate {
+	oc.mutation.SetUpdatedAt(t)
+	return oc
+}
+
+// SetNillableUpdatedAt sets the "updated_at" field if the given value is not nil.
+func (oc *OAuth2ClientCreate) SetNillableUpdatedAt(t *time.Time) *OAuth2ClientCreate {
+	if t != nil {
+		oc.SetUpdatedAt(*t)
+	}
+	return oc
+}
+
+// SetDeletedAt sets the "deleted_at" field.
+func (oc *OAuth2ClientCreate) SetDeletedAt(t time.Time) *OAuth2ClientCreate {
+	oc.mutation.SetDeletedAt(t)
+	return oc
+}
+
+// SetNillableDeletedAt sets the "deleted_at" field if the given value is not nil.
+func (oc *OAuth2ClientCreate) SetNillableDeletedAt(t *time.Time) *OAuth2ClientCreate {
+	if t != nil {
+		oc.SetDeletedAt(*t)
+	}
+	return oc
+}
+
+// SetPolicyURL sets the "policy_url" field.
+func (oc *OAuth2ClientCreate) SetPolicyURL(s string) *OAuth2ClientCreate {
+	oc.mutation.SetPolicyURL(s)
+	return oc
+}
+
+// SetNillablePolicyURL sets the "policy_url" field if the given value is not nil.
+func (oc *OAuth2ClientCreate) SetNillablePolicyURL(s *string) *OAuth2ClientCreate {
+	if s != nil {
+		oc.SetPolicyURL(*s)
+	}
+	return oc
+}
+
+// SetTermsOfServiceURL sets the "terms_of_service_url" field.
+func (oc *OAuth2ClientCreate) SetTermsOfServiceURL(s string) *OAuth2ClientCreate {
+	oc.mutation.SetTermsOfServiceURL(s)
+	return oc
+}
+
+// SetNillableTermsOfServiceURL sets the "terms_of_service_url" field if the given value is not nil.
+func (oc *OAuth2ClientCreate) SetNillableTermsOfServiceURL(s *string) *OAuth2ClientCreate {
+	if s != nil {
+		oc.SetTermsOfServiceURL(*s)
+	}
+	return oc
+}
+
+// SetAllowedOrigins sets the "allowed_origins" field.
+func (oc *OAuth2ClientCreate) SetAllowedOrigins(s []string) *OAuth2ClientCreate {
+	oc.mutation.SetAllowedOrigins(s)
+	return oc
+}
+
+// SetForwardClaimSourceTokens sets the "forward_claim_source_tokens" field.
+func (oc *OAuth2ClientCreate) SetForwardClaimSourceTokens(b bool) *OAuth2ClientCreate {
+	oc.mutation.SetForwardClaimSourceTokens(b)
+	return oc
+}
+
+// SetNillableForwardClaimSourceTokens sets the "forward_claim_source_tokens" field if the given value is not nil.
+func (oc *OAuth2ClientCreate) SetNillableForwardClaimSourceTokens(b *bool) *OAuth2ClientCreate {
+	if b != nil {
+		oc.SetForwardClaimSourceTokens(*b)
+	}
+	return oc
+}
+
 // SetID sets the "id" field.
 func (oc *OAuth2ClientCreate) SetID(s string) *OAuth2ClientCreate {
 	oc.mutation.SetID(s)
@@ -68,6 +224,7 @@ func (oc *OAuth2ClientCreate) Mutation() *OAuth2ClientMutation {
 
 // Save creates the OAuth2Client in the database.
 func (oc *OAuth2ClientCreate) Save(ctx context.Context) (*OAuth2Client, error) {
+	oc.defaults()
 	return withHooks(ctx, oc.sqlSave, oc.mutation, oc.hooks)
 }
 
@@ -93,6 +250,14 @@ func (oc *OAuth2ClientCreate) ExecX(ctx context.Context) {
 	}
 }
 
+// defaults sets the default values of the builder before save.
+func (oc *OAuth2ClientCreate) defaults() {
+	if _, ok := oc.mutation.ForwardClaimSourceTokens(); !ok {
+		v := oauth2client.DefaultForwardClaimSourceTokens
+		oc.mutation.SetForwardClaimSourceTokens(v)
+	}
+}
+
 // check runs all checks and user-defined validators on the builder.
 func (oc *OAuth2ClientCreate) check() error {
 	if _, ok := oc.mutation.Secret(); !ok {
@@ -122,6 +287,9 @@ func (oc *OAuth2ClientCreate) check() error {
 			return &ValidationError{Name: "logo_url", err: fmt.Errorf(`db: validator failed for field "OAuth2Client.logo_url": %w`, err)}
 		}
 	}
+	if _, ok := oc.mutation.ForwardClaimSourceTokens(); !ok {
+		return &ValidationError{Name: "forward_claim_source_tokens", err: errors.New(`db: missing required field "OAuth2Client.forward_claim_source_tokens"`)}
+	}
 	if v, ok := oc.mutation.ID(); ok {
 		if err := oauth2client.IDValidator(v); err != nil {
 			return &ValidationError{Name: "id", err: fmt.Errorf(`db: validator failed for field "OAuth2Client.id": %w`, err)}
@@ -186,6 +354,66 @@ func (oc *OAuth2ClientCreate) createSpec() (*OAuth2Client, *sqlgraph.CreateSpec)
 		_spec.SetField(oauth2client.FieldLogoURL, field.TypeString, value)
 		_node.LogoURL = value
 	}
+	if value, ok := oc.mutation.RefreshTokenPolicy(); ok {
+		_spec.SetField(oauth2client.FieldRefreshTokenPolicy, field.TypeJSON, value)
+		_node.RefreshTokenPolicy = value
+	}
+	if value, ok := oc.mutation.TokenExpiry(); ok {
+		_spec.SetField(oauth2client.FieldTokenExpiry, field.TypeJSON, value)
+		_node.TokenExpiry = value
+	}
+	if value, ok := oc.mutation.GroupsFilter(); ok {
+		_spec.SetField(oauth2client.FieldGroupsFilter, field.TypeJSON, value)
+		_node.GroupsFilter = value
+	}
+	if value, ok := oc.mutation.ClaimsTransforms(); ok {
+		_spec.SetField(oauth2client.FieldClaimsTransforms, field.TypeJSON, value)
+		_node.ClaimsTransforms = value
+	}
+	if value, ok := oc.mutation.RedirectURIPatterns(); ok {
+		_spec.SetField(oauth2client.FieldRedirectURIPatterns, field.TypeJSON, value)
+		_node.RedirectURIPatterns = value
+	}
+	if value, ok := oc.mutation.PreviousSecret(); ok {
+		_spec.SetField(oauth2client.FieldPreviousSecret, field.TypeJSON, value)
+		_node.PreviousSecret = value
+	}
+	if value, ok := oc.mutation.CreatedAt(); ok {
+		_spec.SetField(oauth2client.FieldCreatedAt, field.TypeTime, value)
+		_node.CreatedAt = value
+	}
+	if value, ok := oc.mutation.LastUsed(); ok {
+		_spec.SetField(oauth2client.FieldLastUsed, field.TypeTime, value)
+		_node.LastUsed = value
+	}
+	if value, ok := oc.mutation.CreatedBy(); ok {
+		_spec.SetField(oauth2client.FieldCreatedBy, field.TypeString, value)
+		_node.CreatedBy = value
+	}
+	if value, ok := oc.mutation.UpdatedAt(); ok {
+		_spec.SetField(oauth2client.FieldUpdatedAt, field.TypeTime, value)
+		_node.UpdatedAt = value
+	}
+	if value, ok := oc.mutation.DeletedAt(); ok {
+		_spec.SetField(oauth2client.FieldDeletedAt, field.TypeTime, value)
+		_node.DeletedAt = value
+	}
+	if value, ok := oc.mutation.PolicyURL(); ok {
+		_spec.SetField(oauth2client.FieldPolicyURL, field.TypeString, value)
+		_node.PolicyURL = value
+	}
+	if value, ok := oc.mutation.TermsOfServiceURL(); ok {
+		_spec.SetField(oauth2client.FieldTermsOfServiceURL, field.TypeString, value)
+		_node.TermsOfServiceURL = value
+	}
+	if value, ok := oc.mutation.AllowedOrigins(); ok {
+		_spec.SetField(oauth2client.FieldAllowedOrigins, field.TypeJSON, value)
+		_node.AllowedOrigins = value
+	}
+	if value, ok := oc.mutation.ForwardClaimSourceTokens(); ok {
+		_spec.SetField(oauth2client.FieldForwardClaimSourceTokens, field.TypeBool, value)
+		_node.ForwardClaimSourceTokens = value
+	}
 	return _node, _spec
 }
 
@@ -207,6 +435,7 @@ func (ocb *OAuth2ClientCreateBulk) Save(ctx context.Context) ([]*OAuth2Client, e
 	for i := range ocb.builders {
 		func(i int, root context.Context) {
 			builder := ocb.builders[i]
+			builder.defaults()
 			var mut Mutator = MutateFunc(func(ctx context.Context, m Mutation) (Value, error) {
 				mutation, ok := m.(*OAuth2ClientMutation)
 				if !ok {
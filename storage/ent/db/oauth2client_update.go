@@ -6,11 +6,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"entgo.io/ent/dialect/sql"
 	"entgo.io/ent/dialect/sql/sqlgraph"
 	"entgo.io/ent/dialect/sql/sqljson"
 	"entgo.io/ent/schema/field"
+	"github.com/dexidp/dex/storage"
 	"github.com/dexidp/dex/storage/ent/db/oauth2client"
 	"github.com/dexidp/dex/storage/ent/db/predicate"
 )
@@ -120,6 +122,262 @@ func (ou *OAuth2ClientUpdate) SetNillableLogoURL(s *string) *OAuth2ClientUpdate
 	return ou
 }
 
+// SetRefreshTokenPolicy sets the "refresh_token_policy" field.
+func (ou *OAuth2ClientUpdate) SetRefreshTokenPolicy(stp *storage.RefreshTokenPolicy) *OAuth2ClientUpdate {
+	ou.mutation.SetRefreshTokenPolicy(stp)
+	return ou
+}
+
+// ClearRefreshTokenPolicy clears the value of the "refresh_token_policy" field.
+func (ou *OAuth2ClientUpdate) ClearRefreshTokenPolicy() *OAuth2ClientUpdate {
+	ou.mutation.ClearRefreshTokenPolicy()
+	return ou
+}
+
+// SetTokenExpiry sets the "token_expiry" field.
+func (ou *OAuth2ClientUpdate) SetTokenExpiry(ste *storage.TokenExpiry) *OAuth2ClientUpdate {
+	ou.mutation.SetTokenExpiry(ste)
+	return ou
+}
+
+// ClearTokenExpiry clears the value of the "token_expiry" field.
+func (ou *OAuth2ClientUpdate) ClearTokenExpiry() *OAuth2ClientUpdate {
+	ou.mutation.ClearTokenExpiry()
+	return ou
+}
+
+// SetGroupsFilter sets the "groups_filter" field.
+func (ou *OAuth2ClientUpdate) SetGroupsFilter(sf *storage.GroupsFilter) *OAuth2ClientUpdate {
+	ou.mutation.SetGroupsFilter(sf)
+	return ou
+}
+
+// ClearGroupsFilter clears the value of the "groups_filter" field.
+func (ou *OAuth2ClientUpdate) ClearGroupsFilter() *OAuth2ClientUpdate {
+	ou.mutation.ClearGroupsFilter()
+	return ou
+}
+
+// SetClaimsTransforms sets the "claims_transforms" field.
+func (ou *OAuth2ClientUpdate) SetClaimsTransforms(ct []storage.ClaimsTransform) *OAuth2ClientUpdate {
+	ou.mutation.SetClaimsTransforms(ct)
+	return ou
+}
+
+// AppendClaimsTransforms appends ct to the "claims_transforms" field.
+func (ou *OAuth2ClientUpdate) AppendClaimsTransforms(ct []storage.ClaimsTransform) *OAuth2ClientUpdate {
+	ou.mutation.AppendClaimsTransforms(ct)
+	return ou
+}
+
+// ClearClaimsTransforms clears the value of the "claims_transforms" field.
+func (ou *OAuth2ClientUpdate) ClearClaimsTransforms() *OAuth2ClientUpdate {
+	ou.mutation.ClearClaimsTransforms()
+	return ou
+}
+
+// SetRedirectURIPatterns sets the "redirect_uri_patterns" field.
+func (ou *OAuth2ClientUpdate) SetRedirectURIPatterns(s []string) *OAuth2ClientUpdate {
+	ou.mutation.SetRedirectURIPatterns(s)
+	return ou
+}
+
+// AppendRedirectURIPatterns appends s to the "redirect_uri_patterns" field.
+func (ou *OAuth2ClientUpdate) AppendRedirectURIPatterns(s []string) *OAuth2ClientUpdate {
+	ou.mutation.AppendRedirectURIPatterns(s)
+	return ou
+}
+
+// ClearRedirectURIPatterns clears the value of the "redirect_uri_patterns" field.
+func (ou *OAuth2ClientUpdate) ClearRedirectURIPatterns() *OAuth2ClientUpdate {
+	ou.mutation.ClearRedirectURIPatterns()
+	return ou
+}
+
+// SetPreviousSecret sets the "previous_secret" field.
+func (ou *OAuth2ClientUpdate) SetPreviousSecret(sf *storage.PreviousSecret) *OAuth2ClientUpdate {
+	ou.mutation.SetPreviousSecret(sf)
+	return ou
+}
+
+// ClearPreviousSecret clears the value of the "previous_secret" field.
+func (ou *OAuth2ClientUpdate) ClearPreviousSecret() *OAuth2ClientUpdate {
+	ou.mutation.ClearPreviousSecret()
+	return ou
+}
+
+// SetCreatedAt sets the "created_at" field.
+func (ou *OAuth2ClientUpdate) SetCreatedAt(t time.Time) *OAuth2ClientUpdate {
+	ou.mutation.SetCreatedAt(t)
+	return ou
+}
+
+// SetNillableCreatedAt sets the "created_at" field if the given value is not nil.
+func (ou *OAuth2ClientUpdate) SetNillableCreatedAt(t *time.Time) *OAuth2ClientUpdate {
+	if t != nil {
+		ou.SetCreatedAt(*t)
+	}
+	return ou
+}
+
+// ClearCreatedAt clears the value of the "created_at" field.
+func (ou *OAuth2ClientUpdate) ClearCreatedAt() *OAuth2ClientUpdate {
+	ou.mutation.ClearCreatedAt()
+	return ou
+}
+
+// SetLastUsed sets the "last_used" field.
+func (ou *OAuth2ClientUpdate) SetLastUsed(t time.Time) *OAuth2ClientUpdate {
+	ou.mutation.SetLastUsed(t)
+	return ou
+}
+
+// SetNillableLastUsed sets the "last_used" field if the given value is not nil.
+func (ou *OAuth2ClientUpdate) SetNillableLastUsed(t *time.Time) *OAuth2ClientUpdate {
+	if t != nil {
+		ou.SetLastUsed(*t)
+	}
+	return ou
+}
+
+// ClearLastUsed clears the value of the "last_used" field.
+func (ou *OAuth2ClientUpdate) ClearLastUsed() *OAuth2ClientUpdate {
+	ou.mutation.ClearLastUsed()
+	return ou
+}
+
+// SetCreatedBy sets the "created_by" field.
+func (ou *OAuth2ClientUpdate) SetCreatedBy(s string) *OAuth2ClientUpdate {
+	ou.mutation.SetCreatedBy(s)
+	return ou
+}
+
+// SetNillableCreatedBy sets the "created_by" field if the given value is not nil.
+func (ou *OAuth2ClientUpdate) SetNillableCreatedBy(s *string) *OAuth2ClientUpdate {
+	if s != nil {
+		ou.SetCreatedBy(*s)
+	}
+	return ou
+}
+
+// ClearCreatedBy clears the value of the "created_by" field.
+func (ou *OAuth2ClientUpdate) ClearCreatedBy() *OAuth2ClientUpdate {
+	ou.mutation.ClearCreatedBy()
+	return ou
+}
+
+// SetUpdatedAt sets the "updated_at" field.
+func (ou *OAuth2ClientUpdate) SetUpdatedAt(t time.Time) *OAuth2ClientUpdate {
+	ou.mutation.SetUpdatedAt(t)
+	return ou
+}
+
+// SetNillableUpdatedAt sets the "updated_at" field if the given value is not nil.
+func (ou *OAuth2ClientUpdate) SetNillableUpdatedAt(t *time.Time) *OAuth2ClientUpdate {
+	if t != nil {
+		ou.SetUpdatedAt(*t)
+	}
+	return ou
+}
+
+// ClearUpdatedAt clears the value of the "updated_at" field.
+func (ou *OAuth2ClientUpdate) ClearUpdatedAt() *OAuth2ClientUpdate {
+	ou.mutation.ClearUpdatedAt()
+	return ou
+}
+
+// SetDeletedAt sets the "deleted_at" field.
+func (ou *OAuth2ClientUpdate) SetDeletedAt(t time.Time) *OAuth2ClientUpdate {
+	ou.mutation.SetDeletedAt(t)
+	return ou
+}
+
+// SetNillableDeletedAt sets the "deleted_at" field if the given value is not nil.
+func (ou *OAuth2ClientUpdate) SetNillableDeletedAt(t *time.Time) *OAuth2ClientUpdate {
+	if t != nil {
+		ou.SetDeletedAt(*t)
+	}
+	return ou
+}
+
+// ClearDeletedAt clears the value of the "deleted_at" field.
+func (ou *OAuth2ClientUpdate) ClearDeletedAt() *OAuth2ClientUpdate {
+	ou.mutation.ClearDeletedAt()
+	return ou
+}
+
+// SetPolicyURL sets the "policy_url" field.
+func (ou *OAuth2ClientUpdate) SetPolicyURL(s string) *OAuth2ClientUpdate {
+	ou.mutation.SetPolicyURL(s)
+	return ou
+}
+
+// SetNillablePolicyURL sets the "policy_url" field if the given value is not nil.
+func (ou *OAuth2ClientUpdate) SetNillablePolicyURL(s *string) *OAuth2ClientUpdate {
+	if s != nil {
+		ou.SetPolicyURL(*s)
+	}
+	return ou
+}
+
+// ClearPolicyURL clears the value of the "policy_url" field.
+func (ou *OAuth2ClientUpdate) ClearPolicyURL() *OAuth2ClientUpdate {
+	ou.mutation.ClearPolicyURL()
+	return ou
+}
+
+// SetTermsOfServiceURL sets the "terms_of_service_url" field.
+func (ou *OAuth2ClientUpdate) SetTermsOfServiceURL(s string) *OAuth2ClientUpdate {
+	ou.mutation.SetTermsOfServiceURL(s)
+	return ou
+}
+
+// SetNillableTermsOfServiceURL sets the "terms_of_service_url" field if the given value is not nil.
+func (ou *OAuth2ClientUpdate) SetNillableTermsOfServiceURL(s *string) *OAuth2ClientUpdate {
+	if s != nil {
+		ou.SetTermsOfServiceURL(*s)
+	}
+	return ou
+}
+
+// ClearTermsOfServiceURL clears the value of the "terms_of_service_url" field.
+func (ou *OAuth2ClientUpdate) ClearTermsOfServiceURL() *OAuth2ClientUpdate {
+	ou.mutation.ClearTermsOfServiceURL()
+	return ou
+}
+
+// SetAllowedOrigins sets the "allowed_origins" field.
+func (ou *OAuth2ClientUpdate) SetAllowedOrigins(s []string) *OAuth2ClientUpdate {
+	ou.mutation.SetAllowedOrigins(s)
+	return ou
+}
+
+// AppendAllowedOrigins appends s to the "allowed_origins" field.
+func (ou *OAuth2ClientUpdate) AppendAllowedOrigins(s []string) *OAuth2ClientUpdate {
+	ou.mutation.AppendAllowedOrigins(s)
+	return ou
+}
+
+// ClearAllowedOrigins clears the value of the "allowed_origins" field.
+func (ou *OAuth2ClientUpdate) ClearAllowedOrigins() *OAuth2ClientUpdate {
+	ou.mutation.ClearAllowedOrigins()
+	return ou
+}
+
+// SetForwardClaimSourceTokens sets the "forward_claim_source_tokens" field.
+func (ou *OAuth2ClientUpdate) SetForwardClaimSourceTokens(b bool) *OAuth2ClientUpdate {
+	ou.mutation.SetForwardClaimSourceTokens(b)
+	return ou
+}
+
+// SetNillableForwardClaimSourceTokens sets the "forward_claim_source_tokens" field if the given value is not nil.
+func (ou *OAuth2ClientUpdate) SetNillableForwardClaimSourceTokens(b *bool) *OAuth2ClientUpdate {
+	if b != nil {
+		ou.SetForwardClaimSourceTokens(*b)
+	}
+	return ou
+}
+
 // Mutation returns the OAuth2ClientMutation object of the builder.
 func (ou *OAuth2ClientUpdate) Mutation() *OAuth2ClientMutation {
 	return ou.mutation
@@ -218,6 +476,108 @@ func (ou *OAuth2ClientUpdate) sqlSave(ctx context.Context) (n int, err error) {
 	if value, ok := ou.mutation.LogoURL(); ok {
 		_spec.SetField(oauth2client.FieldLogoURL, field.TypeString, value)
 	}
+	if value, ok := ou.mutation.RefreshTokenPolicy(); ok {
+		_spec.SetField(oauth2client.FieldRefreshTokenPolicy, field.TypeJSON, value)
+	}
+	if ou.mutation.RefreshTokenPolicyCleared() {
+		_spec.ClearField(oauth2client.FieldRefreshTokenPolicy, field.TypeJSON)
+	}
+	if value, ok := ou.mutation.TokenExpiry(); ok {
+		_spec.SetField(oauth2client.FieldTokenExpiry, field.TypeJSON, value)
+	}
+	if ou.mutation.TokenExpiryCleared() {
+		_spec.ClearField(oauth2client.FieldTokenExpiry, field.TypeJSON)
+	}
+	if value, ok := ou.mutation.GroupsFilter(); ok {
+		_spec.SetField(oauth2client.FieldGroupsFilter, field.TypeJSON, value)
+	}
+	if ou.mutation.GroupsFilterCleared() {
+		_spec.ClearField(oauth2client.FieldGroupsFilter, field.TypeJSON)
+	}
+	if value, ok := ou.mutation.ClaimsTransforms(); ok {
+		_spec.SetField(oauth2client.FieldClaimsTransforms, field.TypeJSON, value)
+	}
+	if value, ok := ou.mutation.AppendedClaimsTransforms(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, oauth2client.FieldClaimsTransforms, value)
+		})
+	}
+	if ou.mutation.ClaimsTransformsCleared() {
+		_spec.ClearField(oauth2client.FieldClaimsTransforms, field.TypeJSON)
+	}
+	if value, ok := ou.mutation.RedirectURIPatterns(); ok {
+		_spec.SetField(oauth2client.FieldRedirectURIPatterns, field.TypeJSON, value)
+	}
+	if value, ok := ou.mutation.AppendedRedirectURIPatterns(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, oauth2client.FieldRedirectURIPatterns, value)
+		})
+	}
+	if ou.mutation.RedirectURIPatternsCleared() {
+		_spec.ClearField(oauth2client.FieldRedirectURIPatterns, field.TypeJSON)
+	}
+	if value, ok := ou.mutation.PreviousSecret(); ok {
+		_spec.SetField(oauth2client.FieldPreviousSecret, field.TypeJSON, value)
+	}
+	if ou.mutation.PreviousSecretCleared() {
+		_spec.ClearField(oauth2client.FieldPreviousSecret, field.TypeJSON)
+	}
+	if value, ok := ou.mutation.CreatedAt(); ok {
+		_spec.SetField(oauth2client.FieldCreatedAt, field.TypeTime, value)
+	}
+	if ou.mutation.CreatedAtCleared() {
+		_spec.ClearField(oauth2client.FieldCreatedAt, field.TypeTime)
+	}
+	if value, ok := ou.mutation.LastUsed(); ok {
+		_spec.SetField(oauth2client.FieldLastUsed, field.TypeTime, value)
+	}
+	if ou.mutation.LastUsedCleared() {
+		_spec.ClearField(oauth2client.FieldLastUsed, field.TypeTime)
+	}
+	if value, ok := ou.mutation.CreatedBy(); ok {
+		_spec.SetField(oauth2client.FieldCreatedBy, field.TypeString, value)
+	}
+	if ou.mutation.CreatedByCleared() {
+		_spec.ClearField(oauth2client.FieldCreatedBy, field.TypeString)
+	}
+	if value, ok := ou.mutation.UpdatedAt(); ok {
+		_spec.SetField(oauth2client.FieldUpdatedAt, field.TypeTime, value)
+	}
+	if ou.mutation.UpdatedAtCleared() {
+		_spec.ClearField(oauth2client.FieldUpdatedAt, field.TypeTime)
+	}
+	if value, ok := ou.mutation.DeletedAt(); ok {
+		_spec.SetField(oauth2client.FieldDeletedAt, field.TypeTime, value)
+	}
+	if ou.mutation.DeletedAtCleared() {
+		_spec.ClearField(oauth2client.FieldDeletedAt, field.TypeTime)
+	}
+	if value, ok := ou.mutation.PolicyURL(); ok {
+		_spec.SetField(oauth2client.FieldPolicyURL, field.TypeString, value)
+	}
+	if ou.mutation.PolicyURLCleared() {
+		_spec.ClearField(oauth2client.FieldPolicyURL, field.TypeString)
+	}
+	if value, ok := ou.mutation.TermsOfServiceURL(); ok {
+		_spec.SetField(oauth2client.FieldTermsOfServiceURL, field.TypeString, value)
+	}
+	if ou.mutation.TermsOfServiceURLCleared() {
+		_spec.ClearField(oauth2client.FieldTermsOfServiceURL, field.TypeString)
+	}
+	if value, ok := ou.mutation.AllowedOrigins(); ok {
+		_spec.SetField(oauth2client.FieldAllowedOrigins, field.TypeJSON, value)
+	}
+	if value, ok := ou.mutation.AppendedAllowedOrigins(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, oauth2client.FieldAllowedOrigins, value)
+		})
+	}
+	if ou.mutation.AllowedOriginsCleared() {
+		_spec.ClearField(oauth2client.FieldAllowedOrigins, field.TypeJSON)
+	}
+	if value, ok := ou.mutation.ForwardClaimSourceTokens(); ok {
+		_spec.SetField(oauth2client.FieldForwardClaimSourceTokens, field.TypeBool, value)
+	}
 	if n, err = sqlgraph.UpdateNodes(ctx, ou.driver, _spec); err != nil {
 		if _, ok := err.(*sqlgraph.NotFoundError); ok {
 			err = &NotFoundError{oauth2client.Label}
@@ -330,6 +690,262 @@ func (ouo *OAuth2ClientUpdateOne) SetNillableLogoURL(s *string) *OAuth2ClientUpd
 	return ouo
 }
 
+// SetRefreshTokenPolicy sets the "refresh_token_policy" field.
+func (ouo *OAuth2ClientUpdateOne) SetRefreshTokenPolicy(stp *storage.RefreshTokenPolicy) *OAuth2ClientUpdateOne {
+	ouo.mutation.SetRefreshTokenPolicy(stp)
+	return ouo
+}
+
+// ClearRefreshTokenPolicy clears the value of the "refresh_token_policy" field.
+func (ouo *OAuth2ClientUpdateOne) ClearRefreshTokenPolicy() *OAuth2ClientUpdateOne {
+	ouo.mutation.ClearRefreshTokenPolicy()
+	return ouo
+}
+
+// SetTokenExpiry sets the "token_expiry" field.
+func (ouo *OAuth2ClientUpdateOne) SetTokenExpiry(ste *storage.TokenExpiry) *OAuth2ClientUpdateOne {
+	ouo.mutation.SetTokenExpiry(ste)
+	return ouo
+}
+
+// ClearTokenExpiry clears the value of the "token_expiry" field.
+func (ouo *OAuth2ClientUpdateOne) ClearTokenExpiry() *OAuth2ClientUpdateOne {
+	ouo.mutation.ClearTokenExpiry()
+	return ouo
+}
+
+// SetGroupsFilter sets the "groups_filter" field.
+func (ouo *OAuth2ClientUpdateOne) SetGroupsFilter(sf *storage.GroupsFilter) *OAuth2ClientUpdateOne {
+	ouo.mutation.SetGroupsFilter(sf)
+	return ouo
+}
+
+// ClearGroupsFilter clears the value of the "groups_filter" field.
+func (ouo *OAuth2ClientUpdateOne) ClearGroupsFilter() *OAuth2ClientUpdateOne {
+	ouo.mutation.ClearGroupsFilter()
+	return ouo
+}
+
+// SetClaimsTransforms sets the "claims_transforms" field.
+func (ouo *OAuth2ClientUpdateOne) SetClaimsTransforms(ct []storage.ClaimsTransform) *OAuth2ClientUpdateOne {
+	ouo.mutation.SetClaimsTransforms(ct)
+	return ouo
+}
+
+// AppendClaimsTransforms appends ct to the "claims_transforms" field.
+func (ouo *OAuth2ClientUpdateOne) AppendClaimsTransforms(ct []storage.ClaimsTransform) *OAuth2ClientUpdateOne {
+	ouo.mutation.AppendClaimsTransforms(ct)
+	return ouo
+}
+
+// ClearClaimsTransforms clears the value of the "claims_transforms" field.
+func (ouo *OAuth2ClientUpdateOne) ClearClaimsTransforms() *OAuth2ClientUpdateOne {
+	ouo.mutation.ClearClaimsTransforms()
+	return ouo
+}
+
+// SetRedirectURIPatterns sets the "redirect_uri_patterns" field.
+func (ouo *OAuth2ClientUpdateOne) SetRedirectURIPatterns(s []string) *OAuth2ClientUpdateOne {
+	ouo.mutation.SetRedirectURIPatterns(s)
+	return ouo
+}
+
+// AppendRedirectURIPatterns appends s to the "redirect_uri_patterns" field.
+func (ouo *OAuth2ClientUpdateOne) AppendRedirectURIPatterns(s []string) *OAuth2ClientUpdateOne {
+	ouo.mutation.AppendRedirectURIPatterns(s)
+	return ouo
+}
+
+// ClearRedirectURIPatterns clears the value of the "redirect_uri_patterns" field.
+func (ouo *OAuth2ClientUpdateOne) ClearRedirectURIPatterns() *OAuth2ClientUpdateOne {
+	ouo.mutation.ClearRedirectURIPatterns()
+	return ouo
+}
+
+// SetPreviousSecret sets the "previous_secret" field.
+func (ouo *OAuth2ClientUpdateOne) SetPreviousSecret(sf *storage.PreviousSecret) *OAuth2ClientUpdateOne {
+	ouo.mutation.SetPreviousSecret(sf)
+	return ouo
+}
+
+// ClearPreviousSecret clears the value of the "previous_secret" field.
+func (ouo *OAuth2ClientUpdateOne) ClearPreviousSecret() *OAuth2ClientUpdateOne {
+	ouo.mutation.ClearPreviousSecret()
+	return ouo
+}
+
+// SetCreatedAt sets the "created_at" field.
+func (ouo *OAuth2ClientUpdateOne) SetCreatedAt(t time.Time) *OAuth2ClientUpdateOne {
+	ouo.mutation.SetCreatedAt(t)
+	return ouo
+}
+
+// SetNillableCreatedAt sets the "created_at" field if the given value is not nil.
+func (ouo *OAuth2ClientUpdateOne) SetNillableCreatedAt(t *time.Time) *OAuth2ClientUpdateOne {
+	if t != nil {
+		ouo.SetCreatedAt(*t)
+	}
+	return ouo
+}
+
+// ClearCreatedAt clears the value of the "created_at" field.
+func (ouo *OAuth2ClientUpdateOne) ClearCreatedAt() *OAuth2ClientUpdateOne {
+	ouo.mutation.ClearCreatedAt()
+	return ouo
+}
+
+// SetLastUsed sets the "last_used" field.
+func (ouo *OAuth2ClientUpdateOne) SetLastUsed(t time.Time) *OAuth2ClientUpdateOne {
+	ouo.mutation.SetLastUsed(t)
+	return ouo
+}
+
+// SetNillableLastUsed sets the "last_used" field if the given value is not nil.
+func (ouo *OAuth2ClientUpdateOne) SetNillableLastUsed(t *time.Time) *OAuth2ClientUpdateOne {
+	if t != nil {
+		ouo.SetLastUsed(*t)
+	}
+	return ouo
+}
+
+// ClearLastUsed clears the value of the "last_used" field.
+func (ouo *OAuth2ClientUpdateOne) ClearLastUsed() *OAuth2ClientUpdateOne {
+	ouo.mutation.ClearLastUsed()
+	return ouo
+}
+
+// SetCreatedBy sets the "created_by" field.
+func (ouo *OAuth2ClientUpdateOne) SetCreatedBy(s string) *OAuth2ClientUpdateOne {
+	ouo.mutation.SetCreatedBy(s)
+	return ouo
+}
+
+// SetNillableCreatedBy sets the "created_by" field if the given value is not nil.
+func (ouo *OAuth2ClientUpdateOne) SetNillableCreatedBy(s *string) *OAuth2ClientUpdateOne {
+	if s != nil {
+		ouo.SetCreatedBy(*s)
+	}
+	return ouo
+}
+
+// ClearCreatedBy clears the value of the "created_by" field.
+func (ouo *OAuth2ClientUpdateOne) ClearCreatedBy() *OAuth2ClientUpdateOne {
+	ouo.mutation.ClearCreatedBy()
+	return ouo
+}
+
+// SetUpdatedAt sets the "updated_at" field.
+func (ouo *OAuth2ClientUpdateOne) SetUpdatedAt(t time.Time) *OAuth2ClientUpdateOne {
+	ouo.mutation.SetUpdatedAt(t)
+	return ouo
+}
+
+// SetNillableUpdatedAt sets the "updated_at" field if the given value is not nil.
+func (ouo *OAuth2ClientUpdateOne) SetNillableUpdatedAt(t *time.Time) *OAuth2ClientUpdateOne {
+	if t != nil {
+		ouo.SetUpdatedAt(*t)
+	}
+	return ouo
+}
+
+// ClearUpdatedAt clears the value of the "updated_at" field.
+func (ouo *OAuth2ClientUpdateOne) ClearUpdatedAt() *OAuth2ClientUpdateOne {
+	ouo.mutation.ClearUpdatedAt()
+	return ouo
+}
+
+// SetDeletedAt sets the "deleted_at" field.
+func (ouo *OAuth2ClientUpdateOne) SetDeletedAt(t time.Time) *OAuth2ClientUpdateOne {
+	ouo.mutation.SetDeletedAt(t)
+	return ouo
+}
+
+// SetNillableDeletedAt sets the "deleted_at" field if the given value is not nil.
+func (ouo *OAuth2ClientUpdateOne) SetNillableDeletedAt(t *time.Time) *OAuth2ClientUpdateOne {
+	if t != nil {
+		ouo.SetDeletedAt(*t)
+	}
+	return ouo
+}
+
+// ClearDeletedAt clears the value of the "deleted_at" field.
+func (ouo *OAuth2ClientUpdateOne) ClearDeletedAt() *OAuth2ClientUpdateOne {
+	ouo.mutation.ClearDeletedAt()
+	return ouo
+}
+
+// SetPolicyURL sets the "policy_url" field.
+func (ouo *OAuth2ClientUpdateOne) SetPolicyURL(s string) *OAuth2ClientUpdateOne {
+	ouo.mutation.SetPolicyURL(s)
+	return ouo
+}
+
+// SetNillablePolicyURL sets the "policy_url" field if the given value is not nil.
+func (ouo *OAuth2ClientUpdateOne) SetNillablePolicyURL(s *string) *OAuth2ClientUpdateOne {
+	if s != nil {
+		ouo.SetPolicyURL(*s)
+	}
+	return ouo
+}
+
+// ClearPolicyURL clears the value of the "policy_url" field.
+func (ouo *OAuth2ClientUpdateOne) ClearPolicyURL() *OAuth2ClientUpdateOne {
+	ouo.mutation.ClearPolicyURL()
+	return ouo
+}
+
+// SetTermsOfServiceURL sets the "terms_of_service_url" field.
+func (ouo *OAuth2ClientUpdateOne) SetTermsOfServiceURL(s string) *OAuth2ClientUpdateOne {
+	ouo.mutation.SetTermsOfServiceURL(s)
+	return ouo
+}
+
+// SetNillableTermsOfServiceURL sets the "terms_of_service_url" field if the given value is not nil.
+func (ouo *OAuth2ClientUpdateOne) SetNillableTermsOfServiceURL(s *string) *OAuth2ClientUpdateOne {
+	if s != nil {
+		ouo.SetTermsOfServiceURL(*s)
+	}
+	return ouo
+}
+
+// ClearTermsOfServiceURL clears the value of the "terms_of_service_url" field.
+func (ouo *OAuth2ClientUpdateOne) ClearTermsOfServiceURL() *OAuth2ClientUpdateOne {
+	ouo.mutation.ClearTermsOfServiceURL()
+	return ouo
+}
+
+// SetAllowedOrigins sets the "allowed_origins" field.
+func (ouo *OAuth2ClientUpdateOne) SetAllowedOrigins(s []string) *OAuth2ClientUpdateOne {
+	ouo.mutation.SetAllowedOrigins(s)
+	return ouo
+}
+
+// AppendAllowedOrigins appends s to the "allowed_origins" field.
+func (ouo *OAuth2ClientUpdateOne) AppendAllowedOrigins(s []string) *OAuth2ClientUpdateOne {
+	ouo.mutation.AppendAllowedOrigins(s)
+	return ouo
+}
+
+// ClearAllowedOrigins clears the value of the "allowed_origins" field.
+func (ouo *OAuth2ClientUpdateOne) ClearAllowedOrigins() *OAuth2ClientUpdateOne {
+	ouo.mutation.ClearAllowedOrigins()
+	return ouo
+}
+
+// SetForwardClaimSourceTokens sets the "forward_claim_source_tokens" field.
+func (ouo *OAuth2ClientUpdateOne) SetForwardClaimSourceTokens(b bool) *OAuth2ClientUpdateOne {
+	ouo.mutation.SetForwardClaimSourceTokens(b)
+	return ouo
+}
+
+// SetNillableForwardClaimSourceTokens sets the "forward_claim_source_tokens" field if the given value is not nil.
+func (ouo *OAuth2ClientUpdateOne) SetNillableForwardClaimSourceTokens(b *bool) *OAuth2ClientUpdateOne {
+	if b != nil {
+		ouo.SetForwardClaimSourceTokens(*b)
+	}
+	return ouo
+}
+
 // Mutation returns the OAuth2ClientMutation object of the builder.
 func (ouo *OAuth2ClientUpdateOne) Mutation() *OAuth2ClientMutation {
 	return ouo.mutation
@@ -458,6 +1074,108 @@ func (ouo *OAuth2ClientUpdateOne) sqlSave(ctx context.Context) (_node *OAuth2Cli
 	if value, ok := ouo.mutation.LogoURL(); ok {
 		_spec.SetField(oauth2client.FieldLogoURL, field.TypeString, value)
 	}
+	if value, ok := ouo.mutation.RefreshTokenPolicy(); ok {
+		_spec.SetField(oauth2client.FieldRefreshTokenPolicy, field.TypeJSON, value)
+	}
+	if ouo.mutation.RefreshTokenPolicyCleared() {
+		_spec.ClearField(oauth2client.FieldRefreshTokenPolicy, field.TypeJSON)
+	}
+	if value, ok := ouo.mutation.TokenExpiry(); ok {
+		_spec.SetField(oauth2client.FieldTokenExpiry, field.TypeJSON, value)
+	}
+	if ouo.mutation.TokenExpiryCleared() {
+		_spec.ClearField(oauth2client.FieldTokenExpiry, field.TypeJSON)
+	}
+	if value, ok := ouo.mutation.GroupsFilter(); ok {
+		_spec.SetField(oauth2client.FieldGroupsFilter, field.TypeJSON, value)
+	}
+	if ouo.mutation.GroupsFilterCleared() {
+		_spec.ClearField(oauth2client.FieldGroupsFilter, field.TypeJSON)
+	}
+	if value, ok := ouo.mutation.ClaimsTransforms(); ok {
+		_spec.SetField(oauth2client.FieldClaimsTransforms, field.TypeJSON, value)
+	}
+	if value, ok := ouo.mutation.AppendedClaimsTransforms(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, oauth2client.FieldClaimsTransforms, value)
+		})
+	}
+	if ouo.mutation.ClaimsTransformsCleared() {
+		_spec.ClearField(oauth2client.FieldClaimsTransforms, field.TypeJSON)
+	}
+	if value, ok := ouo.mutation.RedirectURIPatterns(); ok {
+		_spec.SetField(oauth2client.FieldRedirectURIPatterns, field.TypeJSON, value)
+	}
+	if value, ok := ouo.mutation.AppendedRedirectURIPatterns(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, oauth2client.FieldRedirectURIPatterns, value)
+		})
+	}
+	if ouo.mutation.RedirectURIPatternsCleared() {
+		_spec.ClearField(oauth2client.FieldRedirectURIPatterns, field.TypeJSON)
+	}
+	if value, ok := ouo.mutation.PreviousSecret(); ok {
+		_spec.SetField(oauth2client.FieldPreviousSecret, field.TypeJSON, value)
+	}
+	if ouo.mutation.PreviousSecretCleared() {
+		_spec.ClearField(oauth2client.FieldPreviousSecret, field.TypeJSON)
+	}
+	if value, ok := ouo.mutation.CreatedAt(); ok {
+		_spec.SetField(oauth2client.FieldCreatedAt, field.TypeTime, value)
+	}
+	if ouo.mutation.CreatedAtCleared() {
+		_spec.ClearField(oauth2client.FieldCreatedAt, field.TypeTime)
+	}
+	if value, ok := ouo.mutation.LastUsed(); ok {
+		_spec.SetField(oauth2client.FieldLastUsed, field.TypeTime, value)
+	}
+	if ouo.mutation.LastUsedCleared() {
+		_spec.ClearField(oauth2client.FieldLastUsed, field.TypeTime)
+	}
+	if value, ok := ouo.mutation.CreatedBy(); ok {
+		_spec.SetField(oauth2client.FieldCreatedBy, field.TypeString, value)
+	}
+	if ouo.mutation.CreatedByCleared() {
+		_spec.ClearField(oauth2client.FieldCreatedBy, field.TypeString)
+	}
+	if value, ok := ouo.mutation.UpdatedAt(); ok {
+		_spec.SetField(oauth2client.FieldUpdatedAt, field.TypeTime, value)
+	}
+	if ouo.mutation.UpdatedAtCleared() {
+		_spec.ClearField(oauth2client.FieldUpdatedAt, field.TypeTime)
+	}
+	if value, ok := ouo.mutation.DeletedAt(); ok {
+		_spec.SetField(oauth2client.FieldDeletedAt, field.TypeTime, value)
+	}
+	if ouo.mutation.DeletedAtCleared() {
+		_spec.ClearField(oauth2client.FieldDeletedAt, field.TypeTime)
+	}
+	if value, ok := ouo.mutation.PolicyURL(); ok {
+		_spec.SetField(oauth2client.FieldPolicyURL, field.TypeString, value)
+	}
+	if ouo.mutation.PolicyURLCleared() {
+		_spec.ClearField(oauth2client.FieldPolicyURL, field.TypeString)
+	}
+	if value, ok := ouo.mutation.TermsOfServiceURL(); ok {
+		_spec.SetField(oauth2client.FieldTermsOfServiceURL, field.TypeString, value)
+	}
+	if ouo.mutation.TermsOfServiceURLCleared() {
+		_spec.ClearField(oauth2client.FieldTermsOfServiceURL, field.TypeString)
+	}
+	if value, ok := ouo.mutation.AllowedOrigins(); ok {
+		_spec.SetField(oauth2client.FieldAllowedOrigins, field.TypeJSON, value)
+	}
+	if value, ok := ouo.mutation.AppendedAllowedOrigins(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, oauth2client.FieldAllowedOrigins, value)
+		})
+	}
+	if ouo.mutation.AllowedOriginsCleared() {
+		_spec.ClearField(oauth2client.FieldAllowedOrigins, field.TypeJSON)
+	}
+	if value, ok := ouo.mutation.ForwardClaimSourceTokens(); ok {
+		_spec.SetField(oauth2client.FieldForwardClaimSourceTokens, field.TypeBool, value)
+	}
 	_node = &OAuth2Client{config: ouo.config}
 	_spec.Assign = _node.assignValues
 	_spec.ScanValues = _node.scanValues
@@ -5,6 +5,7 @@ package db
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"entgo.io/ent"
 	"entgo.io/ent/dialect/sql"
@@ -23,7 +24,13 @@ type Password struct {
 	// Username holds the value of the "username" field.
 	Username string `json:"username,omitempty"`
 	// UserID holds the value of the "user_id" field.
-	UserID       string `json:"user_id,omitempty"`
+	UserID string `json:"user_id,omitempty"`
+	// EmailVerified holds the value of the "email_verified" field.
+	EmailVerified bool `json:"email_verified,omitempty"`
+	// FailedAttempts holds the value of the "failed_attempts" field.
+	FailedAttempts int `json:"failed_attempts,omitempty"`
+	// LockedUntil holds the value of the "locked_until" field.
+	LockedUntil  time.Time `json:"locked_until,omitempty"`
 	selectValues sql.SelectValues
 }
 
@@ -34,10 +41,14 @@ func (*Password) scanValues(columns []string) ([]any, error) {
 		switch columns[i] {
 		case password.FieldHash:
 			values[i] = new([]byte)
-		case password.FieldID:
+		case password.FieldEmailVerified:
+			values[i] = new(sql.NullBool)
+		case password.FieldID, password.FieldFailedAttempts:
 			values[i] = new(sql.NullInt64)
 		case password.FieldEmail, password.FieldUsername, password.FieldUserID:
 			values[i] = new(sql.NullString)
+		case password.FieldLockedUntil:
+			values[i] = new(sql.NullTime)
 		default:
 			values[i] = new(sql.UnknownType)
 		}
@@ -83,6 +94,24 @@ func (pa *Password) assignValues(columns []string, values []any) error {
 			} else if value.Valid {
 				pa.UserID = value.String
 			}
+		case password.FieldEmailVerified:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field email_verified", values[i])
+			} else if value.Valid {
+				pa.EmailVerified = value.Bool
+			}
+		case password.FieldFailedAttempts:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field failed_attempts", values[i])
+			} else if value.Valid {
+				pa.FailedAttempts = int(value.Int64)
+			}
+		case password.FieldLockedUntil:
+			if value, ok := values[i].(*sql.NullTime); !ok {
+				return fmt.Errorf("unexpected type %T for field locked_until", values[i])
+			} else if value.Valid {
+				pa.LockedUntil = value.Time
+			}
 		default:
 			pa.selectValues.Set(columns[i], values[i])
 		}
@@ -130,6 +159,15 @@ func (pa *Password) String() string {
 	builder.WriteString(", ")
 	builder.WriteString("user_id=")
 	builder.WriteString(pa.UserID)
+	builder.WriteString(", ")
+	builder.WriteString("email_verified=")
+	builder.WriteString(fmt.Sprintf("%v", pa.EmailVerified))
+	builder.WriteString(", ")
+	builder.WriteString("failed_attempts=")
+	builder.WriteString(fmt.Sprintf("%v", pa.FailedAttempts))
+	builder.WriteString(", ")
+	builder.WriteString("locked_until=")
+	builder.WriteString(pa.LockedUntil.Format(time.ANSIC))
 	builder.WriteByte(')')
 	return builder.String()
 }
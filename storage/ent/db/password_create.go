@@ -6,6 +6,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"entgo.io/ent/dialect/sql/sqlgraph"
 	"entgo.io/ent/schema/field"
@@ -43,6 +44,48 @@ func (pc *PasswordCreate) SetUserID(s string) *PasswordCreate {
 	return pc
 }
 
+// SetEmailVerified sets the "email_verified" field.
+func (pc *PasswordCreate) SetEmailVerified(b bool) *PasswordCreate {
+	pc.mutation.SetEmailVerified(b)
+	return pc
+}
+
+// SetNillableEmailVerified sets the "email_verified" field if the given value is not nil.
+func (pc *PasswordCreate) SetNillableEmailVerified(b *bool) *PasswordCreate {
+	if b != nil {
+		pc.SetEmailVerified(*b)
+	}
+	return pc
+}
+
+// SetFailedAttempts sets the "failed_attempts" field.
+func (pc *PasswordCreate) SetFailedAttempts(i int) *PasswordCreate {
+	pc.mutation.SetFailedAttempts(i)
+	return pc
+}
+
+// SetNillableFailedAttempts sets the "failed_attempts" field if the given value is not nil.
+func (pc *PasswordCreate) SetNillableFailedAttempts(i *int) *PasswordCreate {
+	if i != nil {
+		pc.SetFailedAttempts(*i)
+	}
+	return pc
+}
+
+// SetLockedUntil sets the "locked_until" field.
+func (pc *PasswordCreate) SetLockedUntil(t time.Time) *PasswordCreate {
+	pc.mutation.SetLockedUntil(t)
+	return pc
+}
+
+// SetNillableLockedUntil sets the "locked_until" field if the given value is not nil.
+func (pc *PasswordCreate) SetNillableLockedUntil(t *time.Time) *PasswordCreate {
+	if t != nil {
+		pc.SetLockedUntil(*t)
+	}
+	return pc
+}
+
 // Mutation returns the PasswordMutation object of the builder.
 func (pc *PasswordCreate) Mutation() *PasswordMutation {
 	return pc.mutation
@@ -50,6 +93,7 @@ func (pc *PasswordCreate) Mutation() *PasswordMutation {
 
 // Save creates the Password in the database.
 func (pc *PasswordCreate) Save(ctx context.Context) (*Password, error) {
+	pc.defaults()
 	return withHooks(ctx, pc.sqlSave, pc.mutation, pc.hooks)
 }
 
@@ -75,6 +119,18 @@ func (pc *PasswordCreate) ExecX(ctx context.Context) {
 	}
 }
 
+// defaults sets the default values of the builder before save.
+func (pc *PasswordCreate) defaults() {
+	if _, ok := pc.mutation.EmailVerified(); !ok {
+		v := password.DefaultEmailVerified
+		pc.mutation.SetEmailVerified(v)
+	}
+	if _, ok := pc.mutation.FailedAttempts(); !ok {
+		v := password.DefaultFailedAttempts
+		pc.mutation.SetFailedAttempts(v)
+	}
+}
+
 // check runs all checks and user-defined validators on the builder.
 func (pc *PasswordCreate) check() error {
 	if _, ok := pc.mutation.Email(); !ok {
@@ -104,6 +160,12 @@ func (pc *PasswordCreate) check() error {
 			return &ValidationError{Name: "user_id", err: fmt.Errorf(`db: validator failed for field "Password.user_id": %w`, err)}
 		}
 	}
+	if _, ok := pc.mutation.EmailVerified(); !ok {
+		return &ValidationError{Name: "email_verified", err: errors.New(`db: missing required field "Password.email_verified"`)}
+	}
+	if _, ok := pc.mutation.FailedAttempts(); !ok {
+		return &ValidationError{Name: "failed_attempts", err: errors.New(`db: missing required field "Password.failed_attempts"`)}
+	}
 	return nil
 }
 
@@ -146,6 +208,18 @@ func (pc *PasswordCreate) createSpec() (*Password, *sqlgraph.CreateSpec) {
 		_spec.SetField(password.FieldUserID, field.TypeString, value)
 		_node.UserID = value
 	}
+	if value, ok := pc.mutation.EmailVerified(); ok {
+		_spec.SetField(password.FieldEmailVerified, field.TypeBool, value)
+		_node.EmailVerified = value
+	}
+	if value, ok := pc.mutation.FailedAttempts(); ok {
+		_spec.SetField(password.FieldFailedAttempts, field.TypeInt, value)
+		_node.FailedAttempts = value
+	}
+	if value, ok := pc.mutation.LockedUntil(); ok {
+		_spec.SetField(password.FieldLockedUntil, field.TypeTime, value)
+		_node.LockedUntil = value
+	}
 	return _node, _spec
 }
 
@@ -167,6 +241,7 @@ func (pcb *PasswordCreateBulk) Save(ctx context.Context) ([]*Password, error) {
 	for i := range pcb.builders {
 		func(i int, root context.Context) {
 			builder := pcb.builders[i]
+			builder.defaults()
 			var mut Mutator = MutateFunc(func(ctx context.Context, m Mutation) (Value, error) {
 				mutation, ok := m.(*PasswordMutation)
 				if !ok {
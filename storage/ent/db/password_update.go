@@ -6,6 +6,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"entgo.io/ent/dialect/sql"
 	"entgo.io/ent/dialect/sql/sqlgraph"
@@ -75,6 +76,61 @@ func (pu *PasswordUpdate) SetNillableUserID(s *string) *PasswordUpdate {
 	return pu
 }
 
+// SetEmailVerified sets the "email_verified" field.
+func (pu *PasswordUpdate) SetEmailVerified(b bool) *PasswordUpdate {
+	pu.mutation.SetEmailVerified(b)
+	return pu
+}
+
+// SetNillableEmailVerified sets the "email_verified" field if the given value is not nil.
+func (pu *PasswordUpdate) SetNillableEmailVerified(b *bool) *PasswordUpdate {
+	if b != nil {
+		pu.SetEmailVerified(*b)
+	}
+	return pu
+}
+
+// SetFailedAttempts sets the "failed_attempts" field.
+func (pu *PasswordUpdate) SetFailedAttempts(i int) *PasswordUpdate {
+	pu.mutation.ResetFailedAttempts()
+	pu.mutation.SetFailedAttempts(i)
+	return pu
+}
+
+// SetNillableFailedAttempts sets the "failed_attempts" field if the given value is not nil.
+func (pu *PasswordUpdate) SetNillableFailedAttempts(i *int) *PasswordUpdate {
+	if i != nil {
+		pu.SetFailedAttempts(*i)
+	}
+	return pu
+}
+
+// AddFailedAttempts adds i to the "failed_attempts" field.
+func (pu *PasswordUpdate) AddFailedAttempts(i int) *PasswordUpdate {
+	pu.mutation.AddFailedAttempts(i)
+	return pu
+}
+
+// SetLockedUntil sets the "locked_until" field.
+func (pu *PasswordUpdate) SetLockedUntil(t time.Time) *PasswordUpdate {
+	pu.mutation.SetLockedUntil(t)
+	return pu
+}
+
+// SetNillableLockedUntil sets the "locked_until" field if the given value is not nil.
+func (pu *PasswordUpdate) SetNillableLockedUntil(t *time.Time) *PasswordUpdate {
+	if t != nil {
+		pu.SetLockedUntil(*t)
+	}
+	return pu
+}
+
+// ClearLockedUntil clears the value of the "locked_until" field.
+func (pu *PasswordUpdate) ClearLockedUntil() *PasswordUpdate {
+	pu.mutation.ClearLockedUntil()
+	return pu
+}
+
 // Mutation returns the PasswordMutation object of the builder.
 func (pu *PasswordUpdate) Mutation() *PasswordMutation {
 	return pu.mutation
@@ -151,6 +207,21 @@ func (pu *PasswordUpdate) sqlSave(ctx context.Context) (n int, err error) {
 	if value, ok := pu.mutation.UserID(); ok {
 		_spec.SetField(password.FieldUserID, field.TypeString, value)
 	}
+	if value, ok := pu.mutation.EmailVerified(); ok {
+		_spec.SetField(password.FieldEmailVerified, field.TypeBool, value)
+	}
+	if value, ok := pu.mutation.FailedAttempts(); ok {
+		_spec.SetField(password.FieldFailedAttempts, field.TypeInt, value)
+	}
+	if value, ok := pu.mutation.AddedFailedAttempts(); ok {
+		_spec.AddField(password.FieldFailedAttempts, field.TypeInt, value)
+	}
+	if value, ok := pu.mutation.LockedUntil(); ok {
+		_spec.SetField(password.FieldLockedUntil, field.TypeTime, value)
+	}
+	if pu.mutation.LockedUntilCleared() {
+		_spec.ClearField(password.FieldLockedUntil, field.TypeTime)
+	}
 	if n, err = sqlgraph.UpdateNodes(ctx, pu.driver, _spec); err != nil {
 		if _, ok := err.(*sqlgraph.NotFoundError); ok {
 			err = &NotFoundError{password.Label}
@@ -219,6 +290,61 @@ func (puo *PasswordUpdateOne) SetNillableUserID(s *string) *PasswordUpdateOne {
 	return puo
 }
 
+// SetEmailVerified sets the "email_verified" field.
+func (puo *PasswordUpdateOne) SetEmailVerified(b bool) *PasswordUpdateOne {
+	puo.mutation.SetEmailVerified(b)
+	return puo
+}
+
+// SetNillableEmailVerified sets the "email_verified" field if the given value is not nil.
+func (puo *PasswordUpdateOne) SetNillableEmailVerified(b *bool) *PasswordUpdateOne {
+	if b != nil {
+		puo.SetEmailVerified(*b)
+	}
+	return puo
+}
+
+// SetFailedAttempts sets the "failed_attempts" field.
+func (puo *PasswordUpdateOne) SetFailedAttempts(i int) *PasswordUpdateOne {
+	puo.mutation.ResetFailedAttempts()
+	puo.mutation.SetFailedAttempts(i)
+	return puo
+}
+
+// SetNillableFailedAttempts sets the "failed_attempts" field if the given value is not nil.
+func (puo *PasswordUpdateOne) SetNillableFailedAttempts(i *int) *PasswordUpdateOne {
+	if i != nil {
+		puo.SetFailedAttempts(*i)
+	}
+	return puo
+}
+
+// AddFailedAttempts adds i to the "failed_attempts" field.
+func (puo *PasswordUpdateOne) AddFailedAttempts(i int) *PasswordUpdateOne {
+	puo.mutation.AddFailedAttempts(i)
+	return puo
+}
+
+// SetLockedUntil sets the "locked_until" field.
+func (puo *PasswordUpdateOne) SetLockedUntil(t time.Time) *PasswordUpdateOne {
+	puo.mutation.SetLockedUntil(t)
+	return puo
+}
+
+// SetNillableLockedUntil sets the "locked_until" field if the given value is not nil.
+func (puo *PasswordUpdateOne) SetNillableLockedUntil(t *time.Time) *PasswordUpdateOne {
+	if t != nil {
+		puo.SetLockedUntil(*t)
+	}
+	return puo
+}
+
+// ClearLockedUntil clears the value of the "locked_until" field.
+func (puo *PasswordUpdateOne) ClearLockedUntil() *PasswordUpdateOne {
+	puo.mutation.ClearLockedUntil()
+	return puo
+}
+
 // Mutation returns the PasswordMutation object of the builder.
 func (puo *PasswordUpdateOne) Mutation() *PasswordMutation {
 	return puo.mutation
@@ -325,6 +451,21 @@ func (puo *PasswordUpdateOne) sqlSave(ctx context.Context) (_node *Password, err
 	if value, ok := puo.mutation.UserID(); ok {
 		_spec.SetField(password.FieldUserID, field.TypeString, value)
 	}
+	if value, ok := puo.mutation.EmailVerified(); ok {
+		_spec.SetField(password.FieldEmailVerified, field.TypeBool, value)
+	}
+	if value, ok := puo.mutation.FailedAttempts(); ok {
+		_spec.SetField(password.FieldFailedAttempts, field.TypeInt, value)
+	}
+	if value, ok := puo.mutation.AddedFailedAttempts(); ok {
+		_spec.AddField(password.FieldFailedAttempts, field.TypeInt, value)
+	}
+	if value, ok := puo.mutation.LockedUntil(); ok {
+		_spec.SetField(password.FieldLockedUntil, field.TypeTime, value)
+	}
+	if puo.mutation.LockedUntilCleared() {
+		_spec.ClearField(password.FieldLockedUntil, field.TypeTime)
+	}
 	_node = &Password{config: puo.config}
 	_spec.Assign = _node.assignValues
 	_spec.ScanValues = _node.scanValues
@@ -10,6 +10,7 @@ import (
 
 	"entgo.io/ent"
 	"entgo.io/ent/dialect/sql"
+	"github.com/dexidp/dex/storage"
 	"github.com/dexidp/dex/storage/ent/db/refreshtoken"
 )
 
@@ -47,7 +48,15 @@ type RefreshToken struct {
 	// CreatedAt holds the value of the "created_at" field.
 	CreatedAt time.Time `json:"created_at,omitempty"`
 	// LastUsed holds the value of the "last_used" field.
-	LastUsed     time.Time `json:"last_used,omitempty"`
+	LastUsed time.Time `json:"last_used,omitempty"`
+	// ClaimsCustom holds the value of the "claims_custom" field.
+	ClaimsCustom map[string]interface{} `json:"claims_custom,omitempty"`
+	// ClaimsRequest holds the value of the "claims_request" field.
+	ClaimsRequest *[]byte `json:"claims_request,omitempty"`
+	// ClaimsSources holds the value of the "claims_sources" field.
+	ClaimsSources map[string]storage.ClaimSource `json:"claims_sources,omitempty"`
+	// Issuer holds the value of the "issuer" field.
+	Issuer       string `json:"issuer,omitempty"`
 	selectValues sql.SelectValues
 }
 
@@ -56,11 +65,11 @@ func (*RefreshToken) scanValues(columns []string) ([]any, error) {
 	values := make([]any, len(columns))
 	for i := range columns {
 		switch columns[i] {
-		case refreshtoken.FieldScopes, refreshtoken.FieldClaimsGroups, refreshtoken.FieldConnectorData:
+		case refreshtoken.FieldScopes, refreshtoken.FieldClaimsGroups, refreshtoken.FieldConnectorData, refreshtoken.FieldClaimsCustom, refreshtoken.FieldClaimsRequest, refreshtoken.FieldClaimsSources:
 			values[i] = new([]byte)
 		case refreshtoken.FieldClaimsEmailVerified:
 			values[i] = new(sql.NullBool)
-		case refreshtoken.FieldID, refreshtoken.FieldClientID, refreshtoken.FieldNonce, refreshtoken.FieldClaimsUserID, refreshtoken.FieldClaimsUsername, refreshtoken.FieldClaimsEmail, refreshtoken.FieldClaimsPreferredUsername, refreshtoken.FieldConnectorID, refreshtoken.FieldToken, refreshtoken.FieldObsoleteToken:
+		case refreshtoken.FieldID, refreshtoken.FieldClientID, refreshtoken.FieldNonce, refreshtoken.FieldClaimsUserID, refreshtoken.FieldClaimsUsername, refreshtoken.FieldClaimsEmail, refreshtoken.FieldClaimsPreferredUsername, refreshtoken.FieldConnectorID, refreshtoken.FieldToken, refreshtoken.FieldObsoleteToken, refreshtoken.FieldIssuer:
 			values[i] = new(sql.NullString)
 		case refreshtoken.FieldCreatedAt, refreshtoken.FieldLastUsed:
 			values[i] = new(sql.NullTime)
@@ -179,6 +188,34 @@ func (rt *RefreshToken) assignValues(columns []string, values []any) error {
 			} else if value.Valid {
 				rt.LastUsed = value.Time
 			}
+		case refreshtoken.FieldClaimsCustom:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field claims_custom", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &rt.ClaimsCustom); err != nil {
+					return fmt.Errorf("unmarshal field claims_custom: %w", err)
+				}
+			}
+		case refreshtoken.FieldClaimsRequest:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field claims_request", values[i])
+			} else if value != nil {
+				rt.ClaimsRequest = value
+			}
+		case refreshtoken.FieldClaimsSources:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field claims_sources", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &rt.ClaimsSources); err != nil {
+					return fmt.Errorf("unmarshal field claims_sources: %w", err)
+				}
+			}
+		case refreshtoken.FieldIssuer:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field issuer", values[i])
+			} else if value.Valid {
+				rt.Issuer = value.String
+			}
 		default:
 			rt.selectValues.Set(columns[i], values[i])
 		}
@@ -261,6 +298,20 @@ func (rt *RefreshToken) String() string {
 	builder.WriteString(", ")
 	builder.WriteString("last_used=")
 	builder.WriteString(rt.LastUsed.Format(time.ANSIC))
+	builder.WriteString(", ")
+	builder.WriteString("claims_custom=")
+	builder.WriteString(fmt.Sprintf("%v", rt.ClaimsCustom))
+	builder.WriteString(", ")
+	if v := rt.ClaimsRequest; v != nil {
+		builder.WriteString("claims_request=")
+		builder.WriteString(fmt.Sprintf("%v", *v))
+	}
+	builder.WriteString(", ")
+	builder.WriteString("claims_sources=")
+	builder.WriteString(fmt.Sprintf("%v", rt.ClaimsSources))
+	builder.WriteString(", ")
+	builder.WriteString("issuer=")
+	builder.WriteString(rt.Issuer)
 	builder.WriteByte(')')
 	return builder.String()
 }
@@ -43,6 +43,14 @@ const (
 	FieldCreatedAt = "created_at"
 	// FieldLastUsed holds the string denoting the last_used field in the database.
 	FieldLastUsed = "last_used"
+	// FieldClaimsCustom holds the string denoting the claims_custom field in the database.
+	FieldClaimsCustom = "claims_custom"
+	// FieldClaimsRequest holds the string denoting the claims_request field in the database.
+	FieldClaimsRequest = "claims_request"
+	// FieldClaimsSources holds the string denoting the claims_sources field in the database.
+	FieldClaimsSources = "claims_sources"
+	// FieldIssuer holds the string denoting the issuer field in the database.
+	FieldIssuer = "issuer"
 	// Table holds the table name of the refreshtoken in the database.
 	Table = "refresh_tokens"
 )
@@ -65,6 +73,10 @@ var Columns = []string{
 	FieldObsoleteToken,
 	FieldCreatedAt,
 	FieldLastUsed,
+	FieldClaimsCustom,
+	FieldClaimsRequest,
+	FieldClaimsSources,
+	FieldIssuer,
 }
 
 // ValidColumn reports if the column name is valid (part of the table columns).
@@ -100,6 +112,8 @@ var (
 	DefaultCreatedAt func() time.Time
 	// DefaultLastUsed holds the default value on creation for the "last_used" field.
 	DefaultLastUsed func() time.Time
+	// DefaultIssuer holds the default value on creation for the "issuer" field.
+	DefaultIssuer string
 	// IDValidator is a validator for the "id" field. It is called by the builders before save.
 	IDValidator func(string) error
 )
@@ -171,3 +185,8 @@ func ByCreatedAt(opts ...sql.OrderTermOption) OrderOption {
 func ByLastUsed(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldLastUsed, opts...).ToFunc()
 }
+
+// ByIssuer orders the results by the issuer field.
+func ByIssuer(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldIssuer, opts...).ToFunc()
+}
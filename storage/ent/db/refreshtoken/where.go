@@ -129,6 +129,16 @@ func LastUsed(v time.Time) predicate.RefreshToken {
 	return predicate.RefreshToken(sql.FieldEQ(FieldLastUsed, v))
 }
 
+// ClaimsRequest applies equality check predicate on the "claims_request" field. It's identical to ClaimsRequestEQ.
+func ClaimsRequest(v []byte) predicate.RefreshToken {
+	return predicate.RefreshToken(sql.FieldEQ(FieldClaimsRequest, v))
+}
+
+// Issuer applies equality check predicate on the "issuer" field. It's identical to IssuerEQ.
+func Issuer(v string) predicate.RefreshToken {
+	return predicate.RefreshToken(sql.FieldEQ(FieldIssuer, v))
+}
+
 // ClientIDEQ applies the EQ predicate on the "client_id" field.
 func ClientIDEQ(v string) predicate.RefreshToken {
 	return predicate.RefreshToken(sql.FieldEQ(FieldClientID, v))
@@ -874,6 +884,141 @@ func LastUsedLTE(v time.Time) predicate.RefreshToken {
 	return predicate.RefreshToken(sql.FieldLTE(FieldLastUsed, v))
 }
 
+// ClaimsCustomIsNil applies the IsNil predicate on the "claims_custom" field.
+func ClaimsCustomIsNil() predicate.RefreshToken {
+	return predicate.RefreshToken(sql.FieldIsNull(FieldClaimsCustom))
+}
+
+// ClaimsCustomNotNil applies the NotNil predicate on the "claims_custom" field.
+func ClaimsCustomNotNil() predicate.RefreshToken {
+	return predicate.RefreshToken(sql.FieldNotNull(FieldClaimsCustom))
+}
+
+// ClaimsRequestEQ applies the EQ predicate on the "claims_request" field.
+func ClaimsRequestEQ(v []byte) predicate.RefreshToken {
+	return predicate.RefreshToken(sql.FieldEQ(FieldClaimsRequest, v))
+}
+
+// ClaimsRequestNEQ applies the NEQ predicate on the "claims_request" field.
+func ClaimsRequestNEQ(v []byte) predicate.RefreshToken {
+	return predicate.RefreshToken(sql.FieldNEQ(FieldClaimsRequest, v))
+}
+
+// ClaimsRequestIn applies the In predicate on the "claims_request" field.
+func ClaimsRequestIn(vs ...[]byte) predicate.RefreshToken {
+	return predicate.RefreshToken(sql.FieldIn(FieldClaimsRequest, vs...))
+}
+
+// ClaimsRequestNotIn applies the NotIn predicate on the "claims_request" field.
+func ClaimsRequestNotIn(vs ...[]byte) predicate.RefreshToken {
+	return predicate.RefreshToken(sql.FieldNotIn(FieldClaimsRequest, vs...))
+}
+
+// ClaimsRequestGT applies the GT predicate on the "claims_request" field.
+func ClaimsRequestGT(v []byte) predicate.RefreshToken {
+	return predicate.RefreshToken(sql.FieldGT(FieldClaimsRequest, v))
+}
+
+// ClaimsRequestGTE applies the GTE predicate on the "claims_request" field.
+func ClaimsRequestGTE(v []byte) predicate.RefreshToken {
+	return predicate.RefreshToken(sql.FieldGTE(FieldClaimsRequest, v))
+}
+
+// ClaimsRequestLT applies the LT predicate on the "claims_request" field.
+func ClaimsRequestLT(v []byte) predicate.RefreshToken {
+	return predicate.RefreshToken(sql.FieldLT(FieldClaimsRequest, v))
+}
+
+// ClaimsRequestLTE applies the LTE predicate on the "claims_request" field.
+func ClaimsRequestLTE(v []byte) predicate.RefreshToken {
+	return predicate.RefreshToken(sql.FieldLTE(FieldClaimsRequest, v))
+}
+
+// ClaimsRequestIsNil applies the IsNil predicate on the "claims_request" field.
+func ClaimsRequestIsNil() predicate.RefreshToken {
+	return predicate.RefreshToken(sql.FieldIsNull(FieldClaimsRequest))
+}
+
+// ClaimsRequestNotNil applies the NotNil predicate on the "claims_request" field.
+func ClaimsRequestNotNil() predicate.RefreshToken {
+	return predicate.RefreshToken(sql.FieldNotNull(FieldClaimsRequest))
+}
+
+// ClaimsSourcesIsNil applies the IsNil predicate on the "claims_sources" field.
+func ClaimsSourcesIsNil() predicate.RefreshToken {
+	return predicate.RefreshToken(sql.FieldIsNull(FieldClaimsSources))
+}
+
+// ClaimsSourcesNotNil applies the NotNil predicate on the "claims_sources" field.
+func ClaimsSourcesNotNil() predicate.RefreshToken {
+	return predicate.RefreshToken(sql.FieldNotNull(FieldClaimsSources))
+}
+
+// IssuerEQ applies the EQ predicate on the "issuer" field.
+func IssuerEQ(v string) predicate.RefreshToken {
+	return predicate.RefreshToken(sql.FieldEQ(FieldIssuer, v))
+}
+
+// IssuerNEQ applies the NEQ predicate on the "issuer" field.
+func IssuerNEQ(v string) predicate.RefreshToken {
+	return predicate.RefreshToken(sql.FieldNEQ(FieldIssuer, v))
+}
+
+// IssuerIn applies the In predicate on the "issuer" field.
+func IssuerIn(vs ...string) predicate.RefreshToken {
+	return predicate.RefreshToken(sql.FieldIn(FieldIssuer, vs...))
+}
+
+// IssuerNotIn applies the NotIn predicate on the "issuer" field.
+func IssuerNotIn(vs ...string) predicate.RefreshToken {
+	return predicate.RefreshToken(sql.FieldNotIn(FieldIssuer, vs...))
+}
+
+// IssuerGT applies the GT predicate on the "issuer" field.
+func IssuerGT(v string) predicate.RefreshToken {
+	return predicate.RefreshToken(sql.FieldGT(FieldIssuer, v))
+}
+
+// IssuerGTE applies the GTE predicate on the "issuer" field.
+func IssuerGTE(v string) predicate.RefreshToken {
+	return predicate.RefreshToken(sql.FieldGTE(FieldIssuer, v))
+}
+
+// IssuerLT applies the LT predicate on the "issuer" field.
+func IssuerLT(v string) predicate.RefreshToken {
+	return predicate.RefreshToken(sql.FieldLT(FieldIssuer, v))
+}
+
+// IssuerLTE applies the LTE predicate on the "issuer" field.
+func IssuerLTE(v string) predicate.RefreshToken {
+	return predicate.RefreshToken(sql.FieldLTE(FieldIssuer, v))
+}
+
+// IssuerContains applies the Contains predicate on the "issuer" field.
+func IssuerContains(v string) predicate.RefreshToken {
+	return predicate.RefreshToken(sql.FieldContains(FieldIssuer, v))
+}
+
+// IssuerHasPrefix applies the HasPrefix predicate on the "issuer" field.
+func IssuerHasPrefix(v string) predicate.RefreshToken {
+	return predicate.RefreshToken(sql.FieldHasPrefix(FieldIssuer, v))
+}
+
+// IssuerHasSuffix applies the HasSuffix predicate on the "issuer" field.
+func IssuerHasSuffix(v string) predicate.RefreshToken {
+	return predicate.RefreshToken(sql.FieldHasSuffix(FieldIssuer, v))
+}
+
+// IssuerEqualFold applies the EqualFold predicate on the "issuer" field.
+func IssuerEqualFold(v string) predicate.RefreshToken {
+	return predicate.RefreshToken(sql.FieldEqualFold(FieldIssuer, v))
+}
+
+// IssuerContainsFold applies the ContainsFold predicate on the "issuer" field.
+func IssuerContainsFold(v string) predicate.RefreshToken {
+	return predicate.RefreshToken(sql.FieldContainsFold(FieldIssuer, v))
+}
+
 // And groups predicates with the AND operator between them.
 func And(predicates ...predicate.RefreshToken) predicate.RefreshToken {
 	return predicate.RefreshToken(sql.AndPredicates(predicates...))
@@ -10,6 +10,7 @@ import (
 
 	"entgo.io/ent/dialect/sql/sqlgraph"
 	"entgo.io/ent/schema/field"
+	"github.com/dexidp/dex/storage"
 	"github.com/dexidp/dex/storage/ent/db/refreshtoken"
 )
 
@@ -150,6 +151,38 @@ func (rtc *RefreshTokenCreate) SetNillableLastUsed(t *time.Time) *RefreshTokenCr
 	return rtc
 }
 
+// SetClaimsCustom sets the "claims_custom" field.
+func (rtc *RefreshTokenCreate) SetClaimsCustom(m map[string]interface{}) *RefreshTokenCreate {
+	rtc.mutation.SetClaimsCustom(m)
+	return rtc
+}
+
+// SetClaimsRequest sets the "claims_request" field.
+func (rtc *RefreshTokenCreate) SetClaimsRequest(b []byte) *RefreshTokenCreate {
+	rtc.mutation.SetClaimsRequest(b)
+	return rtc
+}
+
+// SetClaimsSources sets the "claims_sources" field.
+func (rtc *RefreshTokenCreate) SetClaimsSources(m map[string]storage.ClaimSource) *RefreshTokenCreate {
+	rtc.mutation.SetClaimsSources(m)
+	return rtc
+}
+
+// SetIssuer sets the "issuer" field.
+func (rtc *RefreshTokenCreate) SetIssuer(s string) *RefreshTokenCreate {
+	rtc.mutation.SetIssuer(s)
+	return rtc
+}
+
+// SetNillableIssuer sets the "issuer" field if the given value is not nil.
+func (rtc *RefreshTokenCreate) SetNillableIssuer(s *string) *RefreshTokenCreate {
+	if s != nil {
+		rtc.SetIssuer(*s)
+	}
+	return rtc
+}
+
 // SetID sets the "id" field.
 func (rtc *RefreshTokenCreate) SetID(s string) *RefreshTokenCreate {
 	rtc.mutation.SetID(s)
@@ -211,6 +244,10 @@ func (rtc *RefreshTokenCreate) defaults() {
 		v := refreshtoken.DefaultLastUsed()
 		rtc.mutation.SetLastUsed(v)
 	}
+	if _, ok := rtc.mutation.Issuer(); !ok {
+		v := refreshtoken.DefaultIssuer
+		rtc.mutation.SetIssuer(v)
+	}
 }
 
 // check runs all checks and user-defined validators on the builder.
@@ -281,6 +318,9 @@ func (rtc *RefreshTokenCreate) check() error {
 	if _, ok := rtc.mutation.LastUsed(); !ok {
 		return &ValidationError{Name: "last_used", err: errors.New(`db: missing required field "RefreshToken.last_used"`)}
 	}
+	if _, ok := rtc.mutation.Issuer(); !ok {
+		return &ValidationError{Name: "issuer", err: errors.New(`db: missing required field "RefreshToken.issuer"`)}
+	}
 	if v, ok := rtc.mutation.ID(); ok {
 		if err := refreshtoken.IDValidator(v); err != nil {
 			return &ValidationError{Name: "id", err: fmt.Errorf(`db: validator failed for field "RefreshToken.id": %w`, err)}
@@ -381,6 +421,22 @@ func (rtc *RefreshTokenCreate) createSpec() (*RefreshToken, *sqlgraph.CreateSpec
 		_spec.SetField(refreshtoken.FieldLastUsed, field.TypeTime, value)
 		_node.LastUsed = value
 	}
+	if value, ok := rtc.mutation.ClaimsCustom(); ok {
+		_spec.SetField(refreshtoken.FieldClaimsCustom, field.TypeJSON, value)
+		_node.ClaimsCustom = value
+	}
+	if value, ok := rtc.mutation.ClaimsRequest(); ok {
+		_spec.SetField(refreshtoken.FieldClaimsRequest, field.TypeBytes, value)
+		_node.ClaimsRequest = &value
+	}
+	if value, ok := rtc.mutation.ClaimsSources(); ok {
+		_spec.SetField(refreshtoken.FieldClaimsSources, field.TypeJSON, value)
+		_node.ClaimsSources = value
+	}
+	if value, ok := rtc.mutation.Issuer(); ok {
+		_spec.SetField(refreshtoken.FieldIssuer, field.TypeString, value)
+		_node.Issuer = value
+	}
 	return _node, _spec
 }
 
@@ -12,6 +12,7 @@ import (
 	"entgo.io/ent/dialect/sql/sqlgraph"
 	"entgo.io/ent/dialect/sql/sqljson"
 	"entgo.io/ent/schema/field"
+	"github.com/dexidp/dex/storage"
 	"github.com/dexidp/dex/storage/ent/db/predicate"
 	"github.com/dexidp/dex/storage/ent/db/refreshtoken"
 )
@@ -245,6 +246,56 @@ func (rtu *RefreshTokenUpdate) SetNillableLastUsed(t *time.Time) *RefreshTokenUp
 	return rtu
 }
 
+// SetClaimsCustom sets the "claims_custom" field.
+func (rtu *RefreshTokenUpdate) SetClaimsCustom(m map[string]interface{}) *RefreshTokenUpdate {
+	rtu.mutation.SetClaimsCustom(m)
+	return rtu
+}
+
+// ClearClaimsCustom clears the value of the "claims_custom" field.
+func (rtu *RefreshTokenUpdate) ClearClaimsCustom() *RefreshTokenUpdate {
+	rtu.mutation.ClearClaimsCustom()
+	return rtu
+}
+
+// SetClaimsRequest sets the "claims_request" field.
+func (rtu *RefreshTokenUpdate) SetClaimsRequest(b []byte) *RefreshTokenUpdate {
+	rtu.mutation.SetClaimsRequest(b)
+	return rtu
+}
+
+// ClearClaimsRequest clears the value of the "claims_request" field.
+func (rtu *RefreshTokenUpdate) ClearClaimsRequest() *RefreshTokenUpdate {
+	rtu.mutation.ClearClaimsRequest()
+	return rtu
+}
+
+// SetClaimsSources sets the "claims_sources" field.
+func (rtu *RefreshTokenUpdate) SetClaimsSources(m map[string]storage.ClaimSource) *RefreshTokenUpdate {
+	rtu.mutation.SetClaimsSources(m)
+	return rtu
+}
+
+// ClearClaimsSources clears the value of the "claims_sources" field.
+func (rtu *RefreshTokenUpdate) ClearClaimsSources() *RefreshTokenUpdate {
+	rtu.mutation.ClearClaimsSources()
+	return rtu
+}
+
+// SetIssuer sets the "issuer" field.
+func (rtu *RefreshTokenUpdate) SetIssuer(s string) *RefreshTokenUpdate {
+	rtu.mutation.SetIssuer(s)
+	return rtu
+}
+
+// SetNillableIssuer sets the "issuer" field if the given value is not nil.
+func (rtu *RefreshTokenUpdate) SetNillableIssuer(s *string) *RefreshTokenUpdate {
+	if s != nil {
+		rtu.SetIssuer(*s)
+	}
+	return rtu
+}
+
 // Mutation returns the RefreshTokenMutation object of the builder.
 func (rtu *RefreshTokenUpdate) Mutation() *RefreshTokenMutation {
 	return rtu.mutation
@@ -388,6 +439,27 @@ func (rtu *RefreshTokenUpdate) sqlSave(ctx context.Context) (n int, err error) {
 	if value, ok := rtu.mutation.LastUsed(); ok {
 		_spec.SetField(refreshtoken.FieldLastUsed, field.TypeTime, value)
 	}
+	if value, ok := rtu.mutation.ClaimsCustom(); ok {
+		_spec.SetField(refreshtoken.FieldClaimsCustom, field.TypeJSON, value)
+	}
+	if rtu.mutation.ClaimsCustomCleared() {
+		_spec.ClearField(refreshtoken.FieldClaimsCustom, field.TypeJSON)
+	}
+	if value, ok := rtu.mutation.ClaimsRequest(); ok {
+		_spec.SetField(refreshtoken.FieldClaimsRequest, field.TypeBytes, value)
+	}
+	if rtu.mutation.ClaimsRequestCleared() {
+		_spec.ClearField(refreshtoken.FieldClaimsRequest, field.TypeBytes)
+	}
+	if value, ok := rtu.mutation.ClaimsSources(); ok {
+		_spec.SetField(refreshtoken.FieldClaimsSources, field.TypeJSON, value)
+	}
+	if rtu.mutation.ClaimsSourcesCleared() {
+		_spec.ClearField(refreshtoken.FieldClaimsSources, field.TypeJSON)
+	}
+	if value, ok := rtu.mutation.Issuer(); ok {
+		_spec.SetField(refreshtoken.FieldIssuer, field.TypeString, value)
+	}
 	if n, err = sqlgraph.UpdateNodes(ctx, rtu.driver, _spec); err != nil {
 		if _, ok := err.(*sqlgraph.NotFoundError); ok {
 			err = &NotFoundError{refreshtoken.Label}
@@ -624,6 +696,56 @@ func (rtuo *RefreshTokenUpdateOne) SetNillableLastUsed(t *time.Time) *RefreshTok
 	return rtuo
 }
 
+// SetClaimsCustom sets the "claims_custom" field.
+func (rtuo *RefreshTokenUpdateOne) SetClaimsCustom(m map[string]interface{}) *RefreshTokenUpdateOne {
+	rtuo.mutation.SetClaimsCustom(m)
+	return rtuo
+}
+
+// ClearClaimsCustom clears the value of the "claims_custom" field.
+func (rtuo *RefreshTokenUpdateOne) ClearClaimsCustom() *RefreshTokenUpdateOne {
+	rtuo.mutation.ClearClaimsCustom()
+	return rtuo
+}
+
+// SetClaimsRequest sets the "claims_request" field.
+func (rtuo *RefreshTokenUpdateOne) SetClaimsRequest(b []byte) *RefreshTokenUpdateOne {
+	rtuo.mutation.SetClaimsRequest(b)
+	return rtuo
+}
+
+// ClearClaimsRequest clears the value of the "claims_request" field.
+func (rtuo *RefreshTokenUpdateOne) ClearClaimsRequest() *RefreshTokenUpdateOne {
+	rtuo.mutation.ClearClaimsRequest()
+	return rtuo
+}
+
+// SetClaimsSources sets the "claims_sources" field.
+func (rtuo *RefreshTokenUpdateOne) SetClaimsSources(m map[string]storage.ClaimSource) *RefreshTokenUpdateOne {
+	rtuo.mutation.SetClaimsSources(m)
+	return rtuo
+}
+
+// ClearClaimsSources clears the value of the "claims_sources" field.
+func (rtuo *RefreshTokenUpdateOne) ClearClaimsSources() *RefreshTokenUpdateOne {
+	rtuo.mutation.ClearClaimsSources()
+	return rtuo
+}
+
+// SetIssuer sets the "issuer" field.
+func (rtuo *RefreshTokenUpdateOne) SetIssuer(s string) *RefreshTokenUpdateOne {
+	rtuo.mutation.SetIssuer(s)
+	return rtuo
+}
+
+// SetNillableIssuer sets the "issuer" field if the given value is not nil.
+func (rtuo *RefreshTokenUpdateOne) SetNillableIssuer(s *string) *RefreshTokenUpdateOne {
+	if s != nil {
+		rtuo.SetIssuer(*s)
+	}
+	return rtuo
+}
+
 // Mutation returns the RefreshTokenMutation object of the builder.
 func (rtuo *RefreshTokenUpdateOne) Mutation() *RefreshTokenMutation {
 	return rtuo.mutation
@@ -797,6 +919,27 @@ func (rtuo *RefreshTokenUpdateOne) sqlSave(ctx context.Context) (_node *RefreshT
 	if value, ok := rtuo.mutation.LastUsed(); ok {
 		_spec.SetField(refreshtoken.FieldLastUsed, field.TypeTime, value)
 	}
+	if value, ok := rtuo.mutation.ClaimsCustom(); ok {
+		_spec.SetField(refreshtoken.FieldClaimsCustom, field.TypeJSON, value)
+	}
+	if rtuo.mutation.ClaimsCustomCleared() {
+		_spec.ClearField(refreshtoken.FieldClaimsCustom, field.TypeJSON)
+	}
+	if value, ok := rtuo.mutation.ClaimsRequest(); ok {
+		_spec.SetField(refreshtoken.FieldClaimsRequest, field.TypeBytes, value)
+	}
+	if rtuo.mutation.ClaimsRequestCleared() {
+		_spec.ClearField(refreshtoken.FieldClaimsRequest, field.TypeBytes)
+	}
+	if value, ok := rtuo.mutation.ClaimsSources(); ok {
+		_spec.SetField(refreshtoken.FieldClaimsSources, field.TypeJSON, value)
+	}
+	if rtuo.mutation.ClaimsSourcesCleared() {
+		_spec.ClearField(refreshtoken.FieldClaimsSources, field.TypeJSON)
+	}
+	if value, ok := rtuo.mutation.Issuer(); ok {
+		_spec.SetField(refreshtoken.FieldIssuer, field.TypeString, value)
+	}
 	_node = &RefreshToken{config: rtuo.config}
 	_spec.Assign = _node.assignValues
 	_spec.ScanValues = _node.scanValues
@@ -15,6 +15,7 @@ import (
 	"github.com/dexidp/dex/storage/ent/db/offlinesession"
 	"github.com/dexidp/dex/storage/ent/db/password"
 	"github.com/dexidp/dex/storage/ent/db/refreshtoken"
+	"github.com/dexidp/dex/storage/ent/db/webhook"
 	"github.com/dexidp/dex/storage/ent/schema"
 )
 
@@ -64,6 +65,10 @@ func init() {
 	authcodeDescCodeChallengeMethod := authcodeFields[15].Descriptor()
 	// authcode.DefaultCodeChallengeMethod holds the default value on creation for the code_challenge_method field.
 	authcode.DefaultCodeChallengeMethod = authcodeDescCodeChallengeMethod.Default.(string)
+	// authcodeDescIssuer is the schema descriptor for issuer field.
+	authcodeDescIssuer := authcodeFields[19].Descriptor()
+	// authcode.DefaultIssuer holds the default value on creation for the issuer field.
+	authcode.DefaultIssuer = authcodeDescIssuer.Default.(string)
 	// authcodeDescID is the schema descriptor for id field.
 	authcodeDescID := authcodeFields[0].Descriptor()
 	// authcode.IDValidator is a validator for the "id" field. It is called by the builders before save.
@@ -170,6 +175,10 @@ func init() {
 	oauth2clientDescLogoURL := oauth2clientFields[6].Descriptor()
 	// oauth2client.LogoURLValidator is a validator for the "logo_url" field. It is called by the builders before save.
 	oauth2client.LogoURLValidator = oauth2clientDescLogoURL.Validators[0].(func(string) error)
+	// oauth2clientDescForwardClaimSourceTokens is the schema descriptor for forward_claim_source_tokens field.
+	oauth2clientDescForwardClaimSourceTokens := oauth2clientFields[21].Descriptor()
+	// oauth2client.DefaultForwardClaimSourceTokens holds the default value on creation for the forward_claim_source_tokens field.
+	oauth2client.DefaultForwardClaimSourceTokens = oauth2clientDescForwardClaimSourceTokens.Default.(bool)
 	// oauth2clientDescID is the schema descriptor for id field.
 	oauth2clientDescID := oauth2clientFields[0].Descriptor()
 	// oauth2client.IDValidator is a validator for the "id" field. It is called by the builders before save.
@@ -216,6 +225,14 @@ func init() {
 	passwordDescUserID := passwordFields[3].Descriptor()
 	// password.UserIDValidator is a validator for the "user_id" field. It is called by the builders before save.
 	password.UserIDValidator = passwordDescUserID.Validators[0].(func(string) error)
+	// passwordDescEmailVerified is the schema descriptor for email_verified field.
+	passwordDescEmailVerified := passwordFields[4].Descriptor()
+	// password.DefaultEmailVerified holds the default value on creation for the email_verified field.
+	password.DefaultEmailVerified = passwordDescEmailVerified.Default.(bool)
+	// passwordDescFailedAttempts is the schema descriptor for failed_attempts field.
+	passwordDescFailedAttempts := passwordFields[5].Descriptor()
+	// password.DefaultFailedAttempts holds the default value on creation for the failed_attempts field.
+	password.DefaultFailedAttempts = passwordDescFailedAttempts.Default.(int)
 	refreshtokenFields := schema.RefreshToken{}.Fields()
 	_ = refreshtokenFields
 	// refreshtokenDescClientID is the schema descriptor for client_id field.
@@ -262,8 +279,40 @@ func init() {
 	refreshtokenDescLastUsed := refreshtokenFields[15].Descriptor()
 	// refreshtoken.DefaultLastUsed holds the default value on creation for the last_used field.
 	refreshtoken.DefaultLastUsed = refreshtokenDescLastUsed.Default.(func() time.Time)
+	// refreshtokenDescIssuer is the schema descriptor for issuer field.
+	refreshtokenDescIssuer := refreshtokenFields[19].Descriptor()
+	// refreshtoken.DefaultIssuer holds the default value on creation for the issuer field.
+	refreshtoken.DefaultIssuer = refreshtokenDescIssuer.Default.(string)
 	// refreshtokenDescID is the schema descriptor for id field.
 	refreshtokenDescID := refreshtokenFields[0].Descriptor()
 	// refreshtoken.IDValidator is a validator for the "id" field. It is called by the builders before save.
 	refreshtoken.IDValidator = refreshtokenDescID.Validators[0].(func(string) error)
+	webhookFields := schema.Webhook{}.Fields()
+	_ = webhookFields
+	// webhookDescSecret is the schema descriptor for secret field.
+	webhookDescSecret := webhookFields[1].Descriptor()
+	// webhook.SecretValidator is a validator for the "secret" field. It is called by the builders before save.
+	webhook.SecretValidator = webhookDescSecret.Validators[0].(func(string) error)
+	// webhookDescURL is the schema descriptor for url field.
+	webhookDescURL := webhookFields[3].Descriptor()
+	// webhook.URLValidator is a validator for the "url" field. It is called by the builders before save.
+	webhook.URLValidator = webhookDescURL.Validators[0].(func(string) error)
+	// webhookDescID is the schema descriptor for id field.
+	webhookDescID := webhookFields[0].Descriptor()
+	// webhook.IDValidator is a validator for the "id" field. It is called by the builders before save.
+	webhook.IDValidator = func() func(string) error {
+		validators := webhookDescID.Validators
+		fns := [...]func(string) error{
+			validators[0].(func(string) error),
+			validators[1].(func(string) error),
+		}
+		return func(id string) error {
+			for _, fn := range fns {
+				if err := fn(id); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}()
 }
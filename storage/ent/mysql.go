@@ -35,6 +35,10 @@ type MySQL struct {
 
 	SSL SSL `json:"ssl"`
 
+	// SoftDelete marks deleted clients and connectors as deleted instead of
+	// removing them.
+	SoftDelete bool `json:"softDelete"`
+
 	params map[string]string
 }
 
@@ -49,6 +53,7 @@ func (m *MySQL) Open(logger *slog.Logger) (storage.Storage, error) {
 	databaseClient := client.NewDatabase(
 		client.WithClient(db.NewClient(db.Driver(drv))),
 		client.WithHasher(sha256.New),
+		client.WithSoftDelete(m.SoftDelete),
 		// Set tx isolation leve for each transaction as dex does for postgres
 		client.WithTxIsolationLevel(sql.LevelSerializable),
 	)
@@ -87,6 +92,18 @@ func (m *MySQL) driver() (*entSQL.Driver, error) {
 		drv.DB().SetMaxIdleConns(m.MaxIdleConns)
 	}
 
+	if m.MaxOpenConns != 0 {
+		drv.DB().SetMaxOpenConns(m.MaxOpenConns)
+	}
+
+	if m.ConnMaxLifetime != 0 {
+		drv.DB().SetConnMaxLifetime(time.Duration(m.ConnMaxLifetime) * time.Second)
+	}
+
+	if m.ConnMaxIdleTime != 0 {
+		drv.DB().SetConnMaxIdleTime(time.Duration(m.ConnMaxIdleTime) * time.Second)
+	}
+
 	return drv, nil
 }
 
@@ -97,7 +114,9 @@ func (m *MySQL) dsn(tlsConfig string) string {
 		DBName:               m.Database,
 		AllowNativePasswords: true,
 
-		Timeout: time.Second * time.Duration(m.ConnectionTimeout),
+		Timeout:      time.Second * time.Duration(m.ConnectionTimeout),
+		ReadTimeout:  time.Second * time.Duration(m.StatementTimeout),
+		WriteTimeout: time.Second * time.Duration(m.StatementTimeout),
 
 		TLSConfig: tlsConfig,
 
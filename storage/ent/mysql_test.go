@@ -71,6 +71,7 @@ func TestMySQL(t *testing.T) {
 	}
 	conformance.RunTests(t, newStorage)
 	conformance.RunTransactionTests(t, newStorage)
+	conformance.RunConcurrencyTests(t, newStorage)
 }
 
 func TestMySQLDSN(t *testing.T) {
@@ -119,6 +120,15 @@ func TestMySQLDSN(t *testing.T) {
 			},
 			desiredDSN: "test:test@/test?checkConnLiveness=false&parseTime=true&timeout=5s&tls=false&maxAllowedPacket=0",
 		},
+		{
+			name: "Statement timeout",
+			cfg: &MySQL{
+				NetworkDB: NetworkDB{
+					StatementTimeout: 30,
+				},
+			},
+			desiredDSN: "/?checkConnLiveness=false&parseTime=true&readTimeout=30s&tls=false&writeTimeout=30s&maxAllowedPacket=0",
+		},
 		{
 			name: "SSL",
 			cfg: &MySQL{
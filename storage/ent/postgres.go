@@ -33,6 +33,10 @@ type Postgres struct {
 	NetworkDB
 
 	SSL SSL `json:"ssl"`
+
+	// SoftDelete marks deleted clients and connectors as deleted instead of
+	// removing them.
+	SoftDelete bool `json:"softDelete"`
 }
 
 // Open always returns a new in sqlite3 storage.
@@ -46,6 +50,7 @@ func (p *Postgres) Open(logger *slog.Logger) (storage.Storage, error) {
 	databaseClient := client.NewDatabase(
 		client.WithClient(db.NewClient(db.Driver(drv))),
 		client.WithHasher(sha256.New),
+		client.WithSoftDelete(p.SoftDelete),
 		// The default behavior for Postgres transactions is consistent reads, not consistent writes.
 		// For each transaction opened, ensure it has the correct isolation level.
 		//
@@ -71,6 +76,10 @@ func (p *Postgres) driver() (*entSQL.Driver, error) {
 		drv.DB().SetConnMaxLifetime(time.Duration(p.ConnMaxLifetime) * time.Second)
 	}
 
+	if p.ConnMaxIdleTime != 0 {
+		drv.DB().SetConnMaxIdleTime(time.Duration(p.ConnMaxIdleTime) * time.Second)
+	}
+
 	if p.MaxIdleConns == 0 {
 		drv.DB().SetMaxIdleConns(5)
 	} else {
@@ -104,6 +113,11 @@ func (p *Postgres) dsn() string {
 
 	addParam("connect_timeout", strconv.Itoa(p.ConnectionTimeout))
 
+	if p.StatementTimeout > 0 {
+		// Unknown parameters are set as run-time parameters of the session.
+		addParam("statement_timeout", strconv.Itoa(p.StatementTimeout*1000))
+	}
+
 	if host != "" {
 		addParam("host", dataSourceStr(host))
 	}
@@ -66,6 +66,7 @@ func TestPostgres(t *testing.T) {
 	}
 	conformance.RunTests(t, newStorage)
 	conformance.RunTransactionTests(t, newStorage)
+	conformance.RunConcurrencyTests(t, newStorage)
 }
 
 func TestPostgresDSN(t *testing.T) {
@@ -114,6 +115,16 @@ func TestPostgresDSN(t *testing.T) {
 			},
 			desiredDSN: "connect_timeout=5 user='test' password='test' dbname='test' sslmode='verify-full'",
 		},
+		{
+			name: "Statement timeout",
+			cfg: &Postgres{
+				NetworkDB: NetworkDB{
+					Host:             "localhost",
+					StatementTimeout: 30,
+				},
+			},
+			desiredDSN: "connect_timeout=0 statement_timeout=30000 host='localhost' sslmode='verify-full'",
+		},
 		{
 			name: "SSL",
 			cfg: &Postgres{
@@ -3,6 +3,8 @@ package schema
 import (
 	"entgo.io/ent"
 	"entgo.io/ent/schema/field"
+
+	"github.com/dexidp/dex/storage"
 )
 
 /* Original SQL table:
@@ -81,6 +83,16 @@ func (AuthCode) Fields() []ent.Field {
 		field.Text("code_challenge_method").
 			SchemaType(textSchema).
 			Default(""),
+		field.JSON("claims_custom", map[string]interface{}{}).
+			Optional(),
+		field.Bytes("claims_request").
+			Nillable().
+			Optional(),
+		field.JSON("claims_sources", map[string]storage.ClaimSource{}).
+			Optional(),
+		field.Text("issuer").
+			SchemaType(textSchema).
+			Default(""),
 	}
 }
 
@@ -3,6 +3,8 @@ package schema
 import (
 	"entgo.io/ent"
 	"entgo.io/ent/schema/field"
+
+	"github.com/dexidp/dex/storage"
 )
 
 /* Original SQL table:
@@ -88,6 +90,13 @@ func (AuthRequest) Fields() []ent.Field {
 			SchemaType(textSchema).
 			Default(""),
 		field.Bytes("hmac_key"),
+		field.JSON("claims_custom", map[string]interface{}{}).
+			Optional(),
+		field.Bytes("claims_request").
+			Nillable().
+			Optional(),
+		field.JSON("claims_sources", map[string]storage.ClaimSource{}).
+			Optional(),
 	}
 }
 
@@ -3,6 +3,8 @@ package schema
 import (
 	"entgo.io/ent"
 	"entgo.io/ent/schema/field"
+
+	"github.com/dexidp/dex/storage"
 )
 
 /* Original SQL table:
@@ -45,6 +47,44 @@ func (OAuth2Client) Fields() []ent.Field {
 		field.Text("logo_url").
 			SchemaType(textSchema).
 			NotEmpty(),
+		field.JSON("refresh_token_policy", &storage.RefreshTokenPolicy{}).
+			Optional(),
+		field.JSON("token_expiry", &storage.TokenExpiry{}).
+			Optional(),
+		field.JSON("groups_filter", &storage.GroupsFilter{}).
+			Optional(),
+		field.JSON("claims_transforms", []storage.ClaimsTransform{}).
+			Optional(),
+		field.JSON("redirect_uri_patterns", []string{}).
+			Optional(),
+		field.JSON("previous_secret", &storage.PreviousSecret{}).
+			Optional(),
+		field.Time("created_at").
+			SchemaType(timeSchema).
+			Optional(),
+		field.Time("last_used").
+			SchemaType(timeSchema).
+			Optional(),
+		// Audit columns, deleted_at is set on soft deleted rows.
+		field.Text("created_by").
+			SchemaType(textSchema).
+			Optional(),
+		field.Time("updated_at").
+			SchemaType(timeSchema).
+			Optional(),
+		field.Time("deleted_at").
+			SchemaType(timeSchema).
+			Optional(),
+		field.Text("policy_url").
+			SchemaType(textSchema).
+			Optional(),
+		field.Text("terms_of_service_url").
+			SchemaType(textSchema).
+			Optional(),
+		field.JSON("allowed_origins", []string{}).
+			Optional(),
+		field.Bool("forward_claim_source_tokens").
+			Default(false),
 	}
 }
 
@@ -3,6 +3,8 @@ package schema
 import (
 	"entgo.io/ent"
 	"entgo.io/ent/schema/field"
+
+	"github.com/dexidp/dex/storage"
 )
 
 /* Original SQL table:
@@ -38,6 +40,18 @@ func (Connector) Fields() []ent.Field {
 		field.Text("resource_version").
 			SchemaType(textSchema),
 		field.Bytes("config"),
+		// Audit columns, deleted_at is set on soft deleted rows.
+		field.Text("created_by").
+			SchemaType(textSchema).
+			Optional(),
+		field.Time("updated_at").
+			SchemaType(timeSchema).
+			Optional(),
+		field.Time("deleted_at").
+			SchemaType(timeSchema).
+			Optional(),
+		field.JSON("display", &storage.ConnectorDisplay{}).
+			Optional(),
 	}
 }
 
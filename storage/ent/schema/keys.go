@@ -36,6 +36,8 @@ func (Keys) Fields() []ent.Field {
 		field.JSON("signing_key_pub", jose.JSONWebKey{}),
 		field.Time("next_rotation").
 			SchemaType(timeSchema),
+		field.JSON("issuer_keys", map[string]storage.Keys{}).
+			Optional(),
 	}
 }
 
@@ -35,6 +35,13 @@ func (Password) Fields() []ent.Field {
 		field.Text("user_id").
 			SchemaType(textSchema).
 			NotEmpty(),
+		field.Bool("email_verified").
+			Default(true),
+		field.Int("failed_attempts").
+			Default(0),
+		field.Time("locked_until").
+			SchemaType(timeSchema).
+			Optional(),
 	}
 }
 
@@ -5,6 +5,8 @@ import (
 
 	"entgo.io/ent"
 	"entgo.io/ent/schema/field"
+
+	"github.com/dexidp/dex/storage"
 )
 
 /* Original SQL table:
@@ -86,6 +88,16 @@ func (RefreshToken) Fields() []ent.Field {
 		field.Time("last_used").
 			SchemaType(timeSchema).
 			Default(time.Now),
+		field.JSON("claims_custom", map[string]interface{}{}).
+			Optional(),
+		field.Bytes("claims_request").
+			Nillable().
+			Optional(),
+		field.JSON("claims_sources", map[string]storage.ClaimSource{}).
+			Optional(),
+		field.Text("issuer").
+			SchemaType(textSchema).
+			Default(""),
 	}
 }
 
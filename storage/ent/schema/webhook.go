@@ -0,0 +1,54 @@
+package schema
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+
+	"github.com/dexidp/dex/storage"
+)
+
+/* Original SQL table:
+create table webhook
+(
+    id           text        not null  primary key,
+    url          text        not null,
+    secret       text        not null,
+    events       bytea       not null,
+    retry_policy bytea,
+    created_at   timestamptz not null
+);
+*/
+
+// Webhook holds the schema definition for the Webhook entity.
+type Webhook struct {
+	ent.Schema
+}
+
+// Fields of the Webhook.
+func (Webhook) Fields() []ent.Field {
+	return []ent.Field{
+		field.Text("id").
+			SchemaType(textSchema).
+			MaxLen(100).
+			NotEmpty().
+			Unique(),
+		field.Text("secret").
+			SchemaType(textSchema).
+			NotEmpty(),
+		field.JSON("events", []string{}).
+			Optional(),
+		field.Text("url").
+			SchemaType(textSchema).
+			NotEmpty(),
+		field.JSON("retry_policy", &storage.WebhookRetryPolicy{}).
+			Optional(),
+		field.Time("created_at").
+			SchemaType(timeSchema).
+			Optional(),
+	}
+}
+
+// Edges of the Webhook.
+func (Webhook) Edges() []ent.Edge {
+	return []ent.Edge{}
+}
@@ -17,6 +17,10 @@ import (
 // SQLite3 options for creating an SQL db.
 type SQLite3 struct {
 	File string `json:"file"`
+
+	// SoftDelete marks deleted clients and connectors as deleted instead of
+	// removing them.
+	SoftDelete bool `json:"softDelete"`
 }
 
 // Open always returns a new in sqlite3 storage.
@@ -39,6 +43,7 @@ func (s *SQLite3) Open(logger *slog.Logger) (storage.Storage, error) {
 	databaseClient := client.NewDatabase(
 		client.WithClient(db.NewClient(db.Driver(drv))),
 		client.WithHasher(sha256.New),
+		client.WithSoftDelete(s.SoftDelete),
 	)
 
 	if err := databaseClient.Schema().Create(context.TODO()); err != nil {
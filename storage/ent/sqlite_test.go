@@ -1,18 +1,24 @@
 package ent
 
 import (
+	"context"
 	"io"
 	"log/slog"
 	"testing"
 
+	"github.com/stretchr/testify/require"
+
 	"github.com/dexidp/dex/storage"
 	"github.com/dexidp/dex/storage/conformance"
 )
 
 func newSQLiteStorage() storage.Storage {
+	return openSQLiteStorage(SQLite3{File: ":memory:"})
+}
+
+func openSQLiteStorage(cfg SQLite3) storage.Storage {
 	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
 
-	cfg := SQLite3{File: ":memory:"}
 	s, err := cfg.Open(logger)
 	if err != nil {
 		panic(err)
@@ -23,3 +29,41 @@ func newSQLiteStorage() storage.Storage {
 func TestSQLite3(t *testing.T) {
 	conformance.RunTests(t, newSQLiteStorage)
 }
+
+func TestSQLite3SoftDelete(t *testing.T) {
+	newStorage := func() storage.Storage {
+		return openSQLiteStorage(SQLite3{File: ":memory:", SoftDelete: true})
+	}
+	conformance.RunTests(t, newStorage)
+
+	s := newStorage()
+	defer s.Close()
+	ctx := storage.WithActor(context.Background(), "token:0")
+
+	client := storage.Client{ID: "app", Secret: "secret", Name: "App", LogoURL: "https://example.com/logo.png"}
+	require.NoError(t, s.CreateClient(ctx, client))
+	require.NoError(t, s.DeleteClient(client.ID))
+	_, err := s.GetClient(client.ID)
+	require.ErrorIs(t, err, storage.ErrNotFound)
+	require.ErrorIs(t, s.DeleteClient(client.ID), storage.ErrNotFound)
+	clients, err := s.ListClients()
+	require.NoError(t, err)
+	require.Empty(t, clients)
+
+	// Soft deleted objects are replaced when created again.
+	client.Secret = "new_secret"
+	require.NoError(t, s.CreateClient(ctx, client))
+	got, err := s.GetClient(client.ID)
+	require.NoError(t, err)
+	require.Equal(t, "new_secret", got.Secret)
+
+	conn := storage.Connector{ID: "ldap", Type: "ldap", Name: "LDAP", Config: []byte(`{}`)}
+	require.NoError(t, s.CreateConnector(ctx, conn))
+	require.NoError(t, s.DeleteConnector(conn.ID))
+	err = s.UpdateConnector(conn.ID, func(c storage.Connector) (storage.Connector, error) { return c, nil })
+	require.ErrorIs(t, err, storage.ErrNotFound)
+	require.NoError(t, s.CreateConnector(ctx, conn))
+	connectors, err := s.ListConnectors()
+	require.NoError(t, err)
+	require.Len(t, connectors, 1)
+}
@@ -10,9 +10,14 @@ type NetworkDB struct {
 
 	ConnectionTimeout int // Seconds
 
-	MaxOpenConns    int // default: 5
-	MaxIdleConns    int // default: 5
+	// StatementTimeout aborts statements running longer than this. For MySQL
+	// it is the timeout of reads and writes on connections instead.
+	StatementTimeout int // Seconds, default: not set
+
+	MaxOpenConns    int // default: 5, unlimited for MySQL
+	MaxIdleConns    int // default: 5, none for MySQL
 	ConnMaxLifetime int // Seconds, default: not set
+	ConnMaxIdleTime int // Seconds, default: not set
 }
 
 // SSL represents SSL options for network databases.
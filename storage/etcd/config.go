@@ -31,6 +31,17 @@ type Etcd struct {
 	Username  string   `json:"username" yaml:"username"`
 	Password  string   `json:"password" yaml:"password"`
 	SSL       SSL      `json:"ssl" yaml:"ssl"`
+
+	// ExpireWithLeases attaches auth requests, auth codes and device requests
+	// to etcd leases, which delete them once they expire. Garbage collection
+	// then only checks them once, for objects stored without a lease.
+	ExpireWithLeases bool `json:"expireWithLeases" yaml:"expireWithLeases"`
+
+	// LeaderElection lets only the dex instance elected with an etcd election
+	// run garbage collection and key rotation.
+	LeaderElection bool `json:"leaderElection" yaml:"leaderElection"`
+	// ElectionName is the name of the election. Defaults to "dex".
+	ElectionName string `json:"electionName" yaml:"electionName"`
 }
 
 // Open creates a new storage implementation backed by Etcd
@@ -82,10 +93,16 @@ func (p *Etcd) open(logger *slog.Logger) (*conn, error) {
 	}
 	if len(p.Namespace) > 0 {
 		db.KV = namespace.NewKV(db.KV, p.Namespace)
+		// Elections watch the keys of other candidates.
+		db.Watcher = namespace.NewWatcher(db.Watcher, p.Namespace)
 	}
 	c := &conn{
 		db:     db,
+		leases: p.ExpireWithLeases,
 		logger: logger,
 	}
+	if p.LeaderElection {
+		c.startLeaderElection(p.ElectionName)
+	}
 	return c, nil
 }
@@ -0,0 +1,114 @@
+package etcd
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"go.etcd.io/etcd/client/v3/concurrency"
+
+	"github.com/dexidp/dex/storage"
+)
+
+const (
+	// defaultElectionName is the name of the election if none is configured.
+	defaultElectionName = "dex"
+	// electionTTL is how long in seconds a leader stays elected after its
+	// session stopped being kept alive, e.g. when the process died.
+	electionTTL = 15
+	// electionRetryPeriod is the time between attempts to campaign after
+	// errors.
+	electionRetryPeriod = 5 * time.Second
+	// electionKeyPrefix is prepended to the name of elections.
+	electionKeyPrefix = "leader_election/"
+)
+
+var _ storage.LeaderElector = (*conn)(nil)
+
+// leaderElector campaigns in an etcd election with a session kept alive by
+// this instance. The leader loses the election when its session expires.
+type leaderElector struct {
+	c        *conn
+	name     string
+	identity string
+	leader   atomic.Bool
+	done     chan struct{}
+}
+
+// IsLeader reports whether this instance won the election, or always true if
+// leader election is disabled.
+func (c *conn) IsLeader() bool {
+	if c.leader == nil {
+		return true
+	}
+	return c.leader.leader.Load()
+}
+
+// startLeaderElection elects a leader among the instances sharing the etcd
+// cluster until the connection is closed.
+func (c *conn) startLeaderElection(name string) {
+	if name == "" {
+		name = defaultElectionName
+	}
+	// A random suffix keeps the identity unique if instances share a hostname.
+	hostname, _ := os.Hostname()
+	l := &leaderElector{
+		c:        c,
+		name:     name,
+		identity: hostname + "_" + storage.NewID(),
+		done:     make(chan struct{}),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.leader = l
+	c.stopLeaderElection = func() {
+		cancel()
+		<-l.done
+	}
+	go l.run(ctx)
+}
+
+// run campaigns until the context is canceled, then resigns so another
+// instance can take over right away.
+func (l *leaderElector) run(ctx context.Context) {
+	defer close(l.done)
+	for {
+		if err := l.campaign(ctx); err != nil && ctx.Err() == nil {
+			l.c.logger.Error("failed to campaign for leadership", "election", l.name, "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(electionRetryPeriod):
+		}
+	}
+}
+
+// campaign waits to be elected and holds the leadership until the session
+// expires or the context is canceled.
+func (l *leaderElector) campaign(ctx context.Context) error {
+	session, err := concurrency.NewSession(l.c.db, concurrency.WithTTL(electionTTL), concurrency.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	election := concurrency.NewElection(session, electionKeyPrefix+l.name)
+	if err := election.Campaign(ctx, l.identity); err != nil {
+		return err
+	}
+	l.leader.Store(true)
+	l.c.logger.Info("became leader", "election", l.name, "identity", l.identity)
+
+	select {
+	case <-ctx.Done():
+		l.leader.Store(false)
+		resignCtx, cancel := context.WithTimeout(context.Background(), defaultStorageTimeout)
+		defer cancel()
+		return election.Resign(resignCtx)
+	case <-session.Done():
+		l.leader.Store(false)
+		l.c.logger.Info("lost leadership", "election", l.name, "identity", l.identity)
+		return nil
+	}
+}
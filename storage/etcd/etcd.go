@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"math"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	clientv3 "go.etcd.io/etcd/client/v3"
@@ -24,6 +26,7 @@ const (
 	keysName             = "openid-connect-keys"
 	deviceRequestPrefix  = "device_req/"
 	deviceTokenPrefix    = "device_token/"
+	webhookPrefix        = "webhook/"
 
 	// defaultStorageTimeout will be applied to all storage's operations.
 	defaultStorageTimeout = 5 * time.Second
@@ -32,71 +35,140 @@ const (
 var _ storage.Storage = (*conn)(nil)
 
 type conn struct {
-	db     *clientv3.Client
+	db *clientv3.Client
+
+	// leases enables the expiry of objects with leases.
+	leases bool
+	// collected is set once garbage collection checked all objects expired by
+	// leases, after which only objects created without a lease remain.
+	collected atomic.Bool
+
 	logger *slog.Logger
+
+	// leader is set if leader election is enabled, see startLeaderElection.
+	leader             *leaderElector
+	stopLeaderElection func()
 }
 
 func (c *conn) Close() error {
+	if c.stopLeaderElection != nil {
+		// Resign while the client is still open.
+		c.stopLeaderElection()
+	}
 	return c.db.Close()
 }
 
-func (c *conn) GarbageCollect(now time.Time) (result storage.GCResult, err error) {
+func (c *conn) GarbageCollect(now time.Time, opts storage.GCOptions) (result storage.GCResult, err error) {
 	ctx, cancel := context.WithTimeout(context.Background(), defaultStorageTimeout)
 	defer cancel()
-	authRequests, err := c.listAuthRequests(ctx)
-	if err != nil {
-		return result, err
-	}
 
-	var delErr error
-	for _, authRequest := range authRequests {
-		if now.After(authRequest.Expiry) {
-			if err := c.deleteKey(ctx, keyID(authRequestPrefix, authRequest.ID)); err != nil {
-				c.logger.Error("failed to delete auth request", "err", err)
-				delErr = fmt.Errorf("failed to delete auth request: %v", err)
-			}
-			result.AuthRequests++
+	leased := opts.Includes(storage.GCAuthRequests) || opts.Includes(storage.GCAuthCodes) || opts.Includes(storage.GCDeviceRequests)
+	if leased && !(c.leases && c.collected.Load()) {
+		result, err = c.garbageCollectLeased(ctx, now, opts)
+	}
+	if opts.Includes(storage.GCDeviceTokens) {
+		deviceTokens, tokensErr := c.garbageCollectDeviceTokens(ctx, now)
+		result.DeviceTokens = deviceTokens.DeviceTokens
+		if err == nil {
+			err = tokensErr
 		}
 	}
-	if delErr != nil {
-		return result, delErr
+	if err == nil && leased && c.leases && !c.collected.Load() {
+		// Objects stored before leases were enabled have to be collected
+		// until none of them are left.
+		unleased, err := c.hasUnleased(ctx, authRequestPrefix, authCodePrefix, deviceRequestPrefix)
+		if err != nil {
+			return result, err
+		}
+		c.collected.Store(!unleased)
 	}
+	return result, err
+}
 
-	authCodes, err := c.listAuthCodes(ctx)
-	if err != nil {
-		return result, err
+// hasUnleased reports if any keys with the given prefixes aren't attached to
+// a lease.
+func (c *conn) hasUnleased(ctx context.Context, prefixes ...string) (bool, error) {
+	for _, prefix := range prefixes {
+		res, err := c.db.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithKeysOnly())
+		if err != nil {
+			return false, err
+		}
+		for _, kv := range res.Kvs {
+			if kv.Lease == 0 {
+				return true, nil
+			}
+		}
 	}
+	return false, nil
+}
 
-	for _, authCode := range authCodes {
-		if now.After(authCode.Expiry) {
-			if err := c.deleteKey(ctx, keyID(authCodePrefix, authCode.ID)); err != nil {
-				c.logger.Error("failed to delete auth code", "err", err)
-				delErr = fmt.Errorf("failed to delete auth code: %v", err)
+// garbageCollectLeased deletes the expired objects of the types which are
+// attached to leases if leases are enabled.
+func (c *conn) garbageCollectLeased(ctx context.Context, now time.Time, opts storage.GCOptions) (result storage.GCResult, err error) {
+	var delErr error
+	if opts.Includes(storage.GCAuthRequests) {
+		authRequests, err := c.listAuthRequests(ctx)
+		if err != nil {
+			return result, err
+		}
+
+		for _, authRequest := range authRequests {
+			if now.After(authRequest.Expiry) {
+				if err := c.deleteKey(ctx, keyID(authRequestPrefix, authRequest.ID)); err != nil {
+					c.logger.Error("failed to delete auth request", "err", err)
+					delErr = fmt.Errorf("failed to delete auth request: %v", err)
+				}
+				result.AuthRequests++
 			}
-			result.AuthCodes++
+		}
+		if delErr != nil {
+			return result, delErr
 		}
 	}
 
-	deviceRequests, err := c.listDeviceRequests(ctx)
-	if err != nil {
-		return result, err
+	if opts.Includes(storage.GCAuthCodes) {
+		authCodes, err := c.listAuthCodes(ctx)
+		if err != nil {
+			return result, err
+		}
+
+		for _, authCode := range authCodes {
+			if now.After(authCode.Expiry) {
+				if err := c.deleteKey(ctx, keyID(authCodePrefix, authCode.ID)); err != nil {
+					c.logger.Error("failed to delete auth code", "err", err)
+					delErr = fmt.Errorf("failed to delete auth code: %v", err)
+				}
+				result.AuthCodes++
+			}
+		}
 	}
 
-	for _, deviceRequest := range deviceRequests {
-		if now.After(deviceRequest.Expiry) {
-			if err := c.deleteKey(ctx, keyID(deviceRequestPrefix, deviceRequest.UserCode)); err != nil {
-				c.logger.Error("failed to delete device request", "err", err)
-				delErr = fmt.Errorf("failed to delete device request: %v", err)
+	if opts.Includes(storage.GCDeviceRequests) {
+		deviceRequests, err := c.listDeviceRequests(ctx)
+		if err != nil {
+			return result, err
+		}
+
+		for _, deviceRequest := range deviceRequests {
+			if now.After(deviceRequest.Expiry) {
+				if err := c.deleteKey(ctx, keyID(deviceRequestPrefix, deviceRequest.UserCode)); err != nil {
+					c.logger.Error("failed to delete device request", "err", err)
+					delErr = fmt.Errorf("failed to delete device request: %v", err)
+				}
+				result.DeviceRequests++
 			}
-			result.DeviceRequests++
 		}
 	}
+	return result, delErr
+}
 
+func (c *conn) garbageCollectDeviceTokens(ctx context.Context, now time.Time) (result storage.GCResult, err error) {
 	deviceTokens, err := c.listDeviceTokens(ctx)
 	if err != nil {
 		return result, err
 	}
 
+	var delErr error
 	for _, deviceToken := range deviceTokens {
 		if now.After(deviceToken.Expiry) {
 			if err := c.deleteKey(ctx, keyID(deviceTokenPrefix, deviceToken.DeviceCode)); err != nil {
@@ -110,7 +182,7 @@ func (c *conn) GarbageCollect(now time.Time) (result storage.GCResult, err error
 }
 
 func (c *conn) CreateAuthRequest(ctx context.Context, a storage.AuthRequest) error {
-	return c.txnCreate(ctx, keyID(authRequestPrefix, a.ID), fromStorageAuthRequest(a))
+	return c.txnCreateExpiring(ctx, keyID(authRequestPrefix, a.ID), fromStorageAuthRequest(a), a.Expiry)
 }
 
 func (c *conn) GetAuthRequest(id string) (a storage.AuthRequest, err error) {
@@ -148,7 +220,7 @@ func (c *conn) DeleteAuthRequest(id string) error {
 }
 
 func (c *conn) CreateAuthCode(ctx context.Context, a storage.AuthCode) error {
-	return c.txnCreate(ctx, keyID(authCodePrefix, a.ID), fromStorageAuthCode(a))
+	return c.txnCreateExpiring(ctx, keyID(authCodePrefix, a.ID), fromStorageAuthCode(a), a.Expiry)
 }
 
 func (c *conn) GetAuthCode(id string) (a storage.AuthCode, err error) {
@@ -519,6 +591,45 @@ func (c *conn) txnCreate(ctx context.Context, key string, value interface{}) err
 	return nil
 }
 
+// txnCreateExpiring is like txnCreate, but attaches the key to a lease which
+// deletes it at expiry if leases are enabled.
+func (c *conn) txnCreateExpiring(ctx context.Context, key string, value interface{}, expiry time.Time) error {
+	if !c.leases {
+		return c.txnCreate(ctx, key, value)
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	// Leases have a granularity of seconds, round up so keys don't vanish
+	// before they expire.
+	ttl := int64(math.Ceil(time.Until(expiry).Seconds()))
+	if ttl < 1 {
+		ttl = 1
+	}
+	lease, err := c.db.Grant(ctx, ttl)
+	if err != nil {
+		return fmt.Errorf("grant lease: %v", err)
+	}
+
+	txn := c.db.Txn(ctx)
+	res, err := txn.
+		If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+		Then(clientv3.OpPut(key, string(b), clientv3.WithLease(lease.ID))).
+		Commit()
+	if err == nil && !res.Succeeded {
+		err = storage.ErrAlreadyExists
+	}
+	if err != nil {
+		if _, rerr := c.db.Revoke(ctx, lease.ID); rerr != nil {
+			c.logger.Error("failed to revoke unused lease", "err", rerr)
+		}
+		return err
+	}
+	return nil
+}
+
 func (c *conn) txnUpdate(ctx context.Context, key string, update func(current []byte) ([]byte, error)) error {
 	getResp, err := c.db.Get(ctx, key)
 	if err != nil {
@@ -526,9 +637,14 @@ func (c *conn) txnUpdate(ctx context.Context, key string, update func(current []
 	}
 	var currentValue []byte
 	var modRev int64
+	var opts []clientv3.OpOption
 	if len(getResp.Kvs) > 0 {
 		currentValue = getResp.Kvs[0].Value
 		modRev = getResp.Kvs[0].ModRevision
+		// Keep the key attached to its lease, a put otherwise detaches it.
+		if getResp.Kvs[0].Lease != 0 {
+			opts = append(opts, clientv3.WithIgnoreLease())
+		}
 	}
 
 	updatedValue, err := update(currentValue)
@@ -539,13 +655,13 @@ func (c *conn) txnUpdate(ctx context.Context, key string, update func(current []
 	txn := c.db.Txn(ctx)
 	updateResp, err := txn.
 		If(clientv3.Compare(clientv3.ModRevision(key), "=", modRev)).
-		Then(clientv3.OpPut(key, string(updatedValue))).
+		Then(clientv3.OpPut(key, string(updatedValue), opts...)).
 		Commit()
 	if err != nil {
 		return err
 	}
 	if !updateResp.Succeeded {
-		return fmt.Errorf("failed to update key=%q: concurrent conflicting update happened", key)
+		return fmt.Errorf("failed to update key=%q: %w", key, storage.ErrConflict)
 	}
 	return nil
 }
@@ -557,7 +673,7 @@ func keySession(userID, connID string) string {
 }
 
 func (c *conn) CreateDeviceRequest(ctx context.Context, d storage.DeviceRequest) error {
-	return c.txnCreate(ctx, keyID(deviceRequestPrefix, d.UserCode), fromStorageDeviceRequest(d))
+	return c.txnCreateExpiring(ctx, keyID(deviceRequestPrefix, d.UserCode), fromStorageDeviceRequest(d), d.Expiry)
 }
 
 func (c *conn) GetDeviceRequest(userCode string) (r storage.DeviceRequest, err error) {
@@ -570,6 +686,19 @@ func (c *conn) GetDeviceRequest(userCode string) (r storage.DeviceRequest, err e
 	return
 }
 
+func (c *conn) ListDeviceRequests() (requests []storage.DeviceRequest, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultStorageTimeout)
+	defer cancel()
+	drs, err := c.listDeviceRequests(ctx)
+	if err != nil {
+		return requests, err
+	}
+	for _, dr := range drs {
+		requests = append(requests, toStorageDeviceRequest(dr))
+	}
+	return requests, nil
+}
+
 func (c *conn) listDeviceRequests(ctx context.Context) (requests []DeviceRequest, err error) {
 	res, err := c.db.Get(ctx, deviceRequestPrefix, clientv3.WithPrefix())
 	if err != nil {
@@ -631,3 +760,55 @@ func (c *conn) UpdateDeviceToken(deviceCode string, updater func(old storage.Dev
 		return json.Marshal(fromStorageDeviceToken(updated))
 	})
 }
+
+func (c *conn) CreateWebhook(ctx context.Context, w storage.Webhook) error {
+	return c.txnCreate(ctx, keyID(webhookPrefix, w.ID), w)
+}
+
+func (c *conn) GetWebhook(id string) (w storage.Webhook, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultStorageTimeout)
+	defer cancel()
+	err = c.getKey(ctx, keyID(webhookPrefix, id), &w)
+	return w, err
+}
+
+func (c *conn) UpdateWebhook(id string, updater func(w storage.Webhook) (storage.Webhook, error)) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultStorageTimeout)
+	defer cancel()
+	return c.txnUpdate(ctx, keyID(webhookPrefix, id), func(currentValue []byte) ([]byte, error) {
+		var current storage.Webhook
+		if len(currentValue) > 0 {
+			if err := json.Unmarshal(currentValue, &current); err != nil {
+				return nil, err
+			}
+		}
+		updated, err := updater(current)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(updated)
+	})
+}
+
+func (c *conn) DeleteWebhook(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultStorageTimeout)
+	defer cancel()
+	return c.deleteKey(ctx, keyID(webhookPrefix, id))
+}
+
+func (c *conn) ListWebhooks() (webhooks []storage.Webhook, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultStorageTimeout)
+	defer cancel()
+	res, err := c.db.Get(ctx, webhookPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range res.Kvs {
+		var w storage.Webhook
+		if err = json.Unmarshal(v.Value, &w); err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, w)
+	}
+	return webhooks, nil
+}
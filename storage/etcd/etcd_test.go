@@ -91,4 +91,131 @@ func TestEtcd(t *testing.T) {
 	withTimeout(time.Minute*1, func() {
 		conformance.RunTransactionTests(t, newStorage)
 	})
+
+	withTimeout(time.Minute*1, func() {
+		conformance.RunConcurrencyTests(t, newStorage)
+	})
+}
+
+func TestEtcdLeases(t *testing.T) {
+	testEtcdEnv := "DEX_ETCD_ENDPOINTS"
+	endpointsStr := os.Getenv(testEtcdEnv)
+	if endpointsStr == "" {
+		t.Skipf("test environment variable %q not set, skipping", testEtcdEnv)
+		return
+	}
+
+	s := &Etcd{
+		Endpoints:        strings.Split(endpointsStr, ","),
+		ExpireWithLeases: true,
+	}
+	conn, err := s.open(logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := cleanDB(conn); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.TODO()
+	a := storage.AuthRequest{
+		ID:       storage.NewID(),
+		ClientID: "client",
+		Expiry:   time.Now().Add(2 * time.Second),
+	}
+	if err := conn.CreateAuthRequest(ctx, a); err != nil {
+		t.Fatal(err)
+	}
+
+	leaseOf := func() clientv3.LeaseID {
+		res, err := conn.db.Get(ctx, keyID(authRequestPrefix, a.ID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Kvs) == 0 {
+			return 0
+		}
+		return clientv3.LeaseID(res.Kvs[0].Lease)
+	}
+	lease := leaseOf()
+	if lease == 0 {
+		t.Fatal("auth request isn't attached to a lease")
+	}
+
+	err = conn.UpdateAuthRequest(a.ID, func(old storage.AuthRequest) (storage.AuthRequest, error) {
+		old.LoggedIn = true
+		return old, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := leaseOf(); got != lease {
+		t.Fatalf("update detached auth request from its lease, got lease %d want %d", got, lease)
+	}
+
+	if _, err := conn.GarbageCollect(time.Now(), storage.GCOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !conn.collected.Load() {
+		t.Error("garbage collection still checks objects expired by leases")
+	}
+
+	// Leases expire within a second or two of their TTL.
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		_, err := conn.GetAuthRequest(a.ID)
+		if err == storage.ErrNotFound {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("auth request wasn't deleted by its lease")
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+func TestEtcdLeaderElection(t *testing.T) {
+	testEtcdEnv := "DEX_ETCD_ENDPOINTS"
+	endpointsStr := os.Getenv(testEtcdEnv)
+	if endpointsStr == "" {
+		t.Skipf("test environment variable %q not set, skipping", testEtcdEnv)
+		return
+	}
+
+	open := func() *conn {
+		s := &Etcd{
+			Endpoints:      strings.Split(endpointsStr, ","),
+			Namespace:      "leader-election-test/",
+			LeaderElection: true,
+		}
+		conn, err := s.open(logger)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return conn
+	}
+	a, b := open(), open()
+	defer b.Close()
+
+	waitFor := func(cond func() bool) {
+		deadline := time.Now().Add(10 * time.Second)
+		for !cond() {
+			if time.Now().After(deadline) {
+				t.Fatalf("leadership: a=%v b=%v", a.IsLeader(), b.IsLeader())
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+	waitFor(func() bool { return a.IsLeader() != b.IsLeader() })
+	if b.IsLeader() {
+		a, b = b, a
+	}
+
+	// Closing the leader resigns, and the other instance takes over.
+	a.Close()
+	waitFor(b.IsLeader)
 }
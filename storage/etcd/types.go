@@ -16,6 +16,8 @@ type AuthCode struct {
 	Nonce       string   `json:"nonce,omitempty"`
 	Scopes      []string `json:"scopes,omitempty"`
 
+	ClaimsRequest []byte `json:"claimsRequest,omitempty"`
+
 	ConnectorID   string `json:"connectorID,omitempty"`
 	ConnectorData []byte `json:"connectorData,omitempty"`
 	Claims        Claims `json:"claims,omitempty"`
@@ -24,6 +26,8 @@ type AuthCode struct {
 
 	CodeChallenge       string `json:"code_challenge,omitempty"`
 	CodeChallengeMethod string `json:"code_challenge_method,omitempty"`
+
+	Issuer string `json:"issuer,omitempty"`
 }
 
 func toStorageAuthCode(a AuthCode) storage.AuthCode {
@@ -35,12 +39,14 @@ func toStorageAuthCode(a AuthCode) storage.AuthCode {
 		ConnectorData: a.ConnectorData,
 		Nonce:         a.Nonce,
 		Scopes:        a.Scopes,
+		ClaimsRequest: a.ClaimsRequest,
 		Claims:        toStorageClaims(a.Claims),
 		Expiry:        a.Expiry,
 		PKCE: storage.PKCE{
 			CodeChallenge:       a.CodeChallenge,
 			CodeChallengeMethod: a.CodeChallengeMethod,
 		},
+		Issuer: a.Issuer,
 	}
 }
 
@@ -53,10 +59,12 @@ func fromStorageAuthCode(a storage.AuthCode) AuthCode {
 		ConnectorData:       a.ConnectorData,
 		Nonce:               a.Nonce,
 		Scopes:              a.Scopes,
+		ClaimsRequest:       a.ClaimsRequest,
 		Claims:              fromStorageClaims(a.Claims),
 		Expiry:              a.Expiry,
 		CodeChallenge:       a.PKCE.CodeChallenge,
 		CodeChallengeMethod: a.PKCE.CodeChallengeMethod,
+		Issuer:              a.Issuer,
 	}
 }
 
@@ -71,6 +79,8 @@ type AuthRequest struct {
 	Nonce         string   `json:"nonce"`
 	State         string   `json:"state"`
 
+	ClaimsRequest []byte `json:"claims_request,omitempty"`
+
 	ForceApprovalPrompt bool `json:"force_approval_prompt"`
 
 	Expiry time.Time `json:"expiry"`
@@ -94,6 +104,7 @@ func fromStorageAuthRequest(a storage.AuthRequest) AuthRequest {
 		ClientID:            a.ClientID,
 		ResponseTypes:       a.ResponseTypes,
 		Scopes:              a.Scopes,
+		ClaimsRequest:       a.ClaimsRequest,
 		RedirectURI:         a.RedirectURI,
 		Nonce:               a.Nonce,
 		State:               a.State,
@@ -115,6 +126,7 @@ func toStorageAuthRequest(a AuthRequest) storage.AuthRequest {
 		ClientID:            a.ClientID,
 		ResponseTypes:       a.ResponseTypes,
 		Scopes:              a.Scopes,
+		ClaimsRequest:       a.ClaimsRequest,
 		RedirectURI:         a.RedirectURI,
 		Nonce:               a.Nonce,
 		State:               a.State,
@@ -150,7 +162,11 @@ type RefreshToken struct {
 
 	Scopes []string `json:"scopes"`
 
+	ClaimsRequest []byte `json:"claims_request,omitempty"`
+
 	Nonce string `json:"nonce"`
+
+	Issuer string `json:"issuer,omitempty"`
 }
 
 func toStorageRefreshToken(r RefreshToken) storage.RefreshToken {
@@ -164,8 +180,10 @@ func toStorageRefreshToken(r RefreshToken) storage.RefreshToken {
 		ConnectorID:   r.ConnectorID,
 		ConnectorData: r.ConnectorData,
 		Scopes:        r.Scopes,
+		ClaimsRequest: r.ClaimsRequest,
 		Nonce:         r.Nonce,
 		Claims:        toStorageClaims(r.Claims),
+		Issuer:        r.Issuer,
 	}
 }
 
@@ -180,19 +198,23 @@ func fromStorageRefreshToken(r storage.RefreshToken) RefreshToken {
 		ConnectorID:   r.ConnectorID,
 		ConnectorData: r.ConnectorData,
 		Scopes:        r.Scopes,
+		ClaimsRequest: r.ClaimsRequest,
 		Nonce:         r.Nonce,
 		Claims:        fromStorageClaims(r.Claims),
+		Issuer:        r.Issuer,
 	}
 }
 
 // Claims is a mirrored struct from storage with JSON struct tags.
 type Claims struct {
-	UserID            string   `json:"userID"`
-	Username          string   `json:"username"`
-	PreferredUsername string   `json:"preferredUsername"`
-	Email             string   `json:"email"`
-	EmailVerified     bool     `json:"emailVerified"`
-	Groups            []string `json:"groups,omitempty"`
+	UserID            string                         `json:"userID"`
+	Username          string                         `json:"username"`
+	PreferredUsername string                         `json:"preferredUsername"`
+	Email             string                         `json:"email"`
+	EmailVerified     bool                           `json:"emailVerified"`
+	Groups            []string                       `json:"groups,omitempty"`
+	CustomClaims      map[string]interface{}         `json:"customClaims,omitempty"`
+	ClaimSources      map[string]storage.ClaimSource `json:"claimSources,omitempty"`
 }
 
 func fromStorageClaims(i storage.Claims) Claims {
@@ -203,6 +225,8 @@ func fromStorageClaims(i storage.Claims) Claims {
 		Email:             i.Email,
 		EmailVerified:     i.EmailVerified,
 		Groups:            i.Groups,
+		CustomClaims:      i.CustomClaims,
+		ClaimSources:      i.ClaimSources,
 	}
 }
 
@@ -214,6 +238,8 @@ func toStorageClaims(i Claims) storage.Claims {
 		Email:             i.Email,
 		EmailVerified:     i.EmailVerified,
 		Groups:            i.Groups,
+		CustomClaims:      i.CustomClaims,
+		ClaimSources:      i.ClaimSources,
 	}
 }
 
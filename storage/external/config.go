@@ -0,0 +1,75 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/dexidp/dex/pkg/apiclient"
+	"github.com/dexidp/dex/storage"
+	"github.com/dexidp/dex/storage/external/storagepb"
+)
+
+// protocolVersion is the version of the storage protocol implemented by dex.
+const protocolVersion = 1
+
+// callTimeout bounds every call to the backend.
+const callTimeout = 10 * time.Second
+
+// Config options for a storage backend implementing the gRPC protocol of
+// storagepb, run as a separate process.
+type Config struct {
+	// Addr is the address of the backend, e.g. "127.0.0.1:5600" or
+	// "unix:///run/dex-storage.sock".
+	Addr string `json:"addr" yaml:"addr"`
+
+	// CACert is a file with the CA certificates verifying the backend. TLS is
+	// only used if it is set.
+	CACert string `json:"caCert" yaml:"caCert"`
+
+	// ClientCert and ClientKey are files with a certificate and key dex
+	// authenticates with to the backend over TLS.
+	ClientCert string `json:"clientCert" yaml:"clientCert"`
+	ClientKey  string `json:"clientKey" yaml:"clientKey"`
+
+	// Token is a bearer token sent with each call.
+	Token string `json:"token" yaml:"token"`
+}
+
+// Open connects to the backend.
+func (c *Config) Open(logger *slog.Logger) (storage.Storage, error) {
+	return c.open()
+}
+
+func (c *Config) open() (*conn, error) {
+	if c.Addr == "" {
+		return nil, errors.New("no address specified")
+	}
+	opts := apiclient.Options{
+		Addr:       c.Addr,
+		CACert:     c.CACert,
+		ClientCert: c.ClientCert,
+		ClientKey:  c.ClientKey,
+		Token:      c.Token,
+	}
+	cc, err := apiclient.Dial(opts)
+	if err != nil {
+		return nil, err
+	}
+	s := &conn{cc: cc, client: storagepb.NewStorageClient(cc), opts: opts}
+
+	ctx, cancel := s.context(context.Background())
+	defer cancel()
+	resp, err := s.client.Handshake(ctx, &storagepb.HandshakeReq{Version: protocolVersion})
+	if err != nil {
+		cc.Close()
+		return nil, fmt.Errorf("handshake with %s: %v", c.Addr, err)
+	}
+	if resp.Version != protocolVersion {
+		cc.Close()
+		return nil, fmt.Errorf("%s implements version %d of the storage protocol, dex requires version %d", c.Addr, resp.Version, protocolVersion)
+	}
+	return s, nil
+}
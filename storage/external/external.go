@@ -0,0 +1,458 @@
+// Package external provides a storage implementation backed by a separate
+// process implementing the gRPC storage protocol of storagepb, so that
+// proprietary databases can be used without changing dex.
+package external
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/dexidp/dex/pkg/apiclient"
+	"github.com/dexidp/dex/storage"
+	"github.com/dexidp/dex/storage/external/storagepb"
+)
+
+// Kinds of objects, each kept in its own collection keyed by ID and encoded
+// as JSON.
+const (
+	kindClient         = "client"
+	kindAuthCode       = "auth_code"
+	kindRefreshToken   = "refresh_token"
+	kindAuthRequest    = "auth_req"
+	kindPassword       = "password"
+	kindOfflineSession = "offline_session"
+	kindConnector      = "connector"
+	kindKeys           = "keys"
+	kindDeviceRequest  = "device_req"
+	kindDeviceToken    = "device_token"
+	kindWebhook        = "webhook"
+)
+
+// keysName is the key of the signing keys.
+const keysName = "openid-connect-keys"
+
+var _ storage.Storage = (*conn)(nil)
+
+type conn struct {
+	cc     *grpc.ClientConn
+	client storagepb.StorageClient
+	opts   apiclient.Options
+}
+
+func (c *conn) Close() error {
+	return c.cc.Close()
+}
+
+// context returns a context for a call to the backend.
+func (c *conn) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(ctx, callTimeout)
+	return c.opts.Context(ctx), cancel
+}
+
+// create stores a new object, failing if the key is taken.
+func create(ctx context.Context, c *conn, kind, key string, v interface{}) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.create(ctx, kind, key, value)
+}
+
+func (c *conn) create(ctx context.Context, kind, key string, value []byte) error {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	resp, err := c.client.Create(ctx, &storagepb.CreateReq{Kind: kind, Key: key, Value: value})
+	if err != nil {
+		return fmt.Errorf("create %s: %v", kind, err)
+	}
+	if resp.AlreadyExists {
+		return storage.ErrAlreadyExists
+	}
+	return nil
+}
+
+// get reads an object, returning storage.ErrNotFound if it doesn't exist.
+func get[T any](c *conn, kind, key string) (v T, err error) {
+	entry, err := c.get(kind, key)
+	if err != nil {
+		return v, err
+	}
+	if entry == nil {
+		return v, storage.ErrNotFound
+	}
+	err = json.Unmarshal(entry.Value, &v)
+	return v, err
+}
+
+// get returns the entry of a key, or nil if it doesn't exist.
+func (c *conn) get(kind, key string) (*storagepb.Entry, error) {
+	ctx, cancel := c.context(context.Background())
+	defer cancel()
+	resp, err := c.client.Get(ctx, &storagepb.GetReq{Kind: kind, Key: key})
+	if err != nil {
+		return nil, fmt.Errorf("get %s: %v", kind, err)
+	}
+	if resp.NotFound || resp.Entry == nil {
+		return nil, nil
+	}
+	return resp.Entry, nil
+}
+
+// list reads all objects of a kind.
+func list[T any](c *conn, kind string) ([]T, error) {
+	entries, err := c.list(kind)
+	if err != nil {
+		return nil, err
+	}
+	var objects []T
+	for _, entry := range entries {
+		var v T
+		if err := json.Unmarshal(entry.Value, &v); err != nil {
+			return nil, err
+		}
+		objects = append(objects, v)
+	}
+	return objects, nil
+}
+
+func (c *conn) list(kind string) ([]*storagepb.Entry, error) {
+	ctx, cancel := c.context(context.Background())
+	defer cancel()
+	resp, err := c.client.List(ctx, &storagepb.ListReq{Kind: kind})
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %v", kind, err)
+	}
+	return resp.Entries, nil
+}
+
+// update replaces an object with the result of the updater, returning
+// storage.ErrNotFound if it doesn't exist. The object is only replaced if it
+// didn't change in the meantime.
+func update[T any](c *conn, kind, key string, updater func(old T) (T, error)) error {
+	return c.compareAndSwap(kind, key, func(value []byte) ([]byte, error) {
+		if value == nil {
+			return nil, storage.ErrNotFound
+		}
+		var old T
+		if err := json.Unmarshal(value, &old); err != nil {
+			return nil, err
+		}
+		updated, err := updater(old)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(updated)
+	})
+}
+
+// compareAndSwap replaces the value of a key with the result of fn, or
+// creates it if it didn't exist, failing if the value was changed while fn
+// was running.
+func (c *conn) compareAndSwap(kind, key string, fn func(value []byte) ([]byte, error)) error {
+	entry, err := c.get(kind, key)
+	if err != nil {
+		return err
+	}
+	var current []byte
+	if entry != nil {
+		current = entry.Value
+	}
+
+	updated, err := fn(current)
+	if err != nil {
+		return err
+	}
+
+	if entry == nil {
+		err := c.create(context.Background(), kind, key, updated)
+		if err == storage.ErrAlreadyExists {
+			return fmt.Errorf("failed to update key=%q: %w", key, storage.ErrConflict)
+		}
+		return err
+	}
+
+	ctx, cancel := c.context(context.Background())
+	defer cancel()
+	resp, err := c.client.Update(ctx, &storagepb.UpdateReq{Kind: kind, Key: key, Value: updated, Revision: entry.Revision})
+	if err != nil {
+		return fmt.Errorf("update %s: %v", kind, err)
+	}
+	if resp.NotFound || resp.Conflict {
+		return fmt.Errorf("failed to update key=%q: %w", key, storage.ErrConflict)
+	}
+	return nil
+}
+
+// remove deletes an object, returning storage.ErrNotFound if it doesn't exist.
+func remove(c *conn, kind, key string) error {
+	ctx, cancel := c.context(context.Background())
+	defer cancel()
+	resp, err := c.client.Delete(ctx, &storagepb.DeleteReq{Kind: kind, Key: key})
+	if err != nil {
+		return fmt.Errorf("delete %s: %v", kind, err)
+	}
+	if resp.NotFound {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// collect deletes the expired objects of a kind. Objects changed since they
+// were listed are left alone.
+func collect[T any](c *conn, kind string, now time.Time, expiry func(T) time.Time) (int64, error) {
+	entries, err := c.list(kind)
+	if err != nil {
+		return 0, err
+	}
+	var deleted int64
+	for _, entry := range entries {
+		var v T
+		if err := json.Unmarshal(entry.Value, &v); err != nil {
+			return deleted, err
+		}
+		if !now.After(expiry(v)) {
+			continue
+		}
+		ctx, cancel := c.context(context.Background())
+		resp, err := c.client.Delete(ctx, &storagepb.DeleteReq{Kind: kind, Key: entry.Key, Revision: entry.Revision})
+		cancel()
+		if err != nil {
+			return deleted, fmt.Errorf("delete %s: %v", kind, err)
+		}
+		if !resp.NotFound && !resp.Conflict {
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// offlineSessionKey joins the IDs of the user and the connector. Neither
+// contains a NUL byte.
+func offlineSessionKey(userID, connID string) string {
+	return userID + "\x00" + connID
+}
+
+func (c *conn) GarbageCollect(now time.Time, opts storage.GCOptions) (result storage.GCResult, err error) {
+	if opts.Includes(storage.GCAuthRequests) {
+		result.AuthRequests, err = collect(c, kindAuthRequest, now, func(a storage.AuthRequest) time.Time { return a.Expiry })
+		if err != nil {
+			return result, err
+		}
+	}
+	if opts.Includes(storage.GCAuthCodes) {
+		result.AuthCodes, err = collect(c, kindAuthCode, now, func(a storage.AuthCode) time.Time { return a.Expiry })
+		if err != nil {
+			return result, err
+		}
+	}
+	if opts.Includes(storage.GCDeviceRequests) {
+		result.DeviceRequests, err = collect(c, kindDeviceRequest, now, func(d storage.DeviceRequest) time.Time { return d.Expiry })
+		if err != nil {
+			return result, err
+		}
+	}
+	if opts.Includes(storage.GCDeviceTokens) {
+		result.DeviceTokens, err = collect(c, kindDeviceToken, now, func(d storage.DeviceToken) time.Time { return d.Expiry })
+	}
+	return result, err
+}
+
+func (c *conn) CreateAuthRequest(ctx context.Context, a storage.AuthRequest) error {
+	return create(ctx, c, kindAuthRequest, a.ID, a)
+}
+
+func (c *conn) GetAuthRequest(id string) (storage.AuthRequest, error) {
+	return get[storage.AuthRequest](c, kindAuthRequest, id)
+}
+
+func (c *conn) UpdateAuthRequest(id string, updater func(a storage.AuthRequest) (storage.AuthRequest, error)) error {
+	return update(c, kindAuthRequest, id, updater)
+}
+
+func (c *conn) DeleteAuthRequest(id string) error {
+	return remove(c, kindAuthRequest, id)
+}
+
+func (c *conn) CreateAuthCode(ctx context.Context, a storage.AuthCode) error {
+	return create(ctx, c, kindAuthCode, a.ID, a)
+}
+
+func (c *conn) GetAuthCode(id string) (storage.AuthCode, error) {
+	return get[storage.AuthCode](c, kindAuthCode, id)
+}
+
+func (c *conn) DeleteAuthCode(id string) error {
+	return remove(c, kindAuthCode, id)
+}
+
+func (c *conn) CreateRefresh(ctx context.Context, r storage.RefreshToken) error {
+	return create(ctx, c, kindRefreshToken, r.ID, r)
+}
+
+func (c *conn) GetRefresh(id string) (storage.RefreshToken, error) {
+	return get[storage.RefreshToken](c, kindRefreshToken, id)
+}
+
+func (c *conn) UpdateRefreshToken(id string, updater func(old storage.RefreshToken) (storage.RefreshToken, error)) error {
+	return update(c, kindRefreshToken, id, updater)
+}
+
+func (c *conn) DeleteRefresh(id string) error {
+	return remove(c, kindRefreshToken, id)
+}
+
+func (c *conn) ListRefreshTokens() ([]storage.RefreshToken, error) {
+	return list[storage.RefreshToken](c, kindRefreshToken)
+}
+
+func (c *conn) CreateClient(ctx context.Context, cli storage.Client) error {
+	return create(ctx, c, kindClient, cli.ID, cli)
+}
+
+func (c *conn) GetClient(id string) (storage.Client, error) {
+	return get[storage.Client](c, kindClient, id)
+}
+
+func (c *conn) UpdateClient(id string, updater func(old storage.Client) (storage.Client, error)) error {
+	return update(c, kindClient, id, updater)
+}
+
+func (c *conn) DeleteClient(id string) error {
+	return remove(c, kindClient, id)
+}
+
+func (c *conn) ListClients() ([]storage.Client, error) {
+	return list[storage.Client](c, kindClient)
+}
+
+// Emails are case insensitive, so passwords are keyed by the lower case email.
+
+func (c *conn) CreatePassword(ctx context.Context, p storage.Password) error {
+	return create(ctx, c, kindPassword, strings.ToLower(p.Email), p)
+}
+
+func (c *conn) GetPassword(email string) (storage.Password, error) {
+	return get[storage.Password](c, kindPassword, strings.ToLower(email))
+}
+
+func (c *conn) UpdatePassword(email string, updater func(p storage.Password) (storage.Password, error)) error {
+	return update(c, kindPassword, strings.ToLower(email), updater)
+}
+
+func (c *conn) DeletePassword(email string) error {
+	return remove(c, kindPassword, strings.ToLower(email))
+}
+
+func (c *conn) ListPasswords() ([]storage.Password, error) {
+	return list[storage.Password](c, kindPassword)
+}
+
+func (c *conn) CreateOfflineSessions(ctx context.Context, s storage.OfflineSessions) error {
+	return create(ctx, c, kindOfflineSession, offlineSessionKey(s.UserID, s.ConnID), s)
+}
+
+func (c *conn) GetOfflineSessions(userID string, connID string) (storage.OfflineSessions, error) {
+	return get[storage.OfflineSessions](c, kindOfflineSession, offlineSessionKey(userID, connID))
+}
+
+func (c *conn) UpdateOfflineSessions(userID string, connID string, updater func(s storage.OfflineSessions) (storage.OfflineSessions, error)) error {
+	return update(c, kindOfflineSession, offlineSessionKey(userID, connID), updater)
+}
+
+func (c *conn) DeleteOfflineSessions(userID string, connID string) error {
+	return remove(c, kindOfflineSession, offlineSessionKey(userID, connID))
+}
+
+func (c *conn) CreateConnector(ctx context.Context, connector storage.Connector) error {
+	return create(ctx, c, kindConnector, connector.ID, connector)
+}
+
+func (c *conn) GetConnector(id string) (storage.Connector, error) {
+	return get[storage.Connector](c, kindConnector, id)
+}
+
+func (c *conn) UpdateConnector(id string, updater func(s storage.Connector) (storage.Connector, error)) error {
+	return update(c, kindConnector, id, updater)
+}
+
+func (c *conn) DeleteConnector(id string) error {
+	return remove(c, kindConnector, id)
+}
+
+func (c *conn) ListConnectors() ([]storage.Connector, error) {
+	return list[storage.Connector](c, kindConnector)
+}
+
+func (c *conn) GetKeys() (storage.Keys, error) {
+	keys, err := get[storage.Keys](c, kindKeys, keysName)
+	if err == storage.ErrNotFound {
+		// Keys are created by the first update.
+		return storage.Keys{}, nil
+	}
+	return keys, err
+}
+
+func (c *conn) UpdateKeys(updater func(old storage.Keys) (storage.Keys, error)) error {
+	return c.compareAndSwap(kindKeys, keysName, func(value []byte) ([]byte, error) {
+		var old storage.Keys
+		if value != nil {
+			if err := json.Unmarshal(value, &old); err != nil {
+				return nil, err
+			}
+		}
+		updated, err := updater(old)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(updated)
+	})
+}
+
+func (c *conn) CreateDeviceRequest(ctx context.Context, d storage.DeviceRequest) error {
+	return create(ctx, c, kindDeviceRequest, d.UserCode, d)
+}
+
+func (c *conn) GetDeviceRequest(userCode string) (storage.DeviceRequest, error) {
+	return get[storage.DeviceRequest](c, kindDeviceRequest, userCode)
+}
+
+func (c *conn) ListDeviceRequests() ([]storage.DeviceRequest, error) {
+	return list[storage.DeviceRequest](c, kindDeviceRequest)
+}
+
+func (c *conn) CreateDeviceToken(ctx context.Context, t storage.DeviceToken) error {
+	return create(ctx, c, kindDeviceToken, t.DeviceCode, t)
+}
+
+func (c *conn) GetDeviceToken(deviceCode string) (storage.DeviceToken, error) {
+	return get[storage.DeviceToken](c, kindDeviceToken, deviceCode)
+}
+
+func (c *conn) UpdateDeviceToken(deviceCode string, updater func(old storage.DeviceToken) (storage.DeviceToken, error)) error {
+	return update(c, kindDeviceToken, deviceCode, updater)
+}
+
+func (c *conn) CreateWebhook(ctx context.Context, w storage.Webhook) error {
+	return create(ctx, c, kindWebhook, w.ID, w)
+}
+
+func (c *conn) GetWebhook(id string) (storage.Webhook, error) {
+	return get[storage.Webhook](c, kindWebhook, id)
+}
+
+func (c *conn) ListWebhooks() ([]storage.Webhook, error) {
+	return list[storage.Webhook](c, kindWebhook)
+}
+
+func (c *conn) DeleteWebhook(id string) error {
+	return remove(c, kindWebhook, id)
+}
+
+func (c *conn) UpdateWebhook(id string, updater func(w storage.Webhook) (storage.Webhook, error)) error {
+	return update(c, kindWebhook, id, updater)
+}
@@ -0,0 +1,144 @@
+package external
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/dexidp/dex/storage"
+	"github.com/dexidp/dex/storage/conformance"
+	"github.com/dexidp/dex/storage/external/storagepb"
+)
+
+// memoryBackend is a storage backend keeping values in memory.
+type memoryBackend struct {
+	storagepb.UnimplementedStorageServer
+
+	version uint32
+
+	mu       sync.Mutex
+	entries  map[string]map[string]*storagepb.Entry
+	revision int
+}
+
+func (b *memoryBackend) Handshake(ctx context.Context, req *storagepb.HandshakeReq) (*storagepb.HandshakeResp, error) {
+	return &storagepb.HandshakeResp{Version: b.version}, nil
+}
+
+func (b *memoryBackend) Get(ctx context.Context, req *storagepb.GetReq) (*storagepb.GetResp, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entry, ok := b.entries[req.Kind][req.Key]
+	if !ok {
+		return &storagepb.GetResp{NotFound: true}, nil
+	}
+	return &storagepb.GetResp{Entry: entry}, nil
+}
+
+func (b *memoryBackend) List(ctx context.Context, req *storagepb.ListReq) (*storagepb.ListResp, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	resp := &storagepb.ListResp{}
+	for _, entry := range b.entries[req.Kind] {
+		resp.Entries = append(resp.Entries, entry)
+	}
+	return resp, nil
+}
+
+func (b *memoryBackend) Create(ctx context.Context, req *storagepb.CreateReq) (*storagepb.CreateResp, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.entries[req.Kind][req.Key]; ok {
+		return &storagepb.CreateResp{AlreadyExists: true}, nil
+	}
+	if b.entries[req.Kind] == nil {
+		b.entries[req.Kind] = make(map[string]*storagepb.Entry)
+	}
+	b.put(req.Kind, req.Key, req.Value)
+	return &storagepb.CreateResp{}, nil
+}
+
+func (b *memoryBackend) Update(ctx context.Context, req *storagepb.UpdateReq) (*storagepb.UpdateResp, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entry, ok := b.entries[req.Kind][req.Key]
+	switch {
+	case !ok:
+		return &storagepb.UpdateResp{NotFound: true}, nil
+	case entry.Revision != req.Revision:
+		return &storagepb.UpdateResp{Conflict: true}, nil
+	}
+	b.put(req.Kind, req.Key, req.Value)
+	return &storagepb.UpdateResp{}, nil
+}
+
+func (b *memoryBackend) Delete(ctx context.Context, req *storagepb.DeleteReq) (*storagepb.DeleteResp, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entry, ok := b.entries[req.Kind][req.Key]
+	switch {
+	case !ok:
+		return &storagepb.DeleteResp{NotFound: true}, nil
+	case req.Revision != "" && entry.Revision != req.Revision:
+		return &storagepb.DeleteResp{Conflict: true}, nil
+	}
+	delete(b.entries[req.Kind], req.Key)
+	return &storagepb.DeleteResp{}, nil
+}
+
+func (b *memoryBackend) put(kind, key string, value []byte) {
+	b.revision++
+	b.entries[kind][key] = &storagepb.Entry{Key: key, Value: value, Revision: strconv.Itoa(b.revision)}
+}
+
+// serve starts a backend requiring the token "secret", and returns its address.
+func serve(t *testing.T, version uint32) string {
+	requireToken := func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if auth := md.Get("authorization"); len(auth) != 1 || auth[0] != "Bearer secret" {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+		return handler(ctx, req)
+	}
+	server := grpc.NewServer(grpc.UnaryInterceptor(requireToken))
+	storagepb.RegisterStorageServer(server, &memoryBackend{version: version, entries: make(map[string]map[string]*storagepb.Entry)})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go server.Serve(l)
+	t.Cleanup(server.Stop)
+	return l.Addr().String()
+}
+
+func TestStorage(t *testing.T) {
+	newStorage := func() storage.Storage {
+		c := &Config{Addr: serve(t, protocolVersion), Token: "secret"}
+		s, err := c.open()
+		require.NoError(t, err)
+		return s
+	}
+	conformance.RunTests(t, newStorage)
+	conformance.RunTransactionTests(t, newStorage)
+	conformance.RunConcurrencyTests(t, newStorage)
+}
+
+func TestOpen(t *testing.T) {
+	addr := serve(t, protocolVersion)
+
+	_, err := (&Config{Addr: addr}).open()
+	require.Error(t, err, "the backend requires a token")
+
+	_, err = (&Config{}).open()
+	require.Error(t, err)
+
+	_, err = (&Config{Addr: serve(t, protocolVersion+1), Token: "secret"}).open()
+	require.ErrorContains(t, err, "version")
+}
@@ -0,0 +1,192 @@
+package storage
+
+import (
+	"context"
+	"errors"
+)
+
+// Tests for this code are in the "memory" package, since this package doesn't
+// define a concrete storage implementation.
+
+// issuerStorage is the storage of an issuer sharing a backend with other
+// issuers. Signing keys, auth codes and refresh tokens are kept apart from
+// those of other issuers, and only the clients and connectors of the issuer
+// are visible.
+type issuerStorage struct {
+	Storage
+
+	// Issuer URL of an additional issuer, empty for the default issuer.
+	issuer string
+
+	// Allowed client and connector IDs. A nil set allows all of them.
+	clients    map[string]bool
+	connectors map[string]bool
+}
+
+// WithIssuer returns the storage of an issuer sharing a backend with other
+// issuers. An additional issuer keeps its signing keys under its issuer URL,
+// and records it on the auth codes and refresh tokens it creates. The default
+// issuer, with an empty issuer URL, keeps the keys of the backend, and owns
+// the codes and tokens without an issuer. Codes and tokens of other issuers
+// aren't found. If clientIDs or connectorIDs are non-empty, only the listed
+// clients and connectors are visible.
+//
+// The keys of the default issuer must be generated before the keys of
+// additional issuers.
+func WithIssuer(s Storage, issuer string, clientIDs, connectorIDs []string) Storage {
+	return issuerStorage{
+		Storage:    s,
+		issuer:     issuer,
+		clients:    idSet(clientIDs),
+		connectors: idSet(connectorIDs),
+	}
+}
+
+func idSet(ids []string) map[string]bool {
+	if len(ids) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+func (s issuerStorage) GetKeys() (Keys, error) {
+	if s.issuer == "" {
+		return s.Storage.GetKeys()
+	}
+	keys, err := s.Storage.GetKeys()
+	if err != nil {
+		return Keys{}, err
+	}
+	return keys.IssuerKeys[s.issuer], nil
+}
+
+func (s issuerStorage) UpdateKeys(updater func(old Keys) (Keys, error)) error {
+	if s.issuer == "" {
+		return s.Storage.UpdateKeys(updater)
+	}
+	return s.Storage.UpdateKeys(func(old Keys) (Keys, error) {
+		if old.SigningKey == nil {
+			return old, errors.New("keys of the default issuer have not been generated")
+		}
+
+		updated, err := updater(old.IssuerKeys[s.issuer])
+		if err != nil {
+			return old, err
+		}
+		updated.IssuerKeys = nil
+
+		issuerKeys := make(map[string]Keys, len(old.IssuerKeys)+1)
+		for issuer, keys := range old.IssuerKeys {
+			issuerKeys[issuer] = keys
+		}
+		issuerKeys[s.issuer] = updated
+		old.IssuerKeys = issuerKeys
+		return old, nil
+	})
+}
+
+func (s issuerStorage) CreateAuthCode(ctx context.Context, c AuthCode) error {
+	c.Issuer = s.issuer
+	return s.Storage.CreateAuthCode(ctx, c)
+}
+
+func (s issuerStorage) GetAuthCode(id string) (AuthCode, error) {
+	c, err := s.Storage.GetAuthCode(id)
+	if err != nil {
+		return AuthCode{}, err
+	}
+	if c.Issuer != s.issuer {
+		return AuthCode{}, ErrNotFound
+	}
+	return c, nil
+}
+
+func (s issuerStorage) CreateRefresh(ctx context.Context, r RefreshToken) error {
+	r.Issuer = s.issuer
+	return s.Storage.CreateRefresh(ctx, r)
+}
+
+func (s issuerStorage) GetRefresh(id string) (RefreshToken, error) {
+	r, err := s.Storage.GetRefresh(id)
+	if err != nil {
+		return RefreshToken{}, err
+	}
+	if r.Issuer != s.issuer {
+		return RefreshToken{}, ErrNotFound
+	}
+	return r, nil
+}
+
+func (s issuerStorage) UpdateRefreshToken(id string, updater func(old RefreshToken) (RefreshToken, error)) error {
+	return s.Storage.UpdateRefreshToken(id, func(old RefreshToken) (RefreshToken, error) {
+		if old.Issuer != s.issuer {
+			return old, ErrNotFound
+		}
+		r, err := updater(old)
+		r.Issuer = s.issuer
+		return r, err
+	})
+}
+
+func (s issuerStorage) ListRefreshTokens() ([]RefreshToken, error) {
+	tokens, err := s.Storage.ListRefreshTokens()
+	if err != nil {
+		return nil, err
+	}
+	n := 0
+	for _, r := range tokens {
+		if r.Issuer == s.issuer {
+			tokens[n] = r
+			n++
+		}
+	}
+	return tokens[:n], nil
+}
+
+func (s issuerStorage) GetClient(id string) (Client, error) {
+	if s.clients != nil && !s.clients[id] {
+		return Client{}, ErrNotFound
+	}
+	return s.Storage.GetClient(id)
+}
+
+func (s issuerStorage) ListClients() ([]Client, error) {
+	clients, err := s.Storage.ListClients()
+	if err != nil || s.clients == nil {
+		return clients, err
+	}
+	n := 0
+	for _, client := range clients {
+		if s.clients[client.ID] {
+			clients[n] = client
+			n++
+		}
+	}
+	return clients[:n], nil
+}
+
+func (s issuerStorage) GetConnector(id string) (Connector, error) {
+	if s.connectors != nil && !s.connectors[id] {
+		return Connector{}, ErrNotFound
+	}
+	return s.Storage.GetConnector(id)
+}
+
+func (s issuerStorage) ListConnectors() ([]Connector, error) {
+	connectors, err := s.Storage.ListConnectors()
+	if err != nil || s.connectors == nil {
+		return connectors, err
+	}
+	n := 0
+	for _, c := range connectors {
+		if s.connectors[c.ID] {
+			connectors[n] = c
+			n++
+		}
+	}
+	return connectors[:n], nil
+}
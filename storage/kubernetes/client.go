@@ -54,6 +54,14 @@ type client struct {
 	// storage opening.
 	crdAPIVersion string
 
+	// Informers keeping frequently read resources in memory, by resource. Nil
+	// if reads always go to the API server.
+	informers map[string]*informer
+
+	// Elects the instance doing garbage collection and key rotation. Nil if
+	// leader election is disabled.
+	leader *storage.LeaseElection
+
 	// This is called once the client's Close method is called to signal goroutines,
 	// such as the one creating third party resources, to stop.
 	cancel context.CancelFunc
@@ -149,6 +157,11 @@ func (e *httpErr) StatusCode() int {
 	return e.status
 }
 
+// Is reports conflicts as storage.ErrConflict.
+func (e *httpErr) Is(target error) bool {
+	return target == storage.ErrConflict && e.status == http.StatusConflict
+}
+
 func (e *httpErr) Error() string {
 	return fmt.Sprintf("%s %s %s: response from server \"%s\"", e.method, e.url, http.StatusText(e.status), bytes.TrimSpace(e.body))
 }
@@ -192,6 +205,44 @@ func (cli *client) get(resource, name string, v interface{}) error {
 	return cli.getResource(cli.apiVersion, cli.namespace, resource, name, v)
 }
 
+// getCached is like get, but reads the object from memory if an informer
+// keeps the resource.
+func (cli *client) getCached(resource, name string, v interface{}) error {
+	if i, ok := cli.informers[resource]; ok {
+		if ok, err := i.get(name, v); ok {
+			return err
+		}
+	}
+	return cli.get(resource, name, v)
+}
+
+// listCached is like list, but reads the objects from memory if an informer
+// keeps the resource.
+func (cli *client) listCached(resource string, v interface{}) error {
+	if i, ok := cli.informers[resource]; ok {
+		if ok, err := i.list(v); ok {
+			return err
+		}
+	}
+	return cli.list(resource, v)
+}
+
+// written tells the informer of a resource about a write by this client, so
+// the object isn't read from memory until the informer has seen it.
+func (cli *client) written(resource string, resp *http.Response) {
+	i, ok := cli.informers[resource]
+	if !ok {
+		return
+	}
+	var obj struct {
+		k8sapi.ObjectMeta `json:"metadata,omitempty"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&obj); err != nil {
+		cli.logger.Debug("failed to decode written object", "resource", resource, "err", err)
+	}
+	i.written(obj.Name, obj.ResourceVersion)
+}
+
 func (cli *client) getURL(url string, v interface{}) error {
 	resp, err := cli.client.Get(url)
 	if err != nil {
@@ -212,7 +263,7 @@ func (cli *client) getResource(apiVersion, namespace, resource, name string, v i
 	return cli.getURL(u, v)
 }
 
-func (cli *client) listN(resource string, v interface{}, n int) error { //nolint:unparam // In practice, n is the gcResultLimit constant.
+func (cli *client) listN(resource string, v interface{}, n int) error {
 	params := url.Values{}
 	params.Add("limit", fmt.Sprintf("%d", n))
 	u, err := cli.urlForWithParams(cli.apiVersion, cli.namespace, resource, "", params)
@@ -227,25 +278,38 @@ func (cli *client) list(resource string, v interface{}) error {
 }
 
 func (cli *client) post(resource string, v interface{}) error {
-	return cli.postResource(cli.apiVersion, cli.namespace, resource, v)
+	resp, err := cli.postResponse(cli.apiVersion, cli.namespace, resource, v)
+	if err != nil {
+		return err
+	}
+	defer closeResp(resp)
+	if err := checkHTTPErr(resp, http.StatusCreated); err != nil {
+		return err
+	}
+	cli.written(resource, resp)
+	return nil
 }
 
 func (cli *client) postResource(apiVersion, namespace, resource string, v interface{}) error {
-	body, err := json.Marshal(v)
+	resp, err := cli.postResponse(apiVersion, namespace, resource, v)
 	if err != nil {
-		return fmt.Errorf("marshal object: %v", err)
+		return err
 	}
+	defer closeResp(resp)
+	return checkHTTPErr(resp, http.StatusCreated)
+}
 
-	url, err := cli.urlFor(apiVersion, namespace, resource, "")
+func (cli *client) postResponse(apiVersion, namespace, resource string, v interface{}) (*http.Response, error) {
+	body, err := json.Marshal(v)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("marshal object: %v", err)
 	}
-	resp, err := cli.client.Post(url, "application/json", bytes.NewReader(body))
+
+	url, err := cli.urlFor(apiVersion, namespace, resource, "")
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer closeResp(resp)
-	return checkHTTPErr(resp, http.StatusCreated)
+	return cli.client.Post(url, "application/json", bytes.NewReader(body))
 }
 
 func (cli *client) detectKubernetesVersion() error {
@@ -293,7 +357,13 @@ func (cli *client) delete(resource, name string) error {
 		return fmt.Errorf("delete request: %v", err)
 	}
 	defer closeResp(resp)
-	return checkHTTPErr(resp, http.StatusOK)
+	if err := checkHTTPErr(resp, http.StatusOK); err != nil {
+		return err
+	}
+	if i, ok := cli.informers[resource]; ok {
+		i.written(name, "")
+	}
+	return nil
 }
 
 func (cli *client) deleteAll(resource string) error {
@@ -317,30 +387,51 @@ func (cli *client) deleteAll(resource string) error {
 }
 
 func (cli *client) put(resource, name string, v interface{}) error {
-	body, err := json.Marshal(v)
+	resp, err := cli.putResponse(cli.apiVersion, cli.namespace, resource, name, v)
 	if err != nil {
-		return fmt.Errorf("marshal object: %v", err)
+		return err
 	}
+	defer closeResp(resp)
 
-	url, err := cli.urlFor(cli.apiVersion, cli.namespace, resource, name)
+	if err := checkHTTPErr(resp, http.StatusOK); err != nil {
+		return err
+	}
+	cli.written(resource, resp)
+	return nil
+}
+
+func (cli *client) putResource(apiVersion, namespace, resource, name string, v interface{}) error {
+	resp, err := cli.putResponse(apiVersion, namespace, resource, name, v)
 	if err != nil {
 		return err
 	}
+	defer closeResp(resp)
+	return checkHTTPErr(resp, http.StatusOK)
+}
+
+func (cli *client) putResponse(apiVersion, namespace, resource, name string, v interface{}) (*http.Response, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("marshal object: %v", err)
+	}
+
+	url, err := cli.urlFor(apiVersion, namespace, resource, name)
+	if err != nil {
+		return nil, err
+	}
 
 	req, err := http.NewRequest("PUT", url, bytes.NewReader(body))
 	if err != nil {
-		return fmt.Errorf("create patch request: %v", err)
+		return nil, fmt.Errorf("create patch request: %v", err)
 	}
 
 	req.Header.Set("Content-Length", strconv.Itoa(len(body)))
 
 	resp, err := cli.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("patch request: %v", err)
+		return nil, fmt.Errorf("patch request: %v", err)
 	}
-	defer closeResp(resp)
-
-	return checkHTTPErr(resp, http.StatusOK)
+	return resp, nil
 }
 
 // Copied from https://github.com/gtank/cryptopasta
@@ -0,0 +1,284 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dexidp/dex/storage/kubernetes/k8sapi"
+)
+
+const (
+	// informerRetryPeriod is the time between failed attempts to list a resource.
+	informerRetryPeriod = 5 * time.Second
+	// informerWatchTimeout asks the API server to end watches after a while,
+	// after which the resource is listed again.
+	informerWatchTimeout = 5 * time.Minute
+)
+
+// errWatchExpired is returned for watches from a resource version the API
+// server no longer has, which requires listing the resource again.
+var errWatchExpired = errors.New("resource version expired")
+
+// cachedResources are the resources read on most requests, which are kept in
+// memory by informers rather than fetched from the API server every time.
+var cachedResources = []string{resourceClient, resourceConnector, resourceKeys}
+
+// informedObject is an object of a resource as last seen by an informer.
+type informedObject struct {
+	resourceVersion string
+	raw             json.RawMessage
+}
+
+// pendingWrite is a write by this client not yet seen by an informer.
+type pendingWrite struct {
+	// resourceVersion of the object written, or empty if it was deleted.
+	resourceVersion string
+	time            time.Time
+}
+
+// informer keeps a copy of all objects of a resource in memory by listing
+// and watching it, similar to the shared informers of client-go.
+//
+// Reads are only served from memory once the first list completes. Objects
+// written by this client are read from the API server until the informer
+// sees the write, so the client always reads its own writes.
+type informer struct {
+	cli      *client
+	resource string
+
+	mu      sync.RWMutex
+	synced  bool
+	objects map[string]informedObject
+	pending map[string]pendingWrite
+}
+
+func newInformer(cli *client, resource string) *informer {
+	return &informer{
+		cli:      cli,
+		resource: resource,
+		objects:  make(map[string]informedObject),
+		pending:  make(map[string]pendingWrite),
+	}
+}
+
+// get decodes the object with the given name into v, reporting false if the
+// object has to be read from the API server instead.
+func (i *informer) get(name string, v interface{}) (bool, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	if !i.synced {
+		return false, nil
+	}
+	if _, ok := i.pending[name]; ok {
+		return false, nil
+	}
+	obj, ok := i.objects[name]
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(obj.raw, v)
+}
+
+// list decodes all objects into a list v, reporting false if the list has to
+// be read from the API server instead.
+func (i *informer) list(v interface{}) (bool, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	if !i.synced || len(i.pending) > 0 {
+		return false, nil
+	}
+	names := make([]string, 0, len(i.objects))
+	for name := range i.objects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	list := struct {
+		Items []json.RawMessage `json:"items"`
+	}{Items: make([]json.RawMessage, len(names))}
+	for n, name := range names {
+		list.Items[n] = i.objects[name].raw
+	}
+	data, err := json.Marshal(list)
+	if err != nil {
+		return true, err
+	}
+	return true, json.Unmarshal(data, v)
+}
+
+// written records a write by this client, which the informer may not have
+// seen yet. The resource version is empty for deletes.
+func (i *informer) written(name, resourceVersion string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if obj, ok := i.objects[name]; ok && resourceVersion != "" && obj.resourceVersion == resourceVersion {
+		delete(i.pending, name)
+		return
+	}
+	i.pending[name] = pendingWrite{resourceVersion, time.Now()}
+}
+
+// run lists and watches the resource until the context is canceled.
+func (i *informer) run(ctx context.Context) {
+	for {
+		resourceVersion, err := i.relist()
+		if err == nil {
+			err = i.watch(ctx, resourceVersion)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			i.cli.logger.Error("kubernetes informer failed, retrying", "resource", i.resource, "err", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(informerRetryPeriod):
+			}
+		}
+	}
+}
+
+// relist replaces the objects in memory with a list of the resource and
+// returns the resource version to watch from.
+func (i *informer) relist() (string, error) {
+	start := time.Now()
+
+	var list struct {
+		k8sapi.ListMeta `json:"metadata,omitempty"`
+		Items           []json.RawMessage `json:"items"`
+	}
+	if err := i.cli.list(i.resource, &list); err != nil {
+		return "", fmt.Errorf("list %s: %v", i.resource, err)
+	}
+
+	objects := make(map[string]informedObject, len(list.Items))
+	for _, raw := range list.Items {
+		meta, err := objectMeta(raw)
+		if err != nil {
+			return "", err
+		}
+		objects[meta.Name] = informedObject{meta.ResourceVersion, raw}
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.objects = objects
+	i.synced = true
+	// The list includes every write that completed before it started.
+	for name, p := range i.pending {
+		if p.time.Before(start) {
+			delete(i.pending, name)
+		}
+	}
+	return list.ResourceVersion, nil
+}
+
+// watchEvent is an event streamed by the watch API.
+type watchEvent struct {
+	Type   string          `json:"type"`
+	Object json.RawMessage `json:"object"`
+}
+
+// watch applies the changes to the resource since resourceVersion until the
+// API server ends the watch.
+func (i *informer) watch(ctx context.Context, resourceVersion string) error {
+	params := url.Values{}
+	params.Set("watch", "true")
+	params.Set("resourceVersion", resourceVersion)
+	params.Set("timeoutSeconds", strconv.Itoa(int(informerWatchTimeout.Seconds())))
+	u, err := i.cli.urlForWithParams(i.cli.apiVersion, i.cli.namespace, i.resource, "", params)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+
+	// The client of the storage times out requests, watches are long-lived.
+	watchClient := &http.Client{Transport: i.cli.client.Transport}
+	resp, err := watchClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("watch %s: %v", i.resource, err)
+	}
+	defer closeResp(resp)
+	if err := checkHTTPErr(resp, http.StatusOK); err != nil {
+		return fmt.Errorf("watch %s: %v", i.resource, err)
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var event watchEvent
+		if err := decoder.Decode(&event); err != nil {
+			// The API server ends the stream once the watch times out. Other
+			// failures are handled the same way, by listing again.
+			return nil
+		}
+		if err := i.apply(event); err != nil {
+			if errors.Is(err, errWatchExpired) {
+				return nil
+			}
+			return fmt.Errorf("watch %s: %v", i.resource, err)
+		}
+	}
+}
+
+// apply updates the objects in memory with an event. Errors, for instance
+// because the resource version to watch from is too old, end the watch so
+// the resource is listed again.
+func (i *informer) apply(event watchEvent) error {
+	if event.Type == "ERROR" {
+		var status struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		}
+		json.Unmarshal(event.Object, &status)
+		if status.Code == http.StatusGone {
+			return errWatchExpired
+		}
+		return fmt.Errorf("error event %d: %s", status.Code, status.Message)
+	}
+	if event.Type == "BOOKMARK" {
+		return nil
+	}
+
+	meta, err := objectMeta(event.Object)
+	if err != nil {
+		return err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	switch event.Type {
+	case "ADDED", "MODIFIED":
+		i.objects[meta.Name] = informedObject{meta.ResourceVersion, event.Object}
+		if p, ok := i.pending[meta.Name]; ok && p.resourceVersion == meta.ResourceVersion {
+			delete(i.pending, meta.Name)
+		}
+	case "DELETED":
+		delete(i.objects, meta.Name)
+		if p, ok := i.pending[meta.Name]; ok && p.resourceVersion == "" {
+			delete(i.pending, meta.Name)
+		}
+	}
+	return nil
+}
+
+func objectMeta(raw json.RawMessage) (k8sapi.ObjectMeta, error) {
+	var obj struct {
+		k8sapi.ObjectMeta `json:"metadata,omitempty"`
+	}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return k8sapi.ObjectMeta{}, fmt.Errorf("decode object: %v", err)
+	}
+	return obj.ObjectMeta, nil
+}
@@ -0,0 +1,112 @@
+package kubernetes
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func connectorJSON(name, resourceVersion, connectorName string) string {
+	return fmt.Sprintf(`{"metadata":{"name":%q,"resourceVersion":%q},"id":%q,"name":%q}`,
+		name, resourceVersion, name, connectorName)
+}
+
+func TestInformer(t *testing.T) {
+	events := make(chan string)
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("watch") == "" {
+			fmt.Fprintf(w, `{"metadata":{"resourceVersion":"1"},"items":[%s]}`, connectorJSON("a", "1", "A"))
+			return
+		}
+		require.Equal(t, "1", r.URL.Query().Get("resourceVersion"))
+		w.WriteHeader(http.StatusOK)
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case event := <-events:
+				fmt.Fprintln(w, event)
+				w.(http.Flusher).Flush()
+			}
+		}
+	}))
+	defer s.Close()
+
+	cli := &client{
+		client: &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}},
+		baseURL:    s.URL,
+		apiVersion: "dex.coreos.com/v1",
+		namespace:  "dex",
+		logger:     slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})),
+	}
+	i := newInformer(cli, resourceConnector)
+
+	var c Connector
+	ok, err := i.get("a", &c)
+	require.NoError(t, err)
+	require.False(t, ok, "informer served a read before listing")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go i.run(ctx)
+
+	require.Eventually(t, func() bool {
+		ok, _ := i.get("a", &c)
+		return ok
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, "A", c.Name)
+
+	events <- fmt.Sprintf(`{"type":"ADDED","object":%s}`, connectorJSON("b", "2", "B"))
+	require.Eventually(t, func() bool {
+		ok, _ := i.get("b", &c)
+		return ok
+	}, 5*time.Second, 10*time.Millisecond)
+
+	var list ConnectorList
+	ok, err = i.list(&list)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Len(t, list.Connectors, 2)
+
+	// Objects written by the client are read live until the informer sees the write.
+	i.written("a", "3")
+	ok, _ = i.get("a", &c)
+	require.False(t, ok, "informer served an object written by the client before seeing the write")
+	ok, _ = i.list(&list)
+	require.False(t, ok, "informer served a list with a pending write")
+
+	events <- fmt.Sprintf(`{"type":"MODIFIED","object":%s}`, connectorJSON("a", "3", "A2"))
+	require.Eventually(t, func() bool {
+		ok, _ := i.get("a", &c)
+		return ok
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, "A2", c.Name)
+
+	i.written("b", "")
+	events <- fmt.Sprintf(`{"type":"DELETED","object":%s}`, connectorJSON("b", "4", "B"))
+	require.Eventually(t, func() bool {
+		ok, _ := i.list(&list)
+		return ok && len(list.Connectors) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestInformerWatchExpired(t *testing.T) {
+	i := newInformer(nil, resourceConnector)
+
+	err := i.apply(watchEvent{Type: "ERROR", Object: []byte(`{"code":410,"message":"too old resource version"}`)})
+	require.ErrorIs(t, err, errWatchExpired)
+
+	err = i.apply(watchEvent{Type: "ERROR", Object: []byte(`{"code":500,"message":"internal error"}`)})
+	require.Error(t, err)
+	require.NotErrorIs(t, err, errWatchExpired)
+}
@@ -0,0 +1,166 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dexidp/dex/storage"
+	"github.com/dexidp/dex/storage/kubernetes/k8sapi"
+)
+
+const (
+	leaseAPIVersion = "coordination.k8s.io/v1"
+	resourceLease   = "leases"
+)
+
+var _ storage.LeaderElector = (*client)(nil)
+
+// microTime is a time in the format used by the times of leases.
+type microTime struct {
+	time.Time
+}
+
+const rfc3339Micro = "2006-01-02T15:04:05.000000Z07:00"
+
+func (t microTime) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.UTC().Format(rfc3339Micro))
+}
+
+func (t *microTime) UnmarshalJSON(b []byte) error {
+	var s *string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	if s == nil {
+		t.Time = time.Time{}
+		return nil
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, *s)
+	if err != nil {
+		return err
+	}
+	t.Time = parsed
+	return nil
+}
+
+// Lease is a coordination.k8s.io/v1 Lease, used to elect a leader among the
+// dex instances sharing the storage.
+type Lease struct {
+	k8sapi.TypeMeta   `json:",inline"`
+	k8sapi.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec LeaseSpec `json:"spec"`
+}
+
+// LeaseSpec is the specification of a Lease.
+type LeaseSpec struct {
+	HolderIdentity       string    `json:"holderIdentity,omitempty"`
+	LeaseDurationSeconds int       `json:"leaseDurationSeconds,omitempty"`
+	AcquireTime          microTime `json:"acquireTime,omitempty"`
+	RenewTime            microTime `json:"renewTime,omitempty"`
+	LeaseTransitions     int       `json:"leaseTransitions,omitempty"`
+}
+
+// leaseHolder holds a Lease while this instance is the leader.
+type leaseHolder struct {
+	cli      *client
+	name     string
+	identity string
+
+	// The lease as last seen held by another instance and when it was seen.
+	// Like client-go, expiry is judged by the local clock since the holder last
+	// changed the lease, so the clocks of the instances needn't be in sync.
+	observedHolder  string
+	observedRenewal time.Time
+	observedAt      time.Time
+}
+
+func newLeaderElection(cli *client, name string) *storage.LeaseElection {
+	if name == "" {
+		name = storage.DefaultLeaseName
+	}
+	l := &leaseHolder{cli: cli, name: name, identity: storage.NewLeaseIdentity()}
+	return storage.NewLeaseElection(cli.logger, name, l.identity, l.acquireOrRenew, l.release)
+}
+
+// acquireOrRenew updates the lease to be held by this instance if it already
+// holds it or the lease expired, reporting whether it holds the lease.
+func (l *leaseHolder) acquireOrRenew(now time.Time) (bool, error) {
+	var lease Lease
+	err := l.cli.getResource(leaseAPIVersion, l.cli.namespace, resourceLease, l.name, &lease)
+	if err == storage.ErrNotFound {
+		lease = Lease{
+			TypeMeta: k8sapi.TypeMeta{
+				Kind:       "Lease",
+				APIVersion: leaseAPIVersion,
+			},
+			ObjectMeta: k8sapi.ObjectMeta{
+				Name:      l.name,
+				Namespace: l.cli.namespace,
+			},
+			Spec: LeaseSpec{
+				HolderIdentity:       l.identity,
+				LeaseDurationSeconds: int(storage.LeaseDuration.Seconds()),
+				AcquireTime:          microTime{now},
+				RenewTime:            microTime{now},
+			},
+		}
+		if err := l.cli.postResource(leaseAPIVersion, l.cli.namespace, resourceLease, lease); err != nil {
+			if err == storage.ErrAlreadyExists {
+				// Another instance created it first.
+				return false, nil
+			}
+			return false, fmt.Errorf("create lease: %v", err)
+		}
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("get lease: %v", err)
+	}
+
+	if holder := lease.Spec.HolderIdentity; holder != l.identity {
+		if holder != "" {
+			if holder != l.observedHolder || !lease.Spec.RenewTime.Equal(l.observedRenewal) {
+				l.observedHolder = holder
+				l.observedRenewal = lease.Spec.RenewTime.Time
+				l.observedAt = now
+			}
+			duration := time.Duration(lease.Spec.LeaseDurationSeconds) * time.Second
+			if now.Before(l.observedAt.Add(duration)) {
+				return false, nil
+			}
+		}
+		lease.Spec.HolderIdentity = l.identity
+		lease.Spec.AcquireTime = microTime{now}
+		lease.Spec.LeaseTransitions++
+	}
+	lease.Spec.LeaseDurationSeconds = int(storage.LeaseDuration.Seconds())
+	lease.Spec.RenewTime = microTime{now}
+
+	// The resource version of the lease makes this a compare-and-swap, which
+	// fails if another instance updated the lease in the meantime.
+	if err := l.cli.putResource(leaseAPIVersion, l.cli.namespace, resourceLease, l.name, lease); err != nil {
+		if isKubernetesAPIConflictError(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("update lease: %v", err)
+	}
+	return true, nil
+}
+
+// release gives up the lease if this instance holds it.
+func (l *leaseHolder) release() error {
+	var lease Lease
+	if err := l.cli.getResource(leaseAPIVersion, l.cli.namespace, resourceLease, l.name, &lease); err != nil {
+		return err
+	}
+	if lease.Spec.HolderIdentity != l.identity {
+		return nil
+	}
+	lease.Spec.HolderIdentity = ""
+	return l.cli.putResource(leaseAPIVersion, l.cli.namespace, resourceLease, l.name, lease)
+}
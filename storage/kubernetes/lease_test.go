@@ -0,0 +1,112 @@
+package kubernetes
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dexidp/dex/storage"
+)
+
+// newLeaseTestClient returns a client of an API server that only stores a
+// single lease, rejecting updates with an outdated resource version.
+func newLeaseTestClient(t *testing.T) *client {
+	var (
+		mu      sync.Mutex
+		lease   *Lease
+		version int
+	)
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		require.True(t, strings.HasPrefix(r.URL.Path, "/apis/coordination.k8s.io/v1/namespaces/dex/leases"), r.URL.Path)
+
+		switch r.Method {
+		case http.MethodGet:
+			if lease == nil {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(lease)
+		case http.MethodPost, http.MethodPut:
+			var l Lease
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&l))
+			if r.Method == http.MethodPost && lease != nil {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			if r.Method == http.MethodPut && l.ResourceVersion != strconv.Itoa(version) {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			version++
+			l.ResourceVersion = strconv.Itoa(version)
+			lease = &l
+			if r.Method == http.MethodPost {
+				w.WriteHeader(http.StatusCreated)
+			}
+			json.NewEncoder(w).Encode(lease)
+		}
+	}))
+	t.Cleanup(s.Close)
+
+	return &client{
+		client: &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}},
+		baseURL:   s.URL,
+		namespace: "dex",
+		logger:    slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})),
+	}
+}
+
+func TestLeaderElection(t *testing.T) {
+	cli := newLeaseTestClient(t)
+
+	now := time.Now()
+	clock := func() time.Time { return now }
+
+	a := newLeaderElection(cli, "")
+	a.Now = clock
+	b := newLeaderElection(cli, "")
+	b.Now = clock
+
+	a.TryAcquireOrRenew()
+	b.TryAcquireOrRenew()
+	require.True(t, a.IsLeader(), "first instance didn't acquire the lease")
+	require.False(t, b.IsLeader(), "second instance acquired a held lease")
+
+	// The leader renews the lease, so it doesn't expire.
+	now = now.Add(10 * time.Second)
+	a.TryAcquireOrRenew()
+	b.TryAcquireOrRenew()
+	require.True(t, a.IsLeader())
+	require.False(t, b.IsLeader())
+
+	// The other instance takes over once the leader stops renewing the lease.
+	now = now.Add(storage.LeaseDuration + time.Second)
+	require.False(t, a.IsLeader(), "leadership didn't expire without renewal")
+	b.TryAcquireOrRenew()
+	require.True(t, b.IsLeader(), "second instance didn't take over an expired lease")
+
+	// Releasing the lease lets another instance take over right away.
+	b.Release()
+	require.False(t, b.IsLeader())
+	a.TryAcquireOrRenew()
+	require.True(t, a.IsLeader(), "first instance didn't acquire a released lease")
+}
+
+func TestIsLeaderWithoutLeaderElection(t *testing.T) {
+	cli := &client{}
+	require.True(t, cli.IsLeader())
+}
@@ -25,6 +25,7 @@ const (
 	kindConnector       = "Connector"
 	kindDeviceRequest   = "DeviceRequest"
 	kindDeviceToken     = "DeviceToken"
+	kindWebhook         = "Webhook"
 )
 
 const (
@@ -38,11 +39,14 @@ const (
 	resourceConnector       = "connectors"
 	resourceDeviceRequest   = "devicerequests"
 	resourceDeviceToken     = "devicetokens"
+	resourceWebhook         = "webhooks"
 )
 
 var _ storage.Storage = (*client)(nil)
 
 const (
+	// gcResultLimit is the number of objects of each resource checked by a
+	// garbage collection run, unless a batch size is configured.
 	gcResultLimit = 500
 )
 
@@ -50,6 +54,18 @@ const (
 type Config struct {
 	InCluster      bool   `json:"inCluster"`
 	KubeConfigFile string `json:"kubeConfigFile"`
+
+	// DisableInformers reads clients, connectors and signing keys from the API
+	// server on every request, instead of watching them and keeping them in
+	// memory.
+	DisableInformers bool `json:"disableInformers"`
+
+	// LeaderElection lets only the dex instance holding a coordination.k8s.io
+	// Lease run garbage collection and key rotation. This requires permission
+	// to get, create and update leases in the namespace of dex.
+	LeaderElection bool `json:"leaderElection"`
+	// LeaseName is the name of the lease. Defaults to "dex".
+	LeaseName string `json:"leaseName"`
 }
 
 // Open returns a storage using Kubernetes third party resource.
@@ -132,6 +148,20 @@ func (c *Config) open(logger *slog.Logger, waitForResources bool) (*client, erro
 		}
 	}
 
+	if !c.DisableInformers {
+		cli.informers = make(map[string]*informer, len(cachedResources))
+		for _, resource := range cachedResources {
+			i := newInformer(cli, resource)
+			cli.informers[resource] = i
+			go i.run(ctx)
+		}
+	}
+
+	if c.LeaderElection {
+		cli.leader = newLeaderElection(cli, c.LeaseName)
+		go cli.leader.Run(ctx)
+	}
+
 	// If the client is closed, stop trying to create resources.
 	cli.cancel = cancel
 	return cli, nil
@@ -227,6 +257,15 @@ func (cli *client) isCRDReady(name string) error {
 	return fmt.Errorf("crd %s not ready %#v", name, conds)
 }
 
+// IsLeader reports whether this instance holds the lease, or always true if
+// leader election is disabled.
+func (cli *client) IsLeader() bool {
+	if cli.leader == nil {
+		return true
+	}
+	return cli.leader.IsLeader()
+}
+
 func (cli *client) Close() error {
 	if cli.cancel != nil {
 		cli.cancel()
@@ -279,17 +318,18 @@ func (cli *client) GetAuthCode(id string) (storage.AuthCode, error) {
 }
 
 func (cli *client) GetClient(id string) (storage.Client, error) {
-	c, err := cli.getClient(id)
+	c, err := cli.getClient(cli.getCached, id)
 	if err != nil {
 		return storage.Client{}, err
 	}
 	return toStorageClient(c), nil
 }
 
-func (cli *client) getClient(id string) (Client, error) {
+// getClient reads a client with get, which is either a live or cached read.
+func (cli *client) getClient(get func(resource, name string, v interface{}) error, id string) (Client, error) {
 	var c Client
 	name := cli.idToName(id)
-	if err := cli.get(resourceClient, name, &c); err != nil {
+	if err := get(resourceClient, name, &c); err != nil {
 		return Client{}, err
 	}
 	if c.ID != id {
@@ -322,7 +362,7 @@ func (cli *client) getPassword(email string) (Password, error) {
 
 func (cli *client) GetKeys() (storage.Keys, error) {
 	var keys Keys
-	if err := cli.get(resourceKeys, keysName, &keys); err != nil {
+	if err := cli.getCached(resourceKeys, keysName, &keys); err != nil {
 		return storage.Keys{}, err
 	}
 	return toStorageKeys(keys), nil
@@ -362,7 +402,7 @@ func (cli *client) getOfflineSessions(userID string, connID string) (o OfflineSe
 
 func (cli *client) GetConnector(id string) (storage.Connector, error) {
 	var c Connector
-	if err := cli.get(resourceConnector, id, &c); err != nil {
+	if err := cli.getCached(resourceConnector, id, &c); err != nil {
 		return storage.Connector{}, err
 	}
 	return toStorageConnector(c), nil
@@ -383,13 +423,7 @@ func (cli *client) ListPasswords() (passwords []storage.Password, err error) {
 	}
 
 	for _, password := range passwordList.Passwords {
-		p := storage.Password{
-			Email:    password.Email,
-			Hash:     password.Hash,
-			Username: password.Username,
-			UserID:   password.UserID,
-		}
-		passwords = append(passwords, p)
+		passwords = append(passwords, toStoragePassword(password))
 	}
 
 	return
@@ -397,7 +431,7 @@ func (cli *client) ListPasswords() (passwords []storage.Password, err error) {
 
 func (cli *client) ListConnectors() (connectors []storage.Connector, err error) {
 	var connectorList ConnectorList
-	if err = cli.list(resourceConnector, &connectorList); err != nil {
+	if err = cli.listCached(resourceConnector, &connectorList); err != nil {
 		return connectors, fmt.Errorf("failed to list connectors: %v", err)
 	}
 
@@ -419,7 +453,7 @@ func (cli *client) DeleteAuthCode(code string) error {
 
 func (cli *client) DeleteClient(id string) error {
 	// Check for hash collision.
-	c, err := cli.getClient(id)
+	c, err := cli.getClient(cli.get, id)
 	if err != nil {
 		return err
 	}
@@ -480,7 +514,7 @@ func (cli *client) UpdateRefreshToken(id string, updater func(old storage.Refres
 }
 
 func (cli *client) UpdateClient(id string, updater func(old storage.Client) (storage.Client, error)) error {
-	c, err := cli.getClient(id)
+	c, err := cli.getClient(cli.get, id)
 	if err != nil {
 		return err
 	}
@@ -612,74 +646,84 @@ func (cli *client) UpdateConnector(id string, updater func(a storage.Connector)
 	})
 }
 
-func (cli *client) GarbageCollect(now time.Time) (result storage.GCResult, err error) {
-	var authRequests AuthRequestList
-	if err := cli.listN(resourceAuthRequest, &authRequests, gcResultLimit); err != nil {
-		return result, fmt.Errorf("failed to list auth requests: %v", err)
+func (cli *client) GarbageCollect(now time.Time, opts storage.GCOptions) (result storage.GCResult, err error) {
+	limit := gcResultLimit
+	if opts.BatchSize > 0 {
+		limit = opts.BatchSize
 	}
 
 	var delErr error
-	for _, authRequest := range authRequests.AuthRequests {
-		if now.After(authRequest.Expiry) {
-			if err := cli.delete(resourceAuthRequest, authRequest.ObjectMeta.Name); err != nil {
-				cli.logger.Error("failed to delete auth request", "err", err)
-				delErr = fmt.Errorf("failed to delete auth request: %v", err)
+	if opts.Includes(storage.GCAuthRequests) {
+		var authRequests AuthRequestList
+		if err := cli.listN(resourceAuthRequest, &authRequests, limit); err != nil {
+			return result, fmt.Errorf("failed to list auth requests: %v", err)
+		}
+
+		for _, authRequest := range authRequests.AuthRequests {
+			if now.After(authRequest.Expiry) {
+				if err := cli.delete(resourceAuthRequest, authRequest.ObjectMeta.Name); err != nil {
+					cli.logger.Error("failed to delete auth request", "err", err)
+					delErr = fmt.Errorf("failed to delete auth request: %v", err)
+				}
+				result.AuthRequests++
 			}
-			result.AuthRequests++
 		}
-	}
-	if delErr != nil {
-		return result, delErr
+		if delErr != nil {
+			return result, delErr
+		}
 	}
 
-	var authCodes AuthCodeList
-	if err := cli.listN(resourceAuthCode, &authCodes, gcResultLimit); err != nil {
-		return result, fmt.Errorf("failed to list auth codes: %v", err)
-	}
+	if opts.Includes(storage.GCAuthCodes) {
+		var authCodes AuthCodeList
+		if err := cli.listN(resourceAuthCode, &authCodes, limit); err != nil {
+			return result, fmt.Errorf("failed to list auth codes: %v", err)
+		}
 
-	for _, authCode := range authCodes.AuthCodes {
-		if now.After(authCode.Expiry) {
-			if err := cli.delete(resourceAuthCode, authCode.ObjectMeta.Name); err != nil {
-				cli.logger.Error("failed to delete auth code", "err", err)
-				delErr = fmt.Errorf("failed to delete auth code: %v", err)
+		for _, authCode := range authCodes.AuthCodes {
+			if now.After(authCode.Expiry) {
+				if err := cli.delete(resourceAuthCode, authCode.ObjectMeta.Name); err != nil {
+					cli.logger.Error("failed to delete auth code", "err", err)
+					delErr = fmt.Errorf("failed to delete auth code: %v", err)
+				}
+				result.AuthCodes++
 			}
-			result.AuthCodes++
 		}
 	}
 
-	var deviceRequests DeviceRequestList
-	if err := cli.listN(resourceDeviceRequest, &deviceRequests, gcResultLimit); err != nil {
-		return result, fmt.Errorf("failed to list device requests: %v", err)
-	}
+	if opts.Includes(storage.GCDeviceRequests) {
+		var deviceRequests DeviceRequestList
+		if err := cli.listN(resourceDeviceRequest, &deviceRequests, limit); err != nil {
+			return result, fmt.Errorf("failed to list device requests: %v", err)
+		}
 
-	for _, deviceRequest := range deviceRequests.DeviceRequests {
-		if now.After(deviceRequest.Expiry) {
-			if err := cli.delete(resourceDeviceRequest, deviceRequest.ObjectMeta.Name); err != nil {
-				cli.logger.Error("failed to delete device request", "err", err)
-				delErr = fmt.Errorf("failed to delete device request: %v", err)
+		for _, deviceRequest := range deviceRequests.DeviceRequests {
+			if now.After(deviceRequest.Expiry) {
+				if err := cli.delete(resourceDeviceRequest, deviceRequest.ObjectMeta.Name); err != nil {
+					cli.logger.Error("failed to delete device request", "err", err)
+					delErr = fmt.Errorf("failed to delete device request: %v", err)
+				}
+				result.DeviceRequests++
 			}
-			result.DeviceRequests++
 		}
 	}
 
-	var deviceTokens DeviceTokenList
-	if err := cli.listN(resourceDeviceToken, &deviceTokens, gcResultLimit); err != nil {
-		return result, fmt.Errorf("failed to list device tokens: %v", err)
-	}
+	if opts.Includes(storage.GCDeviceTokens) {
+		var deviceTokens DeviceTokenList
+		if err := cli.listN(resourceDeviceToken, &deviceTokens, limit); err != nil {
+			return result, fmt.Errorf("failed to list device tokens: %v", err)
+		}
 
-	for _, deviceToken := range deviceTokens.DeviceTokens {
-		if now.After(deviceToken.Expiry) {
-			if err := cli.delete(resourceDeviceToken, deviceToken.ObjectMeta.Name); err != nil {
-				cli.logger.Error("failed to delete device token", "err", err)
-				delErr = fmt.Errorf("failed to delete device token: %v", err)
+		for _, deviceToken := range deviceTokens.DeviceTokens {
+			if now.After(deviceToken.Expiry) {
+				if err := cli.delete(resourceDeviceToken, deviceToken.ObjectMeta.Name); err != nil {
+					cli.logger.Error("failed to delete device token", "err", err)
+					delErr = fmt.Errorf("failed to delete device token: %v", err)
+				}
+				result.DeviceTokens++
 			}
-			result.DeviceTokens++
 		}
 	}
 
-	if delErr != nil {
-		return result, delErr
-	}
 	return result, delErr
 }
 
@@ -695,6 +739,18 @@ func (cli *client) GetDeviceRequest(userCode string) (storage.DeviceRequest, err
 	return toStorageDeviceRequest(req), nil
 }
 
+func (cli *client) ListDeviceRequests() (requests []storage.DeviceRequest, err error) {
+	var deviceRequests DeviceRequestList
+	if err = cli.list(resourceDeviceRequest, &deviceRequests); err != nil {
+		return requests, fmt.Errorf("failed to list device requests: %v", err)
+	}
+
+	for _, req := range deviceRequests.DeviceRequests {
+		requests = append(requests, toStorageDeviceRequest(req))
+	}
+	return
+}
+
 func (cli *client) CreateDeviceToken(ctx context.Context, t storage.DeviceToken) error {
 	return cli.post(resourceDeviceToken, cli.fromStorageDeviceToken(t))
 }
@@ -769,3 +825,50 @@ func retryOnConflict(ctx context.Context, action func() error) error {
 		}
 	}
 }
+
+func (cli *client) CreateWebhook(ctx context.Context, w storage.Webhook) error {
+	return cli.post(resourceWebhook, cli.fromStorageWebhook(w))
+}
+
+func (cli *client) GetWebhook(id string) (storage.Webhook, error) {
+	var w Webhook
+	if err := cli.get(resourceWebhook, id, &w); err != nil {
+		return storage.Webhook{}, err
+	}
+	return toStorageWebhook(w), nil
+}
+
+func (cli *client) ListWebhooks() (webhooks []storage.Webhook, err error) {
+	var webhookList WebhookList
+	if err = cli.list(resourceWebhook, &webhookList); err != nil {
+		return webhooks, fmt.Errorf("failed to list webhooks: %v", err)
+	}
+
+	webhooks = make([]storage.Webhook, len(webhookList.Webhooks))
+	for i, w := range webhookList.Webhooks {
+		webhooks[i] = toStorageWebhook(w)
+	}
+	return
+}
+
+func (cli *client) DeleteWebhook(id string) error {
+	return cli.delete(resourceWebhook, id)
+}
+
+func (cli *client) UpdateWebhook(id string, updater func(w storage.Webhook) (storage.Webhook, error)) error {
+	return retryOnConflict(context.TODO(), func() error {
+		var w Webhook
+		if err := cli.get(resourceWebhook, id, &w); err != nil {
+			return err
+		}
+
+		updated, err := updater(toStorageWebhook(w))
+		if err != nil {
+			return err
+		}
+
+		newWebhook := cli.fromStorageWebhook(updated)
+		newWebhook.ObjectMeta = w.ObjectMeta
+		return cli.put(resourceWebhook, id, newWebhook)
+	})
+}
@@ -87,6 +87,7 @@ func (s *StorageTestSuite) TestStorage() {
 
 	conformance.RunTests(s.T(), newStorage)
 	conformance.RunTransactionTests(s.T(), newStorage)
+	conformance.RunConcurrencyTests(s.T(), newStorage)
 }
 
 func TestURLFor(t *testing.T) {
@@ -226,6 +226,23 @@ func customResourceDefinitions(apiVersion string) []k8sapi.CustomResourceDefinit
 				},
 			},
 		},
+		{
+			ObjectMeta: k8sapi.ObjectMeta{
+				Name: "webhooks.dex.coreos.com",
+			},
+			TypeMeta: crdMeta,
+			Spec: k8sapi.CustomResourceDefinitionSpec{
+				Group:    apiGroup,
+				Version:  version,
+				Versions: versions,
+				Scope:    scope,
+				Names: k8sapi.CustomResourceDefinitionNames{
+					Plural:   "webhooks",
+					Singular: "webhook",
+					Kind:     "Webhook",
+				},
+			},
+		},
 	}
 }
 
@@ -243,14 +260,31 @@ type Client struct {
 	// ID is immutable, since it's a primary key and should not be changed.
 	ID string `json:"id,omitempty"`
 
-	Secret       string   `json:"secret,omitempty"`
-	RedirectURIs []string `json:"redirectURIs,omitempty"`
-	TrustedPeers []string `json:"trustedPeers,omitempty"`
+	Secret              string   `json:"secret,omitempty"`
+	RedirectURIs        []string `json:"redirectURIs,omitempty"`
+	TrustedPeers        []string `json:"trustedPeers,omitempty"`
+	RedirectURIPatterns []string `json:"redirectURIPatterns,omitempty"`
+	AllowedOrigins      []string `json:"allowedOrigins,omitempty"`
 
 	Public bool `json:"public"`
 
+	ForwardClaimSourceTokens bool `json:"forwardClaimSourceTokens,omitempty"`
+
 	Name    string `json:"name,omitempty"`
 	LogoURL string `json:"logoURL,omitempty"`
+
+	PolicyURL         string `json:"policyURL,omitempty"`
+	TermsOfServiceURL string `json:"termsOfServiceURL,omitempty"`
+
+	RefreshTokenPolicy *storage.RefreshTokenPolicy `json:"refreshTokenPolicy,omitempty"`
+	TokenExpiry        *storage.TokenExpiry        `json:"tokenExpiry,omitempty"`
+	GroupsFilter       *storage.GroupsFilter       `json:"groupsFilter,omitempty"`
+	ClaimsTransforms   []storage.ClaimsTransform   `json:"claimsTransforms,omitempty"`
+
+	PreviousSecret *storage.PreviousSecret `json:"previousSecret,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	LastUsed  time.Time `json:"lastUsed"`
 }
 
 // ClientList is a list of Clients.
@@ -277,6 +311,22 @@ func (cli *client) fromStorageClient(c storage.Client) Client {
 		Public:       c.Public,
 		Name:         c.Name,
 		LogoURL:      c.LogoURL,
+
+		PolicyURL:         c.PolicyURL,
+		TermsOfServiceURL: c.TermsOfServiceURL,
+
+		RefreshTokenPolicy:  c.RefreshTokenPolicy,
+		TokenExpiry:         c.TokenExpiry,
+		GroupsFilter:        c.GroupsFilter,
+		ClaimsTransforms:    c.ClaimsTransforms,
+		RedirectURIPatterns: c.RedirectURIPatterns,
+		AllowedOrigins:      c.AllowedOrigins,
+
+		ForwardClaimSourceTokens: c.ForwardClaimSourceTokens,
+
+		PreviousSecret: c.PreviousSecret,
+		CreatedAt:      c.CreatedAt,
+		LastUsed:       c.LastUsed,
 	}
 }
 
@@ -289,17 +339,35 @@ func toStorageClient(c Client) storage.Client {
 		Public:       c.Public,
 		Name:         c.Name,
 		LogoURL:      c.LogoURL,
+
+		PolicyURL:         c.PolicyURL,
+		TermsOfServiceURL: c.TermsOfServiceURL,
+
+		RefreshTokenPolicy:  c.RefreshTokenPolicy,
+		TokenExpiry:         c.TokenExpiry,
+		GroupsFilter:        c.GroupsFilter,
+		ClaimsTransforms:    c.ClaimsTransforms,
+		RedirectURIPatterns: c.RedirectURIPatterns,
+		AllowedOrigins:      c.AllowedOrigins,
+
+		ForwardClaimSourceTokens: c.ForwardClaimSourceTokens,
+
+		PreviousSecret: c.PreviousSecret,
+		CreatedAt:      c.CreatedAt,
+		LastUsed:       c.LastUsed,
 	}
 }
 
 // Claims is a mirrored struct from storage with JSON struct tags.
 type Claims struct {
-	UserID            string   `json:"userID"`
-	Username          string   `json:"username"`
-	PreferredUsername string   `json:"preferredUsername"`
-	Email             string   `json:"email"`
-	EmailVerified     bool     `json:"emailVerified"`
-	Groups            []string `json:"groups,omitempty"`
+	UserID            string                         `json:"userID"`
+	Username          string                         `json:"username"`
+	PreferredUsername string                         `json:"preferredUsername"`
+	Email             string                         `json:"email"`
+	EmailVerified     bool                           `json:"emailVerified"`
+	Groups            []string                       `json:"groups,omitempty"`
+	CustomClaims      map[string]interface{}         `json:"customClaims,omitempty"`
+	ClaimSources      map[string]storage.ClaimSource `json:"claimSources,omitempty"`
 }
 
 func fromStorageClaims(i storage.Claims) Claims {
@@ -310,6 +378,8 @@ func fromStorageClaims(i storage.Claims) Claims {
 		Email:             i.Email,
 		EmailVerified:     i.EmailVerified,
 		Groups:            i.Groups,
+		CustomClaims:      i.CustomClaims,
+		ClaimSources:      i.ClaimSources,
 	}
 }
 
@@ -321,6 +391,8 @@ func toStorageClaims(i Claims) storage.Claims {
 		Email:             i.Email,
 		EmailVerified:     i.EmailVerified,
 		Groups:            i.Groups,
+		CustomClaims:      i.CustomClaims,
+		ClaimSources:      i.ClaimSources,
 	}
 }
 
@@ -338,6 +410,8 @@ type AuthRequest struct {
 	Nonce string `json:"nonce,omitempty"`
 	State string `json:"state,omitempty"`
 
+	ClaimsRequest []byte `json:"claimsRequest,omitempty"`
+
 	// The client has indicated that the end user must be shown an approval prompt
 	// on all requests. The server cannot cache their initial action for subsequent
 	// attempts.
@@ -373,6 +447,7 @@ func toStorageAuthRequest(req AuthRequest) storage.AuthRequest {
 		ClientID:            req.ClientID,
 		ResponseTypes:       req.ResponseTypes,
 		Scopes:              req.Scopes,
+		ClaimsRequest:       req.ClaimsRequest,
 		RedirectURI:         req.RedirectURI,
 		Nonce:               req.Nonce,
 		State:               req.State,
@@ -404,6 +479,7 @@ func (cli *client) fromStorageAuthRequest(a storage.AuthRequest) AuthRequest {
 		ClientID:            a.ClientID,
 		ResponseTypes:       a.ResponseTypes,
 		Scopes:              a.Scopes,
+		ClaimsRequest:       a.ClaimsRequest,
 		RedirectURI:         a.RedirectURI,
 		Nonce:               a.Nonce,
 		State:               a.State,
@@ -434,6 +510,13 @@ type Password struct {
 	Hash     []byte `json:"hash,omitempty"`
 	Username string `json:"username,omitempty"`
 	UserID   string `json:"userID,omitempty"`
+
+	// Unset for passwords created before e-mail addresses were verified, which
+	// count as verified.
+	EmailVerified *bool `json:"emailVerified,omitempty"`
+
+	FailedAttempts int       `json:"failedAttempts,omitempty"`
+	LockedUntil    time.Time `json:"lockedUntil"`
 }
 
 // PasswordList is a list of Passwords.
@@ -454,19 +537,25 @@ func (cli *client) fromStoragePassword(p storage.Password) Password {
 			Name:      cli.idToName(email),
 			Namespace: cli.namespace,
 		},
-		Email:    email,
-		Hash:     p.Hash,
-		Username: p.Username,
-		UserID:   p.UserID,
+		Email:          email,
+		Hash:           p.Hash,
+		Username:       p.Username,
+		UserID:         p.UserID,
+		EmailVerified:  &p.EmailVerified,
+		FailedAttempts: p.FailedAttempts,
+		LockedUntil:    p.LockedUntil,
 	}
 }
 
 func toStoragePassword(p Password) storage.Password {
 	return storage.Password{
-		Email:    p.Email,
-		Hash:     p.Hash,
-		Username: p.Username,
-		UserID:   p.UserID,
+		Email:          p.Email,
+		Hash:           p.Hash,
+		Username:       p.Username,
+		UserID:         p.UserID,
+		EmailVerified:  p.EmailVerified == nil || *p.EmailVerified,
+		FailedAttempts: p.FailedAttempts,
+		LockedUntil:    p.LockedUntil,
 	}
 }
 
@@ -483,6 +572,8 @@ type AuthCode struct {
 	Nonce string `json:"nonce,omitempty"`
 	State string `json:"state,omitempty"`
 
+	ClaimsRequest []byte `json:"claimsRequest,omitempty"`
+
 	Claims Claims `json:"claims,omitempty"`
 
 	ConnectorID   string `json:"connectorID,omitempty"`
@@ -492,6 +583,8 @@ type AuthCode struct {
 
 	CodeChallenge       string `json:"code_challenge,omitempty"`
 	CodeChallengeMethod string `json:"code_challenge_method,omitempty"`
+
+	Issuer string `json:"issuer,omitempty"`
 }
 
 // AuthCodeList is a list of AuthCodes.
@@ -517,10 +610,12 @@ func (cli *client) fromStorageAuthCode(a storage.AuthCode) AuthCode {
 		ConnectorData:       a.ConnectorData,
 		Nonce:               a.Nonce,
 		Scopes:              a.Scopes,
+		ClaimsRequest:       a.ClaimsRequest,
 		Claims:              fromStorageClaims(a.Claims),
 		Expiry:              a.Expiry,
 		CodeChallenge:       a.PKCE.CodeChallenge,
 		CodeChallengeMethod: a.PKCE.CodeChallengeMethod,
+		Issuer:              a.Issuer,
 	}
 }
 
@@ -533,12 +628,14 @@ func toStorageAuthCode(a AuthCode) storage.AuthCode {
 		ConnectorData: a.ConnectorData,
 		Nonce:         a.Nonce,
 		Scopes:        a.Scopes,
+		ClaimsRequest: a.ClaimsRequest,
 		Claims:        toStorageClaims(a.Claims),
 		Expiry:        a.Expiry,
 		PKCE: storage.PKCE{
 			CodeChallenge:       a.CodeChallenge,
 			CodeChallengeMethod: a.CodeChallengeMethod,
 		},
+		Issuer: a.Issuer,
 	}
 }
 
@@ -554,6 +651,8 @@ type RefreshToken struct {
 	ClientID string   `json:"clientID"`
 	Scopes   []string `json:"scopes,omitempty"`
 
+	ClaimsRequest []byte `json:"claimsRequest,omitempty"`
+
 	Token         string `json:"token,omitempty"`
 	ObsoleteToken string `json:"obsoleteToken,omitempty"`
 
@@ -562,6 +661,8 @@ type RefreshToken struct {
 	Claims        Claims `json:"claims,omitempty"`
 	ConnectorID   string `json:"connectorID,omitempty"`
 	ConnectorData []byte `json:"connectorData,omitempty"`
+
+	Issuer string `json:"issuer,omitempty"`
 }
 
 // RefreshList is a list of refresh tokens.
@@ -582,8 +683,10 @@ func toStorageRefreshToken(r RefreshToken) storage.RefreshToken {
 		ConnectorID:   r.ConnectorID,
 		ConnectorData: r.ConnectorData,
 		Scopes:        r.Scopes,
+		ClaimsRequest: r.ClaimsRequest,
 		Nonce:         r.Nonce,
 		Claims:        toStorageClaims(r.Claims),
+		Issuer:        r.Issuer,
 	}
 }
 
@@ -605,8 +708,10 @@ func (cli *client) fromStorageRefreshToken(r storage.RefreshToken) RefreshToken
 		ConnectorID:   r.ConnectorID,
 		ConnectorData: r.ConnectorData,
 		Scopes:        r.Scopes,
+		ClaimsRequest: r.ClaimsRequest,
 		Nonce:         r.Nonce,
 		Claims:        fromStorageClaims(r.Claims),
+		Issuer:        r.Issuer,
 	}
 }
 
@@ -627,6 +732,9 @@ type Keys struct {
 	//
 	// For caching purposes, implementations MUST NOT update keys before this time.
 	NextRotation time.Time `json:"nextRotation"`
+
+	// Keys of additional issuers, indexed by issuer URL.
+	IssuerKeys map[string]storage.Keys `json:"issuerKeys,omitempty"`
 }
 
 func (cli *client) fromStorageKeys(keys storage.Keys) Keys {
@@ -643,6 +751,7 @@ func (cli *client) fromStorageKeys(keys storage.Keys) Keys {
 		SigningKeyPub:    keys.SigningKeyPub,
 		VerificationKeys: keys.VerificationKeys,
 		NextRotation:     keys.NextRotation,
+		IssuerKeys:       keys.IssuerKeys,
 	}
 }
 
@@ -652,6 +761,7 @@ func toStorageKeys(keys Keys) storage.Keys {
 		SigningKeyPub:    keys.SigningKeyPub,
 		VerificationKeys: keys.VerificationKeys,
 		NextRotation:     keys.NextRotation,
+		IssuerKeys:       keys.IssuerKeys,
 	}
 }
 
@@ -708,7 +818,8 @@ type Connector struct {
 	Type string `json:"type,omitempty"`
 	Name string `json:"name,omitempty"`
 	// Config holds connector specific configuration information
-	Config []byte `json:"config,omitempty"`
+	Config  []byte                    `json:"config,omitempty"`
+	Display *storage.ConnectorDisplay `json:"display,omitempty"`
 }
 
 func (cli *client) fromStorageConnector(c storage.Connector) Connector {
@@ -721,10 +832,11 @@ func (cli *client) fromStorageConnector(c storage.Connector) Connector {
 			Name:      c.ID,
 			Namespace: cli.namespace,
 		},
-		ID:     c.ID,
-		Type:   c.Type,
-		Name:   c.Name,
-		Config: c.Config,
+		ID:      c.ID,
+		Type:    c.Type,
+		Name:    c.Name,
+		Config:  c.Config,
+		Display: c.Display,
 	}
 }
 
@@ -735,6 +847,7 @@ func toStorageConnector(c Connector) storage.Connector {
 		Name:            c.Name,
 		ResourceVersion: c.ObjectMeta.ResourceVersion,
 		Config:          c.Config,
+		Display:         c.Display,
 	}
 }
 
@@ -745,6 +858,57 @@ type ConnectorList struct {
 	Connectors      []Connector `json:"items"`
 }
 
+// Webhook is a mirrored struct from storage with JSON struct tags and Kubernetes
+// type metadata.
+type Webhook struct {
+	k8sapi.TypeMeta   `json:",inline"`
+	k8sapi.ObjectMeta `json:"metadata,omitempty"`
+
+	ID          string                      `json:"id,omitempty"`
+	URL         string                      `json:"url,omitempty"`
+	Secret      string                      `json:"secret,omitempty"`
+	Events      []string                    `json:"events,omitempty"`
+	RetryPolicy *storage.WebhookRetryPolicy `json:"retryPolicy,omitempty"`
+	CreatedAt   time.Time                   `json:"createdAt"`
+}
+
+func (cli *client) fromStorageWebhook(w storage.Webhook) Webhook {
+	return Webhook{
+		TypeMeta: k8sapi.TypeMeta{
+			Kind:       kindWebhook,
+			APIVersion: cli.apiVersion,
+		},
+		ObjectMeta: k8sapi.ObjectMeta{
+			Name:      w.ID,
+			Namespace: cli.namespace,
+		},
+		ID:          w.ID,
+		URL:         w.URL,
+		Secret:      w.Secret,
+		Events:      w.Events,
+		RetryPolicy: w.RetryPolicy,
+		CreatedAt:   w.CreatedAt,
+	}
+}
+
+func toStorageWebhook(w Webhook) storage.Webhook {
+	return storage.Webhook{
+		ID:          w.ID,
+		URL:         w.URL,
+		Secret:      w.Secret,
+		Events:      w.Events,
+		RetryPolicy: w.RetryPolicy,
+		CreatedAt:   w.CreatedAt,
+	}
+}
+
+// WebhookList is a list of Webhooks.
+type WebhookList struct {
+	k8sapi.TypeMeta `json:",inline"`
+	k8sapi.ListMeta `json:"metadata,omitempty"`
+	Webhooks        []Webhook `json:"items"`
+}
+
 // DeviceRequest is a mirrored struct from storage with JSON struct tags and
 // Kubernetes type metadata.
 type DeviceRequest struct {
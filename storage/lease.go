@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Tests for this code are in the storages electing a leader with it, since
+// this package doesn't define a concrete storage implementation.
+
+const (
+	// DefaultLeaseName is the name of the lease if none is configured.
+	DefaultLeaseName = "dex"
+	// LeaseDuration is how long a leader holds the lease without renewing it.
+	LeaseDuration = 15 * time.Second
+	// LeaseRetryPeriod is the time between attempts to acquire or renew the lease.
+	LeaseRetryPeriod = 5 * time.Second
+)
+
+// NewLeaseIdentity returns the identity this instance holds leases as. The
+// hostname, the pod name on Kubernetes, tells instances apart in logs and a
+// random suffix keeps the identity unique.
+func NewLeaseIdentity() string {
+	hostname, _ := os.Hostname()
+	return hostname + "_" + NewID()
+}
+
+// LeaseElection elects the instance holding a lease as the leader among the
+// instances sharing a storage. Storages acquire, renew and release the lease,
+// and judge when a lease held by another instance expired.
+type LeaseElection struct {
+	name           string
+	identity       string
+	logger         *slog.Logger
+	acquireOrRenew func(now time.Time) (bool, error)
+	release        func() error
+
+	// Now returns the current time, and may be replaced by tests.
+	Now func() time.Time
+
+	mu sync.Mutex
+	// renewed is the last time this instance acquired or renewed the lease.
+	renewed time.Time
+}
+
+// NewLeaseElection returns an election for the named lease, held by this
+// instance as identity. acquireOrRenew updates the lease to be held by this
+// instance if it already holds it or the lease expired, reporting whether it
+// holds the lease. release gives up the lease if this instance holds it.
+func NewLeaseElection(logger *slog.Logger, name, identity string, acquireOrRenew func(now time.Time) (bool, error), release func() error) *LeaseElection {
+	return &LeaseElection{
+		name:           name,
+		identity:       identity,
+		logger:         logger,
+		acquireOrRenew: acquireOrRenew,
+		release:        release,
+		Now:            time.Now,
+	}
+}
+
+// IsLeader reports whether this instance holds an unexpired lease.
+func (e *LeaseElection) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return !e.renewed.IsZero() && e.Now().Before(e.renewed.Add(LeaseDuration))
+}
+
+// Run tries to acquire or renew the lease until the context is canceled,
+// then releases it so another instance can take over right away.
+func (e *LeaseElection) Run(ctx context.Context) {
+	for {
+		e.TryAcquireOrRenew()
+		select {
+		case <-ctx.Done():
+			e.Release()
+			return
+		case <-time.After(LeaseRetryPeriod):
+		}
+	}
+}
+
+// TryAcquireOrRenew makes a single attempt to acquire or renew the lease.
+func (e *LeaseElection) TryAcquireOrRenew() {
+	wasLeader := e.IsLeader()
+	now := e.Now()
+	acquired, err := e.acquireOrRenew(now)
+	if err != nil {
+		e.logger.Error("failed to acquire or renew lease", "lease", e.name, "err", err)
+	}
+	if acquired {
+		e.mu.Lock()
+		e.renewed = now
+		e.mu.Unlock()
+	}
+
+	switch isLeader := e.IsLeader(); {
+	case isLeader && !wasLeader:
+		e.logger.Info("became leader", "lease", e.name, "identity", e.identity)
+	case !isLeader && wasLeader:
+		e.logger.Info("lost leadership", "lease", e.name, "identity", e.identity)
+	}
+}
+
+// Release gives up the lease if this instance holds it.
+func (e *LeaseElection) Release() {
+	if !e.IsLeader() {
+		return
+	}
+	e.mu.Lock()
+	e.renewed = time.Time{}
+	e.mu.Unlock()
+
+	if err := e.release(); err != nil {
+		e.logger.Error("failed to release lease", "lease", e.name, "err", err)
+	}
+}
@@ -0,0 +1,221 @@
+package memory
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/dexidp/dex/storage"
+	"github.com/dexidp/dex/storage/conformance"
+)
+
+// countingStorage counts the reads of the objects cached by storage.WithCache.
+type countingStorage struct {
+	storage.Storage
+
+	getKeys, getClient, getConnector, listConnectors int
+}
+
+func (s *countingStorage) GetKeys() (storage.Keys, error) {
+	s.getKeys++
+	return s.Storage.GetKeys()
+}
+
+func (s *countingStorage) GetClient(id string) (storage.Client, error) {
+	s.getClient++
+	return s.Storage.GetClient(id)
+}
+
+func (s *countingStorage) GetConnector(id string) (storage.Connector, error) {
+	s.getConnector++
+	return s.Storage.GetConnector(id)
+}
+
+func (s *countingStorage) ListConnectors() ([]storage.Connector, error) {
+	s.listConnectors++
+	return s.Storage.ListConnectors()
+}
+
+func TestCachedStorage(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	newStorage := func() storage.Storage {
+		return storage.WithCache(New(logger), time.Minute, nil)
+	}
+	conformance.RunTests(t, newStorage)
+}
+
+func TestCachedStorageReads(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	backing := &countingStorage{Storage: New(logger)}
+	s := storage.WithCache(backing, time.Minute, nil)
+
+	if err := s.CreateClient(ctx, storage.Client{ID: "foo", Secret: "foo_secret"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CreateConnector(ctx, storage.Connector{ID: "bar", Type: "mockCallback", Name: "Bar"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := s.GetKeys(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.GetClient("foo"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.GetConnector("bar"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.ListConnectors(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if backing.getKeys != 1 || backing.getClient != 1 || backing.getConnector != 1 || backing.listConnectors != 1 {
+		t.Errorf("expected one read of each object from the backing storage, got %+v", *backing)
+	}
+
+	err := s.UpdateClient("foo", func(c storage.Client) (storage.Client, error) {
+		c.Secret = "new_secret"
+		return c, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := s.GetClient("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.Secret != "new_secret" {
+		t.Errorf("expected updated client secret, got %q", client.Secret)
+	}
+
+	if err := s.DeleteConnector("bar"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetConnector("bar"); err != storage.ErrNotFound {
+		t.Errorf("expected deleted connector to be not found, got %v", err)
+	}
+	connectors, err := s.ListConnectors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(connectors) != 0 {
+		t.Errorf("expected no connectors after delete, got %d", len(connectors))
+	}
+}
+
+func TestCachedStorageExpiry(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	backing := New(logger)
+	s := storage.WithCache(backing, 50*time.Millisecond, nil)
+
+	if err := backing.CreateClient(ctx, storage.Client{ID: "foo", Secret: "old_secret"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetClient("foo"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Writes bypassing the cache, e.g. by another dex instance, are only seen
+	// once the cached client expires.
+	err := backing.UpdateClient("foo", func(c storage.Client) (storage.Client, error) {
+		c.Secret = "new_secret"
+		return c, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := s.GetClient("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.Secret != "old_secret" {
+		t.Errorf("expected cached client secret, got %q", client.Secret)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	client, err = s.GetClient("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.Secret != "new_secret" {
+		t.Errorf("expected client secret to be reloaded after expiry, got %q", client.Secret)
+	}
+}
+
+// fakeNotifier notifies changes when told to, like a storage notifying
+// changes by other dex instances.
+type fakeNotifier struct {
+	subscribers []func(storage.Change)
+}
+
+func (n *fakeNotifier) OnChange(fn func(storage.Change)) {
+	n.subscribers = append(n.subscribers, fn)
+}
+
+func (n *fakeNotifier) notify(change storage.Change) {
+	for _, fn := range n.subscribers {
+		fn(change)
+	}
+}
+
+func TestCachedStorageChanges(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	backing := New(logger)
+	notifier := &fakeNotifier{}
+	s := storage.WithCache(backing, time.Minute, notifier)
+
+	if err := backing.CreateClient(ctx, storage.Client{ID: "foo", Secret: "old_secret"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetClient("foo"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ListConnectors(); err != nil {
+		t.Fatal(err)
+	}
+
+	err := backing.UpdateClient("foo", func(c storage.Client) (storage.Client, error) {
+		c.Secret = "new_secret"
+		return c, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := backing.CreateConnector(ctx, storage.Connector{ID: "bar", Type: "mockCallback", Name: "Bar"}); err != nil {
+		t.Fatal(err)
+	}
+
+	notifier.notify(storage.Change{Kind: storage.ChangeClient, ID: "foo"})
+	client, err := s.GetClient("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.Secret != "new_secret" {
+		t.Errorf("expected client secret updated by another instance, got %q", client.Secret)
+	}
+
+	notifier.notify(storage.Change{Kind: storage.ChangeConnector, ID: "bar"})
+	connectors, err := s.ListConnectors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(connectors) != 1 {
+		t.Errorf("expected connector created by another instance, got %d connectors", len(connectors))
+	}
+
+	if err := backing.DeleteClient("foo"); err != nil {
+		t.Fatal(err)
+	}
+	// Changes of unknown objects drop everything.
+	notifier.notify(storage.Change{})
+	if _, err := s.GetClient("foo"); err != storage.ErrNotFound {
+		t.Errorf("expected client deleted by another instance to be not found, got %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+package memory
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+
+	"github.com/dexidp/dex/storage"
+)
+
+func TestIssuerStorage(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	backing := New(logger)
+
+	for _, c := range []storage.Client{{ID: "foo"}, {ID: "bar"}} {
+		if err := backing.CreateClient(ctx, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, c := range []storage.Connector{{ID: "github", Type: "github"}, {ID: "ldap", Type: "ldap"}} {
+		if err := backing.CreateConnector(ctx, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := storage.WithIssuer(backing, "https://tenant.example.com", []string{"foo"}, nil)
+
+	if _, err := s.GetClient("bar"); err != storage.ErrNotFound {
+		t.Errorf("expected client bar to be hidden, got err %v", err)
+	}
+	if _, err := s.GetClient("foo"); err != nil {
+		t.Errorf("get client foo: %v", err)
+	}
+	if clients, err := s.ListClients(); err != nil {
+		t.Errorf("list clients: %v", err)
+	} else if len(clients) != 1 || clients[0].ID != "foo" {
+		t.Errorf("expected only client foo, got %v", clients)
+	}
+	if connectors, err := s.ListConnectors(); err != nil {
+		t.Errorf("list connectors: %v", err)
+	} else if len(connectors) != 2 {
+		t.Errorf("expected all connectors, got %v", connectors)
+	}
+
+	setKeys := func(s storage.Storage, keyID string) error {
+		return s.UpdateKeys(func(old storage.Keys) (storage.Keys, error) {
+			old.SigningKey = &jose.JSONWebKey{KeyID: keyID}
+			old.NextRotation = time.Now().Add(time.Hour)
+			return old, nil
+		})
+	}
+
+	if err := setKeys(s, "tenant"); err == nil {
+		t.Errorf("expected updating keys to fail before the default issuer has keys")
+	}
+	if err := setKeys(backing, "default"); err != nil {
+		t.Fatalf("update default keys: %v", err)
+	}
+	if err := setKeys(s, "tenant"); err != nil {
+		t.Fatalf("update issuer keys: %v", err)
+	}
+
+	keys, err := s.GetKeys()
+	if err != nil {
+		t.Fatalf("get issuer keys: %v", err)
+	}
+	if keys.SigningKey == nil || keys.SigningKey.KeyID != "tenant" {
+		t.Errorf("expected issuer signing key, got %v", keys.SigningKey)
+	}
+
+	keys, err = backing.GetKeys()
+	if err != nil {
+		t.Fatalf("get default keys: %v", err)
+	}
+	if keys.SigningKey.KeyID != "default" {
+		t.Errorf("expected default signing key to be kept, got %q", keys.SigningKey.KeyID)
+	}
+
+	other := storage.WithIssuer(backing, "https://other.example.com", nil, nil)
+	if keys, err := other.GetKeys(); err != nil {
+		t.Errorf("get keys of other issuer: %v", err)
+	} else if keys.SigningKey != nil {
+		t.Errorf("expected other issuer to have no keys, got %v", keys.SigningKey)
+	}
+}
+
+func TestIssuerStorageTokens(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	backing := New(logger)
+
+	defaultIssuer := storage.WithIssuer(backing, "", nil, nil)
+	tenant := storage.WithIssuer(backing, "https://tenant.example.com", nil, nil)
+	expiry := time.Now().Add(time.Hour)
+
+	if err := tenant.CreateAuthCode(ctx, storage.AuthCode{ID: "tenant-code", Expiry: expiry}); err != nil {
+		t.Fatal(err)
+	}
+	if err := defaultIssuer.CreateAuthCode(ctx, storage.AuthCode{ID: "default-code", Expiry: expiry}); err != nil {
+		t.Fatal(err)
+	}
+	if c, err := tenant.GetAuthCode("tenant-code"); err != nil {
+		t.Errorf("get auth code: %v", err)
+	} else if c.Issuer != "https://tenant.example.com" {
+		t.Errorf("expected the issuer to be recorded, got %q", c.Issuer)
+	}
+	if _, err := defaultIssuer.GetAuthCode("tenant-code"); err != storage.ErrNotFound {
+		t.Errorf("expected auth code of another issuer to be hidden, got err %v", err)
+	}
+	if _, err := tenant.GetAuthCode("default-code"); err != storage.ErrNotFound {
+		t.Errorf("expected auth code of the default issuer to be hidden, got err %v", err)
+	}
+
+	for _, r := range []struct {
+		s  storage.Storage
+		id string
+	}{{tenant, "tenant-token"}, {defaultIssuer, "default-token"}} {
+		if err := r.s.CreateRefresh(ctx, storage.RefreshToken{ID: r.id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Tokens stored before issuers were recorded belong to the default issuer.
+	if err := backing.CreateRefresh(ctx, storage.RefreshToken{ID: "old-token"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := defaultIssuer.GetRefresh("old-token"); err != nil {
+		t.Errorf("get refresh token: %v", err)
+	}
+	if _, err := tenant.GetRefresh("old-token"); err != storage.ErrNotFound {
+		t.Errorf("expected refresh token of the default issuer to be hidden, got err %v", err)
+	}
+	if _, err := defaultIssuer.GetRefresh("tenant-token"); err != storage.ErrNotFound {
+		t.Errorf("expected refresh token of another issuer to be hidden, got err %v", err)
+	}
+	err := defaultIssuer.UpdateRefreshToken("tenant-token", func(old storage.RefreshToken) (storage.RefreshToken, error) {
+		old.Issuer = ""
+		return old, nil
+	})
+	if err != storage.ErrNotFound {
+		t.Errorf("expected updating refresh token of another issuer to fail, got err %v", err)
+	}
+	if tokens, err := tenant.ListRefreshTokens(); err != nil {
+		t.Errorf("list refresh tokens: %v", err)
+	} else if len(tokens) != 1 || tokens[0].ID != "tenant-token" {
+		t.Errorf("expected only the refresh token of the issuer, got %v", tokens)
+	}
+}
@@ -25,6 +25,7 @@ func New(logger *slog.Logger) storage.Storage {
 		connectors:      make(map[string]storage.Connector),
 		deviceRequests:  make(map[string]storage.DeviceRequest),
 		deviceTokens:    make(map[string]storage.DeviceToken),
+		webhooks:        make(map[string]storage.Webhook),
 		logger:          logger,
 	}
 }
@@ -52,6 +53,7 @@ type memStorage struct {
 	connectors      map[string]storage.Connector
 	deviceRequests  map[string]storage.DeviceRequest
 	deviceTokens    map[string]storage.DeviceToken
+	webhooks        map[string]storage.Webhook
 
 	keys storage.Keys
 
@@ -71,30 +73,38 @@ func (s *memStorage) tx(f func()) {
 
 func (s *memStorage) Close() error { return nil }
 
-func (s *memStorage) GarbageCollect(now time.Time) (result storage.GCResult, err error) {
+func (s *memStorage) GarbageCollect(now time.Time, opts storage.GCOptions) (result storage.GCResult, err error) {
 	s.tx(func() {
-		for id, a := range s.authCodes {
-			if now.After(a.Expiry) {
-				delete(s.authCodes, id)
-				result.AuthCodes++
+		if opts.Includes(storage.GCAuthCodes) {
+			for id, a := range s.authCodes {
+				if now.After(a.Expiry) {
+					delete(s.authCodes, id)
+					result.AuthCodes++
+				}
 			}
 		}
-		for id, a := range s.authReqs {
-			if now.After(a.Expiry) {
-				delete(s.authReqs, id)
-				result.AuthRequests++
+		if opts.Includes(storage.GCAuthRequests) {
+			for id, a := range s.authReqs {
+				if now.After(a.Expiry) {
+					delete(s.authReqs, id)
+					result.AuthRequests++
+				}
 			}
 		}
-		for id, a := range s.deviceRequests {
-			if now.After(a.Expiry) {
-				delete(s.deviceRequests, id)
-				result.DeviceRequests++
+		if opts.Includes(storage.GCDeviceRequests) {
+			for id, a := range s.deviceRequests {
+				if now.After(a.Expiry) {
+					delete(s.deviceRequests, id)
+					result.DeviceRequests++
+				}
 			}
 		}
-		for id, a := range s.deviceTokens {
-			if now.After(a.Expiry) {
-				delete(s.deviceTokens, id)
-				result.DeviceTokens++
+		if opts.Includes(storage.GCDeviceTokens) {
+			for id, a := range s.deviceTokens {
+				if now.After(a.Expiry) {
+					delete(s.deviceTokens, id)
+					result.DeviceTokens++
+				}
 			}
 		}
 	})
@@ -294,6 +304,15 @@ func (s *memStorage) ListPasswords() (passwords []storage.Password, err error) {
 	return
 }
 
+func (s *memStorage) ListDeviceRequests() (reqs []storage.DeviceRequest, err error) {
+	s.tx(func() {
+		for _, r := range s.deviceRequests {
+			reqs = append(reqs, r)
+		}
+	})
+	return
+}
+
 func (s *memStorage) ListConnectors() (conns []storage.Connector, err error) {
 	s.tx(func() {
 		for _, c := range s.connectors {
@@ -541,3 +560,58 @@ func (s *memStorage) UpdateDeviceToken(deviceCode string, updater func(p storage
 	})
 	return
 }
+
+func (s *memStorage) CreateWebhook(ctx context.Context, w storage.Webhook) (err error) {
+	s.tx(func() {
+		if _, ok := s.webhooks[w.ID]; ok {
+			err = storage.ErrAlreadyExists
+		} else {
+			s.webhooks[w.ID] = w
+		}
+	})
+	return
+}
+
+func (s *memStorage) GetWebhook(id string) (w storage.Webhook, err error) {
+	s.tx(func() {
+		var ok bool
+		if w, ok = s.webhooks[id]; !ok {
+			err = storage.ErrNotFound
+		}
+	})
+	return
+}
+
+func (s *memStorage) ListWebhooks() (webhooks []storage.Webhook, err error) {
+	s.tx(func() {
+		for _, w := range s.webhooks {
+			webhooks = append(webhooks, w)
+		}
+	})
+	return
+}
+
+func (s *memStorage) DeleteWebhook(id string) (err error) {
+	s.tx(func() {
+		if _, ok := s.webhooks[id]; !ok {
+			err = storage.ErrNotFound
+			return
+		}
+		delete(s.webhooks, id)
+	})
+	return
+}
+
+func (s *memStorage) UpdateWebhook(id string, updater func(w storage.Webhook) (storage.Webhook, error)) (err error) {
+	s.tx(func() {
+		w, ok := s.webhooks[id]
+		if !ok {
+			err = storage.ErrNotFound
+			return
+		}
+		if w, err = updater(w); err == nil {
+			s.webhooks[id] = w
+		}
+	})
+	return
+}
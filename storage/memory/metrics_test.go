@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/dexidp/dex/storage"
+	"github.com/dexidp/dex/storage/conformance"
+)
+
+func TestInstrumentedStorage(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	newStorage := func() storage.Storage {
+		s, err := storage.WithMetrics(New(logger), "memory", prometheus.NewRegistry(), storage.SlowOperations{}, logger)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	conformance.RunTests(t, newStorage)
+}
+
+func TestInstrumentedStorageMetrics(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{}))
+	registry := prometheus.NewRegistry()
+
+	slow := storage.SlowOperations{
+		Operations: map[string]time.Duration{"GetClient": time.Nanosecond},
+	}
+	s, err := storage.WithMetrics(New(logger), "memory", registry, slow, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.GetClient("foo"); err != storage.ErrNotFound {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+	if _, err := s.ListClients(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := testutil.CollectAndCount(registry, "dex_storage_operation_duration_seconds"); n != 2 {
+		t.Errorf("expected 2 series, got %d", n)
+	}
+
+	for _, labels := range []prometheus.Labels{
+		{"operation": "GetClient", "backend": "memory", "outcome": "not_found"},
+		{"operation": "ListClients", "backend": "memory", "outcome": "success"},
+	} {
+		if !hasSeries(t, registry, labels) {
+			t.Errorf("no series with labels %v", labels)
+		}
+	}
+
+	// Only GetClient has a threshold.
+	out := logs.String()
+	if !strings.Contains(out, "operation=GetClient") {
+		t.Errorf("expected slow GetClient to be logged, got %q", out)
+	}
+	if strings.Contains(out, "operation=ListClients") {
+		t.Errorf("expected ListClients not to be logged, got %q", out)
+	}
+}
+
+func hasSeries(t *testing.T, registry *prometheus.Registry, labels prometheus.Labels) bool {
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			matched := 0
+			for _, pair := range metric.GetLabel() {
+				if labels[pair.GetName()] == pair.GetValue() {
+					matched++
+				}
+			}
+			if matched == len(labels) && metric.GetHistogram().GetSampleCount() == 1 {
+				return true
+			}
+		}
+	}
+	return false
+}
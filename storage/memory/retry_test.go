@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dexidp/dex/storage"
+)
+
+func TestRetryOnConflict(t *testing.T) {
+	ctx := context.Background()
+
+	var calls int
+	err := storage.RetryOnConflict(ctx, func() error {
+		calls++
+		if calls < 3 {
+			return fmt.Errorf("update: %w", storage.ErrConflict)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, calls, "update wasn't retried after conflicts")
+
+	calls = 0
+	errOther := errors.New("other error")
+	err = storage.RetryOnConflict(ctx, func() error {
+		calls++
+		return errOther
+	})
+	require.ErrorIs(t, err, errOther)
+	require.Equal(t, 1, calls, "update was retried after an error other than a conflict")
+
+	calls = 0
+	err = storage.RetryOnConflict(ctx, func() error {
+		calls++
+		return storage.ErrConflict
+	})
+	require.ErrorIs(t, err, storage.ErrConflict)
+	require.Greater(t, calls, 1)
+	require.Less(t, calls, 10, "update was retried indefinitely")
+}
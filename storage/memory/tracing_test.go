@@ -0,0 +1,49 @@
+package memory
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/dexidp/dex/storage"
+)
+
+func TestTracedStorage(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	s := storage.WithTracing(New(logger), tp)
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "request")
+	if err := s.CreateClient(ctx, storage.Client{ID: "foo"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CreateClient(ctx, storage.Client{ID: "foo"}); err != storage.ErrAlreadyExists {
+		t.Fatalf("expected already exists error, got %v", err)
+	}
+	// Operations without a context aren't traced.
+	if _, err := s.GetClient("foo"); err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(spans))
+	}
+	for _, span := range spans[:2] {
+		if span.Name != "storage.CreateClient" {
+			t.Errorf("expected span storage.CreateClient, got %s", span.Name)
+		}
+		if span.Parent.SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("span %s isn't a child of the request", span.Name)
+		}
+	}
+	if got := spans[1].Status.Description; got != "already_exists" {
+		t.Errorf("expected status already_exists, got %q", got)
+	}
+}
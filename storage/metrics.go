@@ -0,0 +1,316 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Tests for this code are in the "memory" package, since this package doesn't
+// define a concrete storage implementation.
+
+// SlowOperations configures the logging of storage operations that take
+// longer than expected.
+type SlowOperations struct {
+	// Threshold applies to operations without a threshold of their own.
+	// Zero disables logging for them.
+	Threshold time.Duration
+	// Operations holds thresholds by method name, e.g. "GarbageCollect".
+	Operations map[string]time.Duration
+}
+
+func (s SlowOperations) threshold(operation string) time.Duration {
+	if t, ok := s.Operations[operation]; ok {
+		return t
+	}
+	return s.Threshold
+}
+
+// instrumentedStorage records the duration of every operation of the
+// underlying storage and logs the slow ones.
+type instrumentedStorage struct {
+	Storage
+
+	backend  string
+	duration *prometheus.HistogramVec
+	slow     SlowOperations
+	logger   *slog.Logger
+}
+
+// WithMetrics records the duration of the operations of the underlying
+// storage in a histogram labeled by operation, backend and outcome, and logs
+// operations slower than their threshold.
+func WithMetrics(s Storage, backend string, registerer prometheus.Registerer, slow SlowOperations, logger *slog.Logger) (Storage, error) {
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "dex_storage_operation_duration_seconds",
+		Help:    "A histogram of latencies for storage operations.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "backend", "outcome"})
+	if err := registerer.Register(duration); err != nil {
+		return nil, err
+	}
+	return &instrumentedStorage{
+		Storage:  s,
+		backend:  backend,
+		duration: duration,
+		slow:     slow,
+		logger:   logger,
+	}, nil
+}
+
+// outcome returns the label of the result of an operation. Not found and
+// already exists are expected results of some operations, so they're told
+// apart from failures.
+func outcome(err error) string {
+	switch {
+	case err == nil:
+		return "success"
+	case errors.Is(err, ErrNotFound):
+		return "not_found"
+	case errors.Is(err, ErrAlreadyExists):
+		return "already_exists"
+	default:
+		return "error"
+	}
+}
+
+// observe records the duration of an operation. Slow operations are logged
+// with the context of the request, if the operation takes one.
+func (s *instrumentedStorage) observe(ctx context.Context, operation string, start time.Time, err *error) {
+	elapsed := time.Since(start)
+	result := outcome(*err)
+	s.duration.WithLabelValues(operation, s.backend, result).Observe(elapsed.Seconds())
+
+	if threshold := s.slow.threshold(operation); threshold > 0 && elapsed >= threshold {
+		s.logger.WarnContext(ctx, "slow storage operation",
+			"operation", operation, "backend", s.backend, "outcome", result, "duration", elapsed, "threshold", threshold)
+	}
+}
+
+func (s *instrumentedStorage) CreateAuthRequest(ctx context.Context, a AuthRequest) (err error) {
+	defer s.observe(ctx, "CreateAuthRequest", time.Now(), &err)
+	return s.Storage.CreateAuthRequest(ctx, a)
+}
+
+func (s *instrumentedStorage) CreateClient(ctx context.Context, c Client) (err error) {
+	defer s.observe(ctx, "CreateClient", time.Now(), &err)
+	return s.Storage.CreateClient(ctx, c)
+}
+
+func (s *instrumentedStorage) CreateAuthCode(ctx context.Context, c AuthCode) (err error) {
+	defer s.observe(ctx, "CreateAuthCode", time.Now(), &err)
+	return s.Storage.CreateAuthCode(ctx, c)
+}
+
+func (s *instrumentedStorage) CreateRefresh(ctx context.Context, r RefreshToken) (err error) {
+	defer s.observe(ctx, "CreateRefresh", time.Now(), &err)
+	return s.Storage.CreateRefresh(ctx, r)
+}
+
+func (s *instrumentedStorage) CreatePassword(ctx context.Context, p Password) (err error) {
+	defer s.observe(ctx, "CreatePassword", time.Now(), &err)
+	return s.Storage.CreatePassword(ctx, p)
+}
+
+func (s *instrumentedStorage) CreateOfflineSessions(ctx context.Context, o OfflineSessions) (err error) {
+	defer s.observe(ctx, "CreateOfflineSessions", time.Now(), &err)
+	return s.Storage.CreateOfflineSessions(ctx, o)
+}
+
+func (s *instrumentedStorage) CreateConnector(ctx context.Context, c Connector) (err error) {
+	defer s.observe(ctx, "CreateConnector", time.Now(), &err)
+	return s.Storage.CreateConnector(ctx, c)
+}
+
+func (s *instrumentedStorage) CreateDeviceRequest(ctx context.Context, d DeviceRequest) (err error) {
+	defer s.observe(ctx, "CreateDeviceRequest", time.Now(), &err)
+	return s.Storage.CreateDeviceRequest(ctx, d)
+}
+
+func (s *instrumentedStorage) CreateDeviceToken(ctx context.Context, d DeviceToken) (err error) {
+	defer s.observe(ctx, "CreateDeviceToken", time.Now(), &err)
+	return s.Storage.CreateDeviceToken(ctx, d)
+}
+
+func (s *instrumentedStorage) CreateWebhook(ctx context.Context, w Webhook) (err error) {
+	defer s.observe(ctx, "CreateWebhook", time.Now(), &err)
+	return s.Storage.CreateWebhook(ctx, w)
+}
+
+func (s *instrumentedStorage) GetAuthRequest(id string) (_ AuthRequest, err error) {
+	defer s.observe(context.Background(), "GetAuthRequest", time.Now(), &err)
+	return s.Storage.GetAuthRequest(id)
+}
+
+func (s *instrumentedStorage) GetAuthCode(id string) (_ AuthCode, err error) {
+	defer s.observe(context.Background(), "GetAuthCode", time.Now(), &err)
+	return s.Storage.GetAuthCode(id)
+}
+
+func (s *instrumentedStorage) GetClient(id string) (_ Client, err error) {
+	defer s.observe(context.Background(), "GetClient", time.Now(), &err)
+	return s.Storage.GetClient(id)
+}
+
+func (s *instrumentedStorage) GetKeys() (_ Keys, err error) {
+	defer s.observe(context.Background(), "GetKeys", time.Now(), &err)
+	return s.Storage.GetKeys()
+}
+
+func (s *instrumentedStorage) GetRefresh(id string) (_ RefreshToken, err error) {
+	defer s.observe(context.Background(), "GetRefresh", time.Now(), &err)
+	return s.Storage.GetRefresh(id)
+}
+
+func (s *instrumentedStorage) GetPassword(email string) (_ Password, err error) {
+	defer s.observe(context.Background(), "GetPassword", time.Now(), &err)
+	return s.Storage.GetPassword(email)
+}
+
+func (s *instrumentedStorage) GetOfflineSessions(userID string, connID string) (_ OfflineSessions, err error) {
+	defer s.observe(context.Background(), "GetOfflineSessions", time.Now(), &err)
+	return s.Storage.GetOfflineSessions(userID, connID)
+}
+
+func (s *instrumentedStorage) GetConnector(id string) (_ Connector, err error) {
+	defer s.observe(context.Background(), "GetConnector", time.Now(), &err)
+	return s.Storage.GetConnector(id)
+}
+
+func (s *instrumentedStorage) GetDeviceRequest(userCode string) (_ DeviceRequest, err error) {
+	defer s.observe(context.Background(), "GetDeviceRequest", time.Now(), &err)
+	return s.Storage.GetDeviceRequest(userCode)
+}
+
+func (s *instrumentedStorage) GetDeviceToken(deviceCode string) (_ DeviceToken, err error) {
+	defer s.observe(context.Background(), "GetDeviceToken", time.Now(), &err)
+	return s.Storage.GetDeviceToken(deviceCode)
+}
+
+func (s *instrumentedStorage) GetWebhook(id string) (_ Webhook, err error) {
+	defer s.observe(context.Background(), "GetWebhook", time.Now(), &err)
+	return s.Storage.GetWebhook(id)
+}
+
+func (s *instrumentedStorage) ListClients() (_ []Client, err error) {
+	defer s.observe(context.Background(), "ListClients", time.Now(), &err)
+	return s.Storage.ListClients()
+}
+
+func (s *instrumentedStorage) ListRefreshTokens() (_ []RefreshToken, err error) {
+	defer s.observe(context.Background(), "ListRefreshTokens", time.Now(), &err)
+	return s.Storage.ListRefreshTokens()
+}
+
+func (s *instrumentedStorage) ListPasswords() (_ []Password, err error) {
+	defer s.observe(context.Background(), "ListPasswords", time.Now(), &err)
+	return s.Storage.ListPasswords()
+}
+
+func (s *instrumentedStorage) ListConnectors() (_ []Connector, err error) {
+	defer s.observe(context.Background(), "ListConnectors", time.Now(), &err)
+	return s.Storage.ListConnectors()
+}
+
+func (s *instrumentedStorage) ListDeviceRequests() (_ []DeviceRequest, err error) {
+	defer s.observe(context.Background(), "ListDeviceRequests", time.Now(), &err)
+	return s.Storage.ListDeviceRequests()
+}
+
+func (s *instrumentedStorage) ListWebhooks() (_ []Webhook, err error) {
+	defer s.observe(context.Background(), "ListWebhooks", time.Now(), &err)
+	return s.Storage.ListWebhooks()
+}
+
+func (s *instrumentedStorage) DeleteAuthRequest(id string) (err error) {
+	defer s.observe(context.Background(), "DeleteAuthRequest", time.Now(), &err)
+	return s.Storage.DeleteAuthRequest(id)
+}
+
+func (s *instrumentedStorage) DeleteAuthCode(code string) (err error) {
+	defer s.observe(context.Background(), "DeleteAuthCode", time.Now(), &err)
+	return s.Storage.DeleteAuthCode(code)
+}
+
+func (s *instrumentedStorage) DeleteClient(id string) (err error) {
+	defer s.observe(context.Background(), "DeleteClient", time.Now(), &err)
+	return s.Storage.DeleteClient(id)
+}
+
+func (s *instrumentedStorage) DeleteRefresh(id string) (err error) {
+	defer s.observe(context.Background(), "DeleteRefresh", time.Now(), &err)
+	return s.Storage.DeleteRefresh(id)
+}
+
+func (s *instrumentedStorage) DeletePassword(email string) (err error) {
+	defer s.observe(context.Background(), "DeletePassword", time.Now(), &err)
+	return s.Storage.DeletePassword(email)
+}
+
+func (s *instrumentedStorage) DeleteOfflineSessions(userID string, connID string) (err error) {
+	defer s.observe(context.Background(), "DeleteOfflineSessions", time.Now(), &err)
+	return s.Storage.DeleteOfflineSessions(userID, connID)
+}
+
+func (s *instrumentedStorage) DeleteConnector(id string) (err error) {
+	defer s.observe(context.Background(), "DeleteConnector", time.Now(), &err)
+	return s.Storage.DeleteConnector(id)
+}
+
+func (s *instrumentedStorage) DeleteWebhook(id string) (err error) {
+	defer s.observe(context.Background(), "DeleteWebhook", time.Now(), &err)
+	return s.Storage.DeleteWebhook(id)
+}
+
+func (s *instrumentedStorage) UpdateClient(id string, updater func(old Client) (Client, error)) (err error) {
+	defer s.observe(context.Background(), "UpdateClient", time.Now(), &err)
+	return s.Storage.UpdateClient(id, updater)
+}
+
+func (s *instrumentedStorage) UpdateKeys(updater func(old Keys) (Keys, error)) (err error) {
+	defer s.observe(context.Background(), "UpdateKeys", time.Now(), &err)
+	return s.Storage.UpdateKeys(updater)
+}
+
+func (s *instrumentedStorage) UpdateAuthRequest(id string, updater func(a AuthRequest) (AuthRequest, error)) (err error) {
+	defer s.observe(context.Background(), "UpdateAuthRequest", time.Now(), &err)
+	return s.Storage.UpdateAuthRequest(id, updater)
+}
+
+func (s *instrumentedStorage) UpdateRefreshToken(id string, updater func(r RefreshToken) (RefreshToken, error)) (err error) {
+	defer s.observe(context.Background(), "UpdateRefreshToken", time.Now(), &err)
+	return s.Storage.UpdateRefreshToken(id, updater)
+}
+
+func (s *instrumentedStorage) UpdatePassword(email string, updater func(p Password) (Password, error)) (err error) {
+	defer s.observe(context.Background(), "UpdatePassword", time.Now(), &err)
+	return s.Storage.UpdatePassword(email, updater)
+}
+
+func (s *instrumentedStorage) UpdateOfflineSessions(userID string, connID string, updater func(s OfflineSessions) (OfflineSessions, error)) (err error) {
+	defer s.observe(context.Background(), "UpdateOfflineSessions", time.Now(), &err)
+	return s.Storage.UpdateOfflineSessions(userID, connID, updater)
+}
+
+func (s *instrumentedStorage) UpdateConnector(id string, updater func(c Connector) (Connector, error)) (err error) {
+	defer s.observe(context.Background(), "UpdateConnector", time.Now(), &err)
+	return s.Storage.UpdateConnector(id, updater)
+}
+
+func (s *instrumentedStorage) UpdateDeviceToken(deviceCode string, updater func(t DeviceToken) (DeviceToken, error)) (err error) {
+	defer s.observe(context.Background(), "UpdateDeviceToken", time.Now(), &err)
+	return s.Storage.UpdateDeviceToken(deviceCode, updater)
+}
+
+func (s *instrumentedStorage) UpdateWebhook(id string, updater func(w Webhook) (Webhook, error)) (err error) {
+	defer s.observe(context.Background(), "UpdateWebhook", time.Now(), &err)
+	return s.Storage.UpdateWebhook(id, updater)
+}
+
+func (s *instrumentedStorage) GarbageCollect(now time.Time, opts GCOptions) (_ GCResult, err error) {
+	defer s.observe(context.Background(), "GarbageCollect", time.Now(), &err)
+	return s.Storage.GarbageCollect(now, opts)
+}
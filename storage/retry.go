@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+// Tests for this code are in the "memory" package, since this package doesn't
+// define a concrete storage implementation.
+
+// conflictRetryDelays are the delays before retrying an update which failed
+// with ErrConflict. Random jitter of up to the delay is added to each.
+var conflictRetryDelays = []time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+}
+
+// RetryOnConflict calls update until it doesn't fail with ErrConflict, waiting
+// a little longer after each conflict. It gives up after a few retries, or if
+// the context is canceled, and returns the last error.
+//
+// Storages call updaters again on every retry, so they must not have side
+// effects:
+//
+//	err := storage.RetryOnConflict(ctx, func() error {
+//		return s.UpdateRefreshToken(id, updater)
+//	})
+func RetryOnConflict(ctx context.Context, update func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := update()
+		if !errors.Is(err, ErrConflict) || attempt >= len(conflictRetryDelays) {
+			return err
+		}
+		delay := conflictRetryDelays[attempt]
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay + rand.N(delay)):
+		}
+	}
+}
@@ -21,7 +21,8 @@ import (
 
 const (
 	// postgres error codes
-	pgErrUniqueViolation = "23505" // unique_violation
+	pgErrUniqueViolation      = "23505" // unique_violation
+	pgErrSerializationFailure = "40001" // serialization_failure
 )
 
 const (
@@ -57,12 +58,23 @@ type NetworkDB struct {
 
 	ConnectionTimeout int // Seconds
 
+	// StatementTimeout aborts statements running longer than this. For MySQL
+	// it is the timeout of reads and writes on connections instead.
+	StatementTimeout int // Seconds, default: not set
+
 	// database/sql tunables, see
 	// https://golang.org/pkg/database/sql/#DB.SetConnMaxLifetime and below
 	// Note: defaults will be set if these are 0
-	MaxOpenConns    int // default: 5
-	MaxIdleConns    int // default: 5
+	MaxOpenConns    int // default: 5, unlimited for MySQL
+	MaxIdleConns    int // default: 5, none for MySQL
 	ConnMaxLifetime int // Seconds, default: not set
+	ConnMaxIdleTime int // Seconds, default: not set
+
+	// LeaderElection lets only the dex instance holding a lease stored in
+	// the database run garbage collection and key rotation.
+	LeaderElection bool
+	// LeaseName is the name of the lease. Defaults to "dex".
+	LeaseName string
 }
 
 // SSL represents SSL options for network databases.
@@ -87,7 +99,7 @@ func (p *Postgres) Open(logger *slog.Logger) (storage.Storage, error) {
 	if err != nil {
 		return nil, err
 	}
-	return conn, nil
+	return newPostgresConn(conn, p.createDataSourceName()), nil
 }
 
 var strEsc = regexp.MustCompile(`([\\'])`)
@@ -108,6 +120,11 @@ func (p *Postgres) createDataSourceName() string {
 
 	addParam("connect_timeout", strconv.Itoa(p.ConnectionTimeout))
 
+	if p.StatementTimeout > 0 {
+		// Unknown parameters are set as run-time parameters of the session.
+		addParam("statement_timeout", strconv.Itoa(p.StatementTimeout*1000))
+	}
+
 	// detect host:port for backwards-compatibility
 	host, port, err := net.SplitHostPort(p.Host)
 	if err != nil {
@@ -163,6 +180,10 @@ func (p *Postgres) createDataSourceName() string {
 }
 
 func (p *Postgres) open(logger *slog.Logger) (*conn, error) {
+	return p.openFlavor(logger, &flavorPostgres)
+}
+
+func (p *Postgres) openFlavor(logger *slog.Logger, f *flavor) (*conn, error) {
 	dataSourceName := p.createDataSourceName()
 
 	db, err := sql.Open("postgres", dataSourceName)
@@ -175,6 +196,10 @@ func (p *Postgres) open(logger *slog.Logger) (*conn, error) {
 		db.SetConnMaxLifetime(time.Duration(p.ConnMaxLifetime) * time.Second)
 	}
 
+	if p.ConnMaxIdleTime != 0 {
+		db.SetConnMaxIdleTime(time.Duration(p.ConnMaxIdleTime) * time.Second)
+	}
+
 	if p.MaxIdleConns == 0 {
 		db.SetMaxIdleConns(5)
 	} else {
@@ -195,13 +220,42 @@ func (p *Postgres) open(logger *slog.Logger) (*conn, error) {
 		return sqlErr.Code == pgErrUniqueViolation
 	}
 
-	c := &conn{db, &flavorPostgres, logger, errCheck}
+	c := &conn{db: db, flavor: f, logger: logger, alreadyExistsCheck: errCheck}
 	if _, err := c.migrate(); err != nil {
 		return nil, fmt.Errorf("failed to perform migrations: %v", err)
 	}
+	if p.LeaderElection {
+		c.startLeaderElection(p.LeaseName)
+	}
 	return c, nil
 }
 
+// cockroachDefaultPort is the default SQL port of CockroachDB.
+const cockroachDefaultPort = 26257
+
+// CockroachDB options for creating an SQL db. The options are the same as
+// for Postgres, since CockroachDB is accessed with the postgres driver.
+type CockroachDB struct {
+	Postgres
+}
+
+// Open creates a new storage implementation backed by CockroachDB.
+func (c *CockroachDB) Open(logger *slog.Logger) (storage.Storage, error) {
+	conn, err := c.open(logger)
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (c *CockroachDB) open(logger *slog.Logger) (*conn, error) {
+	p := c.Postgres
+	if _, _, err := net.SplitHostPort(p.Host); err != nil && p.Port == 0 {
+		p.Port = cockroachDefaultPort
+	}
+	return p.openFlavor(logger, &flavorCockroach)
+}
+
 // MySQL options for creating a MySQL db.
 type MySQL struct {
 	NetworkDB
@@ -229,7 +283,9 @@ func (s *MySQL) open(logger *slog.Logger) (*conn, error) {
 		DBName:               s.Database,
 		AllowNativePasswords: true,
 
-		Timeout: time.Second * time.Duration(s.ConnectionTimeout),
+		Timeout:      time.Second * time.Duration(s.ConnectionTimeout),
+		ReadTimeout:  time.Second * time.Duration(s.StatementTimeout),
+		WriteTimeout: time.Second * time.Duration(s.StatementTimeout),
 
 		ParseTime: true,
 		Params: map[string]string{
@@ -278,6 +334,18 @@ func (s *MySQL) open(logger *slog.Logger) (*conn, error) {
 		db.SetMaxIdleConns(s.MaxIdleConns)
 	}
 
+	if s.MaxOpenConns != 0 {
+		db.SetMaxOpenConns(s.MaxOpenConns)
+	}
+
+	if s.ConnMaxLifetime != 0 {
+		db.SetConnMaxLifetime(time.Duration(s.ConnMaxLifetime) * time.Second)
+	}
+
+	if s.ConnMaxIdleTime != 0 {
+		db.SetConnMaxIdleTime(time.Duration(s.ConnMaxIdleTime) * time.Second)
+	}
+
 	err = db.Ping()
 	if err != nil {
 		if mysqlErr, ok := err.(*mysql.MySQLError); ok && mysqlErr.Number == mysqlErrUnknownSysVar {
@@ -307,10 +375,13 @@ func (s *MySQL) open(logger *slog.Logger) (*conn, error) {
 			sqlErr.Number == mysqlErrDupEntryWithKeyName
 	}
 
-	c := &conn{db, &flavorMySQL, logger, errCheck}
+	c := &conn{db: db, flavor: &flavorMySQL, logger: logger, alreadyExistsCheck: errCheck}
 	if _, err := c.migrate(); err != nil {
 		return nil, fmt.Errorf("failed to perform migrations: %v", err)
 	}
+	if s.LeaderElection {
+		c.startLeaderElection(s.LeaseName)
+	}
 	return c, nil
 }
 
@@ -73,6 +73,9 @@ func testDB(t *testing.T, o opener, withTransactions bool) {
 	withTimeout(time.Minute*1, func() {
 		conformance.RunTests(t, newStorage)
 	})
+	withTimeout(time.Minute*1, func() {
+		conformance.RunConcurrencyTests(t, newStorage)
+	})
 	if withTransactions {
 		withTimeout(time.Minute*1, func() {
 			conformance.RunTransactionTests(t, newStorage)
@@ -195,6 +198,17 @@ func TestCreateDataSourceName(t *testing.T) {
 			},
 			expected: `connect_timeout=0 host='coreos.com' user='some\'user\\slashed' password='some\'password!' sslmode='verify-full'`,
 		},
+		{
+			description: "with statement timeout",
+			input: &Postgres{
+				NetworkDB: NetworkDB{
+					Host:              "coreos.com",
+					ConnectionTimeout: 5,
+					StatementTimeout:  30,
+				},
+			},
+			expected: "connect_timeout=5 statement_timeout=30000 host='coreos.com' sslmode='verify-full'",
+		},
 	}
 
 	var actual string
@@ -241,6 +255,42 @@ func TestPostgres(t *testing.T) {
 	testDB(t, p, true)
 }
 
+const testCockroachEnv = "DEX_COCKROACH_HOST"
+
+func TestCockroachDB(t *testing.T) {
+	host := os.Getenv(testCockroachEnv)
+	if host == "" {
+		t.Skipf("test environment variable %q not set, skipping", testCockroachEnv)
+	}
+
+	port := uint64(cockroachDefaultPort)
+	if rawPort := os.Getenv("DEX_COCKROACH_PORT"); rawPort != "" {
+		var err error
+
+		port, err = strconv.ParseUint(rawPort, 10, 32)
+		if err != nil {
+			t.Fatalf("invalid cockroach port %q: %s", rawPort, err)
+		}
+	}
+
+	c := &CockroachDB{
+		Postgres: Postgres{
+			NetworkDB: NetworkDB{
+				Database:          getenv("DEX_COCKROACH_DATABASE", "defaultdb"),
+				User:              getenv("DEX_COCKROACH_USER", "root"),
+				Password:          os.Getenv("DEX_COCKROACH_PASSWORD"),
+				Host:              host,
+				Port:              uint16(port),
+				ConnectionTimeout: 5,
+			},
+			SSL: SSL{
+				Mode: pgSSLDisable, // Insecure single node cluster.
+			},
+		},
+	}
+	testDB(t, c, true)
+}
+
 const testMySQLEnv = "DEX_MYSQL_HOST"
 
 func TestMySQL(t *testing.T) {
@@ -41,7 +41,7 @@ func encoder(i interface{}) driver.Valuer {
 // decoder wraps the underlying value in a JSON unmarshaler which can then be passed
 // to a database Scan() method.
 func decoder(i interface{}) sql.Scanner {
-	return jsonDecoder{i}
+	return jsonDecoder{i: i}
 }
 
 type jsonEncoder struct {
@@ -56,12 +56,22 @@ func (j jsonEncoder) Value() (driver.Value, error) {
 	return b, nil
 }
 
+// nullDecoder is like decoder but leaves the underlying value untouched if the
+// column is NULL, for columns added after rows may already have been written.
+func nullDecoder(i interface{}) sql.Scanner {
+	return jsonDecoder{i: i, nullable: true}
+}
+
 type jsonDecoder struct {
-	i interface{}
+	i        interface{}
+	nullable bool
 }
 
 func (j jsonDecoder) Scan(dest interface{}) error {
 	if dest == nil {
+		if j.nullable {
+			return nil
+		}
 		return errors.New("nil value")
 	}
 	b, ok := dest.([]byte)
@@ -86,44 +96,75 @@ type scanner interface {
 
 var _ storage.Storage = (*conn)(nil)
 
-func (c *conn) GarbageCollect(now time.Time) (storage.GCResult, error) {
+func (c *conn) GarbageCollect(now time.Time, opts storage.GCOptions) (storage.GCResult, error) {
 	result := storage.GCResult{}
 
-	r, err := c.Exec(`delete from auth_request where expiry < $1`, now)
-	if err != nil {
-		return result, fmt.Errorf("gc auth_request: %v", err)
-	}
-	if n, err := r.RowsAffected(); err == nil {
-		result.AuthRequests = n
+	var err error
+	if opts.Includes(storage.GCAuthRequests) {
+		if result.AuthRequests, err = c.deleteExpired("auth_request", "id", now, opts.BatchSize); err != nil {
+			return result, fmt.Errorf("gc auth_request: %v", err)
+		}
 	}
 
-	r, err = c.Exec(`delete from auth_code where expiry < $1`, now)
-	if err != nil {
-		return result, fmt.Errorf("gc auth_code: %v", err)
-	}
-	if n, err := r.RowsAffected(); err == nil {
-		result.AuthCodes = n
+	if opts.Includes(storage.GCAuthCodes) {
+		if result.AuthCodes, err = c.deleteExpired("auth_code", "id", now, opts.BatchSize); err != nil {
+			return result, fmt.Errorf("gc auth_code: %v", err)
+		}
 	}
 
-	r, err = c.Exec(`delete from device_request where expiry < $1`, now)
-	if err != nil {
-		return result, fmt.Errorf("gc device_request: %v", err)
-	}
-	if n, err := r.RowsAffected(); err == nil {
-		result.DeviceRequests = n
+	if opts.Includes(storage.GCDeviceRequests) {
+		if result.DeviceRequests, err = c.deleteExpired("device_request", "user_code", now, opts.BatchSize); err != nil {
+			return result, fmt.Errorf("gc device_request: %v", err)
+		}
 	}
 
-	r, err = c.Exec(`delete from device_token where expiry < $1`, now)
-	if err != nil {
-		return result, fmt.Errorf("gc device_token: %v", err)
-	}
-	if n, err := r.RowsAffected(); err == nil {
-		result.DeviceTokens = n
+	if opts.Includes(storage.GCDeviceTokens) {
+		if result.DeviceTokens, err = c.deleteExpired("device_token", "device_code", now, opts.BatchSize); err != nil {
+			return result, fmt.Errorf("gc device_token: %v", err)
+		}
 	}
 
 	return result, err
 }
 
+// deleteExpired deletes the expired rows of a table, at most batchSize rows
+// per statement if it's set, and returns the number of rows deleted.
+func (c *conn) deleteExpired(table, key string, now time.Time, batchSize int) (int64, error) {
+	if batchSize <= 0 {
+		r, err := c.Exec(`delete from `+table+` where expiry < $1`, now)
+		if err != nil {
+			return 0, err
+		}
+		n, _ := r.RowsAffected()
+		return n, nil
+	}
+
+	// MySQL doesn't support limits in subqueries of an IN clause, unless
+	// they are nested in another subquery.
+	query := fmt.Sprintf(`
+		delete from %[1]s where %[2]s in (
+			select %[2]s from (
+				select %[2]s from %[1]s where expiry < $1 limit $2
+			) as expired
+		)`, table, key)
+
+	var deleted int64
+	for {
+		r, err := c.Exec(query, now, batchSize)
+		if err != nil {
+			return deleted, err
+		}
+		n, err := r.RowsAffected()
+		if err != nil {
+			return deleted, err
+		}
+		deleted += n
+		if n < int64(batchSize) {
+			return deleted, nil
+		}
+	}
+}
+
 func (c *conn) CreateAuthRequest(ctx context.Context, a storage.AuthRequest) error {
 	_, err := c.Exec(`
 		insert into auth_request (
@@ -134,10 +175,10 @@ func (c *conn) CreateAuthRequest(ctx context.Context, a storage.AuthRequest) err
 			connector_id, connector_data,
 			expiry,
 			code_challenge, code_challenge_method,
-			hmac_key
+			hmac_key, claims_custom, claims_request, claims_sources
 		)
 		values (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24
 		);
 	`,
 		a.ID, a.ClientID, encoder(a.ResponseTypes), encoder(a.Scopes), a.RedirectURI, a.Nonce, a.State,
@@ -147,7 +188,8 @@ func (c *conn) CreateAuthRequest(ctx context.Context, a storage.AuthRequest) err
 		a.ConnectorID, a.ConnectorData,
 		a.Expiry,
 		a.PKCE.CodeChallenge, a.PKCE.CodeChallengeMethod,
-		a.HMACKey,
+		a.HMACKey, encoder(a.Claims.CustomClaims), a.ClaimsRequest,
+		encoder(a.Claims.ClaimSources),
 	)
 	if err != nil {
 		if c.alreadyExistsCheck(err) {
@@ -180,8 +222,9 @@ func (c *conn) UpdateAuthRequest(id string, updater func(a storage.AuthRequest)
 				connector_id = $15, connector_data = $16,
 				expiry = $17,
 				code_challenge = $18, code_challenge_method = $19,
-				hmac_key = $20
-			where id = $21;
+				hmac_key = $20, claims_custom = $21, claims_request = $22,
+				claims_sources = $23
+			where id = $24;
 		`,
 			a.ClientID, encoder(a.ResponseTypes), encoder(a.Scopes), a.RedirectURI, a.Nonce, a.State,
 			a.ForceApprovalPrompt, a.LoggedIn,
@@ -191,6 +234,8 @@ func (c *conn) UpdateAuthRequest(id string, updater func(a storage.AuthRequest)
 			a.ConnectorID, a.ConnectorData,
 			a.Expiry,
 			a.PKCE.CodeChallenge, a.PKCE.CodeChallengeMethod, a.HMACKey,
+			encoder(a.Claims.CustomClaims), a.ClaimsRequest,
+			encoder(a.Claims.ClaimSources),
 			r.ID,
 		)
 		if err != nil {
@@ -212,7 +257,8 @@ func getAuthRequest(q querier, id string) (a storage.AuthRequest, err error) {
 			claims_user_id, claims_username, claims_preferred_username,
 			claims_email, claims_email_verified, claims_groups,
 			connector_id, connector_data, expiry,
-			code_challenge, code_challenge_method, hmac_key
+			code_challenge, code_challenge_method, hmac_key,
+			claims_custom, claims_request, claims_sources
 		from auth_request where id = $1;
 	`, id).Scan(
 		&a.ID, &a.ClientID, decoder(&a.ResponseTypes), decoder(&a.Scopes), &a.RedirectURI, &a.Nonce, &a.State,
@@ -222,6 +268,8 @@ func getAuthRequest(q querier, id string) (a storage.AuthRequest, err error) {
 		decoder(&a.Claims.Groups),
 		&a.ConnectorID, &a.ConnectorData, &a.Expiry,
 		&a.PKCE.CodeChallenge, &a.PKCE.CodeChallengeMethod, &a.HMACKey,
+		nullDecoder(&a.Claims.CustomClaims), &a.ClaimsRequest,
+		nullDecoder(&a.Claims.ClaimSources),
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -240,14 +288,19 @@ func (c *conn) CreateAuthCode(ctx context.Context, a storage.AuthCode) error {
 			claims_email, claims_email_verified, claims_groups,
 			connector_id, connector_data,
 			expiry,
-			code_challenge, code_challenge_method
+			code_challenge, code_challenge_method,
+			claims_custom, claims_request, claims_sources,
+			issuer
 		)
-		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16);
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20);
 	`,
 		a.ID, a.ClientID, encoder(a.Scopes), a.Nonce, a.RedirectURI, a.Claims.UserID,
 		a.Claims.Username, a.Claims.PreferredUsername, a.Claims.Email, a.Claims.EmailVerified,
 		encoder(a.Claims.Groups), a.ConnectorID, a.ConnectorData, a.Expiry,
 		a.PKCE.CodeChallenge, a.PKCE.CodeChallengeMethod,
+		encoder(a.Claims.CustomClaims), a.ClaimsRequest,
+		encoder(a.Claims.ClaimSources),
+		a.Issuer,
 	)
 	if err != nil {
 		if c.alreadyExistsCheck(err) {
@@ -266,13 +319,18 @@ func (c *conn) GetAuthCode(id string) (a storage.AuthCode, err error) {
 			claims_email, claims_email_verified, claims_groups,
 			connector_id, connector_data,
 			expiry,
-			code_challenge, code_challenge_method
+			code_challenge, code_challenge_method,
+			claims_custom, claims_request, claims_sources,
+			issuer
 		from auth_code where id = $1;
 	`, id).Scan(
 		&a.ID, &a.ClientID, decoder(&a.Scopes), &a.Nonce, &a.RedirectURI, &a.Claims.UserID,
 		&a.Claims.Username, &a.Claims.PreferredUsername, &a.Claims.Email, &a.Claims.EmailVerified,
 		decoder(&a.Claims.Groups), &a.ConnectorID, &a.ConnectorData, &a.Expiry,
 		&a.PKCE.CodeChallenge, &a.PKCE.CodeChallengeMethod,
+		nullDecoder(&a.Claims.CustomClaims), &a.ClaimsRequest,
+		nullDecoder(&a.Claims.ClaimSources),
+		&a.Issuer,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -290,9 +348,11 @@ func (c *conn) CreateRefresh(ctx context.Context, r storage.RefreshToken) error
 			claims_user_id, claims_username, claims_preferred_username,
 			claims_email, claims_email_verified, claims_groups,
 			connector_id, connector_data,
-			token, obsolete_token, created_at, last_used
+			token, obsolete_token, created_at, last_used,
+			claims_custom, claims_request, claims_sources,
+			issuer
 		)
-		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16);
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20);
 	`,
 		r.ID, r.ClientID, encoder(r.Scopes), r.Nonce,
 		r.Claims.UserID, r.Claims.Username, r.Claims.PreferredUsername,
@@ -300,6 +360,9 @@ func (c *conn) CreateRefresh(ctx context.Context, r storage.RefreshToken) error
 		encoder(r.Claims.Groups),
 		r.ConnectorID, r.ConnectorData,
 		r.Token, r.ObsoleteToken, r.CreatedAt, r.LastUsed,
+		encoder(r.Claims.CustomClaims), r.ClaimsRequest,
+		encoder(r.Claims.ClaimSources),
+		r.Issuer,
 	)
 	if err != nil {
 		if c.alreadyExistsCheck(err) {
@@ -336,16 +399,22 @@ func (c *conn) UpdateRefreshToken(id string, updater func(old storage.RefreshTok
 				token = $12,
                 obsolete_token = $13,
 				created_at = $14,
-				last_used = $15
+				last_used = $15,
+				claims_custom = $16,
+				claims_request = $17,
+				claims_sources = $18,
+				issuer = $19
 			where
-				id = $16
+				id = $20
 		`,
 			r.ClientID, encoder(r.Scopes), r.Nonce,
 			r.Claims.UserID, r.Claims.Username, r.Claims.PreferredUsername,
 			r.Claims.Email, r.Claims.EmailVerified,
 			encoder(r.Claims.Groups),
 			r.ConnectorID, r.ConnectorData,
-			r.Token, r.ObsoleteToken, r.CreatedAt, r.LastUsed, id,
+			r.Token, r.ObsoleteToken, r.CreatedAt, r.LastUsed,
+			encoder(r.Claims.CustomClaims), r.ClaimsRequest,
+			encoder(r.Claims.ClaimSources), r.Issuer, id,
 		)
 		if err != nil {
 			return fmt.Errorf("update refresh token: %v", err)
@@ -366,7 +435,9 @@ func getRefresh(q querier, id string) (storage.RefreshToken, error) {
 			claims_email, claims_email_verified,
 			claims_groups,
 			connector_id, connector_data,
-			token, obsolete_token, created_at, last_used
+			token, obsolete_token, created_at, last_used,
+			claims_custom, claims_request, claims_sources,
+			issuer
 		from refresh_token where id = $1;
 	`, id))
 }
@@ -378,7 +449,9 @@ func (c *conn) ListRefreshTokens() ([]storage.RefreshToken, error) {
 			claims_user_id, claims_username, claims_preferred_username,
 			claims_email, claims_email_verified, claims_groups,
 			connector_id, connector_data,
-			token, obsolete_token, created_at, last_used
+			token, obsolete_token, created_at, last_used,
+			claims_custom, claims_request, claims_sources,
+			issuer
 		from refresh_token;
 	`)
 	if err != nil {
@@ -408,6 +481,9 @@ func scanRefresh(s scanner) (r storage.RefreshToken, err error) {
 		decoder(&r.Claims.Groups),
 		&r.ConnectorID, &r.ConnectorData,
 		&r.Token, &r.ObsoleteToken, &r.CreatedAt, &r.LastUsed,
+		nullDecoder(&r.Claims.CustomClaims), &r.ClaimsRequest,
+		nullDecoder(&r.Claims.ClaimSources),
+		&r.Issuer,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -440,12 +516,13 @@ func (c *conn) UpdateKeys(updater func(old storage.Keys) (storage.Keys, error))
 		if firstUpdate {
 			_, err = tx.Exec(`
 				insert into keys (
-					id, verification_keys, signing_key, signing_key_pub, next_rotation
+					id, verification_keys, signing_key, signing_key_pub, next_rotation,
+					issuer_keys
 				)
-				values ($1, $2, $3, $4, $5);
+				values ($1, $2, $3, $4, $5, $6);
 			`,
 				keysRowID, encoder(nk.VerificationKeys), encoder(nk.SigningKey),
-				encoder(nk.SigningKeyPub), nk.NextRotation,
+				encoder(nk.SigningKeyPub), nk.NextRotation, encoder(nk.IssuerKeys),
 			)
 			if err != nil {
 				return fmt.Errorf("insert: %v", err)
@@ -457,17 +534,19 @@ func (c *conn) UpdateKeys(updater func(old storage.Keys) (storage.Keys, error))
 				    verification_keys = $1,
 					signing_key = $2,
 					signing_key_pub = $3,
-					next_rotation = $4
-				where id = $5;
+					next_rotation = $4,
+					issuer_keys = $5
+				where id = $6;
 			`,
 				encoder(nk.VerificationKeys), encoder(nk.SigningKey),
-				encoder(nk.SigningKeyPub), nk.NextRotation, keysRowID,
+				encoder(nk.SigningKeyPub), nk.NextRotation, encoder(nk.IssuerKeys),
+				keysRowID,
 			)
 			if err != nil {
 				return fmt.Errorf("update: %v", err)
 			}
 		}
-		return nil
+		return c.notifyChange(tx, storage.Change{Kind: storage.ChangeKeys})
 	})
 }
 
@@ -478,12 +557,14 @@ func (c *conn) GetKeys() (keys storage.Keys, err error) {
 func getKeys(q querier) (keys storage.Keys, err error) {
 	err = q.QueryRow(`
 		select
-			verification_keys, signing_key, signing_key_pub, next_rotation
+			verification_keys, signing_key, signing_key_pub, next_rotation,
+			issuer_keys
 		from keys
 		where id=$1
 	`, keysRowID).Scan(
 		decoder(&keys.VerificationKeys), decoder(&keys.SigningKey),
 		decoder(&keys.SigningKeyPub), &keys.NextRotation,
+		nullDecoder(&keys.IssuerKeys),
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -513,26 +594,48 @@ func (c *conn) UpdateClient(id string, updater func(old storage.Client) (storage
 				trusted_peers = $3,
 				public = $4,
 				name = $5,
-				logo_url = $6
-			where id = $7;
-		`, nc.Secret, encoder(nc.RedirectURIs), encoder(nc.TrustedPeers), nc.Public, nc.Name, nc.LogoURL, id,
+				logo_url = $6,
+				refresh_token_policy = $7,
+				token_expiry = $8,
+				groups_filter = $9,
+				claims_transforms = $10,
+				redirect_uri_patterns = $11,
+				previous_secret = $12,
+				created_at = $13,
+				last_used = $14,
+				policy_url = $15,
+				terms_of_service_url = $16,
+				allowed_origins = $17,
+				forward_claim_source_tokens = $18
+			where id = $19;
+		`, nc.Secret, encoder(nc.RedirectURIs), encoder(nc.TrustedPeers), nc.Public, nc.Name, nc.LogoURL,
+			encoder(nc.RefreshTokenPolicy), encoder(nc.TokenExpiry), encoder(nc.GroupsFilter),
+			encoder(nc.ClaimsTransforms), encoder(nc.RedirectURIPatterns), encoder(nc.PreviousSecret),
+			nc.CreatedAt, nc.LastUsed, nc.PolicyURL, nc.TermsOfServiceURL, encoder(nc.AllowedOrigins),
+			nc.ForwardClaimSourceTokens, id,
 		)
 		if err != nil {
 			return fmt.Errorf("update client: %v", err)
 		}
-		return nil
+		return c.notifyChange(tx, storage.Change{Kind: storage.ChangeClient, ID: id})
 	})
 }
 
 func (c *conn) CreateClient(ctx context.Context, cli storage.Client) error {
 	_, err := c.Exec(`
 		insert into client (
-			id, secret, redirect_uris, trusted_peers, public, name, logo_url
+			id, secret, redirect_uris, trusted_peers, public, name, logo_url,
+			refresh_token_policy, token_expiry, groups_filter, claims_transforms,
+			redirect_uri_patterns, previous_secret, created_at, last_used,
+			policy_url, terms_of_service_url, allowed_origins, forward_claim_source_tokens
 		)
-		values ($1, $2, $3, $4, $5, $6, $7);
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19);
 	`,
 		cli.ID, cli.Secret, encoder(cli.RedirectURIs), encoder(cli.TrustedPeers),
-		cli.Public, cli.Name, cli.LogoURL,
+		cli.Public, cli.Name, cli.LogoURL, encoder(cli.RefreshTokenPolicy),
+		encoder(cli.TokenExpiry), encoder(cli.GroupsFilter), encoder(cli.ClaimsTransforms),
+		encoder(cli.RedirectURIPatterns), encoder(cli.PreviousSecret), cli.CreatedAt, cli.LastUsed,
+		cli.PolicyURL, cli.TermsOfServiceURL, encoder(cli.AllowedOrigins), cli.ForwardClaimSourceTokens,
 	)
 	if err != nil {
 		if c.alreadyExistsCheck(err) {
@@ -540,13 +643,17 @@ func (c *conn) CreateClient(ctx context.Context, cli storage.Client) error {
 		}
 		return fmt.Errorf("insert client: %v", err)
 	}
+	c.publishChange(storage.Change{Kind: storage.ChangeClient, ID: cli.ID})
 	return nil
 }
 
 func getClient(q querier, id string) (storage.Client, error) {
 	return scanClient(q.QueryRow(`
 		select
-			id, secret, redirect_uris, trusted_peers, public, name, logo_url
+			id, secret, redirect_uris, trusted_peers, public, name, logo_url,
+			refresh_token_policy, token_expiry, groups_filter, claims_transforms,
+			redirect_uri_patterns, previous_secret, created_at, last_used,
+			policy_url, terms_of_service_url, allowed_origins, forward_claim_source_tokens
 	    from client where id = $1;
 	`, id))
 }
@@ -558,7 +665,10 @@ func (c *conn) GetClient(id string) (storage.Client, error) {
 func (c *conn) ListClients() ([]storage.Client, error) {
 	rows, err := c.Query(`
 		select
-			id, secret, redirect_uris, trusted_peers, public, name, logo_url
+			id, secret, redirect_uris, trusted_peers, public, name, logo_url,
+			refresh_token_policy, token_expiry, groups_filter, claims_transforms,
+			redirect_uri_patterns, previous_secret, created_at, last_used,
+			policy_url, terms_of_service_url, allowed_origins, forward_claim_source_tokens
 		from client;
 	`)
 	if err != nil {
@@ -583,7 +693,11 @@ func (c *conn) ListClients() ([]storage.Client, error) {
 func scanClient(s scanner) (cli storage.Client, err error) {
 	err = s.Scan(
 		&cli.ID, &cli.Secret, decoder(&cli.RedirectURIs), decoder(&cli.TrustedPeers),
-		&cli.Public, &cli.Name, &cli.LogoURL,
+		&cli.Public, &cli.Name, &cli.LogoURL, nullDecoder(&cli.RefreshTokenPolicy),
+		nullDecoder(&cli.TokenExpiry), nullDecoder(&cli.GroupsFilter), nullDecoder(&cli.ClaimsTransforms),
+		nullDecoder(&cli.RedirectURIPatterns), nullDecoder(&cli.PreviousSecret),
+		&cli.CreatedAt, &cli.LastUsed, &cli.PolicyURL, &cli.TermsOfServiceURL, nullDecoder(&cli.AllowedOrigins),
+		&cli.ForwardClaimSourceTokens,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -598,13 +712,15 @@ func (c *conn) CreatePassword(ctx context.Context, p storage.Password) error {
 	p.Email = strings.ToLower(p.Email)
 	_, err := c.Exec(`
 		insert into password (
-			email, hash, username, user_id
+			email, hash, username, user_id, email_verified,
+			failed_attempts, locked_until
 		)
 		values (
-			$1, $2, $3, $4
+			$1, $2, $3, $4, $5, $6, $7
 		);
 	`,
-		p.Email, p.Hash, p.Username, p.UserID,
+		p.Email, p.Hash, p.Username, p.UserID, p.EmailVerified,
+		p.FailedAttempts, p.LockedUntil,
 	)
 	if err != nil {
 		if c.alreadyExistsCheck(err) {
@@ -629,10 +745,12 @@ func (c *conn) UpdatePassword(email string, updater func(p storage.Password) (st
 		_, err = tx.Exec(`
 			update password
 			set
-				hash = $1, username = $2, user_id = $3
-			where email = $4;
+				hash = $1, username = $2, user_id = $3, email_verified = $4,
+				failed_attempts = $5, locked_until = $6
+			where email = $7;
 		`,
-			np.Hash, np.Username, np.UserID, p.Email,
+			np.Hash, np.Username, np.UserID, np.EmailVerified,
+			np.FailedAttempts, np.LockedUntil, p.Email,
 		)
 		if err != nil {
 			return fmt.Errorf("update password: %v", err)
@@ -648,7 +766,8 @@ func (c *conn) GetPassword(email string) (storage.Password, error) {
 func getPassword(q querier, email string) (p storage.Password, err error) {
 	return scanPassword(q.QueryRow(`
 		select
-			email, hash, username, user_id
+			email, hash, username, user_id, email_verified,
+			failed_attempts, locked_until
 		from password where email = $1;
 	`, strings.ToLower(email)))
 }
@@ -656,7 +775,8 @@ func getPassword(q querier, email string) (p storage.Password, err error) {
 func (c *conn) ListPasswords() ([]storage.Password, error) {
 	rows, err := c.Query(`
 		select
-			email, hash, username, user_id
+			email, hash, username, user_id, email_verified,
+			failed_attempts, locked_until
 		from password;
 	`)
 	if err != nil {
@@ -680,7 +800,8 @@ func (c *conn) ListPasswords() ([]storage.Password, error) {
 
 func scanPassword(s scanner) (p storage.Password, err error) {
 	err = s.Scan(
-		&p.Email, &p.Hash, &p.Username, &p.UserID,
+		&p.Email, &p.Hash, &p.Username, &p.UserID, &p.EmailVerified,
+		&p.FailedAttempts, &p.LockedUntil,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -767,13 +888,14 @@ func scanOfflineSessions(s scanner) (o storage.OfflineSessions, err error) {
 func (c *conn) CreateConnector(ctx context.Context, connector storage.Connector) error {
 	_, err := c.Exec(`
 		insert into connector (
-			id, type, name, resource_version, config
+			id, type, name, resource_version, config, display
 		)
 		values (
-			$1, $2, $3, $4, $5
+			$1, $2, $3, $4, $5, $6
 		);
 	`,
 		connector.ID, connector.Type, connector.Name, connector.ResourceVersion, connector.Config,
+		encoder(connector.Display),
 	)
 	if err != nil {
 		if c.alreadyExistsCheck(err) {
@@ -781,6 +903,7 @@ func (c *conn) CreateConnector(ctx context.Context, connector storage.Connector)
 		}
 		return fmt.Errorf("insert connector: %v", err)
 	}
+	c.publishChange(storage.Change{Kind: storage.ChangeConnector, ID: connector.ID})
 	return nil
 }
 
@@ -801,15 +924,17 @@ func (c *conn) UpdateConnector(id string, updater func(s storage.Connector) (sto
 			    type = $1,
 			    name = $2,
 			    resource_version = $3,
-			    config = $4
-			where id = $5;
+			    config = $4,
+			    display = $5
+			where id = $6;
 		`,
-			newConn.Type, newConn.Name, newConn.ResourceVersion, newConn.Config, connector.ID,
+			newConn.Type, newConn.Name, newConn.ResourceVersion, newConn.Config,
+			encoder(newConn.Display), connector.ID,
 		)
 		if err != nil {
 			return fmt.Errorf("update connector: %v", err)
 		}
-		return nil
+		return c.notifyChange(tx, storage.Change{Kind: storage.ChangeConnector, ID: connector.ID})
 	})
 }
 
@@ -820,7 +945,7 @@ func (c *conn) GetConnector(id string) (storage.Connector, error) {
 func getConnector(q querier, id string) (storage.Connector, error) {
 	return scanConnector(q.QueryRow(`
 		select
-			id, type, name, resource_version, config
+			id, type, name, resource_version, config, display
 		from connector
 		where id = $1;
 		`, id))
@@ -829,6 +954,7 @@ func getConnector(q querier, id string) (storage.Connector, error) {
 func scanConnector(s scanner) (c storage.Connector, err error) {
 	err = s.Scan(
 		&c.ID, &c.Type, &c.Name, &c.ResourceVersion, &c.Config,
+		nullDecoder(&c.Display),
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -842,7 +968,7 @@ func scanConnector(s scanner) (c storage.Connector, err error) {
 func (c *conn) ListConnectors() ([]storage.Connector, error) {
 	rows, err := c.Query(`
 		select
-			id, type, name, resource_version, config
+			id, type, name, resource_version, config, display
 		from connector;
 	`)
 	if err != nil {
@@ -866,12 +992,27 @@ func (c *conn) ListConnectors() ([]storage.Connector, error) {
 
 func (c *conn) DeleteAuthRequest(id string) error { return c.delete("auth_request", "id", id) }
 func (c *conn) DeleteAuthCode(id string) error    { return c.delete("auth_code", "id", id) }
-func (c *conn) DeleteClient(id string) error      { return c.delete("client", "id", id) }
 func (c *conn) DeleteRefresh(id string) error     { return c.delete("refresh_token", "id", id) }
 func (c *conn) DeletePassword(email string) error {
 	return c.delete("password", "email", strings.ToLower(email))
 }
-func (c *conn) DeleteConnector(id string) error { return c.delete("connector", "id", id) }
+func (c *conn) DeleteWebhook(id string) error { return c.delete("webhook", "id", id) }
+
+func (c *conn) DeleteClient(id string) error {
+	if err := c.delete("client", "id", id); err != nil {
+		return err
+	}
+	c.publishChange(storage.Change{Kind: storage.ChangeClient, ID: id})
+	return nil
+}
+
+func (c *conn) DeleteConnector(id string) error {
+	if err := c.delete("connector", "id", id); err != nil {
+		return err
+	}
+	c.publishChange(storage.Change{Kind: storage.ChangeConnector, ID: id})
+	return nil
+}
 
 func (c *conn) DeleteOfflineSessions(userID string, connID string) error {
 	result, err := c.Exec(`delete from offline_session where user_id = $1 AND conn_id = $2`, userID, connID)
@@ -970,6 +1111,32 @@ func getDeviceRequest(q querier, userCode string) (d storage.DeviceRequest, err
 	return d, nil
 }
 
+func (c *conn) ListDeviceRequests() ([]storage.DeviceRequest, error) {
+	rows, err := c.Query(`
+		select
+			user_code, device_code, client_id, client_secret, scopes, expiry
+		from device_request;
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query: %v", err)
+	}
+	defer rows.Close()
+
+	var reqs []storage.DeviceRequest
+	for rows.Next() {
+		var d storage.DeviceRequest
+		err := rows.Scan(&d.UserCode, &d.DeviceCode, &d.ClientID, &d.ClientSecret, decoder(&d.Scopes), &d.Expiry)
+		if err != nil {
+			return nil, fmt.Errorf("scan device request: %v", err)
+		}
+		reqs = append(reqs, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scan: %v", err)
+	}
+	return reqs, nil
+}
+
 func (c *conn) GetDeviceToken(deviceCode string) (storage.DeviceToken, error) {
 	return getDeviceToken(c, deviceCode)
 }
@@ -1021,3 +1188,103 @@ func (c *conn) UpdateDeviceToken(deviceCode string, updater func(old storage.Dev
 		return nil
 	})
 }
+
+func (c *conn) CreateWebhook(ctx context.Context, w storage.Webhook) error {
+	_, err := c.Exec(`
+		insert into webhook (
+			id, url, secret, events, retry_policy, created_at
+		)
+		values (
+			$1, $2, $3, $4, $5, $6
+		);
+	`,
+		w.ID, w.URL, w.Secret, encoder(w.Events), encoder(w.RetryPolicy), w.CreatedAt,
+	)
+	if err != nil {
+		if c.alreadyExistsCheck(err) {
+			return storage.ErrAlreadyExists
+		}
+		return fmt.Errorf("insert webhook: %v", err)
+	}
+	return nil
+}
+
+func (c *conn) UpdateWebhook(id string, updater func(w storage.Webhook) (storage.Webhook, error)) error {
+	return c.ExecTx(func(tx *trans) error {
+		w, err := getWebhook(tx, id)
+		if err != nil {
+			return err
+		}
+
+		nw, err := updater(w)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`
+			update webhook
+			set
+				url = $1,
+				secret = $2,
+				events = $3,
+				retry_policy = $4
+			where id = $5;
+		`,
+			nw.URL, nw.Secret, encoder(nw.Events), encoder(nw.RetryPolicy), id,
+		)
+		if err != nil {
+			return fmt.Errorf("update webhook: %v", err)
+		}
+		return nil
+	})
+}
+
+func (c *conn) GetWebhook(id string) (storage.Webhook, error) {
+	return getWebhook(c, id)
+}
+
+func getWebhook(q querier, id string) (storage.Webhook, error) {
+	return scanWebhook(q.QueryRow(`
+		select
+			id, url, secret, events, retry_policy, created_at
+		from webhook
+		where id = $1;
+		`, id))
+}
+
+func scanWebhook(s scanner) (w storage.Webhook, err error) {
+	err = s.Scan(
+		&w.ID, &w.URL, &w.Secret, decoder(&w.Events), decoder(&w.RetryPolicy), &w.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return w, storage.ErrNotFound
+		}
+		return w, fmt.Errorf("select webhook: %v", err)
+	}
+	return w, nil
+}
+
+func (c *conn) ListWebhooks() ([]storage.Webhook, error) {
+	rows, err := c.Query(`
+		select
+			id, url, secret, events, retry_policy, created_at
+		from webhook;
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []storage.Webhook
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
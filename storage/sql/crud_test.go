@@ -32,6 +32,31 @@ func TestDecoder(t *testing.T) {
 	}
 }
 
+func TestNullDecoder(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(`create table foo ( id integer primary key, bar blob );`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`insert into foo ( id, bar ) values (1, null);`); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.QueryRow(`select bar from foo where id = 1;`).Scan(decoder(new([]string))); err == nil {
+		t.Errorf("expected decoding null to fail")
+	}
+	var got []string
+	if err := db.QueryRow(`select bar from foo where id = 1;`).Scan(nullDecoder(&got)); err != nil {
+		t.Fatal(err)
+	}
+	if got != nil {
+		t.Errorf("wanted nil got %q", got)
+	}
+}
+
 func TestEncoder(t *testing.T) {
 	db, err := sql.Open("sqlite3", ":memory:")
 	if err != nil {
@@ -0,0 +1,121 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dexidp/dex/storage"
+)
+
+var _ storage.LeaderElector = (*conn)(nil)
+
+// lease is a row of the leader_lease table, held by the leader. The holder
+// counts its renewals in the row, and other instances take over the lease if
+// the count didn't change for the lease duration. Expiry is judged by the
+// local clock of each instance, so the clocks of the instances needn't be in
+// sync.
+type lease struct {
+	c        *conn
+	name     string
+	identity string
+
+	// The lease as last seen held by another instance and when it was seen.
+	observedHolder   string
+	observedRenewals int64
+	observedAt       time.Time
+}
+
+func newLeaderElection(c *conn, name string) *storage.LeaseElection {
+	if name == "" {
+		name = storage.DefaultLeaseName
+	}
+	l := &lease{c: c, name: name, identity: storage.NewLeaseIdentity()}
+	return storage.NewLeaseElection(c.logger, name, l.identity, l.acquireOrRenew, l.release)
+}
+
+// IsLeader reports whether this instance holds the lease, or always true if
+// leader election is disabled.
+func (c *conn) IsLeader() bool {
+	if c.leader == nil {
+		return true
+	}
+	return c.leader.IsLeader()
+}
+
+// startLeaderElection elects a leader among the instances sharing the
+// database until the connection is closed.
+func (c *conn) startLeaderElection(name string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	c.leader = newLeaderElection(c, name)
+	c.stopLeaderElection = func() {
+		cancel()
+		<-done
+	}
+	go func() {
+		defer close(done)
+		c.leader.Run(ctx)
+	}()
+}
+
+// acquireOrRenew updates the lease to be held by this instance if it already
+// holds it or the lease expired, reporting whether it holds the lease.
+func (l *lease) acquireOrRenew(now time.Time) (bool, error) {
+	var (
+		holder   string
+		renewals int64
+	)
+	err := l.c.QueryRow(`select holder, renewals from leader_lease where name = $1;`, l.name).Scan(&holder, &renewals)
+	if errors.Is(err, sql.ErrNoRows) {
+		_, err := l.c.Exec(`insert into leader_lease (name, holder, renewals) values ($1, $2, 0);`, l.name, l.identity)
+		if err != nil {
+			if l.c.alreadyExistsCheck(err) {
+				// Another instance created it first.
+				return false, nil
+			}
+			return false, fmt.Errorf("create lease: %v", err)
+		}
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("get lease: %v", err)
+	}
+
+	if holder != l.identity && holder != "" {
+		if holder != l.observedHolder || renewals != l.observedRenewals {
+			l.observedHolder = holder
+			l.observedRenewals = renewals
+			l.observedAt = now
+		}
+		if now.Before(l.observedAt.Add(storage.LeaseDuration)) {
+			return false, nil
+		}
+	}
+
+	// Matching the holder and count of renewals makes this a compare-and-swap,
+	// which fails if another instance updated the lease in the meantime.
+	result, err := l.c.Exec(`
+		update leader_lease set holder = $1, renewals = $2
+		where name = $3 and holder = $4 and renewals = $5;
+	`, l.identity, renewals+1, l.name, holder, renewals)
+	if err != nil {
+		return false, fmt.Errorf("update lease: %v", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("update lease: %v", err)
+	}
+	return n == 1, nil
+}
+
+// release gives up the lease if this instance holds it.
+func (l *lease) release() error {
+	_, err := l.c.Exec(`
+		update leader_lease set holder = '', renewals = renewals + 1
+		where name = $1 and holder = $2;
+	`, l.name, l.identity)
+	return err
+}
@@ -0,0 +1,71 @@
+//go:build cgo
+// +build cgo
+
+package sql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dexidp/dex/storage"
+)
+
+func TestLeaderElection(t *testing.T) {
+	c, err := (&SQLite3{File: ":memory:"}).open(logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	now := time.Now()
+	clock := func() time.Time { return now }
+	a, b := newLeaderElection(c, ""), newLeaderElection(c, "")
+	a.Now, b.Now = clock, clock
+
+	a.TryAcquireOrRenew()
+	b.TryAcquireOrRenew()
+	if !a.IsLeader() || b.IsLeader() {
+		t.Fatalf("expected only the first instance to acquire the lease: a=%v b=%v", a.IsLeader(), b.IsLeader())
+	}
+
+	// The lease is kept while the leader renews it.
+	now = now.Add(storage.LeaseRetryPeriod)
+	a.TryAcquireOrRenew()
+	b.TryAcquireOrRenew()
+	now = now.Add(storage.LeaseDuration - time.Second)
+	a.TryAcquireOrRenew()
+	b.TryAcquireOrRenew()
+	if !a.IsLeader() || b.IsLeader() {
+		t.Fatalf("expected the leader to keep the lease: a=%v b=%v", a.IsLeader(), b.IsLeader())
+	}
+
+	// Another instance takes over once the leader stopped renewing it.
+	now = now.Add(storage.LeaseDuration)
+	b.TryAcquireOrRenew()
+	if a.IsLeader() || !b.IsLeader() {
+		t.Fatalf("expected the lease to be taken over: a=%v b=%v", a.IsLeader(), b.IsLeader())
+	}
+	a.TryAcquireOrRenew()
+	if a.IsLeader() {
+		t.Fatal("expected the previous leader not to reacquire the lease")
+	}
+
+	// Released leases are acquired right away.
+	b.Release()
+	a.TryAcquireOrRenew()
+	if !a.IsLeader() {
+		t.Fatal("expected the released lease to be acquired")
+	}
+}
+
+func TestLeaderElectionDisabled(t *testing.T) {
+	c, err := (&SQLite3{File: ":memory:"}).open(logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if !c.IsLeader() {
+		t.Fatal("expected instances to lead without leader election")
+	}
+}
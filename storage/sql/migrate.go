@@ -298,4 +298,183 @@ var migrations = []migration{
 				add column hmac_key bytea;`,
 		},
 	},
+	{
+		stmts: []string{
+			`
+			alter table auth_request
+				add column claims_custom bytea;`,
+			`
+			alter table auth_code
+				add column claims_custom bytea;`,
+			`
+			alter table refresh_token
+				add column claims_custom bytea;`,
+		},
+	},
+	{
+		stmts: []string{
+			`
+			alter table auth_request
+				add column claims_request bytea;`,
+			`
+			alter table auth_code
+				add column claims_request bytea;`,
+			`
+			alter table refresh_token
+				add column claims_request bytea;`,
+		},
+	},
+	{
+		stmts: []string{
+			`
+			alter table auth_request
+				add column claims_sources bytea;`,
+			`
+			alter table auth_code
+				add column claims_sources bytea;`,
+			`
+			alter table refresh_token
+				add column claims_sources bytea;`,
+		},
+	},
+	{
+		stmts: []string{
+			`
+			alter table client
+				add column refresh_token_policy bytea;`,
+		},
+	},
+	{
+		stmts: []string{
+			`
+			alter table keys
+				add column issuer_keys bytea;`,
+		},
+	},
+	{
+		stmts: []string{
+			`
+			alter table client
+				add column token_expiry bytea;`,
+		},
+	},
+	{
+		stmts: []string{
+			`
+			alter table client
+				add column groups_filter bytea;`,
+		},
+	},
+	{
+		stmts: []string{
+			`
+			alter table client
+				add column claims_transforms bytea;`,
+		},
+	},
+	{
+		stmts: []string{
+			`
+			alter table client
+				add column redirect_uri_patterns bytea;`,
+		},
+	},
+	{
+		stmts: []string{
+			`
+			alter table client
+				add column previous_secret bytea;`,
+		},
+	},
+	{
+		stmts: []string{
+			`
+			alter table client
+				add column created_at timestamptz not null default '0001-01-01 00:00:00 UTC';`,
+			`
+			alter table client
+				add column last_used timestamptz not null default '0001-01-01 00:00:00 UTC';`,
+		},
+	},
+	{
+		stmts: []string{
+			`
+			create table webhook (
+				id text not null primary key,
+				url text not null,
+				secret text not null,
+				events bytea not null,
+				retry_policy bytea,
+				created_at timestamptz not null
+			);`,
+		},
+	},
+	{
+		stmts: []string{
+			`
+			alter table password
+				add column email_verified boolean not null default true;`,
+		},
+	},
+	{
+		stmts: []string{
+			`
+			alter table client
+				add column policy_url text not null default '';`,
+			`
+			alter table client
+				add column terms_of_service_url text not null default '';`,
+		},
+	},
+	{
+		stmts: []string{
+			`
+			alter table connector
+				add column display bytea;`,
+		},
+	},
+	{
+		stmts: []string{
+			`
+			create table leader_lease (
+				name text not null primary key,
+				holder text not null,
+				renewals bigint not null
+			);`,
+		},
+	},
+	{
+		stmts: []string{
+			`
+			alter table password
+				add column failed_attempts integer not null default 0;`,
+			`
+			alter table password
+				add column locked_until timestamptz not null default '0001-01-01 00:00:00 UTC';`,
+		},
+	},
+	{
+		stmts: []string{
+			`
+			alter table client
+				add column allowed_origins bytea;`,
+		},
+	},
+	{
+		stmts: []string{
+			`
+			alter table client
+				add column forward_claim_source_tokens boolean not null default false;`,
+		},
+	},
+	{
+		stmts: []string{
+			`
+			alter table auth_code
+				add column issuer text not null default '';`,
+			`
+			alter table refresh_token
+				add column issuer text not null default '';`,
+		},
+	},
 }
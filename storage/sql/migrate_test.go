@@ -36,7 +36,7 @@ func TestMigrate(t *testing.T) {
 		}
 	}
 
-	c := &conn{db, &flavorSQLite3, logger, errCheck}
+	c := &conn{db: db, flavor: &flavorSQLite3, logger: logger, alreadyExistsCheck: errCheck}
 	for _, want := range []int{len(sqliteMigrations), 0} {
 		got, err := c.migrate()
 		if err != nil {
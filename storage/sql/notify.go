@@ -0,0 +1,143 @@
+package sql
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/dexidp/dex/storage"
+)
+
+// changesChannel is the Postgres channel changes to clients, connectors and
+// keys are notified on.
+const changesChannel = "dex_changes"
+
+// Reconnect intervals and the interval connections are checked at while no
+// notifications are received.
+const (
+	listenerMinReconnect = 10 * time.Second
+	listenerMaxReconnect = time.Minute
+	listenerPingInterval = 90 * time.Second
+)
+
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// notifyChange notifies all dex instances sharing the database of a change.
+// Notifications sent in a transaction are only delivered once it commits.
+func (c *conn) notifyChange(e execer, change storage.Change) error {
+	if !c.notifyChanges {
+		return nil
+	}
+	payload, err := json.Marshal(change)
+	if err != nil {
+		return fmt.Errorf("encode change: %v", err)
+	}
+	if _, err := e.Exec(`select pg_notify($1, $2)`, changesChannel, string(payload)); err != nil {
+		return fmt.Errorf("notify change: %v", err)
+	}
+	return nil
+}
+
+// publishChange notifies a change outside of a transaction. The change is
+// already stored, so failures are only logged and other instances see it
+// once their caches expire.
+func (c *conn) publishChange(change storage.Change) {
+	if err := c.notifyChange(c, change); err != nil {
+		c.logger.Error("failed to notify other instances of change",
+			"kind", change.Kind, "id", change.ID, "err", err)
+	}
+}
+
+// decodeChange decodes a notification received on changesChannel. A nil
+// notification is received after the listener reconnected, so notifications
+// may have been missed and anything may have changed.
+func decodeChange(n *pq.Notification) (storage.Change, error) {
+	var change storage.Change
+	if n == nil {
+		return change, nil
+	}
+	if err := json.Unmarshal([]byte(n.Extra), &change); err != nil {
+		return storage.Change{}, fmt.Errorf("decode change %q: %v", n.Extra, err)
+	}
+	return change, nil
+}
+
+// postgresConn is a Postgres storage which implements storage.ChangeNotifier
+// by listening for the changes notified by all instances.
+type postgresConn struct {
+	*conn
+	dataSourceName string
+
+	mu          sync.Mutex
+	listener    *pq.Listener
+	subscribers []func(storage.Change)
+}
+
+func newPostgresConn(c *conn, dataSourceName string) *postgresConn {
+	c.notifyChanges = true
+	return &postgresConn{conn: c, dataSourceName: dataSourceName}
+}
+
+// OnChange implements storage.ChangeNotifier. The dedicated listener
+// connection is only opened once the first function is registered.
+func (c *postgresConn) OnChange(fn func(storage.Change)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.subscribers = append(c.subscribers, fn)
+	if c.listener == nil {
+		c.listener = pq.NewListener(c.dataSourceName, listenerMinReconnect, listenerMaxReconnect,
+			func(_ pq.ListenerEventType, err error) {
+				if err != nil {
+					c.logger.Error("postgres change listener", "err", err)
+				}
+			})
+		go c.listen(c.listener)
+	}
+}
+
+func (c *postgresConn) listen(l *pq.Listener) {
+	// Listen succeeds if the connection is down, the channel is listened on
+	// once it is established.
+	if err := l.Listen(changesChannel); err != nil {
+		c.logger.Error("failed to listen for changes", "channel", changesChannel, "err", err)
+		return
+	}
+	for {
+		select {
+		case n, ok := <-l.Notify:
+			if !ok {
+				// The listener was closed.
+				return
+			}
+			change, err := decodeChange(n)
+			if err != nil {
+				// Drop everything, the change is unknown.
+				c.logger.Error("invalid change notification", "err", err)
+			}
+			c.mu.Lock()
+			subscribers := c.subscribers
+			c.mu.Unlock()
+			for _, fn := range subscribers {
+				fn(change)
+			}
+		case <-time.After(listenerPingInterval):
+			go l.Ping()
+		}
+	}
+}
+
+func (c *postgresConn) Close() error {
+	c.mu.Lock()
+	if c.listener != nil {
+		c.listener.Close()
+	}
+	c.mu.Unlock()
+	return c.conn.Close()
+}
@@ -4,12 +4,18 @@
 package sql
 
 import (
+	"context"
 	"os"
 	"strconv"
 	"testing"
+	"time"
+
+	"github.com/dexidp/dex/storage"
 )
 
-func TestPostgresTunables(t *testing.T) {
+// postgresTestConfig returns the config of the Postgres database to test
+// against, the test is skipped if there is none.
+func postgresTestConfig(t *testing.T) *Postgres {
 	host := os.Getenv(testPostgresEnv)
 	if host == "" {
 		t.Skipf("test environment variable %q not set, skipping", testPostgresEnv)
@@ -25,7 +31,7 @@ func TestPostgresTunables(t *testing.T) {
 		}
 	}
 
-	baseCfg := &Postgres{
+	return &Postgres{
 		NetworkDB: NetworkDB{
 			Database: getenv("DEX_POSTGRES_DATABASE", "postgres"),
 			User:     getenv("DEX_POSTGRES_USER", "postgres"),
@@ -37,6 +43,10 @@ func TestPostgresTunables(t *testing.T) {
 			Mode: pgSSLDisable, // Postgres container doesn't support SSL.
 		},
 	}
+}
+
+func TestPostgresTunables(t *testing.T) {
+	baseCfg := postgresTestConfig(t)
 
 	t.Run("with nothing set, uses defaults", func(t *testing.T) {
 		cfg := *baseCfg
@@ -63,3 +73,50 @@ func TestPostgresTunables(t *testing.T) {
 		}
 	})
 }
+
+func TestPostgresChangeNotifications(t *testing.T) {
+	cfg := postgresTestConfig(t)
+
+	s1, err := cfg.Open(logger)
+	if err != nil {
+		t.Fatalf("error opening storage: %v", err)
+	}
+	defer s1.Close()
+	s2, err := cfg.Open(logger)
+	if err != nil {
+		t.Fatalf("error opening storage: %v", err)
+	}
+	defer s2.Close()
+
+	changes := make(chan storage.Change, 10)
+	s2.(storage.ChangeNotifier).OnChange(func(change storage.Change) {
+		select {
+		case changes <- change:
+		default:
+		}
+	})
+
+	// The listener connects in the background, keep notifying until it's up.
+	id := storage.NewID()
+	if err := s1.CreateClient(context.Background(), storage.Client{ID: id, Secret: "secret"}); err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+	defer s1.DeleteClient(id)
+	want := storage.Change{Kind: storage.ChangeClient, ID: id}
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case change := <-changes:
+			if change == want {
+				return
+			}
+		case <-time.After(100 * time.Millisecond):
+			err := s1.UpdateClient(id, func(c storage.Client) (storage.Client, error) { return c, nil })
+			if err != nil {
+				t.Fatalf("update client: %v", err)
+			}
+		case <-timeout:
+			t.Fatalf("change %+v of another instance wasn't notified", want)
+		}
+	}
+}
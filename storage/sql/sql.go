@@ -3,13 +3,18 @@ package sql
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
 	"log/slog"
 	"regexp"
+	"strings"
 	"time"
 
+	"github.com/lib/pq"
 	// import third party drivers
-	_ "github.com/lib/pq"
 	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/dexidp/dex/storage"
 )
 
 // flavor represents a specific SQL implementation, and is used to translate query strings
@@ -60,14 +65,23 @@ var (
 				return err
 			}
 			if err := fn(tx); err != nil {
-				return err
+				return conflictError(err)
 			}
-			return tx.Commit()
+			return conflictError(tx.Commit())
 		},
 
 		supportsTimezones: true,
 	}
 
+	// CockroachDB speaks the postgres protocol, but aborts transactions which
+	// conflict with others instead of blocking them. Such transactions have to
+	// be retried by the client.
+	flavorCockroach = flavor{
+		executeTx: executeCockroachTx,
+
+		supportsTimezones: true,
+	}
+
 	flavorSQLite3 = flavor{
 		queryReplacers: []replacer{
 			{bindRegexp, "?"},
@@ -102,6 +116,80 @@ var (
 	}
 )
 
+// cockroachMaxTxAttempts bounds how often a transaction is retried before
+// the serialization failure is returned.
+const cockroachMaxTxAttempts = 10
+
+// cockroachRestartSavepoint is the savepoint CockroachDB treats as the
+// beginning of a retryable transaction.
+//
+// See: https://www.cockroachlabs.com/docs/stable/advanced-client-side-transaction-retries
+const cockroachRestartSavepoint = "cockroach_restart"
+
+// executeCockroachTx runs fn in a transaction following CockroachDB's client
+// side retry protocol: on a serialization failure the transaction is rolled
+// back to the restart savepoint and fn is run again.
+func executeCockroachTx(db *sql.DB, fn func(sqlTx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// SERIALIZABLE is the default, but clusters can be configured otherwise.
+	if _, err := tx.Exec(`SET TRANSACTION ISOLATION LEVEL SERIALIZABLE;`); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`SAVEPOINT ` + cockroachRestartSavepoint); err != nil {
+		return err
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := fn(tx)
+		if err == nil {
+			_, err = tx.Exec(`RELEASE SAVEPOINT ` + cockroachRestartSavepoint)
+			if err == nil {
+				return tx.Commit()
+			}
+		}
+		if !isCockroachRetryable(err) {
+			return err
+		}
+		if attempt >= cockroachMaxTxAttempts {
+			return fmt.Errorf("%w: %v", storage.ErrConflict, err)
+		}
+		if _, err := tx.Exec(`ROLLBACK TO SAVEPOINT ` + cockroachRestartSavepoint); err != nil {
+			return err
+		}
+	}
+}
+
+// isCockroachRetryable reports if a transaction failed because of a conflict
+// with another transaction and can be retried.
+func isCockroachRetryable(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == pgErrSerializationFailure
+	}
+	// The storage wraps most errors with "%v", which drops the error code.
+	// CockroachDB prefixes the messages of all retryable errors.
+	return err != nil && strings.Contains(err.Error(), "restart transaction")
+}
+
+// conflictError wraps serialization failures, which abort transactions that
+// conflict with others, with storage.ErrConflict so they can be retried.
+func conflictError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == pgErrSerializationFailure {
+		return fmt.Errorf("%w: %v", storage.ErrConflict, err)
+	}
+	// The storage wraps most errors with "%v", which drops the error code.
+	if err != nil && strings.Contains(err.Error(), "could not serialize access") {
+		return fmt.Errorf("%w: %v", storage.ErrConflict, err)
+	}
+	return err
+}
+
 func (f flavor) translate(query string) string {
 	// TODO(ericchiang): Heavy cashing.
 	for _, r := range f.queryReplacers {
@@ -132,9 +220,20 @@ type conn struct {
 	flavor             *flavor
 	logger             *slog.Logger
 	alreadyExistsCheck func(err error) bool
+	// notifyChanges is set if changes to clients, connectors and keys are
+	// notified to other instances, see notifyChange.
+	notifyChanges bool
+
+	// leader is set if leader election is enabled, see startLeaderElection.
+	leader             *storage.LeaseElection
+	stopLeaderElection func()
 }
 
 func (c *conn) Close() error {
+	if c.stopLeaderElection != nil {
+		// Release the lease while the database is still open.
+		c.stopLeaderElection()
+	}
 	return c.db.Close()
 }
 
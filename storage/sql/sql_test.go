@@ -1,6 +1,14 @@
 package sql
 
-import "testing"
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+
+	"github.com/dexidp/dex/storage"
+)
 
 func TestTranslate(t *testing.T) {
 	tests := []struct {
@@ -53,3 +61,83 @@ func TestTranslate(t *testing.T) {
 		}
 	}
 }
+
+func TestIsCockroachRetryable(t *testing.T) {
+	tests := []struct {
+		testCase string
+		err      error
+		exp      bool
+	}{
+		{"no error", nil, false},
+		{"serialization failure", &pq.Error{Code: pgErrSerializationFailure}, true},
+		{"wrapped serialization failure", fmt.Errorf("update: %w", &pq.Error{Code: pgErrSerializationFailure}), true},
+		{
+			"serialization failure wrapped with %v",
+			fmt.Errorf("update: %v", &pq.Error{
+				Code:    pgErrSerializationFailure,
+				Message: "restart transaction: TransactionRetryWithProtoRefreshError: WriteTooOldError",
+			}),
+			true,
+		},
+		{"unique violation", &pq.Error{Code: pgErrUniqueViolation}, false},
+		{"other error", errors.New("connection refused"), false},
+	}
+
+	for _, tc := range tests {
+		if got := isCockroachRetryable(tc.err); got != tc.exp {
+			t.Errorf("%s: want=%t, got=%t", tc.testCase, tc.exp, got)
+		}
+	}
+}
+
+func TestConflictError(t *testing.T) {
+	tests := []struct {
+		testCase string
+		err      error
+		conflict bool
+	}{
+		{"no error", nil, false},
+		{"serialization failure", &pq.Error{Code: pgErrSerializationFailure}, true},
+		{
+			"serialization failure wrapped with %v",
+			fmt.Errorf("update: %v", &pq.Error{
+				Code:    pgErrSerializationFailure,
+				Message: "could not serialize access due to concurrent update",
+			}),
+			true,
+		},
+		{"unique violation", &pq.Error{Code: pgErrUniqueViolation}, false},
+		{"other error", errors.New("connection refused"), false},
+	}
+
+	for _, tc := range tests {
+		err := conflictError(tc.err)
+		if got := errors.Is(err, storage.ErrConflict); got != tc.conflict {
+			t.Errorf("%s: want conflict=%t, got %v", tc.testCase, tc.conflict, err)
+		}
+	}
+}
+
+func TestDecodeChange(t *testing.T) {
+	tests := []struct {
+		testCase string
+		n        *pq.Notification
+		exp      storage.Change
+		err      bool
+	}{
+		{"reconnect", nil, storage.Change{}, false},
+		{"client", &pq.Notification{Extra: `{"kind":"client","id":"app"}`}, storage.Change{Kind: storage.ChangeClient, ID: "app"}, false},
+		{"keys", &pq.Notification{Extra: `{"kind":"keys"}`}, storage.Change{Kind: storage.ChangeKeys}, false},
+		{"invalid", &pq.Notification{Extra: `client:app`}, storage.Change{}, true},
+	}
+
+	for _, tc := range tests {
+		got, err := decodeChange(tc.n)
+		if (err != nil) != tc.err {
+			t.Errorf("%s: want error=%t, got %v", tc.testCase, tc.err, err)
+		}
+		if got != tc.exp {
+			t.Errorf("%s: want %+v, got %+v", tc.testCase, tc.exp, got)
+		}
+	}
+}
@@ -4,9 +4,13 @@
 package sql
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
 
 	sqlite3 "github.com/mattn/go-sqlite3"
 
@@ -17,8 +21,30 @@ import (
 type SQLite3 struct {
 	// File to
 	File string `json:"file"`
+
+	// JournalMode of the database, e.g. "WAL" to let other processes, such as
+	// litestream, read the database while dex writes to it. Defaults to
+	// SQLite's "DELETE".
+	JournalMode string `json:"journalMode"`
+	// BusyTimeout is the number of milliseconds to wait for a lock held by
+	// another process before failing with "database is locked". Defaults to
+	// 5000.
+	BusyTimeout int `json:"busyTimeout"`
+	// Synchronous controls how often SQLite waits for writes to reach the
+	// disk: "OFF", "NORMAL", "FULL" or "EXTRA". "NORMAL" is safe in WAL mode.
+	// Defaults to SQLite's "FULL".
+	Synchronous string `json:"synchronous"`
+	// WALAutoCheckpoint is the number of WAL pages after which SQLite copies
+	// the WAL back into the database. Set it to 0 to leave checkpoints to a
+	// replication tool such as litestream. Defaults to SQLite's 1000.
+	WALAutoCheckpoint *int `json:"walAutoCheckpoint"`
 }
 
+var (
+	sqliteJournalModes = []string{"DELETE", "TRUNCATE", "PERSIST", "MEMORY", "WAL", "OFF"}
+	sqliteSynchronous  = []string{"OFF", "NORMAL", "FULL", "EXTRA"}
+)
+
 // Open creates a new storage implementation backed by SQLite3
 func (s *SQLite3) Open(logger *slog.Logger) (storage.Storage, error) {
 	conn, err := s.open(logger)
@@ -28,12 +54,69 @@ func (s *SQLite3) Open(logger *slog.Logger) (storage.Storage, error) {
 	return conn, nil
 }
 
+// pragmas returns the statements configuring each connection.
+func (s *SQLite3) pragmas() ([]string, error) {
+	var pragmas []string
+	if s.JournalMode != "" {
+		mode := strings.ToUpper(s.JournalMode)
+		if !slices.Contains(sqliteJournalModes, mode) {
+			return nil, fmt.Errorf("invalid journal mode %q, must be one of %s", s.JournalMode, strings.Join(sqliteJournalModes, ", "))
+		}
+		pragmas = append(pragmas, "PRAGMA journal_mode = "+mode)
+	}
+	if s.BusyTimeout < 0 {
+		return nil, fmt.Errorf("invalid busy timeout %d, must not be negative", s.BusyTimeout)
+	}
+	if s.BusyTimeout > 0 {
+		pragmas = append(pragmas, fmt.Sprintf("PRAGMA busy_timeout = %d", s.BusyTimeout))
+	}
+	if s.Synchronous != "" {
+		synchronous := strings.ToUpper(s.Synchronous)
+		if !slices.Contains(sqliteSynchronous, synchronous) {
+			return nil, fmt.Errorf("invalid synchronous %q, must be one of %s", s.Synchronous, strings.Join(sqliteSynchronous, ", "))
+		}
+		pragmas = append(pragmas, "PRAGMA synchronous = "+synchronous)
+	}
+	if s.WALAutoCheckpoint != nil {
+		if *s.WALAutoCheckpoint < 0 {
+			return nil, fmt.Errorf("invalid wal auto checkpoint %d, must not be negative", *s.WALAutoCheckpoint)
+		}
+		pragmas = append(pragmas, fmt.Sprintf("PRAGMA wal_autocheckpoint = %d", *s.WALAutoCheckpoint))
+	}
+	return pragmas, nil
+}
+
 func (s *SQLite3) open(logger *slog.Logger) (*conn, error) {
-	db, err := sql.Open("sqlite3", s.File)
+	pragmas, err := s.pragmas()
 	if err != nil {
 		return nil, err
 	}
 
+	// Take the write lock when a transaction begins rather than when it first
+	// writes. Upgrading a read lock fails right away, regardless of the busy
+	// timeout, if another process holds the lock in the meantime.
+	dataSourceName := s.File
+	if strings.Contains(dataSourceName, "?") {
+		dataSourceName += "&_txlock=immediate"
+	} else {
+		dataSourceName += "?_txlock=immediate"
+	}
+
+	// Pragmas are set for each connection, since the pool may replace them.
+	db := sql.OpenDB(sqliteConnector{
+		driver: &sqlite3.SQLiteDriver{
+			ConnectHook: func(c *sqlite3.SQLiteConn) error {
+				for _, pragma := range pragmas {
+					if _, err := c.Exec(pragma, nil); err != nil {
+						return fmt.Errorf("%s: %v", pragma, err)
+					}
+				}
+				return nil
+			},
+		},
+		dataSourceName: dataSourceName,
+	})
+
 	// always allow only one connection to sqlite3, any other thread/go-routine
 	// attempting concurrent access will have to wait
 	db.SetMaxOpenConns(1)
@@ -45,9 +128,24 @@ func (s *SQLite3) open(logger *slog.Logger) (*conn, error) {
 		return sqlErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey
 	}
 
-	c := &conn{db, &flavorSQLite3, logger, errCheck}
+	c := &conn{db: db, flavor: &flavorSQLite3, logger: logger, alreadyExistsCheck: errCheck}
 	if _, err := c.migrate(); err != nil {
 		return nil, fmt.Errorf("failed to perform migrations: %v", err)
 	}
 	return c, nil
 }
+
+// sqliteConnector opens connections of a SQLite driver with its own connect
+// hook, without registering the driver globally.
+type sqliteConnector struct {
+	driver         *sqlite3.SQLiteDriver
+	dataSourceName string
+}
+
+func (c sqliteConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dataSourceName)
+}
+
+func (c sqliteConnector) Driver() driver.Driver {
+	return c.driver
+}
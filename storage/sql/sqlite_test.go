@@ -4,9 +4,62 @@
 package sql
 
 import (
+	"path/filepath"
 	"testing"
 )
 
 func TestSQLite3(t *testing.T) {
-	testDB(t, &SQLite3{":memory:"}, false)
+	testDB(t, &SQLite3{File: ":memory:"}, false)
+}
+
+func TestSQLite3Pragmas(t *testing.T) {
+	checkpoint := 0
+	s := &SQLite3{
+		File:              filepath.Join(t.TempDir(), "dex.db"),
+		JournalMode:       "wal",
+		BusyTimeout:       1234,
+		Synchronous:       "NORMAL",
+		WALAutoCheckpoint: &checkpoint,
+	}
+	c, err := s.open(logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	tests := []struct {
+		pragma string
+		want   string
+	}{
+		{"journal_mode", "wal"},
+		{"busy_timeout", "1234"},
+		{"synchronous", "1"},
+		{"wal_autocheckpoint", "0"},
+	}
+	for _, tc := range tests {
+		var got string
+		if err := c.db.QueryRow("PRAGMA " + tc.pragma).Scan(&got); err != nil {
+			t.Fatalf("query %s: %v", tc.pragma, err)
+		}
+		if got != tc.want {
+			t.Errorf("%s: want %q, got %q", tc.pragma, tc.want, got)
+		}
+	}
+}
+
+func TestSQLite3InvalidPragmas(t *testing.T) {
+	negative := -1
+	tests := map[string]*SQLite3{
+		"journal mode":        {File: ":memory:", JournalMode: "fast"},
+		"busy timeout":        {File: ":memory:", BusyTimeout: -1},
+		"synchronous":         {File: ":memory:", Synchronous: "sometimes"},
+		"wal auto checkpoint": {File: ":memory:", WALAutoCheckpoint: &negative},
+	}
+	for name, s := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := s.open(logger); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
 }
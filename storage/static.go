@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"strings"
 )
@@ -156,7 +157,7 @@ func (s staticPasswordsStorage) DeletePassword(email string) error {
 
 func (s staticPasswordsStorage) UpdatePassword(email string, updater func(old Password) (Password, error)) error {
 	if s.isStatic(email) {
-		return errors.New("static passwords: read-only cannot update password")
+		return fmt.Errorf("static passwords: %w cannot update password", ErrReadOnly)
 	}
 	return s.Storage.UpdatePassword(email, updater)
 }
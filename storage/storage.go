@@ -5,9 +5,11 @@ import (
 	"crypto"
 	"crypto/rand"
 	"encoding/base32"
+	"encoding/json"
 	"errors"
 	"io"
 	"math/big"
+	"slices"
 	"strings"
 	"time"
 
@@ -20,6 +22,14 @@ var (
 
 	// ErrAlreadyExists is the error returned by storages if a resource ID is taken during a create.
 	ErrAlreadyExists = errors.New("ID already exists")
+
+	// ErrConflict is wrapped by the errors storages return if an update failed
+	// because the object was changed concurrently. See RetryOnConflict.
+	ErrConflict = errors.New("concurrent conflicting update")
+
+	// ErrReadOnly is wrapped by the errors storages return if an object, such as
+	// a static password, can't be changed.
+	ErrReadOnly = errors.New("read-only")
 )
 
 // Kubernetes only allows lower case letters for names.
@@ -27,8 +37,8 @@ var (
 // TODO(ericchiang): refactor ID creation onto the storage.
 var encoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567")
 
-// Valid characters for user codes
-const validUserCharacters = "BCDFGHJKLMNPQRSTVWXZ"
+// UserCodeCharset holds the valid characters for user codes by default.
+const UserCodeCharset = "BCDFGHJKLMNPQRSTVWXZ"
 
 // NewDeviceCode returns a 32 char alphanumeric cryptographically secure string
 func NewDeviceCode() string {
@@ -54,6 +64,51 @@ func NewHMACKey(h crypto.Hash) []byte {
 	return []byte(newSecureID(h.Size()))
 }
 
+// ChangeKind is a kind of object whose changes are notified by a
+// ChangeNotifier.
+type ChangeKind string
+
+// Kinds of changed objects.
+const (
+	ChangeClient    ChangeKind = "client"
+	ChangeConnector ChangeKind = "connector"
+	ChangeKeys      ChangeKind = "keys"
+)
+
+// Change is a change of an object by one of the instances sharing a storage.
+type Change struct {
+	// Kind of the object. If empty, any object may have changed, for instance
+	// because notifications were missed while disconnected.
+	Kind ChangeKind `json:"kind"`
+	// ID of the object, empty for keys.
+	ID string `json:"id,omitempty"`
+}
+
+// ChangeNotifier is implemented by storages which notify all instances
+// sharing them of changes to clients, connectors and keys, so they can drop
+// cached copies right away.
+type ChangeNotifier interface {
+	// OnChange registers a function called with every change by any of the
+	// instances, including this one, until the storage is closed.
+	OnChange(fn func(Change))
+}
+
+// actorKey is the context key of who makes changes with a context.
+type actorKey struct{}
+
+// WithActor returns a context recording who makes the changes done with it,
+// for storages auditing who created objects.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns who makes the changes done with a context, or an
+// empty string if that's unknown.
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
 // GCResult returns the number of objects deleted by garbage collection.
 type GCResult struct {
 	AuthRequests   int64
@@ -70,6 +125,38 @@ func (g *GCResult) IsEmpty() bool {
 		g.DeviceTokens == 0
 }
 
+// GCResource is a type of object deleted by garbage collection once expired.
+type GCResource string
+
+// Types of objects deleted by garbage collection.
+const (
+	GCAuthRequests   GCResource = "authRequests"
+	GCAuthCodes      GCResource = "authCodes"
+	GCDeviceRequests GCResource = "deviceRequests"
+	GCDeviceTokens   GCResource = "deviceTokens"
+)
+
+// GCResources lists all types of objects deleted by garbage collection.
+var GCResources = []GCResource{GCAuthRequests, GCAuthCodes, GCDeviceRequests, GCDeviceTokens}
+
+// GCOptions control a garbage collection run.
+type GCOptions struct {
+	// Resources to delete expired objects of. If empty, all of them.
+	Resources []GCResource
+
+	// BatchSize limits the number of objects deleted by a single statement,
+	// so large tables don't lock up while expired objects are deleted. SQL
+	// storages delete batches until no expired objects are left, the
+	// Kubernetes storage deletes at most one batch of each resource per run.
+	// If zero, storages use their default.
+	BatchSize int
+}
+
+// Includes returns whether garbage collection deletes objects of a resource.
+func (o GCOptions) Includes(r GCResource) bool {
+	return len(o.Resources) == 0 || slices.Contains(o.Resources, r)
+}
+
 // Storage is the storage interface used by the server. Implementations are
 // required to be able to perform atomic compare-and-swap updates and either
 // support timezones or standardize on UTC.
@@ -86,6 +173,7 @@ type Storage interface {
 	CreateConnector(ctx context.Context, c Connector) error
 	CreateDeviceRequest(ctx context.Context, d DeviceRequest) error
 	CreateDeviceToken(ctx context.Context, d DeviceToken) error
+	CreateWebhook(ctx context.Context, w Webhook) error
 
 	// TODO(ericchiang): return (T, bool, error) so we can indicate not found
 	// requests that way instead of using ErrNotFound.
@@ -99,11 +187,14 @@ type Storage interface {
 	GetConnector(id string) (Connector, error)
 	GetDeviceRequest(userCode string) (DeviceRequest, error)
 	GetDeviceToken(deviceCode string) (DeviceToken, error)
+	GetWebhook(id string) (Webhook, error)
 
 	ListClients() ([]Client, error)
 	ListRefreshTokens() ([]RefreshToken, error)
 	ListPasswords() ([]Password, error)
 	ListConnectors() ([]Connector, error)
+	ListDeviceRequests() ([]DeviceRequest, error)
+	ListWebhooks() ([]Webhook, error)
 
 	// Delete methods MUST be atomic.
 	DeleteAuthRequest(id string) error
@@ -113,6 +204,7 @@ type Storage interface {
 	DeletePassword(email string) error
 	DeleteOfflineSessions(userID string, connID string) error
 	DeleteConnector(id string) error
+	DeleteWebhook(id string) error
 
 	// Update methods take a function for updating an object then performs that update within
 	// a transaction. "updater" functions may be called multiple times by a single update call.
@@ -136,10 +228,19 @@ type Storage interface {
 	UpdateOfflineSessions(userID string, connID string, updater func(s OfflineSessions) (OfflineSessions, error)) error
 	UpdateConnector(id string, updater func(c Connector) (Connector, error)) error
 	UpdateDeviceToken(deviceCode string, updater func(t DeviceToken) (DeviceToken, error)) error
+	UpdateWebhook(id string, updater func(w Webhook) (Webhook, error)) error
 
 	// GarbageCollect deletes all expired AuthCodes,
 	// AuthRequests, DeviceRequests, and DeviceTokens.
-	GarbageCollect(now time.Time) (GCResult, error)
+	GarbageCollect(now time.Time, opts GCOptions) (GCResult, error)
+}
+
+// LeaderElector is implemented by storages which elect one of the dex
+// instances sharing them as leader, so that work like garbage collection and
+// key rotation isn't done by every instance.
+type LeaderElector interface {
+	// IsLeader reports whether this instance is currently the leader.
+	IsLeader() bool
 }
 
 // Client represents an OAuth2 client.
@@ -158,6 +259,16 @@ type Client struct {
 	// requested to redirect to MUST match one of these values, unless the client is "public".
 	RedirectURIs []string `json:"redirectURIs" yaml:"redirectURIs"`
 
+	// RedirectURIPatterns opt the client into redirect URIs matching a pattern
+	// like "https://*.preview.example.com/callback", where the wildcard stands
+	// for exactly one DNS label, e.g. for preview environments.
+	RedirectURIPatterns []string `json:"redirectURIPatterns,omitempty" yaml:"redirectURIPatterns,omitempty"`
+
+	// AllowedOrigins are the web origins, like "https://app.example.com",
+	// browser-based clients may call the token, userinfo and keys endpoints
+	// from (CORS).
+	AllowedOrigins []string `json:"allowedOrigins,omitempty" yaml:"allowedOrigins,omitempty"`
+
 	// TrustedPeers are a list of peers which can issue tokens on this client's behalf using
 	// the dynamic "oauth2:server:client_id:(client_id)" scope. If a peer makes such a request,
 	// this client's ID will appear as the ID Token's audience.
@@ -171,6 +282,82 @@ type Client struct {
 	// Name and LogoURL used when displaying this client to the end user.
 	Name    string `json:"name" yaml:"name"`
 	LogoURL string `json:"logoURL" yaml:"logoURL"`
+
+	// Links to the privacy policy and the terms of service of the client,
+	// shown on the approval page.
+	PolicyURL         string `json:"policyURL,omitempty" yaml:"policyURL,omitempty"`
+	TermsOfServiceURL string `json:"termsOfServiceURL,omitempty" yaml:"termsOfServiceURL,omitempty"`
+
+	// RefreshTokenPolicy overrides the server wide refresh token policy for this client.
+	RefreshTokenPolicy *RefreshTokenPolicy `json:"refreshTokenPolicy,omitempty" yaml:"refreshTokenPolicy,omitempty"`
+
+	// TokenExpiry overrides the server wide token lifetimes for this client.
+	TokenExpiry *TokenExpiry `json:"tokenExpiry,omitempty" yaml:"tokenExpiry,omitempty"`
+
+	// GroupsFilter limits the groups released to this client.
+	GroupsFilter *GroupsFilter `json:"groupsFilter,omitempty" yaml:"groupsFilter,omitempty"`
+
+	// ClaimsTransforms are applied to tokens issued to this client, after the
+	// server wide transforms.
+	ClaimsTransforms []ClaimsTransform `json:"claimsTransforms,omitempty" yaml:"claimsTransforms,omitempty"`
+
+	// ForwardClaimSourceTokens opts the client into receiving the access
+	// tokens of upstream claim sources in its ID tokens. Otherwise they're
+	// neither stored nor forwarded.
+	ForwardClaimSourceTokens bool `json:"forwardClaimSourceTokens,omitempty" yaml:"forwardClaimSourceTokens,omitempty"`
+
+	// PreviousSecret is the secret replaced by the last rotation, which is
+	// still accepted until it expires so relying parties can roll their
+	// credentials without downtime.
+	PreviousSecret *PreviousSecret `json:"previousSecret,omitempty" yaml:"previousSecret,omitempty"`
+
+	// CreatedAt is when the client was created through the API, and LastUsed
+	// when it last authenticated at the token endpoint. Zero if unknown.
+	CreatedAt time.Time `json:"createdAt" yaml:"createdAt"`
+	LastUsed  time.Time `json:"lastUsed" yaml:"lastUsed"`
+}
+
+// PreviousSecret is a client secret which has been rotated out. Only a bcrypt
+// hash of it is kept, since it never has to be shown again.
+type PreviousSecret struct {
+	Hash   []byte    `json:"hash" yaml:"hash"`
+	Expiry time.Time `json:"expiry" yaml:"expiry"`
+}
+
+// RefreshTokenPolicy holds per-client overrides of the refresh token policy.
+// Durations use the time.ParseDuration format. Unset fields fall back to the
+// server wide settings.
+type RefreshTokenPolicy struct {
+	DisableRotation   *bool  `json:"disableRotation,omitempty" yaml:"disableRotation,omitempty"`
+	ReuseInterval     string `json:"reuseInterval,omitempty" yaml:"reuseInterval,omitempty"`
+	AbsoluteLifetime  string `json:"absoluteLifetime,omitempty" yaml:"absoluteLifetime,omitempty"`
+	ValidIfNotUsedFor string `json:"validIfNotUsedFor,omitempty" yaml:"validIfNotUsedFor,omitempty"`
+}
+
+// TokenExpiry holds per-client overrides of the lifetimes of ID and access
+// tokens. Durations use the time.ParseDuration format. Unset fields fall back
+// to the server wide settings.
+type TokenExpiry struct {
+	IDTokens     string `json:"idTokens,omitempty" yaml:"idTokens,omitempty"`
+	AccessTokens string `json:"accessTokens,omitempty" yaml:"accessTokens,omitempty"`
+}
+
+// GroupsFilter limits the groups claim of tokens issued to a client, for
+// example to keep large group lists from LDAP within header size limits. A
+// group is released if it's listed in AllowedGroups, starts with one of the
+// Prefixes, or fully matches one of the regular expressions in Patterns.
+type GroupsFilter struct {
+	AllowedGroups []string `json:"allowedGroups,omitempty" yaml:"allowedGroups,omitempty"`
+	Prefixes      []string `json:"prefixes,omitempty" yaml:"prefixes,omitempty"`
+	Patterns      []string `json:"patterns,omitempty" yaml:"patterns,omitempty"`
+}
+
+// ClaimsTransform sets a claim of ID and access tokens to the result of a CEL
+// expression, right before the token is signed. An expression evaluating to
+// null removes the claim.
+type ClaimsTransform struct {
+	Claim      string `json:"claim" yaml:"claim"`
+	Expression string `json:"expression" yaml:"expression"`
 }
 
 // Claims represents the ID Token claims supported by the server.
@@ -182,6 +369,31 @@ type Claims struct {
 	EmailVerified     bool
 
 	Groups []string
+
+	// CustomClaims holds additional claims provided by the connector, which are
+	// released to clients through custom scopes.
+	CustomClaims map[string]interface{}
+
+	// ClaimSources holds the sources of aggregated and distributed claims
+	// provided by the connector, keyed by source name.
+	ClaimSources map[string]ClaimSource
+}
+
+// ClaimSource is a source of aggregated or distributed claims, which clients
+// fetch or verify themselves rather than reading them from the ID token.
+//
+// https://openid.net/specs/openid-connect-core-1_0.html#AggregatedDistributedClaims
+type ClaimSource struct {
+	// Claims provided by the source.
+	Claims []string `json:"claims"`
+
+	// JWT containing the claims, for aggregated claims.
+	JWT string `json:"jwt,omitempty"`
+
+	// Endpoint serving the claims and the access token used to query it, for
+	// distributed claims.
+	Endpoint    string `json:"endpoint,omitempty"`
+	AccessToken string `json:"accessToken,omitempty"`
 }
 
 // PKCE is a container for the data needed to perform Proof Key for Code Exchange (RFC 7636) auth flow
@@ -207,6 +419,9 @@ type AuthRequest struct {
 	Nonce         string
 	State         string
 
+	// ClaimsRequest holds the JSON encoded OIDC claims request parameter, if any.
+	ClaimsRequest []byte
+
 	// The client has indicated that the end user must be shown an approval prompt
 	// on all requests. The server cannot cache their initial action for subsequent
 	// attempts.
@@ -262,6 +477,9 @@ type AuthCode struct {
 	// Scopes authorized by the end user for the client.
 	Scopes []string
 
+	// Individual claims requested through the OIDC claims request parameter.
+	ClaimsRequest []byte
+
 	// Authentication data provided by an upstream source.
 	ConnectorID   string
 	ConnectorData []byte
@@ -271,6 +489,10 @@ type AuthCode struct {
 
 	// PKCE CodeChallenge and CodeChallengeMethod
 	PKCE PKCE
+
+	// Issuer URL of the additional issuer the code was issued by, when several
+	// issuers share a storage. Empty for the default issuer. See WithIssuer.
+	Issuer string
 }
 
 // RefreshToken is an OAuth2 refresh token which allows a client to request new
@@ -300,9 +522,17 @@ type RefreshToken struct {
 	// however those scopes must be encompassed by this set.
 	Scopes []string
 
+	// Individual claims requested through the OIDC claims request parameter in
+	// the initial request.
+	ClaimsRequest []byte
+
 	// Nonce value supplied during the initial redirect. This is required to be part
 	// of the claims of any future id_token generated by the client.
 	Nonce string
+
+	// Issuer URL of the additional issuer the token was issued by, when several
+	// issuers share a storage. Empty for the default issuer. See WithIssuer.
+	Issuer string
 }
 
 // RefreshTokenRef is a reference object that contains metadata about refresh tokens.
@@ -334,8 +564,8 @@ type OfflineSessions struct {
 
 // Password is an email to password mapping managed by the storage.
 type Password struct {
-	// Email and identifying name of the password. Emails are assumed to be valid and
-	// determining that an end-user controls the address is left to an outside application.
+	// Email and identifying name of the password. Emails are assumed to be valid,
+	// EmailVerified records if an end-user has proven control of the address.
 	//
 	// Emails are case insensitive and should be standardized by the storage.
 	//
@@ -354,6 +584,27 @@ type Password struct {
 
 	// Randomly generated user ID. This is NOT the primary ID of the Password object.
 	UserID string `json:"userID"`
+
+	// Whether the user has proven control of the e-mail address. Determines the
+	// email_verified claim.
+	EmailVerified bool `json:"emailVerified"`
+
+	// Number of failed logins since the last successful one, and the time
+	// until which logins are rejected after too many of them.
+	FailedAttempts int       `json:"failedAttempts"`
+	LockedUntil    time.Time `json:"lockedUntil"`
+}
+
+// UnmarshalJSON treats passwords stored before e-mail addresses were verified
+// as verified, since the email_verified claim was always true for them.
+func (p *Password) UnmarshalJSON(b []byte) error {
+	type password Password
+	data := password{EmailVerified: true}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	*p = Password(data)
+	return nil
 }
 
 // Connector is an object that contains the metadata about connectors used to login to Dex.
@@ -374,6 +625,56 @@ type Connector struct {
 	// However, fixing this requires migrating Kubernetes objects for all previously created connectors,
 	// or making Dex reading both tags and act accordingly.
 	Config []byte `json:"email"`
+	// Display controls how the connector is presented on the login page.
+	Display *ConnectorDisplay `json:"display,omitempty"`
+}
+
+// ConnectorDisplay controls how a connector is presented on the login page.
+type ConnectorDisplay struct {
+	// Order of the connector on the login page. Connectors with a lower order
+	// are shown first, connectors with the same order are sorted by name.
+	Order int `json:"order,omitempty"`
+	// Group is the header of the section of the login page the connector is
+	// shown in, e.g. "Employees". Connectors without a group are shown first.
+	Group string `json:"group,omitempty"`
+	// Hidden connectors aren't shown on the login page. They can still be used
+	// by passing their ID in the connector_id parameter of auth requests.
+	Hidden bool `json:"hidden,omitempty"`
+	// IconURL is the URL of the icon on the button of the connector, e.g. of
+	// an SVG image, or a data URL of the image itself. It replaces the icon
+	// the theme shows for the connector type.
+	IconURL string `json:"iconURL,omitempty"`
+	// ButtonColor is the background color of the button of the connector, as
+	// a CSS hex color like "#2f2f2f".
+	ButtonColor string `json:"buttonColor,omitempty"`
+}
+
+// Webhook is an endpoint notified of identity events, such as logins and
+// revoked sessions.
+type Webhook struct {
+	// ID that will uniquely identify the webhook object.
+	ID string `json:"id"`
+	// URL events are posted to.
+	URL string `json:"url"`
+	// Secret signs the payloads, so the endpoint can verify their origin.
+	Secret string `json:"secret"`
+	// Types of the events posted to the endpoint, all events if empty.
+	Events []string `json:"events,omitempty"`
+	// RetryPolicy controls the redelivery of events the endpoint failed to
+	// accept.
+	RetryPolicy *WebhookRetryPolicy `json:"retryPolicy,omitempty"`
+	// CreatedAt is when the webhook was registered.
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// WebhookRetryPolicy controls the redelivery of events. Unset fields fall back
+// to the server defaults.
+type WebhookRetryPolicy struct {
+	// MaxAttempts is the number of deliveries of an event, including the first.
+	MaxAttempts int `json:"maxAttempts,omitempty"`
+	// Backoff is the delay before the first redelivery, doubled after each
+	// failed attempt, e.g. "10s".
+	Backoff string `json:"backoff,omitempty"`
 }
 
 // VerificationKey is a rotated signing key which can still be used to verify
@@ -397,21 +698,39 @@ type Keys struct {
 	//
 	// For caching purposes, implementations MUST NOT update keys before this time.
 	NextRotation time.Time
+
+	// Keys of additional issuers sharing the storage, indexed by issuer URL.
+	// The fields above hold the keys of the default issuer.
+	IssuerKeys map[string]Keys
 }
 
 // NewUserCode returns a randomized 8 character user code for the device flow.
 // No vowels are included to prevent accidental generation of words
 func NewUserCode() string {
-	code := randomString(8)
-	return code[:4] + "-" + code[4:]
+	return NewUserCodeFromCharset(8, UserCodeCharset)
+}
+
+// NewUserCodeFromCharset returns a randomized user code for the device flow
+// of the given length, made of characters from charset. Groups of four
+// characters are separated by dashes.
+func NewUserCodeFromCharset(length int, charset string) string {
+	code := randomString(length, charset)
+	var b strings.Builder
+	for i := 0; i < len(code); i += 4 {
+		if i > 0 {
+			b.WriteByte('-')
+		}
+		b.WriteString(code[i:min(i+4, len(code))])
+	}
+	return b.String()
 }
 
-func randomString(n int) string {
-	v := big.NewInt(int64(len(validUserCharacters)))
+func randomString(n int, charset string) string {
+	v := big.NewInt(int64(len(charset)))
 	bytes := make([]byte, n)
 	for i := 0; i < n; i++ {
 		c, _ := rand.Int(rand.Reader, v)
-		bytes[i] = validUserCharacters[c.Int64()]
+		bytes[i] = charset[c.Int64()]
 	}
 	return string(bytes)
 }
@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tests for this code are in the "memory" package, since this package doesn't
+// define a concrete storage implementation.
+
+// tracedStorage records a span for every operation of the underlying storage
+// which is passed a context. Operations without a context can't be attributed
+// to the request they're part of, so they aren't traced.
+type tracedStorage struct {
+	Storage
+
+	tracer trace.Tracer
+}
+
+// WithTracing records OpenTelemetry spans for the operations of the
+// underlying storage which are passed a context.
+func WithTracing(s Storage, tp trace.TracerProvider) Storage {
+	return &tracedStorage{
+		Storage: s,
+		tracer:  tp.Tracer("github.com/dexidp/dex/storage"),
+	}
+}
+
+func (s *tracedStorage) start(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return s.tracer.Start(ctx, "storage."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.operation.name", operation)))
+}
+
+func endSpan(span trace.Span, err *error) {
+	if *err != nil {
+		span.SetStatus(codes.Error, outcome(*err))
+		span.RecordError(*err)
+	}
+	span.End()
+}
+
+func (s *tracedStorage) CreateAuthRequest(ctx context.Context, a AuthRequest) (err error) {
+	ctx, span := s.start(ctx, "CreateAuthRequest")
+	defer endSpan(span, &err)
+	return s.Storage.CreateAuthRequest(ctx, a)
+}
+
+func (s *tracedStorage) CreateClient(ctx context.Context, c Client) (err error) {
+	ctx, span := s.start(ctx, "CreateClient")
+	defer endSpan(span, &err)
+	return s.Storage.CreateClient(ctx, c)
+}
+
+func (s *tracedStorage) CreateAuthCode(ctx context.Context, c AuthCode) (err error) {
+	ctx, span := s.start(ctx, "CreateAuthCode")
+	defer endSpan(span, &err)
+	return s.Storage.CreateAuthCode(ctx, c)
+}
+
+func (s *tracedStorage) CreateRefresh(ctx context.Context, r RefreshToken) (err error) {
+	ctx, span := s.start(ctx, "CreateRefresh")
+	defer endSpan(span, &err)
+	return s.Storage.CreateRefresh(ctx, r)
+}
+
+func (s *tracedStorage) CreatePassword(ctx context.Context, p Password) (err error) {
+	ctx, span := s.start(ctx, "CreatePassword")
+	defer endSpan(span, &err)
+	return s.Storage.CreatePassword(ctx, p)
+}
+
+func (s *tracedStorage) CreateOfflineSessions(ctx context.Context, o OfflineSessions) (err error) {
+	ctx, span := s.start(ctx, "CreateOfflineSessions")
+	defer endSpan(span, &err)
+	return s.Storage.CreateOfflineSessions(ctx, o)
+}
+
+func (s *tracedStorage) CreateConnector(ctx context.Context, c Connector) (err error) {
+	ctx, span := s.start(ctx, "CreateConnector")
+	defer endSpan(span, &err)
+	return s.Storage.CreateConnector(ctx, c)
+}
+
+func (s *tracedStorage) CreateDeviceRequest(ctx context.Context, d DeviceRequest) (err error) {
+	ctx, span := s.start(ctx, "CreateDeviceRequest")
+	defer endSpan(span, &err)
+	return s.Storage.CreateDeviceRequest(ctx, d)
+}
+
+func (s *tracedStorage) CreateDeviceToken(ctx context.Context, d DeviceToken) (err error) {
+	ctx, span := s.start(ctx, "CreateDeviceToken")
+	defer endSpan(span, &err)
+	return s.Storage.CreateDeviceToken(ctx, d)
+}
+
+func (s *tracedStorage) CreateWebhook(ctx context.Context, w Webhook) (err error) {
+	ctx, span := s.start(ctx, "CreateWebhook")
+	defer endSpan(span, &err)
+	return s.Storage.CreateWebhook(ctx, w)
+}
@@ -5,7 +5,7 @@ import (
 	"io/fs"
 )
 
-//go:embed static/* templates/* themes/* robots.txt
+//go:embed static/* templates/* themes/* translations/* robots.txt
 var files embed.FS
 
 // FS returns a filesystem with the default web assets.